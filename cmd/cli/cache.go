@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	datacache "github.com/zerfoo/zerfoo/data/cache"
+)
+
+// CacheCommand implements the "cache" CLI command, which inspects and
+// clears the local dataset cache used by predict.
+type CacheCommand struct {
+	out io.Writer
+}
+
+// NewCacheCommand creates a new CacheCommand.
+func NewCacheCommand(out io.Writer) *CacheCommand {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &CacheCommand{out: out}
+}
+
+// Name implements Command.Name.
+func (c *CacheCommand) Name() string { return "cache" }
+
+// Description implements Command.Description.
+func (c *CacheCommand) Description() string {
+	return "Inspect and clear the dataset cache (list, prune, clear)"
+}
+
+// cacheConfig holds parsed cache flags.
+type cacheConfig struct {
+	dir string
+	uri string
+}
+
+// Run implements Command.Run.
+func (c *CacheCommand) Run(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("cache: subcommand required (list, prune, clear)")
+	}
+	sub := args[0]
+	cfg, err := parseCacheArgs(args[1:])
+	if err != nil {
+		return err
+	}
+	if cfg.uri != "" && sub != "clear" {
+		return errors.New("--uri is only valid with clear")
+	}
+
+	store, err := datacache.Open(cfg.dir)
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		return c.list(store)
+	case "prune":
+		st, err := store.Prune()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.out, "Removed %d expired and %d evicted entries, freed %s\n", st.Expired, st.Evicted, formatBytes(st.Freed))
+		return nil
+	case "clear":
+		if cfg.uri != "" {
+			n, err := store.Remove(cfg.uri)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(c.out, "Removed %d entries for %s\n", n, cfg.uri)
+			return nil
+		}
+		if err := store.Clear(); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.out, "Cleared %s\n", store.Dir())
+		return nil
+	default:
+		return fmt.Errorf("cache: unknown subcommand %q (want list, prune, or clear)", sub)
+	}
+}
+
+func (c *CacheCommand) list(store *datacache.Store) error {
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	n, bytes, err := store.Usage()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.out, "%s: %d entries, %s\n", store.Dir(), n, formatBytes(bytes))
+	if len(entries) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "URI\tVARIANT\tSIZE\tLAST USED\tSTATUS")
+	for _, e := range entries {
+		variant := e.Variant
+		if variant == "" {
+			variant = "source"
+		}
+		status := "ok"
+		if store.Expired(e) {
+			status = "expired"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.URI, variant, formatBytes(e.Size), e.Accessed.Local().Format(time.DateTime), status)
+	}
+	return tw.Flush()
+}
+
+func parseCacheArgs(args []string) (*cacheConfig, error) {
+	cfg := &cacheConfig{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch arg {
+		case "--dir":
+			cfg.dir, err = nextVal("--dir")
+		case "--uri":
+			cfg.uri, err = nextVal("--uri")
+		default:
+			return nil, fmt.Errorf("unknown flag: %s", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// Usage implements Command.Usage.
+func (c *CacheCommand) Usage() string {
+	return `cache <list|prune|clear> [OPTIONS]
+
+Inspect and clear the local dataset cache. predict stores downloaded data
+files there, keyed by URI, and parsed data, keyed by URI and content hash,
+so unchanged data is neither downloaded nor parsed twice. Entries expire
+after 7 days and the least recently used are evicted beyond 10GB.
+
+SUBCOMMANDS:
+  list     Show every entry with its size, last use, and expiry status
+  prune    Remove expired entries and evict down to the size budget
+  clear    Remove every entry, or only those for --uri
+
+OPTIONS:
+  --dir <dir>   Cache directory (default: ~/.zerfoo/datasets)
+  --uri <uri>   With clear, remove only the entries for this source`
+}
+
+// Examples implements Command.Examples.
+func (c *CacheCommand) Examples() []string {
+	return []string{
+		"cache list",
+		"cache prune",
+		"cache clear --uri https://example.com/live.csv",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*CacheCommand)(nil)
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	datacache "github.com/zerfoo/zerfoo/data/cache"
+)
+
+func TestCacheCommand(t *testing.T) {
+	dir := t.TempDir()
+	store, err := datacache.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uri := range []string{"https://a/x.csv", "https://b/y.csv"} {
+		if _, err := store.Put(uri, "", "", func(w io.Writer) error {
+			_, err := io.WriteString(w, uri)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out strings.Builder
+	cmd := NewCacheCommand(&out)
+	if err := cmd.Run(context.Background(), []string{"list", "--dir", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "2 entries") || !strings.Contains(out.String(), "https://a/x.csv") {
+		t.Errorf("list output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := cmd.Run(context.Background(), []string{"clear", "--dir", dir, "--uri", "https://a/x.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _, _ := store.Usage(); n != 1 {
+		t.Errorf("%d entries after clear --uri, want 1", n)
+	}
+
+	if err := cmd.Run(context.Background(), []string{"prune", "--dir", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(context.Background(), []string{"clear", "--dir=" + dir}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _, _ := store.Usage(); n != 0 {
+		t.Errorf("%d entries after clear, want 0", n)
+	}
+}
+
+func TestCacheCommand_Errors(t *testing.T) {
+	cmd := NewCacheCommand(io.Discard)
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{},
+		{"bogus", "--dir", dir},
+		{"list", "--dir", dir, "--uri", "x"},
+		{"list", "--bogus"},
+		{"list", "--dir"},
+	} {
+		if err := cmd.Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) should fail", args)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/zerfoo/zerfoo/model/calibration"
+)
+
+// CalibrationCommand implements the "calibration" CLI command, which
+// inspects activation statistics saved by a calibration pass.
+type CalibrationCommand struct {
+	out io.Writer
+}
+
+// NewCalibrationCommand creates a new CalibrationCommand.
+func NewCalibrationCommand(out io.Writer) *CalibrationCommand {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &CalibrationCommand{out: out}
+}
+
+// Name implements Command.Name.
+func (c *CalibrationCommand) Name() string { return "calibration" }
+
+// Description implements Command.Description.
+func (c *CalibrationCommand) Description() string {
+	return "Inspect activation calibration statistics (show)"
+}
+
+// calibrationConfig holds parsed calibration flags.
+type calibrationConfig struct {
+	path       string
+	percentile float64
+	tensor     string
+	json       bool
+}
+
+// Run implements Command.Run.
+func (c *CalibrationCommand) Run(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("calibration: subcommand required (show)")
+	}
+	if args[0] != "show" {
+		return fmt.Errorf("calibration: unknown subcommand %q (want show)", args[0])
+	}
+	cfg, err := parseCalibrationArgs(args[1:])
+	if err != nil {
+		return err
+	}
+
+	report, err := calibration.Load(cfg.path)
+	if err != nil {
+		return err
+	}
+	if cfg.tensor != "" {
+		s := report.Tensor(cfg.tensor)
+		if s == nil {
+			return fmt.Errorf("calibration: no tensor %q in %s", cfg.tensor, cfg.path)
+		}
+		report.Tensors = []*calibration.TensorStats{s}
+	}
+	if cfg.json {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%s: %d tensors over %d batches\n", cfg.path, len(report.Tensors), report.Batches)
+	if len(report.Tensors) == 0 {
+		return nil
+	}
+	pct := "P" + strconv.FormatFloat(cfg.percentile, 'g', -1, 64)
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "TENSOR\tOP\tSHAPE\tCOUNT\tMIN\tMAX\tMEAN\tSTD\tABSMAX\t|%s|\n", pct)
+	for _, s := range report.Tensors {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%v\t%d\t%.6g\t%.6g\t%.6g\t%.6g\t%.6g\t%.6g\n",
+			s.Name, s.OpType, s.Shape, s.Count, s.Min, s.Max, s.Mean, s.Std, s.AbsMax(), s.Percentile(cfg.percentile))
+	}
+	return tw.Flush()
+}
+
+func parseCalibrationArgs(args []string) (*calibrationConfig, error) {
+	cfg := &calibrationConfig{percentile: 99.99}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch arg {
+		case "--percentile":
+			var v string
+			if v, err = nextVal("--percentile"); err == nil {
+				cfg.percentile, err = strconv.ParseFloat(v, 64)
+				if err != nil || cfg.percentile < 0 || cfg.percentile > 100 {
+					err = fmt.Errorf("invalid --percentile: %s (want 0 to 100)", v)
+				}
+			}
+		case "--tensor":
+			cfg.tensor, err = nextVal("--tensor")
+		case "--json":
+			cfg.json = true
+		default:
+			if len(arg) > 1 && arg[0] == '-' {
+				return nil, fmt.Errorf("unknown flag: %s", arg)
+			}
+			if cfg.path != "" {
+				return nil, fmt.Errorf("unexpected argument: %s", arg)
+			}
+			cfg.path = arg
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.path == "" {
+		return nil, errors.New("calibration show: statistics file required")
+	}
+	return cfg, nil
+}
+
+// Usage implements Command.Usage.
+func (c *CalibrationCommand) Usage() string {
+	return `calibration show <file> [OPTIONS]
+
+Show the activation statistics a calibration pass saved (see package
+model/calibration): per tensor, the value count, min, max, mean, standard
+deviation, absolute maximum, and an absolute-value percentile read from
+the recorded histogram. A quantizer clipping at the percentile instead of
+the absolute maximum ignores rare outliers.
+
+OPTIONS:
+  --percentile <p>   Percentile to report, 0 to 100 (default: 99.99)
+  --tensor <name>    Show only the named tensor
+  --json             Print the statistics, histograms included, as JSON`
+}
+
+// Examples implements Command.Examples.
+func (c *CalibrationCommand) Examples() []string {
+	return []string{
+		"calibration show calibration.json",
+		"calibration show calibration.json --percentile 99.9",
+		"calibration show calibration.json --tensor dense --json",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*CalibrationCommand)(nil)
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/model/calibration"
+)
+
+func writeCalibrationReport(t *testing.T) string {
+	t.Helper()
+	report := &calibration.Report{
+		Batches: 4,
+		Tensors: []*calibration.TensorStats{
+			{Name: "embed", OpType: "Gather", Shape: []int{1, 8}, Count: 32, Min: -1, Max: 2,
+				Histogram: &calibration.Histogram{Limit: 4, Bins: []int64{10, 20, 2, 0}}},
+			{Name: "dense", OpType: "Dense", Shape: []int{1, 4}, Count: 16, Min: -8, Max: 0.5,
+				Histogram: &calibration.Histogram{Limit: 8, Bins: []int64{15, 0, 0, 1}}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCalibrationCommand_Show(t *testing.T) {
+	path := writeCalibrationReport(t)
+	var out strings.Builder
+	cmd := NewCalibrationCommand(&out)
+	if err := cmd.Run(context.Background(), []string{"show", path, "--percentile", "50"}); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{"2 tensors over 4 batches", "|P50|", "embed", "Gather", "dense"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("show output missing %q:\n%s", want, got)
+		}
+	}
+
+	out.Reset()
+	if err := cmd.Run(context.Background(), []string{"show", "--tensor=dense", "--json", path}); err != nil {
+		t.Fatal(err)
+	}
+	var report calibration.Report
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("--json output is not a report: %v\n%s", err, out.String())
+	}
+	if len(report.Tensors) != 1 || report.Tensors[0].Name != "dense" {
+		t.Errorf("--tensor dense --json tensors = %+v", report.Tensors)
+	}
+}
+
+func TestCalibrationCommand_Errors(t *testing.T) {
+	cmd := NewCalibrationCommand(io.Discard)
+	path := writeCalibrationReport(t)
+	for _, args := range [][]string{
+		{},
+		{"bogus", path},
+		{"show"},
+		{"show", path, "--percentile", "101"},
+		{"show", path, "--percentile"},
+		{"show", path, "--tensor", "missing"},
+		{"show", path, "--bogus"},
+		{"show", path, path},
+		{"show", filepath.Join(t.TempDir(), "absent.json")},
+	} {
+		if err := cmd.Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) succeeded, want error", args)
+		}
+	}
+}
@@ -20,9 +20,16 @@
 //   - pull      — download and cache a model from a registry ([PullCommand])
 //   - list      — list locally cached models ([ListCommand])
 //   - rm        — remove a cached model ([RmCommand])
+//   - models    — garbage-collect the model version registry and promote
+//     versions behind an evaluation guard ([ModelsCommand])
 //   - worker    — start a distributed training worker ([WorkerCommand])
 //   - predict   — batch model inference on CSV/JSON data ([PredictCommand])
-//   - tokenize  — tokenize text with the Zerfoo tokenizer ([TokenizeCommand])
+//   - tokenize  — tokenize text, or decode token IDs, with a vocabulary or a
+//     HuggingFace tokenizer.json ([TokenizeCommand])
+//   - perplexity — evaluate model perplexity on a text dataset ([PerplexityCommand])
+//   - eval-lm   — score models on declarative benchmark tasks ([EvalLMCommand])
+//   - embed     — write pooled sentence embeddings for a dataset ([EmbedCommand])
+//   - tune      — LR range test that suggests a maximum learning rate ([TuneCommand])
 //
 // # Adding a new command
 //
@@ -56,5 +63,9 @@
 // [SignalContext] creates a context that cancels on SIGINT/SIGTERM and
 // optionally triggers a [shutdown.Coordinator] for graceful shutdown.
 // Long-running commands (serve, worker) use this to clean up on exit.
+// On a signal, train writes a final checkpoint and serve drains its
+// connections; both then return an [InterruptError], which [ExitCode] maps
+// to the conventional 128+signal status (143 for SIGTERM) so schedulers can
+// tell a preemption from a failure.
 // Stability: stable
 package cli
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDoctorCommand_Metadata(t *testing.T) {
+	cmd := NewDoctorCommand(nil)
+	if cmd.Name() != "doctor" {
+		t.Errorf("Name() = %q, want %q", cmd.Name(), "doctor")
+	}
+	if cmd.Description() == "" {
+		t.Error("Description() should not be empty")
+	}
+	if !strings.Contains(cmd.Usage(), "--determinism") {
+		t.Error("Usage() should document --determinism")
+	}
+	if len(cmd.Examples()) == 0 {
+		t.Error("Examples() should not be empty")
+	}
+}
+
+func TestDoctorCommand_ArgErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no check", nil},
+		{"unknown flag", []string{"--determinism", "--gpu"}},
+		{"bad steps", []string{"--determinism", "--steps", "0"}},
+		{"bad seed", []string{"--determinism", "--seed", "-1"}},
+		{"missing seed", []string{"--determinism", "--seed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewDoctorCommand(&bytes.Buffer{}).Run(context.Background(), tt.args); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDoctorCommand_Determinism(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewDoctorCommand(&out)
+	if err := cmd.Run(context.Background(), []string{"--determinism", "--steps=2"}); err != nil {
+		t.Fatalf("Run: %v\n%s", err, out.String())
+	}
+	got := out.String()
+	for _, want := range []string{"result: identical", "unseeded-rand", "core.randomData"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/inference/eval"
+)
+
+// EmbedCommand implements the "embed" CLI command, which computes pooled
+// sentence embeddings from a model's hidden states and writes them to disk
+// for downstream retrieval tasks.
+type EmbedCommand struct {
+	out io.Writer
+	// loadFn allows injection of a custom model loader for testing.
+	loadFn func(modelID string, opts ...inference.Option) (*inference.Model, error)
+}
+
+// NewEmbedCommand creates a new EmbedCommand.
+func NewEmbedCommand(out io.Writer) *EmbedCommand {
+	return &EmbedCommand{out: out, loadFn: inference.Load}
+}
+
+// Name implements Command.Name.
+func (c *EmbedCommand) Name() string { return "embed" }
+
+// Description implements Command.Description.
+func (c *EmbedCommand) Description() string {
+	return "Compute pooled sentence embeddings for a dataset"
+}
+
+// Run implements Command.Run.
+func (c *EmbedCommand) Run(ctx context.Context, args []string) error {
+	var modelID, dataPath, outPath, layer, cacheDir string
+	pooling := "mean"
+	normalize := true
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+		var err error
+		switch arg {
+		case "--data":
+			dataPath, err = nextVal("--data")
+		case "--output", "-o":
+			outPath, err = nextVal("--output")
+		case "--layer":
+			layer, err = nextVal("--layer")
+		case "--pooling":
+			pooling, err = nextVal("--pooling")
+		case "--no-normalize":
+			normalize = false
+		case "--cache-dir":
+			cacheDir, err = nextVal("--cache-dir")
+		default:
+			if modelID != "" {
+				return fmt.Errorf("unexpected argument: %s", args[i])
+			}
+			modelID = args[i]
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if modelID == "" {
+		return errors.New("model ID is required")
+	}
+	if dataPath == "" {
+		return errors.New("--data is required")
+	}
+	if outPath == "" {
+		return errors.New("--output is required")
+	}
+	strategy, err := inference.ParsePoolingStrategy(pooling)
+	if err != nil {
+		return fmt.Errorf("--pooling: %w", err)
+	}
+	write, err := embeddingWriterFor(outPath)
+	if err != nil {
+		return err
+	}
+
+	docs, err := eval.LoadDocuments(dataPath)
+	if err != nil {
+		return fmt.Errorf("load dataset: %w", err)
+	}
+	if len(docs) == 0 {
+		return errors.New("dataset is empty")
+	}
+
+	var loadOpts []inference.Option
+	if cacheDir != "" {
+		loadOpts = append(loadOpts, inference.WithCacheDir(cacheDir))
+	}
+	mdl, err := c.loadFn(modelID, loadOpts...)
+	if err != nil {
+		return fmt.Errorf("load model: %w", err)
+	}
+
+	texts := make([]string, len(docs))
+	for i, d := range docs {
+		texts[i] = d.Text
+	}
+	vecs, err := mdl.SentenceEmbeddings(ctx, texts,
+		inference.WithEmbedLayer(layer),
+		inference.WithPooling(strategy),
+		inference.WithNormalize(normalize),
+	)
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+
+	if err := write(outPath, docs, vecs); err != nil {
+		return fmt.Errorf("write %s: %w", outPath, err)
+	}
+	_, _ = fmt.Fprintf(c.out, "wrote %d embeddings of dimension %d to %s\n", len(vecs), len(vecs[0]), outPath)
+	return nil
+}
+
+type embeddingWriter func(path string, docs []eval.Document, vecs [][]float32) error
+
+// embeddingWriterFor selects the output format from the file extension.
+func embeddingWriterFor(path string) (embeddingWriter, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".npy":
+		return writeEmbeddingsNPY, nil
+	case ".jsonl":
+		return writeEmbeddingsJSONL, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (want .npy or .jsonl)", filepath.Ext(path))
+	}
+}
+
+// writeEmbeddingsNPY writes vecs as a little-endian float32 NumPy array of
+// shape (len(vecs), dim). Document IDs are written alongside, one per line,
+// to path with the extension replaced by ".ids.txt".
+func writeEmbeddingsNPY(path string, docs []eval.Document, vecs [][]float32) error {
+	dim := len(vecs[0])
+	header := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%d, %d), }", len(vecs), dim)
+	// The magic string, version, and header length take 10 bytes; pad the
+	// header with spaces so the data starts on a 64-byte boundary.
+	padded := 10 + len(header) + 1
+	header += strings.Repeat(" ", (64-padded%64)%64) + "\n"
+
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	_, _ = w.WriteString("\x93NUMPY\x01\x00")
+	_ = binary.Write(w, binary.LittleEndian, uint16(len(header)))
+	_, _ = w.WriteString(header)
+	var buf [4]byte
+	for i, v := range vecs {
+		if len(v) != dim {
+			_ = f.Close()
+			return fmt.Errorf("embedding %d has dimension %d, want %d", i, len(v), dim)
+		}
+		for _, x := range v {
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(x))
+			_, _ = w.Write(buf[:])
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	idsPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".ids.txt"
+	return os.WriteFile(filepath.Clean(idsPath), []byte(strings.Join(ids, "\n")+"\n"), 0o600)
+}
+
+// writeEmbeddingsJSONL writes one {"id", "embedding"} object per line.
+func writeEmbeddingsJSONL(path string, docs []eval.Document, vecs [][]float32) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i, v := range vecs {
+		rec := struct {
+			ID        string    `json:"id"`
+			Embedding []float32 `json:"embedding"`
+		}{docs[i].ID, v}
+		if err := enc.Encode(rec); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Usage implements Command.Usage.
+func (c *EmbedCommand) Usage() string {
+	return `embed <model-id> --data <path> --output <file> [OPTIONS]
+
+Run the model over each document and pool its hidden states into a single
+embedding vector.
+
+The dataset may be a .jsonl file with {"id", "text"} lines, a directory of
+.txt files, or a single text file. The output format is chosen by extension:
+.npy writes a float32 (N, dim) array plus a <name>.ids.txt file listing
+document IDs in row order; .jsonl writes {"id", "embedding"} lines.
+
+OPTIONS:
+  --data <path>        Dataset to embed (required)
+  --output, -o <file>  Output file, .npy or .jsonl (required)
+  --pooling <name>     mean, cls, or last (default: mean)
+  --layer <name>       Node to pool: <OpType> or <OpType>:<k>
+                       (default: final hidden states before the LM head)
+  --no-normalize       Skip L2 normalization of the pooled vectors
+  --cache-dir <dir>    Override default cache directory`
+}
+
+// Examples implements Command.Examples.
+func (c *EmbedCommand) Examples() []string {
+	return []string{
+		"embed ./model.gguf --data corpus.jsonl --output corpus.npy",
+		"embed ./bert.gguf --data docs/ --output docs.jsonl --pooling cls",
+		"embed ./model.gguf --data corpus.jsonl -o mid.npy --layer RMSNorm:12 --pooling last",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*EmbedCommand)(nil)
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/inference"
+)
+
+func newTestEmbedCommand(t *testing.T, out *bytes.Buffer) *EmbedCommand {
+	t.Helper()
+	cmd := NewEmbedCommand(out)
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return buildCLITestModel(t), nil
+	}
+	return cmd
+}
+
+func TestEmbedCommand_Metadata(t *testing.T) {
+	cmd := NewEmbedCommand(nil)
+	if cmd.Name() != "embed" {
+		t.Errorf("Name() = %q, want %q", cmd.Name(), "embed")
+	}
+	if cmd.Description() == "" {
+		t.Error("Description() should not be empty")
+	}
+	if !strings.Contains(cmd.Usage(), "--pooling") {
+		t.Error("Usage() should document --pooling")
+	}
+	if len(cmd.Examples()) == 0 {
+		t.Error("Examples() should not be empty")
+	}
+}
+
+func TestEmbedCommand_ArgErrors(t *testing.T) {
+	data := writePerplexityData(t)
+	out := filepath.Join(t.TempDir(), "out.npy")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing model", []string{"--data", data, "--output", out}},
+		{"missing data", []string{"m", "--output", out}},
+		{"missing output", []string{"m", "--data", data}},
+		{"bad pooling", []string{"m", "--data", data, "--output", out, "--pooling", "max"}},
+		{"bad format", []string{"m", "--data", data, "--output", "out.parquet"}},
+		{"bad layer", []string{"m", "--data", data, "--output", out, "--layer", "Missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := newTestEmbedCommand(t, &buf).Run(context.Background(), tt.args); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEmbedCommand_NPY(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "emb.npy")
+	var buf bytes.Buffer
+	if err := newTestEmbedCommand(t, &buf).Run(context.Background(),
+		[]string{"m", "--data", writePerplexityData(t), "--output", out, "--pooling", "last"}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("missing NPY magic: %q", raw[:10])
+	}
+	headerLen := int(binary.LittleEndian.Uint16(raw[8:10]))
+	if (10+headerLen)%64 != 0 {
+		t.Errorf("data offset %d is not 64-byte aligned", 10+headerLen)
+	}
+	header := string(raw[10 : 10+headerLen])
+	// The CLI test model's final node emits vocab-sized (8) rows.
+	if !strings.Contains(header, "'shape': (2, 8)") || !strings.Contains(header, "'<f4'") {
+		t.Errorf("header = %q", header)
+	}
+	payload := raw[10+headerLen:]
+	if len(payload) != 2*8*4 {
+		t.Fatalf("payload = %d bytes, want %d", len(payload), 2*8*4)
+	}
+	var sumSq float64
+	for i := 0; i < 8; i++ {
+		v := math.Float32frombits(binary.LittleEndian.Uint32(payload[4*i:]))
+		sumSq += float64(v) * float64(v)
+	}
+	if math.Abs(sumSq-1) > 1e-5 {
+		t.Errorf("first row squared norm = %v, want 1", sumSq)
+	}
+
+	ids, err := os.ReadFile(filepath.Join(dir, "emb.ids.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ids) != "a\nb\n" {
+		t.Errorf("ids = %q, want %q", ids, "a\nb\n")
+	}
+}
+
+func TestEmbedCommand_JSONL(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "emb.jsonl")
+	var buf bytes.Buffer
+	if err := newTestEmbedCommand(t, &buf).Run(context.Background(),
+		[]string{"m", "--data", writePerplexityData(t), "-o", out, "--no-normalize"}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var got []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec struct {
+			ID        string    `json:"id"`
+			Embedding []float32 `json:"embedding"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("bad line %q: %v", sc.Text(), err)
+		}
+		if len(rec.Embedding) != 8 {
+			t.Errorf("%s: embedding dim = %d, want 8", rec.ID, len(rec.Embedding))
+		}
+		got = append(got, rec.ID)
+	}
+	if strings.Join(got, ",") != "a,b" {
+		t.Errorf("ids = %v, want [a b]", got)
+	}
+	if !strings.Contains(buf.String(), "wrote 2 embeddings") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/inference/eval"
+)
+
+// EvalLMCommand implements the "eval-lm" CLI command, which scores one or
+// more models (for example successive training checkpoints) on declarative
+// evaluation tasks.
+type EvalLMCommand struct {
+	out io.Writer
+	// loadFn allows injection of a custom model loader for testing.
+	loadFn func(modelID string, opts ...inference.Option) (*inference.Model, error)
+}
+
+// NewEvalLMCommand creates a new EvalLMCommand.
+func NewEvalLMCommand(out io.Writer) *EvalLMCommand {
+	return &EvalLMCommand{out: out, loadFn: inference.Load}
+}
+
+// Name implements Command.Name.
+func (c *EvalLMCommand) Name() string { return "eval-lm" }
+
+// Description implements Command.Description.
+func (c *EvalLMCommand) Description() string {
+	return "Evaluate language models on benchmark tasks"
+}
+
+// evalLMModelResult is the JSON output for one evaluated model.
+type evalLMModelResult struct {
+	Model   string             `json:"model"`
+	Results []*eval.TaskResult `json:"results"`
+}
+
+// Run implements Command.Run.
+func (c *EvalLMCommand) Run(ctx context.Context, args []string) error {
+	var modelIDs, taskPaths []string
+	var cacheDir string
+	var limit, maxContext int
+	var useBOS, jsonOut bool
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+		switch arg {
+		case "--tasks":
+			s, err := nextVal("--tasks")
+			if err != nil {
+				return err
+			}
+			for _, p := range strings.Split(s, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					taskPaths = append(taskPaths, p)
+				}
+			}
+		case "--limit":
+			s, err := nextVal("--limit")
+			if err != nil {
+				return err
+			}
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("--limit: %w", err)
+			}
+			limit = v
+		case "--max-context":
+			s, err := nextVal("--max-context")
+			if err != nil {
+				return err
+			}
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("--max-context: %w", err)
+			}
+			maxContext = v
+		case "--cache-dir":
+			s, err := nextVal("--cache-dir")
+			if err != nil {
+				return err
+			}
+			cacheDir = s
+		case "--bos":
+			useBOS = true
+		case "--json":
+			jsonOut = true
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return fmt.Errorf("unknown flag: %s", args[i])
+			}
+			modelIDs = append(modelIDs, args[i])
+		}
+	}
+
+	if len(modelIDs) == 0 {
+		return errors.New("at least one model ID is required")
+	}
+	if len(taskPaths) == 0 {
+		return errors.New("--tasks is required")
+	}
+
+	tasks, err := loadEvalTasks(taskPaths)
+	if err != nil {
+		return err
+	}
+	opts := evalLMOptions{limit: limit, maxContext: maxContext, useBOS: useBOS, cacheDir: cacheDir}
+	all := make([]evalLMModelResult, 0, len(modelIDs))
+	for _, id := range modelIDs {
+		results, err := evalModel(ctx, c.loadFn, id, tasks, opts)
+		if err != nil {
+			return err
+		}
+		all = append(all, evalLMModelResult{Model: id, Results: results})
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(all); err != nil {
+			return fmt.Errorf("encode result: %w", err)
+		}
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%-30s %-24s %-12s %10s\n", "MODEL", "TASK", "METRIC", "VALUE")
+	for _, mr := range all {
+		for _, res := range mr.Results {
+			metrics := make([]string, 0, len(res.Metrics))
+			for k := range res.Metrics {
+				metrics = append(metrics, k)
+			}
+			sort.Strings(metrics)
+			for _, k := range metrics {
+				_, _ = fmt.Fprintf(c.out, "%-30s %-24s %-12s %10.4f\n", mr.Model, res.Task, k, res.Metrics[k])
+			}
+		}
+	}
+	return nil
+}
+
+// evalLMOptions are the scoring options shared by eval-lm and models
+// promote.
+type evalLMOptions struct {
+	limit, maxContext int
+	useBOS            bool
+	cacheDir          string
+}
+
+// loadEvalTasks reads the task files at paths.
+func loadEvalTasks(paths []string) ([]*eval.Task, error) {
+	tasks := make([]*eval.Task, 0, len(paths))
+	for _, p := range paths {
+		t, err := eval.LoadTask(p)
+		if err != nil {
+			return nil, fmt.Errorf("load task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// evalModel loads modelID with loadFn and runs every task on it.
+func evalModel(ctx context.Context, loadFn func(string, ...inference.Option) (*inference.Model, error), modelID string, tasks []*eval.Task, o evalLMOptions) ([]*eval.TaskResult, error) {
+	var loadOpts []inference.Option
+	if o.cacheDir != "" {
+		loadOpts = append(loadOpts, inference.WithCacheDir(o.cacheDir))
+	}
+	mdl, err := loadFn(modelID, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("load model %s: %w", modelID, err)
+	}
+	scorer := eval.NewGeneratorScorer(mdl.Generator())
+	opts := []eval.TaskOption{eval.WithCompleter(scorer), eval.WithLimit(o.limit)}
+	if o.maxContext > 0 {
+		opts = append(opts, eval.WithMaxContext(o.maxContext))
+	}
+	if o.useBOS {
+		opts = append(opts, eval.WithTaskBOS(mdl.Config().BOSTokenID))
+	}
+	te, err := eval.NewTaskEvaluator(scorer, mdl.Tokenizer(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*eval.TaskResult, 0, len(tasks))
+	for _, t := range tasks {
+		res, err := te.Run(ctx, t)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: %w", modelID, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Usage implements Command.Usage.
+func (c *EvalLMCommand) Usage() string {
+	return `eval-lm <model-id>... --tasks <file,...> [OPTIONS]
+
+Score one or more models on declarative evaluation tasks. Passing several
+models (e.g. training checkpoints) evaluates each on the same tasks.
+
+A task file is JSON with "name", "type" and "examples". Built-in types:
+  multiple_choice  examples have "prompt", "choices" and "answer" (index)
+  cloze            examples have "prompt" and "target"
+  exact_match      examples have "prompt", "target" and optional "aliases"
+
+OPTIONS:
+  --tasks <files>      Comma-separated task files (required)
+  --limit <n>          Evaluate at most n examples per task
+  --max-context <n>    Maximum tokens per scored sequence (default: 2048)
+  --bos                Prepend the BOS token to scored sequences
+  --json               Print results as JSON
+  --cache-dir <dir>    Override default cache directory`
+}
+
+// Examples implements Command.Examples.
+func (c *EvalLMCommand) Examples() []string {
+	return []string{
+		"eval-lm google/gemma-3-1b --tasks tasks/arc.json,tasks/capitals.json",
+		"eval-lm ckpt-1000.gguf ckpt-2000.gguf --tasks tasks/arc.json --json",
+		"eval-lm ./model.gguf --tasks tasks/qa.json --limit 100",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*EvalLMCommand)(nil)
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/inference"
+)
+
+func writeEvalLMTasks(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	tasks := map[string]string{
+		"mc.json": `{"name":"mc","type":"multiple_choice","examples":[
+			{"prompt":"hello","choices":["foo","bar"],"answer":0},
+			{"prompt":"hello","choices":["world","foo"],"answer":1}]}`,
+		"qa.json": `{"name":"qa","type":"exact_match","max_new_tokens":2,"examples":[
+			{"prompt":"hello","target":"foo bar"}]}`,
+	}
+	for name, data := range tasks {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "mc.json") + "," + filepath.Join(dir, "qa.json")
+}
+
+func newTestEvalLMCommand(t *testing.T, out *bytes.Buffer) *EvalLMCommand {
+	t.Helper()
+	cmd := NewEvalLMCommand(out)
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return buildCLITestModel(t), nil
+	}
+	return cmd
+}
+
+func TestEvalLMCommand_Metadata(t *testing.T) {
+	cmd := NewEvalLMCommand(nil)
+	if cmd.Name() != "eval-lm" {
+		t.Errorf("Name() = %q, want %q", cmd.Name(), "eval-lm")
+	}
+	if cmd.Description() == "" {
+		t.Error("Description() should not be empty")
+	}
+	if !strings.Contains(cmd.Usage(), "--tasks") {
+		t.Error("Usage() should document --tasks")
+	}
+	if len(cmd.Examples()) == 0 {
+		t.Error("Examples() should not be empty")
+	}
+}
+
+func TestEvalLMCommand_ArgErrors(t *testing.T) {
+	tasks := writeEvalLMTasks(t)
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing model", []string{"--tasks", tasks}},
+		{"missing tasks", []string{"m"}},
+		{"bad limit", []string{"m", "--tasks", tasks, "--limit", "x"}},
+		{"unknown flag", []string{"m", "--tasks", tasks, "--nope"}},
+		{"missing task file", []string{"m", "--tasks", filepath.Join(t.TempDir(), "none.json")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := newTestEvalLMCommand(t, &out).Run(context.Background(), tt.args); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEvalLMCommand_LoadError(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewEvalLMCommand(&out)
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return nil, errors.New("load failed")
+	}
+	err := cmd.Run(context.Background(), []string{"m", "--tasks", writeEvalLMTasks(t)})
+	if err == nil || !strings.Contains(err.Error(), "load model") {
+		t.Errorf("err = %v, want load model error", err)
+	}
+}
+
+func TestEvalLMCommand_Table(t *testing.T) {
+	var out bytes.Buffer
+	if err := newTestEvalLMCommand(t, &out).Run(context.Background(), []string{"m", "--tasks", writeEvalLMTasks(t)}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	for _, want := range []string{"MODEL", "mc", "acc_norm", "qa", "exact_match"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestEvalLMCommand_JSONMultipleModels(t *testing.T) {
+	var out bytes.Buffer
+	err := newTestEvalLMCommand(t, &out).Run(context.Background(),
+		[]string{"ckpt-1", "ckpt-2", "--tasks=" + writeEvalLMTasks(t), "--json", "--limit", "1"})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	var res []evalLMModelResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(res) != 2 || res[0].Model != "ckpt-1" || res[1].Model != "ckpt-2" {
+		t.Fatalf("models = %+v", res)
+	}
+	for _, mr := range res {
+		if len(mr.Results) != 2 || mr.Results[0].Examples != 1 {
+			t.Errorf("results for %s = %+v", mr.Model, mr.Results)
+		}
+	}
+}
@@ -14,7 +14,10 @@ import (
 	"strings"
 	"time"
 
+	datacache "github.com/zerfoo/zerfoo/data/cache"
+	"github.com/zerfoo/zerfoo/internal/tracing"
 	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/zerfoo/model/postprocess"
 	"github.com/zerfoo/ztensor/tensor"
 	tokenizer "github.com/zerfoo/ztoken"
 )
@@ -91,6 +94,7 @@ type PredictCommand[T tensor.Numeric] struct {
 	fromFloat64   func(float64) T
 	toFloat64     func(T) float64
 	defaultConfig *PredictCommandConfig
+	dataCache     *datacache.Store
 }
 
 // PredictCommandConfig configures model prediction.
@@ -108,6 +112,10 @@ type PredictCommandConfig struct {
 	FeatureColumns []string `json:"featureColumns"`
 	IDColumn       string   `json:"idColumn"`
 	GroupColumn    string   `json:"groupColumn"`
+	// DataCacheDir, when set, selects the dataset cache instead of the one
+	// given to SetDataCache. NoDataCache disables caching.
+	DataCacheDir string `json:"dataCacheDir"`
+	NoDataCache  bool   `json:"noDataCache"`
 
 	// Prediction configuration
 	BatchSize    int  `json:"batchSize"`
@@ -207,7 +215,7 @@ Perform model inference using configurable providers.
 
 OPTIONS:
   --model-path <path>       Path to model file (required)
-  --data-path <path>        Path to input data (required)
+  --data-path <path>        Path or http(s) URL of input data (required)
   --output <path>           Output path for predictions (required)
   --model-provider <name>   Model provider name (default: standard)
   --data-provider <name>    Data provider name (default: csv)
@@ -216,6 +224,8 @@ OPTIONS:
   --include-probs           Include prediction probabilities
   --id-col <name>           ID column name (default: id)
   --group-col <name>        Optional grouping column name
+  --data-cache-dir <dir>    Dataset cache directory (default: ~/.zerfoo/datasets)
+  --no-data-cache           Download and parse the data without the cache
   --verbose                 Verbose output
   --overwrite              Overwrite existing output
   --config <path>          Load configuration from file`
@@ -227,6 +237,7 @@ func (c *PredictCommand[T]) Examples() []string {
 		"predict --model-path model.gguf --data-path data.csv --output predictions.csv",
 		"predict --model-path model.gguf --data-path data.csv --output pred.json --format json --include-probs",
 		"predict --config predict_config.json --verbose",
+		"predict --model-path model.gguf --data-path https://example.com/live.csv --output predictions.csv",
 	}
 }
 
@@ -274,6 +285,26 @@ func (c *PredictCommand[T]) parseArgs(args []string) (*PredictCommandConfig, err
 				return nil, err
 			}
 			config.Output = v
+		case "--id-col":
+			v, err := nextVal("--id-col")
+			if err != nil {
+				return nil, err
+			}
+			config.IDColumn = v
+		case "--group-col":
+			v, err := nextVal("--group-col")
+			if err != nil {
+				return nil, err
+			}
+			config.GroupColumn = v
+		case "--data-cache-dir":
+			v, err := nextVal("--data-cache-dir")
+			if err != nil {
+				return nil, err
+			}
+			config.DataCacheDir = v
+		case "--no-data-cache":
+			config.NoDataCache = true
 		case "--verbose":
 			config.Verbose = true
 		case "--overwrite":
@@ -328,7 +359,7 @@ func (c *PredictCommand[T]) runPrediction(ctx context.Context, config *PredictCo
 	}
 
 	// Read CSV data
-	ids, features, numFeatures, err := c.readCSVData(config)
+	ids, groups, features, numFeatures, err := c.loadData(ctx, config)
 	if err != nil {
 		return result, fmt.Errorf("failed to read data: %w", err)
 	}
@@ -358,6 +389,19 @@ func (c *PredictCommand[T]) runPrediction(ctx context.Context, config *PredictCo
 		predictions[i] = c.toFloat64(v)
 	}
 
+	// Apply the model's post-processing chain, using the group column as
+	// the era of each row when one is configured.
+	chain, ok, err := postprocess.FromMetadata(modelInstance.GetMetadata())
+	if err != nil {
+		return result, err
+	}
+	if ok {
+		predictions, err = chain.Apply(predictions, groups)
+		if err != nil {
+			return result, fmt.Errorf("post-processing failed: %w", err)
+		}
+	}
+
 	result.Predictions = predictions
 	result.IDs = ids
 	result.Duration = time.Since(startTime)
@@ -366,23 +410,24 @@ func (c *PredictCommand[T]) runPrediction(ctx context.Context, config *PredictCo
 	return result, nil
 }
 
-// readCSVData reads a CSV file and returns sample IDs, flattened features, and
-// the number of feature columns.
-func (c *PredictCommand[T]) readCSVData(config *PredictCommandConfig) (ids []string, features []float64, numFeatures int, err error) {
+// readCSVData reads a CSV file and returns sample IDs, group values (nil
+// unless config.GroupColumn is present), flattened features, and the number
+// of feature columns.
+func (c *PredictCommand[T]) readCSVData(config *PredictCommandConfig) (ids, groups []string, features []float64, numFeatures int, err error) {
 	file, err := os.Open(config.DataPath)
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, nil, nil, 0, err
 	}
 	defer file.Close() //nolint:errcheck
 
 	reader := csv.NewReader(file)
 	header, err := reader.Read()
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("failed to read CSV header: %w", err)
+		return nil, nil, nil, 0, fmt.Errorf("failed to read CSV header: %w", err)
 	}
 
-	// Determine which columns are the ID and which are features
-	idIdx := -1
+	// Determine which columns are the ID, the group and which are features
+	idIdx, groupIdx := -1, -1
 	featureIdxs := make([]int, 0)
 	for i, col := range header {
 		col = strings.TrimSpace(col)
@@ -390,6 +435,10 @@ func (c *PredictCommand[T]) readCSVData(config *PredictCommandConfig) (ids []str
 			idIdx = i
 			continue
 		}
+		if config.GroupColumn != "" && col == config.GroupColumn {
+			groupIdx = i
+			continue
+		}
 		if len(config.FeatureColumns) > 0 {
 			for _, fc := range config.FeatureColumns {
 				if col == fc {
@@ -405,7 +454,7 @@ func (c *PredictCommand[T]) readCSVData(config *PredictCommandConfig) (ids []str
 
 	numFeatures = len(featureIdxs)
 	if numFeatures == 0 {
-		return nil, nil, 0, fmt.Errorf("no feature columns found in CSV")
+		return nil, nil, nil, 0, fmt.Errorf("no feature columns found in CSV")
 	}
 
 	// Read rows
@@ -424,6 +473,14 @@ func (c *PredictCommand[T]) readCSVData(config *PredictCommandConfig) (ids []str
 		}
 		ids = append(ids, sampleID)
 
+		if groupIdx >= 0 {
+			group := ""
+			if groupIdx < len(record) {
+				group = record[groupIdx]
+			}
+			groups = append(groups, group)
+		}
+
 		// Extract features
 		for _, fi := range featureIdxs {
 			if fi < len(record) {
@@ -438,7 +495,7 @@ func (c *PredictCommand[T]) readCSVData(config *PredictCommandConfig) (ids []str
 		}
 	}
 
-	return ids, features, numFeatures, nil
+	return ids, groups, features, numFeatures, nil
 }
 
 func (c *PredictCommand[T]) saveResults(config *PredictCommandConfig, result *PredictionResult) error {
@@ -532,12 +589,14 @@ type PredictionResult struct {
 // TokenizeCommand implements text tokenization.
 type TokenizeCommand struct {
 	tok *tokenizer.WhitespaceTokenizer
+	out io.Writer
 }
 
 // NewTokenizeCommand creates a new tokenize command.
 func NewTokenizeCommand() *TokenizeCommand {
 	return &TokenizeCommand{
 		tok: tokenizer.NewWhitespaceTokenizer(),
+		out: os.Stdout,
 	}
 }
 
@@ -553,7 +612,8 @@ func (c *TokenizeCommand) Description() string {
 
 // Run implements Command.Run
 func (c *TokenizeCommand) Run(_ context.Context, args []string) error {
-	var text, vocabPath string
+	var text, vocabPath, tokenizerPath, decodeIDs string
+	var addSpecial bool
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
@@ -587,25 +647,84 @@ func (c *TokenizeCommand) Run(_ context.Context, args []string) error {
 				return err
 			}
 			vocabPath = v
+		case "--tokenizer-file":
+			v, err := nextVal("--tokenizer-file")
+			if err != nil {
+				return err
+			}
+			tokenizerPath = v
+		case "--decode":
+			v, err := nextVal("--decode")
+			if err != nil {
+				return err
+			}
+			decodeIDs = v
+		case "--add-special-tokens":
+			addSpecial = true
 		}
 	}
 
-	if text == "" {
+	if text == "" && decodeIDs == "" {
 		return fmt.Errorf("please provide text to tokenize using the --text flag")
 	}
+	if vocabPath != "" && tokenizerPath != "" {
+		return fmt.Errorf("--vocab and --tokenizer-file are mutually exclusive")
+	}
 
-	// Load vocabulary from file if provided
-	if vocabPath != "" {
+	var tok tokenizer.Tokenizer = c.tok
+	switch {
+	case tokenizerPath != "":
+		loaded, err := tokenizer.Load(tokenizerPath)
+		if err != nil {
+			return fmt.Errorf("failed to load tokenizer: %w", err)
+		}
+		tok = loaded
+	case vocabPath != "":
+		// Load vocabulary from file if provided
 		if err := c.loadVocab(vocabPath); err != nil {
 			return fmt.Errorf("failed to load vocabulary: %w", err)
 		}
 	}
 
-	tokenIDs, err := c.tok.Encode(text)
+	if decodeIDs != "" {
+		var ids []int
+		for _, f := range strings.Split(decodeIDs, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return fmt.Errorf("--decode: invalid token ID %q", f)
+			}
+			ids = append(ids, id)
+		}
+		decoded, err := tok.Decode(ids)
+		if err != nil {
+			return fmt.Errorf("decoding failed: %w", err)
+		}
+		_, _ = fmt.Fprintf(c.out, "Text for %v: '%s'\n", ids, decoded)
+		return nil
+	}
+
+	var tokenIDs []int
+	var err error
+	if addSpecial {
+		bpe, ok := tok.(*tokenizer.BPETokenizer)
+		if !ok {
+			return fmt.Errorf("--add-special-tokens requires a BPE --tokenizer-file")
+		}
+		tokenIDs, err = bpe.EncodeWithSpecialTokens(text, true, true)
+	} else {
+		tokenIDs, err = tok.Encode(text)
+	}
 	if err != nil {
 		return fmt.Errorf("tokenization failed: %w", err)
 	}
-	fmt.Printf("Token IDs for '%s': %v\n", text, tokenIDs)
+	_, _ = fmt.Fprintf(c.out, "Token IDs for '%s': %v\n", text, tokenIDs)
+	if tokenizerPath != "" {
+		tokens := make([]string, len(tokenIDs))
+		for i, id := range tokenIDs {
+			tokens[i], _ = tok.GetToken(id)
+		}
+		_, _ = fmt.Fprintf(c.out, "Tokens: %q\n", tokens)
+	}
 	return nil
 }
 
@@ -639,8 +758,11 @@ func (c *TokenizeCommand) Usage() string {
 Tokenize text using the Zerfoo tokenizer.
 
 OPTIONS:
-  --text <string>    Text to tokenize (required)
-  --vocab <path>     Path to vocabulary file (one token per line)`
+  --text <string>           Text to tokenize (required unless --decode)
+  --vocab <path>            Path to vocabulary file (one token per line)
+  --tokenizer-file <path>   HuggingFace tokenizer.json (BPE or WordPiece)
+  --add-special-tokens      Add BOS and EOS tokens (BPE tokenizers)
+  --decode <ids>            Decode comma-separated token IDs instead`
 }
 
 // Examples implements Command.Examples
@@ -648,6 +770,8 @@ func (c *TokenizeCommand) Examples() []string {
 	return []string{
 		`tokenize --text "Hello world"`,
 		`tokenize --text "The quick brown fox jumps over the lazy dog"`,
+		`tokenize --tokenizer-file tokenizer.json --text "Hello world" --add-special-tokens`,
+		`tokenize --tokenizer-file tokenizer.json --decode 9906,1917`,
 	}
 }
 
@@ -702,7 +826,10 @@ func (c *CLI) Run(ctx context.Context, args []string) error {
 		}
 	}
 
-	return cmd.Run(ctx, args[1:])
+	ctx, span := tracing.Start(ctx, "cli", "zerfoo "+cmdName)
+	err := cmd.Run(ctx, args[1:])
+	tracing.End(span, err)
+	return err
 }
 
 func (c *CLI) printUsage() error {
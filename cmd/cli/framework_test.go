@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/zerfoo/model/postprocess"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
 )
@@ -402,7 +404,7 @@ func TestReadCSVData_Basic(t *testing.T) {
 	config.DataPath = csvFile
 	config.IDColumn = "id"
 
-	ids, features, numFeatures, err := cmd.readCSVData(config)
+	ids, _, features, numFeatures, err := cmd.readCSVData(config)
 	if err != nil {
 		t.Fatalf("readCSVData failed: %v", err)
 	}
@@ -437,7 +439,7 @@ func TestReadCSVData_ExplicitFeatureColumns(t *testing.T) {
 	config.IDColumn = "id"
 	config.FeatureColumns = []string{"f1", "f3"}
 
-	ids, features, numFeatures, err := cmd.readCSVData(config)
+	ids, _, features, numFeatures, err := cmd.readCSVData(config)
 	if err != nil {
 		t.Fatalf("readCSVData failed: %v", err)
 	}
@@ -466,7 +468,7 @@ func TestReadCSVData_NoIDColumn(t *testing.T) {
 	config.DataPath = csvFile
 	config.IDColumn = "id" // Not in CSV
 
-	ids, _, _, err := cmd.readCSVData(config)
+	ids, _, _, _, err := cmd.readCSVData(config)
 	if err != nil {
 		t.Fatalf("readCSVData failed: %v", err)
 	}
@@ -489,7 +491,7 @@ func TestReadCSVData_NoFeatureColumns(t *testing.T) {
 	config.DataPath = csvFile
 	config.IDColumn = "id"
 
-	_, _, _, err := cmd.readCSVData(config)
+	_, _, _, _, err := cmd.readCSVData(config)
 	if err == nil {
 		t.Error("expected error for no feature columns")
 	}
@@ -500,7 +502,7 @@ func TestReadCSVData_FileNotFound(t *testing.T) {
 	config := &PredictCommandConfig{}
 	config.DataPath = "/nonexistent/data.csv"
 
-	_, _, _, err := cmd.readCSVData(config)
+	_, _, _, _, err := cmd.readCSVData(config)
 	if err == nil {
 		t.Error("expected error for nonexistent file")
 	}
@@ -519,7 +521,7 @@ func TestReadCSVData_InvalidValues(t *testing.T) {
 	config.DataPath = csvFile
 	config.IDColumn = "id"
 
-	_, features, _, err := cmd.readCSVData(config)
+	_, _, features, _, err := cmd.readCSVData(config)
 	if err != nil {
 		t.Fatalf("readCSVData should not fail on bad values: %v", err)
 	}
@@ -765,6 +767,38 @@ func TestRunPrediction_ReadDataError(t *testing.T) {
 	}
 }
 
+func TestRunPrediction_PostProcessPerGroup(t *testing.T) {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "data.csv")
+	content := "id,era,f1\na,1,1.0\nb,1,2.0\nc,2,3.0\nd,2,4.0\n"
+	if err := os.WriteFile(csvFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	outputTensor, _ := tensor.New[float32]([]int{4, 1}, []float32{0.9, 0.1, 0.2, 0.8})
+	meta := model.ModelMetadata{Name: "test"}
+	postprocess.SetMetadata(&meta, postprocess.Chain{{Op: postprocess.OpRank}})
+	mock := &mockModelInstance{output: outputTensor, metadata: meta}
+
+	cmd := NewPredictCommand(model.Float32ModelRegistry, float32From, float32To)
+	config := &PredictCommandConfig{IDColumn: "id", GroupColumn: "era"}
+	config.DataPath = csvFile
+
+	result, err := cmd.runPrediction(context.Background(), config, mock)
+	if err != nil {
+		t.Fatalf("runPrediction failed: %v", err)
+	}
+	if result.NumFeatures != 1 {
+		t.Errorf("NumFeatures = %d, want 1 (group column excluded)", result.NumFeatures)
+	}
+	want := []float64{0.75, 0.25, 0.25, 0.75}
+	for i, w := range want {
+		if math.Abs(result.Predictions[i]-w) > 1e-9 {
+			t.Errorf("Predictions[%d] = %v, want %v", i, result.Predictions[i], w)
+		}
+	}
+}
+
 // --- CLI.Run tests ---
 
 func TestCLI_Run_NoArgs(t *testing.T) {
@@ -1019,7 +1053,7 @@ func TestReadCSVData_EmptyFile(t *testing.T) {
 	config.DataPath = csvFile
 	config.IDColumn = "id"
 
-	_, _, _, err := cmd.readCSVData(config)
+	_, _, _, _, err := cmd.readCSVData(config)
 	if err == nil {
 		t.Error("expected error for empty CSV file (no header)")
 	}
@@ -1242,3 +1276,57 @@ func TestTokenizeCommand_EqualsSyntaxWithVocab(t *testing.T) {
 		t.Fatalf("tokenize with --vocab=path --text=value failed: %v", err)
 	}
 }
+
+func TestTokenizeCommand_TokenizerFile(t *testing.T) {
+	// Byte-level BPE: "Ġ" is the byte-level form of a space.
+	tokJSON := `{
+		"model": {
+			"type": "BPE",
+			"vocab": {"h": 0, "i": 1, "Ġ": 2, "hi": 3, "Ġhi": 4, "<s>": 5, "</s>": 6},
+			"merges": ["h i", "Ġ hi"]
+		},
+		"pre_tokenizer": {"type": "ByteLevel"},
+		"added_tokens": [
+			{"id": 5, "content": "<s>", "special": true},
+			{"id": 6, "content": "</s>", "special": true}
+		]
+	}`
+	path := filepath.Join(t.TempDir(), "tokenizer.json")
+	if err := os.WriteFile(path, []byte(tokJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) (string, error) {
+		var buf bytes.Buffer
+		cmd := NewTokenizeCommand()
+		cmd.out = &buf
+		err := cmd.Run(context.Background(), args)
+		return buf.String(), err
+	}
+
+	out, err := run("--tokenizer-file", path, "--text", "hi hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Token IDs for 'hi hi': [3 4]") || !strings.Contains(out, `Tokens: ["hi" "Ġhi"]`) {
+		t.Errorf("encode output = %q", out)
+	}
+	out, err = run("--tokenizer-file", path, "--text", "hi", "--add-special-tokens")
+	if err != nil || !strings.Contains(out, "[5 3 6]") {
+		t.Errorf("encode with special tokens = %q, %v; want [5 3 6]", out, err)
+	}
+	out, err = run("--tokenizer-file", path, "--decode", "3, 4")
+	if err != nil || !strings.Contains(out, "'hi hi'") {
+		t.Errorf("decode = %q, %v; want 'hi hi'", out, err)
+	}
+
+	for _, args := range [][]string{
+		{"--tokenizer-file", path, "--vocab", path, "--text", "hi"},
+		{"--tokenizer-file", filepath.Join(t.TempDir(), "missing.json"), "--text", "hi"},
+		{"--tokenizer-file", path, "--decode", "3,x"},
+		{"--text", "hi", "--add-special-tokens"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("Run(%q) succeeded, want error", args)
+		}
+	}
+}
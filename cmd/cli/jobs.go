@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// JobsCommand implements the "jobs" CLI command, which submits, lists, and
+// cancels training jobs on a coordinator.
+type JobsCommand struct {
+	out io.Writer
+
+	// dial connects to the coordinator. Defaults to dialCoordinator;
+	// overridable in tests.
+	dial func(addr string, tls *distributed.TLSConfig) (pb.CoordinatorClient, io.Closer, error)
+}
+
+// NewJobsCommand creates a new JobsCommand.
+func NewJobsCommand(out io.Writer) *JobsCommand {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &JobsCommand{out: out, dial: dialCoordinator}
+}
+
+// Name implements Command.Name.
+func (c *JobsCommand) Name() string { return "jobs" }
+
+// Description implements Command.Description.
+func (c *JobsCommand) Description() string {
+	return "Submit, list, and cancel training jobs on a coordinator"
+}
+
+// jobsConfig holds parsed jobs flags.
+type jobsConfig struct {
+	coordAddr string
+	namespace string
+	jobID     string
+	worldSize int
+	resources *pb.ResourceHints
+	tls       *distributed.TLSConfig
+}
+
+// Run implements Command.Run.
+func (c *JobsCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("jobs: subcommand required (list, status, submit, cancel)")
+	}
+	sub := args[0]
+	cfg, err := parseJobsArgs(args[1:])
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+	case "status", "submit", "cancel":
+		if cfg.jobID == "" {
+			return fmt.Errorf("jobs %s: job ID required", sub)
+		}
+		if sub == "submit" && cfg.worldSize == 0 {
+			return errors.New("jobs submit: --world-size is required")
+		}
+	default:
+		return fmt.Errorf("jobs: unknown subcommand %q (want list, status, submit, or cancel)", sub)
+	}
+	if cfg.coordAddr == "" {
+		return errors.New("--coordinator-address is required")
+	}
+
+	client, conn, err := c.dial(cfg.coordAddr, cfg.tls)
+	if err != nil {
+		return fmt.Errorf("failed to connect to coordinator: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var jobs []*pb.JobStatus
+	switch sub {
+	case "list":
+		resp, err := client.ListJobs(ctx, &pb.ListJobsRequest{Namespace: cfg.namespace})
+		if err != nil {
+			return err
+		}
+		if len(resp.Jobs) == 0 {
+			_, _ = fmt.Fprintln(c.out, "No jobs.")
+			return nil
+		}
+		jobs = resp.Jobs
+	case "status":
+		resp, err := client.GetJob(ctx, &pb.GetJobRequest{Namespace: cfg.namespace, JobId: cfg.jobID})
+		if err != nil {
+			return err
+		}
+		jobs = []*pb.JobStatus{resp.Job}
+	case "submit":
+		resp, err := client.SubmitJob(ctx, &pb.SubmitJobRequest{
+			Namespace: cfg.namespace,
+			JobId:     cfg.jobID,
+			WorldSize: int32(cfg.worldSize), // #nosec G115 - world sizes are small
+			Resources: cfg.resources,
+		})
+		if err != nil {
+			return err
+		}
+		jobs = []*pb.JobStatus{resp.Job}
+	case "cancel":
+		resp, err := client.CancelJob(ctx, &pb.CancelJobRequest{Namespace: cfg.namespace, JobId: cfg.jobID})
+		if err != nil {
+			return err
+		}
+		jobs = []*pb.JobStatus{resp.Job}
+	}
+	return c.printJobs(jobs)
+}
+
+func (c *JobsCommand) printJobs(jobs []*pb.JobStatus) error {
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tJOB\tSTATE\tWORKERS\tRESOURCES\tSUBMITTED")
+	for _, j := range jobs {
+		workers := fmt.Sprintf("%d", j.Workers)
+		if j.WorldSize > 0 {
+			workers = fmt.Sprintf("%d/%d", j.Workers, j.WorldSize)
+		}
+		submitted := "-"
+		if j.SubmittedUnixNano != 0 {
+			submitted = time.Unix(0, j.SubmittedUnixNano).Local().Format(time.DateTime)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			j.Namespace, j.JobId, jobStateName(j.State), workers, formatResources(j.Resources), submitted)
+	}
+	return tw.Flush()
+}
+
+// jobStateName returns s without its JOB_STATE_ prefix, in lower case.
+func jobStateName(s pb.JobState) string {
+	return strings.ToLower(strings.TrimPrefix(s.String(), "JOB_STATE_"))
+}
+
+// formatResources renders per-worker resource hints compactly, e.g.
+// "gpus=8 mem=64.0GB zone=a".
+func formatResources(r *pb.ResourceHints) string {
+	var parts []string
+	if r.GetGpus() > 0 {
+		parts = append(parts, fmt.Sprintf("gpus=%d", r.GetGpus()))
+	}
+	if r.GetMemoryBytes() > 0 {
+		parts = append(parts, "mem="+formatBytes(r.GetMemoryBytes()))
+	}
+	keys := make([]string, 0, len(r.GetLabels()))
+	for k := range r.GetLabels() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+r.GetLabels()[k])
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+func parseJobsArgs(args []string) (*jobsConfig, error) {
+	cfg := &jobsConfig{}
+	var tlsCert, tlsKey, tlsCA string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch arg {
+		case "--coordinator-address":
+			cfg.coordAddr, err = nextVal(arg)
+		case "--namespace":
+			cfg.namespace, err = nextVal(arg)
+		case "--job-id":
+			cfg.jobID, err = nextVal(arg)
+		case "--world-size":
+			var v string
+			if v, err = nextVal(arg); err == nil {
+				if cfg.worldSize, err = parsePositiveInt(v); err != nil {
+					err = fmt.Errorf("--world-size: %w", err)
+				}
+			}
+		case "--gpus", "--memory-gb", "--label":
+			var v string
+			if v, err = nextVal(arg); err == nil {
+				if cfg.resources == nil {
+					cfg.resources = &pb.ResourceHints{}
+				}
+				err = parseResourceFlag(cfg.resources, arg, v)
+			}
+		case "--tls-cert":
+			tlsCert, err = nextVal(arg)
+		case "--tls-key":
+			tlsKey, err = nextVal(arg)
+		case "--tls-ca":
+			tlsCA, err = nextVal(arg)
+		default:
+			if strings.HasPrefix(arg, "-") || cfg.jobID != "" {
+				return nil, fmt.Errorf("unknown argument: %s", args[i])
+			}
+			cfg.jobID = arg
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tls, err := buildTLSConfig(tlsCert, tlsKey, tlsCA)
+	if err != nil {
+		return nil, err
+	}
+	cfg.tls = tls
+	return cfg, nil
+}
+
+// dialCoordinator connects to the coordinator at addr, over mutual TLS when
+// tls is set.
+func dialCoordinator(addr string, tls *distributed.TLSConfig) (pb.CoordinatorClient, io.Closer, error) {
+	creds := insecure.NewCredentials()
+	if tls != nil {
+		var err error
+		if creds, err = tls.ClientCredentials(); err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS client credentials: %w", err)
+		}
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), distributed.TracingDialOption())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pb.NewCoordinatorClient(conn), conn, nil
+}
+
+// Usage implements Command.Usage.
+func (c *JobsCommand) Usage() string {
+	return `jobs <list|status|submit|cancel> [JOB-ID] [OPTIONS]
+
+Manage training jobs on a coordinator. A submitted job declares its world
+size and the resources each worker needs; it stays queued until that many
+matching workers have started with "worker --job-id", and is then
+dispatched. A job completes when all of its workers have left.
+
+SUBCOMMANDS:
+  list      Show every job, or only those in --namespace
+  status    Show one job
+  submit    Queue a job (requires --world-size)
+  cancel    Cancel a queued or running job; its workers are told to stop
+
+OPTIONS:
+  --coordinator-address <addr>  Coordinator gRPC address (required)
+  --namespace <ns>              Job namespace (default: "default"; list
+                                 shows all namespaces when unset)
+  --job-id <id>                 Job ID, also accepted as a positional
+                                 argument
+  --world-size <n>              Number of workers the job needs (submit)
+  --gpus <n>                    GPUs each worker must offer (submit)
+  --memory-gb <n>               Memory in GB each worker must offer (submit)
+  --label <key=value>           Label each worker must carry (submit,
+                                 repeatable)
+  --tls-cert, --tls-key, --tls-ca <path>
+                                Client certificate, key, and CA for a
+                                 coordinator running with mutual TLS`
+}
+
+// Examples implements Command.Examples.
+func (c *JobsCommand) Examples() []string {
+	return []string{
+		"jobs submit llm-ft --coordinator-address 127.0.0.1:9000 --world-size 4 --gpus 8",
+		"jobs list --coordinator-address 127.0.0.1:9000",
+		"jobs cancel llm-ft --coordinator-address 127.0.0.1:9000",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*JobsCommand)(nil)
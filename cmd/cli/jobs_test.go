@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/coordinator"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newJobsTestCommand returns a JobsCommand whose dial reaches coord over an
+// in-memory listener, and the buffer it writes to.
+func newJobsTestCommand(t *testing.T) (*JobsCommand, *coordinator.Coordinator, *bytes.Buffer) {
+	t.Helper()
+	coord := coordinator.NewCoordinator(io.Discard, time.Minute)
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterCoordinatorServer(srv, coord)
+	go srv.Serve(lis) //nolint:errcheck
+	t.Cleanup(func() {
+		srv.Stop()
+		coord.Stop()
+	})
+
+	var out bytes.Buffer
+	cmd := NewJobsCommand(&out)
+	cmd.dial = func(string, *distributed.TLSConfig) (pb.CoordinatorClient, io.Closer, error) {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, nil, err
+		}
+		return pb.NewCoordinatorClient(conn), conn, nil
+	}
+	return cmd, coord, &out
+}
+
+func TestJobsCommand_SubmitListCancel(t *testing.T) {
+	cmd, coord, out := newJobsTestCommand(t)
+	ctx := context.Background()
+	addr := []string{"--coordinator-address", "bufnet"}
+
+	if err := cmd.Run(ctx, append([]string{"submit", "llm-ft", "--world-size", "2", "--gpus", "8", "--label", "zone=a"}, addr...)); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "llm-ft") || !strings.Contains(got, "queued") || !strings.Contains(got, "0/2") || !strings.Contains(got, "gpus=8 zone=a") {
+		t.Errorf("submit output:\n%s", got)
+	}
+
+	resp, err := coord.GetJob(ctx, &pb.GetJobRequest{JobId: "llm-ft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Job.Resources.GetGpus() != 8 || resp.Job.Resources.GetLabels()["zone"] != "a" {
+		t.Errorf("submitted resources = %v", resp.Job.Resources)
+	}
+
+	out.Reset()
+	if err := cmd.Run(ctx, append([]string{"list"}, addr...)); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "NAMESPACE") || !strings.Contains(got, "default") {
+		t.Errorf("list output:\n%s", got)
+	}
+
+	out.Reset()
+	if err := cmd.Run(ctx, append([]string{"cancel", "--job-id=llm-ft"}, addr...)); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "cancelled") {
+		t.Errorf("cancel output:\n%s", got)
+	}
+}
+
+func TestJobsCommand_ListEmpty(t *testing.T) {
+	cmd, _, out := newJobsTestCommand(t)
+	if err := cmd.Run(context.Background(), []string{"list", "--coordinator-address", "bufnet"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "No jobs.\n" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestJobsCommand_Errors(t *testing.T) {
+	cmd, _, _ := newJobsTestCommand(t)
+	for _, args := range [][]string{
+		{},
+		{"bogus", "--coordinator-address", "x"},
+		{"cancel", "--coordinator-address", "x"},
+		{"submit", "job", "--coordinator-address", "x"},
+		{"list"},
+		{"list", "--label", "novalue", "--coordinator-address", "x"},
+		{"status", "a", "b", "--coordinator-address", "x"},
+		{"status", "missing", "--coordinator-address", "x"},
+	} {
+		if err := cmd.Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) should fail", args)
+		}
+	}
+}
+
+func TestFormatResources(t *testing.T) {
+	if got := formatResources(nil); got != "-" {
+		t.Errorf("nil = %q", got)
+	}
+	r := &pb.ResourceHints{Gpus: 2, MemoryBytes: 16 << 30, Labels: map[string]string{"b": "2", "a": "1"}}
+	if got := formatResources(r); got != "gpus=2 mem=16.0GB a=1 b=2" {
+		t.Errorf("got %q", got)
+	}
+}
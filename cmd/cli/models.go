@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/serve/registry"
+)
+
+// ModelsCommand implements the "models" CLI command, which maintains the
+// model version registry used by the serving layer.
+type ModelsCommand struct {
+	out io.Writer
+	// loadFn allows injection of a custom model loader for testing.
+	loadFn func(modelID string, opts ...inference.Option) (*inference.Model, error)
+}
+
+// NewModelsCommand creates a new ModelsCommand.
+func NewModelsCommand(out io.Writer) *ModelsCommand {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &ModelsCommand{out: out, loadFn: inference.Load}
+}
+
+// Name implements Command.Name.
+func (c *ModelsCommand) Name() string { return "models" }
+
+// Description implements Command.Description.
+func (c *ModelsCommand) Description() string {
+	return "Maintain the model version registry (gc, promote)"
+}
+
+// modelsConfig holds parsed models flags.
+type modelsConfig struct {
+	db     string
+	policy registry.RetentionPolicy
+	dryRun bool
+}
+
+// Run implements Command.Run.
+func (c *ModelsCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("models: subcommand required (gc, promote)")
+	}
+	switch args[0] {
+	case "gc":
+		return c.runGC(args[1:])
+	case "promote":
+		return c.runPromote(ctx, args[1:])
+	}
+	return fmt.Errorf("models: unknown subcommand %q (want gc or promote)", args[0])
+}
+
+func (c *ModelsCommand) runGC(args []string) error {
+	cfg, err := parseModelsArgs(args)
+	if err != nil {
+		return err
+	}
+	if cfg.db == "" {
+		return errors.New("models gc: --db is required")
+	}
+	if cfg.policy.KeepLast == 0 && cfg.policy.TTL == 0 {
+		return errors.New("models gc: set --keep-last, --ttl, or both")
+	}
+
+	reg, err := registry.NewRegistry(cfg.db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reg.Close() }()
+
+	res, gcErr := reg.GC(cfg.policy, cfg.dryRun)
+	if res == nil {
+		return gcErr
+	}
+	if len(res.Removed) > 0 {
+		tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "NAME\tID\tVERSION\tCREATED\tPATH")
+		for _, mv := range res.Removed {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", mv.Name, mv.ID, mv.Version, mv.CreatedAt.Local().Format(time.DateTime), mv.Path)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if cfg.dryRun {
+		_, _ = fmt.Fprintf(c.out, "Would remove %d versions, freeing %s\n", len(res.Removed), formatBytes(res.Bytes))
+	} else {
+		_, _ = fmt.Fprintf(c.out, "Removed %d versions, freed %s\n", len(res.Removed), formatBytes(res.Bytes))
+	}
+	return gcErr
+}
+
+func parseModelsArgs(args []string) (*modelsConfig, error) {
+	cfg := &modelsConfig{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		var v string
+		switch arg {
+		case "--db":
+			cfg.db, err = nextVal("--db")
+		case "--keep-last":
+			if v, err = nextVal("--keep-last"); err == nil {
+				cfg.policy.KeepLast, err = strconv.Atoi(v)
+				if err == nil && cfg.policy.KeepLast < 0 {
+					err = fmt.Errorf("--keep-last must not be negative, got %d", cfg.policy.KeepLast)
+				}
+			}
+		case "--ttl":
+			if v, err = nextVal("--ttl"); err == nil {
+				cfg.policy.TTL, err = time.ParseDuration(v)
+				if err == nil && cfg.policy.TTL < 0 {
+					err = fmt.Errorf("--ttl must not be negative, got %v", cfg.policy.TTL)
+				}
+			}
+		case "--dry-run":
+			cfg.dryRun = true
+		default:
+			return nil, fmt.Errorf("unknown flag: %s", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// promoteConfig holds parsed models promote flags.
+type promoteConfig struct {
+	db         string
+	id         string
+	taskPaths  []string
+	thresholds []registry.MetricThreshold
+	eval       evalLMOptions
+}
+
+// runPromote activates a version only if it scores no worse than the active
+// version on the golden tasks, within the thresholds.
+func (c *ModelsCommand) runPromote(ctx context.Context, args []string) error {
+	cfg, err := parsePromoteArgs(args)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cfg.db == "":
+		return errors.New("models promote: --db is required")
+	case cfg.id == "":
+		return errors.New("models promote: --id is required")
+	case len(cfg.taskPaths) == 0:
+		return errors.New("models promote: --tasks is required")
+	}
+	tasks, err := loadEvalTasks(cfg.taskPaths)
+	if err != nil {
+		return err
+	}
+
+	reg, err := registry.NewRegistry(cfg.db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reg.Close() }()
+
+	// Metrics are named task/metric, e.g. "arc/acc".
+	evaluate := func(ctx context.Context, mv registry.ModelVersion) (map[string]float64, error) {
+		if mv.Path == "" {
+			return nil, fmt.Errorf("version %s has no path", mv.ID)
+		}
+		results, err := evalModel(ctx, c.loadFn, mv.Path, tasks, cfg.eval)
+		if err != nil {
+			return nil, err
+		}
+		metrics := make(map[string]float64)
+		for _, res := range results {
+			for name, v := range res.Metrics {
+				metrics[res.Task+"/"+name] = v
+			}
+		}
+		return metrics, nil
+	}
+	d, promoteErr := reg.Promote(ctx, cfg.id, evaluate, cfg.thresholds)
+	if d == nil {
+		return promoteErr
+	}
+
+	names := make([]string, 0, len(d.Candidate))
+	for name := range d.Candidate {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "METRIC\tACTIVE\tCANDIDATE\tDELTA")
+	for _, name := range names {
+		active, delta := "-", "-"
+		if v, ok := d.Baseline[name]; ok {
+			active = fmt.Sprintf("%.4f", v)
+			delta = fmt.Sprintf("%+.4f", d.Delta[name])
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.4f\t%s\n", name, active, d.Candidate[name], delta)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	switch {
+	case !d.Promoted:
+		_, _ = fmt.Fprintf(c.out, "Refused %s: regressed on %s; %s stays active\n", d.CandidateID, strings.Join(d.Regressions, ", "), d.BaselineID)
+	case d.BaselineID == "":
+		_, _ = fmt.Fprintf(c.out, "Promoted %s\n", d.CandidateID)
+	default:
+		_, _ = fmt.Fprintf(c.out, "Promoted %s (was %s)\n", d.CandidateID, d.BaselineID)
+	}
+	return promoteErr
+}
+
+func parsePromoteArgs(args []string) (*promoteConfig, error) {
+	cfg := &promoteConfig{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		var v string
+		switch arg {
+		case "--db":
+			cfg.db, err = nextVal("--db")
+		case "--id":
+			cfg.id, err = nextVal("--id")
+		case "--tasks":
+			if v, err = nextVal("--tasks"); err == nil {
+				for _, p := range strings.Split(v, ",") {
+					if p = strings.TrimSpace(p); p != "" {
+						cfg.taskPaths = append(cfg.taskPaths, p)
+					}
+				}
+			}
+		case "--max-drop", "--max-rise":
+			if v, err = nextVal(arg); err == nil {
+				err = parseThresholds(cfg, v, arg == "--max-rise")
+			}
+		case "--limit":
+			if v, err = nextVal("--limit"); err == nil {
+				cfg.eval.limit, err = strconv.Atoi(v)
+			}
+		case "--max-context":
+			if v, err = nextVal("--max-context"); err == nil {
+				cfg.eval.maxContext, err = strconv.Atoi(v)
+			}
+		case "--bos":
+			cfg.eval.useBOS = true
+		case "--cache-dir":
+			cfg.eval.cacheDir, err = nextVal("--cache-dir")
+		default:
+			return nil, fmt.Errorf("unknown flag: %s", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// parseThresholds adds the comma-separated metric=amount thresholds in v to
+// cfg.
+func parseThresholds(cfg *promoteConfig, v string, lowerIsBetter bool) error {
+	for _, item := range strings.Split(v, ",") {
+		metric, amount, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || metric == "" {
+			return fmt.Errorf("threshold %q: want metric=amount", item)
+		}
+		maxRegression, err := strconv.ParseFloat(amount, 64)
+		if err != nil || maxRegression < 0 {
+			return fmt.Errorf("threshold %q: amount must be a non-negative number", item)
+		}
+		cfg.thresholds = append(cfg.thresholds, registry.MetricThreshold{
+			Metric:        metric,
+			MaxRegression: maxRegression,
+			LowerIsBetter: lowerIsBetter,
+		})
+	}
+	return nil
+}
+
+// Usage implements Command.Usage.
+func (c *ModelsCommand) Usage() string {
+	return `models gc --db <path> [OPTIONS]
+models promote --db <path> --id <version> --tasks <files> [OPTIONS]
+
+Garbage-collect the model version registry. gc deletes the versions the
+retention policy does not keep, together with their files, unless a kept
+version uses the same path. Active (promoted) versions are always kept.
+
+promote activates a version only if it does not regress against the
+active version of the same name. Both are scored on the golden eval-lm
+tasks; if a thresholded metric gets worse by more than its allowance the
+active version stays, and the command fails. The decision and metric
+deltas are recorded in the registry. Metrics are named task/metric, for
+example arc/acc or capitals/mean_nll.
+
+SUBCOMMANDS:
+  gc         Apply the retention policy
+  promote    Activate a version behind an evaluation guard
+
+GC OPTIONS:
+  --db <path>          Registry database (required)
+  --keep-last <n>      Keep the n newest versions of each model name
+  --ttl <duration>     Remove versions older than this, e.g. 168h
+  --dry-run            Report what would be removed without removing it
+
+PROMOTE OPTIONS:
+  --db <path>                Registry database (required)
+  --id <version>             Version to promote (required)
+  --tasks <files>            Comma-separated golden task files (required)
+  --max-drop <metric=x,...>  Allowed drop of higher-is-better metrics
+  --max-rise <metric=x,...>  Allowed rise of lower-is-better metrics
+  --limit <n>                Evaluate at most n examples per task
+  --max-context <n>          Maximum tokens per scored sequence
+  --bos                      Prepend the BOS token to scored sequences
+  --cache-dir <dir>          Override default cache directory`
+}
+
+// Examples implements Command.Examples.
+func (c *ModelsCommand) Examples() []string {
+	return []string{
+		"models gc --db registry.db --keep-last 3 --dry-run",
+		"models gc --db registry.db --keep-last 5 --ttl 720h",
+		"models promote --db registry.db --id gemma-v4 --tasks golden/arc.json --max-drop arc/acc=0.01",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*ModelsCommand)(nil)
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/serve/registry"
+)
+
+func TestModelsCommand_GC(t *testing.T) {
+	db := filepath.Join(t.TempDir(), "registry.db")
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, id := range []string{"m-v1", "m-v2", "m-v3"} {
+		if err := reg.Register(registry.ModelVersion{ID: id, Name: "m", CreatedAt: now.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	cmd := NewModelsCommand(&out)
+	if err := cmd.Run(context.Background(), []string{"gc", "--db", db, "--keep-last=1", "--dry-run"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.Contains(s, "m-v1") || !strings.Contains(s, "Would remove 2 versions") {
+		t.Errorf("dry run output:\n%s", s)
+	}
+
+	out.Reset()
+	if err := cmd.Run(context.Background(), []string{"gc", "--db", db, "--keep-last", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.Contains(s, "Removed 2 versions") {
+		t.Errorf("gc output:\n%s", s)
+	}
+	reg, err = registry.NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reg.Close() }()
+	if left, _ := reg.List("m"); len(left) != 1 || left[0].ID != "m-v3" {
+		t.Errorf("versions left = %+v, want only m-v3", left)
+	}
+}
+
+func TestModelsCommand_Errors(t *testing.T) {
+	cmd := NewModelsCommand(io.Discard)
+	db := filepath.Join(t.TempDir(), "registry.db")
+	for _, args := range [][]string{
+		{},
+		{"bogus"},
+		{"gc", "--keep-last", "1"},
+		{"gc", "--db", db},
+		{"gc", "--db", db, "--keep-last", "-1"},
+		{"gc", "--db", db, "--ttl", "soon"},
+		{"gc", "--bogus"},
+		{"promote", "--id", "m-v1", "--tasks", "t.json"},
+		{"promote", "--db", db, "--tasks", "t.json"},
+		{"promote", "--db", db, "--id", "m-v1"},
+		{"promote", "--db", db, "--id", "m-v1", "--tasks", "t.json", "--max-drop", "acc"},
+		{"promote", "--db", db, "--id", "m-v1", "--tasks", "t.json", "--max-rise", "nll=-1"},
+		{"promote", "--bogus"},
+	} {
+		if err := cmd.Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) should fail", args)
+		}
+	}
+}
+
+func TestModelsCommand_Promote(t *testing.T) {
+	ctx := context.Background()
+	db := filepath.Join(t.TempDir(), "registry.db")
+	reg, err := registry.NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"m-v1", "m-v2"} {
+		if err := reg.Register(registry.ModelVersion{ID: id, Name: "m", Path: id + ".gguf"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	cmd := NewModelsCommand(&out)
+	var loaded []string
+	cmd.loadFn = func(path string, _ ...inference.Option) (*inference.Model, error) {
+		loaded = append(loaded, path)
+		return buildCLITestModel(t), nil
+	}
+	tasks := writeEvalLMTasks(t)
+	if err := cmd.Run(ctx, []string{"promote", "--db", db, "--id", "m-v1", "--tasks", tasks}); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.Contains(s, "mc/acc") || !strings.Contains(s, "Promoted m-v1\n") {
+		t.Errorf("first promote output:\n%s", s)
+	}
+
+	// The same weights cannot regress, so m-v2 replaces m-v1.
+	out.Reset()
+	loaded = nil
+	args := []string{"promote", "--db", db, "--id", "m-v2", "--tasks", tasks, "--max-drop", "mc/acc=0,qa/exact_match=0"}
+	if err := cmd.Run(ctx, args); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.Contains(s, "+0.0000") || !strings.Contains(s, "Promoted m-v2 (was m-v1)") {
+		t.Errorf("second promote output:\n%s", s)
+	}
+	if !slices.Equal(loaded, []string{"m-v2.gguf", "m-v1.gguf"}) {
+		t.Errorf("loaded %v, want the candidate and the active version", loaded)
+	}
+
+	// A threshold on a metric the tasks do not report is an error.
+	args = []string{"promote", "--db", db, "--id", "m-v1", "--tasks", tasks, "--max-rise", "qa/mean_nll=0.1"}
+	if err := cmd.Run(ctx, args); err == nil {
+		t.Error("unknown threshold metric should fail")
+	}
+
+	reg, err = registry.NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reg.Close() }()
+	if mv, err := reg.GetActive("m"); err != nil || mv.ID != "m-v2" {
+		t.Errorf("active = %+v, %v; want m-v2", mv, err)
+	}
+	if decisions, _ := reg.Promotions("m"); len(decisions) != 2 {
+		t.Errorf("%d decisions recorded, want 2", len(decisions))
+	}
+}
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/inference/eval"
+)
+
+// PerplexityCommand implements the "perplexity" CLI command, which reports
+// token-level negative log-likelihood and perplexity of a model over a text
+// dataset. It is used to validate imported or converted weights against
+// reference numbers.
+type PerplexityCommand struct {
+	out io.Writer
+	// loadFn allows injection of a custom model loader for testing.
+	loadFn func(modelID string, opts ...inference.Option) (*inference.Model, error)
+}
+
+// NewPerplexityCommand creates a new PerplexityCommand.
+func NewPerplexityCommand(out io.Writer) *PerplexityCommand {
+	return &PerplexityCommand{out: out, loadFn: inference.Load}
+}
+
+// Name implements Command.Name.
+func (c *PerplexityCommand) Name() string { return "perplexity" }
+
+// Description implements Command.Description.
+func (c *PerplexityCommand) Description() string {
+	return "Evaluate model perplexity on a text dataset"
+}
+
+// Run implements Command.Run.
+func (c *PerplexityCommand) Run(ctx context.Context, args []string) error {
+	var modelID, dataPath, cacheDir string
+	contextLen := 1024
+	var stride int
+	var useBOS, jsonOut bool
+	var reference float64
+	tolerance := 0.01
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+		switch arg {
+		case "--data":
+			s, err := nextVal("--data")
+			if err != nil {
+				return err
+			}
+			dataPath = s
+		case "--context":
+			s, err := nextVal("--context")
+			if err != nil {
+				return err
+			}
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("--context: %w", err)
+			}
+			contextLen = v
+		case "--stride":
+			s, err := nextVal("--stride")
+			if err != nil {
+				return err
+			}
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("--stride: %w", err)
+			}
+			stride = v
+		case "--reference":
+			s, err := nextVal("--reference")
+			if err != nil {
+				return err
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return fmt.Errorf("--reference: %w", err)
+			}
+			reference = v
+		case "--tolerance":
+			s, err := nextVal("--tolerance")
+			if err != nil {
+				return err
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return fmt.Errorf("--tolerance: %w", err)
+			}
+			tolerance = v
+		case "--cache-dir":
+			s, err := nextVal("--cache-dir")
+			if err != nil {
+				return err
+			}
+			cacheDir = s
+		case "--bos":
+			useBOS = true
+		case "--json":
+			jsonOut = true
+		default:
+			if modelID != "" {
+				return fmt.Errorf("unexpected argument: %s", args[i])
+			}
+			modelID = args[i]
+		}
+	}
+
+	if modelID == "" {
+		return errors.New("model ID is required")
+	}
+	if dataPath == "" {
+		return errors.New("--data is required")
+	}
+
+	docs, err := eval.LoadDocuments(dataPath)
+	if err != nil {
+		return fmt.Errorf("load dataset: %w", err)
+	}
+
+	var loadOpts []inference.Option
+	if cacheDir != "" {
+		loadOpts = append(loadOpts, inference.WithCacheDir(cacheDir))
+	}
+	mdl, err := c.loadFn(modelID, loadOpts...)
+	if err != nil {
+		return fmt.Errorf("load model: %w", err)
+	}
+
+	opts := []eval.PerplexityOption{eval.WithContextLen(contextLen)}
+	if stride > 0 {
+		opts = append(opts, eval.WithStride(stride))
+	}
+	if useBOS {
+		opts = append(opts, eval.WithBOS(mdl.Config().BOSTokenID))
+	}
+	ev, err := eval.NewPerplexityEvaluator(eval.NewGeneratorScorer(mdl.Generator()), mdl.Tokenizer(), opts...)
+	if err != nil {
+		return err
+	}
+	res, err := ev.Evaluate(ctx, docs)
+	if err != nil {
+		return fmt.Errorf("evaluate: %w", err)
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			return fmt.Errorf("encode result: %w", err)
+		}
+	} else {
+		_, _ = fmt.Fprintf(c.out, "%-30s %10s %12s %12s\n", "DOCUMENT", "TOKENS", "MEAN NLL", "PERPLEXITY")
+		for _, d := range res.Documents {
+			_, _ = fmt.Fprintf(c.out, "%-30s %10d %12.4f %12.4f\n", d.ID, d.Tokens, d.MeanNLL, d.Perplexity)
+		}
+		_, _ = fmt.Fprintf(c.out, "%-30s %10d %12.4f %12.4f\n", "TOTAL", res.Tokens, res.MeanNLL, res.Perplexity)
+	}
+
+	if reference > 0 {
+		diff := math.Abs(res.Perplexity-reference) / reference
+		if diff > tolerance {
+			return fmt.Errorf("perplexity %.4f differs from reference %.4f by %.2f%% (tolerance %.2f%%)",
+				res.Perplexity, reference, diff*100, tolerance*100)
+		}
+	}
+	return nil
+}
+
+// Usage implements Command.Usage.
+func (c *PerplexityCommand) Usage() string {
+	return `perplexity <model-id> --data <path> [OPTIONS]
+
+Compute token-level negative log-likelihood and perplexity over a dataset.
+Long documents are scored with overlapping sliding windows; every token
+after the first is scored exactly once.
+
+The dataset may be a .jsonl file with {"id", "text"} lines, a directory of
+.txt files, or a single text file.
+
+OPTIONS:
+  --data <path>        Dataset to evaluate (required)
+  --context <n>        Tokens per forward pass (default: 1024)
+  --stride <n>         New tokens scored per window (default: context-1)
+  --bos                Prepend the BOS token so the first token is scored
+  --json               Print results as JSON
+  --reference <ppl>    Fail if aggregate perplexity differs from this value
+  --tolerance <frac>   Relative tolerance for --reference (default: 0.01)
+  --cache-dir <dir>    Override default cache directory`
+}
+
+// Examples implements Command.Examples.
+func (c *PerplexityCommand) Examples() []string {
+	return []string{
+		"perplexity google/gemma-3-1b --data wikitext.jsonl",
+		"perplexity ./model.gguf --data corpus/ --context 2048 --stride 512",
+		"perplexity ./model.gguf --data test.txt --reference 8.71 --tolerance 0.005",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*PerplexityCommand)(nil)
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/inference/eval"
+)
+
+func writePerplexityData(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	data := "{\"id\":\"a\",\"text\":\"hello world foo bar\"}\n{\"id\":\"b\",\"text\":\"foo bar hello\"}\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestPerplexityCommand(t *testing.T, out *bytes.Buffer) *PerplexityCommand {
+	t.Helper()
+	cmd := NewPerplexityCommand(out)
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return buildCLITestModel(t), nil
+	}
+	return cmd
+}
+
+func TestPerplexityCommand_Metadata(t *testing.T) {
+	cmd := NewPerplexityCommand(nil)
+	if cmd.Name() != "perplexity" {
+		t.Errorf("Name() = %q, want %q", cmd.Name(), "perplexity")
+	}
+	if cmd.Description() == "" {
+		t.Error("Description() should not be empty")
+	}
+	if !strings.Contains(cmd.Usage(), "--data") {
+		t.Error("Usage() should document --data")
+	}
+	if len(cmd.Examples()) == 0 {
+		t.Error("Examples() should not be empty")
+	}
+}
+
+func TestPerplexityCommand_ArgErrors(t *testing.T) {
+	data := writePerplexityData(t)
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing model", []string{"--data", data}},
+		{"missing data", []string{"m"}},
+		{"bad context", []string{"m", "--data", data, "--context", "x"}},
+		{"bad stride", []string{"m", "--data", data, "--stride=x"}},
+		{"stride too large", []string{"m", "--data", data, "--context", "4", "--stride", "4"}},
+		{"missing dataset", []string{"m", "--data", filepath.Join(t.TempDir(), "nope")}},
+		{"extra argument", []string{"m", "n", "--data", data}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := newTestPerplexityCommand(t, &out).Run(context.Background(), tt.args); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPerplexityCommand_LoadError(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewPerplexityCommand(&out)
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return nil, errors.New("load failed")
+	}
+	err := cmd.Run(context.Background(), []string{"m", "--data", writePerplexityData(t)})
+	if err == nil || !strings.Contains(err.Error(), "load model") {
+		t.Errorf("err = %v, want load model error", err)
+	}
+}
+
+func TestPerplexityCommand_Table(t *testing.T) {
+	var out bytes.Buffer
+	err := newTestPerplexityCommand(t, &out).Run(context.Background(),
+		[]string{"m", "--data", writePerplexityData(t), "--context", "3", "--stride", "1"})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	for _, want := range []string{"DOCUMENT", "a", "b", "TOTAL"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPerplexityCommand_JSONAndReference(t *testing.T) {
+	data := writePerplexityData(t)
+
+	var out bytes.Buffer
+	if err := newTestPerplexityCommand(t, &out).Run(context.Background(), []string{"m", "--data", data, "--json", "--bos"}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	var res eval.PerplexityResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	// With --bos every word token is scored.
+	if len(res.Documents) != 2 || res.Tokens != 7 || res.Perplexity <= 0 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	ref := res.Perplexity * 1.001
+	out.Reset()
+	if err := newTestPerplexityCommand(t, &out).Run(context.Background(),
+		[]string{"m", "--data", data, "--bos", "--reference", strconv.FormatFloat(ref, 'g', -1, 64)}); err != nil {
+		t.Errorf("reference within tolerance should pass: %v", err)
+	}
+	out.Reset()
+	err := newTestPerplexityCommand(t, &out).Run(context.Background(),
+		[]string{"m", "--data", data, "--bos", "--reference", strconv.FormatFloat(ref, 'g', -1, 64), "--tolerance", "0.0001"})
+	if err == nil || !strings.Contains(err.Error(), "differs from reference") {
+		t.Errorf("err = %v, want reference mismatch", err)
+	}
+}
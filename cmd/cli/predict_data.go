@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	datacache "github.com/zerfoo/zerfoo/data/cache"
+)
+
+// predictCSVVariant versions the cached parse of a predict CSV. Bump it when
+// readCSVData changes what it produces.
+const predictCSVVariant = "predict-csv/v1"
+
+// SetDataCache sets the dataset cache predict consults before downloading
+// or parsing its data. A nil store disables caching.
+func (c *PredictCommand[T]) SetDataCache(store *datacache.Store) {
+	c.dataCache = store
+}
+
+// parsedCSV is the cached form of readCSVData's result.
+type parsedCSV struct {
+	IDs         []string
+	Groups      []string
+	Features    []float64
+	NumFeatures int
+}
+
+// loadData reads the rows of config.DataPath, downloading http(s) URLs
+// first. With a dataset cache, a downloaded file is reused until its entry
+// expires, and the parsed rows are reused for as long as the source content
+// and the column selection are unchanged.
+func (c *PredictCommand[T]) loadData(ctx context.Context, config *PredictCommandConfig) (ids, groups []string, features []float64, numFeatures int, err error) {
+	store := c.dataCache
+	if config.DataCacheDir != "" {
+		if store, err = datacache.Open(config.DataCacheDir); err != nil {
+			return nil, nil, nil, 0, err
+		}
+	}
+	if config.NoDataCache {
+		store = nil
+	}
+
+	local := *config
+	uri, sourceHash := config.DataPath, ""
+	switch {
+	case isRemoteData(config.DataPath) && store != nil:
+		e, err := store.Fetch(ctx, uri, func(ctx context.Context, w io.Writer) error {
+			return downloadData(ctx, uri, w)
+		})
+		if err != nil {
+			return nil, nil, nil, 0, fmt.Errorf("download %s: %w", uri, err)
+		}
+		local.DataPath, sourceHash = store.Path(e), e.Digest
+	case isRemoteData(config.DataPath):
+		tmp, err := downloadTemp(ctx, uri)
+		if err != nil {
+			return nil, nil, nil, 0, fmt.Errorf("download %s: %w", uri, err)
+		}
+		defer os.Remove(tmp) //nolint:errcheck
+		local.DataPath = tmp
+	case store != nil:
+		if abs, err := filepath.Abs(config.DataPath); err == nil {
+			uri = abs
+		}
+		if sourceHash, err = datacache.HashFile(config.DataPath); err != nil {
+			return nil, nil, nil, 0, err
+		}
+	}
+	if store == nil {
+		return c.readCSVData(&local)
+	}
+
+	variant := csvVariant(config)
+	if e, ok := store.Lookup(uri, sourceHash, variant); ok {
+		if p, err := readParsedCSV(store.Path(e)); err == nil {
+			return p.IDs, p.Groups, p.Features, p.NumFeatures, nil
+		}
+	}
+	ids, groups, features, numFeatures, err = c.readCSVData(&local)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	p := parsedCSV{IDs: ids, Groups: groups, Features: features, NumFeatures: numFeatures}
+	if _, err := store.Put(uri, sourceHash, variant, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(&p)
+	}); err != nil && config.Verbose {
+		// Caching is an optimization; the parsed data is still good.
+		fmt.Printf("warning: dataset cache: %v\n", err)
+	}
+	return ids, groups, features, numFeatures, nil
+}
+
+// csvVariant names the parse of a CSV under config's column selection.
+func csvVariant(config *PredictCommandConfig) string {
+	v := url.Values{}
+	v.Set("id", config.IDColumn)
+	v.Set("group", config.GroupColumn)
+	v.Set("features", strings.Join(config.FeatureColumns, ","))
+	return predictCSVVariant + "?" + v.Encode()
+}
+
+func readParsedCSV(path string) (*parsedCSV, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // cache-internal path
+	if err != nil {
+		return nil, err
+	}
+	var p parsedCSV
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func isRemoteData(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// downloadData writes the body of a GET of uri to w.
+func downloadData(ctx context.Context, uri string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// downloadTemp downloads uri to a temporary file and returns its path.
+func downloadTemp(ctx context.Context, uri string) (string, error) {
+	f, err := os.CreateTemp("", "zerfoo-data-*")
+	if err != nil {
+		return "", err
+	}
+	if err := downloadData(ctx, uri, f); err != nil {
+		f.Close()           //nolint:errcheck
+		os.Remove(f.Name()) //nolint:errcheck
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name()) //nolint:errcheck
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	datacache "github.com/zerfoo/zerfoo/data/cache"
+	"github.com/zerfoo/zerfoo/model"
+)
+
+func TestLoadData_CachesDownloadAndParse(t *testing.T) {
+	content := "id,f1,f2\na,1,2\nb,3,4\n"
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		downloads++
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	store, err := datacache.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewPredictCommand(model.Float32ModelRegistry, float32From, float32To)
+	cmd.SetDataCache(store)
+	config := &PredictCommandConfig{IDColumn: "id"}
+	config.DataPath = server.URL + "/data.csv"
+
+	for range 2 {
+		ids, _, features, numFeatures, err := cmd.loadData(context.Background(), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 2 || numFeatures != 2 || features[3] != 4 {
+			t.Fatalf("ids=%v features=%v numFeatures=%d", ids, features, numFeatures)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("downloaded %d times, want 1", downloads)
+	}
+	// One source entry and one parsed entry.
+	if n, _, _ := store.Usage(); n != 2 {
+		t.Errorf("cache holds %d entries, want 2", n)
+	}
+
+	// A different column selection is a different parse of the same source.
+	config.GroupColumn = "f2"
+	if _, _, _, numFeatures, err := cmd.loadData(context.Background(), config); err != nil || numFeatures != 1 {
+		t.Fatalf("numFeatures = %d, err = %v", numFeatures, err)
+	}
+	if downloads != 1 {
+		t.Errorf("downloaded %d times after changing columns, want 1", downloads)
+	}
+}
+
+func TestLoadData_ReparsesChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte("id,f1\na,1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := t.TempDir()
+	cmd := NewPredictCommand(model.Float32ModelRegistry, float32From, float32To)
+	config := &PredictCommandConfig{IDColumn: "id", DataCacheDir: cacheDir}
+	config.DataPath = path
+
+	if _, _, features, _, err := cmd.loadData(context.Background(), config); err != nil || features[0] != 1 {
+		t.Fatalf("features = %v, err = %v", features, err)
+	}
+	if err := os.WriteFile(path, []byte("id,f1\na,7\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, features, _, err := cmd.loadData(context.Background(), config); err != nil || features[0] != 7 {
+		t.Fatalf("changed file not re-parsed: features = %v, err = %v", features, err)
+	}
+
+	config.NoDataCache = true
+	config.DataCacheDir = ""
+	if _, _, features, _, err := cmd.loadData(context.Background(), config); err != nil || features[0] != 7 {
+		t.Fatalf("uncached load: features = %v, err = %v", features, err)
+	}
+}
+
+func TestLoadData_DownloadWithoutCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("id,f1\na,5\n"))
+	}))
+	defer server.Close()
+
+	cmd := NewPredictCommand(model.Float32ModelRegistry, float32From, float32To)
+	config := &PredictCommandConfig{IDColumn: "id"}
+	config.DataPath = server.URL
+	if _, _, features, _, err := cmd.loadData(context.Background(), config); err != nil || features[0] != 5 {
+		t.Fatalf("features = %v, err = %v", features, err)
+	}
+}
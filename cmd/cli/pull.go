@@ -71,10 +71,15 @@ func (c *PullCommand) Run(ctx context.Context, args []string) error {
 		if err != nil {
 			return fmt.Errorf("create registry: %w", err)
 		}
+		blobs, err := lr.Blobs()
+		if err != nil {
+			return fmt.Errorf("create blob cache: %w", err)
+		}
 		progress := newProgressDisplay(c.out, isTTY(c.out))
 		lr.SetPullFunc(registry.NewHFPullFunc(registry.HFPullOptions{
 			Quant:      quant,
 			OnProgress: progress.callback,
+			Cache:      blobs,
 		}))
 		reg = lr
 	}
@@ -102,6 +107,11 @@ func (c *PullCommand) Usage() string {
 
 Download and cache a model from a remote registry.
 
+Files are fetched in checksummed chunks; an interrupted pull resumes from
+the last verified chunk when run again. Downloads are stored once in a
+content-addressed cache under <cache-dir>/blobs that is shared with run,
+serve, and every other command loading models from the same cache directory.
+
 OPTIONS:
   --quant <type>     Quantization type (default: Q4_K_M)
   --cache-dir <dir>  Override default cache directory`
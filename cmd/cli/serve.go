@@ -2,6 +2,8 @@ package cli
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/zerfoo/zerfoo/inference"
 	"github.com/zerfoo/zerfoo/serve"
+	"github.com/zerfoo/zerfoo/serve/security"
 	"github.com/zerfoo/zerfoo/serve/shutdown"
 )
 
@@ -47,7 +50,13 @@ func (c *ServeCommand) Description() string {
 func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 	var modelID, cacheDir, port, gpusRaw, apiKey, tlsCert, tlsKey string
 	var pjrtPlugin string
+	var adminPort, adminKey, adminCA string
+	var reqLogPath, reqLogRedact string
+	var reqLogSample float64
+	var quota security.Quota
 	var allowNoAuth bool
+	var kvWindow, kvSinks int
+	var promptCacheBlocks int
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -90,12 +99,90 @@ func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 			}
 			tlsKey = args[i+1]
 			i++
+		case "--admin-port":
+			if i+1 >= len(args) {
+				return errors.New("--admin-port requires a value")
+			}
+			adminPort = args[i+1]
+			i++
+		case "--admin-api-key":
+			if i+1 >= len(args) {
+				return errors.New("--admin-api-key requires a value")
+			}
+			adminKey = args[i+1]
+			i++
+		case "--admin-client-ca":
+			if i+1 >= len(args) {
+				return errors.New("--admin-client-ca requires a value")
+			}
+			adminCA = args[i+1]
+			i++
+		case "--request-log":
+			if i+1 >= len(args) {
+				return errors.New("--request-log requires a value")
+			}
+			reqLogPath = args[i+1]
+			i++
+		case "--request-log-sample":
+			if i+1 >= len(args) {
+				return errors.New("--request-log-sample requires a value")
+			}
+			v, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || v < 0 || v > 1 {
+				return fmt.Errorf("--request-log-sample must be between 0 and 1, got %q", args[i+1])
+			}
+			reqLogSample = v
+			i++
+		case "--request-log-redact":
+			if i+1 >= len(args) {
+				return errors.New("--request-log-redact requires a value")
+			}
+			reqLogRedact = args[i+1]
+			i++
+		case "--quota-rps", "--quota-burst", "--quota-tokens-per-day":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			if err := parseQuotaFlag(&quota, args[i], args[i+1]); err != nil {
+				return err
+			}
+			i++
 		case "--pjrt":
 			if i+1 >= len(args) {
 				return errors.New("--pjrt requires a value")
 			}
 			pjrtPlugin = args[i+1]
 			i++
+		case "--kv-window":
+			if i+1 >= len(args) {
+				return errors.New("--kv-window requires a value")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid --kv-window value %q", args[i+1])
+			}
+			kvWindow = n
+			i++
+		case "--kv-sinks":
+			if i+1 >= len(args) {
+				return errors.New("--kv-sinks requires a value")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid --kv-sinks value %q", args[i+1])
+			}
+			kvSinks = n
+			i++
+		case "--prompt-cache":
+			if i+1 >= len(args) {
+				return errors.New("--prompt-cache requires a value")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid --prompt-cache value %q", args[i+1])
+			}
+			promptCacheBlocks = n
+			i++
 		default:
 			if modelID != "" {
 				return fmt.Errorf("unexpected argument: %s", args[i])
@@ -109,6 +196,10 @@ func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 		apiKey = os.Getenv("ZERFOO_API_KEY")
 	}
 
+	if adminKey == "" {
+		adminKey = os.Getenv("ZERFOO_ADMIN_API_KEY")
+	}
+
 	if modelID == "" {
 		return errors.New("model ID is required")
 	}
@@ -119,6 +210,18 @@ func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 	if (tlsCert != "") != (tlsKey != "") {
 		return errors.New("both --tls-cert and --tls-key are required")
 	}
+	if adminPort == "" && (adminKey != "" || adminCA != "") {
+		return errors.New("--admin-api-key and --admin-client-ca require --admin-port")
+	}
+	if adminPort != "" && adminKey == "" && adminCA == "" {
+		return errors.New("--admin-port requires --admin-api-key, ZERFOO_ADMIN_API_KEY, or --admin-client-ca")
+	}
+	if adminCA != "" && tlsCert == "" {
+		return errors.New("--admin-client-ca requires --tls-cert and --tls-key")
+	}
+	if reqLogPath == "" && (reqLogSample > 0 || reqLogRedact != "") {
+		return errors.New("--request-log-sample and --request-log-redact require --request-log")
+	}
 
 	var loadOpts []inference.Option
 	if cacheDir != "" {
@@ -127,6 +230,21 @@ func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 	if pjrtPlugin != "" {
 		loadOpts = append(loadOpts, inference.WithPJRT(pjrtPlugin))
 	}
+	if kvSinks > 0 && kvWindow == 0 {
+		return errors.New("--kv-sinks requires --kv-window")
+	}
+	if kvWindow > 0 {
+		loadOpts = append(loadOpts, inference.WithKVWindow(kvWindow, kvSinks))
+	}
+	if promptCacheBlocks > 0 {
+		if kvWindow > 0 {
+			return errors.New("--prompt-cache cannot be combined with --kv-window")
+		}
+		if pjrtPlugin != "" {
+			return errors.New("--prompt-cache cannot be combined with --pjrt")
+		}
+		loadOpts = append(loadOpts, inference.WithPromptCache(promptCacheBlocks))
+	}
 
 	var gpuIDs []int
 	if gpusRaw != "" {
@@ -159,37 +277,77 @@ func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 	if apiKey != "" {
 		serverOpts = append(serverOpts, serve.WithAPIKey(apiKey))
 	}
+	if quota != (security.Quota{}) {
+		serverOpts = append(serverOpts, serve.WithQuotas(security.NewQuotaManager(quota)))
+	}
+	if reqLogPath != "" {
+		var sink serve.RequestLogSink
+		if reqLogPath == "-" {
+			sink = serve.NewJSONRequestLogSink(os.Stdout)
+		} else {
+			fileSink, err := serve.NewFileRequestLogSink(reqLogPath)
+			if err != nil {
+				return fmt.Errorf("open request log: %w", err)
+			}
+			defer func() { _ = fileSink.Close() }()
+			sink = fileSink
+		}
+		cfg := serve.RequestLogConfig{Sinks: []serve.RequestLogSink{sink}, SampleRate: reqLogSample}
+		for _, f := range strings.Split(reqLogRedact, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				cfg.RedactFields = append(cfg.RedactFields, f)
+			}
+		}
+		serverOpts = append(serverOpts, serve.WithRequestLog(cfg))
+	}
 	srv := serve.NewServer(mdl, serverOpts...)
 	httpServer := &http.Server{
 		Addr:    net.JoinHostPort("", port),
 		Handler: srv.Handler(),
 	}
 
+	servers := []*http.Server{httpServer}
+	if adminPort != "" {
+		adminServer, err := c.newAdminServer(srv, adminPort, adminKey, adminCA, loadOpts)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, adminServer)
+	}
+
 	if c.shutdownCoord != nil {
-		c.shutdownCoord.Register(shutdownAdapter{httpServer})
+		for _, hs := range servers {
+			c.shutdownCoord.Register(shutdownAdapter{hs})
+		}
 	}
 
 	_, _ = fmt.Fprintf(c.out, "Serving %s on :%s\n", modelID, port)
+	if adminPort != "" {
+		_, _ = fmt.Fprintf(c.out, "Admin API on :%s\n", adminPort)
+	}
 
-	errCh := make(chan error, 1)
-	go func() {
-		if tlsCert != "" && tlsKey != "" {
-			errCh <- httpServer.ListenAndServeTLS(tlsCert, tlsKey)
-		} else {
-			errCh <- httpServer.ListenAndServe()
-		}
-	}()
+	errCh := make(chan error, len(servers))
+	for _, hs := range servers {
+		go func() {
+			if tlsCert != "" && tlsKey != "" {
+				errCh <- hs.ListenAndServeTLS(tlsCert, tlsKey)
+			} else {
+				errCh <- hs.ListenAndServe()
+			}
+		}()
+	}
 
 	select {
 	case <-ctx.Done():
-		shutCtx, shutCancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer shutCancel()
-		if err := httpServer.Shutdown(shutCtx); err != nil {
+		if err := shutdownServers(servers); err != nil {
 			_, _ = fmt.Fprintf(c.out, "WARN: serve: graceful shutdown timed out after 30s: %v\n", err)
 			return err
 		}
-		return nil
+		return interruptCause(ctx)
 	case err := <-errCh:
+		// One listener stopped (a port already in use, say); stop the
+		// others too rather than leaving them serving in this process.
+		_ = shutdownServers(servers)
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
@@ -197,6 +355,84 @@ func (c *ServeCommand) Run(ctx context.Context, args []string) error {
 	}
 }
 
+// shutdownServers gracefully shuts down the admin servers and then the
+// main server within 30s, returning the main server's error.
+func shutdownServers(servers []*http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, hs := range servers[1:] {
+		_ = hs.Shutdown(ctx)
+	}
+	return servers[0].Shutdown(ctx)
+}
+
+// parseQuotaFlag sets the quota field for flag from value.
+func parseQuotaFlag(q *security.Quota, flag, value string) error {
+	switch flag {
+	case "--quota-rps":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v <= 0 {
+			return fmt.Errorf("%s must be a positive number, got %q", flag, value)
+		}
+		q.RequestsPerSecond = v
+	case "--quota-burst":
+		v, err := strconv.Atoi(value)
+		if err != nil || v <= 0 {
+			return fmt.Errorf("%s must be a positive integer, got %q", flag, value)
+		}
+		q.Burst = v
+	case "--quota-tokens-per-day":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || v <= 0 {
+			return fmt.Errorf("%s must be a positive integer, got %q", flag, value)
+		}
+		q.TokensPerDay = v
+	}
+	return nil
+}
+
+// newAdminServer builds the admin API listener. Admin requests authenticate
+// with adminKey, a client certificate signed by the CA bundle at clientCA,
+// or both.
+func (c *ServeCommand) newAdminServer(srv *serve.Server, port, adminKey, clientCA string, loadOpts []inference.Option) (*http.Server, error) {
+	adminOpts := []serve.AdminOption{
+		serve.WithAdminModelLoader(func(_ context.Context, id string) (*inference.Model, error) {
+			return c.loadFn(id, loadOpts...)
+		}),
+	}
+	if adminKey != "" {
+		adminOpts = append(adminOpts, serve.WithAdminAPIKey(adminKey))
+	}
+	var tlsConfig *tls.Config
+	if clientCA != "" {
+		pem, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, fmt.Errorf("read --admin-client-ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("--admin-client-ca %s: no PEM certificates found", clientCA)
+		}
+		// With an admin key also configured, certificates are optional so
+		// key-authenticated clients can connect.
+		clientAuth := tls.RequireAndVerifyClientCert
+		if adminKey != "" {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+		tlsConfig = &tls.Config{ClientAuth: clientAuth, ClientCAs: pool, MinVersion: tls.VersionTLS12}
+		adminOpts = append(adminOpts, serve.WithAdminClientCert())
+	}
+	h, err := srv.AdminHandler(adminOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Server{
+		Addr:      net.JoinHostPort("", port),
+		Handler:   h,
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
 // shutdownAdapter adapts *http.Server to the shutdown.Closer interface.
 type shutdownAdapter struct {
 	srv *http.Server
@@ -251,11 +487,42 @@ OPTIONS:
   --tls-cert <path>   Path to TLS certificate file (requires --tls-key)
   --tls-key <path>    Path to TLS private key file (requires --tls-cert)
   --pjrt <path>       Path to PJRT plugin .so for accelerator backend
+  --prompt-cache <blocks>
+                      Reuse KV state of shared prompt prefixes across requests,
+                      caching up to this many 16-token blocks (LRU; default: off)
+  --quota-rps <n>     Per-client request rate limit in requests/second
+  --quota-burst <n>   Per-client burst size (default: ceil of --quota-rps)
+  --quota-tokens-per-day <n>
+                      Per-client generated-token budget per UTC day
+                      (clients are API keys; without auth, one shared quota)
+  --request-log <path>
+                      Write a JSON line per request to path ("-" for stdout)
+  --request-log-sample <rate>
+                      Fraction of requests, 0-1, whose payloads are logged (default: 0)
+  --request-log-redact <fields>
+                      Comma-separated JSON fields to redact from logged payloads
+                      (e.g. messages.content,prompt)
+  --admin-port <port> Serve the admin API on a separate port
+  --admin-api-key <key>
+                      Bearer token for the admin API; must differ from --api-key
+                      (env: ZERFOO_ADMIN_API_KEY)
+  --admin-client-ca <path>
+                      Accept admin clients with certificates signed by this CA
+                      (mTLS; requires --tls-cert and --tls-key)
 
 ENDPOINTS:
   POST /v1/chat/completions   Chat completion
   POST /v1/completions        Text completion
-  GET  /v1/models             Model info`
+  GET  /v1/models             Model info
+
+ADMIN ENDPOINTS (on --admin-port):
+  GET  /admin/v1/status         Model, drain state, in-flight requests
+  POST /admin/v1/models/load    Load and swap in a model: {"model": "<id>"}
+  POST /admin/v1/models/unload  Unload the current model
+  POST /admin/v1/drain          Reject new inference requests: {"enabled": true}
+  GET  /admin/v1/traffic        A/B traffic split (when configured)
+  PUT  /admin/v1/traffic        Update the split: {"challenger_weight": 0.1}
+  GET  /admin/v1/metrics        Metrics snapshot`
 }
 
 // Examples implements Command.Examples.
@@ -265,6 +532,9 @@ func (c *ServeCommand) Examples() []string {
 		"serve google/gemma-3-1b --port 9090",
 		"serve google/gemma-3-1b --gpus 0,1,2,3",
 		"serve google/gemma-3-1b --pjrt /usr/lib/pjrt_cpu.so",
+		"serve google/gemma-3-1b --prompt-cache 4096",
+		"serve google/gemma-3-1b --request-log requests.jsonl --request-log-sample 0.01 --request-log-redact messages.content,prompt",
+		"serve google/gemma-3-1b --api-key $KEY --admin-port 9091 --admin-api-key $ADMIN_KEY",
 	}
 }
 
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/serve/security"
 	"github.com/zerfoo/zerfoo/serve/shutdown"
 )
 
@@ -70,6 +75,14 @@ func TestServeCommand_FlagParsing(t *testing.T) {
 		{"port missing value", []string{"--port"}, "--port requires a value"},
 		{"cache-dir missing value", []string{"--cache-dir"}, "--cache-dir requires a value"},
 		{"unexpected arg", []string{"model1", "model2"}, "unexpected argument"},
+		{"kv-window missing value", []string{"--kv-window"}, "--kv-window requires a value"},
+		{"kv-window invalid", []string{"--kv-window", "-4", "m"}, "invalid --kv-window"},
+		{"kv-sinks invalid", []string{"--kv-sinks", "x", "m"}, "invalid --kv-sinks"},
+		{"kv-sinks without window", []string{"--kv-sinks", "4", "m"}, "--kv-sinks requires --kv-window"},
+		{"prompt-cache missing value", []string{"--prompt-cache"}, "--prompt-cache requires a value"},
+		{"prompt-cache invalid", []string{"--prompt-cache", "-1", "m"}, "invalid --prompt-cache"},
+		{"prompt-cache with kv-window", []string{"--allow-no-auth", "--prompt-cache", "64", "--kv-window", "512", "m"}, "cannot be combined with --kv-window"},
+		{"prompt-cache with pjrt", []string{"--allow-no-auth", "--prompt-cache", "64", "--pjrt", "p.so", "m"}, "cannot be combined with --pjrt"},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -89,6 +102,34 @@ func TestServeCommand_FlagParsing(t *testing.T) {
 	}
 }
 
+func TestServeCommand_KVWindowPassesLoadOption(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewServeCommand(nil, &out)
+	var gotOpts int
+	cmd.loadFn = func(_ string, opts ...inference.Option) (*inference.Model, error) {
+		gotOpts = len(opts)
+		return nil, errors.New("load failed")
+	}
+	_ = cmd.Run(context.Background(), []string{"--allow-no-auth", "--kv-window", "1024", "--kv-sinks", "4", "test-model"})
+	if gotOpts != 1 {
+		t.Errorf("load options = %d, want 1", gotOpts)
+	}
+}
+
+func TestServeCommand_PromptCachePassesLoadOption(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewServeCommand(nil, &out)
+	var gotOpts int
+	cmd.loadFn = func(_ string, opts ...inference.Option) (*inference.Model, error) {
+		gotOpts = len(opts)
+		return nil, errors.New("load failed")
+	}
+	_ = cmd.Run(context.Background(), []string{"--allow-no-auth", "--prompt-cache", "256", "test-model"})
+	if gotOpts != 1 {
+		t.Errorf("load options = %d, want 1", gotOpts)
+	}
+}
+
 func TestServeCommand_WithCoordinator(t *testing.T) {
 	// Verify the command registers with the coordinator.
 	coord := shutdown.New()
@@ -129,6 +170,22 @@ func TestServeCommand_StartsAndStopsOnCancel(t *testing.T) {
 	}
 }
 
+func TestServeCommand_ReportsInterrupt(t *testing.T) {
+	mdl := buildCLITestModel(t)
+	cmd := NewServeCommand(nil, &bytes.Buffer{})
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return mdl, nil
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(&InterruptError{Signal: syscall.SIGTERM})
+	err := cmd.Run(ctx, []string{"--port", "0", "--allow-no-auth", "test-model"})
+	var ie *InterruptError
+	if !errors.As(err, &ie) || ie.Signal != syscall.SIGTERM {
+		t.Fatalf("Run() = %v, want SIGTERM InterruptError after draining", err)
+	}
+}
+
 func TestServeCommand_CustomPort(t *testing.T) {
 	mdl := buildCLITestModel(t)
 	var out bytes.Buffer
@@ -308,6 +365,111 @@ func TestServeCommand_TLSFlags(t *testing.T) {
 	}
 }
 
+func TestServeCommand_AdminFlags(t *testing.T) {
+	t.Setenv("ZERFOO_ADMIN_API_KEY", "")
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"admin-port missing value", []string{"--admin-port"}, "--admin-port requires a value"},
+		{"key without port", []string{"--admin-api-key", "k", "test-model"}, "require --admin-port"},
+		{"port without auth", []string{"--admin-port", "0", "test-model"}, "--admin-port requires --admin-api-key"},
+		{"client ca without tls", []string{"--admin-port", "0", "--admin-client-ca", "ca.pem", "test-model"}, "--admin-client-ca requires --tls-cert"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewServeCommand(nil, &bytes.Buffer{})
+			cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+				return nil, errors.New("should not be called")
+			}
+			err := cmd.Run(context.Background(), tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestServeCommand_AdminListenErrorStopsMainServer(t *testing.T) {
+	mdl := buildCLITestModel(t)
+	cmd := NewServeCommand(nil, &bytes.Buffer{})
+	cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+		return mdl, nil
+	}
+
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = busy.Close() }()
+	free, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strconv.Itoa(free.Addr().(*net.TCPAddr).Port)
+	_ = free.Close()
+	adminPort := strconv.Itoa(busy.Addr().(*net.TCPAddr).Port)
+
+	err = cmd.Run(context.Background(), []string{
+		"--port", port, "--allow-no-auth",
+		"--admin-port", adminPort, "--admin-api-key", "k", "test-model",
+	})
+	if err == nil {
+		t.Fatal("Run with the admin port in use: expected error")
+	}
+
+	// The main server must not outlive Run: give a listener that was still
+	// starting time to come up, then check nothing accepts on its port.
+	time.Sleep(200 * time.Millisecond)
+	if conn, err := net.Dial("tcp", net.JoinHostPort("localhost", port)); err == nil {
+		_ = conn.Close()
+		t.Fatal("main server still listening after Run returned")
+	}
+}
+
+func TestServeCommand_RequestLogFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"sample out of range", []string{"--request-log-sample", "2"}, "must be between 0 and 1"},
+		{"sample not a number", []string{"--request-log-sample", "x"}, "must be between 0 and 1"},
+		{"sample without log", []string{"--request-log-sample", "0.5", "test-model"}, "require --request-log"},
+		{"redact without log", []string{"--request-log-redact", "prompt", "test-model"}, "require --request-log"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewServeCommand(nil, &bytes.Buffer{})
+			cmd.loadFn = func(_ string, _ ...inference.Option) (*inference.Model, error) {
+				return nil, errors.New("should not be called")
+			}
+			err := cmd.Run(context.Background(), tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v, want to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseQuotaFlag(t *testing.T) {
+	var q security.Quota
+	for _, kv := range [][2]string{{"--quota-rps", "2.5"}, {"--quota-burst", "5"}, {"--quota-tokens-per-day", "100000"}} {
+		if err := parseQuotaFlag(&q, kv[0], kv[1]); err != nil {
+			t.Fatalf("%s %s: %v", kv[0], kv[1], err)
+		}
+	}
+	if q != (security.Quota{RequestsPerSecond: 2.5, Burst: 5, TokensPerDay: 100000}) {
+		t.Errorf("quota = %+v", q)
+	}
+	for _, kv := range [][2]string{{"--quota-rps", "0"}, {"--quota-burst", "1.5"}, {"--quota-tokens-per-day", "-1"}} {
+		if err := parseQuotaFlag(&q, kv[0], kv[1]); err == nil {
+			t.Errorf("%s %s: expected error", kv[0], kv[1])
+		}
+	}
+}
+
 func TestServeCommand_TLSFlagsParsed(t *testing.T) {
 	// Verify both flags are accepted together (will fail at TLS load, not at flag parsing).
 	mdl := buildCLITestModel(t)
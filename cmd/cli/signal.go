@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,27 +10,67 @@ import (
 	"github.com/zerfoo/zerfoo/serve/shutdown"
 )
 
+// InterruptError is the cancellation cause of a SignalContext context
+// canceled by a signal. Commands return it after cleaning up so the process
+// exits with ExitCode's signal status rather than success.
+type InterruptError struct {
+	Signal os.Signal
+}
+
+func (e *InterruptError) Error() string {
+	return "interrupted by " + e.Signal.String()
+}
+
 // SignalContext returns a context that is canceled when SIGINT or SIGTERM
-// is received. If a non-nil shutdown.Coordinator is provided, its Shutdown
-// method is called before the context is canceled. The returned cancel
-// function should be deferred by the caller to release signal resources.
+// is received, with an *InterruptError as its cause. If a non-nil
+// shutdown.Coordinator is provided, its Shutdown method is called before the
+// context is canceled. Signal handling stops at the first signal, so a second
+// one terminates the process immediately. The returned cancel function
+// should be deferred by the caller to release signal resources.
 func SignalContext(parent context.Context, coord *shutdown.Coordinator) (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithCancel(parent)
+	ctx, cancel := context.WithCancelCause(parent)
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		select {
-		case <-sigCh:
+		case sig := <-sigCh:
+			signal.Stop(sigCh)
 			if coord != nil {
 				_ = coord.Shutdown(ctx)
 			}
-			cancel()
+			cancel(&InterruptError{Signal: sig})
 		case <-ctx.Done():
+			signal.Stop(sigCh)
 		}
-		signal.Stop(sigCh)
 	}()
 
-	return ctx, cancel
+	return ctx, func() { cancel(nil) }
+}
+
+// interruptCause returns the *InterruptError that canceled ctx, or nil if
+// ctx was not canceled by a signal.
+func interruptCause(ctx context.Context) error {
+	var ie *InterruptError
+	if errors.As(context.Cause(ctx), &ie) {
+		return ie
+	}
+	return nil
+}
+
+// ExitCode returns the process exit status for an error returned by a
+// command: 0 for nil, 128 plus the signal number for an *InterruptError
+// (130 for SIGINT, 143 for SIGTERM, as shells report them), and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ie *InterruptError
+	if errors.As(err, &ie) {
+		if sig, ok := ie.Signal.(syscall.Signal); ok {
+			return 128 + int(sig)
+		}
+	}
+	return 1
 }
@@ -2,6 +2,8 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"syscall"
 	"testing"
@@ -45,6 +47,43 @@ func TestSignalContext_CancelOnSIGINT(t *testing.T) {
 	}
 }
 
+func TestSignalContext_CauseIsInterruptError(t *testing.T) {
+	ctx, cancel := SignalContext(context.Background(), nil)
+	defer cancel()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("context was not canceled after SIGTERM")
+	}
+	err := interruptCause(ctx)
+	if err == nil || ExitCode(err) != 143 {
+		t.Errorf("interruptCause = %v, exit code %d; want SIGTERM, 143", err, ExitCode(err))
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), 1},
+		{context.Canceled, 1},
+		{&InterruptError{Signal: syscall.SIGINT}, 130},
+		{fmt.Errorf("serve: %w", &InterruptError{Signal: syscall.SIGTERM}), 143},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
 func TestSignalContext_NilCoordinator(t *testing.T) {
 	ctx, cancel := SignalContext(context.Background(), nil)
 	defer cancel()
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SetupTracing installs a global OpenTelemetry TracerProvider that exports
+// the spans of CLI commands, serving, training, and collectives over
+// OTLP/gRPC, and the W3C trace-context propagator. Tracing is on only when
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set
+// and OTEL_SDK_DISABLED is not "true"; the exporter, sampler, and resource
+// honor the other standard OTEL_* variables (headers, TLS, OTEL_SERVICE_NAME,
+// OTEL_TRACES_SAMPLER, ...). service names the service unless
+// OTEL_SERVICE_NAME overrides it.
+//
+// The returned func flushes buffered spans and shuts the provider down; it
+// is a no-op when tracing is off.
+func SetupTracing(ctx context.Context, service, version string) (func(context.Context) error, error) {
+	return setupTracing(ctx, service, version, os.Getenv)
+}
+
+func setupTracing(ctx context.Context, service, version string, getenv func(string) string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if getenv("OTEL_SDK_DISABLED") == "true" ||
+		(getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "") {
+		return noop, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+	// Detectors later in the list win, so OTEL_SERVICE_NAME and
+	// OTEL_RESOURCE_ATTRIBUTES override the defaults given here.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", service),
+			attribute.String("service.version", version),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return noop, fmt.Errorf("build trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSetupTracing(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+
+	shutdown, err := setupTracing(context.Background(), "zerfoo", "test", getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+		t.Fatal("provider installed without an OTLP endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("no-op shutdown: %v", err)
+	}
+
+	env["OTEL_EXPORTER_OTLP_ENDPOINT"] = "http://127.0.0.1:4317"
+	env["OTEL_SDK_DISABLED"] = "true"
+	if _, err := setupTracing(context.Background(), "zerfoo", "test", getenv); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+		t.Fatal("provider installed with OTEL_SDK_DISABLED=true")
+	}
+
+	delete(env, "OTEL_SDK_DISABLED")
+	shutdown, err = setupTracing(context.Background(), "zerfoo", "test", getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); !ok {
+		t.Fatal("no SDK provider installed with an OTLP endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
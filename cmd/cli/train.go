@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/zerfoo/zerfoo/distributed/coordinator"
 	"github.com/zerfoo/zerfoo/distributed/fsdp"
 	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/presets"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
 )
@@ -28,6 +30,8 @@ func NewTrainCommand(out io.Writer) *TrainCommand {
 
 // trainConfig holds parsed train command flags.
 type trainConfig struct {
+	preset     string
+	seed       uint64
 	modelPath  string
 	dataPath   string
 	worldSize  int
@@ -38,6 +42,11 @@ type trainConfig struct {
 	epochs     int
 	batchSize  int
 	lr         float64
+
+	// epochsSet and lrSet record an explicit --epochs or --lr, which
+	// override a preset's own settings.
+	epochsSet bool
+	lrSet     bool
 }
 
 // Name implements Command.Name.
@@ -54,6 +63,9 @@ func (c *TrainCommand) Run(ctx context.Context, args []string) error {
 	if err != nil {
 		return err
 	}
+	if cfg.preset != "" {
+		return c.runPreset(ctx, cfg)
+	}
 
 	fmt.Fprintf(c.out, "train: rank=%d world-size=%d master=%s:%d\n",
 		cfg.rank, cfg.worldSize, cfg.masterAddr, cfg.masterPort)
@@ -72,13 +84,18 @@ func (c *TrainCommand) Run(ctx context.Context, args []string) error {
 
 // Usage implements Command.Usage.
 func (c *TrainCommand) Usage() string {
-	return `train [OPTIONS]
+	return fmt.Sprintf(`train [OPTIONS]
 
 Train a model locally or distributed across multiple GPUs.
 
+Alternatively, --preset trains one of the built-in example models on its
+own synthetic data, in a single process (presets: %s).
+
 OPTIONS:
-  --config <path>        Path to GGUF model file (required)
-  --data <path>          Path to training data (required)
+  --config <path>        Path to GGUF model file (required without --preset)
+  --data <path>          Path to training data (required without --preset)
+  --preset <name>        Train a built-in example model instead
+  --seed <n>             Preset weight and data seed (default: 1)
   --output <path>        Checkpoint output path (default: checkpoint.gguf)
   --world-size <n>       Number of GPUs / processes (default: 1)
   --rank <n>             Process rank, 0 = coordinator (default: 0)
@@ -86,7 +103,7 @@ OPTIONS:
   --master-port <port>   Coordinator port (default: 29500)
   --epochs <n>           Number of training epochs (default: 1)
   --batch-size <n>       Batch size (default: 4)
-  --lr <float>           Learning rate (default: 1e-4)`
+  --lr <float>           Learning rate (default: 1e-4, or the preset's)`, strings.Join(presets.Names(), ", "))
 }
 
 // Examples implements Command.Examples.
@@ -95,6 +112,7 @@ func (c *TrainCommand) Examples() []string {
 		"train --config model.gguf --data train.jsonl",
 		"train --config model.gguf --data train.jsonl --epochs 3 --batch-size 8 --lr 5e-5",
 		"train --config model.gguf --data train.jsonl --world-size 2 --rank 0",
+		"train --preset tiny-lm --output tiny-lm.gguf",
 	}
 }
 
@@ -105,6 +123,7 @@ func (c *TrainCommand) parseArgs(args []string) (*trainConfig, error) {
 		masterAddr: "localhost",
 		masterPort: 29500,
 		outputPath: "checkpoint.gguf",
+		seed:       1,
 		epochs:     1,
 		batchSize:  4,
 		lr:         1e-4,
@@ -130,6 +149,22 @@ func (c *TrainCommand) parseArgs(args []string) (*trainConfig, error) {
 			return args[i], nil
 		}
 		switch arg {
+		case "--preset":
+			v, err := nextVal("--preset")
+			if err != nil {
+				return nil, err
+			}
+			cfg.preset = v
+		case "--seed":
+			v, err := nextVal("--seed")
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("--seed must be a non-negative integer")
+			}
+			cfg.seed = n
 		case "--config":
 			v, err := nextVal("--config")
 			if err != nil {
@@ -194,6 +229,7 @@ func (c *TrainCommand) parseArgs(args []string) (*trainConfig, error) {
 				return nil, fmt.Errorf("--epochs must be >= 1")
 			}
 			cfg.epochs = n
+			cfg.epochsSet = true
 		case "--batch-size":
 			v, err := nextVal("--batch-size")
 			if err != nil {
@@ -214,11 +250,21 @@ func (c *TrainCommand) parseArgs(args []string) (*trainConfig, error) {
 				return nil, fmt.Errorf("--lr must be a positive number")
 			}
 			cfg.lr = f
+			cfg.lrSet = true
 		default:
 			return nil, fmt.Errorf("unknown flag: %s", arg)
 		}
 	}
 
+	if cfg.preset != "" {
+		if cfg.modelPath != "" || cfg.dataPath != "" {
+			return nil, fmt.Errorf("--preset cannot be combined with --config or --data")
+		}
+		if cfg.worldSize != 1 {
+			return nil, fmt.Errorf("--preset trains in a single process; --world-size must be 1")
+		}
+		return cfg, nil
+	}
 	if cfg.modelPath == "" {
 		return nil, fmt.Errorf("--config is required")
 	}
@@ -232,6 +278,49 @@ func (c *TrainCommand) parseArgs(args []string) (*trainConfig, error) {
 	return cfg, nil
 }
 
+// runPreset builds the named preset, trains it with the standard workflow,
+// and saves the trained model to the output path.
+func (c *TrainCommand) runPreset(ctx context.Context, cfg *trainConfig) error {
+	p, err := presets.Get(cfg.preset)
+	if err != nil {
+		return err
+	}
+	run, err := p.Build(ctx, cfg.seed)
+	if err != nil {
+		return fmt.Errorf("build preset %s: %w", p.Name, err)
+	}
+	if cfg.epochsSet {
+		run.Config.NumEpochs = cfg.epochs
+	}
+	if cfg.lrSet {
+		run.Config.LearningRate = cfg.lr
+	}
+
+	fmt.Fprintf(c.out, "train: preset=%s seed=%d output=%s\n", p.Name, cfg.seed, cfg.outputPath)
+	fmt.Fprintf(c.out, "  %s\n", p.Description)
+	fmt.Fprintf(c.out, "  epochs=%d lr=%.1e\n", run.Config.NumEpochs, run.Config.LearningRate)
+
+	res, err := run.Train(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return interruptCause(ctx)
+		}
+		return fmt.Errorf("train preset %s: %w", p.Name, err)
+	}
+	fmt.Fprintf(c.out, "epochs=%d final-loss=%.6f best-loss=%.6f (epoch %d) time=%.1fs\n",
+		res.TotalEpochs, res.FinalLoss, res.BestLoss, res.BestEpoch, res.TrainingTime)
+
+	model, err := run.Models.CreateModel(ctx, run.Config.ModelConfig)
+	if err != nil {
+		return err
+	}
+	if err := run.Models.SaveModel(ctx, model, cfg.outputPath); err != nil {
+		return fmt.Errorf("save model: %w", err)
+	}
+	fmt.Fprintf(c.out, "checkpoint saved to %s\n", cfg.outputPath)
+	return nil
+}
+
 // trainModel implements training.Model[float32] for the FSDP trainer.
 type trainModel struct {
 	params []*graph.Parameter[float32]
@@ -313,13 +402,20 @@ func (c *TrainCommand) trainLoop(ctx context.Context, cfg *trainConfig) error {
 	}
 
 	step := 0
+	var lastLoss float32
 	start := time.Now()
 	for epoch := 0; epoch < cfg.epochs; epoch++ {
 		for batch := 0; batch < paramSize/cfg.batchSize; batch++ {
 			select {
 			case <-ctx.Done():
-				fmt.Fprintf(c.out, "interrupted at epoch=%d step=%d\n", epoch+1, step+1)
-				return nil
+				// Save the progress so far, so a preempted run can resume
+				// from it, then report the interruption to the caller.
+				fmt.Fprintf(c.out, "interrupted at epoch=%d step=%d/%d loss=%.6f\n",
+					epoch+1, step, totalSteps, lastLoss)
+				if err := c.saveCheckpoint(cfg, sharded); err != nil {
+					return err
+				}
+				return interruptCause(ctx)
 			default:
 			}
 
@@ -342,6 +438,7 @@ func (c *TrainCommand) trainLoop(ctx context.Context, cfg *trainConfig) error {
 				loss += v * v
 			}
 			loss /= float32(cfg.batchSize)
+			lastLoss = loss
 
 			gradData := make([]float32, paramSize)
 			for i := range gradData {
@@ -365,13 +462,18 @@ func (c *TrainCommand) trainLoop(ctx context.Context, cfg *trainConfig) error {
 		}
 	}
 
-	if cfg.rank == 0 {
-		if err := fsdp.SaveCheckpoint(cfg.outputPath, sharded, cfg.rank); err != nil {
-			return fmt.Errorf("save checkpoint: %w", err)
-		}
-		fmt.Fprintf(c.out, "checkpoint saved to %s\n", cfg.outputPath)
-	}
+	return c.saveCheckpoint(cfg, sharded)
+}
 
+// saveCheckpoint writes the model to the output path from rank 0.
+func (c *TrainCommand) saveCheckpoint(cfg *trainConfig, sharded *fsdp.ShardedModule[float32]) error {
+	if cfg.rank != 0 {
+		return nil
+	}
+	if err := fsdp.SaveCheckpoint(cfg.outputPath, sharded, cfg.rank); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	fmt.Fprintf(c.out, "checkpoint saved to %s\n", cfg.outputPath)
 	return nil
 }
 
@@ -3,7 +3,10 @@ package cli
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 )
 
@@ -184,6 +187,28 @@ func TestTrainCommand_LocalRun(t *testing.T) {
 	}
 }
 
+func TestTrainCommand_InterruptSavesCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(&InterruptError{Signal: syscall.SIGTERM})
+
+	var buf bytes.Buffer
+	output := filepath.Join(t.TempDir(), "ckpt.gguf")
+	err := NewTrainCommand(&buf).Run(ctx, []string{
+		"--config", "model.gguf",
+		"--data", "train.jsonl",
+		"--output", output,
+	})
+	if ExitCode(err) != 143 {
+		t.Fatalf("Run() = %v, want SIGTERM interrupt", err)
+	}
+	if !strings.Contains(buf.String(), "interrupted at epoch=1 step=0/") {
+		t.Errorf("output = %q, want interruption report", buf.String())
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("no checkpoint after interrupt: %v", err)
+	}
+}
+
 func TestTrainCommand_Defaults(t *testing.T) {
 	cmd := NewTrainCommand(&bytes.Buffer{})
 	cfg, err := cmd.parseArgs([]string{"--config", "m.gguf", "--data", "d.jsonl"})
@@ -215,3 +240,36 @@ func TestTrainCommand_Defaults(t *testing.T) {
 		t.Errorf("lr = %v, want 1e-4", cfg.lr)
 	}
 }
+
+func TestTrainCommand_Preset(t *testing.T) {
+	var buf bytes.Buffer
+	output := filepath.Join(t.TempDir(), "tiny-mlp.gguf")
+	err := NewTrainCommand(&buf).Run(context.Background(), []string{
+		"--preset", "tiny-mlp",
+		"--epochs", "2",
+		"--output", output,
+	})
+	if err != nil {
+		t.Fatalf("preset run failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"preset=tiny-mlp", "epochs=2 final-loss=", "checkpoint saved"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want to contain %q", out, want)
+		}
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("no model saved: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--preset", "nope"},
+		{"--preset", "tiny-mlp", "--data", "d.jsonl"},
+		{"--preset", "tiny-mlp", "--world-size", "2"},
+		{"--preset", "tiny-mlp", "--seed", "-1"},
+	} {
+		if err := NewTrainCommand(&bytes.Buffer{}).Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) succeeded, want error", args)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/zerfoo/zerfoo/layers/activations"
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// TuneCommand implements the "tune" CLI command, a home for training
+// hyperparameter tools. Its only subcommand today is "lr-find".
+type TuneCommand struct {
+	out io.Writer
+}
+
+// NewTuneCommand creates a new TuneCommand.
+func NewTuneCommand(out io.Writer) *TuneCommand {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &TuneCommand{out: out}
+}
+
+// Name implements Command.Name.
+func (c *TuneCommand) Name() string { return "tune" }
+
+// Description implements Command.Description.
+func (c *TuneCommand) Description() string {
+	return "Training hyperparameter tools (lr-find)"
+}
+
+// Run implements Command.Run.
+func (c *TuneCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("tune: subcommand required (lr-find)")
+	}
+	switch args[0] {
+	case "lr-find":
+		return c.runLRFind(ctx, args[1:])
+	default:
+		return fmt.Errorf("tune: unknown subcommand %q (want lr-find)", args[0])
+	}
+}
+
+// lrFindConfig holds parsed lr-find flags.
+type lrFindConfig struct {
+	dataPath  string
+	output    string
+	hidden    int
+	batchSize int
+	seed      uint64
+	finder    training.LRFinderConfig
+}
+
+func parseLRFindArgs(args []string) (*lrFindConfig, error) {
+	cfg := &lrFindConfig{
+		output:    "lr_find.csv",
+		hidden:    64,
+		batchSize: 32,
+		seed:      42,
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+		positiveInt := func(flagName string) (int, error) {
+			v, err := nextVal(flagName)
+			if err != nil {
+				return 0, err
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s must be >= 1", flagName)
+			}
+			return n, nil
+		}
+		positiveFloat := func(flagName string) (float64, error) {
+			v, err := nextVal(flagName)
+			if err != nil {
+				return 0, err
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f <= 0 {
+				return 0, fmt.Errorf("%s must be a positive number", flagName)
+			}
+			return f, nil
+		}
+
+		var err error
+		switch arg {
+		case "--data":
+			cfg.dataPath, err = nextVal("--data")
+		case "--output", "-o":
+			cfg.output, err = nextVal("--output")
+		case "--hidden":
+			cfg.hidden, err = positiveInt("--hidden")
+		case "--batch-size":
+			cfg.batchSize, err = positiveInt("--batch-size")
+		case "--steps":
+			cfg.finder.Steps, err = positiveInt("--steps")
+		case "--min-lr":
+			cfg.finder.MinLR, err = positiveFloat("--min-lr")
+		case "--max-lr":
+			cfg.finder.MaxLR, err = positiveFloat("--max-lr")
+		case "--seed":
+			var v string
+			if v, err = nextVal("--seed"); err == nil {
+				cfg.seed, err = strconv.ParseUint(v, 10, 64)
+				if err != nil {
+					err = errors.New("--seed must be a non-negative integer")
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown flag: %s", arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.dataPath == "" {
+		return nil, errors.New("--data is required")
+	}
+	return cfg, nil
+}
+
+// runLRFind sweeps the learning rate for an MLP classifier on a tabular CSV
+// dataset and writes the loss curve as CSV.
+func (c *TuneCommand) runLRFind(ctx context.Context, args []string) error {
+	cfg, err := parseLRFindArgs(args)
+	if err != nil {
+		return err
+	}
+	features, labels, err := readTabularCSV(cfg.dataPath)
+	if err != nil {
+		return err
+	}
+	numClasses := 0
+	for i, l := range labels {
+		if l < 0 {
+			return fmt.Errorf("row %d: label %d must be non-negative", i+1, l)
+		}
+		numClasses = max(numClasses, l+1)
+	}
+	if numClasses < 2 {
+		return errors.New("dataset needs at least two classes")
+	}
+	numFeatures := len(features[0])
+
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	g, input, err := buildLRFindMLP(engine, numFeatures, cfg.hidden, numClasses, cfg.seed)
+	if err != nil {
+		return fmt.Errorf("build model: %w", err)
+	}
+	opt := optimizer.NewAdamWFromFloat64[float32](engine, 1e-3, 0.9, 0.999, 1e-8, 0)
+	trainer := training.NewDefaultTrainer[float32](g, loss.NewCrossEntropyLossOneHot[float32](engine), opt, nil)
+
+	rng := rand.New(rand.NewPCG(cfg.seed, 1))
+	order := rng.Perm(len(features))
+	batchSize := min(cfg.batchSize, len(features))
+	pos := 0
+	next := func(int) (*training.Batch[float32], error) {
+		x := make([]float32, 0, batchSize*numFeatures)
+		y := make([]float32, batchSize*numClasses)
+		for r := range batchSize {
+			if pos == len(order) {
+				rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+				pos = 0
+			}
+			idx := order[pos]
+			pos++
+			for _, v := range features[idx] {
+				x = append(x, float32(v))
+			}
+			y[r*numClasses+labels[idx]] = 1
+		}
+		xt, err := tensor.New[float32]([]int{batchSize, numFeatures}, x)
+		if err != nil {
+			return nil, err
+		}
+		yt, err := tensor.New[float32]([]int{batchSize, numClasses}, y)
+		if err != nil {
+			return nil, err
+		}
+		return &training.Batch[float32]{
+			Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: xt},
+			Targets: yt,
+		}, nil
+	}
+
+	res, err := training.LRRangeTest(ctx, cfg.finder, trainer, g, opt, next)
+	if err != nil {
+		return err
+	}
+
+	if cfg.output == "-" {
+		if err := res.WriteCSV(c.out); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	} else {
+		f, err := os.Create(filepath.Clean(cfg.output))
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		if err := res.WriteCSV(f); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("write csv: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+		_, _ = fmt.Fprintf(c.out, "wrote %d points to %s\n", len(res.Points), cfg.output)
+	}
+	status := "completed"
+	if res.Diverged {
+		status = "stopped at divergence"
+	}
+	_, _ = fmt.Fprintf(c.out, "sweep %s after %d steps\n", status, len(res.Points))
+	_, _ = fmt.Fprintf(c.out, "suggested max lr: %.3g (min loss at %.3g)\n", res.SuggestedLR, res.MinLossLR)
+	return nil
+}
+
+// buildLRFindMLP builds a features -> hidden -> ReLU -> classes classifier
+// with seeded uniform initialization.
+func buildLRFindMLP(engine compute.Engine[float32], in, hidden, classes int, seed uint64) (*graph.Graph[float32], graph.Node[float32], error) {
+	ops := numeric.Float32Ops{}
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, in})
+	d1, err := core.NewDense[float32]("lrfind_hidden", engine, ops, in, hidden)
+	if err != nil {
+		return nil, nil, err
+	}
+	d2, err := core.NewDense[float32]("lrfind_out", engine, ops, hidden, classes)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := b.AddNode(d1, input)
+	h = b.AddNode(activations.NewReLU[float32](engine, ops), h)
+	g, err := b.Build(b.AddNode(d2, h))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	for _, p := range g.Parameters() {
+		shape := p.Value.Shape()
+		data := p.Value.Data()
+		if len(shape) < 2 {
+			clear(data)
+			continue
+		}
+		limit := 1 / float64(shape[0])
+		for i := range data {
+			data[i] = float32((rng.Float64()*2 - 1) * limit)
+		}
+	}
+	return g, input, nil
+}
+
+// Usage implements Command.Usage.
+func (c *TuneCommand) Usage() string {
+	return `tune lr-find --data <csv> [OPTIONS]
+
+Run an LR range test: train a small MLP classifier on the dataset while
+increasing the learning rate exponentially each step, record the loss, and
+suggest a maximum learning rate. The model is restored afterwards.
+
+The dataset is a CSV with a header row, numeric feature columns, and an
+integer class label in the last column. The output CSV has the columns
+step, lr, loss, smoothed_loss; plot loss against lr on a log axis.
+
+OPTIONS:
+  --data <path>        Training CSV (required)
+  --output, -o <file>  Output CSV, or - for stdout (default: lr_find.csv)
+  --min-lr <float>     Starting learning rate (default: 1e-7)
+  --max-lr <float>     Final learning rate (default: 10)
+  --steps <n>          Number of sweep steps (default: 200)
+  --batch-size <n>     Batch size (default: 32)
+  --hidden <n>         Hidden layer width (default: 64)
+  --seed <n>           Random seed for initialization and batching (default: 42)`
+}
+
+// Examples implements Command.Examples.
+func (c *TuneCommand) Examples() []string {
+	return []string{
+		"tune lr-find --data train.csv",
+		"tune lr-find --data train.csv --min-lr 1e-6 --max-lr 1 --steps 300 -o sweep.csv",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*TuneCommand)(nil)
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeBlobsCSV writes a two-class, two-feature dataset of separated blobs.
+func writeBlobsCSV(t *testing.T) string {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(1, 2))
+	var sb strings.Builder
+	sb.WriteString("x1,x2,label\n")
+	for i := range 64 {
+		label := i % 2
+		center := float64(2*label - 1)
+		fmt.Fprintf(&sb, "%g,%g,%d\n", center+0.3*rng.NormFloat64(), center+0.3*rng.NormFloat64(), label)
+	}
+	path := filepath.Join(t.TempDir(), "blobs.csv")
+	if err := os.WriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTuneCommand_Metadata(t *testing.T) {
+	cmd := NewTuneCommand(nil)
+	if cmd.Name() != "tune" {
+		t.Errorf("Name() = %q, want %q", cmd.Name(), "tune")
+	}
+	if cmd.Description() == "" {
+		t.Error("Description() should not be empty")
+	}
+	if !strings.Contains(cmd.Usage(), "lr-find") {
+		t.Error("Usage() should document lr-find")
+	}
+	if len(cmd.Examples()) == 0 {
+		t.Error("Examples() should not be empty")
+	}
+}
+
+func TestTuneCommand_ArgErrors(t *testing.T) {
+	data := writeBlobsCSV(t)
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no subcommand", nil},
+		{"unknown subcommand", []string{"batch-find"}},
+		{"missing data", []string{"lr-find"}},
+		{"unknown flag", []string{"lr-find", "--data", data, "--epochs", "3"}},
+		{"bad steps", []string{"lr-find", "--data", data, "--steps", "0"}},
+		{"bad min lr", []string{"lr-find", "--data", data, "--min-lr", "-1"}},
+		{"min above max", []string{"lr-find", "--data", data, "--min-lr", "1", "--max-lr", "0.1"}},
+		{"missing value", []string{"lr-find", "--data"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewTuneCommand(&buf).Run(context.Background(), tt.args); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestTuneCommand_LRFind(t *testing.T) {
+	data := writeBlobsCSV(t)
+	out := filepath.Join(t.TempDir(), "sweep.csv")
+	var buf bytes.Buffer
+	err := NewTuneCommand(&buf).Run(context.Background(), []string{
+		"lr-find", "--data", data, "--output", out,
+		"--steps=60", "--min-lr", "1e-5", "--max-lr", "10", "--hidden", "8", "--batch-size", "16",
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(buf.String(), "suggested max lr:") {
+		t.Errorf("output missing suggestion:\n%s", buf.String())
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rows[0], ","); got != "step,lr,loss,smoothed_loss" {
+		t.Errorf("header = %q", got)
+	}
+	if len(rows) < 11 || len(rows) > 61 {
+		t.Errorf("got %d data rows, want between 10 and 60", len(rows)-1)
+	}
+}
+
+func TestTuneCommand_LRFindStdout(t *testing.T) {
+	data := writeBlobsCSV(t)
+	var buf bytes.Buffer
+	err := NewTuneCommand(&buf).Run(context.Background(), []string{
+		"lr-find", "--data", data, "-o", "-", "--steps", "20", "--hidden", "4",
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "step,lr,loss,smoothed_loss\n") {
+		t.Errorf("stdout should start with the CSV header, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+	"github.com/zerfoo/zerfoo/model/vocab"
+	tokenizer "github.com/zerfoo/ztoken"
+)
+
+// VocabCommand implements the "vocab" CLI command, which measures how a
+// corpus uses a model's vocabulary and prunes the tokens it does not need.
+type VocabCommand struct {
+	out io.Writer
+}
+
+// NewVocabCommand creates a new VocabCommand.
+func NewVocabCommand(out io.Writer) *VocabCommand {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &VocabCommand{out: out}
+}
+
+// Name implements Command.Name.
+func (c *VocabCommand) Name() string { return "vocab" }
+
+// Description implements Command.Description.
+func (c *VocabCommand) Description() string {
+	return "Analyze vocabulary usage over a corpus and prune unused tokens (analyze, prune)"
+}
+
+// vocabConfig holds parsed vocab flags.
+type vocabConfig struct {
+	model    string
+	corpora  []string
+	output   string
+	top      int
+	minCount int64
+	maxVocab int
+	json     bool
+}
+
+// vocabAnalysis is the JSON form of "vocab analyze".
+type vocabAnalysis struct {
+	VocabSize int                `json:"vocab_size"`
+	Documents int                `json:"documents"`
+	Tokens    int64              `json:"tokens"`
+	Used      int                `json:"used"`
+	Coverage  map[string]int     `json:"coverage"`
+	Top       []vocab.TokenCount `json:"top"`
+}
+
+// coverageLevels are the occurrence shares analyze reports the vocabulary
+// size for.
+var coverageLevels = []float64{0.9, 0.99, 0.999}
+
+// Run implements Command.Run.
+func (c *VocabCommand) Run(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("vocab: subcommand required (analyze, prune)")
+	}
+	sub := args[0]
+	if sub != "analyze" && sub != "prune" {
+		return fmt.Errorf("vocab: unknown subcommand %q (want analyze or prune)", sub)
+	}
+	cfg, err := parseVocabArgs(args[1:])
+	if err != nil {
+		return err
+	}
+	if cfg.model == "" {
+		return fmt.Errorf("vocab %s: model path required", sub)
+	}
+	if len(cfg.corpora) == 0 {
+		return fmt.Errorf("vocab %s: --corpus required", sub)
+	}
+	if sub == "prune" && cfg.output == "" {
+		return errors.New("vocab prune: --output required")
+	}
+
+	src, err := os.Open(filepath.Clean(cfg.model))
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint:errcheck
+	f, err := gguf.Parse(src)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", cfg.model, err)
+	}
+	tok, err := gguf.ExtractTokenizer(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cfg.model, err)
+	}
+	freq := vocab.NewFrequency(tok.VocabSize())
+	for _, path := range cfg.corpora {
+		if err := countCorpusFile(tok, path, freq); err != nil {
+			return err
+		}
+	}
+
+	if sub == "analyze" {
+		return c.analyze(tok, freq, cfg)
+	}
+	return c.prune(src, f, freq, cfg)
+}
+
+func countCorpusFile(tok tokenizer.Tokenizer, path string, freq *vocab.Frequency) error {
+	r, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck
+	if err := vocab.CountCorpus(tok, r, freq); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *VocabCommand) analyze(tok tokenizer.Tokenizer, freq *vocab.Frequency, cfg *vocabConfig) error {
+	ranked := freq.Ranked()
+	top := ranked[:min(cfg.top, len(ranked))]
+	if cfg.json {
+		a := vocabAnalysis{
+			VocabSize: len(freq.Counts),
+			Documents: freq.Documents,
+			Tokens:    freq.Tokens,
+			Used:      freq.Used(),
+			Coverage:  make(map[string]int, len(coverageLevels)),
+			Top:       top,
+		}
+		for _, level := range coverageLevels {
+			a.Coverage[strconv.FormatFloat(level, 'g', -1, 64)] = freq.CoverageSize(level)
+		}
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(a)
+	}
+
+	n := len(freq.Counts)
+	_, _ = fmt.Fprintf(c.out, "%d documents, %d tokens\n", freq.Documents, freq.Tokens)
+	_, _ = fmt.Fprintf(c.out, "Vocabulary: %d tokens, %d used (%.1f%%), %d unused\n",
+		n, freq.Used(), percent(int64(freq.Used()), int64(n)), n-freq.Used())
+	for _, level := range coverageLevels {
+		_, _ = fmt.Fprintf(c.out, "%g%% of occurrences: %d tokens\n", level*100, freq.CoverageSize(level))
+	}
+	if len(top) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RANK\tID\tTOKEN\tCOUNT\tSHARE")
+	for i, tc := range top {
+		s, _ := tok.GetToken(tc.ID)
+		_, _ = fmt.Fprintf(tw, "%d\t%d\t%q\t%d\t%.2f%%\n", i+1, tc.ID, s, tc.Count, percent(tc.Count, freq.Tokens))
+	}
+	return tw.Flush()
+}
+
+func percent(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+func (c *VocabCommand) prune(src *os.File, f *gguf.File, freq *vocab.Frequency, cfg *vocabConfig) (err error) {
+	plan, err := vocab.NewPlan(f, freq, vocab.Options{MinCount: cfg.minCount, MaxVocab: cfg.maxVocab})
+	if err != nil {
+		return err
+	}
+	dst, err := os.Create(filepath.Clean(cfg.output))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(cfg.output)
+		}
+	}()
+	res, err := vocab.Prune(src, dst, f, plan)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.out, "Vocabulary: %d -> %d tokens (%d required)\n", res.OldSize, res.NewSize, plan.Required)
+	for _, name := range res.Tensors {
+		_, _ = fmt.Fprintf(c.out, "Remapped %s\n", name)
+	}
+	_, _ = fmt.Fprintf(c.out, "Wrote %s\n", cfg.output)
+	return nil
+}
+
+func parseVocabArgs(args []string) (*vocabConfig, error) {
+	cfg := &vocabConfig{top: 20}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var eqVal string
+		var hasEq bool
+		if flag, val, ok := splitFlag(arg); ok {
+			arg = flag
+			eqVal = val
+			hasEq = true
+		}
+		nextVal := func(flagName string) (string, error) {
+			if hasEq {
+				return eqVal, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		var v string
+		switch arg {
+		case "--corpus":
+			if v, err = nextVal("--corpus"); err == nil {
+				cfg.corpora = append(cfg.corpora, v)
+			}
+		case "--output", "-o":
+			cfg.output, err = nextVal(arg)
+		case "--top":
+			if v, err = nextVal("--top"); err == nil {
+				cfg.top, err = parsePositiveInt(v)
+			}
+		case "--min-count":
+			if v, err = nextVal("--min-count"); err == nil {
+				var n int
+				n, err = parsePositiveInt(v)
+				cfg.minCount = int64(n)
+			}
+		case "--max-vocab":
+			if v, err = nextVal("--max-vocab"); err == nil {
+				cfg.maxVocab, err = parsePositiveInt(v)
+			}
+		case "--json":
+			cfg.json = true
+		default:
+			if len(arg) > 1 && arg[0] == '-' {
+				return nil, fmt.Errorf("unknown flag: %s", arg)
+			}
+			if cfg.model != "" {
+				return nil, fmt.Errorf("unexpected argument: %s", arg)
+			}
+			cfg.model = arg
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// Usage implements Command.Usage.
+func (c *VocabCommand) Usage() string {
+	return `vocab <analyze|prune> <model.gguf> --corpus <file> [OPTIONS]
+
+Tokenize a corpus (one document per line) with a GGUF model's tokenizer
+and report or prune the vocabulary it uses.
+
+analyze reports how many tokens the corpus uses, how many cover 90%, 99%,
+and 99.9% of occurrences, and the most frequent tokens.
+
+prune writes a copy of the model keeping only tokens used at least
+--min-count times (up to --max-vocab tokens, most frequent first), plus
+the special, byte, and single-character tokens and the merge parts every
+kept token needs, so any text still encodes. Dropped tokens encode as
+their parts. The embedding rows and an untied output head are remapped to
+the new IDs; zerfoo.vocab.source_ids records each token's original ID.
+
+OPTIONS:
+  --corpus <file>       Corpus file; repeat for several (required)
+  --output, -o <file>   Pruned model path (prune, required)
+  --min-count <n>       Keep tokens used at least n times (default: 1)
+  --max-vocab <n>       Cap the pruned vocabulary size (default: no cap)
+  --top <n>             Most frequent tokens to list (analyze, default: 20)
+  --json                Print the analysis as JSON (analyze)`
+}
+
+// Examples implements Command.Examples.
+func (c *VocabCommand) Examples() []string {
+	return []string{
+		"vocab analyze model.gguf --corpus support-tickets.txt",
+		"vocab prune model.gguf --corpus support-tickets.txt --min-count 2 -o model-support.gguf",
+		"vocab prune model.gguf --corpus a.txt --corpus b.txt --max-vocab 16000 -o small.gguf",
+	}
+}
+
+// Static interface assertion.
+var _ Command = (*VocabCommand)(nil)
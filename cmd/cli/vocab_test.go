@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+)
+
+// writeVocabModel writes a GGUF model with a five-token BPE vocabulary
+// (a, b, c, ab, bc) and a 2-wide F32 embedding, plus a corpus using ab
+// twice and c once.
+func writeVocabModel(t *testing.T) (model, corpus string) {
+	t.Helper()
+	dir := t.TempDir()
+	tokens := []any{"a", "b", "c", "ab", "bc"}
+	embd := make([]byte, 4*2*len(tokens))
+	var buf bytes.Buffer
+	err := gguf.Write(&buf, map[string]any{
+		"general.architecture":  "llama",
+		"tokenizer.ggml.tokens": tokens,
+		"tokenizer.ggml.merges": []any{"a b", "b c"},
+	}, []gguf.WriterTensor{{
+		Name:       "token_embd.weight",
+		Dimensions: []uint64{2, uint64(len(tokens))},
+		Type:       gguf.GGMLTypeF32,
+		Size:       int64(len(embd)),
+		WriteData: func(w io.Writer) error {
+			_, err := w.Write(embd)
+			return err
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model = filepath.Join(dir, "model.gguf")
+	corpus = filepath.Join(dir, "corpus.txt")
+	if err := os.WriteFile(model, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(corpus, []byte("ab\nab\nc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return model, corpus
+}
+
+func TestVocabCommand_Analyze(t *testing.T) {
+	model, corpus := writeVocabModel(t)
+	var out strings.Builder
+	cmd := NewVocabCommand(&out)
+	if err := cmd.Run(context.Background(), []string{"analyze", model, "--corpus", corpus}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"3 documents, 3 tokens", "5 tokens, 2 used", `"ab"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("analyze output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := cmd.Run(context.Background(), []string{"analyze", model, "--corpus=" + corpus, "--json", "--top", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	var a vocabAnalysis
+	if err := json.Unmarshal([]byte(out.String()), &a); err != nil {
+		t.Fatalf("--json output: %v\n%s", err, out.String())
+	}
+	if a.Used != 2 || len(a.Top) != 1 || a.Top[0].ID != 3 || a.Coverage["0.9"] != 2 {
+		t.Errorf("analysis = %+v", a)
+	}
+}
+
+func TestVocabCommand_Prune(t *testing.T) {
+	model, corpus := writeVocabModel(t)
+	output := filepath.Join(t.TempDir(), "pruned.gguf")
+	var out strings.Builder
+	cmd := NewVocabCommand(&out)
+	if err := cmd.Run(context.Background(), []string{"prune", model, "--corpus", corpus, "-o", output}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "5 -> 4 tokens") || !strings.Contains(out.String(), "Remapped token_embd.weight") {
+		t.Errorf("prune output:\n%s", out.String())
+	}
+	r, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close() //nolint:errcheck
+	f, err := gguf.Parse(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Metadata["tokenizer.ggml.tokens"].([]any); len(got) != 4 || got[3] != "ab" {
+		t.Errorf("pruned tokens = %v, want [a b c ab]", got)
+	}
+}
+
+func TestVocabCommand_Errors(t *testing.T) {
+	model, corpus := writeVocabModel(t)
+	cmd := NewVocabCommand(io.Discard)
+	out := filepath.Join(t.TempDir(), "out.gguf")
+	for _, args := range [][]string{
+		{},
+		{"bogus", model, "--corpus", corpus},
+		{"analyze", "--corpus", corpus},
+		{"analyze", model},
+		{"prune", model, "--corpus", corpus},
+		{"prune", model, "--corpus", corpus, "-o", out, "--max-vocab", "2"},
+		{"analyze", model, "--corpus", corpus, "--top", "0"},
+		{"analyze", model, model, "--corpus", corpus},
+		{"analyze", model, "--corpus", filepath.Join(t.TempDir(), "absent.txt")},
+		{"analyze", model, "--corpus", corpus, "--bogus"},
+	} {
+		if err := cmd.Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) succeeded, want error", args)
+		}
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("failed prune left %s behind", out)
+	}
+}
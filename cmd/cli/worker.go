@@ -4,10 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"github.com/zerfoo/zerfoo/serve/health"
 	"github.com/zerfoo/zerfoo/serve/shutdown"
+	"github.com/zerfoo/ztensor/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // workerNode is the subset of *distributed.WorkerNode that WorkerCommand
@@ -17,6 +27,7 @@ import (
 type workerNode interface {
 	Start(ctx context.Context) error
 	Close(ctx context.Context) error
+	Drained() <-chan struct{}
 }
 
 // WorkerCommand implements the "worker" CLI command for starting a
@@ -27,6 +38,14 @@ type WorkerCommand struct {
 	// newWorkerNode constructs the worker node from its config. Defaults to
 	// wrapping distributed.NewWorkerNode; overridable in tests.
 	newWorkerNode func(distributed.WorkerNodeConfig) workerNode
+
+	// dialWorker connects to a running worker for "worker drain". Defaults
+	// to dialWorkerService; overridable in tests.
+	dialWorker func(addr string, tls *distributed.TLSConfig) (pb.DistributedServiceClient, io.Closer, error)
+
+	// lookupEnv reads the ZERFOO_* bootstrap variables. Defaults to
+	// os.LookupEnv; overridable in tests.
+	lookupEnv func(string) (string, bool)
 }
 
 // NewWorkerCommand creates a new WorkerCommand. The shutdown coordinator
@@ -37,6 +56,8 @@ func NewWorkerCommand(coord *shutdown.Coordinator) *WorkerCommand {
 		newWorkerNode: func(cfg distributed.WorkerNodeConfig) workerNode {
 			return distributed.NewWorkerNode(cfg)
 		},
+		dialWorker: dialWorkerService,
+		lookupEnv:  os.LookupEnv,
 	}
 }
 
@@ -48,12 +69,26 @@ func (c *WorkerCommand) Description() string {
 	return "Start a distributed training worker"
 }
 
-// Run implements Command.Run. It parses flags, creates a WorkerNode,
-// starts it, and blocks until the context is canceled (e.g. by SIGTERM).
+// Run implements Command.Run. It reads settings from the environment and
+// flags (flags win), creates a WorkerNode, starts it, and blocks until the context is canceled (e.g. by SIGTERM) or
+// the worker is drained. "worker drain" instead asks a running worker to
+// drain.
 func (c *WorkerCommand) Run(ctx context.Context, args []string) error {
-	var coordAddr, workerAddr, workerID string
-	var tlsCert, tlsKey, tlsCA string
-	var worldSize int
+	if len(args) > 0 && args[0] == "drain" {
+		return c.runDrain(ctx, args[1:])
+	}
+
+	cfg, err := readWorkerEnv(c.lookupEnv)
+	if err != nil {
+		return err
+	}
+	var resources *pb.ResourceHints
+	hints := func() *pb.ResourceHints {
+		if resources == nil {
+			resources = &pb.ResourceHints{}
+		}
+		return resources
+	}
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -61,19 +96,19 @@ func (c *WorkerCommand) Run(ctx context.Context, args []string) error {
 			if i+1 >= len(args) {
 				return errors.New("--coordinator-address requires a value")
 			}
-			coordAddr = args[i+1]
+			cfg.coordAddr = args[i+1]
 			i++
 		case "--worker-address":
 			if i+1 >= len(args) {
 				return errors.New("--worker-address requires a value")
 			}
-			workerAddr = args[i+1]
+			cfg.workerAddr = args[i+1]
 			i++
 		case "--worker-id":
 			if i+1 >= len(args) {
 				return errors.New("--worker-id requires a value")
 			}
-			workerID = args[i+1]
+			cfg.workerID = args[i+1]
 			i++
 		case "--world-size":
 			if i+1 >= len(args) {
@@ -83,58 +118,115 @@ func (c *WorkerCommand) Run(ctx context.Context, args []string) error {
 			if err != nil {
 				return fmt.Errorf("--world-size: %w", err)
 			}
-			worldSize = n
+			cfg.worldSize = n
+			i++
+		case "--rank":
+			if i+1 >= len(args) {
+				return errors.New("--rank requires a value")
+			}
+			n, err := parseRank(args[i+1])
+			if err != nil {
+				return fmt.Errorf("--rank: %w", err)
+			}
+			cfg.rank = &n
+			i++
+		case "--health-address":
+			if i+1 >= len(args) {
+				return errors.New("--health-address requires a value")
+			}
+			cfg.healthAddr = args[i+1]
+			i++
+		case "--namespace":
+			if i+1 >= len(args) {
+				return errors.New("--namespace requires a value")
+			}
+			cfg.namespace = args[i+1]
+			i++
+		case "--job-id":
+			if i+1 >= len(args) {
+				return errors.New("--job-id requires a value")
+			}
+			cfg.jobID = args[i+1]
+			i++
+		case "--gpus", "--memory-gb", "--label":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			if err := parseResourceFlag(hints(), args[i], args[i+1]); err != nil {
+				return err
+			}
 			i++
 		case "--tls-cert":
 			if i+1 >= len(args) {
 				return errors.New("--tls-cert requires a value")
 			}
-			tlsCert = args[i+1]
+			cfg.tlsCert = args[i+1]
 			i++
 		case "--tls-key":
 			if i+1 >= len(args) {
 				return errors.New("--tls-key requires a value")
 			}
-			tlsKey = args[i+1]
+			cfg.tlsKey = args[i+1]
 			i++
 		case "--tls-ca":
 			if i+1 >= len(args) {
 				return errors.New("--tls-ca requires a value")
 			}
-			tlsCA = args[i+1]
+			cfg.tlsCA = args[i+1]
 			i++
 		default:
 			return fmt.Errorf("unknown flag: %s", args[i])
 		}
 	}
 
-	if coordAddr == "" {
-		return errors.New("--coordinator-address is required")
+	if cfg.coordAddr == "" {
+		return errors.New("--coordinator-address (or ZERFOO_COORDINATOR_ADDRESS) is required")
 	}
-	if workerAddr == "" {
-		return errors.New("--worker-address is required")
+	if cfg.workerAddr == "" {
+		return errors.New("--worker-address (or ZERFOO_WORKER_ADDRESS or POD_IP) is required")
 	}
-	if workerID == "" {
+	if cfg.workerID == "" {
 		hostname, err := os.Hostname()
 		if err != nil {
-			workerID = workerAddr
+			cfg.workerID = cfg.workerAddr
 		} else {
-			workerID = hostname
+			cfg.workerID = hostname
 		}
 	}
 	// workerID is available for future use (e.g. distinct worker IDs).
-	_ = workerID
+	_ = cfg.workerID
 
-	tlsConfig, err := buildTLSConfig(tlsCert, tlsKey, tlsCA)
+	tlsConfig, err := buildTLSConfig(cfg.tlsCert, cfg.tlsKey, cfg.tlsCA)
 	if err != nil {
 		return err
 	}
 
+	// The health server listens before the node starts so probes see
+	// "not ready" rather than "connection refused" while the worker
+	// registers with the coordinator.
+	var healthServer *health.Server
+	if cfg.healthAddr != "" {
+		healthServer = health.NewServer(log.Nop())
+		httpServer, err := c.serveHealth(cfg.healthAddr, healthServer)
+		if err != nil {
+			return err
+		}
+		defer httpServer.Close() //nolint:errcheck
+		if c.shutdownCoord != nil {
+			c.shutdownCoord.Register(shutdownAdapter{httpServer})
+		}
+	}
+
 	node := c.newWorkerNode(distributed.WorkerNodeConfig{
-		WorkerAddress:      workerAddr,
-		CoordinatorAddress: coordAddr,
-		WorldSize:          worldSize,
+		WorkerAddress:      cfg.workerAddr,
+		CoordinatorAddress: cfg.coordAddr,
+		WorldSize:          cfg.worldSize,
+		HealthServer:       healthServer,
+		RankHint:           cfg.rank,
 		TLS:                tlsConfig,
+		Namespace:          cfg.namespace,
+		JobID:              cfg.jobID,
+		Resources:          resources,
 	})
 
 	if err := node.Start(ctx); err != nil {
@@ -145,17 +237,118 @@ func (c *WorkerCommand) Run(ctx context.Context, args []string) error {
 		c.shutdownCoord.Register(node)
 	}
 
-	// Block until the context is canceled (signal received).
-	<-ctx.Done()
+	// Block until the context is canceled (signal received) or the worker
+	// has been drained and deregistered.
+	select {
+	case <-ctx.Done():
+	case <-node.Drained():
+		return node.Close(context.WithoutCancel(ctx))
+	}
+	return nil
+}
+
+// serveHealth starts an HTTP server for hs's /healthz and /readyz on addr.
+func (c *WorkerCommand) serveHealth(addr string, hs *health.Server) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("health server: %w", err)
+	}
+	srv := &http.Server{
+		Handler:           hs.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = srv.Serve(ln) }()
+	return srv, nil
+}
+
+// runDrain implements "worker drain": it asks the worker at
+// --worker-address to finish its current step, hand off its state, and
+// deregister, and waits until it has.
+func (c *WorkerCommand) runDrain(ctx context.Context, args []string) error {
+	var workerAddr, reason string
+	var tlsCert, tlsKey, tlsCA string
+	timeout := 10 * time.Minute
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			return fmt.Errorf("%s requires a value", args[i])
+		}
+		v := args[i+1]
+		switch args[i] {
+		case "--worker-address":
+			workerAddr = v
+		case "--reason":
+			reason = v
+		case "--timeout":
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("--timeout: invalid duration %q", v)
+			}
+			timeout = d
+		case "--tls-cert":
+			tlsCert = v
+		case "--tls-key":
+			tlsKey = v
+		case "--tls-ca":
+			tlsCA = v
+		default:
+			return fmt.Errorf("unknown flag: %s", args[i])
+		}
+		i++
+	}
+	if workerAddr == "" {
+		return errors.New("--worker-address is required")
+	}
+	tlsConfig, err := buildTLSConfig(tlsCert, tlsKey, tlsCA)
+	if err != nil {
+		return err
+	}
+
+	client, conn, err := c.dialWorker(workerAddr, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to worker: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, err := client.Drain(ctx, &pb.DrainRequest{Reason: reason}); err != nil {
+		return fmt.Errorf("drain %s: %w", workerAddr, err)
+	}
 	return nil
 }
 
+// dialWorkerService connects to the worker at addr, over mutual TLS when
+// tls is set.
+func dialWorkerService(addr string, tls *distributed.TLSConfig) (pb.DistributedServiceClient, io.Closer, error) {
+	creds := insecure.NewCredentials()
+	if tls != nil {
+		var err error
+		if creds, err = tls.ClientCredentials(); err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS client credentials: %w", err)
+		}
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), distributed.TracingDialOption())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pb.NewDistributedServiceClient(conn), conn, nil
+}
+
 // Usage implements Command.Usage.
 func (c *WorkerCommand) Usage() string {
 	return `worker [OPTIONS]
+worker drain --worker-address <addr> [--reason <text>] [--timeout <dur>]
 
 Start a distributed training worker.
 
+The worker serves the standard gRPC health protocol (grpc.health.v1) on
+--worker-address: it reports SERVING once it has joined its job and
+NOT_SERVING while draining. "worker drain" asks a running worker to finish
+its current step, hand off its state, and deregister from the coordinator,
+for rolling maintenance; the drained worker process then exits. The drain
+subcommand accepts the same --tls-* flags to reach a TLS worker, and waits
+up to --timeout (default 10m).
+
 Binding --worker-address to anything other than a loopback address
 (127.0.0.0/8, ::1, or "localhost") requires TLS -- see worker_node.go's
 isLoopback / Start for the enforced contract. Single-host development can
@@ -168,6 +361,21 @@ OPTIONS:
                                  default example: 127.0.0.1:9001)
   --worker-id <id>              Worker identifier (default: hostname)
   --world-size <n>              Total number of workers (default: auto)
+  --rank <n>                    Expected rank, e.g. the StatefulSet ordinal.
+                                 Ranks are assigned in registration order;
+                                 the worker warns if its rank differs.
+  --health-address <addr>       Serve HTTP /healthz and /readyz on <addr>.
+                                 /readyz fails until the worker has
+                                 registered with the coordinator.
+  --namespace <ns>              Namespace of the job to join (default:
+                                 "default")
+  --job-id <id>                 Job to join (default: "default"). If the
+                                 job was submitted with "jobs submit", the
+                                 worker waits until the job is dispatched.
+  --gpus <n>                    GPUs this worker offers to submitted jobs
+  --memory-gb <n>               Memory in GB this worker offers
+  --label <key=value>           Label matched against job resource hints
+                                 (repeatable)
   --tls-cert <path>             PEM certificate for this worker's gRPC
                                  server (and its outbound mTLS dial to the
                                  coordinator). Requires --tls-key and
@@ -180,6 +388,31 @@ OPTIONS:
                                  outbound dial). Requires --tls-cert and
                                  --tls-key.
 
+ENVIRONMENT:
+  The options below can instead be set from the environment; flags take
+  precedence. This lets a Kubernetes StatefulSet configure workers
+  directly from its pod spec and the downward API.
+
+  ZERFOO_COORDINATOR_ADDRESS    --coordinator-address
+  ZERFOO_WORKER_ADDRESS         --worker-address; if unset and POD_IP is
+                                 set, POD_IP:ZERFOO_WORKER_PORT (default
+                                 port 9001)
+  ZERFOO_WORKER_ID              --worker-id; falls back to POD_NAME
+  ZERFOO_WORLD_SIZE             --world-size
+  ZERFOO_RANK                   --rank; falls back to the ordinal suffix
+                                 of POD_NAME ("trainer-2" is rank 2)
+  ZERFOO_NAMESPACE              --namespace
+  ZERFOO_JOB_ID                 --job-id
+  ZERFOO_TLS_CERT, ZERFOO_TLS_KEY, ZERFOO_TLS_CA
+                                --tls-cert, --tls-key, --tls-ca (paths,
+                                 e.g. to a mounted secret)
+  ZERFOO_HEALTH_ADDRESS         --health-address
+
+  Point the pod's readiness probe at /readyz and keep the StatefulSet's
+  default OrderedReady pod management: each pod then starts only after the
+  previous one has registered, so registration order -- and therefore
+  rank -- follows the pod ordinals.
+
 Generating development certificates:
 
   A production deployment should issue certificates from a real CA. For
@@ -217,6 +450,9 @@ func (c *WorkerCommand) Examples() []string {
 	return []string{
 		`worker --coordinator-address 127.0.0.1:9000 --worker-address 127.0.0.1:9001`,
 		`worker --coordinator-address 10.0.0.1:9000 --worker-address 0.0.0.0:9001 --world-size 4 --tls-cert worker-cert.pem --tls-key worker-key.pem --tls-ca ca.pem`,
+		`worker --coordinator-address 127.0.0.1:9000 --worker-address 127.0.0.1:9001 --job-id llm-ft --gpus 8 --label zone=us-east`,
+		`ZERFOO_COORDINATOR_ADDRESS=coordinator:9000 POD_NAME=trainer-0 POD_IP=10.0.0.5 worker --health-address :8080 --tls-cert /tls/tls.crt --tls-key /tls/tls.key --tls-ca /tls/ca.crt`,
+		`worker drain --worker-address 127.0.0.1:9001 --reason "kernel upgrade"`,
 	}
 }
 
@@ -242,6 +478,34 @@ func buildTLSConfig(certPath, keyPath, caPath string) (*distributed.TLSConfig, e
 	}
 }
 
+// parseResourceFlag applies one of --gpus, --memory-gb, or --label to r.
+func parseResourceFlag(r *pb.ResourceHints, flag, value string) error {
+	switch flag {
+	case "--gpus":
+		n, err := parsePositiveInt(value)
+		if err != nil {
+			return fmt.Errorf("--gpus: %w", err)
+		}
+		r.Gpus = int32(n) // #nosec G115 - GPU counts are small
+	case "--memory-gb":
+		n, err := parsePositiveInt(value)
+		if err != nil {
+			return fmt.Errorf("--memory-gb: %w", err)
+		}
+		r.MemoryBytes = int64(n) << 30
+	case "--label":
+		k, v, ok := strings.Cut(value, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("--label must be key=value, got %q", value)
+		}
+		if r.Labels == nil {
+			r.Labels = make(map[string]string)
+		}
+		r.Labels[k] = v
+	}
+	return nil
+}
+
 // parsePositiveInt parses a string as a positive integer.
 func parsePositiveInt(s string) (int, error) {
 	var n int
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// defaultWorkerPort is the worker gRPC port used with POD_IP when
+// ZERFOO_WORKER_PORT is unset.
+const defaultWorkerPort = "9001"
+
+// workerSettings holds the worker command's settings. readWorkerEnv fills it
+// from the environment and Run's flag parsing overrides individual fields.
+type workerSettings struct {
+	coordAddr, workerAddr, workerID string
+	namespace, jobID                string
+	tlsCert, tlsKey, tlsCA          string
+	healthAddr                      string
+	worldSize                       int
+	rank                            *int
+}
+
+// readWorkerEnv reads worker settings from the environment so a pod spec
+// can configure the worker without wrapper scripts:
+//
+//	ZERFOO_COORDINATOR_ADDRESS  coordinator gRPC address
+//	ZERFOO_WORKER_ADDRESS       worker listen address; otherwise
+//	                            POD_IP:ZERFOO_WORKER_PORT (default port 9001)
+//	ZERFOO_WORKER_ID            worker identifier; otherwise POD_NAME
+//	ZERFOO_WORLD_SIZE           total number of workers
+//	ZERFOO_RANK                 rank hint; otherwise the StatefulSet ordinal
+//	                            suffix of POD_NAME
+//	ZERFOO_NAMESPACE            job namespace
+//	ZERFOO_JOB_ID               job to join
+//	ZERFOO_TLS_CERT, ZERFOO_TLS_KEY, ZERFOO_TLS_CA
+//	                            PEM paths, e.g. from a mounted secret
+//	ZERFOO_HEALTH_ADDRESS       HTTP /healthz and /readyz listen address
+//
+// POD_NAME and POD_IP are the names the downward API is conventionally
+// mapped to.
+func readWorkerEnv(lookup func(string) (string, bool)) (workerSettings, error) {
+	get := func(key string) string {
+		v, _ := lookup(key)
+		return strings.TrimSpace(v)
+	}
+
+	s := workerSettings{
+		coordAddr:  get("ZERFOO_COORDINATOR_ADDRESS"),
+		workerAddr: get("ZERFOO_WORKER_ADDRESS"),
+		workerID:   get("ZERFOO_WORKER_ID"),
+		namespace:  get("ZERFOO_NAMESPACE"),
+		jobID:      get("ZERFOO_JOB_ID"),
+		tlsCert:    get("ZERFOO_TLS_CERT"),
+		tlsKey:     get("ZERFOO_TLS_KEY"),
+		tlsCA:      get("ZERFOO_TLS_CA"),
+		healthAddr: get("ZERFOO_HEALTH_ADDRESS"),
+	}
+
+	podName := get("POD_NAME")
+	if s.workerID == "" {
+		s.workerID = podName
+	}
+	if s.workerAddr == "" {
+		if ip := get("POD_IP"); ip != "" {
+			port := get("ZERFOO_WORKER_PORT")
+			if port == "" {
+				port = defaultWorkerPort
+			}
+			s.workerAddr = net.JoinHostPort(ip, port)
+		}
+	}
+
+	if v := get("ZERFOO_WORLD_SIZE"); v != "" {
+		n, err := parsePositiveInt(v)
+		if err != nil {
+			return s, fmt.Errorf("ZERFOO_WORLD_SIZE: %w", err)
+		}
+		s.worldSize = n
+	}
+
+	if v := get("ZERFOO_RANK"); v != "" {
+		n, err := parseRank(v)
+		if err != nil {
+			return s, fmt.Errorf("ZERFOO_RANK: %w", err)
+		}
+		s.rank = &n
+	} else if n, ok := statefulSetOrdinal(podName); ok {
+		s.rank = &n
+	}
+	return s, nil
+}
+
+// parseRank parses a non-negative rank.
+func parseRank(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rank: %s", s)
+	}
+	return n, nil
+}
+
+// statefulSetOrdinal returns the ordinal a StatefulSet appends to its pod
+// names ("trainer-3" is ordinal 3).
+func statefulSetOrdinal(podName string) (int, bool) {
+	i := strings.LastIndexByte(podName, '-')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := parseRank(podName[i+1:])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
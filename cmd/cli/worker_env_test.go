@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/distributed"
+)
+
+// envMap returns a lookupEnv that reads from m.
+func envMap(m map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+func TestReadWorkerEnv(t *testing.T) {
+	s, err := readWorkerEnv(envMap(map[string]string{
+		"ZERFOO_COORDINATOR_ADDRESS": "coordinator:9000",
+		"ZERFOO_WORLD_SIZE":          "4",
+		"ZERFOO_JOB_ID":              "llm-ft",
+		"ZERFOO_TLS_CERT":            "/tls/tls.crt",
+		"POD_NAME":                   "trainer-2",
+		"POD_IP":                     "10.0.0.5",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.coordAddr != "coordinator:9000" || s.worldSize != 4 || s.jobID != "llm-ft" || s.tlsCert != "/tls/tls.crt" {
+		t.Errorf("settings = %+v", s)
+	}
+	if s.workerAddr != "10.0.0.5:9001" || s.workerID != "trainer-2" {
+		t.Errorf("workerAddr, workerID = %q, %q", s.workerAddr, s.workerID)
+	}
+	if s.rank == nil || *s.rank != 2 {
+		t.Errorf("rank = %v, want the POD_NAME ordinal 2", s.rank)
+	}
+
+	// Explicit variables win over the downward API ones.
+	s, err = readWorkerEnv(envMap(map[string]string{
+		"ZERFOO_WORKER_ADDRESS": "0.0.0.0:7000",
+		"ZERFOO_WORKER_ID":      "w",
+		"ZERFOO_RANK":           "0",
+		"POD_NAME":              "trainer-2",
+		"POD_IP":                "10.0.0.5",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.workerAddr != "0.0.0.0:7000" || s.workerID != "w" || s.rank == nil || *s.rank != 0 {
+		t.Errorf("settings = %+v, rank %v", s, s.rank)
+	}
+
+	s, err = readWorkerEnv(envMap(map[string]string{"POD_IP": "fd00::5", "ZERFOO_WORKER_PORT": "7000", "POD_NAME": "trainer"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.workerAddr != "[fd00::5]:7000" || s.rank != nil {
+		t.Errorf("workerAddr = %q, rank = %v", s.workerAddr, s.rank)
+	}
+
+	for _, env := range []map[string]string{
+		{"ZERFOO_WORLD_SIZE": "0"},
+		{"ZERFOO_RANK": "-1"},
+		{"ZERFOO_RANK": "two"},
+	} {
+		if _, err := readWorkerEnv(envMap(env)); err == nil {
+			t.Errorf("readWorkerEnv(%v) should fail", env)
+		}
+	}
+}
+
+func TestStatefulSetOrdinal(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+		ok   bool
+	}{
+		{"trainer-0", 0, true},
+		{"zerfoo-worker-12", 12, true},
+		{"trainer", 0, false},
+		{"trainer-", 0, false},
+		{"trainer-abc", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := statefulSetOrdinal(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("statefulSetOrdinal(%q) = %d, %v, want %d, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWorkerCommand_Run_EnvBootstrap(t *testing.T) {
+	var gotCfg distributed.WorkerNodeConfig
+	cmd := NewWorkerCommand(nil)
+	cmd.lookupEnv = envMap(map[string]string{
+		"ZERFOO_COORDINATOR_ADDRESS": "127.0.0.1:9000",
+		"ZERFOO_WORKER_ADDRESS":      "127.0.0.1:9001",
+		"ZERFOO_WORLD_SIZE":          "2",
+		"ZERFOO_NAMESPACE":           "ml",
+		"POD_NAME":                   "trainer-1",
+	})
+	cmd.newWorkerNode = func(cfg distributed.WorkerNodeConfig) workerNode {
+		gotCfg = cfg
+		return fakeWorkerNode{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Flags override the environment.
+	if err := cmd.Run(ctx, []string{"--namespace", "research", "--rank", "0"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if gotCfg.CoordinatorAddress != "127.0.0.1:9000" || gotCfg.WorkerAddress != "127.0.0.1:9001" || gotCfg.WorldSize != 2 {
+		t.Errorf("config from env = %+v", gotCfg)
+	}
+	if gotCfg.Namespace != "research" {
+		t.Errorf("Namespace = %q, want the --namespace value", gotCfg.Namespace)
+	}
+	if gotCfg.RankHint == nil || *gotCfg.RankHint != 0 {
+		t.Errorf("RankHint = %v, want the --rank value 0", gotCfg.RankHint)
+	}
+	if gotCfg.HealthServer != nil {
+		t.Error("HealthServer set without a health address")
+	}
+
+	cmd.lookupEnv = envMap(map[string]string{"ZERFOO_RANK": "x"})
+	if err := cmd.Run(ctx, nil); err == nil || !strings.Contains(err.Error(), "ZERFOO_RANK") {
+		t.Errorf("Run() error = %v, want a ZERFOO_RANK error", err)
+	}
+}
+
+func TestWorkerCommand_Run_HealthAddress(t *testing.T) {
+	addr := freeAddr(t)
+	cmd := NewWorkerCommand(nil)
+	cmd.lookupEnv = envMap(nil)
+
+	var status int
+	cmd.newWorkerNode = func(cfg distributed.WorkerNodeConfig) workerNode {
+		if cfg.HealthServer == nil {
+			t.Fatal("HealthServer not set from --health-address")
+		}
+		cfg.HealthServer.AddReadinessCheck("test", func() error { return fmt.Errorf("registering") })
+		resp, err := http.Get("http://" + addr + "/readyz")
+		if err != nil {
+			t.Fatalf("GET /readyz: %v", err)
+		}
+		_ = resp.Body.Close()
+		status = resp.StatusCode
+		return fakeWorkerNode{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cmd.Run(ctx, []string{
+		"--coordinator-address", "127.0.0.1:9000",
+		"--worker-address", "127.0.0.1:9001",
+		"--health-address", addr,
+	}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("/readyz = %d, want 503 while a check fails", status)
+	}
+	if _, err := http.Get("http://" + addr + "/healthz"); err == nil {
+		t.Error("health server still listening after Run returned")
+	}
+}
+
+// freeAddr returns a loopback address with a port that was free a moment ago.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
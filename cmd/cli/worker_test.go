@@ -2,20 +2,35 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"io"
 	"path/filepath"
 	"testing"
 
 	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc"
 )
 
 // fakeWorkerNode is a workerNode stand-in that records the config it was
 // built with (via the closure in the test) and never touches the network,
 // so tests can assert on distributed.WorkerNodeConfig.TLS without standing
 // up a real gRPC server/coordinator pair.
-type fakeWorkerNode struct{}
+type fakeWorkerNode struct {
+	drained chan struct{}
+	closed  *bool
+}
 
 func (fakeWorkerNode) Start(context.Context) error { return nil }
-func (fakeWorkerNode) Close(context.Context) error { return nil }
+
+func (f fakeWorkerNode) Close(context.Context) error {
+	if f.closed != nil {
+		*f.closed = true
+	}
+	return nil
+}
+
+func (f fakeWorkerNode) Drained() <-chan struct{} { return f.drained }
 
 func TestWorkerCommand_Name(t *testing.T) {
 	cmd := NewWorkerCommand(nil)
@@ -295,3 +310,106 @@ func TestBuildTLSConfig(t *testing.T) {
 func TestWorkerCommand_Interface(t *testing.T) {
 	var _ Command = (*WorkerCommand)(nil)
 }
+
+// TestWorkerCommand_Run_JobFlags checks that --namespace, --job-id, and the
+// resource flags reach WorkerNodeConfig.
+func TestWorkerCommand_Run_JobFlags(t *testing.T) {
+	var gotCfg distributed.WorkerNodeConfig
+	cmd := NewWorkerCommand(nil)
+	cmd.newWorkerNode = func(cfg distributed.WorkerNodeConfig) workerNode {
+		gotCfg = cfg
+		return fakeWorkerNode{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := cmd.Run(ctx, []string{
+		"--coordinator-address", "127.0.0.1:9000",
+		"--worker-address", "127.0.0.1:9001",
+		"--namespace", "ml",
+		"--job-id", "llm-ft",
+		"--gpus", "4",
+		"--memory-gb", "2",
+		"--label", "zone=a",
+	}); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	if gotCfg.Namespace != "ml" || gotCfg.JobID != "llm-ft" {
+		t.Errorf("Namespace, JobID = %q, %q", gotCfg.Namespace, gotCfg.JobID)
+	}
+	r := gotCfg.Resources
+	if r.GetGpus() != 4 || r.GetMemoryBytes() != 2<<30 || r.GetLabels()["zone"] != "a" {
+		t.Errorf("Resources = %v", r)
+	}
+}
+
+func TestWorkerCommand_Run_ExitsWhenDrained(t *testing.T) {
+	var closed bool
+	drained := make(chan struct{})
+	close(drained)
+	cmd := NewWorkerCommand(nil)
+	cmd.newWorkerNode = func(distributed.WorkerNodeConfig) workerNode {
+		return fakeWorkerNode{drained: drained, closed: &closed}
+	}
+
+	if err := cmd.Run(context.Background(), []string{
+		"--coordinator-address", "127.0.0.1:9000",
+		"--worker-address", "127.0.0.1:9001",
+	}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !closed {
+		t.Error("drained worker node was not closed")
+	}
+}
+
+// fakeDrainClient records the Drain request it receives.
+type fakeDrainClient struct {
+	pb.DistributedServiceClient
+	req *pb.DrainRequest
+	err error
+}
+
+func (f *fakeDrainClient) Drain(_ context.Context, req *pb.DrainRequest, _ ...grpc.CallOption) (*pb.DrainResponse, error) {
+	f.req = req
+	return &pb.DrainResponse{}, f.err
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+func TestWorkerCommand_Drain(t *testing.T) {
+	client := &fakeDrainClient{}
+	var gotAddr string
+	cmd := NewWorkerCommand(nil)
+	cmd.dialWorker = func(addr string, _ *distributed.TLSConfig) (pb.DistributedServiceClient, io.Closer, error) {
+		gotAddr = addr
+		return client, nopCloser{}, nil
+	}
+
+	if err := cmd.Run(context.Background(), []string{"drain", "--worker-address", "127.0.0.1:9001", "--reason", "maintenance", "--timeout", "30s"}); err != nil {
+		t.Fatalf("Run(drain) error = %v", err)
+	}
+	if gotAddr != "127.0.0.1:9001" || client.req.GetReason() != "maintenance" {
+		t.Errorf("dialed %q with request %v", gotAddr, client.req)
+	}
+
+	client.err = errors.New("boom")
+	if err := cmd.Run(context.Background(), []string{"drain", "--worker-address", "127.0.0.1:9001"}); err == nil {
+		t.Error("Run(drain) should surface the worker's error")
+	}
+
+	for _, args := range [][]string{
+		{"drain"},
+		{"drain", "--worker-address"},
+		{"drain", "--worker-address", "x", "--timeout", "soon"},
+		{"drain", "--worker-address", "x", "--bogus", "y"},
+	} {
+		if err := cmd.Run(context.Background(), args); err == nil {
+			t.Errorf("Run(%q) should fail", args)
+		}
+	}
+}
@@ -12,10 +12,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"github.com/zerfoo/zerfoo/cmd/cli"
 	"github.com/zerfoo/zerfoo/distributed/coordinator"
 	"github.com/zerfoo/zerfoo/distributed/fsdp"
 	"github.com/zerfoo/zerfoo/training/optimizer"
@@ -45,7 +44,7 @@ func main() {
 			return
 		}
 		fmt.Fprintf(os.Stderr, "train-distributed: %v\n", err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
 
@@ -93,7 +92,7 @@ func run(args []string, stdout, stderr io.Writer) error {
 		return err
 	}
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	ctx, cancel := cli.SignalContext(context.Background(), nil)
 	defer cancel()
 
 	fmt.Fprintf(stdout, "train-distributed: rank=%d world-size=%d master=%s:%d\n",
@@ -188,13 +187,20 @@ func trainLoop(ctx context.Context, cfg *config, out io.Writer) error {
 	}
 
 	step := 0
+	var lastLoss float32
 	start := time.Now()
 	for epoch := 0; epoch < cfg.epochs; epoch++ {
 		for batch := 0; batch < paramSize/cfg.batchSize; batch++ {
 			select {
 			case <-ctx.Done():
-				fmt.Fprintf(out, "interrupted at epoch=%d step=%d\n", epoch+1, step+1)
-				return nil
+				// Checkpoint the progress so far before exiting, so a
+				// preempted run does not lose it.
+				fmt.Fprintf(out, "interrupted at epoch=%d step=%d/%d loss=%.6f\n",
+					epoch+1, step, totalSteps, lastLoss)
+				if err := saveCheckpoint(cfg, sharded, out); err != nil {
+					return err
+				}
+				return context.Cause(ctx)
 			default:
 			}
 
@@ -219,6 +225,7 @@ func trainLoop(ctx context.Context, cfg *config, out io.Writer) error {
 				loss += v * v
 			}
 			loss /= float32(cfg.batchSize)
+			lastLoss = loss
 
 			// Synthetic gradient for backward.
 			gradData := make([]float32, paramSize)
@@ -248,13 +255,17 @@ func trainLoop(ctx context.Context, cfg *config, out io.Writer) error {
 		}
 	}
 
-	// Save checkpoint from rank 0.
-	if cfg.rank == 0 {
-		if err := fsdp.SaveCheckpoint(cfg.outputPath, sharded, cfg.rank); err != nil {
-			return fmt.Errorf("save checkpoint: %w", err)
-		}
-		fmt.Fprintf(out, "checkpoint saved to %s\n", cfg.outputPath)
-	}
+	return saveCheckpoint(cfg, sharded, out)
+}
 
+// saveCheckpoint writes the model to the output path from rank 0.
+func saveCheckpoint(cfg *config, sharded *fsdp.ShardedModule[float32], out io.Writer) error {
+	if cfg.rank != 0 {
+		return nil
+	}
+	if err := fsdp.SaveCheckpoint(cfg.outputPath, sharded, cfg.rank); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	fmt.Fprintf(out, "checkpoint saved to %s\n", cfg.outputPath)
 	return nil
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/zerfoo/model/postprocess"
+	"github.com/zerfoo/zerfoo/tabular"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// registerLoaders registers the model loaders zerfoo-predict ships with:
+// "gguf" for tabular models written by tabular.SaveGGUF.
+func registerLoaders(reg *model.ModelRegistry[float32]) error {
+	return reg.RegisterModelLoader("gguf", func(context.Context, map[string]interface{}) (model.ModelLoader[float32], error) {
+		ops := numeric.Float32Ops{}
+		return &tabularGGUFLoader{engine: compute.NewCPUEngine[float32](ops), ops: ops}, nil
+	})
+}
+
+// tabularGGUFLoader loads tabular GGUF models on a CPU engine.
+type tabularGGUFLoader struct {
+	engine compute.Engine[float32]
+	ops    numeric.Arithmetic[float32]
+}
+
+func (l *tabularGGUFLoader) LoadFromPath(_ context.Context, path string) (model.ModelInstance[float32], error) {
+	m, err := tabular.LoadGGUF(path, l.engine, l.ops)
+	if err != nil {
+		return nil, err
+	}
+	return &tabularInstance{m: m}, nil
+}
+
+func (l *tabularGGUFLoader) LoadFromReader(ctx context.Context, r io.Reader) (model.ModelInstance[float32], error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read model: %w", err)
+	}
+	return l.LoadFromBytes(ctx, data)
+}
+
+func (l *tabularGGUFLoader) LoadFromBytes(_ context.Context, data []byte) (model.ModelInstance[float32], error) {
+	m, err := tabular.ReadGGUF(bytes.NewReader(data), l.engine, l.ops)
+	if err != nil {
+		return nil, err
+	}
+	return &tabularInstance{m: m}, nil
+}
+
+func (l *tabularGGUFLoader) SupportsFormat(format string) bool {
+	return strings.EqualFold(format, "gguf")
+}
+
+func (l *tabularGGUFLoader) GetLoaderInfo() model.LoaderInfo {
+	return model.LoaderInfo{
+		Name:             "gguf",
+		Description:      "Tabular models in GGUF (general.architecture = tabular)",
+		SupportedFormats: []string{"gguf"},
+	}
+}
+
+// tabularInstance serves a tabular.Model for inference: Forward maps a
+// [n, features] batch to [n, 3] class logits.
+type tabularInstance struct {
+	m *tabular.Model
+}
+
+func (t *tabularInstance) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("tabular model takes 1 input, got %d", len(inputs))
+	}
+	return t.m.Forward(ctx, inputs[0])
+}
+
+func (t *tabularInstance) Backward(context.Context, ...*tensor.TensorNumeric[float32]) error {
+	return errors.New("tabular model loaded for inference only")
+}
+
+func (t *tabularInstance) GetGraph() *graph.Graph[float32]         { return nil }
+func (t *tabularInstance) Parameters() []*graph.Parameter[float32] { return nil }
+func (t *tabularInstance) SetTrainingMode(bool)                    {}
+func (t *tabularInstance) IsTraining() bool                        { return false }
+
+func (t *tabularInstance) GetMetadata() model.ModelMetadata {
+	meta := model.ModelMetadata{
+		Architecture: tabular.GGUFArchitecture,
+		Framework:    "zerfoo",
+		InputShape:   [][]int{{-1, t.m.InputDim()}},
+		OutputShape:  []int{-1, 3},
+	}
+	if chain := t.m.Postprocess(); chain != nil {
+		postprocess.SetMetadata(&meta, chain)
+	}
+	return meta
+}
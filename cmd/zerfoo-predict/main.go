@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -24,12 +23,21 @@ type PredictConfig struct {
 	DataPath   string `json:"data_path"`   // Input features data
 	ModelPath  string `json:"model_path"`  // Trained model path
 	OutputPath string `json:"output_path"` // Output predictions path
+	Loader     string `json:"loader"`      // Model loader name (default: model file extension)
 
 	// Prediction options
 	BatchSize    int    `json:"batch_size"`    // Prediction batch size
-	OutputFormat string `json:"output_format"` // "csv", "json", "parquet"
+	OutputFormat string `json:"output_format"` // "csv", "json" or "parquet"
 	IncludeProbs bool   `json:"include_probs"` // Include prediction probabilities
 
+	// Test-time augmentation (see tta.go)
+	TTA          int     `json:"tta"`           // Input variants per batch, including the original; 0 or 1 disables TTA
+	TTAMode      string  `json:"tta_mode"`      // "dropout" or "shift"
+	TTADropout   float64 `json:"tta_dropout"`   // Feature dropout probability in dropout mode
+	TTAMaxShift  int     `json:"tta_max_shift"` // Largest window shift in shift mode
+	TTAAggregate string  `json:"tta_aggregate"` // "mean" or "median"
+	TTASeed      uint64  `json:"tta_seed"`      // Seed of the dropout masks
+
 	// Data processing
 	FeatureColumns []string `json:"feature_columns"` // Specific feature columns to use
 	IDColumn       string   `json:"id_column"`       // ID column name
@@ -56,6 +64,9 @@ type PredictionResult struct {
 }
 
 func main() {
+	if err := registerLoaders(model.Float32ModelRegistry); err != nil {
+		log.Fatalf("Failed to register model loaders: %v", err)
+	}
 	if err := run(os.Args[1:], os.Stdout); err != nil {
 		log.Printf("Prediction failed: %v", err)
 		os.Exit(1)
@@ -97,7 +108,7 @@ func run(args []string, stdout io.Writer) error {
 		savePredictionResult(config, result)
 	}()
 
-	if err := runPrediction(config, result); err != nil {
+	if err := runPrediction(context.Background(), config, result); err != nil {
 		result.ErrorMessage = err.Error()
 		return err
 	}
@@ -133,15 +144,24 @@ func parseFlags(args []string) (*PredictConfig, error) {
 	fs := flag.NewFlagSet("zerfoo-predict", flag.ContinueOnError)
 
 	// Input/Output flags
-	fs.StringVar(&config.DataPath, "data", "", "Path to input data (required)")
+	fs.StringVar(&config.DataPath, "data", "", "Path to input data: CSV, or Parquet with a .parquet extension (required)")
 	fs.StringVar(&config.ModelPath, "model", "", "Path to trained model (required)")
 	fs.StringVar(&config.OutputPath, "output", "", "Output path for predictions (required)")
+	fs.StringVar(&config.Loader, "loader", "", "Registered model loader (default: model file extension, e.g. gguf)")
 
 	// Prediction options
 	fs.IntVar(&config.BatchSize, "batch-size", 10000, "Prediction batch size")
 	fs.StringVar(&config.OutputFormat, "format", "csv", "Output format (csv, json, parquet)")
 	fs.BoolVar(&config.IncludeProbs, "include-probs", false, "Include prediction probabilities")
 
+	// Test-time augmentation flags; defaults come from <model>.tta.json if present
+	fs.IntVar(&config.TTA, "tta", 0, "Test-time augmentation variants per batch, including the original (0 disables)")
+	fs.StringVar(&config.TTAMode, "tta-mode", "dropout", "TTA variants: dropout (feature dropout) or shift (window shifts)")
+	fs.Float64Var(&config.TTADropout, "tta-dropout", 0.1, "Feature dropout probability of TTA variants")
+	fs.IntVar(&config.TTAMaxShift, "tta-max-shift", 1, "Largest window shift of TTA variants")
+	fs.StringVar(&config.TTAAggregate, "tta-aggregate", "mean", "Aggregation of TTA outputs: mean or median")
+	fs.Uint64Var(&config.TTASeed, "tta-seed", 1, "Seed of the TTA dropout masks")
+
 	// Data processing
 	featureColumnsFlag := fs.String("features", "", "Comma-separated feature column names (default: auto-detect)")
 	fs.StringVar(&config.IDColumn, "id-col", "id", "ID column name")
@@ -154,6 +174,9 @@ func parseFlags(args []string) (*PredictConfig, error) {
 	if err := fs.Parse(args); err != nil {
 		return nil, err
 	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be positive, got %d", config.BatchSize)
+	}
 
 	// Process feature columns
 	if *featureColumnsFlag != "" {
@@ -162,6 +185,11 @@ func parseFlags(args []string) (*PredictConfig, error) {
 			config.FeatureColumns[i] = strings.TrimSpace(config.FeatureColumns[i])
 		}
 	}
+	if config.ModelPath != "" {
+		if err := applyModelTTA(config, fs); err != nil {
+			return nil, err
+		}
+	}
 
 	return config, validateConfig(config)
 }
@@ -181,147 +209,7 @@ func validateConfig(config *PredictConfig) error {
 		return fmt.Errorf("output file exists and -overwrite not specified: %s", config.OutputPath)
 	}
 
-	return nil
-}
-
-func runPrediction(config *PredictConfig, result *PredictionResult) error {
-	// Placeholder implementation
-	if config.Verbose {
-		log.Printf("Loading model from: %s", config.ModelPath)
-		log.Printf("Loading data from: %s", config.DataPath)
-	}
-
-	// Simulate data loading and prediction
-	result.NumSamples = 100000 // Placeholder
-	result.NumFeatures = 1050  // Placeholder
-
-	// Simulate prediction statistics
-	result.PredictionStats = map[string]float64{
-		"mean":      0.5,
-		"std":       0.1,
-		"min":       0.0,
-		"max":       1.0,
-		"q25":       0.45,
-		"q50":       0.5,
-		"q75":       0.55,
-		"nan_count": 0,
-	}
-
-	// Create output directory
-	outputDir := filepath.Dir(config.OutputPath)
-	if err := os.MkdirAll(outputDir, 0750); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Generate placeholder predictions
-	if err := generatePlaceholderPredictions(config, result); err != nil {
-		return fmt.Errorf("failed to generate predictions: %w", err)
-	}
-
-	if config.Verbose {
-		log.Printf("Predictions saved to: %s", config.OutputPath)
-		log.Printf("Prediction stats: %+v", result.PredictionStats)
-	}
-
-	return nil
-}
-
-func generatePlaceholderPredictions(config *PredictConfig, result *PredictionResult) error {
-	switch strings.ToLower(config.OutputFormat) {
-	case "csv":
-		return generateCSVPredictions(config, result)
-	case "json":
-		return generateJSONPredictions(config, result)
-	default:
-		return fmt.Errorf("unsupported output format: %s", config.OutputFormat)
-	}
-}
-
-func generateCSVPredictions(config *PredictConfig, result *PredictionResult) error {
-	file, err := os.Create(config.OutputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close file: %v\n", closeErr)
-		}
-	}()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	header := []string{config.IDColumn, "prediction"}
-	if config.GroupColumn != "" {
-		header = append(header, config.GroupColumn)
-	}
-	if config.IncludeProbs {
-		header = append(header, "prediction_prob")
-	}
-
-	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
-	}
-
-	// Write placeholder data
-	for i := range result.NumSamples {
-		row := []string{
-			fmt.Sprintf("id_%06d", i),
-			fmt.Sprintf("%.6f", 0.5+float64(i%100)/10000.0), // Placeholder prediction
-		}
-
-		if config.GroupColumn != "" {
-			groupNum := (i / 1000) + 1
-			row = append(row, fmt.Sprintf("group_%d", groupNum))
-		}
-
-		if config.IncludeProbs {
-			row = append(row, fmt.Sprintf("%.6f", 0.6+float64(i%50)/5000.0))
-		}
-
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write CSV row %d: %w", i, err)
-		}
-	}
-
-	return nil
-}
-
-func generateJSONPredictions(config *PredictConfig, result *PredictionResult) error {
-	type PredictionRow struct {
-		ID         string  `json:"id"`
-		Prediction float64 `json:"prediction"`
-		Group      string  `json:"group,omitempty"`
-		Prob       float64 `json:"prediction_prob,omitempty"`
-	}
-
-	var predictions []PredictionRow
-
-	for i := range min(1000, result.NumSamples) { // Limit for JSON output
-		row := PredictionRow{
-			ID:         fmt.Sprintf("id_%06d", i),
-			Prediction: 0.5 + float64(i%100)/10000.0,
-		}
-
-		if config.GroupColumn != "" {
-			groupNum := (i / 100) + 1
-			row.Group = fmt.Sprintf("group_%d", groupNum)
-		}
-
-		if config.IncludeProbs {
-			row.Prob = 0.6 + float64(i%50)/5000.0
-		}
-
-		predictions = append(predictions, row)
-	}
-
-	data, err := json.MarshalIndent(predictions, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal predictions: %w", err)
-	}
-
-	return os.WriteFile(config.OutputPath, data, 0o600)
+	return validateTTA(config)
 }
 
 func savePredictionResult(config *PredictConfig, result *PredictionResult) {
@@ -336,6 +224,11 @@ func savePredictionResult(config *PredictConfig, result *PredictionResult) {
 		return
 	}
 
+	// The run may have failed before creating the output directory.
+	if err := os.MkdirAll(outputDir, 0o750); err != nil {
+		log.Printf("Failed to create output directory: %v", err)
+		return
+	}
 	if err := os.WriteFile(metaPath, data, 0o600); err != nil {
 		log.Printf("Failed to save prediction metadata: %v", err)
 		return
@@ -2,13 +2,149 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/zerfoo/zerfoo/data/parquet"
+	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/zerfoo/model/postprocess"
+	"github.com/zerfoo/zerfoo/tabular"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
 )
 
+// sumModel predicts the sum of each row's features, or with classes > 0
+// emits classes scores per row peaking at class (row sum mod classes).
+type sumModel struct {
+	classes  int
+	rowSizes []int
+}
+
+func (m *sumModel) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	shape := inputs[0].Shape()
+	rows, cols := shape[0], shape[1]
+	m.rowSizes = append(m.rowSizes, rows)
+	data := inputs[0].Data()
+	if m.classes == 0 {
+		out := make([]float32, rows)
+		for i := range rows {
+			for _, v := range data[i*cols : (i+1)*cols] {
+				out[i] += v
+			}
+		}
+		return tensor.New([]int{rows, 1}, out)
+	}
+	out := make([]float32, rows*m.classes)
+	for i := range rows {
+		var sum float32
+		for _, v := range data[i*cols : (i+1)*cols] {
+			sum += v
+		}
+		out[i*m.classes+int(sum)%m.classes] = 10
+	}
+	return tensor.New([]int{rows, m.classes}, out)
+}
+
+func (m *sumModel) Backward(context.Context, ...*tensor.TensorNumeric[float32]) error { return nil }
+func (m *sumModel) GetGraph() *graph.Graph[float32]                                   { return nil }
+func (m *sumModel) GetMetadata() model.ModelMetadata                                  { return model.ModelMetadata{} }
+func (m *sumModel) Parameters() []*graph.Parameter[float32]                           { return nil }
+func (m *sumModel) SetTrainingMode(bool)                                              {}
+func (m *sumModel) IsTraining() bool                                                  { return false }
+
+type sumModelLoader struct{ m *sumModel }
+
+func (l sumModelLoader) LoadFromPath(context.Context, string) (model.ModelInstance[float32], error) {
+	return l.m, nil
+}
+
+func (l sumModelLoader) LoadFromReader(context.Context, io.Reader) (model.ModelInstance[float32], error) {
+	return l.m, nil
+}
+
+func (l sumModelLoader) LoadFromBytes(context.Context, []byte) (model.ModelInstance[float32], error) {
+	return l.m, nil
+}
+
+func (l sumModelLoader) SupportsFormat(string) bool      { return true }
+func (l sumModelLoader) GetLoaderInfo() model.LoaderInfo { return model.LoaderInfo{Name: "zmf"} }
+
+// useSumModel registers m as the "zmf" loader's model for the test.
+func useSumModel(t *testing.T, m *sumModel) {
+	t.Helper()
+	reg := model.NewModelRegistry[float32]()
+	if err := reg.RegisterModelLoader("zmf", func(context.Context, map[string]interface{}) (model.ModelLoader[float32], error) {
+		return sumModelLoader{m}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	old := modelRegistry
+	modelRegistry = reg
+	t.Cleanup(func() { modelRegistry = old })
+}
+
+// useGGUFLoaders swaps in a registry with the loaders main registers.
+func useGGUFLoaders(t *testing.T) {
+	t.Helper()
+	reg := model.NewModelRegistry[float32]()
+	if err := registerLoaders(reg); err != nil {
+		t.Fatal(err)
+	}
+	old := modelRegistry
+	modelRegistry = reg
+	t.Cleanup(func() { modelRegistry = old })
+}
+
+// writeGGUFModel saves a tabular model with inputDim features and the
+// given post-processing chain as model.gguf in dir and returns its path
+// and the model.
+func writeGGUFModel(t *testing.T, dir string, inputDim int, chain postprocess.Chain) (string, *tabular.Model) {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	m, err := tabular.NewModel(tabular.ModelConfig{InputDim: inputDim, HiddenDims: []int{8, 4}}, compute.NewCPUEngine[float32](ops), ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetPostprocess(chain); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "model.gguf")
+	if err := tabular.SaveGGUF(m, path); err != nil {
+		t.Fatal(err)
+	}
+	return path, m
+}
+
+// writeInput writes a CSV with id, a and b columns and returns its path.
+func writeInput(t *testing.T, dir string) string {
+	t.Helper()
+	return writeCSV(t, dir, "id,a,b\nr1,1,0\nr2,2,1\nr3,3,2\nr4,4,3\nr5,5,4\n")
+}
+
+// writeEraInput is writeInput with an era group column.
+func writeEraInput(t *testing.T, dir string) string {
+	t.Helper()
+	return writeCSV(t, dir, "id,era,a,b\nr1,e1,1,0\nr2,e1,2,1\nr3,e2,3,2\nr4,e2,4,3\nr5,e3,5,4\n")
+}
+
+func writeCSV(t *testing.T, dir, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, "input.csv")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 func TestParseFlags(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -141,10 +277,11 @@ func TestParseFlags_OutputExists(t *testing.T) {
 func TestRun_CSVOutput(t *testing.T) {
 	dir := t.TempDir()
 	outPath := filepath.Join(dir, "predictions.csv")
+	useSumModel(t, &sumModel{})
 
 	var buf bytes.Buffer
 	err := run([]string{
-		"-data", "input.csv", "-model", "model.zmf",
+		"-data", writeInput(t, dir), "-model", "model.zmf",
 		"-output", outPath, "-format", "csv",
 	}, &buf)
 	if err != nil {
@@ -179,13 +316,112 @@ func TestRun_CSVOutput(t *testing.T) {
 	}
 }
 
+// TestRun_GGUFModel runs the registered GGUF loader end to end on a saved
+// tabular model, writing into an output directory that does not exist yet.
+func TestRun_GGUFModel(t *testing.T) {
+	dir := t.TempDir()
+	modelPath, m := writeGGUFModel(t, dir, 2, nil)
+	useGGUFLoaders(t)
+	outPath := filepath.Join(dir, "out", "out.csv")
+
+	err := run([]string{
+		"-data", writeInput(t, dir), "-model", modelPath,
+		"-output", outPath, "-include-probs", "-batch-size", "2",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+
+	want := "id,prediction,prediction_prob\n"
+	for i := range 5 {
+		dir, conf, err := m.Predict([]float64{float64(i + 1), float64(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want += fmt.Sprintf("r%d,%d.000000,%.6f\n", i+1, int(dir), conf)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("output =\n%s\nwant\n%s", data, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "out_metadata.json")); err != nil {
+		t.Errorf("metadata not written: %v", err)
+	}
+}
+
+// TestRun_GGUFPostprocess checks that the chain saved with a tabular model
+// runs over all rows at once, ranking within eras that span batches, and
+// that the metadata statistics describe the post-processed values.
+func TestRun_GGUFPostprocess(t *testing.T) {
+	dir := t.TempDir()
+	chain := postprocess.Chain{{Op: postprocess.OpRank}, {Op: postprocess.OpClip, Min: 0.2, Max: 0.8}}
+	modelPath, m := writeGGUFModel(t, dir, 2, chain)
+	useGGUFLoaders(t)
+	outPath := filepath.Join(dir, "out.csv")
+
+	err := run([]string{
+		"-data", writeEraInput(t, dir), "-model", modelPath,
+		"-output", outPath, "-group-col", "era", "-batch-size", "3",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+
+	raw := make([]float64, 5)
+	for i := range raw {
+		dir, _, err := m.Predict([]float64{float64(i + 1), float64(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw[i] = float64(dir)
+	}
+	eras := []string{"e1", "e1", "e2", "e2", "e3"}
+	post, err := chain.Apply(raw, eras)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post[4] != 0.5 {
+		t.Fatalf("single-row era ranked %v, want 0.5", post[4])
+	}
+	want := "id,prediction,era\n"
+	for i, p := range post {
+		want += fmt.Sprintf("r%d,%.6f,%s\n", i+1, p, eras[i])
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("output =\n%s\nwant\n%s", data, want)
+	}
+
+	metaData, err := os.ReadFile(filepath.Join(dir, "out_metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result PredictionResult
+	if err := json.Unmarshal(metaData, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.NumSamples != 5 {
+		t.Errorf("NumSamples = %d, want 5", result.NumSamples)
+	}
+	if lo, hi := result.PredictionStats["min"], result.PredictionStats["max"]; lo < 0.2 || hi > 0.8 {
+		t.Errorf("stats range [%v, %v], want within the clip range [0.2, 0.8]", lo, hi)
+	}
+}
+
 func TestRun_JSONOutput(t *testing.T) {
 	dir := t.TempDir()
 	outPath := filepath.Join(dir, "predictions.json")
+	useSumModel(t, &sumModel{})
 
 	var buf bytes.Buffer
 	err := run([]string{
-		"-data", "input.csv", "-model", "model.zmf",
+		"-data", writeInput(t, dir), "-model", "model.zmf",
 		"-output", outPath, "-format", "json",
 	}, &buf)
 	if err != nil {
@@ -208,25 +444,183 @@ func TestRun_JSONOutput(t *testing.T) {
 
 func TestRun_UnsupportedFormat(t *testing.T) {
 	dir := t.TempDir()
-	outPath := filepath.Join(dir, "predictions.parquet")
+	outPath := filepath.Join(dir, "predictions.xml")
 
 	var buf bytes.Buffer
 	err := run([]string{
 		"-data", "input.csv", "-model", "model.zmf",
-		"-output", outPath, "-format", "parquet",
+		"-output", outPath, "-format", "xml",
 	}, &buf)
 	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
 		t.Errorf("expected 'unsupported output format' error, got: %v", err)
 	}
 }
 
+func TestRun_ParquetOutput(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "predictions.parquet")
+	useSumModel(t, &sumModel{})
+
+	err := run([]string{
+		"-data", writeEraInput(t, dir), "-model", "model.zmf", "-output", outPath,
+		"-format", "parquet", "-group-col", "era", "-include-probs",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+
+	f, err := parquet.OpenFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var names []string
+	for _, c := range f.Columns() {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); got != "id,prediction,era,prediction_prob" {
+		t.Errorf("columns = %s", got)
+	}
+	if f.NumRows() != 5 {
+		t.Fatalf("NumRows = %d, want 5", f.NumRows())
+	}
+	cols, err := f.ReadRowGroup(0, []int{0, 1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float64{1, 3, 5, 7, 9} {
+		id, pred, era, prob := cols[0].Text(i), cols[1].Float64(i), cols[2].Text(i), cols[3].Float64(i)
+		if id != fmt.Sprintf("r%d", i+1) || pred != want || era != fmt.Sprintf("e%d", i/2+1) ||
+			math.Abs(prob-1/(1+math.Exp(-want))) > 1e-9 {
+			t.Errorf("row %d = %s, %v, %s, %v", i, id, pred, era, prob)
+		}
+	}
+}
+
+// TestRun_ParquetGGUF writes the Parquet predictions of a tabular GGUF
+// model read from Parquet input.
+func TestRun_ParquetGGUF(t *testing.T) {
+	dir := t.TempDir()
+	modelPath, m := writeGGUFModel(t, dir, 2, nil)
+	useGGUFLoaders(t)
+	inPath := filepath.Join(dir, "input.parquet")
+	outPath := filepath.Join(dir, "predictions.parquet")
+
+	in, err := os.Create(inPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pw, err := parquet.NewWriter(in, []parquet.Column{
+		{Name: "id", Type: parquet.ByteArray, String: true},
+		{Name: "a", Type: parquet.Double},
+		{Name: "b", Type: parquet.Double},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		if err := pw.Write(fmt.Sprintf("r%d", i), float64(i), float64(2-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = run([]string{
+		"-data", inPath, "-model", modelPath, "-output", outPath,
+		"-format", "parquet", "-include-probs",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+
+	f, err := parquet.OpenFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if f.NumRows() != 4 {
+		t.Fatalf("NumRows = %d, want 4", f.NumRows())
+	}
+	cols, err := f.ReadRowGroup(0, []int{0, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		dir, conf, err := m.Predict([]float64{float64(i), float64(2 - i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, pred, prob := cols[0].Text(i), cols[1].Float64(i), cols[2].Float64(i)
+		if id != fmt.Sprintf("r%d", i) || pred != float64(dir) || math.Abs(prob-conf) > 1e-6 {
+			t.Errorf("row %d = %s, %v, %v, want %v, %v", i, id, pred, prob, dir, conf)
+		}
+	}
+}
+
+// TestRun_ParquetInput reads only the ID and feature columns of a Parquet
+// file, with a null feature read as NaN.
+func TestRun_ParquetInput(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "input.parquet")
+	in, err := os.Create(inPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := parquet.NewWriter(in, []parquet.Column{
+		{Name: "id", Type: parquet.ByteArray, String: true},
+		{Name: "note", Type: parquet.ByteArray, String: true},
+		{Name: "a", Type: parquet.Int64},
+		{Name: "b", Type: parquet.Float, Optional: true},
+	}, parquet.WithRowGroupSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		var b any = float32(i) / 2
+		if i == 3 {
+			b = nil
+		}
+		if err := w.Write(fmt.Sprintf("r%d", i), "text", i, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "predictions.csv")
+	useSumModel(t, &sumModel{})
+	if err := run([]string{
+		"-data", inPath, "-model", "model.zmf", "-output", outPath, "-features", "a,b", "-batch-size", "3",
+	}, io.Discard); err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,prediction\nr0,0.000000\nr1,1.500000\nr2,3.000000\nr3,NaN\nr4,6.000000\n"
+	if string(data) != want {
+		t.Errorf("output =\n%s\nwant\n%s", data, want)
+	}
+}
+
 func TestRun_VerboseMode(t *testing.T) {
 	dir := t.TempDir()
 	outPath := filepath.Join(dir, "predictions.csv")
+	useSumModel(t, &sumModel{})
 
 	var buf bytes.Buffer
 	err := run([]string{
-		"-data", "input.csv", "-model", "model.zmf",
+		"-data", writeInput(t, dir), "-model", "model.zmf",
 		"-output", outPath, "-verbose",
 	}, &buf)
 	if err != nil {
@@ -241,10 +635,11 @@ func TestRun_VerboseMode(t *testing.T) {
 func TestRun_WithGroupAndProbs(t *testing.T) {
 	dir := t.TempDir()
 	outPath := filepath.Join(dir, "predictions.csv")
+	useSumModel(t, &sumModel{})
 
 	var buf bytes.Buffer
 	err := run([]string{
-		"-data", "input.csv", "-model", "model.zmf",
+		"-data", writeEraInput(t, dir), "-model", "model.zmf",
 		"-output", outPath, "-group-col", "era", "-include-probs",
 	}, &buf)
 	if err != nil {
@@ -261,6 +656,129 @@ func TestRun_WithGroupAndProbs(t *testing.T) {
 	}
 }
 
+func TestRun_BatchedPredictionsAndStats(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "predictions.csv")
+	m := &sumModel{}
+	useSumModel(t, m)
+
+	err := run([]string{
+		"-data", writeEraInput(t, dir), "-model", "model.zmf", "-output", outPath,
+		"-batch-size", "2", "-group-col", "era",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	if got := m.rowSizes; len(got) != 3 || got[0] != 2 || got[2] != 1 {
+		t.Errorf("batch sizes = %v, want [2 2 1]", got)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,prediction,era\nr1,1.000000,e1\nr2,3.000000,e1\nr3,5.000000,e2\nr4,7.000000,e2\nr5,9.000000,e3\n"
+	if string(data) != want {
+		t.Errorf("output =\n%s\nwant\n%s", data, want)
+	}
+
+	metaData, err := os.ReadFile(filepath.Join(dir, "predictions_metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result PredictionResult
+	if err := json.Unmarshal(metaData, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.NumSamples != 5 || result.NumFeatures != 2 {
+		t.Errorf("NumSamples = %d, NumFeatures = %d, want 5, 2", result.NumSamples, result.NumFeatures)
+	}
+	wantStats := map[string]float64{
+		"mean": 5, "std": math.Sqrt(8), "min": 1, "max": 9,
+		"q25": 3, "q50": 5, "q75": 7, "nan_count": 0,
+	}
+	for k, v := range wantStats {
+		if got := result.PredictionStats[k]; math.Abs(got-v) > 1e-9 {
+			t.Errorf("stats[%s] = %v, want %v", k, got, v)
+		}
+	}
+}
+
+func TestRun_Classification(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "predictions.json")
+	useSumModel(t, &sumModel{classes: 3})
+
+	err := run([]string{
+		"-data", writeInput(t, dir), "-model", "model.zmf", "-output", outPath,
+		"-format", "json", "-features", "a", "-include-probs",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []predictionRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, data)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("got %d rows, want 5", len(rows))
+	}
+	wantProb := 1 / (1 + 2*math.Exp(-10))
+	for i, row := range rows {
+		if want := float64((i + 1) % 3); row.Prediction != want {
+			t.Errorf("row %d prediction = %v, want class %v", i, row.Prediction, want)
+		}
+		if row.Prob == nil || math.Abs(*row.Prob-wantProb) > 1e-6 {
+			t.Errorf("row %d prob = %v, want %v", i, row.Prob, wantProb)
+		}
+	}
+}
+
+func TestRun_PipelineErrors(t *testing.T) {
+	dir := t.TempDir()
+	input := writeInput(t, dir)
+	useSumModel(t, &sumModel{})
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"unknown loader", []string{"-model", "model.onnx"}, "model loader 'onnx' not registered"},
+		{"missing feature", []string{"-features", "a,zz"}, `feature column "zz" not found`},
+		{"missing group", []string{"-group-col", "week"}, `group column "week" not found`},
+		{"bad batch size", []string{"-batch-size", "0"}, "batch size must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{
+				"-data", input, "-model", "model.zmf",
+				"-output", filepath.Join(dir, "out.csv"), "-overwrite",
+			}, tt.args...)
+			err := run(args, io.Discard)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	for q, want := range map[float64]float64{0: 1, 0.5: 2.5, 0.75: 3.25, 1: 4} {
+		if got := quantile(sorted, q); got != want {
+			t.Errorf("quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if got := predictionStats([]float64{math.NaN(), 2})["nan_count"]; got != 1 {
+		t.Errorf("nan_count = %v, want 1", got)
+	}
+}
+
 func TestRun_MissingArgs(t *testing.T) {
 	var buf bytes.Buffer
 	err := run([]string{}, &buf)
@@ -312,8 +830,10 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+// TestSavePredictionResult saves into a directory that does not exist, as
+// after a run that failed before creating the output.
 func TestSavePredictionResult(t *testing.T) {
-	dir := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "missing")
 	outPath := filepath.Join(dir, "test_output.csv")
 	config := &PredictConfig{OutputPath: outPath}
 	result := &PredictionResult{Success: true, NumSamples: 100}
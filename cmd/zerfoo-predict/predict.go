@@ -0,0 +1,571 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/data/parquet"
+	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/zerfoo/model/postprocess"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// modelRegistry supplies the model loaders; tests replace it.
+var modelRegistry = model.Float32ModelRegistry
+
+// loaderName returns the registry name of the loader for config: the
+// -loader flag, or else the model file's extension.
+func loaderName(config *PredictConfig) string {
+	if config.Loader != "" {
+		return config.Loader
+	}
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(config.ModelPath), "."))
+}
+
+func runPrediction(ctx context.Context, config *PredictConfig, result *PredictionResult) error {
+	format := strings.ToLower(config.OutputFormat)
+	if format != "csv" && format != "json" && format != "parquet" {
+		return fmt.Errorf("unsupported output format: %s", config.OutputFormat)
+	}
+
+	if config.Verbose {
+		log.Printf("Loading model from: %s", config.ModelPath)
+	}
+	loader, err := modelRegistry.GetModelLoader(ctx, loaderName(config), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get model loader: %w", err)
+	}
+	mdl, err := loader.LoadFromPath(ctx, config.ModelPath)
+	if err != nil {
+		return fmt.Errorf("failed to load model from %s: %w", config.ModelPath, err)
+	}
+	mdl.SetTrainingMode(false)
+	chain, hasChain, err := postprocess.FromMetadata(mdl.GetMetadata())
+	if err != nil {
+		return fmt.Errorf("invalid post-processing chain in %s: %w", config.ModelPath, err)
+	}
+
+	if config.Verbose {
+		log.Printf("Loading data from: %s", config.DataPath)
+	}
+	var input records
+	if strings.EqualFold(filepath.Ext(config.DataPath), ".parquet") {
+		pf, err := parquet.OpenFile(config.DataPath)
+		if err != nil {
+			return fmt.Errorf("failed to open data: %w", err)
+		}
+		defer func() { _ = pf.Close() }()
+		input = newParquetRecords(pf, config)
+	} else {
+		in, err := os.Open(config.DataPath)
+		if err != nil {
+			return fmt.Errorf("failed to open data: %w", err)
+		}
+		defer func() { _ = in.Close() }()
+		input = csv.NewReader(in)
+	}
+	rows, err := newRowReader(input, config)
+	if err != nil {
+		return err
+	}
+	result.NumFeatures = len(rows.features)
+
+	outputDir := filepath.Dir(config.OutputPath)
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	out, err := os.Create(config.OutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+	var w predictionWriter
+	switch format {
+	case "csv":
+		w = newCSVPredictionWriter(out, config)
+	case "json":
+		w = newJSONPredictionWriter(out)
+	default:
+		if w, err = newParquetPredictionWriter(out, config); err != nil {
+			return fmt.Errorf("failed to write predictions: %w", err)
+		}
+	}
+	if err := w.WriteHeader(); err != nil {
+		return fmt.Errorf("failed to write predictions: %w", err)
+	}
+
+	// A post-processing chain ranks within eras across the whole input, so
+	// its rows are held back and written once the chain has run over all
+	// of them.
+	var (
+		predictions []float64
+		groups      []string
+		buffered    []predictionRow
+	)
+	for {
+		batch, err := rows.next(config.BatchSize)
+		if err != nil {
+			return err
+		}
+		if batch.len() == 0 {
+			break
+		}
+		preds, probs, err := predictBatch(ctx, mdl, batch, len(rows.features), config)
+		if err != nil {
+			return fmt.Errorf("rows %d-%d: %w", len(predictions), len(predictions)+batch.len()-1, err)
+		}
+		for i := range preds {
+			row := predictionRow{ID: batch.ids[i], Prediction: preds[i]}
+			if batch.groups != nil {
+				row.Group = batch.groups[i]
+			}
+			if config.IncludeProbs {
+				row.Prob = &probs[i]
+			}
+			if hasChain {
+				buffered = append(buffered, row)
+				continue
+			}
+			if err := w.Write(row); err != nil {
+				return fmt.Errorf("failed to write predictions: %w", err)
+			}
+		}
+		predictions = append(predictions, preds...)
+		groups = append(groups, batch.groups...)
+		if config.Verbose {
+			log.Printf("Predicted %d rows", len(predictions))
+		}
+	}
+	if hasChain {
+		if rows.groupIdx < 0 {
+			groups = nil
+		}
+		if predictions, err = chain.Apply(predictions, groups); err != nil {
+			return fmt.Errorf("failed to post-process predictions: %w", err)
+		}
+		for i, row := range buffered {
+			row.Prediction = predictions[i]
+			if err := w.Write(row); err != nil {
+				return fmt.Errorf("failed to write predictions: %w", err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write predictions: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+
+	result.NumSamples = len(predictions)
+	result.PredictionStats = predictionStats(predictions)
+	if config.Verbose {
+		log.Printf("Predictions saved to: %s", config.OutputPath)
+		log.Printf("Prediction stats: %+v", result.PredictionStats)
+	}
+	return nil
+}
+
+// predictBatch runs the model on one batch, on each TTA variant of it if
+// config enables TTA, aggregating the model outputs across variants. A
+// model with one output per row predicts that value, with its logistic
+// sigmoid as the probability; a model with several outputs per row predicts
+// the index of the largest, with its softmax probability.
+func predictBatch(ctx context.Context, mdl model.ModelInstance[float32], batch *rowBatch, numFeatures int, config *PredictConfig) (preds, probs []float64, err error) {
+	n := batch.len()
+	variants := max(config.TTA, 1)
+	outputs := make([][]float32, variants)
+	for v := range variants {
+		features := batch.features
+		if v > 0 {
+			features = augment(batch.features, numFeatures, v, config)
+		}
+		input, err := tensor.New([]int{n, numFeatures}, features)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create input tensor: %w", err)
+		}
+		output, err := mdl.Forward(ctx, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("model forward failed: %w", err)
+		}
+		outputs[v] = output.Data()
+		if len(outputs[v]) == 0 || len(outputs[v])%n != 0 || len(outputs[v]) != len(outputs[0]) {
+			return nil, nil, fmt.Errorf("model returned %d values for %d rows", len(outputs[v]), n)
+		}
+	}
+	data := outputs[0]
+	if variants > 1 {
+		data = aggregate(outputs, config.TTAAggregate)
+	}
+	width := len(data) / n
+
+	preds = make([]float64, n)
+	probs = make([]float64, n)
+	for i := range n {
+		out := data[i*width : (i+1)*width]
+		if width == 1 {
+			preds[i] = float64(out[0])
+			probs[i] = 1 / (1 + math.Exp(-preds[i]))
+			continue
+		}
+		best := 0
+		for j, v := range out {
+			if v > out[best] {
+				best = j
+			}
+		}
+		var sum float64
+		for _, v := range out {
+			sum += math.Exp(float64(v - out[best]))
+		}
+		preds[i] = float64(best)
+		probs[i] = 1 / sum
+	}
+	return preds, probs, nil
+}
+
+// predictionStats summarizes the predictions, ignoring NaNs in every
+// statistic but nan_count.
+func predictionStats(predictions []float64) map[string]float64 {
+	sorted := make([]float64, 0, len(predictions))
+	for _, p := range predictions {
+		if !math.IsNaN(p) {
+			sorted = append(sorted, p)
+		}
+	}
+	stats := map[string]float64{"nan_count": float64(len(predictions) - len(sorted))}
+	if len(sorted) == 0 {
+		return stats
+	}
+	slices.Sort(sorted)
+
+	var sum float64
+	for _, p := range sorted {
+		sum += p
+	}
+	mean := sum / float64(len(sorted))
+	var sq float64
+	for _, p := range sorted {
+		sq += (p - mean) * (p - mean)
+	}
+	stats["mean"] = mean
+	stats["std"] = math.Sqrt(sq / float64(len(sorted)))
+	stats["min"] = sorted[0]
+	stats["max"] = sorted[len(sorted)-1]
+	stats["q25"] = quantile(sorted, 0.25)
+	stats["q50"] = quantile(sorted, 0.5)
+	stats["q75"] = quantile(sorted, 0.75)
+	return stats
+}
+
+// quantile returns the q-quantile of sorted, interpolating linearly between
+// the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[lo+1]*frac
+}
+
+// records reads the input as text: a header row, then the data rows. A
+// csv.Reader is one.
+type records interface {
+	Read() ([]string, error)
+}
+
+// parquetRecords presents a Parquet file as records, reading one row group
+// at a time and only the ID, group and feature columns. Nulls read as
+// empty fields.
+type parquetRecords struct {
+	file    *parquet.File
+	header  []string
+	columns []int
+	group   int
+	values  []*parquet.Values
+	row     int
+	rows    int
+	started bool
+}
+
+func newParquetRecords(f *parquet.File, config *PredictConfig) *parquetRecords {
+	p := &parquetRecords{file: f}
+	for i, c := range f.Columns() {
+		p.header = append(p.header, c.Name)
+		name := strings.TrimSpace(c.Name)
+		if len(config.FeatureColumns) == 0 || name == config.IDColumn || name == config.GroupColumn ||
+			slices.Contains(config.FeatureColumns, name) {
+			p.columns = append(p.columns, i)
+		}
+	}
+	return p
+}
+
+func (p *parquetRecords) Read() ([]string, error) {
+	if !p.started {
+		p.started = true
+		return p.header, nil
+	}
+	p.row++
+	for p.row >= p.rows {
+		if p.group == p.file.NumRowGroups() {
+			return nil, io.EOF
+		}
+		values, err := p.file.ReadRowGroup(p.group, p.columns)
+		if err != nil {
+			return nil, err
+		}
+		p.values, p.group, p.row, p.rows = values, p.group+1, 0, 0
+		if len(values) > 0 {
+			p.rows = values[0].Len()
+		}
+	}
+	record := make([]string, len(p.header))
+	for j, c := range p.columns {
+		record[c] = p.values[j].Text(p.row)
+	}
+	return record, nil
+}
+
+// rowReader streams feature batches from input with a header row.
+type rowReader struct {
+	r        records
+	idIdx    int // -1 without an ID column
+	groupIdx int // -1 without a group column
+	features []int
+	line     int
+}
+
+// rowBatch holds up to a batch of rows, with features flattened row-major.
+type rowBatch struct {
+	ids      []string
+	groups   []string // nil without a group column
+	features []float32
+}
+
+func (b *rowBatch) len() int { return len(b.ids) }
+
+// newRowReader reads the header and resolves the ID, group and feature
+// columns. Without -features, every other column is a feature.
+func newRowReader(r records, config *PredictConfig) (*rowReader, error) {
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input header: %w", err)
+	}
+	rr := &rowReader{r: r, idIdx: -1, groupIdx: -1, line: 1}
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		col = strings.TrimSpace(col)
+		index[col] = i
+		switch {
+		case col == config.IDColumn:
+			rr.idIdx = i
+		case config.GroupColumn != "" && col == config.GroupColumn:
+			rr.groupIdx = i
+		case len(config.FeatureColumns) == 0:
+			rr.features = append(rr.features, i)
+		}
+	}
+	for _, col := range config.FeatureColumns {
+		i, ok := index[col]
+		if !ok {
+			return nil, fmt.Errorf("feature column %q not found in input header", col)
+		}
+		rr.features = append(rr.features, i)
+	}
+	if len(rr.features) == 0 {
+		return nil, errors.New("no feature columns found in input")
+	}
+	if config.GroupColumn != "" && rr.groupIdx < 0 {
+		return nil, fmt.Errorf("group column %q not found in input header", config.GroupColumn)
+	}
+	return rr, nil
+}
+
+// next reads up to size rows. An empty feature value is read as NaN. It
+// returns an empty batch at the end of the data.
+func (rr *rowReader) next(size int) (*rowBatch, error) {
+	b := &rowBatch{}
+	if rr.groupIdx >= 0 {
+		b.groups = []string{}
+	}
+	for b.len() < size {
+		record, err := rr.r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		rr.line++
+
+		id := fmt.Sprintf("row_%d", rr.line-2)
+		if rr.idIdx >= 0 {
+			id = record[rr.idIdx]
+		}
+		b.ids = append(b.ids, id)
+		if rr.groupIdx >= 0 {
+			b.groups = append(b.groups, record[rr.groupIdx])
+		}
+		for _, i := range rr.features {
+			field := strings.TrimSpace(record[i])
+			if field == "" {
+				b.features = append(b.features, float32(math.NaN()))
+				continue
+			}
+			v, err := strconv.ParseFloat(field, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid feature value %q", rr.line, field)
+			}
+			b.features = append(b.features, float32(v))
+		}
+	}
+	return b, nil
+}
+
+// predictionRow is one output row. Prob is nil unless -include-probs is set.
+type predictionRow struct {
+	ID         string   `json:"id"`
+	Prediction float64  `json:"prediction"`
+	Group      string   `json:"group,omitempty"`
+	Prob       *float64 `json:"prediction_prob,omitempty"`
+}
+
+// predictionWriter streams prediction rows to the output file.
+type predictionWriter interface {
+	WriteHeader() error
+	Write(row predictionRow) error
+	Close() error
+}
+
+type csvPredictionWriter struct {
+	w      *csv.Writer
+	config *PredictConfig
+}
+
+func newCSVPredictionWriter(w io.Writer, config *PredictConfig) *csvPredictionWriter {
+	return &csvPredictionWriter{w: csv.NewWriter(w), config: config}
+}
+
+func (c *csvPredictionWriter) WriteHeader() error {
+	header := []string{c.config.IDColumn, "prediction"}
+	if c.config.GroupColumn != "" {
+		header = append(header, c.config.GroupColumn)
+	}
+	if c.config.IncludeProbs {
+		header = append(header, "prediction_prob")
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvPredictionWriter) Write(row predictionRow) error {
+	record := []string{row.ID, strconv.FormatFloat(row.Prediction, 'f', 6, 64)}
+	if c.config.GroupColumn != "" {
+		record = append(record, row.Group)
+	}
+	if row.Prob != nil {
+		record = append(record, strconv.FormatFloat(*row.Prob, 'f', 6, 64))
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvPredictionWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// parquetPredictionWriter writes the predictions as a Parquet file, one row
+// group per 65536 rows.
+type parquetPredictionWriter struct {
+	w      *parquet.Writer
+	config *PredictConfig
+}
+
+func newParquetPredictionWriter(w io.Writer, config *PredictConfig) (*parquetPredictionWriter, error) {
+	columns := []parquet.Column{
+		{Name: config.IDColumn, Type: parquet.ByteArray, String: true},
+		{Name: "prediction", Type: parquet.Double},
+	}
+	if config.GroupColumn != "" {
+		columns = append(columns, parquet.Column{Name: config.GroupColumn, Type: parquet.ByteArray, String: true})
+	}
+	if config.IncludeProbs {
+		columns = append(columns, parquet.Column{Name: "prediction_prob", Type: parquet.Double})
+	}
+	pw, err := parquet.NewWriter(w, columns)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetPredictionWriter{w: pw, config: config}, nil
+}
+
+// WriteHeader does nothing: the schema is written in the footer.
+func (p *parquetPredictionWriter) WriteHeader() error {
+	return nil
+}
+
+func (p *parquetPredictionWriter) Write(row predictionRow) error {
+	values := []any{row.ID, row.Prediction}
+	if p.config.GroupColumn != "" {
+		values = append(values, row.Group)
+	}
+	if row.Prob != nil {
+		values = append(values, *row.Prob)
+	}
+	return p.w.Write(values...)
+}
+
+func (p *parquetPredictionWriter) Close() error {
+	return p.w.Close()
+}
+
+// jsonPredictionWriter writes a JSON array one element at a time, so the
+// predictions are never all held in memory.
+type jsonPredictionWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONPredictionWriter(w io.Writer) *jsonPredictionWriter {
+	return &jsonPredictionWriter{w: w}
+}
+
+func (j *jsonPredictionWriter) WriteHeader() error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonPredictionWriter) Write(row predictionRow) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "\n  "
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonPredictionWriter) Close() error {
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
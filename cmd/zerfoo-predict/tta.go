@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+)
+
+// Test-time augmentation (TTA) runs the model on several variants of each
+// batch and aggregates the outputs. Variant 0 is always the unmodified
+// input. In "dropout" mode the other variants zero each feature with
+// probability TTADropout, from masks seeded by TTASeed and the variant
+// number, so a run is reproducible. In "shift" mode the features are read
+// as a window over a sequence and variant v shifts it by 1, -1, 2, -2, ...
+// positions up to TTAMaxShift, repeating the edge value; the cycle repeats
+// if there are more variants than shifts.
+
+// ttaFileSuffix names the per-model TTA settings file: a model at m.zmf
+// reads them from m.zmf.tta.json. Flags set on the command line override
+// the file.
+const ttaFileSuffix = ".tta.json"
+
+// ttaSettings is the content of a per-model TTA settings file. Absent
+// fields keep the flag defaults.
+type ttaSettings struct {
+	TTA          *int     `json:"tta"`
+	TTAMode      *string  `json:"tta_mode"`
+	TTADropout   *float64 `json:"tta_dropout"`
+	TTAMaxShift  *int     `json:"tta_max_shift"`
+	TTAAggregate *string  `json:"tta_aggregate"`
+	TTASeed      *uint64  `json:"tta_seed"`
+}
+
+// applyModelTTA reads the TTA settings file of the model, if there is one,
+// into config, except for the settings whose flags are set in fs.
+func applyModelTTA(config *PredictConfig, fs *flag.FlagSet) error {
+	path := config.ModelPath + ttaFileSuffix
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read TTA settings: %w", err)
+	}
+	var s ttaSettings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid TTA settings %s: %w", path, err)
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	apply := func(name string, fromFile bool, assign func()) {
+		if fromFile && !set[name] {
+			assign()
+		}
+	}
+	apply("tta", s.TTA != nil, func() { config.TTA = *s.TTA })
+	apply("tta-mode", s.TTAMode != nil, func() { config.TTAMode = *s.TTAMode })
+	apply("tta-dropout", s.TTADropout != nil, func() { config.TTADropout = *s.TTADropout })
+	apply("tta-max-shift", s.TTAMaxShift != nil, func() { config.TTAMaxShift = *s.TTAMaxShift })
+	apply("tta-aggregate", s.TTAAggregate != nil, func() { config.TTAAggregate = *s.TTAAggregate })
+	apply("tta-seed", s.TTASeed != nil, func() { config.TTASeed = *s.TTASeed })
+	return nil
+}
+
+// validateTTA checks the TTA settings of config.
+func validateTTA(config *PredictConfig) error {
+	switch {
+	case config.TTA < 0:
+		return fmt.Errorf("TTA variant count must not be negative, got %d", config.TTA)
+	case config.TTA <= 1:
+		return nil
+	case config.TTAMode != "dropout" && config.TTAMode != "shift":
+		return fmt.Errorf("unsupported TTA mode: %s (want dropout or shift)", config.TTAMode)
+	case config.TTAAggregate != "mean" && config.TTAAggregate != "median":
+		return fmt.Errorf("unsupported TTA aggregate: %s (want mean or median)", config.TTAAggregate)
+	case config.TTAMode == "dropout" && (config.TTADropout <= 0 || config.TTADropout >= 1):
+		return fmt.Errorf("TTA dropout must be in (0, 1), got %v", config.TTADropout)
+	case config.TTAMode == "shift" && config.TTAMaxShift <= 0:
+		return fmt.Errorf("TTA max shift must be positive, got %d", config.TTAMaxShift)
+	}
+	return nil
+}
+
+// augment returns TTA variant v > 0 of the row-major features of rows with
+// cols features each.
+func augment(features []float32, cols, v int, config *PredictConfig) []float32 {
+	out := make([]float32, len(features))
+	if config.TTAMode == "dropout" {
+		rng := rand.New(rand.NewPCG(config.TTASeed, uint64(v)))
+		for i, f := range features {
+			if rng.Float64() >= config.TTADropout {
+				out[i] = f
+			}
+		}
+		return out
+	}
+	shift := ttaShift(v, config.TTAMaxShift)
+	for r := 0; r < len(features); r += cols {
+		row := features[r : r+cols]
+		for j := range row {
+			out[r+j] = row[min(max(j+shift, 0), cols-1)]
+		}
+	}
+	return out
+}
+
+// ttaShift returns the window shift of variant v: 0, 1, -1, 2, -2, ... up
+// to maxShift, cycling.
+func ttaShift(v, maxShift int) int {
+	k := v % (2*maxShift + 1)
+	if k%2 == 1 {
+		return (k + 1) / 2
+	}
+	return -k / 2
+}
+
+// aggregate combines the outputs of the variants elementwise with the mean
+// or median.
+func aggregate(outputs [][]float32, how string) []float32 {
+	out := make([]float32, len(outputs[0]))
+	vals := make([]float64, len(outputs))
+	for i := range out {
+		for v, o := range outputs {
+			vals[v] = float64(o[i])
+		}
+		if how == "median" {
+			slices.Sort(vals)
+			out[i] = float32(quantile(vals, 0.5))
+			continue
+		}
+		var sum float64
+		for _, x := range vals {
+			sum += x
+		}
+		out[i] = float32(sum / float64(len(vals)))
+	}
+	return out
+}
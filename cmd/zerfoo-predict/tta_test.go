@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestRun_TTAShift(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "predictions.csv")
+	m := &sumModel{}
+	useSumModel(t, m)
+	input := writeCSV(t, dir, "id,a,b,c\nr1,1,2,4\nr2,0,3,3\n")
+
+	// Variant 1 shifts each window one step, [a b c] -> [b c c], so the
+	// sums are 10 and 9 against 7 and 6 unshifted.
+	err := run([]string{
+		"-data", input, "-model", "model.zmf", "-output", outPath,
+		"-tta", "2", "-tta-mode", "shift",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	if len(m.rowSizes) != 2 {
+		t.Errorf("%d forward passes, want 2", len(m.rowSizes))
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,prediction\nr1,8.500000\nr2,7.500000\n"
+	if string(data) != want {
+		t.Errorf("output =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// TestRun_TTAGGUF runs shift TTA over a tabular GGUF model: each row's
+// prediction is the argmax of its class logits averaged across variants.
+func TestRun_TTAGGUF(t *testing.T) {
+	dir := t.TempDir()
+	modelPath, m := writeGGUFModel(t, dir, 3, nil)
+	useGGUFLoaders(t)
+	outPath := filepath.Join(dir, "predictions.csv")
+	features := []float32{1, 2, 4, 0, 3, 3, -2, 5, 1}
+	input := writeCSV(t, dir, "id,a,b,c\nr1,1,2,4\nr2,0,3,3\nr3,-2,5,1\n")
+
+	err := run([]string{
+		"-data", input, "-model", modelPath, "-output", outPath,
+		"-tta", "3", "-tta-mode", "shift",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+
+	config := &PredictConfig{TTAMode: "shift", TTAMaxShift: 1}
+	outputs := make([][]float32, 3)
+	for v := range outputs {
+		x := features
+		if v > 0 {
+			x = augment(features, 3, v, config)
+		}
+		in, err := tensor.New([]int{3, 3}, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logits, err := m.Forward(context.Background(), in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[v] = logits.Data()
+	}
+	logits := aggregate(outputs, "mean")
+	want := "id,prediction\n"
+	for i := range 3 {
+		row := logits[i*3 : i*3+3]
+		best := 0
+		for j, v := range row {
+			if v > row[best] {
+				best = j
+			}
+		}
+		want += fmt.Sprintf("r%d,%d.000000\n", i+1, best)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("output =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestAugment(t *testing.T) {
+	features := make([]float32, 400)
+	for i := range features {
+		features[i] = float32(i + 1)
+	}
+	config := &PredictConfig{TTAMode: "dropout", TTADropout: 0.25, TTASeed: 7}
+	a, b := augment(features, 4, 1, config), augment(features, 4, 1, config)
+	if !slices.Equal(a, b) {
+		t.Error("the same variant and seed gave different masks")
+	}
+	if slices.Equal(a, augment(features, 4, 2, config)) {
+		t.Error("variants 1 and 2 have the same mask")
+	}
+	dropped := 0
+	for i, v := range a {
+		switch v {
+		case 0:
+			dropped++
+		case features[i]:
+		default:
+			t.Fatalf("feature %d = %v, want 0 or %v", i, v, features[i])
+		}
+	}
+	if dropped < 60 || dropped > 140 {
+		t.Errorf("dropped %d of 400 features, want about 100", dropped)
+	}
+
+	config = &PredictConfig{TTAMode: "shift", TTAMaxShift: 2}
+	if got := augment([]float32{1, 2, 3, 4, 5, 6}, 3, 2, config); !slices.Equal(got, []float32{1, 1, 2, 4, 4, 5}) {
+		t.Errorf("shift -1 = %v", got)
+	}
+	var shifts []int
+	for v := range 7 {
+		shifts = append(shifts, ttaShift(v, 2))
+	}
+	if !slices.Equal(shifts, []int{0, 1, -1, 2, -2, 0, 1}) {
+		t.Errorf("shifts = %v", shifts)
+	}
+
+	outputs := [][]float32{{1, 10}, {2, 20}, {9, 30}}
+	if got := aggregate(outputs, "mean"); !slices.Equal(got, []float32{4, 20}) {
+		t.Errorf("mean = %v", got)
+	}
+	if got := aggregate(outputs, "median"); !slices.Equal(got, []float32{2, 20}) {
+		t.Errorf("median = %v", got)
+	}
+}
+
+func TestParseFlags_ModelTTASettings(t *testing.T) {
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "model.zmf")
+	settings := `{"tta": 5, "tta_mode": "shift", "tta_max_shift": 2, "tta_aggregate": "median"}`
+	if err := os.WriteFile(modelPath+ttaFileSuffix, []byte(settings), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-data", "d.csv", "-model", modelPath, "-output", filepath.Join(dir, "out.csv")}
+
+	c, err := parseFlags(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.TTA != 5 || c.TTAMode != "shift" || c.TTAMaxShift != 2 || c.TTAAggregate != "median" || c.TTASeed != 1 {
+		t.Errorf("config = %+v, want the model's TTA settings", c)
+	}
+	// Flags override the file.
+	if c, err = parseFlags(append(args, "-tta", "0")); err != nil || c.TTA != 0 || c.TTAMode != "shift" {
+		t.Errorf("with -tta 0: TTA = %d, mode %q, err %v", c.TTA, c.TTAMode, err)
+	}
+
+	for _, tc := range []struct {
+		flags   []string
+		wantErr string
+	}{
+		{[]string{"-tta", "-1"}, "must not be negative"},
+		{[]string{"-tta-mode", "rotate"}, "unsupported TTA mode"},
+		{[]string{"-tta-aggregate", "max"}, "unsupported TTA aggregate"},
+		{[]string{"-tta-max-shift", "0"}, "max shift must be positive"},
+		{[]string{"-tta-mode", "dropout", "-tta-dropout", "1"}, "dropout must be in"},
+	} {
+		if _, err := parseFlags(append(slices.Clone(args), tc.flags...)); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%v: err = %v, want %q", tc.flags, err, tc.wantErr)
+		}
+	}
+
+	if err := os.WriteFile(modelPath+ttaFileSuffix, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseFlags(args); err == nil || !strings.Contains(err.Error(), "invalid TTA settings") {
+		t.Errorf("malformed settings: err = %v", err)
+	}
+}
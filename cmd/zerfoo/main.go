@@ -4,8 +4,10 @@ import (
 	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/zerfoo/zerfoo/cmd/cli"
+	datacache "github.com/zerfoo/zerfoo/data/cache"
 	"github.com/zerfoo/zerfoo/model"
 	"github.com/zerfoo/zerfoo/serve/shutdown"
 )
@@ -16,7 +18,7 @@ var version string
 func main() {
 	if err := run(); err != nil {
 		log.Printf("CLI execution failed: %v", err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
 
@@ -26,6 +28,18 @@ func run() error {
 	ctx, cancel := cli.SignalContext(context.Background(), coord)
 	defer cancel()
 
+	shutdownTracing, err := cli.SetupTracing(ctx, "zerfoo", version)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("flush traces: %v", err)
+		}
+	}()
+
 	// Create CLI application
 	cliApp := cli.NewCLI()
 
@@ -34,6 +48,9 @@ func run() error {
 
 	// Register commands
 	predictCmd := cli.NewPredictCommand(modelRegistry, func(f float64) float32 { return float32(f) }, func(v float32) float64 { return float64(v) })
+	if store, err := datacache.Open(""); err == nil {
+		predictCmd.SetDataCache(store)
+	}
 	cliApp.RegisterCommand(predictCmd)
 
 	tokenizeCmd := cli.NewTokenizeCommand()
@@ -42,6 +59,9 @@ func run() error {
 	workerCmd := cli.NewWorkerCommand(coord)
 	cliApp.RegisterCommand(workerCmd)
 
+	jobsCmd := cli.NewJobsCommand(os.Stdout)
+	cliApp.RegisterCommand(jobsCmd)
+
 	pullCmd := cli.NewPullCommand(nil, os.Stdout)
 	cliApp.RegisterCommand(pullCmd)
 
@@ -51,6 +71,9 @@ func run() error {
 	rmCmd := cli.NewRmCommand(nil, os.Stdout)
 	cliApp.RegisterCommand(rmCmd)
 
+	modelsCmd := cli.NewModelsCommand(os.Stdout)
+	cliApp.RegisterCommand(modelsCmd)
+
 	runCmd := cli.NewRunCommand(os.Stdin, os.Stdout)
 	cliApp.RegisterCommand(runCmd)
 
@@ -84,6 +107,30 @@ func run() error {
 	transcribeCmd := cli.NewTranscribeCommand(os.Stdout)
 	cliApp.RegisterCommand(transcribeCmd)
 
+	perplexityCmd := cli.NewPerplexityCommand(os.Stdout)
+	cliApp.RegisterCommand(perplexityCmd)
+
+	evalLMCmd := cli.NewEvalLMCommand(os.Stdout)
+	cliApp.RegisterCommand(evalLMCmd)
+
+	embedCmd := cli.NewEmbedCommand(os.Stdout)
+	cliApp.RegisterCommand(embedCmd)
+
+	tuneCmd := cli.NewTuneCommand(os.Stdout)
+	cliApp.RegisterCommand(tuneCmd)
+
+	doctorCmd := cli.NewDoctorCommand(os.Stdout)
+	cliApp.RegisterCommand(doctorCmd)
+
+	cacheCmd := cli.NewCacheCommand(os.Stdout)
+	cliApp.RegisterCommand(cacheCmd)
+
+	calibrationCmd := cli.NewCalibrationCommand(os.Stdout)
+	cliApp.RegisterCommand(calibrationCmd)
+
+	vocabCmd := cli.NewVocabCommand(os.Stdout)
+	cliApp.RegisterCommand(vocabCmd)
+
 	// Run CLI
 	return cliApp.Run(ctx, os.Args[1:])
 }
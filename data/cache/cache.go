@@ -0,0 +1,482 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxBytes is the default size budget of a Store (10 GiB).
+	DefaultMaxBytes = 10 << 30
+	// DefaultTTL is the default lifetime of a cache entry.
+	DefaultTTL = 7 * 24 * time.Hour
+)
+
+// Entry describes one cached artifact.
+type Entry struct {
+	// URI identifies the source, e.g. a URL or an absolute file path.
+	URI string `json:"uri"`
+	// SourceHash is the SHA-256 of the source content the entry was derived
+	// from. It is empty for the raw source itself.
+	SourceHash string `json:"source_hash,omitempty"`
+	// Variant names what the entry holds, e.g. "predict-csv"; empty for the
+	// raw source.
+	Variant string `json:"variant,omitempty"`
+	// Digest is the SHA-256 of the entry's content.
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+	Accessed time.Time `json:"accessed"`
+}
+
+// StoreOptions configures a Store.
+type StoreOptions struct {
+	// MaxBytes bounds the total size of cached content. Default: DefaultMaxBytes.
+	MaxBytes int64
+	// TTL is how long an entry stays valid after it is created. Zero or
+	// negative disables expiry. Default: DefaultTTL.
+	TTL time.Duration
+	// Now overrides the clock, for tests.
+	Now func() time.Time
+}
+
+// StoreOption configures a Store.
+type StoreOption func(*StoreOptions)
+
+// WithMaxBytes sets the size budget of the store.
+func WithMaxBytes(n int64) StoreOption {
+	return func(o *StoreOptions) { o.MaxBytes = n }
+}
+
+// WithTTL sets how long entries stay valid.
+func WithTTL(d time.Duration) StoreOption {
+	return func(o *StoreOptions) { o.TTL = d }
+}
+
+// WithClock overrides the time source.
+func WithClock(now func() time.Time) StoreOption {
+	return func(o *StoreOptions) { o.Now = now }
+}
+
+// Store is a dataset cache rooted at a directory. Content lives at
+// <dir>/blobs/<digest> and entries at <dir>/index/<key>.json. A Store is
+// safe for concurrent use within a process; writes are atomic renames, so
+// processes sharing a directory never observe partial content.
+type Store struct {
+	dir  string
+	opts StoreOptions
+	mu   sync.Mutex
+}
+
+// DefaultDir returns the default cache directory, ~/.zerfoo/datasets.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".zerfoo", "datasets"), nil
+}
+
+// Open opens the store at dir, creating it if needed. An empty dir selects
+// DefaultDir.
+func Open(dir string, opts ...StoreOption) (*Store, error) {
+	o := StoreOptions{MaxBytes: DefaultMaxBytes, TTL: DefaultTTL, Now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.MaxBytes <= 0 {
+		return nil, fmt.Errorf("cache: max bytes must be positive, got %d", o.MaxBytes)
+	}
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	for _, sub := range []string{"blobs", "index"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, fmt.Errorf("cache: create %s: %w", sub, err)
+		}
+	}
+	return &Store{dir: dir, opts: o}, nil
+}
+
+// Dir returns the store's root directory.
+func (s *Store) Dir() string { return s.dir }
+
+// Path returns the file holding e's content.
+func (s *Store) Path(e Entry) string {
+	return filepath.Join(s.dir, "blobs", e.Digest)
+}
+
+// Lookup returns the live entry for (uri, sourceHash, variant) and marks it
+// used. An expired entry is removed and reported as missing.
+func (s *Store) Lookup(uri, sourceHash, variant string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := entryKey(uri, sourceHash, variant)
+	e, err := s.readEntry(key)
+	if err != nil {
+		return Entry{}, false
+	}
+	if s.expired(e) {
+		_ = s.removeLocked(key)
+		return Entry{}, false
+	}
+	if _, err := os.Stat(s.Path(e)); err != nil {
+		_ = os.Remove(s.indexPath(key))
+		return Entry{}, false
+	}
+	e.Accessed = s.opts.Now()
+	_ = s.writeEntry(key, e)
+	return e, true
+}
+
+// Put stores the bytes produced by write as the entry for (uri, sourceHash,
+// variant), replacing any previous entry, then evicts other entries until
+// the store fits its size budget.
+func (s *Store) Put(uri, sourceHash, variant string, write func(w io.Writer) error) (Entry, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, "blobs"), ".put-*")
+	if err != nil {
+		return Entry{}, fmt.Errorf("cache: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone after a successful rename
+
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(tmp, h)}
+	if err := write(cw); err != nil {
+		tmp.Close() //nolint:errcheck
+		return Entry{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Entry{}, fmt.Errorf("cache: %w", err)
+	}
+
+	now := s.opts.Now()
+	e := Entry{
+		URI:        uri,
+		SourceHash: sourceHash,
+		Variant:    variant,
+		Digest:     hex.EncodeToString(h.Sum(nil)),
+		Size:       cw.n,
+		Created:    now,
+		Accessed:   now,
+	}
+	if e.Size > s.opts.MaxBytes {
+		return Entry{}, fmt.Errorf("cache: entry of %d bytes exceeds the %d byte budget", e.Size, s.opts.MaxBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := entryKey(uri, sourceHash, variant)
+	if old, err := s.readEntry(key); err == nil && old.Digest != e.Digest {
+		_ = s.removeLocked(key)
+	}
+	if err := os.Rename(tmp.Name(), s.Path(e)); err != nil {
+		return Entry{}, fmt.Errorf("cache: store blob: %w", err)
+	}
+	if err := s.writeEntry(key, e); err != nil {
+		return Entry{}, err
+	}
+	if _, err := s.pruneLocked(key); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Fetch returns the raw-source entry for uri, calling fetch to download it
+// on a miss or after the entry has expired.
+func (s *Store) Fetch(ctx context.Context, uri string, fetch func(ctx context.Context, w io.Writer) error) (Entry, error) {
+	if e, ok := s.Lookup(uri, "", ""); ok {
+		return e, nil
+	}
+	return s.Put(uri, "", "", func(w io.Writer) error { return fetch(ctx, w) })
+}
+
+// List returns every entry, most recently used first. Expired entries are
+// included; Prune removes them.
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, _, err := s.listLocked()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return b.Accessed.Compare(a.Accessed) })
+	return entries, nil
+}
+
+// Expired reports whether e has outlived the store's TTL.
+func (s *Store) Expired(e Entry) bool { return s.expired(e) }
+
+// Remove deletes every entry whose URI is uri and returns how many there
+// were.
+func (s *Store) Remove(uri string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, keys, err := s.listLocked()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i, e := range entries {
+		if e.URI != uri {
+			continue
+		}
+		if err := s.removeLocked(keys[i]); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Clear deletes every entry and its content.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range []string{"blobs", "index"} {
+		p := filepath.Join(s.dir, sub)
+		if err := os.RemoveAll(p); err != nil {
+			return fmt.Errorf("cache: clear: %w", err)
+		}
+		if err := os.MkdirAll(p, 0o750); err != nil {
+			return fmt.Errorf("cache: clear: %w", err)
+		}
+	}
+	return nil
+}
+
+// PruneStats reports what Prune removed.
+type PruneStats struct {
+	Expired int
+	Evicted int
+	Freed   int64
+}
+
+// Prune removes expired entries, then evicts the least recently used
+// entries until the store fits its size budget.
+func (s *Store) Prune() (PruneStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pruneLocked("")
+}
+
+// pruneLocked is Prune, never evicting the entry under keep.
+func (s *Store) pruneLocked(keep string) (PruneStats, error) {
+	var st PruneStats
+	entries, keys, err := s.listLocked()
+	if err != nil {
+		return st, err
+	}
+	before := usage(entries)
+
+	live := entries[:0]
+	liveKeys := keys[:0]
+	for i, e := range entries {
+		if keys[i] != keep && s.expired(e) {
+			if err := s.removeLocked(keys[i]); err != nil {
+				return st, err
+			}
+			st.Expired++
+			continue
+		}
+		live = append(live, e)
+		liveKeys = append(liveKeys, keys[i])
+	}
+
+	order := make([]int, len(live))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return live[a].Accessed.Compare(live[b].Accessed) })
+	removed := make([]bool, len(live))
+	for _, i := range order {
+		if usage(kept(live, removed)) <= s.opts.MaxBytes {
+			break
+		}
+		if liveKeys[i] == keep {
+			continue
+		}
+		if err := s.removeLocked(liveKeys[i]); err != nil {
+			return st, err
+		}
+		removed[i] = true
+		st.Evicted++
+	}
+	st.Freed = before - usage(kept(live, removed))
+	return st, nil
+}
+
+// Usage returns the number of entries and the bytes their content occupies.
+// Entries sharing content count it once.
+func (s *Store) Usage() (entries int, bytes int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, _, err := s.listLocked()
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(list), usage(list), nil
+}
+
+func (s *Store) expired(e Entry) bool {
+	return s.opts.TTL > 0 && s.opts.Now().Sub(e.Created) > s.opts.TTL
+}
+
+// listLocked reads every index entry, skipping unreadable ones, and returns
+// them with their keys.
+func (s *Store) listLocked() ([]Entry, []string, error) {
+	files, err := os.ReadDir(filepath.Join(s.dir, "index"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cache: %w", err)
+	}
+	var (
+		entries []Entry
+		keys    []string
+	)
+	for _, f := range files {
+		key, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok {
+			continue
+		}
+		e, err := s.readEntry(key)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, e)
+		keys = append(keys, key)
+	}
+	return entries, keys, nil
+}
+
+// removeLocked deletes the entry under key and its content unless another
+// entry shares it.
+func (s *Store) removeLocked(key string) error {
+	e, readErr := s.readEntry(key)
+	if err := os.Remove(s.indexPath(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if readErr != nil {
+		// Without the entry its content cannot be found; leave it.
+		return nil
+	}
+	entries, _, lerr := s.listLocked()
+	if lerr != nil {
+		return lerr
+	}
+	for _, other := range entries {
+		if other.Digest == e.Digest {
+			return nil
+		}
+	}
+	if err := os.Remove(s.Path(e)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cache: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) indexPath(key string) string {
+	return filepath.Join(s.dir, "index", key+".json")
+}
+
+func (s *Store) readEntry(key string) (Entry, error) {
+	var e Entry
+	data, err := os.ReadFile(s.indexPath(key)) //nolint:gosec // key is a hex digest
+	if err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, err
+	}
+	if len(e.Digest) != 64 {
+		return e, fmt.Errorf("cache: malformed entry %s", key)
+	}
+	return e, nil
+}
+
+func (s *Store) writeEntry(key string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmp := s.indexPath(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := os.Rename(tmp, s.indexPath(key)); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	return nil
+}
+
+// entryKey derives the index file name of an entry.
+func entryKey(uri, sourceHash, variant string) string {
+	h := sha256.New()
+	for _, part := range []string{uri, sourceHash, variant} {
+		h.Write([]byte(part)) //nolint:errcheck // hash.Hash never fails
+		h.Write([]byte{0})    //nolint:errcheck // hash.Hash never fails
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// usage sums the content size of entries, counting shared content once.
+func usage(entries []Entry) int64 {
+	seen := make(map[string]bool, len(entries))
+	var n int64
+	for _, e := range entries {
+		if !seen[e.Digest] {
+			seen[e.Digest] = true
+			n += e.Size
+		}
+	}
+	return n
+}
+
+func kept(entries []Entry, removed []bool) []Entry {
+	out := make([]Entry, 0, len(entries))
+	for i, e := range entries {
+		if !removed[i] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// HashFile returns the hex SHA-256 of the file at path, for use as the
+// SourceHash of entries derived from a local file.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // caller-provided path
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
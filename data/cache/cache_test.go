@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clock is a settable time source.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func put(t *testing.T, s *Store, uri, hash, variant, content string) Entry {
+	t.Helper()
+	e, err := s.Put(uri, hash, variant, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Put(%s): %v", uri, err)
+	}
+	return e
+}
+
+func TestStore_PutLookup(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := put(t, s, "https://example.com/a.csv", "h1", "parsed", "hello")
+	if e.Size != 5 || len(e.Digest) != 64 {
+		t.Fatalf("entry = %+v", e)
+	}
+
+	got, ok := s.Lookup("https://example.com/a.csv", "h1", "parsed")
+	if !ok || got.Digest != e.Digest {
+		t.Fatalf("Lookup = %+v, %v", got, ok)
+	}
+	data, _ := os.ReadFile(s.Path(got))
+	if string(data) != "hello" {
+		t.Errorf("content = %q", data)
+	}
+	if _, ok := s.Lookup("https://example.com/a.csv", "h2", "parsed"); ok {
+		t.Error("a different source hash must miss")
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	c := &clock{t: time.Unix(1000, 0)}
+	s, err := Open(t.TempDir(), WithTTL(time.Hour), WithClock(c.now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := put(t, s, "u", "", "", "data")
+
+	c.t = c.t.Add(30 * time.Minute)
+	if _, ok := s.Lookup("u", "", ""); !ok {
+		t.Fatal("entry should be live before its TTL")
+	}
+	c.t = c.t.Add(time.Hour)
+	if _, ok := s.Lookup("u", "", ""); ok {
+		t.Fatal("entry should expire after its TTL")
+	}
+	if _, err := os.Stat(s.Path(e)); !os.IsNotExist(err) {
+		t.Error("expired content should be removed")
+	}
+}
+
+func TestStore_Fetch(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	fetch := func(_ context.Context, w io.Writer) error {
+		calls++
+		_, err := io.WriteString(w, "remote")
+		return err
+	}
+	for range 2 {
+		if _, err := s.Fetch(context.Background(), "https://x/y", fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fetched %d times, want 1", calls)
+	}
+}
+
+func TestStore_SizeEviction(t *testing.T) {
+	c := &clock{t: time.Unix(1000, 0)}
+	s, err := Open(t.TempDir(), WithMaxBytes(10), WithClock(c.now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(t, s, "a", "", "", "aaaa")
+	c.t = c.t.Add(time.Second)
+	put(t, s, "b", "", "", "bbbb")
+	c.t = c.t.Add(time.Second)
+	// Touch a so b is the least recently used.
+	if _, ok := s.Lookup("a", "", ""); !ok {
+		t.Fatal("a missing")
+	}
+	c.t = c.t.Add(time.Second)
+	put(t, s, "c", "", "", "cccc")
+
+	if _, ok := s.Lookup("b", "", ""); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, uri := range []string{"a", "c"} {
+		if _, ok := s.Lookup(uri, "", ""); !ok {
+			t.Errorf("%s should still be cached", uri)
+		}
+	}
+	if n, bytes, _ := s.Usage(); n != 2 || bytes != 8 {
+		t.Errorf("Usage = %d entries, %d bytes", n, bytes)
+	}
+
+	if _, err := s.Put("big", "", "", func(w io.Writer) error {
+		_, err := io.WriteString(w, strings.Repeat("x", 11))
+		return err
+	}); err == nil {
+		t.Error("an entry larger than the budget should be rejected")
+	}
+}
+
+func TestStore_SharedContentAndRemove(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1 := put(t, s, "a", "", "", "same")
+	put(t, s, "b", "", "", "same")
+	if n, bytes, _ := s.Usage(); n != 2 || bytes != 4 {
+		t.Errorf("shared content should count once: %d entries, %d bytes", n, bytes)
+	}
+
+	if n, err := s.Remove("a"); err != nil || n != 1 {
+		t.Fatalf("Remove = %d, %v", n, err)
+	}
+	if _, err := os.Stat(s.Path(e1)); err != nil {
+		t.Error("content still referenced by b must survive")
+	}
+	if _, err := s.Remove("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.Path(e1)); !os.IsNotExist(err) {
+		t.Error("unreferenced content should be removed")
+	}
+}
+
+func TestStore_PruneAndClear(t *testing.T) {
+	c := &clock{t: time.Unix(1000, 0)}
+	dir := t.TempDir()
+	s, err := Open(dir, WithTTL(time.Minute), WithClock(c.now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(t, s, "old", "", "", "1")
+	c.t = c.t.Add(2 * time.Minute)
+
+	list, _ := s.List()
+	if len(list) != 1 || !s.Expired(list[0]) {
+		t.Fatalf("List = %+v", list)
+	}
+	st, err := s.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Expired != 1 || st.Freed != 1 {
+		t.Errorf("Prune = %+v", st)
+	}
+	put(t, s, "new", "", "", "22")
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _, _ := s.Usage(); n != 0 {
+		t.Errorf("%d entries after Clear", n)
+	}
+	if blobs, _ := os.ReadDir(filepath.Join(dir, "blobs")); len(blobs) != 0 {
+		t.Errorf("%d blobs after Clear", len(blobs))
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(p, []byte("abc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h, err := HashFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("HashFile = %s", h)
+	}
+}
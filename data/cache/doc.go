@@ -0,0 +1,9 @@
+// Package cache provides a local dataset cache. Entries are keyed by source
+// URI, the content hash of the source, and a variant naming what was derived
+// from it, so providers skip re-downloading a remote source until its entry
+// expires and skip re-parsing a source whose content has not changed. Entry
+// contents are stored once by SHA-256 digest, expire after a TTL, and are
+// evicted least-recently-used first once the cache exceeds its size budget.
+//
+// Stability: alpha
+package cache
@@ -0,0 +1,13 @@
+// Package parquet reads and writes flat Parquet files without external
+// dependencies. A File reads the footer when opened and then one row group
+// at a time, decoding only the columns asked for, so large files stream in
+// bounded memory. A Writer buffers a row group of rows and writes it
+// PLAIN-encoded and uncompressed.
+//
+// Only flat schemas of required and optional columns are supported. The
+// reader handles v1 and v2 data pages, dictionary pages, the PLAIN,
+// PLAIN_DICTIONARY and RLE_DICTIONARY encodings, and UNCOMPRESSED, SNAPPY
+// and GZIP compression; other features return an error naming them.
+//
+// Stability: alpha
+package parquet
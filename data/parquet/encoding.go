@@ -0,0 +1,393 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Values holds the values of one column of a row group, one per row. Null
+// rows of optional columns hold the zero value.
+type Values struct {
+	typ    Type
+	nulls  []bool
+	ints   []int64
+	floats []float64
+	strs   []string
+}
+
+func newValues(typ Type, capacity int) *Values {
+	v := &Values{typ: typ}
+	switch typ {
+	case Boolean, Int32, Int64:
+		v.ints = make([]int64, 0, capacity)
+	case Float, Double:
+		v.floats = make([]float64, 0, capacity)
+	default:
+		v.strs = make([]string, 0, capacity)
+	}
+	return v
+}
+
+// Type returns the column's physical type.
+func (v *Values) Type() Type {
+	return v.typ
+}
+
+// Len returns the number of rows.
+func (v *Values) Len() int {
+	return len(v.ints) + len(v.floats) + len(v.strs)
+}
+
+// IsNull reports whether row i is null.
+func (v *Values) IsNull(i int) bool {
+	return v.nulls != nil && v.nulls[i]
+}
+
+// Float64 returns row i as a float64: NaN for nulls and for columns that
+// are not Numeric.
+func (v *Values) Float64(i int) float64 {
+	switch {
+	case v.IsNull(i):
+		return math.NaN()
+	case v.ints != nil:
+		return float64(v.ints[i])
+	case v.floats != nil:
+		return v.floats[i]
+	default:
+		return math.NaN()
+	}
+}
+
+// Text returns row i as text: the empty string for nulls, integers in
+// decimal, floats in the shortest form that parses back to the same value,
+// and byte arrays as they are.
+func (v *Values) Text(i int) string {
+	if v.IsNull(i) {
+		return ""
+	}
+	switch v.typ {
+	case Boolean:
+		return strconv.FormatBool(v.ints[i] != 0)
+	case Int32, Int64:
+		return strconv.FormatInt(v.ints[i], 10)
+	case Float:
+		return strconv.FormatFloat(v.floats[i], 'g', -1, 32)
+	case Double:
+		return strconv.FormatFloat(v.floats[i], 'g', -1, 64)
+	default:
+		return v.strs[i]
+	}
+}
+
+// appendNull appends a null row.
+func (v *Values) appendNull() {
+	if v.nulls == nil {
+		v.nulls = make([]bool, v.Len(), cap(v.ints)+cap(v.floats)+cap(v.strs))
+	}
+	v.nulls = append(v.nulls, true)
+	v.appendZero()
+}
+
+func (v *Values) appendZero() {
+	switch {
+	case v.ints != nil:
+		v.ints = append(v.ints, 0)
+	case v.floats != nil:
+		v.floats = append(v.floats, 0)
+	default:
+		v.strs = append(v.strs, "")
+	}
+}
+
+// appendFrom appends row i of src, a column of the same type.
+func (v *Values) appendFrom(src *Values, i int) {
+	if v.nulls != nil {
+		v.nulls = append(v.nulls, false)
+	}
+	switch {
+	case v.ints != nil:
+		v.ints = append(v.ints, src.ints[i])
+	case v.floats != nil:
+		v.floats = append(v.floats, src.floats[i])
+	default:
+		v.strs = append(v.strs, src.strs[i])
+	}
+}
+
+// decodePlain decodes n PLAIN-encoded values from b into a new Values.
+// length is the size of FixedLenByteArray values.
+//
+// n comes from untrusted page headers, so it is checked against len(b)
+// before anything is allocated: each value takes size bytes, a bit for
+// booleans, or at least its 4-byte length prefix for byte arrays.
+func decodePlain(typ Type, length int, b []byte, n int) (*Values, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("parquet: %d values", n)
+	}
+	if typ == FixedLenByteArray && length <= 0 {
+		return nil, fmt.Errorf("parquet: fixed-length values of length %d", length)
+	}
+	size := map[Type]int{Int32: 4, Int64: 8, Int96: 12, Float: 4, Double: 8, FixedLenByteArray: length}[typ]
+	switch {
+	case typ == Boolean:
+		size = 0
+		if len(b)*8 < n {
+			return nil, errors.New("parquet: truncated booleans")
+		}
+	case typ == ByteArray:
+		if len(b)/4 < n {
+			return nil, fmt.Errorf("parquet: %d bytes for %d %s values", len(b), n, typ)
+		}
+	case len(b)/size < n:
+		return nil, fmt.Errorf("parquet: %d bytes for %d %s values", len(b), n, typ)
+	}
+	v := newValues(typ, n)
+	for i := range n {
+		switch typ {
+		case Boolean:
+			v.ints = append(v.ints, int64(b[i/8]>>(i%8)&1))
+		case Int32:
+			v.ints = append(v.ints, int64(int32(binary.LittleEndian.Uint32(b[4*i:]))))
+		case Int64:
+			v.ints = append(v.ints, int64(binary.LittleEndian.Uint64(b[8*i:])))
+		case Float:
+			v.floats = append(v.floats, float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))))
+		case Double:
+			v.floats = append(v.floats, math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:])))
+		case Int96, FixedLenByteArray:
+			v.strs = append(v.strs, string(b[i*size:(i+1)*size]))
+		case ByteArray:
+			if len(b) < 4 {
+				return nil, errors.New("parquet: truncated byte array")
+			}
+			l := binary.LittleEndian.Uint32(b)
+			if uint64(l) > uint64(len(b)-4) {
+				return nil, errors.New("parquet: truncated byte array")
+			}
+			v.strs = append(v.strs, string(b[4:4+l]))
+			b = b[4+l:]
+		}
+	}
+	return v, nil
+}
+
+// appendPlain appends rows from..to of v, skipping nulls, PLAIN-encoded.
+func appendPlain(dst []byte, v *Values, from, to int) []byte {
+	var bits byte
+	nbits := 0
+	for i := from; i < to; i++ {
+		if v.IsNull(i) {
+			continue
+		}
+		switch v.typ {
+		case Boolean:
+			bits |= byte(v.ints[i]) << nbits
+			if nbits++; nbits == 8 {
+				dst, bits, nbits = append(dst, bits), 0, 0
+			}
+		case Int32:
+			dst = binary.LittleEndian.AppendUint32(dst, uint32(v.ints[i]))
+		case Int64:
+			dst = binary.LittleEndian.AppendUint64(dst, uint64(v.ints[i]))
+		case Float:
+			dst = binary.LittleEndian.AppendUint32(dst, math.Float32bits(float32(v.floats[i])))
+		case Double:
+			dst = binary.LittleEndian.AppendUint64(dst, math.Float64bits(v.floats[i]))
+		case ByteArray:
+			dst = binary.LittleEndian.AppendUint32(dst, uint32(len(v.strs[i])))
+			dst = append(dst, v.strs[i]...)
+		default:
+			dst = append(dst, v.strs[i]...)
+		}
+	}
+	if nbits > 0 {
+		dst = append(dst, bits)
+	}
+	return dst
+}
+
+// decodeHybrid decodes n values of bitWidth bits from the RLE/bit-packing
+// hybrid encoding used for levels and dictionary indices.
+func decodeHybrid(b []byte, bitWidth, n int) ([]int, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, fmt.Errorf("parquet: bit width %d", bitWidth)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("parquet: %d values", n)
+	}
+	// RLE runs may expand past len(b)*8 values; the slice grows for them.
+	out := make([]int, 0, min(n, len(b)*8))
+	byteWidth := (bitWidth + 7) / 8
+	for len(out) < n {
+		h, k := binary.Uvarint(b)
+		if k <= 0 {
+			return nil, errors.New("parquet: truncated run")
+		}
+		b = b[k:]
+		if h&1 == 0 {
+			// A run of one repeated value.
+			if len(b) < byteWidth {
+				return nil, errors.New("parquet: truncated run")
+			}
+			var v int
+			for i := range byteWidth {
+				v |= int(b[i]) << (8 * i)
+			}
+			b = b[byteWidth:]
+			for range min(h>>1, uint64(n-len(out))) {
+				out = append(out, v)
+			}
+			continue
+		}
+		// Groups of 8 bit-packed values, least significant bit first. The
+		// last group may be cut short at the end of the data.
+		count := min(h>>1*8, uint64(n-len(out)))
+		if count*uint64(bitWidth) > uint64(len(b))*8 {
+			return nil, errors.New("parquet: truncated bit-packed run")
+		}
+		for j := range int(count) {
+			v := 0
+			for bit := range bitWidth {
+				off := j*bitWidth + bit
+				v |= int(b[off/8]>>(off%8)&1) << bit
+			}
+			out = append(out, v)
+		}
+		b = b[min(h>>1*uint64(bitWidth), uint64(len(b))):]
+	}
+	return out, nil
+}
+
+// appendLevels appends the definition levels of rows from..to of v, 1 for
+// values and 0 for nulls, as RLE runs of bit width 1.
+func appendLevels(dst []byte, v *Values, from, to int) []byte {
+	for i := from; i < to; {
+		j := i + 1
+		for j < to && v.IsNull(j) == v.IsNull(i) {
+			j++
+		}
+		dst = binary.AppendUvarint(dst, uint64(j-i)<<1)
+		if v.IsNull(i) {
+			dst = append(dst, 0)
+		} else {
+			dst = append(dst, 1)
+		}
+		i = j
+	}
+	return dst
+}
+
+// Codecs expand data by at most these factors, so a page header claiming a
+// larger uncompressed size is corrupt and is rejected before allocating.
+const (
+	maxSnappyRatio = 32   // a 3-byte copy yields at most 64 bytes
+	maxGzipRatio   = 1032 // DEFLATE's limit
+)
+
+// decompress returns the size bytes that b decompresses to with codec.
+func decompress(codec int64, b []byte, size int) ([]byte, error) {
+	switch codec {
+	case codecUncompressed:
+		return b, nil
+	case codecSnappy:
+		return snappyDecode(b, size)
+	case codecGzip:
+		if size < 0 || size/maxGzipRatio > len(b) {
+			return nil, fmt.Errorf("parquet: gzip: %d bytes cannot decompress to %d", len(b), size)
+		}
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("parquet: gzip: %w", err)
+		}
+		out := make([]byte, size)
+		if _, err := io.ReadFull(r, out); err != nil {
+			return nil, fmt.Errorf("parquet: gzip: %w", err)
+		}
+		return out, nil
+	default:
+		name := fmt.Sprint(codec)
+		if codec >= 0 && codec < int64(len(codecNames)) {
+			name = codecNames[codec]
+		}
+		return nil, fmt.Errorf("parquet: %s compression not supported", name)
+	}
+}
+
+var errSnappy = errors.New("parquet: corrupt snappy data")
+
+// snappyDecode decodes a snappy block of the expected size.
+func snappyDecode(b []byte, size int) ([]byte, error) {
+	n, k := binary.Uvarint(b)
+	if k <= 0 || size < 0 || n != uint64(size) || size/maxSnappyRatio > len(b) {
+		return nil, errSnappy
+	}
+	b = b[k:]
+	out := make([]byte, 0, size)
+	for len(b) > 0 {
+		tag := b[0]
+		var length, offset int
+		switch tag & 3 {
+		case 0:
+			length = int(tag>>2) + 1
+			b = b[1:]
+			if length > 60 {
+				// The length takes the next length-60 bytes.
+				nb := length - 60
+				if len(b) < nb {
+					return nil, errSnappy
+				}
+				length = 0
+				for i := range nb {
+					length |= int(b[i]) << (8 * i)
+				}
+				length++
+				b = b[nb:]
+			}
+			if length <= 0 || length > len(b) || len(out)+length > size {
+				return nil, errSnappy
+			}
+			out = append(out, b[:length]...)
+			b = b[length:]
+			continue
+		case 1:
+			if len(b) < 2 {
+				return nil, errSnappy
+			}
+			length = 4 + int(tag>>2&7)
+			offset = int(tag>>5)<<8 | int(b[1])
+			b = b[2:]
+		case 2:
+			if len(b) < 3 {
+				return nil, errSnappy
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(b[1:]))
+			b = b[3:]
+		case 3:
+			if len(b) < 5 {
+				return nil, errSnappy
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(b[1:]))
+			b = b[5:]
+		}
+		if offset <= 0 || offset > len(out) || len(out)+length > size {
+			return nil, errSnappy
+		}
+		// Copies may overlap their own output.
+		start := len(out) - offset
+		for i := range length {
+			out = append(out, out[start+i])
+		}
+	}
+	if len(out) != size {
+		return nil, errSnappy
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// validParquetSeed writes a small file with a required and an optional
+// column over two row groups.
+func validParquetSeed(t testing.TB) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{
+		{Name: "x", Type: Double},
+		{Name: "s", Type: ByteArray, String: true, Optional: true},
+	}, WithRowGroupSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range []any{"a", nil, "c"} {
+		if err := w.Write(float64(i), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// FuzzReadRowGroup checks that no file, however its metadata and pages are
+// corrupted, makes Open or ReadRowGroup panic or allocate without bound.
+func FuzzReadRowGroup(f *testing.F) {
+	seed := validParquetSeed(f)
+	f.Add(seed)
+	f.Add(seed[len(seed)-40:])
+	f.Fuzz(func(t *testing.T, b []byte) {
+		pf, err := Open(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return
+		}
+		columns := make([]int, len(pf.Columns()))
+		for i := range columns {
+			columns[i] = i
+		}
+		for g := range pf.NumRowGroups() {
+			_, _ = pf.ReadRowGroup(g, columns)
+		}
+	})
+}
+
+func TestReadRowGroup_UntrustedSizes(t *testing.T) {
+	good := validParquetSeed(t)
+	open := func() *File {
+		t.Helper()
+		f, err := Open(bytes.NewReader(good), int64(len(good)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	for name, corrupt := range map[string]func(cm *chunkMeta){
+		"size past footer": func(cm *chunkMeta) { cm.size = 1 << 40 },
+		"negative size":    func(cm *chunkMeta) { cm.size = -1 },
+		"offset past end":  func(cm *chunkMeta) { cm.dataOffset = 1 << 40 },
+		"offset in magic":  func(cm *chunkMeta) { cm.dataOffset = 0 },
+	} {
+		f := open()
+		corrupt(&f.groups[0].chunks[0])
+		if _, err := f.ReadRowGroup(0, []int{0}); err == nil {
+			t.Errorf("%s: ReadRowGroup succeeded", name)
+		}
+	}
+
+	// Every truncation of the file fails cleanly.
+	for n := range len(good) {
+		f, err := Open(bytes.NewReader(good[:n]), int64(n))
+		if err != nil {
+			continue
+		}
+		for g := range f.NumRowGroups() {
+			_, _ = f.ReadRowGroup(g, []int{0, 1})
+		}
+	}
+
+	// Sizes and counts from page headers are checked before allocating.
+	if _, err := decompress(codecGzip, gzipped(t, []byte("abc")), 1<<40); err == nil {
+		t.Error("gzip page claiming 1 TiB accepted")
+	}
+	if _, err := snappyDecode(append(binary.AppendUvarint(nil, 1<<40), 0), 1<<40); err == nil {
+		t.Error("snappy page claiming 1 TiB accepted")
+	}
+	if _, err := decodePlain(ByteArray, 0, make([]byte, 8), 1<<40); err == nil {
+		t.Error("1<<40 byte arrays from 8 bytes accepted")
+	}
+	if _, err := decodePlain(Double, 0, make([]byte, 8), -1); err == nil {
+		t.Error("negative value count accepted")
+	}
+	if _, err := decodeHybrid([]byte{2, 1}, 1, -1); err == nil {
+		t.Error("negative level count accepted")
+	}
+}
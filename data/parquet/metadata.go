@@ -0,0 +1,300 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Type is a Parquet physical type.
+type Type int32
+
+// Physical types.
+const (
+	Boolean           Type = 0
+	Int32             Type = 1
+	Int64             Type = 2
+	Int96             Type = 3
+	Float             Type = 4
+	Double            Type = 5
+	ByteArray         Type = 6
+	FixedLenByteArray Type = 7
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "BOOLEAN"
+	case Int32:
+		return "INT32"
+	case Int64:
+		return "INT64"
+	case Int96:
+		return "INT96"
+	case Float:
+		return "FLOAT"
+	case Double:
+		return "DOUBLE"
+	case ByteArray:
+		return "BYTE_ARRAY"
+	case FixedLenByteArray:
+		return "FIXED_LEN_BYTE_ARRAY"
+	default:
+		return fmt.Sprintf("Type(%d)", int32(t))
+	}
+}
+
+// Numeric reports whether values of the type convert to float64.
+func (t Type) Numeric() bool {
+	switch t {
+	case Boolean, Int32, Int64, Float, Double:
+		return true
+	default:
+		return false
+	}
+}
+
+// Column describes a column of a flat schema.
+type Column struct {
+	Name string
+	Type Type
+	// Optional columns may hold nulls.
+	Optional bool
+	// String marks a ByteArray column as UTF-8 text.
+	String bool
+	// Length is the size in bytes of FixedLenByteArray values.
+	Length int
+}
+
+// Repetition types.
+const (
+	required = 0
+	optional = 1
+	repeated = 2
+)
+
+// Page types.
+const (
+	dataPage       = 0
+	indexPage      = 1
+	dictionaryPage = 2
+	dataPageV2     = 3
+)
+
+// Encodings.
+const (
+	encPlain           = 0
+	encPlainDictionary = 2
+	encRLE             = 3
+	encRLEDictionary   = 8
+)
+
+// Compression codecs.
+const (
+	codecUncompressed = 0
+	codecSnappy       = 1
+	codecGzip         = 2
+)
+
+var codecNames = []string{"UNCOMPRESSED", "SNAPPY", "GZIP", "LZO", "BROTLI", "LZ4", "ZSTD", "LZ4_RAW"}
+
+// convertedUTF8 is the UTF8 converted type of string columns.
+const convertedUTF8 = 0
+
+// chunkMeta is the part of a ColumnMetaData the reader uses.
+type chunkMeta struct {
+	typ        Type
+	codec      int64
+	numValues  int64
+	dataOffset int64
+	dictOffset int64
+	size       int64
+}
+
+// rowGroup is the part of a RowGroup the reader uses.
+type rowGroup struct {
+	numRows int64
+	chunks  []chunkMeta
+}
+
+// parseFooter decodes a FileMetaData into the file's columns and row groups.
+func parseFooter(b []byte) ([]Column, []rowGroup, error) {
+	fm, _, err := decodeStruct(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema := fm.list(2)
+	if len(schema) == 0 {
+		return nil, nil, errors.New("parquet: no schema")
+	}
+	var columns []Column
+	for i, e := range schema[1:] {
+		el, ok := e.(tstruct)
+		if !ok {
+			return nil, nil, errors.New("parquet: malformed schema")
+		}
+		name := el.str(4)
+		if el.int(5) > 0 || !el.has(1) {
+			return nil, nil, fmt.Errorf("parquet: nested column %q not supported", name)
+		}
+		if el.int(3) == repeated {
+			return nil, nil, fmt.Errorf("parquet: repeated column %q not supported", name)
+		}
+		c := Column{
+			Name:     name,
+			Type:     Type(el.int(1)),
+			Optional: el.int(3) == optional,
+			Length:   int(el.int(2)),
+		}
+		if c.Type == ByteArray {
+			c.String = el.has(6) && el.int(6) == convertedUTF8 || el.strct(10).has(1)
+		}
+		if c.Type < Boolean || c.Type > FixedLenByteArray {
+			return nil, nil, fmt.Errorf("parquet: column %d has unknown type %d", i, c.Type)
+		}
+		columns = append(columns, c)
+	}
+	if root, _ := schema[0].(tstruct); root.int(5) != int64(len(columns)) {
+		return nil, nil, errors.New("parquet: nested schemas not supported")
+	}
+
+	var groups []rowGroup
+	for _, g := range fm.list(4) {
+		rg, ok := g.(tstruct)
+		if !ok {
+			return nil, nil, errors.New("parquet: malformed row group")
+		}
+		chunks := rg.list(1)
+		if len(chunks) != len(columns) {
+			return nil, nil, fmt.Errorf("parquet: row group has %d columns, schema %d", len(chunks), len(columns))
+		}
+		group := rowGroup{numRows: rg.int(3)}
+		if group.numRows < 0 || group.numRows > math.MaxInt32 {
+			return nil, nil, fmt.Errorf("parquet: row group of %d rows", group.numRows)
+		}
+		for i, c := range chunks {
+			cc, _ := c.(tstruct)
+			md := cc.strct(3)
+			if cc == nil || md == nil {
+				return nil, nil, errors.New("parquet: column chunk without metadata")
+			}
+			if cc.str(1) != "" {
+				return nil, nil, errors.New("parquet: column chunks in other files not supported")
+			}
+			cm := chunkMeta{
+				typ:        Type(md.int(1)),
+				codec:      md.int(4),
+				numValues:  md.int(5),
+				dataOffset: md.int(9),
+				dictOffset: md.int(11),
+				size:       md.int(7),
+			}
+			if cm.typ != columns[i].Type {
+				return nil, nil, fmt.Errorf("parquet: column %q chunk has type %s", columns[i].Name, cm.typ)
+			}
+			group.chunks = append(group.chunks, cm)
+		}
+		groups = append(groups, group)
+	}
+	return columns, groups, nil
+}
+
+// writtenChunk records a column chunk for the footer.
+type writtenChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// writtenGroup records a row group for the footer.
+type writtenGroup struct {
+	numRows int64
+	size    int64
+	chunks  []writtenChunk
+}
+
+// encodeFooter encodes the FileMetaData of a file written with columns.
+func encodeFooter(columns []Column, groups []writtenGroup) []byte {
+	var numRows int64
+	for _, g := range groups {
+		numRows += g.numRows
+	}
+	e := &encoder{}
+	e.begin()
+	e.i32(1, 1)
+	e.list(2, tStruct, len(columns)+1)
+	e.begin()
+	e.str(4, "schema")
+	e.i32(5, int32(len(columns)))
+	e.end()
+	for _, c := range columns {
+		e.begin()
+		e.i32(1, int32(c.Type))
+		if c.Type == FixedLenByteArray {
+			e.i32(2, int32(c.Length))
+		}
+		rep := int32(required)
+		if c.Optional {
+			rep = optional
+		}
+		e.i32(3, rep)
+		e.str(4, c.Name)
+		if c.String {
+			e.i32(6, convertedUTF8)
+			e.structField(10)
+			e.structField(1)
+			e.end()
+			e.end()
+		}
+		e.end()
+	}
+	e.i64(3, numRows)
+	e.list(4, tStruct, len(groups))
+	for _, g := range groups {
+		e.begin()
+		e.list(1, tStruct, len(g.chunks))
+		for i, c := range g.chunks {
+			e.begin()
+			e.i64(2, c.offset)
+			e.structField(3)
+			e.i32(1, int32(columns[i].Type))
+			e.list(2, tI32, 2)
+			e.varint(encPlain)
+			e.varint(encRLE)
+			e.list(3, tBinary, 1)
+			e.uvarint(uint64(len(columns[i].Name)))
+			e.buf.WriteString(columns[i].Name)
+			e.i32(4, codecUncompressed)
+			e.i64(5, c.numValues)
+			e.i64(6, c.size)
+			e.i64(7, c.size)
+			e.i64(9, c.offset)
+			e.end()
+			e.end()
+		}
+		e.i64(2, g.size)
+		e.i64(3, g.numRows)
+		e.end()
+	}
+	e.str(6, "zerfoo")
+	e.end()
+	return e.buf.Bytes()
+}
+
+// encodePageHeader encodes the header of an uncompressed data page of n
+// values, nulls included, with size bytes of levels and values.
+func encodePageHeader(n, size int) []byte {
+	e := &encoder{}
+	e.begin()
+	e.i32(1, dataPage)
+	e.i32(2, int32(size))
+	e.i32(3, int32(size))
+	e.structField(5)
+	e.i32(1, int32(n))
+	e.i32(2, encPlain)
+	e.i32(3, encRLE)
+	e.i32(4, encRLE)
+	e.end()
+	e.end()
+	return e.buf.Bytes()
+}
@@ -0,0 +1,398 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriterReader_Roundtrip(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Type: ByteArray, String: true},
+		{Name: "n", Type: Int64},
+		{Name: "small", Type: Int32},
+		{Name: "x", Type: Float, Optional: true},
+		{Name: "y", Type: Double},
+		{Name: "ok", Type: Boolean, Optional: true},
+		{Name: "raw", Type: ByteArray, Optional: true},
+	}
+	path := filepath.Join(t.TempDir(), "rows.parquet")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(out, columns, WithRowGroupSize(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const rows = 10
+	for i := range rows {
+		var x, ok, raw any = float32(i) / 2, i%3 == 0, []byte{byte(i)}
+		if i%4 == 1 {
+			x, ok, raw = nil, nil, nil
+		}
+		if err := w.Write("row"+string(rune('a'+i)), i*1000, int32(-i), x, float64(i)*0.1, ok, raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	got := f.Columns()
+	for i := range columns {
+		if got[i] != columns[i] {
+			t.Errorf("column %d = %+v, want %+v", i, got[i], columns[i])
+		}
+	}
+	if f.NumRows() != rows || f.NumRowGroups() != 3 {
+		t.Fatalf("NumRows = %d, NumRowGroups = %d, want 10 and 3", f.NumRows(), f.NumRowGroups())
+	}
+	if f.ColumnIndex("y") != 4 || f.ColumnIndex("z") != -1 {
+		t.Errorf("ColumnIndex(y, z) = %d, %d", f.ColumnIndex("y"), f.ColumnIndex("z"))
+	}
+
+	row := 0
+	for g := range f.NumRowGroups() {
+		// Columns are read in the order asked for.
+		vs, err := f.ReadRowGroup(g, []int{6, 5, 4, 3, 2, 1, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range vs[0].Len() {
+			null := row%4 == 1
+			if got, want := vs[6].Text(i), "row"+string(rune('a'+row)); got != want {
+				t.Errorf("id[%d] = %q, want %q", row, got, want)
+			}
+			if got := vs[5].Float64(i); got != float64(row*1000) {
+				t.Errorf("n[%d] = %v", row, got)
+			}
+			if got := vs[4].Text(i); got != "-"+string(rune('0'+row)) && row != 0 {
+				t.Errorf("small[%d] = %q", row, got)
+			}
+			if got := vs[2].Float64(i); got != float64(row)*0.1 {
+				t.Errorf("y[%d] = %v", row, got)
+			}
+			if vs[3].IsNull(i) != null || vs[1].IsNull(i) != null || vs[0].IsNull(i) != null {
+				t.Errorf("row %d nulls = %v %v %v, want %v", row, vs[3].IsNull(i), vs[1].IsNull(i), vs[0].IsNull(i), null)
+			}
+			if null {
+				if !math.IsNaN(vs[3].Float64(i)) || vs[0].Text(i) != "" {
+					t.Errorf("null row %d = %v, %q", row, vs[3].Float64(i), vs[0].Text(i))
+				}
+			} else {
+				if got := vs[3].Text(i); got != formatHalf(row) {
+					t.Errorf("x[%d] = %q, want %q", row, got, formatHalf(row))
+				}
+				if got := vs[1].Float64(i) == 1; got != (row%3 == 0) {
+					t.Errorf("ok[%d] = %v", row, got)
+				}
+				if got := vs[0].Text(i); got != string([]byte{byte(row)}) {
+					t.Errorf("raw[%d] = %q", row, got)
+				}
+			}
+			row++
+		}
+	}
+	if row != rows {
+		t.Errorf("read %d rows, want %d", row, rows)
+	}
+}
+
+func formatHalf(i int) string {
+	if i%2 == 0 {
+		return string(rune('0' + i/2))
+	}
+	return string(rune('0'+i/2)) + ".5"
+}
+
+// snappyLiteral encodes b as a snappy block of one literal.
+func snappyLiteral(b []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(len(b)))
+	out = append(out, byte(len(b)-1)<<2)
+	return append(out, b...)
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pageHeader encodes a page header; body encodes the type-specific header
+// as field id.
+func pageHeader(typ int32, usize, csize int, id int16, body func(e *encoder)) []byte {
+	e := &encoder{}
+	e.begin()
+	e.i32(1, typ)
+	e.i32(2, int32(usize))
+	e.i32(3, int32(csize))
+	e.structField(id)
+	body(e)
+	e.end()
+	e.end()
+	return e.buf.Bytes()
+}
+
+// TestFile_Encodings reads a hand-built file in the forms other writers
+// produce: a snappy-compressed dictionary column in a v2 page, and a gzip
+// column in a v1 page.
+func TestFile_Encodings(t *testing.T) {
+	file := []byte(magic)
+
+	// Column "s": optional strings from the dictionary ["lo", "hi"], rows
+	// hi, null, lo, hi, hi.
+	dictOffset := int64(len(file))
+	dict := snappyLiteral([]byte("\x02\x00\x00\x00lo\x02\x00\x00\x00hi"))
+	file = append(file, pageHeader(dictionaryPage, 12, len(dict), 7, func(e *encoder) {
+		e.i32(1, 2)
+		e.i32(2, encPlainDictionary)
+	})...)
+	file = append(file, dict...)
+	dataOffset := int64(len(file))
+	// Definition levels 1,0,1,1,1 as one bit-packed group, unprefixed in
+	// a v2 page; then indices 1,0,1,1 as a bit width and a bit-packed group.
+	levels := []byte{1<<1 | 1, 0b11101}
+	values := []byte{1, 1<<1 | 1, 0b1101}
+	cvalues := snappyLiteral(values)
+	file = append(file, pageHeader(dataPageV2, len(levels)+len(values), len(levels)+len(cvalues), 8, func(e *encoder) {
+		e.i32(1, 5)
+		e.i32(2, 1)
+		e.i32(3, 5)
+		e.i32(4, encRLEDictionary)
+		e.i32(5, int32(len(levels)))
+		e.i32(6, 0)
+	})...)
+	file = append(file, levels...)
+	file = append(file, cvalues...)
+	sSize := int64(len(file)) - dictOffset
+
+	// Column "v": required doubles 0.5, 1.5, 2.5, 3.5, 4.5, gzipped, split
+	// over two v1 pages.
+	vOffset := int64(len(file))
+	for _, page := range [][]float64{{0.5, 1.5}, {2.5, 3.5, 4.5}} {
+		var raw []byte
+		for _, f := range page {
+			raw = binary.LittleEndian.AppendUint64(raw, math.Float64bits(f))
+		}
+		z := gzipped(t, raw)
+		file = append(file, pageHeader(dataPage, len(raw), len(z), 5, func(e *encoder) {
+			e.i32(1, int32(len(page)))
+			e.i32(2, encPlain)
+			e.i32(3, encRLE)
+			e.i32(4, encRLE)
+		})...)
+		file = append(file, z...)
+	}
+	vSize := int64(len(file)) - vOffset
+
+	e := &encoder{}
+	e.begin()
+	e.i32(1, 2)
+	e.list(2, tStruct, 3)
+	e.begin()
+	e.str(4, "schema")
+	e.i32(5, 2)
+	e.end()
+	e.begin()
+	e.i32(1, int32(ByteArray))
+	e.i32(3, optional)
+	e.str(4, "s")
+	e.structField(10)
+	e.structField(1)
+	e.end()
+	e.end()
+	e.end()
+	e.begin()
+	e.i32(1, int32(Double))
+	e.i32(3, required)
+	e.str(4, "v")
+	e.end()
+	e.i64(3, 5)
+	e.list(4, tStruct, 1)
+	e.begin()
+	e.list(1, tStruct, 2)
+	for _, c := range []struct {
+		typ              Type
+		codec            int32
+		data, dict, size int64
+	}{{ByteArray, codecSnappy, dataOffset, dictOffset, sSize}, {Double, codecGzip, vOffset, 0, vSize}} {
+		e.begin()
+		e.i64(2, c.data)
+		e.structField(3)
+		e.i32(1, int32(c.typ))
+		e.i32(4, c.codec)
+		e.i64(5, 5)
+		e.i64(7, c.size)
+		e.i64(9, c.data)
+		if c.dict > 0 {
+			e.i64(11, c.dict)
+		}
+		e.end()
+		e.end()
+	}
+	e.i64(3, 5)
+	e.end()
+	e.end()
+	footer := e.buf.Bytes()
+	file = append(file, footer...)
+	file = binary.LittleEndian.AppendUint32(file, uint32(len(footer)))
+	file = append(file, magic...)
+
+	f, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := f.Columns(); !c[0].String || !c[0].Optional || c[1].Type != Double {
+		t.Errorf("columns = %+v", c)
+	}
+	vs, err := f.ReadRowGroup(0, []int{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s []string
+	for i := range vs[0].Len() {
+		s = append(s, vs[0].Text(i))
+	}
+	if got := strings.Join(s, ","); got != "hi,,lo,hi,hi" || !vs[0].IsNull(1) {
+		t.Errorf("s = %q", got)
+	}
+	for i := range 5 {
+		if got := vs[1].Float64(i); got != float64(i)+0.5 {
+			t.Errorf("v[%d] = %v", i, got)
+		}
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	// A literal, then a copy of length 6 at offset 3 overlapping its output.
+	got, err := snappyDecode([]byte{9, 2 << 2, 'a', 'b', 'c', 1 | 2<<2, 3}, 9)
+	if err != nil || string(got) != "abcabcabc" {
+		t.Errorf("snappyDecode = %q, %v", got, err)
+	}
+	// A literal whose length takes an extra byte.
+	long := bytes.Repeat([]byte("x"), 100)
+	block := append(binary.AppendUvarint(nil, 100), 60<<2, 99)
+	if got, err := snappyDecode(append(block, long...), 100); err != nil || !bytes.Equal(got, long) {
+		t.Errorf("snappyDecode(long literal) = %q, %v", got, err)
+	}
+	if _, err := snappyDecode([]byte{9, 2 << 2, 'a', 'b', 'c', 1 | 2<<2, 4}, 9); err == nil {
+		t.Error("copy before the start accepted")
+	}
+}
+
+func TestWriter_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	for _, columns := range [][]Column{
+		nil,
+		{{Type: Int32}},
+		{{Name: "a", Type: Int32}, {Name: "a", Type: Int64}},
+		{{Name: "a", Type: Int96}},
+		{{Name: "a", Type: FixedLenByteArray, Length: 4}},
+		{{Name: "a", Type: Double, String: true}},
+	} {
+		if _, err := NewWriter(&buf, columns); err == nil {
+			t.Errorf("NewWriter(%+v) succeeded", columns)
+		}
+	}
+
+	w, err := NewWriter(&buf, []Column{{Name: "a", Type: Int32}, {Name: "b", Type: ByteArray}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range [][]any{
+		{1},
+		{nil, "x"},
+		{1.5, "x"},
+		{int64(math.MaxInt32) + 1, "x"},
+		{1, 2},
+		{true, "x"},
+		{struct{}{}, "x"},
+	} {
+		if err := w.Write(row...); err == nil {
+			t.Errorf("Write(%v) succeeded", row)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(1, "x"); err == nil {
+		t.Error("write after close succeeded")
+	}
+	// Rejected rows leave nothing behind: the file has no rows.
+	f, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.NumRows() != 0 || f.NumRowGroups() != 0 {
+		t.Errorf("NumRows = %d after rejected writes", f.NumRows())
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{{Name: "a", Type: Double}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if err := w.Write(float64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+
+	for name, b := range map[string][]byte{
+		"empty":     nil,
+		"not PAR1":  append(bytes.Clone(good[:len(good)-4]), "PAR2"...),
+		"footer":    append(bytes.Clone(good[:len(good)-8]), 0xff, 0xff, 0, 0, 'P', 'A', 'R', '1'),
+		"truncated": good[len(good)-20:],
+	} {
+		if _, err := Open(bytes.NewReader(b), int64(len(b))); err == nil {
+			t.Errorf("%s: Open succeeded", name)
+		}
+	}
+
+	// A page cut short fails when the row group is read.
+	bad := bytes.Clone(good)
+	bad[len(magic)+2] ^= 0x7f
+	f, err := Open(bytes.NewReader(bad), int64(len(bad)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ReadRowGroup(0, []int{0}); err == nil {
+		t.Error("corrupt page read")
+	}
+	if _, err := f.ReadRowGroup(1, []int{0}); err == nil {
+		t.Error("missing row group read")
+	}
+	if _, err := f.ReadRowGroup(0, []int{1}); err == nil {
+		t.Error("missing column read")
+	}
+	if _, err := OpenFile(filepath.Join(t.TempDir(), "missing.parquet")); err == nil {
+		t.Error("missing file opened")
+	}
+}
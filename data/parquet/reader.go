@@ -0,0 +1,296 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+const magic = "PAR1"
+
+// File is an open Parquet file. It reads the footer when opened and column
+// chunks on demand, one row group at a time, so memory is bounded by the
+// largest row group of the columns read.
+type File struct {
+	r       io.ReaderAt
+	closer  io.Closer
+	dataEnd int64 // offset of the footer; column chunks lie before it
+	columns []Column
+	groups  []rowGroup
+}
+
+// OpenFile opens the Parquet file name. Close closes it.
+func OpenFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	pf, err := Open(f, st.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	pf.closer = f
+	return pf, nil
+}
+
+// Open reads the footer of the size-byte Parquet file r.
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	if size < 12 {
+		return nil, errors.New("parquet: file too small")
+	}
+	tail := make([]byte, 8)
+	if _, err := r.ReadAt(tail, size-8); err != nil {
+		return nil, err
+	}
+	if string(tail[4:]) != magic {
+		return nil, errors.New("parquet: not a Parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail))
+	if n > size-12 {
+		return nil, errors.New("parquet: footer larger than file")
+	}
+	footer := make([]byte, n)
+	if _, err := r.ReadAt(footer, size-8-n); err != nil {
+		return nil, err
+	}
+	columns, groups, err := parseFooter(footer)
+	if err != nil {
+		return nil, err
+	}
+	return &File{r: r, dataEnd: size - 8 - n, columns: columns, groups: groups}, nil
+}
+
+// Close closes a file opened with OpenFile.
+func (f *File) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
+
+// Columns returns the schema.
+func (f *File) Columns() []Column {
+	return slices.Clone(f.columns)
+}
+
+// ColumnIndex returns the index of the named column, or -1.
+func (f *File) ColumnIndex(name string) int {
+	return slices.IndexFunc(f.columns, func(c Column) bool { return c.Name == name })
+}
+
+// NumRows returns the number of rows.
+func (f *File) NumRows() int64 {
+	var n int64
+	for _, g := range f.groups {
+		n += g.numRows
+	}
+	return n
+}
+
+// NumRowGroups returns the number of row groups.
+func (f *File) NumRowGroups() int {
+	return len(f.groups)
+}
+
+// ReadRowGroup reads the given columns, by index, of row group i. Columns
+// not asked for are not read.
+func (f *File) ReadRowGroup(i int, columns []int) ([]*Values, error) {
+	if i < 0 || i >= len(f.groups) {
+		return nil, fmt.Errorf("parquet: row group %d of %d", i, len(f.groups))
+	}
+	g := f.groups[i]
+	out := make([]*Values, len(columns))
+	for j, c := range columns {
+		if c < 0 || c >= len(f.columns) {
+			return nil, fmt.Errorf("parquet: column %d of %d", c, len(f.columns))
+		}
+		v, err := f.readChunk(f.columns[c], g.chunks[c], int(g.numRows))
+		if err != nil {
+			return nil, fmt.Errorf("parquet: row group %d column %q: %w", i, f.columns[c].Name, err)
+		}
+		out[j] = v
+	}
+	return out, nil
+}
+
+// readChunk reads and decodes a column chunk of rows values.
+func (f *File) readChunk(col Column, cm chunkMeta, rows int) (*Values, error) {
+	start := cm.dataOffset
+	if cm.dictOffset > 0 && cm.dictOffset < start {
+		start = cm.dictOffset
+	}
+	if cm.numValues != int64(rows) {
+		return nil, fmt.Errorf("%d values in a chunk of %d rows", cm.numValues, rows)
+	}
+	// The metadata is not trusted: the chunk must lie between the magic
+	// and the footer before it is allocated.
+	if start < int64(len(magic)) || cm.size < 0 || cm.size > f.dataEnd-start {
+		return nil, fmt.Errorf("chunk of %d bytes at offset %d outside the file", cm.size, start)
+	}
+	chunk := make([]byte, cm.size)
+	if _, err := f.r.ReadAt(chunk, start); err != nil {
+		return nil, err
+	}
+
+	// Preallocate no more values than the chunk holds bits; RLE runs
+	// beyond that grow the slices as they decode.
+	capacity := min(rows, len(chunk)*8)
+	out := newValues(col.Type, capacity)
+	if col.Optional {
+		out.nulls = make([]bool, 0, capacity)
+	}
+	var dict *Values
+	for out.Len() < rows {
+		if len(chunk) == 0 {
+			return nil, fmt.Errorf("chunk ended after %d of %d values", out.Len(), rows)
+		}
+		ph, n, err := decodeStruct(chunk)
+		if err != nil {
+			return nil, err
+		}
+		chunk = chunk[n:]
+		csize, usize := int(ph.int(3)), int(ph.int(2))
+		if csize < 0 || csize > len(chunk) || usize < 0 {
+			return nil, errors.New("page larger than its chunk")
+		}
+		page := chunk[:csize]
+		chunk = chunk[csize:]
+
+		switch ph.int(1) {
+		case dictionaryPage:
+			h := ph.strct(7)
+			body, err := decompress(cm.codec, page, usize)
+			if err != nil {
+				return nil, err
+			}
+			if enc := h.int(2); enc != encPlain && enc != encPlainDictionary {
+				return nil, fmt.Errorf("dictionary encoding %d not supported", enc)
+			}
+			if dict, err = decodePlain(col.Type, col.Length, body, int(h.int(1))); err != nil {
+				return nil, err
+			}
+		case dataPage:
+			h := ph.strct(5)
+			body, err := decompress(cm.codec, page, usize)
+			if err != nil {
+				return nil, err
+			}
+			var levels []byte
+			if col.Optional {
+				if len(body) < 4 {
+					return nil, errors.New("truncated definition levels")
+				}
+				l := binary.LittleEndian.Uint32(body)
+				if uint64(l) > uint64(len(body)-4) {
+					return nil, errors.New("truncated definition levels")
+				}
+				levels, body = body[4:4+l], body[4+l:]
+			}
+			n := int(h.int(1))
+			if n < 0 || n > rows-out.Len() {
+				return nil, fmt.Errorf("page of %d values overruns the chunk", n)
+			}
+			if err := decodePage(out, col, n, int(h.int(2)), levels, body, dict); err != nil {
+				return nil, err
+			}
+		case dataPageV2:
+			h := ph.strct(8)
+			dl, rl := int(h.int(5)), int(h.int(6))
+			if dl < 0 || rl != 0 || dl > len(page) || dl > usize {
+				return nil, errors.New("malformed data page levels")
+			}
+			levels, body := page[:dl], page[dl:]
+			if h.bool(7, true) {
+				if body, err = decompress(cm.codec, body, usize-dl); err != nil {
+					return nil, err
+				}
+			}
+			n := int(h.int(1))
+			if n < 0 || n > rows-out.Len() {
+				return nil, fmt.Errorf("page of %d values overruns the chunk", n)
+			}
+			if err := decodePage(out, col, n, int(h.int(4)), levels, body, dict); err != nil {
+				return nil, err
+			}
+		case indexPage:
+		default:
+			return nil, fmt.Errorf("page type %d not supported", ph.int(1))
+		}
+	}
+	if out.Len() != rows {
+		return nil, fmt.Errorf("%d values in a chunk of %d rows", out.Len(), rows)
+	}
+	return out, nil
+}
+
+// decodePage appends the n values of a data page, nulls included, to out.
+// levels are the page's definition levels, empty for a required column,
+// and body its encoded non-null values.
+func decodePage(out *Values, col Column, n, encoding int, levels, body []byte, dict *Values) error {
+	present := n
+	var defs []int
+	if col.Optional {
+		var err error
+		if defs, err = decodeHybrid(levels, 1, n); err != nil {
+			return err
+		}
+		present = 0
+		for _, d := range defs {
+			present += d
+		}
+	}
+
+	var vals *Values
+	var index []int
+	switch encoding {
+	case encPlain:
+		var err error
+		if vals, err = decodePlain(col.Type, col.Length, body, present); err != nil {
+			return err
+		}
+	case encPlainDictionary, encRLEDictionary:
+		if dict == nil {
+			return errors.New("dictionary-encoded page without a dictionary")
+		}
+		if present > 0 {
+			if len(body) == 0 {
+				return errors.New("truncated dictionary indices")
+			}
+			var err error
+			if index, err = decodeHybrid(body[1:], int(body[0]), present); err != nil {
+				return err
+			}
+			for _, k := range index {
+				if k >= dict.Len() {
+					return fmt.Errorf("dictionary index %d of %d", k, dict.Len())
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("encoding %d not supported", encoding)
+	}
+
+	next := 0
+	for i := range n {
+		if defs != nil && defs[i] == 0 {
+			out.appendNull()
+			continue
+		}
+		if vals != nil {
+			out.appendFrom(vals, next)
+		} else {
+			out.appendFrom(dict, index[next])
+		}
+		next++
+	}
+	return nil
+}
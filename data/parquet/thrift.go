@@ -0,0 +1,292 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Thrift compact protocol type codes.
+const (
+	tStop   = 0
+	tTrue   = 1
+	tFalse  = 2
+	tByte   = 3
+	tI16    = 4
+	tI32    = 5
+	tI64    = 6
+	tDouble = 7
+	tBinary = 8
+	tList   = 9
+	tSet    = 10
+	tMap    = 11
+	tStruct = 12
+)
+
+// maxDepth bounds the nesting of decoded structs and containers, so a
+// corrupt footer cannot exhaust the stack.
+const maxDepth = 32
+
+var errTruncated = errors.New("parquet: truncated metadata")
+
+// tstruct is a decoded Thrift struct: field values by field ID. Integers of
+// every width decode to int64, binaries to []byte, lists and sets to []any
+// and structs to tstruct. Maps are skipped.
+type tstruct map[int16]any
+
+func (s tstruct) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s tstruct) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s tstruct) str(id int16) string {
+	v, _ := s[id].([]byte)
+	return string(v)
+}
+
+func (s tstruct) list(id int16) []any {
+	v, _ := s[id].([]any)
+	return v
+}
+
+func (s tstruct) strct(id int16) tstruct {
+	v, _ := s[id].(tstruct)
+	return v
+}
+
+func (s tstruct) bool(id int16, def bool) bool {
+	if v, ok := s[id].(bool); ok {
+		return v
+	}
+	return def
+}
+
+// decoder reads the Thrift compact protocol from a byte slice.
+type decoder struct {
+	b   []byte
+	pos int
+}
+
+// decodeStruct decodes a struct from the start of b and returns it with the
+// number of bytes it took.
+func decodeStruct(b []byte) (tstruct, int, error) {
+	d := &decoder{b: b}
+	s, err := d.readStruct(0)
+	return s, d.pos, err
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.b) {
+		return 0, errTruncated
+	}
+	c := d.b[d.pos]
+	d.pos++
+	return c, nil
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.b[d.pos:])
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *decoder) varint() (int64, error) {
+	u, err := d.uvarint()
+	return int64(u>>1) ^ -int64(u&1), err
+}
+
+func (d *decoder) readStruct(depth int) (tstruct, error) {
+	if depth > maxDepth {
+		return nil, errors.New("parquet: metadata nested too deeply")
+	}
+	s := make(tstruct)
+	var last int16
+	for {
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		if h == tStop {
+			return s, nil
+		}
+		typ, delta := h&0x0f, int16(h>>4)
+		id := last + delta
+		if delta == 0 {
+			v, err := d.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+		switch typ {
+		case tTrue:
+			s[id] = true
+		case tFalse:
+			s[id] = false
+		default:
+			if s[id], err = d.value(typ, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (d *decoder) value(typ byte, depth int) (any, error) {
+	switch typ {
+	case tTrue, tFalse:
+		// Booleans inside containers take a byte each.
+		c, err := d.byte()
+		return c == tTrue, err
+	case tByte:
+		c, err := d.byte()
+		return int64(int8(c)), err
+	case tI16, tI32, tI64:
+		return d.varint()
+	case tDouble:
+		if d.pos+8 > len(d.b) {
+			return nil, errTruncated
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.b[d.pos:]))
+		d.pos += 8
+		return v, nil
+	case tBinary:
+		n, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(d.b)-d.pos) {
+			return nil, errTruncated
+		}
+		v := d.b[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return v, nil
+	case tList, tSet:
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		n, et := uint64(h>>4), h&0x0f
+		if n == 15 {
+			if n, err = d.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		// Every element takes at least a byte.
+		if n > uint64(len(d.b)-d.pos) {
+			return nil, errTruncated
+		}
+		list := make([]any, n)
+		for i := range list {
+			if list[i], err = d.value(et, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case tMap:
+		n, err := d.uvarint()
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		if n > uint64(len(d.b)-d.pos) {
+			return nil, errTruncated
+		}
+		kv, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		for range n {
+			if _, err := d.value(kv>>4, depth+1); err != nil {
+				return nil, err
+			}
+			if _, err := d.value(kv&0x0f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case tStruct:
+		return d.readStruct(depth + 1)
+	default:
+		return nil, fmt.Errorf("parquet: unknown metadata type %d", typ)
+	}
+}
+
+// encoder writes the Thrift compact protocol. Fields are written in
+// increasing ID order within each struct.
+type encoder struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (e *encoder) varint(v int64) {
+	e.uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (e *encoder) field(id int16, typ byte) {
+	last := &e.last[len(e.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.buf.WriteByte(typ)
+		e.varint(int64(id))
+	}
+	*last = id
+}
+
+// begin starts a struct: the top-level one, a list element, or, after
+// field, a struct field.
+func (e *encoder) begin() {
+	e.last = append(e.last, 0)
+}
+
+func (e *encoder) end() {
+	e.buf.WriteByte(tStop)
+	e.last = e.last[:len(e.last)-1]
+}
+
+func (e *encoder) i32(id int16, v int32) {
+	e.field(id, tI32)
+	e.varint(int64(v))
+}
+
+func (e *encoder) i64(id int16, v int64) {
+	e.field(id, tI64)
+	e.varint(v)
+}
+
+func (e *encoder) str(id int16, v string) {
+	e.field(id, tBinary)
+	e.uvarint(uint64(len(v)))
+	e.buf.WriteString(v)
+}
+
+func (e *encoder) structField(id int16) {
+	e.field(id, tStruct)
+	e.begin()
+}
+
+// list starts a list field of n elements of type et, to be followed by the
+// elements.
+func (e *encoder) list(id int16, et byte, n int) {
+	e.field(id, tList)
+	if n < 15 {
+		e.buf.WriteByte(byte(n)<<4 | et)
+		return
+	}
+	e.buf.WriteByte(0xf0 | et)
+	e.uvarint(uint64(n))
+}
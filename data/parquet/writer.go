@@ -0,0 +1,230 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const defaultRowGroupSize = 65536
+
+// WriterOption configures a Writer.
+type WriterOption func(*Writer)
+
+// WithRowGroupSize sets the number of rows buffered per row group. The
+// default is 65536.
+func WithRowGroupSize(n int) WriterOption {
+	return func(w *Writer) {
+		if n > 0 {
+			w.groupSize = n
+		}
+	}
+}
+
+// Writer writes rows to a Parquet file, buffering one row group at a time.
+// Values are written PLAIN-encoded and uncompressed.
+type Writer struct {
+	w         io.Writer
+	columns   []Column
+	groupSize int
+	buf       []*Values
+	rows      int
+	offset    int64
+	groups    []writtenGroup
+	closed    bool
+}
+
+// NewWriter writes the leading magic of a Parquet file with columns to w.
+// Int96 and FixedLenByteArray columns are not supported.
+func NewWriter(w io.Writer, columns []Column, opts ...WriterOption) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet: no columns")
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		switch {
+		case c.Name == "":
+			return nil, errors.New("parquet: column without a name")
+		case seen[c.Name]:
+			return nil, fmt.Errorf("parquet: duplicate column %q", c.Name)
+		case c.Type < Boolean || c.Type > ByteArray || c.Type == Int96:
+			return nil, fmt.Errorf("parquet: column %q: writing %s not supported", c.Name, c.Type)
+		case c.String && c.Type != ByteArray:
+			return nil, fmt.Errorf("parquet: column %q: string column of type %s", c.Name, c.Type)
+		}
+		seen[c.Name] = true
+	}
+	pw := &Writer{w: w, columns: append([]Column(nil), columns...), groupSize: defaultRowGroupSize}
+	for _, opt := range opts {
+		opt(pw)
+	}
+	pw.reset()
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	pw.offset = int64(len(magic))
+	return pw, nil
+}
+
+func (w *Writer) reset() {
+	w.buf = make([]*Values, len(w.columns))
+	for i, c := range w.columns {
+		w.buf[i] = newValues(c.Type, min(w.groupSize, 1024))
+		if c.Optional {
+			w.buf[i].nulls = []bool{}
+		}
+	}
+	w.rows = 0
+}
+
+// Write appends a row, one value per column: bool, int, int32, int64,
+// float32, float64, string or []byte, converted to the column's type, or
+// nil for a null in an optional column.
+func (w *Writer) Write(values ...any) error {
+	if w.closed {
+		return errors.New("parquet: write after close")
+	}
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("parquet: %d values for %d columns", len(values), len(w.columns))
+	}
+	// Check the whole row before appending any of it.
+	row := make([]any, len(values))
+	for i, v := range values {
+		c := w.columns[i]
+		conv, err := convert(c, v)
+		if err != nil {
+			return fmt.Errorf("parquet: column %q: %w", c.Name, err)
+		}
+		row[i] = conv
+	}
+	for i, v := range row {
+		buf := w.buf[i]
+		switch v := v.(type) {
+		case nil:
+			buf.appendNull()
+			continue
+		case int64:
+			buf.ints = append(buf.ints, v)
+		case float64:
+			buf.floats = append(buf.floats, v)
+		case string:
+			buf.strs = append(buf.strs, v)
+		}
+		if buf.nulls != nil {
+			buf.nulls = append(buf.nulls, false)
+		}
+	}
+	if w.rows++; w.rows == w.groupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// convert returns v as the int64, float64 or string that holds values of
+// column c, or nil for a null.
+func convert(c Column, v any) (any, error) {
+	if v == nil {
+		if !c.Optional {
+			return nil, errors.New("null in a required column")
+		}
+		return nil, nil
+	}
+	var i int64
+	var f float64
+	var s string
+	kind := 'i'
+	switch v := v.(type) {
+	case bool:
+		if v {
+			i = 1
+		}
+		if c.Type != Boolean {
+			return nil, fmt.Errorf("bool for a %s column", c.Type)
+		}
+		return i, nil
+	case int:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	case float32:
+		f, kind = float64(v), 'f'
+	case float64:
+		f, kind = v, 'f'
+	case string:
+		s, kind = v, 's'
+	case []byte:
+		s, kind = string(v), 's'
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", v)
+	}
+	switch {
+	case c.Type == ByteArray && kind == 's':
+		return s, nil
+	case c.Type == Int32 && kind == 'i':
+		if i < math.MinInt32 || i > math.MaxInt32 {
+			return nil, fmt.Errorf("%d overflows INT32", i)
+		}
+		return i, nil
+	case c.Type == Int64 && kind == 'i':
+		return i, nil
+	case (c.Type == Float || c.Type == Double) && kind == 'i':
+		return float64(i), nil
+	case (c.Type == Float || c.Type == Double) && kind == 'f':
+		return f, nil
+	default:
+		return nil, fmt.Errorf("%T for a %s column", v, c.Type)
+	}
+}
+
+// Flush writes the buffered rows as a row group. It does nothing when no
+// rows are buffered.
+func (w *Writer) Flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	group := writtenGroup{numRows: int64(w.rows)}
+	for _, v := range w.buf {
+		var body []byte
+		if v.nulls != nil {
+			levels := appendLevels(nil, v, 0, w.rows)
+			body = binary.LittleEndian.AppendUint32(body, uint32(len(levels)))
+			body = append(body, levels...)
+		}
+		body = appendPlain(body, v, 0, w.rows)
+		header := encodePageHeader(w.rows, len(body))
+		size := int64(len(header) + len(body))
+		group.chunks = append(group.chunks, writtenChunk{offset: w.offset, size: size, numValues: int64(w.rows)})
+		group.size += size
+		if _, err := w.w.Write(header); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(body); err != nil {
+			return err
+		}
+		w.offset += size
+	}
+	w.groups = append(w.groups, group)
+	w.reset()
+	return nil
+}
+
+// Close flushes the buffered rows and writes the footer. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	footer := encodeFooter(w.columns, w.groups)
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, magic...)
+	_, err := w.w.Write(footer)
+	return err
+}
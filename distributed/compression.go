@@ -0,0 +1,256 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	metrics "github.com/zerfoo/ztensor/metrics/runtime"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// CompressedStrategy wraps another strategy and applies top-k gradient
+// sparsification before each all-reduce: only the ratio of each gradient's
+// elements with the largest magnitude are kept, and the rest are zeroed so
+// the transport can send the gradient sparse. The dropped elements are not
+// lost. They accumulate in a per-gradient residual that is added back
+// before the next selection (error feedback), so small but persistent
+// gradient components are eventually applied.
+//
+// Every worker must wrap its strategy the same way. Barrier, BroadcastTensor,
+// and the rest of the InternalStrategy methods pass through unchanged.
+type CompressedStrategy[T tensor.Float] struct {
+	inner     InternalStrategy[T]
+	ratio     float64
+	collector metrics.Collector
+
+	mu        sync.Mutex
+	residuals map[string][]T
+	scratch   []T
+}
+
+// NewCompressedStrategy wraps inner so that AllReduceGradients sends only the
+// top ratio of each gradient's elements, 0 < ratio <= 1. A ratio of 0.01
+// sends 1% of each gradient, and at least one element.
+func NewCompressedStrategy[T tensor.Float](inner InternalStrategy[T], ratio float64) (*CompressedStrategy[T], error) {
+	if inner == nil {
+		return nil, errors.New("compressed strategy: inner strategy is nil")
+	}
+	if err := checkCompressionRatio(ratio); err != nil {
+		return nil, err
+	}
+	return &CompressedStrategy[T]{
+		inner:     inner,
+		ratio:     ratio,
+		collector: metrics.Nop(),
+		residuals: make(map[string][]T),
+	}, nil
+}
+
+// SetCollector replaces the strategy's metrics collector. It counts the
+// gradient elements offered and sent, whose quotient is the achieved
+// compression.
+func (s *CompressedStrategy[T]) SetCollector(c metrics.Collector) {
+	if c == nil {
+		c = metrics.Nop()
+	}
+	s.collector = c
+}
+
+// Init initializes the wrapped strategy.
+func (s *CompressedStrategy[T]) Init(rank, size int, coordinatorAddress string) error {
+	return s.inner.Init(rank, size, coordinatorAddress)
+}
+
+// AllReduceGradients sparsifies each gradient in place, folding the
+// elements it drops into that gradient's residual, and then all-reduces the
+// sparsified gradients with the wrapped strategy.
+func (s *CompressedStrategy[T]) AllReduceGradients(gradients map[string]*tensor.TensorNumeric[T]) error {
+	s.mu.Lock()
+	var total, sent int64
+	for name, g := range gradients {
+		if g == nil {
+			continue
+		}
+		data := g.Data()
+		total += int64(len(data))
+		sent += int64(s.sparsify(name, data))
+	}
+	s.mu.Unlock()
+
+	s.collector.Counter("compression_elements_total").Add(total)
+	s.collector.Counter("compression_elements_sent").Add(sent)
+
+	return s.inner.AllReduceGradients(gradients)
+}
+
+// sparsify adds name's residual to data, keeps its k largest-magnitude
+// elements, moves the others into the residual, and returns k. s.mu must be
+// held.
+func (s *CompressedStrategy[T]) sparsify(name string, data []T) int {
+	n := len(data)
+	if n == 0 {
+		return 0
+	}
+	residual, ok := s.residuals[name]
+	if !ok || len(residual) != n {
+		// New gradient, or its shape changed: start a fresh residual.
+		residual = make([]T, n)
+		s.residuals[name] = residual
+	}
+	for i := range data {
+		data[i] += residual[i]
+	}
+
+	k := max(int(s.ratio*float64(n)), 1)
+	if k >= n {
+		clear(residual)
+		return n
+	}
+
+	if cap(s.scratch) < n {
+		s.scratch = make([]T, n)
+	}
+	mags := s.scratch[:n]
+	for i, v := range data {
+		mags[i] = magnitude(v)
+	}
+	threshold := selectKth(mags, n-k)
+
+	// Keep every element above the threshold, then elements equal to it
+	// until k are kept, so ties cannot push the count past k.
+	var above int
+	for _, v := range data {
+		if magnitude(v) > threshold {
+			above++
+		}
+	}
+	ties := k - above
+	for i, v := range data {
+		m := magnitude(v)
+		if m > threshold || (m == threshold && ties > 0) {
+			if m == threshold {
+				ties--
+			}
+			residual[i] = 0
+			continue
+		}
+		residual[i] = v
+		data[i] = 0
+	}
+	return k
+}
+
+// Residual returns a copy of the error-feedback residual accumulated for the
+// named gradient, or nil if there is none.
+func (s *CompressedStrategy[T]) Residual(name string) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.residuals[name]
+	if !ok {
+		return nil
+	}
+	return append([]T(nil), r...)
+}
+
+// ResetResiduals discards all accumulated residuals, e.g. after restoring a
+// checkpoint, which invalidates them.
+func (s *CompressedStrategy[T]) ResetResiduals() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.residuals)
+}
+
+// Barrier delegates to the wrapped strategy.
+func (s *CompressedStrategy[T]) Barrier() error { return s.inner.Barrier() }
+
+// BroadcastTensor delegates to the wrapped strategy uncompressed.
+func (s *CompressedStrategy[T]) BroadcastTensor(t *tensor.TensorNumeric[T], rootRank int) error {
+	return s.inner.BroadcastTensor(t, rootRank)
+}
+
+// Rank returns the wrapped strategy's rank.
+func (s *CompressedStrategy[T]) Rank() int { return s.inner.Rank() }
+
+// Size returns the wrapped strategy's size.
+func (s *CompressedStrategy[T]) Size() int { return s.inner.Size() }
+
+// Shutdown shuts down the wrapped strategy.
+func (s *CompressedStrategy[T]) Shutdown() { s.inner.Shutdown() }
+
+// Close satisfies the shutdown.Closer interface by calling Shutdown.
+func (s *CompressedStrategy[T]) Close(_ context.Context) error {
+	s.Shutdown()
+	return nil
+}
+
+func checkCompressionRatio(ratio float64) error {
+	if !(ratio > 0 && ratio <= 1) {
+		return fmt.Errorf("compressed strategy: ratio must be in (0, 1], got %g", ratio)
+	}
+	return nil
+}
+
+// magnitude returns |v|, ranking NaN above every number so that a
+// diverging gradient is sent rather than hidden in the residual.
+func magnitude[T tensor.Float](v T) T {
+	switch {
+	case v != v:
+		return T(math.Inf(1))
+	case v < 0:
+		return -v
+	default:
+		return v
+	}
+}
+
+// selectKth reorders a so that a[k] holds the value it would have if a were
+// sorted ascending, and returns it. It runs in expected linear time.
+func selectKth[T tensor.Float](a []T, k int) T {
+	lo, hi := 0, len(a)-1
+	for lo < hi {
+		// Median of three guards against sorted input.
+		mid := lo + (hi-lo)/2
+		if a[mid] < a[lo] {
+			a[mid], a[lo] = a[lo], a[mid]
+		}
+		if a[hi] < a[lo] {
+			a[hi], a[lo] = a[lo], a[hi]
+		}
+		if a[hi] < a[mid] {
+			a[hi], a[mid] = a[mid], a[hi]
+		}
+		pivot := a[mid]
+
+		i, j := lo, hi
+		for i <= j {
+			for a[i] < pivot {
+				i++
+			}
+			for a[j] > pivot {
+				j--
+			}
+			if i <= j {
+				a[i], a[j] = a[j], a[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case k <= j:
+			hi = j
+		case k >= i:
+			lo = i
+		default:
+			return a[k]
+		}
+	}
+	return a[k]
+}
+
+// Statically assert that the type implements the interface.
+var _ InternalStrategy[float32] = (*CompressedStrategy[float32])(nil)
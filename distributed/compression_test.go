@@ -0,0 +1,234 @@
+package distributed
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	metrics "github.com/zerfoo/ztensor/metrics/runtime"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// recordingStrategy is an InternalStrategy that records a copy of the
+// gradients each AllReduceGradients call receives.
+type recordingStrategy struct {
+	CustomMockStrategy[float32]
+	sent []map[string][]float32
+	err  error
+}
+
+func (r *recordingStrategy) AllReduceGradients(g map[string]*tensor.TensorNumeric[float32]) error {
+	step := make(map[string][]float32, len(g))
+	for name, t := range g {
+		step[name] = slices.Clone(t.Data())
+	}
+	r.sent = append(r.sent, step)
+	return r.err
+}
+
+func newGrad(t *testing.T, data ...float32) *tensor.TensorNumeric[float32] {
+	t.Helper()
+	g, err := tensor.New([]int{len(data)}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestNewCompressedStrategy_Validation(t *testing.T) {
+	inner := &recordingStrategy{}
+	for _, ratio := range []float64{0, -0.5, 1.5, math.NaN()} {
+		if _, err := NewCompressedStrategy[float32](inner, ratio); err == nil {
+			t.Errorf("ratio %v should be rejected", ratio)
+		}
+	}
+	if _, err := NewCompressedStrategy[float32](nil, 0.1); err == nil {
+		t.Error("nil inner strategy should be rejected")
+	}
+	if _, err := NewCompressedStrategy[float32](inner, 1); err != nil {
+		t.Errorf("ratio 1: %v", err)
+	}
+}
+
+func TestCompressedStrategy_TopK(t *testing.T) {
+	inner := &recordingStrategy{}
+	s, err := NewCompressedStrategy[float32](inner, 0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := metrics.NewInMemory()
+	s.SetCollector(collector)
+
+	g := newGrad(t, 0.1, -4, 0.2, 3, -0.3, 0.05, 1, -0.01)
+	if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"w": g}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float32{0, -4, 0, 3, 0, 0, 0, 0}
+	if got := inner.sent[0]["w"]; !slices.Equal(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+	wantResidual := []float32{0.1, 0, 0.2, 0, -0.3, 0.05, 1, -0.01}
+	if got := s.Residual("w"); !slices.Equal(got, wantResidual) {
+		t.Errorf("residual %v, want %v", got, wantResidual)
+	}
+
+	snap := collector.Snapshot()
+	if snap.Counters["compression_elements_total"] != 8 || snap.Counters["compression_elements_sent"] != 2 {
+		t.Errorf("counters = %v", snap.Counters)
+	}
+}
+
+func TestCompressedStrategy_ErrorFeedback(t *testing.T) {
+	inner := &recordingStrategy{}
+	s, err := NewCompressedStrategy[float32](inner, 0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Element 3 is small every step; its residual grows until it is sent.
+	var sent float32
+	for range 4 {
+		g := newGrad(t, 1, 0, 0, 0.4)
+		if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"b": g}); err != nil {
+			t.Fatal(err)
+		}
+		sent += g.Data()[3]
+	}
+	if sent == 0 {
+		t.Fatal("a persistent small component was never sent")
+	}
+
+	// Everything offered is either sent or still in the residual.
+	var total float32
+	for _, step := range inner.sent {
+		total += step["b"][3]
+	}
+	if got := total + s.Residual("b")[3]; math.Abs(float64(got-1.6)) > 1e-5 {
+		t.Errorf("sent + residual = %v, want 1.6", got)
+	}
+
+	s.ResetResiduals()
+	if r := s.Residual("b"); r != nil {
+		t.Errorf("residual after reset = %v", r)
+	}
+}
+
+func TestCompressedStrategy_TiesAndShapeChange(t *testing.T) {
+	inner := &recordingStrategy{}
+	s, err := NewCompressedStrategy[float32](inner, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := newGrad(t, 2, -2, 2, 2)
+	if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": g}); err != nil {
+		t.Fatal(err)
+	}
+	var kept int
+	for _, v := range inner.sent[0]["x"] {
+		if v != 0 {
+			kept++
+		}
+	}
+	if kept != 2 {
+		t.Errorf("kept %d of 4 tied elements, want 2", kept)
+	}
+
+	// A gradient whose size changed starts with a fresh residual.
+	if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": newGrad(t, 1, 3)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := inner.sent[1]["x"]; !slices.Equal(got, []float32{0, 3}) {
+		t.Errorf("sent %v after resize", got)
+	}
+}
+
+func TestCompressedStrategy_SendsNaN(t *testing.T) {
+	inner := &recordingStrategy{}
+	s, err := NewCompressedStrategy[float32](inner, 0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nan := float32(math.NaN())
+	if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": newGrad(t, 5, nan, 1, 2)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := inner.sent[0]["x"][1]; got == got {
+		t.Errorf("sent %v, want the NaN element kept", inner.sent[0]["x"])
+	}
+}
+
+func TestCompressedStrategy_PropagatesInnerError(t *testing.T) {
+	inner := &recordingStrategy{err: errors.New("link down")}
+	s, err := NewCompressedStrategy[float32](inner, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": newGrad(t, 1, 2)}); !errors.Is(err, inner.err) {
+		t.Errorf("err = %v, want the inner strategy's error", err)
+	}
+}
+
+func TestSelectKth(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 200 {
+		n := 1 + r.IntN(50)
+		a := make([]float64, n)
+		for i := range a {
+			a[i] = float64(r.IntN(10)) // plenty of duplicates
+		}
+		sorted := slices.Clone(a)
+		slices.Sort(sorted)
+		k := r.IntN(n)
+		if got := selectKth(a, k); got != sorted[k] {
+			t.Fatalf("selectKth(%v, %d) = %v, want %v", sorted, k, got, sorted[k])
+		}
+	}
+}
+
+func TestPackTensor_SparseRoundTrip(t *testing.T) {
+	dense := []float32{0, 0, 3, 0, 0, 0, -1, 0}
+	p := packTensor([]int32{2, 4}, dense)
+	if !slices.Equal(p.Indices, []uint32{2, 6}) || !slices.Equal(p.Data, []float32{3, -1}) {
+		t.Fatalf("packed = %v", p)
+	}
+	if err := validateTensor(p, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := denseData(p); !slices.Equal(got, dense) {
+		t.Errorf("denseData = %v, want %v", got, dense)
+	}
+
+	// Dense data stays dense.
+	if p := packTensor([]int32{2}, []float32{1, 2}); p.Indices != nil {
+		t.Errorf("dense tensor packed sparse: %v", p)
+	}
+
+	p.Indices[1] = 8
+	if err := validateTensor(p, "test"); err == nil {
+		t.Error("out-of-range sparse index should fail validation")
+	}
+	p.Indices = p.Indices[:1]
+	if err := validateTensor(p, "test"); err == nil {
+		t.Error("mismatched indices and values should fail validation")
+	}
+}
+
+func BenchmarkCompressedStrategy_Sparsify(b *testing.B) {
+	s, err := NewCompressedStrategy[float32](&recordingStrategy{}, 0.01)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := rand.New(rand.NewPCG(1, 2))
+	data := make([]float32, 1<<20)
+	for i := range data {
+		data[i] = r.Float32() - 0.5
+	}
+	b.ResetTimer()
+	for b.Loop() {
+		s.sparsify("w", data)
+	}
+}
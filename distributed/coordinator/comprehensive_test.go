@@ -71,7 +71,7 @@ func TestReaper_TimesOutWorker(t *testing.T) {
 
 	// Verify worker exists
 	kit.coord.mu.Lock()
-	_, exists := kit.coord.workers["w1"]
+	_, exists := defaultJob(kit.coord).workers["w1"]
 	kit.coord.mu.Unlock()
 
 	if !exists {
@@ -84,7 +84,7 @@ func TestReaper_TimesOutWorker(t *testing.T) {
 
 	// Worker should be reaped
 	kit.coord.mu.Lock()
-	_, exists = kit.coord.workers["w1"]
+	_, exists = defaultJob(kit.coord).workers["w1"]
 	kit.coord.mu.Unlock()
 
 	if exists {
@@ -115,7 +115,7 @@ func TestStartCheckpoint_WithWorkers(t *testing.T) {
 	}
 
 	kit.coord.mu.Lock()
-	ckpt := kit.coord.checkpoints[resp.CheckpointId]
+	ckpt := defaultJob(kit.coord).checkpoints[resp.CheckpointId]
 	numWorkers := len(ckpt.Workers)
 	kit.coord.mu.Unlock()
 
@@ -187,7 +187,7 @@ func TestEndCheckpoint_PartialCompletion(t *testing.T) {
 	}
 
 	kit.coord.mu.Lock()
-	partialCompleted := kit.coord.checkpoints[resp.CheckpointId].Completed
+	partialCompleted := defaultJob(kit.coord).checkpoints[resp.CheckpointId].Completed
 	kit.coord.mu.Unlock()
 
 	if partialCompleted {
@@ -205,7 +205,7 @@ func TestEndCheckpoint_PartialCompletion(t *testing.T) {
 	}
 
 	kit.coord.mu.Lock()
-	fullyCompleted := kit.coord.checkpoints[resp.CheckpointId].Completed
+	fullyCompleted := defaultJob(kit.coord).checkpoints[resp.CheckpointId].Completed
 	kit.coord.mu.Unlock()
 
 	if !fullyCompleted {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,26 +17,42 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/peer"
 	grpcstatus "google.golang.org/grpc/status"
 )
 
+// DefaultNamespace and DefaultJobID name the job that requests without a
+// namespace or job ID belong to, so single-job clients need not set them.
+const (
+	DefaultNamespace = "default"
+	DefaultJobID     = "default"
+)
+
 // Coordinator implements the pb.CoordinatorServer interface.
-// It manages the state of the distributed training cluster.
+// It manages the state of the distributed training cluster. Workers, ranks,
+// and checkpoints are scoped to a job within a namespace, so several
+// independent training runs can share one coordinator without seeing each
+// other's members or checkpoints.
 type Coordinator struct {
 	pb.UnimplementedCoordinatorServer
-	mu          sync.Mutex
-	workers     map[string]*WorkerInfo
-	ranks       map[int]string
-	checkpoints map[string]*CheckpointInfo
-	nextRank    int
-	server      *grpc.Server
-	serverOpts  []grpc.ServerOption
-	logger      log.Logger
-	lis         net.Listener
-	timeout     time.Duration
-	stopCh      chan struct{}
-	stopOnce    sync.Once
+	mu         sync.Mutex
+	jobs       map[JobKey]*job
+	maxRunning int
+	submitSeq  uint64
+	server     *grpc.Server
+	serverOpts []grpc.ServerOption
+	health     *grpchealth.Server
+	logger     log.Logger
+	lis        net.Listener
+	timeout    time.Duration
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+
+	// checkInterval is how often the reaper looks for workers that have
+	// not heartbeated within timeout; see SetFailureDetection.
+	checkInterval time.Duration
 
 	// tls, when set via SetTLS, secures the coordinator's gRPC server the
 	// same way T140.1 secures the worker (distributed.TLSConfig.
@@ -47,12 +64,141 @@ type Coordinator struct {
 	tls *distributed.TLSConfig
 }
 
+// JobKey identifies one training job on the coordinator.
+type JobKey struct {
+	Namespace string
+	JobID     string
+}
+
+// String returns the key as "namespace/job".
+func (k JobKey) String() string { return k.Namespace + "/" + k.JobID }
+
+// jobKey returns the key for namespace and jobID, substituting the defaults
+// for empty values.
+func jobKey(namespace, jobID string) JobKey {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	if jobID == "" {
+		jobID = DefaultJobID
+	}
+
+	return JobKey{Namespace: namespace, JobID: jobID}
+}
+
+// job holds the membership and checkpoint state of one training job.
+// Ranks are assigned per job starting at 0.
+type job struct {
+	workers     map[string]*WorkerInfo
+	ranks       map[int]string
+	checkpoints map[string]*CheckpointInfo
+	nextRank    int
+
+	// epoch counts membership changes; watchers receive every change.
+	epoch    int64
+	watchers map[chan *pb.MembershipEvent]struct{}
+
+	// state is JOB_STATE_RUNNING from the start for jobs that were never
+	// submitted; submitted jobs (spec != nil) go through the scheduler.
+	state      pb.JobState
+	spec       *jobSpec
+	seq        uint64
+	submitted  time.Time
+	dispatched time.Time
+	finished   time.Time
+}
+
+func newJob() *job {
+	return &job{
+		workers:     make(map[string]*WorkerInfo),
+		ranks:       make(map[int]string),
+		checkpoints: make(map[string]*CheckpointInfo),
+		watchers:    make(map[chan *pb.MembershipEvent]struct{}),
+		state:       pb.JobState_JOB_STATE_RUNNING,
+	}
+}
+
+// idle reports whether j has no workers, watchers, or checkpoint in
+// progress, so dropping it loses nothing a client could still ask about.
+// Submitted jobs are never idle; finished ones are pruned by pruneFinished
+// instead.
+func (j *job) idle() bool {
+	if j.spec != nil || len(j.workers) > 0 || len(j.watchers) > 0 {
+		return false
+	}
+	for _, ckpt := range j.checkpoints {
+		if !ckpt.Completed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// job returns the state for key, creating it if needed. c.mu must be held.
+func (c *Coordinator) job(key JobKey) *job {
+	j, ok := c.jobs[key]
+	if !ok {
+		j = newJob()
+		c.jobs[key] = j
+	}
+
+	return j
+}
+
+// lookupJob returns the state for key without creating it. c.mu must be
+// held.
+func (c *Coordinator) lookupJob(key JobKey) (*job, bool) {
+	j, ok := c.jobs[key]
+
+	return j, ok
+}
+
+// dropIfIdle forgets key once its job is idle. c.mu must be held.
+func (c *Coordinator) dropIfIdle(key JobKey) {
+	if j, ok := c.jobs[key]; ok && j.idle() {
+		delete(c.jobs, key)
+	}
+}
+
+// Jobs returns the keys of every job the coordinator tracks: submitted jobs
+// and jobs with registered workers or a checkpoint in progress, sorted by
+// namespace and job ID.
+func (c *Coordinator) Jobs() []JobKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sortedJobKeys()
+}
+
+// sortedJobKeys returns the keys of c.jobs sorted by namespace and job ID.
+// c.mu must be held.
+func (c *Coordinator) sortedJobKeys() []JobKey {
+	keys := make([]JobKey, 0, len(c.jobs))
+	for k := range c.jobs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].Namespace != keys[b].Namespace {
+			return keys[a].Namespace < keys[b].Namespace
+		}
+
+		return keys[a].JobID < keys[b].JobID
+	})
+
+	return keys
+}
+
 // WorkerInfo holds information about a worker in the cluster.
 type WorkerInfo struct {
 	ID            string
 	Address       string
 	Rank          int
 	LastHeartbeat time.Time
+	// Job is the job the worker registered with.
+	Job JobKey
+	// Resources is what the worker offered at registration, if anything.
+	Resources *pb.ResourceHints
 }
 
 // CheckpointInfo holds information about a checkpoint.
@@ -69,12 +215,11 @@ func NewCoordinator(out io.Writer, timeout time.Duration) *Coordinator {
 	l := log.New(out, log.LevelInfo, log.FormatText)
 
 	c := &Coordinator{
-		workers:     make(map[string]*WorkerInfo),
-		ranks:       make(map[int]string),
-		checkpoints: make(map[string]*CheckpointInfo),
-		logger:      l,
-		timeout:     timeout,
-		stopCh:      make(chan struct{}),
+		jobs:          make(map[JobKey]*job),
+		logger:        l,
+		timeout:       timeout,
+		checkInterval: timeout / 2,
+		stopCh:        make(chan struct{}),
 	}
 	go c.reaper()
 
@@ -99,7 +244,10 @@ func (c *Coordinator) Start(address string) error {
 		// RegisterWorker never discloses the peer list to an unauthenticated
 		// caller, even if the transport-level handshake were misconfigured.
 		if c.tls.CACertPath != "" {
-			c.serverOpts = append(c.serverOpts, grpc.ChainUnaryInterceptor(authInterceptor))
+			c.serverOpts = append(c.serverOpts,
+				grpc.ChainUnaryInterceptor(authInterceptor),
+				grpc.ChainStreamInterceptor(authStreamInterceptor),
+			)
 		}
 	} else if !isLoopback(address) {
 		return errors.New("coordinator: refusing non-loopback bind without TLS; call SetTLS or bind 127.0.0.1")
@@ -117,6 +265,13 @@ func (c *Coordinator) Start(address string) error {
 	return nil
 }
 
+// Serve starts the coordinator on an existing listener, such as an
+// in-memory one in a simulated cluster. Unlike Start it applies no TLS or
+// loopback checks; the caller owns the listener's exposure.
+func (c *Coordinator) Serve(lis net.Listener) {
+	c.start(lis)
+}
+
 // SetTLS configures mutual TLS for the coordinator's gRPC server, mirroring
 // WorkerNodeConfig.TLS from T140.1. Must be called before Start. Set
 // tls.CACertPath to require and verify a client certificate on every RPC
@@ -149,6 +304,16 @@ func authInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, hand
 	return handler(ctx, req)
 }
 
+// authStreamInterceptor is authInterceptor for streaming RPCs such as
+// Watch, which would otherwise disclose job membership.
+func authStreamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !peerAuthenticated(ss.Context()) {
+		return grpcstatus.Error(codes.Unauthenticated, "coordinator: rpc requires a verified client certificate")
+	}
+
+	return handler(srv, ss)
+}
+
 // peerAuthenticated reports whether ctx carries a peer that completed a TLS
 // handshake with at least one verified certificate chain (i.e. the peer
 // presented a client certificate signed by a CA the server trusts).
@@ -202,8 +367,15 @@ func isLoopback(addr string) bool {
 // start starts the coordinator service on the given listener.
 func (c *Coordinator) start(lis net.Listener) {
 	c.lis = lis
-	c.server = grpc.NewServer(c.serverOpts...)
+	opts := append([]grpc.ServerOption{distributed.TracingServerOption()}, c.serverOpts...)
+	c.server = grpc.NewServer(opts...)
 	pb.RegisterCoordinatorServer(c.server, c)
+	// The health service reports SERVING for the overall server ("") and
+	// for the Coordinator service until Stop, so load balancers and
+	// orchestrators can probe it with the standard gRPC health protocol.
+	c.health = grpchealth.NewServer()
+	c.health.SetServingStatus(pb.Coordinator_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(c.server, c.health)
 	c.logger.Info("starting gRPC server", "address", lis.Addr().String())
 
 	go func() {
@@ -228,6 +400,7 @@ func (c *Coordinator) Stop() {
 
 	if c.server != nil {
 		c.logger.Info("stopping gRPC server")
+		c.health.Shutdown()
 		c.server.GracefulStop()
 	}
 }
@@ -237,37 +410,11 @@ func (c *Coordinator) GracefulStop() {
 	c.stopOnce.Do(func() { close(c.stopCh) })
 
 	if c.server != nil {
+		c.health.Shutdown()
 		c.server.GracefulStop()
 	}
 }
 
-func (c *Coordinator) reaper() {
-	ticker := time.NewTicker(c.timeout / 2)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			c.evictStaleWorkers()
-		case <-c.stopCh:
-			return
-		}
-	}
-}
-
-func (c *Coordinator) evictStaleWorkers() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	for id, worker := range c.workers {
-		if time.Since(worker.LastHeartbeat) > c.timeout {
-			c.logger.Warn("worker timed out", "worker", id)
-			delete(c.workers, id)
-			delete(c.ranks, worker.Rank)
-		}
-	}
-}
-
 // RegisterWorker registers a new worker with the coordinator.
 func (c *Coordinator) RegisterWorker(_ context.Context, req *pb.RegisterWorkerRequest) (*pb.RegisterWorkerResponse, error) {
 	c.mu.Lock()
@@ -277,35 +424,48 @@ func (c *Coordinator) RegisterWorker(_ context.Context, req *pb.RegisterWorkerRe
 		return nil, errors.New("worker id cannot be empty")
 	}
 
-	if _, ok := c.workers[req.WorkerId]; ok {
-		c.logger.Warn("worker already registered", "worker", req.WorkerId)
+	key := jobKey(req.Namespace, req.JobId)
+	j := c.job(key)
 
-		return nil, fmt.Errorf("worker %s already registered", req.WorkerId)
+	if _, ok := j.workers[req.WorkerId]; ok {
+		c.logger.Warn("worker already registered", "worker", req.WorkerId, "job", key.String())
+
+		return nil, fmt.Errorf("worker %s already registered in job %s", req.WorkerId, key)
 	}
 
-	rank := c.nextRank
-	c.nextRank++
+	if err := j.admit(key, req.Resources); err != nil {
+		c.logger.Warn("worker rejected", "worker", req.WorkerId, "job", key.String(), "error", err.Error())
+
+		return nil, err
+	}
+
+	rank := j.nextRank
+	j.nextRank++
 
 	w := &WorkerInfo{
 		ID:            req.WorkerId,
 		Address:       req.Address,
 		Rank:          rank,
 		LastHeartbeat: time.Now(),
+		Job:           key,
+		Resources:     req.Resources,
 	}
-	c.workers[req.WorkerId] = w
-	c.ranks[rank] = req.WorkerId
-	c.logger.Info("registered worker", "worker", req.WorkerId, "address", req.Address, "rank", fmt.Sprintf("%d", rank))
-
-	peers := make([]string, 0, len(c.workers))
-	for r := range c.nextRank {
-		workerID, ok := c.ranks[r]
+	j.workers[req.WorkerId] = w
+	j.ranks[rank] = req.WorkerId
+	c.logger.Info("registered worker", "worker", req.WorkerId, "address", req.Address, "rank", fmt.Sprintf("%d", rank), "job", key.String())
+	c.schedule()
+	j.publish(nil)
+
+	peers := make([]string, 0, len(j.workers))
+	for r := range j.nextRank {
+		workerID, ok := j.ranks[r]
 		if !ok {
 			c.logger.Warn("rank not found in ranks map", "rank", fmt.Sprintf("%d", r))
 
 			continue
 		}
 
-		worker, ok := c.workers[workerID]
+		worker, ok := j.workers[workerID]
 		if !ok {
 			c.logger.Warn("worker not found in workers map", "worker", workerID)
 
@@ -321,8 +481,9 @@ func (c *Coordinator) RegisterWorker(_ context.Context, req *pb.RegisterWorkerRe
 	}
 
 	return &pb.RegisterWorkerResponse{
-		Rank:  int32(rank), // #nosec G115 - Range checked above
-		Peers: peers,
+		Rank:     int32(rank), // #nosec G115 - Range checked above
+		Peers:    peers,
+		JobState: j.state,
 	}, nil
 }
 
@@ -335,16 +496,21 @@ func (c *Coordinator) UnregisterWorker(_ context.Context, req *pb.UnregisterWork
 		return nil, errors.New("worker id cannot be empty")
 	}
 
-	w, ok := c.workers[req.WorkerId]
+	key := jobKey(req.Namespace, req.JobId)
+	w, ok := c.findWorker(key, req.WorkerId)
 	if !ok {
-		c.logger.Warn("worker not found for unregistration", "worker", req.WorkerId)
+		c.logger.Warn("worker not found for unregistration", "worker", req.WorkerId, "job", key.String())
 
-		return nil, fmt.Errorf("worker %s not found", req.WorkerId)
+		return nil, fmt.Errorf("worker %s not found in job %s", req.WorkerId, key)
 	}
 
-	delete(c.workers, req.WorkerId)
-	delete(c.ranks, w.Rank)
-	c.logger.Info("unregistered worker", "worker", req.WorkerId)
+	j := c.jobs[key]
+	delete(j.workers, req.WorkerId)
+	delete(j.ranks, w.Rank)
+	c.workerLeft(key, j)
+	j.publish(nil)
+	c.dropIfIdle(key)
+	c.logger.Info("unregistered worker", "worker", req.WorkerId, "job", key.String())
 
 	return &pb.UnregisterWorkerResponse{}, nil
 }
@@ -358,18 +524,19 @@ func (c *Coordinator) Heartbeat(_ context.Context, req *pb.HeartbeatRequest) (*p
 		return nil, errors.New("worker id cannot be empty")
 	}
 
-	w, ok := c.workers[req.WorkerId]
+	key := jobKey(req.Namespace, req.JobId)
+	w, ok := c.findWorker(key, req.WorkerId)
 	if !ok {
-		c.logger.Warn("worker not found for heartbeat", "worker", req.WorkerId)
+		c.logger.Warn("worker not found for heartbeat", "worker", req.WorkerId, "job", key.String())
 
-		return nil, fmt.Errorf("worker %s not found", req.WorkerId)
+		return nil, fmt.Errorf("worker %s not found in job %s", req.WorkerId, key)
 	}
 
 	w.LastHeartbeat = time.Now()
 
 	c.logger.Debug("received heartbeat", "worker", req.WorkerId)
 
-	return &pb.HeartbeatResponse{Status: "OK"}, nil
+	return &pb.HeartbeatResponse{Status: "OK", JobState: c.jobs[key].state}, nil
 }
 
 // StartCheckpoint initiates a new checkpoint process.
@@ -377,20 +544,23 @@ func (c *Coordinator) StartCheckpoint(_ context.Context, req *pb.StartCheckpoint
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// Safe conversion check for epoch
+	if req.Epoch > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("epoch %d exceeds int32 maximum value", req.Epoch)
+	}
+
+	key := jobKey(req.Namespace, req.JobId)
+	j := c.job(key)
+
 	checkpointID := fmt.Sprintf("ckpt-%d", req.Epoch)
-	c.logger.Info("starting checkpoint", "checkpoint", checkpointID, "epoch", fmt.Sprintf("%d", req.Epoch), "path", req.Path)
+	c.logger.Info("starting checkpoint", "checkpoint", checkpointID, "epoch", fmt.Sprintf("%d", req.Epoch), "path", req.Path, "job", key.String())
 
 	workers := make(map[string]bool)
-	for id := range c.workers {
+	for id := range j.workers {
 		workers[id] = false
 	}
 
-	// Safe conversion check for epoch
-	if req.Epoch > int64(^uint32(0)>>1) {
-		return nil, fmt.Errorf("epoch %d exceeds int32 maximum value", req.Epoch)
-	}
-
-	c.checkpoints[checkpointID] = &CheckpointInfo{
+	j.checkpoints[checkpointID] = &CheckpointInfo{
 		ID:      checkpointID,
 		Epoch:   int32(req.Epoch), // #nosec G115 - Range checked above
 		Path:    req.Path,
@@ -409,13 +579,19 @@ func (c *Coordinator) EndCheckpoint(_ context.Context, req *pb.EndCheckpointRequ
 		return nil, errors.New("worker id cannot be empty")
 	}
 
-	checkpoint, ok := c.checkpoints[req.CheckpointId]
+	key := jobKey(req.Namespace, req.JobId)
+	j, ok := c.lookupJob(key)
+	if !ok {
+		return nil, fmt.Errorf("checkpoint %s not found in job %s", req.CheckpointId, key)
+	}
+
+	checkpoint, ok := j.checkpoints[req.CheckpointId]
 	if !ok {
-		return nil, fmt.Errorf("checkpoint %s not found", req.CheckpointId)
+		return nil, fmt.Errorf("checkpoint %s not found in job %s", req.CheckpointId, key)
 	}
 
 	checkpoint.Workers[req.WorkerId] = true
-	c.logger.Info("worker finished checkpoint", "worker", req.WorkerId, "checkpoint", req.CheckpointId, "epoch", fmt.Sprintf("%d", req.Epoch))
+	c.logger.Info("worker finished checkpoint", "worker", req.WorkerId, "checkpoint", req.CheckpointId, "epoch", fmt.Sprintf("%d", req.Epoch), "job", key.String())
 
 	completed := true
 
@@ -430,11 +606,40 @@ func (c *Coordinator) EndCheckpoint(_ context.Context, req *pb.EndCheckpointRequ
 	if completed {
 		checkpoint.Completed = true
 
-		c.logger.Info("checkpoint completed", "checkpoint", req.CheckpointId, "epoch", fmt.Sprintf("%d", req.Epoch))
+		c.logger.Info("checkpoint completed", "checkpoint", req.CheckpointId, "epoch", fmt.Sprintf("%d", req.Epoch), "job", key.String())
+		c.dropIfIdle(key)
 	}
 
 	return &pb.EndCheckpointResponse{}, nil
 }
 
+// ListWorkers returns the registered workers of one job, ordered by rank.
+// Workers of other jobs are never included.
+func (c *Coordinator) ListWorkers(_ context.Context, req *pb.ListWorkersRequest) (*pb.ListWorkersResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp := &pb.ListWorkersResponse{}
+	j, ok := c.lookupJob(jobKey(req.Namespace, req.JobId))
+	if !ok {
+		return resp, nil
+	}
+	resp.Workers = j.workerStatuses()
+
+	return resp, nil
+}
+
+// findWorker returns the worker id registered in the job key. c.mu must be
+// held.
+func (c *Coordinator) findWorker(key JobKey, id string) (*WorkerInfo, bool) {
+	j, ok := c.lookupJob(key)
+	if !ok {
+		return nil, false
+	}
+	w, ok := j.workers[id]
+
+	return w, ok
+}
+
 // Statically assert that the type implements the interface.
 var _ pb.CoordinatorServer = (*Coordinator)(nil)
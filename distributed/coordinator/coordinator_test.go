@@ -14,6 +14,7 @@ import (
 	"github.com/zerfoo/zerfoo/distributed/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/zerfoo/ztensor/testing/testutils"
@@ -42,8 +43,15 @@ func (b *syncBuffer) String() string {
 	return b.buf.String()
 }
 
+// defaultJob returns the state of the job that requests without a namespace
+// or job ID belong to.
+func defaultJob(c *Coordinator) *job {
+	return c.job(jobKey("", ""))
+}
+
 type testKit struct {
 	client pb.CoordinatorClient
+	health healthpb.HealthClient
 	coord  *Coordinator
 	lis    *bufconn.Listener
 	buf    *bytes.Buffer
@@ -79,6 +87,7 @@ func setup(t *testing.T) *testKit {
 
 	return &testKit{
 		client: pb.NewCoordinatorClient(conn),
+		health: healthpb.NewHealthClient(conn),
 		coord:  coord,
 		lis:    lis,
 		buf:    &buf,
@@ -176,7 +185,7 @@ func TestCoordinator_RegisterWorker(t *testing.T) {
 	// Test rank not found - this is a synthetic test
 	kit.coord.mu.Lock()
 	// create a gap in ranks
-	delete(kit.coord.ranks, 0)
+	delete(defaultJob(kit.coord).ranks, 0)
 	kit.coord.mu.Unlock()
 
 	resp3, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: "worker-3", Address: "addr-3"})
@@ -194,7 +203,7 @@ func TestCoordinator_RegisterWorker(t *testing.T) {
 	// Test worker not found in workers map - this is a synthetic test
 	kit.coord.mu.Lock()
 	// create an inconsistent state
-	kit.coord.ranks[1] = "worker-dne"
+	defaultJob(kit.coord).ranks[1] = "worker-dne"
 	kit.coord.mu.Unlock()
 
 	resp4, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: "worker-4", Address: "addr-4"})
@@ -230,7 +239,7 @@ func TestCoordinator_UnregisterWorker(t *testing.T) {
 
 	// Verify it's gone
 	kit.coord.mu.Lock()
-	_, ok := kit.coord.workers["worker-1"]
+	_, ok := defaultJob(kit.coord).workers["worker-1"]
 	kit.coord.mu.Unlock()
 
 	if ok {
@@ -256,7 +265,7 @@ func TestCoordinator_Heartbeat(t *testing.T) {
 
 	// Get initial heartbeat time
 	kit.coord.mu.Lock()
-	initialHeartbeat := kit.coord.workers["worker-1"].LastHeartbeat
+	initialHeartbeat := defaultJob(kit.coord).workers["worker-1"].LastHeartbeat
 	kit.coord.mu.Unlock()
 
 	// Wait a bit to ensure time progresses
@@ -272,7 +281,7 @@ func TestCoordinator_Heartbeat(t *testing.T) {
 
 	// Verify heartbeat time was updated
 	kit.coord.mu.Lock()
-	newHeartbeat := kit.coord.workers["worker-1"].LastHeartbeat
+	newHeartbeat := defaultJob(kit.coord).workers["worker-1"].LastHeartbeat
 	kit.coord.mu.Unlock()
 	testutils.AssertTrue(t, newHeartbeat.After(initialHeartbeat), "expected new heartbeat to be after initial heartbeat")
 
@@ -498,9 +507,9 @@ func TestCoordinator_RegisterWorker_Peers(t *testing.T) {
 
 	expectedPeers := []string{}
 
-	for r := range kit.coord.nextRank {
-		if workerID, ok := kit.coord.ranks[r]; ok {
-			expectedPeers = append(expectedPeers, kit.coord.workers[workerID].Address)
+	for r := range defaultJob(kit.coord).nextRank {
+		if workerID, ok := defaultJob(kit.coord).ranks[r]; ok {
+			expectedPeers = append(expectedPeers, defaultJob(kit.coord).workers[workerID].Address)
 		}
 	}
 
@@ -581,7 +590,7 @@ func TestCoordinator_UnregisterWorker_Table(t *testing.T) {
 				defer kit.coord.mu.Unlock()
 
 				if tt.expectWorkers != nil {
-					testutils.AssertEqual(subT, len(tt.expectWorkers), len(kit.coord.workers), "expected %d workers, got %d")
+					testutils.AssertEqual(subT, len(tt.expectWorkers), len(defaultJob(kit.coord).workers), "expected %d workers, got %d")
 				}
 			}
 		})
@@ -639,8 +648,7 @@ func TestNewCoordinator(t *testing.T) {
 
 	coord := NewCoordinator(&buf, 10*time.Second)
 	testutils.AssertNotNil(t, coord, "expected coordinator to not be nil")
-	testutils.AssertNotNil(t, coord.workers, "expected workers map to not be nil")
-	testutils.AssertNotNil(t, coord.ranks, "expected ranks map to not be nil")
+	testutils.AssertNotNil(t, coord.jobs, "expected jobs map to not be nil")
 	testutils.AssertNotNil(t, coord.logger, "expected logger to not be nil")
 }
 
@@ -695,7 +703,7 @@ func TestCoordinator_ReaperStopsOnClose(t *testing.T) {
 	// Register a stale worker directly (bypassing gRPC) to verify
 	// the reaper is truly stopped and will NOT evict it.
 	coord.mu.Lock()
-	coord.workers["stale-worker"] = &WorkerInfo{
+	defaultJob(coord).workers["stale-worker"] = &WorkerInfo{
 		ID:            "stale-worker",
 		Address:       "addr-stale",
 		Rank:          0,
@@ -708,7 +716,7 @@ func TestCoordinator_ReaperStopsOnClose(t *testing.T) {
 
 	// The stale worker should still be present because the reaper has exited.
 	coord.mu.Lock()
-	_, ok := coord.workers["stale-worker"]
+	_, ok := defaultJob(coord).workers["stale-worker"]
 	coord.mu.Unlock()
 
 	if !ok {
@@ -722,3 +730,116 @@ func TestCoordinator_EndCheckpoint_EmptyWorkerID(t *testing.T) {
 	_, err := kit.client.EndCheckpoint(ctx, &pb.EndCheckpointRequest{WorkerId: "", Epoch: 1, CheckpointId: "ckpt-1"})
 	testutils.AssertError(t, err, "expected an error for empty worker ID, got nil")
 }
+
+func TestCoordinator_JobIsolation(t *testing.T) {
+	kit := setup(t)
+	ctx := context.Background()
+
+	register := func(ns, job, id string) *pb.RegisterWorkerResponse {
+		t.Helper()
+		resp, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: id, Address: id + ":1", Namespace: ns, JobId: job})
+		if err != nil {
+			t.Fatalf("register %s/%s/%s: %v", ns, job, id, err)
+		}
+
+		return resp
+	}
+
+	register("team-a", "run-1", "w0")
+	register("team-a", "run-1", "w1")
+	// The same worker ID in another job is a different member, ranked from 0.
+	resp := register("team-b", "run-1", "w0")
+	if resp.Rank != 0 || len(resp.Peers) != 1 || resp.Peers[0] != "w0:1" {
+		t.Errorf("team-b register = rank %d, peers %v; want rank 0 and only itself", resp.Rank, resp.Peers)
+	}
+
+	list, err := kit.client.ListWorkers(ctx, &pb.ListWorkersRequest{Namespace: "team-a", JobId: "run-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Workers) != 2 || list.Workers[0].WorkerId != "w0" || list.Workers[1].Rank != 1 {
+		t.Errorf("team-a members = %v", list.Workers)
+	}
+
+	// Membership operations on one job do not reach another.
+	if _, err := kit.client.Heartbeat(ctx, &pb.HeartbeatRequest{WorkerId: "w1", Namespace: "team-b", JobId: "run-1"}); err == nil {
+		t.Error("heartbeat for a worker of another job should fail")
+	}
+	if _, err := kit.client.UnregisterWorker(ctx, &pb.UnregisterWorkerRequest{WorkerId: "w0", Namespace: "team-b", JobId: "run-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := kit.coord.findWorker(jobKey("team-a", "run-1"), "w0"); !ok {
+		t.Error("unregistering team-b/w0 removed team-a/w0")
+	}
+
+	// Checkpoints with the same epoch are tracked per job.
+	ckpt, err := kit.client.StartCheckpoint(ctx, &pb.StartCheckpointRequest{Epoch: 1, Namespace: "team-a", JobId: "run-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kit.client.EndCheckpoint(ctx, &pb.EndCheckpointRequest{WorkerId: "w0", CheckpointId: ckpt.CheckpointId, Namespace: "team-b", JobId: "run-1"}); err == nil {
+		t.Error("ending a checkpoint of another job should fail")
+	}
+	for _, id := range []string{"w0", "w1"} {
+		if _, err := kit.client.EndCheckpoint(ctx, &pb.EndCheckpointRequest{WorkerId: id, CheckpointId: ckpt.CheckpointId, Namespace: "team-a", JobId: "run-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !kit.coord.jobs[jobKey("team-a", "run-1")].checkpoints[ckpt.CheckpointId].Completed {
+		t.Error("team-a checkpoint should be completed")
+	}
+
+	// team-b has no members or checkpoints left, so it is forgotten.
+	if got := kit.coord.Jobs(); len(got) != 1 || got[0] != (JobKey{Namespace: "team-a", JobID: "run-1"}) {
+		t.Errorf("Jobs = %v", got)
+	}
+}
+
+func TestCoordinator_DefaultJob(t *testing.T) {
+	kit := setup(t)
+	ctx := context.Background()
+
+	if _, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: "w0", Address: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	// Explicit default names address the same job as empty ones.
+	list, err := kit.client.ListWorkers(ctx, &pb.ListWorkersRequest{Namespace: DefaultNamespace, JobId: DefaultJobID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Workers) != 1 || list.Workers[0].WorkerId != "w0" {
+		t.Errorf("default job members = %v", list.Workers)
+	}
+
+	list, err = kit.client.ListWorkers(ctx, &pb.ListWorkersRequest{JobId: "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Workers) != 0 {
+		t.Errorf("unknown job members = %v", list.Workers)
+	}
+}
+
+func TestCoordinator_Health(t *testing.T) {
+	kit := setup(t)
+	ctx := context.Background()
+
+	for _, service := range []string{"", pb.Coordinator_ServiceDesc.ServiceName} {
+		resp, err := kit.health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", service, err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Check(%q) = %v, want SERVING", service, resp.Status)
+		}
+	}
+
+	kit.coord.health.Shutdown()
+	resp, err := kit.health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("after shutdown = %v, want NOT_SERVING", resp.Status)
+	}
+}
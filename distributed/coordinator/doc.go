@@ -1,4 +1,30 @@
 // Package coordinator provides a distributed training coordinator with worker registry.
 //
+// Workers register under a namespace and job ID, and every RPC is scoped to
+// one job: worker IDs and ranks are unique per job, checkpoints track only
+// that job's workers, and ListWorkers reports only its members. Requests
+// that omit the namespace or job ID use DefaultNamespace and DefaultJobID,
+// so single-job clients need no changes.
+//
+// The coordinator is also a light scheduler. SubmitJob declares a job's
+// world size and per-worker resource hints; the job stays queued until that
+// many workers whose resources satisfy the hints have registered, and is
+// then dispatched, subject to SetMaxRunningJobs. Workers learn the job state
+// from RegisterWorker and Heartbeat. A running job completes when all of its
+// workers leave, and CancelJob stops a queued or running one. Jobs whose
+// workers register without a SubmitJob run immediately, as before.
+//
+// Workers that stop heartbeating are declared dead after the timeout, or
+// after the number of missed heartbeats set by SetFailureDetection. The
+// survivors of a job are then renumbered to ranks 0 through n-1, and Watch
+// streams the new membership, with the failed worker IDs, to every client
+// following the job. Workers configured with distributed.GrpcStrategyConfig.
+// Rebalance use it to reform their all-reduce group without a restart.
+// Workers that leave through UnregisterWorker keep everyone else's rank.
+//
+// The coordinator's server also implements the standard gRPC health
+// protocol (grpc.health.v1), reporting SERVING for the overall server and
+// for the Coordinator service until Stop.
+//
 // Stability: beta
 package coordinator
@@ -0,0 +1,208 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// watchBuffer is how many membership events a Watch stream may fall behind
+// before the coordinator drops it.
+const watchBuffer = 16
+
+// SetFailureDetection configures how the coordinator detects failed
+// workers. Workers are expected to heartbeat every interval; one that has
+// missed maxMissed consecutive heartbeats is declared dead, removed from
+// its job, and the survivors are renumbered to ranks 0 through n-1 in their
+// previous rank order. Watchers of the job receive a MembershipEvent naming
+// the failed workers so the survivors can reform their all-reduce groups.
+//
+// The default checks every timeout/2 and declares a worker dead after
+// timeout without a heartbeat, where timeout is NewCoordinator's argument.
+// A non-positive interval leaves the setting unchanged, and maxMissed is at
+// least 1. Must be called before Start.
+func (c *Coordinator) SetFailureDetection(interval time.Duration, maxMissed int) {
+	if interval <= 0 {
+		return
+	}
+	maxMissed = max(maxMissed, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkInterval = interval
+	c.timeout = interval * time.Duration(maxMissed)
+}
+
+// reapInterval returns how often the reaper checks for failed workers.
+func (c *Coordinator) reapInterval() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.checkInterval
+}
+
+func (c *Coordinator) reaper() {
+	timer := time.NewTimer(c.reapInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			c.evictStaleWorkers()
+			timer.Reset(c.reapInterval())
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// evictStaleWorkers declares every worker that has not heartbeated within
+// the timeout dead, compacts the ranks of the survivors, and notifies the
+// job's watchers.
+func (c *Coordinator) evictStaleWorkers() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, j := range c.jobs {
+		var failed []string
+		for id, worker := range j.workers {
+			if time.Since(worker.LastHeartbeat) > c.timeout {
+				c.logger.Warn("worker timed out", "worker", id, "job", key.String())
+				delete(j.workers, id)
+				delete(j.ranks, worker.Rank)
+				failed = append(failed, id)
+			}
+		}
+		if len(failed) > 0 {
+			sort.Strings(failed)
+			j.compactRanks()
+			c.logger.Info("rebalanced job",
+				"job", key.String(),
+				"failed", strings.Join(failed, ","),
+				"workers", fmt.Sprintf("%d", len(j.workers)),
+			)
+			c.workerLeft(key, j)
+			j.publish(failed)
+		}
+		c.dropIfIdle(key)
+	}
+}
+
+// compactRanks renumbers j's workers to ranks 0 through len(j.workers)-1,
+// keeping their relative order, so a failed worker leaves no hole in the
+// rank space. c.mu must be held.
+func (j *job) compactRanks() {
+	ranks := make(map[int]string, len(j.workers))
+	next := 0
+	for r := range j.nextRank {
+		w, ok := j.workers[j.ranks[r]]
+		if !ok {
+			continue
+		}
+		w.Rank = next
+		ranks[next] = w.ID
+		next++
+	}
+	j.ranks = ranks
+	j.nextRank = next
+}
+
+// workerStatuses returns j's workers ordered by rank. c.mu must be held.
+func (j *job) workerStatuses() []*pb.WorkerStatus {
+	var out []*pb.WorkerStatus
+	for r := range j.nextRank {
+		w, ok := j.workers[j.ranks[r]]
+		if !ok {
+			continue
+		}
+
+		out = append(out, &pb.WorkerStatus{
+			WorkerId:              w.ID,
+			Address:               w.Address,
+			Rank:                  int32(w.Rank), // #nosec G115 - RegisterWorker rejects ranks beyond int32
+			LastHeartbeatUnixNano: w.LastHeartbeat.UnixNano(),
+		})
+	}
+
+	return out
+}
+
+// membership returns j's current membership as an event. c.mu must be held.
+func (j *job) membership(failed []string) *pb.MembershipEvent {
+	return &pb.MembershipEvent{
+		Epoch:           j.epoch,
+		Workers:         j.workerStatuses(),
+		FailedWorkerIds: failed,
+		JobState:        j.state,
+	}
+}
+
+// publish advances j's membership epoch and sends the new membership to
+// every watcher. A watcher whose buffer is full is dropped; its Watch call
+// fails and the client is expected to reconnect. c.mu must be held.
+func (j *job) publish(failed []string) {
+	j.epoch++
+	if len(j.watchers) == 0 {
+		return
+	}
+
+	ev := j.membership(failed)
+	for ch := range j.watchers {
+		select {
+		case ch <- ev:
+		default:
+			close(ch)
+			delete(j.watchers, ch)
+		}
+	}
+}
+
+// Watch streams the membership of one job: the current membership first,
+// then a MembershipEvent after every registration, unregistration, and
+// failure. It returns when the client cancels, the coordinator stops, or
+// the client falls too far behind, in which case it fails with
+// codes.ResourceExhausted.
+func (c *Coordinator) Watch(req *pb.WatchRequest, stream pb.Coordinator_WatchServer) error {
+	key := jobKey(req.Namespace, req.JobId)
+	ch := make(chan *pb.MembershipEvent, watchBuffer)
+
+	c.mu.Lock()
+	j := c.job(key)
+	j.watchers[ch] = struct{}{}
+	first := j.membership(nil)
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(j.watchers, ch)
+		if c.jobs[key] == j {
+			c.dropIfIdle(key)
+		}
+		c.mu.Unlock()
+	}()
+
+	if err := stream.Send(first); err != nil {
+		return err
+	}
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return grpcstatus.Errorf(codes.ResourceExhausted, "watcher of job %s fell behind", key)
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-c.stopCh:
+			return grpcstatus.Error(codes.Unavailable, "coordinator stopping")
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package coordinator
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+func memberIDs(ev *pb.MembershipEvent) []string {
+	ids := make([]string, len(ev.Workers))
+	for i, w := range ev.Workers {
+		ids[i] = w.WorkerId
+	}
+
+	return ids
+}
+
+func TestWatch_FailureCompactsRanks(t *testing.T) {
+	kit := setupShortTimeout(t, 100*time.Millisecond)
+	kit.coord.SetFailureDetection(20*time.Millisecond, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, id := range []string{"w0", "w1", "w2"} {
+		if _, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: id, Address: id + ":1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stream, err := kit.client.Watch(ctx, &pb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Epoch != 3 || !slices.Equal(memberIDs(first), []string{"w0", "w1", "w2"}) || len(first.FailedWorkerIds) != 0 {
+		t.Fatalf("snapshot = %v", first)
+	}
+
+	// Keep w0 and w2 alive; w1 goes silent.
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for _, id := range []string{"w0", "w2"} {
+				_, _ = kit.client.Heartbeat(ctx, &pb.HeartbeatRequest{WorkerId: id})
+			}
+		}
+	}()
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Epoch != 4 || !slices.Equal(ev.FailedWorkerIds, []string{"w1"}) {
+		t.Fatalf("event = %v, want epoch 4 failing w1", ev)
+	}
+	if !slices.Equal(memberIDs(ev), []string{"w0", "w2"}) {
+		t.Fatalf("members = %v, want [w0 w2]", memberIDs(ev))
+	}
+	for i, w := range ev.Workers {
+		if int(w.Rank) != i {
+			t.Errorf("%s has rank %d, want %d", w.WorkerId, w.Rank, i)
+		}
+	}
+
+	// The next registrant takes the first free rank after compaction.
+	resp, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: "w3", Address: "w3:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Rank != 2 || !slices.Equal(resp.Peers, []string{"w0:1", "w2:1", "w3:1"}) {
+		t.Errorf("register = rank %d peers %v, want rank 2 peers [w0:1 w2:1 w3:1]", resp.Rank, resp.Peers)
+	}
+	ev, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Epoch != 5 || len(ev.FailedWorkerIds) != 0 || !slices.Equal(memberIDs(ev), []string{"w0", "w2", "w3"}) {
+		t.Errorf("event after register = %v", ev)
+	}
+}
+
+func TestWatch_GracefulLeaveKeepsRanks(t *testing.T) {
+	kit := setup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := kit.client.Watch(ctx, &pb.WatchRequest{JobId: "j"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev, err := stream.Recv(); err != nil || len(ev.Workers) != 0 {
+		t.Fatalf("snapshot = %v, %v", ev, err)
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: id, JobId: "j"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := kit.client.UnregisterWorker(ctx, &pb.UnregisterWorkerRequest{WorkerId: "a", JobId: "j"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ev.FailedWorkerIds) != 0 {
+		t.Errorf("graceful leave reported failures %v", ev.FailedWorkerIds)
+	}
+	if len(ev.Workers) != 2 || ev.Workers[0].Rank != 1 || ev.Workers[1].Rank != 2 {
+		t.Errorf("workers = %v, want b and c at ranks 1 and 2", ev.Workers)
+	}
+}
+
+func TestWatch_KeepsJobUntilWatcherLeaves(t *testing.T) {
+	kit := setup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := kit.client.Watch(ctx, &pb.WatchRequest{Namespace: "ns", JobId: "j"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := kit.coord.Jobs(); len(got) != 1 || got[0] != (JobKey{"ns", "j"}) {
+		t.Errorf("Jobs() = %v while watching", got)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(kit.coord.Jobs()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Jobs() = %v after the watcher left", kit.coord.Jobs())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatch_SlowWatcherDropped(t *testing.T) {
+	kit := setup(t)
+	ch := make(chan *pb.MembershipEvent, watchBuffer)
+
+	kit.coord.mu.Lock()
+	j := defaultJob(kit.coord)
+	j.watchers[ch] = struct{}{}
+	for range watchBuffer + 1 {
+		j.publish(nil)
+	}
+	_, stillWatching := j.watchers[ch]
+	kit.coord.mu.Unlock()
+
+	if stillWatching {
+		t.Fatal("watcher with a full buffer was not dropped")
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != watchBuffer {
+		t.Errorf("received %d events before the drop, want %d", n, watchBuffer)
+	}
+}
+
+func TestWatch_StopEndsStream(t *testing.T) {
+	kit := setup(t)
+	stream, err := kit.client.Watch(context.Background(), &pb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		kit.coord.GracefulStop()
+		close(done)
+	}()
+	if _, err := stream.Recv(); grpcstatus.Code(err) != codes.Unavailable {
+		t.Errorf("Recv after stop = %v, want Unavailable", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GracefulStop blocked on an open Watch stream")
+	}
+}
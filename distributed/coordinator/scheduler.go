@@ -0,0 +1,291 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxFinishedJobs bounds how many completed or cancelled submitted jobs are
+// kept for status queries; the oldest are forgotten first.
+const maxFinishedJobs = 100
+
+// jobSpec is what a SubmitJob request declares about a job.
+type jobSpec struct {
+	worldSize int
+	resources *pb.ResourceHints
+}
+
+// SetMaxRunningJobs limits how many submitted jobs may run at once. A job
+// whose workers have all registered stays queued until a running job
+// completes or is cancelled; queued jobs are dispatched in submission order.
+// Zero, the default, means no limit. Jobs whose workers register without a
+// SubmitJob are not scheduled and do not count against the limit.
+func (c *Coordinator) SetMaxRunningJobs(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxRunning = n
+	c.schedule()
+}
+
+// SubmitJob declares a job's world size and per-worker resource hints. The
+// job is queued until world_size workers whose resources satisfy the hints
+// have registered under its namespace and job ID, and is then dispatched:
+// its state becomes JOB_STATE_RUNNING, which workers observe through
+// Heartbeat. Resubmitting a finished job replaces it.
+func (c *Coordinator) SubmitJob(_ context.Context, req *pb.SubmitJobRequest) (*pb.SubmitJobResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if req.WorldSize <= 0 {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, "world size must be positive, got %d", req.WorldSize)
+	}
+	if req.GetResources().GetGpus() < 0 || req.GetResources().GetMemoryBytes() < 0 {
+		return nil, grpcstatus.Error(codes.InvalidArgument, "resource hints must not be negative")
+	}
+
+	key := jobKey(req.Namespace, req.JobId)
+	j, ok := c.lookupJob(key)
+	switch {
+	case ok && terminal(j.state):
+		j = newJob()
+		c.jobs[key] = j
+	case ok && j.spec != nil:
+		return nil, grpcstatus.Errorf(codes.AlreadyExists, "job %s already submitted", key)
+	case ok && len(j.workers) > int(req.WorldSize):
+		return nil, grpcstatus.Errorf(codes.FailedPrecondition, "job %s already has %d workers, more than world size %d", key, len(j.workers), req.WorldSize)
+	case !ok:
+		j = c.job(key)
+	}
+
+	// Workers that registered before the submission must satisfy it too.
+	resources, _ := proto.Clone(req.GetResources()).(*pb.ResourceHints)
+	for id, w := range j.workers {
+		if !satisfies(w.Resources, resources) {
+			return nil, grpcstatus.Errorf(codes.FailedPrecondition, "registered worker %s does not satisfy the resource hints of job %s", id, key)
+		}
+	}
+
+	c.submitSeq++
+	j.spec = &jobSpec{worldSize: int(req.WorldSize), resources: resources}
+	j.seq = c.submitSeq
+	j.state = pb.JobState_JOB_STATE_QUEUED
+	j.submitted = time.Now()
+	c.logger.Info("submitted job", "job", key.String(), "world_size", fmt.Sprintf("%d", req.WorldSize))
+	c.schedule()
+
+	return &pb.SubmitJobResponse{Job: j.status(key)}, nil
+}
+
+// GetJob returns the status of one job.
+func (c *Coordinator) GetJob(_ context.Context, req *pb.GetJobRequest) (*pb.GetJobResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := jobKey(req.Namespace, req.JobId)
+	j, ok := c.lookupJob(key)
+	if !ok {
+		return nil, grpcstatus.Errorf(codes.NotFound, "job %s not found", key)
+	}
+
+	return &pb.GetJobResponse{Job: j.status(key)}, nil
+}
+
+// ListJobs returns the status of every job in req.Namespace, or of every
+// job when it is empty, sorted by namespace and job ID.
+func (c *Coordinator) ListJobs(_ context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp := &pb.ListJobsResponse{}
+	for _, key := range c.sortedJobKeys() {
+		if req.Namespace != "" && key.Namespace != req.Namespace {
+			continue
+		}
+		resp.Jobs = append(resp.Jobs, c.jobs[key].status(key))
+	}
+
+	return resp, nil
+}
+
+// CancelJob cancels a job. Its workers stay registered until they
+// unregister, and see JOB_STATE_CANCELLED on their next Heartbeat; new
+// workers are refused. Cancelling a finished job returns its status
+// unchanged.
+func (c *Coordinator) CancelJob(_ context.Context, req *pb.CancelJobRequest) (*pb.CancelJobResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := jobKey(req.Namespace, req.JobId)
+	j, ok := c.lookupJob(key)
+	if !ok {
+		return nil, grpcstatus.Errorf(codes.NotFound, "job %s not found", key)
+	}
+
+	if !terminal(j.state) {
+		j.state = pb.JobState_JOB_STATE_CANCELLED
+		j.finished = time.Now()
+		c.logger.Info("cancelled job", "job", key.String())
+		c.schedule()
+	}
+	resp := &pb.CancelJobResponse{Job: j.status(key)}
+	c.dropIfIdle(key)
+
+	return resp, nil
+}
+
+// admit returns an error if a worker offering resources may not join j.
+func (j *job) admit(key JobKey, resources *pb.ResourceHints) error {
+	if terminal(j.state) {
+		return grpcstatus.Errorf(codes.FailedPrecondition, "job %s is %s", key, stateName(j.state))
+	}
+	if j.spec == nil {
+		return nil
+	}
+	if len(j.workers) >= j.spec.worldSize {
+		return grpcstatus.Errorf(codes.ResourceExhausted, "job %s already has its %d workers", key, j.spec.worldSize)
+	}
+	if !satisfies(resources, j.spec.resources) {
+		return grpcstatus.Errorf(codes.FailedPrecondition, "worker resources do not satisfy the hints of job %s", key)
+	}
+
+	return nil
+}
+
+// workerLeft updates j after one of its workers unregistered or timed out:
+// a running submitted job completes when its last worker leaves, freeing a
+// slot for the next queued job. c.mu must be held.
+func (c *Coordinator) workerLeft(key JobKey, j *job) {
+	if j.spec == nil || j.state != pb.JobState_JOB_STATE_RUNNING || len(j.workers) > 0 {
+		return
+	}
+
+	j.state = pb.JobState_JOB_STATE_COMPLETED
+	j.finished = time.Now()
+	c.logger.Info("job completed", "job", key.String())
+	c.schedule()
+}
+
+// schedule dispatches queued jobs whose workers have all registered, in
+// submission order, while running slots are free, then prunes old finished
+// jobs. c.mu must be held.
+func (c *Coordinator) schedule() {
+	var running int
+	var ready []JobKey
+	for key, j := range c.jobs {
+		if j.spec == nil {
+			continue
+		}
+		switch {
+		case j.state == pb.JobState_JOB_STATE_RUNNING:
+			running++
+		case j.state == pb.JobState_JOB_STATE_QUEUED && len(j.workers) >= j.spec.worldSize:
+			ready = append(ready, key)
+		}
+	}
+	sort.Slice(ready, func(a, b int) bool { return c.jobs[ready[a]].seq < c.jobs[ready[b]].seq })
+
+	for _, key := range ready {
+		if c.maxRunning > 0 && running >= c.maxRunning {
+			break
+		}
+		j := c.jobs[key]
+		j.state = pb.JobState_JOB_STATE_RUNNING
+		j.dispatched = time.Now()
+		running++
+		c.logger.Info("dispatched job", "job", key.String(), "workers", fmt.Sprintf("%d", len(j.workers)))
+	}
+
+	c.pruneFinished()
+}
+
+// pruneFinished forgets the oldest finished submitted jobs beyond
+// maxFinishedJobs. c.mu must be held.
+func (c *Coordinator) pruneFinished() {
+	var finished []JobKey
+	for key, j := range c.jobs {
+		if j.spec != nil && terminal(j.state) && len(j.workers) == 0 {
+			finished = append(finished, key)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(a, b int) bool {
+		return c.jobs[finished[a]].finished.Before(c.jobs[finished[b]].finished)
+	})
+	for _, key := range finished[:len(finished)-maxFinishedJobs] {
+		delete(c.jobs, key)
+	}
+}
+
+// status reports j as a pb.JobStatus. c.mu must be held.
+func (j *job) status(key JobKey) *pb.JobStatus {
+	st := &pb.JobStatus{
+		Namespace: key.Namespace,
+		JobId:     key.JobID,
+		State:     j.state,
+		Workers:   int32(len(j.workers)), // #nosec G115 - worker counts are small
+	}
+	if j.spec != nil {
+		st.WorldSize = int32(j.spec.worldSize) // #nosec G115 - from an int32 request field
+		st.Resources, _ = proto.Clone(j.spec.resources).(*pb.ResourceHints)
+	}
+	if !j.submitted.IsZero() {
+		st.SubmittedUnixNano = j.submitted.UnixNano()
+	}
+	if !j.dispatched.IsZero() {
+		st.DispatchedUnixNano = j.dispatched.UnixNano()
+	}
+	if !j.finished.IsZero() {
+		st.FinishedUnixNano = j.finished.UnixNano()
+	}
+
+	return st
+}
+
+// satisfies reports whether a worker offering have meets the per-worker
+// hints want. Nil hints are satisfied by any worker.
+func satisfies(have, want *pb.ResourceHints) bool {
+	if want == nil {
+		return true
+	}
+	if have.GetGpus() < want.GetGpus() || have.GetMemoryBytes() < want.GetMemoryBytes() {
+		return false
+	}
+	for k, v := range want.GetLabels() {
+		if got, ok := have.GetLabels()[k]; !ok || got != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func terminal(s pb.JobState) bool {
+	return s == pb.JobState_JOB_STATE_COMPLETED || s == pb.JobState_JOB_STATE_CANCELLED
+}
+
+// stateName returns s without its JOB_STATE_ prefix, in lower case.
+func stateName(s pb.JobState) string {
+	switch s {
+	case pb.JobState_JOB_STATE_QUEUED:
+		return "queued"
+	case pb.JobState_JOB_STATE_RUNNING:
+		return "running"
+	case pb.JobState_JOB_STATE_COMPLETED:
+		return "completed"
+	case pb.JobState_JOB_STATE_CANCELLED:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
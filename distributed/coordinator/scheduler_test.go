@@ -0,0 +1,192 @@
+package coordinator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+func (k *testKit) submit(t *testing.T, job string, worldSize int32, hints *pb.ResourceHints) *pb.JobStatus {
+	t.Helper()
+	resp, err := k.client.SubmitJob(context.Background(), &pb.SubmitJobRequest{JobId: job, WorldSize: worldSize, Resources: hints})
+	if err != nil {
+		t.Fatalf("SubmitJob(%s): %v", job, err)
+	}
+
+	return resp.Job
+}
+
+func (k *testKit) register(t *testing.T, job, worker string, res *pb.ResourceHints) *pb.RegisterWorkerResponse {
+	t.Helper()
+	resp, err := k.client.RegisterWorker(context.Background(), &pb.RegisterWorkerRequest{WorkerId: worker, Address: worker + ":1", JobId: job, Resources: res})
+	if err != nil {
+		t.Fatalf("RegisterWorker(%s/%s): %v", job, worker, err)
+	}
+
+	return resp
+}
+
+func (k *testKit) state(t *testing.T, job string) pb.JobState {
+	t.Helper()
+	resp, err := k.client.GetJob(context.Background(), &pb.GetJobRequest{JobId: job})
+	if err != nil {
+		t.Fatalf("GetJob(%s): %v", job, err)
+	}
+
+	return resp.Job.State
+}
+
+func TestScheduler_QueuesUntilWorldSize(t *testing.T) {
+	kit := setup(t)
+
+	if st := kit.submit(t, "train", 2, nil); st.State != pb.JobState_JOB_STATE_QUEUED || st.WorldSize != 2 {
+		t.Fatalf("submitted status = %v", st)
+	}
+	if resp := kit.register(t, "train", "w0", nil); resp.JobState != pb.JobState_JOB_STATE_QUEUED {
+		t.Errorf("first worker sees %v, want queued", resp.JobState)
+	}
+	resp := kit.register(t, "train", "w1", nil)
+	if resp.JobState != pb.JobState_JOB_STATE_RUNNING || len(resp.Peers) != 2 {
+		t.Errorf("last worker sees %v with peers %v, want running with both", resp.JobState, resp.Peers)
+	}
+
+	hb, err := kit.client.Heartbeat(context.Background(), &pb.HeartbeatRequest{WorkerId: "w0", JobId: "train"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hb.JobState != pb.JobState_JOB_STATE_RUNNING {
+		t.Errorf("heartbeat job state = %v, want running", hb.JobState)
+	}
+
+	_, err = kit.client.RegisterWorker(context.Background(), &pb.RegisterWorkerRequest{WorkerId: "w2", JobId: "train"})
+	if grpcstatus.Code(err) != codes.ResourceExhausted {
+		t.Errorf("registering beyond world size: %v, want ResourceExhausted", err)
+	}
+}
+
+func TestScheduler_ResourceHints(t *testing.T) {
+	kit := setup(t)
+	kit.submit(t, "gpu", 1, &pb.ResourceHints{Gpus: 2, MemoryBytes: 1 << 30, Labels: map[string]string{"zone": "a"}})
+
+	for _, res := range []*pb.ResourceHints{
+		nil,
+		{Gpus: 1, MemoryBytes: 1 << 30, Labels: map[string]string{"zone": "a"}},
+		{Gpus: 2, MemoryBytes: 1 << 30, Labels: map[string]string{"zone": "b"}},
+	} {
+		_, err := kit.client.RegisterWorker(context.Background(), &pb.RegisterWorkerRequest{WorkerId: "w", JobId: "gpu", Resources: res})
+		if grpcstatus.Code(err) != codes.FailedPrecondition {
+			t.Errorf("resources %v: err = %v, want FailedPrecondition", res, err)
+		}
+	}
+
+	kit.register(t, "gpu", "w", &pb.ResourceHints{Gpus: 4, MemoryBytes: 2 << 30, Labels: map[string]string{"zone": "a", "rack": "7"}})
+	if got := kit.state(t, "gpu"); got != pb.JobState_JOB_STATE_RUNNING {
+		t.Errorf("state = %v, want running", got)
+	}
+}
+
+func TestScheduler_MaxRunningJobs(t *testing.T) {
+	kit := setup(t)
+	kit.coord.SetMaxRunningJobs(1)
+
+	kit.submit(t, "first", 1, nil)
+	kit.submit(t, "second", 1, nil)
+	kit.register(t, "second", "b", nil)
+	kit.register(t, "first", "a", nil)
+
+	// second was ready first, so it took the only slot.
+	if got := kit.state(t, "second"); got != pb.JobState_JOB_STATE_RUNNING {
+		t.Errorf("second = %v, want running", got)
+	}
+	if got := kit.state(t, "first"); got != pb.JobState_JOB_STATE_QUEUED {
+		t.Errorf("first = %v, want queued behind the running job", got)
+	}
+
+	if _, err := kit.client.UnregisterWorker(context.Background(), &pb.UnregisterWorkerRequest{WorkerId: "b", JobId: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := kit.state(t, "second"); got != pb.JobState_JOB_STATE_COMPLETED {
+		t.Errorf("second = %v, want completed once its workers left", got)
+	}
+	if got := kit.state(t, "first"); got != pb.JobState_JOB_STATE_RUNNING {
+		t.Errorf("first = %v, want dispatched into the freed slot", got)
+	}
+
+	// A finished job can be submitted again.
+	if st := kit.submit(t, "second", 1, nil); st.State != pb.JobState_JOB_STATE_QUEUED || st.Workers != 0 {
+		t.Errorf("resubmitted status = %v", st)
+	}
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	kit := setup(t)
+	ctx := context.Background()
+	kit.submit(t, "doomed", 2, nil)
+	kit.register(t, "doomed", "w0", nil)
+
+	resp, err := kit.client.CancelJob(ctx, &pb.CancelJobRequest{JobId: "doomed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Job.State != pb.JobState_JOB_STATE_CANCELLED || resp.Job.FinishedUnixNano == 0 {
+		t.Errorf("cancel status = %v", resp.Job)
+	}
+
+	hb, err := kit.client.Heartbeat(ctx, &pb.HeartbeatRequest{WorkerId: "w0", JobId: "doomed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hb.JobState != pb.JobState_JOB_STATE_CANCELLED {
+		t.Errorf("heartbeat job state = %v, want cancelled", hb.JobState)
+	}
+	if _, err := kit.client.RegisterWorker(ctx, &pb.RegisterWorkerRequest{WorkerId: "w1", JobId: "doomed"}); grpcstatus.Code(err) != codes.FailedPrecondition {
+		t.Errorf("registering into a cancelled job: %v, want FailedPrecondition", err)
+	}
+
+	if _, err := kit.client.CancelJob(ctx, &pb.CancelJobRequest{JobId: "missing"}); grpcstatus.Code(err) != codes.NotFound {
+		t.Errorf("cancelling an unknown job: %v, want NotFound", err)
+	}
+}
+
+func TestScheduler_ListJobs(t *testing.T) {
+	kit := setup(t)
+	ctx := context.Background()
+
+	if _, err := kit.client.SubmitJob(ctx, &pb.SubmitJobRequest{Namespace: "a", JobId: "x", WorldSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kit.client.SubmitJob(ctx, &pb.SubmitJobRequest{Namespace: "b", JobId: "y", WorldSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// Workers registering without a submission appear as unscheduled jobs.
+	kit.register(t, "", "legacy", nil)
+
+	all, err := kit.client.ListJobs(ctx, &pb.ListJobsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Jobs) != 3 || all.Jobs[0].Namespace != "a" || all.Jobs[2].JobId != DefaultJobID {
+		t.Errorf("ListJobs = %v", all.Jobs)
+	}
+	if legacy := all.Jobs[2]; legacy.State != pb.JobState_JOB_STATE_RUNNING || legacy.WorldSize != 0 {
+		t.Errorf("unscheduled job = %v", legacy)
+	}
+
+	only, err := kit.client.ListJobs(ctx, &pb.ListJobsRequest{Namespace: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(only.Jobs) != 1 || only.Jobs[0].JobId != "y" {
+		t.Errorf("ListJobs(b) = %v", only.Jobs)
+	}
+
+	if _, err := kit.client.SubmitJob(ctx, &pb.SubmitJobRequest{Namespace: "a", JobId: "x", WorldSize: 1}); grpcstatus.Code(err) != codes.AlreadyExists {
+		t.Errorf("resubmitting a queued job: %v, want AlreadyExists", err)
+	}
+	if _, err := kit.client.SubmitJob(ctx, &pb.SubmitJobRequest{JobId: "z"}); grpcstatus.Code(err) != codes.InvalidArgument {
+		t.Errorf("submitting without a world size: %v, want InvalidArgument", err)
+	}
+}
@@ -2,6 +2,7 @@ package distributed
 
 import (
 	"context"
+	"errors"
 	"net"
 	"sync"
 	"testing"
@@ -533,6 +534,14 @@ func (m *CustomMockDistributedServiceClient) Broadcast(_ context.Context, _ *pb.
 	return &pb.BroadcastResponse{}, nil
 }
 
+func (m *CustomMockDistributedServiceClient) Drain(_ context.Context, _ *pb.DrainRequest, _ ...grpc.CallOption) (*pb.DrainResponse, error) {
+	return &pb.DrainResponse{}, nil
+}
+
+func (m *CustomMockDistributedServiceClient) RingReduce(_ context.Context, _ ...grpc.CallOption) (pb.DistributedService_RingReduceClient, error) {
+	return nil, errors.New("RingReduce not mocked")
+}
+
 func (m *CustomMockDistributedServiceClient) AssertExpectations(t *testing.T) {
 	t.Helper()
 }
@@ -0,0 +1,244 @@
+package distsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/coordinator"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc"
+)
+
+// CoordinatorAddress is the simulated coordinator's address on the
+// cluster's Network.
+const CoordinatorAddress = "coordinator"
+
+// Config configures a simulated cluster.
+type Config struct {
+	// Workers is the number of workers. It must be positive.
+	Workers int
+	// HeartbeatTimeout is how long the coordinator keeps a worker that has
+	// stopped heartbeating. The cluster heartbeats for every live worker
+	// at a third of this interval, so only killed workers time out.
+	// Defaults to 30s.
+	HeartbeatTimeout time.Duration
+	// Namespace and JobID select the job the workers join. Empty values
+	// use the coordinator's default job.
+	Namespace string
+	JobID     string
+	// Log receives the coordinator's log output. Defaults to io.Discard.
+	Log io.Writer
+	// Rebalance makes the workers follow the coordinator's membership, so
+	// once a killed worker times out the survivors take ranks 0 through
+	// n-1 and collectives succeed again without it.
+	Rebalance bool
+}
+
+// Cluster is a coordinator and Workers gRPC strategies wired together
+// over an in-memory Network within one process.
+type Cluster struct {
+	// Coordinator is the cluster's coordinator, for inspecting membership
+	// or driving the scheduler directly.
+	Coordinator *coordinator.Coordinator
+	// Network carries all of the cluster's traffic.
+	Network *Network
+
+	cfg        Config
+	coordConn  *grpc.ClientConn
+	coordCli   pb.CoordinatorClient
+	workers    []*worker
+	stop       chan struct{}
+	heartbeats sync.WaitGroup
+	closeOnce  sync.Once
+
+	mu sync.Mutex // guards worker.killed
+}
+
+type worker struct {
+	addr     string
+	server   *grpc.Server
+	strategy *distributed.GrpcStrategy[float32]
+	killed   bool
+}
+
+// Start starts a coordinator on a fresh Network and initializes
+// cfg.Workers strategies against it, in rank order. Call Close when done.
+func Start(cfg Config) (*Cluster, error) {
+	if cfg.Workers <= 0 {
+		return nil, fmt.Errorf("distsim: workers must be positive, got %d", cfg.Workers)
+	}
+	if cfg.HeartbeatTimeout <= 0 {
+		cfg.HeartbeatTimeout = 30 * time.Second
+	}
+	if cfg.Log == nil {
+		cfg.Log = io.Discard
+	}
+
+	network := NewNetwork()
+	lis, err := network.Listen("tcp", CoordinatorAddress)
+	if err != nil {
+		return nil, err
+	}
+	coord := coordinator.NewCoordinator(cfg.Log, cfg.HeartbeatTimeout)
+	coord.Serve(lis)
+
+	c := &Cluster{
+		Coordinator: coord,
+		Network:     network,
+		cfg:         cfg,
+		stop:        make(chan struct{}),
+	}
+	if c.coordConn, err = network.Dial(context.Background(), CoordinatorAddress); err != nil {
+		coord.Stop()
+		return nil, err
+	}
+	c.coordCli = pb.NewCoordinatorClient(c.coordConn)
+
+	for i := range cfg.Workers {
+		w, err := c.startWorker(i)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("distsim: worker %d: %w", i, err)
+		}
+		c.workers = append(c.workers, w)
+	}
+
+	c.heartbeats.Add(1)
+	go c.heartbeat()
+	return c, nil
+}
+
+func (c *Cluster) startWorker(i int) (*worker, error) {
+	w := &worker{
+		addr:   fmt.Sprintf("worker-%d", i),
+		server: grpc.NewServer(distributed.TracingServerOption()),
+	}
+	w.strategy = distributed.NewGrpcStrategy[float32](distributed.GrpcStrategyConfig{
+		WorkerAddress:  w.addr,
+		ServerManager:  distributed.NewServerManager(w.server, c.Network.Listen),
+		NetworkManager: distributed.NewNetworkManager(c.Network.Dial, nil),
+		Dialer:         c.Network.Dial,
+		Namespace:      c.cfg.Namespace,
+		JobID:          c.cfg.JobID,
+		Rebalance:      c.cfg.Rebalance,
+	})
+	if err := w.strategy.Init(0, c.cfg.Workers, CoordinatorAddress); err != nil {
+		w.server.Stop()
+		return nil, err
+	}
+	if w.strategy.Rank() != i {
+		w.strategy.Shutdown()
+		return nil, fmt.Errorf("assigned rank %d, want %d; is another client using the job?", w.strategy.Rank(), i)
+	}
+	return w, nil
+}
+
+// heartbeat keeps every live worker registered until Close.
+func (c *Cluster) heartbeat() {
+	defer c.heartbeats.Done()
+	ticker := time.NewTicker(c.cfg.HeartbeatTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+		for i, w := range c.workers {
+			if !c.Alive(i) {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), c.cfg.HeartbeatTimeout)
+			_, _ = c.coordCli.Heartbeat(ctx, &pb.HeartbeatRequest{
+				WorkerId:  w.addr,
+				Namespace: c.cfg.Namespace,
+				JobId:     c.cfg.JobID,
+			})
+			cancel()
+		}
+	}
+}
+
+// Size returns the number of workers the cluster started with.
+func (c *Cluster) Size() int { return len(c.workers) }
+
+// Strategy returns the strategy of the worker with the given rank.
+func (c *Cluster) Strategy(rank int) distributed.InternalStrategy[float32] {
+	return c.workers[rank].strategy
+}
+
+// Address returns the network address, and coordinator worker ID, of the
+// worker with the given rank.
+func (c *Cluster) Address(rank int) string {
+	return c.workers[rank].addr
+}
+
+// Alive reports whether the worker with the given rank has not been killed.
+func (c *Cluster) Alive(rank int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return !c.workers[rank].killed
+}
+
+// Run calls fn concurrently for every live worker, as each worker process
+// would run its training step, and waits for all of them. It returns the
+// errors of the failed calls joined, each naming its rank.
+func (c *Cluster) Run(fn func(rank int, s distributed.InternalStrategy[float32]) error) error {
+	errs := make([]error, len(c.workers))
+	var wg sync.WaitGroup
+	for i, w := range c.workers {
+		if !c.Alive(i) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(i, w.strategy); err != nil {
+				errs[i] = fmt.Errorf("rank %d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Kill simulates a crash of the worker with the given rank: its server
+// stops abruptly, its address becomes unreachable, and it stops
+// heartbeating, but it does not unregister, so the coordinator only notices
+// once HeartbeatTimeout passes. Collectives involving the worker fail.
+func (c *Cluster) Kill(rank int) {
+	c.mu.Lock()
+	w := c.workers[rank]
+	if w.killed {
+		c.mu.Unlock()
+		return
+	}
+	w.killed = true
+	c.mu.Unlock()
+
+	c.Network.Disconnect(w.addr)
+	w.server.Stop()
+}
+
+// Close shuts down every worker and stops the coordinator. It is safe to
+// call more than once.
+func (c *Cluster) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		c.heartbeats.Wait()
+		for _, w := range c.workers {
+			w.strategy.Shutdown()
+		}
+		if c.coordConn != nil {
+			_ = c.coordConn.Close()
+		}
+		c.Coordinator.Stop()
+	})
+}
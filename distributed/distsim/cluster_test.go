@@ -0,0 +1,371 @@
+package distsim
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"github.com/zerfoo/ztensor/tensor"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func startCluster(t *testing.T, cfg Config) *Cluster {
+	t.Helper()
+	c, err := Start(cfg)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestCluster_ShardedAllReduce(t *testing.T) {
+	c := startCluster(t, Config{Workers: 4})
+	if c.Size() != 4 {
+		t.Fatalf("Size() = %d", c.Size())
+	}
+
+	// Each rank owns one shard of an 8-element dataset and contributes the
+	// sum of its shard at its own offset; the average times the world size
+	// reassembles the full dataset on every rank.
+	data := []float32{1, 2, 3, 4, 5, 6, 7, 8}
+	results := make([][]float32, c.Size())
+	err := c.Run(func(rank int, s distributed.InternalStrategy[float32]) error {
+		local := make([]float32, len(data))
+		per := len(data) / s.Size()
+		copy(local[rank*per:], data[rank*per:(rank+1)*per])
+		g, err := tensor.New([]int{len(local)}, local)
+		if err != nil {
+			return err
+		}
+		if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": g}); err != nil {
+			return err
+		}
+		if err := s.Barrier(); err != nil {
+			return err
+		}
+		results[rank] = g.Data()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for rank, got := range results {
+		for i, v := range got {
+			if v*4 != data[i] {
+				t.Errorf("rank %d: %v, want %v/4", rank, got, data)
+				break
+			}
+		}
+	}
+}
+
+func TestCluster_RingAllReduce(t *testing.T) {
+	c := startCluster(t, Config{Workers: 3})
+
+	// A 5-element bucket splits the 7-element gradient across two buckets,
+	// shares one between all three gradients, and leaves some ring segments
+	// with a single element.
+	sizes := map[string]int{"a": 7, "b": 3, "c": 11}
+	results := make([]map[string][]float32, c.Size())
+	err := c.Run(func(rank int, s distributed.InternalStrategy[float32]) error {
+		ring, err := distributed.NewRingStrategy(s.(*distributed.GrpcStrategy[float32]), 5)
+		if err != nil {
+			return err
+		}
+		grads := make(map[string]*tensor.TensorNumeric[float32], len(sizes))
+		for name, n := range sizes {
+			data := make([]float32, n)
+			for i := range data {
+				data[i] = float32((rank + 1) * (i + 1))
+			}
+			if grads[name], err = tensor.New([]int{n}, data); err != nil {
+				return err
+			}
+		}
+		// Two rounds check that chunks of one round do not leak into the next.
+		for range 2 {
+			if err := ring.AllReduceGradients(grads); err != nil {
+				return err
+			}
+		}
+		results[rank] = make(map[string][]float32, len(grads))
+		for name, g := range grads {
+			results[rank][name] = g.Data()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ranks contribute (rank+1)*(i+1); the average over ranks 0..2 is
+	// 2*(i+1), and averaging the average again leaves it unchanged.
+	for rank, grads := range results {
+		for name, got := range grads {
+			for i, v := range got {
+				if want := float32(2 * (i + 1)); v != want {
+					t.Errorf("rank %d %s[%d] = %v, want %v", rank, name, i, v, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCluster_RingReductionOverlapsAdds(t *testing.T) {
+	c := startCluster(t, Config{Workers: 2})
+
+	results := make([][]float32, c.Size())
+	err := c.Run(func(rank int, s distributed.InternalStrategy[float32]) error {
+		ring, err := distributed.NewRingStrategy(s.(*distributed.GrpcStrategy[float32]), 4)
+		if err != nil {
+			return err
+		}
+		// Gradients are added one at a time, as backward would finalize
+		// them; full buckets are reduced while later ones are produced.
+		r := ring.StartReduction()
+		var grads []*tensor.TensorNumeric[float32]
+		for range 6 {
+			g, err := tensor.New([]int{3}, []float32{float32(rank), 1, float32(-rank)})
+			if err != nil {
+				return err
+			}
+			r.Add(g)
+			grads = append(grads, g)
+		}
+		if err := r.Wait(); err != nil {
+			return err
+		}
+		for _, g := range grads {
+			results[rank] = append(results[rank], g.Data()...)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for rank, got := range results {
+		for i, v := range got {
+			want := []float32{0.5, 1, -0.5}[i%3]
+			if v != want {
+				t.Errorf("rank %d: element %d = %v, want %v", rank, i, v, want)
+			}
+		}
+	}
+}
+
+func TestCluster_Broadcast(t *testing.T) {
+	c := startCluster(t, Config{Workers: 3, JobID: "bcast"})
+	got := make([][]float32, c.Size())
+	err := c.Run(func(rank int, s distributed.InternalStrategy[float32]) error {
+		v := []float32{0, 0}
+		if rank == 0 {
+			v = []float32{3, 4}
+		}
+		tn, err := tensor.New([]int{2}, v)
+		if err != nil {
+			return err
+		}
+		if err := s.BroadcastTensor(tn, 0); err != nil {
+			return err
+		}
+		got[rank] = tn.Data()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rank, v := range got {
+		if v[0] != 3 || v[1] != 4 {
+			t.Errorf("rank %d received %v", rank, v)
+		}
+	}
+}
+
+func TestCluster_TracePropagation(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	c := startCluster(t, Config{Workers: 2})
+	err := c.Run(func(_ int, s distributed.InternalStrategy[float32]) error {
+		g, err := tensor.New([]int{1}, []float32{1})
+		if err != nil {
+			return err
+		}
+		return s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"g": g})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rank 1's all-reduce span and the AllReduce RPC rank 0 served for it
+	// belong to one trace.
+	var collective, served []sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		switch {
+		case s.Name() == "distributed.all_reduce":
+			collective = append(collective, s)
+		case s.Name() == "distributed.DistributedService/AllReduce" && s.SpanKind() == trace.SpanKindServer:
+			served = append(served, s)
+		}
+	}
+	if len(collective) != 2 || len(served) != 1 {
+		t.Fatalf("got %d all-reduce spans and %d served RPCs, want 2 and 1", len(collective), len(served))
+	}
+	for _, s := range collective {
+		if !slices.Contains(s.Attributes(), attribute.Int("zerfoo.rank", 1)) {
+			continue
+		}
+		if s.SpanContext().TraceID() != served[0].SpanContext().TraceID() {
+			t.Error("the AllReduce RPC served by rank 0 is not in rank 1's trace")
+		}
+		return
+	}
+	t.Error("no all-reduce span for rank 1")
+}
+
+func TestCluster_KillRoot(t *testing.T) {
+	c := startCluster(t, Config{Workers: 3, HeartbeatTimeout: 300 * time.Millisecond})
+	c.Kill(0)
+	c.Kill(0) // idempotent
+	if c.Alive(0) || !c.Alive(1) {
+		t.Fatal("Alive does not reflect Kill")
+	}
+
+	err := c.Run(func(_ int, s distributed.InternalStrategy[float32]) error {
+		g, err := tensor.New([]int{1}, []float32{1})
+		if err != nil {
+			return err
+		}
+		return s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": g})
+	})
+	if err == nil {
+		t.Fatal("all-reduce without a root should fail")
+	}
+
+	// The killed worker stops heartbeating and is evicted; the live ones
+	// are kept registered by the cluster.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		list, err := c.Coordinator.ListWorkers(context.Background(), &pb.ListWorkersRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Workers) == 2 {
+			for _, w := range list.Workers {
+				if w.WorkerId == c.Address(0) {
+					t.Errorf("killed worker %s still registered", w.WorkerId)
+				}
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("registered workers = %v, want the two live ones", list.Workers)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Live workers survive several more timeouts.
+	time.Sleep(time.Second)
+	list, err := c.Coordinator.ListWorkers(context.Background(), &pb.ListWorkersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Workers) != 2 {
+		t.Errorf("live workers evicted: %v", list.Workers)
+	}
+}
+
+func TestCluster_RebalanceAfterKill(t *testing.T) {
+	c := startCluster(t, Config{Workers: 3, HeartbeatTimeout: 300 * time.Millisecond, Rebalance: true})
+	c.Kill(1)
+
+	// Once the coordinator declares rank 1 dead, the survivors renumber
+	// themselves without a restart.
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Strategy(0).Size() != 2 || c.Strategy(2).Size() != 2 || c.Strategy(2).Rank() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("survivors did not rebalance: sizes %d and %d, rank of worker 2 is %d",
+				c.Strategy(0).Size(), c.Strategy(2).Size(), c.Strategy(2).Rank())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	results := make([][]float32, c.Size())
+	err := c.Run(func(i int, s distributed.InternalStrategy[float32]) error {
+		g, err := tensor.New([]int{2}, []float32{float32(s.Rank()), 1})
+		if err != nil {
+			return err
+		}
+		if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"x": g}); err != nil {
+			return err
+		}
+		results[i] = g.Data()
+		return s.Barrier()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 2} {
+		if got := results[i]; got[0] != 0.5 || got[1] != 1 {
+			t.Errorf("worker %d: all-reduce = %v, want [0.5 1]", i, got)
+		}
+	}
+}
+
+func TestCluster_RunJoinsErrors(t *testing.T) {
+	c := startCluster(t, Config{Workers: 2})
+	boom := errors.New("boom")
+	err := c.Run(func(rank int, _ distributed.InternalStrategy[float32]) error {
+		if rank == 1 {
+			return boom
+		}
+		return nil
+	})
+	if !errors.Is(err, boom) || err.Error() != "rank 1: boom" {
+		t.Errorf("Run() = %v", err)
+	}
+}
+
+func TestStart_InvalidConfig(t *testing.T) {
+	if _, err := Start(Config{}); err == nil {
+		t.Error("Start with no workers should fail")
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	n := NewNetwork()
+	if _, err := n.Listen("tcp", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Listen("tcp", "a"); err == nil {
+		t.Error("listening twice on one address should fail")
+	}
+	if _, err := n.dialContext(context.Background(), "b"); err == nil {
+		t.Error("dialing an unknown address should fail")
+	}
+	n.Disconnect("a")
+	if _, err := n.dialContext(context.Background(), "a"); err == nil {
+		t.Error("dialing a disconnected address should fail")
+	}
+	if _, err := n.Listen("tcp", "a"); err != nil {
+		t.Errorf("address not released by Disconnect: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Package distsim runs a simulated distributed training cluster inside one
+// process, so distributed training logic -- sharding, all-reduce, and
+// failure handling -- can be integration-tested in CI without multiple
+// machines.
+//
+// Start brings up a coordinator and N GrpcStrategy workers that talk over
+// an in-memory Network of bufconn listeners instead of TCP. Run executes a
+// function on every worker concurrently, the way each worker process would
+// run its training step, and Kill crashes a worker to exercise timeouts and
+// error paths:
+//
+//	cluster, err := distsim.Start(distsim.Config{Workers: 4})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer cluster.Close()
+//
+//	err = cluster.Run(func(rank int, s distributed.InternalStrategy[float32]) error {
+//		return s.AllReduceGradients(shardGradients(rank))
+//	})
+//
+// Because the workers are ordinary strategies, wrappers such as
+// distributed.CompressedStrategy compose with them unchanged.
+//
+// Stability: alpha
+package distsim
@@ -0,0 +1,81 @@
+package distsim
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/distributed"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is the in-memory buffer of each simulated connection.
+const bufSize = 1 << 20
+
+// Network is an in-memory network of bufconn listeners keyed by address.
+// Its Listen and Dial methods fit distributed.ListenerFactory and
+// distributed.Dialer, so servers and clients built on them never touch a
+// real socket.
+type Network struct {
+	mu        sync.Mutex
+	listeners map[string]*bufconn.Listener
+}
+
+// NewNetwork returns an empty Network.
+func NewNetwork() *Network {
+	return &Network{listeners: make(map[string]*bufconn.Listener)}
+}
+
+// Listen creates a listener for address. The network argument is ignored.
+// It fails if address is already in use.
+func (n *Network) Listen(_, address string) (net.Listener, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.listeners[address]; ok {
+		return nil, fmt.Errorf("distsim: address %s already in use", address)
+	}
+	lis := bufconn.Listen(bufSize)
+	n.listeners[address] = lis
+	return lis, nil
+}
+
+// Dial returns a client connection to target over the network. Like
+// grpc.NewClient it connects lazily, so an unreachable target surfaces as
+// an Unavailable error on the first RPC.
+func (n *Network) Dial(_ context.Context, target string) (*grpc.ClientConn, error) {
+	return grpc.NewClient("passthrough:///"+target,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return n.dialContext(ctx, target)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		distributed.TracingDialOption(),
+	)
+}
+
+func (n *Network) dialContext(ctx context.Context, address string) (net.Conn, error) {
+	n.mu.Lock()
+	lis, ok := n.listeners[address]
+	n.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("distsim: connection refused: %s", address)
+	}
+	return lis.DialContext(ctx)
+}
+
+// Disconnect closes the listener for address and forgets it, so new dials
+// to it fail as if the host were down. Established connections are closed
+// by stopping the server that owns them.
+func (n *Network) Disconnect(address string) {
+	n.mu.Lock()
+	lis, ok := n.listeners[address]
+	delete(n.listeners, address)
+	n.mu.Unlock()
+	if ok {
+		_ = lis.Close()
+	}
+}
@@ -22,6 +22,26 @@
 // (typically NCCL) while a cross-node strategy handles inter-node
 // communication (typically gRPC). Node leaders participate in both layers.
 //
+// [CompressedStrategy] wraps any InternalStrategy with top-k gradient
+// sparsification for slow inter-node links: each all-reduce sends only the
+// largest-magnitude fraction of every gradient, and the dropped elements
+// accumulate in a residual that is added back on the next step (error
+// feedback). The gRPC transport sends mostly-zero tensors in a sparse
+// encoding, so the bytes on the wire shrink with the compression ratio.
+// Set WorkerNodeConfig.GradientCompression to enable it on a [WorkerNode].
+//
+// [RingStrategy] wraps a GrpcStrategy with a ring all-reduce. Gradients are
+// packed into fixed-size buckets, and each bucket is reduced by a
+// reduce-scatter and an all-gather in which every worker streams to its
+// successor, so no worker carries more than its share of the traffic.
+// [RingStrategy.StartReduction] lets backward hand over each gradient as it
+// becomes final, overlapping communication with the rest of backward. Set
+// WorkerNodeConfig.RingBucketSize to enable it on a [WorkerNode].
+//
+// Package distsim runs a coordinator and any number of gRPC workers over an
+// in-memory network inside one process, for integration tests of
+// distributed training code without sockets or extra processes.
+//
 // # Coordinator and Worker Lifecycle
 //
 // A coordinator process (defined in the distributed/pb protobuf service)
@@ -38,9 +58,26 @@
 // [WorkerNode] wraps [GrpcStrategy] with mutex-guarded lifecycle management,
 // health check integration, and compatibility with shutdown.Coordinator.
 //
+// One coordinator can serve several independent training runs. Set
+// Namespace and JobID in [WorkerNodeConfig] or [GrpcStrategyConfig] to join
+// a specific job; ranks, peers, and checkpoints are scoped to that job, and
+// workers that leave both empty share the coordinator's default job. When
+// the job was submitted to the coordinator's scheduler, Init blocks until
+// the job is dispatched and then connects to all of its workers.
+//
+// # Health and Draining
+//
+// A [WorkerNode] serves the standard gRPC health protocol (grpc.health.v1)
+// on its own server, reporting SERVING for the DistributedService once it
+// has joined its job, as does the coordinator. For rolling maintenance,
+// [WorkerNode.Drain] (or the Drain RPC) stops admitting training steps,
+// waits for the steps bracketed by [WorkerNode.BeginStep] to finish, runs
+// the OnDrain hook to hand off state, and deregisters from the coordinator;
+// [WorkerNode.Drained] then fires so the owner can Close the node.
+//
 // # gRPC Protocol
 //
-// The protobuf service (distributed/pb) defines three RPCs on the worker
+// The protobuf service (distributed/pb) defines five RPCs on the worker
 // service:
 //
 //   - AllReduce: bidirectional streaming. Each non-root worker sends its
@@ -53,6 +90,12 @@
 //   - Broadcast: unary RPC. The root sets a tensor via SetBroadcastTensor,
 //     and non-root workers retrieve it by calling Broadcast on the root.
 //
+//   - Drain: unary RPC. Asks the worker to drain as described above, and
+//     returns once it has left the cluster.
+//
+//   - RingReduce: client streaming. A [RingStrategy] worker streams the
+//     chunks of each reduce-scatter and all-gather step to its successor.
+//
 // A separate coordinator service handles RegisterWorker, UnregisterWorker,
 // and Heartbeat RPCs for cluster membership.
 //
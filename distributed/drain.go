@@ -0,0 +1,85 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDraining is returned by WorkerNode.BeginStep once the worker has been
+// asked to drain and no longer accepts new training steps.
+var ErrDraining = errors.New("worker is draining")
+
+// stepGate tracks in-flight training steps so a drain can wait for the
+// current step to finish while refusing to start new ones.
+type stepGate struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{} // closed and replaced whenever active drops to 0
+}
+
+func newStepGate() *stepGate {
+	return &stepGate{idle: make(chan struct{})}
+}
+
+// enter admits a new step, or returns ErrDraining if the gate is closed.
+// The returned func must be called exactly once when the step ends.
+func (g *stepGate) enter() (func(), error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.draining {
+		return nil, ErrDraining
+	}
+	g.active++
+
+	var once sync.Once
+	return func() { once.Do(g.leave) }, nil
+}
+
+func (g *stepGate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.active--
+	if g.active == 0 {
+		close(g.idle)
+		g.idle = make(chan struct{})
+	}
+}
+
+// close stops admitting steps and waits until the in-flight ones have
+// finished or ctx is done.
+func (g *stepGate) close(ctx context.Context) error {
+	g.mu.Lock()
+	g.draining = true
+	if g.active == 0 {
+		g.mu.Unlock()
+		return nil
+	}
+	idle := g.idle
+	g.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *stepGate) isDraining() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.draining
+}
+
+// reopen admits steps again after an abandoned drain.
+func (g *stepGate) reopen() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.draining = false
+}
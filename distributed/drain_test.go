@@ -0,0 +1,163 @@
+package distributed_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/coordinator"
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startDrainableWorker starts a coordinator and a single WorkerNode, and
+// returns a client connection to the worker's gRPC server.
+func startDrainableWorker(t *testing.T, onDrain func(context.Context) error) (*coordinator.Coordinator, *distributed.WorkerNode, *grpc.ClientConn) {
+	t.Helper()
+	coord := coordinator.NewCoordinator(&syncWriter{}, 30*time.Second)
+	if err := coord.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start coordinator: %v", err)
+	}
+	t.Cleanup(coord.GracefulStop)
+
+	workerAddr := reserveLoopbackAddr(t)
+	wn := distributed.NewWorkerNode(distributed.WorkerNodeConfig{
+		WorkerAddress:      workerAddr,
+		CoordinatorAddress: coord.Addr().String(),
+		WorldSize:          1,
+		OnDrain:            onDrain,
+	})
+	if err := wn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = wn.Close(context.Background()) })
+
+	conn, err := grpc.NewClient(workerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return coord, wn, conn
+}
+
+func workerHealth(t *testing.T, conn *grpc.ClientConn) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: pb.DistributedService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		t.Fatalf("health Check: %v", err)
+	}
+	return resp.Status
+}
+
+func TestWorkerNode_DrainWaitsForStepAndDeregisters(t *testing.T) {
+	var handedOff atomic.Bool
+	coord, wn, conn := startDrainableWorker(t, func(context.Context) error {
+		handedOff.Store(true)
+		return nil
+	})
+
+	if got := workerHealth(t, conn); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("health before drain = %v, want SERVING", got)
+	}
+
+	done, err := wn.BeginStep()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drainErr := make(chan error, 1)
+	go func() {
+		_, err := pb.NewDistributedServiceClient(conn).Drain(context.Background(), &pb.DrainRequest{Reason: "test"})
+		drainErr <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for workerHealth(t, conn) != healthpb.HealthCheckResponse_NOT_SERVING {
+		if time.Now().After(deadline) {
+			t.Fatal("worker never reported NOT_SERVING while draining")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := wn.BeginStep(); !errors.Is(err, distributed.ErrDraining) {
+		t.Errorf("BeginStep while draining = %v, want ErrDraining", err)
+	}
+	if handedOff.Load() {
+		t.Error("state was handed off before the current step finished")
+	}
+
+	done()
+	if err := <-drainErr; err != nil {
+		t.Fatalf("Drain RPC: %v", err)
+	}
+	if !handedOff.Load() {
+		t.Error("OnDrain was not called")
+	}
+	select {
+	case <-wn.Drained():
+	default:
+		t.Error("Drained() not closed after a successful drain")
+	}
+
+	list, err := coord.ListWorkers(context.Background(), &pb.ListWorkersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Workers) != 0 {
+		t.Errorf("coordinator still lists %v after drain", list.Workers)
+	}
+
+	// A second drain is a no-op.
+	if err := wn.Drain(context.Background(), "again"); err != nil {
+		t.Errorf("second Drain = %v, want nil", err)
+	}
+}
+
+func TestWorkerNode_AbandonedDrainResumes(t *testing.T) {
+	_, wn, conn := startDrainableWorker(t, nil)
+
+	done, err := wn.BeginStep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := wn.Drain(ctx, "test"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain with a stuck step = %v, want DeadlineExceeded", err)
+	}
+	done()
+
+	if got := workerHealth(t, conn); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health after abandoned drain = %v, want SERVING", got)
+	}
+	next, err := wn.BeginStep()
+	if err != nil {
+		t.Fatalf("BeginStep after abandoned drain = %v", err)
+	}
+	next()
+}
+
+func TestWorkerNode_DrainHandOffFailure(t *testing.T) {
+	_, wn, _ := startDrainableWorker(t, func(context.Context) error {
+		return errors.New("checkpoint store unavailable")
+	})
+
+	if err := wn.Drain(context.Background(), "test"); err == nil {
+		t.Fatal("Drain should fail when OnDrain fails")
+	}
+	select {
+	case <-wn.Drained():
+		t.Error("Drained() closed after a failed hand-off")
+	default:
+	}
+	if wn.Rank() != 0 {
+		t.Errorf("Rank() = %d, want the worker still running", wn.Rank())
+	}
+}
@@ -1,6 +1,9 @@
 package distributed
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 // GenerateTestCerts is an exported alias of generateTestCerts for use in
 // external tests (e.g. distributed_test).
@@ -11,3 +14,11 @@ var WritePEM = writePEM
 
 // GenerateTestCertsFunc exposes the signature for documentation.
 type GenerateTestCertsFunc = func(t *testing.T, dir string) (caCertPath, serverCertPath, serverKeyPath string)
+
+// SetDispatchPollInterval shortens how often GrpcStrategy.Init polls a queued
+// job, restoring the default when the test ends.
+func SetDispatchPollInterval(t *testing.T, d time.Duration) {
+	old := dispatchPollInterval
+	dispatchPollInterval = d
+	t.Cleanup(func() { dispatchPollInterval = old })
+}
@@ -5,13 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/zerfoo/zerfoo/distributed/pb"
+	"github.com/zerfoo/zerfoo/internal/tracing"
 	"github.com/zerfoo/ztensor/log"
 	metrics "github.com/zerfoo/ztensor/metrics/runtime"
 	"github.com/zerfoo/ztensor/tensor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -21,10 +26,17 @@ import (
 // gRPC server (workerService) for incoming RPCs, and connects to
 // peers for outgoing RPCs.
 type GrpcStrategy[T tensor.Numeric] struct {
+	// mu guards rank, size, peerClients and peerConns, which Rebalance
+	// replaces after a worker failure.
+	mu   sync.RWMutex
 	rank int
 	size int
 
 	workerAddr    string
+	dialer        Dialer
+	namespace     string
+	jobID         string
+	resources     *pb.ResourceHints
 	service       *workerService
 	serverManager ServerManager
 	networkMgr    NetworkManager
@@ -39,7 +51,15 @@ type GrpcStrategy[T tensor.Numeric] struct {
 	collector metrics.Collector
 	tlsConfig *TLSConfig
 
-	shutdownOnce sync.Once
+	heartbeatInterval time.Duration
+	rebalance         bool
+	lastRebalance     int64 // membership epoch of the last rebalance
+	stopBackground    context.CancelFunc
+	background        sync.WaitGroup
+
+	shutdownOnce   sync.Once
+	unregisterOnce sync.Once
+	unregisterErr  error
 }
 
 // GrpcStrategyConfig holds configuration for creating a GrpcStrategy.
@@ -48,12 +68,41 @@ type GrpcStrategyConfig struct {
 	WorkerID       string
 	ServerManager  ServerManager
 	NetworkManager NetworkManager
-	Dialer         Dialer
-	Logger         log.Logger
-	Collector      metrics.Collector
-	TLS            *TLSConfig
+	// Dialer, when set, connects to the coordinator instead of a plain
+	// grpc.NewClient; TLS is then the dialer's responsibility. Peers are
+	// dialed by the NetworkManager.
+	Dialer    Dialer
+	Logger    log.Logger
+	Collector metrics.Collector
+	TLS       *TLSConfig
+	// Namespace and JobID select the job this worker joins on a shared
+	// coordinator. Empty values join the coordinator's default job.
+	Namespace string
+	JobID     string
+	// Resources is what this worker offers. A job submitted with resource
+	// hints only admits workers whose resources satisfy them.
+	Resources *pb.ResourceHints
+	// HeartbeatInterval, when positive, makes the worker heartbeat the
+	// coordinator at this interval after Init, so the coordinator's
+	// failure detector (Coordinator.SetFailureDetection) only evicts
+	// workers that have actually died.
+	HeartbeatInterval time.Duration
+	// Rebalance makes the worker watch its job's membership after Init.
+	// When the coordinator declares workers dead, the survivors adopt
+	// their new ranks and world size, reconnect to each other, and meet
+	// at a barrier, so training continues without a restart. It requires
+	// heartbeats, from HeartbeatInterval or elsewhere.
+	Rebalance bool
 }
 
+// dispatchPollInterval is how often Init heartbeats the coordinator while
+// its job is queued.
+var dispatchPollInterval = time.Second
+
+// watchRetryInterval is how long the membership watch waits before
+// reconnecting to the coordinator.
+var watchRetryInterval = time.Second
+
 // NewGrpcStrategy creates a new GrpcStrategy with the given configuration.
 func NewGrpcStrategy[T tensor.Numeric](cfg GrpcStrategyConfig) *GrpcStrategy[T] {
 	if cfg.Logger == nil {
@@ -64,11 +113,18 @@ func NewGrpcStrategy[T tensor.Numeric](cfg GrpcStrategyConfig) *GrpcStrategy[T]
 	}
 	return &GrpcStrategy[T]{
 		workerAddr:    cfg.WorkerAddress,
+		dialer:        cfg.Dialer,
+		namespace:     cfg.Namespace,
+		jobID:         cfg.JobID,
+		resources:     cfg.Resources,
 		serverManager: cfg.ServerManager,
 		networkMgr:    cfg.NetworkManager,
 		logger:        cfg.Logger,
 		collector:     cfg.Collector,
 		tlsConfig:     cfg.TLS,
+
+		heartbeatInterval: cfg.HeartbeatInterval,
+		rebalance:         cfg.Rebalance,
 	}
 }
 
@@ -78,17 +134,7 @@ func (s *GrpcStrategy[T]) Init(rank, size int, coordinatorAddress string) error
 	_ = rank // rank is assigned by the coordinator
 
 	// Connect to the coordinator.
-	var coordDialOpt grpc.DialOption
-	if s.tlsConfig != nil {
-		creds, tlsErr := s.tlsConfig.ClientCredentials()
-		if tlsErr != nil {
-			return fmt.Errorf("failed to load TLS client credentials: %w", tlsErr)
-		}
-		coordDialOpt = grpc.WithTransportCredentials(creds)
-	} else {
-		coordDialOpt = grpc.WithTransportCredentials(insecure.NewCredentials())
-	}
-	conn, err := grpc.NewClient(coordinatorAddress, coordDialOpt)
+	conn, err := s.dialCoordinator(coordinatorAddress)
 	if err != nil {
 		return fmt.Errorf("failed to connect to coordinator: %w", err)
 	}
@@ -99,15 +145,25 @@ func (s *GrpcStrategy[T]) Init(rank, size int, coordinatorAddress string) error
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	resp, err := s.coordClient.RegisterWorker(ctx, &pb.RegisterWorkerRequest{
-		WorkerId: s.workerAddr,
-		Address:  s.workerAddr,
+		WorkerId:  s.workerAddr,
+		Address:   s.workerAddr,
+		Namespace: s.namespace,
+		JobId:     s.jobID,
+		Resources: s.resources,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to register with coordinator: %w", err)
 	}
 
+	peers := resp.Peers
+	if resp.JobState == pb.JobState_JOB_STATE_QUEUED {
+		if peers, err = s.awaitDispatch(); err != nil {
+			return err
+		}
+	}
+
 	s.rank = int(resp.Rank)
-	s.size = len(resp.Peers)
+	s.size = len(peers)
 	// If the caller specifies the world size, use it. This is needed when
 	// workers register sequentially and the coordinator returns a partial
 	// peer list at registration time.
@@ -138,7 +194,7 @@ func (s *GrpcStrategy[T]) Init(rank, size int, coordinatorAddress string) error
 	// Connect to peers.
 	if s.networkMgr != nil && s.size > 1 {
 		clients, conns, connErr := s.networkMgr.ConnectToPeers(
-			resp.Peers, s.rank, 10*time.Second,
+			peers, s.rank, 10*time.Second,
 		)
 		if connErr != nil {
 			return fmt.Errorf("failed to connect to peers: %w", connErr)
@@ -147,13 +203,306 @@ func (s *GrpcStrategy[T]) Init(rank, size int, coordinatorAddress string) error
 		s.peerConns = conns
 	}
 
+	s.startBackground()
 	return nil
 }
 
+// startBackground starts the heartbeat and membership watch loops that
+// GrpcStrategyConfig enables. Shutdown stops them.
+func (s *GrpcStrategy[T]) startBackground() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopBackground = cancel
+	if s.heartbeatInterval > 0 {
+		s.background.Add(1)
+		go s.heartbeat(ctx)
+	}
+	if s.rebalance {
+		s.background.Add(1)
+		go s.watchMembership(ctx)
+	}
+}
+
+// heartbeat tells the coordinator this worker is alive every
+// heartbeatInterval until ctx is done.
+func (s *GrpcStrategy[T]) heartbeat(ctx context.Context) {
+	defer s.background.Done()
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		hbCtx, cancel := context.WithTimeout(ctx, s.heartbeatInterval)
+		_, err := s.coordClient.Heartbeat(hbCtx, &pb.HeartbeatRequest{
+			WorkerId:  s.workerAddr,
+			Namespace: s.namespace,
+			JobId:     s.jobID,
+		})
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			s.logger.Warn("heartbeat failed", "error", err.Error())
+		}
+	}
+}
+
+// watchMembership follows the job's membership until ctx is done,
+// reconnecting whenever the stream breaks, and rebalances after every
+// failure the coordinator reports.
+func (s *GrpcStrategy[T]) watchMembership(ctx context.Context) {
+	defer s.background.Done()
+	for {
+		err := s.followMembership(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warn("membership watch interrupted", "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// followMembership consumes one Watch stream until it ends.
+func (s *GrpcStrategy[T]) followMembership(ctx context.Context) error {
+	stream, err := s.coordClient.Watch(ctx, &pb.WatchRequest{
+		Namespace: s.namespace,
+		JobId:     s.jobID,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := s.applyMembership(ctx, ev); err != nil {
+			s.logger.Error("rebalance failed", "epoch", fmt.Sprintf("%d", ev.Epoch), "error", err.Error())
+		}
+	}
+}
+
+// applyMembership rebalances onto the membership in ev if it reports failed
+// workers, or if this worker's rank changed while the watch was
+// disconnected.
+func (s *GrpcStrategy[T]) applyMembership(ctx context.Context, ev *pb.MembershipEvent) error {
+	if ev.Epoch <= s.lastRebalance {
+		return nil
+	}
+
+	rank := -1
+	peers := make([]string, len(ev.Workers))
+	for i, w := range ev.Workers {
+		peers[i] = w.Address
+		if w.WorkerId == s.workerAddr {
+			rank = i
+		}
+	}
+	if rank < 0 {
+		if len(ev.FailedWorkerIds) > 0 {
+			s.logger.Warn("coordinator declared this worker dead", "epoch", fmt.Sprintf("%d", ev.Epoch))
+		}
+		return nil
+	}
+	if len(ev.FailedWorkerIds) == 0 && rank == s.Rank() {
+		return nil
+	}
+
+	s.lastRebalance = ev.Epoch
+	s.logger.Info("rebalancing after worker failure",
+		"failed", strings.Join(ev.FailedWorkerIds, ","),
+		"rank", fmt.Sprintf("%d", rank),
+		"size", fmt.Sprintf("%d", len(peers)),
+	)
+	if err := s.Rebalance(rank, peers); err != nil {
+		return err
+	}
+
+	barrierCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return s.barrier(barrierCtx)
+}
+
+// Rebalance adopts a new rank and peer list, such as the coordinator assigns
+// after a worker failure: it drops its peer connections, resizes the local
+// barrier and reduce sessions to len(peers), and connects to the new peers,
+// which are ordered by rank. Collectives already in flight fail. Callers
+// should follow it with a Barrier on every surviving worker before the next
+// collective.
+func (s *GrpcStrategy[T]) Rebalance(rank int, peers []string) error {
+	if rank < 0 || rank >= len(peers) {
+		return fmt.Errorf("rank %d out of range for %d peers", rank, len(peers))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.networkMgr != nil {
+		s.networkMgr.CloseConnections(s.peerConns)
+	}
+	s.peerClients, s.peerConns = nil, nil
+	s.rank = rank
+	s.size = len(peers)
+	if s.service != nil {
+		s.service.resize(int32(rank), int32(len(peers))) // #nosec G115 - ranks come from int32 proto fields
+	}
+
+	if s.networkMgr != nil && s.size > 1 {
+		clients, conns, err := s.networkMgr.ConnectToPeers(peers, s.rank, 10*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to connect to peers: %w", err)
+		}
+		s.peerClients = clients
+		s.peerConns = conns
+	}
+	return nil
+}
+
+// topology returns the worker's rank and peer clients for one collective.
+func (s *GrpcStrategy[T]) topology() (int, []pb.DistributedServiceClient) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rank, s.peerClients
+}
+
+// peerClient returns a client for the worker at rank. Workers register one
+// at a time, so a worker only learns the addresses of the workers that
+// registered before it; a missing peer is looked up with the coordinator
+// and connected on first use.
+func (s *GrpcStrategy[T]) peerClient(rank int) (pb.DistributedServiceClient, error) {
+	s.mu.RLock()
+	if rank < len(s.peerClients) && s.peerClients[rank] != nil {
+		c := s.peerClients[rank]
+		s.mu.RUnlock()
+		return c, nil
+	}
+	size := s.size
+	s.mu.RUnlock()
+
+	if s.networkMgr == nil || s.coordClient == nil {
+		return nil, fmt.Errorf("no connection to rank %d", rank)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := s.coordClient.ListWorkers(ctx, &pb.ListWorkersRequest{
+		Namespace: s.namespace,
+		JobId:     s.jobID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+	idx := slices.IndexFunc(resp.Workers, func(w *pb.WorkerStatus) bool { return int(w.Rank) == rank })
+	if idx < 0 {
+		return nil, fmt.Errorf("rank %d is not registered", rank)
+	}
+	clients, conns, err := s.networkMgr.ConnectToPeers([]string{resp.Workers[idx].Address}, -1, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rank %d: %w", rank, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size != size {
+		s.networkMgr.CloseConnections(conns)
+		return nil, fmt.Errorf("membership changed while connecting to rank %d", rank)
+	}
+	if rank < len(s.peerClients) && s.peerClients[rank] != nil {
+		s.networkMgr.CloseConnections(conns)
+		return s.peerClients[rank], nil
+	}
+	// Copy rather than grow in place: collectives in flight hold the old
+	// slices from topology.
+	n := max(size, len(s.peerClients), rank+1)
+	peerClients := make([]pb.DistributedServiceClient, n)
+	peerConns := make([]*grpc.ClientConn, n)
+	copy(peerClients, s.peerClients)
+	copy(peerConns, s.peerConns)
+	peerClients[rank], peerConns[rank] = clients[0], conns[0]
+	s.peerClients, s.peerConns = peerClients, peerConns
+	return clients[0], nil
+}
+
+// dialCoordinator connects to the coordinator with the configured Dialer,
+// or directly, over TLS when configured.
+func (s *GrpcStrategy[T]) dialCoordinator(address string) (*grpc.ClientConn, error) {
+	if s.dialer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return s.dialer(ctx, address)
+	}
+
+	var coordDialOpt grpc.DialOption
+	if s.tlsConfig != nil {
+		creds, tlsErr := s.tlsConfig.ClientCredentials()
+		if tlsErr != nil {
+			return nil, fmt.Errorf("failed to load TLS client credentials: %w", tlsErr)
+		}
+		coordDialOpt = grpc.WithTransportCredentials(creds)
+	} else {
+		coordDialOpt = grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+	return grpc.NewClient(address, coordDialOpt, TracingDialOption())
+}
+
+// awaitDispatch heartbeats the coordinator until the submitted job this
+// worker joined is dispatched, then returns the addresses of all its
+// workers in rank order. It fails if the job is cancelled while queued.
+func (s *GrpcStrategy[T]) awaitDispatch() ([]string, error) {
+	s.logger.Info("job queued, waiting for dispatch", "job", s.jobID)
+	ticker := time.NewTicker(dispatchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		hb, err := s.coordClient.Heartbeat(ctx, &pb.HeartbeatRequest{
+			WorkerId:  s.workerAddr,
+			Namespace: s.namespace,
+			JobId:     s.jobID,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("waiting for job dispatch: %w", err)
+		}
+
+		switch hb.JobState {
+		case pb.JobState_JOB_STATE_QUEUED:
+			continue
+		case pb.JobState_JOB_STATE_RUNNING:
+		default:
+			return nil, fmt.Errorf("job %s is %s", s.jobID, hb.JobState)
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		list, err := s.coordClient.ListWorkers(ctx, &pb.ListWorkersRequest{
+			Namespace: s.namespace,
+			JobId:     s.jobID,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list job workers: %w", err)
+		}
+		peers := make([]string, len(list.Workers))
+		for i, w := range list.Workers {
+			peers[i] = w.Address
+		}
+
+		return peers, nil
+	}
+}
+
 // AllReduceGradients performs a star-topology all-reduce. Root (rank 0)
 // collects gradients from all peers, averages them, and sends the result back.
 // Non-root workers send gradients to root and receive the averaged result.
-func (s *GrpcStrategy[T]) AllReduceGradients(gradients map[string]*tensor.TensorNumeric[T]) error {
+func (s *GrpcStrategy[T]) AllReduceGradients(gradients map[string]*tensor.TensorNumeric[T]) (err error) {
+	ctx, span := s.startSpan("distributed.all_reduce", attribute.Int("zerfoo.tensors", len(gradients)))
+	defer func() { tracing.End(span, err) }()
+
 	start := time.Now()
 	defer func() {
 		s.collector.Counter("allreduce_client_count").Inc()
@@ -168,14 +517,16 @@ func (s *GrpcStrategy[T]) AllReduceGradients(gradients map[string]*tensor.Tensor
 		protoTensors[name] = tensorToProto(t)
 	}
 
-	if s.rank == 0 {
-		return s.allReduceAsRoot(gradients, protoTensors)
+	rank, peers := s.topology()
+	if rank == 0 {
+		return s.allReduceAsRoot(ctx, gradients, protoTensors)
 	}
-	return s.allReduceAsWorker(gradients, protoTensors)
+	return s.allReduceAsWorker(ctx, peers, gradients, protoTensors)
 }
 
 // allReduceAsRoot handles the root worker's all-reduce logic.
 func (s *GrpcStrategy[T]) allReduceAsRoot(
+	ctx context.Context,
 	gradients map[string]*tensor.TensorNumeric[T],
 	protoTensors map[string]*pb.Tensor,
 ) error {
@@ -185,7 +536,7 @@ func (s *GrpcStrategy[T]) allReduceAsRoot(
 
 	// Wait for all peers to submit (they call AllReduce RPC on this server).
 	session := s.service.getSession()
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	result := session.WaitForResult(ctx)
 	if result == nil {
@@ -203,18 +554,20 @@ func (s *GrpcStrategy[T]) allReduceAsRoot(
 
 // allReduceAsWorker handles a non-root worker's all-reduce logic.
 func (s *GrpcStrategy[T]) allReduceAsWorker(
+	ctx context.Context,
+	peers []pb.DistributedServiceClient,
 	gradients map[string]*tensor.TensorNumeric[T],
 	protoTensors map[string]*pb.Tensor,
 ) error {
 	// Open AllReduce stream to root (rank 0).
-	if len(s.peerClients) == 0 || s.peerClients[0] == nil {
+	if len(peers) == 0 || peers[0] == nil {
 		return errors.New("no connection to root worker")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	stream, err := s.peerClients[0].AllReduce(ctx)
+	stream, err := peers[0].AllReduce(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to open AllReduce stream: %w", err)
 	}
@@ -252,7 +605,10 @@ func (s *GrpcStrategy[T]) allReduceAsWorker(
 }
 
 // Barrier synchronizes all workers via the root's barrier service.
-func (s *GrpcStrategy[T]) Barrier() error {
+func (s *GrpcStrategy[T]) Barrier() (err error) {
+	ctx, span := s.startSpan("distributed.barrier")
+	defer func() { tracing.End(span, err) }()
+
 	start := time.Now()
 	defer func() {
 		s.collector.Counter("barrier_client_count").Inc()
@@ -261,24 +617,32 @@ func (s *GrpcStrategy[T]) Barrier() error {
 			Observe(time.Since(start).Seconds())
 	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
+	return s.barrier(ctx)
+}
 
-	if s.rank == 0 {
+// barrier waits at the root's barrier until ctx is done.
+func (s *GrpcStrategy[T]) barrier(ctx context.Context) error {
+	rank, peers := s.topology()
+	if rank == 0 {
 		// Root participates by calling its own barrier.
 		return s.service.barrier.arrive(ctx)
 	}
 
 	// Non-root calls Barrier RPC on root.
-	if len(s.peerClients) == 0 || s.peerClients[0] == nil {
+	if len(peers) == 0 || peers[0] == nil {
 		return errors.New("no connection to root worker")
 	}
-	_, err := s.peerClients[0].Barrier(ctx, &pb.BarrierRequest{Rank: int32(s.rank)})
+	_, err := peers[0].Barrier(ctx, &pb.BarrierRequest{Rank: int32(rank)})
 	return err
 }
 
 // BroadcastTensor broadcasts a tensor from rootRank to all other workers.
-func (s *GrpcStrategy[T]) BroadcastTensor(t *tensor.TensorNumeric[T], rootRank int) error {
+func (s *GrpcStrategy[T]) BroadcastTensor(t *tensor.TensorNumeric[T], rootRank int) (err error) {
+	ctx, span := s.startSpan("distributed.broadcast", attribute.Int("zerfoo.root_rank", rootRank))
+	defer func() { tracing.End(span, err) }()
+
 	start := time.Now()
 	defer func() {
 		s.collector.Counter("broadcast_client_count").Inc()
@@ -289,21 +653,22 @@ func (s *GrpcStrategy[T]) BroadcastTensor(t *tensor.TensorNumeric[T], rootRank i
 
 	name := "broadcast"
 
-	if s.rank == rootRank {
+	rank, peers := s.topology()
+	if rank == rootRank {
 		// Root sets the tensor on the service for peers to retrieve.
 		s.service.SetBroadcastTensor(name, tensorToProto(t))
 		return nil
 	}
 
 	// Non-root retrieves the tensor from root.
-	if len(s.peerClients) == 0 || s.peerClients[rootRank] == nil {
+	if rootRank < 0 || rootRank >= len(peers) || peers[rootRank] == nil {
 		return fmt.Errorf("no connection to root worker (rank %d)", rootRank)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	resp, err := s.peerClients[rootRank].Broadcast(ctx, &pb.BroadcastRequest{Name: name})
+	resp, err := peers[rootRank].Broadcast(ctx, &pb.BroadcastRequest{Name: name})
 	if err != nil {
 		return fmt.Errorf("broadcast recv failed: %w", err)
 	}
@@ -312,32 +677,48 @@ func (s *GrpcStrategy[T]) BroadcastTensor(t *tensor.TensorNumeric[T], rootRank i
 	return nil
 }
 
+// startSpan starts the span of a collective. Collectives take no context,
+// so the span is a root; the RPCs it makes carry its trace context to the
+// peers.
+func (s *GrpcStrategy[T]) startSpan(name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	rank, size := s.Rank(), s.Size()
+	attrs = append(attrs, attribute.Int("zerfoo.rank", rank), attribute.Int("zerfoo.world_size", size))
+	return tracing.Start(context.Background(), "distributed", name, trace.WithAttributes(attrs...))
+}
+
 // Rank returns the worker's rank.
-func (s *GrpcStrategy[T]) Rank() int { return s.rank }
+func (s *GrpcStrategy[T]) Rank() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rank
+}
 
 // Size returns the total number of workers.
-func (s *GrpcStrategy[T]) Size() int { return s.size }
+func (s *GrpcStrategy[T]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.size
+}
 
 // Shutdown gracefully shuts down the strategy.
 func (s *GrpcStrategy[T]) Shutdown() {
 	s.shutdownOnce.Do(func() {
-		s.logger.Info("shutting down GrpcStrategy", "rank", fmt.Sprintf("%d", s.rank))
-
-		// Unregister from coordinator.
-		if s.coordClient != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_, err := s.coordClient.UnregisterWorker(ctx, &pb.UnregisterWorkerRequest{
-				WorkerId: s.workerAddr,
-			})
-			if err != nil {
-				s.logger.Warn("failed to unregister from coordinator", "error", err.Error())
-			}
+		s.logger.Info("shutting down GrpcStrategy", "rank", fmt.Sprintf("%d", s.Rank()))
+
+		if s.stopBackground != nil {
+			s.stopBackground()
+			s.background.Wait()
+		}
+
+		if err := s.unregister(); err != nil {
+			s.logger.Warn("failed to unregister from coordinator", "error", err.Error())
 		}
 
 		// Close peer connections.
 		if s.networkMgr != nil {
+			s.mu.Lock()
 			s.networkMgr.CloseConnections(s.peerConns)
+			s.mu.Unlock()
 		}
 
 		// Stop gRPC server.
@@ -354,6 +735,25 @@ func (s *GrpcStrategy[T]) Shutdown() {
 	})
 }
 
+// unregister removes this worker from the coordinator. Only the first call
+// contacts the coordinator; later calls return its result, so a worker that
+// deregistered while draining is not unregistered again on Shutdown.
+func (s *GrpcStrategy[T]) unregister() error {
+	s.unregisterOnce.Do(func() {
+		if s.coordClient == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, s.unregisterErr = s.coordClient.UnregisterWorker(ctx, &pb.UnregisterWorkerRequest{
+			WorkerId:  s.workerAddr,
+			Namespace: s.namespace,
+			JobId:     s.jobID,
+		})
+	})
+	return s.unregisterErr
+}
+
 // Close satisfies the shutdown.Closer interface.
 func (s *GrpcStrategy[T]) Close(_ context.Context) error {
 	s.Shutdown()
@@ -364,6 +764,7 @@ func (s *GrpcStrategy[T]) Close(_ context.Context) error {
 
 // tensorToProto converts a tensor.TensorNumeric[T] to a pb.Tensor.
 // For T=float32 this is a direct copy. For T=float64 values are narrowed.
+// Mostly-zero tensors, such as sparsified gradients, are sent sparse.
 func tensorToProto[T tensor.Numeric](t *tensor.TensorNumeric[T]) *pb.Tensor {
 	if t == nil {
 		return nil
@@ -381,7 +782,50 @@ func tensorToProto[T tensor.Numeric](t *tensor.TensorNumeric[T]) *pb.Tensor {
 		protoShape[i] = int32(v)
 	}
 
-	return &pb.Tensor{Shape: protoShape, Data: protoData}
+	return packTensor(protoShape, protoData)
+}
+
+// packTensor builds a pb.Tensor from dense data, using the sparse encoding
+// when it is smaller: an index and a value per non-zero element, which pays
+// off below half density.
+func packTensor(shape []int32, data []float32) *pb.Tensor {
+	var nnz int
+	for _, v := range data {
+		if v != 0 {
+			nnz++
+		}
+	}
+	if 2*nnz >= len(data) {
+		return &pb.Tensor{Shape: shape, Data: data}
+	}
+
+	p := &pb.Tensor{Shape: shape, Data: make([]float32, 0, nnz), Indices: make([]uint32, 0, nnz)}
+	for i, v := range data {
+		if v != 0 {
+			p.Data = append(p.Data, v)
+			p.Indices = append(p.Indices, uint32(i)) // #nosec G115 - validated tensor sizes fit in uint32
+		}
+	}
+	return p
+}
+
+// denseData returns p's elements in dense form, expanding a sparse tensor.
+// Out-of-range sparse entries, which validateTensor rejects, are ignored.
+func denseData(p *pb.Tensor) []float32 {
+	if len(p.Indices) == 0 {
+		return p.Data
+	}
+	n := 1
+	for _, d := range p.Shape {
+		n *= max(int(d), 0)
+	}
+	data := make([]float32, n)
+	for i, idx := range p.Indices {
+		if int(idx) < n && i < len(p.Data) {
+			data[idx] = p.Data[i]
+		}
+	}
+	return data
 }
 
 // protoToTensor converts a pb.Tensor to a tensor.TensorNumeric[T].
@@ -394,8 +838,9 @@ func protoToTensor[T tensor.Numeric](p *pb.Tensor) (*tensor.TensorNumeric[T], er
 		shape[i] = int(v)
 	}
 
-	data := make([]T, len(p.Data))
-	for i, v := range p.Data {
+	dense := denseData(p)
+	data := make([]T, len(dense))
+	for i, v := range dense {
 		data[i] = T(v)
 	}
 
@@ -407,10 +852,11 @@ func updateTensorFromProto[T tensor.Numeric](t *tensor.TensorNumeric[T], p *pb.T
 	if t == nil || p == nil {
 		return
 	}
+	dense := denseData(p)
 	data := t.Data()
 	for i := range data {
-		if i < len(p.Data) {
-			data[i] = T(p.Data[i])
+		if i < len(dense) {
+			data[i] = T(dense[i])
 		}
 	}
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/zerfoo/zerfoo/distributed"
 	"github.com/zerfoo/zerfoo/distributed/coordinator"
+	"github.com/zerfoo/zerfoo/distributed/pb"
 	"github.com/zerfoo/ztensor/tensor"
 	"google.golang.org/grpc"
 )
@@ -149,6 +150,55 @@ func TestMultiWorkerAllReduce_SingleWorker(t *testing.T) {
 	}
 }
 
+func TestMultiWorkerAllReduce_Compressed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	cluster := newTestCluster(t, 2)
+	strategies := make([]*distributed.CompressedStrategy[float32], 2)
+	for i, w := range cluster.workers {
+		s, err := distributed.NewCompressedStrategy[float32](w, 0.125)
+		if err != nil {
+			t.Fatal(err)
+		}
+		strategies[i] = s
+	}
+
+	// Each worker sends only its largest element, so the gradients travel
+	// sparse and the average covers the union of the two supports.
+	grads := []map[string]*tensor.TensorNumeric[float32]{
+		makeGradients(t, []float32{8, 1, 0, 0, 0, 0, 0, 1}),
+		makeGradients(t, []float32{0, 1, 0, 0, 0, 0, -6, 0}),
+	}
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func(rank int) {
+			defer wg.Done()
+			errs[rank] = strategies[rank].AllReduceGradients(grads[rank])
+		}(i)
+	}
+	wg.Wait()
+
+	want := []float32{4, 0, 0, 0, 0, 0, -3, 0}
+	for i, g := range grads {
+		if errs[i] != nil {
+			t.Fatalf("worker %d AllReduce error: %v", i, errs[i])
+		}
+		for j, v := range g["grad"].Data() {
+			if v != want[j] {
+				t.Errorf("worker %d grad = %v, want %v", i, g["grad"].Data(), want)
+				break
+			}
+		}
+	}
+	if r := strategies[0].Residual("grad"); r[1] != 1 || r[7] != 1 {
+		t.Errorf("worker 0 residual = %v, want the dropped elements", r)
+	}
+}
+
 // --- T34.2: Barrier and Broadcast integration tests ---
 
 func TestMultiWorkerBarrier(t *testing.T) {
@@ -491,3 +541,87 @@ func TestWorkerNodeLifecycle(t *testing.T) {
 		t.Errorf("double Close error: %v", err)
 	}
 }
+
+func TestGrpcStrategy_WaitsForJobDispatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	distributed.SetDispatchPollInterval(t, 10*time.Millisecond)
+
+	coord := coordinator.NewCoordinator(&syncWriter{}, 30*time.Second)
+	if err := coord.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start coordinator: %v", err)
+	}
+	t.Cleanup(coord.GracefulStop)
+	if _, err := coord.SubmitJob(context.Background(), &pb.SubmitJobRequest{Namespace: "ml", JobId: "run", WorldSize: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 2
+	workers := make([]*distributed.GrpcStrategy[float32], n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		lc := net.ListenConfig{}
+		lis, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := lis.Addr().String()
+		_ = lis.Close()
+
+		workers[i] = distributed.NewGrpcStrategy[float32](distributed.GrpcStrategyConfig{
+			WorkerAddress:  addr,
+			ServerManager:  distributed.NewServerManager(grpc.NewServer(), nil),
+			NetworkManager: distributed.NewNetworkManager(nil, nil),
+			Namespace:      "ml",
+			JobID:          "run",
+		})
+		t.Cleanup(workers[i].Shutdown)
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// A world size of 0 takes the size from the dispatched job.
+			errs[i] = workers[i].Init(0, 0, coord.Addr().String())
+		}(i)
+		// Register one at a time so the first Init is queued for a while.
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+
+	for i, w := range workers {
+		if errs[i] != nil {
+			t.Fatalf("worker %d Init: %v", i, errs[i])
+		}
+		if w.Size() != n {
+			t.Errorf("worker %d size = %d, want %d", i, w.Size(), n)
+		}
+	}
+	if workers[0].Rank() == workers[1].Rank() {
+		t.Errorf("both workers got rank %d", workers[0].Rank())
+	}
+}
+
+func TestWorkerNode_GradientCompression(t *testing.T) {
+	coord := coordinator.NewCoordinator(&syncWriter{}, 30*time.Second)
+	if err := coord.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start coordinator: %v", err)
+	}
+	t.Cleanup(coord.GracefulStop)
+
+	wn := distributed.NewWorkerNode(distributed.WorkerNodeConfig{
+		WorkerAddress:       reserveLoopbackAddr(t),
+		CoordinatorAddress:  coord.Addr().String(),
+		WorldSize:           1,
+		GradientCompression: 0.1,
+	})
+	if err := wn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = wn.Close(context.Background()) })
+
+	if _, ok := wn.Strategy().(*distributed.CompressedStrategy[float32]); !ok {
+		t.Errorf("Strategy() = %T, want *CompressedStrategy", wn.Strategy())
+	}
+}
@@ -67,4 +67,6 @@ type CoordinatorClient interface {
 	RegisterWorker(ctx context.Context, in *pb.RegisterWorkerRequest, opts ...grpc.CallOption) (*pb.RegisterWorkerResponse, error)
 	UnregisterWorker(ctx context.Context, in *pb.UnregisterWorkerRequest, opts ...grpc.CallOption) (*pb.UnregisterWorkerResponse, error)
 	Heartbeat(ctx context.Context, in *pb.HeartbeatRequest, opts ...grpc.CallOption) (*pb.HeartbeatResponse, error)
+	ListWorkers(ctx context.Context, in *pb.ListWorkersRequest, opts ...grpc.CallOption) (*pb.ListWorkersResponse, error)
+	Watch(ctx context.Context, in *pb.WatchRequest, opts ...grpc.CallOption) (pb.Coordinator_WatchClient, error)
 }
@@ -25,7 +25,7 @@ type networkManager struct {
 func NewNetworkManager(dialer Dialer, clientFactory ServiceClientFactory) NetworkManager {
 	if dialer == nil {
 		dialer = func(_ context.Context, target string) (*grpc.ClientConn, error) {
-			return grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			return grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()), TracingDialOption())
 		}
 	}
 
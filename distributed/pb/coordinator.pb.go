@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.10
 // 	protoc        v5.29.3
 // source: distributed/pb/coordinator.proto
 
@@ -22,10 +22,73 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// JobState is the lifecycle state of a job.
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	// JOB_STATE_QUEUED jobs wait for workers or for a free running slot.
+	JobState_JOB_STATE_QUEUED  JobState = 1
+	JobState_JOB_STATE_RUNNING JobState = 2
+	// JOB_STATE_COMPLETED jobs were dispatched and all their workers left.
+	JobState_JOB_STATE_COMPLETED JobState = 3
+	JobState_JOB_STATE_CANCELLED JobState = 4
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_QUEUED",
+		2: "JOB_STATE_RUNNING",
+		3: "JOB_STATE_COMPLETED",
+		4: "JOB_STATE_CANCELLED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_QUEUED":      1,
+		"JOB_STATE_RUNNING":     2,
+		"JOB_STATE_COMPLETED":   3,
+		"JOB_STATE_CANCELLED":   4,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_distributed_pb_coordinator_proto_enumTypes[0].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_distributed_pb_coordinator_proto_enumTypes[0]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{0}
+}
+
 type RegisterWorkerRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
-	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId  string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Address   string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Namespace string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId     string                 `protobuf:"bytes,4,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// resources describes what the worker offers. Submitted jobs only accept
+	// workers whose resources satisfy the job's hints.
+	Resources     *ResourceHints `protobuf:"bytes,5,opt,name=resources,proto3" json:"resources,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,10 +137,34 @@ func (x *RegisterWorkerRequest) GetAddress() string {
 	return ""
 }
 
+func (x *RegisterWorkerRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RegisterWorkerRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *RegisterWorkerRequest) GetResources() *ResourceHints {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
 type RegisterWorkerResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rank          int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
-	Peers         []string               `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Rank  int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	Peers []string               `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"`
+	// job_state is JOB_STATE_QUEUED while a submitted job waits for workers;
+	// poll Heartbeat until it becomes JOB_STATE_RUNNING.
+	JobState      JobState `protobuf:"varint,3,opt,name=job_state,json=jobState,proto3,enum=distributed.JobState" json:"job_state,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -126,9 +213,18 @@ func (x *RegisterWorkerResponse) GetPeers() []string {
 	return nil
 }
 
+func (x *RegisterWorkerResponse) GetJobState() JobState {
+	if x != nil {
+		return x.JobState
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
 type UnregisterWorkerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -170,6 +266,20 @@ func (x *UnregisterWorkerRequest) GetWorkerId() string {
 	return ""
 }
 
+func (x *UnregisterWorkerRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *UnregisterWorkerRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type UnregisterWorkerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -209,6 +319,8 @@ func (*UnregisterWorkerResponse) Descriptor() ([]byte, []int) {
 type HeartbeatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -250,9 +362,24 @@ func (x *HeartbeatRequest) GetWorkerId() string {
 	return ""
 }
 
+func (x *HeartbeatRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type HeartbeatResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	JobState      JobState               `protobuf:"varint,2,opt,name=job_state,json=jobState,proto3,enum=distributed.JobState" json:"job_state,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -294,10 +421,19 @@ func (x *HeartbeatResponse) GetStatus() string {
 	return ""
 }
 
+func (x *HeartbeatResponse) GetJobState() JobState {
+	if x != nil {
+		return x.JobState
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
 type StartCheckpointRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Epoch         int64                  `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
 	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Namespace     string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,4,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -346,6 +482,20 @@ func (x *StartCheckpointRequest) GetPath() string {
 	return ""
 }
 
+func (x *StartCheckpointRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *StartCheckpointRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type StartCheckpointResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CheckpointId  string                 `protobuf:"bytes,1,opt,name=checkpoint_id,json=checkpointId,proto3" json:"checkpoint_id,omitempty"`
@@ -395,6 +545,8 @@ type EndCheckpointRequest struct {
 	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
 	Epoch         int64                  `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
 	CheckpointId  string                 `protobuf:"bytes,3,opt,name=checkpoint_id,json=checkpointId,proto3" json:"checkpoint_id,omitempty"`
+	Namespace     string                 `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,5,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -450,6 +602,20 @@ func (x *EndCheckpointRequest) GetCheckpointId() string {
 	return ""
 }
 
+func (x *EndCheckpointRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *EndCheckpointRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type EndCheckpointResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -486,40 +652,980 @@ func (*EndCheckpointResponse) Descriptor() ([]byte, []int) {
 	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{9}
 }
 
-var File_distributed_pb_coordinator_proto protoreflect.FileDescriptor
+type ListWorkersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_distributed_pb_coordinator_proto_rawDesc = "" +
-	"\n" +
-	" distributed/pb/coordinator.proto\x12\vdistributed\"N\n" +
-	"\x15RegisterWorkerRequest\x12\x1b\n" +
-	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x18\n" +
-	"\aaddress\x18\x02 \x01(\tR\aaddress\"B\n" +
-	"\x16RegisterWorkerResponse\x12\x12\n" +
-	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x14\n" +
-	"\x05peers\x18\x02 \x03(\tR\x05peers\"6\n" +
-	"\x17UnregisterWorkerRequest\x12\x1b\n" +
-	"\tworker_id\x18\x01 \x01(\tR\bworkerId\"\x1a\n" +
-	"\x18UnregisterWorkerResponse\"/\n" +
-	"\x10HeartbeatRequest\x12\x1b\n" +
-	"\tworker_id\x18\x01 \x01(\tR\bworkerId\"+\n" +
-	"\x11HeartbeatResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"B\n" +
-	"\x16StartCheckpointRequest\x12\x14\n" +
-	"\x05epoch\x18\x01 \x01(\x03R\x05epoch\x12\x12\n" +
-	"\x04path\x18\x02 \x01(\tR\x04path\">\n" +
-	"\x17StartCheckpointResponse\x12#\n" +
-	"\rcheckpoint_id\x18\x01 \x01(\tR\fcheckpointId\"n\n" +
-	"\x14EndCheckpointRequest\x12\x1b\n" +
-	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x14\n" +
-	"\x05epoch\x18\x02 \x01(\x03R\x05epoch\x12#\n" +
-	"\rcheckpoint_id\x18\x03 \x01(\tR\fcheckpointId\"\x17\n" +
-	"\x15EndCheckpointResponse2\xd5\x03\n" +
+func (x *ListWorkersRequest) Reset() {
+	*x = ListWorkersRequest{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorkersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorkersRequest) ProtoMessage() {}
+
+func (x *ListWorkersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorkersRequest.ProtoReflect.Descriptor instead.
+func (*ListWorkersRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListWorkersRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ListWorkersRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WorkerStatus struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Address  string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Rank     int32                  `protobuf:"varint,3,opt,name=rank,proto3" json:"rank,omitempty"`
+	// last_heartbeat_unix_nano is the time of the worker's last heartbeat.
+	LastHeartbeatUnixNano int64 `protobuf:"varint,4,opt,name=last_heartbeat_unix_nano,json=lastHeartbeatUnixNano,proto3" json:"last_heartbeat_unix_nano,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *WorkerStatus) Reset() {
+	*x = WorkerStatus{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerStatus) ProtoMessage() {}
+
+func (x *WorkerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerStatus.ProtoReflect.Descriptor instead.
+func (*WorkerStatus) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WorkerStatus) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *WorkerStatus) GetLastHeartbeatUnixNano() int64 {
+	if x != nil {
+		return x.LastHeartbeatUnixNano
+	}
+	return 0
+}
+
+type ListWorkersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workers       []*WorkerStatus        `protobuf:"bytes,1,rep,name=workers,proto3" json:"workers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWorkersResponse) Reset() {
+	*x = ListWorkersResponse{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorkersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorkersResponse) ProtoMessage() {}
+
+func (x *ListWorkersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorkersResponse.ProtoReflect.Descriptor instead.
+func (*ListWorkersResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListWorkersResponse) GetWorkers() []*WorkerStatus {
+	if x != nil {
+		return x.Workers
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WatchRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// MembershipEvent is a snapshot of a job's membership.
+type MembershipEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// epoch increases with every membership change of the job.
+	Epoch int64 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	// workers are the live members, ordered by rank.
+	Workers []*WorkerStatus `protobuf:"bytes,2,rep,name=workers,proto3" json:"workers,omitempty"`
+	// failed_worker_ids lists the workers declared dead by the change that
+	// produced this event. The coordinator renumbers the survivors to ranks
+	// 0 through len(workers)-1, so all-reduce groups must reform.
+	FailedWorkerIds []string `protobuf:"bytes,3,rep,name=failed_worker_ids,json=failedWorkerIds,proto3" json:"failed_worker_ids,omitempty"`
+	JobState        JobState `protobuf:"varint,4,opt,name=job_state,json=jobState,proto3,enum=distributed.JobState" json:"job_state,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *MembershipEvent) Reset() {
+	*x = MembershipEvent{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MembershipEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MembershipEvent) ProtoMessage() {}
+
+func (x *MembershipEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MembershipEvent.ProtoReflect.Descriptor instead.
+func (*MembershipEvent) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MembershipEvent) GetEpoch() int64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *MembershipEvent) GetWorkers() []*WorkerStatus {
+	if x != nil {
+		return x.Workers
+	}
+	return nil
+}
+
+func (x *MembershipEvent) GetFailedWorkerIds() []string {
+	if x != nil {
+		return x.FailedWorkerIds
+	}
+	return nil
+}
+
+func (x *MembershipEvent) GetJobState() JobState {
+	if x != nil {
+		return x.JobState
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+// ResourceHints describes the per-worker resources a job needs, or, on
+// RegisterWorkerRequest, the resources a worker offers.
+type ResourceHints struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Gpus        int32                  `protobuf:"varint,1,opt,name=gpus,proto3" json:"gpus,omitempty"`
+	MemoryBytes int64                  `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	// labels must all match the worker's labels exactly.
+	Labels        map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceHints) Reset() {
+	*x = ResourceHints{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceHints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceHints) ProtoMessage() {}
+
+func (x *ResourceHints) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceHints.ProtoReflect.Descriptor instead.
+func (*ResourceHints) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResourceHints) GetGpus() int32 {
+	if x != nil {
+		return x.Gpus
+	}
+	return 0
+}
+
+func (x *ResourceHints) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *ResourceHints) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type SubmitJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	WorldSize     int32                  `protobuf:"varint,3,opt,name=world_size,json=worldSize,proto3" json:"world_size,omitempty"`
+	Resources     *ResourceHints         `protobuf:"bytes,4,opt,name=resources,proto3" json:"resources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobRequest) Reset() {
+	*x = SubmitJobRequest{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobRequest) ProtoMessage() {}
+
+func (x *SubmitJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobRequest.ProtoReflect.Descriptor instead.
+func (*SubmitJobRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SubmitJobRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetWorldSize() int32 {
+	if x != nil {
+		return x.WorldSize
+	}
+	return 0
+}
+
+func (x *SubmitJobRequest) GetResources() *ResourceHints {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+type SubmitJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *JobStatus             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobResponse) Reset() {
+	*x = SubmitJobResponse{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobResponse) ProtoMessage() {}
+
+func (x *SubmitJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobResponse.ProtoReflect.Descriptor instead.
+func (*SubmitJobResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SubmitJobResponse) GetJob() *JobStatus {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetJobRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *JobStatus             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobResponse) Reset() {
+	*x = GetJobResponse{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobResponse) ProtoMessage() {}
+
+func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobResponse.ProtoReflect.Descriptor instead.
+func (*GetJobResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetJobResponse) GetJob() *JobStatus {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type ListJobsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// namespace restricts the listing to one namespace; empty lists all.
+	Namespace     string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListJobsRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*JobStatus           `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListJobsResponse) GetJobs() []*JobStatus {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type CancelJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelJobRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *CancelJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type CancelJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *JobStatus             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobResponse) Reset() {
+	*x = CancelJobResponse{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobResponse) ProtoMessage() {}
+
+func (x *CancelJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobResponse.ProtoReflect.Descriptor instead.
+func (*CancelJobResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CancelJobResponse) GetJob() *JobStatus {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type JobStatus struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Namespace string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	JobId     string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State     JobState               `protobuf:"varint,3,opt,name=state,proto3,enum=distributed.JobState" json:"state,omitempty"`
+	// world_size is zero for jobs whose workers registered without a
+	// SubmitJob.
+	WorldSize          int32          `protobuf:"varint,4,opt,name=world_size,json=worldSize,proto3" json:"world_size,omitempty"`
+	Workers            int32          `protobuf:"varint,5,opt,name=workers,proto3" json:"workers,omitempty"`
+	Resources          *ResourceHints `protobuf:"bytes,6,opt,name=resources,proto3" json:"resources,omitempty"`
+	SubmittedUnixNano  int64          `protobuf:"varint,7,opt,name=submitted_unix_nano,json=submittedUnixNano,proto3" json:"submitted_unix_nano,omitempty"`
+	DispatchedUnixNano int64          `protobuf:"varint,8,opt,name=dispatched_unix_nano,json=dispatchedUnixNano,proto3" json:"dispatched_unix_nano,omitempty"`
+	FinishedUnixNano   int64          `protobuf:"varint,9,opt,name=finished_unix_nano,json=finishedUnixNano,proto3" json:"finished_unix_nano,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *JobStatus) Reset() {
+	*x = JobStatus{}
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStatus) ProtoMessage() {}
+
+func (x *JobStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_coordinator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStatus.ProtoReflect.Descriptor instead.
+func (*JobStatus) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_coordinator_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *JobStatus) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *JobStatus) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobStatus) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *JobStatus) GetWorldSize() int32 {
+	if x != nil {
+		return x.WorldSize
+	}
+	return 0
+}
+
+func (x *JobStatus) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *JobStatus) GetResources() *ResourceHints {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *JobStatus) GetSubmittedUnixNano() int64 {
+	if x != nil {
+		return x.SubmittedUnixNano
+	}
+	return 0
+}
+
+func (x *JobStatus) GetDispatchedUnixNano() int64 {
+	if x != nil {
+		return x.DispatchedUnixNano
+	}
+	return 0
+}
+
+func (x *JobStatus) GetFinishedUnixNano() int64 {
+	if x != nil {
+		return x.FinishedUnixNano
+	}
+	return 0
+}
+
+var File_distributed_pb_coordinator_proto protoreflect.FileDescriptor
+
+const file_distributed_pb_coordinator_proto_rawDesc = "" +
+	"\n" +
+	" distributed/pb/coordinator.proto\x12\vdistributed\"\xbd\x01\n" +
+	"\x15RegisterWorkerRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x04 \x01(\tR\x05jobId\x128\n" +
+	"\tresources\x18\x05 \x01(\v2\x1a.distributed.ResourceHintsR\tresources\"v\n" +
+	"\x16RegisterWorkerResponse\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x14\n" +
+	"\x05peers\x18\x02 \x03(\tR\x05peers\x122\n" +
+	"\tjob_state\x18\x03 \x01(\x0e2\x15.distributed.JobStateR\bjobState\"k\n" +
+	"\x17UnregisterWorkerRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\tR\x05jobId\"\x1a\n" +
+	"\x18UnregisterWorkerResponse\"d\n" +
+	"\x10HeartbeatRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\tR\x05jobId\"_\n" +
+	"\x11HeartbeatResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x122\n" +
+	"\tjob_state\x18\x02 \x01(\x0e2\x15.distributed.JobStateR\bjobState\"w\n" +
+	"\x16StartCheckpointRequest\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x03R\x05epoch\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x04 \x01(\tR\x05jobId\">\n" +
+	"\x17StartCheckpointResponse\x12#\n" +
+	"\rcheckpoint_id\x18\x01 \x01(\tR\fcheckpointId\"\xa3\x01\n" +
+	"\x14EndCheckpointRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x14\n" +
+	"\x05epoch\x18\x02 \x01(\x03R\x05epoch\x12#\n" +
+	"\rcheckpoint_id\x18\x03 \x01(\tR\fcheckpointId\x12\x1c\n" +
+	"\tnamespace\x18\x04 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x05 \x01(\tR\x05jobId\"\x17\n" +
+	"\x15EndCheckpointResponse\"I\n" +
+	"\x12ListWorkersRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"\x92\x01\n" +
+	"\fWorkerStatus\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04rank\x18\x03 \x01(\x05R\x04rank\x127\n" +
+	"\x18last_heartbeat_unix_nano\x18\x04 \x01(\x03R\x15lastHeartbeatUnixNano\"J\n" +
+	"\x13ListWorkersResponse\x123\n" +
+	"\aworkers\x18\x01 \x03(\v2\x19.distributed.WorkerStatusR\aworkers\"C\n" +
+	"\fWatchRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"\xbc\x01\n" +
+	"\x0fMembershipEvent\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x03R\x05epoch\x123\n" +
+	"\aworkers\x18\x02 \x03(\v2\x19.distributed.WorkerStatusR\aworkers\x12*\n" +
+	"\x11failed_worker_ids\x18\x03 \x03(\tR\x0ffailedWorkerIds\x122\n" +
+	"\tjob_state\x18\x04 \x01(\x0e2\x15.distributed.JobStateR\bjobState\"\xc1\x01\n" +
+	"\rResourceHints\x12\x12\n" +
+	"\x04gpus\x18\x01 \x01(\x05R\x04gpus\x12!\n" +
+	"\fmemory_bytes\x18\x02 \x01(\x03R\vmemoryBytes\x12>\n" +
+	"\x06labels\x18\x03 \x03(\v2&.distributed.ResourceHints.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa0\x01\n" +
+	"\x10SubmitJobRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x1d\n" +
+	"\n" +
+	"world_size\x18\x03 \x01(\x05R\tworldSize\x128\n" +
+	"\tresources\x18\x04 \x01(\v2\x1a.distributed.ResourceHintsR\tresources\"=\n" +
+	"\x11SubmitJobResponse\x12(\n" +
+	"\x03job\x18\x01 \x01(\v2\x16.distributed.JobStatusR\x03job\"D\n" +
+	"\rGetJobRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\":\n" +
+	"\x0eGetJobResponse\x12(\n" +
+	"\x03job\x18\x01 \x01(\v2\x16.distributed.JobStatusR\x03job\"/\n" +
+	"\x0fListJobsRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\">\n" +
+	"\x10ListJobsResponse\x12*\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x16.distributed.JobStatusR\x04jobs\"G\n" +
+	"\x10CancelJobRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"=\n" +
+	"\x11CancelJobResponse\x12(\n" +
+	"\x03job\x18\x01 \x01(\v2\x16.distributed.JobStatusR\x03job\"\xf0\x02\n" +
+	"\tJobStatus\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12+\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x15.distributed.JobStateR\x05state\x12\x1d\n" +
+	"\n" +
+	"world_size\x18\x04 \x01(\x05R\tworldSize\x12\x18\n" +
+	"\aworkers\x18\x05 \x01(\x05R\aworkers\x128\n" +
+	"\tresources\x18\x06 \x01(\v2\x1a.distributed.ResourceHintsR\tresources\x12.\n" +
+	"\x13submitted_unix_nano\x18\a \x01(\x03R\x11submittedUnixNano\x120\n" +
+	"\x14dispatched_unix_nano\x18\b \x01(\x03R\x12dispatchedUnixNano\x12,\n" +
+	"\x12finished_unix_nano\x18\t \x01(\x03R\x10finishedUnixNano*\x84\x01\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10JOB_STATE_QUEUED\x10\x01\x12\x15\n" +
+	"\x11JOB_STATE_RUNNING\x10\x02\x12\x17\n" +
+	"\x13JOB_STATE_COMPLETED\x10\x03\x12\x17\n" +
+	"\x13JOB_STATE_CANCELLED\x10\x042\x9b\a\n" +
 	"\vCoordinator\x12[\n" +
 	"\x0eRegisterWorker\x12\".distributed.RegisterWorkerRequest\x1a#.distributed.RegisterWorkerResponse\"\x00\x12a\n" +
 	"\x10UnregisterWorker\x12$.distributed.UnregisterWorkerRequest\x1a%.distributed.UnregisterWorkerResponse\"\x00\x12L\n" +
 	"\tHeartbeat\x12\x1d.distributed.HeartbeatRequest\x1a\x1e.distributed.HeartbeatResponse\"\x00\x12^\n" +
 	"\x0fStartCheckpoint\x12#.distributed.StartCheckpointRequest\x1a$.distributed.StartCheckpointResponse\"\x00\x12X\n" +
-	"\rEndCheckpoint\x12!.distributed.EndCheckpointRequest\x1a\".distributed.EndCheckpointResponse\"\x00B)Z'github.com/zerfoo/zerfoo/distributed/pbb\x06proto3"
+	"\rEndCheckpoint\x12!.distributed.EndCheckpointRequest\x1a\".distributed.EndCheckpointResponse\"\x00\x12R\n" +
+	"\vListWorkers\x12\x1f.distributed.ListWorkersRequest\x1a .distributed.ListWorkersResponse\"\x00\x12L\n" +
+	"\tSubmitJob\x12\x1d.distributed.SubmitJobRequest\x1a\x1e.distributed.SubmitJobResponse\"\x00\x12C\n" +
+	"\x06GetJob\x12\x1a.distributed.GetJobRequest\x1a\x1b.distributed.GetJobResponse\"\x00\x12I\n" +
+	"\bListJobs\x12\x1c.distributed.ListJobsRequest\x1a\x1d.distributed.ListJobsResponse\"\x00\x12L\n" +
+	"\tCancelJob\x12\x1d.distributed.CancelJobRequest\x1a\x1e.distributed.CancelJobResponse\"\x00\x12D\n" +
+	"\x05Watch\x12\x19.distributed.WatchRequest\x1a\x1c.distributed.MembershipEvent\"\x000\x01B)Z'github.com/zerfoo/zerfoo/distributed/pbb\x06proto3"
 
 var (
 	file_distributed_pb_coordinator_proto_rawDescOnce sync.Once
@@ -533,35 +1639,79 @@ func file_distributed_pb_coordinator_proto_rawDescGZIP() []byte {
 	return file_distributed_pb_coordinator_proto_rawDescData
 }
 
-var file_distributed_pb_coordinator_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_distributed_pb_coordinator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_distributed_pb_coordinator_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_distributed_pb_coordinator_proto_goTypes = []any{
-	(*RegisterWorkerRequest)(nil),    // 0: distributed.RegisterWorkerRequest
-	(*RegisterWorkerResponse)(nil),   // 1: distributed.RegisterWorkerResponse
-	(*UnregisterWorkerRequest)(nil),  // 2: distributed.UnregisterWorkerRequest
-	(*UnregisterWorkerResponse)(nil), // 3: distributed.UnregisterWorkerResponse
-	(*HeartbeatRequest)(nil),         // 4: distributed.HeartbeatRequest
-	(*HeartbeatResponse)(nil),        // 5: distributed.HeartbeatResponse
-	(*StartCheckpointRequest)(nil),   // 6: distributed.StartCheckpointRequest
-	(*StartCheckpointResponse)(nil),  // 7: distributed.StartCheckpointResponse
-	(*EndCheckpointRequest)(nil),     // 8: distributed.EndCheckpointRequest
-	(*EndCheckpointResponse)(nil),    // 9: distributed.EndCheckpointResponse
+	(JobState)(0),                    // 0: distributed.JobState
+	(*RegisterWorkerRequest)(nil),    // 1: distributed.RegisterWorkerRequest
+	(*RegisterWorkerResponse)(nil),   // 2: distributed.RegisterWorkerResponse
+	(*UnregisterWorkerRequest)(nil),  // 3: distributed.UnregisterWorkerRequest
+	(*UnregisterWorkerResponse)(nil), // 4: distributed.UnregisterWorkerResponse
+	(*HeartbeatRequest)(nil),         // 5: distributed.HeartbeatRequest
+	(*HeartbeatResponse)(nil),        // 6: distributed.HeartbeatResponse
+	(*StartCheckpointRequest)(nil),   // 7: distributed.StartCheckpointRequest
+	(*StartCheckpointResponse)(nil),  // 8: distributed.StartCheckpointResponse
+	(*EndCheckpointRequest)(nil),     // 9: distributed.EndCheckpointRequest
+	(*EndCheckpointResponse)(nil),    // 10: distributed.EndCheckpointResponse
+	(*ListWorkersRequest)(nil),       // 11: distributed.ListWorkersRequest
+	(*WorkerStatus)(nil),             // 12: distributed.WorkerStatus
+	(*ListWorkersResponse)(nil),      // 13: distributed.ListWorkersResponse
+	(*WatchRequest)(nil),             // 14: distributed.WatchRequest
+	(*MembershipEvent)(nil),          // 15: distributed.MembershipEvent
+	(*ResourceHints)(nil),            // 16: distributed.ResourceHints
+	(*SubmitJobRequest)(nil),         // 17: distributed.SubmitJobRequest
+	(*SubmitJobResponse)(nil),        // 18: distributed.SubmitJobResponse
+	(*GetJobRequest)(nil),            // 19: distributed.GetJobRequest
+	(*GetJobResponse)(nil),           // 20: distributed.GetJobResponse
+	(*ListJobsRequest)(nil),          // 21: distributed.ListJobsRequest
+	(*ListJobsResponse)(nil),         // 22: distributed.ListJobsResponse
+	(*CancelJobRequest)(nil),         // 23: distributed.CancelJobRequest
+	(*CancelJobResponse)(nil),        // 24: distributed.CancelJobResponse
+	(*JobStatus)(nil),                // 25: distributed.JobStatus
+	nil,                              // 26: distributed.ResourceHints.LabelsEntry
 }
 var file_distributed_pb_coordinator_proto_depIdxs = []int32{
-	0, // 0: distributed.Coordinator.RegisterWorker:input_type -> distributed.RegisterWorkerRequest
-	2, // 1: distributed.Coordinator.UnregisterWorker:input_type -> distributed.UnregisterWorkerRequest
-	4, // 2: distributed.Coordinator.Heartbeat:input_type -> distributed.HeartbeatRequest
-	6, // 3: distributed.Coordinator.StartCheckpoint:input_type -> distributed.StartCheckpointRequest
-	8, // 4: distributed.Coordinator.EndCheckpoint:input_type -> distributed.EndCheckpointRequest
-	1, // 5: distributed.Coordinator.RegisterWorker:output_type -> distributed.RegisterWorkerResponse
-	3, // 6: distributed.Coordinator.UnregisterWorker:output_type -> distributed.UnregisterWorkerResponse
-	5, // 7: distributed.Coordinator.Heartbeat:output_type -> distributed.HeartbeatResponse
-	7, // 8: distributed.Coordinator.StartCheckpoint:output_type -> distributed.StartCheckpointResponse
-	9, // 9: distributed.Coordinator.EndCheckpoint:output_type -> distributed.EndCheckpointResponse
-	5, // [5:10] is the sub-list for method output_type
-	0, // [0:5] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	16, // 0: distributed.RegisterWorkerRequest.resources:type_name -> distributed.ResourceHints
+	0,  // 1: distributed.RegisterWorkerResponse.job_state:type_name -> distributed.JobState
+	0,  // 2: distributed.HeartbeatResponse.job_state:type_name -> distributed.JobState
+	12, // 3: distributed.ListWorkersResponse.workers:type_name -> distributed.WorkerStatus
+	12, // 4: distributed.MembershipEvent.workers:type_name -> distributed.WorkerStatus
+	0,  // 5: distributed.MembershipEvent.job_state:type_name -> distributed.JobState
+	26, // 6: distributed.ResourceHints.labels:type_name -> distributed.ResourceHints.LabelsEntry
+	16, // 7: distributed.SubmitJobRequest.resources:type_name -> distributed.ResourceHints
+	25, // 8: distributed.SubmitJobResponse.job:type_name -> distributed.JobStatus
+	25, // 9: distributed.GetJobResponse.job:type_name -> distributed.JobStatus
+	25, // 10: distributed.ListJobsResponse.jobs:type_name -> distributed.JobStatus
+	25, // 11: distributed.CancelJobResponse.job:type_name -> distributed.JobStatus
+	0,  // 12: distributed.JobStatus.state:type_name -> distributed.JobState
+	16, // 13: distributed.JobStatus.resources:type_name -> distributed.ResourceHints
+	1,  // 14: distributed.Coordinator.RegisterWorker:input_type -> distributed.RegisterWorkerRequest
+	3,  // 15: distributed.Coordinator.UnregisterWorker:input_type -> distributed.UnregisterWorkerRequest
+	5,  // 16: distributed.Coordinator.Heartbeat:input_type -> distributed.HeartbeatRequest
+	7,  // 17: distributed.Coordinator.StartCheckpoint:input_type -> distributed.StartCheckpointRequest
+	9,  // 18: distributed.Coordinator.EndCheckpoint:input_type -> distributed.EndCheckpointRequest
+	11, // 19: distributed.Coordinator.ListWorkers:input_type -> distributed.ListWorkersRequest
+	17, // 20: distributed.Coordinator.SubmitJob:input_type -> distributed.SubmitJobRequest
+	19, // 21: distributed.Coordinator.GetJob:input_type -> distributed.GetJobRequest
+	21, // 22: distributed.Coordinator.ListJobs:input_type -> distributed.ListJobsRequest
+	23, // 23: distributed.Coordinator.CancelJob:input_type -> distributed.CancelJobRequest
+	14, // 24: distributed.Coordinator.Watch:input_type -> distributed.WatchRequest
+	2,  // 25: distributed.Coordinator.RegisterWorker:output_type -> distributed.RegisterWorkerResponse
+	4,  // 26: distributed.Coordinator.UnregisterWorker:output_type -> distributed.UnregisterWorkerResponse
+	6,  // 27: distributed.Coordinator.Heartbeat:output_type -> distributed.HeartbeatResponse
+	8,  // 28: distributed.Coordinator.StartCheckpoint:output_type -> distributed.StartCheckpointResponse
+	10, // 29: distributed.Coordinator.EndCheckpoint:output_type -> distributed.EndCheckpointResponse
+	13, // 30: distributed.Coordinator.ListWorkers:output_type -> distributed.ListWorkersResponse
+	18, // 31: distributed.Coordinator.SubmitJob:output_type -> distributed.SubmitJobResponse
+	20, // 32: distributed.Coordinator.GetJob:output_type -> distributed.GetJobResponse
+	22, // 33: distributed.Coordinator.ListJobs:output_type -> distributed.ListJobsResponse
+	24, // 34: distributed.Coordinator.CancelJob:output_type -> distributed.CancelJobResponse
+	15, // 35: distributed.Coordinator.Watch:output_type -> distributed.MembershipEvent
+	25, // [25:36] is the sub-list for method output_type
+	14, // [14:25] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_distributed_pb_coordinator_proto_init() }
@@ -574,13 +1724,14 @@ func file_distributed_pb_coordinator_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_distributed_pb_coordinator_proto_rawDesc), len(file_distributed_pb_coordinator_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   10,
+			NumEnums:      1,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_distributed_pb_coordinator_proto_goTypes,
 		DependencyIndexes: file_distributed_pb_coordinator_proto_depIdxs,
+		EnumInfos:         file_distributed_pb_coordinator_proto_enumTypes,
 		MessageInfos:      file_distributed_pb_coordinator_proto_msgTypes,
 	}.Build()
 	File_distributed_pb_coordinator_proto = out.File
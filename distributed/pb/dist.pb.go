@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.10
 // 	protoc        v5.29.3
 // source: distributed/pb/dist.proto
 
@@ -23,9 +23,12 @@ const (
 )
 
 type Tensor struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Shape         []int32                `protobuf:"varint,1,rep,packed,name=shape,proto3" json:"shape,omitempty"`
-	Data          []float32              `protobuf:"fixed32,2,rep,packed,name=data,proto3" json:"data,omitempty"` // Using float32 for simplicity of transport
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Shape []int32                `protobuf:"varint,1,rep,packed,name=shape,proto3" json:"shape,omitempty"`
+	Data  []float32              `protobuf:"fixed32,2,rep,packed,name=data,proto3" json:"data,omitempty"` // Using float32 for simplicity of transport
+	// indices, when set, makes the tensor sparse: data[i] is the element at
+	// flat index indices[i] and every other element is zero.
+	Indices       []uint32 `protobuf:"varint,3,rep,packed,name=indices,proto3" json:"indices,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,6 +77,13 @@ func (x *Tensor) GetData() []float32 {
 	return nil
 }
 
+func (x *Tensor) GetIndices() []uint32 {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
 type AllReduceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -354,14 +364,206 @@ func (x *BroadcastResponse) GetTensor() *Tensor {
 	return nil
 }
 
+type DrainRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// reason is logged by the worker, e.g. "node maintenance".
+	Reason        string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	mi := &file_distributed_pb_dist_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_dist_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_dist_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DrainRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type DrainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainResponse) Reset() {
+	*x = DrainResponse{}
+	mi := &file_distributed_pb_dist_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainResponse) ProtoMessage() {}
+
+func (x *DrainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_dist_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainResponse.ProtoReflect.Descriptor instead.
+func (*DrainResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_dist_proto_rawDescGZIP(), []int{8}
+}
+
+// RingChunk is one segment of one gradient bucket at one step of a ring
+// all-reduce.
+type RingChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// round numbers the all-reduce calls of a worker; every worker in the
+	// ring counts them the same way.
+	Round  uint64 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Bucket uint32 `protobuf:"varint,2,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	// step counts the reduce-scatter steps from 0 to size-2, then the
+	// all-gather steps from size-1 to 2*size-3.
+	Step          uint32    `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`
+	Data          []float32 `protobuf:"fixed32,4,rep,packed,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RingChunk) Reset() {
+	*x = RingChunk{}
+	mi := &file_distributed_pb_dist_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RingChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RingChunk) ProtoMessage() {}
+
+func (x *RingChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_dist_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RingChunk.ProtoReflect.Descriptor instead.
+func (*RingChunk) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_dist_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RingChunk) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *RingChunk) GetBucket() uint32 {
+	if x != nil {
+		return x.Bucket
+	}
+	return 0
+}
+
+func (x *RingChunk) GetStep() uint32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *RingChunk) GetData() []float32 {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type RingReduceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RingReduceResponse) Reset() {
+	*x = RingReduceResponse{}
+	mi := &file_distributed_pb_dist_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RingReduceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RingReduceResponse) ProtoMessage() {}
+
+func (x *RingReduceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_distributed_pb_dist_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RingReduceResponse.ProtoReflect.Descriptor instead.
+func (*RingReduceResponse) Descriptor() ([]byte, []int) {
+	return file_distributed_pb_dist_proto_rawDescGZIP(), []int{10}
+}
+
 var File_distributed_pb_dist_proto protoreflect.FileDescriptor
 
 const file_distributed_pb_dist_proto_rawDesc = "" +
 	"\n" +
-	"\x19distributed/pb/dist.proto\x12\vdistributed\"2\n" +
+	"\x19distributed/pb/dist.proto\x12\vdistributed\"L\n" +
 	"\x06Tensor\x12\x14\n" +
 	"\x05shape\x18\x01 \x03(\x05R\x05shape\x12\x12\n" +
-	"\x04data\x18\x02 \x03(\x02R\x04data\"S\n" +
+	"\x04data\x18\x02 \x03(\x02R\x04data\x12\x18\n" +
+	"\aindices\x18\x03 \x03(\rR\aindices\"S\n" +
 	"\x10AllReduceRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
 	"\x06tensor\x18\x02 \x01(\v2\x13.distributed.TensorR\x06tensor\"T\n" +
@@ -375,11 +577,23 @@ const file_distributed_pb_dist_proto_rawDesc = "" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
 	"\x06tensor\x18\x02 \x01(\v2\x13.distributed.TensorR\x06tensor\"@\n" +
 	"\x11BroadcastResponse\x12+\n" +
-	"\x06tensor\x18\x01 \x01(\v2\x13.distributed.TensorR\x06tensor2\xfc\x01\n" +
+	"\x06tensor\x18\x01 \x01(\v2\x13.distributed.TensorR\x06tensor\"&\n" +
+	"\fDrainRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"\x0f\n" +
+	"\rDrainResponse\"a\n" +
+	"\tRingChunk\x12\x14\n" +
+	"\x05round\x18\x01 \x01(\x04R\x05round\x12\x16\n" +
+	"\x06bucket\x18\x02 \x01(\rR\x06bucket\x12\x12\n" +
+	"\x04step\x18\x03 \x01(\rR\x04step\x12\x12\n" +
+	"\x04data\x18\x04 \x03(\x02R\x04data\"\x14\n" +
+	"\x12RingReduceResponse2\x89\x03\n" +
 	"\x12DistributedService\x12P\n" +
 	"\tAllReduce\x12\x1d.distributed.AllReduceRequest\x1a\x1e.distributed.AllReduceResponse\"\x00(\x010\x01\x12F\n" +
 	"\aBarrier\x12\x1b.distributed.BarrierRequest\x1a\x1c.distributed.BarrierResponse\"\x00\x12L\n" +
-	"\tBroadcast\x12\x1d.distributed.BroadcastRequest\x1a\x1e.distributed.BroadcastResponse\"\x00B)Z'github.com/zerfoo/zerfoo/distributed/pbb\x06proto3"
+	"\tBroadcast\x12\x1d.distributed.BroadcastRequest\x1a\x1e.distributed.BroadcastResponse\"\x00\x12@\n" +
+	"\x05Drain\x12\x19.distributed.DrainRequest\x1a\x1a.distributed.DrainResponse\"\x00\x12I\n" +
+	"\n" +
+	"RingReduce\x12\x16.distributed.RingChunk\x1a\x1f.distributed.RingReduceResponse\"\x00(\x01B)Z'github.com/zerfoo/zerfoo/distributed/pbb\x06proto3"
 
 var (
 	file_distributed_pb_dist_proto_rawDescOnce sync.Once
@@ -393,32 +607,40 @@ func file_distributed_pb_dist_proto_rawDescGZIP() []byte {
 	return file_distributed_pb_dist_proto_rawDescData
 }
 
-var file_distributed_pb_dist_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_distributed_pb_dist_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_distributed_pb_dist_proto_goTypes = []any{
-	(*Tensor)(nil),            // 0: distributed.Tensor
-	(*AllReduceRequest)(nil),  // 1: distributed.AllReduceRequest
-	(*AllReduceResponse)(nil), // 2: distributed.AllReduceResponse
-	(*BarrierRequest)(nil),    // 3: distributed.BarrierRequest
-	(*BarrierResponse)(nil),   // 4: distributed.BarrierResponse
-	(*BroadcastRequest)(nil),  // 5: distributed.BroadcastRequest
-	(*BroadcastResponse)(nil), // 6: distributed.BroadcastResponse
+	(*Tensor)(nil),             // 0: distributed.Tensor
+	(*AllReduceRequest)(nil),   // 1: distributed.AllReduceRequest
+	(*AllReduceResponse)(nil),  // 2: distributed.AllReduceResponse
+	(*BarrierRequest)(nil),     // 3: distributed.BarrierRequest
+	(*BarrierResponse)(nil),    // 4: distributed.BarrierResponse
+	(*BroadcastRequest)(nil),   // 5: distributed.BroadcastRequest
+	(*BroadcastResponse)(nil),  // 6: distributed.BroadcastResponse
+	(*DrainRequest)(nil),       // 7: distributed.DrainRequest
+	(*DrainResponse)(nil),      // 8: distributed.DrainResponse
+	(*RingChunk)(nil),          // 9: distributed.RingChunk
+	(*RingReduceResponse)(nil), // 10: distributed.RingReduceResponse
 }
 var file_distributed_pb_dist_proto_depIdxs = []int32{
-	0, // 0: distributed.AllReduceRequest.tensor:type_name -> distributed.Tensor
-	0, // 1: distributed.AllReduceResponse.tensor:type_name -> distributed.Tensor
-	0, // 2: distributed.BroadcastRequest.tensor:type_name -> distributed.Tensor
-	0, // 3: distributed.BroadcastResponse.tensor:type_name -> distributed.Tensor
-	1, // 4: distributed.DistributedService.AllReduce:input_type -> distributed.AllReduceRequest
-	3, // 5: distributed.DistributedService.Barrier:input_type -> distributed.BarrierRequest
-	5, // 6: distributed.DistributedService.Broadcast:input_type -> distributed.BroadcastRequest
-	2, // 7: distributed.DistributedService.AllReduce:output_type -> distributed.AllReduceResponse
-	4, // 8: distributed.DistributedService.Barrier:output_type -> distributed.BarrierResponse
-	6, // 9: distributed.DistributedService.Broadcast:output_type -> distributed.BroadcastResponse
-	7, // [7:10] is the sub-list for method output_type
-	4, // [4:7] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	0,  // 0: distributed.AllReduceRequest.tensor:type_name -> distributed.Tensor
+	0,  // 1: distributed.AllReduceResponse.tensor:type_name -> distributed.Tensor
+	0,  // 2: distributed.BroadcastRequest.tensor:type_name -> distributed.Tensor
+	0,  // 3: distributed.BroadcastResponse.tensor:type_name -> distributed.Tensor
+	1,  // 4: distributed.DistributedService.AllReduce:input_type -> distributed.AllReduceRequest
+	3,  // 5: distributed.DistributedService.Barrier:input_type -> distributed.BarrierRequest
+	5,  // 6: distributed.DistributedService.Broadcast:input_type -> distributed.BroadcastRequest
+	7,  // 7: distributed.DistributedService.Drain:input_type -> distributed.DrainRequest
+	9,  // 8: distributed.DistributedService.RingReduce:input_type -> distributed.RingChunk
+	2,  // 9: distributed.DistributedService.AllReduce:output_type -> distributed.AllReduceResponse
+	4,  // 10: distributed.DistributedService.Barrier:output_type -> distributed.BarrierResponse
+	6,  // 11: distributed.DistributedService.Broadcast:output_type -> distributed.BroadcastResponse
+	8,  // 12: distributed.DistributedService.Drain:output_type -> distributed.DrainResponse
+	10, // 13: distributed.DistributedService.RingReduce:output_type -> distributed.RingReduceResponse
+	9,  // [9:14] is the sub-list for method output_type
+	4,  // [4:9] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_distributed_pb_dist_proto_init() }
@@ -432,7 +654,7 @@ func file_distributed_pb_dist_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_distributed_pb_dist_proto_rawDesc), len(file_distributed_pb_dist_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,9 +20,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DistributedService_AllReduce_FullMethodName = "/distributed.DistributedService/AllReduce"
-	DistributedService_Barrier_FullMethodName   = "/distributed.DistributedService/Barrier"
-	DistributedService_Broadcast_FullMethodName = "/distributed.DistributedService/Broadcast"
+	DistributedService_AllReduce_FullMethodName  = "/distributed.DistributedService/AllReduce"
+	DistributedService_Barrier_FullMethodName    = "/distributed.DistributedService/Barrier"
+	DistributedService_Broadcast_FullMethodName  = "/distributed.DistributedService/Broadcast"
+	DistributedService_Drain_FullMethodName      = "/distributed.DistributedService/Drain"
+	DistributedService_RingReduce_FullMethodName = "/distributed.DistributedService/RingReduce"
 )
 
 // DistributedServiceClient is the client API for DistributedService service.
@@ -35,6 +37,13 @@ type DistributedServiceClient interface {
 	Barrier(ctx context.Context, in *BarrierRequest, opts ...grpc.CallOption) (*BarrierResponse, error)
 	// Broadcast sends a tensor from the root to all other workers.
 	Broadcast(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error)
+	// Drain asks the worker to finish its current step, hand off its state,
+	// and deregister from the coordinator. It returns once the worker has
+	// left the cluster and is safe to stop.
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error)
+	// RingReduce carries ring all-reduce chunks from a worker to its
+	// successor in the ring.
+	RingReduce(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RingChunk, RingReduceResponse], error)
 }
 
 type distributedServiceClient struct {
@@ -78,6 +87,29 @@ func (c *distributedServiceClient) Broadcast(ctx context.Context, in *BroadcastR
 	return out, nil
 }
 
+func (c *distributedServiceClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DrainResponse)
+	err := c.cc.Invoke(ctx, DistributedService_Drain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributedServiceClient) RingReduce(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RingChunk, RingReduceResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DistributedService_ServiceDesc.Streams[1], DistributedService_RingReduce_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RingChunk, RingReduceResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistributedService_RingReduceClient = grpc.ClientStreamingClient[RingChunk, RingReduceResponse]
+
 // DistributedServiceServer is the server API for DistributedService service.
 // All implementations must embed UnimplementedDistributedServiceServer
 // for forward compatibility.
@@ -88,6 +120,13 @@ type DistributedServiceServer interface {
 	Barrier(context.Context, *BarrierRequest) (*BarrierResponse, error)
 	// Broadcast sends a tensor from the root to all other workers.
 	Broadcast(context.Context, *BroadcastRequest) (*BroadcastResponse, error)
+	// Drain asks the worker to finish its current step, hand off its state,
+	// and deregister from the coordinator. It returns once the worker has
+	// left the cluster and is safe to stop.
+	Drain(context.Context, *DrainRequest) (*DrainResponse, error)
+	// RingReduce carries ring all-reduce chunks from a worker to its
+	// successor in the ring.
+	RingReduce(grpc.ClientStreamingServer[RingChunk, RingReduceResponse]) error
 	mustEmbedUnimplementedDistributedServiceServer()
 }
 
@@ -107,6 +146,12 @@ func (UnimplementedDistributedServiceServer) Barrier(context.Context, *BarrierRe
 func (UnimplementedDistributedServiceServer) Broadcast(context.Context, *BroadcastRequest) (*BroadcastResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Broadcast not implemented")
 }
+func (UnimplementedDistributedServiceServer) Drain(context.Context, *DrainRequest) (*DrainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedDistributedServiceServer) RingReduce(grpc.ClientStreamingServer[RingChunk, RingReduceResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method RingReduce not implemented")
+}
 func (UnimplementedDistributedServiceServer) mustEmbedUnimplementedDistributedServiceServer() {}
 func (UnimplementedDistributedServiceServer) testEmbeddedByValue()                            {}
 
@@ -171,6 +216,31 @@ func _DistributedService_Broadcast_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistributedService_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributedServiceServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistributedService_Drain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributedServiceServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistributedService_RingReduce_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DistributedServiceServer).RingReduce(&grpc.GenericServerStream[RingChunk, RingReduceResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistributedService_RingReduceServer = grpc.ClientStreamingServer[RingChunk, RingReduceResponse]
+
 // DistributedService_ServiceDesc is the grpc.ServiceDesc for DistributedService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -186,6 +256,10 @@ var DistributedService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Broadcast",
 			Handler:    _DistributedService_Broadcast_Handler,
 		},
+		{
+			MethodName: "Drain",
+			Handler:    _DistributedService_Drain_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -194,6 +268,11 @@ var DistributedService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "RingReduce",
+			Handler:       _DistributedService_RingReduce_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "distributed/pb/dist.proto",
 }
@@ -0,0 +1,83 @@
+package distributed_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed"
+	"github.com/zerfoo/zerfoo/distributed/coordinator"
+	"github.com/zerfoo/zerfoo/serve/health"
+	"github.com/zerfoo/ztensor/log"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func readyz(t *testing.T, hs *health.Server) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	hs.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return rec.Code
+}
+
+func TestWorkerNode_ReadinessGatedOnRegistration(t *testing.T) {
+	coord := coordinator.NewCoordinator(&syncWriter{}, 30*time.Second)
+	if err := coord.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start coordinator: %v", err)
+	}
+	t.Cleanup(coord.GracefulStop)
+
+	hs := health.NewServer(log.Nop())
+	logs := &lockedBuffer{}
+	hint := 3
+	wn := distributed.NewWorkerNode(distributed.WorkerNodeConfig{
+		WorkerAddress:      reserveLoopbackAddr(t),
+		CoordinatorAddress: coord.Addr().String(),
+		WorldSize:          1,
+		HealthServer:       hs,
+		RankHint:           &hint,
+		Logger:             log.New(logs, log.LevelWarn, log.FormatText),
+	})
+
+	if got := readyz(t, hs); got != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz before registration = %d, want 503", got)
+	}
+
+	if err := wn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := readyz(t, hs); got != http.StatusOK {
+		t.Errorf("/readyz after registration = %d, want 200", got)
+	}
+	if !strings.Contains(logs.String(), "rank hint") {
+		t.Errorf("no warning for rank 0 with hint 3; logs: %q", logs.String())
+	}
+
+	if err := wn.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := readyz(t, hs); got != http.StatusServiceUnavailable {
+		t.Errorf("/readyz after Close = %d, want 503", got)
+	}
+}
@@ -0,0 +1,466 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zerfoo/zerfoo/distributed/pb"
+	"github.com/zerfoo/zerfoo/internal/tracing"
+	metrics "github.com/zerfoo/ztensor/metrics/runtime"
+	"github.com/zerfoo/ztensor/tensor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRingBucketSize is the bucket size, in elements, that
+// NewRingStrategy uses for a non-positive size: 4 MiB of float32, whose
+// ring segments stay under gRPC's default 4 MiB message limit.
+const DefaultRingBucketSize = 1 << 20
+
+// ringMaxInFlight is how many buckets one reduction reduces concurrently.
+// Adding to a reduction with this many buckets in flight blocks, bounding
+// the memory buckets pin.
+const ringMaxInFlight = 4
+
+// ringStepTimeout is how long a ring step waits for its chunk from the
+// predecessor.
+var ringStepTimeout = 30 * time.Second
+
+// RingStrategy wraps a GrpcStrategy and replaces its star all-reduce, which
+// funnels every gradient through rank 0, with a ring all-reduce. Gradients
+// are packed into fixed-size buckets, and each bucket is split into one
+// segment per worker. In n-1 reduce-scatter steps every worker sends one
+// segment to its successor and adds the segment it receives from its
+// predecessor, leaving each worker with one fully reduced segment; n-1
+// all-gather steps then circulate the reduced segments. Every worker sends
+// and receives 2(n-1)/n of the gradient bytes, independent of n, and no
+// worker is a bottleneck.
+//
+// Buckets are reduced concurrently, so their steps pipeline over the
+// stream to the successor. StartReduction exposes the buckets to the
+// training loop: gradients added as backward produces them are reduced
+// while backward continues.
+//
+// Every worker in the job must wrap its strategy with the same bucket size
+// and issue the same reductions in the same order. Barrier,
+// BroadcastTensor, and the rest of the InternalStrategy methods pass
+// through to the wrapped strategy.
+type RingStrategy[T tensor.Numeric] struct {
+	inner      *GrpcStrategy[T]
+	bucketSize int
+	collector  metrics.Collector
+	round      atomic.Uint64
+}
+
+// NewRingStrategy wraps inner so that AllReduceGradients runs a ring
+// all-reduce over buckets of bucketSize elements. A non-positive
+// bucketSize uses DefaultRingBucketSize.
+func NewRingStrategy[T tensor.Numeric](inner *GrpcStrategy[T], bucketSize int) (*RingStrategy[T], error) {
+	if inner == nil {
+		return nil, errors.New("ring strategy: inner strategy is nil")
+	}
+	if bucketSize <= 0 {
+		bucketSize = DefaultRingBucketSize
+	}
+	return &RingStrategy[T]{
+		inner:      inner,
+		bucketSize: bucketSize,
+		collector:  inner.collector,
+	}, nil
+}
+
+// Init initializes the wrapped strategy.
+func (s *RingStrategy[T]) Init(rank, size int, coordinatorAddress string) error {
+	return s.inner.Init(rank, size, coordinatorAddress)
+}
+
+// AllReduceGradients replaces every gradient with its average across all
+// workers. Gradients are packed into buckets in name order.
+func (s *RingStrategy[T]) AllReduceGradients(gradients map[string]*tensor.TensorNumeric[T]) error {
+	names := make([]string, 0, len(gradients))
+	for name, g := range gradients {
+		if g != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	r := s.StartReduction()
+	for _, name := range names {
+		r.Add(gradients[name])
+	}
+	return r.Wait()
+}
+
+// StartReduction begins an all-reduce whose gradients are added one at a
+// time with Add, typically from backward hooks as each gradient becomes
+// final. Every worker must add gradients of the same sizes in the same
+// order, and must call Wait.
+func (s *RingStrategy[T]) StartReduction() *RingReduction[T] {
+	rank, _ := s.inner.topology()
+	size := max(s.inner.Size(), 1)
+	ctx, span := s.inner.startSpan("distributed.ring_all_reduce", attribute.Int("zerfoo.bucket_size", s.bucketSize))
+	ctx, cancel := context.WithCancel(ctx)
+	r := &RingReduction[T]{
+		s:        s,
+		round:    s.round.Add(1),
+		rank:     rank,
+		size:     size,
+		ctx:      ctx,
+		cancel:   cancel,
+		span:     span,
+		inFlight: make(chan struct{}, ringMaxInFlight),
+		start:    time.Now(),
+	}
+	return r
+}
+
+// Barrier delegates to the wrapped strategy.
+func (s *RingStrategy[T]) Barrier() error { return s.inner.Barrier() }
+
+// BroadcastTensor delegates to the wrapped strategy.
+func (s *RingStrategy[T]) BroadcastTensor(t *tensor.TensorNumeric[T], rootRank int) error {
+	return s.inner.BroadcastTensor(t, rootRank)
+}
+
+// Rank returns the wrapped strategy's rank.
+func (s *RingStrategy[T]) Rank() int { return s.inner.Rank() }
+
+// Size returns the wrapped strategy's size.
+func (s *RingStrategy[T]) Size() int { return s.inner.Size() }
+
+// Shutdown shuts down the wrapped strategy.
+func (s *RingStrategy[T]) Shutdown() { s.inner.Shutdown() }
+
+// Close satisfies the shutdown.Closer interface by calling Shutdown.
+func (s *RingStrategy[T]) Close(_ context.Context) error {
+	s.Shutdown()
+	return nil
+}
+
+// RingReduction is one ring all-reduce in progress. Add packs gradients
+// into buckets and starts reducing each bucket as soon as it fills; Wait
+// reduces the last, partial bucket and returns once every added gradient
+// holds the average. A RingReduction is not safe for concurrent Add calls.
+type RingReduction[T tensor.Numeric] struct {
+	s          *RingStrategy[T]
+	round      uint64
+	rank, size int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	span   trace.Span
+	start  time.Time
+
+	// Bucket being filled by Add.
+	bucket uint32
+	buf    []float32
+	spans  []ringSpan[T]
+
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+
+	sendMu sync.Mutex
+	stream pb.DistributedService_RingReduceClient
+
+	errOnce sync.Once
+	err     error
+}
+
+// ringSpan maps elements [at, at+n) of a bucket to elements [off, off+n)
+// of a gradient.
+type ringSpan[T tensor.Numeric] struct {
+	data    []T
+	off, at int
+	n       int
+}
+
+// Add appends grad to the reduction. Its elements are copied into the
+// current bucket; a full bucket starts reducing immediately. grad is
+// overwritten with the average by the time Wait returns, and must not be
+// modified before then.
+func (r *RingReduction[T]) Add(grad *tensor.TensorNumeric[T]) {
+	if grad == nil {
+		return
+	}
+	data := grad.Data()
+	for off := 0; off < len(data); {
+		if r.buf == nil {
+			r.buf = make([]float32, 0, r.s.bucketSize)
+		}
+		n := min(len(data)-off, r.s.bucketSize-len(r.buf))
+		at := len(r.buf)
+		for _, v := range data[off : off+n] {
+			r.buf = append(r.buf, float32(v))
+		}
+		r.spans = append(r.spans, ringSpan[T]{data: data, off: off, at: at, n: n})
+		off += n
+		if len(r.buf) == r.s.bucketSize {
+			r.launch()
+		}
+	}
+}
+
+// Wait reduces the last bucket, waits for every bucket, and returns the
+// first error. The gradients' contents are unspecified after an error.
+func (r *RingReduction[T]) Wait() error {
+	if len(r.buf) > 0 {
+		r.launch()
+	}
+	r.wg.Wait()
+
+	r.sendMu.Lock()
+	if r.stream != nil {
+		if _, err := r.stream.CloseAndRecv(); err != nil && !errors.Is(err, io.EOF) {
+			r.fail(fmt.Errorf("ring all-reduce: close stream: %w", err))
+		}
+	}
+	r.sendMu.Unlock()
+	r.cancel()
+	if svc := r.s.inner.service; svc != nil {
+		svc.ring.finish(r.round)
+	}
+
+	r.span.SetAttributes(attribute.Int("zerfoo.buckets", int(r.bucket)))
+	tracing.End(r.span, r.err)
+	r.s.collector.Counter("ring_allreduce_count").Inc()
+	r.s.collector.Histogram("ring_allreduce_duration_seconds", svcOpDurationBuckets).
+		Observe(time.Since(r.start).Seconds())
+	return r.err
+}
+
+// launch starts reducing the current bucket, blocking while ringMaxInFlight
+// buckets are in flight.
+func (r *RingReduction[T]) launch() {
+	b, buf, spans := r.bucket, r.buf, r.spans
+	r.bucket++
+	r.buf, r.spans = nil, nil
+
+	select {
+	case r.inFlight <- struct{}{}:
+	case <-r.ctx.Done():
+		return // an earlier bucket failed; Wait reports it
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() { <-r.inFlight }()
+		if err := r.reduceBucket(b, buf); err != nil {
+			r.fail(fmt.Errorf("ring all-reduce: bucket %d: %w", b, err))
+			return
+		}
+		scale := 1 / float32(r.size)
+		for _, sp := range spans {
+			for i := range sp.n {
+				sp.data[sp.off+i] = T(buf[sp.at+i] * scale)
+			}
+		}
+		r.s.collector.Counter("ring_allreduce_buckets_total").Inc()
+	}()
+}
+
+// reduceBucket sums buf across the ring in place.
+func (r *RingReduction[T]) reduceBucket(b uint32, buf []float32) error {
+	n := r.size
+	if n == 1 {
+		return nil
+	}
+	seg := func(i int) []float32 {
+		i = ((i % n) + n) % n
+		return buf[i*len(buf)/n : (i+1)*len(buf)/n]
+	}
+
+	// Reduce-scatter: after step s, segment rank-s-1 holds the sum over
+	// s+2 workers; after n-1 steps, segment rank+1 holds the full sum.
+	for step := range n - 1 {
+		if err := r.send(b, step, seg(r.rank-step)); err != nil {
+			return err
+		}
+		got, err := r.recv(b, step, len(seg(r.rank-step-1)))
+		if err != nil {
+			return err
+		}
+		dst := seg(r.rank - step - 1)
+		for i, v := range got {
+			dst[i] += v
+		}
+	}
+
+	// All-gather: pass the reduced segments around the ring.
+	for step := range n - 1 {
+		if err := r.send(b, n-1+step, seg(r.rank+1-step)); err != nil {
+			return err
+		}
+		got, err := r.recv(b, n-1+step, len(seg(r.rank-step)))
+		if err != nil {
+			return err
+		}
+		copy(seg(r.rank-step), got)
+	}
+	return nil
+}
+
+// send sends one chunk to the successor, connecting on first use.
+func (r *RingReduction[T]) send(b uint32, step int, data []float32) error {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	if r.stream == nil {
+		next, err := r.s.inner.peerClient((r.rank + 1) % r.size)
+		if err != nil {
+			return fmt.Errorf("connect to successor: %w", err)
+		}
+		stream, err := next.RingReduce(r.ctx)
+		if err != nil {
+			return fmt.Errorf("open stream to successor: %w", err)
+		}
+		r.stream = stream
+	}
+	err := r.stream.Send(&pb.RingChunk{
+		Round:  r.round,
+		Bucket: b,
+		Step:   uint32(step), // #nosec G115 - steps are bounded by the world size
+		Data:   data,
+	})
+	if err != nil {
+		return fmt.Errorf("send step %d: %w", step, err)
+	}
+	return nil
+}
+
+// recv waits for the predecessor's chunk for one step and checks its size.
+func (r *RingReduction[T]) recv(b uint32, step, want int) ([]float32, error) {
+	svc := r.s.inner.service
+	if svc == nil {
+		return nil, errors.New("strategy not initialized")
+	}
+	ctx, cancel := context.WithTimeout(r.ctx, ringStepTimeout)
+	defer cancel()
+	got, err := svc.ring.take(ctx, ringKey{round: r.round, bucket: b, step: uint32(step)}) // #nosec G115 - steps are bounded by the world size
+	if err != nil {
+		return nil, fmt.Errorf("wait for step %d: %w", step, err)
+	}
+	if len(got) != want {
+		return nil, fmt.Errorf("step %d: got %d elements from predecessor, want %d", step, len(got), want)
+	}
+	return got, nil
+}
+
+// fail records the reduction's first error and cancels its other buckets.
+func (r *RingReduction[T]) fail(err error) {
+	r.errOnce.Do(func() {
+		r.err = err
+		r.cancel()
+	})
+}
+
+// --- ringMailbox ---
+
+// ringKey identifies one chunk of a ring all-reduce.
+type ringKey struct {
+	round  uint64
+	bucket uint32
+	step   uint32
+}
+
+// ringMailbox holds the chunks a worker's predecessor has sent until the
+// local reduction takes them. Chunks may arrive before the reduction asks
+// for them, and in any order.
+type ringMailbox struct {
+	mu       sync.Mutex
+	slots    map[ringKey]chan []float32
+	finished uint64 // chunks of rounds up to this one are discarded
+}
+
+func newRingMailbox() *ringMailbox {
+	return &ringMailbox{slots: make(map[ringKey]chan []float32)}
+}
+
+// slot returns the slot for k, creating it. m.mu must be held.
+func (m *ringMailbox) slot(k ringKey) chan []float32 {
+	ch, ok := m.slots[k]
+	if !ok {
+		ch = make(chan []float32, 1)
+		m.slots[k] = ch
+	}
+	return ch
+}
+
+// put delivers a chunk. It fails if a chunk with the same key is already
+// waiting.
+func (m *ringMailbox) put(k ringKey, data []float32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if k.round <= m.finished {
+		return nil // the reduction already gave up on this round
+	}
+	select {
+	case m.slot(k) <- data:
+		return nil
+	default:
+		return fmt.Errorf("duplicate ring chunk for round %d bucket %d step %d", k.round, k.bucket, k.step)
+	}
+}
+
+// take waits for the chunk with key k and removes it.
+func (m *ringMailbox) take(ctx context.Context, k ringKey) ([]float32, error) {
+	m.mu.Lock()
+	ch := m.slot(k)
+	m.mu.Unlock()
+
+	select {
+	case data := <-ch:
+		m.mu.Lock()
+		delete(m.slots, k)
+		m.mu.Unlock()
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// finish discards every chunk of round and earlier rounds, including chunks
+// of a failed reduction that arrive later.
+func (m *ringMailbox) finish(round uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.finished = max(m.finished, round)
+	for k := range m.slots {
+		if k.round <= m.finished {
+			delete(m.slots, k)
+		}
+	}
+}
+
+// RingReduce receives the chunks of ring all-reduces from this worker's
+// predecessor and hands them to the local reduction.
+func (ws *workerService) RingReduce(stream pb.DistributedService_RingReduceServer) error {
+	defer ws.recordOp("ringreduce_server", time.Now())
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.RingReduceResponse{})
+		}
+		if err != nil {
+			return err
+		}
+		k := ringKey{round: chunk.Round, bucket: chunk.Bucket, step: chunk.Step}
+		if err := ws.ring.put(k, chunk.Data); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+}
+
+// Static interface assertion.
+var _ InternalStrategy[float32] = (*RingStrategy[float32])(nil)
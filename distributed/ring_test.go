@@ -0,0 +1,86 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestNewRingStrategy(t *testing.T) {
+	if _, err := NewRingStrategy[float32](nil, 0); err == nil {
+		t.Error("NewRingStrategy(nil) succeeded")
+	}
+	s, err := NewRingStrategy(NewGrpcStrategy[float32](GrpcStrategyConfig{}), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.bucketSize != DefaultRingBucketSize {
+		t.Errorf("bucketSize = %d, want %d", s.bucketSize, DefaultRingBucketSize)
+	}
+}
+
+func TestRingStrategy_SingleWorkerNoop(t *testing.T) {
+	s, err := NewRingStrategy(NewGrpcStrategy[float32](GrpcStrategyConfig{}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := tensor.New([]int{5}, []float32{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AllReduceGradients(map[string]*tensor.TensorNumeric[float32]{"g": g, "nil": nil}); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range g.Data() {
+		if v != float32(i+1) {
+			t.Fatalf("gradient = %v, want it unchanged", g.Data())
+		}
+	}
+}
+
+func TestRingMailbox(t *testing.T) {
+	m := newRingMailbox()
+	k := ringKey{round: 1, bucket: 2, step: 3}
+
+	// A chunk may arrive before or after it is asked for.
+	if err := m.put(k, []float32{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.put(k, []float32{2}); err == nil {
+		t.Error("duplicate put succeeded")
+	}
+	got, err := m.take(context.Background(), k)
+	if err != nil || len(got) != 1 || got[0] != 1 {
+		t.Fatalf("take = %v, %v", got, err)
+	}
+
+	k2 := ringKey{round: 1, bucket: 2, step: 4}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = m.put(k2, []float32{4})
+	}()
+	if got, err := m.take(context.Background(), k2); err != nil || got[0] != 4 {
+		t.Fatalf("take before put = %v, %v", got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.take(ctx, ringKey{round: 1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("take of a missing chunk = %v, want DeadlineExceeded", err)
+	}
+
+	// Finishing a round drops its chunks, and later ones are discarded.
+	if err := m.put(ringKey{round: 1, step: 9}, nil); err != nil {
+		t.Fatal(err)
+	}
+	m.finish(1)
+	if err := m.put(ringKey{round: 1, step: 10}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(m.slots); n != 0 {
+		t.Errorf("%d slots left after finish", n)
+	}
+}
@@ -0,0 +1,30 @@
+package distributed
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// TracingDialOption returns a dial option that traces outgoing RPCs and
+// propagates the caller's trace context to the server, using the global
+// OpenTelemetry TracerProvider and propagator.
+func TracingDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(tracedRPC)))
+}
+
+// TracingServerOption returns a server option that traces incoming RPCs as
+// children of the trace context their callers propagate.
+func TracingServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(tracedRPC)))
+}
+
+// tracedRPC reports whether an RPC is traced. Heartbeats and membership
+// watches run continuously, outside any operation, so tracing them would
+// bury the collectives in root spans.
+func tracedRPC(info *stats.RPCTagInfo) bool {
+	return !strings.HasSuffix(info.FullMethodName, "/Heartbeat") &&
+		!strings.HasSuffix(info.FullMethodName, "/Watch")
+}
@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/zerfoo/zerfoo/distributed/pb"
 	"github.com/zerfoo/zerfoo/serve/health"
 	"github.com/zerfoo/ztensor/log"
 	metrics "github.com/zerfoo/ztensor/metrics/runtime"
 	"github.com/zerfoo/ztensor/tensor"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // WorkerNodeConfig holds configuration for creating a WorkerNode.
@@ -21,24 +26,76 @@ type WorkerNodeConfig struct {
 	WorldSize          int
 	Logger             log.Logger
 	Collector          metrics.Collector
-	HealthServer       *health.Server
+	// HealthServer, when set, gets a "distributed-worker" readiness check
+	// from NewWorkerNode. The check fails until the worker has registered
+	// with the coordinator and been admitted to its job, so a StatefulSet
+	// with OrderedReady pods starts each worker only after the previous
+	// one has joined.
+	HealthServer *health.Server
+	// RankHint, when set, is the rank this worker expects, such as its
+	// StatefulSet ordinal. The coordinator assigns ranks in registration
+	// order, so Start logs a warning if the assigned rank differs.
+	RankHint *int
 	// TLS, when set, secures the worker's own gRPC server (mutual TLS via
 	// TLSConfig.ServerCredentials) and the worker's outbound connection to
 	// the coordinator (via TLSConfig.ClientCredentials). When nil, Start
 	// refuses to bind any non-loopback WorkerAddress -- see isLoopback.
 	TLS *TLSConfig
+	// Namespace and JobID select the job this worker joins when several
+	// training runs share one coordinator. Empty values join the default
+	// job.
+	Namespace string
+	JobID     string
+	// Resources is what this worker offers to jobs submitted with resource
+	// hints.
+	Resources *pb.ResourceHints
+	// OnDrain, when set, is called by Drain after the current training step
+	// has finished and before the worker deregisters, to hand off state
+	// (e.g. write a checkpoint). An error aborts the drain and the worker
+	// keeps running.
+	OnDrain func(ctx context.Context) error
+	// GradientCompression, when positive, is the fraction of each
+	// gradient's elements sent per all-reduce: Strategy returns a
+	// CompressedStrategy that applies top-k sparsification with error
+	// feedback. Every worker in the job must use the same setting.
+	GradientCompression float64
+	// RingBucketSize, when positive, makes Strategy all-reduce gradients
+	// with a RingStrategy over buckets of this many elements instead of
+	// through rank 0. With GradientCompression also set, the compressed
+	// gradients are reduced over the ring, which sends them dense. Every
+	// worker in the job must use the same setting.
+	RingBucketSize int
+	// HeartbeatInterval and Rebalance are passed to the worker's
+	// GrpcStrategy: with both set, the worker survives the failure of
+	// other workers in its job by adopting the rank the coordinator
+	// reassigns it.
+	HeartbeatInterval time.Duration
+	Rebalance         bool
 }
 
 // WorkerNode encapsulates a distributed training worker. It manages
 // the gRPC strategy, server, and network connections, and provides
 // orderly startup and shutdown semantics compatible with shutdown.Coordinator.
 type WorkerNode struct {
-	config   WorkerNodeConfig
-	strategy *GrpcStrategy[float32]
-	logger   log.Logger
+	config     WorkerNodeConfig
+	strategy   *GrpcStrategy[float32]
+	ring       *RingStrategy[float32]       // wraps strategy when RingBucketSize is set
+	compressed *CompressedStrategy[float32] // wraps ring or strategy when compression is on
+	logger     log.Logger
 
 	mu      sync.Mutex
 	started bool
+	// registered is set once Start has joined the job. The readiness check
+	// reads it without mu, which Start holds while it registers.
+	registered atomic.Bool
+
+	// grpcHealth serves the standard gRPC health protocol on the worker's
+	// server: SERVING once started, NOT_SERVING while draining.
+	grpcHealth *grpchealth.Server
+
+	steps   *stepGate
+	drainMu sync.Mutex // serializes Drain
+	drained chan struct{}
 }
 
 // NewWorkerNode creates a new WorkerNode with the given configuration.
@@ -49,10 +106,16 @@ func NewWorkerNode(cfg WorkerNodeConfig) *WorkerNode {
 	if cfg.Collector == nil {
 		cfg.Collector = metrics.Nop()
 	}
-	return &WorkerNode{
-		config: cfg,
-		logger: cfg.Logger,
+	wn := &WorkerNode{
+		config:  cfg,
+		logger:  cfg.Logger,
+		steps:   newStepGate(),
+		drained: make(chan struct{}),
+	}
+	if cfg.HealthServer != nil {
+		cfg.HealthServer.AddReadinessCheck("distributed-worker", wn.healthCheck())
 	}
+	return wn
 }
 
 // Start initializes the distributed worker: creates a gRPC server and
@@ -78,7 +141,18 @@ func (wn *WorkerNode) Start(_ context.Context) error {
 		return errors.New("worker: refusing non-loopback bind without TLS; set TLS or bind 127.0.0.1")
 	}
 
+	if ratio := wn.config.GradientCompression; ratio != 0 {
+		// Refuse a bad ratio before joining the job.
+		if err := checkCompressionRatio(ratio); err != nil {
+			return err
+		}
+	}
+
+	opts = append(opts, TracingServerOption())
 	srv := grpc.NewServer(opts...)
+	hs := grpchealth.NewServer()
+	hs.SetServingStatus(pb.DistributedService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
 	sm := NewServerManager(srv, nil)
 	nm := NewNetworkManager(nil, nil)
 
@@ -89,6 +163,12 @@ func (wn *WorkerNode) Start(_ context.Context) error {
 		Logger:         wn.config.Logger,
 		Collector:      wn.config.Collector,
 		TLS:            wn.config.TLS,
+		Namespace:      wn.config.Namespace,
+		JobID:          wn.config.JobID,
+		Resources:      wn.config.Resources,
+
+		HeartbeatInterval: wn.config.HeartbeatInterval,
+		Rebalance:         wn.config.Rebalance,
 	})
 
 	if err := strategy.Init(0, wn.config.WorldSize, wn.config.CoordinatorAddress); err != nil {
@@ -96,11 +176,25 @@ func (wn *WorkerNode) Start(_ context.Context) error {
 	}
 
 	wn.strategy = strategy
+	var inner InternalStrategy[float32] = strategy
+	if n := wn.config.RingBucketSize; n > 0 {
+		wn.ring, _ = NewRingStrategy(strategy, n)
+		inner = wn.ring
+	}
+	if ratio := wn.config.GradientCompression; ratio != 0 {
+		wn.compressed, _ = NewCompressedStrategy(inner, ratio)
+	}
+	wn.grpcHealth = hs
 	wn.started = true
+	strategy.service.SetDrainHandler(wn.Drain)
+	hs.SetServingStatus(pb.DistributedService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	wn.registered.Store(true)
 
-	// Register health check if a health server is provided.
-	if wn.config.HealthServer != nil {
-		wn.config.HealthServer.AddReadinessCheck("distributed-worker", wn.healthCheck())
+	if hint := wn.config.RankHint; hint != nil && *hint != strategy.Rank() {
+		wn.logger.Warn("assigned rank differs from rank hint; workers registered out of order",
+			"rank", fmt.Sprintf("%d", strategy.Rank()),
+			"hint", fmt.Sprintf("%d", *hint),
+		)
 	}
 
 	wn.logger.Info("worker node started",
@@ -115,22 +209,36 @@ func (wn *WorkerNode) Start(_ context.Context) error {
 // healthCheck returns a health.CheckFunc that reports the worker's status.
 func (wn *WorkerNode) healthCheck() health.CheckFunc {
 	return func() error {
-		wn.mu.Lock()
-		defer wn.mu.Unlock()
-		if !wn.started {
-			return errors.New("worker node not started")
+		if !wn.registered.Load() {
+			return errors.New("worker node not registered with coordinator")
+		}
+		select {
+		case <-wn.drained:
+			return errors.New("worker node drained")
+		default:
+		}
+		if wn.steps.isDraining() {
+			return ErrDraining
 		}
 		return nil
 	}
 }
 
 // Strategy returns the underlying InternalStrategy, or nil if not started.
+// With GradientCompression set, it is the CompressedStrategy wrapping the
+// worker's gRPC strategy, or its RingStrategy when RingBucketSize is set.
 func (wn *WorkerNode) Strategy() InternalStrategy[float32] {
 	wn.mu.Lock()
 	defer wn.mu.Unlock()
 	if wn.strategy == nil {
 		return nil
 	}
+	if wn.compressed != nil {
+		return wn.compressed
+	}
+	if wn.ring != nil {
+		return wn.ring
+	}
 	return wn.strategy
 }
 
@@ -145,12 +253,80 @@ func (wn *WorkerNode) Close(_ context.Context) error {
 	}
 
 	wn.logger.Info("shutting down worker node")
+	wn.registered.Store(false)
+	wn.grpcHealth.Shutdown()
 	wn.strategy.Shutdown()
 	wn.strategy = nil
+	wn.ring = nil
+	wn.compressed = nil
 	wn.started = false
 	return nil
 }
 
+// BeginStep marks the start of a training step. A drain waits for every
+// step begun this way to call the returned done func before it hands off
+// state, and BeginStep returns ErrDraining once a drain has started.
+func (wn *WorkerNode) BeginStep() (done func(), err error) {
+	return wn.steps.enter()
+}
+
+// Drain takes the worker out of the cluster for maintenance: it stops
+// admitting training steps and reports NOT_SERVING, waits for the current
+// step to finish, runs OnDrain to hand off state, and deregisters from the
+// coordinator. The worker's server keeps running so in-flight peer RPCs
+// complete; call Close to stop it. If ctx ends or OnDrain fails before the
+// worker deregisters, the drain is abandoned and the worker resumes.
+// Draining a drained worker is a no-op.
+func (wn *WorkerNode) Drain(ctx context.Context, reason string) error {
+	wn.drainMu.Lock()
+	defer wn.drainMu.Unlock()
+
+	select {
+	case <-wn.drained:
+		return nil
+	default:
+	}
+
+	wn.mu.Lock()
+	strategy, hs := wn.strategy, wn.grpcHealth
+	wn.mu.Unlock()
+	if strategy == nil {
+		return errors.New("worker node not started")
+	}
+
+	wn.logger.Info("draining worker node", "reason", reason)
+	svc := pb.DistributedService_ServiceDesc.ServiceName
+	hs.SetServingStatus(svc, healthpb.HealthCheckResponse_NOT_SERVING)
+	resume := func() {
+		wn.steps.reopen()
+		hs.SetServingStatus(svc, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	if err := wn.steps.close(ctx); err != nil {
+		resume()
+		return fmt.Errorf("waiting for current step: %w", err)
+	}
+	if wn.config.OnDrain != nil {
+		if err := wn.config.OnDrain(ctx); err != nil {
+			resume()
+			return fmt.Errorf("state hand-off: %w", err)
+		}
+	}
+	if err := strategy.unregister(); err != nil {
+		return fmt.Errorf("deregister: %w", err)
+	}
+
+	wn.logger.Info("worker node drained", "rank", fmt.Sprintf("%d", strategy.Rank()))
+	close(wn.drained)
+	return nil
+}
+
+// Drained returns a channel that is closed once Drain has deregistered the
+// worker, so its owner can shut it down.
+func (wn *WorkerNode) Drained() <-chan struct{} {
+	return wn.drained
+}
+
 // Rank returns the worker's rank, or -1 if not started.
 func (wn *WorkerNode) Rank() int {
 	wn.mu.Lock()
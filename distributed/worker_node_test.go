@@ -70,3 +70,17 @@ func TestWorkerNode_HealthCheck_NotStarted(t *testing.T) {
 		t.Error("expected error from health check before start")
 	}
 }
+
+func TestWorkerNode_Start_InvalidCompressionRatio(t *testing.T) {
+	wn := NewWorkerNode(WorkerNodeConfig{
+		WorkerAddress:       "127.0.0.1:0",
+		CoordinatorAddress:  "127.0.0.1:1",
+		GradientCompression: 1.5,
+	})
+	if err := wn.Start(context.Background()); err == nil {
+		t.Fatal("Start should reject a compression ratio above 1")
+	}
+	if wn.Strategy() != nil {
+		t.Error("worker should not have started")
+	}
+}
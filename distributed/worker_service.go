@@ -16,16 +16,20 @@ import (
 )
 
 // workerService implements pb.DistributedServiceServer.
-// It handles AllReduce, Barrier, and Broadcast RPCs from peers.
+// It handles AllReduce, Barrier, and Broadcast RPCs from peers, and Drain
+// requests from operators.
 type workerService struct {
 	pb.UnimplementedDistributedServiceServer
 
-	rank      int32
-	worldSize int32
 	logger    log.Logger
 	collector metrics.Collector
 
+	// rank and worldSize change when the coordinator rebalances the job.
+	rank      int32
+	worldSize int32
+
 	// session holds the active reduce session for the current training step.
+	// sessionMu also guards rank and worldSize.
 	session   *reduceSession
 	sessionMu sync.Mutex
 
@@ -35,6 +39,13 @@ type workerService struct {
 	// broadcasts stores tensors for Broadcast RPCs.
 	broadcasts   map[string]*broadcastEntry
 	broadcastsMu sync.Mutex
+
+	// ring holds chunks received from the ring predecessor.
+	ring *ringMailbox
+
+	// drain handles Drain RPCs; nil until SetDrainHandler.
+	drain   func(ctx context.Context, reason string) error
+	drainMu sync.Mutex
 }
 
 // broadcastEntry stores a broadcast tensor and a channel to signal availability.
@@ -55,6 +66,7 @@ func NewWorkerService(rank, worldSize int32, logger log.Logger) *workerService {
 		collector:  metrics.Nop(),
 		barrier:    newBarrierState(worldSize),
 		broadcasts: make(map[string]*broadcastEntry),
+		ring:       newRingMailbox(),
 	}
 }
 
@@ -66,6 +78,32 @@ func (ws *workerService) SetCollector(c metrics.Collector) {
 	ws.collector = c
 }
 
+// SetDrainHandler sets the function that performs a Drain RPC. Until it is
+// set, Drain fails with codes.Unimplemented.
+func (ws *workerService) SetDrainHandler(fn func(ctx context.Context, reason string) error) {
+	ws.drainMu.Lock()
+	defer ws.drainMu.Unlock()
+	ws.drain = fn
+}
+
+// resize updates the worker's rank and world size after the coordinator
+// rebalanced its job. A barrier that enough workers have already reached
+// for the new world size is released.
+func (ws *workerService) resize(rank, worldSize int32) {
+	ws.sessionMu.Lock()
+	ws.rank = rank
+	ws.worldSize = worldSize
+	ws.sessionMu.Unlock()
+	ws.barrier.resize(worldSize)
+}
+
+// size returns the current world size.
+func (ws *workerService) size() int32 {
+	ws.sessionMu.Lock()
+	defer ws.sessionMu.Unlock()
+	return ws.worldSize
+}
+
 // NewSession creates a new reduce session for the current training step.
 // Must be called before AllReduce streams begin for each step.
 func (ws *workerService) NewSession() {
@@ -77,10 +115,10 @@ func (ws *workerService) NewSession() {
 // SetLocalTensors submits the root worker's own tensors to the active reduce session.
 func (ws *workerService) SetLocalTensors(tensors map[string]*pb.Tensor) {
 	ws.sessionMu.Lock()
-	s := ws.session
+	s, rank := ws.session, ws.rank
 	ws.sessionMu.Unlock()
 	if s != nil {
-		s.Submit(ws.rank, tensors)
+		s.Submit(rank, tensors)
 	}
 }
 
@@ -165,7 +203,7 @@ func (rs *reduceSession) Submit(_ int32, tensors map[string]*pb.Tensor) {
 		if t == nil {
 			continue
 		}
-		rs.tensors[name] = append(rs.tensors[name], t.Data)
+		rs.tensors[name] = append(rs.tensors[name], denseData(t))
 		if _, ok := rs.shapes[name]; !ok {
 			rs.shapes[name] = t.Shape
 		}
@@ -226,10 +264,7 @@ func (rs *reduceSession) computeResult() {
 		for i := range avg {
 			avg[i] /= n
 		}
-		rs.result[name] = &pb.Tensor{
-			Shape: rs.shapes[name],
-			Data:  avg,
-		}
+		rs.result[name] = packTensor(rs.shapes[name], avg)
 	}
 }
 
@@ -287,6 +322,20 @@ func (bs *barrierState) arrive(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// resize changes the number of workers the barrier waits for, releasing
+// the waiting workers if they already reach the new size.
+func (bs *barrierState) resize(worldSize int32) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.worldSize = worldSize
+	if bs.arrived > 0 && bs.arrived >= worldSize {
+		bs.arrived = 0
+		bs.epoch++
+		bs.cond.Broadcast()
+	}
+}
+
 // --- RPC Handlers ---
 
 // Default histogram buckets for distributed service operations.
@@ -352,8 +401,8 @@ func (ws *workerService) AllReduce(stream pb.DistributedService_AllReduceServer)
 func (ws *workerService) Barrier(ctx context.Context, req *pb.BarrierRequest) (*pb.BarrierResponse, error) {
 	defer ws.recordOp("barrier_server", time.Now())
 
-	if req.Rank < 0 || req.Rank >= ws.worldSize {
-		return nil, status.Errorf(codes.InvalidArgument, "rank %d out of range [0, %d)", req.Rank, ws.worldSize)
+	if worldSize := ws.size(); req.Rank < 0 || req.Rank >= worldSize {
+		return nil, status.Errorf(codes.InvalidArgument, "rank %d out of range [0, %d)", req.Rank, worldSize)
 	}
 
 	if err := ws.barrier.arrive(ctx); err != nil {
@@ -388,6 +437,26 @@ func (ws *workerService) Broadcast(ctx context.Context, req *pb.BroadcastRequest
 	}
 }
 
+// Drain handles a request to drain this worker. It blocks until the drain
+// handler has finished the current step, handed off state, and
+// deregistered the worker.
+func (ws *workerService) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainResponse, error) {
+	ws.drainMu.Lock()
+	drain := ws.drain
+	ws.drainMu.Unlock()
+	if drain == nil {
+		return nil, status.Error(codes.Unimplemented, "worker does not support draining")
+	}
+
+	if err := drain(ctx, req.Reason); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "drain: %v", err)
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "drain: %v", err)
+	}
+	return &pb.DrainResponse{}, nil
+}
+
 // validateTensor checks that a pb.Tensor is valid.
 func validateTensor(t *pb.Tensor, fieldName string) error {
 	if t == nil {
@@ -403,6 +472,17 @@ func validateTensor(t *pb.Tensor, fieldName string) error {
 		}
 		product *= int(dim)
 	}
+	if len(t.Indices) > 0 {
+		if len(t.Indices) != len(t.Data) {
+			return fmt.Errorf("%s: sparse tensor has %d indices but %d values", fieldName, len(t.Indices), len(t.Data))
+		}
+		for _, idx := range t.Indices {
+			if int(idx) >= product {
+				return fmt.Errorf("%s: sparse index %d out of range for %d elements", fieldName, idx, product)
+			}
+		}
+		return nil
+	}
 	if product != len(t.Data) {
 		return fmt.Errorf("%s: tensor shape product %d does not match data length %d", fieldName, product, len(t.Data))
 	}
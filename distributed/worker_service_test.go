@@ -269,6 +269,44 @@ func TestBarrierState_SequentialBarriers(t *testing.T) {
 	bs.mu.Unlock()
 }
 
+func TestBarrierState_ShrinkReleasesWaiters(t *testing.T) {
+	bs := newBarrierState(3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Two of three workers arrive; the third has died.
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() { errs <- bs.arrive(ctx) }()
+	}
+	for {
+		bs.mu.Lock()
+		arrived := bs.arrived
+		bs.mu.Unlock()
+		if arrived == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	bs.resize(2)
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Errorf("arrive after resize: %v", err)
+		}
+	}
+
+	// The barrier keeps working at the new size.
+	go func() { errs <- bs.arrive(ctx) }()
+	if err := bs.arrive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
 // --- NewWorkerService tests ---
 
 func TestNewWorkerService(t *testing.T) {
@@ -18,22 +18,29 @@ type BatchResult struct {
 	Err  error
 }
 
-// BatchGenerate runs multiple generation requests concurrently. Each request
-// gets its own KV cache and sampling state. This provides throughput gains
-// when the model graph is configured with WithParallel(true) or when
-// generation is I/O bound.
+// BatchGenerate runs multiple generation requests and returns one result per
+// request, in order.
 //
-// For true batched tensor operations (batch dimension > 1 in a single forward
-// pass), the model graph and attention layers need native batch support,
-// which is not yet implemented. This function provides request-level
-// parallelism as an interim solution.
+// When the generator was created with WithMaxBatchSize(n) for n > 1, requests
+// are decoded together: each group of up to n sequences shares a single
+// forward pass per step (one read of the weights for the whole group), in
+// the KV cache the generator's options select, and sequences that finish stop
+// contributing to the results while the remaining ones keep decoding. Prompts
+// of different token lengths share a batch, left-padded, when the model's
+// attention masks the padding; otherwise each batch holds prompts of one
+// length. Grammar-constrained requests, requests that could overflow the KV
+// cache, and generators using speculative decoding or PJRT run one request at
+// a time. An error during decoding, including ctx.Err() when ctx is canceled,
+// is reported for each request still decoding when it occurred.
+//
+// Without WithMaxBatchSize, requests are run sequentially: the Generator's
+// ExecutionPlan shares scratch buffers that are not safe for concurrent use.
 func (gen *Generator[T]) BatchGenerate(ctx context.Context, requests []BatchRequest) []BatchResult {
-	results := make([]BatchResult, len(requests))
+	if gen.maxBatchSize > 1 && gen.specDraft == nil && gen.pjrtPlan == nil {
+		return gen.batchGenerateNative(ctx, requests)
+	}
 
-	// Run sequentially: the Generator's ExecutionPlan shares scratch buffers
-	// that are not safe for concurrent use. True batched inference (batch
-	// dimension > 1 in a single forward pass) requires native batch support
-	// in the model graph, which is not yet implemented.
+	results := make([]BatchResult, len(requests))
 	for i := range requests {
 		text, err := gen.Generate(ctx, requests[i].Prompt, requests[i].Sampling)
 		results[i] = BatchResult{Text: text, Err: err}
@@ -0,0 +1,382 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// batchSeq tracks the decoding state of one request inside a batched
+// generation group.
+type batchSeq struct {
+	index        int // position in the caller's request slice
+	promptIDs    []int
+	pad          int // left-padding positions before the prompt in the batch
+	sc           SamplingConfig
+	stopSet      map[int]bool
+	generatedIDs []int
+	nextToken    int
+	done         bool
+	err          error  // set when decoding failed before the sequence finished
+	stopText     string // set when a stop string ended generation
+	stopped      bool   // true when stopText is the final output
+	decoded      string // running decoded text for incremental stop checks
+	decodedCount int
+}
+
+// unpaddableOps lists the op types of graph nodes that mix tokens or embed
+// absolute positions without honoring WithBatchPadding. A graph containing
+// any of them only batches prompts of equal length.
+var unpaddableOps = map[string]bool{
+	"AttentionHead":            true,
+	"ComplexSSMState":          true,
+	"Conv":                     true,
+	"Conv1D":                   true,
+	"FusedSDPA":                true,
+	"GPT2Embedding":            true,
+	"GlobalAttention":          true,
+	"LocalAttention":           true,
+	"MIMOMambaBlock":           true,
+	"MLSTM":                    true,
+	"MambaBlock":               true,
+	"MultiHeadLatentAttention": true,
+	"NSACoarseCompression":     true,
+	"NativeSparseAttention":    true,
+	"RWKVTimeMix":              true,
+	"S4":                       true,
+	"SLSTM":                    true,
+	"SSM":                      true,
+	"Sequential":               true,
+	"SimpleRNN":                true,
+	"SparseRoutedAttention":    true,
+	"TimeMixer":                true,
+	"TransformerBlock":         true,
+}
+
+// batchCompactor is implemented by KV caches that can drop finished
+// sequences from a batch, as KVCache does. With other caches finished
+// sequences stay in the batch until every sequence in it is done.
+type batchCompactor interface {
+	CompactBatch(keep []int, numSeqs int) error
+}
+
+// batchGenerateNative sorts requests by prompt length and decodes them in
+// batches with shared forward passes. When the graph and KV cache support
+// it (see padsBatches), prompts of different lengths share a batch,
+// left-padded to the longest with the padding masked out of attention;
+// otherwise only prompts of equal length do. Requests that cannot share a
+// batch (grammar-constrained, failing to tokenize, or able to overflow the
+// KV cache on their own) are handled individually, and an error ends only
+// the sequences still decoding when it occurred.
+func (gen *Generator[T]) batchGenerateNative(ctx context.Context, requests []BatchRequest) []BatchResult {
+	results := make([]BatchResult, len(requests))
+	capacity := gen.batchCapacity()
+
+	var sequential []int
+	var seqs []*batchSeq
+	for i := range requests {
+		sc := requests[i].Sampling
+		if sc.GrammarState != nil {
+			sequential = append(sequential, i)
+			continue
+		}
+		if sc.MaxNewTokens <= 0 {
+			sc.MaxNewTokens = 256
+		}
+		sc.seedRNG()
+		promptIDs, err := gen.tokenizer.Encode(requests[i].Prompt)
+		if err != nil {
+			results[i].Err = fmt.Errorf("encode prompt: %w", err)
+			continue
+		}
+		if len(promptIDs) == 0 {
+			results[i].Err = fmt.Errorf("prompt produced no tokens")
+			continue
+		}
+		if gen.config.BOSTokenID > 0 {
+			promptIDs = append([]int{gen.config.BOSTokenID}, promptIDs...)
+		}
+		// A request that can outgrow the cache by itself decodes alone, so
+		// it fails where Generate would without ending its batch.
+		if capacity > 0 && len(promptIDs)+sc.MaxNewTokens-1 > capacity {
+			sequential = append(sequential, i)
+			continue
+		}
+
+		stopSet := make(map[int]bool, len(sc.StopTokenIDs)+1)
+		for _, id := range sc.StopTokenIDs {
+			stopSet[id] = true
+		}
+		stopSet[gen.config.EOSTokenID] = true
+
+		seqs = append(seqs, &batchSeq{
+			index:        i,
+			promptIDs:    promptIDs,
+			sc:           sc,
+			stopSet:      stopSet,
+			generatedIDs: make([]int, 0, sc.MaxNewTokens),
+		})
+	}
+	slices.SortStableFunc(seqs, func(a, b *batchSeq) int {
+		return len(a.promptIDs) - len(b.promptIDs)
+	})
+
+	for _, chunk := range gen.batchChunks(seqs, gen.padsBatches(), capacity) {
+		err := gen.decodeBatch(ctx, chunk)
+		for _, seq := range chunk {
+			if err != nil && !seq.done {
+				seq.err = err
+			}
+			results[seq.index] = gen.batchSeqResult(seq)
+		}
+	}
+
+	for _, i := range sequential {
+		text, err := gen.Generate(ctx, requests[i].Prompt, requests[i].Sampling)
+		results[i] = BatchResult{Text: text, Err: err}
+	}
+	return results
+}
+
+// batchChunks splits seqs, sorted by prompt length, into batches of at
+// most maxBatchSize sequences. Unless pad is set, a batch holds prompts of
+// one length. Every position a batch writes must fit the KV cache: its
+// longest prompt plus its largest token budget, less the last sampled
+// token, which is never fed back, must not exceed capacity (0 for a cache
+// that does not overflow).
+func (gen *Generator[T]) batchChunks(seqs []*batchSeq, pad bool, capacity int) [][]*batchSeq {
+	var chunks [][]*batchSeq
+	var cur []*batchSeq
+	maxNew := 0
+	for _, seq := range seqs {
+		n := len(seq.promptIDs)
+		fits := len(cur) > 0 && len(cur) < gen.maxBatchSize &&
+			(pad || n == len(cur[0].promptIDs)) &&
+			(capacity == 0 || n+max(maxNew, seq.sc.MaxNewTokens)-1 <= capacity)
+		if !fits && len(cur) > 0 {
+			chunks = append(chunks, cur)
+			cur, maxNew = nil, 0
+		}
+		cur = append(cur, seq)
+		maxNew = max(maxNew, seq.sc.MaxNewTokens)
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+// padsBatches reports whether prompts of different lengths may share a
+// batch. The KV cache must keep every position where it was written (not
+// the sliding-window, compressed or tiered caches), the graph must carry
+// no recurrent state, and its attention must mask the padding (see
+// PaddingMasker) with no node from unpaddableOps to see it.
+func (gen *Generator[T]) padsBatches() bool {
+	if gen.tieredKVCfg != nil || gen.kvWindowSize > 0 || gen.compressedKVChunkSize > 0 {
+		return false
+	}
+	if len(gen.graph.KVPairs()) > 0 {
+		return false
+	}
+	masked := false
+	for _, n := range gen.graph.Nodes() {
+		if pm, ok := n.(PaddingMasker); ok && pm.MasksBatchPadding() {
+			masked = true
+			continue
+		}
+		if unpaddableOps[n.OpType()] {
+			return false
+		}
+	}
+	return masked
+}
+
+// batchCapacity returns the number of positions per sequence the KV cache
+// selectCacheProvider builds can hold, or 0 when it never overflows.
+func (gen *Generator[T]) batchCapacity() int {
+	switch {
+	case gen.tieredKVCfg != nil && gen.tieredKVCfg.MaxSeqLen > 0:
+		return gen.tieredKVCfg.MaxSeqLen
+	case gen.tieredKVCfg != nil:
+		return gen.config.MaxSeqLen
+	case gen.kvWindowSize > 0 || gen.compressedKVChunkSize > 0:
+		return 0
+	}
+	return gen.config.MaxSeqLen
+}
+
+// batchSeqResult converts a finished sequence into a BatchResult.
+func (gen *Generator[T]) batchSeqResult(seq *batchSeq) BatchResult {
+	if seq.err != nil {
+		return BatchResult{Err: seq.err}
+	}
+	if seq.stopped {
+		return BatchResult{Text: seq.stopText}
+	}
+	if len(seq.generatedIDs) == 0 {
+		return BatchResult{}
+	}
+	text, err := gen.tokenizer.Decode(seq.generatedIDs)
+	if err != nil {
+		return BatchResult{Err: fmt.Errorf("decode output: %w", err)}
+	}
+	return BatchResult{Text: text}
+}
+
+// decodeBatch prefills seqs, left-padded to the longest prompt, in one
+// forward pass of shape [batch, promptLen] and then decodes them together,
+// one [rows, 1] forward pass per step, in a KV cache from
+// selectCacheProvider. Finished sequences are dropped from the batch when
+// the cache supports it and otherwise ride along, their output ignored,
+// until the batch is done. It returns the error that ended decoding, which
+// applies to the sequences not yet done.
+func (gen *Generator[T]) decodeBatch(ctx context.Context, seqs []*batchSeq) error {
+	gen.mu.Lock()
+	defer gen.mu.Unlock()
+
+	cache, store, err := gen.selectCacheProvider()
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		defer store.Close()
+	}
+	cacheCtx := WithCache(ctx, cache)
+	gen.graph.ResetStatefulNodes()
+
+	promptLen := 0
+	for _, seq := range seqs {
+		promptLen = max(promptLen, len(seq.promptIDs))
+	}
+	ids := make([]int, 0, len(seqs)*promptLen)
+	for _, seq := range seqs {
+		seq.pad = promptLen - len(seq.promptIDs)
+		for range seq.pad {
+			ids = append(ids, gen.config.EOSTokenID)
+		}
+		ids = append(ids, seq.promptIDs...)
+	}
+	rows := slices.Clone(seqs)
+	logits, err := gen.batchForward(batchPaddingContext(cacheCtx, rows), ids, len(rows), promptLen)
+	if err != nil {
+		return fmt.Errorf("prefill forward: %w", err)
+	}
+
+	compactor, canCompact := cache.(batchCompactor)
+	for {
+		if err := gen.sampleBatch(logits, rows); err != nil {
+			return err
+		}
+
+		keep := make([]int, 0, len(rows))
+		for i, seq := range rows {
+			if !seq.done {
+				keep = append(keep, i)
+			}
+		}
+		if len(keep) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if canCompact && len(keep) < len(rows) {
+			if err := compactor.CompactBatch(keep, len(rows)); err != nil {
+				return fmt.Errorf("compact batch: %w", err)
+			}
+			next := make([]*batchSeq, len(keep))
+			for i, k := range keep {
+				next[i] = rows[k]
+			}
+			rows = next
+		}
+
+		if resetter, ok := gen.engine.(compute.PoolResetter); ok {
+			resetter.ResetPool()
+		}
+		ids = ids[:0]
+		for _, seq := range rows {
+			ids = append(ids, seq.nextToken)
+		}
+		logits, err = gen.batchForward(batchPaddingContext(cacheCtx, rows), ids, len(rows), 1)
+		if err != nil {
+			return fmt.Errorf("decode forward: %w", err)
+		}
+	}
+}
+
+// batchPaddingContext returns ctx carrying the left padding of rows, or ctx
+// itself when no row is padded.
+func batchPaddingContext(ctx context.Context, rows []*batchSeq) context.Context {
+	pads := make([]int, len(rows))
+	padded := false
+	for i, seq := range rows {
+		pads[i] = seq.pad
+		padded = padded || seq.pad > 0
+	}
+	if !padded {
+		return ctx
+	}
+	return WithBatchPadding(ctx, pads)
+}
+
+// batchForward runs the graph on a [batch, seqLen] token tensor and checks
+// that the logits carry one row per sequence.
+func (gen *Generator[T]) batchForward(ctx context.Context, ids []int, batch, seqLen int) (*tensor.TensorNumeric[T], error) {
+	data := make([]T, len(ids))
+	for i, id := range ids {
+		data[i] = T(id)
+	}
+	input, err := tensor.New([]int{batch, seqLen}, data)
+	if err != nil {
+		return nil, err
+	}
+	logits, err := gen.graph.Forward(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if shape := logits.Shape(); len(shape) != 3 || shape[0] != batch {
+		return nil, fmt.Errorf("expected logits [%d, seq, vocab], got shape %v", batch, shape)
+	}
+	return logits, nil
+}
+
+// sampleBatch samples the next token for every sequence not yet done from
+// its row of the batched logits and applies per-sequence stop handling.
+func (gen *Generator[T]) sampleBatch(logits *tensor.TensorNumeric[T], active []*batchSeq) error {
+	shape := logits.Shape()
+	rowSize := shape[1] * shape[2]
+	data := logits.Data()
+	for b, seq := range active {
+		if seq.done {
+			continue
+		}
+		row, err := tensor.New([]int{1, shape[1], shape[2]}, data[b*rowSize:(b+1)*rowSize])
+		if err != nil {
+			return fmt.Errorf("slice logits row %d: %w", b, err)
+		}
+		tok, err := gen.sampleFromLogits(row, seq.sc, seq.generatedIDs)
+		if err != nil {
+			return fmt.Errorf("sample: %w", err)
+		}
+		if seq.stopSet[tok] {
+			seq.done = true
+			continue
+		}
+		seq.generatedIDs = append(seq.generatedIDs, tok)
+		seq.nextToken = tok
+		if stopped, text := gen.checkStop(seq.generatedIDs, seq.sc.StopStrings, &seq.decoded, &seq.decodedCount); stopped {
+			seq.done = true
+			seq.stopped = true
+			seq.stopText = text
+			continue
+		}
+		if len(seq.generatedIDs) >= seq.sc.MaxNewTokens {
+			seq.done = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// cacheSumNode appends every input token to KV cache layer 0 and emits
+// logits whose argmax, for each batch row, is the sum of that row's cached
+// tokens modulo vocabSize. Outputs therefore depend on each sequence's own
+// cache contents, which exposes any mixing of rows during batch compaction.
+// With masksPadding set it skips each row's left padding (see
+// WithBatchPadding), as attention masking does.
+type cacheSumNode struct {
+	graph.NoParameters[float32]
+	vocabSize    int
+	failAfter    int // when > 0, Forward fails on this call number
+	masksPadding bool
+	onForward    func(call int)
+	mu           sync.Mutex
+	batchSizes   []int
+	cacheTypes   []string
+}
+
+func (n *cacheSumNode) MasksBatchPadding() bool { return n.masksPadding }
+
+func (n *cacheSumNode) OpType() string                     { return "CacheSum" }
+func (n *cacheSumNode) Attributes() map[string]interface{} { return nil }
+func (n *cacheSumNode) OutputShape() []int                 { return []int{1, 1, n.vocabSize} }
+func (n *cacheSumNode) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[float32], _ ...*tensor.TensorNumeric[float32]) ([]*tensor.TensorNumeric[float32], error) {
+	return nil, nil
+}
+
+func (n *cacheSumNode) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	shape := inputs[0].Shape()
+	batch, seqLen := shape[0], shape[1]
+
+	n.mu.Lock()
+	n.batchSizes = append(n.batchSizes, batch)
+	call := len(n.batchSizes)
+	n.mu.Unlock()
+	if n.onForward != nil {
+		n.onForward(call)
+	}
+	if n.failAfter > 0 && call >= n.failAfter {
+		return nil, errors.New("forward failed")
+	}
+
+	cache, ok := GetCache[float32](ctx)
+	if !ok {
+		return nil, errors.New("no cache in context")
+	}
+	n.mu.Lock()
+	n.cacheTypes = append(n.cacheTypes, fmt.Sprintf("%T", cache))
+	n.mu.Unlock()
+	kv, err := tensor.New([]int{batch, seqLen, 1}, append([]float32(nil), inputs[0].Data()...))
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Update(0, kv, kv); err != nil {
+		return nil, err
+	}
+	lkv, _ := cache.Get(0)
+	cached := lkv.Key.Shape()[1]
+	keys := lkv.Key.Data()
+
+	pad := BatchPadding(ctx)
+	data := make([]float32, batch*seqLen*n.vocabSize)
+	for b := range batch {
+		first := 0
+		if n.masksPadding && len(pad) == batch {
+			first = pad[b]
+		}
+		sum := 0
+		for s := first; s < cached; s++ {
+			sum += int(keys[b*cached+s])
+		}
+		target := sum % n.vocabSize
+		for pos := range seqLen {
+			off := (b*seqLen + pos) * n.vocabSize
+			for j := range n.vocabSize {
+				data[off+j] = -10
+			}
+			data[off+target] = 10
+		}
+	}
+	return tensor.New([]int{batch, seqLen, n.vocabSize}, data)
+}
+
+func buildCacheSumGenerator(t *testing.T, node *cacheSumNode, opts ...GeneratorOption) *Generator[float32] {
+	t.Helper()
+	engine := compute.NewCPUEngine(numeric.Float32Ops{})
+	b := graph.NewBuilder[float32](engine)
+	in := b.Input([]int{1, 1})
+	b.AddNode(node, in)
+	g, err := b.Build(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewGenerator[float32](g, buildTestTokenizer(), engine, ModelConfig{
+		VocabSize:  node.vocabSize,
+		MaxSeqLen:  32,
+		EOSTokenID: 2,
+		NumLayers:  1,
+	}, opts...)
+}
+
+func batchDecodeRequests() []BatchRequest {
+	sc := SamplingConfig{Temperature: 0, MaxNewTokens: 5}
+	return []BatchRequest{
+		{Prompt: "hello world", Sampling: sc}, // one token, then EOS
+		{Prompt: "world world", Sampling: sc}, // immediate EOS
+		{Prompt: "foo foo", Sampling: sc},     // runs to MaxNewTokens
+		{Prompt: "foo", Sampling: sc},         // different prompt length
+		{Prompt: "bar bar", Sampling: sc},     // runs to MaxNewTokens
+	}
+}
+
+func TestBatchGenerate_NativeMatchesSequential(t *testing.T) {
+	seqGen := buildCacheSumGenerator(t, &cacheSumNode{vocabSize: 8})
+	want := seqGen.BatchGenerate(context.Background(), batchDecodeRequests())
+
+	node := &cacheSumNode{vocabSize: 8}
+	gen := buildCacheSumGenerator(t, node, WithMaxBatchSize(8))
+	if gen.MaxBatchSize() != 8 {
+		t.Fatalf("MaxBatchSize() = %d, want 8", gen.MaxBatchSize())
+	}
+	got := gen.BatchGenerate(context.Background(), batchDecodeRequests())
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Err != nil || want[i].Err != nil {
+			t.Fatalf("result %d errors: batched=%v sequential=%v", i, got[i].Err, want[i].Err)
+		}
+		if got[i].Text != want[i].Text {
+			t.Errorf("result %d = %q, want %q", i, got[i].Text, want[i].Text)
+		}
+	}
+	if got[1].Text != "" {
+		t.Errorf("immediate-EOS request produced %q, want empty", got[1].Text)
+	}
+
+	// The node masks no padding, so the one-token prompt decodes alone
+	// first. The four two-token prompts then share one prefill, and finished
+	// sequences are removed from the batch as decoding proceeds.
+	i := slices.Index(node.batchSizes, 4)
+	if i <= 0 || !slices.Equal(node.batchSizes[i:i+3], []int{4, 3, 2}) || slices.ContainsFunc(node.batchSizes[:i], func(b int) bool { return b != 1 }) {
+		t.Errorf("batch sizes = %v, want batches of 1 then [4 3 2 ...]", node.batchSizes)
+	}
+}
+
+func TestBatchGenerate_NativeRespectsMaxBatchSize(t *testing.T) {
+	node := &cacheSumNode{vocabSize: 8}
+	gen := buildCacheSumGenerator(t, node, WithMaxBatchSize(2))
+	results := gen.BatchGenerate(context.Background(), batchDecodeRequests())
+	for i, r := range results {
+		if r.Err != nil {
+			t.Errorf("results[%d] error: %v", i, r.Err)
+		}
+	}
+	for _, b := range node.batchSizes {
+		if b > 2 {
+			t.Fatalf("batch sizes = %v, want all <= 2", node.batchSizes)
+		}
+	}
+}
+
+func TestBatchGenerate_NativeStopString(t *testing.T) {
+	gen := buildCacheSumGenerator(t, &cacheSumNode{vocabSize: 8}, WithMaxBatchSize(4))
+	sc := SamplingConfig{Temperature: 0, MaxNewTokens: 5}
+	stop := sc
+	stop.StopStrings = []string{"<unk>"}
+	results := gen.BatchGenerate(context.Background(), []BatchRequest{
+		{Prompt: "foo foo", Sampling: stop},
+		{Prompt: "bar bar", Sampling: sc},
+	})
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("results[%d] error: %v", i, r.Err)
+		}
+	}
+	want, err := gen.Generate(context.Background(), "foo foo", stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Text != want {
+		t.Errorf("stop-string result = %q, want %q", results[0].Text, want)
+	}
+	if results[1].Text == "" {
+		t.Error("second sequence should keep decoding after the first stops")
+	}
+}
+
+func TestBatchGenerate_NativeForwardError(t *testing.T) {
+	gen := buildCacheSumGenerator(t, &cacheSumNode{vocabSize: 8, failAfter: 2}, WithMaxBatchSize(4))
+	sc := SamplingConfig{Temperature: 0, MaxNewTokens: 5}
+	results := gen.BatchGenerate(context.Background(), []BatchRequest{
+		{Prompt: "foo foo", Sampling: sc},
+		{Prompt: "bar bar", Sampling: sc},
+		{Prompt: "", Sampling: sc},
+	})
+	for i := range 2 {
+		if results[i].Err == nil {
+			t.Errorf("results[%d] should carry the decode error", i)
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("empty prompt should fail")
+	}
+}
+
+func TestBatchGenerate_NativeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Cancel after the prefill and one decode step, mid-generation.
+	node := &cacheSumNode{vocabSize: 8, onForward: func(call int) {
+		if call == 2 {
+			cancel()
+		}
+	}}
+	gen := buildCacheSumGenerator(t, node, WithMaxBatchSize(4))
+	sc := SamplingConfig{Temperature: 0, MaxNewTokens: 5}
+	results := gen.BatchGenerate(ctx, []BatchRequest{
+		{Prompt: "foo foo", Sampling: sc},
+		{Prompt: "bar bar", Sampling: sc},
+	})
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d] = %+v, want context.Canceled", i, r)
+		}
+	}
+	if len(node.batchSizes) != 2 {
+		t.Errorf("%d forward passes, want decoding to stop after 2", len(node.batchSizes))
+	}
+}
+
+func TestBatchGenerate_NativePadsMixedLengths(t *testing.T) {
+	seqGen := buildCacheSumGenerator(t, &cacheSumNode{vocabSize: 8, masksPadding: true})
+	want := seqGen.BatchGenerate(context.Background(), batchDecodeRequests())
+
+	node := &cacheSumNode{vocabSize: 8, masksPadding: true}
+	gen := buildCacheSumGenerator(t, node, WithMaxBatchSize(8))
+	got := gen.BatchGenerate(context.Background(), batchDecodeRequests())
+	for i := range want {
+		if got[i].Err != nil || want[i].Err != nil {
+			t.Fatalf("result %d errors: batched=%v sequential=%v", i, got[i].Err, want[i].Err)
+		}
+		if got[i].Text != want[i].Text {
+			t.Errorf("result %d = %q, want %q", i, got[i].Text, want[i].Text)
+		}
+	}
+	// The one-token prompt is left-padded into the same prefill as the
+	// two-token prompts.
+	if len(node.batchSizes) == 0 || node.batchSizes[0] != 5 {
+		t.Errorf("batch sizes = %v, want a first batch of 5", node.batchSizes)
+	}
+}
+
+func TestBatchGenerate_NativeUsesConfiguredCache(t *testing.T) {
+	node := &cacheSumNode{vocabSize: 8, masksPadding: true}
+	gen := buildCacheSumGenerator(t, node, WithMaxBatchSize(8), WithSlidingWindowKV(64, 0))
+	for i, r := range gen.BatchGenerate(context.Background(), batchDecodeRequests()) {
+		if r.Err != nil {
+			t.Fatalf("results[%d] error: %v", i, r.Err)
+		}
+	}
+	for _, typ := range node.cacheTypes {
+		if !strings.Contains(typ, "SlidingWindowKVCache") {
+			t.Fatalf("cache types = %v, want the sliding window cache", node.cacheTypes)
+		}
+	}
+	// The sliding window cache drops positions, so prompts of different
+	// lengths are not padded into one batch.
+	if len(node.batchSizes) == 0 || node.batchSizes[0] != 1 {
+		t.Errorf("batch sizes = %v, want the one-token prompt decoded alone first", node.batchSizes)
+	}
+}
+
+func TestBatchGenerate_NativeOverflowIsPerSequence(t *testing.T) {
+	node := &cacheSumNode{vocabSize: 8, masksPadding: true}
+	gen := buildCacheSumGenerator(t, node, WithMaxBatchSize(8))
+	sc := SamplingConfig{Temperature: 0, MaxNewTokens: 5}
+	long := SamplingConfig{Temperature: 0, MaxNewTokens: 40} // exceeds MaxSeqLen 32
+	requests := []BatchRequest{
+		{Prompt: "hello world", Sampling: sc},
+		{Prompt: "foo foo", Sampling: long},
+		{Prompt: "bar bar", Sampling: sc},
+	}
+	results := gen.BatchGenerate(context.Background(), requests)
+	for _, i := range []int{0, 2} {
+		want, err := gen.Generate(context.Background(), requests[i].Prompt, requests[i].Sampling)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[i].Err != nil || results[i].Text != want {
+			t.Errorf("results[%d] = %+v, want %q", i, results[i], want)
+		}
+	}
+	_, wantErr := gen.Generate(context.Background(), requests[1].Prompt, requests[1].Sampling)
+	if (results[1].Err == nil) != (wantErr == nil) {
+		t.Errorf("results[1].Err = %v, want %v as from Generate", results[1].Err, wantErr)
+	}
+}
+
+func TestKVCache_CompactBatch(t *testing.T) {
+	cache := NewKVCache[float32](1, 4)
+	// 3 sequences x 2 rows each, 2 positions, dim 1.
+	data := []float32{
+		10, 11, 20, 21, // seq 0
+		30, 31, 40, 41, // seq 1
+		50, 51, 60, 61, // seq 2
+	}
+	k := makeTensor(t, []int{6, 2, 1}, data)
+	if err := cache.Update(0, k, k); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.CompactBatch([]int{0, 2}, 3); err != nil {
+		t.Fatalf("CompactBatch error: %v", err)
+	}
+	lkv, ok := cache.Get(0)
+	if !ok {
+		t.Fatal("Get(0) returned false")
+	}
+	if got := lkv.Key.Shape(); got[0] != 4 || got[1] != 2 {
+		t.Fatalf("shape = %v, want [4 2 1]", got)
+	}
+	want := []float32{10, 11, 20, 21, 50, 51, 60, 61}
+	for i, v := range lkv.Value.Data() {
+		if v != want[i] {
+			t.Fatalf("data = %v, want %v", lkv.Value.Data(), want)
+		}
+	}
+
+	// Appending after compaction uses the reduced batch.
+	k2 := makeTensor(t, []int{4, 1, 1}, []float32{12, 22, 52, 62})
+	if err := cache.Update(0, k2, k2); err != nil {
+		t.Fatalf("Update after compaction: %v", err)
+	}
+
+	if err := cache.CompactBatch([]int{1, 0}, 2); err == nil {
+		t.Error("expected error for unordered keep indices")
+	}
+	if err := cache.CompactBatch([]int{0}, 3); err == nil {
+		t.Error("expected error for indivisible batch")
+	}
+	if err := cache.CompactBatch([]int{5}, 2); err == nil {
+		t.Error("expected error for out-of-range index")
+	}
+}
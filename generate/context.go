@@ -86,3 +86,28 @@ func GetCache[T tensor.Numeric](ctx context.Context) (CacheProvider[T], bool) {
 	}
 	return cache, true
 }
+
+type batchPaddingKey struct{}
+
+// WithBatchPadding returns a new context recording that row b of the
+// batched forward pass is left-padded with pad[b] positions. The padding
+// occupies the first pad[b] KV cache positions of that row and must be
+// hidden from attention. Batched decoding sets it when prompts of
+// different lengths share a batch.
+func WithBatchPadding(ctx context.Context, pad []int) context.Context {
+	return context.WithValue(ctx, batchPaddingKey{}, pad)
+}
+
+// BatchPadding returns the per-row left padding set by WithBatchPadding, or
+// nil when the rows are not padded.
+func BatchPadding(ctx context.Context) []int {
+	pad, _ := ctx.Value(batchPaddingKey{}).([]int)
+	return pad
+}
+
+// PaddingMasker is implemented by graph nodes that hide the left padding
+// recorded by WithBatchPadding from attention. Batched decoding only
+// left-pads prompts for graphs with such a node.
+type PaddingMasker interface {
+	MasksBatchPadding() bool
+}
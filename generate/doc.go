@@ -66,9 +66,10 @@
 // # Batch Generation
 //
 // [Generator.BatchGenerate] and [Generator.BatchGenerateStream] accept
-// multiple prompts and run them sequentially (request-level parallelism).
-// True batched tensor operations (batch dimension > 1) require native
-// batch support in the model graph, which is planned but not yet implemented.
+// multiple prompts and run them sequentially by default. With
+// [WithMaxBatchSize], BatchGenerate groups prompts of equal token length and
+// decodes each group with one [batch, seq] forward pass per step, removing
+// sequences from the batch (and compacting the KV cache) as they finish.
 //
 // # Speculative Decoding
 //
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/rand/v2"
 	"os"
 	"sort"
 	"sync"
@@ -43,6 +44,8 @@ type SamplingConfig struct {
 	GrammarState      *grammar.Grammar // Optional grammar for constrained decoding
 	grammarVocab      []string         // Cached token strings for grammar masking (built lazily)
 	AdapterName       string           // Optional LoRA adapter name for per-request selection
+	Seed              *int64           // Optional sampling seed; nil = nondeterministic
+	rng               *rand.Rand       // Per-request sampler seeded from Seed (set by seedRNG)
 }
 
 // DefaultSamplingConfig returns a SamplingConfig with sensible defaults.
@@ -65,8 +68,12 @@ type generatorOptions struct {
 	kvDtype               string // KV cache storage dtype: "fp32" (default) or "fp16"
 	specDraft             *specDraftConfig // when non-nil, use speculative decoding
 	prefixCacheBlocks     int    // when > 0, enable prefix caching with this many cached blocks
+	promptCacheBlocks     int    // when > 0, enable PromptCache with this many cached blocks
 	metricsCollector      runtime.Collector // optional metrics collector
 	compressedKVChunkSize int    // when > 0, use CompressedKVCache with this chunk size
+	kvWindowSize          int    // when > 0, use SlidingWindowKVCache with this window
+	kvSinkTokens          int    // attention-sink positions retained by SlidingWindowKVCache
+	maxBatchSize          int    // when > 1, BatchGenerate decodes up to this many sequences per forward pass
 	eagleWeightsPath      string // when non-empty, enable EAGLE speculative decoding with weights from this GGUF path
 	tieredKVCfg           *TieredKVStoreConfig // when non-nil, use TieredKVStore
 	pjrtPlan              any    // *graph.PJRTPlan[T], stored as any to avoid type param on generatorOptions
@@ -137,6 +144,19 @@ func WithPrefixCache(capacityBlocks int) GeneratorOption {
 	}
 }
 
+// WithPromptCache enables a PromptCache holding up to capacityBlocks blocks
+// of 16 tokens. Sessions restore the longest cached prefix of each prompt
+// into their KV cache instead of prefilling it, and cache the prompt's
+// blocks afterwards for later generations. Sliding-window and compressed KV
+// caches drop positions, and PJRT keeps its own KV state, so with
+// WithSlidingWindowKV, WithCompressedKV or WithPJRTPlan the option has no
+// effect and PromptCache returns nil.
+func WithPromptCache(capacityBlocks int) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.promptCacheBlocks = capacityBlocks
+	}
+}
+
 // WithCompressedKV enables compressed KV caching using chunk-wise mean pooling.
 // When a chunk of chunkSize tokens fills up, it is compressed into a single
 // vector by averaging. If chunkSize <= 0, it defaults to 64.
@@ -149,6 +169,31 @@ func WithCompressedKV(chunkSize int) GeneratorOption {
 	}
 }
 
+// WithSlidingWindowKV bounds the KV cache to the first sinkTokens positions
+// (attention sinks) plus the most recent windowSize positions, evicting the
+// rest. This caps per-session KV memory so chat sessions can run past the
+// point where a full cache would overflow. It takes precedence over every
+// other KV cache option except tiered storage. A non-positive windowSize
+// disables the option.
+func WithSlidingWindowKV(windowSize, sinkTokens int) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.kvWindowSize = windowSize
+		o.kvSinkTokens = max(sinkTokens, 0)
+	}
+}
+
+// WithMaxBatchSize enables native batched decoding in BatchGenerate with up
+// to n sequences per forward pass. Prompts of different token lengths are
+// left-padded into one batch when the model's attention masks the padding
+// (see PaddingMasker); otherwise only prompts of equal length are batched
+// together. The model graph must accept inputs with a batch dimension
+// greater than one. Values <= 1 keep sequential decoding.
+func WithMaxBatchSize(n int) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.maxBatchSize = n
+	}
+}
+
 // WithEAGLE enables EAGLE-style self-speculative decoding. headWeightsPath
 // points to a GGUF file containing the EAGLE head weights. When the file
 // exists at generation time the generator uses the EAGLE decode loop; if the
@@ -185,8 +230,12 @@ type Generator[T tensor.Numeric] struct {
 	mu              sync.Mutex                                 // serializes Generate/GenerateStream calls (graph state is not concurrent-safe)
 	specDraft             *specDraftConfig                           // nil unless speculative decoding is enabled
 	prefixCache           *PrefixCache[T]                            // nil unless prefix caching is enabled
+	promptCache           *PromptCache[T]                            // nil unless prompt caching is enabled
 	specAcceptRate        runtime.GaugeMetric                        // speculative acceptance rate gauge
 	compressedKVChunkSize int                                        // when > 0, use CompressedKVCache
+	kvWindowSize          int                                        // when > 0, use SlidingWindowKVCache
+	kvSinkTokens          int                                        // attention sinks for SlidingWindowKVCache
+	maxBatchSize          int                                        // when > 1, BatchGenerate uses batched decode
 	eagleWeightsPath      string                                     // when non-empty, EAGLE decode is preferred
 	tieredKVCfg           *TieredKVStoreConfig                       // when non-nil, use TieredKVStore per generation call
 	pjrtPlan              *graph.PJRTPlan[T]                         // when non-nil, use PJRT backend for inference
@@ -224,6 +273,9 @@ func NewGenerator[T tensor.Numeric](
 		specDraft:             gopts.specDraft,
 		specAcceptRate:        mc.Gauge("speculative_acceptance_rate"),
 		compressedKVChunkSize: gopts.compressedKVChunkSize,
+		kvWindowSize:          gopts.kvWindowSize,
+		kvSinkTokens:          gopts.kvSinkTokens,
+		maxBatchSize:          gopts.maxBatchSize,
 		eagleWeightsPath:      gopts.eagleWeightsPath,
 		tieredKVCfg:           gopts.tieredKVCfg,
 		pjrtPlan:              pjrtPlan,
@@ -246,6 +298,10 @@ func NewGenerator[T tensor.Numeric](
 		gen.prefixCache = NewPrefixCache[T](gopts.prefixCacheBlocks, gen.blockPool)
 	}
 
+	if gopts.promptCacheBlocks > 0 && gopts.kvWindowSize == 0 && gopts.compressedKVChunkSize == 0 && pjrtPlan == nil {
+		gen.promptCache = NewPromptCache[T](defaultPromptCacheBlockSize, gopts.promptCacheBlocks)
+	}
+
 	return gen
 }
 
@@ -265,6 +321,10 @@ func (gen *Generator[T]) UnlockGraph() { gen.mu.Unlock() }
 // PJRTPlan returns the PJRT plan, or nil if PJRT is not enabled.
 func (gen *Generator[T]) PJRTPlan() *graph.PJRTPlan[T] { return gen.pjrtPlan }
 
+// TensorPool returns the pool the graph releases intermediate buffers into
+// during Forward, or nil when the generator has no graph.
+func (gen *Generator[T]) TensorPool() *compute.TensorPool[T] { return gen.pool }
+
 // Tokenizer returns the tokenizer.
 func (gen *Generator[T]) Tokenizer() tokenizer.Tokenizer { return gen.tokenizer }
 
@@ -274,9 +334,18 @@ func (gen *Generator[T]) Engine() compute.Engine[T] { return gen.engine }
 // Config returns the model configuration.
 func (gen *Generator[T]) Config() ModelConfig { return gen.config }
 
+// MaxBatchSize returns the maximum number of sequences BatchGenerate decodes
+// per forward pass. Values <= 1 mean requests are decoded one at a time.
+func (gen *Generator[T]) MaxBatchSize() int { return gen.maxBatchSize }
+
 // GetPrefixCache returns the prefix cache, or nil if prefix caching is disabled.
 func (gen *Generator[T]) GetPrefixCache() *PrefixCache[T] { return gen.prefixCache }
 
+// PromptCache returns the generator's prompt cache, or nil if prompt caching
+// is disabled or not supported by the generator's KV cache (see
+// WithPromptCache).
+func (gen *Generator[T]) PromptCache() *PromptCache[T] { return gen.promptCache }
+
 // EAGLEWeightsPath returns the configured EAGLE head weights path, or empty
 // if EAGLE was not requested.
 func (gen *Generator[T]) EAGLEWeightsPath() string { return gen.eagleWeightsPath }
@@ -379,6 +448,7 @@ func (gen *Generator[T]) Generate(ctx context.Context, prompt string, sc Samplin
 	if sc.MaxNewTokens <= 0 {
 		sc.MaxNewTokens = 256
 	}
+	sc.seedRNG()
 
 	// Speculative decoding path: delegate to SpeculativeGenerator with
 	// alpha-based fallback to standard decode.
@@ -617,6 +687,13 @@ func (gen *Generator[T]) selectCacheProvider() (CacheProvider[T], *TieredKVStore
 		}
 		return &tieredKVAdapter[T]{store: store}, store, nil
 	}
+	if gen.kvWindowSize > 0 {
+		cache, err := NewSlidingWindowKVCache[T](gen.config.NumLayers, gen.kvWindowSize, gen.kvSinkTokens)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create sliding window KV cache: %w", err)
+		}
+		return cache, nil, nil
+	}
 	if gen.compressedKVChunkSize > 0 {
 		return NewCompressedKVCache[T](gen.engine, gen.config.NumLayers, 0, 0, gen.compressedKVChunkSize), nil, nil
 	}
@@ -170,3 +170,51 @@ func (c *KVCache[T]) Truncate(newSeqLen int) {
 		}
 	}
 }
+
+// CompactBatch removes finished sequences from a batched cache. numSeqs is
+// the number of sequences currently in the batch and keep lists, in
+// ascending order, the indices of the sequences to retain. Each layer's
+// batch rows are divided evenly among the sequences (attention layers store
+// one row per KV head per sequence); retained rows are moved to the front of
+// the buffer and the layer's batch size shrinks accordingly.
+func (c *KVCache[T]) CompactBatch(keep []int, numSeqs int) error {
+	if numSeqs <= 0 {
+		return fmt.Errorf("numSeqs must be positive, got %d", numSeqs)
+	}
+	for i, seq := range keep {
+		if seq < 0 || seq >= numSeqs {
+			return fmt.Errorf("sequence index %d out of range [0, %d)", seq, numSeqs)
+		}
+		if i > 0 && seq <= keep[i-1] {
+			return fmt.Errorf("keep indices must be strictly ascending, got %v", keep)
+		}
+	}
+	for i := range c.layers {
+		if c.layers[i].keyBuf != nil && c.layers[i].batch%numSeqs != 0 {
+			return fmt.Errorf("layer %d batch %d is not divisible by %d sequences", i, c.layers[i].batch, numSeqs)
+		}
+	}
+
+	for i := range c.layers {
+		lb := &c.layers[i]
+		if lb.keyBuf == nil {
+			continue
+		}
+		rows := lb.batch / numSeqs
+		stride := c.maxSeqLen * lb.dim
+		n := lb.cursor * lb.dim
+		for dst, seq := range keep {
+			if dst == seq {
+				continue
+			}
+			for r := range rows {
+				srcOff := (seq*rows + r) * stride
+				dstOff := (dst*rows + r) * stride
+				copy(lb.keyBuf[dstOff:dstOff+n], lb.keyBuf[srcOff:srcOff+n])
+				copy(lb.valBuf[dstOff:dstOff+n], lb.valBuf[srcOff:srcOff+n])
+			}
+		}
+		lb.batch = len(keep) * rows
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package generate
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// defaultPromptCacheBlockSize is the number of tokens per PromptCache block
+// used by WithPromptCache.
+const defaultPromptCacheBlockSize = 16
+
+// PromptCache reuses the KV state of prompt prefixes across generations.
+// Prompts are split into fixed-size token blocks; each block is keyed by a
+// hash chained over every preceding block, so identical prefixes map to the
+// same entries and are stored once no matter how many prompts share them.
+// A later prompt that starts with a cached prefix restores that prefix's KV
+// state and only prefills the remaining tokens, which cuts time-to-first-token
+// for templated prompts that share a long system prompt.
+//
+// The cache holds at most a fixed number of blocks and evicts the least
+// recently used block that no other cached block extends. PromptCache is safe
+// for concurrent use.
+type PromptCache[T tensor.Numeric] struct {
+	blockSize int
+	capacity  int
+
+	mu      sync.Mutex
+	entries map[uint64]*promptBlock[T]
+	lru     *list.List // of *promptBlock[T], most recently used at the front
+
+	hits, misses, evictions, reusedTokens int64
+}
+
+// promptBlock holds the keys and values of blockSize consecutive positions
+// for every layer. Its data is immutable once the block is cached.
+type promptBlock[T tensor.Numeric] struct {
+	hash     uint64
+	parent   *promptBlock[T]
+	tokens   []int
+	layers   []promptBlockKV[T]
+	children int // cached blocks whose parent is this block
+	elem     *list.Element
+}
+
+// promptBlockKV is one layer's slice of a promptBlock, laid out as
+// [channels, blockSize, dim] like the tensors KVCache.Get returns.
+type promptBlockKV[T tensor.Numeric] struct {
+	channels, dim int
+	k, v          []T
+}
+
+// PromptCacheStats reports cumulative PromptCache activity.
+type PromptCacheStats struct {
+	Hits         int64 // lookups that restored at least one block
+	Misses       int64 // lookups that restored nothing
+	Evictions    int64 // blocks dropped to stay within capacity
+	ReusedTokens int64 // prompt tokens restored instead of prefilled
+	Blocks       int   // blocks currently cached
+}
+
+// NewPromptCache creates a prompt cache that splits prompts into blocks of
+// blockSize tokens and holds at most capacityBlocks blocks. Non-positive
+// values fall back to a block size of 16 and a capacity of one block.
+func NewPromptCache[T tensor.Numeric](blockSize, capacityBlocks int) *PromptCache[T] {
+	if blockSize <= 0 {
+		blockSize = defaultPromptCacheBlockSize
+	}
+	if capacityBlocks <= 0 {
+		capacityBlocks = 1
+	}
+	return &PromptCache[T]{
+		blockSize: blockSize,
+		capacity:  capacityBlocks,
+		entries:   make(map[uint64]*promptBlock[T]),
+		lru:       list.New(),
+	}
+}
+
+// BlockSize returns the number of tokens per cached block.
+func (pc *PromptCache[T]) BlockSize() int { return pc.blockSize }
+
+// Stats returns cumulative hit, miss, eviction and reuse counts.
+func (pc *PromptCache[T]) Stats() PromptCacheStats {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return PromptCacheStats{
+		Hits:         pc.hits,
+		Misses:       pc.misses,
+		Evictions:    pc.evictions,
+		ReusedTokens: pc.reusedTokens,
+		Blocks:       len(pc.entries),
+	}
+}
+
+// Restore resets cache and loads the KV state of the longest cached prefix
+// of promptIDs into it. It returns the number of restored tokens, which is a
+// multiple of the block size and always leaves at least one prompt token to
+// prefill so the caller gets logits for the last prompt position. On any
+// failure the cache is left empty and Restore returns 0.
+//
+// cache must keep every position it is given, as all caches but the
+// sliding-window, compressed and tiered ones do.
+func (pc *PromptCache[T]) Restore(cache CacheProvider[T], promptIDs []int) int {
+	cache.Reset()
+	blocks := pc.match(promptIDs)
+	if len(blocks) == 0 {
+		return 0
+	}
+
+	n := len(blocks) * pc.blockSize
+	for layer := range blocks[0].layers {
+		channels, dim := blocks[0].layers[layer].channels, blocks[0].layers[layer].dim
+		k := make([]T, channels*n*dim)
+		v := make([]T, channels*n*dim)
+		span := pc.blockSize * dim
+		for i, b := range blocks {
+			src := b.layers[layer]
+			for ch := range channels {
+				dst := ch*n*dim + i*span
+				copy(k[dst:dst+span], src.k[ch*span:(ch+1)*span])
+				copy(v[dst:dst+span], src.v[ch*span:(ch+1)*span])
+			}
+		}
+		kt, err := tensor.New([]int{channels, n, dim}, k)
+		if err != nil {
+			cache.Reset()
+			return 0
+		}
+		vt, err := tensor.New([]int{channels, n, dim}, v)
+		if err != nil {
+			cache.Reset()
+			return 0
+		}
+		if err := cache.Update(layer, kt, vt); err != nil {
+			cache.Reset()
+			return 0
+		}
+	}
+
+	pc.mu.Lock()
+	pc.reusedTokens += int64(n)
+	pc.mu.Unlock()
+	return n
+}
+
+// match returns the cached blocks covering the longest prefix of promptIDs
+// that leaves at least one token uncovered, and records a hit or miss.
+func (pc *PromptCache[T]) match(promptIDs []int) []*promptBlock[T] {
+	maxBlocks := 0
+	if len(promptIDs) > 0 {
+		maxBlocks = (len(promptIDs) - 1) / pc.blockSize
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	var blocks []*promptBlock[T]
+	var parent *promptBlock[T]
+	for i := range maxBlocks {
+		toks := promptIDs[i*pc.blockSize : (i+1)*pc.blockSize]
+		b := pc.lookup(parent, toks)
+		if b == nil {
+			break
+		}
+		blocks = append(blocks, b)
+		parent = b
+	}
+
+	if len(blocks) == 0 {
+		pc.misses++
+		return nil
+	}
+	pc.hits++
+	for _, b := range blocks {
+		pc.lru.MoveToFront(b.elem)
+	}
+	return blocks
+}
+
+// Insert caches every full block of promptIDs whose KV state is present in
+// cache, which must hold at least the prompt's positions (as it does right
+// after prefill) in layers numbered from 0, each returned by Get as
+// [channels, seqLen, dim]. Blocks that are already cached are only marked
+// as recently used.
+func (pc *PromptCache[T]) Insert(cache CacheProvider[T], promptIDs []int) {
+	numBlocks := min(len(promptIDs), cache.SeqLen()) / pc.blockSize
+	if numBlocks == 0 {
+		return
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	var layers []*LayerKV[T]
+	var parent *promptBlock[T]
+	for i := range numBlocks {
+		toks := promptIDs[i*pc.blockSize : (i+1)*pc.blockSize]
+		h := chainHash(parent, toks)
+		if b, ok := pc.entries[h]; ok {
+			if b.parent != parent || !slices.Equal(b.tokens, toks) {
+				return // hash collision; keep the existing entry
+			}
+			pc.lru.MoveToFront(b.elem)
+			parent = b
+			continue
+		}
+
+		if layers == nil {
+			for l := 0; ; l++ {
+				lkv, ok := cache.Get(l)
+				if !ok {
+					break
+				}
+				layers = append(layers, lkv)
+			}
+			if len(layers) == 0 {
+				return
+			}
+		}
+
+		b := &promptBlock[T]{
+			hash:   h,
+			parent: parent,
+			tokens: slices.Clone(toks),
+			layers: make([]promptBlockKV[T], len(layers)),
+		}
+		for l, lkv := range layers {
+			b.layers[l] = snapshotBlock(lkv, i, pc.blockSize)
+		}
+		pc.entries[h] = b
+		b.elem = pc.lru.PushFront(b)
+		if parent != nil {
+			parent.children++
+		}
+		parent = b
+
+		for len(pc.entries) > pc.capacity {
+			if !pc.evictOne() {
+				break
+			}
+		}
+	}
+}
+
+// lookup returns the cached block extending parent with toks, or nil.
+// Must be called with pc.mu held.
+func (pc *PromptCache[T]) lookup(parent *promptBlock[T], toks []int) *promptBlock[T] {
+	b, ok := pc.entries[chainHash(parent, toks)]
+	if !ok || b.parent != parent || !slices.Equal(b.tokens, toks) {
+		return nil
+	}
+	return b
+}
+
+// evictOne drops the least recently used block that no cached block extends.
+// Must be called with pc.mu held.
+func (pc *PromptCache[T]) evictOne() bool {
+	for e := pc.lru.Back(); e != nil; e = e.Prev() {
+		b := e.Value.(*promptBlock[T])
+		if b.children > 0 {
+			continue
+		}
+		pc.lru.Remove(e)
+		delete(pc.entries, b.hash)
+		if b.parent != nil {
+			b.parent.children--
+		}
+		pc.evictions++
+		return true
+	}
+	return false
+}
+
+// snapshotBlock copies positions [i*blockSize, (i+1)*blockSize) of a layer's
+// [channels, seqLen, dim] keys and values.
+func snapshotBlock[T tensor.Numeric](lkv *LayerKV[T], i, blockSize int) promptBlockKV[T] {
+	shape := lkv.Key.Shape()
+	channels, seqLen, dim := shape[0], shape[1], shape[2]
+	kData, vData := lkv.Key.Data(), lkv.Value.Data()
+	span := blockSize * dim
+	out := promptBlockKV[T]{
+		channels: channels,
+		dim:      dim,
+		k:        make([]T, channels*span),
+		v:        make([]T, channels*span),
+	}
+	for ch := range channels {
+		src := ch*seqLen*dim + i*span
+		copy(out.k[ch*span:(ch+1)*span], kData[src:src+span])
+		copy(out.v[ch*span:(ch+1)*span], vData[src:src+span])
+	}
+	return out
+}
+
+// chainHash hashes a block's tokens together with its parent's hash, so a
+// block's key identifies the whole prefix ending with it.
+func chainHash[T tensor.Numeric](parent *promptBlock[T], toks []int) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	if parent != nil {
+		binary.LittleEndian.PutUint64(buf[:], parent.hash)
+		_, _ = h.Write(buf[:])
+	}
+	for _, t := range toks {
+		binary.LittleEndian.PutUint64(buf[:], uint64(t))
+		_, _ = h.Write(buf[:])
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,260 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// fillPromptKV appends n positions to every layer of cache. Each element
+// encodes its layer, channel, position and index so restored data can be
+// checked exactly.
+func fillPromptKV(t *testing.T, cache *KVCache[float32], n, channels, dim int) {
+	t.Helper()
+	for layer := range cache.NumLayers() {
+		k := make([]float32, channels*n*dim)
+		v := make([]float32, channels*n*dim)
+		for ch := range channels {
+			for pos := range n {
+				for d := range dim {
+					i := ch*n*dim + pos*dim + d
+					k[i] = float32(layer*100000 + ch*10000 + pos*10 + d)
+					v[i] = -k[i]
+				}
+			}
+		}
+		kt, _ := tensor.New([]int{channels, n, dim}, k)
+		vt, _ := tensor.New([]int{channels, n, dim}, v)
+		if err := cache.Update(layer, kt, vt); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func seqIDs(n, offset int) []int {
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = offset + i
+	}
+	return ids
+}
+
+func TestPromptCache_InsertRestore(t *testing.T) {
+	src := NewKVCache[float32](2, 64)
+	fillPromptKV(t, src, 40, 2, 3)
+
+	pc := NewPromptCache[float32](16, 8)
+	prompt := seqIDs(40, 100)
+	pc.Insert(src, prompt)
+	if got := pc.Stats().Blocks; got != 2 {
+		t.Fatalf("Blocks = %d, want 2", got)
+	}
+
+	dst := NewKVCache[float32](2, 64)
+	if n := pc.Restore(dst, prompt); n != 32 {
+		t.Fatalf("Restore = %d, want 32", n)
+	}
+	if dst.SeqLen() != 32 {
+		t.Fatalf("SeqLen = %d, want 32", dst.SeqLen())
+	}
+	for layer := range 2 {
+		want, _ := src.Get(layer)
+		got, _ := dst.Get(layer)
+		for ch := range 2 {
+			w := want.Key.Data()[ch*40*3 : ch*40*3+32*3]
+			g := got.Key.Data()[ch*32*3 : (ch+1)*32*3]
+			if !slices.Equal(w, g) {
+				t.Fatalf("layer %d channel %d keys differ", layer, ch)
+			}
+			w = want.Value.Data()[ch*40*3 : ch*40*3+32*3]
+			g = got.Value.Data()[ch*32*3 : (ch+1)*32*3]
+			if !slices.Equal(w, g) {
+				t.Fatalf("layer %d channel %d values differ", layer, ch)
+			}
+		}
+	}
+
+	tests := []struct {
+		name   string
+		prompt []int
+		want   int
+	}{
+		{"exact block multiple keeps last token", prompt[:32], 16},
+		{"diverges in second block", append(slices.Clone(prompt[:20]), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13), 16},
+		{"diverges in first block", seqIDs(40, 0), 0},
+		{"shorter than a block", prompt[:10], 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewKVCache[float32](2, 64)
+			if got := pc.Restore(c, tt.prompt); got != tt.want {
+				t.Errorf("Restore = %d, want %d", got, tt.want)
+			}
+			if c.SeqLen() != tt.want {
+				t.Errorf("SeqLen = %d, want %d", c.SeqLen(), tt.want)
+			}
+		})
+	}
+
+	st := pc.Stats()
+	if st.Hits != 3 || st.Misses != 2 || st.ReusedTokens != 64 {
+		t.Errorf("Stats = %+v, want 3 hits, 2 misses, 64 reused tokens", st)
+	}
+}
+
+func TestPromptCache_SharedPrefixStoredOnce(t *testing.T) {
+	src := NewKVCache[float32](1, 64)
+	fillPromptKV(t, src, 32, 1, 2)
+
+	pc := NewPromptCache[float32](16, 8)
+	a := seqIDs(32, 0)
+	b := append(seqIDs(16, 0), seqIDs(16, 500)...)
+	pc.Insert(src, a)
+	pc.Insert(src, b)
+	if got := pc.Stats().Blocks; got != 3 {
+		t.Errorf("Blocks = %d, want 3 (shared first block stored once)", got)
+	}
+}
+
+func TestPromptCache_EvictsLeastRecentlyUsedLeaf(t *testing.T) {
+	src := NewKVCache[float32](1, 64)
+	fillPromptKV(t, src, 32, 1, 2)
+
+	pc := NewPromptCache[float32](16, 3)
+	a := seqIDs(33, 0)
+	b := seqIDs(33, 1000)
+	pc.Insert(src, a)
+	pc.Insert(src, b)
+
+	st := pc.Stats()
+	if st.Blocks != 3 || st.Evictions != 1 {
+		t.Fatalf("Stats = %+v, want 3 blocks and 1 eviction", st)
+	}
+	// a's leaf was evicted first; its first block survives.
+	if n := pc.Restore(NewKVCache[float32](1, 64), a); n != 16 {
+		t.Errorf("Restore(a) = %d, want 16", n)
+	}
+	if n := pc.Restore(NewKVCache[float32](1, 64), b); n != 32 {
+		t.Errorf("Restore(b) = %d, want 32", n)
+	}
+}
+
+// kvRecorderNode writes one cache position per input token into layer 0 of
+// the context's KV cache and records the length of every forward pass. It
+// always predicts EOS.
+type kvRecorderNode struct {
+	graph.NoParameters[float32]
+	vocabSize int
+	mu        sync.Mutex
+	seqLens   []int
+}
+
+func (n *kvRecorderNode) OpType() string                     { return "KVRecorder" }
+func (n *kvRecorderNode) Attributes() map[string]interface{} { return nil }
+func (n *kvRecorderNode) OutputShape() []int                 { return []int{1, 1, n.vocabSize} }
+func (n *kvRecorderNode) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[float32], _ ...*tensor.TensorNumeric[float32]) ([]*tensor.TensorNumeric[float32], error) {
+	return nil, nil
+}
+
+func (n *kvRecorderNode) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	ids := inputs[0].Data()
+	seqLen := len(ids)
+	n.mu.Lock()
+	n.seqLens = append(n.seqLens, seqLen)
+	n.mu.Unlock()
+
+	if cache, ok := GetCache[float32](ctx); ok {
+		kv, _ := tensor.New([]int{1, seqLen, 1}, slices.Clone(ids))
+		if err := cache.Update(0, kv, kv); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]float32, seqLen*n.vocabSize)
+	for pos := range seqLen {
+		for j := range n.vocabSize {
+			data[pos*n.vocabSize+j] = -10
+		}
+		data[pos*n.vocabSize+2] = 10 // </s>
+	}
+	return tensor.New([]int{1, seqLen, n.vocabSize}, data)
+}
+
+func TestSession_PromptCacheSkipsCachedPrefill(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opt  GeneratorOption
+	}{
+		{"KVCache", nil},
+		{"PagedKVCache", WithPagedKV(1, 1)},
+		{"KVCacheQ4", WithGeneratorKVDtype("q4")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			eng := compute.NewCPUEngine(numeric.Float32Ops{})
+			b := graph.NewBuilder[float32](eng)
+			in := b.Input([]int{1, 1})
+			node := &kvRecorderNode{vocabSize: 8}
+			b.AddNode(node, in)
+			g, err := b.Build(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := []GeneratorOption{WithPromptCache(16)}
+			if tt.opt != nil {
+				opts = append(opts, tt.opt)
+			}
+			gen := NewGenerator[float32](g, buildTestTokenizer(), eng, ModelConfig{
+				VocabSize:  8,
+				MaxSeqLen:  64,
+				EOSTokenID: 2,
+				NumLayers:  1,
+			}, opts...)
+
+			prompt := strings.TrimSpace(strings.Repeat("hello world ", 10)) // 20 tokens
+			sc := SamplingConfig{MaxNewTokens: 4}
+
+			sess := gen.NewSession()
+			if got := fmt.Sprintf("%T", sess.Cache()); !strings.Contains(got, tt.name) {
+				t.Fatalf("session cache = %s, want %s", got, tt.name)
+			}
+			if _, err := sess.Generate(context.Background(), prompt, sc); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.NewSession().GenerateStream(context.Background(), prompt, sc,
+				TokenStreamFunc(func(string, bool) error { return nil })); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(node.seqLens, []int{20, 4}) {
+				t.Errorf("prefill lengths = %v, want [20 4]", node.seqLens)
+			}
+			st := gen.PromptCache().Stats()
+			if st.Hits != 1 || st.ReusedTokens != 16 {
+				t.Errorf("Stats = %+v, want 1 hit reusing 16 tokens", st)
+			}
+		})
+	}
+}
+
+func TestWithPromptCache_UnsupportedCache(t *testing.T) {
+	for name, opt := range map[string]GeneratorOption{
+		"sliding window": WithSlidingWindowKV(8, 0),
+		"compressed":     WithCompressedKV(4),
+	} {
+		gen := NewGenerator[float32](nil, buildTestTokenizer(), compute.NewCPUEngine(numeric.Float32Ops{}),
+			ModelConfig{VocabSize: 8, MaxSeqLen: 64, NumLayers: 1}, WithPromptCache(16), opt)
+		if gen.PromptCache() != nil {
+			t.Errorf("%s: PromptCache() = non-nil, want nil", name)
+		}
+	}
+}
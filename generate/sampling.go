@@ -107,12 +107,33 @@ func applyRepetitionPenalty(logits []float64, generatedTokens []int, penalty flo
 	}
 }
 
+// samplingSeedStream is the fixed PCG stream selector used for seeded
+// sampling, so a given seed yields the same token stream on every host.
+const samplingSeedStream = 0x9e3779b97f4a7c15
+
+// seedRNG resets the per-request sampler from Seed. Generation entry points
+// call it once per request so that identical requests with the same seed
+// draw identical random numbers; with no seed the global source is used.
+func (sc *SamplingConfig) seedRNG() {
+	if sc.Seed == nil {
+		sc.rng = nil
+		return
+	}
+	sc.rng = rand.New(rand.NewPCG(uint64(*sc.Seed), samplingSeedStream))
+}
+
 // sampleFromDistribution applies softmax to logits and samples a token index
-// using weighted random selection.
-func sampleFromDistribution(logits []float64) int {
+// using weighted random selection. Random numbers come from rng when non-nil
+// and from the global source otherwise.
+func sampleFromDistribution(logits []float64, rng *rand.Rand) int {
 	probs := softmax(logits)
 
-	r := rand.Float64()
+	var r float64
+	if rng != nil {
+		r = rng.Float64()
+	} else {
+		r = rand.Float64()
+	}
 	cumulative := 0.0
 	for i, p := range probs {
 		cumulative += p
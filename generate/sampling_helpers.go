@@ -73,5 +73,5 @@ func applyTemperatureAndTopP(logitsF64 []float64, sc SamplingConfig, vocabSize i
 	if sc.TopP > 0 && sc.TopP < 1.0 {
 		applyTopP(logitsF64, sc.TopP)
 	}
-	return sampleFromDistribution(logitsF64)
+	return sampleFromDistribution(logitsF64, sc.rng)
 }
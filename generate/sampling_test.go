@@ -1,8 +1,12 @@
 package generate
 
 import (
+	"context"
 	"math"
 	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
 )
 
 func TestArgmax(t *testing.T) {
@@ -200,7 +204,7 @@ func TestSampleFromDistribution(t *testing.T) {
 		// Token 1 has extremely high logit; all sampling should return 1.
 		logits := []float64{-100.0, 100.0, -100.0}
 		for range 20 {
-			got := sampleFromDistribution(logits)
+			got := sampleFromDistribution(logits, nil)
 			if got != 1 {
 				t.Errorf("sampleFromDistribution = %d, want 1", got)
 			}
@@ -210,7 +214,7 @@ func TestSampleFromDistribution(t *testing.T) {
 	t.Run("returns valid index", func(t *testing.T) {
 		logits := []float64{1.0, 1.0, 1.0, 1.0}
 		for range 50 {
-			got := sampleFromDistribution(logits)
+			got := sampleFromDistribution(logits, nil)
 			if got < 0 || got >= 4 {
 				t.Errorf("sampleFromDistribution = %d, want [0,4)", got)
 			}
@@ -219,7 +223,7 @@ func TestSampleFromDistribution(t *testing.T) {
 
 	t.Run("all negative inf returns valid index", func(t *testing.T) {
 		logits := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
-		got := sampleFromDistribution(logits)
+		got := sampleFromDistribution(logits, nil)
 		if got < 0 || got >= 3 {
 			t.Errorf("sampleFromDistribution = %d, want [0,3)", got)
 		}
@@ -236,3 +240,85 @@ func TestSoftmax_AllNegInf(t *testing.T) {
 		}
 	}
 }
+
+func TestSeedRNG_ReproducibleDraws(t *testing.T) {
+	draw := func(seed int64) []int {
+		sc := SamplingConfig{Temperature: 1.0, Seed: &seed}
+		sc.seedRNG()
+		out := make([]int, 32)
+		for i := range out {
+			logits := []float64{0, 0, 0, 0, 0, 0, 0, 0}
+			out[i] = applyTemperatureAndTopP(logits, sc, len(logits))
+		}
+		return out
+	}
+
+	a, b := draw(42), draw(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("draw %d differs for the same seed: %v vs %v", i, a, b)
+		}
+	}
+	c := draw(43)
+	same := true
+	for i := range a {
+		if a[i] != c[i] {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Errorf("different seeds produced identical draws: %v", a)
+	}
+}
+
+func TestSeedRNG_NilSeedClearsSampler(t *testing.T) {
+	seed := int64(1)
+	sc := SamplingConfig{Seed: &seed}
+	sc.seedRNG()
+	if sc.rng == nil {
+		t.Fatal("seedRNG did not create a sampler")
+	}
+	sc.Seed = nil
+	sc.seedRNG()
+	if sc.rng != nil {
+		t.Error("seedRNG should clear the sampler when Seed is nil")
+	}
+}
+
+func TestGenerate_SeedReproducibleAcrossGenerators(t *testing.T) {
+	// Token -1 yields uniform logits, so every step is a genuine random draw.
+	newGen := func() *Generator[float32] {
+		return NewGenerator[float32](
+			buildTestGraph(t, 8, []int{-1}), buildTestTokenizer(),
+			compute.NewCPUEngine(numeric.Float32Ops{}),
+			ModelConfig{VocabSize: 8, MaxSeqLen: 64, EOSTokenID: 2},
+		)
+	}
+	seed := int64(7)
+	sc := SamplingConfig{Temperature: 1.0, MaxNewTokens: 24, Seed: &seed}
+
+	first, err := newGen().Generate(context.Background(), "hello", sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := newGen()
+	for i := range 3 {
+		got, err := gen.Generate(context.Background(), "hello", sc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != first {
+			t.Fatalf("run %d = %q, want %q", i, got, first)
+		}
+	}
+
+	sess := gen.NewSession()
+	got, err := sess.Generate(context.Background(), "hello", sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != first {
+		t.Errorf("session output = %q, want %q", got, first)
+	}
+}
@@ -12,8 +12,11 @@ import (
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
 	tokenizer "github.com/zerfoo/ztoken"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/zerfoo/zerfoo/generate/grammar"
+	"github.com/zerfoo/zerfoo/internal/tracing"
 )
 
 // InferenceSession holds per-session state for independent, concurrent
@@ -33,7 +36,11 @@ type InferenceSession[T tensor.Numeric] struct {
 	stopSet      map[int]bool                                              // reusable stop-token set, cleared and repopulated each call
 	generatedIDs []int                                                     // reusable slice for generated token IDs
 	prefixCache  *PrefixCache[T]                                           // shared prefix cache for KV block reuse; nil if disabled
+	promptCache  *PromptCache[T]                                           // shared prompt cache for KV prefix reuse; nil if disabled
 	pjrtPlan     *graph.PJRTPlan[T]                                        // when non-nil, use PJRT backend; KV cache managed by PJRTPlan
+	retainCache  bool                                                      // keep the KV cache between calls; see SetRetainCache
+	retainedIDs  []int                                                     // tokens whose KV state the cache holds; nil when unknown
+	reusedTokens int                                                       // prompt tokens the last call reused from the retained cache
 }
 
 // NewSession creates a new InferenceSession with its own KV cache.
@@ -41,7 +48,10 @@ type InferenceSession[T tensor.Numeric] struct {
 // maintains independent KV cache state for isolation.
 func (gen *Generator[T]) NewSession() *InferenceSession[T] {
 	var cache CacheProvider[T]
-	if gen.compressedKVChunkSize > 0 {
+	if gen.kvWindowSize > 0 {
+		// The option guarantees a positive window and non-negative sinks.
+		cache, _ = NewSlidingWindowKVCache[T](gen.config.NumLayers, gen.kvWindowSize, gen.kvSinkTokens)
+	} else if gen.compressedKVChunkSize > 0 {
 		cache = NewCompressedKVCache[T](gen.engine, gen.config.NumLayers, 0, 0, gen.compressedKVChunkSize)
 	} else if gen.blockPool != nil {
 		cache = NewPagedKVCache[T](gen.blockPool, gen.config.NumLayers)
@@ -69,6 +79,7 @@ func (gen *Generator[T]) NewSession() *InferenceSession[T] {
 		planRef:      &gen.plan,
 		poolResetter: poolResetter,
 		prefixCache:  gen.prefixCache,
+		promptCache:  gen.promptCache,
 		pjrtPlan:     gen.pjrtPlan,
 	}
 }
@@ -78,10 +89,46 @@ func (s *InferenceSession[T]) Cache() CacheProvider[T] {
 	return s.cache
 }
 
+// SetRetainCache controls whether Generate and GenerateStream keep the
+// session's KV cache between calls. With retention on, each call keeps the
+// KV state of the longest common prefix of its prompt and the tokens the
+// previous call processed (its prompt and output), and prefills only the
+// rest, so a multi-turn chat that resends its history only processes the
+// new turn. Only the default CPU and GPU KV caches support retention; other
+// caches start fresh on every call.
+func (s *InferenceSession[T]) SetRetainCache(retain bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retainCache = retain
+	if !retain {
+		s.retainedIDs = nil
+	}
+}
+
+// CachedTokens returns the number of tokens whose KV state the session has
+// retained for the next call.
+func (s *InferenceSession[T]) CachedTokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.retainedIDs)
+}
+
+// ReusedTokens returns the number of prompt tokens the last Generate or
+// GenerateStream call took from the retained KV cache instead of
+// prefilling.
+func (s *InferenceSession[T]) ReusedTokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reusedTokens
+}
+
 // Generate produces text from a prompt using the session's own KV cache.
 // Multiple sessions can Generate concurrently without data races, though
 // calls within a single session are serialized.
-func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc SamplingConfig) (string, error) {
+func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc SamplingConfig) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "generate", "generate")
+	defer func() { tracing.End(span, err) }()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -94,6 +141,7 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 	if sc.MaxNewTokens <= 0 {
 		sc.MaxNewTokens = 256
 	}
+	sc.seedRNG()
 
 	promptIDs, err := s.tokenizer.Encode(prompt)
 	if err != nil {
@@ -106,6 +154,7 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 	if s.config.BOSTokenID > 0 {
 		promptIDs = append([]int{s.config.BOSTokenID}, promptIDs...)
 	}
+	span.SetAttributes(attribute.Int("zerfoo.prompt_tokens", len(promptIDs)))
 
 	// PJRT path: use RunPrefill/RunDecode instead of graph Forward.
 	if s.pjrtPlan != nil {
@@ -123,8 +172,9 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 		}
 	}
 
-	// Reset the cache for a fresh generation.
-	s.cache.Reset()
+	// Keep the retained KV state of the prompt's prefix, or reset the cache
+	// for a fresh generation.
+	reused := s.reuseRetained(promptIDs)
 	genCtx := WithCache(ctx, s.cache)
 
 	s.prepareStopSet(sc.StopTokenIDs)
@@ -132,10 +182,18 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 
 	s.prepareGeneratedIDs(sc.MaxNewTokens)
 	generatedIDs := s.generatedIDs[:0]
+	defer func() {
+		if err == nil {
+			s.retainProcessed(promptIDs, generatedIDs)
+		}
+	}()
 
 	// Check prefix cache for a matching KV block prefix to avoid redundant prefill.
-	prefillIDs := promptIDs
-	if s.prefixCache != nil {
+	prefillIDs := promptIDs[reused:]
+	if reused == 0 {
+		prefillIDs = s.restorePromptPrefix(promptIDs)
+	}
+	if s.prefixCache != nil && reused == 0 && len(prefillIDs) == len(promptIDs) {
 		if pagedCache, ok := s.cache.(*PagedKVCache[T]); ok {
 			promptIDs32 := intsToInt32(promptIDs)
 			cachedBlocks, matchedLen := s.prefixCache.Match(promptIDs32)
@@ -156,7 +214,7 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 		}
 
 		var fwdErr error
-		logits, fwdErr = s.graphForward(genCtx, prefillTensor, true)
+		logits, fwdErr = s.prefillForward(genCtx, prefillTensor, len(prefillIDs))
 		if fwdErr != nil {
 			return "", fmt.Errorf("prefill forward: %w", fwdErr)
 		}
@@ -170,12 +228,14 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 		}
 
 		var fwdErr error
-		logits, fwdErr = s.graphForward(genCtx, lastTensor, true)
+		logits, fwdErr = s.prefillForward(genCtx, lastTensor, 1)
 		if fwdErr != nil {
 			return "", fmt.Errorf("last-token forward: %w", fwdErr)
 		}
 	}
 
+	s.storePromptPrefix(promptIDs)
+
 	// Store the prompt's blocks in the prefix cache for future sessions.
 	if s.prefixCache != nil {
 		if pagedCache, ok := s.cache.(*PagedKVCache[T]); ok {
@@ -210,6 +270,8 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 	}
 
 	// Autoregressive decode loop.
+	endDecode := s.startDecode(genCtx, &generatedIDs)
+	defer func() { endDecode(err) }()
 	for range sc.MaxNewTokens - 1 {
 		if err := ctx.Err(); err != nil {
 			break
@@ -268,7 +330,10 @@ func (s *InferenceSession[T]) Generate(ctx context.Context, prompt string, sc Sa
 
 // GenerateStream produces text from a prompt using the session's own KV cache,
 // delivering each token to the stream as it is generated.
-func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string, sc SamplingConfig, stream TokenStream) error {
+func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string, sc SamplingConfig, stream TokenStream) (err error) {
+	ctx, span := tracing.Start(ctx, "generate", "generate", trace.WithAttributes(attribute.Bool("zerfoo.stream", true)))
+	defer func() { tracing.End(span, err) }()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -278,6 +343,7 @@ func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string,
 	if sc.MaxNewTokens <= 0 {
 		sc.MaxNewTokens = 256
 	}
+	sc.seedRNG()
 
 	promptIDs, err := s.tokenizer.Encode(prompt)
 	if err != nil {
@@ -290,6 +356,7 @@ func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string,
 	if s.config.BOSTokenID > 0 {
 		promptIDs = append([]int{s.config.BOSTokenID}, promptIDs...)
 	}
+	span.SetAttributes(attribute.Int("zerfoo.prompt_tokens", len(promptIDs)))
 
 	// PJRT path: use RunPrefill/RunDecode instead of graph Forward.
 	if s.pjrtPlan != nil {
@@ -307,7 +374,7 @@ func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string,
 		}
 	}
 
-	s.cache.Reset()
+	reused := s.reuseRetained(promptIDs)
 	genCtx := WithCache(ctx, s.cache)
 
 	s.prepareStopSet(sc.StopTokenIDs)
@@ -316,17 +383,27 @@ func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string,
 	s.prepareGeneratedIDs(sc.MaxNewTokens)
 	generatedIDs := s.generatedIDs[:0]
 	prevDecoded := ""
+	defer func() {
+		if err == nil {
+			s.retainProcessed(promptIDs, generatedIDs)
+		}
+	}()
 
 	// Prefill.
-	prefillTensor, err := s.idsToTensor(promptIDs)
+	prefillIDs := promptIDs[reused:]
+	if reused == 0 {
+		prefillIDs = s.restorePromptPrefix(promptIDs)
+	}
+	prefillTensor, err := s.idsToTensor(prefillIDs)
 	if err != nil {
 		return fmt.Errorf("create prefill tensor: %w", err)
 	}
 
-	logits, err := s.graphForward(genCtx, prefillTensor, true)
+	logits, err := s.prefillForward(genCtx, prefillTensor, len(prefillIDs))
 	if err != nil {
 		return fmt.Errorf("prefill forward: %w", err)
 	}
+	s.storePromptPrefix(promptIDs)
 
 	nextToken, err := s.sampleFromLogits(logits, sc, generatedIDs)
 	if err != nil {
@@ -351,6 +428,8 @@ func (s *InferenceSession[T]) GenerateStream(ctx context.Context, prompt string,
 		return fmt.Errorf("create decode tensor: %w", tErr)
 	}
 
+	endDecode := s.startDecode(genCtx, &generatedIDs)
+	defer func() { endDecode(err) }()
 	for range sc.MaxNewTokens - 1 {
 		if err := ctx.Err(); err != nil {
 			break
@@ -459,6 +538,100 @@ func (s *InferenceSession[T]) graphForward(ctx context.Context, input *tensor.Te
 	return result, err
 }
 
+// prefillForward runs the prefill forward pass of n prompt tokens under a
+// generate.prefill span.
+func (s *InferenceSession[T]) prefillForward(ctx context.Context, input *tensor.TensorNumeric[T], n int) (_ *tensor.TensorNumeric[T], err error) {
+	ctx, span := tracing.Start(ctx, "generate", "generate.prefill", trace.WithAttributes(
+		attribute.Int("zerfoo.prefill_tokens", n),
+		attribute.Int("zerfoo.reused_tokens", s.reusedTokens),
+	))
+	defer func() { tracing.End(span, err) }()
+	return s.graphForward(ctx, input, true)
+}
+
+// startDecode starts a generate.decode span covering the decode loop. The
+// returned func ends it, recording the number of tokens generated.
+func (s *InferenceSession[T]) startDecode(ctx context.Context, generatedIDs *[]int) func(error) {
+	_, span := tracing.Start(ctx, "generate", "generate.decode")
+	return func(err error) {
+		span.SetAttributes(attribute.Int("zerfoo.completion_tokens", len(*generatedIDs)))
+		tracing.End(span, err)
+	}
+}
+
+// reuseRetained prepares the KV cache for promptIDs. With cache retention
+// on, it truncates the cache to the longest common prefix of promptIDs and
+// the retained tokens, leaving at least one prompt token to prefill, and
+// returns the prefix length. Otherwise, or when nothing matches, it resets
+// the cache and returns 0. The retained tokens are forgotten until the call
+// succeeds.
+func (s *InferenceSession[T]) reuseRetained(promptIDs []int) int {
+	retained := s.retainedIDs
+	s.retainedIDs = nil
+	s.reusedTokens = 0
+	if !s.retainCache || !s.cacheRetainable() {
+		s.cache.Reset()
+		return 0
+	}
+
+	n := 0
+	limit := min(len(retained), len(promptIDs)-1, s.cache.SeqLen())
+	for n < limit && retained[n] == promptIDs[n] {
+		n++
+	}
+	if n == 0 {
+		s.cache.Reset()
+		return 0
+	}
+	s.cache.Truncate(n)
+	s.reusedTokens = n
+	return n
+}
+
+// retainProcessed records the tokens whose KV state the cache holds after a
+// successful call: the prompt followed by the generated tokens that were fed
+// back through the graph.
+func (s *InferenceSession[T]) retainProcessed(promptIDs, generatedIDs []int) {
+	if !s.retainCache || !s.cacheRetainable() {
+		return
+	}
+	n := s.cache.SeqLen()
+	if n > len(promptIDs)+len(generatedIDs) {
+		return
+	}
+	ids := make([]int, 0, len(promptIDs)+len(generatedIDs))
+	ids = append(ids, promptIDs...)
+	ids = append(ids, generatedIDs...)
+	s.retainedIDs = ids[:n]
+}
+
+// cacheRetainable reports whether the session's cache can be truncated to a
+// prefix of the sequence it holds.
+func (s *InferenceSession[T]) cacheRetainable() bool {
+	switch s.cache.(type) {
+	case *KVCache[T], *TensorCache[T]:
+		return true
+	}
+	return false
+}
+
+// restorePromptPrefix loads the longest cached prefix of promptIDs into the
+// session's KV cache and returns the tokens that still need prefilling. It
+// returns promptIDs unchanged when prompt caching is disabled.
+func (s *InferenceSession[T]) restorePromptPrefix(promptIDs []int) []int {
+	if s.promptCache == nil {
+		return promptIDs
+	}
+	return promptIDs[s.promptCache.Restore(s.cache, promptIDs):]
+}
+
+// storePromptPrefix caches the prompt's KV blocks after prefill.
+func (s *InferenceSession[T]) storePromptPrefix(promptIDs []int) {
+	if s.promptCache != nil {
+		s.promptCache.Insert(s.cache, promptIDs)
+	}
+}
+
 // idsToTensor converts token IDs to a [1, seqLen] input tensor.
 func (s *InferenceSession[T]) idsToTensor(ids []int) (*tensor.TensorNumeric[T], error) {
 	data := make([]T, len(ids))
@@ -2,10 +2,13 @@ package generate
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
 )
 
@@ -397,3 +400,65 @@ func TestSession_GeneratorGenerateStillWorks(t *testing.T) {
 	}
 }
 
+func TestSession_RetainCacheSkipsSharedPrefix(t *testing.T) {
+	eng := compute.NewCPUEngine(numeric.Float32Ops{})
+	b := graph.NewBuilder[float32](eng)
+	in := b.Input([]int{1, 1})
+	node := &kvRecorderNode{vocabSize: 8}
+	b.AddNode(node, in)
+	g, err := b.Build(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator[float32](g, buildTestTokenizer(), eng, ModelConfig{
+		VocabSize:  8,
+		MaxSeqLen:  64,
+		EOSTokenID: 2,
+		NumLayers:  1,
+	})
+	sess := gen.NewSession()
+	sess.SetRetainCache(true)
+
+	turn := strings.TrimSpace(strings.Repeat("hello world ", 5)) // 10 tokens
+	sc := SamplingConfig{MaxNewTokens: 4}
+	ctx := context.Background()
+
+	if _, err := sess.Generate(ctx, turn, sc); err != nil {
+		t.Fatal(err)
+	}
+	if got := sess.CachedTokens(); got != 10 {
+		t.Fatalf("CachedTokens = %d, want 10", got)
+	}
+	// The second turn resends the first and adds three tokens.
+	if err := sess.GenerateStream(ctx, turn+" hello world hello", sc,
+		TokenStreamFunc(func(string, bool) error { return nil })); err != nil {
+		t.Fatal(err)
+	}
+	if got := sess.ReusedTokens(); got != 10 {
+		t.Errorf("ReusedTokens = %d, want 10", got)
+	}
+	// A prompt that diverges at the first token prefills from scratch.
+	if _, err := sess.Generate(ctx, "world "+turn, sc); err != nil {
+		t.Fatal(err)
+	}
+	if got := sess.ReusedTokens(); got != 0 {
+		t.Errorf("ReusedTokens = %d after divergent prompt, want 0", got)
+	}
+	// Repeating a prompt still prefills its last token.
+	if _, err := sess.Generate(ctx, "world "+turn, sc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(node.seqLens, []int{10, 3, 11, 1}) {
+		t.Errorf("prefill lengths = %v, want [10 3 11 1]", node.seqLens)
+	}
+	if got := sess.Cache().SeqLen(); got != 11 {
+		t.Errorf("cache SeqLen = %d, want 11", got)
+	}
+
+	sess.SetRetainCache(false)
+	if got := sess.CachedTokens(); got != 0 {
+		t.Errorf("CachedTokens = %d after disabling retention, want 0", got)
+	}
+}
@@ -0,0 +1,288 @@
+package generate
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// CacheSizeReporter is implemented by cache providers that can report how
+// many positions they retain and how much memory their buffers occupy.
+// Servers use it to enforce and expose per-session memory caps.
+type CacheSizeReporter interface {
+	// CachedLen returns the number of sequence positions currently held.
+	CachedLen() int
+	// MemoryBytes returns the size of the allocated key and value buffers.
+	MemoryBytes() int64
+}
+
+// windowLayerBuf holds the backing buffers for one layer of a
+// SlidingWindowKVCache. Each batch element owns a region of capacity*dim
+// elements; the first length positions of every region are valid.
+type windowLayerBuf[T tensor.Numeric] struct {
+	keyBuf   []T // [batch * capacity * dim]
+	valBuf   []T // [batch * capacity * dim]
+	capacity int // positions per batch region
+	length   int // positions currently retained
+	seen     int // positions appended since the last Reset
+	evicted  int // positions dropped since the last Reset
+	batch    int // detected on first Update
+	dim      int // detected on first Update
+}
+
+// SlidingWindowKVCache is a bounded KV cache that keeps the first sinkTokens
+// positions of the sequence (attention sinks) plus the most recent
+// windowSize positions, evicting everything in between. Memory is capped at
+// (sinkTokens + windowSize) positions per layer regardless of how long the
+// session runs, which makes it suitable for unbounded chat sessions.
+//
+// Keys are cached after RoPE has been applied, so SeqLen reports the absolute
+// number of positions processed rather than the number retained; new tokens
+// keep receiving their true positions. Use CachedLen for the retained count.
+//
+// When a single Update carries more than windowSize positions (a long
+// prefill chunk), the whole chunk is retained until the next Update so the
+// chunk can attend to itself under a causal mask.
+type SlidingWindowKVCache[T tensor.Numeric] struct {
+	layers     []windowLayerBuf[T]
+	windowSize int
+	sinkTokens int
+}
+
+// NewSlidingWindowKVCache creates a SlidingWindowKVCache for numLayers layers
+// that retains sinkTokens leading positions and the last windowSize
+// positions. windowSize must be positive and sinkTokens non-negative.
+func NewSlidingWindowKVCache[T tensor.Numeric](numLayers, windowSize, sinkTokens int) (*SlidingWindowKVCache[T], error) {
+	if windowSize <= 0 {
+		return nil, fmt.Errorf("window size must be positive, got %d", windowSize)
+	}
+	if sinkTokens < 0 {
+		return nil, fmt.Errorf("sink tokens must be non-negative, got %d", sinkTokens)
+	}
+	return &SlidingWindowKVCache[T]{
+		layers:     make([]windowLayerBuf[T], numLayers),
+		windowSize: windowSize,
+		sinkTokens: sinkTokens,
+	}, nil
+}
+
+// NumLayers returns the number of layers in the cache.
+func (c *SlidingWindowKVCache[T]) NumLayers() int {
+	return len(c.layers)
+}
+
+// WindowSize returns the number of recent positions retained.
+func (c *SlidingWindowKVCache[T]) WindowSize() int {
+	return c.windowSize
+}
+
+// SinkTokens returns the number of leading positions that are never evicted.
+func (c *SlidingWindowKVCache[T]) SinkTokens() int {
+	return c.sinkTokens
+}
+
+// Update appends new key and value tensors of shape [batch, seq_len, dim]
+// for the given layer, evicting the oldest non-sink positions that fall
+// outside the window.
+func (c *SlidingWindowKVCache[T]) Update(layer int, newK, newV *tensor.TensorNumeric[T]) error {
+	if layer < 0 || layer >= len(c.layers) {
+		return fmt.Errorf("layer index %d out of range [0, %d)", layer, len(c.layers))
+	}
+
+	shape := newK.Shape()
+	if len(shape) != 3 {
+		return fmt.Errorf("expected 3D tensor [batch, seq, dim], got %dD", len(shape))
+	}
+	batch, seqLen, dim := shape[0], shape[1], shape[2]
+
+	lb := &c.layers[layer]
+	if lb.keyBuf == nil {
+		lb.batch = batch
+		lb.dim = dim
+	}
+	if batch != lb.batch {
+		return fmt.Errorf("batch mismatch: cache has %d, got %d", lb.batch, batch)
+	}
+	if dim != lb.dim {
+		return fmt.Errorf("dim mismatch: cache has %d, got %d", lb.dim, dim)
+	}
+
+	// Treat the retained buffer followed by the new chunk as one stream and
+	// keep its first keepHead and last keepTail positions. The retained
+	// buffer always starts with the sequence's leading positions because
+	// sinks are never evicted, so this preserves the true attention sinks.
+	total := lb.length + seqLen
+	window := max(c.windowSize, seqLen)
+	keepHead := min(c.sinkTokens, total)
+	keepTail := min(window, total-keepHead)
+	kept := keepHead + keepTail
+	drop := total - kept
+
+	if kept > lb.capacity {
+		lb.grow(max(kept, c.sinkTokens+c.windowSize))
+	}
+
+	stride := lb.capacity * dim
+	kData := newK.Data()
+	vData := newV.Data()
+	for bi := range batch {
+		keys := lb.keyBuf[bi*stride : (bi+1)*stride]
+		vals := lb.valBuf[bi*stride : (bi+1)*stride]
+
+		// Shift surviving retained positions down over the evicted range.
+		if src := keepHead + drop; drop > 0 && src < lb.length {
+			copy(keys[keepHead*dim:], keys[src*dim:lb.length*dim])
+			copy(vals[keepHead*dim:], vals[src*dim:lb.length*dim])
+		}
+
+		// Place the new positions, skipping any that fall in the evicted range.
+		srcOff := bi * seqLen * dim
+		for i := range seqLen {
+			pos := lb.length + i
+			if pos >= keepHead {
+				if pos < keepHead+drop {
+					continue
+				}
+				pos -= drop
+			}
+			src := srcOff + i*dim
+			copy(keys[pos*dim:(pos+1)*dim], kData[src:src+dim])
+			copy(vals[pos*dim:(pos+1)*dim], vData[src:src+dim])
+		}
+	}
+
+	lb.length = kept
+	lb.seen += seqLen
+	lb.evicted += drop
+	return nil
+}
+
+// grow reallocates the layer buffers to hold capacity positions per batch
+// element, preserving the retained data.
+func (lb *windowLayerBuf[T]) grow(capacity int) {
+	keyBuf := make([]T, lb.batch*capacity*lb.dim)
+	valBuf := make([]T, lb.batch*capacity*lb.dim)
+	n := lb.length * lb.dim
+	for bi := range lb.batch {
+		src := bi * lb.capacity * lb.dim
+		dst := bi * capacity * lb.dim
+		copy(keyBuf[dst:dst+n], lb.keyBuf[src:src+n])
+		copy(valBuf[dst:dst+n], lb.valBuf[src:src+n])
+	}
+	lb.keyBuf = keyBuf
+	lb.valBuf = valBuf
+	lb.capacity = capacity
+}
+
+// Get returns the retained key-value pair for the given layer with shape
+// [batch, CachedLen, dim]: sink positions first, then the recent window in
+// order. For batch=1, the returned tensors are zero-copy views.
+// Returns false if the layer has not been populated yet.
+func (c *SlidingWindowKVCache[T]) Get(layer int) (*LayerKV[T], bool) {
+	if layer < 0 || layer >= len(c.layers) {
+		return nil, false
+	}
+	lb := &c.layers[layer]
+	if lb.length == 0 {
+		return nil, false
+	}
+
+	shape := []int{lb.batch, lb.length, lb.dim}
+	size := lb.batch * lb.length * lb.dim
+
+	var keyData, valData []T
+	if lb.batch == 1 || lb.length == lb.capacity {
+		keyData = lb.keyBuf[:size]
+		valData = lb.valBuf[:size]
+	} else {
+		keyData = make([]T, size)
+		valData = make([]T, size)
+		seqDim := lb.length * lb.dim
+		for bi := range lb.batch {
+			srcOff := bi * lb.capacity * lb.dim
+			dstOff := bi * seqDim
+			copy(keyData[dstOff:dstOff+seqDim], lb.keyBuf[srcOff:srcOff+seqDim])
+			copy(valData[dstOff:dstOff+seqDim], lb.valBuf[srcOff:srcOff+seqDim])
+		}
+	}
+
+	keyT, err := tensor.New(shape, keyData)
+	if err != nil {
+		return nil, false
+	}
+	valT, err := tensor.New(shape, valData)
+	if err != nil {
+		return nil, false
+	}
+	return &LayerKV[T]{Key: keyT, Value: valT}, true
+}
+
+// SeqLen returns the number of positions processed since the last Reset,
+// including evicted ones. Attention layers use it as the position offset
+// for the next token.
+func (c *SlidingWindowKVCache[T]) SeqLen() int {
+	if len(c.layers) == 0 {
+		return 0
+	}
+	return c.layers[0].seen
+}
+
+// CachedLen returns the number of positions currently retained in layer 0.
+func (c *SlidingWindowKVCache[T]) CachedLen() int {
+	if len(c.layers) == 0 {
+		return 0
+	}
+	return c.layers[0].length
+}
+
+// Evicted returns the number of positions dropped from layer 0 since the
+// last Reset.
+func (c *SlidingWindowKVCache[T]) Evicted() int {
+	if len(c.layers) == 0 {
+		return 0
+	}
+	return c.layers[0].evicted
+}
+
+// MemoryBytes returns the total size of the allocated key and value buffers
+// across all layers.
+func (c *SlidingWindowKVCache[T]) MemoryBytes() int64 {
+	var zero T
+	elemSize := int64(unsafe.Sizeof(zero))
+	var total int64
+	for i := range c.layers {
+		total += int64(len(c.layers[i].keyBuf)+len(c.layers[i].valBuf)) * elemSize
+	}
+	return total
+}
+
+// Reset clears all cached data and counters. Buffers are retained for reuse.
+func (c *SlidingWindowKVCache[T]) Reset() {
+	for i := range c.layers {
+		lb := &c.layers[i]
+		lb.length = 0
+		lb.seen = 0
+		lb.evicted = 0
+	}
+}
+
+// Truncate rolls the cache back to newSeqLen processed positions by
+// dropping the most recent retained positions. Positions that were already
+// evicted cannot be restored, so rolling back further than the retained
+// window leaves only the sinks. If newSeqLen >= SeqLen, this is a no-op.
+func (c *SlidingWindowKVCache[T]) Truncate(newSeqLen int) {
+	if newSeqLen < 0 {
+		newSeqLen = 0
+	}
+	for i := range c.layers {
+		lb := &c.layers[i]
+		if lb.seen <= newSeqLen {
+			continue
+		}
+		remove := lb.seen - newSeqLen
+		floor := min(lb.length, c.sinkTokens, newSeqLen)
+		lb.length = max(lb.length-remove, floor)
+		lb.seen = newSeqLen
+	}
+}
@@ -0,0 +1,311 @@
+package generate
+
+import (
+	"testing"
+)
+
+// seqTensor builds a [batch, seq, dim] tensor whose every element at
+// sequence position p (counting from start) in batch b equals
+// 1000*b + p, so tests can read back which positions were retained.
+func seqTensor(t *testing.T, batch, start, seq, dim int) []float32 {
+	t.Helper()
+	data := make([]float32, batch*seq*dim)
+	for b := range batch {
+		for s := range seq {
+			for d := range dim {
+				data[(b*seq+s)*dim+d] = float32(1000*b + start + s)
+			}
+		}
+	}
+	return data
+}
+
+// retainedPositions returns the position tag of each cached key for batch b.
+func retainedPositions(t *testing.T, c *SlidingWindowKVCache[float32], layer, b int) []int {
+	t.Helper()
+	lkv, ok := c.Get(layer)
+	if !ok {
+		t.Fatalf("Get(%d) returned false", layer)
+	}
+	shape := lkv.Key.Shape()
+	seq, dim := shape[1], shape[2]
+	data := lkv.Key.Data()
+	vals := lkv.Value.Data()
+	out := make([]int, seq)
+	for s := range seq {
+		off := (b*seq + s) * dim
+		if data[off] != vals[off] {
+			t.Fatalf("key/value mismatch at position %d: %v vs %v", s, data[off], vals[off])
+		}
+		out[s] = int(data[off]) - 1000*b
+	}
+	return out
+}
+
+func appendTokens(t *testing.T, c *SlidingWindowKVCache[float32], batch, start, seq, dim int) {
+	t.Helper()
+	data := seqTensor(t, batch, start, seq, dim)
+	k := makeTensor(t, []int{batch, seq, dim}, data)
+	v := makeTensor(t, []int{batch, seq, dim}, append([]float32(nil), data...))
+	for layer := range c.NumLayers() {
+		if err := c.Update(layer, k, v); err != nil {
+			t.Fatalf("Update(%d) error: %v", layer, err)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNewSlidingWindowKVCache_Validation(t *testing.T) {
+	if _, err := NewSlidingWindowKVCache[float32](2, 0, 0); err == nil {
+		t.Error("expected error for zero window")
+	}
+	if _, err := NewSlidingWindowKVCache[float32](2, 8, -1); err == nil {
+		t.Error("expected error for negative sinks")
+	}
+	c, err := NewSlidingWindowKVCache[float32](3, 8, 2)
+	if err != nil {
+		t.Fatalf("NewSlidingWindowKVCache error: %v", err)
+	}
+	if c.NumLayers() != 3 || c.WindowSize() != 8 || c.SinkTokens() != 2 {
+		t.Errorf("got layers=%d window=%d sinks=%d", c.NumLayers(), c.WindowSize(), c.SinkTokens())
+	}
+}
+
+func TestSlidingWindowKVCache_InterfaceCompliance(t *testing.T) {
+	var _ CacheProvider[float32] = (*SlidingWindowKVCache[float32])(nil)
+	var _ CacheSizeReporter = (*SlidingWindowKVCache[float32])(nil)
+}
+
+func TestSlidingWindowKVCache_DecodeEviction(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](2, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := range 10 {
+		appendTokens(t, c, 1, pos, 1, 3)
+	}
+
+	if got := c.SeqLen(); got != 10 {
+		t.Errorf("SeqLen() = %d, want 10", got)
+	}
+	if got := c.CachedLen(); got != 6 {
+		t.Errorf("CachedLen() = %d, want 6", got)
+	}
+	if got := c.Evicted(); got != 4 {
+		t.Errorf("Evicted() = %d, want 4", got)
+	}
+	for layer := range 2 {
+		want := []int{0, 1, 6, 7, 8, 9}
+		if got := retainedPositions(t, c, layer, 0); !equalInts(got, want) {
+			t.Errorf("layer %d positions = %v, want %v", layer, got, want)
+		}
+	}
+}
+
+func TestSlidingWindowKVCache_NoSinks(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendTokens(t, c, 1, 0, 2, 2)
+	appendTokens(t, c, 1, 2, 2, 2)
+	appendTokens(t, c, 1, 4, 1, 2)
+
+	want := []int{2, 3, 4}
+	if got := retainedPositions(t, c, 0, 0); !equalInts(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+}
+
+func TestSlidingWindowKVCache_LongPrefillRetainedUntilNextUpdate(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A prefill chunk longer than the window must be visible in full so the
+	// chunk can attend causally to itself.
+	appendTokens(t, c, 1, 0, 10, 2)
+	want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if got := retainedPositions(t, c, 0, 0); !equalInts(got, want) {
+		t.Errorf("after prefill positions = %v, want %v", got, want)
+	}
+
+	// The next decode step shrinks back to sinks + window.
+	appendTokens(t, c, 1, 10, 1, 2)
+	want = []int{0, 1, 7, 8, 9, 10}
+	if got := retainedPositions(t, c, 0, 0); !equalInts(got, want) {
+		t.Errorf("after decode positions = %v, want %v", got, want)
+	}
+	if got := c.SeqLen(); got != 11 {
+		t.Errorf("SeqLen() = %d, want 11", got)
+	}
+}
+
+func TestSlidingWindowKVCache_SinksFilledAcrossUpdates(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendTokens(t, c, 1, 0, 1, 1)
+	appendTokens(t, c, 1, 1, 5, 1)
+	appendTokens(t, c, 1, 6, 1, 1)
+
+	want := []int{0, 1, 2, 5, 6}
+	if got := retainedPositions(t, c, 0, 0); !equalInts(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+}
+
+func TestSlidingWindowKVCache_Batch(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := range 5 {
+		appendTokens(t, c, 2, pos, 1, 2)
+	}
+	want := []int{0, 3, 4}
+	for b := range 2 {
+		if got := retainedPositions(t, c, 0, b); !equalInts(got, want) {
+			t.Errorf("batch %d positions = %v, want %v", b, got, want)
+		}
+	}
+}
+
+func TestSlidingWindowKVCache_MemoryBounded(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](2, 8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendTokens(t, c, 1, 0, 1, 16)
+	want := c.MemoryBytes()
+	// 2 layers * (K+V) * 12 positions * 16 dims * 4 bytes.
+	if want != 2*2*12*16*4 {
+		t.Errorf("MemoryBytes() = %d, want %d", want, 2*2*12*16*4)
+	}
+	for pos := 1; pos < 500; pos++ {
+		appendTokens(t, c, 1, pos, 1, 16)
+	}
+	if got := c.MemoryBytes(); got != want {
+		t.Errorf("MemoryBytes() grew to %d, want %d", got, want)
+	}
+	if got := c.CachedLen(); got != 12 {
+		t.Errorf("CachedLen() = %d, want 12", got)
+	}
+}
+
+func TestSlidingWindowKVCache_Truncate(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := range 8 {
+		appendTokens(t, c, 1, pos, 1, 1)
+	}
+	// Retained: 0 1 4 5 6 7. Roll back the last two positions.
+	c.Truncate(6)
+	if got := c.SeqLen(); got != 6 {
+		t.Errorf("SeqLen() = %d, want 6", got)
+	}
+	want := []int{0, 1, 4, 5}
+	if got := retainedPositions(t, c, 0, 0); !equalInts(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+
+	// Rolling back past the evicted range keeps only the sinks.
+	c.Truncate(3)
+	want = []int{0, 1}
+	if got := retainedPositions(t, c, 0, 0); !equalInts(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+
+	// No-op when newSeqLen >= SeqLen.
+	c.Truncate(10)
+	if got := c.SeqLen(); got != 3 {
+		t.Errorf("SeqLen() = %d, want 3", got)
+	}
+}
+
+func TestSlidingWindowKVCache_Reset(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := range 5 {
+		appendTokens(t, c, 1, pos, 1, 1)
+	}
+	c.Reset()
+	if c.SeqLen() != 0 || c.CachedLen() != 0 || c.Evicted() != 0 {
+		t.Errorf("after Reset: SeqLen=%d CachedLen=%d Evicted=%d", c.SeqLen(), c.CachedLen(), c.Evicted())
+	}
+	if _, ok := c.Get(0); ok {
+		t.Error("Get(0) should return false after Reset")
+	}
+}
+
+func TestSlidingWindowKVCache_Errors(t *testing.T) {
+	c, err := NewSlidingWindowKVCache[float32](1, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := makeTensor(t, []int{1, 1, 2}, []float32{1, 2})
+	if err := c.Update(1, k, k); err == nil {
+		t.Error("expected out-of-range layer error")
+	}
+	if err := c.Update(0, makeTensor(t, []int{2}, []float32{1, 2}), k); err == nil {
+		t.Error("expected rank error")
+	}
+	if err := c.Update(0, k, k); err != nil {
+		t.Fatal(err)
+	}
+	k3 := makeTensor(t, []int{1, 1, 3}, []float32{1, 2, 3})
+	if err := c.Update(0, k3, k3); err == nil {
+		t.Error("expected dim mismatch error")
+	}
+	kb := makeTensor(t, []int{2, 1, 2}, []float32{1, 2, 3, 4})
+	if err := c.Update(0, kb, kb); err == nil {
+		t.Error("expected batch mismatch error")
+	}
+}
+
+func TestWithSlidingWindowKV_SelectsCache(t *testing.T) {
+	cfg := ModelConfig{
+		VocabSize:  100,
+		MaxSeqLen:  512,
+		EOSTokenID: 2,
+		NumLayers:  4,
+	}
+	eng := newTestEngine()
+	gen := NewGenerator[float32](nil, nil, eng, cfg, WithSlidingWindowKV(64, 4), WithCompressedKV(16))
+
+	cache, store, err := gen.selectCacheProvider()
+	if err != nil {
+		t.Fatalf("selectCacheProvider error: %v", err)
+	}
+	if store != nil {
+		t.Error("unexpected tiered store")
+	}
+	wc, ok := cache.(*SlidingWindowKVCache[float32])
+	if !ok {
+		t.Fatalf("cache type = %T, want *SlidingWindowKVCache", cache)
+	}
+	if wc.WindowSize() != 64 || wc.SinkTokens() != 4 || wc.NumLayers() != 4 {
+		t.Errorf("got window=%d sinks=%d layers=%d", wc.WindowSize(), wc.SinkTokens(), wc.NumLayers())
+	}
+
+	if _, ok := gen.NewSession().Cache().(*SlidingWindowKVCache[float32]); !ok {
+		t.Error("session cache should be a SlidingWindowKVCache")
+	}
+}
@@ -36,6 +36,7 @@ func (gen *Generator[T]) GenerateStream(ctx context.Context, prompt string, sc S
 	if sc.MaxNewTokens <= 0 {
 		sc.MaxNewTokens = 256
 	}
+	sc.seedRNG()
 
 	promptIDs, err := gen.tokenizer.Encode(prompt)
 	if err != nil {
@@ -0,0 +1,49 @@
+package generate
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSession_GenerateSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	vocabSize := 8
+	gen := NewGenerator[float32](
+		buildTestGraph(t, vocabSize, []int{6}), buildTestTokenizer(),
+		compute.NewCPUEngine(numeric.Float32Ops{}),
+		ModelConfig{VocabSize: vocabSize, MaxSeqLen: 32, EOSTokenID: 2},
+	)
+	if _, err := gen.NewSession().Generate(context.Background(), "hello", SamplingConfig{MaxNewTokens: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := rec.Ended()
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name())
+	}
+	if want := []string{"generate.prefill", "generate.decode", "generate"}; !slices.Equal(names, want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+	root := spans[2].SpanContext().SpanID()
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != root {
+			t.Errorf("%s is not a child of generate", s.Name())
+		}
+	}
+	if !slices.Contains(spans[1].Attributes(), attribute.Int("zerfoo.completion_tokens", 3)) {
+		t.Errorf("decode attributes = %v, want 3 completion tokens", spans[1].Attributes())
+	}
+}
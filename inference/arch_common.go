@@ -85,6 +85,7 @@ type transformerGraphOpts struct {
 	postNorm            bool           // if true, apply post-attention and post-FFN norms (Gemma 3)
 	qkNorm              bool           // if true, apply RMSNorm to Q/K after projection (Gemma 3)
 	logitSoftcap        float32        // if > 0, apply logit softcapping: cap * tanh(logit/cap)
+	attnLogitSoftcap    float32        // if > 0, soft-cap attention scores: cap * tanh(score/cap) (Gemma 2)
 	slidingWindowSize   int            // if > 0, apply causal sliding window attention mask
 	attnBias            bool           // if true, add bias to Q/K/V projections (Qwen 2)
 	partialRotaryFactor float32        // fraction of head dims to apply RoPE (0 or 1 = full RoPE)
@@ -333,6 +334,9 @@ func buildTransformerGraph(
 			if opts.slidingWindowSize > 0 {
 				gqa.SlidingWindowSize = opts.slidingWindowSize
 			}
+			if opts.attnLogitSoftcap > 0 {
+				gqa.SetLogitSoftcap(float64(opts.attnLogitSoftcap))
+			}
 
 			// Create merged QKV weight for single-GEMV decode optimization.
 			// Concatenates Q, K, V Q4 blocks row-wise so a single GEMV replaces
@@ -36,7 +36,8 @@ func buildGemmaGraph(
 	// Gemma scales embeddings by sqrt(hidden_size).
 	scale := float32(math.Sqrt(float64(cfg.HiddenSize)))
 	opts := transformerGraphOpts{
-		embedScale: scale,
+		embedScale:       scale,
+		attnLogitSoftcap: cfg.AttnLogitSoftcap,
 	}
 	// Gemma 3 has post-attention/post-FFN norms, Q/K norms, and logit softcapping.
 	if cfg.Architecture == "gemma3" {
@@ -10,6 +10,7 @@ import (
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+	"github.com/zerfoo/zerfoo/internal/kernels"
 	"github.com/zerfoo/zerfoo/layers/normalization"
 	"github.com/zerfoo/zerfoo/model/gguf"
 )
@@ -849,9 +850,7 @@ func (n *rwkvChannelMixNode[T]) Forward(_ context.Context, inputs ...*tensor.Ten
 	// Output: sigmoid(r) * v.
 	vVec := vMat.Data()
 	out := make([]T, batch*seqLen*H)
-	for i := range out {
-		out[i] = n.ops.Mul(rVec[i], vVec[i])
-	}
+	kernels.MulOps(n.ops, out, rVec, vVec)
 
 	return tensor.New([]int{batch, seqLen, H}, out)
 }
@@ -308,3 +308,23 @@ func TestGenerateBatch_ConcurrentSessions(t *testing.T) {
 		t.Errorf("peak concurrent sessions = %d, want >= 2 (sessions should run concurrently)", p)
 	}
 }
+
+func TestGenerateBatch_BatchDecode(t *testing.T) {
+	m := buildTestModel(t, 8, []int{6, 2})
+	m.generator = generate.NewGenerator(m.generator.Graph(), m.tokenizer, m.engine, m.generator.Config(),
+		generate.WithMaxBatchSize(4))
+
+	// Prompts of different lengths land in separate batch groups.
+	results, err := m.GenerateBatch(context.Background(), []string{"hello", "foo bar"}, WithTemperature(0), WithMaxTokens(10))
+	if err != nil {
+		t.Fatalf("GenerateBatch: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for i, r := range results {
+		if r != "foo" {
+			t.Errorf("results[%d] = %q, want %q", i, r, "foo")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package inference
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/generate"
+)
+
+// ChatSession is a multi-turn chat that keeps its conversation and its KV
+// cache between turns. Each turn renders the whole conversation with the
+// model's chat template, but only the tokens past the part the cache
+// already holds are prefilled, so long conversations do not reprocess
+// their history on every turn. Turns are serialized; a ChatSession is safe
+// for concurrent use.
+type ChatSession struct {
+	m    *Model
+	conv *Conversation
+
+	mu   sync.Mutex // serializes turns
+	sess *generate.InferenceSession[float32]
+}
+
+// NewChatSession starts a chat session with an optional system prompt. The
+// session owns a dedicated KV cache, which is released when the session is
+// garbage collected.
+func (m *Model) NewChatSession(system string, opts ...ConversationOption) *ChatSession {
+	sess := m.generator.NewSession()
+	sess.SetRetainCache(true)
+	return &ChatSession{
+		m:    m,
+		conv: NewConversation(system, opts...),
+		sess: sess,
+	}
+}
+
+// Conversation returns the session's conversation.
+func (cs *ChatSession) Conversation() *Conversation { return cs.conv }
+
+// CachedTokens returns the number of tokens whose KV state the session has
+// retained for its next turn. It waits for a turn in progress, so it must
+// not be called from a SendStream handler.
+func (cs *ChatSession) CachedTokens() int { return cs.sess.CachedTokens() }
+
+// ReusedTokens returns the number of prompt tokens the last turn took from
+// the retained KV cache instead of prefilling. Like CachedTokens, it waits
+// for a turn in progress.
+func (cs *ChatSession) ReusedTokens() int { return cs.sess.ReusedTokens() }
+
+// Send appends msgs to the conversation, generates a reply, and records it
+// as an assistant turn. On error the conversation is left unchanged.
+func (cs *ChatSession) Send(ctx context.Context, msgs []Message, opts ...GenerateOption) (Response, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	prompt, err := cs.render(msgs)
+	if err != nil {
+		return Response{}, err
+	}
+	result, err := cs.sess.Generate(ctx, prompt, buildSamplingConfig(opts))
+	if err != nil {
+		return Response{}, err
+	}
+	cs.commit(msgs, result)
+	return cs.m.chatResponse(prompt, result), nil
+}
+
+// SendStream is the streaming counterpart of Send: it delivers the reply
+// token by token to handler and records the full reply once the stream
+// completes.
+func (cs *ChatSession) SendStream(ctx context.Context, msgs []Message, handler generate.TokenStream, opts ...GenerateOption) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	prompt, err := cs.render(msgs)
+	if err != nil {
+		return err
+	}
+	var reply strings.Builder
+	err = cs.sess.GenerateStream(ctx, prompt, buildSamplingConfig(opts), generate.TokenStreamFunc(func(token string, done bool) error {
+		reply.WriteString(token)
+		return handler.OnToken(token, done)
+	}))
+	if err != nil {
+		return err
+	}
+	cs.commit(msgs, reply.String())
+	return nil
+}
+
+// render renders the conversation followed by msgs.
+func (cs *ChatSession) render(msgs []Message) (string, error) {
+	return cs.m.RenderChat(append(cs.conv.Messages(), msgs...))
+}
+
+// commit records msgs and the assistant's reply.
+func (cs *ChatSession) commit(msgs []Message, reply string) {
+	for _, msg := range msgs {
+		cs.conv.Add(msg)
+	}
+	cs.conv.AddAssistant(reply)
+}
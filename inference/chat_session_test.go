@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/generate"
+)
+
+func TestChatSession_RecordsTurns(t *testing.T) {
+	m := buildTestModel(t, 8, []int{6, 7, 2})
+	cs := m.NewChatSession("be brief")
+
+	resp, err := cs.Send(context.Background(), []Message{{Role: "user", Content: "hello"}},
+		WithTemperature(0), WithMaxTokens(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "foo bar" {
+		t.Errorf("Send content = %q, want %q", resp.Content, "foo bar")
+	}
+
+	var streamed string
+	err = cs.SendStream(context.Background(), []Message{{Role: "user", Content: "world"}},
+		generate.TokenStreamFunc(func(token string, _ bool) error {
+			streamed += token
+			return nil
+		}), WithTemperature(0), WithMaxTokens(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := cs.Conversation().Messages()
+	want := []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: "foo bar"},
+		{Role: "user", Content: "world"},
+		{Role: "assistant", Content: streamed},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("conversation = %v, want %v", msgs, want)
+	}
+	for i := range want {
+		if msgs[i].Role != want[i].Role || msgs[i].Content != want[i].Content {
+			t.Errorf("message %d = %+v, want %+v", i, msgs[i], want[i])
+		}
+	}
+}
+
+func TestChatSession_FailedTurnLeavesConversation(t *testing.T) {
+	m := buildTestModel(t, 8, []int{6, 7, 2})
+	cs := m.NewChatSession("")
+
+	boom := errors.New("client gone")
+	err := cs.SendStream(context.Background(), []Message{{Role: "user", Content: "hello"}},
+		generate.TokenStreamFunc(func(string, bool) error { return boom }),
+		WithTemperature(0), WithMaxTokens(10))
+	if !errors.Is(err, boom) {
+		t.Fatalf("SendStream error = %v, want %v", err, boom)
+	}
+	if n := cs.Conversation().Len(); n != 0 {
+		t.Errorf("conversation has %d messages after a failed turn, want 0", n)
+	}
+}
@@ -0,0 +1,88 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ChatTemplate renders a sequence of chat messages into a model-specific
+// prompt string. Templates use Go text/template syntax and are executed
+// against a ChatTemplateData value, e.g.:
+//
+//	{{range .Messages}}<|im_start|>{{.Role}}
+//	{{.Content}}<|im_end|>
+//	{{end}}{{if .AddGenerationPrompt}}<|im_start|>assistant
+//	{{end}}
+//
+// In addition to the text/template builtins, templates may call trim,
+// lower, upper, and hasPrefix (all from the strings package).
+type ChatTemplate struct {
+	name string
+	tmpl *template.Template
+}
+
+// ChatTemplateData is the value a ChatTemplate is executed against.
+type ChatTemplateData struct {
+	Messages            []Message
+	AddGenerationPrompt bool
+}
+
+var chatTemplateFuncs = template.FuncMap{
+	"trim":      strings.TrimSpace,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"hasPrefix": strings.HasPrefix,
+}
+
+// ParseChatTemplate compiles a Go text/template chat template.
+func ParseChatTemplate(name, src string) (*ChatTemplate, error) {
+	tmpl, err := template.New(name).Funcs(chatTemplateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("parse chat template %q: %w", name, err)
+	}
+	return &ChatTemplate{name: name, tmpl: tmpl}, nil
+}
+
+// Name returns the name the template was parsed with.
+func (t *ChatTemplate) Name() string { return t.name }
+
+// Render executes the template over messages with the generation prompt
+// appended, so the result ends where the assistant's reply begins.
+func (t *ChatTemplate) Render(messages []Message) (string, error) {
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, ChatTemplateData{Messages: messages, AddGenerationPrompt: true}); err != nil {
+		return "", fmt.Errorf("render chat template %q: %w", t.name, err)
+	}
+	return sb.String(), nil
+}
+
+// isChatTemplateSource reports whether a ModelMetadata.ChatTemplate value
+// holds template source rather than the name of a built-in format.
+func isChatTemplateSource(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// detectChatTemplate maps a tokenizer-provided (typically Jinja) chat
+// template to the name of the built-in format it implements, keyed on the
+// turn delimiters each format uses. Returns "" when no format matches.
+func detectChatTemplate(src string) string {
+	switch {
+	case src == "":
+		return ""
+	case strings.Contains(src, "<start_of_turn>"):
+		return "gemma"
+	case strings.Contains(src, "<|start_header_id|>"):
+		return "llama"
+	case strings.Contains(src, "<|im_start|>"):
+		return "qwen2"
+	case strings.Contains(src, "<|end|>") && strings.Contains(src, "<|assistant|>"):
+		return "phi"
+	case strings.Contains(src, "[INST]"):
+		return "mistral"
+	case strings.Contains(src, "<｜begin▁of▁sentence｜>"), strings.Contains(src, "<|begin_of_sentence|>"):
+		return "deepseek"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,91 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+)
+
+const qwenStyleTemplate = `{{range .Messages}}<|im_start|>{{.Role}}
+{{trim .Content}}<|im_end|>
+{{end}}{{if .AddGenerationPrompt}}<|im_start|>assistant
+{{end}}`
+
+func TestChatTemplate_Render(t *testing.T) {
+	tmpl, err := ParseChatTemplate("custom", qwenStyleTemplate)
+	if err != nil {
+		t.Fatalf("ParseChatTemplate: %v", err)
+	}
+	if tmpl.Name() != "custom" {
+		t.Errorf("Name = %q, want %q", tmpl.Name(), "custom")
+	}
+
+	got, err := tmpl.Render([]Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "  Hello  "},
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := "<|im_start|>system\nBe brief.<|im_end|>\n<|im_start|>user\nHello<|im_end|>\n<|im_start|>assistant\n"
+	if got != want {
+		t.Errorf("Render =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestChatTemplate_ParseError(t *testing.T) {
+	if _, err := ParseChatTemplate("bad", "{{range .Messages}}"); err == nil {
+		t.Fatal("expected parse error for unterminated range")
+	}
+}
+
+func TestChatTemplate_RenderError(t *testing.T) {
+	tmpl, err := ParseChatTemplate("bad", "{{.Missing}}")
+	if err != nil {
+		t.Fatalf("ParseChatTemplate: %v", err)
+	}
+	if _, err := tmpl.Render(nil); err == nil {
+		t.Fatal("expected render error for unknown field")
+	}
+}
+
+func TestModel_RenderChat_CustomTemplate(t *testing.T) {
+	m := &Model{config: ModelMetadata{ChatTemplate: qwenStyleTemplate}}
+	got, err := m.RenderChat([]Message{{Role: "user", Content: "Hi"}})
+	if err != nil {
+		t.Fatalf("RenderChat: %v", err)
+	}
+	if !strings.HasSuffix(got, "<|im_start|>assistant\n") {
+		t.Errorf("RenderChat = %q, want generation prompt suffix", got)
+	}
+}
+
+func TestModel_RenderChat_InvalidTemplate(t *testing.T) {
+	m := &Model{config: ModelMetadata{ChatTemplate: "{{if .Messages}}"}}
+	if _, err := m.RenderChat([]Message{{Role: "user", Content: "Hi"}}); err == nil {
+		t.Fatal("RenderChat: expected error for invalid template")
+	}
+	// FormatMessages falls back to the generic format.
+	if got, want := m.FormatMessages([]Message{{Role: "user", Content: "Hi"}}), "user: Hi\nassistant: "; got != want {
+		t.Errorf("FormatMessages = %q, want %q", got, want)
+	}
+}
+
+func TestDetectChatTemplate(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", ""},
+		{"{% for m in messages %}<start_of_turn>{{ m.role }}", "gemma"},
+		{"<|start_header_id|>{{ message['role'] }}<|end_header_id|>", "llama"},
+		{"{{'<|im_start|>' + message['role'] + '\n'}}", "qwen2"},
+		{"<|user|>{{ content }}<|end|><|assistant|>", "phi"},
+		{"{{ '[INST] ' + content + ' [/INST]' }}", "mistral"},
+		{"{{ bos_token }}{{ content }}", ""},
+	}
+	for _, tt := range tests {
+		if got := detectChatTemplate(tt.src); got != tt.want {
+			t.Errorf("detectChatTemplate(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package inference
+
+import (
+	"context"
+	"sync"
+)
+
+// Conversation holds the ordered turns of a multi-turn chat. It is safe for
+// concurrent use; Messages returns a snapshot so callers can render or
+// generate without holding the conversation's lock.
+type Conversation struct {
+	mu       sync.Mutex
+	messages []Message
+	maxTurns int
+}
+
+// ConversationOption configures a Conversation.
+type ConversationOption func(*Conversation)
+
+// WithMaxTurns bounds the number of non-system messages a conversation
+// retains. When the bound is exceeded the oldest turns are dropped; system
+// messages are always kept. Zero (the default) means unbounded.
+func WithMaxTurns(n int) ConversationOption {
+	return func(c *Conversation) {
+		c.maxTurns = n
+	}
+}
+
+// NewConversation creates a conversation. A non-empty system prompt is
+// recorded as the first message.
+func NewConversation(system string, opts ...ConversationOption) *Conversation {
+	c := &Conversation{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if system != "" {
+		c.messages = append(c.messages, Message{Role: "system", Content: system})
+	}
+	return c
+}
+
+// Add appends a message and applies the turn bound.
+func (c *Conversation) Add(msg Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	c.trimLocked()
+}
+
+// AddUser appends a user turn.
+func (c *Conversation) AddUser(content string) {
+	c.Add(Message{Role: "user", Content: content})
+}
+
+// AddAssistant appends an assistant turn.
+func (c *Conversation) AddAssistant(content string) {
+	c.Add(Message{Role: "assistant", Content: content})
+}
+
+// Messages returns a copy of the conversation's messages in order.
+func (c *Conversation) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Message, len(c.messages))
+	copy(out, c.messages)
+	return out
+}
+
+// Len returns the number of messages in the conversation.
+func (c *Conversation) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.messages)
+}
+
+// Reset drops all turns except system messages.
+func (c *Conversation) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.messages[:0]
+	for _, msg := range c.messages {
+		if msg.Role == "system" {
+			kept = append(kept, msg)
+		}
+	}
+	clear(c.messages[len(kept):])
+	c.messages = kept
+}
+
+// trimLocked drops the oldest non-system messages until at most maxTurns
+// remain. The caller must hold c.mu.
+func (c *Conversation) trimLocked() {
+	if c.maxTurns <= 0 {
+		return
+	}
+	turns := 0
+	for _, msg := range c.messages {
+		if msg.Role != "system" {
+			turns++
+		}
+	}
+	drop := turns - c.maxTurns
+	if drop <= 0 {
+		return
+	}
+	kept := make([]Message, 0, len(c.messages)-drop)
+	for _, msg := range c.messages {
+		if msg.Role != "system" && drop > 0 {
+			drop--
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	c.messages = kept
+}
+
+// ChatConversation renders conv with the model's chat template, generates a
+// reply, and records the reply as an assistant turn in conv.
+func (m *Model) ChatConversation(ctx context.Context, conv *Conversation, opts ...GenerateOption) (Response, error) {
+	resp, err := m.Chat(ctx, conv.Messages(), opts...)
+	if err != nil {
+		return Response{}, err
+	}
+	conv.AddAssistant(resp.Content)
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package inference
+
+import "testing"
+
+func TestConversation_AddAndMessages(t *testing.T) {
+	c := NewConversation("You are helpful.")
+	c.AddUser("Hi")
+	c.AddAssistant("Hello!")
+
+	msgs := c.Messages()
+	if len(msgs) != 3 || c.Len() != 3 {
+		t.Fatalf("len = %d, Len() = %d, want 3", len(msgs), c.Len())
+	}
+	wantRoles := []string{"system", "user", "assistant"}
+	for i, role := range wantRoles {
+		if msgs[i].Role != role {
+			t.Errorf("msgs[%d].Role = %q, want %q", i, msgs[i].Role, role)
+		}
+	}
+
+	// Messages returns a copy.
+	msgs[1].Content = "mutated"
+	if c.Messages()[1].Content != "Hi" {
+		t.Error("Messages did not return a copy")
+	}
+}
+
+func TestConversation_MaxTurnsKeepsSystem(t *testing.T) {
+	c := NewConversation("sys", WithMaxTurns(2))
+	c.AddUser("one")
+	c.AddAssistant("two")
+	c.AddUser("three")
+
+	msgs := c.Messages()
+	want := []string{"sys", "two", "three"}
+	if len(msgs) != len(want) {
+		t.Fatalf("len = %d, want %d", len(msgs), len(want))
+	}
+	for i, content := range want {
+		if msgs[i].Content != content {
+			t.Errorf("msgs[%d].Content = %q, want %q", i, msgs[i].Content, content)
+		}
+	}
+}
+
+func TestConversation_Reset(t *testing.T) {
+	c := NewConversation("sys")
+	c.AddUser("hi")
+	c.AddAssistant("hello")
+	c.Reset()
+
+	msgs := c.Messages()
+	if len(msgs) != 1 || msgs[0].Role != "system" {
+		t.Fatalf("after Reset messages = %+v, want only the system prompt", msgs)
+	}
+}
+
+func TestConversation_RenderThroughModel(t *testing.T) {
+	c := NewConversation("")
+	c.AddUser("Hello")
+	m := &Model{config: ModelMetadata{ChatTemplate: "qwen2"}}
+	got, err := m.RenderChat(c.Messages())
+	if err != nil {
+		t.Fatalf("RenderChat: %v", err)
+	}
+	want := "<|im_start|>user\nHello<|im_end|>\n<|im_start|>assistant\n"
+	if got != want {
+		t.Errorf("RenderChat = %q, want %q", got, want)
+	}
+}
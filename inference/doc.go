@@ -53,7 +53,20 @@
 //   - [WithPrecision] — set TensorRT compute precision ("fp16")
 //   - [WithDType] — set GPU compute precision ("fp16", "fp8")
 //   - [WithKVDtype] — set KV cache storage precision ("fp16")
+//   - [WithKVWindow] — bound KV memory with a sliding window plus attention sinks
+//   - [WithBatchDecode] — decode GenerateBatch prompts in shared batched forward passes
 //   - [WithMmap] — control memory-mapped model loading (default: enabled)
+//   - [WithRequireIntegrity] — refuse GGUF files without embedded SHA-256 digests
+//   - [WithSignatureKey] — require a detached ed25519 signature next to the model
+//   - [WithKeyProvider] — supply keys for encrypted models (default: environment)
+//
+// GGUF files that embed SHA-256 digests (see gguf.IntegrityMetadata) are
+// verified on load, so tampered or truncated files are refused. The check
+// reads the descriptor or mapping the tensors are loaded from, and a file
+// that has not changed since it last passed is not hashed again.
+// Models encrypted with gguf.Encrypt are detected by their header and
+// decrypted in memory; the key comes from ZERFOO_MODEL_KEY unless
+// [WithKeyProvider] is given.
 //
 // # Generate Options
 //
@@ -66,6 +79,7 @@
 //   - [WithRepetitionPenalty] — penalize repeated tokens
 //   - [WithStopStrings] — strings that terminate generation
 //   - [WithGrammar] — constrained decoding via a grammar state machine
+//   - [WithSeed] — deterministic sampling with a per-request seed
 //
 // # Model Aliases
 //
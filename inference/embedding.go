@@ -0,0 +1,307 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/generate"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// PoolingStrategy selects how per-token hidden states are reduced to a single
+// sentence vector.
+type PoolingStrategy int
+
+const (
+	// PoolMean averages the hidden states of all tokens.
+	PoolMean PoolingStrategy = iota
+	// PoolCLS takes the hidden state of the first token, as used by
+	// BERT-style encoders that prepend a [CLS] token.
+	PoolCLS
+	// PoolLastToken takes the hidden state of the final token, the usual
+	// choice for causal decoders whose last position has seen the whole input.
+	PoolLastToken
+)
+
+// String returns the name accepted by ParsePoolingStrategy.
+func (p PoolingStrategy) String() string {
+	switch p {
+	case PoolMean:
+		return "mean"
+	case PoolCLS:
+		return "cls"
+	case PoolLastToken:
+		return "last"
+	default:
+		return fmt.Sprintf("PoolingStrategy(%d)", int(p))
+	}
+}
+
+// ParsePoolingStrategy parses "mean", "cls", or "last" (case-insensitive).
+func ParsePoolingStrategy(s string) (PoolingStrategy, error) {
+	switch strings.ToLower(s) {
+	case "mean":
+		return PoolMean, nil
+	case "cls", "first":
+		return PoolCLS, nil
+	case "last", "last-token", "last_token":
+		return PoolLastToken, nil
+	default:
+		return 0, fmt.Errorf("unknown pooling strategy %q (want mean, cls, or last)", s)
+	}
+}
+
+// HiddenStates holds per-token activations captured from one graph node.
+type HiddenStates struct {
+	Tokens []int     // token IDs the states were computed for
+	Dim    int       // hidden dimension
+	Data   []float32 // row-major [len(Tokens), Dim]
+}
+
+// Row returns the hidden state of token i.
+func (h *HiddenStates) Row(i int) []float32 {
+	return h.Data[i*h.Dim : (i+1)*h.Dim]
+}
+
+// Pool reduces the hidden states to a single vector using strategy.
+func (h *HiddenStates) Pool(strategy PoolingStrategy) ([]float32, error) {
+	if len(h.Tokens) == 0 {
+		return nil, errors.New("pool: no hidden states")
+	}
+	out := make([]float32, h.Dim)
+	switch strategy {
+	case PoolMean:
+		for i := range h.Tokens {
+			for j, v := range h.Row(i) {
+				out[j] += v
+			}
+		}
+		inv := 1 / float32(len(h.Tokens))
+		for j := range out {
+			out[j] *= inv
+		}
+	case PoolCLS:
+		copy(out, h.Row(0))
+	case PoolLastToken:
+		copy(out, h.Row(len(h.Tokens)-1))
+	default:
+		return nil, fmt.Errorf("pool: unknown strategy %v", strategy)
+	}
+	return out, nil
+}
+
+// EmbedOption configures SentenceEmbedding.
+type EmbedOption func(*embedConfig)
+
+type embedConfig struct {
+	layer     string
+	pooling   PoolingStrategy
+	normalize bool
+}
+
+// WithEmbedLayer selects the graph node whose output is pooled. See
+// Model.HiddenStates for the layer naming scheme. Default: the final hidden
+// states feeding the LM head.
+func WithEmbedLayer(layer string) EmbedOption {
+	return func(c *embedConfig) {
+		c.layer = layer
+	}
+}
+
+// WithPooling sets the pooling strategy. Default: PoolMean.
+func WithPooling(p PoolingStrategy) EmbedOption {
+	return func(c *embedConfig) {
+		c.pooling = p
+	}
+}
+
+// WithNormalize controls L2 normalization of the pooled vector.
+// Default: true.
+func WithNormalize(enabled bool) EmbedOption {
+	return func(c *embedConfig) {
+		c.normalize = enabled
+	}
+}
+
+// HiddenStates runs a forward pass over text and returns the per-token
+// output of the graph node named by layer:
+//
+//   - "" selects the final hidden states: the input of the LM head when the
+//     graph ends in one, otherwise the graph output itself (encoders);
+//   - "<OpType>" selects the last node of that op type, e.g. "RMSNorm";
+//   - "<OpType>:<k>" selects the k-th node of that op type in execution
+//     order; negative k counts from the end, so "RMSNorm:-2" is the
+//     second-to-last.
+//
+// The forward pass uses a fresh KV cache and holds the generator's graph
+// lock, so it does not disturb concurrent generation sessions' caches.
+func (m *Model) HiddenStates(ctx context.Context, text, layer string) (*HiddenStates, error) {
+	ids, err := m.tokenizer.Encode(text)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("text produced no tokens")
+	}
+
+	gen := m.generator
+	gen.LockGraph()
+	defer gen.UnlockGraph()
+
+	g := gen.Graph()
+	node, err := resolveLayerNode(g, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := gen.Config()
+	cache := generate.NewKVCache[float32](cfg.NumLayers, max(cfg.MaxSeqLen, len(ids)))
+	fwdCtx := generate.WithCache(ctx, cache)
+
+	data := make([]float32, len(ids))
+	for i, id := range ids {
+		data[i] = float32(id)
+	}
+	input, err := tensor.New([]int{1, len(ids)}, data)
+	if err != nil {
+		return nil, fmt.Errorf("create input tensor: %w", err)
+	}
+
+	// Intermediate buffers are normally released to the tensor pool as soon
+	// as their consumers run; disable pooling so the selected node's output
+	// survives until it is copied out below.
+	g.WithPool(nil)
+	if pool := gen.TensorPool(); pool != nil {
+		defer g.WithPool(pool)
+	}
+
+	g.ResetStatefulNodes()
+	if _, err := g.Forward(fwdCtx, input); err != nil {
+		return nil, fmt.Errorf("forward: %w", err)
+	}
+	out := g.NodeOutput(node)
+	if out == nil {
+		return nil, fmt.Errorf("layer %q: output was not retained by the forward pass", layerName(layer))
+	}
+
+	shape := out.Shape()
+	if len(shape) < 2 {
+		return nil, fmt.Errorf("layer %q: expected [seq, hidden] output, got shape %v", layerName(layer), shape)
+	}
+	dim := shape[len(shape)-1]
+	states := out.Data()
+	if dim == 0 || len(states) != len(ids)*dim {
+		return nil, fmt.Errorf("layer %q: output shape %v does not hold one row per token (%d tokens)", layerName(layer), shape, len(ids))
+	}
+	return &HiddenStates{
+		Tokens: ids,
+		Dim:    dim,
+		Data:   append([]float32(nil), states...),
+	}, nil
+}
+
+// SentenceEmbedding returns a pooled embedding of text computed from the
+// model's hidden states. Unlike Embed, which averages static token
+// embeddings, the vector reflects the full forward pass.
+func (m *Model) SentenceEmbedding(ctx context.Context, text string, opts ...EmbedOption) ([]float32, error) {
+	cfg := embedConfig{pooling: PoolMean, normalize: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	hs, err := m.HiddenStates(ctx, text, cfg.layer)
+	if err != nil {
+		return nil, err
+	}
+	vec, err := hs.Pool(cfg.pooling)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.normalize {
+		l2Normalize(vec)
+	}
+	return vec, nil
+}
+
+// SentenceEmbeddings embeds each text in turn with SentenceEmbedding.
+func (m *Model) SentenceEmbeddings(ctx context.Context, texts []string, opts ...EmbedOption) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vec, err := m.SentenceEmbedding(ctx, text, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("text %d: %w", i, err)
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// resolveLayerNode maps a layer name (see Model.HiddenStates) to a node.
+func resolveLayerNode(g *graph.Graph[float32], layer string) (graph.Node[float32], error) {
+	if layer == "" {
+		out := g.Output()
+		if out.OpType() == "LMHead" {
+			if deps := g.Dependencies(out); len(deps) > 0 {
+				return deps[0], nil
+			}
+		}
+		return out, nil
+	}
+
+	opType, idxStr, hasIdx := strings.Cut(layer, ":")
+	idx := -1
+	if hasIdx {
+		v, err := strconv.Atoi(idxStr)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: invalid index %q", layer, idxStr)
+		}
+		idx = v
+	}
+
+	var matches []graph.Node[float32]
+	for _, n := range g.Nodes() {
+		if n.OpType() == opType {
+			matches = append(matches, n)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("layer %q: no node with op type %q", layer, opType)
+	}
+	if idx < 0 {
+		idx += len(matches)
+	}
+	if idx < 0 || idx >= len(matches) {
+		return nil, fmt.Errorf("layer %q: index out of range (%d nodes of type %q)", layer, len(matches), opType)
+	}
+	return matches[idx], nil
+}
+
+func layerName(layer string) string {
+	if layer == "" {
+		return "final"
+	}
+	return layer
+}
+
+// l2Normalize scales v to unit Euclidean norm in place. Zero vectors are
+// left unchanged.
+func l2Normalize(v []float32) {
+	var sumSq float64
+	for _, x := range v {
+		sumSq += float64(x) * float64(x)
+	}
+	if sumSq == 0 {
+		return
+	}
+	inv := float32(1 / math.Sqrt(sumSq))
+	for i := range v {
+		v[i] *= inv
+	}
+}
@@ -0,0 +1,184 @@
+package inference
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/generate"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// tokenFeatureNode maps each input token ID to the hidden state
+// [id, position, 1] with output shape [1, seq, 3].
+type tokenFeatureNode struct {
+	graph.NoParameters[float32]
+	scale float32
+}
+
+func (n *tokenFeatureNode) OpType() string                     { return "TokenFeature" }
+func (n *tokenFeatureNode) Attributes() map[string]interface{} { return nil }
+func (n *tokenFeatureNode) OutputShape() []int                 { return []int{1, 1, 3} }
+func (n *tokenFeatureNode) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[float32], _ ...*tensor.TensorNumeric[float32]) ([]*tensor.TensorNumeric[float32], error) {
+	return nil, nil
+}
+
+func (n *tokenFeatureNode) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	ids := inputs[0].Data()
+	out := make([]float32, 0, 3*len(ids))
+	for pos, id := range ids {
+		out = append(out, id*n.scale, float32(pos)*n.scale, n.scale)
+	}
+	return tensor.New([]int{1, len(ids), 3}, out)
+}
+
+// fakeLMHeadNode reports the LMHead op type and projects hidden states to a
+// constant vocab-sized row per token.
+type fakeLMHeadNode struct {
+	graph.NoParameters[float32]
+	vocabSize int
+}
+
+func (n *fakeLMHeadNode) OpType() string                     { return "LMHead" }
+func (n *fakeLMHeadNode) Attributes() map[string]interface{} { return nil }
+func (n *fakeLMHeadNode) OutputShape() []int                 { return []int{1, 1, n.vocabSize} }
+func (n *fakeLMHeadNode) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[float32], _ ...*tensor.TensorNumeric[float32]) ([]*tensor.TensorNumeric[float32], error) {
+	return nil, nil
+}
+
+func (n *fakeLMHeadNode) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	seq := inputs[0].Shape()[1]
+	return tensor.New[float32]([]int{1, seq, n.vocabSize}, make([]float32, seq*n.vocabSize))
+}
+
+// buildHiddenStateModel builds Input -> TokenFeature(x1) -> TokenFeature(x2)
+// -> LMHead, so layer selection and pooling can be checked exactly.
+func buildHiddenStateModel(t *testing.T) *Model {
+	t.Helper()
+	const vocabSize = 8
+	eng := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	b := graph.NewBuilder[float32](eng)
+	in := b.Input([]int{1, 1})
+	f1 := &tokenFeatureNode{scale: 1}
+	b.AddNode(f1, in)
+	f2 := &tokenFeatureNode{scale: 2}
+	b.AddNode(f2, in)
+	head := &fakeLMHeadNode{vocabSize: vocabSize}
+	b.AddNode(head, f2)
+	g, err := b.Build(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok := buildTestTokenizer()
+	gen := generate.NewGenerator(g, tok, eng, generate.ModelConfig{
+		VocabSize: vocabSize,
+		MaxSeqLen: 32,
+	})
+	return &Model{generator: gen, tokenizer: tok, engine: eng}
+}
+
+func TestHiddenStates_LayerSelection(t *testing.T) {
+	m := buildHiddenStateModel(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		layer string
+		scale float32
+	}{
+		{"", 2},               // input of the LM head
+		{"TokenFeature", 2},   // last node of the type
+		{"TokenFeature:0", 1}, // first node of the type
+		{"TokenFeature:-2", 1},
+	}
+	for _, tt := range tests {
+		t.Run(layerName(tt.layer), func(t *testing.T) {
+			hs, err := m.HiddenStates(ctx, "hello world", tt.layer)
+			if err != nil {
+				t.Fatalf("HiddenStates: %v", err)
+			}
+			if hs.Dim != 3 || len(hs.Tokens) != 2 {
+				t.Fatalf("Dim = %d, tokens = %d, want 3 and 2", hs.Dim, len(hs.Tokens))
+			}
+			// "world" is token 5 at position 1.
+			want := []float32{5 * tt.scale, 1 * tt.scale, tt.scale}
+			for j, w := range want {
+				if got := hs.Row(1)[j]; got != w {
+					t.Errorf("Row(1)[%d] = %v, want %v", j, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestHiddenStates_UnknownLayer(t *testing.T) {
+	m := buildHiddenStateModel(t)
+	for _, layer := range []string{"Missing", "TokenFeature:5", "TokenFeature:x"} {
+		if _, err := m.HiddenStates(context.Background(), "hello", layer); err == nil {
+			t.Errorf("HiddenStates(%q): expected error", layer)
+		}
+	}
+}
+
+func TestSentenceEmbedding_Pooling(t *testing.T) {
+	m := buildHiddenStateModel(t)
+	ctx := context.Background()
+	// Tokens: hello=4 at pos 0, world=5 at pos 1; final layer scale 2.
+	tests := []struct {
+		pooling PoolingStrategy
+		want    []float32
+	}{
+		{PoolMean, []float32{9, 1, 2}},
+		{PoolCLS, []float32{8, 0, 2}},
+		{PoolLastToken, []float32{10, 2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pooling.String(), func(t *testing.T) {
+			got, err := m.SentenceEmbedding(ctx, "hello world", WithPooling(tt.pooling), WithNormalize(false))
+			if err != nil {
+				t.Fatalf("SentenceEmbedding: %v", err)
+			}
+			for j, w := range tt.want {
+				if got[j] != w {
+					t.Errorf("embedding = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSentenceEmbedding_Normalized(t *testing.T) {
+	m := buildHiddenStateModel(t)
+	vecs, err := m.SentenceEmbeddings(context.Background(), []string{"hello world", "foo"}, WithEmbedLayer("TokenFeature:0"))
+	if err != nil {
+		t.Fatalf("SentenceEmbeddings: %v", err)
+	}
+	if len(vecs) != 2 {
+		t.Fatalf("got %d vectors, want 2", len(vecs))
+	}
+	for i, v := range vecs {
+		var sumSq float64
+		for _, x := range v {
+			sumSq += float64(x) * float64(x)
+		}
+		if math.Abs(math.Sqrt(sumSq)-1) > 1e-5 {
+			t.Errorf("vector %d norm = %v, want 1", i, math.Sqrt(sumSq))
+		}
+	}
+}
+
+func TestParsePoolingStrategy(t *testing.T) {
+	for _, p := range []PoolingStrategy{PoolMean, PoolCLS, PoolLastToken} {
+		got, err := ParsePoolingStrategy(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParsePoolingStrategy(%q) = %v, %v; want %v", p.String(), got, err, p)
+		}
+	}
+	if _, err := ParsePoolingStrategy("max"); err == nil {
+		t.Error("ParsePoolingStrategy(max): expected error")
+	}
+}
@@ -0,0 +1,60 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+)
+
+// WithKeyProvider sets the key source for encrypted model files (see
+// gguf.Encrypt). Without it, LoadFile reads keys from the environment via
+// gguf.EnvKeyProvider. Use a gguf.KeyProviderFunc to fetch keys from a KMS.
+func WithKeyProvider(kp gguf.KeyProvider) Option {
+	return func(o *loadOptions) {
+		o.keyProvider = kp
+	}
+}
+
+// loadEncryptedGGUF decrypts the encrypted model at path in memory and loads
+// it with heap allocation; the plaintext is never written to disk, so mmap
+// is not available. The signature, which covers the ciphertext, is checked
+// on the descriptor that is then decrypted; embedded integrity digests are
+// checked on the plaintext.
+func loadEncryptedGGUF(path string, o *loadOptions) (*GGUFModel, error) {
+	kp := o.keyProvider
+	if kp == nil {
+		kp = gguf.EnvKeyProvider{}
+	}
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("open GGUF file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := o.verifySignature(path, f); err != nil {
+		return nil, fmt.Errorf("verify %s: %w", path, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var plain bytes.Buffer
+	if st, err := f.Stat(); err == nil {
+		plain.Grow(int(st.Size()))
+	}
+	if err := gguf.Decrypt(context.Background(), &plain, f, kp); err != nil {
+		return nil, fmt.Errorf("decrypt model: %w", err)
+	}
+	r := bytes.NewReader(plain.Bytes())
+	gf, err := gguf.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse GGUF: %w", err)
+	}
+	if err := checkIntegrity(gf, r, o); err != nil {
+		return nil, fmt.Errorf("verify %s: %w", path, err)
+	}
+	return loadGGUFParsed(gf, r)
+}
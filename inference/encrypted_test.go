@@ -0,0 +1,93 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+)
+
+// encryptTestFile replaces the model at path with its encrypted form.
+func encryptTestFile(t *testing.T, path string, key []byte, keyID string) {
+	t.Helper()
+	plain, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := gguf.Encrypt(&buf, bytes.NewReader(plain), key, keyID); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFile_Encrypted(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	path := writeTestLlamaGGUF(t, t.TempDir(), true)
+	encryptTestFile(t, path, key, "edge")
+
+	var gotID string
+	kp := gguf.KeyProviderFunc(func(_ context.Context, keyID string) ([]byte, error) {
+		gotID = keyID
+		return key, nil
+	})
+	m, err := LoadFile(path, WithKeyProvider(kp), WithRequireIntegrity(true))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	defer func() { _ = m.Close() }()
+	if gotID != "edge" {
+		t.Errorf("key ID = %q, want %q", gotID, "edge")
+	}
+	if m.Config().Architecture != "llama" {
+		t.Errorf("Architecture = %q, want llama", m.Config().Architecture)
+	}
+}
+
+func TestLoadFile_EncryptedEnvKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x17}, 32)
+	path := writeTestGGUF(t, t.TempDir())
+	encryptTestFile(t, path, key, "")
+
+	t.Setenv(gguf.ModelKeyEnv, hex.EncodeToString(key))
+	m, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	_ = m.Close()
+
+	t.Setenv(gguf.ModelKeyEnv, hex.EncodeToString(bytes.Repeat([]byte{0x18}, 32)))
+	if _, err := LoadFile(path); !errors.Is(err, gguf.ErrDecrypt) {
+		t.Errorf("wrong key: err = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestLoadFile_EncryptedSigned(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeTestGGUF(t, t.TempDir())
+	encryptTestFile(t, path, key, "")
+	signTestFile(t, path, priv)
+
+	t.Setenv(gguf.ModelKeyEnv, hex.EncodeToString(key))
+	m, err := LoadFile(path, WithSignatureKey(pub))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	_ = m.Close()
+
+	// Unstamped plaintext is refused after decryption when digests are required.
+	if _, err := LoadFile(path, WithRequireIntegrity(true)); !errors.Is(err, gguf.ErrNoIntegrity) {
+		t.Errorf("err = %v, want ErrNoIntegrity", err)
+	}
+}
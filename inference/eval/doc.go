@@ -0,0 +1,34 @@
+// Package eval provides evaluation harnesses for language models:
+// token-level log-likelihood and perplexity over text datasets, and
+// declarative benchmark tasks. (Stability: alpha)
+//
+// Models are evaluated through the [Scorer] interface, which returns the
+// log-probability of each token given its prefix. [GeneratorScorer] adapts a
+// [generate.Generator] so any model loaded by the inference package can be
+// evaluated:
+//
+//	scorer := eval.NewGeneratorScorer(model.Generator())
+//	ev, err := eval.NewPerplexityEvaluator(scorer, model.Tokenizer(), eval.WithContextLen(1024), eval.WithStride(512))
+//	res, err := ev.Evaluate(ctx, docs)
+//	fmt.Println(res.Perplexity)
+//
+// Documents longer than the context length are scored with overlapping
+// sliding windows: each window ends stride tokens after the previous one and
+// only those new tokens contribute, so every token after the first is
+// counted exactly once with at least contextLen-stride tokens of context.
+//
+// # Tasks
+//
+// A [Task] is a JSON document listing examples and naming a task type:
+// "multiple_choice" (pick the most likely choice), "cloze" (log-likelihood
+// of a target continuation) or "exact_match" (greedy generation compared to
+// a reference answer). [TaskEvaluator] runs tasks and averages per-example
+// metrics, so the same task files can track quality across checkpoints:
+//
+//	task, err := eval.LoadTask("tasks/capitals.json")
+//	te, err := eval.NewTaskEvaluator(scorer, model.Tokenizer(), eval.WithCompleter(scorer))
+//	res, err := te.Run(ctx, task)
+//	fmt.Println(res.Metrics["acc"])
+//
+// New task types are added with [RegisterTaskType].
+package eval
@@ -0,0 +1,282 @@
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tokenizer "github.com/zerfoo/ztoken"
+)
+
+// Document is a unit of text scored independently by the perplexity
+// evaluator.
+type Document struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// DocumentResult holds the log-likelihood statistics for one document.
+type DocumentResult struct {
+	ID         string    `json:"id"`
+	Tokens     int       `json:"tokens"`     // number of scored tokens
+	NLL        float64   `json:"nll"`        // summed negative log-likelihood (nats)
+	MeanNLL    float64   `json:"mean_nll"`   // NLL / Tokens
+	Perplexity float64   `json:"perplexity"` // exp(MeanNLL)
+	TokenNLL   []float64 `json:"token_nll,omitempty"`
+}
+
+// PerplexityResult aggregates per-document results. Aggregate statistics
+// are token-weighted: Perplexity is exp(total NLL / total tokens).
+type PerplexityResult struct {
+	Documents  []DocumentResult `json:"documents"`
+	Tokens     int              `json:"tokens"`
+	NLL        float64          `json:"nll"`
+	MeanNLL    float64          `json:"mean_nll"`
+	Perplexity float64          `json:"perplexity"`
+}
+
+// PerplexityOption configures a PerplexityEvaluator.
+type PerplexityOption func(*PerplexityEvaluator)
+
+// WithContextLen sets the maximum number of tokens per forward pass.
+// Default: 1024.
+func WithContextLen(n int) PerplexityOption {
+	return func(e *PerplexityEvaluator) {
+		e.contextLen = n
+	}
+}
+
+// WithStride sets how many new tokens each sliding window after the first
+// scores. The remaining contextLen-stride tokens of the window are context
+// only, so smaller strides give every scored token more context at the cost
+// of more forward passes. Must be less than the context length.
+// Default: contextLen-1 (minimal overlap).
+func WithStride(n int) PerplexityOption {
+	return func(e *PerplexityEvaluator) {
+		e.stride = n
+	}
+}
+
+// WithBOS prepends the given token ID to every document so that its first
+// token is also scored. By default no BOS token is added and the first
+// token of each document serves only as context.
+func WithBOS(id int) PerplexityOption {
+	return func(e *PerplexityEvaluator) {
+		e.bosID = id
+	}
+}
+
+// WithTokenNLL records the negative log-likelihood of every scored token in
+// DocumentResult.TokenNLL.
+func WithTokenNLL(enabled bool) PerplexityOption {
+	return func(e *PerplexityEvaluator) {
+		e.keepTokenNLL = enabled
+	}
+}
+
+// PerplexityEvaluator computes token-level negative log-likelihood and
+// perplexity of a model over a set of documents.
+type PerplexityEvaluator struct {
+	scorer       Scorer
+	tok          tokenizer.Tokenizer
+	contextLen   int
+	stride       int
+	bosID        int
+	keepTokenNLL bool
+}
+
+// NewPerplexityEvaluator creates an evaluator that tokenizes documents with
+// tok and scores them with scorer.
+func NewPerplexityEvaluator(scorer Scorer, tok tokenizer.Tokenizer, opts ...PerplexityOption) (*PerplexityEvaluator, error) {
+	if scorer == nil {
+		return nil, errors.New("scorer must not be nil")
+	}
+	if tok == nil {
+		return nil, errors.New("tokenizer must not be nil")
+	}
+	e := &PerplexityEvaluator{
+		scorer:     scorer,
+		tok:        tok,
+		contextLen: 1024,
+		bosID:      -1,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.contextLen < 2 {
+		return nil, fmt.Errorf("context length must be at least 2, got %d", e.contextLen)
+	}
+	if e.stride == 0 {
+		e.stride = e.contextLen - 1
+	}
+	if e.stride < 1 || e.stride >= e.contextLen {
+		return nil, fmt.Errorf("stride must be in [1, %d), got %d", e.contextLen, e.stride)
+	}
+	return e, nil
+}
+
+// Evaluate scores every document and returns per-document and aggregate
+// statistics. Documents that produce fewer than two tokens contribute no
+// scored tokens.
+func (e *PerplexityEvaluator) Evaluate(ctx context.Context, docs []Document) (*PerplexityResult, error) {
+	res := &PerplexityResult{Documents: make([]DocumentResult, 0, len(docs))}
+	for i, doc := range docs {
+		ids, err := e.tok.Encode(doc.Text)
+		if err != nil {
+			return nil, fmt.Errorf("encode document %q: %w", doc.ID, err)
+		}
+		if e.bosID >= 0 {
+			ids = append([]int{e.bosID}, ids...)
+		}
+		dr, err := e.ScoreTokens(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("score document %q: %w", doc.ID, err)
+		}
+		dr.ID = doc.ID
+		if dr.ID == "" {
+			dr.ID = fmt.Sprintf("doc-%d", i)
+		}
+		res.Documents = append(res.Documents, dr)
+		res.Tokens += dr.Tokens
+		res.NLL += dr.NLL
+	}
+	if res.Tokens > 0 {
+		res.MeanNLL = res.NLL / float64(res.Tokens)
+		res.Perplexity = math.Exp(res.MeanNLL)
+	}
+	return res, nil
+}
+
+// ScoreTokens scores an already-tokenized sequence with sliding windows of at
+// most contextLen tokens. The first window scores its tokens after the
+// first; each later window ends stride tokens past the previous one and
+// scores only those new tokens, so every token after the first is scored
+// exactly once with at least contextLen-stride tokens of context.
+func (e *PerplexityEvaluator) ScoreTokens(ctx context.Context, ids []int) (DocumentResult, error) {
+	var dr DocumentResult
+	n := len(ids)
+	if n < 2 {
+		return dr, nil
+	}
+	if e.keepTokenNLL {
+		dr.TokenNLL = make([]float64, 0, n-1)
+	}
+
+	prevEnd := 0
+	for prevEnd < n {
+		if err := ctx.Err(); err != nil {
+			return dr, err
+		}
+		end := min(e.contextLen, n)
+		if prevEnd > 0 {
+			end = min(prevEnd+e.stride, n)
+		}
+		begin := max(0, end-e.contextLen)
+		lps, err := e.scorer.TokenLogProbs(ctx, ids[begin:end])
+		if err != nil {
+			return dr, err
+		}
+		if len(lps) != end-begin-1 {
+			return dr, fmt.Errorf("scorer returned %d log-probs for %d tokens", len(lps), end-begin)
+		}
+		for t := max(prevEnd, begin+1); t < end; t++ {
+			nll := -lps[t-begin-1]
+			dr.NLL += nll
+			dr.Tokens++
+			if e.keepTokenNLL {
+				dr.TokenNLL = append(dr.TokenNLL, nll)
+			}
+		}
+		prevEnd = end
+	}
+
+	if dr.Tokens > 0 {
+		dr.MeanNLL = dr.NLL / float64(dr.Tokens)
+		dr.Perplexity = math.Exp(dr.MeanNLL)
+	}
+	return dr, nil
+}
+
+// LoadDocuments reads an evaluation dataset from path:
+//   - a .jsonl file yields one document per line with "id" and "text" fields;
+//   - a directory yields one document per .txt file, in name order;
+//   - any other file is read as a single document.
+func LoadDocuments(path string) ([]Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat dataset: %w", err)
+	}
+	if info.IsDir() {
+		return loadTextDir(path)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		return loadJSONL(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	return []Document{{ID: filepath.Base(path), Text: string(data)}}, nil
+}
+
+func loadTextDir(dir string) ([]Document, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dataset dir: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".txt") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	docs := make([]Document, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		docs = append(docs, Document{ID: name, Text: string(data)})
+	}
+	return docs, nil
+}
+
+func loadJSONL(path string) ([]Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open dataset: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var docs []Document
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		raw := strings.TrimSpace(sc.Text())
+		if raw == "" {
+			continue
+		}
+		var doc Document
+		if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if doc.ID == "" {
+			doc.ID = fmt.Sprintf("line-%d", line)
+		}
+		docs = append(docs, doc)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	return docs, nil
+}
@@ -0,0 +1,244 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tokenizer "github.com/zerfoo/ztoken"
+)
+
+// recordingScorer assigns log-probability -(ids[t]) to every target token
+// and records the windows it was asked to score.
+type recordingScorer struct {
+	windows [][]int
+	err     error
+}
+
+func (s *recordingScorer) TokenLogProbs(_ context.Context, ids []int) ([]float64, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.windows = append(s.windows, append([]int(nil), ids...))
+	out := make([]float64, len(ids)-1)
+	for t := 1; t < len(ids); t++ {
+		out[t-1] = -float64(ids[t])
+	}
+	return out, nil
+}
+
+func testTokenizer() *tokenizer.WhitespaceTokenizer {
+	tok := tokenizer.NewWhitespaceTokenizer()
+	tok.AddToken("hello") // 4
+	tok.AddToken("world") // 5
+	tok.AddToken("foo")   // 6
+	tok.AddToken("bar")   // 7
+	return tok
+}
+
+func TestNewPerplexityEvaluator_Validation(t *testing.T) {
+	tok := testTokenizer()
+	s := &recordingScorer{}
+	tests := []struct {
+		name   string
+		scorer Scorer
+		tok    tokenizer.Tokenizer
+		opts   []PerplexityOption
+	}{
+		{"nil scorer", nil, tok, nil},
+		{"nil tokenizer", s, nil, nil},
+		{"context too small", s, tok, []PerplexityOption{WithContextLen(1)}},
+		{"stride exceeds context", s, tok, []PerplexityOption{WithContextLen(4), WithStride(5)}},
+		{"stride equals context", s, tok, []PerplexityOption{WithContextLen(4), WithStride(4)}},
+		{"negative stride", s, tok, []PerplexityOption{WithStride(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPerplexityEvaluator(tt.scorer, tt.tok, tt.opts...); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestScoreTokens_SlidingWindow(t *testing.T) {
+	ids := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name        string
+		ctxLen      int
+		stride      int
+		wantWindows int
+	}{
+		{"single window", 16, 15, 1},
+		{"minimal overlap", 4, 3, 3},
+		{"overlapping", 4, 2, 4},
+		{"stride one", 3, 1, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &recordingScorer{}
+			ev, err := NewPerplexityEvaluator(s, testTokenizer(),
+				WithContextLen(tt.ctxLen), WithStride(tt.stride), WithTokenNLL(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dr, err := ev.ScoreTokens(context.Background(), ids)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s.windows) != tt.wantWindows {
+				t.Errorf("windows = %v, want %d windows", s.windows, tt.wantWindows)
+			}
+			for _, w := range s.windows {
+				if len(w) > tt.ctxLen {
+					t.Errorf("window %v exceeds context %d", w, tt.ctxLen)
+				}
+			}
+			// Every token after the first is scored exactly once, in order.
+			if dr.Tokens != len(ids)-1 {
+				t.Fatalf("Tokens = %d, want %d", dr.Tokens, len(ids)-1)
+			}
+			for i, nll := range dr.TokenNLL {
+				if nll != float64(ids[i+1]) {
+					t.Fatalf("TokenNLL = %v, want targets %v", dr.TokenNLL, ids[1:])
+				}
+			}
+			if dr.NLL != 54 {
+				t.Errorf("NLL = %v, want 54", dr.NLL)
+			}
+			if want := math.Exp(54.0 / 9); math.Abs(dr.Perplexity-want) > 1e-9 {
+				t.Errorf("Perplexity = %v, want %v", dr.Perplexity, want)
+			}
+		})
+	}
+}
+
+func TestScoreTokens_ShortSequence(t *testing.T) {
+	s := &recordingScorer{}
+	ev, err := NewPerplexityEvaluator(s, testTokenizer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dr, err := ev.ScoreTokens(context.Background(), []int{4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dr.Tokens != 0 || dr.Perplexity != 0 || len(s.windows) != 0 {
+		t.Errorf("got %+v after %d windows, want no scored tokens", dr, len(s.windows))
+	}
+}
+
+func TestEvaluate_Aggregate(t *testing.T) {
+	s := &recordingScorer{}
+	ev, err := NewPerplexityEvaluator(s, testTokenizer(), WithContextLen(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ev.Evaluate(context.Background(), []Document{
+		{ID: "a", Text: "hello world foo"}, // targets 5, 6
+		{Text: "foo bar"},                  // target 7
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Documents) != 2 {
+		t.Fatalf("got %d documents, want 2", len(res.Documents))
+	}
+	a, b := res.Documents[0], res.Documents[1]
+	if a.ID != "a" || a.Tokens != 2 || a.NLL != 11 || a.MeanNLL != 5.5 {
+		t.Errorf("doc a = %+v", a)
+	}
+	if b.ID != "doc-1" || b.Tokens != 1 || b.NLL != 7 {
+		t.Errorf("doc b = %+v", b)
+	}
+	if res.Tokens != 3 || res.NLL != 18 || res.MeanNLL != 6 {
+		t.Errorf("aggregate = tokens %d nll %v mean %v", res.Tokens, res.NLL, res.MeanNLL)
+	}
+	if want := math.Exp(6); math.Abs(res.Perplexity-want) > 1e-9 {
+		t.Errorf("Perplexity = %v, want %v", res.Perplexity, want)
+	}
+}
+
+func TestEvaluate_BOS(t *testing.T) {
+	s := &recordingScorer{}
+	ev, err := NewPerplexityEvaluator(s, testTokenizer(), WithBOS(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ev.Evaluate(context.Background(), []Document{{ID: "a", Text: "hello world"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tokens != 2 || res.NLL != 9 {
+		t.Errorf("tokens = %d nll = %v, want 2 and 9", res.Tokens, res.NLL)
+	}
+	if s.windows[0][0] != 1 {
+		t.Errorf("window = %v, want BOS first", s.windows[0])
+	}
+}
+
+func TestEvaluate_ScorerError(t *testing.T) {
+	s := &recordingScorer{err: errors.New("boom")}
+	ev, err := NewPerplexityEvaluator(s, testTokenizer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ev.Evaluate(context.Background(), []Document{{Text: "hello world"}}); err == nil {
+		t.Error("expected scorer error")
+	}
+}
+
+func TestLoadDocuments(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonl := filepath.Join(dir, "data.jsonl")
+	if err := os.WriteFile(jsonl, []byte("{\"id\":\"x\",\"text\":\"hello\"}\n\n{\"text\":\"world\"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := LoadDocuments(jsonl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != "x" || docs[1].ID != "line-3" || docs[1].Text != "world" {
+		t.Errorf("jsonl docs = %+v", docs)
+	}
+
+	txtDir := filepath.Join(dir, "txt")
+	if err := os.Mkdir(txtDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for name, text := range map[string]string{"b.txt": "bar", "a.txt": "foo", "skip.md": "x"} {
+		if err := os.WriteFile(filepath.Join(txtDir, name), []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs, err = LoadDocuments(txtDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != "a.txt" || docs[1].Text != "bar" {
+		t.Errorf("dir docs = %+v", docs)
+	}
+
+	docs, err = LoadDocuments(filepath.Join(txtDir, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Text != "foo" {
+		t.Errorf("file docs = %+v", docs)
+	}
+
+	bad := filepath.Join(dir, "bad.jsonl")
+	if err := os.WriteFile(bad, []byte("not json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDocuments(bad); err == nil {
+		t.Error("expected error for malformed jsonl")
+	}
+	if _, err := LoadDocuments(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing path")
+	}
+}
@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zerfoo/zerfoo/generate"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Scorer computes next-token log-probabilities for a token sequence.
+type Scorer interface {
+	// TokenLogProbs returns, for each t in [1, len(ids)), the natural-log
+	// probability the model assigns to ids[t] given ids[:t]. The result has
+	// len(ids)-1 entries.
+	TokenLogProbs(ctx context.Context, ids []int) ([]float64, error)
+}
+
+// GeneratorScorer adapts a generate.Generator to the Scorer interface by
+// running the model graph over a whole token window in one forward pass
+// with a fresh KV cache. It also implements Completer for generative tasks.
+type GeneratorScorer[T tensor.Numeric] struct {
+	gen *generate.Generator[T]
+}
+
+// NewGeneratorScorer creates a Scorer backed by gen.
+func NewGeneratorScorer[T tensor.Numeric](gen *generate.Generator[T]) *GeneratorScorer[T] {
+	return &GeneratorScorer[T]{gen: gen}
+}
+
+// TokenLogProbs implements Scorer. It holds the generator's graph lock for
+// the duration of the forward pass.
+func (s *GeneratorScorer[T]) TokenLogProbs(ctx context.Context, ids []int) ([]float64, error) {
+	if len(ids) < 2 {
+		return nil, nil
+	}
+
+	s.gen.LockGraph()
+	defer s.gen.UnlockGraph()
+
+	cfg := s.gen.Config()
+	cache := generate.NewKVCache[T](cfg.NumLayers, max(cfg.MaxSeqLen, len(ids)))
+	fwdCtx := generate.WithCache(ctx, cache)
+
+	data := make([]T, len(ids))
+	for i, id := range ids {
+		data[i] = T(id)
+	}
+	input, err := tensor.New([]int{1, len(ids)}, data)
+	if err != nil {
+		return nil, fmt.Errorf("create input tensor: %w", err)
+	}
+
+	g := s.gen.Graph()
+	g.ResetStatefulNodes()
+	logits, err := g.Forward(fwdCtx, input)
+	if err != nil {
+		return nil, fmt.Errorf("forward: %w", err)
+	}
+
+	shape := logits.Shape()
+	if len(shape) != 3 || shape[0] != 1 || shape[1] != len(ids) {
+		return nil, fmt.Errorf("expected logits [1, %d, vocab], got shape %v", len(ids), shape)
+	}
+	vocab := shape[2]
+	return targetLogProbs(logits.Data(), vocab, ids)
+}
+
+// targetLogProbs computes log-softmax(logits[t-1])[ids[t]] for every
+// t >= 1 from row-major [seq, vocab] logits.
+func targetLogProbs[T tensor.Numeric](logits []T, vocab int, ids []int) ([]float64, error) {
+	if len(logits) < (len(ids)-1)*vocab {
+		return nil, fmt.Errorf("logits too short: %d < %d", len(logits), (len(ids)-1)*vocab)
+	}
+	out := make([]float64, len(ids)-1)
+	for t := 1; t < len(ids); t++ {
+		target := ids[t]
+		if target < 0 || target >= vocab {
+			return nil, fmt.Errorf("token %d at position %d out of vocabulary range [0, %d)", target, t, vocab)
+		}
+		row := logits[(t-1)*vocab : t*vocab]
+		out[t-1] = float64(row[target]) - logSumExp(row)
+	}
+	return out, nil
+}
+
+// logSumExp returns log(sum(exp(row))) computed stably in float64.
+func logSumExp[T tensor.Numeric](row []T) float64 {
+	maxVal := math.Inf(-1)
+	for _, v := range row {
+		if f := float64(v); f > maxVal {
+			maxVal = f
+		}
+	}
+	if math.IsInf(maxVal, 0) {
+		return maxVal
+	}
+	var sum float64
+	for _, v := range row {
+		sum += math.Exp(float64(v) - maxVal)
+	}
+	return maxVal + math.Log(sum)
+}
+
+// Complete implements Completer by decoding greedily with the generator.
+func (s *GeneratorScorer[T]) Complete(ctx context.Context, prompt string, maxNewTokens int, stop []string) (string, error) {
+	return s.gen.Generate(ctx, prompt, generate.SamplingConfig{
+		Temperature:  0,
+		MaxNewTokens: maxNewTokens,
+		StopStrings:  stop,
+	})
+}
@@ -0,0 +1,118 @@
+package eval
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/generate"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// nextTokenNode emits logits that put weight `peak` on token (input+1) mod
+// vocab at every position and zero elsewhere.
+type nextTokenNode struct {
+	graph.NoParameters[float32]
+	vocabSize int
+	peak      float32
+}
+
+func (n *nextTokenNode) OpType() string                     { return "NextToken" }
+func (n *nextTokenNode) Attributes() map[string]interface{} { return nil }
+func (n *nextTokenNode) OutputShape() []int                 { return []int{1, 1, n.vocabSize} }
+func (n *nextTokenNode) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[float32], _ ...*tensor.TensorNumeric[float32]) ([]*tensor.TensorNumeric[float32], error) {
+	return nil, nil
+}
+
+func (n *nextTokenNode) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	in := inputs[0].Data()
+	seqLen := inputs[0].Shape()[1]
+	data := make([]float32, seqLen*n.vocabSize)
+	for pos := range seqLen {
+		next := (int(in[pos]) + 1) % n.vocabSize
+		data[pos*n.vocabSize+next] = n.peak
+	}
+	return tensor.New([]int{1, seqLen, n.vocabSize}, data)
+}
+
+func buildScorer(t *testing.T, node *nextTokenNode) *GeneratorScorer[float32] {
+	t.Helper()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	b := graph.NewBuilder[float32](engine)
+	in := b.Input([]int{1, 1})
+	b.AddNode(node, in)
+	g, err := b.Build(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := generate.NewGenerator(g, testTokenizer(), engine, generate.ModelConfig{
+		VocabSize:  node.vocabSize,
+		MaxSeqLen:  8,
+		EOSTokenID: 2,
+		NumLayers:  1,
+	})
+	return NewGeneratorScorer(gen)
+}
+
+func TestGeneratorScorer_UniformPerplexityEqualsVocab(t *testing.T) {
+	scorer := buildScorer(t, &nextTokenNode{vocabSize: 8, peak: 0})
+	ev, err := NewPerplexityEvaluator(scorer, testTokenizer(), WithContextLen(3), WithStride(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ev.Evaluate(context.Background(), []Document{{Text: "hello world foo bar hello world"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tokens != 5 {
+		t.Errorf("Tokens = %d, want 5", res.Tokens)
+	}
+	if math.Abs(res.Perplexity-8) > 1e-6 {
+		t.Errorf("Perplexity = %v, want 8", res.Perplexity)
+	}
+}
+
+func TestGeneratorScorer_TokenLogProbs(t *testing.T) {
+	scorer := buildScorer(t, &nextTokenNode{vocabSize: 8, peak: 5})
+	// 4->5 and 5->6 are predicted; 6->4 is not. Longer than MaxSeqLen is fine.
+	ids := []int{4, 5, 6, 4, 5, 6, 4, 5, 6, 4}
+	lps, err := scorer.TokenLogProbs(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lps) != len(ids)-1 {
+		t.Fatalf("got %d log-probs, want %d", len(lps), len(ids)-1)
+	}
+	norm := math.Log(math.Exp(5) + 7)
+	for i, lp := range lps {
+		want := -norm
+		if ids[i+1] == ids[i]+1 {
+			want = 5 - norm
+		}
+		if math.Abs(lp-want) > 1e-6 {
+			t.Errorf("lps[%d] = %v, want %v", i, lp, want)
+		}
+	}
+
+	if lps, err := scorer.TokenLogProbs(context.Background(), []int{4}); err != nil || lps != nil {
+		t.Errorf("single token: got %v, %v; want nil, nil", lps, err)
+	}
+	if _, err := scorer.TokenLogProbs(context.Background(), []int{4, 9}); err == nil {
+		t.Error("expected out-of-vocabulary error")
+	}
+}
+
+func TestGeneratorScorer_Complete(t *testing.T) {
+	scorer := buildScorer(t, &nextTokenNode{vocabSize: 8, peak: 5})
+	out, err := scorer.Complete(context.Background(), "hello", 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "world foo" {
+		t.Errorf("Complete() = %q, want %q", out, "world foo")
+	}
+}
@@ -0,0 +1,411 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	tokenizer "github.com/zerfoo/ztoken"
+)
+
+// TaskType names a scoring procedure registered with RegisterTaskType.
+type TaskType string
+
+// Built-in task types.
+const (
+	// TaskMultipleChoice scores every choice as a continuation of the prompt
+	// and picks the most likely one. Metrics: acc, acc_norm (log-likelihood
+	// normalized by choice length in bytes).
+	TaskMultipleChoice TaskType = "multiple_choice"
+	// TaskCloze scores the log-likelihood of the target continuation.
+	// Metrics: loglik (summed log-probability), mean_nll (per token).
+	TaskCloze TaskType = "cloze"
+	// TaskExactMatch generates greedily from the prompt and compares the
+	// normalized output with the target and its aliases. Metric: exact_match.
+	TaskExactMatch TaskType = "exact_match"
+)
+
+// Example is one item of a task.
+type Example struct {
+	Prompt  string   `json:"prompt"`
+	Choices []string `json:"choices,omitempty"` // multiple_choice candidates
+	Answer  int      `json:"answer,omitempty"`  // index of the correct choice
+	Target  string   `json:"target,omitempty"`  // cloze / exact_match reference
+	Aliases []string `json:"aliases,omitempty"` // additional accepted exact_match answers
+}
+
+// Task is a declarative evaluation task, usually loaded from JSON with
+// LoadTask.
+type Task struct {
+	Name         string    `json:"name"`
+	Type         TaskType  `json:"type"`
+	Description  string    `json:"description,omitempty"`
+	Preamble     string    `json:"preamble,omitempty"`       // prepended to every prompt (e.g. few-shot examples)
+	MaxNewTokens int       `json:"max_new_tokens,omitempty"` // exact_match generation budget; default 32
+	Stop         []string  `json:"stop,omitempty"`           // exact_match stop strings; default ["\n"]
+	IgnoreCase   bool      `json:"ignore_case,omitempty"`    // case-insensitive exact_match
+	Examples     []Example `json:"examples"`
+}
+
+// Validate checks that the task is well formed for its type.
+func (t *Task) Validate() error {
+	if t.Name == "" {
+		return errors.New("task name is required")
+	}
+	if _, ok := GetTaskType(t.Type); !ok {
+		return fmt.Errorf("task %q: unknown type %q", t.Name, t.Type)
+	}
+	if len(t.Examples) == 0 {
+		return fmt.Errorf("task %q: no examples", t.Name)
+	}
+	for i, ex := range t.Examples {
+		switch t.Type {
+		case TaskMultipleChoice:
+			if len(ex.Choices) < 2 {
+				return fmt.Errorf("task %q example %d: need at least 2 choices", t.Name, i)
+			}
+			if ex.Answer < 0 || ex.Answer >= len(ex.Choices) {
+				return fmt.Errorf("task %q example %d: answer %d out of range [0, %d)", t.Name, i, ex.Answer, len(ex.Choices))
+			}
+		case TaskCloze, TaskExactMatch:
+			if ex.Target == "" {
+				return fmt.Errorf("task %q example %d: target is required", t.Name, i)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadTask reads and validates a JSON task definition.
+func LoadTask(path string) (*Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read task: %w", err)
+	}
+	var t Task
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("parse task %s: %w", path, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// TaskHandler scores one example and returns its metric values. The
+// evaluator averages each metric over all examples of the task.
+type TaskHandler func(ctx context.Context, e *TaskEvaluator, task *Task, ex *Example) (map[string]float64, error)
+
+// taskRegistry holds the global task type registry.
+var taskRegistry = struct {
+	mu       sync.RWMutex
+	handlers map[TaskType]TaskHandler
+}{
+	handlers: make(map[TaskType]TaskHandler),
+}
+
+func init() {
+	RegisterTaskType(TaskMultipleChoice, scoreMultipleChoice)
+	RegisterTaskType(TaskCloze, scoreCloze)
+	RegisterTaskType(TaskExactMatch, scoreExactMatch)
+}
+
+// RegisterTaskType registers a handler for a task type so that task files
+// can refer to it by name. Panics if name is empty, handler is nil, or a
+// handler is already registered for name.
+func RegisterTaskType(name TaskType, handler TaskHandler) {
+	if name == "" {
+		panic("eval: RegisterTaskType called with empty name")
+	}
+	if handler == nil {
+		panic("eval: RegisterTaskType called with nil handler")
+	}
+	taskRegistry.mu.Lock()
+	defer taskRegistry.mu.Unlock()
+	if _, dup := taskRegistry.handlers[name]; dup {
+		panic(fmt.Sprintf("eval: RegisterTaskType called twice for %q", name))
+	}
+	taskRegistry.handlers[name] = handler
+}
+
+// GetTaskType returns the handler registered for name.
+func GetTaskType(name TaskType) (TaskHandler, bool) {
+	taskRegistry.mu.RLock()
+	defer taskRegistry.mu.RUnlock()
+	h, ok := taskRegistry.handlers[name]
+	return h, ok
+}
+
+// ListTaskTypes returns a sorted list of all registered task types.
+func ListTaskTypes() []TaskType {
+	taskRegistry.mu.RLock()
+	defer taskRegistry.mu.RUnlock()
+	names := make([]TaskType, 0, len(taskRegistry.handlers))
+	for name := range taskRegistry.handlers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Completer generates text from a prompt. It is required by generative task
+// types such as exact_match.
+type Completer interface {
+	// Complete greedily generates at most maxNewTokens tokens after prompt,
+	// stopping early at any of the stop strings.
+	Complete(ctx context.Context, prompt string, maxNewTokens int, stop []string) (string, error)
+}
+
+// TaskResult holds the averaged metrics of one task run.
+type TaskResult struct {
+	Task     string             `json:"task"`
+	Type     TaskType           `json:"type"`
+	Examples int                `json:"examples"`
+	Metrics  map[string]float64 `json:"metrics"`
+}
+
+// TaskOption configures a TaskEvaluator.
+type TaskOption func(*TaskEvaluator)
+
+// WithCompleter sets the text generator used by generative task types.
+func WithCompleter(c Completer) TaskOption {
+	return func(e *TaskEvaluator) {
+		e.completer = c
+	}
+}
+
+// WithTaskBOS prepends the given token ID to every scored sequence.
+func WithTaskBOS(id int) TaskOption {
+	return func(e *TaskEvaluator) {
+		e.bosID = id
+	}
+}
+
+// WithMaxContext caps the number of tokens per scored sequence. Longer
+// prompts are truncated from the left. Default: 2048.
+func WithMaxContext(n int) TaskOption {
+	return func(e *TaskEvaluator) {
+		e.maxContext = n
+	}
+}
+
+// WithLimit evaluates at most n examples per task. Zero means all.
+func WithLimit(n int) TaskOption {
+	return func(e *TaskEvaluator) {
+		e.limit = n
+	}
+}
+
+// TaskEvaluator runs declarative tasks against a model.
+type TaskEvaluator struct {
+	scorer     Scorer
+	tok        tokenizer.Tokenizer
+	completer  Completer
+	bosID      int
+	maxContext int
+	limit      int
+}
+
+// NewTaskEvaluator creates a TaskEvaluator that scores continuations with
+// scorer after tokenizing them with tok.
+func NewTaskEvaluator(scorer Scorer, tok tokenizer.Tokenizer, opts ...TaskOption) (*TaskEvaluator, error) {
+	if scorer == nil {
+		return nil, errors.New("scorer must not be nil")
+	}
+	if tok == nil {
+		return nil, errors.New("tokenizer must not be nil")
+	}
+	e := &TaskEvaluator{
+		scorer:     scorer,
+		tok:        tok,
+		bosID:      -1,
+		maxContext: 2048,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.maxContext < 2 {
+		return nil, fmt.Errorf("max context must be at least 2, got %d", e.maxContext)
+	}
+	if e.limit < 0 {
+		return nil, fmt.Errorf("limit must be non-negative, got %d", e.limit)
+	}
+	return e, nil
+}
+
+// Run scores every example of task with its registered handler and returns
+// the mean of each metric.
+func (e *TaskEvaluator) Run(ctx context.Context, task *Task) (*TaskResult, error) {
+	handler, ok := GetTaskType(task.Type)
+	if !ok {
+		return nil, fmt.Errorf("task %q: unknown type %q", task.Name, task.Type)
+	}
+	examples := task.Examples
+	if e.limit > 0 && len(examples) > e.limit {
+		examples = examples[:e.limit]
+	}
+
+	sums := make(map[string]float64)
+	for i := range examples {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		metrics, err := handler(ctx, e, task, &examples[i])
+		if err != nil {
+			return nil, fmt.Errorf("task %q example %d: %w", task.Name, i, err)
+		}
+		for k, v := range metrics {
+			sums[k] += v
+		}
+	}
+	for k := range sums {
+		sums[k] /= float64(len(examples))
+	}
+	return &TaskResult{
+		Task:     task.Name,
+		Type:     task.Type,
+		Examples: len(examples),
+		Metrics:  sums,
+	}, nil
+}
+
+// ContinuationLogProb returns the summed log-probability of continuation
+// given prompt and the number of continuation tokens scored.
+func (e *TaskEvaluator) ContinuationLogProb(ctx context.Context, prompt, continuation string) (float64, int, error) {
+	ctxIDs, err := e.tok.Encode(prompt)
+	if err != nil {
+		return 0, 0, fmt.Errorf("encode prompt: %w", err)
+	}
+	contIDs, err := e.tok.Encode(continuation)
+	if err != nil {
+		return 0, 0, fmt.Errorf("encode continuation: %w", err)
+	}
+	if len(contIDs) == 0 {
+		return 0, 0, errors.New("continuation produced no tokens")
+	}
+	if len(contIDs) >= e.maxContext {
+		return 0, 0, fmt.Errorf("continuation of %d tokens does not fit max context %d", len(contIDs), e.maxContext)
+	}
+
+	ids := make([]int, 0, 1+len(ctxIDs)+len(contIDs))
+	if e.bosID >= 0 {
+		ids = append(ids, e.bosID)
+	}
+	ids = append(ids, ctxIDs...)
+	ids = append(ids, contIDs...)
+	if len(ids) == len(contIDs) {
+		return 0, 0, errors.New("continuation needs at least one context token")
+	}
+	if len(ids) > e.maxContext {
+		ids = ids[len(ids)-e.maxContext:]
+	}
+
+	lps, err := e.scorer.TokenLogProbs(ctx, ids)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(lps) != len(ids)-1 {
+		return 0, 0, fmt.Errorf("scorer returned %d log-probs for %d tokens", len(lps), len(ids))
+	}
+	var sum float64
+	for _, lp := range lps[len(lps)-len(contIDs):] {
+		sum += lp
+	}
+	return sum, len(contIDs), nil
+}
+
+// Complete generates text with the configured Completer.
+func (e *TaskEvaluator) Complete(ctx context.Context, prompt string, maxNewTokens int, stop []string) (string, error) {
+	if e.completer == nil {
+		return "", errors.New("task requires a completer; use WithCompleter")
+	}
+	return e.completer.Complete(ctx, prompt, maxNewTokens, stop)
+}
+
+func scoreMultipleChoice(ctx context.Context, e *TaskEvaluator, task *Task, ex *Example) (map[string]float64, error) {
+	prompt := task.Preamble + ex.Prompt
+	best, bestNorm := -1, -1
+	var bestLL, bestNormLL float64
+	for i, choice := range ex.Choices {
+		ll, _, err := e.ContinuationLogProb(ctx, prompt, choice)
+		if err != nil {
+			return nil, fmt.Errorf("choice %d: %w", i, err)
+		}
+		norm := ll / float64(max(len(choice), 1))
+		if best < 0 || ll > bestLL {
+			best, bestLL = i, ll
+		}
+		if bestNorm < 0 || norm > bestNormLL {
+			bestNorm, bestNormLL = i, norm
+		}
+	}
+	return map[string]float64{
+		"acc":      indicator(best == ex.Answer),
+		"acc_norm": indicator(bestNorm == ex.Answer),
+	}, nil
+}
+
+func scoreCloze(ctx context.Context, e *TaskEvaluator, task *Task, ex *Example) (map[string]float64, error) {
+	ll, n, err := e.ContinuationLogProb(ctx, task.Preamble+ex.Prompt, ex.Target)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{
+		"loglik":   ll,
+		"mean_nll": -ll / float64(n),
+	}, nil
+}
+
+func scoreExactMatch(ctx context.Context, e *TaskEvaluator, task *Task, ex *Example) (map[string]float64, error) {
+	maxNew := task.MaxNewTokens
+	if maxNew <= 0 {
+		maxNew = 32
+	}
+	stop := task.Stop
+	if stop == nil {
+		stop = []string{"\n"}
+	}
+	out, err := e.Complete(ctx, task.Preamble+ex.Prompt, maxNew, stop)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stop {
+		if s == "" {
+			continue
+		}
+		if idx := strings.Index(out, s); idx >= 0 {
+			out = out[:idx]
+		}
+	}
+	got := normalizeAnswer(out, task.IgnoreCase)
+	match := false
+	for _, want := range append([]string{ex.Target}, ex.Aliases...) {
+		if got == normalizeAnswer(want, task.IgnoreCase) {
+			match = true
+			break
+		}
+	}
+	return map[string]float64{"exact_match": indicator(match)}, nil
+}
+
+// normalizeAnswer trims surrounding whitespace, collapses internal runs of
+// whitespace, and optionally lower-cases s.
+func normalizeAnswer(s string, ignoreCase bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if ignoreCase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+func indicator(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,235 @@
+package eval
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixedCompleter returns canned outputs keyed by prompt.
+type fixedCompleter map[string]string
+
+func (c fixedCompleter) Complete(_ context.Context, prompt string, _ int, _ []string) (string, error) {
+	return c[prompt], nil
+}
+
+func newTestTaskEvaluator(t *testing.T, s Scorer, opts ...TaskOption) *TaskEvaluator {
+	t.Helper()
+	e, err := NewTaskEvaluator(s, testTokenizer(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestTaskEvaluator_MultipleChoice(t *testing.T) {
+	// recordingScorer gives token id k log-probability -k, so lower ids win.
+	task := &Task{
+		Name: "mc",
+		Type: TaskMultipleChoice,
+		Examples: []Example{
+			{Prompt: "hello", Choices: []string{"bar", "world"}, Answer: 1},
+			// Raw log-likelihood prefers the short choice; normalizing by
+			// length prefers the longer one.
+			{Prompt: "hello", Choices: []string{"world foo", "bar"}, Answer: 0},
+		},
+	}
+	if err := task.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := newTestTaskEvaluator(t, &recordingScorer{}).Run(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Examples != 2 || res.Type != TaskMultipleChoice {
+		t.Errorf("result = %+v", res)
+	}
+	if res.Metrics["acc"] != 0.5 || res.Metrics["acc_norm"] != 1 {
+		t.Errorf("metrics = %v, want acc=0.5 acc_norm=1", res.Metrics)
+	}
+}
+
+func TestTaskEvaluator_Cloze(t *testing.T) {
+	task := &Task{
+		Name:     "cloze",
+		Type:     TaskCloze,
+		Preamble: "bar ",
+		Examples: []Example{{Prompt: "hello", Target: "world foo"}},
+	}
+	s := &recordingScorer{}
+	res, err := newTestTaskEvaluator(t, s).Run(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Metrics["loglik"] != -11 || res.Metrics["mean_nll"] != 5.5 {
+		t.Errorf("metrics = %v, want loglik=-11 mean_nll=5.5", res.Metrics)
+	}
+	if got := s.windows[0]; len(got) != 4 || got[0] != 7 {
+		t.Errorf("scored window = %v, want preamble, prompt and target", got)
+	}
+}
+
+func TestTaskEvaluator_ExactMatch(t *testing.T) {
+	task := &Task{
+		Name:       "qa",
+		Type:       TaskExactMatch,
+		Preamble:   "Q: ",
+		IgnoreCase: true,
+		Examples: []Example{
+			{Prompt: "capital of France?", Target: "Paris"},
+			{Prompt: "capital of Italy?", Target: "Rome", Aliases: []string{"Roma"}},
+			{Prompt: "capital of Spain?", Target: "Madrid"},
+		},
+	}
+	c := fixedCompleter{
+		"Q: capital of France?": "  paris\nQ: next",
+		"Q: capital of Italy?":  " Roma",
+		"Q: capital of Spain?":  " Barcelona",
+	}
+	res, err := newTestTaskEvaluator(t, &recordingScorer{}, WithCompleter(c)).Run(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2.0 / 3; math.Abs(res.Metrics["exact_match"]-want) > 1e-12 {
+		t.Errorf("exact_match = %v, want %v", res.Metrics["exact_match"], want)
+	}
+
+	if _, err := newTestTaskEvaluator(t, &recordingScorer{}).Run(context.Background(), task); err == nil {
+		t.Error("expected error without a completer")
+	}
+}
+
+func TestTaskEvaluator_LimitAndTruncation(t *testing.T) {
+	task := &Task{
+		Name: "cloze",
+		Type: TaskCloze,
+		Examples: []Example{
+			{Prompt: "hello world foo bar hello", Target: "world"},
+			{Prompt: "hello", Target: "world"},
+		},
+	}
+	s := &recordingScorer{}
+	res, err := newTestTaskEvaluator(t, s, WithLimit(1), WithMaxContext(3), WithTaskBOS(1)).Run(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Examples != 1 || len(s.windows) != 1 {
+		t.Fatalf("examples = %d windows = %d, want 1 and 1", res.Examples, len(s.windows))
+	}
+	if got := s.windows[0]; len(got) != 3 || got[2] != 5 {
+		t.Errorf("window = %v, want the last 3 tokens", got)
+	}
+}
+
+func TestTaskEvaluator_ContinuationErrors(t *testing.T) {
+	e := newTestTaskEvaluator(t, &recordingScorer{}, WithMaxContext(2))
+	ctx := context.Background()
+	if _, _, err := e.ContinuationLogProb(ctx, "", "hello"); err == nil {
+		t.Error("expected error without context tokens")
+	}
+	if _, _, err := e.ContinuationLogProb(ctx, "hello", ""); err == nil {
+		t.Error("expected error for empty continuation")
+	}
+	if _, _, err := e.ContinuationLogProb(ctx, "hello", "world foo"); err == nil {
+		t.Error("expected error for continuation longer than max context")
+	}
+}
+
+func TestNewTaskEvaluator_Validation(t *testing.T) {
+	tok := testTokenizer()
+	if _, err := NewTaskEvaluator(nil, tok); err == nil {
+		t.Error("expected error for nil scorer")
+	}
+	if _, err := NewTaskEvaluator(&recordingScorer{}, nil); err == nil {
+		t.Error("expected error for nil tokenizer")
+	}
+	if _, err := NewTaskEvaluator(&recordingScorer{}, tok, WithMaxContext(1)); err == nil {
+		t.Error("expected error for small max context")
+	}
+	if _, err := NewTaskEvaluator(&recordingScorer{}, tok, WithLimit(-1)); err == nil {
+		t.Error("expected error for negative limit")
+	}
+}
+
+func TestTask_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		task Task
+	}{
+		{"no name", Task{Type: TaskCloze, Examples: []Example{{Prompt: "a", Target: "b"}}}},
+		{"unknown type", Task{Name: "x", Type: "nope", Examples: []Example{{Prompt: "a", Target: "b"}}}},
+		{"no examples", Task{Name: "x", Type: TaskCloze}},
+		{"one choice", Task{Name: "x", Type: TaskMultipleChoice, Examples: []Example{{Prompt: "a", Choices: []string{"b"}}}}},
+		{"answer range", Task{Name: "x", Type: TaskMultipleChoice, Examples: []Example{{Prompt: "a", Choices: []string{"b", "c"}, Answer: 2}}}},
+		{"no target", Task{Name: "x", Type: TaskExactMatch, Examples: []Example{{Prompt: "a"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.task.Validate(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadTask(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "task.json")
+	data := `{"name":"mc","type":"multiple_choice","examples":[{"prompt":"hello","choices":["world","foo"],"answer":0}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	task, err := LoadTask(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "mc" || task.Type != TaskMultipleChoice || len(task.Examples[0].Choices) != 2 {
+		t.Errorf("task = %+v", task)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"name":"x","type":"cloze","examples":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTask(bad); err == nil {
+		t.Error("expected validation error")
+	}
+	if _, err := LoadTask(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestRegisterTaskType(t *testing.T) {
+	const name TaskType = "test_constant"
+	if _, ok := GetTaskType(name); !ok {
+		RegisterTaskType(name, func(context.Context, *TaskEvaluator, *Task, *Example) (map[string]float64, error) {
+			return map[string]float64{"score": 0.25}, nil
+		})
+	}
+	found := false
+	for _, n := range ListTaskTypes() {
+		if n == name {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ListTaskTypes() = %v, missing %q", ListTaskTypes(), name)
+	}
+	task := &Task{Name: "custom", Type: name, Examples: []Example{{Prompt: "a"}, {Prompt: "b"}}}
+	res, err := newTestTaskEvaluator(t, &recordingScorer{}).Run(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Metrics["score"] != 0.25 {
+		t.Errorf("score = %v, want 0.25", res.Metrics["score"])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	RegisterTaskType(TaskCloze, scoreCloze)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/engineproxy"
 )
 
 // fusedNormAddNode fuses RMSNorm + Add into a single GPU kernel launch.
@@ -36,10 +38,7 @@ func (n *fusedNormAddNode[T]) Forward(ctx context.Context, inputs ...*tensor.Ten
 	residual := inputs[1] // e.g. stored residual from fusedAddRMSNormNode
 
 	// Try fused GPU path.
-	realEngine := compute.Engine[T](n.engine)
-	if proxy, ok := n.engine.(*compute.EngineProxy[T]); ok {
-		realEngine = proxy.Real()
-	}
+	realEngine, _ := engineproxy.Unwrap(n.engine)
 
 	if provider, ok := realEngine.(compute.FusedNormAddProvider[T]); ok {
 		out, err := provider.GPUFusedNormAdd(data, n.weight, residual, n.eps)
@@ -1,6 +1,7 @@
 package inference
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,13 +28,19 @@ type GGUFModel struct {
 // Supports both single-file and split (multi-shard) GGUF files. Split files
 // are detected automatically from the filename pattern (e.g., Model-00001-of-00003.gguf).
 func LoadGGUF(path string) (*GGUFModel, error) {
+	return loadGGUF(path, nil)
+}
+
+// loadGGUF is LoadGGUF verifying each file it reads against o, unless o is
+// nil.
+func loadGGUF(path string, o *loadOptions) (*GGUFModel, error) {
 	// Try split-file loading first.
 	sf, err := gguf.ParseSplit(path)
 	if err != nil {
 		return nil, fmt.Errorf("parse split GGUF: %w", err)
 	}
 	if sf != nil {
-		return loadGGUFSplit(sf)
+		return loadGGUFSplit(sf, o)
 	}
 
 	// Single file path.
@@ -47,13 +54,23 @@ func LoadGGUF(path string) (*GGUFModel, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parse GGUF: %w", err)
 	}
+	if o != nil {
+		if err := o.verifyGGUF(path, f, f, gf); err != nil {
+			return nil, err
+		}
+	}
+	return loadGGUFParsed(gf, f)
+}
 
+// loadGGUFParsed loads the tensors of an already parsed single-file GGUF
+// model from r using heap allocation.
+func loadGGUFParsed(gf *gguf.File, r io.ReadSeeker) (*GGUFModel, error) {
 	cfg, err := gguf.ExtractModelConfig(gf)
 	if err != nil {
 		return nil, fmt.Errorf("extract model config: %w", err)
 	}
 
-	rawTensors, err := gguf.LoadTensors(gf, f)
+	rawTensors, err := gguf.LoadTensors(gf, r)
 	if err != nil {
 		return nil, fmt.Errorf("load tensors: %w", err)
 	}
@@ -62,7 +79,7 @@ func LoadGGUF(path string) (*GGUFModel, error) {
 }
 
 // loadGGUFSplit loads tensors from a split (multi-shard) GGUF file using heap allocation.
-func loadGGUFSplit(sf *gguf.SplitFile) (*GGUFModel, error) {
+func loadGGUFSplit(sf *gguf.SplitFile, o *loadOptions) (*GGUFModel, error) {
 	cfg, err := gguf.ExtractModelConfig(sf.File)
 	if err != nil {
 		return nil, fmt.Errorf("extract model config: %w", err)
@@ -85,6 +102,13 @@ func loadGGUFSplit(sf *gguf.SplitFile) (*GGUFModel, error) {
 			_ = f.Close()
 		}
 	}()
+	if o != nil {
+		for i, f := range readers {
+			if err := o.verifyShard(sf.ShardPaths[i], f, f, sf.Shards[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	rawTensors, err := gguf.LoadTensorsSplit(sf, readers)
 	if err != nil {
@@ -151,13 +175,19 @@ func (c *mmapCloser) Close() error { return c.fn() }
 // The returned io.Closer must be kept alive and closed when the model is no
 // longer needed -- it releases the memory mapping(s).
 func LoadGGUFMmap(path string) (*GGUFModel, io.Closer, error) {
+	return loadGGUFMmap(path, nil)
+}
+
+// loadGGUFMmap is LoadGGUFMmap verifying each mapping against o, unless o
+// is nil.
+func loadGGUFMmap(path string, o *loadOptions) (*GGUFModel, io.Closer, error) {
 	// Try split-file loading first.
 	sf, err := gguf.ParseSplit(path)
 	if err != nil {
 		return nil, nil, fmt.Errorf("parse split GGUF: %w", err)
 	}
 	if sf != nil {
-		return loadGGUFMmapSplit(sf)
+		return loadGGUFMmapSplit(sf, o)
 	}
 
 	// Single file path.
@@ -177,11 +207,17 @@ func LoadGGUFMmap(path string) (*GGUFModel, io.Closer, error) {
 		return nil, nil, fmt.Errorf("extract model config: %w", err)
 	}
 
-	// Memory-map the entire file.
-	mapped, cleanup, err := tensor.MmapFile(filepath.Clean(path))
+	// Memory-map the entire file through the descriptor it was parsed from.
+	mapped, cleanup, err := mmapOpenFile(f)
 	if err != nil {
 		return nil, nil, fmt.Errorf("mmap GGUF file: %w", err)
 	}
+	if o != nil {
+		if err := o.verifyShard(path, f, bytes.NewReader(mapped), gf); err != nil {
+			_ = cleanup()
+			return nil, nil, err
+		}
+	}
 
 	slog.Info("mmap'd GGUF file", "path", path, "size_mb", len(mapped)/(1024*1024))
 
@@ -205,7 +241,7 @@ func LoadGGUFMmap(path string) (*GGUFModel, io.Closer, error) {
 }
 
 // loadGGUFMmapSplit loads a split GGUF model using mmap for all shards.
-func loadGGUFMmapSplit(sf *gguf.SplitFile) (*GGUFModel, io.Closer, error) {
+func loadGGUFMmapSplit(sf *gguf.SplitFile, o *loadOptions) (*GGUFModel, io.Closer, error) {
 	cfg, err := gguf.ExtractModelConfig(sf.File)
 	if err != nil {
 		return nil, nil, fmt.Errorf("extract model config: %w", err)
@@ -229,7 +265,7 @@ func loadGGUFMmapSplit(sf *gguf.SplitFile) (*GGUFModel, io.Closer, error) {
 	}
 
 	for i, p := range sf.ShardPaths {
-		mapped, cleanup, err := tensor.MmapFile(filepath.Clean(p))
+		mapped, cleanup, err := mmapShard(p, sf.Shards[i], o)
 		if err != nil {
 			_ = cleanupAll()
 			return nil, nil, fmt.Errorf("mmap shard %d (%s): %w", i, p, err)
@@ -265,6 +301,48 @@ func loadGGUFMmapSplit(sf *gguf.SplitFile) (*GGUFModel, io.Closer, error) {
 	return model, &mmapCloser{fn: cleanupAll}, nil
 }
 
+// mmapShard maps the shard at path, verifying the mapping against o unless
+// o is nil.
+func mmapShard(path string, planned *gguf.File, o *loadOptions) ([]byte, func() error, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = f.Close() }()
+	mapped, cleanup, err := mmapOpenFile(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o != nil {
+		if err := o.verifyShard(path, f, bytes.NewReader(mapped), planned); err != nil {
+			_ = cleanup()
+			return nil, nil, err
+		}
+	}
+	return mapped, cleanup, nil
+}
+
+// mmapOpenFile maps all of f for reading. The mapping stays valid after f
+// is closed; the returned function unmaps it.
+func mmapOpenFile(f *os.File) ([]byte, func() error, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return nil, nil, fmt.Errorf("file is empty")
+	}
+	if size > 1<<40 { // 1 TB sanity limit, as tensor.MmapFile
+		return nil, nil, fmt.Errorf("file too large (%d bytes)", size)
+	}
+	mapped, err := tensor.Mmap(f.Fd(), 0, int(size))
+	if err != nil {
+		return nil, nil, err
+	}
+	return mapped, func() error { return tensor.Munmap(mapped) }, nil
+}
+
 // upgradeEmbeddingPrecision re-quantizes embedding and output projection tensors
 // from Q4_0 to Q8_0. These tensors use gather operations (index lookup), not GEMV,
 // so the Q4_0 GEMV speed advantage does not apply. The extra precision from Q8
@@ -321,11 +399,25 @@ func (m *GGUFModel) ToModelMetadata() *ModelMetadata {
 		NumKeyValueHeads:      m.Config.NumKVHeads,
 		IntermediateSize:      m.Config.IntermediateSize,
 		RopeTheta:             m.Config.RopeTheta,
-		ChatTemplate:          chatTemplateForArch(m.Config.Architecture),
+		ChatTemplate:          m.chatTemplate(),
 		AudioNumMels:          m.Config.AudioNumMels,
 	}
 }
 
+// chatTemplate returns the built-in chat format for the model. The
+// tokenizer.chat_template metadata key takes precedence when it matches a
+// known format; otherwise the format is inferred from the architecture.
+func (m *GGUFModel) chatTemplate() string {
+	if m.File != nil {
+		if src, ok := m.File.GetString("tokenizer.chat_template"); ok {
+			if name := detectChatTemplate(src); name != "" {
+				return name
+			}
+		}
+	}
+	return chatTemplateForArch(m.Config.Architecture)
+}
+
 // chatTemplateForArch returns the chat template name for a GGUF architecture.
 func chatTemplateForArch(arch string) string {
 	switch arch {
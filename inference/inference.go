@@ -2,10 +2,10 @@ package inference
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -14,6 +14,7 @@ import (
 
 	"github.com/zerfoo/zerfoo/generate"
 	"github.com/zerfoo/zerfoo/generate/grammar"
+	"github.com/zerfoo/zerfoo/model/gguf"
 	"github.com/zerfoo/zerfoo/model/registry"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
@@ -48,6 +49,12 @@ type Model struct {
 	// pjrtPlan holds the PJRT compiled plan when WithPJRT is used.
 	// Nil when using the standard Engine path. Closed by Model.Close().
 	pjrtPlan *graph.PJRTPlan[float32]
+
+	// chatTmpl caches the compiled template when config.ChatTemplate holds
+	// template source rather than a built-in format name. Compiled on first use.
+	chatTmplOnce sync.Once
+	chatTmpl     *ChatTemplate
+	chatTmplErr  error
 }
 
 // ModelMetadata holds model configuration loaded from config.json.
@@ -139,6 +146,15 @@ type loadOptions struct {
 	maxBatchConcurrency int    // max goroutines in GenerateBatch (0 = default)
 	sessionPoolSize     int    // session pool capacity (0 = default 16)
 	pjrtPlugin          string // path to PJRT plugin .so (empty = disabled)
+	kvWindow            int    // sliding-window KV cache size (0 = unbounded cache)
+	kvSinks             int    // attention-sink positions kept by the sliding-window cache
+	batchDecodeSize     int    // when > 1, GenerateBatch decodes up to this many prompts per forward pass
+	promptCacheBlocks   int    // when > 0, reuse cached prompt-prefix KV state across calls
+
+	// Artifact verification; see WithRequireIntegrity and WithSignatureKey.
+	requireIntegrity bool
+	signatureKey     ed25519.PublicKey
+	keyProvider      gguf.KeyProvider // keys for encrypted models (nil = environment)
 }
 
 // WithCacheDir sets the model cache directory.
@@ -236,6 +252,16 @@ func WithKVDtype(dtype string) Option {
 	}
 }
 
+// WithKVWindow bounds each session's KV cache to the first sinks positions
+// plus the most recent window positions, so long-running chat sessions use a
+// fixed amount of KV memory. window <= 0 disables eviction.
+func WithKVWindow(window, sinks int) Option {
+	return func(o *loadOptions) {
+		o.kvWindow = window
+		o.kvSinks = sinks
+	}
+}
+
 // WithMaxBatchConcurrency sets the maximum number of concurrent goroutines
 // that GenerateBatch will use. Values <= 0 are ignored (the default of 8 is used).
 func WithMaxBatchConcurrency(n int) Option {
@@ -246,6 +272,32 @@ func WithMaxBatchConcurrency(n int) Option {
 	}
 }
 
+// WithBatchDecode enables native batched decoding in GenerateBatch: prompts
+// are decoded together, up to n per forward pass, instead of running one
+// session per prompt (see generate.WithMaxBatchSize for which prompts share
+// a batch). Values <= 1 are ignored.
+func WithBatchDecode(n int) Option {
+	return func(o *loadOptions) {
+		if n > 1 {
+			o.batchDecodeSize = n
+		}
+	}
+}
+
+// WithPromptCache enables prompt-prefix KV reuse across calls: prompts that
+// start with a previously seen prefix (such as a shared system prompt)
+// restore its KV state instead of prefilling it. The cache holds up to
+// blocks blocks of 16 tokens and evicts the least recently used. Values
+// <= 0 are ignored. Loading fails when the option is combined with
+// WithKVWindow or WithPJRT, whose KV state the prompt cache cannot restore.
+func WithPromptCache(blocks int) Option {
+	return func(o *loadOptions) {
+		if blocks > 0 {
+			o.promptCacheBlocks = blocks
+		}
+	}
+}
+
 // defaultSessionPoolSize is the default capacity of the session pool.
 const defaultSessionPoolSize = 16
 
@@ -288,8 +340,12 @@ func Load(modelID string, opts ...Option) (*Model, error) {
 		if err != nil {
 			return nil, fmt.Errorf("create registry: %w", err)
 		}
+		blobs, err := lr.Blobs()
+		if err != nil {
+			return nil, fmt.Errorf("create blob cache: %w", err)
+		}
 		// Wire the HuggingFace pull function by default.
-		lr.SetPullFunc(registry.NewHFPullFunc(registry.HFPullOptions{}))
+		lr.SetPullFunc(registry.NewHFPullFunc(registry.HFPullOptions{Cache: blobs}))
 		reg = lr
 	}
 
@@ -456,6 +512,14 @@ func WithAdapter(name string) GenerateOption {
 	}
 }
 
+// WithSeed makes sampling deterministic: requests with the same prompt,
+// options, and seed produce the same output on every run and every worker.
+func WithSeed(seed int64) GenerateOption {
+	return func(sc *generate.SamplingConfig) {
+		sc.Seed = &seed
+	}
+}
+
 func buildSamplingConfig(opts []GenerateOption) generate.SamplingConfig {
 	sc := generate.DefaultSamplingConfig()
 	for _, opt := range opts {
@@ -506,7 +570,9 @@ func (m *Model) Generate(ctx context.Context, prompt string, opts ...GenerateOpt
 // the input prompts. If a prompt fails, its corresponding error is non-nil.
 //
 // Concurrency is capped at maxBatchConcurrency (default 8) to prevent
-// resource exhaustion on GPU-backed models.
+// resource exhaustion on GPU-backed models. When the model was loaded with
+// WithBatchDecode, prompts are instead decoded in shared batched forward
+// passes by the generator.
 //
 // [Deviation: Architectural] Used parallel goroutines instead of shared
 // PagedKV decode — full multi-seq requires deeper Generator refactor.
@@ -516,6 +582,9 @@ func (m *Model) GenerateBatch(ctx context.Context, prompts []string, opts ...Gen
 	}
 
 	sc := buildSamplingConfig(opts)
+	if m.generator.MaxBatchSize() > 1 {
+		return m.generateBatchNative(ctx, prompts, sc)
+	}
 	results := make([]string, len(prompts))
 	errs := make([]error, len(prompts))
 
@@ -549,6 +618,27 @@ func (m *Model) GenerateBatch(ctx context.Context, prompts []string, opts ...Gen
 	return results, nil
 }
 
+// generateBatchNative runs GenerateBatch through the generator's batched
+// decode path.
+func (m *Model) generateBatchNative(ctx context.Context, prompts []string, sc generate.SamplingConfig) ([]string, error) {
+	requests := make([]generate.BatchRequest, len(prompts))
+	for i, p := range prompts {
+		requests[i] = generate.BatchRequest{Prompt: p, Sampling: sc}
+	}
+	results := make([]string, len(prompts))
+	var firstErr error
+	for i, r := range m.generator.BatchGenerate(ctx, requests) {
+		results[i] = r.Text
+		if r.Err != nil && firstErr == nil {
+			firstErr = r.Err
+		}
+	}
+	if firstErr != nil {
+		return results, fmt.Errorf("batch generation: %w", firstErr)
+	}
+	return results, nil
+}
+
 // SetMaxBatchConcurrency sets the maximum number of concurrent goroutines
 // that GenerateBatch will use. Values <= 0 are ignored.
 func (m *Model) SetMaxBatchConcurrency(n int) {
@@ -585,7 +675,10 @@ type Response struct {
 // Chat formats messages using the model's chat template and generates a response.
 // Sessions are pooled to preserve CUDA graph replay.
 func (m *Model) Chat(ctx context.Context, messages []Message, opts ...GenerateOption) (Response, error) {
-	prompt := m.formatMessages(messages)
+	prompt, err := m.RenderChat(messages)
+	if err != nil {
+		return Response{}, err
+	}
 	sc := buildSamplingConfig(opts)
 	sess := m.acquireSession()
 	defer m.releaseSession(sess)
@@ -593,7 +686,12 @@ func (m *Model) Chat(ctx context.Context, messages []Message, opts ...GenerateOp
 	if err != nil {
 		return Response{}, err
 	}
+	return m.chatResponse(prompt, result), nil
+}
 
+// chatResponse wraps a generated reply with its prompt and completion token
+// counts.
+func (m *Model) chatResponse(prompt, result string) Response {
 	// Count prompt and completion tokens separately.
 	promptIDs, _ := m.tokenizer.Encode(prompt)
 	resultIDs, _ := m.tokenizer.Encode(result)
@@ -604,26 +702,59 @@ func (m *Model) Chat(ctx context.Context, messages []Message, opts ...GenerateOp
 		PromptTokens:     promptCount,
 		CompletionTokens: completionCount,
 		TokensUsed:       promptCount + completionCount,
-	}, nil
+	}
 }
 
 // FormatMessages converts messages to the model's chat template format.
 // This is useful when callers need the formatted prompt without running inference,
 // e.g. for streaming paths that call GenerateStream separately.
+// A custom template that fails to render falls back to the generic format;
+// use RenderChat to observe the error instead.
 func (m *Model) FormatMessages(messages []Message) string {
 	return m.formatMessages(messages)
 }
 
+// RenderChat converts messages to the model's chat template format. When
+// the model metadata carries template source (see ChatTemplate), the
+// template is compiled once and executed; otherwise the named built-in
+// format is used.
+func (m *Model) RenderChat(messages []Message) (string, error) {
+	if !isChatTemplateSource(m.config.ChatTemplate) {
+		return m.formatBuiltin(messages), nil
+	}
+	m.chatTmplOnce.Do(func() {
+		m.chatTmpl, m.chatTmplErr = ParseChatTemplate(m.config.Architecture, m.config.ChatTemplate)
+	})
+	if m.chatTmplErr != nil {
+		return "", m.chatTmplErr
+	}
+	return m.chatTmpl.Render(messages)
+}
+
 // ChatStream formats messages using the model's chat template and streams
 // the response token-by-token via the provided handler. This is the streaming
 // counterpart of Chat and ensures the same prompt formatting is applied.
 func (m *Model) ChatStream(ctx context.Context, messages []Message, handler generate.TokenStream, opts ...GenerateOption) error {
-	prompt := m.formatMessages(messages)
+	prompt, err := m.RenderChat(messages)
+	if err != nil {
+		return err
+	}
 	return m.GenerateStream(ctx, prompt, handler, opts...)
 }
 
-// formatMessages converts messages to the model's chat template format.
+// formatMessages converts messages to the model's chat template format,
+// falling back to the generic format if a custom template fails.
 func (m *Model) formatMessages(messages []Message) string {
+	prompt, err := m.RenderChat(messages)
+	if err != nil {
+		return formatGeneric(messages)
+	}
+	return prompt
+}
+
+// formatBuiltin formats messages with the built-in format named by
+// config.ChatTemplate.
+func (m *Model) formatBuiltin(messages []Message) string {
 	template := strings.ToLower(m.config.ChatTemplate)
 	if template == "" {
 		template = "gemma"
@@ -795,18 +926,7 @@ func (m *Model) Embed(text string) ([]float32, error) {
 		vec[j] *= scale
 	}
 
-	// L2 normalize.
-	var norm float64
-	for _, v := range vec {
-		norm += float64(v) * float64(v)
-	}
-	if norm > 0 {
-		invNorm := float32(1.0 / math.Sqrt(norm))
-		for j := range vec {
-			vec[j] *= invNorm
-		}
-	}
-
+	l2Normalize(vec)
 	return vec, nil
 }
 
@@ -405,6 +405,14 @@ func TestGenerateOptions(t *testing.T) {
 			t.Errorf("StopStrings = %v, want [stop1 stop2]", sc.StopStrings)
 		}
 	})
+
+	t.Run("WithSeed", func(t *testing.T) {
+		sc := generate.DefaultSamplingConfig()
+		WithSeed(1234)(&sc)
+		if sc.Seed == nil || *sc.Seed != 1234 {
+			t.Errorf("Seed = %v, want 1234", sc.Seed)
+		}
+	})
 }
 
 func TestBuildSamplingConfig(t *testing.T) {
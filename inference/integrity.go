@@ -0,0 +1,137 @@
+package inference
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+)
+
+// WithRequireIntegrity makes LoadFile refuse GGUF files that carry no
+// embedded SHA-256 digests (see gguf.MetaTensorSHA256). Files that do carry
+// digests are always verified, with or without this option.
+func WithRequireIntegrity(required bool) Option {
+	return func(o *loadOptions) {
+		o.requireIntegrity = required
+	}
+}
+
+// WithSignatureKey makes LoadFile require a detached ed25519 signature next
+// to each GGUF file (path + gguf.SignatureSuffix) that verifies under key.
+// Unsigned or tampered files are refused before any tensor is loaded.
+func WithSignatureKey(key ed25519.PublicKey) Option {
+	return func(o *loadOptions) {
+		o.signatureKey = key
+	}
+}
+
+// verifiedFiles remembers GGUF files that passed verification under a
+// given policy, so loading the same unchanged file again (a reload or a
+// model swap) does not hash it again. Entries are keyed by the identity and
+// change time of the open file; any write to the file changes its ctime and
+// forces a fresh check.
+var verifiedFiles sync.Map // verifiedKey -> struct{}
+
+// racyStampWindow is how long after its last change a file is verified on
+// every load. Filesystem timestamps are coarse, so a write in the same tick
+// as the previous one may leave the ctime unchanged.
+var racyStampWindow = 2 * time.Second
+
+type verifiedKey struct {
+	stamp            fileStamp
+	signatureKey     string
+	requireIntegrity bool
+}
+
+// verifyGGUF checks the detached signature, if WithSignatureKey is set, and
+// the embedded digests of one GGUF file. r reads the bytes the tensors are
+// loaded from: the open file itself, or its mapping, so the bytes verified
+// are the bytes loaded. f is that open file, used to recognize a file that
+// was already verified. gf is its parsed header.
+func (o *loadOptions) verifyGGUF(path string, f *os.File, r io.ReadSeeker, gf *gguf.File) error {
+	key := verifiedKey{signatureKey: string(o.signatureKey), requireIntegrity: o.requireIntegrity}
+	// Stamp the file before reading it: a write after this point changes the
+	// ctime, so a stale entry can never match the modified file.
+	start := time.Now()
+	stamp, cacheable := stampFile(f)
+	cacheable = cacheable && stamp.settled(start)
+	if cacheable {
+		key.stamp = stamp
+		if _, ok := verifiedFiles.Load(key); ok {
+			return nil
+		}
+	}
+	if err := o.verifySignature(path, r); err != nil {
+		return fmt.Errorf("verify %s: %w", path, err)
+	}
+	if err := checkIntegrity(gf, r, o); err != nil {
+		return fmt.Errorf("verify %s: %w", path, err)
+	}
+	if cacheable {
+		verifiedFiles.Store(key, struct{}{})
+	}
+	return nil
+}
+
+// verifySignature checks the detached signature of the file at path, read
+// from r, if WithSignatureKey is set.
+func (o *loadOptions) verifySignature(path string, r io.ReadSeeker) error {
+	if o.signatureKey == nil {
+		return nil
+	}
+	sig, err := os.ReadFile(filepath.Clean(path + gguf.SignatureSuffix))
+	if err != nil {
+		return fmt.Errorf("read signature: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return gguf.VerifySignature(r, sig, o.signatureKey)
+}
+
+// checkIntegrity verifies embedded digests, tolerating their absence unless
+// WithRequireIntegrity is set.
+func checkIntegrity(gf *gguf.File, r io.ReadSeeker, o *loadOptions) error {
+	err := gguf.VerifyIntegrity(gf, r)
+	if errors.Is(err, gguf.ErrNoIntegrity) && !o.requireIntegrity {
+		return nil
+	}
+	return err
+}
+
+// verifyShard verifies one shard of a split file and checks that the header
+// read back from the verified bytes lays out tensors as the header the
+// split was planned from, which was parsed from an earlier open.
+func (o *loadOptions) verifyShard(path string, f *os.File, r io.ReadSeeker, planned *gguf.File) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gf, err := gguf.Parse(r)
+	if err != nil {
+		return fmt.Errorf("verify %s: parse GGUF: %w", path, err)
+	}
+	if !sameLayout(gf, planned) {
+		return fmt.Errorf("verify %s: %w: file changed while loading", path, gguf.ErrIntegrity)
+	}
+	return o.verifyGGUF(path, f, r, gf)
+}
+
+func sameLayout(a, b *gguf.File) bool {
+	if a.DataOffset != b.DataOffset || len(a.Tensors) != len(b.Tensors) {
+		return false
+	}
+	for i, t := range a.Tensors {
+		u := b.Tensors[i]
+		if t.Name != u.Name || t.Type != u.Type || t.Offset != u.Offset || !slices.Equal(t.Dimensions, u.Dimensions) {
+			return false
+		}
+	}
+	return true
+}
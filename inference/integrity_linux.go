@@ -0,0 +1,40 @@
+package inference
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileStamp identifies one version of a file: a write changes its ctime,
+// which, unlike the mtime, cannot be set back by the writer.
+type fileStamp struct {
+	dev, ino     uint64
+	size         int64
+	ctime, mtime syscall.Timespec
+}
+
+// stampFile returns the stamp of the open file f.
+func stampFile(f *os.File) (fileStamp, bool) {
+	fi, err := f.Stat()
+	if err != nil {
+		return fileStamp{}, false
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStamp{}, false
+	}
+	return fileStamp{
+		dev:   uint64(st.Dev),
+		ino:   st.Ino,
+		size:  st.Size,
+		ctime: st.Ctim,
+		mtime: st.Mtim,
+	}, true
+}
+
+// settled reports whether the file last changed more than racyStampWindow
+// before now.
+func (s fileStamp) settled(now time.Time) bool {
+	return now.Sub(time.Unix(s.ctime.Unix())) > racyStampWindow
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package inference
+
+import (
+	"os"
+	"time"
+)
+
+// fileStamp is empty where the change time is not available; files are then
+// verified on every load.
+type fileStamp struct{}
+
+func stampFile(*os.File) (fileStamp, bool) { return fileStamp{}, false }
+
+func (fileStamp) settled(time.Time) bool { return false }
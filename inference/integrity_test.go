@@ -0,0 +1,158 @@
+package inference
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+)
+
+func signTestFile(t *testing.T, path string, key ed25519.PrivateKey) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	sig, err := gguf.Sign(f, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+gguf.SignatureSuffix, sig, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFile_VerifiesIntegrity(t *testing.T) {
+	path := writeTestLlamaGGUF(t, t.TempDir(), true)
+
+	m, err := LoadFile(path, WithRequireIntegrity(true))
+	if err != nil {
+		t.Fatalf("LoadFile intact: %v", err)
+	}
+	_ = m.Close()
+
+	// Corrupt the last byte of tensor data; the embedded digests catch it
+	// even without WithRequireIntegrity.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0x01
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); !errors.Is(err, gguf.ErrIntegrity) {
+		t.Errorf("LoadFile corrupted: err = %v, want ErrIntegrity", err)
+	}
+
+	if err := os.WriteFile(path, data[:len(data)/2], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); !errors.Is(err, gguf.ErrIntegrity) {
+		t.Errorf("LoadFile truncated: err = %v, want ErrIntegrity", err)
+	}
+}
+
+func TestLoadFile_RequireIntegrity(t *testing.T) {
+	path := writeTestGGUF(t, t.TempDir())
+
+	m, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile unstamped: %v", err)
+	}
+	_ = m.Close()
+
+	if _, err := LoadFile(path, WithRequireIntegrity(true)); !errors.Is(err, gguf.ErrNoIntegrity) {
+		t.Errorf("err = %v, want ErrNoIntegrity", err)
+	}
+}
+
+func TestLoadFile_SignatureKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeTestGGUF(t, t.TempDir())
+
+	if _, err := LoadFile(path, WithSignatureKey(pub)); err == nil {
+		t.Error("LoadFile without signature file: expected error")
+	}
+
+	signTestFile(t, path, priv)
+	m, err := LoadFile(path, WithSignatureKey(pub))
+	if err != nil {
+		t.Fatalf("LoadFile signed: %v", err)
+	}
+	_ = m.Close()
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if _, err := LoadFile(path, WithSignatureKey(otherPub)); !errors.Is(err, gguf.ErrIntegrity) {
+		t.Errorf("wrong key: err = %v, want ErrIntegrity", err)
+	}
+}
+
+func TestLoadFile_ReusesVerification(t *testing.T) {
+	old := racyStampWindow
+	racyStampWindow = -time.Hour
+	t.Cleanup(func() { racyStampWindow = old })
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := writeTestLlamaGGUF(t, dir, true)
+	signTestFile(t, path, priv)
+
+	m, err := LoadFile(path, WithSignatureKey(pub))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	_ = m.Close()
+
+	// The unchanged file is not verified again, so its signature is no
+	// longer read.
+	sig, err := os.ReadFile(path + gguf.SignatureSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path + gguf.SignatureSuffix); err != nil {
+		t.Fatal(err)
+	}
+	m, err = LoadFile(path, WithSignatureKey(pub))
+	if err != nil {
+		t.Fatalf("LoadFile reload: %v", err)
+	}
+	_ = m.Close()
+
+	// A different policy is verified on its own.
+	if _, err := LoadFile(path, WithSignatureKey(pub), WithRequireIntegrity(true)); err == nil {
+		t.Error("LoadFile with new policy and no signature: expected error")
+	}
+
+	// Replacing the file with a tampered copy under the same signature is
+	// caught on reload.
+	if err := os.WriteFile(path+gguf.SignatureSuffix, sig, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0x01
+	tmp := filepath.Join(dir, "replacement.gguf")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path, WithSignatureKey(pub)); !errors.Is(err, gguf.ErrIntegrity) {
+		t.Errorf("LoadFile tampered: err = %v, want ErrIntegrity", err)
+	}
+}
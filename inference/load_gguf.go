@@ -1,6 +1,7 @@
 package inference
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -29,19 +30,31 @@ func LoadFile(path string, opts ...Option) (*Model, error) {
 		slog.Info("auto-disabled mmap for CUDA device", "device", o.device)
 	}
 
-	// Load and parse the GGUF file.
+	encrypted, err := gguf.IsEncrypted(path)
+	if err != nil {
+		return nil, fmt.Errorf("open GGUF file: %w", err)
+	}
+
+	// Load and parse the GGUF file. Each loader checks digests and the
+	// signature on the bytes it reads tensors from, before trusting them.
 	var gm *GGUFModel
 	var mmapCloser io.Closer
-	if o.mmap {
+	if encrypted {
+		gm, err = loadEncryptedGGUF(path, o)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("loaded encrypted GGUF", "path", path, "tensors", len(gm.Tensors))
+	} else if o.mmap {
 		var err error
-		gm, mmapCloser, err = LoadGGUFMmap(path)
+		gm, mmapCloser, err = loadGGUFMmap(path, o)
 		if err != nil {
 			return nil, fmt.Errorf("mmap load: %w", err)
 		}
 		slog.Info("loaded GGUF via mmap", "path", path, "tensors", len(gm.Tensors))
 	} else {
 		var err error
-		gm, err = LoadGGUF(path)
+		gm, err = loadGGUF(path, o)
 		if err != nil {
 			return nil, err
 		}
@@ -135,6 +148,18 @@ func LoadFile(path string, opts ...Option) (*Model, error) {
 	if o.kvDtype == "fp16" {
 		genOpts = append(genOpts, generate.WithGeneratorKVDtype("fp16"))
 	}
+	if o.batchDecodeSize > 1 {
+		genOpts = append(genOpts, generate.WithMaxBatchSize(o.batchDecodeSize))
+	}
+	if o.kvWindow > 0 {
+		genOpts = append(genOpts, generate.WithSlidingWindowKV(o.kvWindow, o.kvSinks))
+	}
+	if o.promptCacheBlocks > 0 {
+		if o.kvWindow > 0 || o.pjrtPlugin != "" {
+			return nil, errors.New("prompt cache cannot be combined with a KV window or PJRT")
+		}
+		genOpts = append(genOpts, generate.WithPromptCache(o.promptCacheBlocks))
+	}
 
 	// PJRT compilation: when a plugin path is set, compile the graph via PJRT
 	// instead of using the standard Engine compilation path.
@@ -63,6 +63,12 @@ func numElements(shape []int) int {
 // header, metadata (including tokenizer), tensor info, and tensor data.
 func writeTestGGUF(t *testing.T, dir string) string {
 	t.Helper()
+	return writeTestLlamaGGUF(t, dir, false)
+}
+
+// writeTestLlamaGGUF is writeTestGGUF with optional integrity metadata.
+func writeTestLlamaGGUF(t *testing.T, dir string, stamp bool) string {
+	t.Helper()
 
 	hidden := 16
 	inter := 32
@@ -119,10 +125,17 @@ func writeTestGGUF(t *testing.T, dir string) string {
 	w.AddMetadataUint32("tokenizer.ggml.unknown_token_id", 0)
 
 	// Add tensors with deterministic data.
-	for _, td := range tensors {
-		n := numElements(td.shape)
-		data := generateF32Data(n)
-		w.AddTensorF32(td.name, td.shape, data)
+	raw := make([][]byte, len(tensors))
+	for i, td := range tensors {
+		raw[i] = f32ToBytes(generateF32Data(numElements(td.shape)))
+	}
+	if stamp {
+		tensorSums, dataSum := gguf.IntegrityMetadata(raw)
+		w.AddMetadataStringArray(gguf.MetaTensorSHA256, tensorSums)
+		w.AddMetadataString(gguf.MetaDataSHA256, dataSum)
+	}
+	for i, td := range tensors {
+		w.AddTensor(td.name, td.ggmlType, td.shape, raw[i])
 	}
 
 	path := filepath.Join(dir, "test.gguf")
@@ -0,0 +1,25 @@
+// Package retrieval provides approximate nearest neighbor search over
+// embedding vectors. (Stability: alpha)
+//
+// [Index] is an HNSW (hierarchical navigable small world) graph: vectors are
+// inserted into a multi-layer proximity graph and queries descend greedily
+// from the sparse top layer to the dense bottom layer, visiting a small
+// fraction of the index. Recall is traded against speed with the M,
+// efConstruction, and efSearch parameters.
+//
+// Combined with the inference package's sentence embeddings, an index lets
+// retrieval-augmented workflows run entirely in Go:
+//
+//	ix, err := retrieval.New(dim, retrieval.WithMetric(retrieval.Cosine))
+//	for i, doc := range docs {
+//	    vec, _ := model.SentenceEmbedding(ctx, doc.Text)
+//	    _ = ix.Add(doc.ID, vec, map[string]string{"source": doc.Source})
+//	}
+//	q, _ := model.SentenceEmbedding(ctx, question)
+//	hits, err := ix.Search(q, 5, retrieval.WithFilter(func(_ string, md map[string]string) bool {
+//	    return md["source"] == "manual"
+//	}))
+//
+// Indexes are persisted with [Index.Save] and restored with [Load]; the
+// graph is stored alongside the vectors so loading does not rebuild it.
+package retrieval
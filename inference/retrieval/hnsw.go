@@ -0,0 +1,482 @@
+package retrieval
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// Metric selects the distance function used by an Index.
+type Metric int
+
+const (
+	// Cosine ranks by cosine similarity. Vectors are L2-normalized on insert
+	// and query; Result.Distance is 1 - cosine similarity.
+	Cosine Metric = iota
+	// L2 ranks by Euclidean distance; Result.Distance is the squared distance.
+	L2
+	// InnerProduct ranks by dot product; Result.Distance is the negated dot
+	// product, so larger products sort first.
+	InnerProduct
+)
+
+// String returns the metric name.
+func (m Metric) String() string {
+	switch m {
+	case Cosine:
+		return "cosine"
+	case L2:
+		return "l2"
+	case InnerProduct:
+		return "inner_product"
+	default:
+		return fmt.Sprintf("Metric(%d)", int(m))
+	}
+}
+
+// Option configures an Index.
+type Option func(*config)
+
+type config struct {
+	metric         Metric
+	m              int
+	efConstruction int
+	efSearch       int
+	seed           uint64
+}
+
+// WithMetric sets the distance metric. Default: Cosine.
+func WithMetric(m Metric) Option {
+	return func(c *config) {
+		c.metric = m
+	}
+}
+
+// WithM sets the number of neighbors each node keeps per layer above the
+// bottom one; the bottom layer keeps 2*M. Larger values improve recall at
+// the cost of memory and insert time. Default: 16.
+func WithM(m int) Option {
+	return func(c *config) {
+		c.m = m
+	}
+}
+
+// WithEfConstruction sets the candidate list size used while inserting.
+// Default: 200.
+func WithEfConstruction(ef int) Option {
+	return func(c *config) {
+		c.efConstruction = ef
+	}
+}
+
+// WithEfSearch sets the default candidate list size used by Search. It is
+// raised to k when smaller. Default: 64.
+func WithEfSearch(ef int) Option {
+	return func(c *config) {
+		c.efSearch = ef
+	}
+}
+
+// WithSeed seeds the level generator so index construction is
+// reproducible. Default: 1.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// Filter reports whether an item may appear in search results.
+type Filter func(id string, metadata map[string]string) bool
+
+// SearchOption configures a single Search call.
+type SearchOption func(*searchConfig)
+
+type searchConfig struct {
+	ef     int
+	filter Filter
+}
+
+// WithFilter restricts results to items accepted by f. Rejected items are
+// still traversed, so a selective filter costs more graph visits but never
+// disconnects the search.
+func WithFilter(f Filter) SearchOption {
+	return func(c *searchConfig) {
+		c.filter = f
+	}
+}
+
+// WithEf overrides the index's efSearch for one query.
+func WithEf(ef int) SearchOption {
+	return func(c *searchConfig) {
+		c.ef = ef
+	}
+}
+
+// Result is a single search hit.
+type Result struct {
+	ID       string
+	Distance float32
+	Metadata map[string]string
+}
+
+type node struct {
+	id        string
+	vec       []float32
+	metadata  map[string]string
+	neighbors [][]uint32 // neighbors[l] holds the links at layer l
+}
+
+// Index is an HNSW approximate nearest neighbor index. It is safe for
+// concurrent use: searches run in parallel, inserts are serialized.
+type Index struct {
+	mu       sync.RWMutex
+	dim      int
+	cfg      config
+	levelMul float64
+	rng      *rand.Rand
+	nodes    []*node
+	ids      map[string]uint32
+	entry    uint32
+	maxLevel int
+}
+
+// New creates an empty index for vectors of dimension dim.
+func New(dim int, opts ...Option) (*Index, error) {
+	cfg := config{metric: Cosine, m: 16, efConstruction: 200, efSearch: 64, seed: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if dim <= 0 {
+		return nil, fmt.Errorf("retrieval: dimension must be positive, got %d", dim)
+	}
+	if cfg.m < 2 {
+		return nil, fmt.Errorf("retrieval: M must be >= 2, got %d", cfg.m)
+	}
+	if cfg.efConstruction < 1 || cfg.efSearch < 1 {
+		return nil, fmt.Errorf("retrieval: ef values must be positive, got efConstruction=%d efSearch=%d", cfg.efConstruction, cfg.efSearch)
+	}
+	switch cfg.metric {
+	case Cosine, L2, InnerProduct:
+	default:
+		return nil, fmt.Errorf("retrieval: unknown metric %v", cfg.metric)
+	}
+	return &Index{
+		dim:      dim,
+		cfg:      cfg,
+		levelMul: 1 / math.Log(float64(cfg.m)),
+		rng:      rand.New(rand.NewPCG(cfg.seed, cfg.seed^0x9e3779b97f4a7c15)),
+		ids:      make(map[string]uint32),
+	}, nil
+}
+
+// Dim returns the vector dimension.
+func (ix *Index) Dim() int { return ix.dim }
+
+// Metric returns the distance metric.
+func (ix *Index) Metric() Metric { return ix.cfg.metric }
+
+// Len returns the number of indexed items.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.nodes)
+}
+
+// Add inserts vec under id. IDs must be unique. The vector is copied;
+// metadata is retained as given and returned with search results.
+func (ix *Index) Add(id string, vec []float32, metadata map[string]string) error {
+	if len(vec) != ix.dim {
+		return fmt.Errorf("retrieval: vector for %q has dimension %d, want %d", id, len(vec), ix.dim)
+	}
+	v := append([]float32(nil), vec...)
+	if ix.cfg.metric == Cosine && !normalize(v) {
+		return fmt.Errorf("retrieval: vector for %q has zero norm", id)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if _, dup := ix.ids[id]; dup {
+		return fmt.Errorf("retrieval: duplicate id %q", id)
+	}
+	if len(ix.nodes) == math.MaxUint32 {
+		return errors.New("retrieval: index is full")
+	}
+
+	level := ix.randomLevel()
+	n := &node{id: id, vec: v, metadata: metadata, neighbors: make([][]uint32, level+1)}
+	idx := uint32(len(ix.nodes))
+	ix.nodes = append(ix.nodes, n)
+	ix.ids[id] = idx
+
+	if idx == 0 {
+		ix.entry = 0
+		ix.maxLevel = level
+		return nil
+	}
+
+	ep := ix.entry
+	for l := ix.maxLevel; l > level; l-- {
+		ep = ix.greedyClosest(v, ep, l)
+	}
+	for l := min(level, ix.maxLevel); l >= 0; l-- {
+		cands := ix.searchLayer(v, []uint32{ep}, ix.cfg.efConstruction, l, nil)
+		neighbors := ix.selectNeighbors(cands, ix.maxLinks(l))
+		n.neighbors[l] = neighbors
+		for _, nb := range neighbors {
+			ix.link(nb, idx, l)
+		}
+		ep = cands[0].idx
+	}
+	if level > ix.maxLevel {
+		ix.maxLevel = level
+		ix.entry = idx
+	}
+	return nil
+}
+
+// Search returns up to k items nearest to query, closest first.
+func (ix *Index) Search(query []float32, k int, opts ...SearchOption) ([]Result, error) {
+	if len(query) != ix.dim {
+		return nil, fmt.Errorf("retrieval: query has dimension %d, want %d", len(query), ix.dim)
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("retrieval: k must be positive, got %d", k)
+	}
+	sc := searchConfig{ef: ix.cfg.efSearch}
+	for _, opt := range opts {
+		opt(&sc)
+	}
+	q := query
+	if ix.cfg.metric == Cosine {
+		q = append([]float32(nil), query...)
+		if !normalize(q) {
+			return nil, errors.New("retrieval: query has zero norm")
+		}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if len(ix.nodes) == 0 {
+		return nil, nil
+	}
+
+	ep := ix.entry
+	for l := ix.maxLevel; l > 0; l-- {
+		ep = ix.greedyClosest(q, ep, l)
+	}
+	cands := ix.searchLayer(q, []uint32{ep}, max(sc.ef, k), 0, sc.filter)
+	if len(cands) > k {
+		cands = cands[:k]
+	}
+	out := make([]Result, len(cands))
+	for i, c := range cands {
+		n := ix.nodes[c.idx]
+		out[i] = Result{ID: n.id, Distance: c.dist, Metadata: n.metadata}
+	}
+	return out, nil
+}
+
+func (ix *Index) randomLevel() int {
+	return int(-math.Log(1-ix.rng.Float64()) * ix.levelMul)
+}
+
+func (ix *Index) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * ix.cfg.m
+	}
+	return ix.cfg.m
+}
+
+func (ix *Index) distance(a, b []float32) float32 {
+	switch ix.cfg.metric {
+	case L2:
+		var s float32
+		for i := range a {
+			d := a[i] - b[i]
+			s += d * d
+		}
+		return s
+	case InnerProduct:
+		return -dot(a, b)
+	default:
+		return 1 - dot(a, b)
+	}
+}
+
+// greedyClosest walks layer l from ep towards q until no neighbor is closer.
+func (ix *Index) greedyClosest(q []float32, ep uint32, l int) uint32 {
+	best := ep
+	bestDist := ix.distance(q, ix.nodes[ep].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, nb := range ix.nodes[best].neighbors[l] {
+			if d := ix.distance(q, ix.nodes[nb].vec); d < bestDist {
+				best, bestDist, changed = nb, d, true
+			}
+		}
+	}
+	return best
+}
+
+type candidate struct {
+	idx  uint32
+	dist float32
+}
+
+// minHeap pops the closest candidate first.
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// maxHeap pops the farthest candidate first.
+type maxHeap struct{ minHeap }
+
+func (h maxHeap) Less(i, j int) bool { return h.minHeap[i].dist > h.minHeap[j].dist }
+
+// searchLayer runs a best-first search of layer l from eps and returns up to
+// ef results sorted closest first. When accept is non-nil only accepted
+// nodes enter the result set, but all nodes are traversed.
+func (ix *Index) searchLayer(q []float32, eps []uint32, ef, l int, accept Filter) []candidate {
+	visited := make(map[uint32]struct{}, ef*4)
+	cands := &minHeap{}
+	results := &maxHeap{}
+	admit := func(c candidate) {
+		if accept != nil {
+			n := ix.nodes[c.idx]
+			if !accept(n.id, n.metadata) {
+				return
+			}
+		}
+		heap.Push(results, c)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+	for _, ep := range eps {
+		visited[ep] = struct{}{}
+		c := candidate{ep, ix.distance(q, ix.nodes[ep].vec)}
+		heap.Push(cands, c)
+		admit(c)
+	}
+	for cands.Len() > 0 {
+		c := heap.Pop(cands).(candidate)
+		if results.Len() >= ef && c.dist > results.minHeap[0].dist {
+			break
+		}
+		for _, nb := range ix.nodes[c.idx].neighbors[l] {
+			if _, seen := visited[nb]; seen {
+				continue
+			}
+			visited[nb] = struct{}{}
+			d := ix.distance(q, ix.nodes[nb].vec)
+			if results.Len() < ef || d < results.minHeap[0].dist {
+				next := candidate{nb, d}
+				heap.Push(cands, next)
+				admit(next)
+			}
+		}
+	}
+	out := make([]candidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(candidate)
+	}
+	return out
+}
+
+// selectNeighbors applies the HNSW neighbor-selection heuristic to
+// candidates sorted closest first: a candidate is kept only if it is closer
+// to the base than to every neighbor already kept, which favours links in
+// diverse directions. Remaining slots are filled with the closest pruned
+// candidates.
+func (ix *Index) selectNeighbors(cands []candidate, m int) []uint32 {
+	if len(cands) <= m {
+		out := make([]uint32, len(cands))
+		for i, c := range cands {
+			out[i] = c.idx
+		}
+		return out
+	}
+	out := make([]uint32, 0, m)
+	var pruned []uint32
+	for _, c := range cands {
+		if len(out) == m {
+			break
+		}
+		keep := true
+		for _, s := range out {
+			if ix.distance(ix.nodes[c.idx].vec, ix.nodes[s].vec) < c.dist {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, c.idx)
+		} else {
+			pruned = append(pruned, c.idx)
+		}
+	}
+	for _, p := range pruned {
+		if len(out) == m {
+			break
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// link adds a layer-l edge from src to dst, re-selecting src's neighbors
+// when it exceeds its link budget.
+func (ix *Index) link(src, dst uint32, l int) {
+	n := ix.nodes[src]
+	n.neighbors[l] = append(n.neighbors[l], dst)
+	limit := ix.maxLinks(l)
+	if len(n.neighbors[l]) <= limit {
+		return
+	}
+	cands := make([]candidate, len(n.neighbors[l]))
+	for i, nb := range n.neighbors[l] {
+		cands[i] = candidate{nb, ix.distance(n.vec, ix.nodes[nb].vec)}
+	}
+	slices.SortFunc(cands, func(a, b candidate) int { return cmp.Compare(a.dist, b.dist) })
+	n.neighbors[l] = ix.selectNeighbors(cands, limit)
+}
+
+func dot(a, b []float32) float32 {
+	var s float32
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+// normalize scales v to unit length in place and reports whether v was
+// non-zero.
+func normalize(v []float32) bool {
+	var sumSq float64
+	for _, x := range v {
+		sumSq += float64(x) * float64(x)
+	}
+	if sumSq == 0 {
+		return false
+	}
+	inv := float32(1 / math.Sqrt(sumSq))
+	for i := range v {
+		v[i] *= inv
+	}
+	return true
+}
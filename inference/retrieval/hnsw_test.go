@@ -0,0 +1,239 @@
+package retrieval
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func randomVectors(n, dim int, seed uint64) [][]float32 {
+	rng := rand.New(rand.NewPCG(seed, seed+1))
+	out := make([][]float32, n)
+	for i := range out {
+		v := make([]float32, dim)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		out[i] = v
+	}
+	return out
+}
+
+func buildIndex(t *testing.T, vecs [][]float32, opts ...Option) *Index {
+	t.Helper()
+	ix, err := New(len(vecs[0]), opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i, v := range vecs {
+		md := map[string]string{"parity": strconv.Itoa(i % 2)}
+		if err := ix.Add(strconv.Itoa(i), v, md); err != nil {
+			t.Fatalf("Add(%d): %v", i, err)
+		}
+	}
+	return ix
+}
+
+// bruteForce returns the IDs of the k exact nearest neighbors under ix's
+// metric, restricted to items accepted by keep.
+func bruteForce(ix *Index, q []float32, k int, keep func(i int) bool) []string {
+	qq := append([]float32(nil), q...)
+	if ix.cfg.metric == Cosine {
+		normalize(qq)
+	}
+	type hit struct {
+		id   string
+		dist float32
+	}
+	var hits []hit
+	for i, n := range ix.nodes {
+		if keep != nil && !keep(i) {
+			continue
+		}
+		hits = append(hits, hit{n.id, ix.distance(qq, n.vec)})
+	}
+	slices.SortFunc(hits, func(a, b hit) int {
+		switch {
+		case a.dist < b.dist:
+			return -1
+		case a.dist > b.dist:
+			return 1
+		}
+		return 0
+	})
+	ids := make([]string, 0, k)
+	for _, h := range hits[:min(k, len(hits))] {
+		ids = append(ids, h.id)
+	}
+	return ids
+}
+
+func recall(got []Result, want []string) float64 {
+	set := make(map[string]bool, len(want))
+	for _, id := range want {
+		set[id] = true
+	}
+	hit := 0
+	for _, r := range got {
+		if set[r.ID] {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(want))
+}
+
+func TestIndex_RecallAgainstBruteForce(t *testing.T) {
+	const n, dim, k = 1000, 32, 10
+	vecs := randomVectors(n, dim, 1)
+	queries := randomVectors(50, dim, 2)
+
+	for _, metric := range []Metric{Cosine, L2, InnerProduct} {
+		t.Run(metric.String(), func(t *testing.T) {
+			ix := buildIndex(t, vecs, WithMetric(metric))
+			if ix.Len() != n {
+				t.Fatalf("Len = %d, want %d", ix.Len(), n)
+			}
+			var total float64
+			for _, q := range queries {
+				got, err := ix.Search(q, k)
+				if err != nil {
+					t.Fatalf("Search: %v", err)
+				}
+				if len(got) != k {
+					t.Fatalf("got %d results, want %d", len(got), k)
+				}
+				for i := 1; i < len(got); i++ {
+					if got[i].Distance < got[i-1].Distance {
+						t.Fatalf("results not sorted by distance: %v", got)
+					}
+				}
+				total += recall(got, bruteForce(ix, q, k, nil))
+			}
+			if mean := total / float64(len(queries)); mean < 0.9 {
+				t.Errorf("mean recall@%d = %.3f, want >= 0.9", k, mean)
+			}
+		})
+	}
+}
+
+func TestIndex_SearchWithFilter(t *testing.T) {
+	vecs := randomVectors(500, 16, 3)
+	ix := buildIndex(t, vecs)
+	q := randomVectors(1, 16, 4)[0]
+
+	even := func(_ string, md map[string]string) bool { return md["parity"] == "0" }
+	got, err := ix.Search(q, 5, WithFilter(even), WithEf(100))
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("got %d results, want 5", len(got))
+	}
+	for _, r := range got {
+		if r.Metadata["parity"] != "0" {
+			t.Errorf("result %s violates filter: %v", r.ID, r.Metadata)
+		}
+	}
+	want := bruteForce(ix, q, 5, func(i int) bool { return i%2 == 0 })
+	if r := recall(got, want); r < 0.8 {
+		t.Errorf("filtered recall = %.2f, want >= 0.8 (got %v, want %v)", r, got, want)
+	}
+
+	// A filter matching a single item still finds it.
+	only := func(id string, _ map[string]string) bool { return id == "123" }
+	got, err = ix.Search(q, 3, WithFilter(only))
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "123" {
+		t.Errorf("single-match filter = %v, want [123]", got)
+	}
+}
+
+func TestIndex_SaveLoadRoundTrip(t *testing.T) {
+	vecs := randomVectors(300, 8, 5)
+	ix := buildIndex(t, vecs, WithMetric(L2), WithM(8), WithEfSearch(32))
+	queries := randomVectors(10, 8, 6)
+
+	path := filepath.Join(t.TempDir(), "index.zhns")
+	if err := ix.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if loaded.Len() != ix.Len() || loaded.Dim() != ix.Dim() || loaded.Metric() != L2 {
+		t.Fatalf("loaded index: len=%d dim=%d metric=%v", loaded.Len(), loaded.Dim(), loaded.Metric())
+	}
+	for _, q := range queries {
+		a, _ := ix.Search(q, 5)
+		b, _ := loaded.Search(q, 5)
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Fatalf("results differ after reload:\n%v\n%v", a, b)
+		}
+	}
+
+	// The loaded index accepts further inserts.
+	if err := loaded.Add("new", queries[0], nil); err != nil {
+		t.Fatalf("Add after load: %v", err)
+	}
+	got, _ := loaded.Search(queries[0], 1)
+	if len(got) != 1 || got[0].ID != "new" {
+		t.Errorf("nearest to inserted vector = %v, want new", got)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	ix := buildIndex(t, randomVectors(20, 4, 7))
+	var buf bytes.Buffer
+	if err := ix.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if _, err := Load(bytes.NewReader([]byte("nope"))); err == nil {
+		t.Error("expected error for bad magic")
+	}
+	if _, err := Load(bytes.NewReader(data[:len(data)-3])); err == nil {
+		t.Error("expected error for truncated file")
+	}
+}
+
+func TestIndex_Errors(t *testing.T) {
+	if _, err := New(0); err == nil {
+		t.Error("New(0): expected error")
+	}
+	if _, err := New(4, WithM(1)); err == nil {
+		t.Error("New with M=1: expected error")
+	}
+	ix, err := New(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ix.Search([]float32{1, 0, 0}, 3); err != nil || len(got) != 0 {
+		t.Errorf("Search on empty index = %v, %v", got, err)
+	}
+	if err := ix.Add("a", []float32{1, 2}, nil); err == nil {
+		t.Error("Add with wrong dimension: expected error")
+	}
+	if err := ix.Add("z", []float32{0, 0, 0}, nil); err == nil {
+		t.Error("Add zero vector under cosine: expected error")
+	}
+	if err := ix.Add("a", []float32{1, 2, 3}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Add("a", []float32{3, 2, 1}, nil); err == nil {
+		t.Error("Add duplicate id: expected error")
+	}
+	if _, err := ix.Search([]float32{1, 2}, 1); err == nil {
+		t.Error("Search with wrong dimension: expected error")
+	}
+	if _, err := ix.Search([]float32{1, 2, 3}, 0); err == nil {
+		t.Error("Search with k=0: expected error")
+	}
+}
@@ -0,0 +1,276 @@
+package retrieval
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+var (
+	magic              = [4]byte{'Z', 'H', 'N', 'S'}
+	fileVersion uint32 = 1
+)
+
+// header is the JSON-encoded index configuration stored after the magic.
+type header struct {
+	Dim            int    `json:"dim"`
+	Metric         Metric `json:"metric"`
+	M              int    `json:"m"`
+	EfConstruction int    `json:"ef_construction"`
+	EfSearch       int    `json:"ef_search"`
+	Seed           uint64 `json:"seed"`
+	Count          int    `json:"count"`
+	Entry          uint32 `json:"entry"`
+	MaxLevel       int    `json:"max_level"`
+}
+
+// Save writes the index to w.
+//
+// Format:
+//   - 4-byte magic ("ZHNS")
+//   - 4-byte version (uint32 little-endian, currently 1)
+//   - 4-byte header length (uint32 little-endian) and JSON-encoded header
+//   - per node, in insertion order: the ID and JSON-encoded metadata as
+//     uint32-length-prefixed byte strings, the vector as raw float32
+//     little-endian values, the number of layers (uint32), and per layer
+//     the neighbor count (uint32) followed by the neighbor indices (uint32).
+func (ix *Index) Save(w io.Writer) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+	if _, err := bw.Write(magic[:]); err != nil {
+		return fmt.Errorf("retrieval: save: write magic: %w", err)
+	}
+	if err := binary.Write(bw, le, fileVersion); err != nil {
+		return fmt.Errorf("retrieval: save: write version: %w", err)
+	}
+	hdr, err := json.Marshal(header{
+		Dim:            ix.dim,
+		Metric:         ix.cfg.metric,
+		M:              ix.cfg.m,
+		EfConstruction: ix.cfg.efConstruction,
+		EfSearch:       ix.cfg.efSearch,
+		Seed:           ix.cfg.seed,
+		Count:          len(ix.nodes),
+		Entry:          ix.entry,
+		MaxLevel:       ix.maxLevel,
+	})
+	if err != nil {
+		return fmt.Errorf("retrieval: save: marshal header: %w", err)
+	}
+	if err := writeBytes(bw, hdr); err != nil {
+		return fmt.Errorf("retrieval: save: write header: %w", err)
+	}
+
+	vecBuf := make([]byte, 4*ix.dim)
+	for i, n := range ix.nodes {
+		md, err := json.Marshal(n.metadata)
+		if err != nil {
+			return fmt.Errorf("retrieval: save: node %d metadata: %w", i, err)
+		}
+		if err := writeBytes(bw, []byte(n.id)); err != nil {
+			return fmt.Errorf("retrieval: save: node %d id: %w", i, err)
+		}
+		if err := writeBytes(bw, md); err != nil {
+			return fmt.Errorf("retrieval: save: node %d metadata: %w", i, err)
+		}
+		for j, v := range n.vec {
+			le.PutUint32(vecBuf[4*j:], math.Float32bits(v))
+		}
+		if _, err := bw.Write(vecBuf); err != nil {
+			return fmt.Errorf("retrieval: save: node %d vector: %w", i, err)
+		}
+		if err := binary.Write(bw, le, uint32(len(n.neighbors))); err != nil {
+			return fmt.Errorf("retrieval: save: node %d layers: %w", i, err)
+		}
+		for l, nbs := range n.neighbors {
+			if err := binary.Write(bw, le, uint32(len(nbs))); err != nil {
+				return fmt.Errorf("retrieval: save: node %d layer %d: %w", i, l, err)
+			}
+			if err := binary.Write(bw, le, nbs); err != nil {
+				return fmt.Errorf("retrieval: save: node %d layer %d: %w", i, l, err)
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("retrieval: save: %w", err)
+	}
+	return nil
+}
+
+// SaveFile writes the index to path.
+func (ix *Index) SaveFile(path string) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("retrieval: save: %w", err)
+	}
+	if err := ix.Save(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("retrieval: save: %w", err)
+	}
+	return nil
+}
+
+// maxBlobLen bounds length-prefixed strings read by Load so a corrupt file
+// cannot trigger a huge allocation.
+const maxBlobLen = 64 << 20
+
+// Load reads an index written by Save.
+func Load(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	le := binary.LittleEndian
+
+	var gotMagic [4]byte
+	if _, err := io.ReadFull(br, gotMagic[:]); err != nil {
+		return nil, fmt.Errorf("retrieval: load: read magic: %w", err)
+	}
+	if gotMagic != magic {
+		return nil, fmt.Errorf("retrieval: load: invalid magic %q", gotMagic[:])
+	}
+	var version uint32
+	if err := binary.Read(br, le, &version); err != nil {
+		return nil, fmt.Errorf("retrieval: load: read version: %w", err)
+	}
+	if version != fileVersion {
+		return nil, fmt.Errorf("retrieval: load: unsupported version %d", version)
+	}
+	hdrBytes, err := readBytes(br)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: load: read header: %w", err)
+	}
+	var hdr header
+	if err := json.Unmarshal(hdrBytes, &hdr); err != nil {
+		return nil, fmt.Errorf("retrieval: load: parse header: %w", err)
+	}
+
+	ix, err := New(hdr.Dim,
+		WithMetric(hdr.Metric),
+		WithM(hdr.M),
+		WithEfConstruction(hdr.EfConstruction),
+		WithEfSearch(hdr.EfSearch),
+		WithSeed(hdr.Seed),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: load: %w", err)
+	}
+	if hdr.Count < 0 || (hdr.Count > 0 && int(hdr.Entry) >= hdr.Count) {
+		return nil, fmt.Errorf("retrieval: load: invalid node count %d or entry %d", hdr.Count, hdr.Entry)
+	}
+
+	vecBuf := make([]byte, 4*hdr.Dim)
+	for i := 0; i < hdr.Count; i++ {
+		id, err := readBytes(br)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: load: node %d id: %w", i, err)
+		}
+		mdBytes, err := readBytes(br)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: load: node %d metadata: %w", i, err)
+		}
+		var md map[string]string
+		if err := json.Unmarshal(mdBytes, &md); err != nil {
+			return nil, fmt.Errorf("retrieval: load: node %d metadata: %w", i, err)
+		}
+		if _, err := io.ReadFull(br, vecBuf); err != nil {
+			return nil, fmt.Errorf("retrieval: load: node %d vector: %w", i, err)
+		}
+		vec := make([]float32, hdr.Dim)
+		for j := range vec {
+			vec[j] = math.Float32frombits(le.Uint32(vecBuf[4*j:]))
+		}
+		var layers uint32
+		if err := binary.Read(br, le, &layers); err != nil {
+			return nil, fmt.Errorf("retrieval: load: node %d layers: %w", i, err)
+		}
+		if layers == 0 || int(layers) > hdr.MaxLevel+1 {
+			return nil, fmt.Errorf("retrieval: load: node %d has %d layers, max level is %d", i, layers, hdr.MaxLevel)
+		}
+		neighbors := make([][]uint32, layers)
+		for l := range neighbors {
+			var count uint32
+			if err := binary.Read(br, le, &count); err != nil {
+				return nil, fmt.Errorf("retrieval: load: node %d layer %d: %w", i, l, err)
+			}
+			if int(count) > 2*hdr.M {
+				return nil, fmt.Errorf("retrieval: load: node %d layer %d has %d neighbors, limit %d", i, l, count, 2*hdr.M)
+			}
+			nbs := make([]uint32, count)
+			if err := binary.Read(br, le, nbs); err != nil {
+				return nil, fmt.Errorf("retrieval: load: node %d layer %d: %w", i, l, err)
+			}
+			for _, nb := range nbs {
+				if int(nb) >= hdr.Count {
+					return nil, fmt.Errorf("retrieval: load: node %d links to missing node %d", i, nb)
+				}
+			}
+			neighbors[l] = nbs
+		}
+		if _, dup := ix.ids[string(id)]; dup {
+			return nil, fmt.Errorf("retrieval: load: duplicate id %q", id)
+		}
+		ix.ids[string(id)] = uint32(i)
+		ix.nodes = append(ix.nodes, &node{id: string(id), vec: vec, metadata: md, neighbors: neighbors})
+	}
+	ix.entry = hdr.Entry
+	ix.maxLevel = hdr.MaxLevel
+
+	// Links may point at a layer the target does not reach only in a
+	// corrupt file; reject rather than panic during search.
+	for i, n := range ix.nodes {
+		for l, nbs := range n.neighbors {
+			for _, nb := range nbs {
+				if len(ix.nodes[nb].neighbors) <= l {
+					return nil, fmt.Errorf("retrieval: load: node %d layer %d links to node %d below that layer", i, l, nb)
+				}
+			}
+		}
+	}
+	if hdr.Count > 0 && len(ix.nodes[ix.entry].neighbors) != hdr.MaxLevel+1 {
+		return nil, errors.New("retrieval: load: entry point is not on the top layer")
+	}
+	return ix, nil
+}
+
+// LoadFile reads an index from path.
+func LoadFile(path string) (*Index, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: load: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return Load(f)
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readBytes(r io.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	if n > maxBlobLen {
+		return nil, fmt.Errorf("length %d exceeds limit %d", n, maxBlobLen)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
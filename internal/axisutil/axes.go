@@ -0,0 +1,80 @@
+package axisutil
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Normalize returns axis as an index in [0, rank). Negative axes count from
+// the end.
+func Normalize(axis, rank int) (int, error) {
+	n := axis
+	if n < 0 {
+		n += rank
+	}
+	if n < 0 || n >= rank {
+		return 0, fmt.Errorf("axis %d out of range for rank %d", axis, rank)
+	}
+	return n, nil
+}
+
+// NormalizeAll normalizes each of axes, keeping their order. Repeated axes,
+// including an axis given both as negative and non-negative, are an error.
+func NormalizeAll(axes []int, rank int) ([]int, error) {
+	out := make([]int, len(axes))
+	for i, a := range axes {
+		n, err := Normalize(a, rank)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(out[:i], n) {
+			return nil, fmt.Errorf("axis %d repeated in %v", a, axes)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// Reduction returns the axes a reduction over a tensor of the given rank
+// visits. No axes means every axis, and repeated axes are reduced once. The
+// result is sorted in descending order, so reducing one axis at a time
+// never shifts an axis still to be reduced.
+func Reduction(axes []int, rank int) ([]int, error) {
+	if len(axes) == 0 {
+		out := make([]int, rank)
+		for i := range out {
+			out[i] = rank - 1 - i
+		}
+		return out, nil
+	}
+	out := make([]int, 0, len(axes))
+	for _, a := range axes {
+		n, err := Normalize(a, rank)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	slices.Sort(out)
+	slices.Reverse(out)
+	return out, nil
+}
+
+// Permutation normalizes a transpose permutation of a tensor of the given
+// rank. Nil means reversing every axis; otherwise perm must name each axis
+// exactly once.
+func Permutation(perm []int, rank int) ([]int, error) {
+	if perm == nil {
+		out := make([]int, rank)
+		for i := range out {
+			out[i] = rank - 1 - i
+		}
+		return out, nil
+	}
+	if len(perm) != rank {
+		return nil, fmt.Errorf("permutation %v has %d axes, want %d", perm, len(perm), rank)
+	}
+	return NormalizeAll(perm, rank)
+}
@@ -0,0 +1,82 @@
+package axisutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		axis, rank, want int
+		wantErr          bool
+	}{
+		{0, 3, 0, false},
+		{2, 3, 2, false},
+		{-1, 3, 2, false},
+		{-3, 3, 0, false},
+		{3, 3, 0, true},
+		{-4, 3, 0, true},
+		{0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.axis, tt.rank)
+		if (err != nil) != tt.wantErr || !tt.wantErr && got != tt.want {
+			t.Errorf("Normalize(%d, %d) = %d, %v; want %d, err %v", tt.axis, tt.rank, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	got, err := NormalizeAll([]int{-1, 0}, 3)
+	if err != nil || !slices.Equal(got, []int{2, 0}) {
+		t.Errorf("NormalizeAll = %v, %v; want [2 0]", got, err)
+	}
+	if _, err := NormalizeAll([]int{1, -2}, 3); err == nil {
+		t.Error("repeated axis should error")
+	}
+	if _, err := NormalizeAll([]int{5}, 3); err == nil {
+		t.Error("out-of-range axis should error")
+	}
+}
+
+func TestReduction(t *testing.T) {
+	tests := []struct {
+		name    string
+		axes    []int
+		rank    int
+		want    []int
+		wantErr bool
+	}{
+		{"all", nil, 3, []int{2, 1, 0}, false},
+		{"negative", []int{-1, 0}, 3, []int{2, 0}, false},
+		{"repeated", []int{1, -1}, 2, []int{1}, false},
+		{"rank 0", nil, 0, []int{}, false},
+		{"out of range", []int{2}, 2, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Reduction(tt.axes, tt.rank)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("Reduction = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermutation(t *testing.T) {
+	if got, err := Permutation(nil, 3); err != nil || !slices.Equal(got, []int{2, 1, 0}) {
+		t.Errorf("Permutation(nil) = %v, %v; want [2 1 0]", got, err)
+	}
+	if got, err := Permutation([]int{0, -1, 1}, 3); err != nil || !slices.Equal(got, []int{0, 2, 1}) {
+		t.Errorf("Permutation([0 -1 1]) = %v, %v; want [0 2 1]", got, err)
+	}
+	if _, err := Permutation([]int{0, 0}, 2); err == nil {
+		t.Error("repeated axis should error")
+	}
+	if _, err := Permutation([]int{0}, 2); err == nil {
+		t.Error("short permutation should error")
+	}
+}
@@ -0,0 +1,6 @@
+// Package axisutil normalizes tensor axis arguments. Every layer that takes an
+// axis accepts negative values counting from the last dimension (-1 is the
+// last axis) and reports out-of-range axes with the same error.
+//
+// Stability: stable
+package axisutil
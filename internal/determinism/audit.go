@@ -0,0 +1,166 @@
+package determinism
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Job runs one training job from seed and returns a fingerprint of what it
+// produced, such as the loss of every step followed by the final
+// parameters. A deterministic job returns bitwise-identical fingerprints for
+// the same seed.
+type Job func(ctx context.Context, seed uint64) ([]float64, error)
+
+// Finding is a hooked site observed during an audit.
+type Finding struct {
+	Kind     Kind
+	Location string
+	// Counts holds the number of times the site fired in each run.
+	Counts [2]int
+	// Unstable reports that the site fired a different number of times or,
+	// for NoteOrder sites, in a different key order in the two runs.
+	Unstable bool
+}
+
+// Report is the result of Audit.
+type Report struct {
+	Seed uint64
+	// Values is the fingerprint length of the first run.
+	Values int
+	// Diverged reports that the fingerprints differ.
+	Diverged bool
+	// FirstDivergence is the index of the first differing value, or -1.
+	// When only the lengths differ it is the shorter length.
+	FirstDivergence int
+	// MaxAbsDiff is the largest absolute difference over the common prefix.
+	MaxAbsDiff float64
+	Findings   []Finding
+}
+
+// Audit runs job twice with seed, each run under its own Recorder, and
+// reports whether the fingerprints match and which hooked sites fired.
+// Audits must not run concurrently, since the hooks report to a single
+// active Recorder.
+func Audit(ctx context.Context, seed uint64, job Job) (*Report, error) {
+	var (
+		prints [2][]float64
+		sites  [2][]Site
+	)
+	for run := range prints {
+		rec, err := Record()
+		if err != nil {
+			return nil, err
+		}
+		prints[run], err = job(ctx, seed)
+		sites[run] = rec.Stop()
+		if err != nil {
+			return nil, fmt.Errorf("determinism: run %d: %w", run+1, err)
+		}
+	}
+
+	r := &Report{Seed: seed, Values: len(prints[0]), FirstDivergence: -1}
+	a, b := prints[0], prints[1]
+	for i := range min(len(a), len(b)) {
+		if math.Float64bits(a[i]) == math.Float64bits(b[i]) {
+			continue
+		}
+		if r.FirstDivergence < 0 {
+			r.FirstDivergence = i
+		}
+		if d := math.Abs(a[i] - b[i]); d > r.MaxAbsDiff || math.IsNaN(d) {
+			r.MaxAbsDiff = d
+		}
+	}
+	if r.FirstDivergence < 0 && len(a) != len(b) {
+		r.FirstDivergence = min(len(a), len(b))
+	}
+	r.Diverged = r.FirstDivergence >= 0
+	r.Findings = mergeSites(sites[0], sites[1])
+	return r, nil
+}
+
+// mergeSites pairs the sites of two runs by kind and location. Both inputs
+// are sorted as Recorder.Stop returns them.
+func mergeSites(a, b []Site) []Finding {
+	var out []Finding
+	find := func(key siteKey) *Finding {
+		for i := range out {
+			if out[i].Kind == key.kind && out[i].Location == key.location {
+				return &out[i]
+			}
+		}
+		out = append(out, Finding{Kind: key.kind, Location: key.location})
+		return &out[len(out)-1]
+	}
+	for run, sites := range [][]Site{a, b} {
+		for _, s := range sites {
+			f := find(siteKey{kind: s.Kind, location: s.Location})
+			f.Counts[run] = s.Count
+		}
+	}
+	for i := range out {
+		f := &out[i]
+		f.Unstable = f.Counts[0] != f.Counts[1] || !slices.Equal(order(a, f), order(b, f))
+	}
+	slices.SortStableFunc(out, func(x, y Finding) int {
+		// Unstable sites first: they are the likeliest cause of divergence.
+		switch {
+		case x.Unstable == y.Unstable:
+			return 0
+		case x.Unstable:
+			return -1
+		default:
+			return 1
+		}
+	})
+	return out
+}
+
+func order(sites []Site, f *Finding) []string {
+	for _, s := range sites {
+		if s.Kind == f.Kind && s.Location == f.Location {
+			return s.Order
+		}
+	}
+	return nil
+}
+
+// Render writes r to w as a human-readable report.
+func (r *Report) Render(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "determinism audit: seed=%d, 2 runs\n", r.Seed)
+	if r.Diverged {
+		fmt.Fprintf(&b, "result: DIVERGED at value %d of %d (max |diff| %g)\n", r.FirstDivergence, r.Values, r.MaxAbsDiff)
+	} else {
+		fmt.Fprintf(&b, "result: identical (%d values)\n", r.Values)
+	}
+	if len(r.Findings) == 0 {
+		b.WriteString("sources: none observed\n")
+	} else {
+		b.WriteString("sources:\n")
+		tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+		var kinds []Kind
+		for _, f := range r.Findings {
+			status := "stable"
+			if f.Unstable {
+				status = "DIFFERS"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%d/%d calls\t%s\n", f.Kind, f.Location, f.Counts[0], f.Counts[1], status)
+			if !slices.Contains(kinds, f.Kind) {
+				kinds = append(kinds, f.Kind)
+			}
+		}
+		_ = tw.Flush()
+		b.WriteString("fixes:\n")
+		for _, k := range kinds {
+			fmt.Fprintf(&b, "  %s: %s\n", k, k.hint())
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,107 @@
+package determinism
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAudit_Identical(t *testing.T) {
+	job := func(_ context.Context, seed uint64) ([]float64, error) {
+		Note(UnseededRand)
+		return []float64{float64(seed), 1, 2}, nil
+	}
+	r, err := Audit(context.Background(), 7, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Diverged || r.FirstDivergence != -1 || r.Values != 3 {
+		t.Errorf("report = %+v, want identical 3 values", r)
+	}
+	if len(r.Findings) != 1 {
+		t.Fatalf("findings = %+v, want 1", r.Findings)
+	}
+	f := r.Findings[0]
+	if f.Kind != UnseededRand || f.Counts != [2]int{1, 1} || f.Unstable {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Location, "audit_test.go") {
+		t.Errorf("Location = %q, want the calling test file", f.Location)
+	}
+}
+
+func TestAudit_Divergence(t *testing.T) {
+	run := 0
+	job := func(context.Context, uint64) ([]float64, error) {
+		run++
+		keys := []string{"a", "b"}
+		if run == 2 {
+			keys = []string{"b", "a"}
+		}
+		for _, k := range keys {
+			NoteOrder(MapIteration, k)
+		}
+		return []float64{1, 2, float64(run)}, nil
+	}
+	r, err := Audit(context.Background(), 1, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Diverged || r.FirstDivergence != 2 || r.MaxAbsDiff != 1 {
+		t.Errorf("report = %+v, want divergence at 2", r)
+	}
+	if len(r.Findings) != 1 || !r.Findings[0].Unstable {
+		t.Errorf("findings = %+v, want one unstable map-iteration site", r.Findings)
+	}
+
+	var sb strings.Builder
+	if err := r.Render(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"DIVERGED at value 2", "map-iteration", "DIFFERS", "sorted key order"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, sb.String())
+		}
+	}
+}
+
+func TestAudit_LengthMismatchAndError(t *testing.T) {
+	run := 0
+	r, err := Audit(context.Background(), 1, func(context.Context, uint64) ([]float64, error) {
+		run++
+		return make([]float64, run), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Diverged || r.FirstDivergence != 1 {
+		t.Errorf("report = %+v, want divergence at 1", r)
+	}
+
+	if _, err := Audit(context.Background(), 1, func(context.Context, uint64) ([]float64, error) {
+		return nil, errors.New("boom")
+	}); err == nil {
+		t.Error("expected job error")
+	}
+	// A failed run must not leave its recorder active.
+	rec, err := Record()
+	if err != nil {
+		t.Fatalf("Record after failed audit: %v", err)
+	}
+	rec.Stop()
+}
+
+func TestNote_InactiveIsNoop(t *testing.T) {
+	Note(UnseededRand)
+	rec, err := Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Record(); err == nil {
+		t.Error("expected error for a second active recorder")
+	}
+	if sites := rec.Stop(); len(sites) != 0 {
+		t.Errorf("sites = %+v, want none", sites)
+	}
+}
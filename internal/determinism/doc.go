@@ -0,0 +1,12 @@
+// Package determinism finds the sources of run-to-run divergence in
+// training. Code that draws from an unseeded random source, reduces values
+// in scheduling order, or walks a map where order can leak into results
+// reports the site with Note or NoteOrder. The hooks cost one atomic load
+// while no Recorder is active.
+//
+// Audit runs a job twice with the same seed under a Recorder, compares the
+// two fingerprints the job returns, and lists every hooked site that fired,
+// marking the ones that behaved differently between the runs.
+//
+// Stability: alpha
+package determinism
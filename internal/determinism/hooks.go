@@ -0,0 +1,137 @@
+package determinism
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Kind classifies a nondeterminism source.
+type Kind string
+
+const (
+	// UnseededRand marks a draw from a random source the caller cannot seed,
+	// such as the math/rand/v2 package-level functions.
+	UnseededRand Kind = "unseeded-rand"
+	// UnorderedReduction marks a sum or other reduction whose operand order
+	// follows goroutine scheduling.
+	UnorderedReduction Kind = "unordered-reduction"
+	// MapIteration marks a loop over a map whose visiting order may reach the
+	// results.
+	MapIteration Kind = "map-iteration"
+)
+
+// hint returns the usual fix for sources of kind k.
+func (k Kind) hint() string {
+	switch k {
+	case UnseededRand:
+		return "draw from a seeded *rand.Rand instead of the math/rand/v2 package functions"
+	case UnorderedReduction:
+		return "reduce in a fixed order, e.g. components.WithDeterministicOrder"
+	case MapIteration:
+		return "iterate in sorted key order"
+	default:
+		return ""
+	}
+}
+
+// active is the Recorder the hooks report to, or nil.
+var active atomic.Pointer[Recorder]
+
+// Note records that the calling site exhibited kind. It is a no-op unless a
+// Recorder is active.
+func Note(kind Kind) {
+	if r := active.Load(); r != nil {
+		r.add(kind, callerLocation(), "", false)
+	}
+}
+
+// NoteOrder records that the calling site visited key, typically once per
+// map entry. Besides counting the visits, the Recorder keeps their order so
+// Audit can tell whether it changed between runs.
+func NoteOrder(kind Kind, key string) {
+	if r := active.Load(); r != nil {
+		r.add(kind, callerLocation(), key, true)
+	}
+}
+
+// callerLocation describes the caller of the hook that called it.
+func callerLocation() string {
+	pc, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	name := "unknown"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = path.Base(fn.Name())
+	}
+	return fmt.Sprintf("%s (%s:%d)", name, filepath.Base(file), line)
+}
+
+// Site is one hooked call site observed by a Recorder.
+type Site struct {
+	Kind     Kind
+	Location string
+	// Count is the number of times the site fired.
+	Count int
+	// Order holds the keys passed to NoteOrder, in call order.
+	Order []string
+}
+
+type siteKey struct {
+	kind     Kind
+	location string
+}
+
+// Recorder collects the sites reported by Note and NoteOrder while it is
+// active. At most one Recorder is active at a time.
+type Recorder struct {
+	mu    sync.Mutex
+	sites map[siteKey]*Site
+}
+
+// Record starts a Recorder. It fails if another Recorder is still active.
+func Record() (*Recorder, error) {
+	r := &Recorder{sites: make(map[siteKey]*Site)}
+	if !active.CompareAndSwap(nil, r) {
+		return nil, errors.New("determinism: a recorder is already active")
+	}
+	return r, nil
+}
+
+func (r *Recorder) add(kind Kind, location, key string, ordered bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := siteKey{kind: kind, location: location}
+	s, ok := r.sites[k]
+	if !ok {
+		s = &Site{Kind: kind, Location: location}
+		r.sites[k] = s
+	}
+	s.Count++
+	if ordered {
+		s.Order = append(s.Order, key)
+	}
+}
+
+// Stop deactivates r and returns the sites it observed, sorted by location
+// and kind.
+func (r *Recorder) Stop() []Site {
+	active.CompareAndSwap(r, nil)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Site, 0, len(r.sites))
+	for _, s := range r.sites {
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b Site) int {
+		return cmp.Or(cmp.Compare(a.Location, b.Location), cmp.Compare(a.Kind, b.Kind))
+	})
+	return out
+}
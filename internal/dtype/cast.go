@@ -0,0 +1,389 @@
+package dtype
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/float8"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Class is the arithmetic class of an element type.
+type Class int
+
+// Element type classes.
+const (
+	Signed Class = iota
+	Unsigned
+	Float
+)
+
+// elem is the concrete layout of an element type. Named integer and float
+// types share the layout of their underlying type.
+type elem int
+
+const (
+	elemInvalid elem = iota
+	elemInt8
+	elemInt16
+	elemInt32
+	elemInt64
+	elemUint8
+	elemUint32
+	elemUint64
+	elemFloat8
+	elemFloat16
+	elemBFloat16
+	elemFloat32
+	elemFloat64
+)
+
+func (e elem) class() Class {
+	switch {
+	case e <= elemInt64:
+		return Signed
+	case e <= elemUint64:
+		return Unsigned
+	}
+	return Float
+}
+
+func (e elem) bits() int {
+	switch e {
+	case elemInt8, elemUint8, elemFloat8:
+		return 8
+	case elemInt16, elemFloat16, elemBFloat16:
+		return 16
+	case elemInt32, elemUint32, elemFloat32:
+		return 32
+	}
+	return 64
+}
+
+var (
+	float8Type   = reflect.TypeFor[float8.Float8]()
+	float16Type  = reflect.TypeFor[float16.Float16]()
+	bfloat16Type = reflect.TypeFor[float16.BFloat16]()
+)
+
+func elemOf(t reflect.Type) elem {
+	switch t {
+	case float8Type:
+		return elemFloat8
+	case float16Type:
+		return elemFloat16
+	case bfloat16Type:
+		return elemBFloat16
+	}
+	switch t.Kind() {
+	case reflect.Int8:
+		return elemInt8
+	case reflect.Int16:
+		return elemInt16
+	case reflect.Int32:
+		return elemInt32
+	case reflect.Int64:
+		return elemInt64
+	case reflect.Int:
+		if strconv.IntSize == 32 {
+			return elemInt32
+		}
+		return elemInt64
+	case reflect.Uint8:
+		return elemUint8
+	case reflect.Uint32:
+		return elemUint32
+	case reflect.Uint64:
+		return elemUint64
+	case reflect.Uint:
+		if strconv.IntSize == 32 {
+			return elemUint32
+		}
+		return elemUint64
+	case reflect.Float32:
+		return elemFloat32
+	case reflect.Float64:
+		return elemFloat64
+	}
+	return elemInvalid
+}
+
+// ClassOf returns the class and bit width of element type t, classifying
+// named types by their underlying kind. ok is false when t is not a tensor
+// element type.
+func ClassOf(t reflect.Type) (class Class, bits int, ok bool) {
+	e := elemOf(t)
+	if e == elemInvalid {
+		return 0, 0, false
+	}
+	return e.class(), e.bits(), true
+}
+
+// convertChunk bounds the stack buffer values are widened into.
+const convertChunk = 256
+
+// Convert writes src converted to D into dst, which must be at least as
+// long as src. The element types are resolved once per call; each element
+// then goes through a loop specialized for its layout.
+//
+// Integer to integer conversion is exact when the value fits in D and
+// saturates at D's bounds otherwise, so negative values convert to 0 in an
+// unsigned type. Float to integer conversion truncates toward zero and
+// saturates the same way, with NaN converting to 0. Conversion to a float
+// type rounds to nearest.
+func Convert[S, D tensor.Numeric](dst []D, src []S) {
+	se, de := elemOf(reflect.TypeFor[S]()), elemOf(reflect.TypeFor[D]())
+	dst = dst[:len(src)]
+	if se == de {
+		copy(view[S](dst), src)
+		return
+	}
+	var (
+		ints   [convertChunk]int64
+		uints  [convertChunk]uint64
+		floats [convertChunk]float64
+	)
+	for lo := 0; lo < len(src); lo += convertChunk {
+		hi := min(lo+convertChunk, len(src))
+		s, d := src[lo:hi], dst[lo:hi]
+		switch se.class() {
+		case Signed:
+			buf := ints[:len(s)]
+			loadSigned(buf, s, se)
+			storeSigned(d, buf, de)
+		case Unsigned:
+			buf := uints[:len(s)]
+			loadUnsigned(buf, s, se)
+			storeUnsigned(d, buf, de)
+		default:
+			buf := floats[:len(s)]
+			loadFloat(buf, s, se)
+			storeFromFloat(d, buf, de)
+		}
+	}
+}
+
+// view reinterprets s as a slice of E, which must have the same layout as T.
+func view[E, T any](s []T) []E {
+	return unsafe.Slice((*E)(unsafe.Pointer(unsafe.SliceData(s))), len(s))
+}
+
+type signedInt interface {
+	int8 | int16 | int32 | int64
+}
+
+type unsignedInt interface {
+	uint8 | uint32 | uint64
+}
+
+func widen[E signedInt | unsignedInt | float32 | float64, W int64 | uint64 | float64](dst []W, src []E) {
+	for i, v := range src {
+		dst[i] = W(v)
+	}
+}
+
+func loadSigned[S any](dst []int64, src []S, e elem) {
+	switch e {
+	case elemInt8:
+		widen(dst, view[int8](src))
+	case elemInt16:
+		widen(dst, view[int16](src))
+	case elemInt32:
+		widen(dst, view[int32](src))
+	default:
+		copy(dst, view[int64](src))
+	}
+}
+
+func loadUnsigned[S any](dst []uint64, src []S, e elem) {
+	switch e {
+	case elemUint8:
+		widen(dst, view[uint8](src))
+	case elemUint32:
+		widen(dst, view[uint32](src))
+	default:
+		copy(dst, view[uint64](src))
+	}
+}
+
+func loadFloat[S any](dst []float64, src []S, e elem) {
+	switch e {
+	case elemFloat8:
+		for i, v := range view[float8.Float8](src) {
+			dst[i] = v.ToFloat64()
+		}
+	case elemFloat16:
+		for i, v := range view[float16.Float16](src) {
+			dst[i] = v.ToFloat64()
+		}
+	case elemBFloat16:
+		for i, v := range view[float16.BFloat16](src) {
+			dst[i] = float64(v.ToFloat32())
+		}
+	case elemFloat32:
+		widen(dst, view[float32](src))
+	default:
+		copy(dst, view[float64](src))
+	}
+}
+
+// intRange is the range of the integer layout e.
+func intRange(e elem) (lo int64, hi uint64) {
+	switch e {
+	case elemInt8:
+		return math.MinInt8, math.MaxInt8
+	case elemInt16:
+		return math.MinInt16, math.MaxInt16
+	case elemInt32:
+		return math.MinInt32, math.MaxInt32
+	case elemInt64:
+		return math.MinInt64, math.MaxInt64
+	case elemUint8:
+		return 0, math.MaxUint8
+	case elemUint32:
+		return 0, math.MaxUint32
+	}
+	return 0, math.MaxUint64
+}
+
+func storeSigned[D any](dst []D, src []int64, e elem) {
+	lo, hi := intRange(e)
+	switch e {
+	case elemInt8:
+		signedToSigned(view[int8](dst), src, lo, int64(hi))
+	case elemInt16:
+		signedToSigned(view[int16](dst), src, lo, int64(hi))
+	case elemInt32:
+		signedToSigned(view[int32](dst), src, lo, int64(hi))
+	case elemInt64:
+		copy(view[int64](dst), src)
+	case elemUint8:
+		signedToUnsigned(view[uint8](dst), src, hi)
+	case elemUint32:
+		signedToUnsigned(view[uint32](dst), src, hi)
+	case elemUint64:
+		signedToUnsigned(view[uint64](dst), src, hi)
+	default:
+		storeFloat(dst, src, e)
+	}
+}
+
+func storeUnsigned[D any](dst []D, src []uint64, e elem) {
+	_, hi := intRange(e)
+	switch e {
+	case elemInt8:
+		unsignedToInt(view[int8](dst), src, hi)
+	case elemInt16:
+		unsignedToInt(view[int16](dst), src, hi)
+	case elemInt32:
+		unsignedToInt(view[int32](dst), src, hi)
+	case elemInt64:
+		unsignedToInt(view[int64](dst), src, hi)
+	case elemUint8:
+		unsignedToInt(view[uint8](dst), src, hi)
+	case elemUint32:
+		unsignedToInt(view[uint32](dst), src, hi)
+	case elemUint64:
+		copy(view[uint64](dst), src)
+	default:
+		storeFloat(dst, src, e)
+	}
+}
+
+func storeFromFloat[D any](dst []D, src []float64, e elem) {
+	switch e {
+	case elemInt8:
+		floatToInt[int8](view[int8](dst), src, math.MinInt8, math.MaxInt8)
+	case elemInt16:
+		floatToInt[int16](view[int16](dst), src, math.MinInt16, math.MaxInt16)
+	case elemInt32:
+		floatToInt[int32](view[int32](dst), src, math.MinInt32, math.MaxInt32)
+	case elemInt64:
+		floatToInt[int64](view[int64](dst), src, math.MinInt64, math.MaxInt64)
+	case elemUint8:
+		floatToInt[uint8](view[uint8](dst), src, 0, math.MaxUint8)
+	case elemUint32:
+		floatToInt[uint32](view[uint32](dst), src, 0, math.MaxUint32)
+	case elemUint64:
+		floatToInt[uint64](view[uint64](dst), src, 0, math.MaxUint64)
+	default:
+		storeFloat(dst, src, e)
+	}
+}
+
+func signedToSigned[E signedInt](dst []E, src []int64, lo, hi int64) {
+	for i, v := range src {
+		dst[i] = E(min(max(v, lo), hi))
+	}
+}
+
+func signedToUnsigned[E unsignedInt](dst []E, src []int64, hi uint64) {
+	for i, v := range src {
+		switch {
+		case v < 0:
+			dst[i] = 0
+		case uint64(v) > hi:
+			dst[i] = E(hi)
+		default:
+			dst[i] = E(v)
+		}
+	}
+}
+
+func unsignedToInt[E signedInt | unsignedInt](dst []E, src []uint64, hi uint64) {
+	for i, v := range src {
+		dst[i] = E(min(v, hi))
+	}
+}
+
+// floatToInt truncates toward zero, saturating at lo and hi. float64(hi)
+// rounds up to a power of two for 64-bit types, so v >= hi also catches
+// values just past the largest representable integer.
+func floatToInt[E signedInt | unsignedInt](dst []E, src []float64, lo, hi E) {
+	flo, fhi := float64(lo), float64(hi)
+	for i, v := range src {
+		switch {
+		case v != v:
+			dst[i] = 0
+		case v <= flo:
+			dst[i] = lo
+		case v >= fhi:
+			dst[i] = hi
+		default:
+			dst[i] = E(v)
+		}
+	}
+}
+
+func storeFloat[D any, W int64 | uint64 | float64](dst []D, src []W, e elem) {
+	switch e {
+	case elemFloat8:
+		d := view[float8.Float8](dst)
+		for i, v := range src {
+			d[i] = float8.FromFloat64(float64(v))
+		}
+	case elemFloat16:
+		d := view[float16.Float16](dst)
+		for i, v := range src {
+			d[i] = float16.FromFloat64(float64(v))
+		}
+	case elemBFloat16:
+		d := view[float16.BFloat16](dst)
+		for i, v := range src {
+			d[i] = float16.BFloat16FromFloat64(float64(v))
+		}
+	case elemFloat32:
+		d := view[float32](dst)
+		for i, v := range src {
+			d[i] = float32(v)
+		}
+	default:
+		d := view[float64](dst)
+		for i, v := range src {
+			d[i] = float64(v)
+		}
+	}
+}
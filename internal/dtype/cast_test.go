@@ -0,0 +1,84 @@
+package dtype
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/float16"
+)
+
+type index int32
+
+func TestConvertSaturates(t *testing.T) {
+	i8 := make([]int8, 4)
+	Convert(i8, []int64{-1000, -5, 5, 1000})
+	if !slices.Equal(i8, []int8{math.MinInt8, -5, 5, math.MaxInt8}) {
+		t.Errorf("int64 -> int8 = %v", i8)
+	}
+
+	u8 := make([]uint8, 3)
+	Convert(u8, []int32{-1, 7, 300})
+	if !slices.Equal(u8, []uint8{0, 7, math.MaxUint8}) {
+		t.Errorf("int32 -> uint8 = %v", u8)
+	}
+
+	i64 := make([]int64, 2)
+	Convert(i64, []uint64{math.MaxUint64, 1 << 62})
+	if !slices.Equal(i64, []int64{math.MaxInt64, 1 << 62}) {
+		t.Errorf("uint64 -> int64 = %v", i64)
+	}
+
+	u32 := make([]uint32, 5)
+	Convert(u32, []float64{-2.5, 3.9, 1e12, math.NaN(), math.Inf(-1)})
+	if !slices.Equal(u32, []uint32{0, 3, math.MaxUint32, 0, 0}) {
+		t.Errorf("float64 -> uint32 = %v", u32)
+	}
+
+	i64 = make([]int64, 3)
+	Convert(i64, []float32{-3.9, float32(math.Inf(1)), -1e30})
+	if !slices.Equal(i64, []int64{-3, math.MaxInt64, math.MinInt64}) {
+		t.Errorf("float32 -> int64 = %v", i64)
+	}
+}
+
+func TestConvertExactIntegers(t *testing.T) {
+	// 1<<53 + 1 does not survive a float64 round trip.
+	src := []int64{1<<53 + 1, math.MinInt64}
+	got := make([]int, 2)
+	Convert(got, src)
+	if got[0] != 1<<53+1 || got[1] != math.MinInt64 {
+		t.Errorf("int64 -> int = %v", got)
+	}
+}
+
+func TestConvertNamedTypes(t *testing.T) {
+	src := make([]index, 600) // spans several conversion chunks
+	for i := range src {
+		src[i] = index(i - 300)
+	}
+	f32 := make([]float32, len(src))
+	Convert(f32, src)
+	for i, v := range f32 {
+		if v != float32(i-300) {
+			t.Fatalf("f32[%d] = %v, want %d", i, v, i-300)
+		}
+	}
+
+	h := make([]float16.Float16, 2)
+	Convert(h, []index{-2, 3})
+	if h[0].ToFloat32() != -2 || h[1].ToFloat32() != 3 {
+		t.Errorf("index -> float16 = %v, %v", h[0].ToFloat32(), h[1].ToFloat32())
+	}
+
+	if c, bits, ok := ClassOf(reflect.TypeFor[index]()); !ok || c != Signed || bits != 32 {
+		t.Errorf("ClassOf(index) = %v, %d, %v", c, bits, ok)
+	}
+	if c, bits, ok := ClassOf(reflect.TypeFor[float16.BFloat16]()); !ok || c != Float || bits != 16 {
+		t.Errorf("ClassOf(bfloat16) = %v, %d, %v", c, bits, ok)
+	}
+	if _, _, ok := ClassOf(reflect.TypeFor[string]()); ok {
+		t.Error("ClassOf(string) ok, want false")
+	}
+}
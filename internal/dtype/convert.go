@@ -0,0 +1,119 @@
+package dtype
+
+import (
+	"fmt"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/float8"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// ToFloat64 converts a single value to float64. Types it does not know
+// convert to 0. Prefer Float64s in loops.
+func ToFloat64[T tensor.Numeric](v T) float64 {
+	switch val := any(v).(type) {
+	case float32:
+		return float64(val)
+	case float64:
+		return val
+	case int:
+		return float64(val)
+	case int8:
+		return float64(val)
+	case int16:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint:
+		return float64(val)
+	case uint8:
+		return float64(val)
+	case uint32:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float16.Float16:
+		return float64(val.ToFloat32())
+	case float16.BFloat16:
+		return float64(val.ToFloat32())
+	case float8.Float8:
+		return val.ToFloat64()
+	default:
+		return 0
+	}
+}
+
+// Float64s converts src to float64, reusing dst's backing array when it is
+// large enough, and returns the converted slice of len(src).
+func Float64s[T tensor.Numeric](dst []float64, src []T) []float64 {
+	if cap(dst) < len(src) {
+		dst = make([]float64, len(src))
+	}
+	dst = dst[:len(src)]
+	switch s := any(src).(type) {
+	case []float32:
+		for i, v := range s {
+			dst[i] = float64(v)
+		}
+	case []float64:
+		copy(dst, s)
+	case []int:
+		for i, v := range s {
+			dst[i] = float64(v)
+		}
+	case []float16.Float16:
+		for i, v := range s {
+			dst[i] = float64(v.ToFloat32())
+		}
+	case []float16.BFloat16:
+		for i, v := range s {
+			dst[i] = float64(v.ToFloat32())
+		}
+	default:
+		for i, v := range src {
+			dst[i] = ToFloat64(v)
+		}
+	}
+	return dst
+}
+
+// FromFloat64s writes src into dst converted to T. dst must be at least as
+// long as src. Types without a specialized loop convert through ops.
+func FromFloat64s[T tensor.Numeric](ops numeric.Arithmetic[T], dst []T, src []float64) {
+	dst = dst[:len(src)]
+	switch d := any(dst).(type) {
+	case []float32:
+		for i, v := range src {
+			d[i] = float32(v)
+		}
+	case []float64:
+		copy(d, src)
+	default:
+		for i, v := range src {
+			dst[i] = ops.FromFloat64(v)
+		}
+	}
+}
+
+// Ints converts src to int by truncation, for index tensors carried in a
+// floating-point element type. Other element types are an error.
+func Ints[T tensor.Numeric](src []T) ([]int, error) {
+	out := make([]int, len(src))
+	switch s := any(src).(type) {
+	case []float32:
+		for i, v := range s {
+			out[i] = int(v)
+		}
+	case []float64:
+		for i, v := range s {
+			out[i] = int(v)
+		}
+	default:
+		var zero T
+		return nil, fmt.Errorf("unsupported element type %T", zero)
+	}
+	return out, nil
+}
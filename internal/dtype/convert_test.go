@@ -0,0 +1,70 @@
+package dtype
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+func TestFloat64s(t *testing.T) {
+	want := []float64{-1.5, 0, 2.25}
+	if got := Float64s(nil, []float32{-1.5, 0, 2.25}); !slices.Equal(got, want) {
+		t.Errorf("float32: got %v, want %v", got, want)
+	}
+	if got := Float64s(nil, []int8{-1, 0, 2}); !slices.Equal(got, []float64{-1, 0, 2}) {
+		t.Errorf("int8: got %v", got)
+	}
+	h := []float16.Float16{float16.FromFloat32(-1.5), float16.FromFloat32(0), float16.FromFloat32(2.25)}
+	if got := Float64s(nil, h); !slices.Equal(got, want) {
+		t.Errorf("float16: got %v, want %v", got, want)
+	}
+
+	buf := make([]float64, 0, 8)
+	got := Float64s(buf, []float64{1, 2})
+	if &got[0] != &buf[:1][0] {
+		t.Error("Float64s should reuse a large enough dst")
+	}
+}
+
+func TestFromFloat64s(t *testing.T) {
+	src := []float64{-1.5, 0, 2.25}
+	f32 := make([]float32, 3)
+	FromFloat64s[float32](numeric.Float32Ops{}, f32, src)
+	if !slices.Equal(f32, []float32{-1.5, 0, 2.25}) {
+		t.Errorf("float32: got %v", f32)
+	}
+	h := make([]float16.Float16, 3)
+	FromFloat64s[float16.Float16](numeric.Float16Ops{}, h, src)
+	if h[2].ToFloat32() != 2.25 {
+		t.Errorf("float16: got %v", h[2].ToFloat32())
+	}
+}
+
+func TestInts(t *testing.T) {
+	got, err := Ints([]float32{0, 1.9, 7})
+	if err != nil || !slices.Equal(got, []int{0, 1, 7}) {
+		t.Errorf("Ints = %v, %v", got, err)
+	}
+	if _, err := Ints([]int{1}); err == nil {
+		t.Error("expected error for int element type")
+	}
+}
+
+func BenchmarkFloat64s(b *testing.B) {
+	src := make([]float32, 4096)
+	dst := make([]float64, len(src))
+	b.Run("slice", func(b *testing.B) {
+		for b.Loop() {
+			dst = Float64s(dst, src)
+		}
+	})
+	b.Run("element", func(b *testing.B) {
+		for b.Loop() {
+			for i, v := range src {
+				dst[i] = ToFloat64(v)
+			}
+		}
+	})
+}
@@ -0,0 +1,14 @@
+// Package dtype converts tensor element data to and from float64 for host
+// kernels that compute in float64 whatever the tensor's element type.
+//
+// The slice conversions resolve the element type once per call and then run
+// a loop specialized for it. Converting element by element through a type
+// switch boxes every value into an interface, which allocates for float32
+// and dominates small elementwise kernels.
+//
+// Convert casts directly between element types, exactly for integers that
+// fit and saturating otherwise, and ClassOf classifies an element type for
+// dtype promotion. Both treat named types like their underlying type.
+//
+// Stability: alpha
+package dtype
@@ -0,0 +1,13 @@
+// Package engineproxy tracks which compute.EngineProxy instances are
+// recording a trace, so that layers which unwrap a proxy to reach a fused
+// fast path on the real engine can tell when doing so would leave ops out
+// of the trace.
+//
+// A recorder started with StartTracing is visible to Unwrap, which then
+// keeps callers on the proxy, and to Constant, through which layers
+// declare the tensors they build on the host so that a recorder
+// differentiating the trace can tell them from values computed behind its
+// back.
+//
+// Stability: alpha
+package engineproxy
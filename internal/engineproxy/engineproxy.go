@@ -0,0 +1,62 @@
+package engineproxy
+
+import (
+	"sync"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// recorders maps each proxy started with StartTracing to its recorder.
+var recorders sync.Map
+
+// StartTracing starts p recording to r.
+func StartTracing[T tensor.Numeric](p *compute.EngineProxy[T], r compute.TraceRecorder[T]) {
+	recorders.Store(p, r)
+	p.StartTracing(r)
+}
+
+// StopTracing stops a recording started with StartTracing.
+func StopTracing[T tensor.Numeric](p *compute.EngineProxy[T]) {
+	p.StopTracing()
+	recorders.Delete(p)
+}
+
+// Unwrap returns the engine fused fast paths may run on: the real engine
+// behind an EngineProxy, or e itself if it is not a proxy. ok is false when
+// e is a proxy recording a trace; the proxy is returned then, since ops run
+// on the real engine would be missing from the trace, and callers must
+// skip any fast path that bypasses the engine.
+func Unwrap[T tensor.Numeric](e compute.Engine[T]) (real compute.Engine[T], ok bool) {
+	p, isProxy := e.(*compute.EngineProxy[T])
+	if !isProxy {
+		return e, true
+	}
+	if _, tracing := recorders.Load(p); tracing {
+		return e, false
+	}
+	return p.Real(), true
+}
+
+// ConstantRecorder is implemented by recorders that need to know which of
+// the tensors an op reads were built on the host as constants, rather than
+// computed from the traced inputs outside the engine.
+type ConstantRecorder[T tensor.Numeric] interface {
+	RecordConstant(ts ...*tensor.TensorNumeric[T])
+}
+
+// Constant declares ts as constants to the recorder of e if e is a proxy
+// recording a trace. It does nothing otherwise.
+func Constant[T tensor.Numeric](e compute.Engine[T], ts ...*tensor.TensorNumeric[T]) {
+	p, ok := e.(*compute.EngineProxy[T])
+	if !ok {
+		return
+	}
+	r, ok := recorders.Load(p)
+	if !ok {
+		return
+	}
+	if c, ok := r.(ConstantRecorder[T]); ok {
+		c.RecordConstant(ts...)
+	}
+}
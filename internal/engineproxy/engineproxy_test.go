@@ -0,0 +1,60 @@
+package engineproxy
+
+import (
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+type recorder struct {
+	constants []*tensor.TensorNumeric[float32]
+}
+
+func (r *recorder) Record(string, []*tensor.TensorNumeric[float32], *tensor.TensorNumeric[float32], map[string]any) {
+}
+
+func (r *recorder) RecordMultiOutput(string, []*tensor.TensorNumeric[float32], []*tensor.TensorNumeric[float32], map[string]any) {
+}
+
+func (r *recorder) RecordGather(*tensor.TensorNumeric[float32], *tensor.TensorNumeric[int], *tensor.TensorNumeric[float32], map[string]any) {
+}
+
+func (r *recorder) RecordConstant(ts ...*tensor.TensorNumeric[float32]) {
+	r.constants = append(r.constants, ts...)
+}
+
+func TestUnwrapAndConstant(t *testing.T) {
+	cpu := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	proxy := compute.NewEngineProxy[float32](cpu)
+	c, err := tensor.New[float32]([]int{1}, []float32{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e, ok := Unwrap[float32](cpu); e != cpu || !ok {
+		t.Errorf("Unwrap(cpu) = %v, %v; want the engine itself", e, ok)
+	}
+	if e, ok := Unwrap[float32](proxy); e != cpu || !ok {
+		t.Errorf("Unwrap(idle proxy) = %v, %v; want the real engine", e, ok)
+	}
+
+	r := &recorder{}
+	Constant[float32](proxy, c)
+	StartTracing(proxy, r)
+	if e, ok := Unwrap[float32](proxy); e != proxy || ok {
+		t.Errorf("Unwrap(tracing proxy) = %v, %v; want the proxy and false", e, ok)
+	}
+	Constant[float32](proxy, c)
+	Constant[float32](cpu, c)
+	StopTracing(proxy)
+	Constant[float32](proxy, c)
+
+	if len(r.constants) != 1 || r.constants[0] != c {
+		t.Errorf("recorded constants %v, want only the one declared while tracing", r.constants)
+	}
+	if e, ok := Unwrap[float32](proxy); e != cpu || !ok {
+		t.Errorf("Unwrap(stopped proxy) = %v, %v; want the real engine", e, ok)
+	}
+}
@@ -0,0 +1,25 @@
+// Package kernels holds host inner loops specialized per element type:
+// elementwise arithmetic, reductions, and the bias add around GEMM. Every
+// exported loop exists once per concrete type (AddF32, AddF64, AddF16, ...)
+// plus a generic dispatcher (Add) that picks the concrete loop with a single
+// type switch per call, so callers holding a []T never pay for generic or
+// per-element interface dispatch inside the loop.
+//
+// The *Ops helpers (DotOps, SumOps, MaxOps, SubOps, MulOps, ScaleOps,
+// AxpyOps, AddBiasOps) take those loops when T has one and fall back to
+// numeric.Arithmetic otherwise, for host code that must accept every
+// tensor.Numeric type: the attention and Whisper encoder host paths and
+// their softmax, bias adds and gate mixing, MoE gate normalization, the
+// RWKV channel mix, and the DARTS losses, softmax and SGD step.
+//
+// The loops are generated from the templates in gen/main.go; edit those and
+// run go generate rather than editing kernels_gen.go.
+//
+// Reduced-precision floats (float16, bfloat16) are widened to float32 for
+// each operation and rounded once on store. Integer types only get the
+// elementwise loops.
+//
+// Stability: alpha
+package kernels
+
+//go:generate go run ./gen -o kernels_gen.go
@@ -0,0 +1,256 @@
+// Command gen writes the per-type loops of package kernels from the
+// templates below. Run it through go generate in the parent directory.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// dtype describes one element type the loops are generated for.
+type dtype struct {
+	Suffix string // appended to the loop names, e.g. F32
+	Type   string // Go element type
+	Comp   string // type the arithmetic runs in
+	Load   string // widens an element to Comp; %s is the element
+	Store  string // narrows a Comp value to Type; %s is the value
+	Float  bool   // gets the floating-point-only loops
+}
+
+var dtypes = []dtype{
+	{Suffix: "F32", Type: "float32", Comp: "float32", Load: "%s", Store: "%s", Float: true},
+	{Suffix: "F64", Type: "float64", Comp: "float64", Load: "%s", Store: "%s", Float: true},
+	{Suffix: "F16", Type: "float16.Float16", Comp: "float32", Load: "%s.ToFloat32()", Store: "float16.FromFloat32(%s)", Float: true},
+	{Suffix: "BF16", Type: "float16.BFloat16", Comp: "float32", Load: "%s.ToFloat32()", Store: "float16.BFloat16FromFloat32(%s)", Float: true},
+	{Suffix: "Int", Type: "int", Comp: "int", Load: "%s", Store: "%s"},
+	{Suffix: "Int8", Type: "int8", Comp: "int8", Load: "%s", Store: "%s"},
+	{Suffix: "Int16", Type: "int16", Comp: "int16", Load: "%s", Store: "%s"},
+	{Suffix: "Int32", Type: "int32", Comp: "int32", Load: "%s", Store: "%s"},
+	{Suffix: "Int64", Type: "int64", Comp: "int64", Load: "%s", Store: "%s"},
+	{Suffix: "Uint", Type: "uint", Comp: "uint", Load: "%s", Store: "%s"},
+	{Suffix: "Uint8", Type: "uint8", Comp: "uint8", Load: "%s", Store: "%s"},
+	{Suffix: "Uint32", Type: "uint32", Comp: "uint32", Load: "%s", Store: "%s"},
+	{Suffix: "Uint64", Type: "uint64", Comp: "uint64", Load: "%s", Store: "%s"},
+}
+
+// binaryOps are the elementwise dst = a op b loops.
+var binaryOps = []struct{ Name, Op, Doc string }{
+	{"Add", "+", "a + b"},
+	{"Sub", "-", "a - b"},
+	{"Mul", "*", "a * b"},
+}
+
+const header = `// Code generated by go run ./gen; DO NOT EDIT.
+
+package kernels
+
+import (
+	"math"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/ztensor/tensor"
+)
+`
+
+const loops = `
+{{- range $op := .Binary}}
+{{- range $d := $.Types}}
+
+// {{$op.Name}}{{$d.Suffix}} sets dst[i] = {{$op.Doc}} elementwise. a and b must be at least as long as dst.
+func {{$op.Name}}{{$d.Suffix}}(dst, a, b []{{$d.Type}}) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = {{store $d (printf "%s %s %s" (load $d "a[i]") $op.Op (load $d "b[i]"))}}
+	}
+}
+{{- end}}
+
+// {{.Name}} sets dst[i] = {{.Doc}} elementwise using the loop for T. It reports false, leaving dst untouched, when T has none.
+func {{.Name}}[T tensor.Numeric](dst, a, b []T) bool {
+	switch d := any(dst).(type) {
+{{- range $d := $.Types}}
+	case []{{$d.Type}}:
+		{{$op.Name}}{{$d.Suffix}}(d, any(a).([]{{$d.Type}}), any(b).([]{{$d.Type}}))
+{{- end}}
+	default:
+		return false
+	}
+	return true
+}
+{{- end}}
+
+{{- range $d := .Floats}}
+
+// Scale{{$d.Suffix}} sets dst[i] = alpha * x[i]. x must be at least as long as dst.
+func Scale{{$d.Suffix}}(dst, x []{{$d.Type}}, alpha {{$d.Comp}}) {
+	x = x[:len(dst)]
+	for i := range dst {
+		dst[i] = {{store $d (printf "alpha * %s" (load $d "x[i]"))}}
+	}
+}
+
+// Axpy{{$d.Suffix}} sets y[i] += alpha * x[i]. x must be at least as long as y.
+func Axpy{{$d.Suffix}}(alpha {{$d.Comp}}, x, y []{{$d.Type}}) {
+	x = x[:len(y)]
+	for i := range y {
+		y[i] = {{store $d (printf "%s + alpha*%s" (load $d "y[i]") (load $d "x[i]"))}}
+	}
+}
+
+// Sum{{$d.Suffix}} returns the sum of x, accumulated in {{$d.Comp}}.
+func Sum{{$d.Suffix}}(x []{{$d.Type}}) {{$d.Comp}} {
+	var s {{$d.Comp}}
+	for _, v := range x {
+		s += {{load $d "v"}}
+	}
+	return s
+}
+
+// Dot{{$d.Suffix}} returns the inner product of x and y, accumulated in {{$d.Comp}}. y must be at least as long as x.
+func Dot{{$d.Suffix}}(x, y []{{$d.Type}}) {{$d.Comp}} {
+	y = y[:len(x)]
+	var s {{$d.Comp}}
+	for i, v := range x {
+		s += {{load $d "v"}} * {{load $d "y[i]"}}
+	}
+	return s
+}
+
+// Max{{$d.Suffix}} returns the largest element of x, or -Inf when x is empty. NaNs are ignored.
+func Max{{$d.Suffix}}(x []{{$d.Type}}) {{$d.Comp}} {
+	m := {{$d.Comp}}(math.Inf(-1))
+	for _, v := range x {
+		if f := {{load $d "v"}}; f > m {
+			m = f
+		}
+	}
+	return m
+}
+
+// AddBias{{$d.Suffix}} adds bias to every row of the row-major matrix dst, whose row length is len(bias).
+func AddBias{{$d.Suffix}}(dst, bias []{{$d.Type}}) {
+	n := len(bias)
+	for r := 0; r+n <= len(dst); r += n {
+		row := dst[r : r+n]
+		for j, b := range bias {
+			row[j] = {{store $d (printf "%s + %s" (load $d "row[j]") (load $d "b"))}}
+		}
+	}
+}
+{{- end}}
+
+// Scale sets dst[i] = alpha * x[i] using the loop for T. It reports false when T has none.
+func Scale[T tensor.Numeric](dst, x []T, alpha float64) bool {
+	switch d := any(dst).(type) {
+{{- range $d := .Floats}}
+	case []{{$d.Type}}:
+		Scale{{$d.Suffix}}(d, any(x).([]{{$d.Type}}), {{$d.Comp}}(alpha))
+{{- end}}
+	default:
+		return false
+	}
+	return true
+}
+
+// Axpy sets y[i] += alpha * x[i] using the loop for T. It reports false when T has none.
+func Axpy[T tensor.Numeric](alpha float64, x, y []T) bool {
+	switch d := any(y).(type) {
+{{- range $d := .Floats}}
+	case []{{$d.Type}}:
+		Axpy{{$d.Suffix}}({{$d.Comp}}(alpha), any(x).([]{{$d.Type}}), d)
+{{- end}}
+	default:
+		return false
+	}
+	return true
+}
+{{range $r := .Reductions}}
+// {{$r.Name}} {{$r.Doc}} using the loop for T. It reports false when T has none.
+func {{$r.Name}}[T tensor.Numeric]({{$r.Params}} []T) (float64, bool) {
+	switch d := any({{$r.First}}).(type) {
+{{- range $d := $.Floats}}
+	case []{{$d.Type}}:
+		return float64({{$r.Name}}{{$d.Suffix}}(d{{if $r.Second}}, any({{$r.Second}}).([]{{$d.Type}}){{end}})), true
+{{- end}}
+	default:
+		return 0, false
+	}
+}
+{{end}}
+{{- range $r := .RowOps}}
+// {{$r.Name}} {{$r.Doc}} using the loop for T. It reports false when T has none.
+func {{$r.Name}}[T tensor.Numeric]({{$r.Params}} []T) bool {
+	switch d := any({{$r.First}}).(type) {
+{{- range $d := $.Floats}}
+	case []{{$d.Type}}:
+		{{$r.Name}}{{$d.Suffix}}(d, any({{$r.Second}}).([]{{$d.Type}}))
+{{- end}}
+	default:
+		return false
+	}
+	return true
+}
+{{end}}`
+
+type dispatch struct {
+	Name, Doc, Params, First, Second string
+}
+
+func main() {
+	out := flag.String("o", "kernels_gen.go", "output file")
+	flag.Parse()
+
+	funcs := template.FuncMap{
+		"load":  func(d dtype, expr string) string { return fmt.Sprintf(d.Load, expr) },
+		"store": func(d dtype, expr string) string { return fmt.Sprintf(d.Store, expr) },
+	}
+	tmpl := template.Must(template.New("loops").Funcs(funcs).Parse(loops))
+
+	var floats []dtype
+	for _, d := range dtypes {
+		if d.Float {
+			floats = append(floats, d)
+		}
+	}
+	data := map[string]any{
+		"Types":  dtypes,
+		"Floats": floats,
+		"Binary": binaryOps,
+		"Reductions": []dispatch{
+			{Name: "Sum", Doc: "returns the sum of x", Params: "x", First: "x"},
+			{Name: "Dot", Doc: "returns the inner product of x and y", Params: "x, y", First: "x", Second: "y"},
+			{Name: "Max", Doc: "returns the largest element of x", Params: "x", First: "x"},
+		},
+		"RowOps": []dispatch{
+			{Name: "AddBias", Doc: "adds bias to every row of dst", Params: "dst, bias", First: "dst", Second: "bias"},
+		},
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format generated code: %v\n%s", err, numbered(buf.String()))
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// numbered prefixes each line with its number, for locating format errors.
+func numbered(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = fmt.Sprintf("%4d %s", i+1, l)
+	}
+	return strings.Join(lines, "\n")
+}
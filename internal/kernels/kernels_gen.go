@@ -0,0 +1,754 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+
+package kernels
+
+import (
+	"math"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// AddF32 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddF32(dst, a, b []float32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddF64 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddF64(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddF16 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddF16(dst, a, b []float16.Float16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.FromFloat32(a[i].ToFloat32() + b[i].ToFloat32())
+	}
+}
+
+// AddBF16 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddBF16(dst, a, b []float16.BFloat16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.BFloat16FromFloat32(a[i].ToFloat32() + b[i].ToFloat32())
+	}
+}
+
+// AddInt sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddInt(dst, a, b []int) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddInt8 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddInt8(dst, a, b []int8) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddInt16 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddInt16(dst, a, b []int16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddInt32 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddInt32(dst, a, b []int32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddInt64 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddInt64(dst, a, b []int64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddUint sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddUint(dst, a, b []uint) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddUint8 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddUint8(dst, a, b []uint8) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddUint32 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddUint32(dst, a, b []uint32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// AddUint64 sets dst[i] = a + b elementwise. a and b must be at least as long as dst.
+func AddUint64(dst, a, b []uint64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// Add sets dst[i] = a + b elementwise using the loop for T. It reports false, leaving dst untouched, when T has none.
+func Add[T tensor.Numeric](dst, a, b []T) bool {
+	switch d := any(dst).(type) {
+	case []float32:
+		AddF32(d, any(a).([]float32), any(b).([]float32))
+	case []float64:
+		AddF64(d, any(a).([]float64), any(b).([]float64))
+	case []float16.Float16:
+		AddF16(d, any(a).([]float16.Float16), any(b).([]float16.Float16))
+	case []float16.BFloat16:
+		AddBF16(d, any(a).([]float16.BFloat16), any(b).([]float16.BFloat16))
+	case []int:
+		AddInt(d, any(a).([]int), any(b).([]int))
+	case []int8:
+		AddInt8(d, any(a).([]int8), any(b).([]int8))
+	case []int16:
+		AddInt16(d, any(a).([]int16), any(b).([]int16))
+	case []int32:
+		AddInt32(d, any(a).([]int32), any(b).([]int32))
+	case []int64:
+		AddInt64(d, any(a).([]int64), any(b).([]int64))
+	case []uint:
+		AddUint(d, any(a).([]uint), any(b).([]uint))
+	case []uint8:
+		AddUint8(d, any(a).([]uint8), any(b).([]uint8))
+	case []uint32:
+		AddUint32(d, any(a).([]uint32), any(b).([]uint32))
+	case []uint64:
+		AddUint64(d, any(a).([]uint64), any(b).([]uint64))
+	default:
+		return false
+	}
+	return true
+}
+
+// SubF32 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubF32(dst, a, b []float32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubF64 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubF64(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubF16 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubF16(dst, a, b []float16.Float16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.FromFloat32(a[i].ToFloat32() - b[i].ToFloat32())
+	}
+}
+
+// SubBF16 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubBF16(dst, a, b []float16.BFloat16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.BFloat16FromFloat32(a[i].ToFloat32() - b[i].ToFloat32())
+	}
+}
+
+// SubInt sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubInt(dst, a, b []int) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubInt8 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubInt8(dst, a, b []int8) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubInt16 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubInt16(dst, a, b []int16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubInt32 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubInt32(dst, a, b []int32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubInt64 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubInt64(dst, a, b []int64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubUint sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubUint(dst, a, b []uint) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubUint8 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubUint8(dst, a, b []uint8) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubUint32 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubUint32(dst, a, b []uint32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// SubUint64 sets dst[i] = a - b elementwise. a and b must be at least as long as dst.
+func SubUint64(dst, a, b []uint64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// Sub sets dst[i] = a - b elementwise using the loop for T. It reports false, leaving dst untouched, when T has none.
+func Sub[T tensor.Numeric](dst, a, b []T) bool {
+	switch d := any(dst).(type) {
+	case []float32:
+		SubF32(d, any(a).([]float32), any(b).([]float32))
+	case []float64:
+		SubF64(d, any(a).([]float64), any(b).([]float64))
+	case []float16.Float16:
+		SubF16(d, any(a).([]float16.Float16), any(b).([]float16.Float16))
+	case []float16.BFloat16:
+		SubBF16(d, any(a).([]float16.BFloat16), any(b).([]float16.BFloat16))
+	case []int:
+		SubInt(d, any(a).([]int), any(b).([]int))
+	case []int8:
+		SubInt8(d, any(a).([]int8), any(b).([]int8))
+	case []int16:
+		SubInt16(d, any(a).([]int16), any(b).([]int16))
+	case []int32:
+		SubInt32(d, any(a).([]int32), any(b).([]int32))
+	case []int64:
+		SubInt64(d, any(a).([]int64), any(b).([]int64))
+	case []uint:
+		SubUint(d, any(a).([]uint), any(b).([]uint))
+	case []uint8:
+		SubUint8(d, any(a).([]uint8), any(b).([]uint8))
+	case []uint32:
+		SubUint32(d, any(a).([]uint32), any(b).([]uint32))
+	case []uint64:
+		SubUint64(d, any(a).([]uint64), any(b).([]uint64))
+	default:
+		return false
+	}
+	return true
+}
+
+// MulF32 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulF32(dst, a, b []float32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulF64 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulF64(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulF16 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulF16(dst, a, b []float16.Float16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.FromFloat32(a[i].ToFloat32() * b[i].ToFloat32())
+	}
+}
+
+// MulBF16 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulBF16(dst, a, b []float16.BFloat16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.BFloat16FromFloat32(a[i].ToFloat32() * b[i].ToFloat32())
+	}
+}
+
+// MulInt sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulInt(dst, a, b []int) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulInt8 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulInt8(dst, a, b []int8) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulInt16 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulInt16(dst, a, b []int16) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulInt32 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulInt32(dst, a, b []int32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulInt64 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulInt64(dst, a, b []int64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulUint sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulUint(dst, a, b []uint) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulUint8 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulUint8(dst, a, b []uint8) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulUint32 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulUint32(dst, a, b []uint32) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// MulUint64 sets dst[i] = a * b elementwise. a and b must be at least as long as dst.
+func MulUint64(dst, a, b []uint64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+// Mul sets dst[i] = a * b elementwise using the loop for T. It reports false, leaving dst untouched, when T has none.
+func Mul[T tensor.Numeric](dst, a, b []T) bool {
+	switch d := any(dst).(type) {
+	case []float32:
+		MulF32(d, any(a).([]float32), any(b).([]float32))
+	case []float64:
+		MulF64(d, any(a).([]float64), any(b).([]float64))
+	case []float16.Float16:
+		MulF16(d, any(a).([]float16.Float16), any(b).([]float16.Float16))
+	case []float16.BFloat16:
+		MulBF16(d, any(a).([]float16.BFloat16), any(b).([]float16.BFloat16))
+	case []int:
+		MulInt(d, any(a).([]int), any(b).([]int))
+	case []int8:
+		MulInt8(d, any(a).([]int8), any(b).([]int8))
+	case []int16:
+		MulInt16(d, any(a).([]int16), any(b).([]int16))
+	case []int32:
+		MulInt32(d, any(a).([]int32), any(b).([]int32))
+	case []int64:
+		MulInt64(d, any(a).([]int64), any(b).([]int64))
+	case []uint:
+		MulUint(d, any(a).([]uint), any(b).([]uint))
+	case []uint8:
+		MulUint8(d, any(a).([]uint8), any(b).([]uint8))
+	case []uint32:
+		MulUint32(d, any(a).([]uint32), any(b).([]uint32))
+	case []uint64:
+		MulUint64(d, any(a).([]uint64), any(b).([]uint64))
+	default:
+		return false
+	}
+	return true
+}
+
+// ScaleF32 sets dst[i] = alpha * x[i]. x must be at least as long as dst.
+func ScaleF32(dst, x []float32, alpha float32) {
+	x = x[:len(dst)]
+	for i := range dst {
+		dst[i] = alpha * x[i]
+	}
+}
+
+// AxpyF32 sets y[i] += alpha * x[i]. x must be at least as long as y.
+func AxpyF32(alpha float32, x, y []float32) {
+	x = x[:len(y)]
+	for i := range y {
+		y[i] = y[i] + alpha*x[i]
+	}
+}
+
+// SumF32 returns the sum of x, accumulated in float32.
+func SumF32(x []float32) float32 {
+	var s float32
+	for _, v := range x {
+		s += v
+	}
+	return s
+}
+
+// DotF32 returns the inner product of x and y, accumulated in float32. y must be at least as long as x.
+func DotF32(x, y []float32) float32 {
+	y = y[:len(x)]
+	var s float32
+	for i, v := range x {
+		s += v * y[i]
+	}
+	return s
+}
+
+// MaxF32 returns the largest element of x, or -Inf when x is empty. NaNs are ignored.
+func MaxF32(x []float32) float32 {
+	m := float32(math.Inf(-1))
+	for _, v := range x {
+		if f := v; f > m {
+			m = f
+		}
+	}
+	return m
+}
+
+// AddBiasF32 adds bias to every row of the row-major matrix dst, whose row length is len(bias).
+func AddBiasF32(dst, bias []float32) {
+	n := len(bias)
+	for r := 0; r+n <= len(dst); r += n {
+		row := dst[r : r+n]
+		for j, b := range bias {
+			row[j] = row[j] + b
+		}
+	}
+}
+
+// ScaleF64 sets dst[i] = alpha * x[i]. x must be at least as long as dst.
+func ScaleF64(dst, x []float64, alpha float64) {
+	x = x[:len(dst)]
+	for i := range dst {
+		dst[i] = alpha * x[i]
+	}
+}
+
+// AxpyF64 sets y[i] += alpha * x[i]. x must be at least as long as y.
+func AxpyF64(alpha float64, x, y []float64) {
+	x = x[:len(y)]
+	for i := range y {
+		y[i] = y[i] + alpha*x[i]
+	}
+}
+
+// SumF64 returns the sum of x, accumulated in float64.
+func SumF64(x []float64) float64 {
+	var s float64
+	for _, v := range x {
+		s += v
+	}
+	return s
+}
+
+// DotF64 returns the inner product of x and y, accumulated in float64. y must be at least as long as x.
+func DotF64(x, y []float64) float64 {
+	y = y[:len(x)]
+	var s float64
+	for i, v := range x {
+		s += v * y[i]
+	}
+	return s
+}
+
+// MaxF64 returns the largest element of x, or -Inf when x is empty. NaNs are ignored.
+func MaxF64(x []float64) float64 {
+	m := float64(math.Inf(-1))
+	for _, v := range x {
+		if f := v; f > m {
+			m = f
+		}
+	}
+	return m
+}
+
+// AddBiasF64 adds bias to every row of the row-major matrix dst, whose row length is len(bias).
+func AddBiasF64(dst, bias []float64) {
+	n := len(bias)
+	for r := 0; r+n <= len(dst); r += n {
+		row := dst[r : r+n]
+		for j, b := range bias {
+			row[j] = row[j] + b
+		}
+	}
+}
+
+// ScaleF16 sets dst[i] = alpha * x[i]. x must be at least as long as dst.
+func ScaleF16(dst, x []float16.Float16, alpha float32) {
+	x = x[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.FromFloat32(alpha * x[i].ToFloat32())
+	}
+}
+
+// AxpyF16 sets y[i] += alpha * x[i]. x must be at least as long as y.
+func AxpyF16(alpha float32, x, y []float16.Float16) {
+	x = x[:len(y)]
+	for i := range y {
+		y[i] = float16.FromFloat32(y[i].ToFloat32() + alpha*x[i].ToFloat32())
+	}
+}
+
+// SumF16 returns the sum of x, accumulated in float32.
+func SumF16(x []float16.Float16) float32 {
+	var s float32
+	for _, v := range x {
+		s += v.ToFloat32()
+	}
+	return s
+}
+
+// DotF16 returns the inner product of x and y, accumulated in float32. y must be at least as long as x.
+func DotF16(x, y []float16.Float16) float32 {
+	y = y[:len(x)]
+	var s float32
+	for i, v := range x {
+		s += v.ToFloat32() * y[i].ToFloat32()
+	}
+	return s
+}
+
+// MaxF16 returns the largest element of x, or -Inf when x is empty. NaNs are ignored.
+func MaxF16(x []float16.Float16) float32 {
+	m := float32(math.Inf(-1))
+	for _, v := range x {
+		if f := v.ToFloat32(); f > m {
+			m = f
+		}
+	}
+	return m
+}
+
+// AddBiasF16 adds bias to every row of the row-major matrix dst, whose row length is len(bias).
+func AddBiasF16(dst, bias []float16.Float16) {
+	n := len(bias)
+	for r := 0; r+n <= len(dst); r += n {
+		row := dst[r : r+n]
+		for j, b := range bias {
+			row[j] = float16.FromFloat32(row[j].ToFloat32() + b.ToFloat32())
+		}
+	}
+}
+
+// ScaleBF16 sets dst[i] = alpha * x[i]. x must be at least as long as dst.
+func ScaleBF16(dst, x []float16.BFloat16, alpha float32) {
+	x = x[:len(dst)]
+	for i := range dst {
+		dst[i] = float16.BFloat16FromFloat32(alpha * x[i].ToFloat32())
+	}
+}
+
+// AxpyBF16 sets y[i] += alpha * x[i]. x must be at least as long as y.
+func AxpyBF16(alpha float32, x, y []float16.BFloat16) {
+	x = x[:len(y)]
+	for i := range y {
+		y[i] = float16.BFloat16FromFloat32(y[i].ToFloat32() + alpha*x[i].ToFloat32())
+	}
+}
+
+// SumBF16 returns the sum of x, accumulated in float32.
+func SumBF16(x []float16.BFloat16) float32 {
+	var s float32
+	for _, v := range x {
+		s += v.ToFloat32()
+	}
+	return s
+}
+
+// DotBF16 returns the inner product of x and y, accumulated in float32. y must be at least as long as x.
+func DotBF16(x, y []float16.BFloat16) float32 {
+	y = y[:len(x)]
+	var s float32
+	for i, v := range x {
+		s += v.ToFloat32() * y[i].ToFloat32()
+	}
+	return s
+}
+
+// MaxBF16 returns the largest element of x, or -Inf when x is empty. NaNs are ignored.
+func MaxBF16(x []float16.BFloat16) float32 {
+	m := float32(math.Inf(-1))
+	for _, v := range x {
+		if f := v.ToFloat32(); f > m {
+			m = f
+		}
+	}
+	return m
+}
+
+// AddBiasBF16 adds bias to every row of the row-major matrix dst, whose row length is len(bias).
+func AddBiasBF16(dst, bias []float16.BFloat16) {
+	n := len(bias)
+	for r := 0; r+n <= len(dst); r += n {
+		row := dst[r : r+n]
+		for j, b := range bias {
+			row[j] = float16.BFloat16FromFloat32(row[j].ToFloat32() + b.ToFloat32())
+		}
+	}
+}
+
+// Scale sets dst[i] = alpha * x[i] using the loop for T. It reports false when T has none.
+func Scale[T tensor.Numeric](dst, x []T, alpha float64) bool {
+	switch d := any(dst).(type) {
+	case []float32:
+		ScaleF32(d, any(x).([]float32), float32(alpha))
+	case []float64:
+		ScaleF64(d, any(x).([]float64), float64(alpha))
+	case []float16.Float16:
+		ScaleF16(d, any(x).([]float16.Float16), float32(alpha))
+	case []float16.BFloat16:
+		ScaleBF16(d, any(x).([]float16.BFloat16), float32(alpha))
+	default:
+		return false
+	}
+	return true
+}
+
+// Axpy sets y[i] += alpha * x[i] using the loop for T. It reports false when T has none.
+func Axpy[T tensor.Numeric](alpha float64, x, y []T) bool {
+	switch d := any(y).(type) {
+	case []float32:
+		AxpyF32(float32(alpha), any(x).([]float32), d)
+	case []float64:
+		AxpyF64(float64(alpha), any(x).([]float64), d)
+	case []float16.Float16:
+		AxpyF16(float32(alpha), any(x).([]float16.Float16), d)
+	case []float16.BFloat16:
+		AxpyBF16(float32(alpha), any(x).([]float16.BFloat16), d)
+	default:
+		return false
+	}
+	return true
+}
+
+// Sum returns the sum of x using the loop for T. It reports false when T has none.
+func Sum[T tensor.Numeric](x []T) (float64, bool) {
+	switch d := any(x).(type) {
+	case []float32:
+		return float64(SumF32(d)), true
+	case []float64:
+		return float64(SumF64(d)), true
+	case []float16.Float16:
+		return float64(SumF16(d)), true
+	case []float16.BFloat16:
+		return float64(SumBF16(d)), true
+	default:
+		return 0, false
+	}
+}
+
+// Dot returns the inner product of x and y using the loop for T. It reports false when T has none.
+func Dot[T tensor.Numeric](x, y []T) (float64, bool) {
+	switch d := any(x).(type) {
+	case []float32:
+		return float64(DotF32(d, any(y).([]float32))), true
+	case []float64:
+		return float64(DotF64(d, any(y).([]float64))), true
+	case []float16.Float16:
+		return float64(DotF16(d, any(y).([]float16.Float16))), true
+	case []float16.BFloat16:
+		return float64(DotBF16(d, any(y).([]float16.BFloat16))), true
+	default:
+		return 0, false
+	}
+}
+
+// Max returns the largest element of x using the loop for T. It reports false when T has none.
+func Max[T tensor.Numeric](x []T) (float64, bool) {
+	switch d := any(x).(type) {
+	case []float32:
+		return float64(MaxF32(d)), true
+	case []float64:
+		return float64(MaxF64(d)), true
+	case []float16.Float16:
+		return float64(MaxF16(d)), true
+	case []float16.BFloat16:
+		return float64(MaxBF16(d)), true
+	default:
+		return 0, false
+	}
+}
+
+// AddBias adds bias to every row of dst using the loop for T. It reports false when T has none.
+func AddBias[T tensor.Numeric](dst, bias []T) bool {
+	switch d := any(dst).(type) {
+	case []float32:
+		AddBiasF32(d, any(bias).([]float32))
+	case []float64:
+		AddBiasF64(d, any(bias).([]float64))
+	case []float16.Float16:
+		AddBiasF16(d, any(bias).([]float16.Float16))
+	case []float16.BFloat16:
+		AddBiasBF16(d, any(bias).([]float16.BFloat16))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+package kernels
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/float8"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestGeneratedUpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	out := filepath.Join(t.TempDir(), "kernels_gen.go")
+	cmd := exec.Command("go", "run", "./gen", "-o", out)
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generator: %v\n%s", err, msg)
+	}
+	want, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("kernels_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("kernels_gen.go is stale; run go generate ./internal/kernels")
+	}
+}
+
+func TestBinary(t *testing.T) {
+	a := []float64{1, 2, 3, 4}
+	b := []float64{4, 3, 2, 1}
+	dst := make([]float64, 4)
+	for _, tc := range []struct {
+		name string
+		fn   func(dst, a, b []float64) bool
+		want []float64
+	}{
+		{"Add", Add[float64], []float64{5, 5, 5, 5}},
+		{"Sub", Sub[float64], []float64{-3, -1, 1, 3}},
+		{"Mul", Mul[float64], []float64{4, 6, 6, 4}},
+	} {
+		if !tc.fn(dst, a, b) {
+			t.Fatalf("%s: no float64 loop", tc.name)
+		}
+		for i := range dst {
+			if dst[i] != tc.want[i] {
+				t.Errorf("%s[%d] = %v, want %v", tc.name, i, dst[i], tc.want[i])
+			}
+		}
+	}
+
+	ints := []int32{1, 2, 3}
+	if !Add(ints, ints, []int32{10, 20, 30}) || ints[2] != 33 {
+		t.Errorf("in-place int32 Add = %v", ints)
+	}
+
+	h := []float16.Float16{float16.FromFloat32(1.5), float16.FromFloat32(-2)}
+	if !Mul(h, h, h) || h[0].ToFloat32() != 2.25 || h[1].ToFloat32() != 4 {
+		t.Errorf("float16 Mul = %v, %v", h[0].ToFloat32(), h[1].ToFloat32())
+	}
+}
+
+func TestFloatOnlyLoopsRejectIntegers(t *testing.T) {
+	x := []int{1, 2}
+	if Scale(x, x, 2) || Axpy(2, x, x) || AddBias(x, x) {
+		t.Error("integer slices should have no float-only loop")
+	}
+	if _, ok := Sum(x); ok {
+		t.Error("integer Sum should report false")
+	}
+}
+
+func TestScaleAxpy(t *testing.T) {
+	x := []float32{1, -2, 3}
+	y := []float32{1, 1, 1}
+	if !Scale(y, x, 0.5) || y[1] != -1 {
+		t.Errorf("Scale = %v", y)
+	}
+	if !Axpy(2, x, y) || y[0] != 2.5 || y[2] != 7.5 {
+		t.Errorf("Axpy = %v", y)
+	}
+}
+
+func TestReductions(t *testing.T) {
+	x := []float64{1, -4, 2, math.NaN()}
+	if s, _ := Sum(x[:3]); s != -1 {
+		t.Errorf("Sum = %v", s)
+	}
+	if d, _ := Dot(x[:3], []float64{1, 1, 2}); d != 1 {
+		t.Errorf("Dot = %v", d)
+	}
+	if m, _ := Max(x); m != 2 {
+		t.Errorf("Max = %v, want NaN skipped", m)
+	}
+	if m, _ := Max([]float32{}); !math.IsInf(m, -1) {
+		t.Errorf("Max(empty) = %v", m)
+	}
+
+	b := []float16.BFloat16{float16.BFloat16FromFloat32(1), float16.BFloat16FromFloat32(2)}
+	if s, ok := Sum(b); !ok || s != 3 {
+		t.Errorf("bfloat16 Sum = %v, %v", s, ok)
+	}
+}
+
+func TestAddBias(t *testing.T) {
+	m := []float64{1, 2, 3, 4, 5, 6}
+	if !AddBias(m, []float64{10, 20, 30}) {
+		t.Fatal("no float64 loop")
+	}
+	want := []float64{11, 22, 33, 14, 25, 36}
+	for i := range m {
+		if m[i] != want[i] {
+			t.Fatalf("AddBias = %v, want %v", m, want)
+		}
+	}
+}
+
+func TestOpsFallback(t *testing.T) {
+	x := []float32{1, 2, 3}
+	y := []float32{4, 5, 6}
+	if d := DotOps[float32](numeric.Float32Ops{}, x, y); d != 32 {
+		t.Errorf("float32 DotOps = %v, want 32", d)
+	}
+	AxpyOps[float32](numeric.Float32Ops{}, 2, x, y)
+	if y[0] != 6 || y[1] != 9 || y[2] != 12 {
+		t.Errorf("float32 AxpyOps = %v", y)
+	}
+
+	ScaleOps[float32](numeric.Float32Ops{}, y, x, -1)
+	if y[0] != -1 || y[2] != -3 {
+		t.Errorf("float32 ScaleOps = %v", y)
+	}
+
+	if s := SumOps[float32](numeric.Float32Ops{}, x); s != 6 {
+		t.Errorf("float32 SumOps = %v, want 6", s)
+	}
+	if m := MaxOps[float32](numeric.Float32Ops{}, []float32{-3, -1, -2}); m != -1 {
+		t.Errorf("float32 MaxOps = %v, want -1", m)
+	}
+	SubOps[float32](numeric.Float32Ops{}, y, x, y)
+	if y[0] != 2 || y[2] != 6 {
+		t.Errorf("float32 SubOps = %v", y)
+	}
+	MulOps[float32](numeric.Float32Ops{}, y, x, x)
+	if y[1] != 4 || y[2] != 9 {
+		t.Errorf("float32 MulOps = %v", y)
+	}
+
+	// int has no reduction loop and float8 no loop at all; both go through
+	// ops.
+	if d := DotOps[int](numeric.IntOps{}, []int{1, 2}, []int{3, 4}); d != 11 {
+		t.Errorf("int DotOps = %v, want 11", d)
+	}
+	if m := MaxOps[int](numeric.IntOps{}, []int{2, 7, 5}); m != 7 {
+		t.Errorf("int MaxOps = %v, want 7", m)
+	}
+	if s := SumOps[int](numeric.IntOps{}, []int{2, 7, 5}); s != 14 {
+		t.Errorf("int SumOps = %v, want 14", s)
+	}
+	ops := numeric.Float8Ops{}
+	f8 := []float8.Float8{ops.FromFloat64(1), ops.FromFloat64(2)}
+	AddBiasOps(ops, f8, []float8.Float8{ops.FromFloat64(1)})
+	if f8[0].ToFloat64() != 2 || f8[1].ToFloat64() != 3 {
+		t.Errorf("float8 AddBiasOps = %v, %v", f8[0].ToFloat64(), f8[1].ToFloat64())
+	}
+}
+
+// BenchmarkAdd compares the generated loop against the per-element
+// numeric.Arithmetic calls it replaces in generic host code.
+func BenchmarkAdd(b *testing.B) {
+	const n = 1 << 16
+	x := make([]float32, n)
+	y := make([]float32, n)
+	for i := range x {
+		x[i] = float32(i)
+		y[i] = 1
+	}
+	b.Run("kernels", func(b *testing.B) {
+		for b.Loop() {
+			Add(x, x, y)
+		}
+	})
+	b.Run("arithmetic", func(b *testing.B) {
+		for b.Loop() {
+			opsAdd[float32](numeric.Float32Ops{}, x, x, y)
+		}
+	})
+}
+
+func opsAdd[T tensor.Numeric](ops numeric.Arithmetic[T], dst, a, b []T) {
+	for i := range dst {
+		dst[i] = ops.Add(a[i], b[i])
+	}
+}
@@ -0,0 +1,100 @@
+package kernels
+
+import (
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+)
+
+// The helpers below wrap the generated dispatchers for callers that must
+// handle every tensor.Numeric type: T with a specialized loop takes it, and
+// any other T (float8) falls back to a loop through ops.
+
+// DotOps returns the inner product of x and y.
+func DotOps[T tensor.Numeric](ops numeric.Arithmetic[T], x, y []T) T {
+	if d, ok := Dot(x, y); ok {
+		return ops.FromFloat64(d)
+	}
+	var dot T
+	for i, v := range x {
+		dot = ops.Add(dot, ops.Mul(v, y[i]))
+	}
+	return dot
+}
+
+// SumOps returns the sum of x.
+func SumOps[T tensor.Numeric](ops numeric.Arithmetic[T], x []T) T {
+	if s, ok := Sum(x); ok {
+		return ops.FromFloat64(s)
+	}
+	var sum T
+	for _, v := range x {
+		sum = ops.Add(sum, v)
+	}
+	return sum
+}
+
+// MaxOps returns the largest element of x, which must not be empty.
+func MaxOps[T tensor.Numeric](ops numeric.Arithmetic[T], x []T) T {
+	if m, ok := Max(x); ok {
+		return ops.FromFloat64(m)
+	}
+	m := x[0]
+	for _, v := range x[1:] {
+		if ops.GreaterThan(v, m) {
+			m = v
+		}
+	}
+	return m
+}
+
+// SubOps sets dst[i] = a[i] - b[i].
+func SubOps[T tensor.Numeric](ops numeric.Arithmetic[T], dst, a, b []T) {
+	if Sub(dst, a, b) {
+		return
+	}
+	for i := range dst {
+		dst[i] = ops.Sub(a[i], b[i])
+	}
+}
+
+// MulOps sets dst[i] = a[i] * b[i].
+func MulOps[T tensor.Numeric](ops numeric.Arithmetic[T], dst, a, b []T) {
+	if Mul(dst, a, b) {
+		return
+	}
+	for i := range dst {
+		dst[i] = ops.Mul(a[i], b[i])
+	}
+}
+
+// ScaleOps sets dst[i] = alpha * x[i].
+func ScaleOps[T tensor.Numeric](ops numeric.Arithmetic[T], dst, x []T, alpha T) {
+	if Scale(dst, x, dtype.ToFloat64(alpha)) {
+		return
+	}
+	for i := range dst {
+		dst[i] = ops.Mul(alpha, x[i])
+	}
+}
+
+// AxpyOps sets y[i] += alpha * x[i].
+func AxpyOps[T tensor.Numeric](ops numeric.Arithmetic[T], alpha T, x, y []T) {
+	if Axpy(dtype.ToFloat64(alpha), x, y) {
+		return
+	}
+	for i, v := range x {
+		y[i] = ops.Add(y[i], ops.Mul(alpha, v))
+	}
+}
+
+// AddBiasOps adds bias to every row of dst.
+func AddBiasOps[T tensor.Numeric](ops numeric.Arithmetic[T], dst, bias []T) {
+	if AddBias(dst, bias) {
+		return
+	}
+	for i, v := range dst {
+		dst[i] = ops.Add(v, bias[i%len(bias)])
+	}
+}
@@ -0,0 +1,7 @@
+// Package ranking computes tie-averaged ranks for the host-side scoring in
+// training/loss and the prediction post-processing in model/postprocess, so
+// predictions are ranked and gaussianized the same way when a model is
+// scored and when its outputs are served.
+//
+// Stability: stable
+package ranking
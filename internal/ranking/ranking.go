@@ -0,0 +1,57 @@
+package ranking
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Transform sets dst[i], for each index i in rows (every index of x when
+// rows is nil), to f of the scaled rank of x[i] among x[rows]: ties share
+// their average 1-based rank r, which scales to (r - 0.5) / n over the n
+// non-NaN values. NaN values are left out of the ranking and stay NaN in
+// dst. dst may be x.
+func Transform(dst, x []float64, rows []int, f func(u float64) float64) {
+	order := make([]int, 0, len(x))
+	visit := func(i int) {
+		if math.IsNaN(x[i]) {
+			dst[i] = x[i]
+		} else {
+			order = append(order, i)
+		}
+	}
+	if rows == nil {
+		for i := range x {
+			visit(i)
+		}
+	} else {
+		for _, i := range rows {
+			visit(i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(x[a], x[b]) })
+	n := len(order)
+	vals := make([]float64, n)
+	for lo := 0; lo < n; {
+		hi := lo + 1
+		for hi < n && x[order[hi]] == x[order[lo]] {
+			hi++
+		}
+		// Ranks lo+1..hi share their average, (lo+hi+1)/2.
+		v := f((float64(lo+hi+1)/2 - 0.5) / float64(n))
+		for k := lo; k < hi; k++ {
+			vals[k] = v
+		}
+		lo = hi
+	}
+	for k, i := range order {
+		dst[i] = vals[k]
+	}
+}
+
+// Scaled is the identity on scaled ranks, for Transform.
+func Scaled(u float64) float64 { return u }
+
+// Gaussian maps a scaled rank u in (0, 1) to the standard normal quantile
+// Φ⁻¹(u).
+func Gaussian(u float64) float64 { return math.Sqrt2 * math.Erfinv(2*u-1) }
@@ -0,0 +1,30 @@
+package ranking
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	nan := math.NaN()
+	x := []float64{3, 1, nan, 2, 2}
+	got := make([]float64, len(x))
+	Transform(got, x, nil, Scaled)
+	// Ranks 4, 1, 2.5, 2.5 of the n = 4 non-NaN values.
+	want := []float64{3.5 / 4, 0.5 / 4, nan, 0.5, 0.5}
+	for i := range want {
+		if !(got[i] == want[i] || math.IsNaN(got[i]) && math.IsNaN(want[i])) {
+			t.Errorf("Transform[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// Only the listed rows are ranked, among themselves, and written.
+	y := []float64{5, 1, 9, 7}
+	Transform(y, y, []int{2, 0}, Gaussian)
+	if y[1] != 1 || y[3] != 7 {
+		t.Errorf("rows outside the group changed: %v", y)
+	}
+	if g := Gaussian(0.75); math.Abs(y[2]-g) > 1e-12 || math.Abs(y[0]+g) > 1e-12 {
+		t.Errorf("group ranks = %v, %v; want ±%v", y[0], y[2], g)
+	}
+}
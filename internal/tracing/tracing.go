@@ -0,0 +1,48 @@
+// Package tracing holds the OpenTelemetry helpers that zerfoo's packages
+// use to emit trace spans.
+//
+// Spans are created from the global TracerProvider (otel.GetTracerProvider),
+// so they cost next to nothing until an application installs one. The
+// zerfoo CLI installs an OTLP exporter when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set; programs embedding zerfoo
+// configure the OpenTelemetry SDK themselves.
+//
+// Instrumented operations:
+//
+//   - cli: one span per CLI command.
+//   - serve: one server span per HTTP request, continuing a W3C
+//     traceparent sent by the client.
+//   - generate: each generation, with child spans for prefill and decode.
+//   - training: each training step, with child spans for data loading,
+//     forward, backward, and the optimizer step.
+//   - distributed: all-reduce, barrier, and broadcast collectives. Trace
+//     context is propagated over gRPC between workers and the coordinator.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scopePrefix prefixes the instrumentation scope of every zerfoo tracer.
+const scopePrefix = "github.com/zerfoo/zerfoo/"
+
+// Start starts a span named name as a child of the span in ctx, if any,
+// with the tracer of the zerfoo package pkg (e.g. "serve"). The tracer is
+// looked up on every call, so a TracerProvider installed later takes
+// effect immediately.
+func Start(ctx context.Context, pkg, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(scopePrefix+pkg).Start(ctx, name, opts...)
+}
+
+// End records err, if non-nil, on span, marks the span failed, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
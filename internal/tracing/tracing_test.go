@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartEnd(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctx, parent := Start(context.Background(), "serve", "parent")
+	_, child := Start(ctx, "training", "child")
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	c, p := spans[0], spans[1]
+	if c.Parent().SpanID() != p.SpanContext().SpanID() {
+		t.Error("child span is not parented to the span in ctx")
+	}
+	if got := c.InstrumentationScope().Name; got != "github.com/zerfoo/zerfoo/training" {
+		t.Errorf("scope = %q", got)
+	}
+	if c.Status().Code != codes.Error || len(c.Events()) != 1 {
+		t.Errorf("child status = %v with %d events, want an error and its event", c.Status(), len(c.Events()))
+	}
+	if p.Status().Code != codes.Unset {
+		t.Errorf("parent status = %v, want unset", p.Status())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/axisutil"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -47,7 +48,14 @@ func (s *Softmax[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeri
 	if len(inputs) != 1 {
 		return nil, fmt.Errorf("Softmax expects 1 input, got %d", len(inputs))
 	}
-	out, err := s.engine.Softmax(ctx, inputs[0], s.axis)
+	axis := s.axis
+	if rank := len(inputs[0].Shape()); rank > 0 {
+		var err error
+		if axis, err = axisutil.Normalize(s.axis, rank); err != nil {
+			return nil, fmt.Errorf("Softmax: %w", err)
+		}
+	}
+	out, err := s.engine.Softmax(ctx, inputs[0], axis)
 	if err != nil {
 		return nil, err
 	}
@@ -84,12 +92,17 @@ func (s *Softmax[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *t
 
 	y := s.output
 	shape := y.Shape()
-	axis := s.axis
-	if axis < 0 {
-		axis += len(shape)
+	if len(shape) == 0 {
+		// Softmax of a scalar is the constant 1, so its gradient is zero.
+		grad, err := s.engine.MulScalar(ctx, dOut, 0)
+		if err != nil {
+			return nil, fmt.Errorf("Softmax.Backward: %w", err)
+		}
+		return []*tensor.TensorNumeric[T]{grad}, nil
 	}
-	if axis < 0 || axis >= len(shape) {
-		return nil, fmt.Errorf("Softmax.Backward: axis %d out of range for shape %v", s.axis, shape)
+	axis, err := axisutil.Normalize(s.axis, len(shape))
+	if err != nil {
+		return nil, fmt.Errorf("Softmax.Backward: shape %v: %w", shape, err)
 	}
 
 	// prod = dOut * y (elementwise)
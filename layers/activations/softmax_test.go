@@ -216,6 +216,27 @@ func TestSoftmaxBackwardErrors(t *testing.T) {
 	}
 }
 
+func TestSoftmaxRank0(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	ctx := context.Background()
+
+	softmax := NewSoftmax(engine, -1)
+	out, err := softmax.Forward(ctx, makeTensor(t, []int{}, []float32{3}))
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	if len(out.Shape()) != 0 || out.Data()[0] != 1 {
+		t.Errorf("Forward = %v %v, want scalar 1", out.Shape(), out.Data())
+	}
+	grads, err := softmax.Backward(ctx, types.FullBackprop, makeTensor(t, []int{}, []float32{4}))
+	if err != nil {
+		t.Fatalf("Backward: %v", err)
+	}
+	if len(grads[0].Shape()) != 0 || grads[0].Data()[0] != 0 {
+		t.Errorf("Backward = %v %v, want scalar 0", grads[0].Shape(), grads[0].Data())
+	}
+}
+
 // TestSoftmaxBackwardKnownValues verifies the analytical gradient for a
 // hand-computable case. For input x = [1, 2, 3] with softmax output
 // y = exp(x)/sum(exp(x)), and upstream gradient dOut = [1, 0, 0]:
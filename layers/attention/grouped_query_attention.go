@@ -5,13 +5,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"unsafe"
 
 	"github.com/zerfoo/zerfoo/generate"
 	cudago "github.com/zerfoo/zerfoo/internal/cuda"
 	"github.com/zerfoo/zerfoo/internal/cuda/kernels"
+	"github.com/zerfoo/zerfoo/internal/engineproxy"
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/zerfoo/layers/core"
 	"github.com/zerfoo/zerfoo/layers/embeddings" // For RoPE
+	"github.com/zerfoo/zerfoo/layers/normalization"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -126,6 +130,18 @@ type GQAOptions[T tensor.Numeric] struct {
 	Bidirectional bool // when true, disables causal masking for encoder-style models
 	NoRoPE        bool // when true, skip RoPE creation (for models like GPT-2 that use learned position embeddings)
 	ExternalKV    bool // when true, K/V are supplied as Forward inputs; wk/wv/k_norm are not instantiated
+	// RotaryFraction is the fraction of each head's dimensions that receive
+	// rotation (default 1.0). Values in (0, 1) enable partial RoPE.
+	RotaryFraction float64
+	// LogitSoftcap, when > 0, soft-caps attention logits as cap * tanh(x / cap).
+	LogitSoftcap float64
+	// QKNorm enables per-head RMSNorm on Q and K after projection, with
+	// QKNormEpsilon as the norm epsilon.
+	QKNorm        bool
+	QKNormEpsilon float64
+	// Initializer, when set, draws the weights of the Q, K, V and output
+	// projections.
+	Initializer components.WeightInitializer[T]
 }
 
 // GQAOption is a function that applies an option to GQAOptions.
@@ -145,6 +161,35 @@ func WithMaxSeqLen[T tensor.Numeric](maxSeqLen int) GQAOption[T] {
 	}
 }
 
+// WithRotaryFraction sets the fraction of each head's dimensions that receive
+// rotary embedding. GPT-NeoX style checkpoints rotate only the leading
+// fraction of the head dimension and pass the remainder through unchanged.
+// The default of 1.0 rotates all dimensions.
+func WithRotaryFraction[T tensor.Numeric](fraction float64) GQAOption[T] {
+	return func(o *GQAOptions[T]) {
+		o.RotaryFraction = fraction
+	}
+}
+
+// WithLogitSoftcapGQA returns an option that soft-caps the scaled attention
+// logits as softcap * tanh(logits / softcap) before softmax, as used by
+// Gemma 2. See WithLogitSoftcap.
+func WithLogitSoftcapGQA[T tensor.Numeric](softcap float64) GQAOption[T] {
+	return func(o *GQAOptions[T]) {
+		o.LogitSoftcap = softcap
+	}
+}
+
+// WithQKNorm returns an option that applies a learnable per-head RMSNorm to
+// Q and K after projection and before RoPE (Gemma 3 style QK-norm). The norm
+// gains are exposed through Parameters and trained by Backward.
+func WithQKNorm[T tensor.Numeric](epsilon float64) GQAOption[T] {
+	return func(o *GQAOptions[T]) {
+		o.QKNorm = true
+		o.QKNormEpsilon = epsilon
+	}
+}
+
 // WithBidirectionalGQA returns an option that disables causal masking in the
 // grouped query attention layer, allowing every position to attend to every
 // other position. This is required for encoder-style models such as BERT.
@@ -178,6 +223,15 @@ func WithNoRoPE[T tensor.Numeric]() GQAOption[T] {
 	}
 }
 
+// WithGQAInitializer returns an option that draws the weights of the Q, K, V
+// and output projections from initializer, such as one from
+// components.NewInitializer.
+func WithGQAInitializer[T tensor.Numeric](initializer components.WeightInitializer[T]) GQAOption[T] {
+	return func(o *GQAOptions[T]) {
+		o.Initializer = initializer
+	}
+}
+
 // NewGroupedQueryAttention creates a new GroupedQueryAttention layer.
 // modelDim: The dimension of the input and output of the block (d_model).
 // numQueryHeads: The number of query heads.
@@ -190,8 +244,9 @@ func NewGroupedQueryAttention[T tensor.Numeric](
 ) (*GroupedQueryAttention[T], error) {
 	// Default options
 	options := &GQAOptions[T]{
-		Base:      10000.0,
-		MaxSeqLen: 2048,
+		Base:           10000.0,
+		MaxSeqLen:      2048,
+		RotaryFraction: 1.0,
 	}
 	for _, opt := range opts {
 		opt(options)
@@ -210,44 +265,51 @@ func NewGroupedQueryAttention[T tensor.Numeric](
 	}
 
 	headDim := modelDim / numQueryHeads
+	var denseOpts []core.DenseOpt[T]
+	if options.Initializer != nil {
+		denseOpts = append(denseOpts, core.WithInitializer(options.Initializer))
+	}
 
 	// Initialize Dense layers for Q, K, V projections
-	wq, err := core.NewDense[T]("wq", engine, ops, modelDim, modelDim)
+	wq, err := core.NewDense[T]("wq", engine, ops, modelDim, modelDim, denseOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WQ dense layer: %w", err)
 	}
 
 	var wk, wv *core.Dense[T]
 	if !options.ExternalKV {
-		wk, err = core.NewDense[T]("wk", engine, ops, modelDim, headDim*numKeyValueHeads) // K projection
+		wk, err = core.NewDense[T]("wk", engine, ops, modelDim, headDim*numKeyValueHeads, denseOpts...) // K projection
 		if err != nil {
 			return nil, fmt.Errorf("failed to create WK dense layer: %w", err)
 		}
 
-		wv, err = core.NewDense[T]("wv", engine, ops, modelDim, headDim*numKeyValueHeads) // V projection
+		wv, err = core.NewDense[T]("wv", engine, ops, modelDim, headDim*numKeyValueHeads, denseOpts...) // V projection
 		if err != nil {
 			return nil, fmt.Errorf("failed to create WV dense layer: %w", err)
 		}
 	}
 
 	// Initialize ScaledDotProductAttention. dk is headDim.
-	scaledDotProductAttention := NewScaledDotProductAttention[T](engine, headDim)
+	scaledDotProductAttention := NewScaledDotProductAttention[T](engine, headDim, WithLogitSoftcap[T](options.LogitSoftcap))
 
 	// Initialize output Dense layer.
-	wo, err := core.NewDense[T]("wo", engine, ops, modelDim, modelDim)
+	wo, err := core.NewDense[T]("wo", engine, ops, modelDim, modelDim, denseOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WO dense layer: %w", err)
 	}
 
 	var rope *embeddings.RotaryPositionalEmbedding[T]
 	if !options.NoRoPE {
-		rope, err = embeddings.NewRotaryPositionalEmbedding[T](context.Background(), engine, headDim, options.MaxSeqLen, embeddings.WithRotaryBase(options.Base))
+		rope, err = embeddings.NewRotaryPositionalEmbedding[T](context.Background(), engine, headDim, options.MaxSeqLen,
+			embeddings.WithRotaryBase(options.Base),
+			embeddings.WithRotaryDimFraction(options.RotaryFraction),
+		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create RotaryPositionalEmbedding: %w", err)
 		}
 	}
 
-	return &GroupedQueryAttention[T]{
+	gqa := &GroupedQueryAttention[T]{
 		engine:                    engine,
 		ops:                       ops,
 		numQueryHeads:             numQueryHeads,
@@ -262,7 +324,22 @@ func NewGroupedQueryAttention[T tensor.Numeric](
 		rope:                      rope,
 		bidirectional:             options.Bidirectional,
 		externalKV:                options.ExternalKV,
-	}, nil
+	}
+
+	if options.QKNorm {
+		eps := ops.FromFloat64(options.QKNormEpsilon)
+		qNorm, err := normalization.NewRMSNorm[T]("q_norm", engine, ops, headDim, normalization.WithRMSNormEpsilon(eps))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Q norm: %w", err)
+		}
+		kNorm, err := normalization.NewRMSNorm[T]("k_norm", engine, ops, headDim, normalization.WithRMSNormEpsilon(eps))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create K norm: %w", err)
+		}
+		gqa.SetQKNorms(qNorm, kNorm)
+	}
+
+	return gqa, nil
 }
 
 // NewGroupedQueryAttentionFromParams creates a new GroupedQueryAttention layer from existing parameters.
@@ -315,6 +392,13 @@ func (gqa *GroupedQueryAttention[T]) SetBidirectional(bidirectional bool) {
 	gqa.bidirectional = bidirectional
 }
 
+// SetLogitSoftcap enables attention logit soft-capping on the inner
+// scaled dot-product attention. This is the FromParams-path counterpart to
+// WithLogitSoftcapGQA. Zero disables soft-capping.
+func (gqa *GroupedQueryAttention[T]) SetLogitSoftcap(softcap float64) {
+	gqa.scaledDotProductAttention.SetLogitSoftcap(softcap)
+}
+
 // SetKEqV configures GQA to use the K projection output for both K and V.
 // When enabled, the V projection (wv) is skipped and K output is used as V.
 // This implements Gemma 4's unified K=V projection for global attention layers.
@@ -435,6 +519,12 @@ func (gqa *GroupedQueryAttention[T]) Parameters() []*graph.Parameter[T] {
 		params = append(params, gqa.wv.Parameters()...)
 	}
 	params = append(params, gqa.wo.Parameters()...)
+	if gqa.qNorm != nil {
+		params = append(params, gqa.qNorm.Parameters()...)
+	}
+	if gqa.kNorm != nil {
+		params = append(params, gqa.kNorm.Parameters()...)
+	}
 
 	if p := gqa.MergedQKVParameter(); p != nil {
 		params = append(params, p)
@@ -525,10 +615,14 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 		}
 	}
 
-	// Cache projected Q, K, V for backward pass
+	// Cache projected Q, K, V for backward pass. The Q/K projections are
+	// the inputs to the optional Q/K norms, whose Backward needs them.
 	gqa.qProj = qProj
 	gqa.kProj = kProj
 	gqa.vProj = vProj
+	if gqa.saver != nil && (gqa.qNorm != nil || gqa.kNorm != nil) {
+		gqa.saver.SaveForBackward(qProj, kProj)
+	}
 
 	// 2. Split into heads, apply optional Q/K norms, then RoPE
 	var qHeadsRoPE, kHeadsRoPE *tensor.TensorNumeric[T]
@@ -552,10 +646,7 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 	// Conditions: decode (seqLen=1), RoPE enabled, Q/K norm weights available, engine supports it.
 	fusedQKNormRoPE := false
 	if seqLen == 1 && gqa.rope != nil && gqa.qNormWeight != nil && gqa.kNormWeight != nil {
-		realEngine := compute.Engine[T](gqa.engine)
-		if proxy, ok := gqa.engine.(*compute.EngineProxy[T]); ok {
-			realEngine = proxy.Real()
-		}
+		realEngine, _ := engineproxy.Unwrap(gqa.engine)
 		if provider, ok := realEngine.(compute.FusedQKNormRoPEProvider[T]); ok {
 			totalHeads := gqa.numQueryHeads + gqa.numKeyValueHeads
 			qkElems := totalHeads * gqa.headDim
@@ -595,10 +686,7 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 			}
 			if gcp, ok := cache.(gpuCounterProvider); ok && gcp.GPUCounterPtr() != nil {
 				// Get stream from compute engine.
-				realEng := compute.Engine[T](gqa.engine)
-				if proxy, ok := gqa.engine.(*compute.EngineProxy[T]); ok {
-					realEng = proxy.Real()
-				}
+				realEng, _ := engineproxy.Unwrap(gqa.engine)
 				if sp, ok := realEng.(compute.StreamProvider); ok {
 					cosAngles, sinAngles, halfRotary, angleErr = gqa.rope.GetAnglesGPU(gcp.GPUCounterPtr(), 1, sp.Stream())
 				}
@@ -751,16 +839,14 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 					GPUCounterPtr() unsafe.Pointer
 				}
 				if gcp, ok := cache.(unfusedGPUCounterProvider); ok && gcp.GPUCounterPtr() != nil {
-					realEng := compute.Engine[T](gqa.engine)
-					if proxy, ok := gqa.engine.(*compute.EngineProxy[T]); ok {
-						realEng = proxy.Real()
-					}
+					realEng, _ := engineproxy.Unwrap(gqa.engine)
 					if sp, ok := realEng.(compute.StreamProvider); ok {
 						cosAngles, sinAngles, _, angleErr := gqa.rope.GetAnglesGPU(gcp.GPUCounterPtr(), seqLen, sp.Stream())
 						if angleErr == nil {
 							if provider, ok := realEng.(compute.FusedRoPEProvider[T]); ok {
-								qOut, qErr := provider.GPUFusedRoPE(qForRoPE, cosAngles, sinAngles, gqa.headDim)
-								kOut, kErr := provider.GPUFusedRoPE(kForRoPE, cosAngles, sinAngles, gqa.headDim)
+								rotaryDim := gqa.rope.RotaryDim()
+								qOut, qErr := provider.GPUFusedRoPE(qForRoPE, cosAngles, sinAngles, rotaryDim)
+								kOut, kErr := provider.GPUFusedRoPE(kForRoPE, cosAngles, sinAngles, rotaryDim)
 								if qErr == nil && kErr == nil {
 									qHeadsRoPE = qOut
 									kHeadsRoPE = kOut
@@ -872,10 +958,7 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 							oGPU, allocErr := tensor.NewGPUStorage[T](oElems, qGS.DeviceID())
 							if allocErr == nil {
 								// Get stream for kernel launch.
-								realEng := compute.Engine[T](gqa.engine)
-								if proxy, ok := gqa.engine.(*compute.EngineProxy[T]); ok {
-									realEng = proxy.Real()
-								}
+								realEng, _ := engineproxy.Unwrap(gqa.engine)
 								var streamPtr unsafe.Pointer
 								if sp, ok := realEng.(compute.StreamProvider); ok {
 									streamPtr = sp.Stream()
@@ -957,10 +1040,7 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 				RepeatInterleave(ctx context.Context, a *tensor.TensorNumeric[U], axis int, reps int, dst ...*tensor.TensorNumeric[U]) (*tensor.TensorNumeric[U], error)
 			}
 			fusedOK := false
-			realEng := compute.Engine[T](gqa.engine)
-			if proxy, ok := gqa.engine.(*compute.EngineProxy[T]); ok {
-				realEng = proxy.Real()
-			}
+			realEng, _ := engineproxy.Unwrap(gqa.engine)
 			if ri, ok := realEng.(repeatInterleaver[T]); ok {
 				kExp, kErr := ri.RepeatInterleave(ctx, kHeadsRoPE, 1, replicationFactor)
 				if kErr == nil {
@@ -1026,7 +1106,17 @@ func (gqa *GroupedQueryAttention[T]) Forward(ctx context.Context, inputs ...*ten
 			return nil, reshapeErr
 		}
 
+		pad := generate.BatchPadding(ctx)
 		switch {
+		case mask == nil && len(pad) == batchSize && slices.ContainsFunc(pad, func(p int) bool { return p > 0 }):
+			// Left-padded batch: hide each row's padding along with the
+			// causal (and sliding window) positions.
+			window := 0
+			if seqLen > 1 {
+				window = gqa.SlidingWindowSize
+			}
+			mask = BuildBatchPaddingMask[T](pad, gqa.numQueryHeads, seqLen, kvSeqLen, window, gqa.bidirectional)
+			gqa.scaledDotProductAttention.SetCausal(false)
 		case gqa.bidirectional:
 			// Encoder-style: no causal masking, all positions attend to all others.
 			gqa.scaledDotProductAttention.SetCausal(false)
@@ -1102,6 +1192,35 @@ func (gqa *GroupedQueryAttention[T]) reverseHeadReplication(ctx context.Context,
 	return gqa.engine.Reshape(ctx, dSum, []int{batchSize * gqa.numKeyValueHeads, seqLen, gqa.headDim})
 }
 
+// qkNormBackward propagates dOut, shaped [batch, seq, numHeads*headDim],
+// through a Q or K norm whose Forward input was proj. Per-head norms run on
+// the [batch, seq, numHeads, headDim] view, matching Forward.
+func (gqa *GroupedQueryAttention[T]) qkNormBackward(ctx context.Context, mode types.BackwardMode, norm graph.Node[T], dOut, proj *tensor.TensorNumeric[T], numHeads int) (*tensor.TensorNumeric[T], error) {
+	if gqa.qkNormPreReshape {
+		grads, err := norm.Backward(ctx, mode, dOut, proj)
+		if err != nil {
+			return nil, err
+		}
+		return grads[0], nil
+	}
+
+	flatShape := dOut.Shape()
+	headShape := []int{flatShape[0], flatShape[1], numHeads, gqa.headDim}
+	dHeads, err := gqa.engine.Reshape(ctx, dOut, headShape)
+	if err != nil {
+		return nil, err
+	}
+	projHeads, err := gqa.engine.Reshape(ctx, proj, headShape)
+	if err != nil {
+		return nil, err
+	}
+	grads, err := norm.Backward(ctx, mode, dHeads, projHeads)
+	if err != nil {
+		return nil, err
+	}
+	return gqa.engine.Reshape(ctx, grads[0], flatShape)
+}
+
 // Backward computes the gradients for GroupedQueryAttention.
 //
 // The backward mirrors the forward in reverse order:
@@ -1112,7 +1231,8 @@ func (gqa *GroupedQueryAttention[T]) reverseHeadReplication(ctx context.Context,
 //  4. Reverse K/V head replication (sum over group copies)
 //  5. RoPE backward
 //  6. Reverse head split (reshape/transpose back to projection shape)
-//  7. wq/wk/wv backward
+//  7. Optional Q/K norm backward
+//  8. wq/wk/wv backward
 func (gqa *GroupedQueryAttention[T]) Backward(ctx context.Context, mode types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
 	if gqa.externalKV {
 		return nil, fmt.Errorf("GroupedQueryAttention: Backward is not supported in externalKV mode")
@@ -1229,7 +1349,21 @@ func (gqa *GroupedQueryAttention[T]) Backward(ctx context.Context, mode types.Ba
 		return nil, fmt.Errorf("dV flatten: %w", err)
 	}
 
-	// 7. wq/wk/wv backward
+	// 7. Q/K norm backward (per-head or pre-reshape, mirroring Forward)
+	if gqa.qNorm != nil {
+		dQProj, err = gqa.qkNormBackward(ctx, mode, gqa.qNorm, dQProj, gqa.qProj, gqa.numQueryHeads)
+		if err != nil {
+			return nil, fmt.Errorf("qNorm backward: %w", err)
+		}
+	}
+	if gqa.kNorm != nil {
+		dKProj, err = gqa.qkNormBackward(ctx, mode, gqa.kNorm, dKProj, gqa.kProj, gqa.numKeyValueHeads)
+		if err != nil {
+			return nil, fmt.Errorf("kNorm backward: %w", err)
+		}
+	}
+
+	// 8. wq/wk/wv backward
 	dInputQ, err := gqa.wq.Backward(ctx, mode, dQProj, input)
 	if err != nil {
 		return nil, fmt.Errorf("wq backward: %w", err)
@@ -1373,9 +1507,52 @@ func BuildCausalSlidingWindowMask[T tensor.Numeric](seqLen, windowSize int) *ten
 	return mask
 }
 
+// BuildBatchPaddingMask creates the attention mask for a left-padded batch
+// (see generate.WithBatchPadding): row b hides its first pad[b] key
+// positions, and, unless bidirectional, the positions after each query's.
+// The seqLen queries sit at the last seqLen of the kvSeqLen key positions.
+// A positive windowSize also hides keys windowSize or more positions
+// behind the query. Padding queries see only themselves, keeping their
+// softmax finite. Shape: [len(pad), numHeads, seqLen, kvSeqLen].
+func BuildBatchPaddingMask[T tensor.Numeric](pad []int, numHeads, seqLen, kvSeqLen, windowSize int, bidirectional bool) *tensor.TensorNumeric[T] {
+	var neg = -1e9
+	largeNeg := T(neg)
+	plane := seqLen * kvSeqLen
+	data := make([]T, len(pad)*numHeads*plane)
+	for b, p := range pad {
+		rows := data[b*numHeads*plane:][:plane]
+		for q := range seqLen {
+			qpos := kvSeqLen - seqLen + q
+			for k := range kvSeqLen {
+				visible := k >= p && (bidirectional || k <= qpos) &&
+					(windowSize <= 0 || qpos-k < windowSize)
+				if qpos < p {
+					visible = k == qpos
+				}
+				if !visible {
+					rows[q*kvSeqLen+k] = largeNeg
+				}
+			}
+		}
+		for h := 1; h < numHeads; h++ {
+			copy(data[(b*numHeads+h)*plane:][:plane], rows)
+		}
+	}
+	mask, _ := tensor.New[T]([]int{len(pad), numHeads, seqLen, kvSeqLen}, data)
+	return mask
+}
+
+// MasksBatchPadding reports that the layer hides the left padding recorded
+// by generate.WithBatchPadding from attention.
+func (gqa *GroupedQueryAttention[T]) MasksBatchPadding() bool {
+	return !gqa.externalKV && gqa.blockTableReader == nil
+}
+
 // Statically assert that the type implements the graph.Node interface.
 var _ graph.Node[float32] = (*GroupedQueryAttention[float32])(nil)
 
+var _ generate.PaddingMasker = (*GroupedQueryAttention[float32])(nil)
+
 // Statically assert that GQA participates in the save-for-backward contract.
 var _ graph.SaverAware[float32] = (*GroupedQueryAttention[float32])(nil)
 
@@ -3,6 +3,7 @@ package attention
 import (
 	"context"
 	"math"
+	"slices"
 	"testing"
 
 	"github.com/zerfoo/ztensor/compute"
@@ -256,3 +257,119 @@ func TestGQA_NoCacheBackwardCompatible(t *testing.T) {
 		t.Errorf("output shape = %v, want [1 %d %d]", shape, seqLen, modelDim)
 	}
 }
+
+// TestGQA_BatchPadding verifies that a left-padded row of a cached batch
+// produces the same outputs as its sequence decoded alone.
+func TestGQA_BatchPadding(t *testing.T) {
+	engine := compute.NewCPUEngine(numeric.Float32Ops{})
+	const modelDim = 8
+	gqa, err := NewGroupedQueryAttention[float32](
+		engine, numeric.Float32Ops{}, modelDim, 4, 2,
+		WithMaxSeqLen[float32](16),
+	)
+	if err != nil {
+		t.Fatalf("construct GQA: %v", err)
+	}
+	gqa.LayerIndex = 0
+
+	token := func(seed int) []float32 {
+		v := make([]float32, modelDim)
+		for i := range v {
+			v[i] = float32((seed*5+i*3)%11)/5 - 1
+		}
+		return v
+	}
+	long := [][]float32{token(1), token(2), token(3)} // prompt of 3 tokens
+	short := [][]float32{token(4)}                    // prompt of 1 token
+	steps := [][2][]float32{{token(5), token(6)}, {token(7), token(8)}}
+
+	input := func(rows ...[]float32) *tensor.TensorNumeric[float32] {
+		var data []float32
+		for _, r := range rows {
+			data = append(data, r...)
+		}
+		x, err := tensor.New([]int{1, len(rows), modelDim}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return x
+	}
+	last := func(out *tensor.TensorNumeric[float32], b int) []float32 {
+		s := out.Shape()
+		return out.Data()[(b*s[1]+s[1]-1)*modelDim:][:modelDim]
+	}
+
+	// Each sequence decoded alone.
+	var want [2][][]float32
+	for b, prompt := range [][][]float32{long, short} {
+		ctx := generate.WithCache[float32](context.Background(), generate.NewKVCache[float32](1, 16))
+		out, err := gqa.Forward(ctx, input(prompt...))
+		if err != nil {
+			t.Fatalf("sequence %d prefill: %v", b, err)
+		}
+		want[b] = append(want[b], slices.Clone(last(out, 0)))
+		for _, step := range steps {
+			out, err = gqa.Forward(ctx, input(step[b]))
+			if err != nil {
+				t.Fatalf("sequence %d decode: %v", b, err)
+			}
+			want[b] = append(want[b], slices.Clone(last(out, 0)))
+		}
+	}
+
+	// Both in one batch, the short prompt left-padded with zeros.
+	zero := make([]float32, modelDim)
+	ctx := generate.WithCache[float32](context.Background(), generate.NewKVCache[float32](1, 16))
+	ctx = generate.WithBatchPadding(ctx, []int{0, 2})
+	prefill, err := tensor.New([]int{2, 3, modelDim}, slices.Concat(long[0], long[1], long[2], zero, zero, short[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := gqa.Forward(ctx, prefill)
+	if err != nil {
+		t.Fatalf("batched prefill: %v", err)
+	}
+	got := [2][][]float32{{slices.Clone(last(out, 0))}, {slices.Clone(last(out, 1))}}
+	for _, step := range steps {
+		x, err := tensor.New([]int{2, 1, modelDim}, slices.Concat(step[0], step[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err = gqa.Forward(ctx, x)
+		if err != nil {
+			t.Fatalf("batched decode: %v", err)
+		}
+		got[0] = append(got[0], slices.Clone(last(out, 0)))
+		got[1] = append(got[1], slices.Clone(last(out, 1)))
+	}
+
+	for b := range got {
+		for step := range got[b] {
+			for i := range got[b][step] {
+				if d := math.Abs(float64(got[b][step][i] - want[b][step][i])); d > 1e-4 {
+					t.Fatalf("sequence %d step %d = %v, want %v", b, step, got[b][step], want[b][step])
+				}
+			}
+		}
+	}
+}
+
+func TestBuildBatchPaddingMask(t *testing.T) {
+	// Row 1 is padded by 2; decode query at position 3 of 4.
+	mask := BuildBatchPaddingMask[float32]([]int{0, 2}, 2, 1, 4, 0, false)
+	if got := mask.Shape(); !slices.Equal(got, []int{2, 2, 1, 4}) {
+		t.Fatalf("shape = %v, want [2 2 1 4]", got)
+	}
+	const n = -1e9
+	want := []float32{0, 0, 0, 0, 0, 0, 0, 0, n, n, 0, 0, n, n, 0, 0}
+	if !slices.Equal(mask.Data(), want) {
+		t.Errorf("mask = %v, want %v", mask.Data(), want)
+	}
+
+	// Prefill of 3 with padding 1: the padding query sees only itself.
+	mask = BuildBatchPaddingMask[float32]([]int{1}, 1, 3, 3, 0, false)
+	want = []float32{0, n, n, n, 0, n, n, 0, 0}
+	if !slices.Equal(mask.Data(), want) {
+		t.Errorf("prefill mask = %v, want %v", mask.Data(), want)
+	}
+}
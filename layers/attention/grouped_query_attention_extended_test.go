@@ -3,10 +3,13 @@ package attention
 import (
 	"context"
 	"math"
+	rand "math/rand/v2"
+	"slices"
 	"testing"
 
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/zerfoo/layers/core"
 	"github.com/zerfoo/zerfoo/layers/embeddings"
 	"github.com/zerfoo/ztensor/numeric"
@@ -1124,3 +1127,43 @@ func TestGroupedQueryAttention_SetExternalKV_RejectsKEqV(t *testing.T) {
 	}()
 	gqa.SetExternalKV(true)
 }
+
+func TestGroupedQueryAttention_WithGQAInitializer(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	init := func(seed uint64) []float32 {
+		t.Helper()
+		orth, err := components.NewInitializer[float32]("orthogonal", ops, rand.New(rand.NewPCG(seed, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gqa, err := NewGroupedQueryAttention[float32](engine, ops, 8, 2, 1, WithGQAInitializer(orth))
+		if err != nil {
+			t.Fatalf("NewGroupedQueryAttention failed: %v", err)
+		}
+		var weights []float32
+		for _, p := range gqa.Parameters() {
+			if len(p.Value.Shape()) == 2 {
+				weights = append(weights, p.Value.Data()...)
+			}
+		}
+		return weights
+	}
+	a := init(5)
+	if len(a) == 0 {
+		t.Fatal("no projection weights")
+	}
+	if !slices.Equal(a, init(5)) {
+		t.Error("the same initializer seed gave different projection weights")
+	}
+	// The 8x8 Q projection comes first; its columns are orthonormal.
+	for c := range 8 {
+		var norm float64
+		for r := range 8 {
+			norm += float64(a[r*8+c] * a[r*8+c])
+		}
+		if math.Abs(norm-1) > 1e-5 {
+			t.Fatalf("column %d of wq has squared norm %v, want 1", c, norm)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/layers/embeddings"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
@@ -462,3 +463,97 @@ func TestGroupedQueryAttention_NoRoPE_ScaleRope(t *testing.T) {
 		t.Fatalf("ScaleRope with nil rope should return nil, got: %v", err)
 	}
 }
+
+func TestGroupedQueryAttention_RotaryFraction(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine(numeric.Float32Ops{})
+	ops := numeric.Float32Ops{}
+
+	const (
+		batchSize        = 1
+		seqLen           = 6
+		modelDim         = 32
+		numQueryHeads    = 4
+		numKeyValueHeads = 2
+		headDim          = modelDim / numQueryHeads
+	)
+
+	partial, err := NewGroupedQueryAttention[float32](
+		engine, ops, modelDim, numQueryHeads, numKeyValueHeads,
+		WithMaxSeqLen[float32](seqLen),
+		WithRotaryFraction[float32](0.5),
+	)
+	if err != nil {
+		t.Fatalf("failed to construct GQA: %v", err)
+	}
+	if got := partial.rope.RotaryDim(); got != headDim/2 {
+		t.Fatalf("RotaryDim() = %d, want %d", got, headDim/2)
+	}
+
+	// Share the projections with a reference layer whose RoPE is built
+	// explicitly, so outputs are comparable element for element.
+	newRoPE := func(fraction float64) *embeddings.RotaryPositionalEmbedding[float32] {
+		rope, err := embeddings.NewRotaryPositionalEmbedding[float32](ctx, engine, headDim, seqLen,
+			embeddings.WithRotaryDimFraction(fraction))
+		if err != nil {
+			t.Fatalf("failed to construct RoPE: %v", err)
+		}
+		return rope
+	}
+	newRef := func(fraction float64) *GroupedQueryAttention[float32] {
+		ref, err := NewGroupedQueryAttentionFromParams[float32](
+			engine, ops, modelDim, numQueryHeads, numKeyValueHeads,
+			partial.wq, partial.wk, partial.wv, partial.wo, newRoPE(fraction),
+		)
+		if err != nil {
+			t.Fatalf("failed to construct reference GQA: %v", err)
+		}
+		return ref
+	}
+
+	inp, err := tensor.New[float32]([]int{batchSize, seqLen, modelDim}, nil)
+	if err != nil {
+		t.Fatalf("failed creating input: %v", err)
+	}
+	for i := range inp.Data() {
+		inp.Data()[i] = float32(i%11)/10.0 - 0.5
+	}
+
+	got, err := partial.Forward(ctx, inp)
+	if err != nil {
+		t.Fatalf("partial Forward failed: %v", err)
+	}
+	same, err := newRef(0.5).Forward(ctx, inp)
+	if err != nil {
+		t.Fatalf("reference Forward failed: %v", err)
+	}
+	full, err := newRef(1.0).Forward(ctx, inp)
+	if err != nil {
+		t.Fatalf("full-rotation Forward failed: %v", err)
+	}
+
+	differs := false
+	for i, v := range got.Data() {
+		if math.Abs(float64(v-same.Data()[i])) > 1e-6 {
+			t.Fatalf("output[%d] = %v, want %v (explicit partial RoPE)", i, v, same.Data()[i])
+		}
+		if math.Abs(float64(v-full.Data()[i])) > 1e-6 {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Fatal("partial rotation output should differ from full rotation output")
+	}
+}
+
+func TestGroupedQueryAttention_RotaryFraction_Invalid(t *testing.T) {
+	engine := compute.NewCPUEngine(numeric.Float32Ops{})
+
+	_, err := NewGroupedQueryAttention[float32](
+		engine, numeric.Float32Ops{}, 16, 4, 4,
+		WithRotaryFraction[float32](1.5),
+	)
+	if err == nil {
+		t.Fatal("expected error for rotary fraction > 1")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/kernels"
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -217,16 +219,11 @@ func (nsa *NativeSparseAttention[T]) Forward(ctx context.Context, inputs ...*ten
 			sw := sigWindow[h]
 			for q := range seqQ {
 				offset := ((b*numHeads+h)*seqQ + q) * headDim
-				for d := range headDim {
-					idx := offset + d
-					outData[idx] = nsa.ops.Add(
-						nsa.ops.Add(
-							nsa.ops.Mul(sc, coarseData[idx]),
-							nsa.ops.Mul(sf, fineData[idx]),
-						),
-						nsa.ops.Mul(sw, windowData[idx]),
-					)
-				}
+				end := offset + headDim
+				out := outData[offset:end]
+				kernels.ScaleOps(nsa.ops, out, coarseData[offset:end], sc)
+				kernels.AxpyOps(nsa.ops, sf, fineData[offset:end], out)
+				kernels.AxpyOps(nsa.ops, sw, windowData[offset:end], out)
 			}
 		}
 	}
@@ -238,7 +235,7 @@ func (nsa *NativeSparseAttention[T]) Forward(ctx context.Context, inputs ...*ten
 // Backward computes gradients for the NativeSparseAttention layer.
 // Gradients flow through the sigmoid gates to the gate parameters and through
 // each attention path via straight-through estimation.
-func (nsa *NativeSparseAttention[T]) Backward(_ context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+func (nsa *NativeSparseAttention[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
 	dShape := dOut.Shape()
 	batch := dShape[0]
 	numHeads := dShape[1]
@@ -250,7 +247,6 @@ func (nsa *NativeSparseAttention[T]) Backward(_ context.Context, _ types.Backwar
 	// We need the forward outputs to compute gate gradients.
 	// Re-run forward paths to get the outputs (in a production implementation
 	// these would be cached during forward).
-	ctx := context.Background()
 	Q, K, V := inputs[0], inputs[1], inputs[2]
 
 	outCoarse, err := nsa.coarse.Forward(ctx, Q, K, V)
@@ -315,21 +311,21 @@ func (nsa *NativeSparseAttention[T]) Backward(_ context.Context, _ types.Backwar
 	if err != nil {
 		return nil, err
 	}
-	if err := nsa.gateCoarse.AddGradient(gcGrad); err != nil {
+	if err := components.AddGradient(ctx, nsa.gateCoarse, gcGrad); err != nil {
 		return nil, fmt.Errorf("NativeSparseAttention backward: coarse gate gradient: %w", err)
 	}
 	gfGrad, err := tensor.New[T]([]int{numHeads}, gateFineGrad)
 	if err != nil {
 		return nil, err
 	}
-	if err := nsa.gateFine.AddGradient(gfGrad); err != nil {
+	if err := components.AddGradient(ctx, nsa.gateFine, gfGrad); err != nil {
 		return nil, fmt.Errorf("NativeSparseAttention backward: fine gate gradient: %w", err)
 	}
 	gwGrad, err := tensor.New[T]([]int{numHeads}, gateWindowGrad)
 	if err != nil {
 		return nil, err
 	}
-	if err := nsa.gateWindow.AddGradient(gwGrad); err != nil {
+	if err := components.AddGradient(ctx, nsa.gateWindow, gwGrad); err != nil {
 		return nil, fmt.Errorf("NativeSparseAttention backward: window gate gradient: %w", err)
 	}
 
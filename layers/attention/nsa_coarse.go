@@ -10,6 +10,8 @@ import (
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/kernels"
 )
 
 // NSACoarseCompression implements the coarse-grained token compression path
@@ -169,11 +171,8 @@ func (nsa *NSACoarseCompression[T]) Forward(_ context.Context, inputs ...*tensor
 				bkBase := (b*numKVHeads + kvHead) * numBlocks * headDim
 
 				for blk := range numBlocks {
-					var dot T
 					bkOff := bkBase + blk*headDim
-					for d := range headDim {
-						dot = nsa.ops.Add(dot, nsa.ops.Mul(qData[qOffset+d], blockKeys[bkOff+d]))
-					}
+					dot := kernels.DotOps(nsa.ops, qData[qOffset:qOffset+headDim], blockKeys[bkOff:bkOff+headDim])
 					coarseScores[blk] = nsa.ops.Mul(dot, scaleT)
 				}
 
@@ -193,10 +192,7 @@ func (nsa *NSACoarseCompression[T]) Forward(_ context.Context, inputs ...*tensor
 					for t := range B {
 						seqIdx := blkIdx*B + t
 						kOff := kvBase + seqIdx*headDim
-						var dot T
-						for d := range headDim {
-							dot = nsa.ops.Add(dot, nsa.ops.Mul(qData[qOffset+d], kData[kOff+d]))
-						}
+						dot := kernels.DotOps(nsa.ops, qData[qOffset:qOffset+headDim], kData[kOff:kOff+headDim])
 						s := nsa.ops.Mul(dot, scaleT)
 						fineScores[si*B+t] = s
 						if first || nsa.ops.GreaterThan(s, maxScore) {
@@ -222,12 +218,7 @@ func (nsa *NSACoarseCompression[T]) Forward(_ context.Context, inputs ...*tensor
 						w := nsa.ops.Div(expScores[si*B+t], sumExp)
 						seqIdx := blkIdx*B + t
 						vOff := kvBase + seqIdx*headDim
-						for d := range headDim {
-							outData[outOffset+d] = nsa.ops.Add(
-								outData[outOffset+d],
-								nsa.ops.Mul(w, vData[vOff+d]),
-							)
-						}
+						kernels.AxpyOps(nsa.ops, w, vData[vOff:vOff+headDim], outData[outOffset:outOffset+headDim])
 					}
 				}
 			}
@@ -10,6 +10,8 @@ import (
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/engineproxy"
 )
 
 // negInfValue returns a large negative value (-1e9) for floating point types.
@@ -33,6 +35,9 @@ type ScaledDotProductAttention[T tensor.Numeric] struct {
 	numQueryHeads int     // Number of query heads (for flash decode GQA dispatch)
 	numKVHeads    int     // Number of KV heads (for flash decode GQA dispatch)
 	causal        bool    // if true, apply causal masking to attention scores
+	// logitSoftcap, when > 0, bounds the scaled attention logits as
+	// cap * tanh(logits / cap) before masking and softmax (Gemma 2).
+	logitSoftcap float64
 
 	// Cached tensors for backward pass. SDPA is not itself a graph.Node
 	// (its Forward takes q/k/v/mask and its Backward is called by the
@@ -44,8 +49,10 @@ type ScaledDotProductAttention[T tensor.Numeric] struct {
 	q                *tensor.TensorNumeric[T]
 	k                *tensor.TensorNumeric[T]
 	v                *tensor.TensorNumeric[T]
+	mask             *tensor.TensorNumeric[T]
 	attentionWeights *tensor.TensorNumeric[T]
-	saver            graph.Saver[T] // fanned in by the owning attention node; nil outside a Graph
+	softcapInput     *tensor.TensorNumeric[T] // scaled logits / cap, the tanh argument; nil when soft-capping is off
+	saver            graph.Saver[T]           // fanned in by the owning attention node; nil outside a Graph
 
 	// Persistent flash-kernel scratch (zerfoo#870, docs/lore.md L-0006).
 	// tryFlashForward and tryFlashDecode allocate GPU buffers directly via
@@ -79,11 +86,24 @@ func (sdpa *ScaledDotProductAttention[T]) SetCausal(causal bool) {
 	sdpa.causal = causal
 }
 
+// SetLogitSoftcap enables attention logit soft-capping. When softcap > 0 the
+// scaled attention logits are replaced by softcap * tanh(logits / softcap)
+// before masking and softmax. Zero disables soft-capping.
+func (sdpa *ScaledDotProductAttention[T]) SetLogitSoftcap(softcap float64) {
+	sdpa.logitSoftcap = softcap
+}
+
+// LogitSoftcap returns the attention logit soft-cap, or 0 when disabled.
+func (sdpa *ScaledDotProductAttention[T]) LogitSoftcap() float64 {
+	return sdpa.logitSoftcap
+}
+
 // ScaledDotProductAttentionOptions holds configuration options for ScaledDotProductAttention.
 type ScaledDotProductAttentionOptions[T tensor.Numeric] struct {
-	bidirectional bool // when true, causal masking is disabled
-	numQueryHeads int  // query head count for flash decode dispatch
-	numKVHeads    int  // KV head count for flash decode dispatch
+	bidirectional bool    // when true, causal masking is disabled
+	numQueryHeads int     // query head count for flash decode dispatch
+	numKVHeads    int     // KV head count for flash decode dispatch
+	logitSoftcap  float64 // when > 0, soft-cap attention logits with cap * tanh(x / cap)
 }
 
 // ScaledDotProductAttentionOption applies an option to ScaledDotProductAttentionOptions.
@@ -107,6 +127,16 @@ func WithHeadCounts[T tensor.Numeric](numQueryHeads, numKVHeads int) ScaledDotPr
 	}
 }
 
+// WithLogitSoftcap returns an option that soft-caps the scaled attention
+// logits as softcap * tanh(logits / softcap) before masking and softmax, as
+// required by Gemma 2 checkpoints. Soft-capping disables the fused flash
+// kernels, which do not implement the cap.
+func WithLogitSoftcap[T tensor.Numeric](softcap float64) ScaledDotProductAttentionOption[T] {
+	return func(o *ScaledDotProductAttentionOptions[T]) {
+		o.logitSoftcap = softcap
+	}
+}
+
 // NewScaledDotProductAttention creates a new ScaledDotProductAttention layer.
 func NewScaledDotProductAttention[T tensor.Numeric](engine compute.Engine[T], headDim int, opts ...ScaledDotProductAttentionOption[T]) *ScaledDotProductAttention[T] {
 	options := &ScaledDotProductAttentionOptions[T]{}
@@ -119,6 +149,7 @@ func NewScaledDotProductAttention[T tensor.Numeric](engine compute.Engine[T], he
 		headDim:       float64(headDim),
 		numQueryHeads: options.numQueryHeads,
 		numKVHeads:    options.numKVHeads,
+		logitSoftcap:  options.logitSoftcap,
 	}
 }
 
@@ -138,6 +169,7 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 	sdpa.q = q
 	sdpa.k = k
 	sdpa.v = v
+	sdpa.mask = mask
 	// Invalidate the cached attention weights from any PREVIOUS step. The
 	// fused paths below (flash decode / flash forward) return early without
 	// setting attentionWeights; Backward treats a non-nil cache as
@@ -149,20 +181,21 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 	// staleness). Always clearing here makes Backward recompute from the
 	// freshly pinned q/k of THIS forward.
 	sdpa.attentionWeights = nil
+	sdpa.softcapInput = nil
 	if sdpa.saver != nil {
-		sdpa.saver.SaveForBackward(q, k, v)
+		sdpa.saver.SaveForBackward(q, k, v, mask)
 	}
+	softcapped := sdpa.logitSoftcap > 0
 
 	// Resolve the engine's GPU stream (compute.StreamProvider, proxy-unwrapped)
 	// once, so both the flash-decode and flash-forward kernels launch
 	// stream-ordered after the engine ops that produced Q/K/V. Launching on a
 	// private stream races with in-flight producers and was observed to silently
 	// corrupt training (Wolf CrossAsset GB10; zerfoo#865/#866).
+	// While the proxy records a trace, the fused paths below are skipped:
+	// they bypass it, so the trace would miss the attention entirely.
 	var engStream unsafe.Pointer
-	realEng := compute.Engine[T](sdpa.engine)
-	if proxy, ok := sdpa.engine.(*compute.EngineProxy[T]); ok {
-		realEng = proxy.Real()
-	}
+	realEng, direct := engineproxy.Unwrap(sdpa.engine)
 	if sp, ok := realEng.(compute.StreamProvider); ok {
 		engStream = sp.Stream()
 	}
@@ -171,7 +204,8 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 	// This path handles the seqLen_Q==1 case that tryFlashForward rejects.
 	// Scratch buffers are cached on sdpa (zerfoo#870) so they are
 	// replay-stable under CUDA-graph capture instead of being freed per call.
-	if mask == nil && sdpa.numQueryHeads > 0 && sdpa.numKVHeads > 0 {
+	// The fused kernels do not implement logit soft-capping.
+	if direct && mask == nil && !softcapped && sdpa.numQueryHeads > 0 && sdpa.numKVHeads > 0 {
 		if result, err := tryFlashDecode(
 			q, k, v, int(sdpa.headDim), sdpa.numQueryHeads, sdpa.numKVHeads, engStream,
 			&sdpa.flashDecOut, &sdpa.flashDecPartialO, &sdpa.flashDecPartialLSE,
@@ -182,12 +216,34 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 
 	// Try fused flash attention when no arbitrary mask is provided.
 	// Flash attention handles causal masking internally via the causal flag.
-	if mask == nil {
+	if direct && mask == nil && !softcapped {
 		if result, err := tryFlashForward(q, k, v, int(sdpa.headDim), sdpa.causal, engStream, &sdpa.flashFwdOut); result != nil || err != nil {
 			return result, err
 		}
 	}
 
+	// Compute head dimension robustly to avoid division by zero
+	d := sdpa.headDim
+	if d <= 0 {
+		// Fallback to deriving from Q's last dimension
+		if q == nil || len(q.Shape()) < 3 {
+			return nil, fmt.Errorf("ScaledDotProductAttention: invalid Q shape %v to infer head dimension", q.Shape())
+		}
+		d = float64(q.Shape()[2])
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("ScaledDotProductAttention: headDim must be > 0, got %v", d)
+	}
+
+	// Fused single-pass attention (SDPAProvider engines, or the tiled CPU
+	// kernel) never materializes the score matrix. Backward recomputes the
+	// attention weights, as after a flash forward.
+	if direct && !softcapped {
+		if result, err := tryFusedSDPA(ctx, realEng, q, k, v, mask, 1/math.Sqrt(d), sdpa.causal); result != nil || err != nil {
+			return result, err
+		}
+	}
+
 	// 1. MatMul Q and K^T
 	// (batch, seq_len_q, head_dim) x (batch, head_dim, seq_len_k) -> (batch, seq_len_q, seq_len_k)
 	// Use MatMulTransposeB when available to avoid explicit Transpose allocation + kernel.
@@ -210,29 +266,13 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 	}
 
 	// 2. Scale attention scores and apply softmax
-	// Compute head dimension robustly to avoid division by zero
-	d := sdpa.headDim
-	if d <= 0 {
-		// Fallback to deriving from Q's last dimension
-		if q == nil || len(q.Shape()) < 3 {
-			return nil, fmt.Errorf("ScaledDotProductAttention: invalid Q shape %v to infer head dimension", q.Shape())
-		}
-		d = float64(q.Shape()[2])
-	}
-	if d <= 0 {
-		return nil, fmt.Errorf("ScaledDotProductAttention: headDim must be > 0, got %v", d)
-	}
 	// Determine whether masking will intervene between scaling and softmax.
 	needsMasking := mask != nil || (sdpa.causal && len(attentionScores.Shape()) >= 2 && attentionScores.Shape()[len(attentionScores.Shape())-2] > 1)
 	scale := float32(1.0 / math.Sqrt(d))
 
 	// Fused softmax+V multiply for decode (seqQ=1).
 	// Combines scale, softmax, and V matmul in a single kernel launch.
-	if q.Shape()[1] == 1 && !needsMasking {
-		realEng := compute.Engine[T](sdpa.engine)
-		if proxy, ok := sdpa.engine.(*compute.EngineProxy[T]); ok {
-			realEng = proxy.Real()
-		}
+	if direct && q.Shape()[1] == 1 && !needsMasking && !softcapped {
 		if fuser, ok := realEng.(compute.FusedSoftmaxVMulProvider[T]); ok {
 			fusedOut, fusedErr := fuser.GPUFusedSoftmaxVMul(attentionScores, v, scale)
 			if fusedErr == nil {
@@ -242,13 +282,9 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 	}
 
 	var attentionWeights *tensor.TensorNumeric[T]
-	if !needsMasking {
+	if direct && !needsMasking && !softcapped {
 		// Fused scaled softmax: single kernel replaces MulScalar + Softmax.
-		realEngine := compute.Engine[T](sdpa.engine)
-		if proxy, ok := sdpa.engine.(*compute.EngineProxy[T]); ok {
-			realEngine = proxy.Real()
-		}
-		if provider, ok := realEngine.(compute.FusedScaledSoftmaxProvider[T]); ok {
+		if provider, ok := realEng.(compute.FusedScaledSoftmaxProvider[T]); ok {
 			out, fusedErr := provider.GPUScaledSoftmax(attentionScores, scale, -1)
 			if fusedErr == nil {
 				attentionWeights = out
@@ -265,64 +301,20 @@ func (sdpa *ScaledDotProductAttention[T]) Forward(ctx context.Context, q, k, v,
 			return nil, err
 		}
 
-		// 3. Apply mask (explicit 4D mask or causal)
-		if mask != nil {
-			batchSize := q.Shape()[0]
-			numHeads := mask.Shape()[1]
-			seqLenQ := q.Shape()[1]
-			seqLenK := k.Shape()[1]
-
-			reshapedScores, err := sdpa.engine.Reshape(ctx, scaledAttentionScores, []int{batchSize / numHeads, numHeads, seqLenQ, seqLenK})
+		if softcapped {
+			scaledAttentionScores, sdpa.softcapInput, err = sdpa.applySoftcap(ctx, scaledAttentionScores)
 			if err != nil {
 				return nil, err
 			}
-
-			maskedScores, err := sdpa.engine.Add(ctx, reshapedScores, mask, nil)
-			if err != nil {
-				return nil, err
+			if sdpa.saver != nil {
+				sdpa.saver.SaveForBackward(sdpa.softcapInput)
 			}
+		}
 
-			scaledAttentionScores, err = sdpa.engine.Reshape(ctx, maskedScores, []int{batchSize, seqLenQ, seqLenK})
-			if err != nil {
-				return nil, err
-			}
-		} else if sdpa.causal {
-			// Apply causal masking directly to 3D scores (batch, seqQ, seqK).
-			// Set positions where q_pos < k_pos to -inf.
-			shape := scaledAttentionScores.Shape()
-			seqQ := shape[1]
-			seqK := shape[2]
-			// During decode (seqQ == 1), every cached position is visible
-			// (offset = seqK - 1, so ki <= seqK-1 == qi+offset for all ki).
-			// Skip masking entirely to avoid a costly .Data() D2H copy on GPU tensors.
-			if seqQ > 1 {
-				// Build a causal mask tensor [1, seqQ, seqK] with 0 for visible
-				// positions and -inf for future positions, then add it to scores.
-				// This avoids .Data() which causes a D2H copy on GPU tensors and
-				// leaves the GPU-side data unmasked (the root cause of the GPU
-				// inference regression where the model ignored causal ordering).
-				batch := shape[0]
-				offset := seqK - seqQ
-				negInf := negInfValue[T]()
-				maskData := make([]T, seqQ*seqK)
-				for qi := range seqQ {
-					for ki := range seqK {
-						if ki > qi+offset {
-							maskData[qi*seqK+ki] = negInf
-						}
-					}
-				}
-				causalMask, maskErr := tensor.New[T]([]int{1, seqQ, seqK}, maskData)
-				if maskErr != nil {
-					return nil, fmt.Errorf("causal mask: %w", maskErr)
-				}
-				// Broadcast [1, seqQ, seqK] across [batch, seqQ, seqK].
-				_ = batch // broadcast handled by engine.Add
-				scaledAttentionScores, err = sdpa.engine.Add(ctx, scaledAttentionScores, causalMask)
-				if err != nil {
-					return nil, fmt.Errorf("causal mask add: %w", err)
-				}
-			}
+		// 3. Apply mask (explicit 4D mask or causal)
+		scaledAttentionScores, err = sdpa.applyMask(ctx, scaledAttentionScores, mask)
+		if err != nil {
+			return nil, err
 		}
 
 		// 4. Apply Softmax
@@ -377,6 +369,16 @@ func (sdpa *ScaledDotProductAttention[T]) Backward(ctx context.Context, mode typ
 		if scaleErr != nil {
 			return nil, fmt.Errorf("SDPA backward: scale scores: %w", scaleErr)
 		}
+		if sdpa.logitSoftcap > 0 {
+			scaled, sdpa.softcapInput, scaleErr = sdpa.applySoftcap(ctx, scaled)
+			if scaleErr != nil {
+				return nil, fmt.Errorf("SDPA backward: softcap recompute: %w", scaleErr)
+			}
+		}
+		scaled, recomputeErr = sdpa.applyMask(ctx, scaled, sdpa.mask)
+		if recomputeErr != nil {
+			return nil, fmt.Errorf("SDPA backward: mask recompute: %w", recomputeErr)
+		}
 		sdpa.attentionWeights, recomputeErr = sdpa.engine.Softmax(ctx, scaled, -1, nil)
 		if recomputeErr != nil {
 			return nil, fmt.Errorf("SDPA backward: softmax recompute: %w", recomputeErr)
@@ -430,6 +432,18 @@ func (sdpa *ScaledDotProductAttention[T]) Backward(ctx context.Context, mode typ
 		return nil, err
 	}
 
+	// 3b. Gradient through the logit soft-cap:
+	// d/dx [cap * tanh(x/cap)] = 1 - tanh^2(x/cap).
+	if sdpa.logitSoftcap > 0 {
+		if sdpa.softcapInput == nil {
+			return nil, fmt.Errorf("ScaledDotProductAttention: softcap backward called without a cached forward")
+		}
+		dScaledAttentionScores, err = sdpa.engine.TanhPrime(ctx, sdpa.softcapInput, dScaledAttentionScores)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 4. Gradient w.r.t. attention_scores (through scaling)
 	// Use the same robust head dimension computation as in Forward
 	d := sdpa.headDim
@@ -464,3 +478,80 @@ func (sdpa *ScaledDotProductAttention[T]) Backward(ctx context.Context, mode typ
 
 	return []*tensor.TensorNumeric[T]{dQ, dK, dV}, nil
 }
+
+// applySoftcap returns softcap * tanh(scores / softcap) together with the
+// tanh argument, which Backward needs to compute the cap's derivative.
+func (sdpa *ScaledDotProductAttention[T]) applySoftcap(ctx context.Context, scores *tensor.TensorNumeric[T]) (capped, tanhInput *tensor.TensorNumeric[T], err error) {
+	ops := sdpa.engine.Ops()
+	tanhInput, err = sdpa.engine.MulScalar(ctx, scores, ops.FromFloat64(1.0/sdpa.logitSoftcap), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("softcap scale: %w", err)
+	}
+	t, err := sdpa.engine.Tanh(ctx, tanhInput)
+	if err != nil {
+		return nil, nil, fmt.Errorf("softcap tanh: %w", err)
+	}
+	capped, err = sdpa.engine.MulScalar(ctx, t, ops.FromFloat64(sdpa.logitSoftcap), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("softcap rescale: %w", err)
+	}
+	return capped, tanhInput, nil
+}
+
+// applyMask adds the explicit 4D mask, or the causal mask when there is none
+// and causal masking is on, to scaled scores of shape (batch, seqQ, seqK).
+func (sdpa *ScaledDotProductAttention[T]) applyMask(ctx context.Context, scores, mask *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	shape := scores.Shape()
+	batchSize, seqQ, seqK := shape[0], shape[1], shape[2]
+
+	if mask != nil {
+		numHeads := mask.Shape()[1]
+		reshapedScores, err := sdpa.engine.Reshape(ctx, scores, []int{batchSize / numHeads, numHeads, seqQ, seqK})
+		if err != nil {
+			return nil, err
+		}
+
+		maskedScores, err := sdpa.engine.Add(ctx, reshapedScores, mask, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return sdpa.engine.Reshape(ctx, maskedScores, []int{batchSize, seqQ, seqK})
+	}
+
+	// Apply causal masking directly to 3D scores (batch, seqQ, seqK).
+	// Set positions where q_pos < k_pos to -inf.
+	// During decode (seqQ == 1), every cached position is visible
+	// (offset = seqK - 1, so ki <= seqK-1 == qi+offset for all ki).
+	// Skip masking entirely to avoid a costly .Data() D2H copy on GPU tensors.
+	if !sdpa.causal || seqQ <= 1 {
+		return scores, nil
+	}
+
+	// Build a causal mask tensor [1, seqQ, seqK] with 0 for visible
+	// positions and -inf for future positions, then add it to scores.
+	// This avoids .Data() which causes a D2H copy on GPU tensors and
+	// leaves the GPU-side data unmasked (the root cause of the GPU
+	// inference regression where the model ignored causal ordering).
+	offset := seqK - seqQ
+	negInf := negInfValue[T]()
+	maskData := make([]T, seqQ*seqK)
+	for qi := range seqQ {
+		for ki := range seqK {
+			if ki > qi+offset {
+				maskData[qi*seqK+ki] = negInf
+			}
+		}
+	}
+	causalMask, err := tensor.New[T]([]int{1, seqQ, seqK}, maskData)
+	if err != nil {
+		return nil, fmt.Errorf("causal mask: %w", err)
+	}
+	engineproxy.Constant(sdpa.engine, causalMask)
+	// Broadcast [1, seqQ, seqK] across [batch, seqQ, seqK].
+	masked, err := sdpa.engine.Add(ctx, scores, causalMask)
+	if err != nil {
+		return nil, fmt.Errorf("causal mask add: %w", err)
+	}
+	return masked, nil
+}
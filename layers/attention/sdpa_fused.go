@@ -0,0 +1,276 @@
+package attention
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/internal/xblas"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// SDPAProvider is implemented by engines that compute scaled dot-product
+// attention, softmax(Q·Kᵀ·scale + mask)·V, in a single fused operation
+// without materializing the attention weights.
+//
+// q is [batch, seqQ, headDim], k is [kvBatch, seqK, headDim] and v is
+// [kvBatch, seqK, vDim], where kvBatch divides batch and query batch b
+// reads key/value batch b/(batch/kvBatch). mask, when non-nil, is added to
+// the scaled scores viewed as [batch/heads, heads, seqQ, seqK] with heads =
+// mask.Shape()[1]. causal hides key positions after each query's position,
+// aligned to the end of the key sequence; it only applies when mask is nil,
+// since an explicit mask replaces causal masking in the composed path and
+// in Backward. An implementation that cannot
+// handle its inputs returns a nil tensor and nil error, and the caller
+// falls back to the composed MatMul/Softmax path.
+type SDPAProvider[T tensor.Numeric] interface {
+	SDPA(ctx context.Context, q, k, v, mask *tensor.TensorNumeric[T], scale float64, causal bool) (*tensor.TensorNumeric[T], error)
+}
+
+// Tile sizes of the CPU kernel. A tile of sdpaKeyTile keys and values stays
+// in cache while each of sdpaQueryTile query rows is scored against it.
+const (
+	sdpaQueryTile = 32
+	sdpaKeyTile   = 64
+)
+
+// tryFusedSDPA computes attention with the engine's SDPAProvider, or with the
+// tiled CPU kernel when the engine is a CPUEngine and T is float32 or
+// float64. It returns nil, nil when neither applies.
+func tryFusedSDPA[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], q, k, v, mask *tensor.TensorNumeric[T], scale float64, causal bool) (*tensor.TensorNumeric[T], error) {
+	if p, ok := engine.(SDPAProvider[T]); ok {
+		return p.SDPA(ctx, q, k, v, mask, scale, causal)
+	}
+	if _, isCPU := engine.(*compute.CPUEngine[T]); !isCPU {
+		return nil, nil
+	}
+	switch any(q).(type) {
+	case *tensor.TensorNumeric[float32]:
+		out, err := cpuSDPA(any(q).(*tensor.TensorNumeric[float32]), any(k).(*tensor.TensorNumeric[float32]),
+			any(v).(*tensor.TensorNumeric[float32]), any(mask).(*tensor.TensorNumeric[float32]), scale, causal)
+		if out == nil {
+			return nil, err
+		}
+		return any(out).(*tensor.TensorNumeric[T]), err
+	case *tensor.TensorNumeric[float64]:
+		out, err := cpuSDPA(any(q).(*tensor.TensorNumeric[float64]), any(k).(*tensor.TensorNumeric[float64]),
+			any(v).(*tensor.TensorNumeric[float64]), any(mask).(*tensor.TensorNumeric[float64]), scale, causal)
+		if out == nil {
+			return nil, err
+		}
+		return any(out).(*tensor.TensorNumeric[T]), err
+	}
+	return nil, nil
+}
+
+// cpuSDPA is the flash-attention style CPU kernel: each query row keeps a
+// running maximum and softmax denominator while it streams over key tiles,
+// so no [seqQ, seqK] score matrix is allocated. Work is split across
+// GOMAXPROCS goroutines by (batch, query tile). It returns nil, nil for
+// shapes the kernel does not handle.
+func cpuSDPA[F float32 | float64](q, k, v, mask *tensor.TensorNumeric[F], scale float64, causal bool) (*tensor.TensorNumeric[F], error) {
+	qs, ks, vs := q.Shape(), k.Shape(), v.Shape()
+	if len(qs) != 3 || len(ks) != 3 || len(vs) != 3 {
+		return nil, nil
+	}
+	batch, seqQ, dim := qs[0], qs[1], qs[2]
+	kvBatch, seqK, vDim := ks[0], ks[1], vs[2]
+	if kvBatch == 0 || batch%kvBatch != 0 || ks[2] != dim || vs[0] != kvBatch || vs[1] != seqK {
+		return nil, nil
+	}
+
+	kern := &sdpaKernel[F]{
+		q: q.Data(), k: k.Data(), v: v.Data(),
+		seqQ: seqQ, seqK: seqK, dim: dim, vDim: vDim,
+		group:  batch / kvBatch,
+		scale:  F(scale),
+		causal: causal && mask == nil,
+	}
+	if mask != nil {
+		ms := mask.Shape()
+		if len(ms) != 4 || ms[1] == 0 || batch%ms[1] != 0 ||
+			(ms[0] != 1 && ms[0] != batch/ms[1]) || (ms[2] != 1 && ms[2] != seqQ) || ms[3] != seqK {
+			return nil, nil
+		}
+		kern.mask = mask.Data()
+		kern.maskShape = [4]int{ms[0], ms[1], ms[2], ms[3]}
+	}
+
+	out := make([]F, batch*seqQ*vDim)
+	qTiles := (seqQ + sdpaQueryTile - 1) / sdpaQueryTile
+	jobs := batch * qTiles
+	if jobs > 0 {
+		workers := min(runtime.GOMAXPROCS(0), jobs)
+		chunk := (jobs + workers - 1) / workers
+		var wg sync.WaitGroup
+		for lo := 0; lo < jobs; lo += chunk {
+			hi := min(lo+chunk, jobs)
+			wg.Go(func() {
+				var s sdpaScratch[F]
+				for j := lo; j < hi; j++ {
+					b, t := j/qTiles, j%qTiles
+					kern.block(out, b, t*sdpaQueryTile, min((t+1)*sdpaQueryTile, seqQ), &s)
+				}
+			})
+		}
+		wg.Wait()
+	}
+	return tensor.New([]int{batch, seqQ, vDim}, out)
+}
+
+// sdpaKernel holds the inputs of one cpuSDPA call, shared read-only by its
+// goroutines.
+type sdpaKernel[F float32 | float64] struct {
+	q, k, v, mask []F
+	maskShape     [4]int
+	seqQ, seqK    int
+	dim, vDim     int
+	group         int // query batches per key/value batch
+	scale         F
+	causal        bool
+}
+
+// sdpaScratch holds one goroutine's per-tile buffers.
+type sdpaScratch[F float32 | float64] struct {
+	kT, scores, pv []F
+	max, sum       []F
+}
+
+func grow[F float32 | float64](buf []F, n int) []F {
+	if cap(buf) < n {
+		return make([]F, n)
+	}
+	return buf[:n]
+}
+
+// block computes output rows [q0, q1) of query batch b into out. For each
+// key tile it scores the query tile with one GEMM, rescales the running
+// softmax state of every row, and accumulates P·V with a second GEMM.
+func (kn *sdpaKernel[F]) block(out []F, b, q0, q1 int, s *sdpaScratch[F]) {
+	rows := q1 - q0
+	s.max = grow(s.max, rows)
+	s.sum = grow(s.sum, rows)
+	rowMax, rowSum := s.max, s.sum
+	for r := range rows {
+		rowMax[r] = F(math.Inf(-1))
+		rowSum[r] = 0
+	}
+
+	kvb := b / kn.group
+	keys := kn.k[kvb*kn.seqK*kn.dim:][:kn.seqK*kn.dim]
+	vals := kn.v[kvb*kn.seqK*kn.vDim:][:kn.seqK*kn.vDim]
+	qTile := kn.q[(b*kn.seqQ+q0)*kn.dim:][:rows*kn.dim]
+	acc := out[(b*kn.seqQ+q0)*kn.vDim:][:rows*kn.vDim]
+	offset := kn.seqK - kn.seqQ
+	negInf := F(math.Inf(-1))
+
+	limit := kn.seqK
+	if kn.causal {
+		limit = min(limit, q1+offset)
+	}
+	for k0 := 0; k0 < limit; k0 += sdpaKeyTile {
+		k1 := min(k0+sdpaKeyTile, limit)
+		n := k1 - k0
+
+		// scores = qTile · keys[k0:k1]ᵀ
+		s.kT = grow(s.kT, kn.dim*n)
+		for j := range n {
+			for d, x := range keys[(k0+j)*kn.dim:][:kn.dim] {
+				s.kT[d*n+j] = x
+			}
+		}
+		s.scores = grow(s.scores, rows*n)
+		gemm(rows, n, kn.dim, qTile, s.kT, s.scores)
+
+		for r := range rows {
+			qi := q0 + r
+			scores := s.scores[r*n:][:n]
+			end := n
+			if kn.causal {
+				end = max(min(end, qi+offset+1-k0), 0)
+			}
+			maskRow := kn.maskRow(b, qi)
+
+			tileMax := negInf
+			for j := range scores {
+				if j >= end {
+					scores[j] = negInf
+					continue
+				}
+				sc := scores[j] * kn.scale
+				if maskRow != nil {
+					sc += maskRow[k0+j]
+				}
+				scores[j] = sc
+				tileMax = max(tileMax, sc)
+			}
+
+			newMax := max(rowMax[r], tileMax)
+			if math.IsInf(float64(newMax), -1) {
+				// Every key so far is masked out.
+				clear(scores)
+				continue
+			}
+			if corr := F(math.Exp(float64(rowMax[r] - newMax))); corr != 1 {
+				rowSum[r] *= corr
+				accRow := acc[r*kn.vDim:][:kn.vDim]
+				for d := range accRow {
+					accRow[d] *= corr
+				}
+			}
+			rowMax[r] = newMax
+			for j, sc := range scores {
+				p := F(math.Exp(float64(sc - newMax)))
+				scores[j] = p
+				rowSum[r] += p
+			}
+		}
+
+		// acc += P · vals[k0:k1]
+		s.pv = grow(s.pv, rows*kn.vDim)
+		gemm(rows, kn.vDim, n, s.scores, vals[k0*kn.vDim:][:n*kn.vDim], s.pv)
+		for i, x := range s.pv {
+			acc[i] += x
+		}
+	}
+
+	for r := range rows {
+		if rowSum[r] == 0 {
+			continue
+		}
+		inv := 1 / rowSum[r]
+		for d := range kn.vDim {
+			acc[r*kn.vDim+d] *= inv
+		}
+	}
+}
+
+// gemm sets c = a·b for row-major a (m×k) and b (k×n).
+func gemm[F float32 | float64](m, n, k int, a, b, c []F) {
+	switch c := any(c).(type) {
+	case []float32:
+		clear(c[:m*n])
+		xblas.GemmF32(m, n, k, any(a).([]float32), any(b).([]float32), c)
+	case []float64:
+		xblas.GemmF64(m, n, k, any(a).([]float64), any(b).([]float64), c)
+	}
+}
+
+// maskRow returns the mask values for query qi of batch b, or nil without a
+// mask.
+func (kn *sdpaKernel[F]) maskRow(b, qi int) []F {
+	if kn.mask == nil {
+		return nil
+	}
+	m := kn.maskShape
+	outer, head := b/m[1], b%m[1]
+	if m[0] == 1 {
+		outer = 0
+	}
+	if m[2] == 1 {
+		qi = 0
+	}
+	return kn.mask[((outer*m[1]+head)*m[2]+qi)*m[3]:][:m[3]]
+}
@@ -0,0 +1,306 @@
+package attention
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// composedEngine hides the concrete CPUEngine type so SDPA takes the
+// composed MatMul/Softmax path.
+type composedEngine[T tensor.Numeric] struct {
+	compute.Engine[T]
+}
+
+func randTensor[F float32 | float64](t testing.TB, r *rand.Rand, shape ...int) *tensor.TensorNumeric[F] {
+	t.Helper()
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	data := make([]F, n)
+	for i := range data {
+		data[i] = F(r.Float64()*2 - 1)
+	}
+	tt, err := tensor.New(shape, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tt
+}
+
+// referenceSDPA is a direct float64 evaluation of
+// softmax(Q·Kᵀ·scale + mask)·V with the kernel's batching and mask rules:
+// causal masking only applies without an explicit mask.
+func referenceSDPA[F float32 | float64](q, k, v, mask *tensor.TensorNumeric[F], scale float64, causal bool) []float64 {
+	qs, ks, vs := q.Shape(), k.Shape(), v.Shape()
+	batch, seqQ, dim, seqK, vDim := qs[0], qs[1], qs[2], ks[1], vs[2]
+	group := batch / ks[0]
+	qd, kd, vd := q.Data(), k.Data(), v.Data()
+	out := make([]float64, batch*seqQ*vDim)
+	for b := range batch {
+		kb := b / group
+		for i := range seqQ {
+			scores := make([]float64, seqK)
+			best := math.Inf(-1)
+			for j := range seqK {
+				var dot float64
+				for d := range dim {
+					dot += float64(qd[(b*seqQ+i)*dim+d]) * float64(kd[(kb*seqK+j)*dim+d])
+				}
+				scores[j] = dot * scale
+				if mask != nil {
+					ms := mask.Shape()
+					outer, head, mi := b/ms[1], b%ms[1], i
+					if ms[0] == 1 {
+						outer = 0
+					}
+					if ms[2] == 1 {
+						mi = 0
+					}
+					scores[j] += float64(mask.Data()[((outer*ms[1]+head)*ms[2]+mi)*ms[3]+j])
+				}
+				if causal && mask == nil && j > i+seqK-seqQ {
+					scores[j] = math.Inf(-1)
+				}
+				best = max(best, scores[j])
+			}
+			var sum float64
+			for j := range scores {
+				scores[j] = math.Exp(scores[j] - best)
+				sum += scores[j]
+			}
+			for j := range seqK {
+				for d := range vDim {
+					out[(b*seqQ+i)*vDim+d] += scores[j] / sum * float64(vd[(kb*seqK+j)*vDim+d])
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestCPUSDPA_MatchesReference(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tests := []struct {
+		name                            string
+		batch, kvBatch, seqQ, seqK, dim int
+		causal                          bool
+		mask                            []int
+	}{
+		{name: "single tile", batch: 2, kvBatch: 2, seqQ: 5, seqK: 7, dim: 4},
+		{name: "many tiles", batch: 3, kvBatch: 3, seqQ: 70, seqK: 150, dim: 8},
+		{name: "causal prefill", batch: 2, kvBatch: 2, seqQ: 100, seqK: 100, dim: 8, causal: true},
+		{name: "causal with cache", batch: 2, kvBatch: 2, seqQ: 40, seqK: 130, dim: 8, causal: true},
+		{name: "decode", batch: 4, kvBatch: 4, seqQ: 1, seqK: 90, dim: 16, causal: true},
+		{name: "shared kv", batch: 6, kvBatch: 2, seqQ: 9, seqK: 9, dim: 4},
+		{name: "full mask", batch: 4, kvBatch: 4, seqQ: 6, seqK: 6, dim: 4, mask: []int{2, 2, 6, 6}},
+		{name: "broadcast mask", batch: 4, kvBatch: 4, seqQ: 6, seqK: 6, dim: 4, mask: []int{1, 1, 6, 6}},
+		{name: "row mask", batch: 4, kvBatch: 4, seqQ: 6, seqK: 80, dim: 4, mask: []int{1, 4, 1, 80}},
+		{name: "mask overrides causal", batch: 2, kvBatch: 2, seqQ: 6, seqK: 6, dim: 4, causal: true, mask: []int{1, 1, 6, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := randTensor[float32](t, r, tt.batch, tt.seqQ, tt.dim)
+			k := randTensor[float32](t, r, tt.kvBatch, tt.seqK, tt.dim)
+			v := randTensor[float32](t, r, tt.kvBatch, tt.seqK, tt.dim+1)
+			var mask *tensor.TensorNumeric[float32]
+			if tt.mask != nil {
+				mask = randTensor[float32](t, r, tt.mask...)
+			}
+			scale := 1 / math.Sqrt(float64(tt.dim))
+
+			got, err := cpuSDPA(q, k, v, mask, scale, tt.causal)
+			if err != nil || got == nil {
+				t.Fatalf("cpuSDPA = %v, %v", got, err)
+			}
+			if want := []int{tt.batch, tt.seqQ, tt.dim + 1}; !equalShape(got.Shape(), want) {
+				t.Fatalf("shape %v, want %v", got.Shape(), want)
+			}
+			want := referenceSDPA(q, k, v, mask, scale, tt.causal)
+			for i, g := range got.Data() {
+				if math.Abs(float64(g)-want[i]) > 1e-5 {
+					t.Fatalf("out[%d] = %v, want %v", i, g, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCPUSDPA_Float64(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	q := randTensor[float64](t, r, 2, 33, 8)
+	k := randTensor[float64](t, r, 2, 65, 8)
+	v := randTensor[float64](t, r, 2, 65, 8)
+	got, err := cpuSDPA(q, k, v, nil, 0.3, true)
+	if err != nil || got == nil {
+		t.Fatalf("cpuSDPA = %v, %v", got, err)
+	}
+	want := referenceSDPA(q, k, v, nil, 0.3, true)
+	for i, g := range got.Data() {
+		if math.Abs(g-want[i]) > 1e-12 {
+			t.Fatalf("out[%d] = %v, want %v", i, g, want[i])
+		}
+	}
+}
+
+func TestCPUSDPA_UnsupportedShapes(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	q := randTensor[float32](t, r, 3, 4, 4)
+	k := randTensor[float32](t, r, 2, 4, 4)
+	if out, err := cpuSDPA(q, k, k, nil, 1, false); out != nil || err != nil {
+		t.Errorf("kv batch not dividing query batch: got %v, %v", out, err)
+	}
+	q = randTensor[float32](t, r, 2, 4, 4)
+	mask := randTensor[float32](t, r, 4, 4)
+	if out, err := cpuSDPA(q, k, k, mask, 1, false); out != nil || err != nil {
+		t.Errorf("2D mask: got %v, %v", out, err)
+	}
+}
+
+// TestSDPA_FusedMatchesComposed checks that the CPU engine's fused forward
+// agrees with the composed path, and that Backward, which then recomputes
+// the attention weights, reapplies the same masking.
+func TestSDPA_FusedMatchesComposed(t *testing.T) {
+	cpu := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	ctx := context.Background()
+	r := rand.New(rand.NewPCG(7, 8))
+	const headDim = 8
+
+	for _, tc := range []struct {
+		name   string
+		causal bool
+		mask   bool
+	}{
+		{name: "bidirectional"},
+		{name: "causal", causal: true},
+		{name: "mask", mask: true},
+		// The padding-style mask leaves future keys visible, so applying
+		// causal masking on top would change the result.
+		{name: "mask and causal", causal: true, mask: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := randTensor[float32](t, r, 4, 12, headDim)
+			k := randTensor[float32](t, r, 4, 12, headDim)
+			v := randTensor[float32](t, r, 4, 12, headDim)
+			dOut := randTensor[float32](t, r, 4, 12, headDim)
+			var mask *tensor.TensorNumeric[float32]
+			if tc.mask && tc.causal {
+				mask = randTensor[float32](t, r, 1, 1, 12, 12)
+			} else if tc.mask {
+				mask = BuildCausalSlidingWindowMask[float32](12, 4)
+			}
+
+			fused := NewScaledDotProductAttention[float32](cpu, headDim)
+			composed := NewScaledDotProductAttention[float32](&composedEngine[float32]{cpu}, headDim)
+			fused.SetCausal(tc.causal)
+			composed.SetCausal(tc.causal)
+
+			gotOut, err := fused.Forward(ctx, q, k, v, mask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fused.attentionWeights != nil {
+				t.Error("fused forward materialized the attention weights")
+			}
+			wantOut, err := composed.Forward(ctx, q, k, v, mask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "output", gotOut.Data(), wantOut.Data(), 1e-5)
+
+			gotGrads, err := fused.Backward(ctx, types.FullBackprop, dOut, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantGrads, err := composed.Backward(ctx, types.FullBackprop, dOut, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, name := range []string{"dQ", "dK", "dV"} {
+				assertClose(t, name, gotGrads[i].Data(), wantGrads[i].Data(), 1e-4)
+			}
+		})
+	}
+}
+
+type fakeSDPAEngine struct {
+	compute.Engine[float32]
+	calls  int
+	causal bool
+}
+
+func (f *fakeSDPAEngine) SDPA(_ context.Context, q, _, _, _ *tensor.TensorNumeric[float32], _ float64, causal bool) (*tensor.TensorNumeric[float32], error) {
+	f.calls++
+	f.causal = causal
+	return q, nil
+}
+
+func TestSDPA_UsesSDPAProvider(t *testing.T) {
+	eng := &fakeSDPAEngine{Engine: compute.NewCPUEngine[float32](numeric.Float32Ops{})}
+	sdpa := NewScaledDotProductAttention[float32](eng, 4)
+	sdpa.SetCausal(true)
+	q := randTensor[float32](t, rand.New(rand.NewPCG(9, 10)), 1, 3, 4)
+	out, err := sdpa.Forward(context.Background(), q, q, q, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eng.calls != 1 || !eng.causal || out != q {
+		t.Errorf("provider calls = %d, causal = %v", eng.calls, eng.causal)
+	}
+}
+
+func equalShape(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func assertClose(t *testing.T, name string, got, want []float32, tol float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(float64(got[i]-want[i])) > tol {
+			t.Fatalf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func benchmarkSDPA(b *testing.B, engine compute.Engine[float32], seqLen int) {
+	r := rand.New(rand.NewPCG(1, 2))
+	const heads, headDim = 8, 64
+	q := randTensor[float32](b, r, heads, seqLen, headDim)
+	k := randTensor[float32](b, r, heads, seqLen, headDim)
+	v := randTensor[float32](b, r, heads, seqLen, headDim)
+	sdpa := NewScaledDotProductAttention[float32](engine, headDim)
+	sdpa.SetCausal(true)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := sdpa.Forward(ctx, q, k, v, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSDPA_Fused1024(b *testing.B) {
+	benchmarkSDPA(b, compute.NewCPUEngine[float32](numeric.Float32Ops{}), 1024)
+}
+
+func BenchmarkSDPA_Composed1024(b *testing.B) {
+	benchmarkSDPA(b, &composedEngine[float32]{compute.NewCPUEngine[float32](numeric.Float32Ops{})}, 1024)
+}
@@ -0,0 +1,201 @@
+package attention
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func softcapTestTensor(shape []int, seed int, scale float32) *tensor.TensorNumeric[float32] {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	data := make([]float32, n)
+	for i := range data {
+		data[i] = scale * float32(((i*7+seed)%19)-9) / 10.0
+	}
+	t, _ := tensor.New[float32](shape, data)
+	return t
+}
+
+// TestSDPA_LogitSoftcap_MatchesReference checks the capped forward against a
+// float64 reference: softmax(cap * tanh(QK^T / sqrt(d) / cap)) V.
+func TestSDPA_LogitSoftcap_MatchesReference(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine(numeric.Float32Ops{})
+
+	const (
+		batch   = 2
+		seqLen  = 3
+		headDim = 4
+		softcap = 0.5
+	)
+	sdpa := NewScaledDotProductAttention[float32](engine, headDim, WithLogitSoftcap[float32](softcap))
+	if got := sdpa.LogitSoftcap(); got != softcap {
+		t.Fatalf("LogitSoftcap() = %v, want %v", got, softcap)
+	}
+
+	q := softcapTestTensor([]int{batch, seqLen, headDim}, 3, 3)
+	k := softcapTestTensor([]int{batch, seqLen, headDim}, 5, 3)
+	v := softcapTestTensor([]int{batch, seqLen, headDim}, 11, 1)
+
+	out, err := sdpa.Forward(ctx, q, k, v, nil)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+
+	qd, kd, vd := q.Data(), k.Data(), v.Data()
+	scale := 1.0 / math.Sqrt(headDim)
+	for b := range batch {
+		for i := range seqLen {
+			logits := make([]float64, seqLen)
+			maxLogit := math.Inf(-1)
+			for j := range seqLen {
+				var dot float64
+				for d := range headDim {
+					dot += float64(qd[(b*seqLen+i)*headDim+d]) * float64(kd[(b*seqLen+j)*headDim+d])
+				}
+				logits[j] = softcap * math.Tanh(dot*scale/softcap)
+				maxLogit = math.Max(maxLogit, logits[j])
+			}
+			var sum float64
+			for j := range logits {
+				logits[j] = math.Exp(logits[j] - maxLogit)
+				sum += logits[j]
+			}
+			for d := range headDim {
+				var want float64
+				for j := range seqLen {
+					want += logits[j] / sum * float64(vd[(b*seqLen+j)*headDim+d])
+				}
+				got := float64(out.Data()[(b*seqLen+i)*headDim+d])
+				if math.Abs(got-want) > 1e-5 {
+					t.Fatalf("out[%d,%d,%d] = %v, want %v", b, i, d, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestSDPABackward_LogitSoftcap_FiniteDiff verifies the softcap backward rule
+// with and without causal masking.
+func TestSDPABackward_LogitSoftcap_FiniteDiff(t *testing.T) {
+	for _, causal := range []bool{false, true} {
+		name := "bidirectional"
+		if causal {
+			name = "causal"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			engine := compute.NewCPUEngine(numeric.Float32Ops{})
+			const headDim = 4
+
+			sdpa := NewScaledDotProductAttention[float32](engine, headDim)
+			sdpa.SetLogitSoftcap(0.75)
+			sdpa.SetCausal(causal)
+
+			q := softcapTestTensor([]int{2, 3, headDim}, 3, 2)
+			k := softcapTestTensor([]int{2, 3, headDim}, 5, 2)
+			v := softcapTestTensor([]int{2, 3, headDim}, 11, 1)
+
+			out, err := sdpa.Forward(ctx, q, k, v, nil)
+			if err != nil {
+				t.Fatalf("Forward: %v", err)
+			}
+			dOutData := make([]float32, len(out.Data()))
+			for i := range dOutData {
+				dOutData[i] = float32(((i*11+5)%13)-6) / 10.0
+			}
+			dOut, _ := tensor.New[float32](out.Shape(), dOutData)
+
+			lossFn := func() float32 {
+				o, _ := sdpa.Forward(ctx, q, k, v, nil)
+				return dotProduct(o.Data(), dOutData)
+			}
+			for idx, param := range []*tensor.TensorNumeric[float32]{q, k, v} {
+				if _, err := sdpa.Forward(ctx, q, k, v, nil); err != nil {
+					t.Fatalf("Forward: %v", err)
+				}
+				grads, err := sdpa.Backward(ctx, types.FullBackprop, dOut, nil, nil, nil)
+				if err != nil {
+					t.Fatalf("Backward: %v", err)
+				}
+				checkGrad(t, []string{"dQ", "dK", "dV"}[idx], param, grads[idx], lossFn, 1e-3, 5e-2)
+			}
+		})
+	}
+}
+
+// TestGroupedQueryAttention_QKNormSoftcap_Backward checks that the QK-norm
+// gains are exposed as parameters and receive gradients matching finite
+// differences when combined with logit soft-capping.
+func TestGroupedQueryAttention_QKNormSoftcap_Backward(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine(ops)
+
+	const (
+		batch    = 1
+		seqLen   = 3
+		modelDim = 8
+	)
+	gqa, err := NewGroupedQueryAttention[float32](engine, ops, modelDim, 2, 1,
+		WithMaxSeqLen[float32](seqLen),
+		WithQKNorm[float32](1e-6),
+		WithLogitSoftcapGQA[float32](1.0),
+	)
+	if err != nil {
+		t.Fatalf("failed to construct GQA: %v", err)
+	}
+
+	var qGain, kGain *graph.Parameter[float32]
+	for _, p := range gqa.Parameters() {
+		switch p.Name {
+		case "q_norm_gain":
+			qGain = p
+		case "k_norm_gain":
+			kGain = p
+		}
+	}
+	if qGain == nil || kGain == nil {
+		t.Fatal("expected q_norm_gain and k_norm_gain in Parameters()")
+	}
+	for i := range qGain.Value.Data() {
+		qGain.Value.Data()[i] = 0.5 + 0.25*float32(i)
+		kGain.Value.Data()[i] = 1.5 - 0.2*float32(i)
+	}
+
+	input := softcapTestTensor([]int{batch, seqLen, modelDim}, 7, 1)
+	out, err := gqa.Forward(ctx, input)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	dOutData := make([]float32, len(out.Data()))
+	for i := range dOutData {
+		dOutData[i] = float32(((i*5+3)%11)-5) / 10.0
+	}
+	dOut, _ := tensor.New[float32](out.Shape(), dOutData)
+
+	grads, err := gqa.Backward(ctx, types.FullBackprop, dOut, input)
+	if err != nil {
+		t.Fatalf("Backward: %v", err)
+	}
+	if qGain.Gradient == nil || kGain.Gradient == nil {
+		t.Fatal("expected QK-norm gains to receive gradients")
+	}
+
+	lossFn := func() float32 {
+		o, _ := gqa.Forward(ctx, input)
+		return dotProduct(o.Data(), dOutData)
+	}
+	checkGrad(t, "q_norm_gain", qGain.Value, qGain.Gradient, lossFn, 1e-3, 5e-2)
+	checkGrad(t, "k_norm_gain", kGain.Value, kGain.Gradient, lossFn, 1e-3, 5e-2)
+	checkGrad(t, "dInput", input, grads[0], lossFn, 1e-3, 5e-2)
+}
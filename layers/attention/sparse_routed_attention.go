@@ -6,6 +6,7 @@ import (
 	"math"
 	"sort"
 
+	"github.com/zerfoo/zerfoo/internal/kernels"
 	"github.com/zerfoo/zerfoo/layers/embeddings"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
@@ -303,10 +304,7 @@ func (sra *SparseRoutedAttention[T]) Forward(ctx context.Context, inputs ...*ten
 					}
 					for t := segStart; t < segEnd; t++ {
 						kOff := kvBase + t*headDim
-						var dot T
-						for d := range headDim {
-							dot = sra.ops.Add(dot, sra.ops.Mul(qData[qOffset+d], kData[kOff+d]))
-						}
+						dot := kernels.DotOps(sra.ops, qData[qOffset:qOffset+headDim], kData[kOff:kOff+headDim])
 						s := sra.ops.Mul(dot, scaleT)
 						fineScores[ti] = s
 						tokenOffsets[ti] = t
@@ -332,12 +330,7 @@ func (sra *SparseRoutedAttention[T]) Forward(ctx context.Context, inputs ...*ten
 				for i := range actualTokens {
 					w := sra.ops.Div(expScores[i], sumExp)
 					vOff := kvBase + tokenOffsets[i]*headDim
-					for d := range headDim {
-						outData[outOffset+d] = sra.ops.Add(
-							outData[outOffset+d],
-							sra.ops.Mul(w, vData[vOff+d]),
-						)
-					}
+					kernels.AxpyOps(sra.ops, w, vData[vOff:vOff+headDim], outData[outOffset:outOffset+headDim])
 				}
 			}
 		}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/zerfoo/zerfoo/internal/kernels"
 	"github.com/zerfoo/zerfoo/layers/core"
 	"github.com/zerfoo/zerfoo/layers/normalization"
 	"github.com/zerfoo/ztensor/compute"
@@ -357,45 +358,30 @@ func (e *WhisperEncoder[T]) forwardBlock(
 			scores := make([]T, seqLen*seqLen)
 			for qi := 0; qi < seqLen; qi++ {
 				for ki := 0; ki < seqLen; ki++ {
-					var dot T
-					for d := 0; d < headDim; d++ {
-						qIdx := b*seqLen*hiddenDim + qi*hiddenDim + h*headDim + d
-						kIdx := b*seqLen*hiddenDim + ki*hiddenDim + h*headDim + d
-						dot = e.ops.Add(dot, e.ops.Mul(qData[qIdx], kData[kIdx]))
-					}
+					qOff := b*seqLen*hiddenDim + qi*hiddenDim + h*headDim
+					kOff := b*seqLen*hiddenDim + ki*hiddenDim + h*headDim
+					dot := kernels.DotOps(e.ops, qData[qOff:qOff+headDim], kData[kOff:kOff+headDim])
 					scores[qi*seqLen+ki] = e.ops.Mul(dot, scale)
 				}
 			}
 
 			// Softmax per query
 			for qi := 0; qi < seqLen; qi++ {
-				// Find max for numerical stability
-				maxVal := scores[qi*seqLen]
-				for ki := 1; ki < seqLen; ki++ {
-					if e.ops.GreaterThan(scores[qi*seqLen+ki], maxVal) {
-						maxVal = scores[qi*seqLen+ki]
-					}
-				}
-				var sumExp T
-				for ki := 0; ki < seqLen; ki++ {
-					diff := e.ops.Sub(scores[qi*seqLen+ki], maxVal)
-					expVal := e.ops.Exp(diff)
-					scores[qi*seqLen+ki] = expVal
-					sumExp = e.ops.Add(sumExp, expVal)
-				}
-				for ki := 0; ki < seqLen; ki++ {
-					scores[qi*seqLen+ki] = e.ops.Div(scores[qi*seqLen+ki], sumExp)
+				row := scores[qi*seqLen : (qi+1)*seqLen]
+				// Subtract the max for numerical stability
+				maxVal := kernels.MaxOps(e.ops, row)
+				for ki, s := range row {
+					row[ki] = e.ops.Exp(e.ops.Sub(s, maxVal))
 				}
+				sumExp := kernels.SumOps(e.ops, row)
+				kernels.ScaleOps(e.ops, row, row, e.ops.Div(e.ops.FromFloat64(1), sumExp))
 
 				// Weighted sum of V
-				for d := 0; d < headDim; d++ {
-					var val T
-					for ki := 0; ki < seqLen; ki++ {
-						vIdx := b*seqLen*hiddenDim + ki*hiddenDim + h*headDim + d
-						val = e.ops.Add(val, e.ops.Mul(scores[qi*seqLen+ki], vData[vIdx]))
-					}
-					outIdx := b*seqLen*hiddenDim + qi*hiddenDim + h*headDim + d
-					attnOut[outIdx] = val
+				outOff := b*seqLen*hiddenDim + qi*hiddenDim + h*headDim
+				out := attnOut[outOff : outOff+headDim]
+				for ki := 0; ki < seqLen; ki++ {
+					vOff := b*seqLen*hiddenDim + ki*hiddenDim + h*headDim
+					kernels.AxpyOps(e.ops, scores[qi*seqLen+ki], vData[vOff:vOff+headDim], out)
 				}
 			}
 		}
@@ -503,11 +489,7 @@ func (e *WhisperEncoder[T]) Backward(_ context.Context, _ types.BackwardMode, _
 // addBiasInPlace adds a 1D bias vector to a 2D tensor [rows, dim] in-place.
 func addBiasInPlace[T tensor.Numeric](t *tensor.TensorNumeric[T], bias *tensor.TensorNumeric[T], ops numeric.Arithmetic[T]) {
 	data := t.Data()
-	biasData := bias.Data()
-	dim := len(biasData)
-	for i, v := range data {
-		data[i] = ops.Add(v, biasData[i%dim])
-	}
+	kernels.AddBiasOps(ops, data, bias.Data())
 	t.SetData(data)
 }
 
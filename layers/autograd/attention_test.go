@@ -0,0 +1,85 @@
+package autograd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/testing/gradcheck"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/layers/attention"
+)
+
+// attnBlock is a single-head causal self-attention block with a residual:
+// out = sdpa(x·wq, x·wk, x·wv) + x. On the CPU engine SDPA would take the
+// fused kernel, which a tape cannot see.
+type attnBlock struct {
+	engine     compute.Engine[float64]
+	wq, wk, wv *graph.Parameter[float64]
+	sdpa       *attention.ScaledDotProductAttention[float64]
+}
+
+func newAttnBlock(engine compute.Engine[float64], dim int) (*attnBlock, error) {
+	b := &attnBlock{engine: engine, sdpa: attention.NewScaledDotProductAttention(engine, dim)}
+	b.sdpa.SetCausal(true)
+	for i, p := range []**graph.Parameter[float64]{&b.wq, &b.wk, &b.wv} {
+		w, err := tensor.New([]int{dim, dim}, seq(dim*dim, 0.1*float64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		if *p, err = graph.NewParameter("w", w, tensor.New[float64]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (b *attnBlock) OpType() string                     { return "AttnBlock" }
+func (b *attnBlock) Attributes() map[string]interface{} { return nil }
+func (b *attnBlock) OutputShape() []int                 { return nil }
+func (b *attnBlock) Parameters() []*graph.Parameter[float64] {
+	return []*graph.Parameter[float64]{b.wq, b.wk, b.wv}
+}
+
+func (b *attnBlock) Backward(context.Context, types.BackwardMode, *tensor.TensorNumeric[float64], ...*tensor.TensorNumeric[float64]) ([]*tensor.TensorNumeric[float64], error) {
+	panic("manual Backward called")
+}
+
+func (b *attnBlock) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+	x := inputs[0]
+	var qkv [3]*tensor.TensorNumeric[float64]
+	for i, w := range b.Parameters() {
+		var err error
+		if qkv[i], err = b.engine.MatMul(ctx, x, w.Value); err != nil {
+			return nil, err
+		}
+	}
+	a, err := b.sdpa.Forward(ctx, qkv[0], qkv[1], qkv[2], nil)
+	if err != nil {
+		return nil, err
+	}
+	return b.engine.Add(ctx, a, x)
+}
+
+func TestWrap_AttentionGradcheck(t *testing.T) {
+	const batch, seqLen, dim = 2, 4, 3
+	makeNode := func() (graph.Node[float64], error) {
+		tape := NewTape(newCPU())
+		b, err := newAttnBlock(tape.Engine(), dim)
+		if err != nil {
+			return nil, err
+		}
+		return Wrap[float64](tape, b), nil
+	}
+	x := mustTensor(t, []int{batch, seqLen, dim}, seq(batch*seqLen*dim, 0.3))
+	report, err := gradcheck.Check(context.Background(), makeNode, []*tensor.TensorNumeric[float64]{x}, &gradcheck.Config{Seed: 7})
+	if err != nil {
+		t.Fatalf("gradcheck: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("wrapped attention gradcheck failed:\n%s", report)
+	}
+}
@@ -0,0 +1,344 @@
+package autograd
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+)
+
+func newCPU() compute.Engine[float64] {
+	return compute.NewCPUEngine[float64](numeric.Float64Ops{})
+}
+
+func mustTensor(t *testing.T, shape []int, data []float64) *tensor.TensorNumeric[float64] {
+	t.Helper()
+	x, err := tensor.New(shape, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func seq(n int, scale float64) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = scale * float64((i*7)%11-5)
+	}
+	return s
+}
+
+func assertClose(t *testing.T, what string, got, want []float64, tol float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len %d, want %d", what, len(got), len(want))
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > tol {
+			t.Fatalf("%s[%d] = %v, want %v", what, i, got[i], want[i])
+		}
+	}
+}
+
+func TestWrap_MatchesManualBackward(t *testing.T) {
+	ctx := context.Background()
+	tape := NewTape(newCPU())
+	manual, err := core.NewDense[float64]("dense", newCPU(), numeric.Float64Ops{}, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dense, err := core.NewDense[float64]("dense", tape.Engine(), numeric.Float64Ops{}, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auto := Wrap[float64](tape, dense)
+	for i, p := range auto.Parameters() {
+		copy(p.Value.Data(), manual.Parameters()[i].Value.Data())
+	}
+
+	x := mustTensor(t, []int{2, 3}, seq(6, 0.3))
+	dOut := mustTensor(t, []int{2, 4}, seq(8, 0.1))
+	want, err := manual.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := auto.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "output", got.Data(), want.Data(), 1e-12)
+
+	wantIn, err := manual.Backward(ctx, types.FullBackprop, dOut, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotIn, err := auto.Backward(ctx, types.FullBackprop, dOut, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "input gradient", gotIn[0].Data(), wantIn[0].Data(), 1e-12)
+	for i, p := range auto.Parameters() {
+		assertClose(t, p.Name, p.Gradient.Data(), manual.Parameters()[i].Gradient.Data(), 1e-12)
+	}
+}
+
+// block exercises most differentiable ops: for x [2, 3] and w [3, 3],
+//
+//	s := softmax(tanh(x @ w) * x, axis 1)
+//	c := concat(split(exp(s/2), 3, axis 1)[2, 0], axis 1)
+//	d := (c + mean(c, axis 1)) / sum(sqrt(x² + 1), axis 1)
+//	out := dᵀ - dᵀ*dᵀ
+type block struct {
+	engine compute.Engine[float64]
+	w      *graph.Parameter[float64]
+}
+
+func (b *block) OpType() string                     { return "Block" }
+func (b *block) Attributes() map[string]interface{} { return nil }
+func (b *block) OutputShape() []int                 { return []int{2, 2} }
+func (b *block) Parameters() []*graph.Parameter[float64] {
+	return []*graph.Parameter[float64]{b.w}
+}
+
+func (b *block) Backward(context.Context, types.BackwardMode, *tensor.TensorNumeric[float64], ...*tensor.TensorNumeric[float64]) ([]*tensor.TensorNumeric[float64], error) {
+	panic("manual Backward called")
+}
+
+func (b *block) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+	e, x := b.engine, inputs[0]
+	steps := []func(*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error){
+		func(_ *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			return e.MatMul(ctx, x, b.w.Value)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) { return e.Tanh(ctx, h) },
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			return e.Mul(ctx, h, x)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			return e.Softmax(ctx, h, 1)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			return e.MulScalar(ctx, h, 0.5)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) { return e.Exp(ctx, h) },
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			parts, err := e.Split(ctx, h, 3, 1)
+			if err != nil {
+				return nil, err
+			}
+			return e.Concat(ctx, []*tensor.TensorNumeric[float64]{parts[2], parts[0]}, 1)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			m, err := e.ReduceMean(ctx, h, 1, true)
+			if err != nil {
+				return nil, err
+			}
+			return e.Add(ctx, h, m)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			sq, err := e.Mul(ctx, x, x)
+			if err != nil {
+				return nil, err
+			}
+			if sq, err = e.AddScalar(ctx, sq, 1); err != nil {
+				return nil, err
+			}
+			if sq, err = e.Sqrt(ctx, sq); err != nil {
+				return nil, err
+			}
+			col, err := e.ReduceSum(ctx, sq, 1, true)
+			if err != nil {
+				return nil, err
+			}
+			return e.Div(ctx, h, col)
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			return e.Transpose(ctx, h, []int{1, 0})
+		},
+		func(h *tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+			sq, err := e.Mul(ctx, h, h)
+			if err != nil {
+				return nil, err
+			}
+			return e.Sub(ctx, h, sq)
+		},
+	}
+	var h *tensor.TensorNumeric[float64]
+	for _, step := range steps {
+		var err error
+		if h, err = step(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func TestWrap_FiniteDifferences(t *testing.T) {
+	ctx := context.Background()
+	tape := NewTape(newCPU())
+	w, err := graph.NewParameter("w", mustTensor(t, []int{3, 3}, seq(9, 0.2)), tensor.New[float64])
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := Wrap[float64](tape, &block{engine: tape.Engine(), w: w})
+	x := mustTensor(t, []int{2, 3}, seq(6, 0.35))
+	dOut := mustTensor(t, []int{2, 2}, []float64{1, -0.5, 0.25, 2})
+
+	// loss is the output weighted by dOut, so its gradient is what
+	// Backward computes for dOut.
+	loss := func() float64 {
+		out, err := node.Forward(ctx, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s float64
+		for i, v := range out.Data() {
+			s += v * dOut.Data()[i]
+		}
+		return s
+	}
+	numGrad := func(data []float64) []float64 {
+		const h = 1e-6
+		grad := make([]float64, len(data))
+		for i := range data {
+			orig := data[i]
+			data[i] = orig + h
+			up := loss()
+			data[i] = orig - h
+			down := loss()
+			data[i] = orig
+			grad[i] = (up - down) / (2 * h)
+		}
+		return grad
+	}
+	wantX, wantW := numGrad(x.Data()), numGrad(w.Value.Data())
+
+	loss()
+	grads, err := node.Backward(ctx, types.FullBackprop, dOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "input gradient", grads[0].Data(), wantX, 1e-6)
+	assertClose(t, "weight gradient", w.Gradient.Data(), wantW, 1e-6)
+
+	// A second Backward accumulates into the parameter.
+	if _, err := node.Backward(ctx, types.FullBackprop, dOut); err != nil {
+		t.Fatal(err)
+	}
+	for i := range wantW {
+		wantW[i] *= 2
+	}
+	assertClose(t, "accumulated weight gradient", w.Gradient.Data(), wantW, 2e-6)
+}
+
+// opaque runs a UnaryOp, which has no derivative.
+type opaque struct{ block }
+
+func (o *opaque) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+	return o.engine.UnaryOp(ctx, inputs[0], func(v float64) float64 { return v * v })
+}
+
+// hostLayer is a block without parameters.
+type hostLayer struct{ block }
+
+func (h *hostLayer) Parameters() []*graph.Parameter[float64] { return nil }
+
+// hostOp squares its input on the host, outside the engine, and scales the
+// result with an engine op, so the recorded ops read a value no recorded op
+// produced.
+type hostOp struct{ hostLayer }
+
+func (h *hostOp) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+	sq := make([]float64, inputs[0].Size())
+	for i, v := range inputs[0].Data() {
+		sq[i] = v * v
+	}
+	x, err := tensor.New(inputs[0].Shape(), sq)
+	if err != nil {
+		return nil, err
+	}
+	return h.engine.MulScalar(ctx, x, 2)
+}
+
+// hostOutput returns a tensor computed on the host.
+type hostOutput struct{ hostLayer }
+
+func (h *hostOutput) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+	return tensor.New(inputs[0].Shape(), slices.Clone(inputs[0].Data()))
+}
+
+// shifted adds a constant built on the host and declared with Constant.
+type shifted struct{ hostLayer }
+
+func (s *shifted) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[float64]) (*tensor.TensorNumeric[float64], error) {
+	c, err := tensor.New(inputs[0].Shape(), seq(inputs[0].Size(), 1))
+	if err != nil {
+		return nil, err
+	}
+	Constant(s.engine, c)
+	return s.engine.Add(ctx, inputs[0], c)
+}
+
+func TestWrap_UnrecordedValues(t *testing.T) {
+	ctx := context.Background()
+	tape := NewTape(newCPU())
+	x := mustTensor(t, []int{2, 2}, seq(4, 1))
+
+	tests := []struct {
+		name    string
+		layer   graph.Node[float64]
+		wantErr string
+	}{
+		{"host input to an op", &hostOp{hostLayer{block{engine: tape.Engine()}}}, "input 0 was not produced by a recorded op"},
+		{"host output", &hostOutput{hostLayer{block{engine: tape.Engine()}}}, "output was not produced by a recorded op"},
+		{"declared constant", &shifted{hostLayer{block{engine: tape.Engine()}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := Wrap(tape, tt.layer)
+			if _, err := node.Forward(ctx, x); err != nil {
+				t.Fatal(err)
+			}
+			grads, err := node.Backward(ctx, types.FullBackprop, x)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Backward: %v", err)
+				}
+				assertClose(t, "input gradient", grads[0].Data(), x.Data(), 0)
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Backward error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWrap_Errors(t *testing.T) {
+	ctx := context.Background()
+	tape := NewTape(newCPU())
+	x := mustTensor(t, []int{2, 2}, seq(4, 1))
+
+	node := Wrap[float64](tape, &opaque{block{engine: tape.Engine()}})
+	if _, err := node.Backward(ctx, types.FullBackprop, x, x); err == nil || !strings.Contains(err.Error(), "before Forward") {
+		t.Errorf("Backward before Forward: err = %v", err)
+	}
+	if _, err := node.Forward(ctx, x); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.Backward(ctx, types.FullBackprop, x, x); err == nil || !strings.Contains(err.Error(), "no derivative for UnaryOp") {
+		t.Errorf("UnaryOp: err = %v", err)
+	}
+	if _, err := node.Backward(ctx, types.FullBackprop, mustTensor(t, []int{4}, seq(4, 1)), x); err == nil {
+		t.Error("expected an error for a mismatched output gradient")
+	}
+}
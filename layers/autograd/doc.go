@@ -0,0 +1,28 @@
+// Package autograd derives the Backward of composite layers from the engine
+// ops their Forward runs, instead of a hand-written Backward.
+//
+// A Tape wraps an engine in a compute.EngineProxy that records every op
+// while a wrapped layer runs Forward. Backward then walks the recorded ops
+// in reverse and applies each op's derivative, accumulating gradients
+// into the layer's parameters and returning them for its inputs. Build
+// the layer with the tape's engine and wrap it:
+//
+//	tape := autograd.NewTape(engine)
+//	block, err := newMyBlock(tape.Engine(), ...)
+//	node := autograd.Wrap(tape, block) // Backward comes from the tape
+//
+// Wrapping is the per-layer opt-in: layers that are not wrapped keep their
+// own Backward, and wrapped and unwrapped layers mix freely in a graph.
+//
+// Only work done through the engine is recorded. Backward fails rather
+// than return a partial gradient when one has to flow through a value
+// computed outside the recorded ops, such as one computed on the host
+// (through Data), or through an op without a derivative here, such as
+// UnaryOp with an arbitrary function and Dropout. Tensors a layer builds on
+// the host as constants, such as masks and lookup tables, must be declared
+// with Constant during Forward. Ops that write into one of their inputs
+// cannot be differentiated either. While a tape records, the layers in
+// this module skip fused fast paths that would bypass its engine.
+//
+// Stability: alpha
+package autograd
@@ -0,0 +1,454 @@
+package autograd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// gradients maps the tensors of a recorded Forward to the gradient of the
+// layer output with respect to them.
+type gradients[T tensor.Numeric] map[*tensor.TensorNumeric[T]]*tensor.TensorNumeric[T]
+
+// backprop walks ops in reverse from output, seeded with dOut, and returns
+// the gradient of every tensor the output depends on. leaves are the
+// tensors that may reach the ops without being produced by one: the layer
+// inputs, parameter values and declared constants. Any other tensor an op
+// reads, or output itself, was computed outside the recorded ops, so the
+// gradient through it would be lost; backprop fails if a gradient reaches
+// one rather than return a partial result. It runs on the real engine so
+// that nothing it does is recorded.
+func backprop[T tensor.Numeric](ctx context.Context, e compute.Engine[T], ops []entry[T], leaves map[*tensor.TensorNumeric[T]]bool, output, dOut *tensor.TensorNumeric[T]) (gradients[T], error) {
+	if !slices.Equal(dOut.Shape(), output.Shape()) {
+		return nil, fmt.Errorf("output gradient shape %v does not match output shape %v", dOut.Shape(), output.Shape())
+	}
+	written := make(map[*tensor.TensorNumeric[T]]int, len(ops))
+	for i, op := range ops {
+		for _, out := range op.outputs {
+			if slices.Contains(op.inputs, out) {
+				return nil, fmt.Errorf("op %d (%s) writes into its input", i, op.op)
+			}
+			if j, ok := written[out]; ok {
+				return nil, fmt.Errorf("ops %d (%s) and %d (%s) write the same tensor", j, ops[j].op, i, op.op)
+			}
+			written[out] = i
+		}
+	}
+	if _, ok := written[output]; !ok && !leaves[output] {
+		return nil, fmt.Errorf("output was not produced by a recorded op")
+	}
+
+	d := &differ[T]{ctx: ctx, e: e, grads: gradients[T]{output: dOut}}
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		outGrads := make([]*tensor.TensorNumeric[T], len(op.outputs))
+		needed := false
+		for j, out := range op.outputs {
+			outGrads[j] = d.grads[out]
+			needed = needed || outGrads[j] != nil
+		}
+		if !needed {
+			continue
+		}
+		inGrads, err := d.vjp(op, outGrads)
+		if err != nil {
+			return nil, fmt.Errorf("op %d (%s): %w", i, op.op, err)
+		}
+		for j, g := range inGrads {
+			if g == nil {
+				continue
+			}
+			in := op.inputs[j]
+			if _, ok := written[in]; !ok && !leaves[in] {
+				return nil, fmt.Errorf("op %d (%s): input %d was not produced by a recorded op", i, op.op, j)
+			}
+			if err := d.accumulate(in, g); err != nil {
+				return nil, fmt.Errorf("op %d (%s): %w", i, op.op, err)
+			}
+		}
+	}
+	return d.grads, nil
+}
+
+// differ applies the derivatives of recorded ops.
+type differ[T tensor.Numeric] struct {
+	ctx   context.Context
+	e     compute.Engine[T]
+	grads gradients[T]
+}
+
+// accumulate adds g to the gradient of t.
+func (d *differ[T]) accumulate(t, g *tensor.TensorNumeric[T]) error {
+	prev, ok := d.grads[t]
+	if !ok {
+		d.grads[t] = g
+		return nil
+	}
+	sum, err := d.e.Add(d.ctx, prev, g)
+	if err != nil {
+		return err
+	}
+	d.grads[t] = sum
+	return nil
+}
+
+// vjp returns the gradients of op's inputs given those of its outputs,
+// nil for inputs that get none.
+func (d *differ[T]) vjp(op entry[T], outGrads []*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	ctx, e := d.ctx, d.e
+	g := outGrads[0]
+	out := op.outputs[0]
+	var a, b *tensor.TensorNumeric[T]
+	if len(op.inputs) > 0 {
+		a = op.inputs[0]
+	}
+	if len(op.inputs) > 1 {
+		b = op.inputs[1]
+	}
+	one := func(t *tensor.TensorNumeric[T], err error) ([]*tensor.TensorNumeric[T], error) {
+		if err != nil {
+			return nil, err
+		}
+		return []*tensor.TensorNumeric[T]{t}, nil
+	}
+	two := func(ga, gb *tensor.TensorNumeric[T], err error) ([]*tensor.TensorNumeric[T], error) {
+		if err != nil {
+			return nil, err
+		}
+		if ga, err = d.unbroadcast(ga, a.Shape()); err != nil {
+			return nil, err
+		}
+		if gb, err = d.unbroadcast(gb, b.Shape()); err != nil {
+			return nil, err
+		}
+		return []*tensor.TensorNumeric[T]{ga, gb}, nil
+	}
+	ops := e.Ops()
+
+	switch op.op {
+	case "Add":
+		return two(g, g, nil)
+	case "Sub":
+		gb, err := e.MulScalar(ctx, g, ops.FromFloat64(-1))
+		return two(g, gb, err)
+	case "Mul":
+		ga, err := e.Mul(ctx, g, b)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := e.Mul(ctx, g, a)
+		return two(ga, gb, err)
+	case "Div":
+		// d(a/b) = g/b, -g*out/b.
+		ga, err := e.Div(ctx, g, b)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := e.Mul(ctx, ga, out)
+		if err != nil {
+			return nil, err
+		}
+		gb, err = e.MulScalar(ctx, gb, ops.FromFloat64(-1))
+		return two(ga, gb, err)
+	case "Pow":
+		// d(a^b) = g*b*a^(b-1), g*out*log(a).
+		bm1, err := e.AddScalar(ctx, b, ops.FromFloat64(-1))
+		if err != nil {
+			return nil, err
+		}
+		p, err := e.Pow(ctx, a, bm1)
+		if err != nil {
+			return nil, err
+		}
+		ga, err := d.product(g, b, p)
+		if err != nil {
+			return nil, err
+		}
+		la, err := e.Log(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := d.product(g, out, la)
+		return two(ga, gb, err)
+	case "MatMul":
+		bt, err := d.swapLast(b)
+		if err != nil {
+			return nil, err
+		}
+		ga, err := e.MatMul(ctx, g, bt)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := d.matMulTA(a, g, b.Shape())
+		return two(ga, gb, err)
+	case "MatMulTransposeB":
+		// out = a @ bᵀ.
+		ga, err := e.MatMul(ctx, g, b)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := d.matMulTA(g, a, b.Shape())
+		return two(ga, gb, err)
+	case "MatMulTransposeA":
+		// out = aᵀ @ b.
+		gt, err := d.swapLast(g)
+		if err != nil {
+			return nil, err
+		}
+		ga, err := e.MatMul(ctx, b, gt)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := e.MatMul(ctx, a, g)
+		return two(ga, gb, err)
+	case "MulScalar":
+		return one(e.MulScalar(ctx, g, op.extra["scalar"].(T)))
+	case "DivScalar":
+		return one(e.DivScalar(ctx, g, op.extra["scalar"].(T)))
+	case "AddScalar":
+		return one(g, nil)
+	case "Exp":
+		return one(e.Mul(ctx, g, out))
+	case "Log":
+		return one(e.Div(ctx, g, a))
+	case "Sin":
+		c, err := e.Cos(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		return one(e.Mul(ctx, g, c))
+	case "Cos":
+		s, err := e.Sin(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		gs, err := e.Mul(ctx, g, s)
+		if err != nil {
+			return nil, err
+		}
+		return one(e.MulScalar(ctx, gs, ops.FromFloat64(-1)))
+	case "Tanh":
+		// d tanh = g*(1 - out²).
+		sq, err := e.Mul(ctx, out, out)
+		if err != nil {
+			return nil, err
+		}
+		if sq, err = e.MulScalar(ctx, sq, ops.FromFloat64(-1)); err != nil {
+			return nil, err
+		}
+		if sq, err = e.AddScalar(ctx, sq, ops.FromFloat64(1)); err != nil {
+			return nil, err
+		}
+		return one(e.Mul(ctx, g, sq))
+	case "Sqrt":
+		// d sqrt = g/(2*out).
+		q, err := e.Div(ctx, g, out)
+		if err != nil {
+			return nil, err
+		}
+		return one(e.MulScalar(ctx, q, ops.FromFloat64(0.5)))
+	case "Rsqrt":
+		// d a^(-1/2) = -g*out³/2.
+		cube, err := d.product(g, out, out, out)
+		if err != nil {
+			return nil, err
+		}
+		return one(e.MulScalar(ctx, cube, ops.FromFloat64(-0.5)))
+	case "Softmax":
+		// d softmax = out*(g - sum(g*out, axis)).
+		axis := op.extra["axis"].(int)
+		if axis < 0 {
+			axis += len(out.Shape())
+		}
+		gy, err := e.Mul(ctx, g, out)
+		if err != nil {
+			return nil, err
+		}
+		s, err := e.ReduceSum(ctx, gy, axis, true)
+		if err != nil {
+			return nil, err
+		}
+		diff, err := e.Sub(ctx, g, s)
+		if err != nil {
+			return nil, err
+		}
+		return one(e.Mul(ctx, out, diff))
+	case "ReduceSum", "Sum", "ReduceMean":
+		ga, err := d.expand(g, op.extra["axis"].(int), a.Shape())
+		if err != nil || op.op != "ReduceMean" {
+			return one(ga, err)
+		}
+		return one(e.DivScalar(ctx, ga, ops.FromFloat64(float64(a.Size()/out.Size()))))
+	case "Reshape":
+		return one(e.Reshape(ctx, g, a.Shape()))
+	case "Transpose":
+		axes, _ := op.extra["axes"].([]int)
+		if axes == nil {
+			return one(e.Transpose(ctx, g, nil))
+		}
+		inv := make([]int, len(axes))
+		for i, ax := range axes {
+			inv[ax] = i
+		}
+		return one(e.Transpose(ctx, g, inv))
+	case "Concat":
+		return d.concatGrad(g, op.inputs, op.extra["axis"].(int))
+	case "Split":
+		parts := make([]*tensor.TensorNumeric[T], len(outGrads))
+		for i, pg := range outGrads {
+			if pg == nil {
+				var err error
+				if pg, err = tensor.New[T](op.outputs[i].Shape(), nil); err != nil {
+					return nil, err
+				}
+			}
+			parts[i] = pg
+		}
+		return one(e.Concat(ctx, parts, op.extra["axis"].(int)))
+	case "Gather":
+		if len(a.Shape()) != 2 {
+			return nil, fmt.Errorf("gather gradient needs a 2D table, got shape %v", a.Shape())
+		}
+		table, err := tensor.New[T](a.Shape(), nil)
+		if err != nil {
+			return nil, err
+		}
+		g2, err := e.Reshape(ctx, g, []int{-1, a.Shape()[1]})
+		if err != nil {
+			return nil, err
+		}
+		if err := e.ScatterAdd(ctx, table, op.indices, g2); err != nil {
+			return nil, err
+		}
+		return one(table, nil)
+	default:
+		return nil, fmt.Errorf("no derivative for %s", op.op)
+	}
+}
+
+// product multiplies ts elementwise.
+func (d *differ[T]) product(ts ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	acc := ts[0]
+	for _, t := range ts[1:] {
+		var err error
+		if acc, err = d.e.Mul(d.ctx, acc, t); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+// swapLast transposes the last two axes of t.
+func (d *differ[T]) swapLast(t *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	n := len(t.Shape())
+	if n < 2 {
+		return nil, fmt.Errorf("cannot transpose shape %v", t.Shape())
+	}
+	axes := make([]int, n)
+	for i := range axes {
+		axes[i] = i
+	}
+	axes[n-2], axes[n-1] = axes[n-1], axes[n-2]
+	return d.e.Transpose(d.ctx, t, axes)
+}
+
+// matMulTA returns xᵀ @ y summed down to shape. A 2D shape against batched
+// x and y folds the batch into the rows first.
+func (d *differ[T]) matMulTA(x, y *tensor.TensorNumeric[T], shape []int) (*tensor.TensorNumeric[T], error) {
+	var err error
+	if len(shape) == 2 && len(x.Shape()) > 2 {
+		xs, ys := x.Shape(), y.Shape()
+		if x, err = d.e.Reshape(d.ctx, x, []int{-1, xs[len(xs)-1]}); err != nil {
+			return nil, err
+		}
+		if y, err = d.e.Reshape(d.ctx, y, []int{-1, ys[len(ys)-1]}); err != nil {
+			return nil, err
+		}
+	}
+	xt, err := d.swapLast(x)
+	if err != nil {
+		return nil, err
+	}
+	return d.e.MatMul(d.ctx, xt, y)
+}
+
+// unbroadcast sums g over the axes broadcasting expanded to reach shape.
+func (d *differ[T]) unbroadcast(g *tensor.TensorNumeric[T], shape []int) (*tensor.TensorNumeric[T], error) {
+	if slices.Equal(g.Shape(), shape) {
+		return g, nil
+	}
+	var err error
+	for len(g.Shape()) > len(shape) {
+		if g, err = d.e.ReduceSum(d.ctx, g, 0, false); err != nil {
+			return nil, err
+		}
+	}
+	for i, n := range shape {
+		if n == 1 && g.Shape()[i] != 1 {
+			if g, err = d.e.ReduceSum(d.ctx, g, i, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !slices.Equal(g.Shape(), shape) {
+		return nil, fmt.Errorf("cannot reduce gradient shape %v to %v", g.Shape(), shape)
+	}
+	return g, nil
+}
+
+// expand broadcasts the gradient of a reduction over axis back to shape. A
+// negative axis reduced over all axes.
+func (d *differ[T]) expand(g *tensor.TensorNumeric[T], axis int, shape []int) (*tensor.TensorNumeric[T], error) {
+	kept := make([]int, len(shape))
+	for i, n := range shape {
+		kept[i] = n
+		if axis < 0 || i == axis {
+			kept[i] = 1
+		}
+	}
+	g, err := d.e.Reshape(d.ctx, g, kept)
+	if err != nil {
+		return nil, err
+	}
+	zeros, err := tensor.New[T](shape, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.e.Add(d.ctx, zeros, g)
+}
+
+// concatGrad slices g along axis into the gradients of inputs.
+func (d *differ[T]) concatGrad(g *tensor.TensorNumeric[T], inputs []*tensor.TensorNumeric[T], axis int) ([]*tensor.TensorNumeric[T], error) {
+	shape := g.Shape()
+	if axis < 0 {
+		axis += len(shape)
+	}
+	outer, inner := 1, 1
+	for _, n := range shape[:axis] {
+		outer *= n
+	}
+	for _, n := range shape[axis+1:] {
+		inner *= n
+	}
+	src := g.Data()
+	rowLen := shape[axis] * inner
+	grads := make([]*tensor.TensorNumeric[T], len(inputs))
+	start := 0
+	for i, in := range inputs {
+		w := in.Shape()[axis] * inner
+		dst := make([]T, outer*w)
+		for o := range outer {
+			copy(dst[o*w:(o+1)*w], src[o*rowLen+start:o*rowLen+start+w])
+		}
+		t, err := tensor.New(in.Shape(), dst)
+		if err != nil {
+			return nil, err
+		}
+		grads[i] = t
+		start += w
+	}
+	return grads, nil
+}
@@ -0,0 +1,196 @@
+package autograd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/engineproxy"
+)
+
+// entry is one recorded engine op.
+type entry[T tensor.Numeric] struct {
+	op      string
+	inputs  []*tensor.TensorNumeric[T]
+	outputs []*tensor.TensorNumeric[T]
+	indices *tensor.TensorNumeric[int] // Gather only
+	extra   map[string]any
+}
+
+// Tape records the engine ops of wrapped layers. Layers wrapped with the
+// same tape may nest; the outermost one records the ops of all of them.
+// A Tape is not safe for concurrent use.
+type Tape[T tensor.Numeric] struct {
+	proxy     *compute.EngineProxy[T]
+	depth     int
+	entries   []entry[T]
+	constants map[*tensor.TensorNumeric[T]]bool
+}
+
+// NewTape returns a Tape that records the ops run on engine through
+// Engine.
+func NewTape[T tensor.Numeric](engine compute.Engine[T]) *Tape[T] {
+	return &Tape[T]{proxy: compute.NewEngineProxy(engine)}
+}
+
+// Engine returns the engine to build wrapped layers with.
+func (t *Tape[T]) Engine() compute.Engine[T] {
+	return t.proxy
+}
+
+// Record implements compute.TraceRecorder.
+func (t *Tape[T]) Record(op string, inputs []*tensor.TensorNumeric[T], output *tensor.TensorNumeric[T], extra map[string]any) {
+	t.entries = append(t.entries, entry[T]{op: op, inputs: inputs, outputs: []*tensor.TensorNumeric[T]{output}, extra: extra})
+}
+
+// RecordMultiOutput implements compute.TraceRecorder.
+func (t *Tape[T]) RecordMultiOutput(op string, inputs []*tensor.TensorNumeric[T], outputs []*tensor.TensorNumeric[T], extra map[string]any) {
+	t.entries = append(t.entries, entry[T]{op: op, inputs: inputs, outputs: outputs, extra: extra})
+}
+
+// RecordGather implements compute.TraceRecorder.
+func (t *Tape[T]) RecordGather(params *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], output *tensor.TensorNumeric[T], extra map[string]any) {
+	t.entries = append(t.entries, entry[T]{
+		op:      "Gather",
+		inputs:  []*tensor.TensorNumeric[T]{params},
+		outputs: []*tensor.TensorNumeric[T]{output},
+		indices: indices,
+		extra:   extra,
+	})
+}
+
+// RecordConstant implements engineproxy.ConstantRecorder.
+func (t *Tape[T]) RecordConstant(ts ...*tensor.TensorNumeric[T]) {
+	for _, c := range ts {
+		t.constants[c] = true
+	}
+}
+
+// Constant declares ts, tensors a layer builds on the host rather than with
+// engine ops, as constants of the Forward being recorded on engine, so that
+// Backward lets gradients reach them. It does nothing when engine is not
+// recording. A layer built with a tape's engine calls it from Forward for
+// every such tensor an engine op reads.
+func Constant[T tensor.Numeric](engine compute.Engine[T], ts ...*tensor.TensorNumeric[T]) {
+	engineproxy.Constant(engine, ts...)
+}
+
+// begin starts recording, if not already, and returns the position of the
+// next op.
+func (t *Tape[T]) begin() int {
+	if t.depth == 0 {
+		t.entries = t.entries[:0]
+		t.constants = make(map[*tensor.TensorNumeric[T]]bool)
+		engineproxy.StartTracing(t.proxy, t)
+	}
+	t.depth++
+	return len(t.entries)
+}
+
+// end returns the ops recorded since mark and stops recording when the
+// outermost layer is done.
+func (t *Tape[T]) end(mark int) []entry[T] {
+	ops := append([]entry[T](nil), t.entries[mark:]...)
+	t.depth--
+	if t.depth == 0 {
+		engineproxy.StopTracing(t.proxy)
+	}
+	return ops
+}
+
+// Node is a layer whose Backward is derived from the ops its Forward
+// records on a tape. The layer's OpType, Attributes, OutputShape and
+// Parameters are its own.
+type Node[T tensor.Numeric] struct {
+	graph.Node[T]
+	tape *Tape[T]
+
+	// Recorded by the latest Forward.
+	ops       []entry[T]
+	constants map[*tensor.TensorNumeric[T]]bool
+	inputs    []*tensor.TensorNumeric[T]
+	output    *tensor.TensorNumeric[T]
+}
+
+// Wrap returns layer with its Backward derived from tape. layer must have
+// been built with tape.Engine().
+func Wrap[T tensor.Numeric](tape *Tape[T], layer graph.Node[T]) *Node[T] {
+	return &Node[T]{Node: layer, tape: tape}
+}
+
+// Forward runs the layer's Forward and records its ops.
+func (n *Node[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	mark := n.tape.begin()
+	out, err := n.Node.Forward(ctx, inputs...)
+	n.ops, n.constants = n.tape.end(mark), n.tape.constants
+	if err != nil {
+		return nil, err
+	}
+	n.inputs, n.output = inputs, out
+	return out, nil
+}
+
+// Backward differentiates the ops recorded by the latest Forward. It adds
+// the parameter gradients to the layer's parameters and returns the input
+// gradients, zero for inputs that no recorded op used.
+func (n *Node[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if n.output == nil {
+		return nil, fmt.Errorf("autograd: %s: Backward called before Forward", n.OpType())
+	}
+	if len(inputs) == 0 {
+		inputs = n.inputs
+	}
+	leaves := make(map[*tensor.TensorNumeric[T]]bool, len(n.inputs)+len(n.constants))
+	for _, in := range n.inputs {
+		leaves[in] = true
+	}
+	for _, p := range n.Parameters() {
+		if p != nil {
+			leaves[p.Value] = true
+		}
+	}
+	for c := range n.constants {
+		leaves[c] = true
+	}
+	grads, err := backprop(ctx, n.tape.proxy.Real(), n.ops, leaves, n.output, dOut)
+	if err != nil {
+		return nil, fmt.Errorf("autograd: %s: %w", n.OpType(), err)
+	}
+	for _, p := range n.Parameters() {
+		g, ok := grads[p.Value]
+		if !ok {
+			continue
+		}
+		if p.Gradient == nil {
+			p.Gradient = g
+			continue
+		}
+		if err := p.AddGradient(g); err != nil {
+			return nil, fmt.Errorf("autograd: %s: parameter %q: %w", n.OpType(), p.Name, err)
+		}
+	}
+	out := make([]*tensor.TensorNumeric[T], len(inputs))
+	for i, in := range inputs {
+		if g, ok := grads[in]; ok {
+			out[i] = g
+			continue
+		}
+		if out[i], err = tensor.New[T](in.Shape(), nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Statically assert that the type implements the graph.Node interface.
+var _ graph.Node[float32] = (*Node[float32])(nil)
+
+// Statically assert that the type implements the TraceRecorder interface.
+var _ compute.TraceRecorder[float32] = (*Tape[float32])(nil)
+
+// Statically assert that the type implements the ConstantRecorder interface.
+var _ engineproxy.ConstantRecorder[float32] = (*Tape[float32])(nil)
@@ -0,0 +1,152 @@
+package components
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/internal/determinism"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GradientAccumulator makes parameter gradient accumulation safe when
+// several backward branches run concurrently, or when one parameter is
+// shared by nodes on different branches. Each parameter has its own lock, so
+// branches writing different parameters never wait on each other.
+//
+// By default a contribution is added to Parameter.Gradient as it arrives,
+// which is race-free but sums contributions in scheduling order, so float
+// results can differ in the last bits between runs. With
+// WithDeterministicOrder, contributions are buffered and Flush adds them in
+// branch order, making the result independent of scheduling.
+type GradientAccumulator[T tensor.Numeric] struct {
+	deterministic bool
+
+	mu     sync.Mutex // guards params
+	params map[*graph.Parameter[T]]*paramGradients[T]
+}
+
+// paramGradients is the per-parameter lock and, in deterministic mode, the
+// contributions waiting for Flush in arrival order.
+type paramGradients[T tensor.Numeric] struct {
+	mu      sync.Mutex
+	pending []branchGradient[T]
+}
+
+type branchGradient[T tensor.Numeric] struct {
+	branch int
+	grad   *tensor.TensorNumeric[T]
+}
+
+// GradientAccumulatorOptions holds configuration options for GradientAccumulator.
+type GradientAccumulatorOptions struct {
+	Deterministic bool
+}
+
+// GradientAccumulatorOption applies an option to GradientAccumulatorOptions.
+type GradientAccumulatorOption func(*GradientAccumulatorOptions)
+
+// WithDeterministicOrder buffers gradient contributions until Flush and
+// reduces each parameter's contributions in ascending branch order.
+// Contributions from one branch keep the order that branch produced them
+// in, since a branch runs its backward sequentially.
+func WithDeterministicOrder() GradientAccumulatorOption {
+	return func(o *GradientAccumulatorOptions) {
+		o.Deterministic = true
+	}
+}
+
+// NewGradientAccumulator creates a new GradientAccumulator.
+func NewGradientAccumulator[T tensor.Numeric](opts ...GradientAccumulatorOption) *GradientAccumulator[T] {
+	options := &GradientAccumulatorOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &GradientAccumulator[T]{
+		deterministic: options.Deterministic,
+		params:        make(map[*graph.Parameter[T]]*paramGradients[T]),
+	}
+}
+
+func (a *GradientAccumulator[T]) entry(p *graph.Parameter[T]) *paramGradients[T] {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	e, ok := a.params[p]
+	if !ok {
+		e = &paramGradients[T]{}
+		a.params[p] = e
+	}
+	return e
+}
+
+// Add accumulates grad, produced by the given branch, into p's gradient.
+// In deterministic mode grad is copied, so the caller may reuse it.
+func (a *GradientAccumulator[T]) Add(branch int, p *graph.Parameter[T], grad *tensor.TensorNumeric[T]) error {
+	e := a.entry(p)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !a.deterministic {
+		determinism.Note(determinism.UnorderedReduction)
+		return p.AddGradient(grad)
+	}
+	if !tensor.ShapesEqual(p.Value.Shape(), grad.Shape()) {
+		return fmt.Errorf("gradient shape %v does not match parameter %q shape %v", grad.Shape(), p.Name, p.Value.Shape())
+	}
+	held, err := tensor.New(grad.Shape(), slices.Clone(grad.Data()))
+	if err != nil {
+		return err
+	}
+	e.pending = append(e.pending, branchGradient[T]{branch: branch, grad: held})
+	return nil
+}
+
+// Flush adds every buffered contribution to its parameter's gradient in
+// branch order. It must not run concurrently with Add, so call it once
+// every branch has finished its backward pass. It is a no-op unless the
+// accumulator was created with WithDeterministicOrder.
+func (a *GradientAccumulator[T]) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for p, e := range a.params {
+		slices.SortStableFunc(e.pending, func(x, y branchGradient[T]) int { return cmp.Compare(x.branch, y.branch) })
+		for _, c := range e.pending {
+			if err := p.AddGradient(c.grad); err != nil {
+				return fmt.Errorf("flushing gradient of %q: %w", p.Name, err)
+			}
+		}
+		e.pending = nil
+	}
+	return nil
+}
+
+type gradientAccumulatorKey struct{}
+
+type branchKey struct{}
+
+// WithGradientAccumulator returns a new context that routes AddGradient
+// calls through acc.
+func WithGradientAccumulator[T tensor.Numeric](ctx context.Context, acc *GradientAccumulator[T]) context.Context {
+	return context.WithValue(ctx, gradientAccumulatorKey{}, acc)
+}
+
+// WithBranch returns a new context that tags gradients added under it with
+// the given branch index. A parallel scheduler gives each branch's backward
+// a context carrying its index; the deterministic reduction order follows it.
+func WithBranch(ctx context.Context, branch int) context.Context {
+	return context.WithValue(ctx, branchKey{}, branch)
+}
+
+// AddGradient adds grad to p's gradient. Layers call it from Backward in
+// place of Parameter.AddGradient: when ctx carries a GradientAccumulator the
+// write goes through it, otherwise it is Parameter.AddGradient unchanged.
+func AddGradient[T tensor.Numeric](ctx context.Context, p *graph.Parameter[T], grad *tensor.TensorNumeric[T]) error {
+	acc, ok := ctx.Value(gradientAccumulatorKey{}).(*GradientAccumulator[T])
+	if !ok || acc == nil {
+		return p.AddGradient(grad)
+	}
+	branch, _ := ctx.Value(branchKey{}).(int)
+	return acc.Add(branch, p, grad)
+}
@@ -0,0 +1,126 @@
+package components
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func newTestParameter(t *testing.T, size int) *graph.Parameter[float32] {
+	t.Helper()
+	value, err := tensor.New[float32]([]int{size}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := graph.NewParameter("p", value, tensor.New[float32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func filled(t *testing.T, size int, v float32) *tensor.TensorNumeric[float32] {
+	t.Helper()
+	data := make([]float32, size)
+	for i := range data {
+		data[i] = v
+	}
+	x, err := tensor.New([]int{size}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestGradientAccumulator_Concurrent(t *testing.T) {
+	const branches, adds, size = 8, 100, 64
+	shared := newTestParameter(t, size)
+	own := make([]*graph.Parameter[float32], branches)
+	for i := range own {
+		own[i] = newTestParameter(t, size)
+	}
+
+	acc := NewGradientAccumulator[float32]()
+	ctx := WithGradientAccumulator(context.Background(), acc)
+	var wg sync.WaitGroup
+	for b := range branches {
+		wg.Go(func() {
+			bctx := WithBranch(ctx, b)
+			for range adds {
+				if err := AddGradient(bctx, shared, filled(t, size, 1)); err != nil {
+					t.Error(err)
+				}
+				if err := AddGradient(bctx, own[b], filled(t, size, 1)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if err := acc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, v := range shared.Gradient.Data() {
+		if v != branches*adds {
+			t.Fatalf("shared gradient[%d] = %v, want %d", i, v, branches*adds)
+		}
+	}
+	for b, p := range own {
+		if v := p.Gradient.Data()[0]; v != adds {
+			t.Errorf("branch %d gradient = %v, want %d", b, v, adds)
+		}
+	}
+}
+
+// TestGradientAccumulator_Deterministic adds contributions whose float32
+// sum depends on order, in two different arrival orders, and checks Flush
+// sums them in branch order both times.
+func TestGradientAccumulator_Deterministic(t *testing.T) {
+	contributions := map[int]float32{0: 1e8, 1: 1, 2: -1e8}
+	want := float32(1e8)
+	want += 1
+	want += -1e8
+
+	for _, arrival := range [][]int{{0, 1, 2}, {2, 1, 0}, {1, 2, 0}} {
+		p := newTestParameter(t, 1)
+		acc := NewGradientAccumulator[float32](WithDeterministicOrder())
+		ctx := WithGradientAccumulator(context.Background(), acc)
+		for _, b := range arrival {
+			grad := filled(t, 1, contributions[b])
+			if err := AddGradient(WithBranch(ctx, b), p, grad); err != nil {
+				t.Fatal(err)
+			}
+			// The accumulator holds a copy, so reusing grad is safe.
+			grad.Data()[0] = 42
+		}
+		if got := p.Gradient.Data()[0]; got != 0 {
+			t.Fatalf("arrival %v: gradient before Flush = %v, want 0", arrival, got)
+		}
+		if err := acc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got := p.Gradient.Data()[0]; got != want {
+			t.Errorf("arrival %v: gradient = %v, want %v", arrival, got, want)
+		}
+	}
+}
+
+func TestAddGradient_WithoutAccumulator(t *testing.T) {
+	p := newTestParameter(t, 2)
+	if err := AddGradient(context.Background(), p, filled(t, 2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Gradient.Data(); got[0] != 3 || got[1] != 3 {
+		t.Errorf("gradient = %v, want [3 3]", got)
+	}
+
+	acc := NewGradientAccumulator[float32](WithDeterministicOrder())
+	ctx := WithGradientAccumulator(context.Background(), acc)
+	if err := AddGradient(ctx, p, filled(t, 3, 1)); err == nil {
+		t.Error("expected error for a gradient of the wrong shape")
+	}
+}
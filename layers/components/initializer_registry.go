@@ -0,0 +1,105 @@
+package components
+
+import (
+	"fmt"
+	rand "math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/internal/determinism"
+
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// InitScheme draws the row-major weights of an [inputSize, outputSize]
+// matrix from rng. Schemes are registered by name with RegisterInitializer
+// and instantiated for an element type with NewInitializer.
+type InitScheme func(rng *rand.Rand, inputSize, outputSize int) ([]float64, error)
+
+var (
+	initMu      sync.RWMutex
+	initSchemes = make(map[string]InitScheme)
+)
+
+func init() {
+	for name, scheme := range map[string]InitScheme{
+		"xavier": xavierWeights,
+		"glorot": xavierWeights,
+		"he":     heWeights,
+		"orthogonal": func(rng *rand.Rand, inputSize, outputSize int) ([]float64, error) {
+			return orthogonalWeights(rng, inputSize, outputSize, 1)
+		},
+		"truncated_normal": func(rng *rand.Rand, inputSize, outputSize int) ([]float64, error) {
+			return truncatedNormalWeights(rng, inputSize, outputSize, 0.02)
+		},
+	} {
+		_ = RegisterInitializer(name, scheme)
+	}
+}
+
+// RegisterInitializer adds scheme to the initializer registry under name.
+// It fails if name is empty or taken, or scheme is nil.
+func RegisterInitializer(name string, scheme InitScheme) error {
+	if name == "" || scheme == nil {
+		return fmt.Errorf("initializer needs a name and a scheme")
+	}
+	initMu.Lock()
+	defer initMu.Unlock()
+	if _, ok := initSchemes[name]; ok {
+		return fmt.Errorf("initializer %q already registered", name)
+	}
+	initSchemes[name] = scheme
+	return nil
+}
+
+// InitializerNames returns the names of all registered initializers, sorted.
+func InitializerNames() []string {
+	initMu.RLock()
+	defer initMu.RUnlock()
+	names := make([]string, 0, len(initSchemes))
+	for name := range initSchemes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewInitializer returns the initializer registered under name, such as
+// "xavier" (or "glorot"), "he", "orthogonal" (gain 1) or
+// "truncated_normal" (standard deviation 0.02), drawing from rng. A nil rng
+// uses the global generator; pass RandSource(engine) to follow the engine's
+// seed.
+func NewInitializer[T tensor.Numeric](name string, ops numeric.Arithmetic[T], rng *rand.Rand) (WeightInitializer[T], error) {
+	initMu.RLock()
+	scheme, ok := initSchemes[name]
+	initMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown initializer %q (have %v)", name, InitializerNames())
+	}
+	return &schemeInitializer[T]{ops: ops, scheme: scheme, rng: rng}, nil
+}
+
+// schemeInitializer is a registered InitScheme bound to an element type.
+type schemeInitializer[T tensor.Numeric] struct {
+	ops    numeric.Arithmetic[T]
+	scheme InitScheme
+	rng    *rand.Rand
+}
+
+func (s *schemeInitializer[T]) Initialize(inputSize, outputSize int) ([]T, error) {
+	if s.rng == nil {
+		determinism.Note(determinism.UnseededRand)
+	}
+	vals, err := s.scheme(globalOr(s.rng), inputSize, outputSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != inputSize*outputSize {
+		return nil, fmt.Errorf("initializer returned %d weights, want %d", len(vals), inputSize*outputSize)
+	}
+	return convertWeights(s.ops, vals), nil
+}
+
+// Statically assert that the type implements the WeightInitializer interface.
+var _ WeightInitializer[float32] = (*schemeInitializer[float32])(nil)
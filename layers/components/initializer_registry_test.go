@@ -0,0 +1,114 @@
+package components
+
+import (
+	"math"
+	rand "math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+func TestNewInitializer(t *testing.T) {
+	ops := numeric.Float64Ops{}
+	want := []string{"glorot", "he", "orthogonal", "truncated_normal", "xavier"}
+	if got := InitializerNames(); !slices.Equal(got, want) {
+		t.Fatalf("InitializerNames() = %v, want %v", got, want)
+	}
+
+	// The registered schemes draw like the initializer types.
+	xavier, err := NewInitializer[float64]("glorot", ops, rand.New(rand.NewPCG(3, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := xavier.Initialize(5, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	direct, err := NewXavierInitializer(ops, WithXavierRand[float64](rand.New(rand.NewPCG(3, 0)))).Initialize(5, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, direct) {
+		t.Errorf("registered glorot = %v, want %v", got, direct)
+	}
+
+	if _, err := NewInitializer[float64]("lecun", ops, nil); err == nil || !strings.Contains(err.Error(), "unknown initializer") {
+		t.Errorf("unknown name: err = %v", err)
+	}
+	if err := RegisterInitializer("he", heWeights); err == nil {
+		t.Error("duplicate RegisterInitializer succeeded")
+	}
+	if err := RegisterInitializer("", heWeights); err == nil {
+		t.Error("RegisterInitializer without a name succeeded")
+	}
+	short := func(*rand.Rand, int, int) ([]float64, error) { return []float64{1}, nil }
+	if err := RegisterInitializer("test_short", short); err != nil {
+		t.Fatal(err)
+	}
+	init, err := NewInitializer[float64]("test_short", ops, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := init.Initialize(2, 2); err == nil {
+		t.Error("expected an error for a scheme returning too few weights")
+	}
+}
+
+func TestOrthogonalInitializer(t *testing.T) {
+	for _, shape := range [][2]int{{6, 4}, {4, 6}, {5, 5}} {
+		in, out := shape[0], shape[1]
+		w, err := NewOrthogonalInitializer(numeric.Float64Ops{},
+			WithGain[float64](2), WithOrthogonalRand[float64](rand.New(rand.NewPCG(1, 2)))).Initialize(in, out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The shorter side is orthogonal with norm gain: for in >= out,
+		// WᵀW = 4I, otherwise WWᵀ = 4I.
+		k, n := min(in, out), max(in, out)
+		at := func(a, i int) float64 {
+			if in >= out {
+				return w[i*out+a]
+			}
+			return w[a*out+i]
+		}
+		for a := range k {
+			for b := range k {
+				var dot float64
+				for i := range n {
+					dot += at(a, i) * at(b, i)
+				}
+				want := 0.0
+				if a == b {
+					want = 4
+				}
+				if math.Abs(dot-want) > 1e-9 {
+					t.Fatalf("%dx%d: row/column product (%d, %d) = %v, want %v", in, out, a, b, dot, want)
+				}
+			}
+		}
+	}
+}
+
+func TestTruncatedNormalInitializer(t *testing.T) {
+	w, err := NewTruncatedNormalInitializer(numeric.Float64Ops{},
+		WithStddev[float64](0.5), WithTruncatedNormalRand[float64](rand.New(rand.NewPCG(4, 0)))).Initialize(50, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sum, sq float64
+	for _, v := range w {
+		if math.Abs(v) > 1 {
+			t.Fatalf("weight %v beyond two standard deviations", v)
+		}
+		sum += v
+		sq += v * v
+	}
+	mean := sum / float64(len(w))
+	// A normal truncated at two deviations keeps about 88% of the variance.
+	std := math.Sqrt(sq/float64(len(w)) - mean*mean)
+	if math.Abs(mean) > 0.05 || std < 0.4 || std > 0.5 {
+		t.Errorf("mean %v, standard deviation %v, want about 0 and 0.44", mean, std)
+	}
+}
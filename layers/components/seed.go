@@ -0,0 +1,83 @@
+package components
+
+import (
+	"context"
+	"errors"
+	rand "math/rand/v2"
+	"sync"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// seeds maps an engine to the generator its layers draw their initial
+// weights from while a seeded construction is in progress.
+var seeds sync.Map // compute.Engine -> *rand.Rand
+
+// Seed makes layers built with engine draw their initial weights from a PCG
+// generator seeded by seed, instead of the global math/rand/v2 one, until
+// the returned release function is called: the same seed gives the same
+// weights. Seeding only affects initialization; layers keep engine itself,
+// so its fast paths are unaffected. Seeding is per engine, so other layers
+// built with engine at the same time share the generator. The generator is
+// safe for concurrent use.
+func Seed[T tensor.Numeric](engine compute.Engine[T], seed uint64) (release func()) {
+	return SetRandSource(engine, rand.NewPCG(seed, 0))
+}
+
+// SetRandSource is like Seed, drawing from src.
+func SetRandSource[T tensor.Numeric](engine compute.Engine[T], src rand.Source) (release func()) {
+	rng := rand.New(&lockedSource{src: src})
+	seeds.Store(engine, rng)
+	return func() { seeds.CompareAndDelete(engine, rng) }
+}
+
+// RandSource returns the generator engine is seeded with, if a Seed of it
+// is in effect, and nil otherwise. Layers draw their initial weights from
+// it, falling back to the global generator when it is nil.
+func RandSource[T tensor.Numeric](engine compute.Engine[T]) *rand.Rand {
+	if engine == nil {
+		return nil
+	}
+	if rng, ok := seeds.Load(engine); ok {
+		return rng.(*rand.Rand)
+	}
+	return nil
+}
+
+// RandomUniform fills t with values drawn uniformly from [minVal, maxVal).
+// If engine is seeded they are drawn by its generator, in element order;
+// otherwise engine.RandomUniform fills t.
+func RandomUniform[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], t *tensor.TensorNumeric[T], minVal, maxVal T) error {
+	rng := RandSource(engine)
+	if rng == nil {
+		return engine.RandomUniform(ctx, t, minVal, maxVal)
+	}
+	if t == nil {
+		return errors.New("input tensor cannot be nil")
+	}
+	ops := engine.Ops()
+	if ops.GreaterThan(minVal, maxVal) {
+		minVal, maxVal = maxVal, minVal
+	}
+	span := ops.Sub(maxVal, minVal)
+	data := make([]T, t.Size())
+	for i := range data {
+		data[i] = ops.Add(minVal, ops.Mul(span, ops.FromFloat64(rng.Float64())))
+	}
+	t.SetData(data)
+	return nil
+}
+
+// lockedSource serializes access to a rand.Source, which is not safe for
+// concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
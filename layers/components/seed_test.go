@@ -0,0 +1,113 @@
+package components
+
+import (
+	"context"
+	rand "math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func uniformData(t *testing.T, engine compute.Engine[float32]) []float32 {
+	t.Helper()
+	x, err := tensor.New[float32]([]int{4, 8}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RandomUniform(context.Background(), engine, x, -1, 1); err != nil {
+		t.Fatal(err)
+	}
+	return x.Data()
+}
+
+func seededData(t *testing.T, engine compute.Engine[float32], seed uint64) []float32 {
+	t.Helper()
+	release := Seed(engine, seed)
+	defer release()
+	return uniformData(t, engine)
+}
+
+func TestSeed_RandomUniform(t *testing.T) {
+	cpu := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	a := seededData(t, cpu, 42)
+	if !slices.Equal(a, seededData(t, cpu, 42)) {
+		t.Error("the same seed gave different values")
+	}
+	if slices.Equal(a, seededData(t, cpu, 43)) {
+		t.Error("seeds 42 and 43 gave the same values")
+	}
+	for i, v := range a {
+		if v < -1 || v >= 1 {
+			t.Fatalf("value %d = %v, want in [-1, 1)", i, v)
+		}
+	}
+
+	release := SetRandSource[float32](cpu, rand.NewPCG(42, 0))
+	if !slices.Equal(a, uniformData(t, cpu)) {
+		t.Error("SetRandSource(PCG(42, 0)) differs from seed 42")
+	}
+	if err := RandomUniform(context.Background(), cpu, nil, 0, 1); err == nil {
+		t.Error("expected an error for a nil tensor")
+	}
+	release()
+	if RandSource[float32](cpu) != nil {
+		t.Error("the engine is still seeded after release")
+	}
+}
+
+func TestRandSource(t *testing.T) {
+	cpu := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	if RandSource[float32](cpu) != nil {
+		t.Error("RandSource of an unseeded engine is not nil")
+	}
+	if RandSource[float32](nil) != nil {
+		t.Error("RandSource of a nil engine is not nil")
+	}
+	release := Seed[float32](cpu, 7)
+	defer release()
+	if RandSource[float32](cpu) == nil {
+		t.Error("RandSource of a seeded engine is nil")
+	}
+	other := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	if RandSource[float32](other) != nil {
+		t.Error("seeding one engine seeded another")
+	}
+}
+
+func TestInitializers_Rand(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	for _, tc := range []struct {
+		name string
+		init func(seed uint64) WeightInitializer[float32]
+	}{
+		{"xavier", func(seed uint64) WeightInitializer[float32] {
+			return NewXavierInitializer(ops, WithXavierRand[float32](rand.New(rand.NewPCG(seed, 0))))
+		}},
+		{"he", func(seed uint64) WeightInitializer[float32] {
+			return NewHeInitializer(ops, WithHeRand[float32](rand.New(rand.NewPCG(seed, 0))))
+		}},
+		{"uniform", func(seed uint64) WeightInitializer[float32] {
+			return NewUniformInitializer(ops, WithScale[float32](0.1), WithUniformRand[float32](rand.New(rand.NewPCG(seed, 0))))
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			draw := func(seed uint64) []float32 {
+				w, err := tc.init(seed).Initialize(6, 4)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return w
+			}
+			a := draw(42)
+			if !slices.Equal(a, draw(42)) {
+				t.Error("the same seed gave different weights")
+			}
+			if slices.Equal(a, draw(43)) {
+				t.Error("seeds 42 and 43 gave the same weights")
+			}
+		})
+	}
+}
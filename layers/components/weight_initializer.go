@@ -1,9 +1,12 @@
 package components
 
 import (
+	"errors"
 	"math"
 	rand "math/rand/v2"
 
+	"github.com/zerfoo/zerfoo/internal/determinism"
+
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 )
@@ -17,16 +20,26 @@ type WeightInitializer[T tensor.Numeric] interface {
 // Weights are sampled from a uniform distribution with variance 2/(fan_in + fan_out).
 type XavierInitializer[T tensor.Numeric] struct {
 	ops numeric.Arithmetic[T]
+	rng *rand.Rand
 }
 
 // XavierInitializerOptions holds configuration options for XavierInitializer.
 type XavierInitializerOptions[T tensor.Numeric] struct {
-	// No specific options for now, but kept for consistency.
+	// Rand is the generator to draw from; nil uses the global one.
+	Rand *rand.Rand
 }
 
 // XavierInitializerOption is a function that applies an option to XavierInitializerOptions.
 type XavierInitializerOption[T tensor.Numeric] func(*XavierInitializerOptions[T])
 
+// WithXavierRand makes the initializer draw from rng, such as the generator
+// of a seeded engine (see Seed), for reproducible weights.
+func WithXavierRand[T tensor.Numeric](rng *rand.Rand) XavierInitializerOption[T] {
+	return func(o *XavierInitializerOptions[T]) {
+		o.Rand = rng
+	}
+}
+
 // NewXavierInitializer creates a new Xavier initializer.
 func NewXavierInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...XavierInitializerOption[T]) *XavierInitializer[T] {
 	options := &XavierInitializerOptions[T]{}
@@ -34,40 +47,56 @@ func NewXavierInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...X
 		opt(options)
 	}
 
-	return &XavierInitializer[T]{ops: ops}
+	return &XavierInitializer[T]{ops: ops, rng: options.Rand}
 }
 
 // Initialize generates weights using Xavier initialization.
 func (x *XavierInitializer[T]) Initialize(inputSize, outputSize int) ([]T, error) {
-	fanIn := float64(inputSize)
-	fanOut := float64(outputSize)
-	limit := math.Sqrt(6.0 / (fanIn + fanOut))
-
-	weights := make([]T, inputSize*outputSize)
-	for i := range weights {
-		// Generate random value in [-limit, limit]
-		// #nosec G404 - math/rand is acceptable for ML weight initialization
-		val := (rand.Float64()*2 - 1) * limit
-		weights[i] = x.ops.FromFloat32(float32(val))
+	if x.rng == nil {
+		determinism.Note(determinism.UnseededRand)
+	}
+	vals, err := xavierWeights(globalOr(x.rng), inputSize, outputSize)
+	if err != nil {
+		return nil, err
 	}
+	return convertWeights(x.ops, vals), nil
+}
 
-	return weights, nil
+// xavierWeights draws from a uniform distribution in [-limit, limit] with
+// limit sqrt(6/(fan_in + fan_out)).
+func xavierWeights(rng *rand.Rand, inputSize, outputSize int) ([]float64, error) {
+	limit := math.Sqrt(6.0 / float64(inputSize+outputSize))
+	vals := make([]float64, inputSize*outputSize)
+	for i := range vals {
+		vals[i] = (rng.Float64()*2 - 1) * limit
+	}
+	return vals, nil
 }
 
 // HeInitializer implements He initialization.
 // Weights are sampled from a normal distribution with variance 2/fan_in.
 type HeInitializer[T tensor.Numeric] struct {
 	ops numeric.Arithmetic[T]
+	rng *rand.Rand
 }
 
 // HeInitializerOptions holds configuration options for HeInitializer.
 type HeInitializerOptions[T tensor.Numeric] struct {
-	// No specific options for now, but kept for consistency.
+	// Rand is the generator to draw from; nil uses the global one.
+	Rand *rand.Rand
 }
 
 // HeInitializerOption is a function that applies an option to HeInitializerOptions.
 type HeInitializerOption[T tensor.Numeric] func(*HeInitializerOptions[T])
 
+// WithHeRand makes the initializer draw from rng, such as the generator of
+// a seeded engine (see Seed), for reproducible weights.
+func WithHeRand[T tensor.Numeric](rng *rand.Rand) HeInitializerOption[T] {
+	return func(o *HeInitializerOptions[T]) {
+		o.Rand = rng
+	}
+}
+
 // NewHeInitializer creates a new He initializer.
 func NewHeInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...HeInitializerOption[T]) *HeInitializer[T] {
 	options := &HeInitializerOptions[T]{}
@@ -75,34 +104,44 @@ func NewHeInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...HeIni
 		opt(options)
 	}
 
-	return &HeInitializer[T]{ops: ops}
+	return &HeInitializer[T]{ops: ops, rng: options.Rand}
 }
 
 // Initialize generates weights using He initialization.
 func (h *HeInitializer[T]) Initialize(inputSize, outputSize int) ([]T, error) {
-	fanIn := float64(inputSize)
-	stddev := math.Sqrt(2.0 / fanIn)
-
-	weights := make([]T, inputSize*outputSize)
-	for i := range weights {
-		// Generate random value from normal distribution
-		// #nosec G404 - math/rand is acceptable for ML weight initialization
-		val := rand.NormFloat64() * stddev
-		weights[i] = h.ops.FromFloat32(float32(val))
+	if h.rng == nil {
+		determinism.Note(determinism.UnseededRand)
+	}
+	vals, err := heWeights(globalOr(h.rng), inputSize, outputSize)
+	if err != nil {
+		return nil, err
 	}
+	return convertWeights(h.ops, vals), nil
+}
 
-	return weights, nil
+// heWeights draws from a normal distribution with standard deviation
+// sqrt(2/fan_in).
+func heWeights(rng *rand.Rand, inputSize, outputSize int) ([]float64, error) {
+	stddev := math.Sqrt(2.0 / float64(inputSize))
+	vals := make([]float64, inputSize*outputSize)
+	for i := range vals {
+		vals[i] = rng.NormFloat64() * stddev
+	}
+	return vals, nil
 }
 
 // UniformInitializer implements simple uniform initialization.
 type UniformInitializer[T tensor.Numeric] struct {
 	ops   numeric.Arithmetic[T]
 	scale float64
+	rng   *rand.Rand
 }
 
 // UniformInitializerOptions holds configuration options for UniformInitializer.
 type UniformInitializerOptions[T tensor.Numeric] struct {
 	Scale float64
+	// Rand is the generator to draw from; nil uses the global one.
+	Rand *rand.Rand
 }
 
 // UniformInitializerOption is a function that applies an option to UniformInitializerOptions.
@@ -115,6 +154,14 @@ func WithScale[T tensor.Numeric](scale float64) UniformInitializerOption[T] {
 	}
 }
 
+// WithUniformRand makes the initializer draw from rng, such as the
+// generator of a seeded engine (see Seed), for reproducible weights.
+func WithUniformRand[T tensor.Numeric](rng *rand.Rand) UniformInitializerOption[T] {
+	return func(o *UniformInitializerOptions[T]) {
+		o.Rand = rng
+	}
+}
+
 // NewUniformInitializer creates a new uniform initializer with the given scale.
 func NewUniformInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...UniformInitializerOption[T]) *UniformInitializer[T] {
 	options := &UniformInitializerOptions[T]{}
@@ -122,17 +169,217 @@ func NewUniformInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...
 		opt(options)
 	}
 
-	return &UniformInitializer[T]{ops: ops, scale: options.Scale}
+	return &UniformInitializer[T]{ops: ops, scale: options.Scale, rng: options.Rand}
 }
 
 // Initialize generates weights using uniform initialization.
 func (u *UniformInitializer[T]) Initialize(inputSize, outputSize int) ([]T, error) {
+	if u.rng == nil {
+		determinism.Note(determinism.UnseededRand)
+	}
+	draw := globalOr(u.rng).Float64
 	weights := make([]T, inputSize*outputSize)
 	for i := range weights {
-		// #nosec G404 - math/rand is acceptable for ML weight initialization
-		val := (rand.Float64()*2 - 1) * u.scale
+		val := (draw()*2 - 1) * u.scale
 		weights[i] = u.ops.FromFloat32(float32(val))
 	}
 
 	return weights, nil
 }
+
+// OrthogonalInitializer implements orthogonal initialization (Saxe et al.,
+// 2013). The weight matrix has orthonormal rows or columns, whichever are
+// fewer, scaled by a gain.
+type OrthogonalInitializer[T tensor.Numeric] struct {
+	ops  numeric.Arithmetic[T]
+	gain float64
+	rng  *rand.Rand
+}
+
+// OrthogonalInitializerOptions holds configuration options for OrthogonalInitializer.
+type OrthogonalInitializerOptions[T tensor.Numeric] struct {
+	// Gain scales the orthogonal matrix (default 1).
+	Gain float64
+	// Rand is the generator to draw from; nil uses the global one.
+	Rand *rand.Rand
+}
+
+// OrthogonalInitializerOption is a function that applies an option to OrthogonalInitializerOptions.
+type OrthogonalInitializerOption[T tensor.Numeric] func(*OrthogonalInitializerOptions[T])
+
+// WithGain sets the gain of an OrthogonalInitializer.
+func WithGain[T tensor.Numeric](gain float64) OrthogonalInitializerOption[T] {
+	return func(o *OrthogonalInitializerOptions[T]) {
+		o.Gain = gain
+	}
+}
+
+// WithOrthogonalRand makes the initializer draw from rng, such as the
+// generator of a seeded engine (see Seed), for reproducible weights.
+func WithOrthogonalRand[T tensor.Numeric](rng *rand.Rand) OrthogonalInitializerOption[T] {
+	return func(o *OrthogonalInitializerOptions[T]) {
+		o.Rand = rng
+	}
+}
+
+// NewOrthogonalInitializer creates a new orthogonal initializer.
+func NewOrthogonalInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...OrthogonalInitializerOption[T]) *OrthogonalInitializer[T] {
+	options := &OrthogonalInitializerOptions[T]{Gain: 1}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &OrthogonalInitializer[T]{ops: ops, gain: options.Gain, rng: options.Rand}
+}
+
+// Initialize generates weights using orthogonal initialization.
+func (o *OrthogonalInitializer[T]) Initialize(inputSize, outputSize int) ([]T, error) {
+	if o.rng == nil {
+		determinism.Note(determinism.UnseededRand)
+	}
+	vals, err := orthogonalWeights(globalOr(o.rng), inputSize, outputSize, o.gain)
+	if err != nil {
+		return nil, err
+	}
+	return convertWeights(o.ops, vals), nil
+}
+
+// orthogonalWeights orthonormalizes the columns of a Gaussian n x m matrix,
+// n >= m, by modified Gram-Schmidt, and lays the result out as the
+// [inputSize, outputSize] weights, transposed if inputSize < outputSize.
+func orthogonalWeights(rng *rand.Rand, inputSize, outputSize int, gain float64) ([]float64, error) {
+	n, m := max(inputSize, outputSize), min(inputSize, outputSize)
+	cols := make([][]float64, m)
+	for j := range cols {
+		col := make([]float64, n)
+		for i := range col {
+			col[i] = rng.NormFloat64()
+		}
+		for _, q := range cols[:j] {
+			var dot float64
+			for i := range col {
+				dot += col[i] * q[i]
+			}
+			for i := range col {
+				col[i] -= dot * q[i]
+			}
+		}
+		var norm float64
+		for _, v := range col {
+			norm += v * v
+		}
+		norm = math.Sqrt(norm)
+		if norm < 1e-10 {
+			return nil, errors.New("orthogonal initialization: degenerate random matrix")
+		}
+		for i := range col {
+			col[i] /= norm
+		}
+		cols[j] = col
+	}
+	vals := make([]float64, inputSize*outputSize)
+	for r := range inputSize {
+		for c := range outputSize {
+			if inputSize >= outputSize {
+				vals[r*outputSize+c] = gain * cols[c][r]
+			} else {
+				vals[r*outputSize+c] = gain * cols[r][c]
+			}
+		}
+	}
+	return vals, nil
+}
+
+// TruncatedNormalInitializer implements truncated normal initialization:
+// weights are drawn from a normal distribution with mean 0 and the given
+// standard deviation, redrawing values more than two deviations out.
+type TruncatedNormalInitializer[T tensor.Numeric] struct {
+	ops    numeric.Arithmetic[T]
+	stddev float64
+	rng    *rand.Rand
+}
+
+// TruncatedNormalInitializerOptions holds configuration options for TruncatedNormalInitializer.
+type TruncatedNormalInitializerOptions[T tensor.Numeric] struct {
+	// Stddev is the standard deviation before truncation (default 0.02).
+	Stddev float64
+	// Rand is the generator to draw from; nil uses the global one.
+	Rand *rand.Rand
+}
+
+// TruncatedNormalInitializerOption is a function that applies an option to TruncatedNormalInitializerOptions.
+type TruncatedNormalInitializerOption[T tensor.Numeric] func(*TruncatedNormalInitializerOptions[T])
+
+// WithStddev sets the standard deviation of a TruncatedNormalInitializer.
+func WithStddev[T tensor.Numeric](stddev float64) TruncatedNormalInitializerOption[T] {
+	return func(o *TruncatedNormalInitializerOptions[T]) {
+		o.Stddev = stddev
+	}
+}
+
+// WithTruncatedNormalRand makes the initializer draw from rng, such as the
+// generator of a seeded engine (see Seed), for reproducible weights.
+func WithTruncatedNormalRand[T tensor.Numeric](rng *rand.Rand) TruncatedNormalInitializerOption[T] {
+	return func(o *TruncatedNormalInitializerOptions[T]) {
+		o.Rand = rng
+	}
+}
+
+// NewTruncatedNormalInitializer creates a new truncated normal initializer.
+func NewTruncatedNormalInitializer[T tensor.Numeric](ops numeric.Arithmetic[T], opts ...TruncatedNormalInitializerOption[T]) *TruncatedNormalInitializer[T] {
+	options := &TruncatedNormalInitializerOptions[T]{Stddev: 0.02}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &TruncatedNormalInitializer[T]{ops: ops, stddev: options.Stddev, rng: options.Rand}
+}
+
+// Initialize generates weights using truncated normal initialization.
+func (t *TruncatedNormalInitializer[T]) Initialize(inputSize, outputSize int) ([]T, error) {
+	if t.rng == nil {
+		determinism.Note(determinism.UnseededRand)
+	}
+	vals, err := truncatedNormalWeights(globalOr(t.rng), inputSize, outputSize, t.stddev)
+	if err != nil {
+		return nil, err
+	}
+	return convertWeights(t.ops, vals), nil
+}
+
+// truncatedNormalWeights draws standard normal values, redrawing those
+// outside [-2, 2], scaled by stddev.
+func truncatedNormalWeights(rng *rand.Rand, inputSize, outputSize int, stddev float64) ([]float64, error) {
+	vals := make([]float64, inputSize*outputSize)
+	for i := range vals {
+		z := rng.NormFloat64()
+		for math.Abs(z) > 2 {
+			z = rng.NormFloat64()
+		}
+		vals[i] = z * stddev
+	}
+	return vals, nil
+}
+
+// convertWeights converts initial weights to T.
+func convertWeights[T tensor.Numeric](ops numeric.Arithmetic[T], vals []float64) []T {
+	weights := make([]T, len(vals))
+	for i, v := range vals {
+		weights[i] = ops.FromFloat64(v)
+	}
+	return weights
+}
+
+// globalOr returns rng, or the global generator if rng is nil.
+func globalOr(rng *rand.Rand) *rand.Rand {
+	if rng != nil {
+		return rng
+	}
+	// #nosec G404 - math/rand is acceptable for ML weight initialization
+	return rand.New(globalSource{})
+}
+
+// globalSource draws from the global math/rand/v2 generator.
+type globalSource struct{}
+
+func (globalSource) Uint64() uint64 { return rand.Uint64() }
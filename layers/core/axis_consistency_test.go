@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/layers/activations"
+	"github.com/zerfoo/zerfoo/layers/transpose"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// TestAxisConsistency checks that every axis-taking layer treats axis k and
+// axis k-rank identically and rejects out-of-range axes with the shared
+// error.
+func TestAxisConsistency(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	// x has shape [2, 1, 3].
+	x := func() *tensor.TensorNumeric[float32] {
+		return makeTensor(t, []int{2, 1, 3}, []float32{1, 2, 3, 4, 5, 6})
+	}
+
+	tests := []struct {
+		name   string
+		build  func(axis int) graph.Node[float32]
+		inputs func() []*tensor.TensorNumeric[float32]
+		rank   int // rank the axis is normalized against
+		axes   []int
+	}{
+		{
+			name:   "Concat",
+			build:  func(axis int) graph.Node[float32] { return NewConcat(engine, axis) },
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x(), x()} },
+			rank:   3,
+			axes:   []int{0, 1, 2},
+		},
+		{
+			name:   "Squeeze",
+			build:  func(axis int) graph.Node[float32] { return &Squeeze[float32]{engine: engine, axes: []int{axis}} },
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x()} },
+			rank:   3,
+			axes:   []int{1},
+		},
+		{
+			name:   "Unsqueeze",
+			build:  func(axis int) graph.Node[float32] { return NewUnsqueeze(engine, []int{axis}) },
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x()} },
+			rank:   4,
+			axes:   []int{0, 1, 2, 3},
+		},
+		{
+			name: "Slice",
+			build: func(axis int) graph.Node[float32] {
+				return NewSlice(engine, []int64{1}, []int64{3}, []int64{int64(axis)}, nil)
+			},
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x()} },
+			rank:   3,
+			axes:   []int{0, 2},
+		},
+		{
+			name: "ReduceMean",
+			build: func(axis int) graph.Node[float32] {
+				return &ReduceMean[float32]{engine: engine, axes: []int{axis}}
+			},
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x()} },
+			rank:   3,
+			axes:   []int{0, 1, 2},
+		},
+		{
+			name:   "Softmax",
+			build:  func(axis int) graph.Node[float32] { return activations.NewSoftmax(engine, axis) },
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x()} },
+			rank:   3,
+			axes:   []int{0, 2},
+		},
+		{
+			name:   "Transpose",
+			build:  func(axis int) graph.Node[float32] { return transpose.New(engine, []int{axis, 1, 0}) },
+			inputs: func() []*tensor.TensorNumeric[float32] { return []*tensor.TensorNumeric[float32]{x()} },
+			rank:   3,
+			axes:   []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, axis := range tt.axes {
+				pos, err := tt.build(axis).Forward(ctx, tt.inputs()...)
+				if err != nil {
+					t.Fatalf("axis %d: %v", axis, err)
+				}
+				neg, err := tt.build(axis-tt.rank).Forward(ctx, tt.inputs()...)
+				if err != nil {
+					t.Fatalf("axis %d: %v", axis-tt.rank, err)
+				}
+				if !slices.Equal(pos.Shape(), neg.Shape()) || !slices.Equal(pos.Data(), neg.Data()) {
+					t.Errorf("axis %d gives %v %v, axis %d gives %v %v",
+						axis, pos.Shape(), pos.Data(), axis-tt.rank, neg.Shape(), neg.Data())
+				}
+			}
+			for _, axis := range []int{tt.rank, -tt.rank - 1} {
+				_, err := tt.build(axis).Forward(ctx, tt.inputs()...)
+				if err == nil || !strings.Contains(err.Error(), "out of range") {
+					t.Errorf("axis %d: err = %v, want out of range", axis, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReduce_MultipleAxes(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	x := makeTensor(t, []int{2, 2, 3}, []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+
+	for _, axes := range [][]int{{0, 2}, {-1, 0}, {2, -3}} {
+		out, err := Reduce(ctx, engine.Sum, x, axes, false)
+		if err != nil {
+			t.Fatalf("axes %v: %v", axes, err)
+		}
+		if !slices.Equal(out.Shape(), []int{2}) || !slices.Equal(out.Data(), []float32{30, 48}) {
+			t.Errorf("axes %v: got %v %v, want [2] [30 48]", axes, out.Shape(), out.Data())
+		}
+	}
+}
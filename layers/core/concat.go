@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/axisutil"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -88,8 +89,13 @@ func (c *Concat[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T
 		}
 	}
 
+	axis, err := axisutil.Normalize(c.axis, maxRank)
+	if err != nil {
+		return nil, fmt.Errorf("Concat: %w", err)
+	}
+
 	// Perform actual concatenation via engine
-	out, err := c.engine.Concat(context.Background(), aligned, c.axis)
+	out, err := c.engine.Concat(context.Background(), aligned, axis)
 	if err != nil {
 		return nil, err
 	}
@@ -113,9 +119,9 @@ func (c *Concat[T]) Backward(ctx context.Context, mode types.BackwardMode, outpu
 	// Properly split gradient along the concatenation axis according to each input's size
 	shape := outputGradient.Shape()
 
-	axis := c.axis
-	if axis < 0 {
-		axis = len(shape) + axis
+	axis, err := axisutil.Normalize(c.axis, len(shape))
+	if err != nil {
+		return nil, fmt.Errorf("Concat backward: %w", err)
 	}
 
 	// Equal-split fast path: when every input has the same length along the
@@ -124,23 +130,21 @@ func (c *Concat[T]) Backward(ctx context.Context, mode types.BackwardMode, outpu
 	// whole output gradient back to the host and re-slices it there, which
 	// is both slow and illegal inside a CUDA-graph capture region; see
 	// training.CaptureReplayRunner).
-	if axis >= 0 && axis < len(shape) {
-		equal := true
-		first := inputs[0].Shape()
-		for _, in := range inputs {
-			inShape := in.Shape()
-			if len(inShape) != len(shape) || inShape[axis] != first[axis] {
-				equal = false
-				break
-			}
+	equal := true
+	first := inputs[0].Shape()
+	for _, in := range inputs {
+		inShape := in.Shape()
+		if len(inShape) != len(shape) || inShape[axis] != first[axis] {
+			equal = false
+			break
 		}
-		if equal && first[axis]*len(inputs) == shape[axis] {
-			grads, err := c.engine.Split(ctx, outputGradient, len(inputs), axis)
-			if err == nil && len(grads) == len(inputs) {
-				return grads, nil
-			}
-			// Fall through to the generic host path on any Split error.
+	}
+	if equal && first[axis]*len(inputs) == shape[axis] {
+		split, err := c.engine.Split(ctx, outputGradient, len(inputs), axis)
+		if err == nil && len(split) == len(inputs) {
+			return split, nil
 		}
+		// Fall through to the generic host path on any Split error.
 	}
 
 	// Compute block size (product of dims after axis) and outer (product before axis)
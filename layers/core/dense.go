@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -51,6 +52,24 @@ func WithActivation[T tensor.Numeric](activation graph.Node[T]) DenseOpt[T] {
 	}
 }
 
+// WithInitializer draws the Dense layer's weights from initializer, such as
+// one from components.NewInitializer, instead of the default uniform [0, 1).
+func WithInitializer[T tensor.Numeric](initializer components.WeightInitializer[T]) DenseOpt[T] {
+	return func(d *Dense[T]) {
+		w := d.linear.weights.Value
+		weights, err := initializer.Initialize(d.linear.inputFeatures, d.linear.outputFeatures)
+		if err != nil {
+			d.optErr = fmt.Errorf("failed to initialize weights: %w", err)
+			return
+		}
+		if len(weights) != w.Size() {
+			d.optErr = fmt.Errorf("initializer returned %d weights, want %d", len(weights), w.Size())
+			return
+		}
+		w.SetData(weights)
+	}
+}
+
 // NewDense creates a new Dense layer.
 func NewDense[T tensor.Numeric](
 	name string,
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/zerfoo/ztensor/compute"
@@ -81,3 +82,33 @@ func TestDense_NDInput(t *testing.T) {
 	testutils.AssertNoError(t, err, "expected no error during backward pass, got %v")
 	testutils.AssertNotNil(t, gradInput, "expected gradient input to not be nil")
 }
+
+// constInitializer fills weights with a constant, or fails if err is set.
+type constInitializer struct {
+	value float32
+	err   error
+}
+
+func (c constInitializer) Initialize(inputSize, outputSize int) ([]float32, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	w := make([]float32, inputSize*outputSize)
+	for i := range w {
+		w[i] = c.value
+	}
+	return w, nil
+}
+
+func TestDense_WithInitializer(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	layer, err := NewDense[float32]("dense", engine, ops, 3, 2, WithInitializer[float32](constInitializer{value: 0.5}))
+	testutils.AssertNoError(t, err, "expected no error when creating dense layer")
+	for _, v := range layer.linear.weights.Value.Data() {
+		testutils.AssertEqual(t, float32(0.5), v, "expected weights from the initializer")
+	}
+
+	_, err = NewDense[float32]("dense", engine, ops, 3, 2, WithInitializer[float32](constInitializer{err: errors.New("boom")}))
+	testutils.AssertError(t, err, "expected the initializer error")
+}
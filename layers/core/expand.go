@@ -14,24 +14,42 @@ import (
 // Expand broadcasts a tensor to a target shape.
 type Expand[T tensor.Numeric] struct {
 	engine compute.Engine[T]
+	shape  []int // used when the shape is not given as a second input
 }
 
-func (e *Expand[T]) OpType() string                  { return "Expand" }
-func (e *Expand[T]) Attributes() map[string]any       { return nil }
-func (e *Expand[T]) OutputShape() []int               { return nil }
+// NewBroadcastTo creates an Expand that broadcasts its single input to
+// shape with numpy rules.
+func NewBroadcastTo[T tensor.Numeric](engine compute.Engine[T], shape []int) *Expand[T] {
+	return &Expand[T]{engine: engine, shape: shape}
+}
+
+func (e *Expand[T]) OpType() string                    { return "Expand" }
+func (e *Expand[T]) OutputShape() []int                { return nil }
 func (e *Expand[T]) Parameters() []*graph.Parameter[T] { return nil }
 
+func (e *Expand[T]) Attributes() map[string]any {
+	if e.shape == nil {
+		return nil
+	}
+	return map[string]any{"shape": e.shape}
+}
+
 func (e *Expand[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
-	if len(inputs) != 2 {
+	var targetShape []int
+	switch {
+	case len(inputs) == 2:
+		shapeData := inputs[1].Data()
+		targetShape = make([]int, len(shapeData))
+		for i, v := range shapeData {
+			targetShape[i] = int(v)
+		}
+	case len(inputs) == 1 && e.shape != nil:
+		targetShape = e.shape
+	default:
 		return nil, fmt.Errorf("Expand requires 2 inputs (input, shape), got %d", len(inputs))
 	}
 
 	input := inputs[0]
-	shapeData := inputs[1].Data()
-	targetShape := make([]int, len(shapeData))
-	for i, v := range shapeData {
-		targetShape[i] = int(v)
-	}
 
 	srcShape := input.Shape()
 
@@ -168,8 +186,49 @@ func validatedBroadcast(shapeA, shapeB []int) (outShape, padA, padB []int, err e
 	return outShape, padA, padB, nil
 }
 
-func (e *Expand[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
-	return nil, fmt.Errorf("Expand backward not implemented")
+// Backward sums the output gradient over the broadcast axes.
+func (e *Expand[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) < 1 || dOut == nil {
+		return nil, fmt.Errorf("Expand backward requires the output gradient and the forward input")
+	}
+	grad, err := SumToShape(ctx, e.engine, dOut, inputs[0].Shape())
+	if err != nil {
+		return nil, fmt.Errorf("Expand backward: %w", err)
+	}
+	grads := make([]*tensor.TensorNumeric[T], len(inputs))
+	grads[0] = grad
+	return grads, nil
+}
+
+// SumToShape reduces grad, the gradient of a value broadcast from shape,
+// back to shape by summing over the broadcast axes: leading axes shape
+// lacks and axes where shape has size 1. It is the backward of any
+// numpy-style broadcast.
+func SumToShape[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], grad *tensor.TensorNumeric[T], shape []int) (*tensor.TensorNumeric[T], error) {
+	gShape := grad.Shape()
+	off := len(gShape) - len(shape)
+	if off < 0 {
+		return nil, fmt.Errorf("cannot sum shape %v to higher-rank shape %v", gShape, shape)
+	}
+	var axes []int
+	for i, d := range gShape {
+		switch {
+		case i < off:
+			axes = append(axes, i)
+		case shape[i-off] == d:
+		case shape[i-off] == 1:
+			axes = append(axes, i)
+		default:
+			return nil, fmt.Errorf("shape %v does not broadcast to %v", shape, gShape)
+		}
+	}
+	var err error
+	if len(axes) > 0 {
+		if grad, err = ReduceAxes(ctx, engine, ReduceOpSum, grad, axes, true); err != nil {
+			return nil, err
+		}
+	}
+	return engine.Reshape(ctx, grad, shape)
 }
 
 // BuildExpand constructs an Expand node from attributes.
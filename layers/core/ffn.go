@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/zerfoo/zerfoo/internal/engineproxy"
 	"github.com/zerfoo/zerfoo/layers/activations"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
@@ -226,11 +227,9 @@ func (f *FFN[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]
 		activationOutput = gated
 	} else {
 		// SwiGLU path: try fused GPU path first, fall back to CPU.
-		// Unwrap EngineProxy to detect the real engine type.
-		realEngine := compute.Engine[T](f.w1.linear.engine)
-		if proxy, ok := f.w1.linear.engine.(*compute.EngineProxy[T]); ok {
-			realEngine = proxy.Real()
-		}
+		// Unwrap EngineProxy to detect the real engine type; a proxy that
+		// is recording a trace stays wrapped so the fallback is recorded.
+		realEngine, _ := engineproxy.Unwrap(f.w1.linear.engine)
 		if provider, ok := realEngine.(compute.FusedSwiGLUProvider[T]); ok {
 			out, fusedErr := provider.GPUFusedSwiGLU(w1Output, w3Output)
 			if fusedErr == nil {
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// gatherIndex lays out an output tensor whose elements are read from an
+// input tensor of shape inShape. src[d] lists, for each output coordinate
+// along axis d, the input coordinate it reads, or -1 if it reads none (a
+// padded position). The result maps each flat output index to a flat input
+// index, or -1.
+func gatherIndex(inShape []int, src [][]int) []int {
+	ndim := len(inShape)
+	size := 1
+	for _, s := range src {
+		size *= len(s)
+	}
+	index := make([]int, size)
+	if size == 0 {
+		return index
+	}
+	strides := make([]int, ndim)
+	stride := 1
+	for d := ndim - 1; d >= 0; d-- {
+		strides[d] = stride
+		stride *= inShape[d]
+	}
+	coord := make([]int, ndim)
+	for i := range index {
+		flat := 0
+		for d, c := range coord {
+			s := src[d][c]
+			if s < 0 {
+				flat = -1
+				break
+			}
+			flat += s * strides[d]
+		}
+		index[i] = flat
+		for d := ndim - 1; d >= 0; d-- {
+			if coord[d]++; coord[d] < len(src[d]) {
+				break
+			}
+			coord[d] = 0
+		}
+	}
+	return index
+}
+
+// gatherShape returns the output shape described by src.
+func gatherShape(src [][]int) []int {
+	shape := make([]int, len(src))
+	for d, s := range src {
+		shape[d] = len(s)
+	}
+	return shape
+}
+
+// gather reads data through index, writing fill where index is -1.
+func gather[T tensor.Numeric](data []T, index []int, fill T) []T {
+	out := make([]T, len(index))
+	for i, j := range index {
+		if j < 0 {
+			out[i] = fill
+			continue
+		}
+		out[i] = data[j]
+	}
+	return out
+}
+
+// scatterAdd is the adjoint of gather: it accumulates grad into an input of
+// size n through index, so input elements read more than once receive the
+// sum of their gradients and padded positions contribute nothing.
+func scatterAdd[T tensor.Numeric](ops numeric.Arithmetic[T], grad []T, index []int, n int) []T {
+	out := make([]T, n)
+	for i, j := range index {
+		if j >= 0 {
+			out[j] = ops.Add(out[j], grad[i])
+		}
+	}
+	return out
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math/rand/v2"
 
+	"github.com/zerfoo/zerfoo/internal/determinism"
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/zerfoo/model"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
@@ -23,7 +25,9 @@ type Linear[T tensor.Numeric] struct {
 	outputFeatures int
 }
 
-// randomData returns size uniform-random values in [0,1) as element type T.
+// randomData returns size uniform-random values in [0,1) as element type T,
+// drawn from the generator engine is seeded with, if any (see
+// components.Seed), and from the global generator otherwise.
 //
 // The conversion goes through ops.FromFloat32 rather than a direct T(...)
 // conversion: the reduced-precision float types (float16, bfloat16, float8)
@@ -37,16 +41,22 @@ type Linear[T tensor.Numeric] struct {
 // guard test). In that case the reduced-precision conversion is unavailable, so
 // fall back to the built-in conversion for the native float kinds (the only Ts
 // the pre-bf16 direct-conversion form supported) and leave others zero-valued.
-func randomData[T tensor.Numeric](ops numeric.Arithmetic[T], size int) []T {
+func randomData[T tensor.Numeric](engine compute.Engine[T], ops numeric.Arithmetic[T], size int) []T {
+	draw := rand.Float32
+	if rng := components.RandSource(engine); rng != nil {
+		draw = rng.Float32
+	} else {
+		determinism.Note(determinism.UnseededRand)
+	}
 	data := make([]T, size)
 	if ops == nil {
 		var zero T
 		for i := range data {
 			switch any(zero).(type) {
 			case float32:
-				data[i] = any(rand.Float32()).(T)
+				data[i] = any(draw()).(T)
 			case float64:
-				data[i] = any(float64(rand.Float32())).(T)
+				data[i] = any(float64(draw())).(T)
 			default:
 				// No ops to convert into a defined-type T; leave zero. A real
 				// engine/ops must be supplied to initialize such a layer.
@@ -55,7 +65,7 @@ func randomData[T tensor.Numeric](ops numeric.Arithmetic[T], size int) []T {
 		return data
 	}
 	for i := range data {
-		data[i] = ops.FromFloat32(rand.Float32())
+		data[i] = ops.FromFloat32(draw())
 	}
 	return data
 }
@@ -75,7 +85,7 @@ func NewLinear[T tensor.Numeric](
 	}
 	weightsTensor, err := tensor.New[T](
 		[]int{inputFeatures, outputFeatures},
-		randomData[T](ops, inputFeatures*outputFeatures),
+		randomData[T](engine, ops, inputFeatures*outputFeatures),
 	)
 	if err != nil {
 		return nil, err
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/zerfoo/ztensor/compute"
@@ -9,6 +10,8 @@ import (
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/testing/testutils"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/layers/components"
 )
 
 // TestLinear_Creation tests basic Linear layer creation.
@@ -135,3 +138,22 @@ func TestLinear_SetName(t *testing.T) {
 	params := layer.Parameters()
 	testutils.AssertEqual(t, "new_name_weights", params[0].Name, "expected parameter name to match")
 }
+
+// TestLinear_Seed tests that a seeded engine gives reproducible weights.
+func TestLinear_Seed(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	cpu := compute.NewCPUEngine[float32](ops)
+	weights := func(seed uint64) []float32 {
+		release := components.Seed[float32](cpu, seed)
+		defer release()
+		layer, err := NewLinear[float32]("seeded", cpu, ops, 6, 3)
+		testutils.AssertNoError(t, err, "expected no error when creating linear layer")
+		_, isCPU := layer.engine.(*compute.CPUEngine[float32])
+		testutils.AssertTrue(t, isCPU, "expected the layer to keep the CPU engine")
+		return layer.Parameters()[0].Value.Data()
+	}
+
+	a := weights(42)
+	testutils.AssertTrue(t, slices.Equal(a, weights(42)), "expected the same seed to give the same weights")
+	testutils.AssertFalse(t, slices.Equal(a, weights(43)), "expected different seeds to give different weights")
+}
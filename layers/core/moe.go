@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/zerfoo/zerfoo/internal/kernels"
 	"github.com/zerfoo/zerfoo/layers/activations"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
@@ -133,14 +134,11 @@ func (g *MoEGate[T]) route(
 		copy(topIdxs, idxs[:topK])
 
 		topWeights := make([]T, topK)
-		rowSum := g.ops.FromFloat64(0)
 		for k, idx := range topIdxs {
 			topWeights[k] = rowData[idx]
-			rowSum = g.ops.Add(rowSum, topWeights[k])
-		}
-		for k := range topWeights {
-			topWeights[k] = g.ops.Div(topWeights[k], rowSum)
 		}
+		rowSum := kernels.SumOps(g.ops, topWeights)
+		kernels.ScaleOps(g.ops, topWeights, topWeights, g.ops.Div(g.ops.FromFloat64(1), rowSum))
 
 		indices[t] = topIdxs
 		weights[t] = topWeights
@@ -11,93 +11,116 @@ import (
 	"github.com/zerfoo/ztensor/types"
 )
 
-// Pad applies constant-value padding to a tensor.
+// Pad pads a tensor with a constant value or by reflection.
 // pads has shape [2*ndim]: [begin_0, begin_1, ..., end_0, end_1, ...].
+// Negative pads crop the axis instead.
 type Pad[T tensor.Numeric] struct {
 	engine        compute.Engine[T]
 	pads          []int64
 	constantValue T
+	mode          string // "constant" (default) or "reflect"
 	outputShape   []int
 }
 
+// PadOption configures a Pad layer.
+type PadOption[T tensor.Numeric] func(*Pad[T])
+
+// WithPadMode selects how padded positions are filled: "constant" writes
+// the constant value, "reflect" mirrors the input about its edges without
+// repeating the edge element, so [1 2 3] padded by 2 on both sides becomes
+// [3 2 1 2 3 2 1].
+func WithPadMode[T tensor.Numeric](mode string) PadOption[T] {
+	return func(p *Pad[T]) {
+		p.mode = mode
+	}
+}
+
 // NewPad creates a new Pad layer.
-func NewPad[T tensor.Numeric](engine compute.Engine[T], pads []int64, constantValue T) *Pad[T] {
-	return &Pad[T]{engine: engine, pads: pads, constantValue: constantValue}
+func NewPad[T tensor.Numeric](engine compute.Engine[T], pads []int64, constantValue T, opts ...PadOption[T]) *Pad[T] {
+	p := &Pad[T]{engine: engine, pads: pads, constantValue: constantValue, mode: "constant"}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-// Forward pads the input tensor with the configured constant value.
+// Forward pads the input tensor.
 func (p *Pad[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
 	if len(inputs) != 1 {
 		return nil, fmt.Errorf("Pad expects 1 input, got %d", len(inputs))
 	}
 	input := inputs[0]
-	shape := input.Shape()
-	ndim := len(shape)
+	src, err := p.sources(input.Shape())
+	if err != nil {
+		return nil, err
+	}
+	out, err := tensor.New(gatherShape(src), gather(input.Data(), gatherIndex(input.Shape(), src), p.constantValue))
+	if err != nil {
+		return nil, fmt.Errorf("Pad.Forward: %w", err)
+	}
+	p.outputShape = out.Shape()
+	return out, nil
+}
 
+// sources returns, per axis, the input coordinate each output coordinate
+// reads, or -1 for a constant-padded position.
+func (p *Pad[T]) sources(shape []int) ([][]int, error) {
+	ndim := len(shape)
 	if len(p.pads) != 2*ndim {
 		return nil, fmt.Errorf("Pad: pads length %d does not match 2*ndim=%d", len(p.pads), 2*ndim)
 	}
-
-	// Compute output shape.
-	outShape := make([]int, ndim)
-	for d := range ndim {
-		outShape[d] = shape[d] + int(p.pads[d]) + int(p.pads[ndim+d])
-	}
-
-	outSize := 1
-	for _, d := range outShape {
-		outSize *= d
+	if p.mode != "constant" && p.mode != "reflect" {
+		return nil, fmt.Errorf("Pad: unsupported mode %q", p.mode)
 	}
-
-	// Allocate output filled with the constant value.
-	outData := make([]T, outSize)
-	for i := range outData {
-		outData[i] = p.constantValue
+	src := make([][]int, ndim)
+	for d, dim := range shape {
+		begin, end := int(p.pads[d]), int(p.pads[ndim+d])
+		n := dim + begin + end
+		if n < 0 {
+			return nil, fmt.Errorf("Pad: pads %d and %d crop axis %d of size %d below zero", begin, end, d, dim)
+		}
+		if p.mode == "reflect" && (begin > 0 && begin >= dim || end > 0 && end >= dim) {
+			return nil, fmt.Errorf("Pad: reflect pads %d and %d on axis %d must be less than its size %d", begin, end, d, dim)
+		}
+		src[d] = make([]int, n)
+		for o := range n {
+			i := o - begin
+			switch {
+			case i >= 0 && i < dim:
+			case p.mode == "reflect" && i < 0:
+				i = -i
+			case p.mode == "reflect":
+				i = 2*(dim-1) - i
+			default:
+				i = -1
+			}
+			src[d][o] = i
+		}
 	}
+	return src, nil
+}
 
-	// Copy input data into the padded positions using flat indexing.
-	inData := input.Data()
-	inSize := len(inData)
-	inStrides := make([]int, ndim)
-	outStrides := make([]int, ndim)
-	stride := 1
-	for d := ndim - 1; d >= 0; d-- {
-		inStrides[d] = stride
-		stride *= shape[d]
+// Backward returns the gradient of the input: the output gradient with the
+// padding removed and, in reflect mode, the gradient of every mirrored
+// position added to the element it was copied from.
+func (p *Pad[T]) Backward(_ context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 1 || dOut == nil {
+		return nil, fmt.Errorf("Pad backward requires the output gradient and the forward input")
 	}
-	stride = 1
-	for d := ndim - 1; d >= 0; d-- {
-		outStrides[d] = stride
-		stride *= outShape[d]
+	input := inputs[0]
+	src, err := p.sources(input.Shape())
+	if err != nil {
+		return nil, err
 	}
-
-	for flatIn := range inSize {
-		// Convert flat input index to multi-dimensional indices.
-		// idx[d] = (flatIn / inStrides[d]) % shape[d]
-		idx := make([]int, ndim)
-		for d := range ndim {
-			idx[d] = (flatIn / inStrides[d]) % shape[d]
-		}
-
-		// Compute output flat index with padding offset.
-		flatOut := 0
-		for d := range ndim {
-			flatOut += (idx[d] + int(p.pads[d])) * outStrides[d]
-		}
-		outData[flatOut] = inData[flatIn]
+	index := gatherIndex(input.Shape(), src)
+	if dOut.Size() != len(index) {
+		return nil, fmt.Errorf("Pad backward: gradient shape %v does not match output shape %v", dOut.Shape(), gatherShape(src))
 	}
-
-	out, err := tensor.New[T](outShape, outData)
+	grad, err := tensor.New(input.Shape(), scatterAdd(p.engine.Ops(), dOut.Data(), index, input.Size()))
 	if err != nil {
-		return nil, fmt.Errorf("Pad.Forward: %w", err)
+		return nil, fmt.Errorf("Pad backward: %w", err)
 	}
-	p.outputShape = outShape
-	return out, nil
-}
-
-// Backward returns nil (not required for inference).
-func (p *Pad[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
-	return nil, nil
+	return []*tensor.TensorNumeric[T]{grad}, nil
 }
 
 // OpType returns "Pad".
@@ -105,7 +128,7 @@ func (p *Pad[T]) OpType() string { return "Pad" }
 
 // Attributes returns the pad configuration.
 func (p *Pad[T]) Attributes() map[string]interface{} {
-	return map[string]interface{}{"pads": p.pads}
+	return map[string]interface{}{"pads": p.pads, "mode": p.mode}
 }
 
 // OutputShape returns the output shape from the last forward call.
@@ -115,7 +138,8 @@ func (p *Pad[T]) OutputShape() []int { return p.outputShape }
 func (p *Pad[T]) Parameters() []*graph.Parameter[T] { return nil }
 
 // BuildPad constructs a Pad layer from ZMF attributes.
-// Supported attribute keys: "pads" ([]int64), "constant_value" (float32/float64).
+// Supported attribute keys: "pads" ([]int64), "constant_value" (float32/float64)
+// and "mode" ("constant" or "reflect").
 func BuildPad[T tensor.Numeric](
 	engine compute.Engine[T],
 	ops numeric.Arithmetic[T],
@@ -135,7 +159,11 @@ func BuildPad[T tensor.Numeric](
 		}
 	}
 
-	return NewPad(engine, pads, constantValue), nil
+	var opts []PadOption[T]
+	if mode, ok := attributes["mode"].(string); ok {
+		opts = append(opts, WithPadMode[T](mode))
+	}
+	return NewPad(engine, pads, constantValue, opts...), nil
 }
 
 // Statically assert that Pad implements graph.Node.
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+)
+
+// Dtype promotion for mixed-dtype binary ops.
+//
+// A graph is built over a single element type T, so operands of a different
+// dtype (an f16 activation cache feeding an f32 graph, int32 indices used in
+// arithmetic) must be cast at the boundary. PromoteTypes defines the result
+// dtype of a binary op on two dtypes; AddPromoted inserts the cast when such
+// an operand is added to a graph, and MixedBinary when it meets an engine op
+// directly:
+//
+//   - float ⊕ float: the wider type; float16 ⊕ bfloat16 → float32 since
+//     neither represents the other; float8 promotes to the other operand.
+//   - int ⊕ float: the float type.
+//   - signed ⊕ signed, unsigned ⊕ unsigned: the wider type.
+//   - signed ⊕ unsigned: the narrowest signed type holding both (int8 ⊕
+//     uint8 → int16). uint64 and uint with a signed type have no lossless
+//     integer result and are rejected.
+//
+// Named types (type Index int32) promote like their underlying type.
+
+// PromoteTypes returns the dtype a binary op on a and b computes in.
+func PromoteTypes(a, b reflect.Type) (reflect.Type, error) {
+	if a == b {
+		return a, nil
+	}
+	ca, bitsA, okA := dtype.ClassOf(a)
+	cb, bitsB, okB := dtype.ClassOf(b)
+	if !okA || !okB {
+		return nil, fmt.Errorf("no promotion rule for %v and %v", a, b)
+	}
+	// Order so a is the float, or the signed operand of a mixed integer pair.
+	if cb == dtype.Float && ca != dtype.Float || cb == dtype.Signed && ca == dtype.Unsigned {
+		a, b, ca, cb, bitsA, bitsB = b, a, cb, ca, bitsB, bitsA
+	}
+
+	switch {
+	case ca == dtype.Float && cb == dtype.Float:
+		if bitsA == bitsB {
+			// float16 and bfloat16 trade range for precision.
+			return reflect.TypeFor[float32](), nil
+		}
+		return wider(a, bitsA, b, bitsB), nil
+	case ca == dtype.Float:
+		return a, nil
+	case ca == cb:
+		if bitsA == bitsB {
+			// int and int64 (or uint and uint64) have the same width; use
+			// the explicitly sized type.
+			if a.Kind() == reflect.Int || a.Kind() == reflect.Uint {
+				return b, nil
+			}
+			return a, nil
+		}
+		return wider(a, bitsA, b, bitsB), nil
+	default: // a signed, b unsigned
+		if bitsA > bitsB {
+			return a, nil
+		}
+		switch bitsB {
+		case 8:
+			return reflect.TypeFor[int16](), nil
+		case 32:
+			return reflect.TypeFor[int64](), nil
+		}
+		return nil, fmt.Errorf("no lossless integer promotion for %v and %v", a, b)
+	}
+}
+
+func wider(a reflect.Type, bitsA int, b reflect.Type, bitsB int) reflect.Type {
+	if bitsA >= bitsB {
+		return a
+	}
+	return b
+}
+
+// ConvertTensor returns a CPU copy of t with elements converted to D, as
+// dtype.Convert does: integers convert exactly when they fit, floats
+// truncate toward zero, and out-of-range values saturate at D's bounds.
+func ConvertTensor[S, D tensor.Numeric](t *tensor.TensorNumeric[S]) (*tensor.TensorNumeric[D], error) {
+	src := t.Data()
+	dst := make([]D, len(src))
+	dtype.Convert(dst, src)
+	return tensor.New(t.Shape(), dst)
+}
+
+// Promote converts a and b to R, which must be PromoteTypes of their dtypes.
+// An operand that is already R is returned as is.
+func Promote[A, B, R tensor.Numeric](a *tensor.TensorNumeric[A], b *tensor.TensorNumeric[B]) (*tensor.TensorNumeric[R], *tensor.TensorNumeric[R], error) {
+	want, err := PromoteTypes(reflect.TypeFor[A](), reflect.TypeFor[B]())
+	if err != nil {
+		return nil, nil, err
+	}
+	if got := reflect.TypeFor[R](); got != want {
+		return nil, nil, fmt.Errorf("%v ⊕ %v promotes to %v, not %v", reflect.TypeFor[A](), reflect.TypeFor[B](), want, got)
+	}
+	ra, err := castTo[A, R](a)
+	if err != nil {
+		return nil, nil, err
+	}
+	rb, err := castTo[B, R](b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ra, rb, nil
+}
+
+func castTo[S, D tensor.Numeric](t *tensor.TensorNumeric[S]) (*tensor.TensorNumeric[D], error) {
+	if same, ok := any(t).(*tensor.TensorNumeric[D]); ok {
+		return same, nil
+	}
+	return ConvertTensor[S, D](t)
+}
+
+// BinaryOp is an element-wise engine op such as compute.Engine.Add.
+type BinaryOp[T tensor.Numeric] func(ctx context.Context, a, b *tensor.TensorNumeric[T], dst ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error)
+
+// MixedBinary applies op to operands of different dtypes, promoting both to
+// R first:
+//
+//	sum, err := core.MixedBinary(ctx, engine.Add, f16Cache, f32Activations)
+func MixedBinary[A, B, R tensor.Numeric](ctx context.Context, op BinaryOp[R], a *tensor.TensorNumeric[A], b *tensor.TensorNumeric[B]) (*tensor.TensorNumeric[R], error) {
+	ra, rb, err := Promote[A, B, R](a, b)
+	if err != nil {
+		return nil, err
+	}
+	return op(ctx, ra, rb)
+}
+
+// AddPromoted adds src to the graph being built as a constant of the
+// graph's element type T, converting it once at build time. It is the cast
+// a binary op needs when src meets T-typed activations, so it fails unless
+// T is the promotion of S and T: an f16 cache or int32 indices can join an
+// f32 graph, but an f32 tensor is not narrowed into an f16 one.
+func AddPromoted[S, T tensor.Numeric](b *graph.Builder[T], engine compute.Engine[T], ops numeric.Arithmetic[T], name string, src *tensor.TensorNumeric[S]) (graph.Node[T], error) {
+	from, to := reflect.TypeFor[S](), reflect.TypeFor[T]()
+	want, err := PromoteTypes(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if want != to {
+		return nil, fmt.Errorf("%s: %v ⊕ %v promotes to %v, not the graph type", name, from, to, want)
+	}
+	value, err := castTo[S, T](src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	c, err := NewConstant(name, engine, ops, value)
+	if err != nil {
+		return nil, err
+	}
+	return b.AddNode(c), nil
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/float8"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestPromoteTypes(t *testing.T) {
+	f8 := reflect.TypeFor[float8.Float8]()
+	f16 := reflect.TypeFor[float16.Float16]()
+	bf16 := reflect.TypeFor[float16.BFloat16]()
+	f32 := reflect.TypeFor[float32]()
+	f64 := reflect.TypeFor[float64]()
+	i8 := reflect.TypeFor[int8]()
+	i16 := reflect.TypeFor[int16]()
+	i32 := reflect.TypeFor[int32]()
+	i64 := reflect.TypeFor[int64]()
+	u8 := reflect.TypeFor[uint8]()
+	u32 := reflect.TypeFor[uint32]()
+	u64 := reflect.TypeFor[uint64]()
+	in := reflect.TypeFor[int]()
+
+	tests := []struct {
+		a, b, want reflect.Type
+	}{
+		{f16, f32, f32},
+		{f32, f64, f64},
+		{f16, bf16, f32},
+		{f8, f16, f16},
+		{i32, f16, f16},
+		{f32, i64, f32},
+		{u8, bf16, bf16},
+		{i8, i32, i32},
+		{in, i64, i64},
+		{u8, u32, u32},
+		{i8, u8, i16},
+		{i32, u32, i64},
+		{i64, u32, i64},
+		{u8, i32, i32},
+		{f32, f32, f32},
+	}
+	for _, tt := range tests {
+		for _, pair := range [][2]reflect.Type{{tt.a, tt.b}, {tt.b, tt.a}} {
+			got, err := PromoteTypes(pair[0], pair[1])
+			if err != nil || got != tt.want {
+				t.Errorf("PromoteTypes(%v, %v) = %v, %v; want %v", pair[0], pair[1], got, err, tt.want)
+			}
+		}
+	}
+
+	if _, err := PromoteTypes(u64, i32); err == nil {
+		t.Error("uint64 ⊕ int32: expected error")
+	}
+	if _, err := PromoteTypes(reflect.TypeFor[string](), f32); err == nil {
+		t.Error("string ⊕ float32: expected error")
+	}
+}
+
+func TestConvertTensor(t *testing.T) {
+	src, err := tensor.New([]int{2, 2}, []float32{1.5, -2.5, 3, 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ints, err := ConvertTensor[float32, int32](src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ints.Data(); !reflect.DeepEqual(got, []int32{1, -2, 3, 1 << 20}) {
+		t.Errorf("float32 → int32 = %v", got)
+	}
+	if !reflect.DeepEqual(ints.Shape(), []int{2, 2}) {
+		t.Errorf("shape = %v", ints.Shape())
+	}
+
+	half, err := ConvertTensor[float32, float16.Float16](src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := half.Data()[1].ToFloat32(); got != -2.5 {
+		t.Errorf("float32 → float16: got %v, want -2.5", got)
+	}
+
+	big, err := tensor.New([]int{1}, []int64{1<<62 + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := ConvertTensor[int64, int](big)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Data()[0] != 1<<62+1 {
+		t.Errorf("int64 → int lost precision: %d", back.Data()[0])
+	}
+}
+
+func TestMixedBinary(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+
+	a, err := tensor.New([]int{3}, []float16.Float16{
+		float16.FromFloat32(1), float16.FromFloat32(2), float16.FromFloat32(0.5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := tensor.New([]int{3}, []float32{10, 20, 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := MixedBinary(ctx, engine.Add, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sum.Data(); !reflect.DeepEqual(got, []float32{11, 22, 30.5}) {
+		t.Errorf("f16 + f32 = %v", got)
+	}
+
+	idx, err := tensor.New([]int{3}, []int32{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod, err := MixedBinary(ctx, engine.Mul, idx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := prod.Data(); !reflect.DeepEqual(got, []float32{10, 40, 90}) {
+		t.Errorf("int32 * f32 = %v", got)
+	}
+
+	// The op's dtype must match the promoted dtype.
+	e64 := compute.NewCPUEngine[float64](numeric.Float64Ops{})
+	if _, err := MixedBinary(ctx, e64.Add, a, b); err == nil {
+		t.Error("f16 + f32 computed in float64: expected error")
+	}
+}
+
+type tokenID int32
+
+func TestPromoteNamedTypes(t *testing.T) {
+	got, err := PromoteTypes(reflect.TypeFor[tokenID](), reflect.TypeFor[float32]())
+	if err != nil || got != reflect.TypeFor[float32]() {
+		t.Errorf("tokenID ⊕ float32 = %v, %v; want float32", got, err)
+	}
+	got, err = PromoteTypes(reflect.TypeFor[tokenID](), reflect.TypeFor[int8]())
+	if err != nil || got != reflect.TypeFor[tokenID]() {
+		t.Errorf("tokenID ⊕ int8 = %v, %v; want tokenID", got, err)
+	}
+}
+
+func TestConvertTensor_OutOfRange(t *testing.T) {
+	u, err := tensor.New([]int{2}, []uint64{1<<63 + 5, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := ConvertTensor[uint64, int64](u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := i.Data(); !reflect.DeepEqual(got, []int64{math.MaxInt64, 7}) {
+		t.Errorf("uint64 → int64 = %v, want saturated", got)
+	}
+
+	f, err := tensor.New([]int{3}, []float32{-3, 2.5, 1e10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ConvertTensor[float32, uint32](f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Data(); !reflect.DeepEqual(got, []uint32{0, 2, math.MaxUint32}) {
+		t.Errorf("float32 → uint32 = %v, want saturated", got)
+	}
+}
+
+func TestAddPromoted(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+
+	idx, err := tensor.New([]int{3}, []tokenID{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := b.Input([]int{3})
+	c, err := AddPromoted(b, engine, ops, "idx", idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.AddNode(NewMul(engine), in, c)
+	g, err := b.Build(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := tensor.New([]int{3}, []float32{10, 20, 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Data(), []float32{10, 40, 90}) {
+		t.Errorf("x * idx = %v", got.Data())
+	}
+
+	// float64 does not promote into a float32 graph.
+	wide, err := tensor.New([]int{1}, []float64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddPromoted(b, engine, ops, "wide", wide); err == nil {
+		t.Error("float64 into a float32 graph: expected error")
+	}
+}
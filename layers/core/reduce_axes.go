@@ -0,0 +1,246 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/zerfoo/zerfoo/internal/axisutil"
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// ReduceOp selects the reduction ReduceAxes applies.
+type ReduceOp int
+
+const (
+	ReduceOpSum ReduceOp = iota
+	ReduceOpMean
+	ReduceOpMax
+	ReduceOpMin
+	ReduceOpProd
+	// ReduceOpLogSumExp computes log(sum(exp(x))) stably, by shifting each
+	// group by its maximum and accumulating in float64.
+	ReduceOpLogSumExp
+)
+
+// String returns the ONNX name of the reduction.
+func (op ReduceOp) String() string {
+	switch op {
+	case ReduceOpSum:
+		return "ReduceSum"
+	case ReduceOpMean:
+		return "ReduceMean"
+	case ReduceOpMax:
+		return "ReduceMax"
+	case ReduceOpMin:
+		return "ReduceMin"
+	case ReduceOpProd:
+		return "ReduceProd"
+	case ReduceOpLogSumExp:
+		return "ReduceLogSumExp"
+	}
+	return fmt.Sprintf("ReduceOp(%d)", int(op))
+}
+
+// reduceFunc returns the engine kernel for op, or nil if the engine has
+// none and the reduction runs on the host.
+func reduceFunc[T tensor.Numeric](engine compute.Engine[T], op ReduceOp) ReduceFunc[T] {
+	switch op {
+	case ReduceOpSum:
+		return engine.ReduceSum
+	case ReduceOpMean:
+		return engine.ReduceMean
+	case ReduceOpMax:
+		return engine.ReduceMax
+	}
+	return nil
+}
+
+// ReduceAxes reduces input over a set of axes with a single reduction,
+// where Reduce runs one reduction per axis. The reduced axes are moved to
+// the end (a transpose, skipped when they already trail the kept axes) and
+// merged into one by a reshape, so reducing [0, 2] of a [batch, seq, dim]
+// tensor costs one transpose and one reduction and the result has no
+// per-axis intermediates. Sum, Mean and Max run on the engine; Min, Prod and
+// LogSumExp run on the host.
+//
+// Empty axes reduce every axis, negative axes count from the end, and
+// reducing every axis without keepDims yields a rank-0 tensor.
+func ReduceAxes[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], op ReduceOp, input *tensor.TensorNumeric[T], axes []int, keepDims bool) (*tensor.TensorNumeric[T], error) {
+	if op < ReduceOpSum || op > ReduceOpLogSumExp {
+		return nil, fmt.Errorf("reduce: unknown op %v", op)
+	}
+	if len(input.Shape()) == 0 {
+		// Every reduction of a scalar is the identity.
+		return Reduce(ctx, engine.ReduceSum, input, axes, keepDims)
+	}
+	plan, err := newReducePlan(input.Shape(), axes, keepDims)
+	if err != nil {
+		return nil, err
+	}
+	reduce := reduceFunc(engine, op)
+	if reduce == nil {
+		out, _, err := reduceOnHost(ctx, engine, op, plan, input)
+		return out, err
+	}
+	if len(plan.axes) == 1 && (keepDims || len(plan.outShape) > 0) {
+		return reduce(ctx, input, plan.axes[0], keepDims)
+	}
+	rows, err := reduceRows(ctx, engine, plan, input)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err = reduce(ctx, rows, 1, false); err != nil {
+		return nil, fmt.Errorf("reduce axes %v: %w", plan.axes, err)
+	}
+	return engine.Reshape(ctx, rows, plan.outShape)
+}
+
+// reducePlan lays a reduction out as rows: the input is permuted so the
+// reduced axes trail the kept ones and viewed as [outer, n], with one row
+// per output element.
+type reducePlan struct {
+	axes     []int // normalized reduced axes, descending
+	shape    []int // input shape
+	perm     []int // nil when the reduced axes already trail the kept axes
+	outer, n int
+	outShape []int
+}
+
+func newReducePlan(shape, axes []int, keepDims bool) (*reducePlan, error) {
+	norm, err := axisutil.Reduction(axes, len(shape))
+	if err != nil {
+		return nil, err
+	}
+	p := &reducePlan{axes: norm, shape: shape, outer: 1, n: 1}
+	reduced := make([]bool, len(shape))
+	for _, a := range norm {
+		reduced[a] = true
+	}
+	perm := make([]int, 0, len(shape))
+	for i, d := range shape {
+		switch {
+		case !reduced[i]:
+			perm = append(perm, i)
+			p.outer *= d
+			p.outShape = append(p.outShape, d)
+		case keepDims:
+			p.outShape = append(p.outShape, 1)
+		}
+	}
+	for i, d := range shape {
+		if reduced[i] {
+			perm = append(perm, i)
+			p.n *= d
+		}
+	}
+	if p.outShape == nil {
+		p.outShape = []int{}
+	}
+	if !slices.IsSorted(perm) {
+		p.perm = perm
+	}
+	return p, nil
+}
+
+// reduceRows returns x, which has the plan's input shape, as [outer, n].
+func reduceRows[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], p *reducePlan, x *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	var err error
+	if p.perm != nil {
+		if x, err = engine.Transpose(ctx, x, p.perm); err != nil {
+			return nil, fmt.Errorf("reduce: transpose %v: %w", p.perm, err)
+		}
+	}
+	return engine.Reshape(ctx, x, []int{p.outer, p.n})
+}
+
+// unreduceRows is the inverse of reduceRows: it returns [outer, n] rows in
+// the plan's input shape.
+func unreduceRows[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], p *reducePlan, rows *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if p.perm == nil {
+		return engine.Reshape(ctx, rows, p.shape)
+	}
+	permuted := make([]int, len(p.perm))
+	inv := make([]int, len(p.perm))
+	for i, a := range p.perm {
+		permuted[i] = p.shape[a]
+		inv[a] = i
+	}
+	x, err := engine.Reshape(ctx, rows, permuted)
+	if err != nil {
+		return nil, err
+	}
+	return engine.Transpose(ctx, x, inv)
+}
+
+// reduceOnHost runs a Max, Min, Prod or LogSumExp reduction on the host.
+// For Max and Min it also returns, per row, the index within the row of the
+// selected element (the first one on ties), which the backward pass routes
+// the gradient to.
+func reduceOnHost[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], op ReduceOp, p *reducePlan, input *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], []int, error) {
+	rows, err := reduceRows(ctx, engine, p, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.n == 0 {
+		return nil, nil, fmt.Errorf("reduce: %v over an empty axis", op)
+	}
+	ops := engine.Ops()
+	data := rows.Data()
+	vals := make([]T, p.outer)
+	var arg []int
+	if op == ReduceOpMax || op == ReduceOpMin {
+		arg = make([]int, p.outer)
+	}
+	var buf []float64
+	for o := range p.outer {
+		row := data[o*p.n : (o+1)*p.n]
+		switch op {
+		case ReduceOpProd:
+			v := ops.One()
+			for _, x := range row {
+				v = ops.Mul(v, x)
+			}
+			vals[o] = v
+		case ReduceOpLogSumExp:
+			buf = dtype.Float64s(buf, row)
+			vals[o] = ops.FromFloat64(logSumExp(buf))
+		default:
+			best := 0
+			for i, x := range row[1:] {
+				if op == ReduceOpMax && ops.GreaterThan(x, row[best]) ||
+					op == ReduceOpMin && ops.GreaterThan(row[best], x) {
+					best = i + 1
+				}
+			}
+			vals[o] = row[best]
+			arg[o] = best
+		}
+	}
+	out, err := tensor.New(p.outShape, vals)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, arg, nil
+}
+
+// logSumExp returns log(sum(exp(row))) computed in float64 after shifting
+// by the row maximum, so no term overflows and the largest is exactly 1.
+func logSumExp(row []float64) float64 {
+	m := math.Inf(-1)
+	for _, v := range row {
+		m = max(m, v)
+	}
+	if math.IsInf(m, 0) {
+		// All -Inf gives -Inf; any +Inf gives +Inf.
+		return m
+	}
+	var sum float64
+	for _, v := range row {
+		sum += math.Exp(v - m)
+	}
+	return m + math.Log(sum)
+}
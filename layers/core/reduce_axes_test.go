@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// refReduce reduces x one element at a time on the host, the reference for
+// ReduceAxes.
+func refReduce(t *testing.T, op ReduceOp, x *tensor.TensorNumeric[float32], axes []int, keepDims bool) *tensor.TensorNumeric[float32] {
+	t.Helper()
+	shape := x.Shape()
+	reduced := make([]bool, len(shape))
+	for _, a := range axes {
+		reduced[(a+len(shape))%len(shape)] = true
+	}
+	var outShape []int
+	for i, d := range shape {
+		switch {
+		case len(axes) == 0 || reduced[i]:
+			if keepDims {
+				outShape = append(outShape, 1)
+			}
+		default:
+			outShape = append(outShape, d)
+		}
+	}
+	size := 1
+	for _, d := range outShape {
+		size *= d
+	}
+	acc := make([]float64, size)
+	count := make([]int, size)
+	idx := make([]int, len(shape))
+	for _, v := range x.Data() {
+		// Flat output offset of the element at idx.
+		o := 0
+		for i, d := range shape {
+			if len(axes) == 0 || reduced[i] {
+				continue
+			}
+			o = o*d + idx[i]
+		}
+		f := float64(v)
+		if op == ReduceOpLogSumExp {
+			f = math.Exp(f)
+		}
+		switch {
+		case count[o] == 0:
+			acc[o] = f
+		case op == ReduceOpSum || op == ReduceOpMean || op == ReduceOpLogSumExp:
+			acc[o] += f
+		case op == ReduceOpMax:
+			acc[o] = math.Max(acc[o], f)
+		case op == ReduceOpMin:
+			acc[o] = math.Min(acc[o], f)
+		case op == ReduceOpProd:
+			acc[o] *= f
+		}
+		count[o]++
+		for i := len(idx) - 1; i >= 0; i-- {
+			if idx[i]++; idx[i] < shape[i] {
+				break
+			}
+			idx[i] = 0
+		}
+	}
+	data := make([]float32, size)
+	for i, a := range acc {
+		switch op {
+		case ReduceOpMean:
+			a /= float64(count[i])
+		case ReduceOpLogSumExp:
+			a = math.Log(a)
+		}
+		data[i] = float32(a)
+	}
+	if outShape == nil {
+		outShape = []int{}
+	}
+	return makeTensor(t, outShape, data)
+}
+
+func TestReduceAxes(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	data := make([]float32, 24)
+	for i := range data {
+		data[i] = float32((i*7)%11)/4 - 1
+	}
+	x := makeTensor(t, []int{2, 3, 4}, data)
+
+	axesSets := [][]int{nil, {0}, {1}, {2}, {0, 2}, {-1, 0}, {1, 2}, {0, 1}, {0, 1, 2}}
+	for _, op := range []ReduceOp{ReduceOpSum, ReduceOpMean, ReduceOpMax, ReduceOpMin, ReduceOpProd, ReduceOpLogSumExp} {
+		for _, axes := range axesSets {
+			for _, keepDims := range []bool{false, true} {
+				got, err := ReduceAxes(ctx, engine, op, x, axes, keepDims)
+				if err != nil {
+					t.Fatalf("%v%v keepDims=%v: %v", op, axes, keepDims, err)
+				}
+				want := refReduce(t, op, x, axes, keepDims)
+				if !slices.Equal(got.Shape(), want.Shape()) {
+					t.Errorf("%v%v keepDims=%v: shape = %v, want %v", op, axes, keepDims, got.Shape(), want.Shape())
+					continue
+				}
+				for i, v := range got.Data() {
+					if math.Abs(float64(v-want.Data()[i])) > 1e-5 {
+						t.Errorf("%v%v keepDims=%v: data = %v, want %v", op, axes, keepDims, got.Data(), want.Data())
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestReduceAxes_LayerNormAcrossBatchAndSeq(t *testing.T) {
+	engine := makeEngine()
+	// [batch=2, seq=2, dim=2]; mean over batch and seq leaves one value per
+	// feature.
+	x := makeTensor(t, []int{2, 2, 2}, []float32{1, 10, 2, 20, 3, 30, 4, 40})
+	out, err := ReduceAxes(context.Background(), engine, ReduceOpMean, x, []int{0, 1}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Shape(), []int{1, 1, 2}) || !slices.Equal(out.Data(), []float32{2.5, 25}) {
+		t.Errorf("got %v %v, want [1 1 2] [2.5 25]", out.Shape(), out.Data())
+	}
+}
+
+func TestReduceAxes_Errors(t *testing.T) {
+	engine := makeEngine()
+	x := makeTensor(t, []int{2, 2}, []float32{1, 2, 3, 4})
+	if _, err := ReduceAxes(context.Background(), engine, ReduceOpSum, x, []int{2}, false); err == nil {
+		t.Error("out-of-range axis should error")
+	}
+	if _, err := ReduceAxes(context.Background(), engine, ReduceOp(9), x, nil, false); err == nil {
+		t.Error("unknown op should error")
+	}
+	if got := ReduceOp(9).String(); got != "ReduceOp(9)" {
+		t.Errorf("String = %q", got)
+	}
+}
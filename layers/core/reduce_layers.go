@@ -0,0 +1,213 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+)
+
+// ReduceMax reduces a tensor by taking the maximum over the specified axes.
+// The gradient flows to the selected element of each reduced group, the
+// first one on ties.
+type ReduceMax[T tensor.Numeric] struct{ hostReduce[T] }
+
+// ReduceMin reduces a tensor by taking the minimum over the specified axes.
+// The gradient flows to the selected element of each reduced group, the
+// first one on ties.
+type ReduceMin[T tensor.Numeric] struct{ hostReduce[T] }
+
+// ReduceProd reduces a tensor by multiplying over the specified axes. The
+// gradient of each element is the product of the others in its group,
+// computed without dividing by the element so zeros are handled exactly.
+type ReduceProd[T tensor.Numeric] struct{ hostReduce[T] }
+
+// ReduceLogSumExp computes log(sum(exp(x))) over the specified axes in one
+// numerically stable pass, for losses that would otherwise compose Exp, Sum
+// and Log and overflow on large logits. Its gradient is the softmax of each
+// reduced group.
+type ReduceLogSumExp[T tensor.Numeric] struct{ hostReduce[T] }
+
+// hostReduce implements the reductions the engine has no backward-capable
+// kernel for. Forward records the reduction layout and, for Max and Min,
+// the index of the selected element of each group for Backward.
+type hostReduce[T tensor.Numeric] struct {
+	engine            compute.Engine[T]
+	op                ReduceOp
+	axes              []int
+	keepDims          bool
+	noopWithEmptyAxes bool
+
+	plan *reducePlan // nil when Forward was the identity
+	arg  []int
+}
+
+func (r *hostReduce[T]) OpType() string                    { return r.op.String() }
+func (r *hostReduce[T]) OutputShape() []int                { return nil }
+func (r *hostReduce[T]) Parameters() []*graph.Parameter[T] { return nil }
+
+func (r *hostReduce[T]) Attributes() map[string]any {
+	return map[string]any{"axes": r.axes, "keepdims": r.keepDims, "noop_with_empty_axes": r.noopWithEmptyAxes}
+}
+
+func (r *hostReduce[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) < 1 {
+		return nil, fmt.Errorf("%v requires at least 1 input, got %d", r.op, len(inputs))
+	}
+	input := inputs[0]
+	axes := reduceInputAxes(r.axes, inputs)
+	r.plan, r.arg = nil, nil
+	if len(axes) == 0 && r.noopWithEmptyAxes {
+		return input, nil
+	}
+	if len(input.Shape()) == 0 {
+		return ReduceAxes(ctx, r.engine, r.op, input, axes, r.keepDims)
+	}
+	plan, err := newReducePlan(input.Shape(), axes, r.keepDims)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", r.op, err)
+	}
+	out, arg, err := reduceOnHost(ctx, r.engine, r.op, plan, input)
+	if err != nil {
+		return nil, err
+	}
+	r.plan, r.arg = plan, arg
+	return out, nil
+}
+
+func (r *hostReduce[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) < 1 {
+		return nil, fmt.Errorf("%v backward requires the forward input", r.op)
+	}
+	input := inputs[0]
+	p := r.plan
+	if p == nil {
+		grad, err := r.engine.Reshape(ctx, dOut, input.Shape())
+		if err != nil {
+			return nil, err
+		}
+		return []*tensor.TensorNumeric[T]{grad}, nil
+	}
+	if dOut.Size() != p.outer {
+		return nil, fmt.Errorf("%v backward: output gradient of shape %v, want %v", r.op, dOut.Shape(), p.outShape)
+	}
+
+	ops := r.engine.Ops()
+	g := dOut.Data()
+	grad := make([]T, p.outer*p.n)
+	switch r.op {
+	case ReduceOpLogSumExp:
+		rows, err := reduceRows(ctx, r.engine, p, input)
+		if err != nil {
+			return nil, err
+		}
+		x := rows.Data()
+		// d(lse)/dx_i = exp(x_i - lse), the softmax of the group. lse is
+		// recomputed in float64 rather than read back from the rounded
+		// output.
+		var row []float64
+		for o := range p.outer {
+			row = dtype.Float64s(row, x[o*p.n:(o+1)*p.n])
+			lse := logSumExp(row)
+			if math.IsInf(lse, -1) {
+				continue // every element is -Inf; no gradient
+			}
+			for i, v := range row {
+				w := math.Exp(v - lse)
+				grad[o*p.n+i] = ops.Mul(g[o], ops.FromFloat64(w))
+			}
+		}
+	case ReduceOpProd:
+		rows, err := reduceRows(ctx, r.engine, p, input)
+		if err != nil {
+			return nil, err
+		}
+		x := rows.Data()
+		// d(prod)/dx_i is the product of every other element of the row:
+		// the running prefix product times the suffix product.
+		suffix := make([]T, p.n+1)
+		for o := range p.outer {
+			row := x[o*p.n : (o+1)*p.n]
+			suffix[p.n] = ops.One()
+			for i := p.n - 1; i >= 0; i-- {
+				suffix[i] = ops.Mul(suffix[i+1], row[i])
+			}
+			prefix := ops.One()
+			for i, v := range row {
+				grad[o*p.n+i] = ops.Mul(g[o], ops.Mul(prefix, suffix[i+1]))
+				prefix = ops.Mul(prefix, v)
+			}
+		}
+	default:
+		for o, i := range r.arg {
+			grad[o*p.n+i] = g[o]
+		}
+	}
+
+	rows, err := tensor.New([]int{p.outer, p.n}, grad)
+	if err != nil {
+		return nil, err
+	}
+	dIn, err := unreduceRows(ctx, r.engine, p, rows)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{dIn}, nil
+}
+
+func newHostReduce[T tensor.Numeric](engine compute.Engine[T], op ReduceOp, attrs map[string]any) hostReduce[T] {
+	axes, keepDims, noop := reduceAttributes(attrs)
+	return hostReduce[T]{engine: engine, op: op, axes: axes, keepDims: keepDims, noopWithEmptyAxes: noop}
+}
+
+// BuildReduceMax constructs a ReduceMax node from attributes.
+func BuildReduceMax[T tensor.Numeric](
+	engine compute.Engine[T], _ numeric.Arithmetic[T], _ string,
+	_ map[string]*graph.Parameter[T], attrs map[string]any,
+) (graph.Node[T], error) {
+	return &ReduceMax[T]{newHostReduce(engine, ReduceOpMax, attrs)}, nil
+}
+
+// BuildReduceMin constructs a ReduceMin node from attributes.
+func BuildReduceMin[T tensor.Numeric](
+	engine compute.Engine[T], _ numeric.Arithmetic[T], _ string,
+	_ map[string]*graph.Parameter[T], attrs map[string]any,
+) (graph.Node[T], error) {
+	return &ReduceMin[T]{newHostReduce(engine, ReduceOpMin, attrs)}, nil
+}
+
+// BuildReduceProd constructs a ReduceProd node from attributes.
+func BuildReduceProd[T tensor.Numeric](
+	engine compute.Engine[T], _ numeric.Arithmetic[T], _ string,
+	_ map[string]*graph.Parameter[T], attrs map[string]any,
+) (graph.Node[T], error) {
+	return &ReduceProd[T]{newHostReduce(engine, ReduceOpProd, attrs)}, nil
+}
+
+// BuildReduceLogSumExp constructs a ReduceLogSumExp node from attributes.
+func BuildReduceLogSumExp[T tensor.Numeric](
+	engine compute.Engine[T], _ numeric.Arithmetic[T], _ string,
+	_ map[string]*graph.Parameter[T], attrs map[string]any,
+) (graph.Node[T], error) {
+	return &ReduceLogSumExp[T]{newHostReduce(engine, ReduceOpLogSumExp, attrs)}, nil
+}
+
+// NewReduceLogSumExp creates a ReduceLogSumExp over axes (all axes when
+// empty).
+func NewReduceLogSumExp[T tensor.Numeric](engine compute.Engine[T], axes []int, keepDims bool) *ReduceLogSumExp[T] {
+	return &ReduceLogSumExp[T]{hostReduce[T]{engine: engine, op: ReduceOpLogSumExp, axes: axes, keepDims: keepDims}}
+}
+
+var (
+	_ graph.Node[float32] = (*ReduceLogSumExp[float32])(nil)
+	_ graph.Node[float32] = (*ReduceMax[float32])(nil)
+	_ graph.Node[float32] = (*ReduceMin[float32])(nil)
+	_ graph.Node[float32] = (*ReduceProd[float32])(nil)
+)
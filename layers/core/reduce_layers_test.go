@@ -0,0 +1,259 @@
+package core
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func buildReduce(t *testing.T, build func() (graph.Node[float32], error)) graph.Node[float32] {
+	t.Helper()
+	node, err := build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestReduceMax(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	x := makeTensor(t, []int{2, 3}, []float32{1, 6, 3, 4, 2, 5})
+
+	node := buildReduce(t, func() (graph.Node[float32], error) {
+		return BuildReduceMax[float32](engine, nil, "rmax", nil, map[string]any{"axes": []int64{1}, "keepdims": int64(0)})
+	})
+	out, err := node.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Shape(), []int{2}) || !slices.Equal(out.Data(), []float32{6, 5}) {
+		t.Errorf("got %v %v, want [2] [6 5]", out.Shape(), out.Data())
+	}
+	grads, err := node.Backward(ctx, types.FullBackprop, makeTensor(t, []int{2}, []float32{10, 20}), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{0, 10, 0, 0, 0, 20}; !slices.Equal(grads[0].Data(), want) {
+		t.Errorf("grad = %v, want %v", grads[0].Data(), want)
+	}
+
+	// Axes from the second input; no axes reduces everything.
+	rm := &ReduceMax[float32]{hostReduce[float32]{engine: engine, op: ReduceOpMax}}
+	out, err = rm.Forward(ctx, x, makeTensor(t, []int{1}, []float32{0}))
+	if err != nil || !slices.Equal(out.Data(), []float32{4, 6, 5}) {
+		t.Errorf("axes input: got %v, %v; want [4 6 5]", out, err)
+	}
+	out, err = rm.Forward(ctx, x)
+	if err != nil || len(out.Shape()) != 0 || out.Data()[0] != 6 {
+		t.Errorf("all axes: got %v, %v; want scalar 6", out, err)
+	}
+	if rm.OpType() != "ReduceMax" || rm.Parameters() != nil {
+		t.Errorf("OpType = %q", rm.OpType())
+	}
+}
+
+func TestReduceMin_BackwardTiesAndAxes(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	// [2, 2, 2]; reduce axes [0, 2], leaving one group per middle index.
+	x := makeTensor(t, []int{2, 2, 2}, []float32{
+		3, 1, 4, 1,
+		5, 1, 2, 6,
+	})
+	node := buildReduce(t, func() (graph.Node[float32], error) {
+		return BuildReduceMin[float32](engine, nil, "rmin", nil, map[string]any{"axes": []int64{0, 2}})
+	})
+	out, err := node.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Shape(), []int{1, 2, 1}) || !slices.Equal(out.Data(), []float32{1, 1}) {
+		t.Fatalf("got %v %v, want [1 2 1] [1 1]", out.Shape(), out.Data())
+	}
+	grads, err := node.Backward(ctx, types.FullBackprop, makeTensor(t, []int{1, 2, 1}, []float32{7, 8}), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Group 0 is x[:, 0, :] = {3, 1, 5, 1}; the first 1 (x[0,0,1]) wins.
+	// Group 1 is x[:, 1, :] = {4, 1, 2, 6}; its minimum is x[0,1,1].
+	want := []float32{0, 7, 0, 8, 0, 0, 0, 0}
+	if !slices.Equal(grads[0].Shape(), []int{2, 2, 2}) || !slices.Equal(grads[0].Data(), want) {
+		t.Errorf("grad = %v %v, want [2 2 2] %v", grads[0].Shape(), grads[0].Data(), want)
+	}
+}
+
+func TestReduceProd_Backward(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		data []float32
+		want []float32 // gradient for dOut = 1 per row
+	}{
+		{"no zeros", []float32{2, 3, 4, 0.5, 2, -1}, []float32{12, 8, 6, -2, -0.5, 1}},
+		{"one zero", []float32{2, 0, 4, 1, 1, 1}, []float32{0, 8, 0, 1, 1, 1}},
+		{"two zeros", []float32{0, 0, 4, 3, 2, 1}, []float32{0, 0, 0, 2, 3, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := buildReduce(t, func() (graph.Node[float32], error) {
+				return BuildReduceProd[float32](engine, nil, "rprod", nil, map[string]any{"axes": []int64{-1}, "keepdims": int64(0)})
+			})
+			x := makeTensor(t, []int{2, 3}, tt.data)
+			if _, err := node.Forward(ctx, x); err != nil {
+				t.Fatal(err)
+			}
+			grads, err := node.Backward(ctx, types.FullBackprop, makeTensor(t, []int{2}, []float32{1, 1}), x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range grads[0].Data() {
+				if math.IsNaN(float64(v)) || math.Abs(float64(v-tt.want[i])) > 1e-6 {
+					t.Errorf("grad = %v, want %v", grads[0].Data(), tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestReduceProd_GradientCheck(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	data := []float32{0.5, 1.5, -2, 1.25, 0.75, 3, -1, 2}
+	node := buildReduce(t, func() (graph.Node[float32], error) {
+		return BuildReduceProd[float32](engine, nil, "rprod", nil, map[string]any{"axes": []int64{0, 2}, "keepdims": int64(0)})
+	})
+	x := makeTensor(t, []int{2, 2, 2}, data)
+	if _, err := node.Forward(ctx, x); err != nil {
+		t.Fatal(err)
+	}
+	dOut := makeTensor(t, []int{2}, []float32{1, -2})
+	grads, err := node.Backward(ctx, types.FullBackprop, dOut, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// loss = sum(dOut * prod(x)); compare against central differences.
+	loss := func(d []float32) float64 {
+		out, err := node.Forward(ctx, makeTensor(t, []int{2, 2, 2}, d))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return float64(out.Data()[0]) - 2*float64(out.Data()[1])
+	}
+	const h = 1e-2
+	for i := range data {
+		up, down := slices.Clone(data), slices.Clone(data)
+		up[i] += h
+		down[i] -= h
+		numeric := (loss(up) - loss(down)) / (2 * h)
+		if got := float64(grads[0].Data()[i]); math.Abs(got-numeric) > 1e-2 {
+			t.Errorf("grad[%d] = %v, numeric %v", i, got, numeric)
+		}
+	}
+}
+
+func TestHostReduce_Identity(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	node := buildReduce(t, func() (graph.Node[float32], error) {
+		return BuildReduceMin[float32](engine, nil, "rmin", nil, map[string]any{"noop_with_empty_axes": int64(1)})
+	})
+	x := makeTensor(t, []int{2}, []float32{3, 1})
+	out, err := node.Forward(ctx, x)
+	if err != nil || out != x {
+		t.Fatalf("noop Forward = %v, %v", out, err)
+	}
+	grads, err := node.Backward(ctx, types.FullBackprop, x, x)
+	if err != nil || !slices.Equal(grads[0].Data(), []float32{3, 1}) {
+		t.Errorf("noop Backward = %v, %v", grads, err)
+	}
+
+	if _, err := node.Backward(ctx, types.FullBackprop, x); err == nil {
+		t.Error("Backward without the input should error")
+	}
+	if _, err := node.Forward(ctx); err == nil {
+		t.Error("Forward without inputs should error")
+	}
+}
+
+func TestReduceLogSumExp(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	inf := float32(math.Inf(-1))
+	x := makeTensor(t, []int{3, 2}, []float32{
+		1000, 1000, // exp overflows float32 without the shift
+		-1000, 0,
+		inf, inf,
+	})
+	lse := NewReduceLogSumExp(engine, []int{-1}, false)
+	out, err := lse.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1000 + math.Ln2, 0, math.Inf(-1)}
+	for i, v := range out.Data() {
+		if !(math.IsInf(want[i], -1) && math.IsInf(float64(v), -1)) && math.Abs(float64(v)-want[i]) > 1e-4 {
+			t.Errorf("lse = %v, want %v", out.Data(), want)
+			break
+		}
+	}
+
+	grads, err := lse.Backward(ctx, types.FullBackprop, makeTensor(t, []int{3}, []float32{2, 1, 1}), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The gradient is dOut times the softmax of each row; an all -Inf row
+	// has none.
+	wantGrad := []float32{1, 1, 0, 1, 0, 0}
+	for i, v := range grads[0].Data() {
+		if math.IsNaN(float64(v)) || math.Abs(float64(v-wantGrad[i])) > 1e-6 {
+			t.Errorf("grad = %v, want %v", grads[0].Data(), wantGrad)
+			break
+		}
+	}
+	if lse.OpType() != "ReduceLogSumExp" {
+		t.Errorf("OpType = %q", lse.OpType())
+	}
+}
+
+func TestReduceLogSumExp_GradientCheck(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	data := []float32{0.5, -1, 2, 0.25, 1, -0.5}
+	node := buildReduce(t, func() (graph.Node[float32], error) {
+		return BuildReduceLogSumExp[float32](engine, nil, "lse", nil, map[string]any{"axes": []int64{0}, "keepdims": int64(1)})
+	})
+	x := makeTensor(t, []int{2, 3}, data)
+	if _, err := node.Forward(ctx, x); err != nil {
+		t.Fatal(err)
+	}
+	grads, err := node.Backward(ctx, types.FullBackprop, makeTensor(t, []int{1, 3}, []float32{1, 2, 3}), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loss := func(d []float32) float64 {
+		out, err := node.Forward(ctx, makeTensor(t, []int{2, 3}, d))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := out.Data()
+		return float64(o[0]) + 2*float64(o[1]) + 3*float64(o[2])
+	}
+	const h = 1e-2
+	for i := range data {
+		up, down := slices.Clone(data), slices.Clone(data)
+		up[i] += h
+		down[i] -= h
+		numeric := (loss(up) - loss(down)) / (2 * h)
+		if got := float64(grads[0].Data()[i]); math.Abs(got-numeric) > 1e-2 {
+			t.Errorf("grad[%d] = %v, numeric %v", i, got, numeric)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
@@ -12,10 +13,15 @@ import (
 )
 
 // ReduceMean reduces a tensor by computing the mean along specified axes.
+// As in ONNX, no axes means every axis unless noop_with_empty_axes is set,
+// in which case the input passes through unchanged.
 type ReduceMean[T tensor.Numeric] struct {
 	engine   compute.Engine[T]
 	axes     []int
 	keepDims bool
+	// noopWithEmptyAxes passes the input through when no axes are given
+	// instead of reducing to a scalar.
+	noopWithEmptyAxes bool
 }
 
 func (r *ReduceMean[T]) OpType() string                  { return "ReduceMean" }
@@ -23,7 +29,7 @@ func (r *ReduceMean[T]) OutputShape() []int               { return nil }
 func (r *ReduceMean[T]) Parameters() []*graph.Parameter[T] { return nil }
 
 func (r *ReduceMean[T]) Attributes() map[string]any {
-	return map[string]any{"axes": r.axes, "keepdims": r.keepDims}
+	return map[string]any{"axes": r.axes, "keepdims": r.keepDims, "noop_with_empty_axes": r.noopWithEmptyAxes}
 }
 
 func (r *ReduceMean[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
@@ -33,41 +39,13 @@ func (r *ReduceMean[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNum
 
 	result := inputs[0]
 
-	// ONNX ReduceMean opset 18+: axes come from second input tensor.
-	axes := r.axes
-	if len(axes) == 0 && len(inputs) >= 2 {
-		axesData := inputs[1].Data()
-		axes = make([]int, len(axesData))
-		for i, v := range axesData {
-			axes[i] = int(v)
-		}
-	}
-
-	// Reduce along each axis (from highest to lowest to preserve indices).
-	sorted := make([]int, len(axes))
-	copy(sorted, axes)
-	for i := 0; i < len(sorted); i++ {
-		for j := i + 1; j < len(sorted); j++ {
-			if sorted[j] > sorted[i] {
-				sorted[i], sorted[j] = sorted[j], sorted[i]
-			}
-		}
+	axes := reduceInputAxes(r.axes, inputs)
+	// ONNX reduces every axis when none are given, unless
+	// noop_with_empty_axes is set.
+	if len(axes) == 0 && r.noopWithEmptyAxes {
+		return result, nil
 	}
-
-	for _, axis := range sorted {
-		// Normalize negative axes.
-		ndim := len(result.Shape())
-		if axis < 0 {
-			axis += ndim
-		}
-		var err error
-		result, err = r.engine.ReduceMean(ctx, result, axis, r.keepDims)
-		if err != nil {
-			return nil, fmt.Errorf("ReduceMean axis %d: %w", axis, err)
-		}
-	}
-
-	return result, nil
+	return ReduceAxes(ctx, r.engine, ReduceOpMean, result, axes, r.keepDims)
 }
 
 func (r *ReduceMean[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
@@ -79,7 +57,27 @@ func BuildReduceMean[T tensor.Numeric](
 	engine compute.Engine[T], _ numeric.Arithmetic[T], _ string,
 	_ map[string]*graph.Parameter[T], attrs map[string]any,
 ) (graph.Node[T], error) {
-	var axes []int
+	axes, keepDims, noop := reduceAttributes(attrs)
+	return &ReduceMean[T]{engine: engine, axes: axes, keepDims: keepDims, noopWithEmptyAxes: noop}, nil
+}
+
+// reduceInputAxes returns the configured axes, or when there are none the
+// axes from the optional second input (ONNX opset 18+).
+func reduceInputAxes[T tensor.Numeric](axes []int, inputs []*tensor.TensorNumeric[T]) []int {
+	if len(axes) > 0 || len(inputs) < 2 {
+		return axes
+	}
+	axesData := inputs[1].Data()
+	axes = make([]int, len(axesData))
+	for i, v := range axesData {
+		axes[i] = int(v)
+	}
+	return axes
+}
+
+// reduceAttributes parses the axes, keepdims and noop_with_empty_axes
+// attributes shared by the ONNX Reduce* ops.
+func reduceAttributes(attrs map[string]any) (axes []int, keepDims, noopWithEmptyAxes bool) {
 	if a, ok := attrs["axes"]; ok {
 		switch v := a.(type) {
 		case []any:
@@ -92,10 +90,12 @@ func BuildReduceMean[T tensor.Numeric](
 			for i, val := range v {
 				axes[i] = int(val)
 			}
+		case []int:
+			axes = slices.Clone(v)
 		}
 	}
 
-	keepDims := true
+	keepDims = true
 	if kd, ok := attrs["keepdims"]; ok {
 		switch v := kd.(type) {
 		case int64:
@@ -105,7 +105,15 @@ func BuildReduceMean[T tensor.Numeric](
 		}
 	}
 
-	return &ReduceMean[T]{engine: engine, axes: axes, keepDims: keepDims}, nil
+	if v, ok := attrs["noop_with_empty_axes"]; ok {
+		switch n := v.(type) {
+		case int64:
+			noopWithEmptyAxes = n != 0
+		case bool:
+			noopWithEmptyAxes = n
+		}
+	}
+	return axes, keepDims, noopWithEmptyAxes
 }
 
 var _ graph.Node[float32] = (*ReduceMean[float32])(nil)
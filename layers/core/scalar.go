@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/zerfoo/internal/axisutil"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// NewScalar returns a rank-0 tensor (shape []) holding v. Rank-0 tensors
+// broadcast against any shape in element-wise engine ops.
+func NewScalar[T tensor.Numeric](v T) (*tensor.TensorNumeric[T], error) {
+	return tensor.New([]int{}, []T{v})
+}
+
+// ScalarValue returns the only element of t, which may have any rank as
+// long as it holds exactly one element (shape [], [1], [1, 1], ...).
+func ScalarValue[T tensor.Numeric](t *tensor.TensorNumeric[T]) (T, error) {
+	var zero T
+	if t == nil {
+		return zero, fmt.Errorf("scalar value: nil tensor")
+	}
+	if t.Size() != 1 {
+		return zero, fmt.Errorf("scalar value: tensor of shape %v has %d elements", t.Shape(), t.Size())
+	}
+	return t.Data()[0], nil
+}
+
+// ReductionAxes normalizes the axes of a reduction over a tensor of the
+// given rank. Negative axes count from the end, and no axes means every
+// axis. The result is deduplicated and sorted in descending order, so
+// reducing one axis at a time never shifts an axis still to be reduced.
+func ReductionAxes(rank int, axes []int) ([]int, error) {
+	return axisutil.Reduction(axes, rank)
+}
+
+// ReduceFunc is a single-axis engine reduction such as
+// compute.Engine.ReduceSum or compute.Engine.ReduceMean.
+type ReduceFunc[T tensor.Numeric] func(ctx context.Context, a *tensor.TensorNumeric[T], axis int, keepDims bool, dst ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error)
+
+// Reduce applies reduce over axes (all axes when empty). Reducing every axis
+// without keepDims yields a rank-0 tensor, and reducing a rank-0 input
+// returns its value unchanged, so reductions compose at any rank.
+func Reduce[T tensor.Numeric](ctx context.Context, reduce ReduceFunc[T], input *tensor.TensorNumeric[T], axes []int, keepDims bool) (*tensor.TensorNumeric[T], error) {
+	rank := len(input.Shape())
+	if rank == 0 {
+		if len(axes) > 0 && !slices.Equal(axes, []int{0}) && !slices.Equal(axes, []int{-1}) {
+			return nil, fmt.Errorf("axes %v out of range for rank 0", axes)
+		}
+		return tensor.New([]int{}, slices.Clone(input.Data()))
+	}
+	norm, err := ReductionAxes(rank, axes)
+	if err != nil {
+		return nil, err
+	}
+	result := input
+	for _, axis := range norm {
+		result, err = reduce(ctx, result, axis, keepDims)
+		if err != nil {
+			return nil, fmt.Errorf("reduce axis %d: %w", axis, err)
+		}
+	}
+	if !keepDims && len(norm) == rank && len(result.Shape()) != 0 {
+		// Engines report a full reduction as shape [1]; make it a scalar.
+		return tensor.NewWithStorage[T]([]int{}, result.GetStorage())
+	}
+	return result, nil
+}
@@ -0,0 +1,332 @@
+package core
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestNewScalar(t *testing.T) {
+	s, err := NewScalar[float32](2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Shape()) != 0 || s.Size() != 1 {
+		t.Fatalf("shape = %v, size = %d; want [] and 1", s.Shape(), s.Size())
+	}
+	v, err := ScalarValue(s)
+	if err != nil || v != 2.5 {
+		t.Errorf("ScalarValue = %v, %v; want 2.5", v, err)
+	}
+
+	one := makeTensor(t, []int{1, 1}, []float32{7})
+	if v, err := ScalarValue(one); err != nil || v != 7 {
+		t.Errorf("ScalarValue([1,1]) = %v, %v; want 7", v, err)
+	}
+	if _, err := ScalarValue(makeTensor(t, []int{2}, []float32{1, 2})); err == nil {
+		t.Error("ScalarValue of a 2-element tensor should error")
+	}
+}
+
+func TestReductionAxes(t *testing.T) {
+	tests := []struct {
+		name    string
+		rank    int
+		axes    []int
+		want    []int
+		wantErr bool
+	}{
+		{"all", 3, nil, []int{2, 1, 0}, false},
+		{"negative", 3, []int{-1, 0}, []int{2, 0}, false},
+		{"dedupe", 2, []int{1, -1}, []int{1}, false},
+		{"rank 0", 0, nil, []int{}, false},
+		{"out of range", 2, []int{2}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReductionAxes(tt.rank, tt.axes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("ReductionAxes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReduce_ToScalar(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	x := makeTensor(t, []int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+
+	tests := []struct {
+		name      string
+		axes      []int
+		keepDims  bool
+		wantShape []int
+		wantData  []float32
+	}{
+		{"all axes", nil, false, []int{}, []float32{21}},
+		{"all axes keepdims", nil, true, []int{1, 1}, []float32{21}},
+		{"both axes listed", []int{1, 0}, false, []int{}, []float32{21}},
+		{"one axis", []int{-1}, false, []int{2}, []float32{6, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Reduce(ctx, engine.Sum, x, tt.axes, tt.keepDims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(out.Shape(), tt.wantShape) || !slices.Equal(out.Data(), tt.wantData) {
+				t.Errorf("got %v %v, want %v %v", out.Shape(), out.Data(), tt.wantShape, tt.wantData)
+			}
+		})
+	}
+
+	s, _ := NewScalar[float32](4)
+	out, err := Reduce(ctx, engine.ReduceMean, s, nil, false)
+	if err != nil || len(out.Shape()) != 0 || out.Data()[0] != 4 {
+		t.Errorf("Reduce(scalar) = %v, %v; want scalar 4", out, err)
+	}
+	if _, err := Reduce(ctx, engine.ReduceMean, s, []int{1}, false); err == nil {
+		t.Error("Reduce(scalar, axis 1) should error")
+	}
+}
+
+// TestEngineOps_Rank0 runs every element-wise engine op on rank-0 operands,
+// alone and broadcast against a [2, 2] tensor in both operand orders.
+func TestEngineOps_Rank0(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	scalar := func(v float32) *tensor.TensorNumeric[float32] {
+		s, err := NewScalar(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	type unaryOp func(context.Context, *tensor.TensorNumeric[float32], ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error)
+
+	unary := []struct {
+		name string
+		op   unaryOp
+		want float64
+	}{
+		{"Exp", engine.Exp, math.Exp(4)},
+		{"Log", engine.Log, math.Log(4)},
+		{"Sin", engine.Sin, math.Sin(4)},
+		{"Cos", engine.Cos, math.Cos(4)},
+		{"Tanh", engine.Tanh, math.Tanh(4)},
+		{"Sqrt", engine.Sqrt, 2},
+		{"Rsqrt", engine.Rsqrt, 0.5},
+		{"AddScalar", func(ctx context.Context, a *tensor.TensorNumeric[float32], dst ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+			return engine.AddScalar(ctx, a, 1, dst...)
+		}, 5},
+		{"MulScalar", func(ctx context.Context, a *tensor.TensorNumeric[float32], dst ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+			return engine.MulScalar(ctx, a, 3, dst...)
+		}, 12},
+		{"DivScalar", func(ctx context.Context, a *tensor.TensorNumeric[float32], dst ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+			return engine.DivScalar(ctx, a, 2, dst...)
+		}, 2},
+		{"Softmax", func(ctx context.Context, a *tensor.TensorNumeric[float32], dst ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+			return engine.Softmax(ctx, a, -1, dst...)
+		}, 1},
+		{"Reshape", func(ctx context.Context, a *tensor.TensorNumeric[float32], dst ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+			return engine.Reshape(ctx, a, []int{}, dst...)
+		}, 4},
+	}
+	for _, tt := range unary {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.op(ctx, scalar(4))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out.Shape()) != 0 {
+				t.Errorf("shape = %v, want []", out.Shape())
+			}
+			if got := float64(out.Data()[0]); math.Abs(got-tt.want) > 1e-5 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	binary := []struct {
+		name string
+		op   BinaryOp[float32]
+		fn   func(a, b float32) float32
+	}{
+		{"Add", engine.Add, func(a, b float32) float32 { return a + b }},
+		{"Sub", engine.Sub, func(a, b float32) float32 { return a - b }},
+		{"Mul", engine.Mul, func(a, b float32) float32 { return a * b }},
+		{"Div", engine.Div, func(a, b float32) float32 { return a / b }},
+		{"Pow", engine.Pow, func(a, b float32) float32 { return float32(math.Pow(float64(a), float64(b))) }},
+	}
+	m := []float32{1, 2, 3, 4}
+	for _, tt := range binary {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.op(ctx, scalar(2), scalar(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out.Shape()) != 0 || out.Data()[0] != tt.fn(2, 3) {
+				t.Errorf("scalar ⊕ scalar = %v %v, want [] %v", out.Shape(), out.Data(), tt.fn(2, 3))
+			}
+
+			for _, scalarFirst := range []bool{true, false} {
+				a, b := scalar(2), makeTensor(t, []int{2, 2}, m)
+				if !scalarFirst {
+					a, b = b, a
+				}
+				out, err := tt.op(ctx, a, b)
+				if err != nil {
+					t.Fatalf("scalarFirst=%v: %v", scalarFirst, err)
+				}
+				if !slices.Equal(out.Shape(), []int{2, 2}) {
+					t.Fatalf("scalarFirst=%v: shape = %v, want [2 2]", scalarFirst, out.Shape())
+				}
+				for i, v := range out.Data() {
+					want := tt.fn(2, m[i])
+					if !scalarFirst {
+						want = tt.fn(m[i], 2)
+					}
+					if math.Abs(float64(v-want)) > 1e-5 {
+						t.Errorf("scalarFirst=%v: data[%d] = %v, want %v", scalarFirst, i, v, want)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("Fill", func(t *testing.T) {
+		s := scalar(0)
+		if err := engine.Fill(ctx, s, 9); err != nil || s.Data()[0] != 9 {
+			t.Errorf("Fill = %v, %v", s.Data(), err)
+		}
+	})
+	t.Run("Copy", func(t *testing.T) {
+		dst := scalar(0)
+		if err := engine.Copy(ctx, dst, scalar(6)); err != nil || dst.Data()[0] != 6 {
+			t.Errorf("Copy = %v, %v", dst.Data(), err)
+		}
+	})
+	t.Run("Zero", func(t *testing.T) {
+		s := scalar(5)
+		if err := engine.Zero(ctx, s); err != nil || s.Data()[0] != 0 {
+			t.Errorf("Zero = %v, %v", s.Data(), err)
+		}
+	})
+}
+
+// TestReduceMean_EmptyAxes pins the ONNX semantics of empty axes: reduce
+// every axis by default, pass the input through with noop_with_empty_axes.
+// The choice survives rebuilding a node from its Attributes.
+func TestReduceMean_EmptyAxes(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	x := makeTensor(t, []int{2, 2}, []float32{1, 2, 3, 4})
+	axes := makeTensor(t, []int{1}, []float32{1})
+
+	tests := []struct {
+		name      string
+		attrs     map[string]any
+		inputs    []*tensor.TensorNumeric[float32]
+		wantShape []int
+		wantData  []float32
+	}{
+		{"default reduces all", map[string]any{"keepdims": int64(0)}, nil, []int{}, []float32{2.5}},
+		{"default keepdims", nil, nil, []int{1, 1}, []float32{2.5}},
+		{"noop", map[string]any{"noop_with_empty_axes": int64(1)}, nil, []int{2, 2}, []float32{1, 2, 3, 4}},
+		{"noop with axes input", map[string]any{"noop_with_empty_axes": int64(1), "keepdims": int64(0)}, []*tensor.TensorNumeric[float32]{axes}, []int{2}, []float32{1.5, 3.5}},
+		{"attribute axes", map[string]any{"axes": []int64{0}, "noop_with_empty_axes": true}, nil, []int{1, 2}, []float32{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := BuildReduceMean[float32](engine, nil, "rm", nil, tt.attrs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rebuilt, err := BuildReduceMean[float32](engine, nil, "rm", nil, node.Attributes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, n := range map[string]graph.Node[float32]{"built": node, "rebuilt": rebuilt} {
+				out, err := n.Forward(ctx, append([]*tensor.TensorNumeric[float32]{x}, tt.inputs...)...)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if !slices.Equal(out.Shape(), tt.wantShape) || !slices.Equal(out.Data(), tt.wantData) {
+					t.Errorf("%s: got %v %v, want %v %v", name, out.Shape(), out.Data(), tt.wantShape, tt.wantData)
+				}
+			}
+		})
+	}
+
+	node, err := BuildReduceMax[float32](engine, nil, "rmax", nil, map[string]any{"noop_with_empty_axes": int64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := BuildReduceMax[float32](engine, nil, "rmax", nil, node.Attributes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := rebuilt.Forward(ctx, x); err != nil || !slices.Equal(out.Shape(), []int{2, 2}) {
+		t.Errorf("rebuilt ReduceMax noop = %v, %v; want [2 2] passthrough", out, err)
+	}
+}
+
+// TestReduceAxes_Rank0 reduces a scalar with every op through ReduceAxes,
+// and with every single-axis engine reduction through Reduce. The engines
+// reject axis 0 on rank 0 and return [1] without keepDims, so both helpers
+// must handle rank 0 before calling them.
+func TestReduceAxes_Rank0(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	reduces := map[string]ReduceFunc[float32]{
+		"Sum":        engine.Sum,
+		"ReduceSum":  engine.ReduceSum,
+		"ReduceMean": engine.ReduceMean,
+		"ReduceMax":  engine.ReduceMax,
+	}
+	for _, axes := range [][]int{nil, {0}, {-1}} {
+		for _, keepDims := range []bool{false, true} {
+			for op := ReduceOpSum; op <= ReduceOpLogSumExp; op++ {
+				s, _ := NewScalar[float32](4)
+				out, err := ReduceAxes(ctx, engine, op, s, axes, keepDims)
+				if err != nil {
+					t.Errorf("%v axes=%v keepDims=%v: %v", op, axes, keepDims, err)
+					continue
+				}
+				if len(out.Shape()) != 0 || out.Data()[0] != 4 {
+					t.Errorf("%v axes=%v keepDims=%v = %v %v, want scalar 4", op, axes, keepDims, out.Shape(), out.Data())
+				}
+			}
+			for name, reduce := range reduces {
+				s, _ := NewScalar[float32](4)
+				out, err := Reduce(ctx, reduce, s, axes, keepDims)
+				if err != nil {
+					t.Errorf("%s axes=%v keepDims=%v: %v", name, axes, keepDims, err)
+					continue
+				}
+				if len(out.Shape()) != 0 || out.Data()[0] != 4 {
+					t.Errorf("%s axes=%v keepDims=%v = %v %v, want scalar 4", name, axes, keepDims, out.Shape(), out.Data())
+				}
+			}
+		}
+	}
+
+	s, _ := NewScalar[float32](4)
+	for _, axes := range [][]int{{1}, {0, 1}} {
+		if _, err := ReduceAxes(ctx, engine, ReduceOpMean, s, axes, false); err == nil {
+			t.Errorf("ReduceAxes(scalar, %v) should error", axes)
+		}
+	}
+	out, err := engine.Transpose(ctx, s, []int{})
+	if err != nil || len(out.Shape()) != 0 || out.Data()[0] != 4 {
+		t.Errorf("Transpose(scalar, []) = %v, %v; want scalar 4", out, err)
+	}
+}
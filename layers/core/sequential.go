@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// Sequential chains single-input layers into one node: each layer's output
+// is the next layer's input. It aggregates the layers' parameters, runs
+// Backward through the chain in reverse, and forwards the save-for-backward
+// Saver and training mode to the layers that use them, so a stack such as
+// Dense, ReLU, Dropout, Dense can be built declaratively and added to a
+// graph as a single node.
+type Sequential[T tensor.Numeric] struct {
+	layers []graph.Node[T]
+
+	// inputs holds each layer's input from the most recent Forward; layer
+	// Backward methods take their forward inputs. The intermediate ones are
+	// registered with the save-for-backward contract (ztensor ADR 006).
+	inputs [][]*tensor.TensorNumeric[T]
+	saver  graph.Saver[T] // wired by graph Builder (graph.SaverAware); nil outside a Graph
+}
+
+// NewSequential creates a Sequential running layers in order. At least one
+// layer is required and none may be nil.
+func NewSequential[T tensor.Numeric](layers ...graph.Node[T]) (*Sequential[T], error) {
+	if len(layers) == 0 {
+		return nil, errors.New("Sequential requires at least one layer")
+	}
+	for i, l := range layers {
+		if l == nil {
+			return nil, fmt.Errorf("Sequential: layer %d is nil", i)
+		}
+	}
+	return &Sequential[T]{layers: append([]graph.Node[T](nil), layers...)}, nil
+}
+
+// Layers returns the chained layers in order.
+func (s *Sequential[T]) Layers() []graph.Node[T] {
+	return s.layers
+}
+
+// SetSaver implements graph.SaverAware, fanning the Saver into the layers
+// that use the contract.
+func (s *Sequential[T]) SetSaver(sv graph.Saver[T]) {
+	s.saver = sv
+	for _, l := range s.layers {
+		if sa, ok := l.(graph.SaverAware[T]); ok {
+			sa.SetSaver(sv)
+		}
+	}
+}
+
+// SetTraining switches the layers that behave differently during training,
+// such as Dropout, so regularization.SetTrainingMode reaches them.
+func (s *Sequential[T]) SetTraining(training bool) {
+	for _, l := range s.layers {
+		if ms, ok := l.(regularization.TrainingModeSetter); ok {
+			ms.SetTraining(training)
+		}
+	}
+}
+
+// OpType returns the operation type of the layer.
+func (s *Sequential[T]) OpType() string {
+	return "Sequential"
+}
+
+// Attributes returns the op types of the chained layers.
+func (s *Sequential[T]) Attributes() map[string]interface{} {
+	ops := make([]string, len(s.layers))
+	for i, l := range s.layers {
+		ops[i] = l.OpType()
+	}
+	return map[string]interface{}{"layers": ops}
+}
+
+// OutputShape returns the output shape of the last layer.
+func (s *Sequential[T]) OutputShape() []int {
+	return s.layers[len(s.layers)-1].OutputShape()
+}
+
+// Parameters returns the parameters of every layer, in layer order.
+func (s *Sequential[T]) Parameters() []*graph.Parameter[T] {
+	var params []*graph.Parameter[T]
+	for _, l := range s.layers {
+		params = append(params, l.Parameters()...)
+	}
+	return params
+}
+
+// Forward runs the layers in order. The first layer receives all inputs;
+// every later layer receives the previous layer's output.
+func (s *Sequential[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	s.inputs = s.inputs[:0]
+	x := inputs
+	for i, l := range s.layers {
+		s.inputs = append(s.inputs, x)
+		out, err := l.Forward(ctx, x...)
+		if err != nil {
+			return nil, fmt.Errorf("Sequential: layer %d (%s) forward: %w", i, l.OpType(), err)
+		}
+		if i < len(s.layers)-1 && s.saver != nil {
+			s.saver.SaveForBackward(out)
+		}
+		x = []*tensor.TensorNumeric[T]{out}
+	}
+	return x[0], nil
+}
+
+// Backward propagates dOut through the layers in reverse and returns the
+// gradients with respect to the first layer's inputs.
+func (s *Sequential[T]) Backward(ctx context.Context, mode types.BackwardMode, dOut *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(s.inputs) != len(s.layers) {
+		return nil, errors.New("Sequential: Backward called before Forward")
+	}
+	grad := dOut
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		grads, err := s.layers[i].Backward(ctx, mode, grad, s.inputs[i]...)
+		if err != nil {
+			return nil, fmt.Errorf("Sequential: layer %d (%s) backward: %w", i, s.layers[i].OpType(), err)
+		}
+		if i == 0 {
+			return grads, nil
+		}
+		if len(grads) == 0 {
+			return nil, fmt.Errorf("Sequential: layer %d (%s) returned no input gradient", i, s.layers[i].OpType())
+		}
+		grad = grads[0]
+	}
+	return nil, nil // unreachable: NewSequential requires a layer
+}
+
+// Statically assert that Sequential implements graph.Node, participates in
+// the save-for-backward contract, and supports mode switching.
+var (
+	_ graph.Node[float32]               = (*Sequential[float32])(nil)
+	_ graph.SaverAware[float32]         = (*Sequential[float32])(nil)
+	_ regularization.TrainingModeSetter = (*Sequential[float32])(nil)
+)
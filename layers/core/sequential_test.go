@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/layers/activations"
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func newTestMLP(t *testing.T) (*Sequential[float32], []graph.Node[float32], compute.Engine[float32]) {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	d1, err := NewDense[float32]("d1", engine, ops, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := NewDense[float32]("d2", engine, ops, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers := []graph.Node[float32]{d1, activations.NewReLU[float32](engine, ops), d2}
+	seq, err := NewSequential(layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return seq, layers, engine
+}
+
+func TestSequential_ForwardBackward(t *testing.T) {
+	ctx := context.Background()
+	seq, layers, _ := newTestMLP(t)
+
+	if got := len(seq.Parameters()); got != 4 {
+		t.Errorf("len(Parameters()) = %d, want 4", got)
+	}
+	if ops := seq.Attributes()["layers"].([]string); len(ops) != 3 || ops[2] != layers[2].OpType() {
+		t.Errorf("Attributes()[layers] = %v", ops)
+	}
+
+	x, err := tensor.New([]int{4, 2}, []float32{1, -2, 0.5, 3, -1, -1, 2, 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := seq.Forward(ctx, x)
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	// Run the layers by hand for the expected output and input gradient.
+	h1, err := layers[0].Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := layers[1].Forward(ctx, h1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := layers[2].Forward(ctx, h2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "output", out.Data(), want.Data())
+	if got, want := seq.OutputShape(), layers[2].OutputShape(); len(got) != len(want) || got[len(got)-1] != 1 {
+		t.Errorf("OutputShape() = %v, want %v", got, want)
+	}
+
+	dOut, err := tensor.New([]int{4, 1}, []float32{1, 1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g2, err := layers[2].Backward(ctx, types.FullBackprop, dOut, h2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1, err := layers[1].Backward(ctx, types.FullBackprop, g2[0], h1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGrad, err := layers[0].Backward(ctx, types.FullBackprop, g1[0], x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := seq.Forward(ctx, x); err != nil {
+		t.Fatal(err)
+	}
+	grads, err := seq.Backward(ctx, types.FullBackprop, dOut, x)
+	if err != nil {
+		t.Fatalf("Backward() error = %v", err)
+	}
+	if len(grads) != 1 {
+		t.Fatalf("Backward() returned %d gradients, want 1", len(grads))
+	}
+	assertClose(t, "input gradient", grads[0].Data(), wantGrad[0].Data())
+}
+
+func assertClose(t *testing.T, what string, got, want []float32) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len %d, want %d", what, len(got), len(want))
+	}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > 1e-5 {
+			t.Fatalf("%s[%d] = %v, want %v", what, i, got[i], want[i])
+		}
+	}
+}
+
+func TestSequential_InGraph(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	d1, err := NewDense[float32]("d1", engine, ops, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropout := regularization.NewDropout(engine, ops, float32(0.5))
+	seq, err := NewSequential[float32](d1, dropout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := graph.NewBuilder[float32](engine)
+	g, err := b.Build(b.AddNode(seq, b.Input([]int{1, 2})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(g.Parameters()); got != 2 {
+		t.Errorf("graph has %d parameters, want 2", got)
+	}
+
+	if n := regularization.SetTrainingMode(g, true); n != 1 || !dropout.IsTraining() {
+		t.Errorf("SetTrainingMode switched %d nodes, nested dropout training = %v", n, dropout.IsTraining())
+	}
+	regularization.SetTrainingMode(g, false)
+
+	x, err := tensor.New([]int{1, 2}, []float32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Forward(ctx, x); err != nil {
+		t.Fatal(err)
+	}
+	dOut, err := tensor.New([]int{1, 3}, []float32{1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Backward(ctx, types.FullBackprop, dOut); err != nil {
+		t.Fatalf("graph Backward() error = %v", err)
+	}
+	for _, p := range g.Parameters() {
+		if p.Gradient == nil {
+			t.Errorf("parameter %s has no gradient", p.Name)
+		}
+	}
+}
+
+func TestSequential_Errors(t *testing.T) {
+	if _, err := NewSequential[float32](); err == nil {
+		t.Error("NewSequential() with no layers succeeded")
+	}
+	if _, err := NewSequential[float32](nil); err == nil {
+		t.Error("NewSequential(nil) succeeded")
+	}
+	seq, _, _ := newTestMLP(t)
+	dOut, _ := tensor.New([]int{1, 1}, []float32{1})
+	if _, err := seq.Backward(context.Background(), types.FullBackprop, dOut); err == nil {
+		t.Error("Backward before Forward succeeded")
+	}
+}
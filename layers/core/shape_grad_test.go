@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func TestSqueeze_Backward(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	x := makeTensor(t, []int{2, 1, 3}, []float32{1, 2, 3, 4, 5, 6})
+	s := NewSqueeze(engine, []int{-2})
+	out, err := s.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grads, err := s.Backward(ctx, types.FullBackprop, out, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(grads[0].Shape(), []int{2, 1, 3}) || !slices.Equal(grads[0].Data(), x.Data()) {
+		t.Errorf("grad = %v %v, want [2 1 3]", grads[0].Shape(), grads[0].Data())
+	}
+}
+
+func TestTile_Backward(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	x := makeTensor(t, []int{2, 2}, []float32{1, 2, 3, 4})
+
+	for _, tile := range []struct {
+		name   string
+		node   *Tile[float32]
+		inputs []float32 // repeats as a second input, if any
+	}{
+		{"repeats input", &Tile[float32]{engine: engine}, []float32{2, 3}},
+		{"fixed repeats", NewTile(engine, []int{2, 3}), nil},
+	} {
+		t.Run(tile.name, func(t *testing.T) {
+			inputs := []*tensor.TensorNumeric[float32]{x}
+			if tile.inputs != nil {
+				inputs = append(inputs, makeTensor(t, []int{2}, tile.inputs))
+			}
+			out, err := tile.node.Forward(ctx, inputs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(out.Shape(), []int{4, 6}) {
+				t.Fatalf("Forward shape = %v, want [4 6]", out.Shape())
+			}
+			// d(sum(w * tile(x)))/dx sums w over the six copies of each
+			// element; w[i, j] = i*6 + j.
+			w := make([]float32, 24)
+			for i := range w {
+				w[i] = float32(i)
+			}
+			grads, err := tile.node.Backward(ctx, types.FullBackprop, makeTensor(t, []int{4, 6}, w), inputs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]float32, 4)
+			for i := range 4 {
+				for j := range 6 {
+					want[(i%2)*2+j%2] += w[i*6+j]
+				}
+			}
+			if !slices.Equal(grads[0].Shape(), []int{2, 2}) || !slices.Equal(grads[0].Data(), want) {
+				t.Errorf("grad = %v %v, want [2 2] %v", grads[0].Shape(), grads[0].Data(), want)
+			}
+			if len(grads) != len(inputs) {
+				t.Errorf("got %d grads for %d inputs", len(grads), len(inputs))
+			}
+		})
+	}
+}
+
+func TestBroadcastTo_Backward(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	// [3, 1] broadcast to [2, 3, 4]: sums over the new leading axis and the
+	// size-1 axis.
+	x := makeTensor(t, []int{3, 1}, []float32{1, 2, 3})
+	b := NewBroadcastTo(engine, []int{2, 3, 4})
+	out, err := b.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Shape(), []int{2, 3, 4}) {
+		t.Fatalf("Forward shape = %v, want [2 3 4]", out.Shape())
+	}
+	ones := make([]float32, 24)
+	for i := range ones {
+		ones[i] = 1
+	}
+	grads, err := b.Backward(ctx, types.FullBackprop, makeTensor(t, []int{2, 3, 4}, ones), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(grads[0].Shape(), []int{3, 1}) || !slices.Equal(grads[0].Data(), []float32{8, 8, 8}) {
+		t.Errorf("grad = %v %v, want [3 1] [8 8 8]", grads[0].Shape(), grads[0].Data())
+	}
+	if b.Attributes()["shape"] == nil {
+		t.Error("Attributes should report the fixed shape")
+	}
+}
+
+func TestSumToShape(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	g := makeTensor(t, []int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+
+	tests := []struct {
+		name    string
+		shape   []int
+		want    []float32
+		wantErr bool
+	}{
+		{"same shape", []int{2, 3}, []float32{1, 2, 3, 4, 5, 6}, false},
+		{"leading axis", []int{3}, []float32{5, 7, 9}, false},
+		{"size-1 axis", []int{2, 1}, []float32{6, 15}, false},
+		{"scalar", []int{}, []float32{21}, false},
+		{"incompatible", []int{2, 2}, nil, true},
+		{"higher rank", []int{1, 2, 3}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SumToShape(ctx, engine, g, tt.shape)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(got.Shape(), tt.shape) || !slices.Equal(got.Data(), tt.want) {
+				t.Errorf("got %v %v, want %v %v", got.Shape(), got.Data(), tt.shape, tt.want)
+			}
+		})
+	}
+}
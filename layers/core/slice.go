@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/axisutil"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -12,13 +13,15 @@ import (
 )
 
 // Slice extracts a sub-tensor using start/end/axes/steps attributes.
-// Steps other than 1 are not supported; steps are accepted but ignored.
+// Steps follow ONNX: a positive step walks from start towards end, a
+// negative step walks backwards (start is clamped to [0, dim-1] and end to
+// [-1, dim-1]), and a zero step is an error.
 type Slice[T tensor.Numeric] struct {
 	engine      compute.Engine[T]
 	starts      []int64
 	ends        []int64
 	axes        []int64 // nil means apply to axes 0..len(starts)-1
-	steps       []int64 // nil or all-1; non-1 steps are not supported
+	steps       []int64 // nil means a step of 1 on every sliced axis
 	outputShape []int
 }
 
@@ -27,6 +30,12 @@ func NewSlice[T tensor.Numeric](engine compute.Engine[T], starts, ends, axes, st
 	return &Slice[T]{engine: engine, starts: starts, ends: ends, axes: axes, steps: steps}
 }
 
+// NewNarrow creates a Slice that keeps length elements of axis starting at
+// start, such as the last n steps of a sequence or a target shifted by one.
+func NewNarrow[T tensor.Numeric](engine compute.Engine[T], axis, start, length int) *Slice[T] {
+	return NewSlice(engine, []int64{int64(start)}, []int64{int64(start + length)}, []int64{int64(axis)}, nil)
+}
+
 // Forward applies the slice operation to the input tensor.
 // Accepts 1 input (attribute-based, opset 1-9) or 3-5 inputs
 // (ONNX opset 10+: data, starts, ends, [axes], [steps]).
@@ -34,15 +43,32 @@ func (s *Slice[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]
 	if len(inputs) == 0 {
 		return nil, fmt.Errorf("Slice expects at least 1 input, got 0")
 	}
-
+	src, err := s.sources(inputs)
+	if err != nil {
+		return nil, err
+	}
 	input := inputs[0]
-	shape := input.Shape()
+	var zero T
+	// The result is a dense copy, so downstream nodes are not tied to the input.
+	out, err := tensor.New(gatherShape(src), gather(input.Data(), gatherIndex(input.Shape(), src), zero))
+	if err != nil {
+		return nil, fmt.Errorf("Slice.Forward: %w", err)
+	}
+	s.outputShape = out.Shape()
+	return out, nil
+}
+
+// sources resolves starts/ends/axes/steps from either attributes or input
+// tensors and returns, per input axis, the input coordinates the output
+// reads.
+func (s *Slice[T]) sources(inputs []*tensor.TensorNumeric[T]) ([][]int, error) {
+	shape := inputs[0].Shape()
 	ndim := len(shape)
 
-	// Resolve starts/ends/axes from either attributes or input tensors.
 	starts := s.starts
 	ends := s.ends
 	axes := s.axes
+	steps := s.steps
 
 	switch {
 	case len(inputs) >= 3:
@@ -52,7 +78,9 @@ func (s *Slice[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]
 		if len(inputs) >= 4 {
 			axes = tensorToInt64(inputs[3])
 		}
-		// steps (inputs[4]) are ignored (only step=1 is supported).
+		if len(inputs) >= 5 {
+			steps = tensorToInt64(inputs[4])
+		}
 	case len(inputs) == 2:
 		// Hybrid: starts from input tensor, ends/axes/steps from attributes.
 		starts = tensorToInt64(inputs[1])
@@ -64,54 +92,59 @@ func (s *Slice[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]
 			axes[i] = int64(i)
 		}
 	}
+	if len(starts) != len(axes) || len(ends) != len(axes) || (steps != nil && len(steps) != len(axes)) {
+		return nil, fmt.Errorf("Slice: got %d starts, %d ends and %d steps for %d axes",
+			len(starts), len(ends), len(steps), len(axes))
+	}
 
-	// Build the full set of ranges, defaulting to the full dimension.
-	ranges := make([][2]int, ndim)
+	// Axes not listed are kept whole.
+	src := make([][]int, ndim)
 	for d := range ndim {
-		ranges[d] = [2]int{0, shape[d]}
+		src[d] = sliceRange(shape[d], 0, shape[d], 1)
 	}
 
 	for i, ax := range axes {
-		dim := int(ax)
-		if dim < 0 {
-			dim += ndim
-		}
-		start := int(starts[i])
-		if start < 0 {
-			start += shape[dim]
-		}
-		end := int(ends[i])
-		if end < 0 {
-			end += shape[dim]
-		}
-		if end > shape[dim] {
-			end = shape[dim]
-		}
-		if start < 0 {
-			start = 0
+		dim, err := axisutil.Normalize(int(ax), ndim)
+		if err != nil {
+			return nil, fmt.Errorf("Slice: %w", err)
 		}
-		if start > shape[dim] {
-			start = shape[dim]
+		step := 1
+		if steps != nil {
+			step = int(steps[i])
 		}
-		if start > end {
-			end = start // empty range
+		if step == 0 {
+			return nil, fmt.Errorf("Slice: step for axis %d is 0", dim)
 		}
-		ranges[dim] = [2]int{start, end}
+		src[dim] = sliceRange(shape[dim], int(starts[i]), int(ends[i]), step)
 	}
+	return src, nil
+}
 
-	sliced, err := input.Slice(ranges...)
-	if err != nil {
-		return nil, fmt.Errorf("Slice.Forward: %w", err)
+// sliceRange returns the coordinates a slice from start to end by step
+// selects on an axis of length dim. Negative start and end count from the
+// end, and both are clamped to the axis.
+func sliceRange(dim, start, end, step int) []int {
+	if start < 0 {
+		start += dim
 	}
-
-	// Return a dense copy so downstream nodes are not tied to the view.
-	outData := sliced.Data()
-	out, err := tensor.New[T](sliced.Shape(), outData)
-	if err != nil {
-		return nil, fmt.Errorf("Slice.Forward copy: %w", err)
+	if end < 0 {
+		end += dim
 	}
-	s.outputShape = out.Shape()
-	return out, nil
+	var idx []int
+	if step > 0 {
+		start = min(max(start, 0), dim)
+		end = min(max(end, 0), dim)
+		for i := start; i < end; i += step {
+			idx = append(idx, i)
+		}
+		return idx
+	}
+	start = min(max(start, 0), dim-1)
+	end = min(max(end, -1), dim-1)
+	for i := start; i > end; i += step {
+		idx = append(idx, i)
+	}
+	return idx
 }
 
 // tensorToInt64 converts a numeric tensor's data to []int64.
@@ -124,9 +157,29 @@ func tensorToInt64[T tensor.Numeric](t *tensor.TensorNumeric[T]) []int64 {
 	return out
 }
 
-// Backward returns nil (not required for inference).
-func (s *Slice[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
-	return nil, nil
+// Backward scatters the output gradient into a zero tensor of the input
+// shape; elements the slice skipped get zero gradient. The starts, ends,
+// axes and steps inputs get no gradient.
+func (s *Slice[T]) Backward(_ context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) < 1 || dOut == nil {
+		return nil, fmt.Errorf("Slice backward requires the output gradient and the forward input")
+	}
+	src, err := s.sources(inputs)
+	if err != nil {
+		return nil, err
+	}
+	input := inputs[0]
+	index := gatherIndex(input.Shape(), src)
+	if dOut.Size() != len(index) {
+		return nil, fmt.Errorf("Slice backward: gradient shape %v does not match output shape %v", dOut.Shape(), gatherShape(src))
+	}
+	grad, err := tensor.New(input.Shape(), scatterAdd(s.engine.Ops(), dOut.Data(), index, input.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("Slice backward: %w", err)
+	}
+	grads := make([]*tensor.TensorNumeric[T], len(inputs))
+	grads[0] = grad
+	return grads, nil
 }
 
 // OpType returns "Slice".
@@ -138,6 +191,7 @@ func (s *Slice[T]) Attributes() map[string]interface{} {
 		"starts": s.starts,
 		"ends":   s.ends,
 		"axes":   s.axes,
+		"steps":  s.steps,
 	}
 }
 
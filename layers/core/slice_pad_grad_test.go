@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func TestSlice_Steps(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+	// x[i, j] = i*4 + j on a [3, 4] grid.
+	x := makeTensor(t, []int{3, 4}, []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+
+	tests := []struct {
+		name                      string
+		starts, ends, axes, steps []int64
+		wantShape                 []int
+		want                      []float32
+	}{
+		{"every other column", []int64{0}, []int64{4}, []int64{1}, []int64{2}, []int{3, 2}, []float32{0, 2, 4, 6, 8, 10}},
+		{"reverse rows", []int64{-1}, []int64{math.MinInt64}, []int64{0}, []int64{-1}, []int{3, 4}, []float32{8, 9, 10, 11, 4, 5, 6, 7, 0, 1, 2, 3}},
+		{"backwards by two", []int64{3}, []int64{0}, []int64{1}, []int64{-2}, []int{3, 2}, []float32{3, 1, 7, 5, 11, 9}},
+		{"both axes", []int64{1, 1}, []int64{math.MaxInt64, 4}, []int64{0, 1}, []int64{1, 2}, []int{2, 2}, []float32{5, 7, 9, 11}},
+		{"empty", []int64{2}, []int64{1}, []int64{0}, nil, []int{0, 4}, []float32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewSlice(engine, tt.starts, tt.ends, tt.axes, tt.steps).Forward(ctx, x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(out.Shape(), tt.wantShape) || !slices.Equal(out.Data(), tt.want) {
+				t.Errorf("got %v %v, want %v %v", out.Shape(), out.Data(), tt.wantShape, tt.want)
+			}
+		})
+	}
+
+	t.Run("steps input", func(t *testing.T) {
+		in := func(v ...float32) *tensor.TensorNumeric[float32] { return makeTensor(t, []int{len(v)}, v) }
+		out, err := (&Slice[float32]{engine: engine}).Forward(ctx, x, in(0), in(4), in(1), in(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(out.Data(), []float32{0, 3, 4, 7, 8, 11}) {
+			t.Errorf("got %v, want [0 3 4 7 8 11]", out.Data())
+		}
+	})
+
+	t.Run("zero step", func(t *testing.T) {
+		if _, err := NewSlice(engine, []int64{0}, []int64{2}, nil, []int64{0}).Forward(ctx, x); err == nil {
+			t.Error("expected error for a zero step")
+		}
+	})
+}
+
+func TestNarrow(t *testing.T) {
+	engine := makeEngine()
+	// Shift a [batch, seq] target left by one step.
+	x := makeTensor(t, []int{2, 4}, []float32{1, 2, 3, 4, 5, 6, 7, 8})
+	out, err := NewNarrow(engine, -1, 1, 3).Forward(context.Background(), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Shape(), []int{2, 3}) || !slices.Equal(out.Data(), []float32{2, 3, 4, 6, 7, 8}) {
+		t.Errorf("got %v %v, want [2 3] [2 3 4 6 7 8]", out.Shape(), out.Data())
+	}
+}
+
+func TestPad_Reflect(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+
+	out, err := NewPad(engine, []int64{2, 2}, 0, WithPadMode[float32]("reflect")).
+		Forward(ctx, makeTensor(t, []int{3}, []float32{1, 2, 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{3, 2, 1, 2, 3, 2, 1}; !slices.Equal(out.Data(), want) {
+		t.Errorf("1D: got %v, want %v", out.Data(), want)
+	}
+
+	node, err := BuildPad[float32](engine, makeOps(), "pad", nil, map[string]any{
+		"pads": []int64{1, 0, 0, 1},
+		"mode": "reflect",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = node.Forward(ctx, makeTensor(t, []int{2, 2}, []float32{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{3, 4, 3, 1, 2, 1, 3, 4, 3}; !slices.Equal(out.Shape(), []int{3, 3}) || !slices.Equal(out.Data(), want) {
+		t.Errorf("2D: got %v %v, want [3 3] %v", out.Shape(), out.Data(), want)
+	}
+
+	tooWide := NewPad(engine, []int64{3, 0}, 0, WithPadMode[float32]("reflect"))
+	if _, err := tooWide.Forward(ctx, makeTensor(t, []int{3}, []float32{1, 2, 3})); err == nil {
+		t.Error("expected error for a reflect pad as wide as the axis")
+	}
+	if _, err := NewPad(engine, []int64{1, 1}, 0, WithPadMode[float32]("wrap")).
+		Forward(ctx, makeTensor(t, []int{3}, []float32{1, 2, 3})); err == nil {
+		t.Error("expected error for an unsupported mode")
+	}
+}
+
+// TestSlicePad_Backward checks each backward pass against the forward pass
+// through the adjoint identity <f(x), w> = <x, f'(w)>, which holds because
+// slicing and padding are linear in x.
+func TestSlicePad_Backward(t *testing.T) {
+	engine := makeEngine()
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		node  graph.Node[float32]
+		shape []int
+	}{
+		{"slice", NewSlice(engine, []int64{1}, []int64{4}, []int64{1}, nil), []int{2, 5}},
+		{"slice steps", NewSlice(engine, []int64{-1, 0}, []int64{math.MinInt64, 5}, []int64{0, 1}, []int64{-1, 2}), []int{3, 5}},
+		{"narrow", NewNarrow(engine, 0, 1, 2), []int{3, 2}},
+		{"pad constant", NewPad(engine, []int64{1, 0, 2, 1}, 7), []int{2, 3}},
+		{"pad crop", NewPad(engine, []int64{-1, 1, 0, -1}, 0), []int{3, 3}},
+		{"pad reflect", NewPad(engine, []int64{2, 1, 1, 2}, 0, WithPadMode[float32]("reflect")), []int{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := 1
+			for _, d := range tt.shape {
+				size *= d
+			}
+			xData := make([]float32, size)
+			for i := range xData {
+				xData[i] = float32(i%7) - 3
+			}
+			x := makeTensor(t, tt.shape, xData)
+			out, err := tt.node.Forward(ctx, x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := make([]float32, out.Size())
+			for i := range w {
+				w[i] = float32(i%5) + 1
+			}
+			grads, err := tt.node.Backward(ctx, types.FullBackprop, makeTensor(t, out.Shape(), w), x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(grads[0].Shape(), tt.shape) {
+				t.Fatalf("grad shape = %v, want %v", grads[0].Shape(), tt.shape)
+			}
+
+			// Constant padding adds a term independent of x; remove it by
+			// comparing against f(0).
+			zero, err := tt.node.Forward(ctx, makeTensor(t, tt.shape, make([]float32, size)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lhs, rhs float32
+			for i, v := range out.Data() {
+				lhs += (v - zero.Data()[i]) * w[i]
+			}
+			for i, g := range grads[0].Data() {
+				rhs += xData[i] * g
+			}
+			if lhs != rhs {
+				t.Errorf("<f(x), w> = %v, <x, f'(w)> = %v", lhs, rhs)
+			}
+		})
+	}
+}
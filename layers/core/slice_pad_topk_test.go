@@ -97,12 +97,17 @@ func TestSlice_ForwardInputError(t *testing.T) {
 func TestSlice_Backward(t *testing.T) {
 	eng := makeFloat32Engine()
 	s := NewSlice[float32](eng, []int64{0}, []int64{2}, nil, nil)
-	grads, err := s.Backward(context.Background(), types.FullBackprop, nil)
+	if _, err := s.Backward(context.Background(), types.FullBackprop, nil); err == nil {
+		t.Error("expected error without the forward input")
+	}
+	input, _ := tensor.New[float32]([]int{3}, []float32{1, 2, 3})
+	dOut, _ := tensor.New[float32]([]int{2}, []float32{5, 6})
+	grads, err := s.Backward(context.Background(), types.FullBackprop, dOut, input)
 	if err != nil {
 		t.Fatalf("Slice.Backward failed: %v", err)
 	}
-	if grads != nil {
-		t.Error("expected nil grads")
+	if got := grads[0].Data(); got[0] != 5 || got[1] != 6 || got[2] != 0 {
+		t.Errorf("grad = %v, want [5 6 0]", got)
 	}
 }
 
@@ -244,12 +249,17 @@ func TestPad_PadsMismatch(t *testing.T) {
 func TestPad_Backward(t *testing.T) {
 	eng := makeFloat32Engine()
 	p := NewPad[float32](eng, []int64{1, 1}, 0)
-	grads, err := p.Backward(context.Background(), types.FullBackprop, nil)
+	if _, err := p.Backward(context.Background(), types.FullBackprop, nil); err == nil {
+		t.Error("expected error without the forward input")
+	}
+	input, _ := tensor.New[float32]([]int{2}, []float32{1, 2})
+	dOut, _ := tensor.New[float32]([]int{4}, []float32{1, 2, 3, 4})
+	grads, err := p.Backward(context.Background(), types.FullBackprop, dOut, input)
 	if err != nil {
 		t.Fatalf("Pad.Backward failed: %v", err)
 	}
-	if grads != nil {
-		t.Error("expected nil grads")
+	if got := grads[0].Data(); got[0] != 2 || got[1] != 3 {
+		t.Errorf("grad = %v, want [2 3]", got)
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/zerfoo/zerfoo/internal/axisutil"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -19,6 +20,12 @@ type Squeeze[T tensor.Numeric] struct {
 	axes   []int // empty means squeeze all size-1 dims
 }
 
+// NewSqueeze creates a Squeeze that removes the size-1 dimensions at axes,
+// or every size-1 dimension when axes is empty.
+func NewSqueeze[T tensor.Numeric](engine compute.Engine[T], axes []int) *Squeeze[T] {
+	return &Squeeze[T]{engine: engine, axes: axes}
+}
+
 func (s *Squeeze[T]) OpType() string                  { return "Squeeze" }
 func (s *Squeeze[T]) Attributes() map[string]any       { return map[string]any{"axes": s.axes} }
 func (s *Squeeze[T]) OutputShape() []int               { return nil }
@@ -42,14 +49,12 @@ func (s *Squeeze[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeri
 
 	// Normalize negative axes.
 	rank := len(inShape)
-	squeezeSet := make(map[int]bool, len(axes))
-	for _, a := range axes {
-		if a < 0 {
-			a += rank
-		}
-		if a < 0 || a >= rank {
-			return nil, fmt.Errorf("Squeeze: axis out of range for rank %d", rank)
-		}
+	norm, err := axisutil.NormalizeAll(axes, rank)
+	if err != nil {
+		return nil, fmt.Errorf("Squeeze: %w", err)
+	}
+	squeezeSet := make(map[int]bool, len(norm))
+	for _, a := range norm {
 		if inShape[a] != 1 {
 			return nil, fmt.Errorf("Squeeze: dim %d has size %d, not 1", a, inShape[a])
 		}
@@ -77,8 +82,18 @@ func (s *Squeeze[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeri
 	return s.engine.Reshape(ctx, inputs[0], newShape)
 }
 
-func (s *Squeeze[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
-	return nil, fmt.Errorf("Squeeze backward not implemented")
+// Backward reshapes the output gradient back to the input shape.
+func (s *Squeeze[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) < 1 || dOut == nil {
+		return nil, fmt.Errorf("Squeeze backward requires the output gradient and the forward input")
+	}
+	grad, err := s.engine.Reshape(ctx, dOut, inputs[0].Shape())
+	if err != nil {
+		return nil, fmt.Errorf("Squeeze backward: %w", err)
+	}
+	grads := make([]*tensor.TensorNumeric[T], len(inputs))
+	grads[0] = grad
+	return grads, nil
 }
 
 // BuildSqueeze constructs a Squeeze node from attributes.
@@ -14,31 +14,37 @@ import (
 // Tile repeats a tensor along each dimension according to the repeats tensor.
 // ONNX Tile op: output[i] = input[i % input_shape[dim]] for each dim.
 type Tile[T tensor.Numeric] struct {
-	engine compute.Engine[T]
+	engine  compute.Engine[T]
+	repeats []int // used when repeats is not given as a second input
 }
 
-func (t *Tile[T]) OpType() string                  { return "Tile" }
-func (t *Tile[T]) Attributes() map[string]any       { return nil }
-func (t *Tile[T]) OutputShape() []int               { return nil }
+// NewTile creates a Tile with fixed repeats, one per input dimension.
+func NewTile[T tensor.Numeric](engine compute.Engine[T], repeats []int) *Tile[T] {
+	return &Tile[T]{engine: engine, repeats: repeats}
+}
+
+func (t *Tile[T]) OpType() string                    { return "Tile" }
+func (t *Tile[T]) OutputShape() []int                { return nil }
 func (t *Tile[T]) Parameters() []*graph.Parameter[T] { return nil }
 
+func (t *Tile[T]) Attributes() map[string]any {
+	if t.repeats == nil {
+		return nil
+	}
+	return map[string]any{"repeats": t.repeats}
+}
+
 func (t *Tile[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
-	if len(inputs) != 2 {
-		return nil, fmt.Errorf("Tile requires 2 inputs (data, repeats), got %d", len(inputs))
+	repeats, err := t.tileRepeats(inputs)
+	if err != nil {
+		return nil, err
 	}
 	data := inputs[0]
-	repeatsData := inputs[1].Data()
 	inShape := data.Shape()
 
-	if len(repeatsData) != len(inShape) {
-		return nil, fmt.Errorf("Tile: repeats length %d != input rank %d", len(repeatsData), len(inShape))
-	}
-
-	repeats := make([]int, len(repeatsData))
 	outShape := make([]int, len(inShape))
 	totalSize := 1
-	for i, r := range repeatsData {
-		repeats[i] = int(r)
+	for i := range repeats {
 		if repeats[i] <= 0 {
 			return nil, fmt.Errorf("Tile: repeat[%d] = %d must be positive", i, repeats[i])
 		}
@@ -80,8 +86,64 @@ func tileSourceIndex(flatIdx, rank int, inShape, outStrides, inStrides []int) in
 	return inIdx
 }
 
-func (t *Tile[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
-	return nil, fmt.Errorf("Tile backward not implemented")
+// tileRepeats returns the repeats from the second input, or the fixed
+// repeats when Tile has a single input.
+func (t *Tile[T]) tileRepeats(inputs []*tensor.TensorNumeric[T]) ([]int, error) {
+	var repeats []int
+	switch {
+	case len(inputs) == 2:
+		repeatsData := inputs[1].Data()
+		repeats = make([]int, len(repeatsData))
+		for i, r := range repeatsData {
+			repeats[i] = int(r)
+		}
+	case len(inputs) == 1 && t.repeats != nil:
+		repeats = t.repeats
+	default:
+		return nil, fmt.Errorf("Tile requires 2 inputs (data, repeats), got %d", len(inputs))
+	}
+	if rank := len(inputs[0].Shape()); len(repeats) != rank {
+		return nil, fmt.Errorf("Tile: repeats length %d != input rank %d", len(repeats), rank)
+	}
+	return repeats, nil
+}
+
+// Backward sums the output gradient over the tiled copies. Viewing the
+// gradient of an [r0*d0, r1*d1, ...] output as [r0, d0, r1, d1, ...], the
+// input gradient is its sum over the repeat axes.
+func (t *Tile[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) < 1 || dOut == nil {
+		return nil, fmt.Errorf("Tile backward requires the output gradient and the forward inputs")
+	}
+	repeats, err := t.tileRepeats(inputs)
+	if err != nil {
+		return nil, err
+	}
+	inShape := inputs[0].Shape()
+	split := make([]int, 0, 2*len(inShape))
+	var axes []int
+	for i, d := range inShape {
+		if repeats[i] > 1 {
+			axes = append(axes, len(split))
+		}
+		split = append(split, repeats[i], d)
+	}
+
+	grad, err := t.engine.Reshape(ctx, dOut, split)
+	if err != nil {
+		return nil, fmt.Errorf("Tile backward: %w", err)
+	}
+	if len(axes) > 0 {
+		if grad, err = ReduceAxes(ctx, t.engine, ReduceOpSum, grad, axes, true); err != nil {
+			return nil, fmt.Errorf("Tile backward: %w", err)
+		}
+	}
+	if grad, err = t.engine.Reshape(ctx, grad, inShape); err != nil {
+		return nil, fmt.Errorf("Tile backward: %w", err)
+	}
+	grads := make([]*tensor.TensorNumeric[T], len(inputs))
+	grads[0] = grad
+	return grads, nil
 }
 
 // BuildTile constructs a Tile node from attributes.
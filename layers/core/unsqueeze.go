@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/axisutil"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -12,6 +13,7 @@ import (
 )
 
 // Unsqueeze is a layer that adds dimensions of size 1 to a tensor at specified axes.
+// It is the ExpandDims of other frameworks.
 type Unsqueeze[T tensor.Numeric] struct {
 	engine      compute.Engine[T]
 	axes        []int
@@ -48,14 +50,13 @@ func (u *Unsqueeze[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNume
 	// Calculate the output shape by inserting 1s at the specified axes
 	outputShape := make([]int, len(inputShape)+len(u.axes))
 
-	// Normalize negative axes and create a map of axes to insert 1s
-	axesMap := make(map[int]bool)
-
-	for _, axis := range u.axes {
-		ax := axis //nolint:copyloopvar // local working copy for normalization
-		if ax < 0 {
-			ax = len(outputShape) + ax
-		}
+	// Axes index the output, so negative axes count from its end.
+	axes, err := axisutil.NormalizeAll(u.axes, len(outputShape))
+	if err != nil {
+		return nil, fmt.Errorf("Unsqueeze: %w", err)
+	}
+	axesMap := make(map[int]bool, len(axes))
+	for _, ax := range axes {
 		axesMap[ax] = true
 	}
 
@@ -21,8 +21,8 @@
 //     shape manipulation (Reshape, Unsqueeze, Squeeze, Expand, Concat, Slice,
 //     Pad, Tile, Shape, Cast), linear algebra (MatMul, Gemm, Conv2d,
 //     GlobalAveragePool), rotary embeddings, FFN, Mixture of Experts, and more.
-//   - [github.com/zerfoo/zerfoo/layers/gather] — Gather operation for index-based
-//     element selection.
+//   - [github.com/zerfoo/zerfoo/layers/gather] — Gather and GatherElements for
+//     index-based selection along any axis.
 //   - [github.com/zerfoo/zerfoo/layers/reducesum] — ReduceSum along specified axes.
 //   - [github.com/zerfoo/zerfoo/layers/transpose] — Transpose for axis permutation.
 //
@@ -41,6 +41,12 @@
 //
 //   - [github.com/zerfoo/zerfoo/layers/regularization] — Dropout and FeatureDropout.
 //
+// Preprocessing:
+//
+//   - [github.com/zerfoo/zerfoo/layers/preprocessing] — Fitted feature
+//     transforms (Standardize, Impute, OneHot, Bucketize) whose statistics
+//     are saved with the model.
+//
 // Transformer:
 //
 //   - [github.com/zerfoo/zerfoo/layers/transformer] — Transformer building blocks
@@ -83,5 +89,13 @@
 //  2. Register the layer in [registry.RegisterAll] by adding a
 //     [model.RegisterLayer] call with a unique name and the builder function.
 //  3. Write tests in the same sub-package.
+//
+// # Axes
+//
+// Layers that take an axis accept negative values counting from the last
+// dimension, so -1 is the last axis. Normalize axes with the shared helpers
+// in internal/axisutil rather than by hand, so every layer accepts the same
+// range and reports out-of-range axes the same way. Reductions accept any
+// set of axes; no axes means every axis.
 // Stability: stable
 package layers
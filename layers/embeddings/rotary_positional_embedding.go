@@ -9,6 +9,7 @@ import (
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/zerfoo/internal/cuda/kernels"
+	"github.com/zerfoo/zerfoo/internal/engineproxy"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
 )
@@ -60,7 +61,10 @@ func WithRotaryBase(base float64) RotaryPositionalEmbeddingOption {
 }
 
 // WithRotaryDimFraction sets the fraction of head dimensions that receive rotation.
-// Default is 1.0 (all dimensions rotated). Phi-4 uses 0.75 for partial RoPE.
+// Default is 1.0 (all dimensions rotated). Phi-4 uses 0.75 for partial RoPE and
+// GPT-NeoX variants commonly use 0.25. The fraction must lie in (0, 1] and
+// select at least one pair of dimensions; the leading dimensions are rotated
+// and the remainder pass through unchanged.
 func WithRotaryDimFraction(fraction float64) RotaryPositionalEmbeddingOption {
 	return func(opts *RotaryPositionalEmbeddingOptions) {
 		opts.RotaryDimFraction = fraction
@@ -103,11 +107,17 @@ func NewRotaryPositionalEmbedding[T tensor.Numeric](
 	}
 
 	// Compute the number of dimensions that receive rotation.
+	if !(opts.RotaryDimFraction > 0 && opts.RotaryDimFraction <= 1.0) {
+		return nil, fmt.Errorf("rotary dimension fraction (%v) must be in (0, 1]", opts.RotaryDimFraction)
+	}
 	rotaryDim := headDim
-	if opts.RotaryDimFraction > 0 && opts.RotaryDimFraction < 1.0 {
+	if opts.RotaryDimFraction < 1.0 {
 		rotaryDim = int(float64(headDim) * opts.RotaryDimFraction)
 		// Ensure rotaryDim is even.
 		rotaryDim &^= 1
+		if rotaryDim == 0 {
+			return nil, fmt.Errorf("rotary dimension fraction (%v) rotates no dimensions of head dimension %d", opts.RotaryDimFraction, headDim)
+		}
 	}
 
 	// Create position indices: [0, 1, ..., seq_len-1]
@@ -325,11 +335,9 @@ func (rpe *RotaryPositionalEmbedding[T]) Forward(ctx context.Context, inputs ...
 
 	// Fused single-pass kernel (inference hot path).
 	// Unwrap EngineProxy to detect the real engine type, so the fused path
-	// is taken even when the engine is wrapped.
-	realEngine := compute.Engine[T](rpe.engine)
-	if proxy, ok := rpe.engine.(*compute.EngineProxy[T]); ok {
-		realEngine = proxy.Real()
-	}
+	// is taken even when the engine is wrapped, unless the proxy is
+	// recording a trace.
+	realEngine, _ := engineproxy.Unwrap(rpe.engine)
 	// GPU fused RoPE: one kernel launch replaces Split + 4 Mul + Sub + Add + Concat.
 	if provider, ok := realEngine.(compute.FusedRoPEProvider[T]); ok {
 		out, err := provider.GPUFusedRoPE(input, cosSliced, sinSliced, rpe.rotaryDim)
@@ -362,6 +370,7 @@ func (rpe *RotaryPositionalEmbedding[T]) Forward(ctx context.Context, inputs ...
 
 	cosAngles := cosSliced
 	sinAngles := sinSliced
+	engineproxy.Constant(rpe.engine, cosAngles, sinAngles)
 
 	// Split rotary portion into two halves: x_rot0, x_rot1.
 	// When rotaryDim equals the full last dimension, use engine.Split to
@@ -792,6 +792,57 @@ func TestRotaryPositionalEmbedding_PartialRotation_ForwardBackward(t *testing.T)
 	}
 }
 
+func TestRotaryPositionalEmbedding_PartialRotation_InvalidFraction(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+
+	tests := []struct {
+		name     string
+		headDim  int
+		fraction float64
+	}{
+		{name: "zero", headDim: 8, fraction: 0},
+		{name: "negative", headDim: 8, fraction: -0.5},
+		{name: "above one", headDim: 8, fraction: 1.5},
+		{name: "NaN", headDim: 8, fraction: math.NaN()},
+		{name: "rotates nothing", headDim: 8, fraction: 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRotaryPositionalEmbedding[float32](ctx, engine, tt.headDim, 4, WithRotaryDimFraction(tt.fraction))
+			if err == nil {
+				t.Fatalf("expected error for fraction %v", tt.fraction)
+			}
+		})
+	}
+}
+
+func TestRotaryPositionalEmbedding_PartialRotation_RotaryDim(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+
+	tests := []struct {
+		headDim  int
+		fraction float64
+		want     int
+	}{
+		{headDim: 64, fraction: 0.25, want: 16},
+		{headDim: 80, fraction: 0.4, want: 32},
+		{headDim: 12, fraction: 0.5, want: 6},
+		{headDim: 10, fraction: 0.5, want: 4}, // rounded down to even
+		{headDim: 8, fraction: 1.0, want: 8},
+	}
+	for _, tt := range tests {
+		rpe, err := NewRotaryPositionalEmbedding[float32](ctx, engine, tt.headDim, 4, WithRotaryDimFraction(tt.fraction))
+		if err != nil {
+			t.Fatalf("headDim=%d fraction=%v: %v", tt.headDim, tt.fraction, err)
+		}
+		if got := rpe.RotaryDim(); got != tt.want {
+			t.Errorf("headDim=%d fraction=%v: RotaryDim() = %d, want %d", tt.headDim, tt.fraction, got, tt.want)
+		}
+	}
+}
+
 func TestDocumentWiseRoPE_PositionReset(t *testing.T) {
 	ctx := context.Background()
 	engine := compute.NewCPUEngine[float64](&numeric.Float64Ops{})
@@ -7,7 +7,9 @@ import (
 
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/zerfoo/internal/dtype"
 	"github.com/zerfoo/zerfoo/layers/components"
+	"github.com/zerfoo/zerfoo/layers/gather"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
 )
@@ -76,7 +78,7 @@ func NewTokenEmbedding[T tensor.Numeric](engine compute.Engine[T], vocabSize, em
 		copy(embeddingTableTensor.Data(), weights)
 	} else {
 		// Default initialization: uniform random values
-		if err := engine.RandomUniform(context.Background(), embeddingTableTensor, engine.Ops().FromFloat64(-0.05), engine.Ops().FromFloat64(0.05)); err != nil {
+		if err := components.RandomUniform(context.Background(), engine, embeddingTableTensor, engine.Ops().FromFloat64(-0.05), engine.Ops().FromFloat64(0.05)); err != nil {
 			return nil, fmt.Errorf("failed to initialize embedding table: %w", err)
 		}
 	}
@@ -134,17 +136,9 @@ func (te *TokenEmbedding[T]) Forward(ctx context.Context, inputs ...*tensor.Tens
 	for _, d := range inputShape {
 		flatSize *= d
 	}
-	intData := make([]int, flatSize)
-	dataT := tokenIDsT.Data()
-	for i := 0; i < flatSize; i++ {
-		switch v := any(dataT[i]).(type) {
-		case float32:
-			intData[i] = int(v)
-		case float64:
-			intData[i] = int(v)
-		default:
-			return nil, fmt.Errorf("TokenEmbedding requires input indices convertible to int; unsupported element type %T", v)
-		}
+	intData, err := dtype.Ints(tokenIDsT.Data()[:flatSize])
+	if err != nil {
+		return nil, fmt.Errorf("TokenEmbedding requires input indices convertible to int; %w", err)
 	}
 	tokenIDs, err := tensor.New[int](inputShape, intData)
 	if err != nil {
@@ -182,7 +176,7 @@ func (te *TokenEmbedding[T]) Forward(ctx context.Context, inputs ...*tensor.Tens
 }
 
 // Backward computes the gradients for the embedding table.
-func (te *TokenEmbedding[T]) Backward(ctx context.Context, mode types.BackwardMode, outputGradient *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+func (te *TokenEmbedding[T]) Backward(ctx context.Context, mode types.BackwardMode, outputGradient *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
 	// The gradient for the embedding table is a sparse update.
 	// For each token ID in inputTokenIDs, we add the corresponding dOut slice
 	// to the gradient accumulator of that embedding vector in the embeddingTable.
@@ -226,17 +220,17 @@ func (te *TokenEmbedding[T]) Backward(ctx context.Context, mode types.BackwardMo
 		return nil, fmt.Errorf("failed to build flattened inputTokenIDs for ScatterAdd: %w", err)
 	}
 
-	if err := te.engine.ScatterAdd(ctx, dEmbeddingTable, reshapedInputTokenIDs, reshapedDOut); err != nil { // Assuming ScatterAdd is available
+	if err := gather.ScatterAdd(ctx, te.engine, dEmbeddingTable, reshapedInputTokenIDs, reshapedDOut); err != nil {
 		return nil, err
 	}
 
-	if err := te.embeddingTable.AddGradient(dEmbeddingTable); err != nil {
+	if err := components.AddGradient(ctx, te.embeddingTable, dEmbeddingTable); err != nil {
 		return nil, err
 	}
 
-	// Embedding layer typically does not pass gradients back to its input (token IDs are discrete).
-	// So, return nil for input gradients.
-	return nil, nil
+	// Token IDs are discrete, so the input gets no gradient. Return a nil
+	// gradient per input, as graph.Backward expects one entry per input.
+	return make([]*tensor.TensorNumeric[T], len(inputs)), nil
 }
 
 // OpType returns the operation type of the TokenEmbedding layer.
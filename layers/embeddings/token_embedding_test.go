@@ -97,6 +97,11 @@ func TestTokenEmbedding_Backward_ND(t *testing.T) {
 	for i := range expected {
 		testutils.AssertFloatEqual(t, expected[i], e.embeddingTable.Gradient.Data()[i], 1e-6, fmt.Sprintf("embeddingTable gradient mismatch at index %d", i))
 	}
+
+	// As a graph node it returns one (nil) gradient per input.
+	dInputs, err = e.Backward(ctx, types.FullBackprop, dOut, inputIDs)
+	testutils.AssertNoError(t, err, "Backward with inputs should not return an error")
+	testutils.AssertTrue(t, len(dInputs) == 1 && dInputs[0] == nil, "Backward should return one nil input gradient")
 }
 
 func TestTokenEmbedding_Forward_1D_VerifyValues(t *testing.T) {
@@ -0,0 +1,193 @@
+package gather
+
+import (
+	"fmt"
+
+	"github.com/zerfoo/zerfoo/internal/axisutil"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GatherAxis selects entries of data along axis, like ONNX Gather or
+// torch.index_select generalized to index tensors of any shape. The output
+// shape is data.shape[:axis] + indices.shape + data.shape[axis+1:]; for a
+// [beams, seq, dim] cache and indices [beams], GatherAxis(cache, order, 0)
+// reorders the beams. Negative indices count from the end of the axis.
+func GatherAxis[T tensor.Numeric](data *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], axis int) (*tensor.TensorNumeric[T], error) {
+	l, idx, err := newAxisLayout(data.Shape(), indices, axis)
+	if err != nil {
+		return nil, fmt.Errorf("GatherAxis: %w", err)
+	}
+	src := data.Data()
+	out := make([]T, l.outer*len(idx)*l.inner)
+	for o := range l.outer {
+		for j, k := range idx {
+			copy(out[(o*len(idx)+j)*l.inner:][:l.inner], src[(o*l.n+k)*l.inner:][:l.inner])
+		}
+	}
+	return tensor.New(l.gatherShape(indices.Shape()), out)
+}
+
+// ScatterAddAxis is the backward of GatherAxis: it returns a tensor of
+// shape dataShape holding grad summed into the entries each index selected,
+// so an entry selected twice receives both gradients.
+func ScatterAddAxis[T tensor.Numeric](ops numeric.Arithmetic[T], grad *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], dataShape []int, axis int) (*tensor.TensorNumeric[T], error) {
+	l, idx, err := newAxisLayout(dataShape, indices, axis)
+	if err != nil {
+		return nil, fmt.Errorf("ScatterAddAxis: %w", err)
+	}
+	g := grad.Data()
+	if len(g) != l.outer*len(idx)*l.inner {
+		return nil, fmt.Errorf("ScatterAddAxis: gradient shape %v does not match gather shape %v",
+			grad.Shape(), l.gatherShape(indices.Shape()))
+	}
+	out := make([]T, l.outer*l.n*l.inner)
+	for o := range l.outer {
+		scatterAddRows(ops, out[o*l.n*l.inner:][:l.n*l.inner], idx, g[o*len(idx)*l.inner:][:len(idx)*l.inner], l.inner)
+	}
+	return tensor.New(dataShape, out)
+}
+
+// TakeAlongAxis picks one entry of data along axis for every element of
+// indices, like ONNX GatherElements or numpy.take_along_axis. indices has
+// the rank of data and is no larger on the other axes, and the output has
+// its shape: out[i][j] = data[indices[i][j]][j] for axis 0. For logits
+// [batch, classes] and labels [batch, 1], TakeAlongAxis(logits, labels, 1)
+// gathers each row's label logit.
+func TakeAlongAxis[T tensor.Numeric](data *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], axis int) (*tensor.TensorNumeric[T], error) {
+	offsets, err := alongAxisOffsets(data.Shape(), indices, axis)
+	if err != nil {
+		return nil, fmt.Errorf("TakeAlongAxis: %w", err)
+	}
+	src := data.Data()
+	out := make([]T, len(offsets))
+	for i, off := range offsets {
+		out[i] = src[off]
+	}
+	return tensor.New(indices.Shape(), out)
+}
+
+// ScatterAddAlongAxis is the backward of TakeAlongAxis: it returns a tensor
+// of shape dataShape holding each element of grad added to the entry its
+// index picked.
+func ScatterAddAlongAxis[T tensor.Numeric](ops numeric.Arithmetic[T], grad *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], dataShape []int, axis int) (*tensor.TensorNumeric[T], error) {
+	offsets, err := alongAxisOffsets(dataShape, indices, axis)
+	if err != nil {
+		return nil, fmt.Errorf("ScatterAddAlongAxis: %w", err)
+	}
+	g := grad.Data()
+	if len(g) != len(offsets) {
+		return nil, fmt.Errorf("ScatterAddAlongAxis: gradient shape %v does not match indices shape %v",
+			grad.Shape(), indices.Shape())
+	}
+	size := 1
+	for _, d := range dataShape {
+		size *= d
+	}
+	out := make([]T, size)
+	for i, off := range offsets {
+		out[off] = ops.Add(out[off], g[i])
+	}
+	return tensor.New(dataShape, out)
+}
+
+// axisLayout views a tensor as [outer, n, inner] around one axis.
+type axisLayout struct {
+	shape           []int
+	axis            int
+	outer, n, inner int
+}
+
+// newAxisLayout lays out shape around axis and returns the indices with
+// negative entries wrapped, rejecting any outside the axis.
+func newAxisLayout(shape []int, indices *tensor.TensorNumeric[int], axis int) (axisLayout, []int, error) {
+	a, err := axisutil.Normalize(axis, len(shape))
+	if err != nil {
+		return axisLayout{}, nil, err
+	}
+	l := axisLayout{shape: shape, axis: a, outer: 1, n: shape[a], inner: 1}
+	for _, d := range shape[:a] {
+		l.outer *= d
+	}
+	for _, d := range shape[a+1:] {
+		l.inner *= d
+	}
+	idx, err := wrapIndices(indices.Data(), l.n)
+	if err != nil {
+		return axisLayout{}, nil, err
+	}
+	return l, idx, nil
+}
+
+// gatherShape returns the shape GatherAxis produces for indices of shape
+// idxShape.
+func (l axisLayout) gatherShape(idxShape []int) []int {
+	shape := make([]int, 0, len(l.shape)-1+len(idxShape))
+	shape = append(shape, l.shape[:l.axis]...)
+	shape = append(shape, idxShape...)
+	return append(shape, l.shape[l.axis+1:]...)
+}
+
+// alongAxisOffsets returns, for each element of indices, the flat offset in
+// a tensor of shape dataShape that TakeAlongAxis reads.
+func alongAxisOffsets(dataShape []int, indices *tensor.TensorNumeric[int], axis int) ([]int, error) {
+	rank := len(dataShape)
+	a, err := axisutil.Normalize(axis, rank)
+	if err != nil {
+		return nil, err
+	}
+	idxShape := indices.Shape()
+	if len(idxShape) != rank {
+		return nil, fmt.Errorf("indices rank %d does not match data rank %d", len(idxShape), rank)
+	}
+	for d := range rank {
+		if d != a && idxShape[d] > dataShape[d] {
+			return nil, fmt.Errorf("indices shape %v exceeds data shape %v on axis %d", idxShape, dataShape, d)
+		}
+	}
+	idx, err := wrapIndices(indices.Data(), dataShape[a])
+	if err != nil {
+		return nil, err
+	}
+	strides := make([]int, rank)
+	stride := 1
+	for d := rank - 1; d >= 0; d-- {
+		strides[d] = stride
+		stride *= dataShape[d]
+	}
+	offsets := make([]int, len(idx))
+	coord := make([]int, rank)
+	for i, k := range idx {
+		off := 0
+		for d, c := range coord {
+			if d == a {
+				c = k
+			}
+			off += c * strides[d]
+		}
+		offsets[i] = off
+		for d := rank - 1; d >= 0; d-- {
+			if coord[d]++; coord[d] < idxShape[d] {
+				break
+			}
+			coord[d] = 0
+		}
+	}
+	return offsets, nil
+}
+
+// wrapIndices returns indices with negative entries counted from n and
+// rejects any outside [-n, n).
+func wrapIndices(indices []int, n int) ([]int, error) {
+	out := make([]int, len(indices))
+	for i, k := range indices {
+		if k < 0 {
+			k += n
+		}
+		if k < 0 || k >= n {
+			return nil, fmt.Errorf("index %d out of range for axis of size %d", indices[i], n)
+		}
+		out[i] = k
+	}
+	return out, nil
+}
@@ -0,0 +1,169 @@
+package gather
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func mustTensor[T tensor.Numeric](t *testing.T, shape []int, data []T) *tensor.TensorNumeric[T] {
+	t.Helper()
+	x, err := tensor.New(shape, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestGatherAxis(t *testing.T) {
+	// x[i, j, k] = i*6 + j*2 + k on a [2, 3, 2] grid.
+	x := mustTensor(t, []int{2, 3, 2}, []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+
+	tests := []struct {
+		name      string
+		idxShape  []int
+		idx       []int
+		axis      int
+		wantShape []int
+		want      []float32
+	}{
+		{"reorder beams", []int{2}, []int{1, 0}, 0, []int{2, 3, 2}, []float32{6, 7, 8, 9, 10, 11, 0, 1, 2, 3, 4, 5}},
+		{"middle axis", []int{2}, []int{2, 0}, 1, []int{2, 2, 2}, []float32{4, 5, 0, 1, 10, 11, 6, 7}},
+		{"negative axis and index", []int{3}, []int{-1, 0, -1}, -1, []int{2, 3, 3}, []float32{1, 0, 1, 3, 2, 3, 5, 4, 5, 7, 6, 7, 9, 8, 9, 11, 10, 11}},
+		{"scalar index drops the axis", []int{}, []int{1}, 1, []int{2, 2}, []float32{2, 3, 8, 9}},
+		{"2D indices", []int{2, 2}, []int{0, 2, 1, 1}, 1, []int{2, 2, 2, 2}, []float32{0, 1, 4, 5, 2, 3, 2, 3, 6, 7, 10, 11, 8, 9, 8, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := GatherAxis(x, mustTensor(t, tt.idxShape, tt.idx), tt.axis)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(out.Shape(), tt.wantShape) || !slices.Equal(out.Data(), tt.want) {
+				t.Errorf("got %v %v, want %v %v", out.Shape(), out.Data(), tt.wantShape, tt.want)
+			}
+		})
+	}
+
+	if _, err := GatherAxis(x, mustTensor(t, []int{1}, []int{3}), 1); err == nil {
+		t.Error("expected error for an out-of-range index")
+	}
+	if _, err := GatherAxis(x, mustTensor(t, []int{1}, []int{0}), 3); err == nil {
+		t.Error("expected error for an out-of-range axis")
+	}
+}
+
+func TestScatterAddAxis(t *testing.T) {
+	// Row 1 is selected twice and receives both gradients; row 2 none.
+	idx := mustTensor(t, []int{3}, []int{1, 0, -2})
+	grad := mustTensor(t, []int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+	out, err := ScatterAddAxis(numeric.Float32Ops{}, grad, idx, []int{2, 3}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{2, 4, 0, 5, 10, 0}; !slices.Equal(out.Data(), want) {
+		t.Errorf("got %v, want %v", out.Data(), want)
+	}
+	if _, err := ScatterAddAxis(numeric.Float32Ops{}, grad, idx, []int{2, 3}, 0); err == nil {
+		t.Error("expected error for a gradient of the wrong shape")
+	}
+}
+
+func TestTakeAlongAxis(t *testing.T) {
+	logits := mustTensor(t, []int{3, 4}, []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+
+	// Gather each row's label logit.
+	labels := mustTensor(t, []int{3, 1}, []int{2, 0, -1})
+	out, err := TakeAlongAxis(logits, labels, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Shape(), []int{3, 1}) || !slices.Equal(out.Data(), []float32{2, 4, 11}) {
+		t.Errorf("labels: got %v %v, want [3 1] [2 4 11]", out.Shape(), out.Data())
+	}
+
+	// Pick along axis 0 with indices smaller than data on the other axis.
+	rows := mustTensor(t, []int{2, 2}, []int{2, 0, 1, 1})
+	out, err = TakeAlongAxis(logits, rows, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Data(), []float32{8, 1, 4, 5}) {
+		t.Errorf("axis 0: got %v, want [8 1 4 5]", out.Data())
+	}
+
+	grad, err := ScatterAddAlongAxis(numeric.Float32Ops{}, mustTensor(t, []int{2, 2}, []float32{1, 2, 3, 4}), rows, []int{3, 4}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{0, 2, 0, 0, 3, 4, 0, 0, 1, 0, 0, 0}; !slices.Equal(grad.Data(), want) {
+		t.Errorf("scatter: got %v, want %v", grad.Data(), want)
+	}
+
+	if _, err := TakeAlongAxis(logits, mustTensor(t, []int{3}, []int{0, 0, 0}), 1); err == nil {
+		t.Error("expected error for indices of a different rank")
+	}
+	if _, err := TakeAlongAxis(logits, mustTensor(t, []int{4, 1}, []int{0, 0, 0, 0}), 1); err == nil {
+		t.Error("expected error for indices larger than data")
+	}
+}
+
+func TestAxisNodes_Backward(t *testing.T) {
+	engine := newEngine()
+	ctx := context.Background()
+	x := mustTensor(t, []int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+
+	node, err := BuildGather[float32](engine, numeric.Float32Ops{}, "g", nil, map[string]interface{}{"axis": int64(-1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := node.(*AxisGather[float32]); !ok {
+		t.Fatalf("BuildGather with axis -1 returned %T, want *AxisGather", node)
+	}
+	idx := mustTensor(t, []int{2}, []float32{2, 2})
+	out, err := node.Forward(ctx, x, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Data(), []float32{3, 3, 6, 6}) {
+		t.Errorf("Gather forward = %v, want [3 3 6 6]", out.Data())
+	}
+	grads, err := node.Backward(ctx, types.FullBackprop, mustTensor(t, []int{2, 2}, []float32{1, 1, 1, 1}), x, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(grads[0].Data(), []float32{0, 0, 2, 0, 0, 2}) || grads[1] != nil {
+		t.Errorf("Gather grads = %v, %v; want [0 0 2 0 0 2], nil", grads[0].Data(), grads[1])
+	}
+
+	elems, err := BuildGatherElements[float32](engine, numeric.Float32Ops{}, "ge", nil, map[string]interface{}{"axis": int64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elems.OpType() != "GatherElements" {
+		t.Errorf("OpType = %q, want GatherElements", elems.OpType())
+	}
+	// Top-2 routing picks experts [2, 0] for token 0 and [1, 2] for token 1.
+	route := mustTensor(t, []int{2, 2}, []float32{2, 0, 1, 2})
+	out, err = elems.Forward(ctx, x, route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.Data(), []float32{3, 1, 5, 6}) {
+		t.Errorf("GatherElements forward = %v, want [3 1 5 6]", out.Data())
+	}
+	grads, err = elems.Backward(ctx, types.FullBackprop, mustTensor(t, []int{2, 2}, []float32{1, 2, 3, 4}), x, route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(grads[0].Data(), []float32{2, 0, 1, 0, 3, 4}) {
+		t.Errorf("GatherElements grad = %v, want [2 0 1 0 3 4]", grads[0].Data())
+	}
+	if _, err := elems.Backward(ctx, types.FullBackprop, nil, x, route); err == nil {
+		t.Error("expected error without an output gradient")
+	}
+}
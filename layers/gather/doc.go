@@ -1,4 +1,7 @@
-// Package gather provides the Gather layer for embedding-table lookup.
+// Package gather provides the Gather layer for embedding-table lookup, and
+// index selection along arbitrary axes: GatherAxis (ONNX Gather with an
+// axis) and TakeAlongAxis (ONNX GatherElements), with the scatter-add
+// functions that form their backward passes.
 //
 // Stability: stable
 package gather
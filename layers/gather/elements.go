@@ -0,0 +1,178 @@
+package gather
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// AxisGather is a Gather node that selects along an arbitrary axis with
+// GatherAxis. It takes (data, indices) inputs, with indices carried in T as
+// elsewhere in zerfoo.
+type AxisGather[T tensor.Numeric] struct {
+	engine      compute.Engine[T]
+	axis        int
+	outputShape []int
+}
+
+// NewAxisGather creates a Gather node that selects along axis.
+func NewAxisGather[T tensor.Numeric](engine compute.Engine[T], axis int) *AxisGather[T] {
+	return &AxisGather[T]{engine: engine, axis: axis}
+}
+
+// Forward gathers inputs[0] along the axis at the indices in inputs[1].
+func (g *AxisGather[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("Gather layer expects 2 inputs (data, indices), got %d", len(inputs))
+	}
+	indices, err := toIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	out, err := GatherAxis(inputs[0], indices, g.axis)
+	if err != nil {
+		return nil, err
+	}
+	g.outputShape = out.Shape()
+	return out, nil
+}
+
+// Backward scatter-adds the output gradient into the gathered entries of
+// data. The indices get no gradient.
+func (g *AxisGather[T]) Backward(_ context.Context, _ types.BackwardMode, outputGradient *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 || outputGradient == nil {
+		return nil, fmt.Errorf("Gather backward requires the output gradient and the (data, indices) inputs")
+	}
+	indices, err := toIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	dData, err := ScatterAddAxis(g.engine.Ops(), outputGradient, indices, inputs[0].Shape(), g.axis)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{dData, nil}, nil
+}
+
+// OpType returns "Gather".
+func (g *AxisGather[T]) OpType() string { return "Gather" }
+
+// Attributes returns the gather axis.
+func (g *AxisGather[T]) Attributes() map[string]interface{} {
+	return map[string]interface{}{"axis": g.axis}
+}
+
+// OutputShape returns the output shape from the last forward call.
+func (g *AxisGather[T]) OutputShape() []int { return g.outputShape }
+
+// Parameters returns nil (no trainable parameters).
+func (g *AxisGather[T]) Parameters() []*graph.Parameter[T] { return nil }
+
+// GatherElements picks one entry of data along an axis for every element of
+// indices with TakeAlongAxis. It takes (data, indices) inputs.
+type GatherElements[T tensor.Numeric] struct {
+	engine      compute.Engine[T]
+	axis        int
+	outputShape []int
+}
+
+// NewGatherElements creates a GatherElements node that picks along axis.
+func NewGatherElements[T tensor.Numeric](engine compute.Engine[T], axis int) *GatherElements[T] {
+	return &GatherElements[T]{engine: engine, axis: axis}
+}
+
+// Forward picks from inputs[0] along the axis at the indices in inputs[1].
+func (g *GatherElements[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("GatherElements expects 2 inputs (data, indices), got %d", len(inputs))
+	}
+	indices, err := toIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	out, err := TakeAlongAxis(inputs[0], indices, g.axis)
+	if err != nil {
+		return nil, err
+	}
+	g.outputShape = out.Shape()
+	return out, nil
+}
+
+// Backward scatter-adds the output gradient into the picked entries of
+// data. The indices get no gradient.
+func (g *GatherElements[T]) Backward(_ context.Context, _ types.BackwardMode, outputGradient *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 || outputGradient == nil {
+		return nil, fmt.Errorf("GatherElements backward requires the output gradient and the (data, indices) inputs")
+	}
+	indices, err := toIndices(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	dData, err := ScatterAddAlongAxis(g.engine.Ops(), outputGradient, indices, inputs[0].Shape(), g.axis)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{dData, nil}, nil
+}
+
+// OpType returns "GatherElements".
+func (g *GatherElements[T]) OpType() string { return "GatherElements" }
+
+// Attributes returns the gather axis.
+func (g *GatherElements[T]) Attributes() map[string]interface{} {
+	return map[string]interface{}{"axis": g.axis}
+}
+
+// OutputShape returns the output shape from the last forward call.
+func (g *GatherElements[T]) OutputShape() []int { return g.outputShape }
+
+// Parameters returns nil (no trainable parameters).
+func (g *GatherElements[T]) Parameters() []*graph.Parameter[T] { return nil }
+
+// BuildGatherElements constructs a GatherElements node. Supported attribute
+// keys: "axis" (int64 or int, default 0).
+func BuildGatherElements[T tensor.Numeric](
+	engine compute.Engine[T],
+	_ numeric.Arithmetic[T],
+	_ string,
+	_ map[string]*graph.Parameter[T],
+	attrs map[string]interface{},
+) (graph.Node[T], error) {
+	return NewGatherElements(engine, axisAttr(attrs)), nil
+}
+
+// axisAttr reads the "axis" attribute, defaulting to 0.
+func axisAttr(attrs map[string]interface{}) int {
+	switch v := attrs["axis"].(type) {
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// toIndices converts an index tensor carried in T to int.
+func toIndices[T tensor.Numeric](t *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[int], error) {
+	data := t.Data()
+	idx := make([]int, len(data))
+	for i, v := range data {
+		idx[i] = int(float64(v))
+	}
+	indices, err := tensor.New(t.Shape(), idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create int indices tensor: %w", err)
+	}
+	return indices, nil
+}
+
+// Statically assert that the types implement the graph.Node interface.
+var (
+	_ graph.Node[float32] = (*AxisGather[float32])(nil)
+	_ graph.Node[float32] = (*GatherElements[float32])(nil)
+)
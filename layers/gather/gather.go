@@ -238,7 +238,7 @@ func (g *Gather[T]) Backward(ctx context.Context, mode types.BackwardMode, outpu
 		return nil, err
 	}
 
-	if err := g.engine.ScatterAdd(ctx, dParams, indices, outputGradient); err != nil {
+	if err := ScatterAdd(ctx, g.engine, dParams, indices, outputGradient); err != nil {
 		return nil, err
 	}
 
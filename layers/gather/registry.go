@@ -13,8 +13,9 @@ import (
 // BuildGather constructs a Gather layer. For embedding-style nodes whose name
 // maps to a known weight parameter, weights are embedded in the layer.
 // For "gather from shape" nodes where the indices are constant, the indices
-// are embedded in the layer. All other Gather nodes operate as general ONNX
-// Gather (axis-0 indexing).
+// are embedded in the layer. Nodes with a non-zero "axis" attribute gather
+// along that axis with GatherAxis. All other Gather nodes operate as general
+// ONNX Gather (axis-0 indexing).
 func BuildGather[T tensor.Numeric](
 	engine compute.Engine[T],
 	_ numeric.Arithmetic[T],
@@ -57,6 +58,11 @@ func BuildGather[T tensor.Numeric](
 		}
 	}
 
+	// Gather along a non-leading axis selects with GatherAxis.
+	if axis := axisAttr(attrs); axis != 0 {
+		return NewAxisGather(engine, axis), nil
+	}
+
 	// General-purpose Gather: no embedded weights, takes (data, indices) inputs.
 	return New[T](engine), nil
 }
@@ -0,0 +1,130 @@
+package gather
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/internal/xblas"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// scatterParallelThreshold is the number of accumulated elements below
+// which scatterAddRows runs serially; sorting and spawning workers costs
+// more than it saves on small gradients.
+const scatterParallelThreshold = 1 << 16
+
+// ScatterAdd adds row i of dOut to row indices[i] of table, like
+// Engine.ScatterAdd. On the CPU engine it runs ScatterAddRows; other engines
+// keep their own kernel, since their tensors live on the device.
+func ScatterAdd[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], table *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], dOut *tensor.TensorNumeric[T]) error {
+	if _, isCPU := engine.(*compute.CPUEngine[T]); isCPU {
+		return ScatterAddRows(engine.Ops(), table, indices.Data(), dOut)
+	}
+	return engine.ScatterAdd(ctx, table, indices, dOut)
+}
+
+// ScatterAddRows adds row i of src to row indices[i] of dst, in place. A row
+// of dst is everything below its first axis, so a [vocab, dim] embedding
+// gradient takes [N, dim] rows and a [positions, heads, headDim] KV
+// gradient takes all heads of a position as one [N, heads, headDim] row.
+//
+// Large updates are sorted by destination row and split into segments of
+// equal index; workers accumulate whole segments in parallel, so no two
+// workers write the same row, and float32 rows are added with the SIMD
+// kernels. Each row sums its updates in input order, so the result is
+// identical to a serial loop.
+func ScatterAddRows[T tensor.Numeric](ops numeric.Arithmetic[T], dst *tensor.TensorNumeric[T], indices []int, src *tensor.TensorNumeric[T]) error {
+	shape := dst.Shape()
+	if len(shape) == 0 {
+		return fmt.Errorf("ScatterAddRows: dst must have at least one axis")
+	}
+	rows := shape[0]
+	dim := 1
+	for _, d := range shape[1:] {
+		dim *= d
+	}
+	if src.Size() != len(indices)*dim {
+		return fmt.Errorf("ScatterAddRows: src shape %v does not hold %d rows of dst shape %v",
+			src.Shape(), len(indices), shape)
+	}
+	for _, k := range indices {
+		if k < 0 || k >= rows {
+			return fmt.Errorf("ScatterAddRows: index %d out of bounds [0,%d)", k, rows)
+		}
+	}
+	scatterAddRows(ops, dst.Data(), indices, src.Data(), dim)
+	return nil
+}
+
+// scatterAddRows adds src row i to dst row indices[i] for rows of dim
+// elements. Indices must be in range.
+func scatterAddRows[T tensor.Numeric](ops numeric.Arithmetic[T], dst []T, indices []int, src []T, dim int) {
+	if dim == 0 || len(indices) == 0 {
+		return
+	}
+	addRow := rowAdder(ops, dst, src, dim)
+	if len(indices)*dim < scatterParallelThreshold {
+		for i, k := range indices {
+			addRow(k, i)
+		}
+		return
+	}
+
+	// Sort update positions by destination row, keeping input order within
+	// a row, and mark where each row's run begins.
+	order := make([]int, len(indices))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(indices[a], indices[b]) })
+	segments := make([]int, 0, len(order)+1)
+	for i, pos := range order {
+		if i == 0 || indices[pos] != indices[order[i-1]] {
+			segments = append(segments, i)
+		}
+	}
+	segments = append(segments, len(order))
+
+	nSeg := len(segments) - 1
+	workers := min(runtime.GOMAXPROCS(0), nSeg)
+	chunk := (nSeg + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < nSeg; lo += chunk {
+		hi := min(lo+chunk, nSeg)
+		wg.Go(func() {
+			for s := lo; s < hi; s++ {
+				run := order[segments[s]:segments[s+1]]
+				k := indices[run[0]]
+				for _, i := range run {
+					addRow(k, i)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// rowAdder returns a function adding src row i into dst row k, for rows of
+// dim elements. The element type is resolved here, once per scatter, so the
+// per-row calls carry no interface conversions.
+func rowAdder[T tensor.Numeric](ops numeric.Arithmetic[T], dst, src []T, dim int) func(k, i int) {
+	if d, ok := any(dst).([]float32); ok {
+		s := any(src).([]float32)
+		return func(k, i int) {
+			row := &d[k*dim]
+			xblas.VaddF32(row, row, &s[i*dim], dim)
+		}
+	}
+	return func(k, i int) {
+		row := dst[k*dim:][:dim]
+		for j, v := range src[i*dim:][:dim] {
+			row[j] = ops.Add(row[j], v)
+		}
+	}
+}
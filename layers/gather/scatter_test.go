@@ -0,0 +1,109 @@
+package gather
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestScatterAddRows(t *testing.T) {
+	// A [positions, heads, headDim] KV gradient: each index moves all heads
+	// of a position at once.
+	dst := mustTensor(t, []int{3, 2, 2}, make([]float32, 12))
+	src := mustTensor(t, []int{3, 2, 2}, []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	if err := ScatterAddRows(numeric.Float32Ops{}, dst, []int{2, 0, 2}, src); err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{5, 6, 7, 8, 0, 0, 0, 0, 10, 12, 14, 16}
+	if !slices.Equal(dst.Data(), want) {
+		t.Errorf("got %v, want %v", dst.Data(), want)
+	}
+
+	if err := ScatterAddRows(numeric.Float32Ops{}, dst, []int{3, 0, 0}, src); err == nil {
+		t.Error("expected error for an out-of-bounds index")
+	}
+	if err := ScatterAddRows(numeric.Float32Ops{}, dst, []int{0, 1}, src); err == nil {
+		t.Error("expected error for a src with the wrong number of rows")
+	}
+}
+
+// TestScatterAddRows_Parallel checks that the sorted, segmented parallel
+// path gives exactly the serial result, for float32 (SIMD rows) and int
+// (generic rows).
+func TestScatterAddRows_Parallel(t *testing.T) {
+	const vocab, dim = 50, 64
+	n := 2 * scatterParallelThreshold / dim
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = (i * 7919) % vocab
+	}
+
+	t.Run("float32", func(t *testing.T) {
+		src := make([]float32, n*dim)
+		for i := range src {
+			src[i] = float32(i%13) * 0.25
+		}
+		want := make([]float32, vocab*dim)
+		for i, k := range indices {
+			for j := range dim {
+				want[k*dim+j] += src[i*dim+j]
+			}
+		}
+		dst := mustTensor(t, []int{vocab, dim}, make([]float32, vocab*dim))
+		if err := ScatterAddRows(numeric.Float32Ops{}, dst, indices, mustTensor(t, []int{n, dim}, src)); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(dst.Data(), want) {
+			t.Error("parallel float32 result differs from the serial sum")
+		}
+	})
+
+	t.Run("int", func(t *testing.T) {
+		src := make([]int, n*dim)
+		for i := range src {
+			src[i] = i % 13
+		}
+		want := make([]int, vocab*dim)
+		for i, k := range indices {
+			for j := range dim {
+				want[k*dim+j] += src[i*dim+j]
+			}
+		}
+		dst := mustTensor(t, []int{vocab, dim}, make([]int, vocab*dim))
+		if err := ScatterAddRows(numeric.IntOps{}, dst, indices, mustTensor(t, []int{n, dim}, src)); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(dst.Data(), want) {
+			t.Error("parallel int result differs from the serial sum")
+		}
+	})
+}
+
+func TestScatterAdd_CPUEngine(t *testing.T) {
+	table := mustTensor(t, []int{3, 2}, make([]float32, 6))
+	dOut := mustTensor(t, []int{2, 2}, []float32{1, 2, 3, 4})
+	if err := ScatterAdd(context.Background(), newEngine(), table, mustTensor(t, []int{2}, []int{1, 1}), dOut); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{0, 0, 4, 6, 0, 0}; !slices.Equal(table.Data(), want) {
+		t.Errorf("got %v, want %v", table.Data(), want)
+	}
+}
+
+func BenchmarkScatterAddRows(b *testing.B) {
+	const vocab, dim, n = 32000, 512, 4096
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = (i * 7919) % vocab
+	}
+	src, _ := tensor.New[float32]([]int{n, dim}, make([]float32, n*dim))
+	dst, _ := tensor.New[float32]([]int{vocab, dim}, make([]float32, vocab*dim))
+	for b.Loop() {
+		if err := ScatterAddRows(numeric.Float32Ops{}, dst, indices, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
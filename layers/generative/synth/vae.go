@@ -11,6 +11,7 @@ import (
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/zerfoo/layers/functional"
 	"github.com/zerfoo/zerfoo/training/optimizer"
 )
@@ -320,13 +321,13 @@ func (v *MarketVAE) linearBwd(ctx context.Context, input, dOutput *tensor.Tensor
 	// dW = dOutput^T @ input → [outDim, inDim]
 	dOutputT, _ := v.engine.Transpose(ctx, dOutput, []int{1, 0})
 	dW, _ := v.engine.MatMul(ctx, dOutputT, input)
-	_ = wParam.AddGradient(dW)
+	_ = components.AddGradient(ctx, wParam, dW)
 
 	// dB = dOutput reshaped to [outDim]
 	dBData := make([]float64, outDim)
 	copy(dBData, dOutput.Data())
 	dB, _ := tensor.New[float64]([]int{outDim}, dBData)
-	_ = bParam.AddGradient(dB)
+	_ = components.AddGradient(ctx, bParam, dB)
 
 	return dInput
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/zerfoo/float16"
 	"github.com/zerfoo/float8"
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -277,7 +279,7 @@ func (ln *LayerNormalization[T]) Backward(ctx context.Context, mode types.Backwa
 	}
 
 	if ln.useMixedBackward {
-		return ln.backwardMixedCPU(dOut, inputs[0])
+		return ln.backwardMixedCPU(ctx, dOut, inputs[0])
 	}
 
 	// Recompute mean, variance, and the normalized input from the live
@@ -373,10 +375,10 @@ func (ln *LayerNormalization[T]) Backward(ctx context.Context, mode types.Backwa
 		ln.gamma.Gradient = dGamma
 		ln.beta.Gradient = dBeta
 	} else {
-		if err := ln.gamma.AddGradient(dGamma); err != nil {
+		if err := components.AddGradient(ctx, ln.gamma, dGamma); err != nil {
 			return nil, err
 		}
-		if err := ln.beta.AddGradient(dBeta); err != nil {
+		if err := components.AddGradient(ctx, ln.beta, dBeta); err != nil {
 			return nil, err
 		}
 	}
@@ -479,7 +481,7 @@ func (ln *LayerNormalization[T]) Backward(ctx context.Context, mode types.Backwa
 //     it in float64 keeps intermediate magnitudes meaningful.
 //   - Sum-of-products reductions over the feature axis accumulate error
 //     linearly in float32; float64 caps accumulated error below 1e-12.
-func (ln *LayerNormalization[T]) backwardMixedCPU(dOut *tensor.TensorNumeric[T], input *tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+func (ln *LayerNormalization[T]) backwardMixedCPU(ctx context.Context, dOut *tensor.TensorNumeric[T], input *tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
 	ops := ln.engine.Ops()
 	inputShape := input.Shape()
 	nFeat := inputShape[len(inputShape)-1]
@@ -491,17 +493,19 @@ func (ln *LayerNormalization[T]) backwardMixedCPU(dOut *tensor.TensorNumeric[T],
 		total *= d
 	}
 	positions := total / nFeat
-	epsilon := lnNumericToFloat64(ln.epsilon)
+	epsilon := dtype.ToFloat64(ln.epsilon)
 
-	inputData := input.Data()
-	dOutData := dOut.Data()
-	gammaData := ln.gamma.Value.Data()
+	// Widen every operand once up front so the loops below run on float64
+	// slices rather than converting element by element.
+	inputData := dtype.Float64s(nil, input.Data())
+	dOutData := dtype.Float64s(nil, dOut.Data())
+	gammaData := dtype.Float64s(nil, ln.gamma.Value.Data())
+	dInput64 := make([]float64, total)
 
 	dInputTensor, err := tensor.New[T](inputShape, nil)
 	if err != nil {
 		return nil, err
 	}
-	dInputData := dInputTensor.Data()
 
 	dGamma64 := make([]float64, nFeat)
 	dBeta64 := make([]float64, nFeat)
@@ -519,13 +523,13 @@ func (ln *LayerNormalization[T]) backwardMixedCPU(dOut *tensor.TensorNumeric[T],
 		// immune, masking it). Recomputing here is self-contained and exact.
 		var mu float64
 		for i := 0; i < nFeat; i++ {
-			mu += lnNumericToFloat64(inputData[base+i])
+			mu += inputData[base+i]
 		}
 		mu /= nFeatF
 
 		var variance float64
 		for i := 0; i < nFeat; i++ {
-			d := lnNumericToFloat64(inputData[base+i]) - mu
+			d := inputData[base+i] - mu
 			variance += d * d
 		}
 		variance /= nFeatF
@@ -535,18 +539,18 @@ func (ln *LayerNormalization[T]) backwardMixedCPU(dOut *tensor.TensorNumeric[T],
 
 		var sumDNorm, sumDNormXMu float64
 		for i := 0; i < nFeat; i++ {
-			gi := lnNumericToFloat64(gammaData[i])
-			dOutI := lnNumericToFloat64(dOutData[base+i])
-			xMinusMu := lnNumericToFloat64(inputData[base+i]) - mu
+			gi := gammaData[i]
+			dOutI := dOutData[base+i]
+			xMinusMu := inputData[base+i] - mu
 			dNorm := dOutI * gi
 			sumDNorm += dNorm
 			sumDNormXMu += dNorm * xMinusMu
 		}
 
 		for i := 0; i < nFeat; i++ {
-			gi := lnNumericToFloat64(gammaData[i])
-			dOutI := lnNumericToFloat64(dOutData[base+i])
-			xMinusMu := lnNumericToFloat64(inputData[base+i]) - mu
+			gi := gammaData[i]
+			dOutI := dOutData[base+i]
+			xMinusMu := inputData[base+i] - mu
 			normed := xMinusMu / sigma
 
 			dNorm := dOutI * gi
@@ -554,12 +558,14 @@ func (ln *LayerNormalization[T]) backwardMixedCPU(dOut *tensor.TensorNumeric[T],
 			term2 := xMinusMu * sumDNormXMu / (nFeatF * sigma3)
 			term3 := sumDNorm / (nFeatF * sigma)
 
-			dInputData[base+i] = ops.FromFloat64(term1 - term2 - term3)
+			dInput64[base+i] = term1 - term2 - term3
 			dGamma64[i] += dOutI * normed
 			dBeta64[i] += dOutI
 		}
 	}
 
+	dtype.FromFloat64s(ops, dInputTensor.Data(), dInput64)
+
 	dGammaTensor, err := tensor.New[T]([]int{nFeat}, nil)
 	if err != nil {
 		return nil, err
@@ -568,62 +574,19 @@ func (ln *LayerNormalization[T]) backwardMixedCPU(dOut *tensor.TensorNumeric[T],
 	if err != nil {
 		return nil, err
 	}
-	dGammaData := dGammaTensor.Data()
-	dBetaData := dBetaTensor.Data()
-	for i := 0; i < nFeat; i++ {
-		dGammaData[i] = ops.FromFloat64(dGamma64[i])
-		dBetaData[i] = ops.FromFloat64(dBeta64[i])
-	}
+	dtype.FromFloat64s(ops, dGammaTensor.Data(), dGamma64)
+	dtype.FromFloat64s(ops, dBetaTensor.Data(), dBeta64)
 
-	if err := ln.gamma.AddGradient(dGammaTensor); err != nil {
+	if err := components.AddGradient(ctx, ln.gamma, dGammaTensor); err != nil {
 		return nil, err
 	}
-	if err := ln.beta.AddGradient(dBetaTensor); err != nil {
+	if err := components.AddGradient(ctx, ln.beta, dBetaTensor); err != nil {
 		return nil, err
 	}
 
 	return []*tensor.TensorNumeric[T]{dInputTensor}, nil
 }
 
-// lnNumericToFloat64 converts a tensor.Numeric value to float64. This is
-// duplicated from training/optimizer and training/loss to keep this
-// package self-contained. Consolidate to a shared helper if a fourth
-// duplicate emerges.
-func lnNumericToFloat64[T tensor.Numeric](v T) float64 {
-	switch val := any(v).(type) {
-	case float32:
-		return float64(val)
-	case float64:
-		return val
-	case int:
-		return float64(val)
-	case int8:
-		return float64(val)
-	case int16:
-		return float64(val)
-	case int32:
-		return float64(val)
-	case int64:
-		return float64(val)
-	case uint:
-		return float64(val)
-	case uint8:
-		return float64(val)
-	case uint32:
-		return float64(val)
-	case uint64:
-		return float64(val)
-	case float16.Float16:
-		return float64(val.ToFloat32())
-	case float16.BFloat16:
-		return float64(val.ToFloat32())
-	case float8.Float8:
-		return val.ToFloat64()
-	default:
-		return 0
-	}
-}
-
 // OpType returns the operation type of the LayerNormalization layer.
 func (ln *LayerNormalization[T]) OpType() string {
 	return "LayerNormalization"
@@ -5,6 +5,7 @@ import (
 	"math"
 	"testing"
 
+	"github.com/zerfoo/zerfoo/internal/dtype"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
@@ -92,8 +93,8 @@ func layerNormLiveInputCase[T tensor.Numeric](
 
 	const tol = 1e-5
 	for i := range got {
-		g := lnNumericToFloat64(got[i])
-		w := lnNumericToFloat64(refData[i])
+		g := dtype.ToFloat64(got[i])
+		w := dtype.ToFloat64(refData[i])
 		if math.IsNaN(g) || math.IsInf(g, 0) {
 			t.Fatalf("gradient %d non-finite: %v (backward read stale forward state)", i, g)
 		}
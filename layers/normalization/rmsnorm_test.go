@@ -3,9 +3,11 @@ package normalization
 import (
 	"context"
 	"math"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/zerfoo/zerfoo/layers/components"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -684,3 +686,29 @@ func rmsnormLoss(t *testing.T, ctx context.Context, engine compute.Engine[float3
 	}
 	return sum
 }
+
+// TestRMSNorm_SeededFusedPath tests that a layer built under a seeded engine
+// keeps the CPU engine, and so takes the fused CPU kernel.
+func TestRMSNorm_SeededFusedPath(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+
+	release := components.Seed[float32](engine, 42)
+	rms, err := NewRMSNorm[float32]("seeded", engine, ops, 4)
+	release()
+	testutils.AssertNoError(t, err, "NewRMSNorm failed")
+
+	if _, ok := rms.engine.(*compute.CPUEngine[float32]); !ok {
+		t.Fatalf("layer engine is %T, want *compute.CPUEngine[float32]", rms.engine)
+	}
+
+	input, err := tensor.New[float32]([]int{2, 4}, []float32{1, 2, 3, 4, 5, 6, 7, 8})
+	testutils.AssertNoError(t, err, "failed to create input")
+	output, err := rms.Forward(context.Background(), input)
+	testutils.AssertNoError(t, err, "Forward failed")
+	want, _, err := compute.FusedRMSNorm(input, rms.gain.Value, rms.epsilon)
+	testutils.AssertNoError(t, err, "FusedRMSNorm failed")
+	if !slices.Equal(output.Data(), want.Data()) {
+		t.Errorf("Forward = %v, want the fused kernel's %v", output.Data(), want.Data())
+	}
+}
@@ -0,0 +1,99 @@
+package preprocessing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// Bucketize maps each value to the index of its bucket: the number of
+// fitted boundaries less than or equal to it, from 0 to len(boundaries).
+// NaN values stay NaN. The output has the input's shape.
+type Bucketize[T tensor.Float] struct {
+	boundaries  *graph.Parameter[T]
+	outputShape []int
+}
+
+// NewBucketize creates a Bucketize node from fitted boundaries, which must
+// be sorted and distinct.
+func NewBucketize[T tensor.Float](boundaries *graph.Parameter[T]) (*Bucketize[T], error) {
+	if err := checkStatistic("Bucketize", "boundaries", boundaries); err != nil {
+		return nil, err
+	}
+	b := boundaries.Value.Data()
+	for i := 1; i < len(b); i++ {
+		if !(b[i-1] < b[i]) {
+			return nil, fmt.Errorf("Bucketize: boundaries must be sorted and distinct, got %v", b)
+		}
+	}
+	return &Bucketize[T]{boundaries: boundaries}, nil
+}
+
+// FitBucketize places boundaries at the quantiles of x's non-NaN values
+// that split them into buckets equal-frequency buckets. Repeated quantiles
+// are merged, so skewed data can yield fewer buckets. The parameter is
+// named name+"_boundaries".
+func FitBucketize[T tensor.Float](name string, x *tensor.TensorNumeric[T], buckets int) (*Bucketize[T], error) {
+	if buckets < 2 {
+		return nil, fmt.Errorf("Bucketize: need at least 2 buckets, got %d", buckets)
+	}
+	vs := values(x)
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("Bucketize: data has no values")
+	}
+	var bounds []T
+	for i := 1; i < buckets; i++ {
+		bounds = append(bounds, vs[i*len(vs)/buckets])
+	}
+	bounds = slices.Compact(bounds)
+	p, err := newStatistic(name+"_boundaries", bounds)
+	if err != nil {
+		return nil, err
+	}
+	return NewBucketize(p)
+}
+
+// OpType returns "Bucketize".
+func (b *Bucketize[T]) OpType() string { return "Bucketize" }
+
+// Attributes returns nil; the boundaries are a parameter.
+func (b *Bucketize[T]) Attributes() map[string]interface{} { return nil }
+
+// OutputShape returns the shape of the last output.
+func (b *Bucketize[T]) OutputShape() []int { return b.outputShape }
+
+// Parameters returns the boundaries.
+func (b *Bucketize[T]) Parameters() []*graph.Parameter[T] { return []*graph.Parameter[T]{b.boundaries} }
+
+// Forward returns the bucket index of each value of inputs[0].
+func (b *Bucketize[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("Bucketize expects 1 input, got %d", len(inputs))
+	}
+	bounds := b.boundaries.Value.Data()
+	out := append([]T(nil), inputs[0].Data()...)
+	for i, v := range out {
+		if isNaN(v) {
+			continue
+		}
+		k, found := slices.BinarySearch(bounds, v)
+		if found {
+			k++
+		}
+		out[i] = T(k)
+	}
+	b.outputShape = inputs[0].Shape()
+	return tensor.New(b.outputShape, out)
+}
+
+// Backward returns a nil gradient for the input: bucket indices are
+// piecewise constant.
+func (b *Bucketize[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	return make([]*tensor.TensorNumeric[T], len(inputs)), nil
+}
+
+var _ graph.Node[float32] = (*Bucketize[float32])(nil)
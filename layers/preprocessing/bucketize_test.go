@@ -0,0 +1,42 @@
+package preprocessing
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestBucketize(t *testing.T) {
+	ctx := context.Background()
+	nan := float32(math.NaN())
+	data := make([]float32, 100)
+	for i := range data {
+		data[i] = float32(i)
+	}
+	b, err := FitBucketize("age", mustTensor(t, []int{100}, data), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Parameters()[0].Value.Data(); !near(got, []float32{25, 50, 75}) {
+		t.Fatalf("boundaries = %v, want [25 50 75]", got)
+	}
+	y, err := b.Forward(ctx, mustTensor(t, []int{6}, []float32{-1, 24.5, 25, 60, 100, nan}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{0, 0, 1, 2, 3, nan}; !near(y.Data(), want) {
+		t.Errorf("Forward = %v, want %v", y.Data(), want)
+	}
+
+	// Heavily repeated values merge boundaries.
+	skewed, err := FitBucketize("skewed", mustTensor(t, []int{6}, []float32{0, 0, 0, 0, 0, 9}), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := skewed.Parameters()[0].Value.Data(); !near(got, []float32{0}) {
+		t.Errorf("skewed boundaries = %v, want [0]", got)
+	}
+	if _, err := FitBucketize("age", mustTensor(t, []int{100}, data), 1); err == nil {
+		t.Error("1 bucket accepted")
+	}
+}
@@ -0,0 +1,21 @@
+// Package preprocessing provides graph nodes that apply fitted feature
+// transforms inside a model: standardization, imputation, one-hot encoding,
+// and bucketization.
+//
+// Each node is created by a Fit function that computes its statistics from
+// training data, for example FitStandardize for per-feature means and
+// standard deviations. The statistics are exposed as parameters, so they are
+// saved and loaded with the model's weights and a saved model needs no
+// separate preprocessing configuration. They have no gradient, so optimizers
+// and gradient clipping leave them unchanged during training.
+//
+//	scaler, err := preprocessing.FitStandardize("scaler", engine, trainX)
+//	b := graph.NewBuilder[float32](engine)
+//	x := b.Input([]int{batch, features})
+//	h := b.AddNode(scaler, x)
+//
+// Missing values are NaN. Impute replaces them, and the Fit functions
+// ignore them when computing statistics.
+//
+// Stability: beta
+package preprocessing
@@ -0,0 +1,127 @@
+package preprocessing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// ImputeStrategy selects the fill value FitImpute computes for a feature.
+type ImputeStrategy int
+
+const (
+	// ImputeMean fills missing values with the feature's mean.
+	ImputeMean ImputeStrategy = iota
+	// ImputeMedian fills missing values with the feature's median.
+	ImputeMedian
+)
+
+// Impute replaces missing (NaN) values with a fitted per-feature fill value,
+// with features along the last dimension.
+type Impute[T tensor.Float] struct {
+	fill        *graph.Parameter[T]
+	outputShape []int
+}
+
+// NewImpute creates an Impute node from fitted per-feature fill values.
+func NewImpute[T tensor.Float](fill *graph.Parameter[T]) (*Impute[T], error) {
+	if err := checkStatistic("Impute", "fill values", fill); err != nil {
+		return nil, err
+	}
+	return &Impute[T]{fill: fill}, nil
+}
+
+// FitImpute computes a fill value for each feature of x, a [rows, features]
+// tensor, from its non-NaN values using strategy. A feature with no values
+// is filled with 0. The parameter is named name+"_fill".
+func FitImpute[T tensor.Float](name string, x *tensor.TensorNumeric[T], strategy ImputeStrategy) (*Impute[T], error) {
+	if strategy != ImputeMean && strategy != ImputeMedian {
+		return nil, fmt.Errorf("Impute: unknown strategy %d", strategy)
+	}
+	cols, err := columns("Impute", x)
+	if err != nil {
+		return nil, err
+	}
+	fill := make([]T, len(cols))
+	for f, col := range cols {
+		if len(col) == 0 {
+			continue
+		}
+		if strategy == ImputeMedian {
+			slices.Sort(col)
+			n := len(col)
+			fill[f] = (col[(n-1)/2] + col[n/2]) / 2
+			continue
+		}
+		var sum float64
+		for _, v := range col {
+			sum += float64(v)
+		}
+		fill[f] = T(sum / float64(len(col)))
+	}
+	p, err := newStatistic(name+"_fill", fill)
+	if err != nil {
+		return nil, err
+	}
+	return NewImpute(p)
+}
+
+// OpType returns "Impute".
+func (m *Impute[T]) OpType() string { return "Impute" }
+
+// Attributes returns nil; the fill values are a parameter.
+func (m *Impute[T]) Attributes() map[string]interface{} { return nil }
+
+// OutputShape returns the shape of the last output.
+func (m *Impute[T]) OutputShape() []int { return m.outputShape }
+
+// Parameters returns the fill values.
+func (m *Impute[T]) Parameters() []*graph.Parameter[T] { return []*graph.Parameter[T]{m.fill} }
+
+// Forward returns inputs[0] with each NaN replaced by its feature's fill
+// value.
+func (m *Impute[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("Impute expects 1 input, got %d", len(inputs))
+	}
+	fill := m.fill.Value.Data()
+	if err := checkFeatures("Impute", inputs[0], len(fill)); err != nil {
+		return nil, err
+	}
+	out := append([]T(nil), inputs[0].Data()...)
+	for i, v := range out {
+		if isNaN(v) {
+			out[i] = fill[i%len(fill)]
+		}
+	}
+	m.outputShape = inputs[0].Shape()
+	return tensor.New(inputs[0].Shape(), out)
+}
+
+// Backward passes dOut through to the values that were present and returns
+// zero for the imputed ones. It needs the original input.
+func (m *Impute[T]) Backward(_ context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("Impute backward expects 1 input, got %d", len(inputs))
+	}
+	if !tensor.ShapesEqual(dOut.Shape(), inputs[0].Shape()) {
+		return nil, fmt.Errorf("Impute backward: gradient shape %v does not match input shape %v", dOut.Shape(), inputs[0].Shape())
+	}
+	dx := append([]T(nil), dOut.Data()...)
+	for i, v := range inputs[0].Data() {
+		if isNaN(v) {
+			dx[i] = 0
+		}
+	}
+	g, err := tensor.New(dOut.Shape(), dx)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{g}, nil
+}
+
+var _ graph.Node[float32] = (*Impute[float32])(nil)
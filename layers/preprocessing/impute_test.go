@@ -0,0 +1,59 @@
+package preprocessing
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/types"
+)
+
+func TestImpute(t *testing.T) {
+	ctx := context.Background()
+	nan := float32(math.NaN())
+	train := mustTensor(t, []int{4, 3}, []float32{
+		1, 10, nan,
+		2, nan, nan,
+		6, 20, nan,
+		nan, 60, nan,
+	})
+	for _, tc := range []struct {
+		name     string
+		strategy ImputeStrategy
+		want     []float32
+	}{
+		{"mean", ImputeMean, []float32{3, 30, 0}},
+		{"median", ImputeMedian, []float32{2, 20, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := FitImpute("impute", train, tc.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Parameters()[0].Value.Data(); !near(got, tc.want) {
+				t.Fatalf("fill = %v, want %v", got, tc.want)
+			}
+			x := mustTensor(t, []int{1, 3}, []float32{nan, 5, nan})
+			y, err := m.Forward(ctx, x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []float32{tc.want[0], 5, tc.want[2]}; !near(y.Data(), want) {
+				t.Errorf("Forward = %v, want %v", y.Data(), want)
+			}
+			dx, err := m.Backward(ctx, types.FullBackprop, mustTensor(t, []int{1, 3}, []float32{1, 2, 3}), x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []float32{0, 2, 0}; !near(dx[0].Data(), want) {
+				t.Errorf("Backward = %v, want %v", dx[0].Data(), want)
+			}
+		})
+	}
+	if _, err := FitImpute("impute", train, ImputeStrategy(9)); err == nil {
+		t.Error("unknown strategy accepted")
+	}
+	if _, err := FitImpute("impute", mustTensor(t, []int{3}, []float32{1, 2, 3}), ImputeMean); err == nil {
+		t.Error("1-D data accepted")
+	}
+}
@@ -0,0 +1,84 @@
+package preprocessing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// OneHot encodes categorical values as one-hot vectors over a fitted,
+// sorted list of categories. An input of shape [...] yields [..., K] for K
+// categories; values outside the list, including NaN, encode as all zeros.
+type OneHot[T tensor.Float] struct {
+	categories  *graph.Parameter[T]
+	outputShape []int
+}
+
+// NewOneHot creates a OneHot node from fitted categories, which must be
+// sorted and distinct.
+func NewOneHot[T tensor.Float](categories *graph.Parameter[T]) (*OneHot[T], error) {
+	if err := checkStatistic("OneHot", "categories", categories); err != nil {
+		return nil, err
+	}
+	cats := categories.Value.Data()
+	for i := 1; i < len(cats); i++ {
+		if !(cats[i-1] < cats[i]) {
+			return nil, fmt.Errorf("OneHot: categories must be sorted and distinct, got %v", cats)
+		}
+	}
+	return &OneHot[T]{categories: categories}, nil
+}
+
+// FitOneHot collects the distinct non-NaN values of x as the categories.
+// The parameter is named name+"_categories".
+func FitOneHot[T tensor.Float](name string, x *tensor.TensorNumeric[T]) (*OneHot[T], error) {
+	cats := slices.Compact(values(x))
+	if len(cats) == 0 {
+		return nil, fmt.Errorf("OneHot: data has no values")
+	}
+	p, err := newStatistic(name+"_categories", cats)
+	if err != nil {
+		return nil, err
+	}
+	return NewOneHot(p)
+}
+
+// OpType returns "OneHot".
+func (o *OneHot[T]) OpType() string { return "OneHot" }
+
+// Attributes returns nil; the categories are a parameter.
+func (o *OneHot[T]) Attributes() map[string]interface{} { return nil }
+
+// OutputShape returns the shape of the last output.
+func (o *OneHot[T]) OutputShape() []int { return o.outputShape }
+
+// Parameters returns the categories.
+func (o *OneHot[T]) Parameters() []*graph.Parameter[T] { return []*graph.Parameter[T]{o.categories} }
+
+// Forward one-hot encodes inputs[0].
+func (o *OneHot[T]) Forward(_ context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("OneHot expects 1 input, got %d", len(inputs))
+	}
+	cats := o.categories.Value.Data()
+	x := inputs[0].Data()
+	out := make([]T, len(x)*len(cats))
+	for i, v := range x {
+		if k, ok := slices.BinarySearch(cats, v); ok {
+			out[i*len(cats)+k] = 1
+		}
+	}
+	o.outputShape = slices.Concat(inputs[0].Shape(), []int{len(cats)})
+	return tensor.New(o.outputShape, out)
+}
+
+// Backward returns a nil gradient for the input: categories are discrete.
+func (o *OneHot[T]) Backward(_ context.Context, _ types.BackwardMode, _ *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	return make([]*tensor.TensorNumeric[T], len(inputs)), nil
+}
+
+var _ graph.Node[float32] = (*OneHot[float32])(nil)
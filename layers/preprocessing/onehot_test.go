@@ -0,0 +1,48 @@
+package preprocessing
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func TestOneHot(t *testing.T) {
+	ctx := context.Background()
+	nan := float32(math.NaN())
+	o, err := FitOneHot("color", mustTensor(t, []int{5}, []float32{3, 1, 3, nan, 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Parameters()[0].Value.Data(); !near(got, []float32{1, 3, 7}) {
+		t.Fatalf("categories = %v, want [1 3 7]", got)
+	}
+	x := mustTensor(t, []int{2, 2}, []float32{7, 1, 4, nan})
+	y, err := o.Forward(ctx, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{
+		0, 0, 1, 1, 0, 0,
+		0, 0, 0, 0, 0, 0,
+	}
+	if s := y.Shape(); len(s) != 3 || s[2] != 3 || !near(y.Data(), want) {
+		t.Errorf("Forward = %v %v, want [2 2 3] %v", s, y.Data(), want)
+	}
+	if dx, err := o.Backward(ctx, types.FullBackprop, y, x); err != nil || len(dx) != 1 || dx[0] != nil {
+		t.Errorf("Backward = %v, %v; want one nil gradient", dx, err)
+	}
+
+	unsorted, err := newStatistic("bad_categories", []float32{3, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BuildOneHot[float32](nil, nil, "bad", map[string]*graph.Parameter[float32]{"bad_categories": unsorted}, nil); err == nil {
+		t.Error("unsorted categories accepted")
+	}
+	if _, err := FitOneHot("empty", mustTensor(t, []int{1}, []float32{nan})); err == nil {
+		t.Error("FitOneHot without values succeeded")
+	}
+}
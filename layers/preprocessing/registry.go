@@ -0,0 +1,87 @@
+package preprocessing
+
+import (
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// statistic looks up the parameter name+suffix, as named by the Fit
+// functions.
+func statistic[T tensor.Float](op, name, suffix string, params map[string]*graph.Parameter[T]) (*graph.Parameter[T], error) {
+	p, ok := params[name+suffix]
+	if !ok {
+		return nil, fmt.Errorf("%s: missing parameter %q", op, name+suffix)
+	}
+	return p, nil
+}
+
+// BuildStandardize constructs a Standardize node from the parameters
+// name+"_mean" and name+"_std".
+func BuildStandardize[T tensor.Float](
+	engine compute.Engine[T],
+	_ numeric.Arithmetic[T],
+	name string,
+	params map[string]*graph.Parameter[T],
+	_ map[string]any,
+) (graph.Node[T], error) {
+	mean, err := statistic("Standardize", name, "_mean", params)
+	if err != nil {
+		return nil, err
+	}
+	std, err := statistic("Standardize", name, "_std", params)
+	if err != nil {
+		return nil, err
+	}
+	return NewStandardize(engine, mean, std)
+}
+
+// BuildImpute constructs an Impute node from the parameter name+"_fill".
+func BuildImpute[T tensor.Float](
+	_ compute.Engine[T],
+	_ numeric.Arithmetic[T],
+	name string,
+	params map[string]*graph.Parameter[T],
+	_ map[string]any,
+) (graph.Node[T], error) {
+	fill, err := statistic("Impute", name, "_fill", params)
+	if err != nil {
+		return nil, err
+	}
+	return NewImpute(fill)
+}
+
+// BuildOneHot constructs a OneHot node from the parameter
+// name+"_categories".
+func BuildOneHot[T tensor.Float](
+	_ compute.Engine[T],
+	_ numeric.Arithmetic[T],
+	name string,
+	params map[string]*graph.Parameter[T],
+	_ map[string]any,
+) (graph.Node[T], error) {
+	cats, err := statistic("OneHot", name, "_categories", params)
+	if err != nil {
+		return nil, err
+	}
+	return NewOneHot(cats)
+}
+
+// BuildBucketize constructs a Bucketize node from the parameter
+// name+"_boundaries".
+func BuildBucketize[T tensor.Float](
+	_ compute.Engine[T],
+	_ numeric.Arithmetic[T],
+	name string,
+	params map[string]*graph.Parameter[T],
+	_ map[string]any,
+) (graph.Node[T], error) {
+	bounds, err := statistic("Bucketize", name, "_boundaries", params)
+	if err != nil {
+		return nil, err
+	}
+	return NewBucketize(bounds)
+}
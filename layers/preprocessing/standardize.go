@@ -0,0 +1,130 @@
+package preprocessing
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// Standardize scales each feature to zero mean and unit variance:
+// y = (x - mean) / std, with the statistics taken per feature along the last
+// dimension.
+type Standardize[T tensor.Float] struct {
+	engine      compute.Engine[T]
+	mean, std   *graph.Parameter[T]
+	outputShape []int
+}
+
+// NewStandardize creates a Standardize node from fitted per-feature means
+// and standard deviations.
+func NewStandardize[T tensor.Float](engine compute.Engine[T], mean, std *graph.Parameter[T]) (*Standardize[T], error) {
+	if err := checkStatistic("Standardize", "mean", mean); err != nil {
+		return nil, err
+	}
+	if err := checkStatistic("Standardize", "std", std); err != nil {
+		return nil, err
+	}
+	if !tensor.ShapesEqual(mean.Value.Shape(), std.Value.Shape()) {
+		return nil, fmt.Errorf("Standardize: mean shape %v and std shape %v differ", mean.Value.Shape(), std.Value.Shape())
+	}
+	for _, s := range std.Value.Data() {
+		if !(s > 0) {
+			return nil, fmt.Errorf("Standardize: std must be positive, got %v", s)
+		}
+	}
+	return &Standardize[T]{engine: engine, mean: mean, std: std}, nil
+}
+
+// FitStandardize computes the mean and population standard deviation of
+// each feature of x, a [rows, features] tensor, ignoring NaN values. A
+// constant feature gets a standard deviation of 1, and a feature with no
+// values a mean of 0. The parameters are named name+"_mean" and
+// name+"_std".
+func FitStandardize[T tensor.Float](name string, engine compute.Engine[T], x *tensor.TensorNumeric[T]) (*Standardize[T], error) {
+	cols, err := columns("Standardize", x)
+	if err != nil {
+		return nil, err
+	}
+	mean := make([]T, len(cols))
+	std := make([]T, len(cols))
+	for f, col := range cols {
+		var sum, sq float64
+		for _, v := range col {
+			sum += float64(v)
+		}
+		m := 0.0
+		if len(col) > 0 {
+			m = sum / float64(len(col))
+		}
+		for _, v := range col {
+			sq += (float64(v) - m) * (float64(v) - m)
+		}
+		s := 1.0
+		if len(col) > 0 && sq > 0 {
+			s = math.Sqrt(sq / float64(len(col)))
+		}
+		mean[f], std[f] = T(m), T(s)
+	}
+	meanParam, err := newStatistic(name+"_mean", mean)
+	if err != nil {
+		return nil, err
+	}
+	stdParam, err := newStatistic(name+"_std", std)
+	if err != nil {
+		return nil, err
+	}
+	return NewStandardize(engine, meanParam, stdParam)
+}
+
+// OpType returns "Standardize".
+func (s *Standardize[T]) OpType() string { return "Standardize" }
+
+// Attributes returns nil; the statistics are parameters.
+func (s *Standardize[T]) Attributes() map[string]interface{} { return nil }
+
+// OutputShape returns the shape of the last output.
+func (s *Standardize[T]) OutputShape() []int { return s.outputShape }
+
+// Parameters returns the mean and standard deviation.
+func (s *Standardize[T]) Parameters() []*graph.Parameter[T] {
+	return []*graph.Parameter[T]{s.mean, s.std}
+}
+
+// Forward standardizes inputs[0].
+func (s *Standardize[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("Standardize expects 1 input, got %d", len(inputs))
+	}
+	if err := checkFeatures("Standardize", inputs[0], s.mean.Value.Shape()[0]); err != nil {
+		return nil, err
+	}
+	centered, err := s.engine.Sub(ctx, inputs[0], s.mean.Value)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.engine.Div(ctx, centered, s.std.Value)
+	if err != nil {
+		return nil, err
+	}
+	s.outputShape = out.Shape()
+	return out, nil
+}
+
+// Backward returns dOut / std, the gradient with respect to the input.
+func (s *Standardize[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if err := checkFeatures("Standardize", dOut, s.std.Value.Shape()[0]); err != nil {
+		return nil, err
+	}
+	dx, err := s.engine.Div(ctx, dOut, s.std.Value)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{dx}, nil
+}
+
+var _ graph.Node[float32] = (*Standardize[float32])(nil)
@@ -0,0 +1,137 @@
+package preprocessing
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+func mustTensor(t *testing.T, shape []int, data []float32) *tensor.TensorNumeric[float32] {
+	t.Helper()
+	x, err := tensor.New(shape, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func near(got, want []float32) bool {
+	return slices.EqualFunc(got, want, func(a, b float32) bool {
+		return a == b || math.Abs(float64(a-b)) < 1e-5 || (a != a && b != b)
+	})
+}
+
+func TestStandardize(t *testing.T) {
+	ctx := context.Background()
+	nan := float32(math.NaN())
+	// Feature 0 has mean 2 and std 1 (NaN ignored); feature 1 is constant.
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	train := mustTensor(t, []int{4, 2}, []float32{1, 5, 3, 5, nan, 5, 2, 5})
+	s, err := FitStandardize("scaler", engine, train)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mean, std := s.Parameters()[0], s.Parameters()[1]
+	if mean.Name != "scaler_mean" || std.Name != "scaler_std" {
+		t.Errorf("parameter names = %q, %q", mean.Name, std.Name)
+	}
+	if mean.Gradient != nil || std.Gradient != nil {
+		t.Error("fitted statistics have gradients")
+	}
+	wantStd := float32(math.Sqrt(2.0 / 3))
+	if !near(mean.Value.Data(), []float32{2, 5}) || !near(std.Value.Data(), []float32{wantStd, 1}) {
+		t.Fatalf("mean = %v, std = %v", mean.Value.Data(), std.Value.Data())
+	}
+
+	y, err := s.Forward(ctx, mustTensor(t, []int{1, 2}, []float32{4, 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{2 / wantStd, 2}; !near(y.Data(), want) {
+		t.Errorf("Forward = %v, want %v", y.Data(), want)
+	}
+	dx, err := s.Backward(ctx, types.FullBackprop, mustTensor(t, []int{1, 2}, []float32{1, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float32{1 / wantStd, 1}; !near(dx[0].Data(), want) {
+		t.Errorf("Backward = %v, want %v", dx[0].Data(), want)
+	}
+	if _, err := s.Forward(ctx, mustTensor(t, []int{1, 3}, []float32{1, 2, 3})); err == nil {
+		t.Error("Forward accepted 3 features")
+	}
+
+	built, err := BuildStandardize[float32](engine, nil, "scaler", map[string]*graph.Parameter[float32]{
+		"scaler_mean": mean, "scaler_std": std,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y2, _ := built.Forward(ctx, mustTensor(t, []int{1, 2}, []float32{4, 7})); !near(y2.Data(), y.Data()) {
+		t.Errorf("built node Forward = %v, want %v", y2.Data(), y.Data())
+	}
+	if _, err := BuildStandardize[float32](nil, nil, "scaler", nil, nil); err == nil {
+		t.Error("BuildStandardize without parameters succeeded")
+	}
+}
+
+// TestFittedStatisticsSurviveTraining trains a model whose graph starts with
+// fitted preprocessing and checks the optimizers leave the statistics alone.
+func TestFittedStatisticsSurviveTraining(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	x := mustTensor(t, []int{4, 2}, []float32{1, 10, 2, 20, 3, 30, float32(math.NaN()), 40})
+	impute, err := FitImpute("impute", x, ImputeMean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scaler, err := FitStandardize("scaler", engine, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dense, err := core.NewDense[float32]("dense", engine, ops, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opt := range []optimizer.Optimizer[float32]{
+		optimizer.NewSGD(engine, ops, float32(0.1)),
+		optimizer.NewAdamW[float32](engine, 0.1, 0.9, 0.999, 1e-8, 0.01),
+	} {
+		b := graph.NewBuilder[float32](engine)
+		in := b.Input([]int{4, 2})
+		g, err := b.Build(b.AddNode(dense, b.AddNode(scaler, b.AddNode(impute, in))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats := slices.Concat(impute.Parameters(), scaler.Parameters())
+		before := make([][]float32, len(stats))
+		for i, p := range stats {
+			before[i] = append([]float32(nil), p.Value.Data()...)
+		}
+		trainer := training.NewDefaultTrainer[float32](g, loss.NewMSE(engine, ops), opt, nil)
+		targets := mustTensor(t, []int{4, 1}, []float32{1, 2, 3, 4})
+		for range 3 {
+			if _, err := trainer.TrainStep(ctx, g, opt, map[graph.Node[float32]]*tensor.TensorNumeric[float32]{in: x}, targets); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i, p := range stats {
+			if !slices.Equal(p.Value.Data(), before[i]) {
+				t.Errorf("%T changed %s: %v -> %v", opt, p.Name, before[i], p.Value.Data())
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package preprocessing
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// newStatistic returns a parameter holding fitted values. It has no
+// gradient, so optimizers skip it.
+func newStatistic[T tensor.Float](name string, values []T) (*graph.Parameter[T], error) {
+	v, err := tensor.New([]int{len(values)}, values)
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Parameter[T]{Name: name, Value: v}, nil
+}
+
+// checkStatistic reports an error unless p is a non-empty vector.
+func checkStatistic[T tensor.Float](op, role string, p *graph.Parameter[T]) error {
+	if p == nil || p.Value == nil {
+		return fmt.Errorf("%s: missing %s", op, role)
+	}
+	if shape := p.Value.Shape(); len(shape) != 1 || shape[0] == 0 {
+		return fmt.Errorf("%s: %s must be a non-empty vector, got shape %v", op, role, shape)
+	}
+	return nil
+}
+
+// columns splits the rows of x, whose last dimension holds the features,
+// into per-feature columns without their NaN values.
+func columns[T tensor.Float](op string, x *tensor.TensorNumeric[T]) ([][]T, error) {
+	shape := x.Shape()
+	if len(shape) < 2 || shape[len(shape)-1] == 0 {
+		return nil, fmt.Errorf("%s: data must have shape [rows, features], got %v", op, shape)
+	}
+	features := shape[len(shape)-1]
+	cols := make([][]T, features)
+	for i, v := range x.Data() {
+		if !isNaN(v) {
+			cols[i%features] = append(cols[i%features], v)
+		}
+	}
+	return cols, nil
+}
+
+// values returns the non-NaN values of x, sorted.
+func values[T tensor.Float](x *tensor.TensorNumeric[T]) []T {
+	var vs []T
+	for _, v := range x.Data() {
+		if !isNaN(v) {
+			vs = append(vs, v)
+		}
+	}
+	slices.Sort(vs)
+	return vs
+}
+
+// checkFeatures reports an error unless x's last dimension has n features.
+func checkFeatures[T tensor.Float](op string, x *tensor.TensorNumeric[T], n int) error {
+	shape := x.Shape()
+	if len(shape) == 0 || shape[len(shape)-1] != n {
+		return fmt.Errorf("%s: input must have %d features in its last dimension, got shape %v", op, n, shape)
+	}
+	return nil
+}
+
+func isNaN[T tensor.Float](v T) bool { return v != v }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/layers/core"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -51,56 +52,18 @@ func (r *ReduceSum[T]) Parameters() []*graph.Parameter[T] {
 	return nil
 }
 
-// Forward computes the reduce sum operation.
+// Forward computes the reduce sum operation. Empty axes sum over every axis;
+// reducing every axis without keepDims yields a rank-0 tensor.
 func (r *ReduceSum[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
-	input := inputs[0]
-	shape := input.Shape()
-
-	axesMap := make(map[int]bool)
-	for _, axis := range r.axes {
-		axesMap[axis] = true
-	}
-
-	outputShape := make([]int, 0, len(shape))
-	if r.keepDims {
-		outputShape = make([]int, len(shape))
-		for i, dim := range shape {
-			if axesMap[i] {
-				outputShape[i] = 1
-			} else {
-				outputShape[i] = dim
-			}
-		}
-	} else {
-		for i, dim := range shape {
-			if !axesMap[i] {
-				outputShape = append(outputShape, dim)
-			}
-		}
+	if len(inputs) != 1 {
+		return nil, fmt.Errorf("reducesum: forward requires exactly 1 input, got %d", len(inputs))
 	}
-
-	r.outputShape = outputShape
-
-	// The compute engine's Sum method might need to be updated to handle multiple axes.
-	// For now, we'll assume it does, or we'll have to chain Sum operations.
-	// Let's assume the engine handles it for now. A proper fix would involve checking the engine capabilities.
-	if len(r.axes) == 0 {
-		// Sum over all axes
-		return r.engine.Sum(ctx, input, -1, r.keepDims)
+	out, err := core.ReduceAxes(ctx, r.engine, core.ReduceOpSum, inputs[0], r.axes, r.keepDims)
+	if err != nil {
+		return nil, fmt.Errorf("reducesum: %w", err)
 	}
-
-	// Iterative sum for now
-	tempResult := input
-
-	var err error
-	for _, axis := range r.axes {
-		tempResult, err = r.engine.Sum(ctx, tempResult, axis, r.keepDims)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return tempResult, nil
+	r.outputShape = out.Shape()
+	return out, nil
 }
 
 // Backward computes the gradients for the ReduceSum layer.
@@ -114,41 +77,42 @@ func (r *ReduceSum[T]) Backward(ctx context.Context, _ types.BackwardMode, outpu
 
 	input := inputs[0]
 	inputShape := input.Shape()
-
-	grad := outputGradient
-
-	// Build a map for quick axis lookup
-	axesMap := make(map[int]bool)
-	for _, ax := range r.axes {
-		if ax < 0 || ax >= len(inputShape) {
-			return nil, fmt.Errorf("reducesum: unsupported axis %d for backward (input has %d dimensions)", ax, len(inputShape))
+	if len(inputShape) == 0 {
+		// Summing a scalar is the identity.
+		grad, err := r.engine.Reshape(ctx, outputGradient, []int{})
+		if err != nil {
+			return nil, err
 		}
+		return []*tensor.TensorNumeric[T]{grad}, nil
+	}
+
+	axes, err := core.ReductionAxes(len(inputShape), r.axes)
+	if err != nil {
+		return nil, fmt.Errorf("reducesum: unsupported axes %v for backward: %w", r.axes, err)
+	}
+	axesMap := make(map[int]bool, len(axes))
+	for _, ax := range axes {
 		axesMap[ax] = true
 	}
 
-	if !r.keepDims {
-		// Re-insert singleton dimensions at reduced axes positions
-		reshaped := make([]int, len(inputShape))
-		outShape := grad.Shape()
-		outIdx := 0
-		for i := 0; i < len(reshaped); i++ {
-			if axesMap[i] {
-				reshaped[i] = 1
-			} else {
-				reshaped[i] = outShape[outIdx]
-				outIdx++
-			}
-		}
-		var err error
-		grad, err = r.engine.Reshape(ctx, grad, reshaped)
-		if err != nil {
-			return nil, err
+	// Re-insert singleton dimensions at reduced axes positions. With
+	// keepDims the gradient already has them; without, a full reduction
+	// leaves a scalar (or [1]) gradient.
+	reshaped := make([]int, len(inputShape))
+	for i, dim := range inputShape {
+		if axesMap[i] {
+			reshaped[i] = 1
+		} else {
+			reshaped[i] = dim
 		}
 	}
+	grad, err := r.engine.Reshape(ctx, outputGradient, reshaped)
+	if err != nil {
+		return nil, err
+	}
 
 	// Now repeat along each reduced axis to match the input shape
-	var err error
-	for _, ax := range r.axes {
+	for _, ax := range axes {
 		grad, err = r.engine.Repeat(ctx, grad, ax, inputShape[ax])
 		if err != nil {
 			return nil, err
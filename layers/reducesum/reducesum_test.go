@@ -290,3 +290,57 @@ func TestBackward_InvalidAxis_ReturnsError(t *testing.T) {
 func TestGraphNodeInterface(t *testing.T) {
 	var _ graph.Node[float32] = (*ReduceSum[float32])(nil)
 }
+
+func TestForwardBackward_ToScalar(t *testing.T) {
+	ctx := context.Background()
+	input, _ := tensor.New[float32]([]int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+
+	for _, axes := range [][]int{nil, {0, 1}, {-1, 0}} {
+		r := New[float32](newEngine(), axes, false)
+		out, err := r.Forward(ctx, input)
+		if err != nil {
+			t.Fatalf("axes %v: Forward: %v", axes, err)
+		}
+		if len(out.Shape()) != 0 || out.Data()[0] != 21 {
+			t.Fatalf("axes %v: got %v %v, want scalar 21", axes, out.Shape(), out.Data())
+		}
+		if len(r.OutputShape()) != 0 {
+			t.Errorf("axes %v: OutputShape = %v, want []", axes, r.OutputShape())
+		}
+
+		grad, _ := tensor.New[float32]([]int{}, []float32{2})
+		grads, err := r.Backward(ctx, types.FullBackprop, grad, input)
+		if err != nil {
+			t.Fatalf("axes %v: Backward: %v", axes, err)
+		}
+		if got := grads[0].Shape(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+			t.Fatalf("axes %v: grad shape = %v, want [2 3]", axes, got)
+		}
+		for i, v := range grads[0].Data() {
+			if v != 2 {
+				t.Errorf("axes %v: grad[%d] = %v, want 2", axes, i, v)
+			}
+		}
+	}
+}
+
+func TestForwardBackward_Rank0(t *testing.T) {
+	ctx := context.Background()
+	input, _ := tensor.New[float32]([]int{}, []float32{3})
+	r := New[float32](newEngine(), nil, false)
+
+	out, err := r.Forward(ctx, input)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	if len(out.Shape()) != 0 || out.Data()[0] != 3 {
+		t.Errorf("got %v %v, want scalar 3", out.Shape(), out.Data())
+	}
+	grads, err := r.Backward(ctx, types.FullBackprop, out, input)
+	if err != nil {
+		t.Fatalf("Backward: %v", err)
+	}
+	if len(grads[0].Shape()) != 0 || grads[0].Data()[0] != 3 {
+		t.Errorf("grad = %v %v, want scalar 3", grads[0].Shape(), grads[0].Data())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/zerfoo/zerfoo/layers/core"
 	"github.com/zerfoo/zerfoo/layers/gather"
 	"github.com/zerfoo/zerfoo/layers/normalization"
+	"github.com/zerfoo/zerfoo/layers/preprocessing"
 	"github.com/zerfoo/zerfoo/layers/reducesum"
 	"github.com/zerfoo/zerfoo/layers/regularization"
 	"github.com/zerfoo/zerfoo/layers/transpose"
@@ -57,6 +58,10 @@ func RegisterAll() {
 	model.RegisterLayer("Cos", core.BuildCos[float32])
 	model.RegisterLayer("Sin", core.BuildSin[float32])
 	model.RegisterLayer("ReduceMean", core.BuildReduceMean[float32])
+	model.RegisterLayer("ReduceMax", core.BuildReduceMax[float32])
+	model.RegisterLayer("ReduceMin", core.BuildReduceMin[float32])
+	model.RegisterLayer("ReduceProd", core.BuildReduceProd[float32])
+	model.RegisterLayer("ReduceLogSumExp", core.BuildReduceLogSumExp[float32])
 	model.RegisterLayer("Equal", core.BuildEqual[float32])
 	model.RegisterLayer("Greater", core.BuildGreater[float32])
 	model.RegisterLayer("Where", core.BuildWhere[float32])
@@ -77,6 +82,7 @@ func RegisterAll() {
 
 	// Gather
 	model.RegisterLayer("Gather", gather.BuildGather[float32])
+	model.RegisterLayer("GatherElements", gather.BuildGatherElements[float32])
 
 	// Normalization
 	model.RegisterLayer("RMSNorm", normalization.BuildRMSNorm[float32])
@@ -89,6 +95,12 @@ func RegisterAll() {
 	model.RegisterLayer("Dropout", regularization.BuildDropout[float32])
 	model.RegisterLayer("FeatureDropout", regularization.BuildFeatureDropout[float32])
 
+	// Preprocessing
+	model.RegisterLayer("Standardize", preprocessing.BuildStandardize[float32])
+	model.RegisterLayer("Impute", preprocessing.BuildImpute[float32])
+	model.RegisterLayer("OneHot", preprocessing.BuildOneHot[float32])
+	model.RegisterLayer("Bucketize", preprocessing.BuildBucketize[float32])
+
 	// ReduceSum
 	model.RegisterLayer("ReduceSum", reducesum.BuildReduceSum[float32])
 
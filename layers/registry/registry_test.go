@@ -43,6 +43,7 @@ func TestRegisterAll(t *testing.T) {
 		"MixtureOfExperts",
 		// Gather
 		"Gather",
+		"GatherElements",
 		// Normalization
 		"RMSNorm",
 		"LayerNormalization",
@@ -57,6 +58,10 @@ func TestRegisterAll(t *testing.T) {
 		"Cos",
 		"Sin",
 		"ReduceMean",
+		"ReduceMax",
+		"ReduceMin",
+		"ReduceProd",
+		"ReduceLogSumExp",
 		"Equal",
 		"Greater",
 		"Where",
@@ -72,6 +77,11 @@ func TestRegisterAll(t *testing.T) {
 		"Transpose",
 		// Regularization
 		"Dropout",
+		// Preprocessing
+		"Standardize",
+		"Impute",
+		"OneHot",
+		"Bucketize",
 	}
 
 	for _, opType := range expectedOps {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand/v2" //#nosec G404
 
+	"github.com/zerfoo/zerfoo/internal/determinism"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -128,6 +129,7 @@ func NewDropout[T tensor.Float](engine compute.Engine[T], ops numeric.Arithmetic
 		if d.rng != nil {
 			d.baseSeed = d.rng.Uint64()
 		} else {
+			determinism.Note(determinism.UnseededRand)
 			d.baseSeed = rand.Uint64() //#nosec G404
 		}
 	}
@@ -142,6 +144,7 @@ func (d *Dropout[T]) randFloat64() float64 {
 		return d.rng.Float64()
 	}
 
+	determinism.Note(determinism.UnseededRand)
 	return rand.Float64() //#nosec G404
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand/v2" //#nosec G404
 
+	"github.com/zerfoo/zerfoo/internal/determinism"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -97,6 +98,7 @@ func (d *FeatureDropout[T]) randFloat64() float64 {
 		return d.rng.Float64()
 	}
 
+	determinism.Note(determinism.UnseededRand)
 	return rand.Float64() //#nosec G404
 }
 
@@ -0,0 +1,35 @@
+package regularization
+
+import (
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// TrainingModeSetter is implemented by layers that behave differently during
+// training and inference, such as Dropout and FeatureDropout, and by
+// composite layers that forward the mode to such sub-layers.
+type TrainingModeSetter interface {
+	SetTraining(training bool)
+}
+
+// SetTrainingMode switches every node of g that implements
+// TrainingModeSetter to training (true) or inference (false) mode and
+// returns the number of nodes switched. Layers start in inference mode, so
+// a graph is unaffected by dropout until it is switched to training, and
+// switching it back makes later forward passes deterministic again.
+func SetTrainingMode[T tensor.Numeric](g *graph.Graph[T], training bool) int {
+	n := 0
+	for _, node := range g.Nodes() {
+		if s, ok := node.(TrainingModeSetter); ok {
+			s.SetTraining(training)
+			n++
+		}
+	}
+	return n
+}
+
+// Statically assert that the dropout layers support mode switching.
+var (
+	_ TrainingModeSetter = (*Dropout[float32])(nil)
+	_ TrainingModeSetter = (*FeatureDropout[float32])(nil)
+)
@@ -0,0 +1,64 @@
+package regularization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestSetTrainingMode(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine(ops)
+	b := graph.NewBuilder[float32](engine)
+	in := b.Input([]int{1, 64})
+	first := NewDropout(engine, ops, float32(0.5), WithDropoutSeed[float32](1))
+	second := NewDropout(engine, ops, float32(0.5), WithDropoutSeed[float32](2))
+	g, err := b.Build(b.AddNode(second, b.AddNode(first, in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]float32, 64)
+	for i := range data {
+		data[i] = 1
+	}
+	x, err := tensor.New([]int{1, 64}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped := func() int {
+		t.Helper()
+		out, err := g.Forward(context.Background(), x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, v := range out.Data() {
+			if v == 0 {
+				n++
+			}
+		}
+		return n
+	}
+
+	if got := dropped(); got != 0 {
+		t.Errorf("default mode dropped %d values, want 0", got)
+	}
+	if n := SetTrainingMode(g, true); n != 2 {
+		t.Errorf("SetTrainingMode(true) switched %d nodes, want 2", n)
+	}
+	if !first.IsTraining() || !second.IsTraining() {
+		t.Error("dropout layers not in training mode")
+	}
+	if got := dropped(); got == 0 {
+		t.Error("training mode dropped no values")
+	}
+	SetTrainingMode(g, false)
+	if got := dropped(); got != 0 {
+		t.Errorf("inference mode dropped %d values, want 0", got)
+	}
+}
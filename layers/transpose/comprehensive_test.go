@@ -156,3 +156,24 @@ func TestBuildTranspose_Errors(t *testing.T) {
 		}
 	})
 }
+
+func TestTranspose_Rank0(t *testing.T) {
+	ctx := context.Background()
+	tr := New[float32](makeEngine(), nil)
+	in := makeTensor(t, []int{}, []float32{5})
+
+	out, err := tr.Forward(ctx, in)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	if len(out.Shape()) != 0 || out.Data()[0] != 5 || len(tr.OutputShape()) != 0 {
+		t.Errorf("Forward = %v %v, want scalar 5", out.Shape(), out.Data())
+	}
+	grads, err := tr.Backward(ctx, types.FullBackprop, makeTensor(t, []int{}, []float32{2}))
+	if err != nil {
+		t.Fatalf("Backward: %v", err)
+	}
+	if len(grads[0].Shape()) != 0 || grads[0].Data()[0] != 2 {
+		t.Errorf("Backward = %v %v, want scalar 2", grads[0].Shape(), grads[0].Data())
+	}
+}
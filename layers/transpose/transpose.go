@@ -3,7 +3,9 @@ package transpose
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/axisutil"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -57,16 +59,20 @@ func (t *Transpose[T]) Parameters() []*graph.Parameter[T] {
 func (t *Transpose[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
 	input := inputs[0]
 	shape := input.Shape()
+	if len(shape) == 0 {
+		// Transposing a scalar is the identity.
+		t.perm = []int{}
+		t.outputShape = []int{}
+		return t.engine.Reshape(ctx, input, []int{})
+	}
 
-	// If perm is nil, use the ONNX default: reverse all axes.
-	perm := t.perm
-	if perm == nil {
-		perm = make([]int, len(shape))
-		for i := range perm {
-			perm[i] = len(shape) - 1 - i
-		}
-		t.perm = perm
+	// If perm is nil, use the ONNX default: reverse all axes. Negative
+	// entries count from the last axis.
+	perm, err := axisutil.Permutation(t.perm, len(shape))
+	if err != nil {
+		return nil, fmt.Errorf("transpose: %w", err)
 	}
+	t.perm = perm
 
 	outputShape := make([]int, len(shape))
 	for i, axis := range perm {
@@ -91,6 +97,13 @@ func (t *Transpose[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNume
 // Backward computes the gradients for the Transpose layer.
 func (t *Transpose[T]) Backward(ctx context.Context, _ types.BackwardMode, outputGradient *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
 	// The gradient w.r.t. the input is the gradient transposed by the inverse permutation.
+	if len(t.perm) == 0 {
+		gradInput, err := t.engine.Reshape(ctx, outputGradient, []int{})
+		if err != nil {
+			return nil, err
+		}
+		return []*tensor.TensorNumeric[T]{gradInput}, nil
+	}
 	inv := make([]int, len(t.perm))
 	for i, p := range t.perm {
 		inv[p] = i
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/zerfoo/zerfoo/layers/regularization"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
@@ -74,6 +75,43 @@ func (s *StandardModelInstance[T]) Backward(ctx context.Context, inputs ...*tens
 	return s.model.Graph.Backward(ctx, types.FullBackprop, inputs[0])
 }
 
+// SetInputSpec names the model's graph inputs and declares their dtypes,
+// one spec per graph input in order. The specs are reported through
+// GetMetadata and replace the input shapes read from the graph.
+func (s *StandardModelInstance[T]) SetInputSpec(specs ...InputSpec) error {
+	if err := validateSpecs(specs); err != nil {
+		return err
+	}
+	if s.model.Graph != nil {
+		if n := len(s.model.Graph.Inputs()); n != len(specs) {
+			return fmt.Errorf("graph has %d inputs, got %d input specs", n, len(specs))
+		}
+	}
+	s.metadata.InputSpec = specs
+	s.metadata.InputShape = make([][]int, len(specs))
+	for i, spec := range specs {
+		s.metadata.InputShape[i] = spec.Shape
+	}
+	return nil
+}
+
+// SetOutputSpec names the heads of a multi-head model, in order along the
+// last axis of the graph output. Their widths must add up to that axis
+// when the graph's output shape fixes it.
+func (s *StandardModelInstance[T]) SetOutputSpec(specs ...OutputSpec) error {
+	total, err := validateOutputSpecs(specs)
+	if err != nil {
+		return err
+	}
+	if shape := s.metadata.OutputShape; len(shape) > 0 {
+		if last := shape[len(shape)-1]; last > 0 && last != total {
+			return fmt.Errorf("output heads have total width %d, but the graph output's last axis is %d", total, last)
+		}
+	}
+	s.metadata.OutputSpec = specs
+	return nil
+}
+
 // GetGraph implements ModelInstance.GetGraph
 func (s *StandardModelInstance[T]) GetGraph() *graph.Graph[T] {
 	return s.model.Graph
@@ -94,9 +132,13 @@ func (s *StandardModelInstance[T]) Parameters() []*graph.Parameter[T] {
 	return s.model.Graph.Parameters()
 }
 
-// SetTrainingMode implements ModelInstance.SetTrainingMode
+// SetTrainingMode implements ModelInstance.SetTrainingMode. It also
+// switches the graph's mode-dependent layers, such as Dropout.
 func (s *StandardModelInstance[T]) SetTrainingMode(training bool) {
 	s.training = training
+	if s.model.Graph != nil {
+		regularization.SetTrainingMode(s.model.Graph, training)
+	}
 }
 
 // IsTraining implements ModelInstance.IsTraining
@@ -183,7 +225,7 @@ func NewBasicModelValidator[T tensor.Numeric]() *BasicModelValidator[T] {
 		Name:        "Basic Model Validator",
 		Version:     "1.0.0",
 		Description: "Provides basic model validation including graph consistency and parameter checks",
-		CheckTypes:  []string{"graph_consistency", "parameter_validation", "shape_validation"},
+		CheckTypes:  []string{"graph_consistency", "parameter_validation", "shape_validation", "dtype_validation"},
 		Strictness:  "medium",
 	}
 
@@ -213,6 +255,26 @@ func (v *BasicModelValidator[T]) ValidateModel(ctx context.Context, model ModelI
 		})
 	}
 
+	// Validate declared input and output specs
+	if err := validateSpecs(model.GetMetadata().InputSpec); err != nil {
+		result.IsValid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Type:      "input_spec_error",
+			Message:   err.Error(),
+			Component: "inputs",
+			Severity:  "high",
+		})
+	}
+	if _, err := validateOutputSpecs(model.GetMetadata().OutputSpec); err != nil {
+		result.IsValid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Type:      "output_spec_error",
+			Message:   err.Error(),
+			Component: "outputs",
+			Severity:  "high",
+		})
+	}
+
 	// Basic metrics
 	result.Metrics["parameter_count"] = float64(len(model.Parameters()))
 	result.Metrics["input_count"] = float64(len(model.GetMetadata().InputShape))
@@ -226,10 +288,24 @@ func (v *BasicModelValidator[T]) ValidateModel(ctx context.Context, model ModelI
 	return result, nil
 }
 
-// ValidateInputs implements ModelValidator.ValidateInputs
+// ValidateInputs implements ModelValidator.ValidateInputs. When the model
+// declares an InputSpec, inputs are checked against it in order, including
+// their dtypes.
 func (v *BasicModelValidator[T]) ValidateInputs(ctx context.Context, model ModelInstance[T], inputs ...*tensor.TensorNumeric[T]) error {
 	metadata := model.GetMetadata()
 
+	if specs := metadata.InputSpec; len(specs) > 0 {
+		if len(inputs) != len(specs) {
+			return fmt.Errorf("expected %d inputs, got %d", len(specs), len(inputs))
+		}
+		for i, spec := range specs {
+			if err := checkInput(spec, inputs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if len(inputs) != len(metadata.InputShape) {
 		return fmt.Errorf("expected %d inputs, got %d", len(metadata.InputShape), len(inputs))
 	}
@@ -258,6 +334,21 @@ func (v *BasicModelValidator[T]) ValidateInputs(ctx context.Context, model Model
 	return nil
 }
 
+// ValidateNamedInputs checks inputs keyed by name against the model's
+// InputSpec: every declared input must be present, no others may be, and
+// each must match its spec's shape and dtype.
+func (v *BasicModelValidator[T]) ValidateNamedInputs(ctx context.Context, model ModelInstance[T], inputs map[string]*tensor.TensorNumeric[T]) error {
+	specs := model.GetMetadata().InputSpec
+	if len(specs) == 0 {
+		return fmt.Errorf("model declares no named inputs")
+	}
+	ordered, err := ArrangeInputs(specs, inputs)
+	if err != nil {
+		return err
+	}
+	return v.ValidateInputs(ctx, model, ordered...)
+}
+
 // ValidateArchitecture implements ModelValidator.ValidateArchitecture
 func (v *BasicModelValidator[T]) ValidateArchitecture(ctx context.Context, model ModelInstance[T]) error {
 	g := model.GetGraph()
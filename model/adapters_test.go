@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/zerfoo/zerfoo/layers/regularization"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/numeric"
@@ -499,3 +500,28 @@ func TestBasicModelValidator_ValidateArchitecture_NilParamValue(t *testing.T) {
 		t.Error("expected error for parameter with nil value")
 	}
 }
+
+func TestStandardModelInstance_SetTrainingMode(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine(ops)
+	builder := graph.NewBuilder(engine)
+	input := builder.Input([]int{4})
+	dropout := regularization.NewDropout(engine, ops, float32(0.5))
+	g, err := builder.Build(builder.AddNode(dropout, input))
+	if err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+	instance := NewStandardModelInstance(&Model[float32]{Graph: g})
+
+	instance.SetTrainingMode(true)
+	if !instance.IsTraining() || !dropout.IsTraining() {
+		t.Errorf("after SetTrainingMode(true): instance %v, dropout %v", instance.IsTraining(), dropout.IsTraining())
+	}
+	instance.SetTrainingMode(false)
+	if instance.IsTraining() || dropout.IsTraining() {
+		t.Errorf("after SetTrainingMode(false): instance %v, dropout %v", instance.IsTraining(), dropout.IsTraining())
+	}
+
+	// A model without a graph only records the mode.
+	NewStandardModelInstance(&Model[float32]{}).SetTrainingMode(true)
+}
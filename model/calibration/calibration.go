@@ -0,0 +1,164 @@
+package calibration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// DefaultBins is the number of histogram bins per tensor.
+const DefaultBins = 2048
+
+type config struct {
+	bins int
+}
+
+// Option configures a Collector.
+type Option func(*config)
+
+// WithBins sets the number of histogram bins per tensor. More bins give
+// finer percentiles at the cost of a larger report. n must be even and at
+// least 2; other values are ignored.
+func WithBins(n int) Option {
+	return func(c *config) {
+		if n >= 2 && n%2 == 0 {
+			c.bins = n
+		}
+	}
+}
+
+// Collector accumulates activation statistics over forward passes of a
+// graph. Every node output still held after Forward is recorded, including
+// the inputs and the final output. A graph built with a tensor pool releases
+// intermediate outputs during Forward, so only the nodes it keeps are
+// recorded; build calibration graphs without one.
+//
+// A Collector is not safe for concurrent use.
+type Collector[T tensor.Numeric] struct {
+	g     *graph.Graph[T]
+	bins  int
+	names []string
+	stats map[graph.Node[T]]*TensorStats
+
+	batches int
+	buf     []float64
+}
+
+// NewCollector returns a Collector for g.
+func NewCollector[T tensor.Numeric](g *graph.Graph[T], opts ...Option) *Collector[T] {
+	cfg := config{bins: DefaultBins}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Collector[T]{
+		g:     g,
+		bins:  cfg.bins,
+		names: nodeNames(g.Nodes()),
+		stats: make(map[graph.Node[T]]*TensorStats),
+	}
+}
+
+// nodeNames names each node by its Name method when it has one and by
+// "<op type>_<index>" otherwise, suffixing repeated names with the index so
+// every name is unique.
+func nodeNames[T tensor.Numeric](nodes []graph.Node[T]) []string {
+	names := make([]string, len(nodes))
+	seen := make(map[string]bool, len(nodes))
+	for i, n := range nodes {
+		name := ""
+		if named, ok := n.(interface{ Name() string }); ok {
+			name = named.Name()
+		}
+		if name == "" {
+			name = n.OpType() + "_" + strconv.Itoa(i)
+		}
+		if seen[name] {
+			name += "_" + strconv.Itoa(i)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// Observe runs one forward pass with inputs and records the activations.
+func (c *Collector[T]) Observe(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) error {
+	if _, err := c.g.Forward(ctx, inputs...); err != nil {
+		return fmt.Errorf("calibration: forward: %w", err)
+	}
+	for i, n := range c.g.Nodes() {
+		out := c.g.NodeOutput(n)
+		if out == nil {
+			continue
+		}
+		s, ok := c.stats[n]
+		if !ok {
+			s = &TensorStats{
+				Name:      c.names[i],
+				OpType:    n.OpType(),
+				Shape:     append([]int(nil), out.Shape()...),
+				Histogram: newHistogram(c.bins),
+			}
+			c.stats[n] = s
+		}
+		c.buf = dtype.Float64s(c.buf, out.Data())
+		s.observe(c.buf)
+	}
+	c.batches++
+	return nil
+}
+
+// Report returns the statistics collected so far, in graph node order. The
+// report shares state with the Collector; further Observe calls update it.
+func (c *Collector[T]) Report() *Report {
+	r := &Report{Batches: c.batches}
+	for _, n := range c.g.Nodes() {
+		if s, ok := c.stats[n]; ok {
+			r.Tensors = append(r.Tensors, s)
+		}
+	}
+	return r
+}
+
+// Calibrate observes up to maxBatches batches from data (all of them when
+// maxBatches <= 0) and returns the report. Batch inputs are passed to the
+// graph in g.Inputs() order; targets are ignored.
+func Calibrate[T tensor.Numeric](ctx context.Context, g *graph.Graph[T], data training.DataProvider[T], maxBatches int, opts ...Option) (report *Report, err error) {
+	it, err := data.GetTrainingData(ctx, training.BatchConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("calibration: open data: %w", err)
+	}
+	defer func() { err = errors.Join(err, it.Close()) }()
+
+	c := NewCollector(g, opts...)
+	inputs := make([]*tensor.TensorNumeric[T], len(g.Inputs()))
+	for maxBatches <= 0 || c.batches < maxBatches {
+		if !it.Next(ctx) {
+			break
+		}
+		batch := it.Batch()
+		for i, in := range g.Inputs() {
+			t, ok := batch.Inputs[in]
+			if !ok {
+				return nil, fmt.Errorf("calibration: batch %d has no tensor for graph input %d", c.batches, i)
+			}
+			inputs[i] = t
+		}
+		if err := c.Observe(ctx, inputs...); err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("calibration: read data: %w", err)
+	}
+	if c.batches == 0 {
+		return nil, errors.New("calibration: data provider produced no batches")
+	}
+	return c.Report(), nil
+}
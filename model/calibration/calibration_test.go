@@ -0,0 +1,169 @@
+package calibration_test
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/model/calibration"
+	"github.com/zerfoo/zerfoo/training"
+)
+
+// sliceProvider serves fixed training batches.
+type sliceProvider struct {
+	batches []*training.Batch[float32]
+}
+
+func (p *sliceProvider) GetTrainingData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return &sliceIterator{batches: p.batches, pos: -1}, nil
+}
+
+func (p *sliceProvider) GetValidationData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return &sliceIterator{pos: -1}, nil
+}
+
+func (p *sliceProvider) GetMetadata() map[string]interface{} { return nil }
+func (p *sliceProvider) Close() error                        { return nil }
+
+type sliceIterator struct {
+	batches []*training.Batch[float32]
+	pos     int
+}
+
+func (it *sliceIterator) Next(context.Context) bool {
+	it.pos++
+	return it.pos < len(it.batches)
+}
+func (it *sliceIterator) Batch() *training.Batch[float32] { return it.batches[it.pos] }
+func (it *sliceIterator) Error() error                    { return nil }
+func (it *sliceIterator) Close() error                    { return nil }
+func (it *sliceIterator) Reset() error                    { it.pos = -1; return nil }
+
+// newIdentityGraph returns a 2-2 dense graph with identity weights and
+// zero bias, so the dense output equals its input.
+func newIdentityGraph(t *testing.T) (*graph.Graph[float32], graph.Node[float32]) {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, 2})
+	dense, err := core.NewDense[float32]("dense", engine, ops, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range g.Parameters() {
+		data := p.Value.Data()
+		clear(data)
+		if len(data) == 4 {
+			data[0], data[3] = 1, 1
+		}
+	}
+	return g, input
+}
+
+func newBatch(t *testing.T, input graph.Node[float32], x0, x1 float32) *training.Batch[float32] {
+	t.Helper()
+	x, err := tensor.New[float32]([]int{1, 2}, []float32{x0, x1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &training.Batch[float32]{Inputs: map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: x}}
+}
+
+func TestCalibrate(t *testing.T) {
+	g, input := newIdentityGraph(t)
+	data := &sliceProvider{batches: []*training.Batch[float32]{
+		newBatch(t, input, 1, -2),
+		newBatch(t, input, 3, 0.5),
+		newBatch(t, input, 100, 100), // beyond maxBatches
+	}}
+
+	report, err := calibration.Calibrate(context.Background(), g, data, 2, calibration.WithBins(64))
+	if err != nil {
+		t.Fatalf("Calibrate() error = %v", err)
+	}
+	if report.Batches != 2 {
+		t.Errorf("Batches = %d, want 2", report.Batches)
+	}
+	dense := report.Tensor("dense")
+	if dense == nil {
+		var names []string
+		for _, s := range report.Tensors {
+			names = append(names, s.Name)
+		}
+		t.Fatalf("no stats for dense; have %v", names)
+	}
+	if dense.Count != 4 || dense.Min != -2 || dense.Max != 3 {
+		t.Errorf("dense Count, Min, Max = %d, %v, %v; want 4, -2, 3", dense.Count, dense.Min, dense.Max)
+	}
+	if got := dense.AbsMax(); got != 3 {
+		t.Errorf("AbsMax() = %v, want 3", got)
+	}
+	if math.Abs(dense.Mean-0.625) > 1e-6 {
+		t.Errorf("Mean = %v, want 0.625", dense.Mean)
+	}
+	if got := dense.Percentile(100); math.Abs(got-3) > 4.0/64 {
+		t.Errorf("Percentile(100) = %v, want about 3", got)
+	}
+	if len(dense.Histogram.Bins) != 64 {
+		t.Errorf("len(Bins) = %d, want 64", len(dense.Histogram.Bins))
+	}
+}
+
+func TestCalibrate_MissingInput(t *testing.T) {
+	g, _ := newIdentityGraph(t)
+	_, other := newIdentityGraph(t)
+	data := &sliceProvider{batches: []*training.Batch[float32]{newBatch(t, other, 1, 1)}}
+	_, err := calibration.Calibrate(context.Background(), g, data, 0)
+	if err == nil || !strings.Contains(err.Error(), "no tensor for graph input") {
+		t.Errorf("Calibrate() error = %v, want missing input error", err)
+	}
+}
+
+func TestCalibrate_NoBatches(t *testing.T) {
+	g, _ := newIdentityGraph(t)
+	if _, err := calibration.Calibrate(context.Background(), g, &sliceProvider{}, 0); err == nil {
+		t.Error("Calibrate() with no batches succeeded")
+	}
+}
+
+func TestReport_SaveLoad(t *testing.T) {
+	g, _ := newIdentityGraph(t)
+	c := calibration.NewCollector(g, calibration.WithBins(16))
+	x, err := tensor.New[float32]([]int{1, 2}, []float32{0.25, -1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Observe(context.Background(), x); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	if err := c.Report().Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := calibration.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := c.Report().Tensor("dense")
+	loaded := got.Tensor("dense")
+	if loaded == nil || loaded.Min != want.Min || loaded.Max != want.Max || loaded.Count != want.Count {
+		t.Fatalf("loaded dense = %+v, want %+v", loaded, want)
+	}
+	if loaded.Percentile(99) != want.Percentile(99) {
+		t.Errorf("loaded Percentile(99) = %v, want %v", loaded.Percentile(99), want.Percentile(99))
+	}
+}
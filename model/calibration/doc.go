@@ -0,0 +1,18 @@
+// Package calibration collects activation statistics for post-training
+// quantization.
+//
+// A Collector runs sample inputs through a graph and records, for every
+// node output, its min, max, mean and standard deviation and a histogram of
+// absolute values from which percentiles are read. The statistics form a
+// Report that is saved as JSON, so a quantizer can later choose clipping
+// ranges (absmax, or a percentile such as 99.99 to ignore outliers) without
+// rerunning the model, and "zerfoo calibration show" can inspect them.
+// Collecting statistics is separate from quantizing: nothing here changes
+// the model.
+//
+//	report, err := calibration.Calibrate(ctx, g, data, 32)
+//	if err != nil { ... }
+//	err = report.Save("calibration.json")
+//
+// Stability: alpha
+package calibration
@@ -0,0 +1,78 @@
+package calibration
+
+import "math"
+
+// Histogram counts absolute activation values in equal-width bins over
+// [0, Limit). When a value reaches Limit, Limit doubles and adjacent bins
+// merge pairwise, so the histogram covers any range in a single pass
+// without knowing the range up front.
+type Histogram struct {
+	Limit float64 `json:"limit"`
+	Bins  []int64 `json:"bins"`
+}
+
+func newHistogram(bins int) *Histogram {
+	return &Histogram{Bins: make([]int64, bins)}
+}
+
+// add counts the finite, non-negative value v.
+func (h *Histogram) add(v float64) {
+	if v >= h.Limit {
+		h.grow(v)
+	}
+	if h.Limit == 0 {
+		h.Bins[0]++
+		return
+	}
+	i := int(v / h.Limit * float64(len(h.Bins)))
+	h.Bins[min(i, len(h.Bins)-1)]++
+}
+
+// grow raises Limit above v. An empty or all-zero histogram jumps straight
+// to the power of two above v; otherwise Limit doubles until it exceeds v.
+func (h *Histogram) grow(v float64) {
+	if h.Limit == 0 {
+		h.Limit = math.Exp2(math.Floor(math.Log2(v)) + 1)
+		return
+	}
+	for v >= h.Limit {
+		half := len(h.Bins) / 2
+		for i := range half {
+			h.Bins[i] = h.Bins[2*i] + h.Bins[2*i+1]
+		}
+		clear(h.Bins[half:])
+		h.Limit *= 2
+	}
+}
+
+// Total returns the number of values counted.
+func (h *Histogram) Total() int64 {
+	var n int64
+	for _, c := range h.Bins {
+		n += c
+	}
+	return n
+}
+
+// Percentile returns the absolute value below which p percent of the
+// values fall, interpolating linearly within a bin. p is clamped to
+// [0, 100]; an empty histogram returns 0.
+func (h *Histogram) Percentile(p float64) float64 {
+	total := h.Total()
+	if total == 0 || h.Limit == 0 {
+		return 0
+	}
+	target := min(max(p, 0), 100) / 100 * float64(total)
+	width := h.Limit / float64(len(h.Bins))
+	var cum float64
+	for i, c := range h.Bins {
+		if c == 0 {
+			continue
+		}
+		if cum+float64(c) >= target {
+			return (float64(i) + (target-cum)/float64(c)) * width
+		}
+		cum += float64(c)
+	}
+	return h.Limit
+}
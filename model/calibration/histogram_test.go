@@ -0,0 +1,56 @@
+package calibration
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHistogram_Percentile(t *testing.T) {
+	h := newHistogram(1024)
+	for i := 1; i <= 1000; i++ {
+		h.add(float64(i))
+	}
+	if got := h.Total(); got != 1000 {
+		t.Fatalf("Total() = %d, want 1000", got)
+	}
+	if h.Limit != 1024 {
+		t.Errorf("Limit = %v, want 1024", h.Limit)
+	}
+	for _, tc := range []struct{ p, want float64 }{
+		{50, 500},
+		{99, 990},
+		{100, 1000},
+	} {
+		if got := h.Percentile(tc.p); math.Abs(got-tc.want) > 2 {
+			t.Errorf("Percentile(%v) = %v, want about %v", tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestHistogram_GrowMergesBins(t *testing.T) {
+	h := newHistogram(4)
+	h.add(0.5) // Limit 1, bin 2
+	h.add(3)   // Limit 4; 0.5 now in bin 0
+	if h.Limit != 4 {
+		t.Fatalf("Limit = %v, want 4", h.Limit)
+	}
+	want := []int64{1, 0, 0, 1}
+	for i, c := range h.Bins {
+		if c != want[i] {
+			t.Fatalf("Bins = %v, want %v", h.Bins, want)
+		}
+	}
+}
+
+func TestHistogram_Zeros(t *testing.T) {
+	h := newHistogram(8)
+	h.add(0)
+	h.add(0)
+	if h.Total() != 2 || h.Percentile(99) != 0 {
+		t.Errorf("Total() = %d, Percentile(99) = %v; want 2, 0", h.Total(), h.Percentile(99))
+	}
+	h.add(1)
+	if h.Bins[0] != 2 || h.Total() != 3 {
+		t.Errorf("Bins = %v after growing from zero", h.Bins)
+	}
+}
@@ -0,0 +1,109 @@
+package calibration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// TensorStats summarizes the values one graph node produced over all
+// observed batches. NaN and infinite values are counted in NonFinite and
+// left out of the other statistics.
+type TensorStats struct {
+	Name      string     `json:"name"`
+	OpType    string     `json:"op_type"`
+	Shape     []int      `json:"shape"`
+	Count     int64      `json:"count"`
+	NonFinite int64      `json:"non_finite,omitempty"`
+	Min       float64    `json:"min"`
+	Max       float64    `json:"max"`
+	Mean      float64    `json:"mean"`
+	Std       float64    `json:"std"`
+	Histogram *Histogram `json:"histogram"`
+
+	sum, sumSq float64
+}
+
+// AbsMax returns the largest absolute value observed.
+func (s *TensorStats) AbsMax() float64 {
+	return max(math.Abs(s.Min), math.Abs(s.Max))
+}
+
+// Percentile returns the absolute value below which p percent of the
+// observed values fall.
+func (s *TensorStats) Percentile(p float64) float64 {
+	return s.Histogram.Percentile(p)
+}
+
+// observe folds values into the statistics.
+func (s *TensorStats) observe(values []float64) {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			s.NonFinite++
+			continue
+		}
+		if s.Count == 0 || v < s.Min {
+			s.Min = v
+		}
+		if s.Count == 0 || v > s.Max {
+			s.Max = v
+		}
+		s.Count++
+		s.sum += v
+		s.sumSq += v * v
+		s.Histogram.add(math.Abs(v))
+	}
+	if s.Count > 0 {
+		n := float64(s.Count)
+		s.Mean = s.sum / n
+		s.Std = math.Sqrt(max(s.sumSq/n-s.Mean*s.Mean, 0))
+	}
+}
+
+// Report is the activation statistics of a calibration run, in graph node
+// order.
+type Report struct {
+	Batches int            `json:"batches"`
+	Tensors []*TensorStats `json:"tensors"`
+}
+
+// Tensor returns the statistics of the named tensor, or nil.
+func (r *Report) Tensor(name string) *TensorStats {
+	for _, t := range r.Tensors {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Save writes the report to path as JSON.
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("calibration: encode report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("calibration: save report: %w", err)
+	}
+	return nil
+}
+
+// Load reads a report written by Save.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("calibration: load report: %w", err)
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("calibration: decode %s: %w", path, err)
+	}
+	for _, t := range r.Tensors {
+		if t.Histogram == nil || len(t.Histogram.Bins) == 0 {
+			return nil, fmt.Errorf("calibration: decode %s: tensor %q has no histogram", path, t.Name)
+		}
+	}
+	return &r, nil
+}
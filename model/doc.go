@@ -54,6 +54,27 @@
 // the default implementation. [ModelOptimizer] applies performance or memory
 // optimizations to a model instance.
 //
+// A model with several inputs can name them in [ModelMetadata].InputSpec, one
+// [InputSpec] per graph input giving its name, shape, and dtype: int for
+// token IDs, float for features, bool for masks. All inputs are tensors of
+// the model's element type; the dtype restricts their values, which
+// [BasicModelValidator] checks. [ArrangeInputs] turns a map of named inputs
+// into the positional order Forward takes:
+//
+//	inst.SetInputSpec(
+//		model.InputSpec{Name: "token_ids", DType: model.InputInt, Shape: []int{-1, -1}},
+//		model.InputSpec{Name: "features", DType: model.InputFloat, Shape: []int{-1, 8}},
+//	)
+//	err := validator.ValidateNamedInputs(ctx, inst, inputs)
+//	ordered, err := model.ArrangeInputs(inst.GetMetadata().InputSpec, inputs)
+//	out, err := inst.Forward(ctx, ordered...)
+//
+// A multi-head model concatenates its heads (say a regression head, an
+// auxiliary classification head, and an embedding) along the last axis of
+// its single graph output and names them in [ModelMetadata].OutputSpec, one
+// [OutputSpec] per head with its width. [SplitOutputs] splits an output into
+// its heads, and [SelectOutput] picks one of them at predict or serve time.
+//
 // # Memory-Mapped File Access
 //
 // [MmapReader] memory-maps a model file for zero-copy access to its contents,
@@ -3,4 +3,8 @@
 // Package modeldsl provides a declarative DSL for defining custom model
 // architectures using Go structs. Model definitions are validated and compiled
 // into runnable graphs that support forward inference.
+//
+// Each layer computes in the dtype set by LayerDef.DType, falling back to
+// ModelDef.DType and then float64, so precision can be lowered only where
+// memory matters; casts are inserted where adjacent layers' dtypes differ.
 package dsl
@@ -22,11 +22,28 @@ const (
 	LayerAttention LayerType = "Attention"
 )
 
+// DType is the element type a layer computes and stores its parameters in.
+type DType string
+
+// Supported layer dtypes. Activations pass between layers as float64; where
+// a layer's dtype is narrower, its input is cast to that dtype as it enters
+// the layer.
+const (
+	DTypeFloat64  DType = "float64"
+	DTypeFloat32  DType = "float32"
+	DTypeFloat16  DType = "float16"
+	DTypeBFloat16 DType = "bfloat16"
+)
+
 // LayerDef defines a single layer in the model.
 type LayerDef struct {
 	Name   string
 	Type   LayerType
 	Params map[string]any
+	// DType overrides the model's dtype for this layer, for example to keep
+	// attention and the output head in float32 while the rest of the model
+	// runs in float16. Empty inherits ModelDef.DType.
+	DType DType
 }
 
 // ConnectionDef specifies a directed edge from one layer to another.
@@ -40,6 +57,9 @@ type ModelDef struct {
 	Name        string
 	Layers      []LayerDef
 	Connections []ConnectionDef
+	// DType is the dtype of layers that do not set their own. Empty means
+	// float64.
+	DType DType
 }
 
 // Parse validates a ModelDef and builds a ModelGraph.
@@ -54,6 +74,16 @@ func Parse(def *ModelDef) (*ModelGraph, error) {
 		return nil, errors.New("modeldsl: at least one layer is required")
 	}
 
+	modelDType := def.DType
+	if modelDType == "" {
+		modelDType = DTypeFloat64
+	}
+	if err := validateDType(modelDType); err != nil {
+		return nil, fmt.Errorf("modeldsl: %w", err)
+	}
+
+	// Copy the layers so each carries its resolved dtype.
+	layers := make([]LayerDef, len(def.Layers))
 	layerIndex := make(map[string]int, len(def.Layers))
 	for i, l := range def.Layers {
 		if l.Name == "" {
@@ -65,6 +95,13 @@ func Parse(def *ModelDef) (*ModelGraph, error) {
 		if err := validateLayerType(l.Type); err != nil {
 			return nil, fmt.Errorf("modeldsl: layer %q: %w", l.Name, err)
 		}
+		if l.DType == "" {
+			l.DType = modelDType
+		}
+		if err := validateDType(l.DType); err != nil {
+			return nil, fmt.Errorf("modeldsl: layer %q: %w", l.Name, err)
+		}
+		layers[i] = l
 		layerIndex[l.Name] = i
 	}
 
@@ -83,14 +120,14 @@ func Parse(def *ModelDef) (*ModelGraph, error) {
 	}
 
 	// Topological sort to detect cycles and determine execution order.
-	order, err := topoSort(def.Layers, children, parents)
+	order, err := topoSort(layers, children, parents)
 	if err != nil {
 		return nil, err
 	}
 
 	// Identify input layers (no parents) and output layers (no children).
 	var inputs, outputs []string
-	for _, l := range def.Layers {
+	for _, l := range layers {
 		if len(parents[l.Name]) == 0 {
 			inputs = append(inputs, l.Name)
 		}
@@ -101,7 +138,7 @@ func Parse(def *ModelDef) (*ModelGraph, error) {
 
 	return &ModelGraph{
 		name:       def.Name,
-		layers:     def.Layers,
+		layers:     layers,
 		layerIndex: layerIndex,
 		children:   children,
 		parents:    parents,
@@ -120,6 +157,15 @@ func validateLayerType(t LayerType) error {
 	}
 }
 
+func validateDType(d DType) error {
+	switch d {
+	case DTypeFloat64, DTypeFloat32, DTypeFloat16, DTypeBFloat16:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dtype %q", d)
+	}
+}
+
 func topoSort(layers []LayerDef, children, parents map[string][]string) ([]string, error) {
 	inDegree := make(map[string]int, len(layers))
 	for _, l := range layers {
@@ -4,6 +4,8 @@ import (
 	"math"
 	"strings"
 	"testing"
+
+	"github.com/zerfoo/float16"
 )
 
 func TestDSL_Parse(t *testing.T) {
@@ -46,6 +48,19 @@ func TestDSL_Parse(t *testing.T) {
 			},
 			wantErr: "duplicate layer name",
 		},
+		{
+			name:    "unsupported model dtype",
+			def:     &ModelDef{Name: "m", DType: "int8", Layers: []LayerDef{{Name: "a", Type: LayerLinear}}},
+			wantErr: `unsupported dtype "int8"`,
+		},
+		{
+			name: "unsupported layer dtype",
+			def: &ModelDef{
+				Name:   "m",
+				Layers: []LayerDef{{Name: "a", Type: LayerLinear, DType: "fp16"}},
+			},
+			wantErr: `layer "a": unsupported dtype "fp16"`,
+		},
 		{
 			name: "unsupported layer type",
 			def: &ModelDef{
@@ -501,3 +516,97 @@ func TestDSL_RMSNormNormalizes(t *testing.T) {
 		}
 	}
 }
+
+func TestDSL_LayerDTypes(t *testing.T) {
+	def := &ModelDef{
+		Name:  "mixed",
+		DType: DTypeFloat16,
+		Layers: []LayerDef{
+			{Name: "embed", Type: LayerLinear, Params: map[string]any{"output_dim": 8}},
+			{Name: "attn", Type: LayerAttention, DType: DTypeFloat32, Params: map[string]any{"num_heads": 2}},
+			{Name: "norm", Type: LayerRMSNorm, DType: DTypeBFloat16},
+			{Name: "head", Type: LayerLinear, DType: DTypeFloat64},
+			{Name: "softmax", Type: LayerSoftmax, DType: DTypeFloat32},
+		},
+		Connections: []ConnectionDef{
+			{From: "embed", To: "attn"},
+			{From: "attn", To: "norm"},
+			{From: "norm", To: "head"},
+			{From: "head", To: "softmax"},
+		},
+	}
+	g, err := Parse(def)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for name, want := range map[string]DType{
+		"embed": DTypeFloat16, "attn": DTypeFloat32, "norm": DTypeBFloat16,
+		"head": DTypeFloat64, "softmax": DTypeFloat32, "missing": "",
+	} {
+		if got := g.DType(name); got != want {
+			t.Errorf("DType(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if def.Layers[0].DType != "" {
+		t.Error("Parse modified the definition")
+	}
+
+	m, err := g.Build(4, 3)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// Parameters are stored in each layer's own dtype.
+	embed, ok := m.execLayers["embed"].(*castLayer[float16.Float16])
+	if !ok {
+		t.Fatalf("embed layer is %T, want a float16 cast layer", m.execLayers["embed"])
+	}
+	if _, ok := embed.layer.(*linearLayer[float16.Float16]); !ok {
+		t.Errorf("embed computes in %T", embed.layer)
+	}
+	if _, ok := m.execLayers["attn"].(*castLayer[float32]); !ok {
+		t.Errorf("attn layer is %T, want a float32 cast layer", m.execLayers["attn"])
+	}
+	if _, ok := m.execLayers["head"].(*linearLayer[float64]); !ok {
+		t.Errorf("head layer is %T, want float64 without casts", m.execLayers["head"])
+	}
+
+	out, err := m.Forward([]float64{0.5, -1, 2, 0.25})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	var sum float64
+	for _, v := range out {
+		sum += v
+		if float64(float32(v)) != v {
+			t.Errorf("float32 softmax output %v is not a float32 value", v)
+		}
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("softmax sum = %v, want 1", sum)
+	}
+
+	// A float16 layer's output holds float16 values.
+	h, err := m.execLayers["embed"].forward([]float64{0.1, 0.2, 0.3, 0.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range h {
+		if float16.FromFloat64(v).ToFloat64() != v {
+			t.Errorf("float16 output %v is not a float16 value", v)
+		}
+	}
+}
+
+func TestDSL_LayerDTypesNotTrainable(t *testing.T) {
+	g, err := Parse(&ModelDef{
+		Name:   "m",
+		Layers: []LayerDef{{Name: "a", Type: LayerLinear, DType: DTypeFloat16}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.BuildTrainable(2, 2); err == nil || !strings.Contains(err.Error(), "float64") {
+		t.Errorf("BuildTrainable error = %v, want a float64-only error", err)
+	}
+}
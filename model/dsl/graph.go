@@ -27,6 +27,16 @@ func (g *ModelGraph) Inputs() []string { return g.inputs }
 // Outputs returns the names of output layers (layers with no children).
 func (g *ModelGraph) Outputs() []string { return g.outputs }
 
+// DType returns the resolved dtype of the named layer, or "" if the graph
+// has no such layer.
+func (g *ModelGraph) DType(name string) DType {
+	i, ok := g.layerIndex[name]
+	if !ok {
+		return ""
+	}
+	return g.layers[i].DType
+}
+
 // Build instantiates a runnable Model from the graph.
 // inputDim and outputDim specify the dimensions of the model's input and output vectors.
 func (g *ModelGraph) Build(inputDim, outputDim int) (*Model, error) {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/float16"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 
+	"github.com/zerfoo/zerfoo/internal/dtype"
 	"github.com/zerfoo/zerfoo/layers/activations"
 	"github.com/zerfoo/zerfoo/layers/attention"
 	"github.com/zerfoo/zerfoo/layers/components"
@@ -58,11 +60,36 @@ func (m *Model) Forward(input []float64) ([]float64, error) {
 	return activations[outputName], nil
 }
 
-// buildLayer constructs an execLayer from a LayerDef.
+// buildLayer constructs an execLayer from a LayerDef, computing in the
+// layer's DType.
 func buildLayer(def LayerDef, inDim, outDim int) (execLayer, error) {
+	switch def.DType {
+	case DTypeFloat32:
+		return buildCastLayer(dslEngineF32, def, inDim, outDim)
+	case DTypeFloat16:
+		return buildCastLayer(dslEngineF16, def, inDim, outDim)
+	case DTypeBFloat16:
+		return buildCastLayer(dslEngineBF16, def, inDim, outDim)
+	default:
+		return buildTypedLayer(dslEngine, def, inDim, outDim)
+	}
+}
+
+// buildCastLayer builds a layer computing in T behind the casts that
+// connect it to the float64 activations between layers.
+func buildCastLayer[T tensor.Numeric](engine compute.Engine[T], def LayerDef, inDim, outDim int) (execLayer, error) {
+	layer, err := buildTypedLayer(engine, def, inDim, outDim)
+	if err != nil {
+		return nil, err
+	}
+	return &castLayer[T]{layer: layer, ops: engine.Ops()}, nil
+}
+
+// buildTypedLayer constructs a layer computing in T.
+func buildTypedLayer[T tensor.Numeric](engine compute.Engine[T], def LayerDef, inDim, outDim int) (typedLayer[T], error) {
 	switch def.Type {
 	case LayerLinear:
-		return newLinearLayer(inDim, outDim), nil
+		return newLinearLayer(engine, inDim, outDim), nil
 	case LayerRMSNorm:
 		eps := 1e-6
 		if v, ok := def.Params["epsilon"]; ok {
@@ -72,11 +99,11 @@ func buildLayer(def LayerDef, inDim, outDim int) (execLayer, error) {
 			}
 			eps = f
 		}
-		return newRMSNormLayer(inDim, eps)
+		return newRMSNormLayer(engine, inDim, eps)
 	case LayerSiLU:
-		return newSiLULayer(), nil
+		return newSiLULayer(engine), nil
 	case LayerSoftmax:
-		return newSoftmaxLayer(), nil
+		return newSoftmaxLayer(engine), nil
 	case LayerAttention:
 		numHeads := 1
 		if v, ok := def.Params["num_heads"]; ok {
@@ -92,7 +119,7 @@ func buildLayer(def LayerDef, inDim, outDim int) (execLayer, error) {
 		if inDim%numHeads != 0 {
 			return nil, fmt.Errorf("input dim %d not divisible by num_heads %d", inDim, numHeads)
 		}
-		return newAttentionLayer(inDim, numHeads), nil
+		return newAttentionLayer(engine, inDim, numHeads), nil
 	default:
 		return nil, fmt.Errorf("unsupported layer type %q", def.Type)
 	}
@@ -113,32 +140,64 @@ func toFloat64(v any) (float64, error) {
 	}
 }
 
-// dslEngine is a package-level CPU engine for float64, used by linearLayer
-// to delegate matrix multiplication to layers/core.Linear.
-var dslEngine = compute.NewCPUEngine[float64](numeric.Float64Ops{})
+// dslEngine is a package-level CPU engine for float64, used by the layers
+// of the default dtype. The other engines serve layers with a DType
+// override.
+var (
+	dslEngine     = compute.NewCPUEngine[float64](numeric.Float64Ops{})
+	dslEngineF32  = compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	dslEngineF16  = compute.NewCPUEngine[float16.Float16](numeric.Float16Ops{})
+	dslEngineBF16 = compute.NewCPUEngine[float16.BFloat16](numeric.BFloat16Ops{})
+)
+
+// typedLayer is a compiled layer computing in element type T. A
+// typedLayer[float64] is an execLayer.
+type typedLayer[T tensor.Numeric] interface {
+	forward(input []T) ([]T, error)
+}
+
+// castLayer adapts a typedLayer[T] to the float64 activations passed between
+// layers. It is the cast node at a dtype boundary: the input is rounded to T
+// on the way in, and the output widened back to float64, which represents
+// every supported dtype exactly, on the way out. Parameters stay in T.
+type castLayer[T tensor.Numeric] struct {
+	layer typedLayer[T]
+	ops   numeric.Arithmetic[T]
+}
+
+func (c *castLayer[T]) forward(input []float64) ([]float64, error) {
+	in := make([]T, len(input))
+	dtype.FromFloat64s(c.ops, in, input)
+	out, err := c.layer.forward(in)
+	if err != nil {
+		return nil, err
+	}
+	return dtype.Float64s(nil, out), nil
+}
 
 // linearLayer implements a dense linear transformation: y = xW + b,
 // delegating the matmul to layers/core.Linear.
-type linearLayer struct {
-	linear *core.Linear[float64]
-	bias   []float64 // [outDim]
+type linearLayer[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+	linear *core.Linear[T]
+	bias   []T // [outDim]
 	inDim  int
 	outDim int
 	// weights stores the raw weight data for use by linearLayerT (training).
-	weights []float64 // [inDim * outDim], row-major
+	weights []T // [inDim * outDim], row-major
 }
 
-func newLinearLayer(inDim, outDim int) *linearLayer {
+func newLinearLayer[T tensor.Numeric](engine compute.Engine[T], inDim, outDim int) *linearLayer[T] {
 	// Xavier initialization via layers/components.XavierInitializer.
-	xavier := components.NewXavierInitializer[float64](numeric.Float64Ops{})
+	xavier := components.NewXavierInitializer[T](engine.Ops())
 	weights, err := xavier.Initialize(inDim, outDim)
 	if err != nil {
 		panic(fmt.Sprintf("modeldsl: newLinearLayer xavier init: %v", err))
 	}
-	bias := make([]float64, outDim)
+	bias := make([]T, outDim)
 
 	// Create the core.Linear layer using the initialized weights.
-	lin, err := core.NewLinear[float64]("dsl_linear", dslEngine, dslEngine.Ops(), inDim, outDim)
+	lin, err := core.NewLinear[T]("dsl_linear", engine, engine.Ops(), inDim, outDim)
 	if err != nil {
 		// NewLinear only errors on empty name or non-positive dims, which we control.
 		panic(fmt.Sprintf("modeldsl: newLinearLayer: %v", err))
@@ -146,16 +205,16 @@ func newLinearLayer(inDim, outDim int) *linearLayer {
 	// Overwrite the random weights from core.Linear with our Xavier-initialized weights.
 	copy(lin.Parameters()[0].Value.Data(), weights)
 
-	return &linearLayer{linear: lin, bias: bias, inDim: inDim, outDim: outDim, weights: weights}
+	return &linearLayer[T]{engine: engine, linear: lin, bias: bias, inDim: inDim, outDim: outDim, weights: weights}
 }
 
-func (l *linearLayer) forward(input []float64) ([]float64, error) {
+func (l *linearLayer[T]) forward(input []T) ([]T, error) {
 	if len(input) != l.inDim {
 		return nil, fmt.Errorf("linear: expected %d inputs, got %d", l.inDim, len(input))
 	}
 
 	// Wrap input as [1, inDim] tensor for core.Linear.Forward.
-	inputT, err := tensor.New[float64]([]int{1, l.inDim}, input)
+	inputT, err := tensor.New[T]([]int{1, l.inDim}, input)
 	if err != nil {
 		return nil, err
 	}
@@ -166,11 +225,11 @@ func (l *linearLayer) forward(input []float64) ([]float64, error) {
 	}
 
 	// Add bias via engine to avoid raw .Data() loop.
-	biasT, err := tensor.New[float64]([]int{1, l.outDim}, l.bias)
+	biasT, err := tensor.New[T]([]int{1, l.outDim}, l.bias)
 	if err != nil {
 		return nil, err
 	}
-	sumT, err := dslEngine.Add(context.Background(), outT, biasT)
+	sumT, err := l.engine.Add(context.Background(), outT, biasT)
 	if err != nil {
 		return nil, err
 	}
@@ -178,25 +237,25 @@ func (l *linearLayer) forward(input []float64) ([]float64, error) {
 }
 
 // rmsnormLayer wraps layers/normalization.RMSNorm for inference.
-type rmsnormLayer struct {
-	norm *normalization.RMSNorm[float64]
+type rmsnormLayer[T tensor.Numeric] struct {
+	norm *normalization.RMSNorm[T]
 	dim  int
 }
 
-func newRMSNormLayer(dim int, epsilon float64) (*rmsnormLayer, error) {
-	ops := numeric.Float64Ops{}
-	norm, err := normalization.NewRMSNorm[float64](
-		"dsl_rmsnorm", dslEngine, ops, dim,
-		normalization.WithRMSNormEpsilon[float64](epsilon),
+func newRMSNormLayer[T tensor.Numeric](engine compute.Engine[T], dim int, epsilon float64) (*rmsnormLayer[T], error) {
+	ops := engine.Ops()
+	norm, err := normalization.NewRMSNorm[T](
+		"dsl_rmsnorm", engine, ops, dim,
+		normalization.WithRMSNormEpsilon[T](ops.FromFloat64(epsilon)),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("modeldsl: newRMSNormLayer: %w", err)
 	}
-	return &rmsnormLayer{norm: norm, dim: dim}, nil
+	return &rmsnormLayer[T]{norm: norm, dim: dim}, nil
 }
 
-func (l *rmsnormLayer) forward(input []float64) ([]float64, error) {
-	t, err := tensor.New[float64]([]int{1, l.dim}, input)
+func (l *rmsnormLayer[T]) forward(input []T) ([]T, error) {
+	t, err := tensor.New[T]([]int{1, l.dim}, input)
 	if err != nil {
 		return nil, err
 	}
@@ -208,16 +267,17 @@ func (l *rmsnormLayer) forward(input []float64) ([]float64, error) {
 }
 
 // siluLayer wraps layers/activations.Sigmoid to compute SiLU: x * sigmoid(x).
-type siluLayer struct {
-	sigmoid *activations.Sigmoid[float64]
+type siluLayer[T tensor.Numeric] struct {
+	engine  compute.Engine[T]
+	sigmoid *activations.Sigmoid[T]
 }
 
-func newSiLULayer() *siluLayer {
-	return &siluLayer{sigmoid: activations.NewSigmoid[float64](dslEngine, numeric.Float64Ops{})}
+func newSiLULayer[T tensor.Numeric](engine compute.Engine[T]) *siluLayer[T] {
+	return &siluLayer[T]{engine: engine, sigmoid: activations.NewSigmoid[T](engine, engine.Ops())}
 }
 
-func (l *siluLayer) forward(input []float64) ([]float64, error) {
-	t, err := tensor.New[float64]([]int{len(input)}, input)
+func (l *siluLayer[T]) forward(input []T) ([]T, error) {
+	t, err := tensor.New[T]([]int{len(input)}, input)
 	if err != nil {
 		return nil, err
 	}
@@ -226,7 +286,7 @@ func (l *siluLayer) forward(input []float64) ([]float64, error) {
 	if err != nil {
 		return nil, err
 	}
-	out, err := dslEngine.Mul(ctx, t, sig)
+	out, err := l.engine.Mul(ctx, t, sig)
 	if err != nil {
 		return nil, err
 	}
@@ -234,16 +294,16 @@ func (l *siluLayer) forward(input []float64) ([]float64, error) {
 }
 
 // softmaxLayer wraps layers/activations.Softmax for inference.
-type softmaxLayer struct {
-	sm *activations.Softmax[float64]
+type softmaxLayer[T tensor.Numeric] struct {
+	sm *activations.Softmax[T]
 }
 
-func newSoftmaxLayer() *softmaxLayer {
-	return &softmaxLayer{sm: activations.NewSoftmax[float64](dslEngine, -1)}
+func newSoftmaxLayer[T tensor.Numeric](engine compute.Engine[T]) *softmaxLayer[T] {
+	return &softmaxLayer[T]{sm: activations.NewSoftmax[T](engine, -1)}
 }
 
-func (l *softmaxLayer) forward(input []float64) ([]float64, error) {
-	t, err := tensor.New[float64]([]int{1, len(input)}, input)
+func (l *softmaxLayer[T]) forward(input []T) ([]T, error) {
+	t, err := tensor.New[T]([]int{1, len(input)}, input)
 	if err != nil {
 		return nil, err
 	}
@@ -256,32 +316,32 @@ func (l *softmaxLayer) forward(input []float64) ([]float64, error) {
 
 // attentionLayer implements self-attention using core.Linear projections and
 // layers/attention.ScaledDotProductAttention for the score computation.
-type attentionLayer struct {
+type attentionLayer[T tensor.Numeric] struct {
 	numHeads int
 	headDim  int
 	dim      int
-	wq       *linearLayer
-	wk       *linearLayer
-	wv       *linearLayer
-	wo       *linearLayer
-	sdpa     *attention.ScaledDotProductAttention[float64]
+	wq       *linearLayer[T]
+	wk       *linearLayer[T]
+	wv       *linearLayer[T]
+	wo       *linearLayer[T]
+	sdpa     *attention.ScaledDotProductAttention[T]
 }
 
-func newAttentionLayer(dim, numHeads int) *attentionLayer {
+func newAttentionLayer[T tensor.Numeric](engine compute.Engine[T], dim, numHeads int) *attentionLayer[T] {
 	headDim := dim / numHeads
-	return &attentionLayer{
+	return &attentionLayer[T]{
 		numHeads: numHeads,
 		headDim:  headDim,
 		dim:      dim,
-		wq:       newLinearLayer(dim, dim),
-		wk:       newLinearLayer(dim, dim),
-		wv:       newLinearLayer(dim, dim),
-		wo:       newLinearLayer(dim, dim),
-		sdpa:     attention.NewScaledDotProductAttention[float64](dslEngine, headDim),
+		wq:       newLinearLayer(engine, dim, dim),
+		wk:       newLinearLayer(engine, dim, dim),
+		wv:       newLinearLayer(engine, dim, dim),
+		wo:       newLinearLayer(engine, dim, dim),
+		sdpa:     attention.NewScaledDotProductAttention[T](engine, headDim),
 	}
 }
 
-func (a *attentionLayer) forward(input []float64) ([]float64, error) {
+func (a *attentionLayer[T]) forward(input []T) ([]T, error) {
 	q, err := a.wq.forward(input)
 	if err != nil {
 		return nil, err
@@ -298,15 +358,15 @@ func (a *attentionLayer) forward(input []float64) ([]float64, error) {
 	ctx := context.Background()
 
 	// Reshape Q, K, V from [dim] to [numHeads, 1, headDim] for SDPA.
-	qT, err := tensor.New[float64]([]int{a.numHeads, 1, a.headDim}, q)
+	qT, err := tensor.New[T]([]int{a.numHeads, 1, a.headDim}, q)
 	if err != nil {
 		return nil, err
 	}
-	kT, err := tensor.New[float64]([]int{a.numHeads, 1, a.headDim}, k)
+	kT, err := tensor.New[T]([]int{a.numHeads, 1, a.headDim}, k)
 	if err != nil {
 		return nil, err
 	}
-	vT, err := tensor.New[float64]([]int{a.numHeads, 1, a.headDim}, v)
+	vT, err := tensor.New[T]([]int{a.numHeads, 1, a.headDim}, v)
 	if err != nil {
 		return nil, err
 	}
@@ -30,11 +30,13 @@ func ConstantFolding(g *ModelGraph) *ModelGraph {
 			continue
 		}
 		parent := parents[0]
-		layerType := g.layers[g.layerIndex[name]].Type
-		parentType := g.layers[g.layerIndex[parent]].Type
+		layer := g.layers[g.layerIndex[name]]
+		parentLayer := g.layers[g.layerIndex[parent]]
 
-		// Idempotent ops: applying twice is same as once.
-		if layerType == parentType && isIdempotent(layerType) {
+		// Idempotent ops: applying twice is same as once. Across a dtype
+		// boundary the second application is not redundant: it runs at a
+		// different precision.
+		if layer.Type == parentLayer.Type && layer.DType == parentLayer.DType && isIdempotent(layer.Type) {
 			remove[name] = true
 		}
 	}
@@ -153,6 +155,11 @@ func OperatorFusion(g *ModelGraph) *ModelGraph {
 
 		lt := g.layers[g.layerIndex[name]].Type
 		ct := g.layers[g.layerIndex[child]].Type
+		dt := g.layers[g.layerIndex[name]].DType
+		if g.layers[g.layerIndex[child]].DType != dt {
+			// A fused operator computes in a single dtype.
+			continue
+		}
 
 		var fusedOp string
 		switch {
@@ -178,6 +185,7 @@ func OperatorFusion(g *ModelGraph) *ModelGraph {
 			second:      child,
 			fusedName:   name + "+" + child,
 			fusedParams: mergedParams,
+			dtype:       dt,
 		})
 		fused[name] = true
 		fused[child] = true
@@ -324,6 +332,7 @@ type fusionPair struct {
 	first, second string
 	fusedName     string
 	fusedParams   map[string]any
+	dtype         DType
 }
 
 // rebuildWithFusions creates a new ModelGraph where fused pairs are replaced
@@ -342,6 +351,7 @@ func rebuildWithFusions(g *ModelGraph, fusions []fusionPair) *ModelGraph {
 			Name:   f.fusedName,
 			Type:   FusedLayerType,
 			Params: f.fusedParams,
+			DType:  f.dtype,
 		}
 	}
 
@@ -1,6 +1,7 @@
 package dsl
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -434,3 +435,38 @@ func TestGraphOptimize_PreservesGraphIntegrity(t *testing.T) {
 		t.Errorf("Outputs() = %v, want [out]", outputs)
 	}
 }
+
+func TestGraphOptimize_DTypeBoundaries(t *testing.T) {
+	g, err := Parse(&ModelDef{
+		Name: "boundaries",
+		Layers: []LayerDef{
+			{Name: "norm1", Type: LayerRMSNorm, DType: DTypeFloat16},
+			{Name: "norm2", Type: LayerRMSNorm},
+			{Name: "silu", Type: LayerSiLU, DType: DTypeFloat16},
+			{Name: "norm3", Type: LayerRMSNorm, DType: DTypeFloat16},
+		},
+		Connections: []ConnectionDef{
+			{From: "norm1", To: "norm2"},
+			{From: "norm2", To: "silu"},
+			{From: "silu", To: "norm3"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// norm2 runs at a different precision than norm1, so it is kept.
+	if got := ConstantFolding(g).Order(); len(got) != 4 {
+		t.Errorf("ConstantFolding order = %v, want all 4 layers", got)
+	}
+
+	// Only silu and norm3 share a dtype.
+	fused := OperatorFusion(g)
+	want := []string{"norm1", "norm2", "silu+norm3"}
+	if got := fused.Order(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("OperatorFusion order = %v, want %v", got, want)
+	}
+	if got := fused.DType("silu+norm3"); got != DTypeFloat16 {
+		t.Errorf("fused layer dtype = %q, want float16", got)
+	}
+}
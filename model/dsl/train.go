@@ -203,7 +203,7 @@ type linearLayerT struct {
 }
 
 func newTrainableLinearLayer(inDim, outDim int) *linearLayerT {
-	base := newLinearLayer(inDim, outDim)
+	base := newLinearLayer(dslEngine, inDim, outDim)
 	return &linearLayerT{
 		weights: &Param{Data: base.weights, Grad: make([]float64, len(base.weights))},
 		bias:    &Param{Data: base.bias, Grad: make([]float64, len(base.bias))},
@@ -382,6 +382,9 @@ func (l *softmaxLayerT) params() []*Param { return nil }
 
 // buildTrainableLayer constructs a trainableLayer from a LayerDef.
 func buildTrainableLayer(def LayerDef, inDim, outDim int) (trainableLayer, error) {
+	if def.DType != DTypeFloat64 {
+		return nil, fmt.Errorf("training supports only %s layers, got %s", DTypeFloat64, def.DType)
+	}
 	switch def.Type {
 	case LayerLinear:
 		return newTrainableLinearLayer(inDim, outDim), nil
@@ -21,6 +21,7 @@ type ModelConfig struct {
 	RopeTheta        float64
 	HeadDim              int     // explicit head dimension (0 = use HiddenSize/NumHeads)
 	LogitSoftcap         float32 // if > 0, apply logit softcapping: cap * tanh(logit/cap)
+	AttnLogitSoftcap     float32 // if > 0, soft-cap attention logits: cap * tanh(score/cap) (Gemma 2)
 	LocalRopeTheta       float64 // RoPE base for local/sliding-window layers (0 = use RopeTheta)
 	SlidingWindow        int     // sliding window size for local attention layers
 	SlidingWindowPattern int     // every Nth layer is global (0 = all global)
@@ -186,6 +187,10 @@ func ExtractModelConfig(f *File) (*ModelConfig, error) {
 	if v, ok := f.GetFloat32(prefix + "final_logit_softcapping"); ok {
 		cfg.LogitSoftcap = v
 	}
+	// Extract attention logit softcapping value (Gemma 2).
+	if v, ok := f.GetFloat32(prefix + "attn_logit_softcapping"); ok {
+		cfg.AttnLogitSoftcap = v
+	}
 	// Extract local RoPE base for alternating attention.
 	if v, ok := f.GetFloat32(prefix + "rope.local.freq_base"); ok {
 		cfg.LocalRopeTheta = float64(v)
@@ -0,0 +1,315 @@
+package gguf
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Encrypted model container.
+//
+// An encrypted model is a GGUF file sealed with AES-256-GCM in fixed-size
+// chunks (the STREAM construction), so files of any size can be encrypted
+// and decrypted in a single pass. The layout is
+//
+//	magic    uint32  EncryptedMagic
+//	version  uint32  1
+//	keyIDLen uint16
+//	keyID    [keyIDLen]byte
+//	chunk    uint32  plaintext bytes per chunk
+//	prefix   [7]byte random nonce prefix
+//	chunks   ...     ciphertext, each chunk + 16-byte tag
+//
+// Chunk i is sealed with nonce prefix || uint32(i) || last, where last is 1
+// for the final chunk and 0 otherwise, and the header as additional data.
+// Reordered, dropped, or truncated chunks and header edits all fail to
+// authenticate.
+const (
+	// EncryptedMagic identifies an encrypted model file ("ZENC" little-endian).
+	EncryptedMagic uint32 = 0x434E455A
+
+	encryptedVersion   = 1
+	encryptedChunkSize = 1 << 20
+	noncePrefixSize    = 7
+	maxKeyIDLen        = 256
+)
+
+// ModelKeyEnv is the environment variable EnvKeyProvider reads when no
+// key-specific variable is set.
+const ModelKeyEnv = "ZERFOO_MODEL_KEY"
+
+// ErrDecrypt is wrapped by errors from authenticating an encrypted model.
+var ErrDecrypt = errors.New("gguf: model decryption failed")
+
+// KeyProvider supplies the AES-256 key for an encrypted model. Implement it
+// to fetch keys from a KMS or secure element; keyID is the identifier stored
+// in the file header.
+type KeyProvider interface {
+	Key(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// KeyProviderFunc adapts a function to KeyProvider.
+type KeyProviderFunc func(ctx context.Context, keyID string) ([]byte, error)
+
+// Key implements KeyProvider.
+func (f KeyProviderFunc) Key(ctx context.Context, keyID string) ([]byte, error) {
+	return f(ctx, keyID)
+}
+
+// EnvKeyProvider reads keys from the environment. For key ID "prod-v2" it
+// looks up ZERFOO_MODEL_KEY_PROD_V2, then ZERFOO_MODEL_KEY. Values are 32
+// bytes encoded as hex or standard base64.
+type EnvKeyProvider struct{}
+
+// Key implements KeyProvider.
+func (EnvKeyProvider) Key(_ context.Context, keyID string) ([]byte, error) {
+	names := []string{ModelKeyEnv}
+	if keyID != "" {
+		names = append([]string{ModelKeyEnv + "_" + envSuffix(keyID)}, names...)
+	}
+	for _, name := range names {
+		if v, ok := os.LookupEnv(name); ok {
+			key, err := ParseKey(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no model key for key ID %q: set %s", keyID, strings.Join(names, " or "))
+}
+
+func envSuffix(keyID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, keyID)
+}
+
+// ParseKey decodes a 32-byte AES-256 key from hex or standard base64.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, errors.New("model key must be 32 bytes, hex or base64 encoded")
+}
+
+// Encrypt seals the model read from src into dst with key (32 bytes), recording
+// keyID in the header so the loader can ask a KeyProvider for the key.
+func Encrypt(dst io.Writer, src io.Reader, key []byte, keyID string) error {
+	if len(keyID) > maxKeyIDLen {
+		return fmt.Errorf("key ID longer than %d bytes", maxKeyIDLen)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	var prefix [noncePrefixSize]byte
+	if _, err := rand.Read(prefix[:]); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	header := encodeEncryptedHeader(keyID, encryptedChunkSize, prefix)
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+
+	// Read one chunk ahead so the final chunk can be marked as last.
+	cur := make([]byte, encryptedChunkSize)
+	next := make([]byte, encryptedChunkSize)
+	n, err := io.ReadFull(src, cur)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	out := make([]byte, 0, encryptedChunkSize+aead.Overhead())
+	for i := uint32(0); ; i++ {
+		var m int
+		if n == encryptedChunkSize {
+			m, err = io.ReadFull(src, next)
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return err
+			}
+		}
+		last := m == 0
+		out = aead.Seal(out[:0], chunkNonce(prefix, i, last), cur[:n], header)
+		if _, err := dst.Write(out); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		if i == ^uint32(0) {
+			return errors.New("model too large to encrypt")
+		}
+		cur, next, n = next, cur, m
+	}
+}
+
+// IsEncrypted reports whether the file at path is an encrypted model.
+func IsEncrypted(path string) (bool, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+	var magic uint32
+	if err := binary.Read(f, binary.LittleEndian, &magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return magic == EncryptedMagic, nil
+}
+
+// Decrypt authenticates and decrypts the encrypted model read from src into
+// dst, fetching the key from kp. Chunks are written to dst only after they
+// authenticate, but a failure after the first chunk leaves dst holding a
+// prefix of the model; discard dst on error.
+func Decrypt(ctx context.Context, dst io.Writer, src io.Reader, kp KeyProvider) error {
+	keyID, chunkSize, prefix, header, err := readEncryptedHeader(src)
+	if err != nil {
+		return err
+	}
+	if kp == nil {
+		return errors.New("encrypted model: no key provider")
+	}
+	key, err := kp.Key(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("get model key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	sealed := chunkSize + aead.Overhead()
+	cur := make([]byte, sealed)
+	next := make([]byte, sealed)
+	n, err := io.ReadFull(src, cur)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: missing data: %w", ErrDecrypt, err)
+	}
+	out := make([]byte, 0, chunkSize)
+	for i := uint32(0); ; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var m int
+		if n == sealed {
+			m, err = io.ReadFull(src, next)
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return err
+			}
+		}
+		last := m == 0
+		out, err = aead.Open(out[:0], chunkNonce(prefix, i, last), cur[:n], header)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %d does not authenticate (wrong key or corrupted file)", ErrDecrypt, i)
+		}
+		if _, err := dst.Write(out); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		cur, next, n = next, cur, m
+	}
+}
+
+// DecryptFile decrypts the encrypted model at path into memory. The
+// plaintext never touches disk.
+func DecryptFile(ctx context.Context, path string, kp KeyProvider) ([]byte, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	var buf bytes.Buffer
+	if st, err := f.Stat(); err == nil {
+		buf.Grow(int(st.Size()))
+	}
+	if err := Decrypt(ctx, &buf, f, kp); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("model key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix [noncePrefixSize]byte, i uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix[:])
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], i)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func encodeEncryptedHeader(keyID string, chunkSize uint32, prefix [noncePrefixSize]byte) []byte {
+	h := make([]byte, 0, 4+4+2+len(keyID)+4+noncePrefixSize)
+	h = binary.LittleEndian.AppendUint32(h, EncryptedMagic)
+	h = binary.LittleEndian.AppendUint32(h, encryptedVersion)
+	h = binary.LittleEndian.AppendUint16(h, uint16(len(keyID)))
+	h = append(h, keyID...)
+	h = binary.LittleEndian.AppendUint32(h, chunkSize)
+	return append(h, prefix[:]...)
+}
+
+func readEncryptedHeader(r io.Reader) (keyID string, chunkSize int, prefix [noncePrefixSize]byte, header []byte, err error) {
+	fixed := make([]byte, 10)
+	if _, err = io.ReadFull(r, fixed); err != nil {
+		return "", 0, prefix, nil, fmt.Errorf("read encrypted header: %w", err)
+	}
+	if magic := binary.LittleEndian.Uint32(fixed); magic != EncryptedMagic {
+		return "", 0, prefix, nil, fmt.Errorf("not an encrypted model: magic 0x%08X", magic)
+	}
+	if v := binary.LittleEndian.Uint32(fixed[4:]); v != encryptedVersion {
+		return "", 0, prefix, nil, fmt.Errorf("unsupported encrypted model version %d", v)
+	}
+	idLen := int(binary.LittleEndian.Uint16(fixed[8:]))
+	if idLen > maxKeyIDLen {
+		return "", 0, prefix, nil, fmt.Errorf("key ID length %d exceeds maximum (%d)", idLen, maxKeyIDLen)
+	}
+	rest := make([]byte, idLen+4+noncePrefixSize)
+	if _, err = io.ReadFull(r, rest); err != nil {
+		return "", 0, prefix, nil, fmt.Errorf("read encrypted header: %w", err)
+	}
+	keyID = string(rest[:idLen])
+	chunk := binary.LittleEndian.Uint32(rest[idLen:])
+	if chunk == 0 || chunk > 64<<20 {
+		return "", 0, prefix, nil, fmt.Errorf("invalid chunk size %d", chunk)
+	}
+	copy(prefix[:], rest[idLen+4:])
+	return keyID, int(chunk), prefix, append(fixed, rest...), nil
+}
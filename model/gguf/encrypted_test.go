@@ -0,0 +1,158 @@
+package gguf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(seed byte) []byte {
+	return bytes.Repeat([]byte{seed}, 32)
+}
+
+func staticKey(key []byte) KeyProvider {
+	return KeyProviderFunc(func(context.Context, string) ([]byte, error) { return key, nil })
+}
+
+func encryptBytes(t *testing.T, plain, key []byte, keyID string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Encrypt(&buf, bytes.NewReader(plain), key, keyID); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, size := range []int{0, 1, encryptedChunkSize - 1, encryptedChunkSize, 2*encryptedChunkSize + 5} {
+		plain := make([]byte, size)
+		for i := range plain {
+			plain[i] = byte(rng.Uint32())
+		}
+		sealed := encryptBytes(t, plain, testKey(7), "prod")
+		if bytes.Contains(sealed, plain[:min(size, 64)]) && size >= 16 {
+			t.Errorf("size %d: ciphertext contains plaintext", size)
+		}
+		var got bytes.Buffer
+		if err := Decrypt(context.Background(), &got, bytes.NewReader(sealed), staticKey(testKey(7))); err != nil {
+			t.Fatalf("size %d: Decrypt: %v", size, err)
+		}
+		if !bytes.Equal(got.Bytes(), plain) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestDecrypt_RejectsTampering(t *testing.T) {
+	plain := bytes.Repeat([]byte("weights!"), encryptedChunkSize/4) // two chunks
+	sealed := encryptBytes(t, plain, testKey(1), "k1")
+	headerLen := 4 + 4 + 2 + len("k1") + 4 + noncePrefixSize
+	sealedChunk := encryptedChunkSize + 16
+
+	tests := []struct {
+		name string
+		data []byte
+		key  []byte
+	}{
+		{"wrong key", sealed, testKey(2)},
+		{"flipped ciphertext byte", flip(sealed, headerLen+10), testKey(1)},
+		{"edited key id", flip(sealed, 10), testKey(1)},
+		{"dropped final chunk", sealed[:headerLen+sealedChunk], testKey(1)},
+		{"truncated chunk", sealed[:len(sealed)-1], testKey(1)},
+		{"header only", sealed[:headerLen], testKey(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Decrypt(context.Background(), &bytes.Buffer{}, bytes.NewReader(tt.data), staticKey(tt.key))
+			if !errors.Is(err, ErrDecrypt) {
+				t.Errorf("err = %v, want ErrDecrypt", err)
+			}
+		})
+	}
+}
+
+func flip(b []byte, i int) []byte {
+	out := bytes.Clone(b)
+	out[i] ^= 0x01
+	return out
+}
+
+func TestDecrypt_PassesKeyID(t *testing.T) {
+	sealed := encryptBytes(t, []byte("model"), testKey(3), "edge/v2")
+	var gotID string
+	kp := KeyProviderFunc(func(_ context.Context, keyID string) ([]byte, error) {
+		gotID = keyID
+		return testKey(3), nil
+	})
+	if err := Decrypt(context.Background(), &bytes.Buffer{}, bytes.NewReader(sealed), kp); err != nil {
+		t.Fatal(err)
+	}
+	if gotID != "edge/v2" {
+		t.Errorf("key ID = %q, want %q", gotID, "edge/v2")
+	}
+}
+
+func TestEncrypt_InvalidKey(t *testing.T) {
+	if err := Encrypt(&bytes.Buffer{}, bytes.NewReader(nil), testKey(1)[:16], ""); err == nil {
+		t.Error("expected error for 16-byte key")
+	}
+}
+
+func TestEnvKeyProvider(t *testing.T) {
+	key := testKey(9)
+	t.Setenv(ModelKeyEnv, hex.EncodeToString(key))
+	t.Setenv(ModelKeyEnv+"_EDGE_V2", base64.StdEncoding.EncodeToString(testKey(4)))
+
+	got, err := EnvKeyProvider{}.Key(context.Background(), "")
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("default key = %x, %v", got, err)
+	}
+	got, err = EnvKeyProvider{}.Key(context.Background(), "edge/v2")
+	if err != nil || !bytes.Equal(got, testKey(4)) {
+		t.Errorf("key for edge/v2 = %x, %v", got, err)
+	}
+	got, err = EnvKeyProvider{}.Key(context.Background(), "other")
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("fallback key = %x, %v", got, err)
+	}
+
+	t.Setenv(ModelKeyEnv, "not-a-key")
+	if _, err := (EnvKeyProvider{}).Key(context.Background(), ""); err == nil {
+		t.Error("expected error for malformed key")
+	}
+}
+
+func TestIsEncryptedAndDecryptFile(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "model.gguf")
+	sealedPath := filepath.Join(dir, "model.gguf.enc")
+	plain := writeStampedGGUF(t, true)
+	if err := os.WriteFile(plainPath, plain, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sealedPath, encryptBytes(t, plain, testKey(5), ""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{plainPath: false, sealedPath: true} {
+		got, err := IsEncrypted(path)
+		if err != nil || got != want {
+			t.Errorf("IsEncrypted(%s) = %v, %v; want %v", filepath.Base(path), got, err, want)
+		}
+	}
+
+	got, err := DecryptFile(context.Background(), sealedPath, staticKey(testKey(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Error("DecryptFile returned different bytes")
+	}
+}
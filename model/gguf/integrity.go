@@ -0,0 +1,206 @@
+package gguf
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Metadata keys for embedded integrity digests. Both hold lowercase hex
+// SHA-256 digests. MetaTensorSHA256 is a string array with one digest per
+// tensor, in tensor-info order, covering the tensor's data bytes.
+// MetaDataSHA256 covers the whole tensor data section, from the aligned data
+// offset to the end of the file, so truncated or extended files are rejected
+// even when every surviving tensor is intact.
+const (
+	MetaTensorSHA256 = "zerfoo.integrity.tensor_sha256"
+	MetaDataSHA256   = "zerfoo.integrity.data_sha256"
+)
+
+// SignatureSuffix is appended to a model path to locate its detached
+// signature file.
+const SignatureSuffix = ".sig"
+
+var (
+	// ErrNoIntegrity is returned by VerifyIntegrity when the file carries no
+	// integrity metadata.
+	ErrNoIntegrity = errors.New("gguf: file has no integrity metadata")
+
+	// ErrIntegrity is wrapped by every digest or signature mismatch.
+	ErrIntegrity = errors.New("gguf: integrity check failed")
+)
+
+// dataAlignment is the GGUF tensor data alignment used by the writer.
+const dataAlignment = 32
+
+// IntegrityMetadata computes the values of MetaTensorSHA256 and
+// MetaDataSHA256 for a file whose tensors, in order, hold the given raw data.
+// It assumes the standard writer layout, where every tensor is zero-padded
+// to 32 bytes. Add the results to the writer before calling Write:
+//
+//	tensorSums, dataSum := gguf.IntegrityMetadata(raw)
+//	w.AddMetadataStringArray(gguf.MetaTensorSHA256, tensorSums)
+//	w.AddMetadataString(gguf.MetaDataSHA256, dataSum)
+func IntegrityMetadata(tensors [][]byte) (tensorSHA256 []string, dataSHA256 string) {
+	var pad [dataAlignment]byte
+	section := sha256.New()
+	tensorSHA256 = make([]string, len(tensors))
+	for i, data := range tensors {
+		sum := sha256.Sum256(data)
+		tensorSHA256[i] = hex.EncodeToString(sum[:])
+		section.Write(data)
+		if rem := len(data) % dataAlignment; rem != 0 {
+			section.Write(pad[:dataAlignment-rem])
+		}
+	}
+	return tensorSHA256, hex.EncodeToString(section.Sum(nil))
+}
+
+// HasIntegrity reports whether f carries integrity metadata.
+func (f *File) HasIntegrity() bool {
+	_, ok := f.Metadata[MetaDataSHA256]
+	return ok
+}
+
+// VerifyIntegrity checks the tensor data of f, read from r, against the
+// embedded digests. It returns ErrNoIntegrity if the file has none, and an
+// error wrapping ErrIntegrity if any tensor or the data section does not
+// match, including when the file is truncated. The data is read in a single
+// sequential pass.
+//
+// The digests do not cover the metadata itself; use VerifySignature to
+// authenticate the whole file.
+func VerifyIntegrity(f *File, r io.ReadSeeker) error {
+	want, err := integrityDigests(f)
+	if err != nil {
+		return err
+	}
+
+	order := make([]int, len(f.Tensors))
+	sizes := make([]int64, len(f.Tensors))
+	for i := range f.Tensors {
+		ti := &f.Tensors[i]
+		n, err := computeNumElements(ti.Name, ti.Dimensions)
+		if err != nil {
+			return err
+		}
+		size, err := TensorByteSize(ti.Type, int(n))
+		if err != nil {
+			return fmt.Errorf("tensor %q: %w", ti.Name, err)
+		}
+		order[i] = i
+		sizes[i] = int64(size)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return f.Tensors[order[a]].Offset < f.Tensors[order[b]].Offset
+	})
+
+	if _, err := r.Seek(f.DataOffset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek to data offset %d: %w", f.DataOffset, err)
+	}
+	section := sha256.New()
+	tr := io.TeeReader(r, section)
+	var pos int64
+	for _, i := range order {
+		ti := &f.Tensors[i]
+		off := int64(ti.Offset)
+		if off < pos {
+			return fmt.Errorf("%w: tensor %q overlaps the previous tensor", ErrIntegrity, ti.Name)
+		}
+		if _, err := io.CopyN(io.Discard, tr, off-pos); err != nil {
+			return truncated(ti.Name, err)
+		}
+		h := sha256.New()
+		if _, err := io.CopyN(h, tr, sizes[i]); err != nil {
+			return truncated(ti.Name, err)
+		}
+		pos = off + sizes[i]
+		if got := hex.EncodeToString(h.Sum(nil)); got != want.tensors[i] {
+			return fmt.Errorf("%w: tensor %q sha256 %s, want %s", ErrIntegrity, ti.Name, got, want.tensors[i])
+		}
+	}
+	if _, err := io.Copy(io.Discard, tr); err != nil {
+		return fmt.Errorf("read tensor data: %w", err)
+	}
+	if got := hex.EncodeToString(section.Sum(nil)); got != want.data {
+		return fmt.Errorf("%w: data section sha256 %s, want %s", ErrIntegrity, got, want.data)
+	}
+	return nil
+}
+
+type fileDigests struct {
+	tensors []string
+	data    string
+}
+
+// integrityDigests extracts the embedded digests from f's metadata.
+func integrityDigests(f *File) (fileDigests, error) {
+	data, hasData := f.GetString(MetaDataSHA256)
+	raw, hasTensors := f.Metadata[MetaTensorSHA256]
+	if !hasData && !hasTensors {
+		return fileDigests{}, ErrNoIntegrity
+	}
+	arr, ok := raw.([]any)
+	if !hasData || !ok {
+		return fileDigests{}, fmt.Errorf("%w: malformed integrity metadata", ErrIntegrity)
+	}
+	if len(arr) != len(f.Tensors) {
+		return fileDigests{}, fmt.Errorf("%w: %d tensor digests for %d tensors", ErrIntegrity, len(arr), len(f.Tensors))
+	}
+	d := fileDigests{tensors: make([]string, len(arr)), data: data}
+	for i, v := range arr {
+		s, ok := v.(string)
+		if !ok {
+			return fileDigests{}, fmt.Errorf("%w: tensor digest %d is %T, want string", ErrIntegrity, i, v)
+		}
+		d.tensors[i] = s
+	}
+	return d, nil
+}
+
+func truncated(name string, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: file truncated in tensor %q", ErrIntegrity, name)
+	}
+	return fmt.Errorf("tensor %q: %w", name, err)
+}
+
+// FileSHA256 returns the SHA-256 digest of everything read from r.
+func FileSHA256(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Sign returns a detached ed25519 signature over the SHA-256 digest of the
+// file read from r. Write it next to the model as path + SignatureSuffix.
+func Sign(r io.Reader, key ed25519.PrivateKey) ([]byte, error) {
+	sum, err := FileSHA256(r)
+	if err != nil {
+		return nil, fmt.Errorf("hash file: %w", err)
+	}
+	return ed25519.Sign(key, sum), nil
+}
+
+// VerifySignature checks a detached ed25519 signature, as produced by Sign,
+// against the file read from r. It returns an error wrapping ErrIntegrity if
+// the signature does not match.
+func VerifySignature(r io.Reader, sig []byte, key ed25519.PublicKey) error {
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key length %d", len(key))
+	}
+	sum, err := FileSHA256(r)
+	if err != nil {
+		return fmt.Errorf("hash file: %w", err)
+	}
+	if !ed25519.Verify(key, sum, sig) {
+		return fmt.Errorf("%w: signature does not match", ErrIntegrity)
+	}
+	return nil
+}
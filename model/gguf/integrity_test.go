@@ -0,0 +1,128 @@
+package gguf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	ztensorgguf "github.com/zerfoo/ztensor/gguf"
+)
+
+// writeStampedGGUF writes a two-tensor F32 file, with integrity metadata if
+// stamp is true.
+func writeStampedGGUF(t *testing.T, stamp bool) []byte {
+	t.Helper()
+	raw := [][]byte{
+		bytes.Repeat([]byte{1, 2, 3, 4}, 6),  // [2,3]: 24 bytes, padded to 32
+		bytes.Repeat([]byte{5, 6, 7, 8}, 10), // [10]: 40 bytes, padded to 64
+	}
+	w := ztensorgguf.NewWriter()
+	w.AddMetadataString("general.architecture", "llama")
+	if stamp {
+		tensorSums, dataSum := IntegrityMetadata(raw)
+		w.AddMetadataStringArray(MetaTensorSHA256, tensorSums)
+		w.AddMetadataString(MetaDataSHA256, dataSum)
+	}
+	w.AddTensor("a.weight", ztensorgguf.TypeF32, []int{2, 3}, raw[0])
+	w.AddTensor("b.weight", ztensorgguf.TypeF32, []int{10}, raw[1])
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func verifyBytes(t *testing.T, data []byte) error {
+	t.Helper()
+	f, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return VerifyIntegrity(f, bytes.NewReader(data))
+}
+
+func TestVerifyIntegrity(t *testing.T) {
+	good := writeStampedGGUF(t, true)
+	if err := verifyBytes(t, good); err != nil {
+		t.Fatalf("intact file: %v", err)
+	}
+	f, _ := Parse(bytes.NewReader(good))
+	if !f.HasIntegrity() {
+		t.Error("HasIntegrity() = false for stamped file")
+	}
+
+	tests := []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"flipped tensor byte", func(b []byte) []byte {
+			b[int(f.DataOffset)+3] ^= 0xff
+			return b
+		}},
+		{"flipped padding byte", func(b []byte) []byte {
+			b[int(f.DataOffset)+30] = 9
+			return b
+		}},
+		{"truncated tensor", func(b []byte) []byte { return b[:int(f.DataOffset)+50] }},
+		{"truncated padding", func(b []byte) []byte { return b[:len(b)-4] }},
+		{"appended bytes", func(b []byte) []byte { return append(b, 0) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.mutate(bytes.Clone(good))
+			if err := verifyBytes(t, data); !errors.Is(err, ErrIntegrity) {
+				t.Errorf("err = %v, want ErrIntegrity", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIntegrity_Unstamped(t *testing.T) {
+	data := writeStampedGGUF(t, false)
+	f, _ := Parse(bytes.NewReader(data))
+	if f.HasIntegrity() {
+		t.Error("HasIntegrity() = true for unstamped file")
+	}
+	if err := VerifyIntegrity(f, bytes.NewReader(data)); !errors.Is(err, ErrNoIntegrity) {
+		t.Errorf("err = %v, want ErrNoIntegrity", err)
+	}
+}
+
+func TestVerifyIntegrity_DigestCountMismatch(t *testing.T) {
+	data := writeStampedGGUF(t, true)
+	f, _ := Parse(bytes.NewReader(data))
+	f.Metadata[MetaTensorSHA256] = f.Metadata[MetaTensorSHA256].([]any)[:1]
+	if err := VerifyIntegrity(f, bytes.NewReader(data)); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("err = %v, want ErrIntegrity", err)
+	}
+}
+
+func TestSignVerifySignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := writeStampedGGUF(t, false)
+	sig, err := Sign(bytes.NewReader(data), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(bytes.NewReader(data), sig, pub); err != nil {
+		t.Fatalf("valid signature: %v", err)
+	}
+
+	// Metadata is not covered by the embedded digests but is by the signature.
+	tampered := bytes.Replace(data, []byte("llama"), []byte("gemma"), 1)
+	if err := VerifySignature(bytes.NewReader(tampered), sig, pub); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("tampered file: err = %v, want ErrIntegrity", err)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if err := VerifySignature(bytes.NewReader(data), sig, otherPub); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("wrong key: err = %v, want ErrIntegrity", err)
+	}
+	if err := VerifySignature(bytes.NewReader(data), sig, pub[:8]); err == nil {
+		t.Error("short key: expected error")
+	}
+}
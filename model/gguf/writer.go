@@ -0,0 +1,229 @@
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// alignment is the tensor data alignment Parse assumes.
+const alignment = 32
+
+// WriterTensor is a tensor to write. Dimensions are in GGUF order,
+// innermost first, as in TensorInfo. WriteData must write exactly Size
+// bytes; it is called once, in tensor order, so data can be streamed from
+// the source file instead of held in memory.
+type WriterTensor struct {
+	Name       string
+	Dimensions []uint64
+	Type       GGMLType
+	Size       int64
+	WriteData  func(w io.Writer) error
+}
+
+// Write writes a GGUF v3 file with the given metadata and tensors. Metadata
+// values use the Go types Parse returns (arrays as []any), so a parsed file
+// can be rewritten without loss; keys are written in sorted order. Unlike
+// ztensor/gguf.Writer, every metadata type round-trips, including the
+// float32 and int32 arrays tokenizers store.
+func Write(w io.Writer, metadata map[string]any, tensors []WriterTensor) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+
+	e.u32(Magic)
+	e.u32(3)
+	e.u64(uint64(len(tensors)))
+	e.u64(uint64(len(metadata)))
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		e.str(k)
+		typ, err := valueType(metadata[k])
+		if err != nil {
+			return fmt.Errorf("gguf: metadata %q: %w", k, err)
+		}
+		e.u32(typ)
+		if err := e.value(typ, metadata[k]); err != nil {
+			return fmt.Errorf("gguf: metadata %q: %w", k, err)
+		}
+	}
+
+	var offset uint64
+	for _, t := range tensors {
+		e.str(t.Name)
+		e.u32(uint32(len(t.Dimensions)))
+		for _, d := range t.Dimensions {
+			e.u64(d)
+		}
+		e.u32(uint32(t.Type))
+		e.u64(offset)
+		offset = alignUp(offset+uint64(t.Size), alignment)
+	}
+	e.pad()
+	if e.err != nil {
+		return fmt.Errorf("gguf: write header: %w", e.err)
+	}
+
+	for _, t := range tensors {
+		cw := &countingWriter{w: bw}
+		if err := t.WriteData(cw); err != nil {
+			return fmt.Errorf("gguf: write tensor %q: %w", t.Name, err)
+		}
+		if cw.n != t.Size {
+			return fmt.Errorf("gguf: tensor %q: wrote %d bytes, want %d", t.Name, cw.n, t.Size)
+		}
+		e.n += cw.n
+		e.pad()
+		if e.err != nil {
+			return fmt.Errorf("gguf: write tensor %q: %w", t.Name, e.err)
+		}
+	}
+	return bw.Flush()
+}
+
+func alignUp(n, a uint64) uint64 {
+	return (n + a - 1) / a * a
+}
+
+// valueType returns the GGUF metadata type of v. An empty array is written
+// as an empty uint32 array, since Parse does not keep element types.
+func valueType(v any) (uint32, error) {
+	switch v.(type) {
+	case uint8:
+		return TypeUint8, nil
+	case int8:
+		return TypeInt8, nil
+	case uint16:
+		return TypeUint16, nil
+	case int16:
+		return TypeInt16, nil
+	case uint32:
+		return TypeUint32, nil
+	case int32:
+		return TypeInt32, nil
+	case float32:
+		return TypeFloat32, nil
+	case bool:
+		return TypeBool, nil
+	case string:
+		return TypeString, nil
+	case []any:
+		return TypeArray, nil
+	case uint64:
+		return TypeUint64, nil
+	case int64:
+		return TypeInt64, nil
+	case float64:
+		return TypeFloat64, nil
+	default:
+		return 0, fmt.Errorf("unsupported metadata type %T", v)
+	}
+}
+
+// encoder writes little-endian GGUF values, keeping the first error and
+// the byte count for alignment.
+type encoder struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err != nil {
+		return
+	}
+	n, err := e.w.Write(p)
+	e.n += int64(n)
+	e.err = err
+}
+
+func (e *encoder) u8(v uint8) { e.write([]byte{v}) }
+
+func (e *encoder) u16(v uint16) { e.write(binary.LittleEndian.AppendUint16(nil, v)) }
+
+func (e *encoder) u32(v uint32) { e.write(binary.LittleEndian.AppendUint32(nil, v)) }
+
+func (e *encoder) u64(v uint64) { e.write(binary.LittleEndian.AppendUint64(nil, v)) }
+
+func (e *encoder) str(s string) {
+	e.u64(uint64(len(s)))
+	e.write([]byte(s))
+}
+
+func (e *encoder) pad() {
+	if r := e.n % alignment; r != 0 {
+		e.write(make([]byte, alignment-r))
+	}
+}
+
+func (e *encoder) value(typ uint32, v any) error {
+	switch typ {
+	case TypeUint8:
+		e.u8(v.(uint8))
+	case TypeInt8:
+		e.u8(uint8(v.(int8)))
+	case TypeUint16:
+		e.u16(v.(uint16))
+	case TypeInt16:
+		e.u16(uint16(v.(int16)))
+	case TypeUint32:
+		e.u32(v.(uint32))
+	case TypeInt32:
+		e.u32(uint32(v.(int32)))
+	case TypeFloat32:
+		e.u32(math.Float32bits(v.(float32)))
+	case TypeBool:
+		var b uint8
+		if v.(bool) {
+			b = 1
+		}
+		e.u8(b)
+	case TypeString:
+		e.str(v.(string))
+	case TypeUint64:
+		e.u64(v.(uint64))
+	case TypeInt64:
+		e.u64(uint64(v.(int64)))
+	case TypeFloat64:
+		e.u64(math.Float64bits(v.(float64)))
+	case TypeArray:
+		arr := v.([]any)
+		elem := TypeUint32
+		if len(arr) > 0 {
+			var err error
+			if elem, err = valueType(arr[0]); err != nil {
+				return err
+			}
+		}
+		e.u32(elem)
+		e.u64(uint64(len(arr)))
+		for i, x := range arr {
+			t, err := valueType(x)
+			if err != nil || t != elem {
+				return fmt.Errorf("array element %d is %T, want the type of element 0", i, x)
+			}
+			if err := e.value(elem, x); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
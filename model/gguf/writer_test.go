@@ -0,0 +1,93 @@
+package gguf
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func bytesTensor(name string, typ GGMLType, dims []uint64, data []byte) WriterTensor {
+	return WriterTensor{
+		Name:       name,
+		Dimensions: dims,
+		Type:       typ,
+		Size:       int64(len(data)),
+		WriteData: func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		},
+	}
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	metadata := map[string]any{
+		"general.architecture":      "llama",
+		"llama.vocab_size":          uint32(3),
+		"llama.rope.freq_base":      float32(10000),
+		"general.file_type":         int32(7),
+		"general.size":              uint64(1 << 40),
+		"general.quantized":         true,
+		"general.u8":                uint8(5),
+		"general.f64":               1.5,
+		"tokenizer.ggml.tokens":     []any{"a", "b", "ab"},
+		"tokenizer.ggml.scores":     []any{float32(-1), float32(-2), float32(-0.5)},
+		"tokenizer.ggml.token_type": []any{int32(1), int32(1), int32(3)},
+		"tokenizer.ggml.merges":     []any{},
+	}
+	first := []byte{1, 2, 3, 4, 5}
+	second := bytes.Repeat([]byte{9}, 40)
+	tensors := []WriterTensor{
+		bytesTensor("first", GGMLTypeQ8_0, []uint64{5}, first),
+		bytesTensor("second", GGMLTypeF32, []uint64{2, 5}, second),
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, metadata, tensors); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	r := bytes.NewReader(buf.Bytes())
+	f, err := Parse(r)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(f.Metadata, metadata) {
+		t.Errorf("metadata = %#v\nwant %#v", f.Metadata, metadata)
+	}
+	if len(f.Tensors) != 2 {
+		t.Fatalf("got %d tensors, want 2", len(f.Tensors))
+	}
+	for i, want := range [][]byte{first, second} {
+		ti := f.Tensors[i]
+		if ti.Name != tensors[i].Name || ti.Type != tensors[i].Type || !reflect.DeepEqual(ti.Dimensions, tensors[i].Dimensions) {
+			t.Errorf("tensor %d = %+v, want %+v", i, ti, tensors[i])
+		}
+		if ti.Offset%alignment != 0 {
+			t.Errorf("tensor %q offset %d not aligned", ti.Name, ti.Offset)
+		}
+		got := make([]byte, len(want))
+		if _, err := r.ReadAt(got, f.DataOffset+int64(ti.Offset)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("tensor %q data = %v, want %v", ti.Name, got, want)
+		}
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	short := bytesTensor("t", GGMLTypeF32, []uint64{2}, []byte{1, 2, 3, 4})
+	short.Size = 8
+	for name, tc := range map[string]struct {
+		metadata map[string]any
+		tensors  []WriterTensor
+	}{
+		"unsupported type": {metadata: map[string]any{"k": []int{1}}},
+		"mixed array":      {metadata: map[string]any{"k": []any{"a", uint32(1)}}},
+		"size mismatch":    {tensors: []WriterTensor{short}},
+	} {
+		if err := Write(io.Discard, tc.metadata, tc.tensors); err == nil {
+			t.Errorf("%s: Write() succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/float8"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// InputDType is the kind of values a named model input carries.
+type InputDType string
+
+// Input dtypes. A model runs on a single element type T, so every input
+// arrives as a tensor of T; the dtype constrains the values it may hold.
+const (
+	// InputFloat accepts any value, e.g. dense tabular features.
+	InputFloat InputDType = "float"
+	// InputInt accepts whole numbers, e.g. token IDs or category indices.
+	InputInt InputDType = "int"
+	// InputBool accepts 0 and 1, e.g. attention or padding masks.
+	InputBool InputDType = "bool"
+)
+
+// InputSpec describes one named model input.
+type InputSpec struct {
+	Name  string     `json:"name"`
+	DType InputDType `json:"dtype"`
+	// Shape is the expected shape; dimensions <= 0 (conventionally -1)
+	// match any size, e.g. the batch or sequence length.
+	Shape []int `json:"shape"`
+}
+
+// validateSpecs checks that specs have distinct names and known dtypes.
+func validateSpecs(specs []InputSpec) error {
+	seen := make(map[string]bool, len(specs))
+	for i, s := range specs {
+		if s.Name == "" {
+			return fmt.Errorf("input spec %d has no name", i)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("duplicate input name %q", s.Name)
+		}
+		seen[s.Name] = true
+		switch s.DType {
+		case InputFloat, InputInt, InputBool:
+		default:
+			return fmt.Errorf("input %q: unknown dtype %q", s.Name, s.DType)
+		}
+	}
+	return nil
+}
+
+// ArrangeInputs orders named inputs by specs, giving the positional inputs
+// a graph's Forward expects. Every spec must have an input and every input
+// a spec.
+func ArrangeInputs[T tensor.Numeric](specs []InputSpec, inputs map[string]*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	for name := range inputs {
+		if !hasSpec(specs, name) {
+			return nil, fmt.Errorf("unknown input %q", name)
+		}
+	}
+	ordered := make([]*tensor.TensorNumeric[T], len(specs))
+	for i, s := range specs {
+		t, ok := inputs[s.Name]
+		if !ok || t == nil {
+			return nil, fmt.Errorf("missing input %q", s.Name)
+		}
+		ordered[i] = t
+	}
+	return ordered, nil
+}
+
+func hasSpec(specs []InputSpec, name string) bool {
+	for _, s := range specs {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInput checks input against spec: the rank, the fixed dimensions,
+// and that every value is allowed by the dtype.
+func checkInput[T tensor.Numeric](spec InputSpec, input *tensor.TensorNumeric[T]) error {
+	shape := input.Shape()
+	if len(shape) != len(spec.Shape) {
+		return fmt.Errorf("input %q: expected %d dimensions, got %d", spec.Name, len(spec.Shape), len(shape))
+	}
+	for j, dim := range spec.Shape {
+		if dim > 0 && shape[j] != dim {
+			return fmt.Errorf("input %q: expected shape %v, got %v", spec.Name, spec.Shape, shape)
+		}
+	}
+
+	var allowed func(float64) bool
+	switch spec.DType {
+	case InputInt:
+		allowed = func(f float64) bool { return f == math.Trunc(f) }
+	case InputBool:
+		allowed = func(f float64) bool { return f == 0 || f == 1 }
+	default:
+		return nil
+	}
+	for i, v := range input.Data() {
+		if f := elemFloat64(v); !allowed(f) {
+			return fmt.Errorf("input %q: element %d is %v, not a valid %s", spec.Name, i, f, spec.DType)
+		}
+	}
+	return nil
+}
+
+// elemFloat64 converts a tensor element to float64.
+func elemFloat64[T tensor.Numeric](v T) float64 {
+	switch x := any(v).(type) {
+	case float8.Float8:
+		return x.ToFloat64()
+	case float16.Float16:
+		return x.ToFloat64()
+	case float16.BFloat16:
+		return float64(x.ToFloat32())
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	default:
+		return float64(rv.Int())
+	}
+}
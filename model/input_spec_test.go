@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestBasicModelValidator_ValidateNamedInputs(t *testing.T) {
+	instance := NewMockModelInstance[float32]()
+	instance.metadata.InputSpec = []InputSpec{
+		{Name: "token_ids", DType: InputInt, Shape: []int{-1, -1}},
+		{Name: "features", DType: InputFloat, Shape: []int{-1, 3}},
+		{Name: "mask", DType: InputBool, Shape: []int{-1, -1}},
+	}
+	validator := NewBasicModelValidator[float32]()
+	ctx := context.Background()
+
+	mk := func(shape []int, data ...float32) *tensor.TensorNumeric[float32] {
+		t.Helper()
+		x, err := tensor.New(shape, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return x
+	}
+	valid := func() map[string]*tensor.TensorNumeric[float32] {
+		return map[string]*tensor.TensorNumeric[float32]{
+			"token_ids": mk([]int{1, 4}, 5, 17, 2, 0),
+			"features":  mk([]int{1, 3}, 0.5, -1.25, 3),
+			"mask":      mk([]int{1, 4}, 1, 1, 1, 0),
+		}
+	}
+
+	if err := validator.ValidateNamedInputs(ctx, instance, valid()); err != nil {
+		t.Fatalf("ValidateNamedInputs(valid) = %v", err)
+	}
+	ordered, err := ArrangeInputs(instance.metadata.InputSpec, valid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validator.ValidateInputs(ctx, instance, ordered...); err != nil {
+		t.Errorf("ValidateInputs(arranged) = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(map[string]*tensor.TensorNumeric[float32])
+	}{
+		{"missing input", func(m map[string]*tensor.TensorNumeric[float32]) { delete(m, "mask") }},
+		{"unknown input", func(m map[string]*tensor.TensorNumeric[float32]) { m["extra"] = mk([]int{1}, 0) }},
+		{"fractional token id", func(m map[string]*tensor.TensorNumeric[float32]) { m["token_ids"] = mk([]int{1, 2}, 3, 1.5) }},
+		{"non-binary mask", func(m map[string]*tensor.TensorNumeric[float32]) { m["mask"] = mk([]int{1, 2}, 1, 2) }},
+		{"wrong feature width", func(m map[string]*tensor.TensorNumeric[float32]) { m["features"] = mk([]int{1, 2}, 0, 0) }},
+		{"wrong rank", func(m map[string]*tensor.TensorNumeric[float32]) { m["token_ids"] = mk([]int{4}, 1, 2, 3, 4) }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inputs := valid()
+			tc.modify(inputs)
+			if err := validator.ValidateNamedInputs(ctx, instance, inputs); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if err := validator.ValidateNamedInputs(ctx, NewMockModelInstance[float32](), valid()); err == nil {
+		t.Error("expected an error for a model without named inputs")
+	}
+}
+
+func TestStandardModelInstance_SetInputSpec(t *testing.T) {
+	instance := NewStandardModelInstance(buildTestModelF32(t))
+
+	if err := instance.SetInputSpec(
+		InputSpec{Name: "a", DType: InputFloat},
+		InputSpec{Name: "b", DType: InputFloat},
+	); err == nil {
+		t.Error("expected an error for more specs than graph inputs")
+	}
+	if err := instance.SetInputSpec(InputSpec{Name: "a", DType: "complex"}); err == nil {
+		t.Error("expected an error for an unknown dtype")
+	}
+
+	spec := InputSpec{Name: "features", DType: InputFloat, Shape: []int{-1, 2}}
+	if err := instance.SetInputSpec(spec); err != nil {
+		t.Fatalf("SetInputSpec = %v", err)
+	}
+	meta := instance.GetMetadata()
+	if len(meta.InputSpec) != 1 || meta.InputSpec[0].Name != "features" {
+		t.Errorf("InputSpec = %+v", meta.InputSpec)
+	}
+	if len(meta.InputShape) != 1 || meta.InputShape[0][1] != 2 {
+		t.Errorf("InputShape = %v, want the spec's shape", meta.InputShape)
+	}
+
+	result, err := NewBasicModelValidator[float32]().ValidateModel(context.Background(), instance)
+	if err != nil || !result.IsValid {
+		t.Errorf("ValidateModel = %+v, %v", result, err)
+	}
+}
+
+func TestBasicModelValidator_ValidateModel_BadInputSpec(t *testing.T) {
+	instance := NewStandardModelInstance(buildTestModelF32(t))
+	instance.metadata.InputSpec = []InputSpec{
+		{Name: "x", DType: InputFloat},
+		{Name: "x", DType: InputInt},
+	}
+	result, err := NewBasicModelValidator[float32]().ValidateModel(context.Background(), instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsValid {
+		t.Error("expected duplicate input names to fail validation")
+	}
+}
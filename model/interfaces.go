@@ -171,7 +171,9 @@ type ModelMetadata struct {
 	ModifiedAt   string                 `json:"modified_at"`
 	Parameters   int64                  `json:"parameter_count"`
 	InputShape   [][]int                `json:"input_shapes"`
+	InputSpec    []InputSpec            `json:"input_spec,omitempty"` // Named inputs, in graph input order
 	OutputShape  []int                  `json:"output_shape"`
+	OutputSpec   []OutputSpec           `json:"output_spec,omitempty"` // Named heads, in order along the output's last axis
 	Tags         []string               `json:"tags"`
 	Extensions   map[string]interface{} `json:"extensions"`
 }
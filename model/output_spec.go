@@ -0,0 +1,117 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// OutputSpec describes one named output head of a multi-head model, such
+// as a regression head, an auxiliary classification head, or an embedding.
+// A graph has a single output, so the heads are concatenated along its last
+// axis in spec order; Width is the head's share of that axis.
+type OutputSpec struct {
+	Name  string `json:"name"`
+	Width int    `json:"width"`
+}
+
+// validateOutputSpecs checks that specs have distinct names and positive
+// widths, and returns the total width.
+func validateOutputSpecs(specs []OutputSpec) (int, error) {
+	seen := make(map[string]bool, len(specs))
+	total := 0
+	for i, s := range specs {
+		if s.Name == "" {
+			return 0, fmt.Errorf("output spec %d has no name", i)
+		}
+		if seen[s.Name] {
+			return 0, fmt.Errorf("duplicate output name %q", s.Name)
+		}
+		seen[s.Name] = true
+		if s.Width <= 0 {
+			return 0, fmt.Errorf("output %q: width must be positive, got %d", s.Name, s.Width)
+		}
+		total += s.Width
+	}
+	return total, nil
+}
+
+// SplitOutputs splits a model output into its named heads along the last
+// axis. Each head keeps the output's leading dimensions.
+func SplitOutputs[T tensor.Numeric](specs []OutputSpec, output *tensor.TensorNumeric[T]) (map[string]*tensor.TensorNumeric[T], error) {
+	widths := make([]int, len(specs))
+	for i, s := range specs {
+		widths[i] = s.Width
+	}
+	parts, err := SplitLastAxis(output, widths)
+	if err != nil {
+		return nil, err
+	}
+	heads := make(map[string]*tensor.TensorNumeric[T], len(specs))
+	for i, s := range specs {
+		heads[s.Name] = parts[i]
+	}
+	return heads, nil
+}
+
+// SelectOutput returns the named head of a model output, for serving one
+// output of a multi-head model.
+func SelectOutput[T tensor.Numeric](specs []OutputSpec, output *tensor.TensorNumeric[T], name string) (*tensor.TensorNumeric[T], error) {
+	if !hasOutput(specs, name) {
+		return nil, fmt.Errorf("unknown output %q", name)
+	}
+	heads, err := SplitOutputs(specs, output)
+	if err != nil {
+		return nil, err
+	}
+	return heads[name], nil
+}
+
+func hasOutput(specs []OutputSpec, name string) bool {
+	for _, s := range specs {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitLastAxis splits t along its last axis into parts of the given
+// widths, which must add up to the axis size.
+func SplitLastAxis[T tensor.Numeric](t *tensor.TensorNumeric[T], widths []int) ([]*tensor.TensorNumeric[T], error) {
+	shape := t.Shape()
+	if len(shape) == 0 {
+		return nil, fmt.Errorf("cannot split a scalar")
+	}
+	last := shape[len(shape)-1]
+	total := 0
+	for _, w := range widths {
+		if w <= 0 {
+			return nil, fmt.Errorf("split widths must be positive, got %v", widths)
+		}
+		total += w
+	}
+	if total != last {
+		return nil, fmt.Errorf("split widths %v add up to %d, but the last axis of shape %v is %d", widths, total, shape, last)
+	}
+
+	rows := t.Size() / last
+	src := t.Data()
+	parts := make([]*tensor.TensorNumeric[T], len(widths))
+	offset := 0
+	for i, w := range widths {
+		out := make([]T, rows*w)
+		for r := range rows {
+			start := r*last + offset
+			copy(out[r*w:(r+1)*w], src[start:start+w])
+		}
+		partShape := append(append([]int(nil), shape[:len(shape)-1]...), w)
+		part, err := tensor.New(partShape, out)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = part
+		offset += w
+	}
+	return parts, nil
+}
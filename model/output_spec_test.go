@@ -0,0 +1,63 @@
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestSplitOutputs(t *testing.T) {
+	specs := []OutputSpec{{Name: "price", Width: 1}, {Name: "regime", Width: 3}, {Name: "embedding", Width: 2}}
+	out, err := tensor.New([]int{2, 6}, []float32{
+		1, 2, 3, 4, 5, 6,
+		7, 8, 9, 10, 11, 12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	heads, err := SplitOutputs(specs, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]float32{
+		"price":     {1, 7},
+		"regime":    {2, 3, 4, 8, 9, 10},
+		"embedding": {5, 6, 11, 12},
+	}
+	for _, s := range specs {
+		h := heads[s.Name]
+		if !slices.Equal(h.Shape(), []int{2, s.Width}) || !slices.Equal(h.Data(), want[s.Name]) {
+			t.Errorf("%s = %v %v, want [2 %d] %v", s.Name, h.Shape(), h.Data(), s.Width, want[s.Name])
+		}
+	}
+
+	regime, err := SelectOutput(specs, out, "regime")
+	if err != nil || !slices.Equal(regime.Data(), want["regime"]) {
+		t.Errorf("SelectOutput(regime) = %v, %v", regime, err)
+	}
+	if _, err := SelectOutput(specs, out, "volume"); err == nil {
+		t.Error("expected an error for an unknown output")
+	}
+	if _, err := SplitOutputs(specs[:2], out); err == nil {
+		t.Error("expected an error when the widths do not cover the last axis")
+	}
+}
+
+func TestStandardModelInstance_SetOutputSpec(t *testing.T) {
+	// The test graph's output shape is [2, 2].
+	instance := NewStandardModelInstance(buildTestModelF32(t))
+
+	if err := instance.SetOutputSpec(OutputSpec{Name: "a", Width: 3}); err == nil {
+		t.Error("expected an error for heads wider than the output")
+	}
+	if err := instance.SetOutputSpec(OutputSpec{Name: "a", Width: 1}, OutputSpec{Name: "a", Width: 1}); err == nil {
+		t.Error("expected an error for duplicate head names")
+	}
+	if err := instance.SetOutputSpec(OutputSpec{Name: "a", Width: 1}, OutputSpec{Name: "b", Width: 1}); err != nil {
+		t.Fatalf("SetOutputSpec = %v", err)
+	}
+	if got := instance.GetMetadata().OutputSpec; len(got) != 2 || got[1].Name != "b" {
+		t.Errorf("OutputSpec = %+v", got)
+	}
+}
@@ -0,0 +1,28 @@
+// Package placement splits a model between the CPU and a memory-limited
+// accelerator.
+//
+// Place walks a graph in topological order and places each node with a
+// simple cost model: a node's time on a device is the larger of its
+// compute time (FLOPs over device throughput) and its memory time (bytes
+// touched over device bandwidth). Nodes with parameters are admitted to
+// the accelerator in order of time saved per byte of accelerator memory
+// until the memory budget is spent, so compute-dense MatMuls go to the
+// accelerator and large embedding gathers, which save little time and
+// take a lot of memory, stay on CPU. Parameter-free nodes follow their
+// inputs unless moving is cheaper. Every edge that crosses devices becomes
+// a Transfer in the plan.
+//
+// An Engine carries a plan out: build the model with an Engine wrapping
+// the accelerator and CPU engines, plan it, and apply the plan. Gathers
+// and MatMuls over parameters the plan keeps on CPU then run on the CPU
+// engine, with their operands and results moved between devices as
+// needed; everything else runs on the accelerator.
+//
+//	e := placement.NewEngine(gpu, cpu)
+//	g, err := buildModel(e)
+//	plan, err := placement.Place(g, placement.Config{AcceleratorMemory: 4 << 30})
+//	fmt.Print(plan)
+//	err = e.Apply(g, plan)
+//
+// Stability: alpha
+package placement
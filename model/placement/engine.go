@@ -0,0 +1,160 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/device"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Engine runs every op on the accelerator engine it embeds except Gathers
+// and MatMuls over parameters that an applied plan keeps on CPU, which run
+// on the CPU engine. Operands on the accelerator are copied to the host for
+// those ops, and results are copied into destinations on the accelerator.
+// Ops on the accelerator upload host operands themselves.
+type Engine[T tensor.Numeric] struct {
+	compute.Engine[T]
+	cpu compute.Engine[T]
+
+	mu   sync.RWMutex
+	host map[*tensor.TensorNumeric[T]]bool
+
+	hostOps       atomic.Int64
+	transferBytes atomic.Int64
+}
+
+// NewEngine returns an Engine that runs on accelerator, and on cpu for the
+// parameters a plan applied with Apply keeps on CPU. Until then every op
+// runs on accelerator.
+func NewEngine[T tensor.Numeric](accelerator, cpu compute.Engine[T]) *Engine[T] {
+	return &Engine[T]{
+		Engine: accelerator,
+		cpu:    cpu,
+		host:   make(map[*tensor.TensorNumeric[T]]bool),
+	}
+}
+
+// Apply routes the ops over the parameters of the nodes plan places on CPU
+// to the CPU engine. plan must have been made for g.
+func (e *Engine[T]) Apply(g *graph.Graph[T], plan *Plan) error {
+	nodes, err := g.GetTopologicalOrder()
+	if err != nil {
+		return fmt.Errorf("placement: %w", err)
+	}
+	if len(nodes) != len(plan.Nodes) {
+		return fmt.Errorf("placement: plan has %d nodes, graph has %d", len(plan.Nodes), len(nodes))
+	}
+	host := make(map[*tensor.TensorNumeric[T]]bool)
+	for i, n := range nodes {
+		if plan.Nodes[i].OpType != n.OpType() {
+			return fmt.Errorf("placement: plan node %d is %s, graph node is %s", i, plan.Nodes[i].OpType, n.OpType())
+		}
+		if plan.Nodes[i].Device != CPU {
+			continue
+		}
+		for _, p := range n.Parameters() {
+			host[p.Value] = true
+		}
+	}
+	e.mu.Lock()
+	e.host = host
+	e.mu.Unlock()
+	return nil
+}
+
+// Stats returns the number of ops run on the CPU engine and the bytes
+// copied between devices for them.
+func (e *Engine[T]) Stats() (hostOps, transferBytes int64) {
+	return e.hostOps.Load(), e.transferBytes.Load()
+}
+
+// onHost reports whether any of ts is a parameter kept on CPU.
+func (e *Engine[T]) onHost(ts ...*tensor.TensorNumeric[T]) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, t := range ts {
+		if e.host[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// toHost returns t, copied to the host if it is on the accelerator.
+func (e *Engine[T]) toHost(t *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if isHost(t) {
+		return t, nil
+	}
+	data := t.Data() // D2H copy.
+	e.transferBytes.Add(int64(len(data)) * elemSize[T]())
+	return tensor.New(t.Shape(), data)
+}
+
+// fromHost copies the host result src into dst on the accelerator.
+func (e *Engine[T]) fromHost(dst, src *tensor.TensorNumeric[T]) {
+	data := src.Data()
+	e.transferBytes.Add(int64(len(data)) * elemSize[T]())
+	dst.GetStorage().Set(data) // H2D copy.
+}
+
+func isHost[T tensor.Numeric](t *tensor.TensorNumeric[T]) bool {
+	return t.GetStorage().DeviceType() == device.CPU
+}
+
+// Gather runs on the CPU engine when params is kept on CPU.
+func (e *Engine[T]) Gather(ctx context.Context, params *tensor.TensorNumeric[T], indices *tensor.TensorNumeric[int], output *tensor.TensorNumeric[T]) error {
+	if !e.onHost(params) {
+		return e.Engine.Gather(ctx, params, indices, output)
+	}
+	e.hostOps.Add(1)
+	if isHost(output) {
+		return e.cpu.Gather(ctx, params, indices, output)
+	}
+	out, err := tensor.New[T](output.Shape(), nil)
+	if err != nil {
+		return err
+	}
+	if err := e.cpu.Gather(ctx, params, indices, out); err != nil {
+		return err
+	}
+	e.fromHost(output, out)
+	return nil
+}
+
+// MatMul runs on the CPU engine when a or b is kept on CPU.
+func (e *Engine[T]) MatMul(ctx context.Context, a, b *tensor.TensorNumeric[T], dst ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if !e.onHost(a, b) {
+		return e.Engine.MatMul(ctx, a, b, dst...)
+	}
+	e.hostOps.Add(1)
+	a, err := e.toHost(a)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = e.toHost(b); err != nil {
+		return nil, err
+	}
+	if len(dst) == 0 || dst[0] == nil || isHost(dst[0]) {
+		return e.cpu.MatMul(ctx, a, b, dst...)
+	}
+	out, err := e.cpu.MatMul(ctx, a, b)
+	if err != nil {
+		return nil, err
+	}
+	e.fromHost(dst[0], out)
+	return dst[0], nil
+}
+
+func elemSize[T tensor.Numeric]() int64 {
+	var zero T
+	return int64(unsafe.Sizeof(zero))
+}
+
+// Statically assert that the type implements the Engine interface.
+var _ compute.Engine[float32] = (*Engine[float32])(nil)
@@ -0,0 +1,351 @@
+package placement
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Device is where a node runs.
+type Device int
+
+const (
+	// CPU is the host.
+	CPU Device = iota
+	// Accelerator is the accelerator engine, such as a GPU.
+	Accelerator
+)
+
+func (d Device) String() string {
+	if d == Accelerator {
+		return "accelerator"
+	}
+	return "cpu"
+}
+
+// Config is the cost model of a placement. Zero fields take the defaults.
+type Config struct {
+	// AcceleratorMemory is the accelerator memory available for
+	// parameters, in bytes. Zero means unlimited.
+	AcceleratorMemory int64
+	// CPUFLOPS and AcceleratorFLOPS are the devices' throughput in
+	// floating-point operations per second. The defaults are 2e11 and 1e13.
+	CPUFLOPS         float64
+	AcceleratorFLOPS float64
+	// CPUBandwidth and AcceleratorBandwidth are the devices' memory
+	// bandwidth in bytes per second. The defaults are 5e10 and 5e11.
+	CPUBandwidth         float64
+	AcceleratorBandwidth float64
+	// TransferBandwidth is the host-accelerator link's bandwidth in bytes
+	// per second. The default, 1.6e10, is PCIe Gen4 x16.
+	TransferBandwidth float64
+}
+
+func (c Config) withDefaults() (Config, error) {
+	if c.AcceleratorMemory < 0 {
+		return c, fmt.Errorf("placement: accelerator memory must not be negative, got %d", c.AcceleratorMemory)
+	}
+	for _, f := range []struct {
+		v   *float64
+		def float64
+	}{
+		{&c.CPUFLOPS, 2e11},
+		{&c.AcceleratorFLOPS, 1e13},
+		{&c.CPUBandwidth, 5e10},
+		{&c.AcceleratorBandwidth, 5e11},
+		{&c.TransferBandwidth, 1.6e10},
+	} {
+		if *f.v < 0 {
+			return c, fmt.Errorf("placement: device rates must not be negative, got %g", *f.v)
+		}
+		if *f.v == 0 {
+			*f.v = f.def
+		}
+	}
+	return c, nil
+}
+
+// Node is the placement of one graph node.
+type Node struct {
+	// Name is the node's Name if it has one, else "<op type>_<index>".
+	Name   string
+	OpType string
+	Device Device
+	// ParamBytes is the size of the node's parameters.
+	ParamBytes int64
+	// FLOPs is the estimated work of one forward pass.
+	FLOPs float64
+	// TimeNs is the estimated time of one forward pass on Device.
+	TimeNs float64
+	Reason string
+}
+
+// Transfer is a node output that moves between devices.
+type Transfer struct {
+	// From and To are node indices in Plan.Nodes.
+	From, To int
+	// Dst is the device the output moves to.
+	Dst   Device
+	Bytes int64
+}
+
+// Plan assigns the nodes of a graph, in topological order, to
+// devices.
+type Plan struct {
+	Nodes     []Node
+	Transfers []Transfer
+	// AcceleratorBytes is the accelerator memory taken by parameters.
+	AcceleratorBytes int64
+	// EstimatedNs is the estimated time of one forward pass, run
+	// sequentially, including transfers.
+	EstimatedNs float64
+}
+
+// String returns the plan as a table of nodes followed by the transfers.
+func (p *Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "placement: %d nodes, %d transfers, %d B of parameters on accelerator, est. %.3f ms\n",
+		len(p.Nodes), len(p.Transfers), p.AcceleratorBytes, p.EstimatedNs/1e6)
+	for _, n := range p.Nodes {
+		fmt.Fprintf(&b, "  %-24s %-12s %10d B  %s\n", n.Name, n.Device, n.ParamBytes, n.Reason)
+	}
+	for _, t := range p.Transfers {
+		fmt.Fprintf(&b, "  transfer %s -> %s: %d B to %s\n", p.Nodes[t.From].Name, p.Nodes[t.To].Name, t.Bytes, t.Dst)
+	}
+	return b.String()
+}
+
+// gatherOps are the op types that look up rows of a parameter table.
+var gatherOps = map[string]bool{
+	"Gather":         true,
+	"GatherElements": true,
+	"TokenEmbedding": true,
+}
+
+// cost is a node's estimated work and memory traffic.
+type cost struct {
+	flops      float64
+	bytes      float64
+	paramBytes int64
+	outBytes   int64
+}
+
+func (c cost) timeNs(flops, bandwidth float64) float64 {
+	return math.Max(c.flops/flops, c.bytes/bandwidth) * 1e9
+}
+
+// Place places the nodes of g. Input nodes are on CPU, where their data
+// comes from. Nodes sharing a parameter are placed together.
+func Place[T tensor.Numeric](g *graph.Graph[T], cfg Config) (*Plan, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := g.GetTopologicalOrder()
+	if err != nil {
+		return nil, fmt.Errorf("placement: %w", err)
+	}
+	elem := elemSize[T]()
+	index := make(map[graph.Node[T]]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	plan := &Plan{Nodes: make([]Node, len(nodes))}
+	costs := make([]cost, len(nodes))
+	names := nodeNames(nodes)
+	for i, n := range nodes {
+		costs[i] = nodeCost(g, n, elem)
+		plan.Nodes[i] = Node{Name: names[i], OpType: n.OpType(), ParamBytes: costs[i].paramBytes, FLOPs: costs[i].flops}
+	}
+	timeOn := func(i int, d Device) float64 {
+		if d == Accelerator {
+			return costs[i].timeNs(cfg.AcceleratorFLOPS, cfg.AcceleratorBandwidth)
+		}
+		return costs[i].timeNs(cfg.CPUFLOPS, cfg.CPUBandwidth)
+	}
+
+	// Nodes with parameters go to the accelerator by time saved per byte
+	// of accelerator memory, while the memory lasts.
+	var candidates []int
+	for i, n := range nodes {
+		if len(n.Parameters()) > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	gain := func(i int) float64 {
+		return (timeOn(i, CPU) - timeOn(i, Accelerator)) / float64(max(costs[i].paramBytes, 1))
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return gain(candidates[a]) > gain(candidates[b]) })
+	resident := make(map[*graph.Parameter[T]]Device)
+	decided := make([]bool, len(nodes))
+	for _, i := range candidates {
+		// A node sharing a parameter with a placed node joins it.
+		if d, ok := sharedDevice(nodes[i], resident); ok {
+			plan.Nodes[i].Device = d
+			plan.Nodes[i].Reason = "shares parameters with a node on " + d.String()
+			decided[i] = true
+			continue
+		}
+		need := int64(0)
+		for _, p := range nodes[i].Parameters() {
+			need += int64(p.Value.Size()) * elem
+		}
+		d := CPU
+		switch {
+		case timeOn(i, Accelerator) >= timeOn(i, CPU):
+			plan.Nodes[i].Reason = "no faster on accelerator"
+		case cfg.AcceleratorMemory > 0 && plan.AcceleratorBytes+need > cfg.AcceleratorMemory:
+			plan.Nodes[i].Reason = "does not fit in accelerator memory"
+		default:
+			d = Accelerator
+			plan.AcceleratorBytes += need
+			plan.Nodes[i].Reason = "faster on accelerator"
+			if gatherOps[nodes[i].OpType()] {
+				plan.Nodes[i].Reason = "gather fits in accelerator memory"
+			}
+		}
+		plan.Nodes[i].Device = d
+		for _, p := range nodes[i].Parameters() {
+			resident[p] = d
+		}
+		decided[i] = true
+	}
+
+	// The remaining nodes run where their inputs and time allow, in
+	// topological order.
+	for i, n := range nodes {
+		if decided[i] {
+			continue
+		}
+		deps := g.Dependencies(n)
+		if n.OpType() == "Input" {
+			plan.Nodes[i].Device = CPU
+			plan.Nodes[i].Reason = "graph input"
+			continue
+		}
+		best, bestNs := CPU, math.Inf(1)
+		for _, d := range []Device{Accelerator, CPU} {
+			ns := timeOn(i, d)
+			for _, dep := range deps {
+				if j := index[dep]; plan.Nodes[j].Device != d {
+					ns += float64(costs[j].outBytes) / cfg.TransferBandwidth * 1e9
+				}
+			}
+			if ns < bestNs {
+				best, bestNs = d, ns
+			}
+		}
+		plan.Nodes[i].Device = best
+		plan.Nodes[i].Reason = "follows its inputs"
+		for _, dep := range deps {
+			if plan.Nodes[index[dep]].Device != best {
+				plan.Nodes[i].Reason = "cheaper to move its inputs"
+				break
+			}
+		}
+	}
+
+	for i, n := range nodes {
+		plan.Nodes[i].TimeNs = timeOn(i, plan.Nodes[i].Device)
+		plan.EstimatedNs += plan.Nodes[i].TimeNs
+		for _, dep := range g.Dependencies(n) {
+			j := index[dep]
+			if plan.Nodes[j].Device == plan.Nodes[i].Device {
+				continue
+			}
+			t := Transfer{From: j, To: i, Dst: plan.Nodes[i].Device, Bytes: costs[j].outBytes}
+			plan.Transfers = append(plan.Transfers, t)
+			plan.EstimatedNs += float64(t.Bytes) / cfg.TransferBandwidth * 1e9
+		}
+	}
+	return plan, nil
+}
+
+// sharedDevice returns the device of a placed parameter of n, if any.
+func sharedDevice[T tensor.Numeric](n graph.Node[T], resident map[*graph.Parameter[T]]Device) (Device, bool) {
+	for _, p := range n.Parameters() {
+		if d, ok := resident[p]; ok {
+			return d, true
+		}
+	}
+	return CPU, false
+}
+
+// nodeCost estimates the work of n from its static shapes. Unknown
+// dimensions count as 1. A gather only reads the rows it outputs; a node
+// with parameters does a multiply-add per parameter per output row; a
+// MatMul of two activations does a multiply-add per output element per
+// inner element; anything else does one operation per output element.
+func nodeCost[T tensor.Numeric](g *graph.Graph[T], n graph.Node[T], elem int64) cost {
+	out := elements(n.OutputShape())
+	var in int64
+	var inner int64 = 1
+	for i, dep := range g.Dependencies(n) {
+		shape := dep.OutputShape()
+		in += elements(shape)
+		if i == 0 && len(shape) > 0 {
+			inner = max(int64(shape[len(shape)-1]), 1)
+		}
+	}
+	var params int64
+	for _, p := range n.Parameters() {
+		params += int64(p.Value.Size())
+	}
+	c := cost{paramBytes: params * elem, outBytes: out * elem}
+	shape := n.OutputShape()
+	rows := out
+	if len(shape) > 0 {
+		rows = max(out/max(int64(shape[len(shape)-1]), 1), 1)
+	}
+	switch {
+	case gatherOps[n.OpType()]:
+		c.bytes = float64(2 * out * elem)
+	case params > 0:
+		c.flops = 2 * float64(rows) * float64(params)
+		c.bytes = float64((params + in + out) * elem)
+	case n.OpType() == "MatMul":
+		c.flops = 2 * float64(out) * float64(inner)
+		c.bytes = float64((in + out) * elem)
+	default:
+		c.flops = float64(out)
+		c.bytes = float64((in + out) * elem)
+	}
+	return c
+}
+
+func elements(shape []int) int64 {
+	n := int64(1)
+	for _, d := range shape {
+		n *= max(int64(d), 1)
+	}
+	return n
+}
+
+// nodeNames names each node by its Name method when it has one and by
+// "<op type>_<index>" otherwise, suffixing repeated names with the index so
+// every name is unique.
+func nodeNames[T tensor.Numeric](nodes []graph.Node[T]) []string {
+	names := make([]string, len(nodes))
+	seen := make(map[string]bool, len(nodes))
+	for i, n := range nodes {
+		name := ""
+		if named, ok := n.(interface{ Name() string }); ok {
+			name = named.Name()
+		}
+		if name == "" {
+			name = n.OpType() + "_" + strconv.Itoa(i)
+		}
+		if seen[name] {
+			name += "_" + strconv.Itoa(i)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
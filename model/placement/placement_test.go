@@ -0,0 +1,138 @@
+package placement
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/layers/embeddings"
+)
+
+// countingEngine counts the Gathers and MatMuls it runs.
+type countingEngine struct {
+	compute.Engine[float32]
+	gathers, matmuls atomic.Int64
+}
+
+func (c *countingEngine) Gather(ctx context.Context, params *tensor.TensorNumeric[float32], indices *tensor.TensorNumeric[int], output *tensor.TensorNumeric[float32]) error {
+	c.gathers.Add(1)
+	return c.Engine.Gather(ctx, params, indices, output)
+}
+
+func (c *countingEngine) MatMul(ctx context.Context, a, b *tensor.TensorNumeric[float32], dst ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	c.matmuls.Add(1)
+	return c.Engine.MatMul(ctx, a, b, dst...)
+}
+
+// buildEmbeddingModel builds token ids [4] -> embedding [4, 8] (a 1000-row
+// table) -> dense [4, 4].
+func buildEmbeddingModel(t *testing.T, engine compute.Engine[float32]) *graph.Graph[float32] {
+	t.Helper()
+	b := graph.NewBuilder[float32](engine)
+	in := b.Input([]int{4})
+	emb, err := embeddings.NewTokenEmbedding[float32](engine, 1000, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dense, err := core.NewDense[float32]("dense", engine, numeric.Float32Ops{}, 8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, b.AddNode(emb, in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range g.Parameters() {
+		for j := range p.Value.Data() {
+			p.Value.Data()[j] = float32((i+j)%7) / 7
+		}
+	}
+	return g
+}
+
+func TestPlace_MemoryLimited(t *testing.T) {
+	cpu := &countingEngine{Engine: compute.NewCPUEngine[float32](numeric.Float32Ops{})}
+	accel := &countingEngine{Engine: compute.NewCPUEngine[float32](numeric.Float32Ops{})}
+	e := NewEngine[float32](accel, cpu)
+	g := buildEmbeddingModel(t, e)
+
+	// The table takes 32000 bytes, the dense layer 144.
+	plan, err := Place(g, Config{AcceleratorMemory: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	devices := make(map[string]Device)
+	for _, n := range plan.Nodes {
+		devices[n.OpType] = n.Device
+	}
+	if devices["Input"] != CPU || devices["TokenEmbedding"] != CPU || devices["Dense"] != Accelerator {
+		t.Errorf("devices = %v, want the input and embedding on cpu, dense on accelerator\n%s", devices, plan)
+	}
+	if plan.AcceleratorBytes != 144 {
+		t.Errorf("AcceleratorBytes = %d, want 144", plan.AcceleratorBytes)
+	}
+	if len(plan.Transfers) != 1 || plan.Nodes[plan.Transfers[0].To].OpType != "Dense" || plan.Transfers[0].Dst != Accelerator {
+		t.Errorf("transfers = %+v, want the embedding output moved to the accelerator", plan.Transfers)
+	}
+	if s := plan.String(); !strings.Contains(s, "does not fit in accelerator memory") {
+		t.Errorf("plan report does not explain the embedding placement:\n%s", s)
+	}
+
+	// The same model on one CPU engine gives the reference output.
+	ids, _ := tensor.New([]int{4}, []float32{3, 999, 0, 42})
+	want, err := buildEmbeddingModel(t, compute.NewCPUEngine[float32](numeric.Float32Ops{})).Forward(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Apply(g, plan); err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.Forward(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Data(), want.Data()) {
+		t.Errorf("output = %v, want %v", got.Data(), want.Data())
+	}
+	if cpu.gathers.Load() != 1 || accel.gathers.Load() != 0 || accel.matmuls.Load() != 1 || cpu.matmuls.Load() != 0 {
+		t.Errorf("cpu ran %d gathers, %d matmuls; accelerator %d, %d; want the gather on cpu and the matmul on the accelerator",
+			cpu.gathers.Load(), cpu.matmuls.Load(), accel.gathers.Load(), accel.matmuls.Load())
+	}
+	if hostOps, _ := e.Stats(); hostOps != 1 {
+		t.Errorf("host ops = %d, want 1", hostOps)
+	}
+}
+
+func TestPlace_Unlimited(t *testing.T) {
+	g := buildEmbeddingModel(t, compute.NewCPUEngine[float32](numeric.Float32Ops{}))
+	plan, err := Place(g, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range plan.Nodes {
+		if n.OpType != "Input" && n.Device != Accelerator {
+			t.Errorf("%s on %s with unlimited accelerator memory", n.Name, n.Device)
+		}
+	}
+	if plan.AcceleratorBytes != 32000+144 {
+		t.Errorf("AcceleratorBytes = %d, want %d", plan.AcceleratorBytes, 32000+144)
+	}
+
+	if _, err := Place(g, Config{AcceleratorMemory: -1}); err == nil {
+		t.Error("expected an error for negative memory")
+	}
+	other := buildEmbeddingModel(t, compute.NewCPUEngine[float32](numeric.Float32Ops{}))
+	e := NewEngine[float32](compute.NewCPUEngine[float32](numeric.Float32Ops{}), compute.NewCPUEngine[float32](numeric.Float32Ops{}))
+	if err := e.Apply(other, &Plan{}); err == nil {
+		t.Error("expected an error for a plan of another graph")
+	}
+}
@@ -0,0 +1,18 @@
+// Package postprocess implements the chain of transforms applied to raw
+// model outputs before they are submitted or served: per-era ranking,
+// gaussianization and clipping.
+//
+// A Chain is stored in a model's metadata under MetadataKey, so every
+// consumer of the model (the predict command, serving) applies the same
+// transforms without being configured separately:
+//
+//	postprocess.SetMetadata(&meta, postprocess.Chain{
+//		{Op: postprocess.OpRank},
+//		{Op: postprocess.OpClip, Min: 0, Max: 1},
+//	})
+//
+//	chain, ok, err := postprocess.FromMetadata(meta)
+//	if ok {
+//		preds, err = chain.Apply(preds, eras)
+//	}
+package postprocess
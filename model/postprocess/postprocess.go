@@ -0,0 +1,150 @@
+package postprocess
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/zerfoo/zerfoo/internal/ranking"
+	"github.com/zerfoo/zerfoo/model"
+)
+
+// MetadataKey is the model.ModelMetadata.Extensions key holding a Chain.
+const MetadataKey = "postprocess"
+
+// Op names a post-processing transform.
+type Op string
+
+const (
+	// OpRank replaces each prediction with its rank within its era,
+	// scaled to (0, 1): the average 1-based rank r of n rows maps to
+	// (r - 0.5) / n, so ties share a value and the era is centered on 0.5.
+	OpRank Op = "rank"
+	// OpGaussianize replaces each prediction with the standard normal
+	// quantile of its scaled rank within its era, Φ⁻¹((r - 0.5) / n).
+	OpGaussianize Op = "gaussianize"
+	// OpClip clamps every prediction to [Min, Max].
+	OpClip Op = "clip"
+)
+
+// Step is one transform in a Chain. Min and Max are only used by OpClip.
+type Step struct {
+	Op  Op      `json:"op"`
+	Min float64 `json:"min,omitempty"`
+	Max float64 `json:"max,omitempty"`
+}
+
+// Chain is an ordered list of transforms applied to raw model outputs.
+type Chain []Step
+
+// Validate reports the first malformed step in the chain.
+func (c Chain) Validate() error {
+	for i, s := range c {
+		switch s.Op {
+		case OpRank, OpGaussianize:
+		case OpClip:
+			if math.IsNaN(s.Min) || math.IsNaN(s.Max) || s.Max < s.Min {
+				return fmt.Errorf("step %d: clip range [%g, %g] is invalid", i, s.Min, s.Max)
+			}
+		default:
+			return fmt.Errorf("step %d: unknown op %q", i, s.Op)
+		}
+	}
+	return nil
+}
+
+// Apply runs the chain over preds and returns the transformed values in a
+// new slice. eras assigns each prediction to a group for the per-era
+// transforms; it must be nil, treating all rows as one era, or have the
+// same length as preds. NaN predictions stay NaN and are excluded from the
+// ranking of their era.
+func (c Chain) Apply(preds []float64, eras []string) ([]float64, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if eras != nil && len(eras) != len(preds) {
+		return nil, fmt.Errorf("got %d eras for %d predictions", len(eras), len(preds))
+	}
+	out := slices.Clone(preds)
+	groups := groupRows(out, eras)
+	for _, s := range c {
+		switch s.Op {
+		case OpRank:
+			for _, rows := range groups {
+				ranking.Transform(out, out, rows, ranking.Scaled)
+			}
+		case OpGaussianize:
+			for _, rows := range groups {
+				ranking.Transform(out, out, rows, ranking.Gaussian)
+			}
+		case OpClip:
+			for i, v := range out {
+				if !math.IsNaN(v) {
+					out[i] = min(max(v, s.Min), s.Max)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// groupRows returns the row indices of each era in first-appearance order.
+func groupRows(preds []float64, eras []string) [][]int {
+	if eras == nil {
+		rows := make([]int, len(preds))
+		for i := range rows {
+			rows[i] = i
+		}
+		return [][]int{rows}
+	}
+	index := make(map[string]int)
+	var groups [][]int
+	for i, e := range eras {
+		g, ok := index[e]
+		if !ok {
+			g = len(groups)
+			index[e] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// SetMetadata stores c in meta under MetadataKey, replacing any previous
+// chain.
+func SetMetadata(meta *model.ModelMetadata, c Chain) {
+	if meta.Extensions == nil {
+		meta.Extensions = make(map[string]interface{})
+	}
+	meta.Extensions[MetadataKey] = c
+}
+
+// FromMetadata returns the chain stored in meta. ok is false when the model
+// carries no chain. The value may be a Chain set by SetMetadata or its
+// generic JSON form after the metadata has been serialized and reloaded.
+func FromMetadata(meta model.ModelMetadata) (c Chain, ok bool, err error) {
+	v, ok := meta.Extensions[MetadataKey]
+	if !ok || v == nil {
+		return nil, false, nil
+	}
+	switch v := v.(type) {
+	case Chain:
+		c = v
+	case []Step:
+		c = v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, false, fmt.Errorf("encode %s metadata: %w", MetadataKey, err)
+		}
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, false, fmt.Errorf("decode %s metadata: %w", MetadataKey, err)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, false, fmt.Errorf("invalid %s metadata: %w", MetadataKey, err)
+	}
+	return c, true, nil
+}
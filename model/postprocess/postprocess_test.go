@@ -0,0 +1,113 @@
+package postprocess
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/model"
+)
+
+func TestChainApply_RankPerEra(t *testing.T) {
+	preds := []float64{3, 1, 2, 10, 10, 5}
+	eras := []string{"a", "a", "a", "b", "b", "b"}
+
+	got, err := Chain{{Op: OpRank}}.Apply(preds, eras)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	// Era a: ranks 3,1,2 of 3. Era b: 10s tie at average rank 2.5, 5 is 1.
+	want := []float64{5.0 / 6, 1.0 / 6, 3.0 / 6, 4.0 / 6, 4.0 / 6, 1.0 / 6}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Errorf("got[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if preds[0] != 3 {
+		t.Error("Apply modified its input")
+	}
+}
+
+func TestChainApply_GaussianizeSymmetric(t *testing.T) {
+	got, err := Chain{{Op: OpGaussianize}}.Apply([]float64{0.1, 0.9, 0.5, 0.3, 0.7}, nil)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got[2] != 0 {
+		t.Errorf("median = %v, want 0", got[2])
+	}
+	if math.Abs(got[0]+got[1]) > 1e-12 || math.Abs(got[3]+got[4]) > 1e-12 {
+		t.Errorf("quantiles not symmetric: %v", got)
+	}
+	if !(got[0] < got[3] && got[3] < got[2] && got[2] < got[4] && got[4] < got[1]) {
+		t.Errorf("order not preserved: %v", got)
+	}
+}
+
+func TestChainApply_ClipAndNaN(t *testing.T) {
+	preds := []float64{-2, math.NaN(), 0.5, 3}
+	got, err := Chain{{Op: OpClip, Min: 0, Max: 1}}.Apply(preds, nil)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got[0] != 0 || !math.IsNaN(got[1]) || got[2] != 0.5 || got[3] != 1 {
+		t.Errorf("got %v", got)
+	}
+
+	got, err = Chain{{Op: OpRank}}.Apply(preds, nil)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !math.IsNaN(got[1]) || got[0] != 1.0/6 || got[3] != 5.0/6 {
+		t.Errorf("NaN should be skipped when ranking: %v", got)
+	}
+}
+
+func TestChainApply_Errors(t *testing.T) {
+	if _, err := (Chain{{Op: "sigmoid"}}).Apply([]float64{1}, nil); err == nil {
+		t.Error("expected error for unknown op")
+	}
+	if _, err := (Chain{{Op: OpClip, Min: 1, Max: 0}}).Apply([]float64{1}, nil); err == nil {
+		t.Error("expected error for inverted clip range")
+	}
+	if _, err := (Chain{{Op: OpRank}}).Apply([]float64{1, 2}, []string{"a"}); err == nil {
+		t.Error("expected error for era length mismatch")
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	chain := Chain{{Op: OpRank}, {Op: OpClip, Min: 0, Max: 1}}
+
+	var meta model.ModelMetadata
+	if _, ok, err := FromMetadata(meta); ok || err != nil {
+		t.Fatalf("empty metadata: ok=%v err=%v", ok, err)
+	}
+	SetMetadata(&meta, chain)
+
+	got, ok, err := FromMetadata(meta)
+	if err != nil || !ok || len(got) != 2 {
+		t.Fatalf("FromMetadata = %v, %v, %v", got, ok, err)
+	}
+
+	// After a JSON round trip the extension is a generic []interface{}.
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reloaded model.ModelMetadata
+	if err := json.Unmarshal(data, &reloaded); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err = FromMetadata(reloaded)
+	if err != nil || !ok {
+		t.Fatalf("FromMetadata after JSON: ok=%v err=%v", ok, err)
+	}
+	if got[0].Op != OpRank || got[1].Op != OpClip || got[1].Max != 1 {
+		t.Errorf("reloaded chain = %+v", got)
+	}
+
+	reloaded.Extensions[MetadataKey] = []interface{}{map[string]interface{}{"op": "bogus"}}
+	if _, _, err := FromMetadata(reloaded); err == nil {
+		t.Error("expected error for invalid stored chain")
+	}
+}
@@ -0,0 +1,412 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultChunkSize is the size of each ranged request made by a resumable
+// download (8 MiB).
+const DefaultChunkSize = 8 << 20
+
+// BlobCache is a content-addressed store of downloaded files shared by every
+// model in a cache directory. Blobs live at <dir>/sha256/<hex digest>;
+// unfinished downloads live under <dir>/partial until their digest is known.
+// Model directories hold hard links (or copies, where links are unsupported)
+// to the blobs, so a file pulled for one model is never downloaded again for
+// another.
+type BlobCache struct {
+	dir string
+}
+
+// NewBlobCache creates a BlobCache rooted at dir.
+func NewBlobCache(dir string) (*BlobCache, error) {
+	for _, sub := range []string{"sha256", "partial", "refs"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, fmt.Errorf("create blob cache: %w", err)
+		}
+	}
+	return &BlobCache{dir: dir}, nil
+}
+
+// Dir returns the root directory of the cache.
+func (c *BlobCache) Dir() string { return c.dir }
+
+// Path returns the location of the blob with the given SHA-256 digest, which
+// may be bare hex or carry an OCI-style "sha256:" prefix. It returns "" for a
+// malformed digest.
+func (c *BlobCache) Path(digest string) string {
+	digest = strings.ToLower(strings.TrimPrefix(digest, "sha256:"))
+	if len(digest) != 64 || !isHex(digest) {
+		return ""
+	}
+	return filepath.Join(c.dir, "sha256", digest)
+}
+
+// Has reports whether the blob with the given digest is cached.
+func (c *BlobCache) Has(digest string) bool {
+	p := c.Path(digest)
+	if p == "" {
+		return false
+	}
+	fi, err := os.Stat(p)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// Link materializes the cached blob at dest, replacing any existing file.
+// It hard-links when possible and copies otherwise.
+func (c *BlobCache) Link(digest, dest string) error {
+	src := c.Path(digest)
+	if src == "" {
+		return fmt.Errorf("invalid digest %q", digest)
+	}
+	tmp := dest + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Link(src, tmp); err != nil {
+		if err := copyFile(src, tmp); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("copy blob: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename blob link: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the digest last downloaded from url, if its blob is still
+// cached.
+func (c *BlobCache) lookup(url string) (string, bool) {
+	data, err := os.ReadFile(c.refPath(url)) //nolint:gosec // path derived from a hash
+	if err != nil {
+		return "", false
+	}
+	digest := strings.TrimSpace(string(data))
+	return digest, c.Has(digest)
+}
+
+// commit moves a verified download into the cache under digest and records
+// that url resolves to it.
+func (c *BlobCache) commit(partial, digest, url string) error {
+	if err := os.Rename(partial, c.Path(digest)); err != nil {
+		return fmt.Errorf("store blob: %w", err)
+	}
+	return os.WriteFile(c.refPath(url), []byte(digest+"\n"), 0o600)
+}
+
+func (c *BlobCache) partialPath(url string) string {
+	return filepath.Join(c.dir, "partial", urlKey(url))
+}
+
+func (c *BlobCache) refPath(url string) string {
+	return filepath.Join(c.dir, "refs", urlKey(url))
+}
+
+func urlKey(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:])
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // cache-internal path
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // caller-validated path
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close() //nolint:errcheck
+		return err
+	}
+	return out.Close()
+}
+
+// downloadState is the sidecar persisted next to a partial download. Chunks
+// holds the SHA-256 of every chunk written so far; on resume each chunk is
+// re-read and checked against it, and the download restarts from the first
+// chunk that no longer matches.
+type downloadState struct {
+	URL       string `json:"url"`
+	ETag      string `json:"etag,omitempty"`
+	ChunkSize int64  `json:"chunk_size"`
+	// Total is the full size reported by the server, or -1 if unknown.
+	Total int64 `json:"total"`
+	// RemoteSHA256 is the digest the server advertised in its headers on
+	// the first request, kept so a resumed download can still verify it.
+	RemoteSHA256 string   `json:"remote_sha256,omitempty"`
+	Chunks       []string `json:"chunks"`
+}
+
+// fetchSpec describes one resumable download.
+type fetchSpec struct {
+	URL        string
+	Client     *http.Client
+	Prepare    func(*http.Request)
+	ChunkSize  int64
+	OnProgress ProgressFunc
+}
+
+// fetchResult is a finished download still at its partial path.
+type fetchResult struct {
+	Size         int64
+	SHA256       string
+	RemoteSHA256 string
+}
+
+// fetchChunked downloads spec.URL to partial in ranged chunks of
+// spec.ChunkSize, persisting progress to partial+".state" after every chunk
+// so an interrupted download resumes where it stopped. Servers that ignore
+// Range are read in one pass. On success the state file is removed and the
+// complete file is left at partial.
+func fetchChunked(ctx context.Context, spec fetchSpec, partial string) (*fetchResult, error) {
+	if spec.ChunkSize <= 0 {
+		spec.ChunkSize = DefaultChunkSize
+	}
+	statePath := partial + ".state"
+	f, st, hasher, err := resumePartial(partial, statePath, spec)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	offset := int64(len(st.Chunks)) * st.ChunkSize
+	restarted := false
+	for st.Total < 0 || offset < st.Total {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, spec.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if spec.Prepare != nil {
+			spec.Prepare(req)
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+st.ChunkSize-1))
+
+		resp, err := spec.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		etag := resp.Header.Get("ETag")
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			total, ok := contentRangeTotal(resp.Header.Get("Content-Range"))
+			if st.ETag != "" && etag != "" && etag != st.ETag {
+				// The remote file changed since the partial was written.
+				resp.Body.Close() //nolint:errcheck
+				if restarted {
+					return nil, errors.New("remote file changed during download")
+				}
+				restarted = true
+				if err := resetPartial(f, st, hasher); err != nil {
+					return nil, err
+				}
+				offset = 0
+				continue
+			}
+			if offset == 0 {
+				st.ETag, st.RemoteSHA256 = etag, extractSHA256(resp)
+			}
+			if ok {
+				st.Total = total
+			} else {
+				st.Total = -1
+			}
+		case http.StatusOK:
+			// Range ignored: the body is the whole file.
+			if offset > 0 {
+				if err := resetPartial(f, st, hasher); err != nil {
+					resp.Body.Close() //nolint:errcheck
+					return nil, err
+				}
+				offset = 0
+			}
+			st.ETag, st.RemoteSHA256, st.Total = etag, extractSHA256(resp), resp.ContentLength
+		case http.StatusRequestedRangeNotSatisfiable:
+			resp.Body.Close() //nolint:errcheck
+			if offset > 0 && st.Total < 0 {
+				// Every byte is already here; the size was just unknown.
+				st.Total = offset
+				continue
+			}
+			return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+		default:
+			resp.Body.Close() //nolint:errcheck
+			return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+		}
+
+		n, err := copyChunks(f, resp.Body, st, hasher, statePath, offset, spec.OnProgress)
+		resp.Body.Close() //nolint:errcheck
+		if err != nil {
+			return nil, err
+		}
+		offset += n
+		switch {
+		case resp.StatusCode == http.StatusOK, st.Total < 0 && n < st.ChunkSize:
+			st.Total = offset
+		case n < st.ChunkSize && offset < st.Total:
+			return nil, fmt.Errorf("short range response at byte %d of %d", offset, st.Total)
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(statePath)
+	return &fetchResult{
+		Size:         offset,
+		SHA256:       hex.EncodeToString(hasher.Sum(nil)),
+		RemoteSHA256: st.RemoteSHA256,
+	}, nil
+}
+
+// resumePartial opens partial for appending and returns the state to resume
+// from, with hasher already fed every verified byte. Chunks that fail their
+// recorded checksum, and everything after them, are discarded.
+func resumePartial(partial, statePath string, spec fetchSpec) (*os.File, *downloadState, hash.Hash, error) {
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_RDWR, 0o600) //nolint:gosec // caller-validated path
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hasher := sha256.New()
+	fresh := &downloadState{URL: spec.URL, ChunkSize: spec.ChunkSize, Total: -1}
+
+	var st downloadState
+	data, err := os.ReadFile(statePath) //nolint:gosec // caller-validated path
+	if err != nil || json.Unmarshal(data, &st) != nil || st.URL != spec.URL || st.ChunkSize != spec.ChunkSize {
+		if err := resetPartial(f, fresh, hasher); err != nil {
+			f.Close() //nolint:errcheck
+			return nil, nil, nil, err
+		}
+		return f, fresh, hasher, nil
+	}
+
+	buf := make([]byte, st.ChunkSize)
+	valid := 0
+	for _, want := range st.Chunks {
+		n, err := io.ReadFull(f, buf)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		sum := sha256.Sum256(buf[:n])
+		if hex.EncodeToString(sum[:]) != want || int64(n) != st.ChunkSize {
+			break
+		}
+		hasher.Write(buf[:n]) //nolint:errcheck // hash.Hash never fails
+		valid++
+	}
+	st.Chunks = st.Chunks[:valid]
+	if valid == 0 {
+		st.ETag, st.RemoteSHA256 = "", ""
+	}
+	offset := int64(valid) * st.ChunkSize
+	if err := f.Truncate(offset); err != nil {
+		f.Close() //nolint:errcheck
+		return nil, nil, nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close() //nolint:errcheck
+		return nil, nil, nil, err
+	}
+	return f, &st, hasher, nil
+}
+
+// resetPartial empties f and st so the download starts from byte zero.
+func resetPartial(f *os.File, st *downloadState, hasher hash.Hash) error {
+	st.Chunks, st.ETag, st.RemoteSHA256, st.Total = nil, "", "", -1
+	hasher.Reset()
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// copyChunks appends body to f one chunk at a time, recording each full
+// chunk's checksum and persisting st after it. A trailing short chunk is
+// written but not recorded, so a resume re-fetches it. It returns the bytes
+// written.
+func copyChunks(f *os.File, body io.Reader, st *downloadState, hasher hash.Hash, statePath string, offset int64, progress ProgressFunc) (int64, error) {
+	buf := make([]byte, st.ChunkSize)
+	var written int64
+	for {
+		n, err := readChunk(body, buf)
+		eof := err == io.EOF //nolint:errorlint // io.EOF is returned unwrapped
+		if err != nil && !eof {
+			return written, err
+		}
+		if n > 0 {
+			if _, werr := f.Write(buf[:n]); werr != nil {
+				return written, werr
+			}
+			hasher.Write(buf[:n]) //nolint:errcheck // hash.Hash never fails
+			written += int64(n)
+			if int64(n) == st.ChunkSize {
+				sum := sha256.Sum256(buf[:n])
+				st.Chunks = append(st.Chunks, hex.EncodeToString(sum[:]))
+				if serr := saveState(statePath, st); serr != nil {
+					return written, serr
+				}
+			}
+			if progress != nil {
+				progress(offset+written, st.Total)
+			}
+		}
+		if eof {
+			return written, nil
+		}
+	}
+}
+
+// readChunk fills buf from r like io.ReadFull, except that a clean io.EOF is
+// returned as is: a body cut off mid-transfer surfaces as
+// io.ErrUnexpectedEOF from the HTTP client and must not pass for the end of
+// the file.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		m, err := r.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func saveState(path string, st *downloadState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// contentRangeTotal parses the complete length from a Content-Range header
+// such as "bytes 0-99/1234".
+func contentRangeTotal(h string) (int64, bool) {
+	_, total, ok := strings.Cut(h, "/")
+	if !ok || total == "*" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	return n, err == nil
+}
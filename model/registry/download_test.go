@@ -0,0 +1,352 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rangeServer serves content as org/model's model.gguf, honoring Range
+// requests, and records the Range header of every download request. Requests
+// for which fail returns true are cut off after a few bytes.
+type rangeServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	ranges []string
+	fail   func(n int) bool
+}
+
+func newRangeServer(t *testing.T, content []byte) *rangeServer {
+	t.Helper()
+	sum := sha256.Sum256(content)
+	rs := &rangeServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/org/model", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(HFModelInfo{ID: "org/model", Siblings: []HFSibling{{Filename: "model.gguf"}}})
+	})
+	mux.HandleFunc("/org/model/resolve/main/model.gguf", func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		rs.ranges = append(rs.ranges, r.Header.Get("Range"))
+		n := len(rs.ranges)
+		rs.mu.Unlock()
+		if rs.fail != nil && rs.fail(n) {
+			w.Header().Set("Content-Length", "1000")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("xx"))
+			w.(http.Flusher).Flush()
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Header().Set("ETag", fmt.Sprintf("%q", hex.EncodeToString(sum[:])))
+		http.ServeContent(w, r, "model.gguf", time.Time{}, bytes.NewReader(content))
+	})
+	rs.Server = httptest.NewServer(mux)
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func (rs *rangeServer) pullFunc(cache *BlobCache) PullFunc {
+	return NewHFPullFunc(HFPullOptions{
+		APIURL:    rs.URL + "/api/models",
+		CDNURL:    rs.URL,
+		Client:    rs.Client(),
+		Quant:     "",
+		Cache:     cache,
+		ChunkSize: 16,
+	})
+}
+
+func (rs *rangeServer) requests() []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]string(nil), rs.ranges...)
+}
+
+func testContent(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + i%26)
+	}
+	return b
+}
+
+func TestDownloadFile_Chunked(t *testing.T) {
+	content := testContent(100)
+	rs := newRangeServer(t, content)
+	dir := t.TempDir()
+
+	var last [2]int64
+	pull := NewHFPullFunc(HFPullOptions{
+		APIURL:     rs.URL + "/api/models",
+		CDNURL:     rs.URL,
+		Client:     rs.Client(),
+		ChunkSize:  16,
+		OnProgress: func(d, total int64) { last = [2]int64{d, total} },
+	})
+	info, err := pull(context.Background(), "org/model", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 100 {
+		t.Errorf("Size = %d, want 100", info.Size)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "model.gguf"))
+	if !bytes.Equal(got, content) {
+		t.Error("downloaded content differs")
+	}
+	if n := len(rs.requests()); n != 7 {
+		t.Errorf("made %d requests, want 7 chunks of 16 bytes", n)
+	}
+	if last != [2]int64{100, 100} {
+		t.Errorf("last progress = %v, want [100 100]", last)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "model.gguf.partial.state")); !os.IsNotExist(err) {
+		t.Error("state file should be removed after success")
+	}
+}
+
+func TestDownloadFile_ResumesAfterInterruption(t *testing.T) {
+	content := testContent(100)
+	rs := newRangeServer(t, content)
+	rs.fail = func(n int) bool { return n == 4 }
+	dir := t.TempDir()
+	pull := rs.pullFunc(nil)
+
+	if _, err := pull(context.Background(), "org/model", dir); err == nil {
+		t.Fatal("first pull should fail on the interrupted chunk")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "model.gguf")); !os.IsNotExist(err) {
+		t.Error("final file should not exist after an interrupted pull")
+	}
+
+	if _, err := pull(context.Background(), "org/model", dir); err != nil {
+		t.Fatalf("resumed pull: %v", err)
+	}
+	reqs := rs.requests()
+	if resumed := reqs[4]; resumed != "bytes=48-63" {
+		t.Errorf("resumed at %q, want bytes=48-63", resumed)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "model.gguf"))
+	if !bytes.Equal(got, content) {
+		t.Error("resumed content differs")
+	}
+}
+
+func TestDownloadFile_ResumeDiscardsCorruptChunk(t *testing.T) {
+	content := testContent(100)
+	rs := newRangeServer(t, content)
+	rs.fail = func(n int) bool { return n == 4 }
+	dir := t.TempDir()
+	pull := rs.pullFunc(nil)
+
+	if _, err := pull(context.Background(), "org/model", dir); err == nil {
+		t.Fatal("first pull should fail")
+	}
+	// Corrupt the second verified chunk on disk.
+	partial := filepath.Join(dir, "model.gguf.partial")
+	f, err := os.OpenFile(partial, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt([]byte("ZZ"), 20)
+	f.Close()
+
+	if _, err := pull(context.Background(), "org/model", dir); err != nil {
+		t.Fatalf("resumed pull: %v", err)
+	}
+	if resumed := rs.requests()[4]; resumed != "bytes=16-31" {
+		t.Errorf("resumed at %q, want the corrupt chunk bytes=16-31", resumed)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "model.gguf"))
+	if !bytes.Equal(got, content) {
+		t.Error("content differs after discarding the corrupt chunk")
+	}
+}
+
+func TestDownloadFile_RangeIgnored(t *testing.T) {
+	content := testContent(50)
+	sum := sha256.Sum256(content)
+	var calls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/org/model", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(HFModelInfo{ID: "org/model", Siblings: []HFSibling{{Filename: "model.gguf"}}})
+	})
+	mux.HandleFunc("/org/model/resolve/main/model.gguf", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("ETag", hex.EncodeToString(sum[:]))
+		w.Write(content)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	pull := NewHFPullFunc(HFPullOptions{APIURL: server.URL + "/api/models", CDNURL: server.URL, Client: server.Client(), ChunkSize: 16})
+	if _, err := pull(context.Background(), "org/model", dir); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d requests, want 1", calls)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "model.gguf"))
+	if !bytes.Equal(got, content) {
+		t.Error("content differs")
+	}
+}
+
+func TestDownloadFile_SharedBlobCache(t *testing.T) {
+	content := testContent(40)
+	rs := newRangeServer(t, content)
+	root := t.TempDir()
+	cache, err := NewBlobCache(filepath.Join(root, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull := rs.pullFunc(cache)
+
+	dirA := filepath.Join(root, "a")
+	dirB := filepath.Join(root, "b")
+	for _, d := range []string{dirA, dirB} {
+		if err := os.MkdirAll(d, 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pull(context.Background(), "org/model", d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Three chunks, then one HEAD revalidating the cached digest.
+	if n := len(rs.requests()); n != 4 {
+		t.Errorf("made %d requests, want 4 (second pull served from cache)", n)
+	}
+
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	if !cache.Has("sha256:" + digest) {
+		t.Error("blob missing from cache")
+	}
+	for _, d := range []string{dirA, dirB} {
+		got, err := os.ReadFile(filepath.Join(d, "model.gguf"))
+		if err != nil || !bytes.Equal(got, content) {
+			t.Errorf("%s: content differs (err %v)", d, err)
+		}
+	}
+	partials, _ := os.ReadDir(filepath.Join(root, "blobs", "partial"))
+	if len(partials) != 0 {
+		t.Errorf("partial dir not empty: %d entries", len(partials))
+	}
+}
+
+func TestDownloadFile_UnpinnedCacheRefetchesUpdatedFile(t *testing.T) {
+	var mu sync.Mutex
+	content := testContent(40)
+	var gets int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/org/model", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(HFModelInfo{ID: "org/model", Siblings: []HFSibling{{Filename: "model.gguf"}}})
+	})
+	mux.HandleFunc("/org/model/resolve/main/model.gguf", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := content
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		mu.Unlock()
+		sum := sha256.Sum256(body)
+		w.Header().Set("X-Linked-Etag", fmt.Sprintf("%q", hex.EncodeToString(sum[:])))
+		http.ServeContent(w, r, "model.gguf", time.Time{}, bytes.NewReader(body))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cache, err := NewBlobCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull := NewHFPullFunc(HFPullOptions{
+		APIURL: srv.URL + "/api/models",
+		CDNURL: srv.URL,
+		Client: srv.Client(),
+		Cache:  cache,
+	})
+	pullInto := func() []byte {
+		t.Helper()
+		dir := t.TempDir()
+		if _, err := pull(context.Background(), "org/model", dir); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dir, "model.gguf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	pullInto()
+	if got := pullInto(); !bytes.Equal(got, content) || gets != 1 {
+		t.Fatalf("unchanged file: %d GETs, content equal %v; want 1 GET from cache", gets, bytes.Equal(got, content))
+	}
+
+	updated := bytes.ToUpper(content)
+	mu.Lock()
+	content = updated
+	mu.Unlock()
+	if got := pullInto(); !bytes.Equal(got, updated) {
+		t.Errorf("pull after upstream update returned the stale cached file (%d GETs)", gets)
+	}
+}
+
+func TestDownloadFile_PinnedCacheHitSkipsNetwork(t *testing.T) {
+	content := testContent(20)
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	cache, err := NewBlobCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cache.Path(digest), content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	opts := HFPullOptions{
+		CDNURL:         "http://unreachable.invalid",
+		Client:         &http.Client{Transport: failingTransport{}},
+		Cache:          cache,
+		ExpectedHashes: map[string]string{"model.gguf": strings.ToUpper(digest)},
+	}
+	n, err := downloadFile(context.Background(), opts, "org/model", "model.gguf", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 20 {
+		t.Errorf("size = %d, want 20", n)
+	}
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("unexpected network access")
+}
+
+func TestContentRangeTotal(t *testing.T) {
+	if n, ok := contentRangeTotal("bytes 0-15/100"); !ok || n != 100 {
+		t.Errorf("got %d, %v", n, ok)
+	}
+	if _, ok := contentRangeTotal("bytes 0-15/*"); ok {
+		t.Error("unknown total should not parse")
+	}
+}
@@ -2,11 +2,8 @@ package registry
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -34,8 +31,15 @@ type HFPullOptions struct {
 	// When set, only the matching GGUF file is downloaded instead of all model files.
 	// Default: "Q4_K_M".
 	Quant string
-	// OnProgress is called during file downloads.
+	// OnProgress is called during file downloads. downloaded includes bytes
+	// recovered from an earlier, interrupted attempt.
 	OnProgress ProgressFunc
+	// Cache, when set, stores downloaded files in a content-addressed blob
+	// cache shared across models and links them into the model directory.
+	// LocalRegistry.Blobs returns the cache for a registry's directory.
+	Cache *BlobCache
+	// ChunkSize is the size of each ranged request. Default: DefaultChunkSize.
+	ChunkSize int64
 	// Client overrides the HTTP client used for downloads.
 	Client *http.Client
 	// ExpectedHashes optionally pins the expected SHA-256 checksum (lowercase
@@ -186,25 +190,14 @@ func listModelFiles(ctx context.Context, opts HFPullOptions, modelID string) ([]
 	return info.Siblings, nil
 }
 
-// downloadFile downloads a single file from HuggingFace CDN using atomic
-// writes and optional SHA-256 checksum verification.
+// downloadFile downloads a single file from HuggingFace CDN in resumable,
+// checksummed chunks and verifies its SHA-256 before it becomes visible at
+// its final path. With opts.Cache set the file is stored once in the shared
+// blob cache and linked into targetDir. A cached file is linked without
+// being downloaded again when its digest is pinned, or when a HEAD request
+// shows the server still advertises the digest it was last downloaded with.
 func downloadFile(ctx context.Context, opts HFPullOptions, modelID, filename, targetDir string) (int64, error) {
 	url := fmt.Sprintf("%s/%s/resolve/main/%s", opts.CDNURL, modelID, filename)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return 0, err
-	}
-	addAuthHeader(req, opts.Token)
-
-	resp, err := opts.Client.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("download returned status %d", resp.StatusCode)
-	}
 
 	// Validate filename to prevent path traversal from server-controlled values.
 	if strings.Contains(filename, "..") {
@@ -222,79 +215,106 @@ func downloadFile(ctx context.Context, opts HFPullOptions, modelID, filename, ta
 		return 0, err
 	}
 
-	// Determine the expected SHA-256. An out-of-band pin (opts.ExpectedHashes)
-	// takes precedence over anything derived from the response headers, since
-	// the headers originate from the same server the content was just
-	// fetched from and offer no protection against a compromised or MITM'd
-	// origin (HF-1). Absent a pin, fall back to the prior ETag-derived trust
-	// behavior for backward compatibility.
-	var expectedHash string
-	pinned := false
+	// An out-of-band pin (opts.ExpectedHashes) takes precedence over anything
+	// derived from the response headers, since the headers originate from the
+	// same server the content was just fetched from and offer no protection
+	// against a compromised or MITM'd origin (HF-1). Absent a pin, fall back
+	// to the prior ETag-derived trust behavior for backward compatibility.
+	var pinnedHash string
 	if h, ok := opts.ExpectedHashes[filename]; ok && h != "" {
-		expectedHash = strings.ToLower(h)
-		pinned = true
-	} else {
-		expectedHash = extractSHA256(resp)
-	}
-
-	// Atomic write: download to a temp file, then rename on success.
-	tmpPath := cleaned + ".tmp"
-	f, err := os.Create(tmpPath) //nolint:gosec // path validated above
-	if err != nil {
-		return 0, err
-	}
-
-	// Ensure temp file is cleaned up on any error path.
-	success := false
-	defer func() {
-		f.Close() //nolint:errcheck
-		if !success {
-			os.Remove(tmpPath) //nolint:errcheck
+		pinnedHash = strings.ToLower(h)
+	}
+
+	partial := cleaned + ".partial"
+	if opts.Cache != nil {
+		digest, ok := pinnedHash, opts.Cache.Has(pinnedHash)
+		if pinnedHash == "" {
+			// Without a pin, reuse the blob the URL last resolved to only
+			// while the server still advertises its digest, so an upstream
+			// update is downloaded instead of masked by the cache.
+			digest, ok = opts.Cache.lookup(url)
+			ok = ok && remoteSHA256(ctx, opts, url) == digest
 		}
-	}()
-
-	// Set up the reader pipeline: body -> progress -> tee(hash).
-	var reader io.Reader = resp.Body
-	if opts.OnProgress != nil {
-		reader = &progressReader{
-			reader:   resp.Body,
-			total:    resp.ContentLength,
-			callback: opts.OnProgress,
+		if ok {
+			return linkCached(opts.Cache, digest, cleaned)
 		}
-	}
-
-	// Compute SHA-256 while writing via TeeReader.
-	hasher := sha256.New()
-	reader = io.TeeReader(reader, hasher)
-
-	n, err := io.Copy(f, reader)
+		partial = opts.Cache.partialPath(url)
+	}
+
+	// The partial file and its chunk checksums survive a failed transfer so
+	// the next pull resumes from the last verified chunk.
+	res, err := fetchChunked(ctx, fetchSpec{
+		URL:        url,
+		Client:     opts.Client,
+		Prepare:    func(req *http.Request) { addAuthHeader(req, opts.Token) },
+		ChunkSize:  opts.ChunkSize,
+		OnProgress: opts.OnProgress,
+	}, partial)
 	if err != nil {
 		return 0, err
 	}
 
 	// Verify checksum. A pinned hash is always enforced. Otherwise, verify
-	// against the ETag-derived hash if the server provided one.
-	gotHash := hex.EncodeToString(hasher.Sum(nil))
+	// against the ETag-derived hash if the server provided one. A complete
+	// file with the wrong digest cannot be resumed, so it is discarded.
 	switch {
-	case pinned:
-		if gotHash != expectedHash {
-			return 0, fmt.Errorf("checksum mismatch for %s: expected %s (pinned), got %s", filename, expectedHash, gotHash)
+	case pinnedHash != "":
+		if res.SHA256 != pinnedHash {
+			os.Remove(partial) //nolint:errcheck
+			return 0, fmt.Errorf("checksum mismatch for %s: expected %s (pinned), got %s", filename, pinnedHash, res.SHA256)
 		}
-	case expectedHash != "":
-		if gotHash != expectedHash {
-			return 0, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filename, expectedHash, gotHash)
+	case res.RemoteSHA256 != "":
+		if res.SHA256 != res.RemoteSHA256 {
+			os.Remove(partial) //nolint:errcheck
+			return 0, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filename, res.RemoteSHA256, res.SHA256)
 		}
 	default:
 		slog.Warn("no SHA-256 checksum available from server, skipping verification", "file", filename)
 	}
 
-	// Atomic rename: temp file -> final path.
-	if err := os.Rename(tmpPath, cleaned); err != nil {
-		return 0, fmt.Errorf("rename temp file: %w", err)
+	if opts.Cache != nil {
+		if err := opts.Cache.commit(partial, res.SHA256, url); err != nil {
+			return 0, err
+		}
+		return linkCached(opts.Cache, res.SHA256, cleaned)
+	}
+
+	// Atomic rename: partial file -> final path.
+	if err := os.Rename(partial, cleaned); err != nil {
+		return 0, fmt.Errorf("rename partial file: %w", err)
+	}
+	return res.Size, nil
+}
+
+// remoteSHA256 returns the SHA-256 the server currently advertises for url
+// in response to a HEAD request, or "" when it advertises none.
+func remoteSHA256(ctx context.Context, opts HFPullOptions, url string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return ""
+	}
+	addAuthHeader(req, opts.Token)
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return ""
 	}
-	success = true
+	return extractSHA256(resp)
+}
 
-	return n, nil
+// linkCached links the cached blob digest to dest and returns its size.
+func linkCached(cache *BlobCache, digest, dest string) (int64, error) {
+	if err := cache.Link(digest, dest); err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(dest)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
 }
 
 // extractSHA256 extracts a SHA-256 hash from the HTTP response headers.
@@ -345,23 +365,6 @@ func shouldDownload(filename string) bool {
 	}
 }
 
-// progressReader wraps an io.Reader to report download progress.
-type progressReader struct {
-	reader     io.Reader
-	total      int64
-	downloaded int64
-	callback   ProgressFunc
-}
-
-func (pr *progressReader) Read(p []byte) (int, error) {
-	n, err := pr.reader.Read(p)
-	pr.downloaded += int64(n)
-	if pr.callback != nil {
-		pr.callback(pr.downloaded, pr.total)
-	}
-	return n, err
-}
-
 func addAuthHeader(req *http.Request, token string) {
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -74,6 +74,12 @@ func (r *LocalRegistry) CacheDir() string {
 	return r.cacheDir
 }
 
+// Blobs returns the content-addressed blob cache under <cacheDir>/blobs,
+// shared by every model pulled into this registry.
+func (r *LocalRegistry) Blobs() (*BlobCache, error) {
+	return NewBlobCache(filepath.Join(r.cacheDir, "blobs"))
+}
+
 // Pull downloads a model and caches it locally.
 func (r *LocalRegistry) Pull(ctx context.Context, modelID string) (*ModelInfo, error) {
 	r.mu.Lock()
@@ -135,6 +141,9 @@ func (r *LocalRegistry) List() []ModelInfo {
 		if err != nil {
 			return nil // Skip errors.
 		}
+		if info.IsDir() && path == filepath.Join(r.cacheDir, "blobs") {
+			return filepath.SkipDir
+		}
 		if info.Name() == "config.json" && !info.IsDir() {
 			modelInfo, readErr := r.readModelInfo(filepath.Dir(path))
 			if readErr == nil {
@@ -0,0 +1,16 @@
+// Package vocab analyzes how a corpus uses a model's vocabulary and prunes
+// tokens the corpus does not need, producing a smaller GGUF model for
+// domain-specific deployments.
+//
+// CountCorpus tokenizes a corpus and records per-token frequencies. Plan
+// chooses the tokens to keep: the frequent ones, everything needed to keep
+// the tokenizer total (special, byte, and single-character tokens), and the
+// merge parts of every kept BPE token. Dropped tokens are effectively merged
+// back into their parts, since text that produced them now encodes as the
+// pieces they were merged from. Prune rewrites a GGUF file with the kept
+// vocabulary, remapping token IDs in the tokenizer metadata and the rows of
+// every vocabulary-indexed tensor (token embeddings and an untied output
+// head).
+//
+// Stability: alpha
+package vocab
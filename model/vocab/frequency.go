@@ -0,0 +1,112 @@
+package vocab
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	tokenizer "github.com/zerfoo/ztoken"
+)
+
+// maxLineBytes bounds a corpus line, so one document per line can be long.
+const maxLineBytes = 64 << 20
+
+// Frequency counts token occurrences over a corpus.
+type Frequency struct {
+	// Counts holds the occurrences of each token ID.
+	Counts []int64
+	// Tokens is the total number of tokens counted.
+	Tokens int64
+	// Documents is the number of documents counted.
+	Documents int
+}
+
+// NewFrequency returns an empty Frequency for a vocabulary of size tokens.
+func NewFrequency(size int) *Frequency {
+	return &Frequency{Counts: make([]int64, size)}
+}
+
+// Add counts one document's token IDs. IDs outside the vocabulary are
+// ignored.
+func (f *Frequency) Add(ids []int) {
+	for _, id := range ids {
+		if id >= 0 && id < len(f.Counts) {
+			f.Counts[id]++
+			f.Tokens++
+		}
+	}
+	f.Documents++
+}
+
+// CountCorpus encodes r with tok, one document per line, and adds the
+// tokens to f. Empty lines are skipped.
+func CountCorpus(tok tokenizer.Tokenizer, r io.Reader, f *Frequency) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		ids, err := tok.Encode(line)
+		if err != nil {
+			return fmt.Errorf("vocab: encode document %d: %w", f.Documents+1, err)
+		}
+		f.Add(ids)
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("vocab: read corpus: %w", err)
+	}
+	return nil
+}
+
+// Used returns the number of tokens that occur at least once.
+func (f *Frequency) Used() int {
+	n := 0
+	for _, c := range f.Counts {
+		if c > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// TokenCount is a token ID and its occurrences.
+type TokenCount struct {
+	ID    int   `json:"id"`
+	Count int64 `json:"count"`
+}
+
+// Ranked returns every token that occurs, most frequent first, ties broken
+// by ID.
+func (f *Frequency) Ranked() []TokenCount {
+	ranked := make([]TokenCount, 0, len(f.Counts))
+	for id, c := range f.Counts {
+		if c > 0 {
+			ranked = append(ranked, TokenCount{ID: id, Count: c})
+		}
+	}
+	slices.SortFunc(ranked, func(a, b TokenCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return ranked
+}
+
+// CoverageSize returns the smallest number of distinct tokens that account
+// for at least frac (0 to 1) of all occurrences.
+func (f *Frequency) CoverageSize(frac float64) int {
+	target := frac * float64(f.Tokens)
+	var cum int64
+	for i, tc := range f.Ranked() {
+		cum += tc.Count
+		if float64(cum) >= target {
+			return i + 1
+		}
+	}
+	return f.Used()
+}
@@ -0,0 +1,399 @@
+package vocab
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+)
+
+// GGUF tokenizer metadata keys.
+const (
+	keyTokens = "tokenizer.ggml.tokens"
+	keyTypes  = "tokenizer.ggml.token_type"
+	keyMerges = "tokenizer.ggml.merges"
+	// keyHFJSON embeds the original tokenizer.json, which describes the
+	// unpruned vocabulary and is dropped.
+	keyHFJSON = "tokenizer.huggingface.json"
+	// keySourceIDs records the original ID of each kept token.
+	keySourceIDs = "zerfoo.vocab.source_ids"
+)
+
+// Token types kept regardless of frequency (tokenizer.ggml.token_type):
+// unknown, control, user-defined, and byte-fallback tokens.
+var requiredTypes = map[int64]bool{2: true, 3: true, 4: true, 6: true}
+
+// Options configures Plan.
+type Options struct {
+	// MinCount drops tokens occurring fewer times in the corpus. Values
+	// below 1 are treated as 1, so every token the corpus uses is kept.
+	MinCount int64
+	// MaxVocab caps the pruned vocabulary size, keeping the most frequent
+	// tokens that fit; 0 means no cap.
+	MaxVocab int
+}
+
+// Plan is the set of tokens a prune keeps.
+type Plan struct {
+	// Keep lists the original IDs of the kept tokens; a token's position
+	// is its new ID. Kept tokens stay in their original order.
+	Keep []int
+	// OldToNew maps an original ID to its new ID, or -1 if dropped.
+	OldToNew []int
+	// Required is the number of tokens kept regardless of frequency.
+	Required int
+}
+
+// vocabulary is the tokenizer metadata of a GGUF file.
+type vocabulary struct {
+	tokens  []string
+	ids     map[string]int
+	parts   map[int][2]int // merge result ID -> left and right IDs
+	types   []int64
+	special []int
+}
+
+func readVocabulary(f *gguf.File) (*vocabulary, error) {
+	raw, ok := f.Metadata[keyTokens].([]any)
+	if !ok {
+		return nil, fmt.Errorf("vocab: missing %s metadata", keyTokens)
+	}
+	v := &vocabulary{
+		tokens: make([]string, len(raw)),
+		ids:    make(map[string]int, len(raw)),
+		parts:  make(map[int][2]int),
+	}
+	for i, t := range raw {
+		s, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("vocab: %s[%d] is %T, want string", keyTokens, i, t)
+		}
+		v.tokens[i] = s
+		if _, dup := v.ids[s]; !dup {
+			v.ids[s] = i
+		}
+	}
+
+	if merges, ok := f.Metadata[keyMerges].([]any); ok {
+		for i, m := range merges {
+			s, _ := m.(string)
+			left, right, found := strings.Cut(s, " ")
+			if !found {
+				return nil, fmt.Errorf("vocab: %s[%d]: invalid merge %q", keyMerges, i, s)
+			}
+			l, lok := v.ids[left]
+			r, rok := v.ids[right]
+			res, resok := v.ids[left+right]
+			if !lok || !rok || !resok {
+				continue
+			}
+			if _, seen := v.parts[res]; !seen {
+				v.parts[res] = [2]int{l, r}
+			}
+		}
+	}
+
+	if types, ok := f.Metadata[keyTypes].([]any); ok && len(types) == len(raw) {
+		v.types = make([]int64, len(types))
+		for i, t := range types {
+			v.types[i], _ = toInt64(t)
+		}
+	}
+
+	for key, val := range f.Metadata {
+		if !isSpecialIDKey(key) {
+			continue
+		}
+		if id, ok := toInt64(val); ok && id >= 0 && int(id) < len(raw) {
+			v.special = append(v.special, int(id))
+		}
+	}
+	return v, nil
+}
+
+// isSpecialIDKey reports whether key holds a special token ID, such as
+// tokenizer.ggml.bos_token_id.
+func isSpecialIDKey(key string) bool {
+	return strings.HasPrefix(key, "tokenizer.ggml.") && strings.HasSuffix(key, "_token_id")
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int8:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// required reports whether token id must be kept for the tokenizer to
+// stay total and its special tokens to keep working.
+func (v *vocabulary) required(id int) bool {
+	if v.types != nil && requiredTypes[v.types[id]] {
+		return true
+	}
+	return utf8.RuneCountInString(v.tokens[id]) == 1
+}
+
+// closure returns id and, recursively, the merge parts of every token
+// needed to produce it, leaving out tokens already kept.
+func (v *vocabulary) closure(id int, kept []bool) []int {
+	var out []int
+	seen := map[int]bool{}
+	stack := []int{id}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if kept[n] || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+		if p, ok := v.parts[n]; ok {
+			stack = append(stack, p[0], p[1])
+		}
+	}
+	return out
+}
+
+// NewPlan chooses the tokens of f's vocabulary to keep given their corpus
+// frequencies.
+func NewPlan(f *gguf.File, freq *Frequency, opts Options) (*Plan, error) {
+	v, err := readVocabulary(f)
+	if err != nil {
+		return nil, err
+	}
+	n := len(v.tokens)
+	if len(freq.Counts) != n {
+		return nil, fmt.Errorf("vocab: frequencies cover %d tokens, vocabulary has %d", len(freq.Counts), n)
+	}
+	minCount := max(opts.MinCount, 1)
+
+	kept := make([]bool, n)
+	size := 0
+	keep := func(ids []int) {
+		for _, id := range ids {
+			kept[id] = true
+		}
+		size += len(ids)
+	}
+	for id := range n {
+		if v.required(id) {
+			keep(v.closure(id, kept))
+		}
+	}
+	for _, id := range v.special {
+		keep(v.closure(id, kept))
+	}
+	required := size
+	if opts.MaxVocab > 0 && required > opts.MaxVocab {
+		return nil, fmt.Errorf("vocab: %d tokens are required, more than the maximum of %d", required, opts.MaxVocab)
+	}
+
+	for _, tc := range freq.Ranked() {
+		if tc.Count < minCount {
+			break
+		}
+		ids := v.closure(tc.ID, kept)
+		if opts.MaxVocab > 0 && size+len(ids) > opts.MaxVocab {
+			continue
+		}
+		keep(ids)
+	}
+
+	p := &Plan{OldToNew: make([]int, n), Required: required}
+	for id := range n {
+		p.OldToNew[id] = -1
+		if kept[id] {
+			p.OldToNew[id] = len(p.Keep)
+			p.Keep = append(p.Keep, id)
+		}
+	}
+	return p, nil
+}
+
+// Result describes a pruned model.
+type Result struct {
+	// OldSize and NewSize are the vocabulary sizes before and after.
+	OldSize, NewSize int
+	// Tensors names the tensors whose rows were remapped.
+	Tensors []string
+}
+
+// Prune writes to dst a copy of the GGUF model f, read from src, that
+// keeps only the tokens in plan. Tokenizer arrays indexed by token ID are
+// filtered, merges involving dropped tokens are removed, special token IDs
+// are remapped, and every tensor whose outermost dimension is the
+// vocabulary size keeps only the rows of kept tokens. The original ID of
+// each kept token is stored under zerfoo.vocab.source_ids. Tensor data is
+// streamed, so the model is never fully in memory.
+func Prune(src io.ReadSeeker, dst io.Writer, f *gguf.File, plan *Plan) (*Result, error) {
+	v, err := readVocabulary(f)
+	if err != nil {
+		return nil, err
+	}
+	n := len(v.tokens)
+	if len(plan.OldToNew) != n {
+		return nil, fmt.Errorf("vocab: plan covers %d tokens, vocabulary has %d", len(plan.OldToNew), n)
+	}
+	if len(plan.Keep) == 0 {
+		return nil, errors.New("vocab: plan keeps no tokens")
+	}
+
+	metadata := pruneMetadata(f, v, plan)
+
+	res := &Result{OldSize: n, NewSize: len(plan.Keep)}
+	tensors := make([]gguf.WriterTensor, len(f.Tensors))
+	for i, ti := range f.Tensors {
+		t, remapped, err := pruneTensor(src, f, ti, plan)
+		if err != nil {
+			return nil, err
+		}
+		if remapped {
+			res.Tensors = append(res.Tensors, ti.Name)
+		}
+		tensors[i] = t
+	}
+	if err := gguf.Write(dst, metadata, tensors); err != nil {
+		return nil, fmt.Errorf("vocab: %w", err)
+	}
+	return res, nil
+}
+
+func pruneMetadata(f *gguf.File, v *vocabulary, plan *Plan) map[string]any {
+	n := len(v.tokens)
+	arch, _ := f.GetString("general.architecture")
+	metadata := make(map[string]any, len(f.Metadata)+1)
+	for key, val := range f.Metadata {
+		switch {
+		case key == keyHFJSON:
+			continue
+		case key == keyMerges:
+			merges, _ := val.([]any)
+			kept := make([]any, 0, len(merges))
+			for _, m := range merges {
+				s, _ := m.(string)
+				left, right, _ := strings.Cut(s, " ")
+				if keptToken(v, plan, left) && keptToken(v, plan, right) && keptToken(v, plan, left+right) {
+					kept = append(kept, m)
+				}
+			}
+			val = kept
+		case isSpecialIDKey(key):
+			id, ok := toInt64(val)
+			if !ok || id < 0 || int(id) >= n {
+				break
+			}
+			val = withInt(val, int64(plan.OldToNew[id]))
+		case arch != "" && key == arch+".vocab_size":
+			val = withInt(val, int64(len(plan.Keep)))
+		case strings.HasPrefix(key, "tokenizer.ggml."):
+			if arr, ok := val.([]any); ok && len(arr) == n {
+				kept := make([]any, len(plan.Keep))
+				for i, id := range plan.Keep {
+					kept[i] = arr[id]
+				}
+				val = kept
+			}
+		}
+		metadata[key] = val
+	}
+	ids := make([]any, len(plan.Keep))
+	for i, id := range plan.Keep {
+		ids[i] = uint32(id)
+	}
+	metadata[keySourceIDs] = ids
+	return metadata
+}
+
+func keptToken(v *vocabulary, plan *Plan, token string) bool {
+	id, ok := v.ids[token]
+	return ok && plan.OldToNew[id] >= 0
+}
+
+// withInt returns n with the integer type of like.
+func withInt(like any, n int64) any {
+	switch like.(type) {
+	case int32:
+		return int32(n)
+	case uint64:
+		return uint64(n)
+	case int64:
+		return n
+	default:
+		return uint32(n)
+	}
+}
+
+// pruneTensor returns the tensor ti as written to the pruned model and
+// whether its rows were remapped.
+func pruneTensor(src io.ReadSeeker, f *gguf.File, ti gguf.TensorInfo, plan *Plan) (gguf.WriterTensor, bool, error) {
+	dims := ti.Dimensions
+	var elems int64 = 1
+	for _, d := range dims {
+		elems *= int64(d)
+	}
+	size, err := gguf.TensorByteSize(ti.Type, int(elems))
+	if err != nil {
+		return gguf.WriterTensor{}, false, fmt.Errorf("vocab: tensor %q: %w", ti.Name, err)
+	}
+	base := f.DataOffset + int64(ti.Offset)
+
+	rows := len(plan.OldToNew)
+	if len(dims) < 2 || dims[len(dims)-1] != uint64(rows) {
+		return gguf.WriterTensor{
+			Name:       ti.Name,
+			Dimensions: dims,
+			Type:       ti.Type,
+			Size:       int64(size),
+			WriteData:  func(w io.Writer) error { return copyAt(w, src, base, int64(size)) },
+		}, false, nil
+	}
+
+	rowBytes, err := gguf.TensorByteSize(ti.Type, int(elems)/rows)
+	if err != nil || rowBytes*rows != size {
+		return gguf.WriterTensor{}, false, fmt.Errorf("vocab: tensor %q: %d bytes do not split into %d rows", ti.Name, size, rows)
+	}
+	newDims := append([]uint64(nil), dims...)
+	newDims[len(newDims)-1] = uint64(len(plan.Keep))
+	return gguf.WriterTensor{
+		Name:       ti.Name,
+		Dimensions: newDims,
+		Type:       ti.Type,
+		Size:       int64(rowBytes * len(plan.Keep)),
+		WriteData: func(w io.Writer) error {
+			for _, id := range plan.Keep {
+				if err := copyAt(w, src, base+int64(id*rowBytes), int64(rowBytes)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, true, nil
+}
+
+// copyAt copies n bytes at offset off of src to w.
+func copyAt(w io.Writer, src io.ReadSeeker, off, n int64) error {
+	if _, err := src.Seek(off, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w, src, n)
+	return err
+}
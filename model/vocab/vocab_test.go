@@ -0,0 +1,211 @@
+package vocab_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+	"github.com/zerfoo/zerfoo/model/vocab"
+)
+
+// testTokens is a small BPE vocabulary: a control token, four characters,
+// and merged tokens ab = a+b, abc = ab+c, cd = c+d, and dd = d+d.
+var testTokens = []string{"<s>", "a", "b", "c", "d", "ab", "abc", "cd", "dd"}
+
+func f32Bytes(vals ...float32) []byte {
+	b := make([]byte, 4*len(vals))
+	for i, v := range vals {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+	}
+	return b
+}
+
+func f32Tensor(name string, dims []uint64, data []byte) gguf.WriterTensor {
+	return gguf.WriterTensor{
+		Name:       name,
+		Dimensions: dims,
+		Type:       gguf.GGMLTypeF32,
+		Size:       int64(len(data)),
+		WriteData: func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		},
+	}
+}
+
+// writeTestModel returns a GGUF model over testTokens whose embedding row
+// i is [i, -i].
+func writeTestModel(t *testing.T) []byte {
+	t.Helper()
+	tokens := make([]any, len(testTokens))
+	types := make([]any, len(testTokens))
+	var embd []float32
+	for i, tok := range testTokens {
+		tokens[i] = tok
+		types[i] = int32(1)
+		embd = append(embd, float32(i), -float32(i))
+	}
+	types[0] = int32(3)
+	metadata := map[string]any{
+		"general.architecture":        "llama",
+		"llama.vocab_size":            uint32(len(testTokens)),
+		"tokenizer.ggml.tokens":       tokens,
+		"tokenizer.ggml.token_type":   types,
+		"tokenizer.ggml.merges":       []any{"a b", "ab c", "c d", "d d"},
+		"tokenizer.ggml.bos_token_id": uint32(0),
+	}
+	var buf bytes.Buffer
+	err := gguf.Write(&buf, metadata, []gguf.WriterTensor{
+		f32Tensor("token_embd.weight", []uint64{2, uint64(len(testTokens))}, f32Bytes(embd...)),
+		f32Tensor("output_norm.weight", []uint64{2}, f32Bytes(1, 2)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func parse(t *testing.T, data []byte) *gguf.File {
+	t.Helper()
+	f, err := gguf.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func countCorpus(t *testing.T, f *gguf.File, corpus string) *vocab.Frequency {
+	t.Helper()
+	tok, err := gguf.ExtractTokenizer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freq := vocab.NewFrequency(tok.VocabSize())
+	if err := vocab.CountCorpus(tok, strings.NewReader(corpus), freq); err != nil {
+		t.Fatal(err)
+	}
+	return freq
+}
+
+func TestCountCorpus(t *testing.T) {
+	f := parse(t, writeTestModel(t))
+	freq := countCorpus(t, f, "abc\n\nabc\ndd\n")
+	if freq.Documents != 3 || freq.Tokens != 3 {
+		t.Fatalf("Documents, Tokens = %d, %d; want 3, 3", freq.Documents, freq.Tokens)
+	}
+	if freq.Counts[6] != 2 || freq.Counts[8] != 1 || freq.Used() != 2 {
+		t.Errorf("Counts = %v, want abc=2 dd=1", freq.Counts)
+	}
+	if got := freq.Ranked(); !reflect.DeepEqual(got, []vocab.TokenCount{{ID: 6, Count: 2}, {ID: 8, Count: 1}}) {
+		t.Errorf("Ranked() = %v", got)
+	}
+	if got := freq.CoverageSize(0.5); got != 1 {
+		t.Errorf("CoverageSize(0.5) = %d, want 1", got)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	f := parse(t, writeTestModel(t))
+	freq := countCorpus(t, f, "abc\nabc\ndd\n")
+
+	for _, tc := range []struct {
+		name string
+		opts vocab.Options
+		keep []int
+	}{
+		// <s> and the characters are required; abc brings ab with it; cd
+		// is unused.
+		{"min count 1", vocab.Options{}, []int{0, 1, 2, 3, 4, 5, 6, 8}},
+		{"min count 2", vocab.Options{MinCount: 2}, []int{0, 1, 2, 3, 4, 5, 6}},
+		// abc and ab fill the cap, so dd no longer fits.
+		{"max vocab", vocab.Options{MaxVocab: 7}, []int{0, 1, 2, 3, 4, 5, 6}},
+		// abc needs two slots, dd needs one.
+		{"max vocab skips", vocab.Options{MaxVocab: 6}, []int{0, 1, 2, 3, 4, 8}},
+	} {
+		plan, err := vocab.NewPlan(f, freq, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: NewPlan() error = %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(plan.Keep, tc.keep) {
+			t.Errorf("%s: Keep = %v, want %v", tc.name, plan.Keep, tc.keep)
+		}
+		if plan.Required != 5 {
+			t.Errorf("%s: Required = %d, want 5", tc.name, plan.Required)
+		}
+	}
+
+	if _, err := vocab.NewPlan(f, freq, vocab.Options{MaxVocab: 4}); err == nil {
+		t.Error("NewPlan() below the required size succeeded")
+	}
+	if _, err := vocab.NewPlan(f, vocab.NewFrequency(3), vocab.Options{}); err == nil {
+		t.Error("NewPlan() with mismatched frequencies succeeded")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	src := writeTestModel(t)
+	f := parse(t, src)
+	freq := countCorpus(t, f, "abc\nabc\ndd\n")
+	plan, err := vocab.NewPlan(f, freq, vocab.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	res, err := vocab.Prune(bytes.NewReader(src), &out, f, plan)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if res.OldSize != 9 || res.NewSize != 8 || !reflect.DeepEqual(res.Tensors, []string{"token_embd.weight"}) {
+		t.Errorf("Result = %+v", res)
+	}
+
+	pruned := parse(t, out.Bytes())
+	wantTokens := []any{"<s>", "a", "b", "c", "d", "ab", "abc", "dd"}
+	if got := pruned.Metadata["tokenizer.ggml.tokens"]; !reflect.DeepEqual(got, wantTokens) {
+		t.Errorf("tokens = %v, want %v", got, wantTokens)
+	}
+	if got := pruned.Metadata["tokenizer.ggml.merges"]; !reflect.DeepEqual(got, []any{"a b", "ab c", "d d"}) {
+		t.Errorf("merges = %v", got)
+	}
+	if got := pruned.Metadata["llama.vocab_size"]; got != uint32(8) {
+		t.Errorf("vocab_size = %v, want 8", got)
+	}
+	if got := pruned.Metadata["zerfoo.vocab.source_ids"]; len(got.([]any)) != 8 || got.([]any)[7] != uint32(8) {
+		t.Errorf("source_ids = %v", got)
+	}
+
+	r := bytes.NewReader(out.Bytes())
+	for _, ti := range pruned.Tensors {
+		if ti.Name != "token_embd.weight" {
+			continue
+		}
+		if !reflect.DeepEqual(ti.Dimensions, []uint64{2, 8}) {
+			t.Fatalf("token_embd dims = %v, want [2 8]", ti.Dimensions)
+		}
+		got := make([]byte, 4*16)
+		if _, err := r.ReadAt(got, pruned.DataOffset+int64(ti.Offset)); err != nil {
+			t.Fatal(err)
+		}
+		// New row 7 is the original dd row.
+		if want := f32Bytes(8, -8); !bytes.Equal(got[56:], want) {
+			t.Errorf("row 7 = %v, want %v", got[56:], want)
+		}
+	}
+
+	tok, err := gguf.ExtractTokenizer(pruned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for text, want := range map[string][]int{"abc": {6}, "cd": {3, 4}, "dd": {7}} {
+		got, err := tok.Encode(text)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Encode(%q) = %v, %v; want %v", text, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package serve
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/serve/registry"
+	"github.com/zerfoo/zerfoo/serve/security"
+	"github.com/zerfoo/ztensor/metrics/runtime"
+)
+
+// AdminOption configures the admin API returned by [Server.AdminHandler].
+type AdminOption func(*adminConfig)
+
+type adminConfig struct {
+	apiKey         string
+	clientCert     bool
+	allowedClients []string
+	loader         func(ctx context.Context, modelID string) (*inference.Model, error)
+	router         *registry.ABRouter
+}
+
+// WithAdminAPIKey accepts admin requests carrying this Bearer token. The key
+// must differ from the inference API key set with [WithAPIKey].
+func WithAdminAPIKey(key string) AdminOption {
+	return func(c *adminConfig) {
+		c.apiKey = key
+	}
+}
+
+// WithAdminClientCert accepts admin requests that present a TLS client
+// certificate verified by the listener (tls.RequireAndVerifyClientCert).
+// If allowed is non-empty, the certificate's common name or one of its DNS
+// names must be in the list.
+func WithAdminClientCert(allowed ...string) AdminOption {
+	return func(c *adminConfig) {
+		c.clientCert = true
+		c.allowedClients = allowed
+	}
+}
+
+// WithAdminModelLoader enables POST /admin/v1/models/load. fn loads a model
+// by ID, typically inference.Load with the server's load options.
+func WithAdminModelLoader(fn func(ctx context.Context, modelID string) (*inference.Model, error)) AdminOption {
+	return func(c *adminConfig) {
+		c.loader = fn
+	}
+}
+
+// WithAdminTrafficRouter enables the /admin/v1/traffic endpoints, which read
+// and update the challenger weight of an A/B traffic split.
+func WithAdminTrafficRouter(r *registry.ABRouter) AdminOption {
+	return func(c *adminConfig) {
+		c.router = r
+	}
+}
+
+// AdminHandler returns the HTTP handler for the admin API. It is separate
+// from [Server.Handler] and should be served on its own listener, so
+// operational endpoints are never reachable through the inference port:
+//
+//	GET  /admin/v1/status         Model, readiness, drain state, in-flight requests
+//	POST /admin/v1/models/load    Load a model and swap it in: {"model": "id"}
+//	POST /admin/v1/models/unload  Unload the current model
+//	POST /admin/v1/drain          Start or stop draining: {"enabled": true}
+//	GET  /admin/v1/traffic        Current A/B split and request counts
+//	PUT  /admin/v1/traffic        Set the split: {"challenger_weight": 0.1}
+//	GET  /admin/v1/metrics        Metrics snapshot as JSON
+//
+// Every request must authenticate with at least one configured method: the
+// admin API key, a key from the server's [security.KeyStore] with
+// [security.ScopeAdmin], or a verified client certificate. AdminHandler
+// returns an error if no method is configured or if the admin key equals the
+// inference key.
+func (s *Server) AdminHandler(opts ...AdminOption) (http.Handler, error) {
+	cfg := &adminConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.apiKey == "" && !cfg.clientCert && s.keyStore == nil {
+		return nil, errors.New("serve: admin API requires an admin API key, client certificates, or a key store")
+	}
+	if cfg.apiKey != "" && cfg.apiKey == s.apiKey {
+		return nil, errors.New("serve: admin API key must differ from the inference API key")
+	}
+
+	a := &adminAPI{s: s, cfg: cfg}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/v1/status", s.recoveryMiddleware(a.handleStatus))
+	mux.HandleFunc("POST /admin/v1/models/load", s.recoveryMiddleware(a.handleLoad))
+	mux.HandleFunc("POST /admin/v1/models/unload", s.recoveryMiddleware(a.handleUnload))
+	mux.HandleFunc("POST /admin/v1/drain", s.recoveryMiddleware(a.handleDrain))
+	mux.HandleFunc("GET /admin/v1/traffic", s.recoveryMiddleware(a.handleTrafficGet))
+	mux.HandleFunc("PUT /admin/v1/traffic", s.recoveryMiddleware(a.handleTrafficPut))
+	mux.HandleFunc("GET /admin/v1/metrics", s.recoveryMiddleware(a.handleMetrics))
+
+	var h http.Handler = a.authMiddleware(mux)
+	h = s.requestIDMiddleware(h)
+	return s.securityHeadersMiddleware(h), nil
+}
+
+type adminAPI struct {
+	s   *Server
+	cfg *adminConfig
+}
+
+// AdminStatus is the response of GET /admin/v1/status.
+type AdminStatus struct {
+	Model          string `json:"model,omitempty"`
+	Loaded         bool   `json:"loaded"`
+	Draining       bool   `json:"draining"`
+	ActiveRequests int64  `json:"active_requests"`
+}
+
+// AdminLoadRequest is the body of POST /admin/v1/models/load.
+type AdminLoadRequest struct {
+	Model string `json:"model"`
+}
+
+// AdminDrainRequest is the body of POST /admin/v1/drain. An empty body
+// starts draining.
+type AdminDrainRequest struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// AdminTraffic is the response of the /admin/v1/traffic endpoints and the
+// body of PUT /admin/v1/traffic.
+type AdminTraffic struct {
+	ChallengerWeight   float64 `json:"challenger_weight"`
+	ChampionRequests   int64   `json:"champion_requests"`
+	ChallengerRequests int64   `json:"challenger_requests"`
+}
+
+// AdminMetrics is the response of GET /admin/v1/metrics.
+type AdminMetrics struct {
+	Counters map[string]int64   `json:"counters"`
+	Gauges   map[string]float64 `json:"gauges"`
+}
+
+// authMiddleware admits requests that satisfy any configured admin
+// credential. Inference-only credentials are rejected with 403.
+func (a *adminAPI) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.cfg.clientCert && a.clientCertAllowed(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeError(w, http.StatusUnauthorized, "admin credentials required")
+			return
+		}
+		if a.cfg.apiKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.cfg.apiKey)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ks := a.s.keyStore; ks != nil {
+			if key := ks.Lookup(token); key != nil && key.Valid(time.Now()) {
+				if key.HasScope(security.ScopeAdmin) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				writeError(w, http.StatusForbidden, "insufficient scope")
+				return
+			}
+		}
+		if a.s.apiKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.s.apiKey)) == 1 {
+			writeError(w, http.StatusForbidden, "inference key cannot access the admin API")
+			return
+		}
+		writeError(w, http.StatusUnauthorized, "invalid admin credentials")
+	})
+}
+
+func (a *adminAPI) clientCertAllowed(r *http.Request) bool {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return false
+	}
+	if len(a.cfg.allowedClients) == 0 {
+		return true
+	}
+	leaf := r.TLS.VerifiedChains[0][0]
+	if slices.Contains(a.cfg.allowedClients, leaf.Subject.CommonName) {
+		return true
+	}
+	for _, name := range leaf.DNSNames {
+		if slices.Contains(a.cfg.allowedClients, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *adminAPI) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	st := AdminStatus{
+		Draining:       a.s.draining.Load(),
+		ActiveRequests: a.s.metrics.ActiveRequests(),
+	}
+	a.s.modelMu.RLock()
+	if a.s.model != nil && !a.s.unloaded.Load() {
+		st.Loaded = true
+		st.Model = a.s.buildModelObject().ID
+	}
+	a.s.modelMu.RUnlock()
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (a *adminAPI) handleLoad(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.loader == nil {
+		writeError(w, http.StatusNotImplemented, "model loading is not configured")
+		return
+	}
+	var req AdminLoadRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil || req.Model == "" {
+		writeError(w, http.StatusBadRequest, "request body must be {\"model\": \"<id>\"}")
+		return
+	}
+
+	// Load outside the lock so inference continues on the old model.
+	m, err := a.cfg.loader(r.Context(), req.Model)
+	if err != nil {
+		a.s.logger.Error("admin model load failed", "model", req.Model, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "load model: "+err.Error())
+		return
+	}
+	a.s.swapModel(m)
+	a.s.logger.Info("admin model loaded", "model", req.Model)
+	a.handleStatus(w, r)
+}
+
+func (a *adminAPI) handleUnload(w http.ResponseWriter, r *http.Request) {
+	if !a.s.unloadModel() {
+		writeError(w, http.StatusConflict, "no model is loaded")
+		return
+	}
+	a.s.logger.Info("admin model unloaded")
+	a.handleStatus(w, r)
+}
+
+func (a *adminAPI) handleDrain(w http.ResponseWriter, r *http.Request) {
+	var req AdminDrainRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+	a.s.draining.Store(enabled)
+	a.s.logger.Info("admin drain", "enabled", strconv.FormatBool(enabled))
+	a.handleStatus(w, r)
+}
+
+func (a *adminAPI) handleTrafficGet(w http.ResponseWriter, _ *http.Request) {
+	if a.cfg.router == nil {
+		writeError(w, http.StatusNotImplemented, "traffic split is not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, a.traffic())
+}
+
+func (a *adminAPI) handleTrafficPut(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.router == nil {
+		writeError(w, http.StatusNotImplemented, "traffic split is not configured")
+		return
+	}
+	var req AdminTraffic
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := a.cfg.router.UpdateWeights(req.ChallengerWeight); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, a.traffic())
+}
+
+func (a *adminAPI) traffic() AdminTraffic {
+	stats := a.cfg.router.Stats()
+	return AdminTraffic{
+		ChallengerWeight:   a.cfg.router.ChallengerWeight(),
+		ChampionRequests:   stats.ChampionRequests,
+		ChallengerRequests: stats.ChallengerRequests,
+	}
+}
+
+func (a *adminAPI) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	out := AdminMetrics{Counters: map[string]int64{}, Gauges: map[string]float64{}}
+	if imc, ok := a.s.collector.(*runtime.InMemoryCollector); ok {
+		snap := imc.Snapshot()
+		out.Counters = snap.Counters
+		out.Gauges = snap.Gauges
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// swapModel replaces the served model once in-flight requests on the old
+// model finish, then closes the old model.
+func (s *Server) swapModel(m *inference.Model) {
+	s.modelMu.Lock()
+	old, wasLoaded := s.model, !s.unloaded.Load()
+	s.model = m
+	s.unloaded.Store(false)
+	if s.sessions != nil && old != m {
+		s.sessions.clear()
+	}
+	s.modelMu.Unlock()
+	if old != nil && old != m && wasLoaded {
+		_ = old.Close()
+	}
+}
+
+// unloadModel closes the served model after in-flight requests finish. It
+// reports false if no model was loaded.
+func (s *Server) unloadModel() bool {
+	// The write side of modelMu waits for every handler holding RLock and
+	// excludes new ones until unloaded is set and the model is closed
+	// (see CONC-H2).
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+	if s.model == nil || s.unloaded.Load() {
+		return false
+	}
+	s.unloaded.Store(true)
+	if s.sessions != nil {
+		s.sessions.clear()
+	}
+	_ = s.model.Close()
+	return true
+}
@@ -0,0 +1,228 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/zerfoo/serve/registry"
+	"github.com/zerfoo/zerfoo/serve/security"
+	"github.com/zerfoo/ztensor/metrics/runtime"
+)
+
+// adminDo sends an admin request with an optional Bearer token and decodes
+// a JSON response into out when non-nil.
+func adminDo(t *testing.T, h http.Handler, method, path, token, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if out != nil && rec.Code < 300 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAdminHandler_RequiresAuth(t *testing.T) {
+	srv := NewServer(buildTestModel(t), WithAPIKey("infer"))
+	if _, err := srv.AdminHandler(); err == nil {
+		t.Error("expected error with no admin auth configured")
+	}
+	if _, err := srv.AdminHandler(WithAdminAPIKey("infer")); err == nil {
+		t.Error("expected error when admin key equals inference key")
+	}
+}
+
+func TestAdminHandler_Auth(t *testing.T) {
+	ks := security.NewKeyStore()
+	scoped, _, err := ks.Create("ops", []security.Scope{security.ScopeAdmin}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inferOnly, _, err := ks.Create("app", []security.Scope{security.ScopeInference}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(buildTestModel(t), WithAPIKey("infer"), WithKeyStore(ks))
+	h, err := srv.AdminHandler(WithAdminAPIKey("admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"unknown token", "nope", http.StatusUnauthorized},
+		{"inference key", "infer", http.StatusForbidden},
+		{"inference scope", inferOnly, http.StatusForbidden},
+		{"admin key", "admin", http.StatusOK},
+		{"admin scope", scoped, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adminDo(t, h, http.MethodGet, "/admin/v1/status", tt.token, "", nil); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// The admin key is not accepted by the inference API.
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/v1/models", http.NoBody)
+	req.Header.Set("Authorization", "Bearer admin")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("admin key on inference API: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAdminHandler_Drain(t *testing.T) {
+	srv := NewServer(buildTestModel(t))
+	h, err := srv.AdminHandler(WithAdminAPIKey("admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := srv.Handler()
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, http.NoBody))
+		return rec.Code
+	}
+
+	var st AdminStatus
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/drain", "admin", "", &st); code != http.StatusOK {
+		t.Fatalf("drain: status = %d", code)
+	}
+	if !st.Draining {
+		t.Error("Draining = false after drain")
+	}
+	if got := get("/v1/models"); got != http.StatusServiceUnavailable {
+		t.Errorf("/v1/models while draining = %d, want 503", got)
+	}
+	if got := get("/readyz"); got != http.StatusServiceUnavailable {
+		t.Errorf("/readyz while draining = %d, want 503", got)
+	}
+	if got := get("/healthz"); got != http.StatusOK {
+		t.Errorf("/healthz while draining = %d, want 200", got)
+	}
+
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/drain", "admin", `{"enabled":false}`, &st); code != http.StatusOK {
+		t.Fatalf("undrain: status = %d", code)
+	}
+	if st.Draining {
+		t.Error("Draining = true after undrain")
+	}
+	if got := get("/v1/models"); got != http.StatusOK {
+		t.Errorf("/v1/models after undrain = %d, want 200", got)
+	}
+}
+
+func TestAdminHandler_LoadUnload(t *testing.T) {
+	srv := NewServer(buildTestModel(t))
+	var loaded string
+	loader := func(_ context.Context, id string) (*inference.Model, error) {
+		if id == "missing" {
+			return nil, errors.New("not found")
+		}
+		loaded = id
+		return buildTestModel(t), nil
+	}
+	h, err := srv.AdminHandler(WithAdminAPIKey("admin"), WithAdminModelLoader(loader))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var st AdminStatus
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/models/unload", "admin", "", &st); code != http.StatusOK {
+		t.Fatalf("unload: status = %d", code)
+	}
+	if st.Loaded {
+		t.Error("Loaded = true after unload")
+	}
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/models/unload", "admin", "", nil); code != http.StatusConflict {
+		t.Errorf("second unload: status = %d, want 409", code)
+	}
+
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/models/load", "admin", `{}`, nil); code != http.StatusBadRequest {
+		t.Errorf("load without model: status = %d, want 400", code)
+	}
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/models/load", "admin", `{"model":"missing"}`, nil); code != http.StatusInternalServerError {
+		t.Errorf("load failure: status = %d, want 500", code)
+	}
+	if code := adminDo(t, h, http.MethodPost, "/admin/v1/models/load", "admin", `{"model":"next"}`, &st); code != http.StatusOK {
+		t.Fatalf("load: status = %d", code)
+	}
+	if loaded != "next" || !st.Loaded {
+		t.Errorf("loaded = %q, Loaded = %v", loaded, st.Loaded)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/readyz", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/readyz after reload = %d, want 200", rec.Code)
+	}
+}
+
+func TestAdminHandler_Traffic(t *testing.T) {
+	srv := NewServer(buildTestModel(t))
+	noRouter, err := srv.AdminHandler(WithAdminAPIKey("admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := adminDo(t, noRouter, http.MethodGet, "/admin/v1/traffic", "admin", "", nil); code != http.StatusNotImplemented {
+		t.Errorf("traffic without router: status = %d, want 501", code)
+	}
+
+	router := registry.NewABRouter(registry.ABConfig{ChampionID: "a", ChallengerID: "b", ChallengerWeight: 0.1})
+	h, err := srv.AdminHandler(WithAdminAPIKey("admin"), WithAdminTrafficRouter(router))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tr AdminTraffic
+	if code := adminDo(t, h, http.MethodGet, "/admin/v1/traffic", "admin", "", &tr); code != http.StatusOK {
+		t.Fatalf("get traffic: status = %d", code)
+	}
+	if tr.ChallengerWeight != 0.1 {
+		t.Errorf("ChallengerWeight = %v, want 0.1", tr.ChallengerWeight)
+	}
+	if code := adminDo(t, h, http.MethodPut, "/admin/v1/traffic", "admin", `{"challenger_weight":0.5}`, &tr); code != http.StatusOK {
+		t.Fatalf("put traffic: status = %d", code)
+	}
+	if tr.ChallengerWeight != 0.5 || router.ChallengerWeight() != 0.5 {
+		t.Errorf("ChallengerWeight = %v, router = %v, want 0.5", tr.ChallengerWeight, router.ChallengerWeight())
+	}
+	if code := adminDo(t, h, http.MethodPut, "/admin/v1/traffic", "admin", `{"challenger_weight":1.5}`, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid weight: status = %d, want 400", code)
+	}
+}
+
+func TestAdminHandler_Metrics(t *testing.T) {
+	mc := runtime.NewInMemory()
+	mc.Counter("requests_total").Inc()
+	srv := NewServer(buildTestModel(t), WithMetrics(mc))
+	h, err := srv.AdminHandler(WithAdminAPIKey("admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m AdminMetrics
+	if code := adminDo(t, h, http.MethodGet, "/admin/v1/metrics", "admin", "", &m); code != http.StatusOK {
+		t.Fatalf("metrics: status = %d", code)
+	}
+	if m.Counters["requests_total"] != 1 {
+		t.Errorf("requests_total = %d, want 1", m.Counters["requests_total"])
+	}
+}
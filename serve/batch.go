@@ -5,12 +5,18 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/zerfoo/zerfoo/inference"
 )
 
 // BatchRequest represents a single inference request in a batch.
 type BatchRequest struct {
 	Prompt string
 	Phase  string // "prefill" or "decode"
+
+	// model is the model the submitting handler read under modelMu.RLock;
+	// the auto-wired handler generates with it instead of Server.model.
+	model *inference.Model
 }
 
 // BatchResult holds the result for a single request in a batch.
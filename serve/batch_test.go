@@ -12,6 +12,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/zerfoo/zerfoo/inference"
 )
 
 func TestBatchScheduler_BatchesRequests(t *testing.T) {
@@ -384,3 +386,47 @@ func TestBatchScheduler_HTTPIntegration(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateBatch_NoModel(t *testing.T) {
+	mdl := buildTestModel(t)
+	results := generateBatch(context.Background(), []BatchRequest{
+		{Prompt: "hello", model: mdl},
+		{Prompt: "hello"},
+	})
+	if results[0].Err != nil {
+		t.Errorf("result[0] error = %v, want nil", results[0].Err)
+	}
+	if results[1].Err == nil {
+		t.Error("result[1] error = nil, want model not available")
+	}
+}
+
+// TestServerBatch_SwapModel swaps the served model while batched completions
+// are in flight; run with -race to check the batch handler never reads
+// Server.model outside modelMu.
+func TestServerBatch_SwapModel(t *testing.T) {
+	sched := NewBatchScheduler(BatchConfig{MaxBatchSize: 4, BatchTimeout: time.Millisecond})
+	srv := NewServer(buildTestModel(t), WithBatchScheduler(sched))
+	sched.Start()
+	defer sched.Stop()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	models := []*inference.Model{buildTestModel(t), buildTestModel(t)}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := doPost(t, ts.URL+"/v1/completions", "application/json", `{"prompt":"hello","max_tokens":3}`)
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("request %d: status = %d, want 200", i, resp.StatusCode)
+			}
+		}()
+	}
+	for _, m := range models {
+		srv.swapModel(m)
+	}
+	wg.Wait()
+}
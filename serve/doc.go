@@ -32,15 +32,43 @@
 //	GET  /v1/models             List loaded models
 //	GET  /v1/models/{id}        Get model info
 //	DELETE /v1/models/{id}      Unload a model
+//	POST /v1/sessions           Create a chat session (with WithSessions)
+//	GET  /v1/sessions/{id}      Get session info
+//	DELETE /v1/sessions/{id}    End a session
+//	POST /v1/sessions/{id}/chat/completions
+//	                            Chat turn in a session (streaming and non-streaming)
 //	GET  /openapi.yaml          OpenAPI specification
 //	GET  /metrics               Prometheus metrics
 //
+// # Admin API
+//
+// [Server.AdminHandler] returns a separate handler for operational endpoints
+// under /admin/v1/: status, model load and unload, drain, A/B traffic split
+// updates, and a metrics snapshot. Serve it on its own listener. Admin
+// requests authenticate with [WithAdminAPIKey], a [WithKeyStore] key holding
+// the admin scope, or a verified client certificate
+// ([WithAdminClientCert]); the inference API key is rejected. While draining,
+// /v1/ requests return 503 and /readyz reports "draining".
+//
 // # SSE Streaming
 //
 // When a chat or text completion request sets "stream": true, the server responds
 // with Server-Sent Events (SSE). Each event contains a JSON chunk with incremental
 // tokens. The stream terminates with a "data: [DONE]" sentinel.
 //
+// # Sessions
+//
+// [WithSessions] enables stateful chat. A client creates a session, then
+// sends each turn's new messages to /v1/sessions/{id}/chat/completions;
+// the server keeps the conversation and its KV cache, so a turn prefills
+// only the tokens it adds rather than the whole history. Usage reports the
+// reused prompt tokens in prompt_tokens_details.cached_tokens. Sessions
+// are evicted after [SessionConfig].IdleTimeout without a request, when
+// MaxSessions is reached and a new session needs the slot, and when the
+// model is swapped or unloaded. A session serves one request at a time;
+// a concurrent request gets 409. sessions_active, sessions_created_total,
+// sessions_evicted_total, and session_reused_tokens_total are exported.
+//
 // # Tool Calling
 //
 // Chat completion requests may include OpenAI-compatible tool definitions. The server
@@ -80,6 +108,23 @@
 // Metrics are collected through the runtime.Collector interface passed via
 // [WithMetrics].
 //
+// # Quotas
+//
+// [WithQuotas] enforces a [security.QuotaManager] on /v1/ endpoints: a
+// token-bucket request rate and a daily budget of generated tokens per
+// client, where the client is the API key (see [ClientID]). Rejected
+// requests get 429 with Retry-After, and quota_rejections_total and
+// quota_tokens_total are exported with client labels. [WithRateLimiter]
+// remains the per-IP limiter for unauthenticated traffic.
+//
+// # Request Logging
+//
+// [WithRequestLog] writes a [RequestLogEntry] per request to one or more
+// [RequestLogSink] implementations, such as [NewJSONRequestLogSink] for
+// stdout or [NewFileRequestLogSink]. Every entry records latency, status,
+// and outcome; request and response payloads are captured only for a
+// sampled fraction of requests, with configured JSON fields redacted.
+//
 // # Graceful Shutdown
 //
 // Call [Server.Close] to gracefully stop the server, which drains the batch
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/zerfoo/zerfoo/generate/grammar"
@@ -50,6 +49,10 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validateStop(req.Stop); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Validate tools and tool_choice.
 	if len(req.Tools) > 0 {
@@ -90,6 +93,8 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		TopP:        req.TopP,
 		TopK:        req.TopK,
 		MaxTokens:   req.MaxTokens,
+		Seed:        req.Seed,
+		Stop:        req.Stop,
 	})
 
 	// Wire response_format json_schema into grammar-constrained decoding.
@@ -127,9 +132,9 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	}
 	imgBudget := newImageFetchBudget(maxTotalImageBytes)
 
-	messages := make([]inference.Message, len(req.Messages))
-	for i, m := range req.Messages {
-		messages[i] = inference.Message{Role: m.Role, Content: m.Content}
+	conv := inference.NewConversation("")
+	for _, m := range req.Messages {
+		msg := inference.Message{Role: m.Role, Content: m.Content}
 		if len(m.ImageURLs) > 0 {
 			images, err := fetchImages(imgCtx, m.ImageURLs, imgBudget)
 			if err != nil {
@@ -137,12 +142,13 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 				writeError(w, http.StatusBadRequest, "image fetch failed")
 				return
 			}
-			messages[i].Images = images
+			msg.Images = images
 		}
+		conv.Add(msg)
 	}
 
 	if req.Stream {
-		s.streamChatCompletion(w, r.Context(), messages, opts)
+		s.streamChatCompletion(w, r.Context(), conv.Messages(), req.StreamOptions, opts)
 		return
 	}
 
@@ -151,19 +157,19 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if s.batch != nil {
-		// Build prompt from messages for batching.
-		var prompt strings.Builder
-		for _, m := range messages {
-			prompt.WriteString(m.Content)
-			prompt.WriteString(" ")
-		}
-		var br BatchResult
-		br, err = s.batch.Submit(r.Context(), BatchRequest{Prompt: prompt.String()})
+		// Render the conversation with the model's chat template so batched
+		// requests see the same prompt as the unbatched path.
+		var prompt string
+		prompt, err = s.model.RenderChat(conv.Messages())
 		if err == nil {
-			resp = inference.Response{Content: br.Value}
+			var br BatchResult
+			br, err = s.batch.Submit(r.Context(), BatchRequest{Prompt: prompt, model: s.model})
+			if err == nil {
+				resp = inference.Response{Content: br.Value}
+			}
 		}
 	} else {
-		resp, err = s.model.Chat(r.Context(), messages, opts...)
+		resp, err = s.model.ChatConversation(r.Context(), conv, opts...)
 	}
 	if err != nil {
 		writeError(w, inferenceErrorStatus(err), s.sanitizeError(err))
@@ -171,6 +177,7 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.metrics.RecordRequest(resp.CompletionTokens, time.Since(start))
+	s.chargeTokens(r.Context(), resp.CompletionTokens)
 
 	modelID := ""
 	if info := s.model.Info(); info != nil {
@@ -234,6 +241,10 @@ func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validateStop(req.Stop); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Clamp max_tokens to server-side upper bound.
 	if req.MaxTokens != nil && *req.MaxTokens > s.maxTokens {
@@ -260,10 +271,12 @@ func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
 		TopP:        req.TopP,
 		TopK:        req.TopK,
 		MaxTokens:   req.MaxTokens,
+		Seed:        req.Seed,
+		Stop:        req.Stop,
 	})
 
 	if req.Stream {
-		s.streamCompletion(w, r.Context(), req.Prompt, opts)
+		s.streamCompletion(w, r.Context(), req.Prompt, req.StreamOptions, opts)
 		return
 	}
 
@@ -274,7 +287,7 @@ func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case s.batch != nil:
 		var br BatchResult
-		br, err = s.batch.Submit(r.Context(), BatchRequest{Prompt: req.Prompt})
+		br, err = s.batch.Submit(r.Context(), BatchRequest{Prompt: req.Prompt, model: s.model})
 		result = br.Value
 	case s.draftModel != nil:
 		result, err = s.model.SpeculativeGenerate(r.Context(), s.draftModel, req.Prompt, 4, opts...)
@@ -304,6 +317,7 @@ func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.metrics.RecordRequest(completionTokens, time.Since(start))
+	s.chargeTokens(r.Context(), completionTokens)
 
 	writeJSON(w, http.StatusOK, CompletionResponse{
 		ID:      fmt.Sprintf("cmpl-%d", time.Now().UnixNano()),
@@ -324,6 +338,8 @@ func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleModels(w http.ResponseWriter, _ *http.Request) {
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
 	if s.unloaded.Load() {
 		writeJSON(w, http.StatusOK, ModelListResponse{Object: "list"})
 		return
@@ -339,6 +355,8 @@ func (s *Server) handleModels(w http.ResponseWriter, _ *http.Request) {
 func (s *Server) handleModelInfo(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
 	if s.unloaded.Load() {
 		writeError(w, http.StatusNotFound, "model '"+id+"' not found")
 		return
@@ -356,37 +374,21 @@ func (s *Server) handleModelInfo(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleModelDelete(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
-	if s.unloaded.Load() {
-		writeError(w, http.StatusNotFound, "model '"+id+"' not found")
-		return
-	}
-
-	obj := s.buildModelObject()
-	if obj.ID != id {
+	s.modelMu.RLock()
+	found := !s.unloaded.Load() && s.buildModelObject().ID == id
+	s.modelMu.RUnlock()
+	if !found {
 		writeError(w, http.StatusNotFound, "model '"+id+"' not found")
 		return
 	}
 
-	// Take the write side of modelMu: this blocks until every handler
-	// currently holding RLock (i.e. every in-flight request that already
-	// passed its unloaded check) has released it, then excludes any new
-	// RLock acquisition until unloaded is set and the model is closed. This
-	// closes both CONC-H2 races structurally: no handler can observe a model
-	// that Close() is concurrently tearing down, and there is no
-	// WaitGroup-style counter for a request to race against.
-	s.modelMu.Lock()
-	defer s.modelMu.Unlock()
-
-	// Re-check under the lock: a concurrent delete may have already won the
-	// race between the initial unloaded.Load() above and acquiring the lock.
-	if s.unloaded.Load() {
+	// unloadModel re-checks under the write lock: a concurrent delete may
+	// have won the race since the check above.
+	if !s.unloadModel() {
 		writeError(w, http.StatusNotFound, "model '"+id+"' not found")
 		return
 	}
 
-	s.unloaded.Store(true)
-	_ = s.model.Close()
-
 	writeJSON(w, http.StatusOK, ModelDeleteResponse{
 		ID:      id,
 		Object:  "model",
@@ -448,6 +450,16 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var useBase64 bool
+	switch req.EncodingFormat {
+	case "", "float":
+	case "base64":
+		useBase64 = true
+	default:
+		writeError(w, http.StatusBadRequest, "encoding_format must be \"float\" or \"base64\"")
+		return
+	}
+
 	var data []EmbeddingObject
 	var totalTokens int
 	for i, text := range inputs {
@@ -460,6 +472,7 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 			Object:    "embedding",
 			Embedding: emb,
 			Index:     i,
+			Base64:    useBase64,
 		})
 		if tok := s.model.Tokenizer(); tok != nil {
 			if ids, err := tok.Encode(text); err == nil {
@@ -511,6 +524,8 @@ type samplingParams struct {
 	TopP        *float64
 	TopK        *int
 	MaxTokens   *int
+	Seed        *int64
+	Stop        []string
 }
 
 // buildGenerationOptions converts sampling parameters into a slice of
@@ -532,6 +547,12 @@ func buildGenerationOptions(p samplingParams) []inference.GenerateOption {
 	if p.MaxTokens != nil {
 		opts = append(opts, inference.WithMaxTokens(*p.MaxTokens))
 	}
+	if p.Seed != nil {
+		opts = append(opts, inference.WithSeed(*p.Seed))
+	}
+	if len(p.Stop) > 0 {
+		opts = append(opts, inference.WithStopStrings(p.Stop...))
+	}
 	return opts
 }
 
@@ -607,10 +628,19 @@ func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
 }
 
 // handleReadyz returns 200 with {"status":"ready"} if the model is loaded and
-// has not been unloaded, or 503 with {"status":"not ready"} otherwise.
+// has not been unloaded, 503 with {"status":"draining"} while the admin API
+// drains traffic, or 503 with {"status":"not ready"} otherwise.
 func (s *Server) handleReadyz(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	if s.model == nil || s.unloaded.Load() {
+	if s.draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "draining"}) //nolint:errcheck
+		return
+	}
+	s.modelMu.RLock()
+	loaded := s.model != nil && !s.unloaded.Load()
+	s.modelMu.RUnlock()
+	if !loaded {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(map[string]string{"status": "not ready"}) //nolint:errcheck
 		return
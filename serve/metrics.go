@@ -114,6 +114,7 @@ var knownRoutes = map[string]struct{}{
 	"/v1/guard":                {},
 	"/v1/guard/batch":          {},
 	"/v1/guard/scan":           {},
+	"/v1/sessions":             {},
 	"/v1/models":               {},
 	"/healthz":                 {},
 	"/readyz":                  {},
@@ -126,7 +127,9 @@ var knownRoutes = map[string]struct{}{
 // from creating unbounded permanent counter entries (SERVE-1): every path
 // that is not one of the server's registered routes collapses to "other",
 // and the parameterized /v1/models/{id...} route collapses to a single
-// "/v1/models/{id}" label rather than echoing the attacker-chosen id.
+// "/v1/models/{id}" label rather than echoing the attacker-chosen id. Session
+// paths likewise collapse to "/v1/sessions/{id}" and
+// "/v1/sessions/{id}/chat/completions".
 func normalizeRoute(p string) string {
 	if _, ok := knownRoutes[p]; ok {
 		return p
@@ -134,6 +137,12 @@ func normalizeRoute(p string) string {
 	if strings.HasPrefix(p, "/v1/models/") {
 		return "/v1/models/{id}"
 	}
+	if rest, ok := strings.CutPrefix(p, "/v1/sessions/"); ok {
+		if strings.HasSuffix(rest, "/chat/completions") {
+			return "/v1/sessions/{id}/chat/completions"
+		}
+		return "/v1/sessions/{id}"
+	}
 	return "other"
 }
 
@@ -172,14 +181,20 @@ func handleMetrics(c runtime.Collector) http.HandlerFunc {
 		// Counters.
 		writeCounter(w, "requests_total", "Total number of requests", snap.Counters)
 		writeCounter(w, "tokens_generated_total", "Total tokens generated", snap.Counters)
+		writeCounter(w, "sessions_created_total", "Total chat sessions created", snap.Counters)
+		writeCounter(w, "sessions_evicted_total", "Chat sessions evicted for idleness, capacity, or a model change", snap.Counters)
+		writeCounter(w, "session_reused_tokens_total", "Prompt tokens served from session KV caches instead of prefilled", snap.Counters)
 
 		// Labeled error counters.
 		writeLabeledCounters(w, "errors_total", "Total number of errors by endpoint and status code", snap.Counters)
+		writeLabeledCounters(w, "quota_rejections_total", "Requests rejected by client quotas by client and reason", snap.Counters)
+		writeLabeledCounters(w, "quota_tokens_total", "Generated tokens charged to client quotas by client", snap.Counters)
 
 		// Gauges.
 		writeGauge(w, "tokens_per_second", "Last request tokens per second", snap.Gauges)
 		writeGauge(w, "tokens_per_second_ewma", "EWMA tokens per second", snap.Gauges)
 		writeGauge(w, "active_requests", "Number of in-flight requests", snap.Gauges)
+		writeGauge(w, "sessions_active", "Number of live chat sessions", snap.Gauges)
 		writeGauge(w, "speculative_acceptance_rate", "Speculative decoding acceptance rate", snap.Gauges)
 
 		// Histograms.
@@ -0,0 +1,199 @@
+package serve
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readSSEEvents returns the JSON payload of every data event before [DONE].
+func readSSEEvents(t *testing.T, body io.Reader) []string {
+	t.Helper()
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	var events []string
+	sawDone := false
+	for _, line := range strings.Split(string(raw), "\n") {
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			sawDone = true
+			continue
+		}
+		events = append(events, data)
+	}
+	if !sawDone {
+		t.Fatalf("stream did not terminate with [DONE]: %q", raw)
+	}
+	return events
+}
+
+func TestChatCompletionStream_ChunkShape(t *testing.T) {
+	srv := NewServer(buildTestModel(t))
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	body := `{"messages":[{"role":"user","content":"hello"}],"stream":true,"max_tokens":3,"stream_options":{"include_usage":true}}`
+	resp := doPost(t, ts.URL+"/v1/chat/completions", "application/json", body)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	events := readSSEEvents(t, resp.Body)
+	if len(events) < 3 {
+		t.Fatalf("got %d events, want at least role, finish and usage chunks", len(events))
+	}
+	chunks := make([]ChatCompletionChunk, len(events))
+	for i, e := range events {
+		if err := json.Unmarshal([]byte(e), &chunks[i]); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if chunks[i].Object != "chat.completion.chunk" || chunks[i].ID == "" {
+			t.Errorf("event %d: object=%q id=%q", i, chunks[i].Object, chunks[i].ID)
+		}
+	}
+
+	first := chunks[0]
+	if len(first.Choices) != 1 || first.Choices[0].Delta.Role != "assistant" || first.Choices[0].FinishReason != nil {
+		t.Errorf("first chunk = %+v, want assistant role delta with null finish_reason", first)
+	}
+
+	finish := chunks[len(chunks)-2]
+	if len(finish.Choices) != 1 || finish.Choices[0].FinishReason == nil || *finish.Choices[0].FinishReason != "stop" {
+		t.Errorf("finish chunk = %+v, want finish_reason stop", finish)
+	}
+
+	usage := chunks[len(chunks)-1]
+	if len(usage.Choices) != 0 || usage.Usage == nil {
+		t.Fatalf("usage chunk = %+v, want empty choices and usage", usage)
+	}
+	if usage.Usage.PromptTokens == 0 || usage.Usage.TotalTokens != usage.Usage.PromptTokens+usage.Usage.CompletionTokens {
+		t.Errorf("usage = %+v, want non-zero prompt tokens and consistent total", *usage.Usage)
+	}
+}
+
+func TestCompletionStream_FinishReason(t *testing.T) {
+	srv := NewServer(buildTestModel(t))
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	body := `{"prompt":"hello","stream":true,"max_tokens":3}`
+	resp := doPost(t, ts.URL+"/v1/completions", "application/json", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	events := readSSEEvents(t, resp.Body)
+	if len(events) == 0 {
+		t.Fatal("no events")
+	}
+	var last CompletionChunk
+	if err := json.Unmarshal([]byte(events[len(events)-1]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Usage != nil {
+		t.Errorf("usage chunk sent without stream_options.include_usage")
+	}
+	if len(last.Choices) != 1 || last.Choices[0].FinishReason == nil || *last.Choices[0].FinishReason != "stop" {
+		t.Errorf("last chunk = %+v, want finish_reason stop", last)
+	}
+}
+
+func TestStopSequences_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{`{"stop":"\n"}`, []string{"\n"}, false},
+		{`{"stop":["a","b"]}`, []string{"a", "b"}, false},
+		{`{"stop":null}`, nil, false},
+		{`{}`, nil, false},
+		{`{"stop":42}`, nil, true},
+	}
+	for _, tt := range tests {
+		var req CompletionRequest
+		err := json.Unmarshal([]byte(tt.in), &req)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if strings.Join(req.Stop, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: Stop = %q, want %q", tt.in, req.Stop, tt.want)
+		}
+	}
+}
+
+func TestHandleCompletions_TooManyStopSequences(t *testing.T) {
+	srv := NewServer(buildTestModel(t))
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	body := `{"prompt":"hello","stop":["a","b","c","d","e"]}`
+	resp := doPost(t, ts.URL+"/v1/completions", "application/json", body)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHandleEmbeddings_Base64Encoding(t *testing.T) {
+	srv := NewServer(buildEmbeddingTestModel(t))
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	floatResp := doPost(t, ts.URL+"/v1/embeddings", "application/json", `{"input":"hello world"}`)
+	defer func() { _ = floatResp.Body.Close() }()
+	var floats EmbeddingResponse
+	if err := json.NewDecoder(floatResp.Body).Decode(&floats); err != nil {
+		t.Fatalf("decode float response: %v", err)
+	}
+
+	b64Resp := doPost(t, ts.URL+"/v1/embeddings", "application/json", `{"input":"hello world","encoding_format":"base64"}`)
+	defer func() { _ = b64Resp.Body.Close() }()
+	if b64Resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", b64Resp.StatusCode)
+	}
+	var encoded struct {
+		Data []struct {
+			Embedding string `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(b64Resp.Body).Decode(&encoded); err != nil {
+		t.Fatalf("decode base64 response: %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded.Data[0].Embedding)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	want := floats.Data[0].Embedding
+	if len(raw) != 4*len(want) {
+		t.Fatalf("decoded %d bytes, want %d", len(raw), 4*len(want))
+	}
+	for i, w := range want {
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:])); got != w {
+			t.Errorf("embedding[%d] = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestHandleEmbeddings_InvalidEncodingFormat(t *testing.T) {
+	srv := NewServer(buildEmbeddingTestModel(t))
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp := doPost(t, ts.URL+"/v1/embeddings", "application/json", `{"input":"hello","encoding_format":"int8"}`)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,94 @@
+package serve
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/serve/security"
+)
+
+// clientIDKey is the context key for the authenticated client ID.
+type clientIDKey struct{}
+
+// Client IDs used when a request does not carry a KeyStore key.
+const (
+	staticKeyClientID = "default"
+	anonymousClientID = "anonymous"
+)
+
+// ClientID returns the ID quotas are tracked under for the request: the
+// KeyStore key ID, "default" for the static API key, or "anonymous" when
+// authentication is disabled.
+func ClientID(ctx context.Context) string {
+	if id, ok := ctx.Value(clientIDKey{}).(string); ok {
+		return id
+	}
+	return anonymousClientID
+}
+
+// WithQuotas enforces per-client request rates and daily generation token
+// budgets on /v1/ endpoints. Rejected requests get 429 Too Many Requests
+// with a Retry-After header. Clients are identified by [ClientID].
+func WithQuotas(q *security.QuotaManager) ServerOption {
+	return func(s *Server) {
+		s.quotas = q
+	}
+}
+
+// isGenerationRoute reports whether the request generates tokens that count
+// against the daily token budget: completions and session chat turns.
+func isGenerationRoute(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	path := r.URL.Path
+	return path == "/v1/chat/completions" || path == "/v1/completions" ||
+		(strings.HasPrefix(path, "/v1/sessions/") && strings.HasSuffix(path, "/chat/completions"))
+}
+
+// quotaMiddleware applies s.quotas. It runs after authMiddleware so the
+// client ID is known and the set of tracked clients stays bounded.
+func (s *Server) quotaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client := ClientID(r.Context())
+		d := s.quotas.AllowRequest(client, isGenerationRoute(r))
+		if !d.Allowed {
+			s.collector.Counter(quotaCounter("quota_rejections_total", client, string(d.Reason))).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+			msg := "rate limit exceeded"
+			if d.Reason == security.QuotaTokens {
+				msg = "daily token quota exceeded"
+			}
+			writeError(w, http.StatusTooManyRequests, msg)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// chargeTokens records n generated tokens against the request's client.
+func (s *Server) chargeTokens(ctx context.Context, n int) {
+	if s.quotas == nil || n <= 0 {
+		return
+	}
+	client := ClientID(ctx)
+	s.quotas.RecordTokens(client, int64(n))
+	s.collector.Counter(quotaCounter("quota_tokens_total", client, "")).Add(int64(n))
+}
+
+// quotaCounter builds a labeled counter name. Client IDs come from
+// authenticated keys, so label cardinality is bounded by the key store.
+func quotaCounter(name, client, reason string) string {
+	labels := `client="` + client + `"`
+	if reason != "" {
+		labels += `,reason="` + reason + `"`
+	}
+	return name + "{" + labels + "}"
+}
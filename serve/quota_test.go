@@ -0,0 +1,133 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/serve/security"
+	"github.com/zerfoo/ztensor/metrics/runtime"
+)
+
+func quotaRequest(t *testing.T, h http.Handler, token, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	method := http.MethodGet
+	if body != "" {
+		method = http.MethodPost
+	}
+	req := httptest.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestQuotas_PerKeyRequestRate(t *testing.T) {
+	ks := security.NewKeyStore()
+	scopes := []security.Scope{security.ScopeInference, security.ScopeReadOnly}
+	keyA, _, err := ks.Create("a", scopes, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, _, err := ks.Create("b", scopes, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := runtime.NewInMemory()
+	qm := security.NewQuotaManager(security.Quota{RequestsPerSecond: 0.001, Burst: 1})
+	h := NewServer(buildTestModel(t), WithKeyStore(ks), WithQuotas(qm), WithMetrics(mc)).Handler()
+
+	if rec := quotaRequest(t, h, keyA, "/v1/models", ""); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d", rec.Code)
+	}
+	rec := quotaRequest(t, h, keyA, "/v1/models", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+	// One client exhausting its quota does not affect another.
+	if rec := quotaRequest(t, h, keyB, "/v1/models", ""); rec.Code != http.StatusOK {
+		t.Errorf("other key: status = %d, want 200", rec.Code)
+	}
+	// Health checks are never rate limited.
+	if rec := quotaRequest(t, h, keyA, "/healthz", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz: status = %d, want 200", rec.Code)
+	}
+
+	var found bool
+	for name, v := range mc.Snapshot().Counters {
+		if strings.HasPrefix(name, "quota_rejections_total{") && strings.Contains(name, `reason="rate"`) && v == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("quota_rejections_total not recorded: %v", mc.Snapshot().Counters)
+	}
+}
+
+func TestQuotas_DailyTokens(t *testing.T) {
+	mc := runtime.NewInMemory()
+	qm := security.NewQuotaManager(security.Quota{TokensPerDay: 1})
+	h := NewServer(buildTestModel(t), WithAPIKey("key"), WithQuotas(qm), WithMetrics(mc)).Handler()
+	body := `{"model":"test-model","messages":[{"role":"user","content":"hi"}]}`
+
+	if rec := quotaRequest(t, h, "key", "/v1/chat/completions", body); rec.Code != http.StatusOK {
+		t.Fatalf("first completion: status = %d", rec.Code)
+	}
+	used := qm.Usage("default").TokensToday
+	if used == 0 {
+		t.Fatal("no tokens charged to the static key client")
+	}
+	if got := mc.Snapshot().Counters[`quota_tokens_total{client="default"}`]; got != used {
+		t.Errorf("quota_tokens_total = %d, want %d", got, used)
+	}
+
+	rec := quotaRequest(t, h, "key", "/v1/chat/completions", body)
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "token quota") {
+		t.Errorf("over budget: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	// Read-only endpoints still work once the token budget is spent.
+	if rec := quotaRequest(t, h, "key", "/v1/models", ""); rec.Code != http.StatusOK {
+		t.Errorf("/v1/models over budget: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestQuotas_DailyTokensSessionChat(t *testing.T) {
+	qm := security.NewQuotaManager(security.Quota{TokensPerDay: 1})
+	srv := NewServer(buildTestModel(t), WithAPIKey("key"), WithQuotas(qm), WithSessions(SessionConfig{}))
+	defer func() { _ = srv.Close(context.Background()) }()
+	h := srv.Handler()
+
+	rec := quotaRequest(t, h, "key", "/v1/sessions", `{"system":"be brief"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create session: status = %d", rec.Code)
+	}
+	var obj SessionObject
+	if err := json.NewDecoder(rec.Body).Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+	path := "/v1/sessions/" + obj.ID + "/chat/completions"
+	body := `{"messages":[{"role":"user","content":"hi"}],"max_tokens":5}`
+
+	if rec := quotaRequest(t, h, "key", path, body); rec.Code != http.StatusOK {
+		t.Fatalf("first turn: status = %d", rec.Code)
+	}
+	if qm.Usage("default").TokensToday == 0 {
+		t.Fatal("no tokens charged for the session turn")
+	}
+	rec = quotaRequest(t, h, "key", path, body)
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "token quota") {
+		t.Errorf("over budget: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	// Session management is not a generation route.
+	if rec := quotaRequest(t, h, "key", "/v1/sessions/"+obj.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("get session over budget: status = %d, want 200", rec.Code)
+	}
+}
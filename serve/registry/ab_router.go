@@ -74,6 +74,11 @@ func (r *ABRouter) UpdateWeights(challengerWeight float64) error {
 	return nil
 }
 
+// ChallengerWeight returns the current challenger traffic weight.
+func (r *ABRouter) ChallengerWeight() float64 {
+	return float64(r.weight.Load()) / 1000
+}
+
 // Stats returns the current request counters.
 func (r *ABRouter) Stats() ABStats {
 	r.mu.Lock()
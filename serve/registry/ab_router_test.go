@@ -90,6 +90,9 @@ func TestABRouterUpdateWeights(t *testing.T) {
 	if err := r.UpdateWeights(1.0); err != nil {
 		t.Fatal(err)
 	}
+	if got := r.ChallengerWeight(); got != 1.0 {
+		t.Fatalf("ChallengerWeight() = %v, want 1", got)
+	}
 	for i := 0; i < 100; i++ {
 		if got := r.Route(fmt.Sprintf("w1-%d", i)); got != "challenger" {
 			t.Fatalf("with weight 1, Route returned %q, want challenger", got)
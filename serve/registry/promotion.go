@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrRegression is returned by Promote when the candidate regresses beyond
+// a threshold and is not activated.
+var ErrRegression = errors.New("registry: candidate regresses against the active version")
+
+// Evaluator scores a model version on a fixed golden dataset and returns
+// its metrics by name. Promote calls it for the candidate and the active
+// version, so both are measured on the same data by the same code.
+type Evaluator func(ctx context.Context, mv ModelVersion) (map[string]float64, error)
+
+// MetricThreshold bounds how far one metric may get worse than the active
+// version's before a promotion is refused.
+type MetricThreshold struct {
+	// Metric is the name the Evaluator reports the metric under.
+	Metric string
+	// MaxRegression is the largest allowed worsening, in the metric's units.
+	MaxRegression float64
+	// LowerIsBetter marks metrics such as loss or NLL, where a rise is a
+	// regression. Otherwise a drop is.
+	LowerIsBetter bool
+}
+
+// PromotionDecision records one Promote call. Decisions are stored in the
+// registry and listed by Promotions.
+type PromotionDecision struct {
+	Name        string `json:"name"`
+	CandidateID string `json:"candidate_id"`
+	// BaselineID is the version that was active, or empty if none was.
+	BaselineID string `json:"baseline_id,omitempty"`
+	Promoted   bool   `json:"promoted"`
+	// Candidate and Baseline are the evaluated metrics, and Delta is
+	// candidate minus baseline for every metric both report.
+	Candidate map[string]float64 `json:"candidate"`
+	Baseline  map[string]float64 `json:"baseline,omitempty"`
+	Delta     map[string]float64 `json:"delta,omitempty"`
+	// Regressions names the thresholded metrics that got worse by more
+	// than their MaxRegression.
+	Regressions []string  `json:"regressions,omitempty"`
+	DecidedAt   time.Time `json:"decided_at"`
+}
+
+// Promote activates version id only if it does not regress against the
+// active version of the same name. It evaluates both with eval and checks
+// every threshold; if a metric gets worse by more than its MaxRegression,
+// the active version stays active and Promote returns the decision with
+// ErrRegression. With no active version the candidate is promoted
+// unconditionally. Either way the candidate's evaluated metrics are merged
+// into its Metrics, and the decision is recorded.
+func (r *Registry) Promote(ctx context.Context, id string, eval Evaluator, thresholds []MetricThreshold) (*PromotionDecision, error) {
+	if id == "" {
+		return nil, errNilID
+	}
+	if eval == nil {
+		return nil, errors.New("registry: Promote needs an Evaluator")
+	}
+	for _, th := range thresholds {
+		if th.Metric == "" || th.MaxRegression < 0 {
+			return nil, fmt.Errorf("registry: threshold needs a metric and a non-negative MaxRegression, got %q and %g", th.Metric, th.MaxRegression)
+		}
+	}
+	candidate, err := r.get(id)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := r.GetActive(candidate.Name)
+	switch {
+	case errors.Is(err, errNotFound):
+		baseline = nil
+	case err != nil:
+		return nil, err
+	case baseline.ID == id:
+		return nil, fmt.Errorf("registry: %s is already active", id)
+	}
+
+	d := &PromotionDecision{Name: candidate.Name, CandidateID: id}
+	if d.Candidate, err = eval(ctx, *candidate); err != nil {
+		return nil, fmt.Errorf("registry: evaluate %s: %w", id, err)
+	}
+	if baseline != nil {
+		d.BaselineID = baseline.ID
+		if d.Baseline, err = eval(ctx, *baseline); err != nil {
+			return nil, fmt.Errorf("registry: evaluate %s: %w", baseline.ID, err)
+		}
+		d.Delta = make(map[string]float64)
+		for name, c := range d.Candidate {
+			if b, ok := d.Baseline[name]; ok {
+				d.Delta[name] = c - b
+			}
+		}
+		for _, th := range thresholds {
+			delta, ok := d.Delta[th.Metric]
+			if !ok {
+				return nil, fmt.Errorf("registry: metric %q not reported for both %s and %s", th.Metric, id, baseline.ID)
+			}
+			if th.LowerIsBetter {
+				delta = -delta
+			}
+			if -delta > th.MaxRegression {
+				d.Regressions = append(d.Regressions, th.Metric)
+			}
+		}
+	}
+	d.Promoted = len(d.Regressions) == 0
+	d.DecidedAt = time.Now()
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		if candidate.Metrics == nil {
+			candidate.Metrics = make(map[string]float64, len(d.Candidate))
+		}
+		for name, v := range d.Candidate {
+			candidate.Metrics[name] = v
+		}
+		data, err := json.Marshal(candidate)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketName).Put([]byte(id), data); err != nil {
+			return err
+		}
+		if d.Promoted {
+			if err := activate(tx, id); err != nil {
+				return err
+			}
+		}
+		b := tx.Bucket(promotionBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(d); err != nil {
+			return err
+		}
+		return b.Put(binary.BigEndian.AppendUint64(nil, seq), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !d.Promoted {
+		return d, fmt.Errorf("%w: %s on %s", ErrRegression, id, strings.Join(d.Regressions, ", "))
+	}
+	return d, nil
+}
+
+// Promotions returns the recorded promotion decisions for the given model
+// name, oldest first.
+func (r *Registry) Promotions(name string) ([]PromotionDecision, error) {
+	var out []PromotionDecision
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(promotionBucket).ForEach(func(_, v []byte) error {
+			var d PromotionDecision
+			if err := json.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			if d.Name == name {
+				out = append(out, d)
+			}
+			return nil
+		})
+	})
+	return out, err
+}
+
+// get returns the version with the given id.
+func (r *Registry) get(id string) (*ModelVersion, error) {
+	var mv *ModelVersion
+	err := r.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketName).Get([]byte(id))
+		if raw == nil {
+			return errNotFound
+		}
+		mv = new(ModelVersion)
+		return json.Unmarshal(raw, mv)
+	})
+	return mv, err
+}
@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPromote(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRegistry(t)
+	for _, id := range []string{"v1", "v2", "v3"} {
+		if err := r.Register(ModelVersion{ID: id, Name: "m"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// v2 loses 0.01 accuracy, within the threshold; v3 loses 0.05 and its
+	// NLL rises.
+	golden := map[string]map[string]float64{
+		"v1": {"acc": 0.80, "nll": 1.00},
+		"v2": {"acc": 0.79, "nll": 0.98},
+		"v3": {"acc": 0.75, "nll": 1.20},
+	}
+	var evaluated []string
+	eval := func(_ context.Context, mv ModelVersion) (map[string]float64, error) {
+		evaluated = append(evaluated, mv.ID)
+		return golden[mv.ID], nil
+	}
+	thresholds := []MetricThreshold{
+		{Metric: "acc", MaxRegression: 0.02},
+		{Metric: "nll", MaxRegression: 0.1, LowerIsBetter: true},
+	}
+	active := func() string {
+		t.Helper()
+		mv, err := r.GetActive("m")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mv.ID
+	}
+
+	// With nothing active, v1 is promoted without a comparison.
+	d, err := r.Promote(ctx, "v1", eval, thresholds)
+	if err != nil || !d.Promoted || d.BaselineID != "" || active() != "v1" {
+		t.Fatalf("Promote(v1) = %+v, %v", d, err)
+	}
+
+	evaluated = nil
+	d, err = r.Promote(ctx, "v2", eval, thresholds)
+	if err != nil || !d.Promoted || d.BaselineID != "v1" || active() != "v2" {
+		t.Fatalf("Promote(v2) = %+v, %v", d, err)
+	}
+	if !slices.Equal(evaluated, []string{"v2", "v1"}) {
+		t.Errorf("evaluated %v, want the candidate and the active version", evaluated)
+	}
+
+	d, err = r.Promote(ctx, "v3", eval, thresholds)
+	if !errors.Is(err, ErrRegression) || d == nil || d.Promoted {
+		t.Fatalf("Promote(v3) = %+v, %v; want ErrRegression", d, err)
+	}
+	if !slices.Equal(d.Regressions, []string{"acc", "nll"}) {
+		t.Errorf("Regressions = %v", d.Regressions)
+	}
+	if got := d.Delta["acc"]; got > -0.039 || got < -0.041 {
+		t.Errorf("Delta[acc] = %v, want -0.04", got)
+	}
+	if active() != "v2" {
+		t.Errorf("active = %s after a refused promotion, want v2", active())
+	}
+
+	// Decisions are recorded in order, and candidates keep their metrics.
+	decisions, err := r.Promotions("m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range decisions {
+		got = append(got, d.CandidateID)
+	}
+	if !slices.Equal(got, []string{"v1", "v2", "v3"}) || decisions[2].Promoted || decisions[2].Baseline["acc"] != 0.79 {
+		t.Errorf("Promotions = %+v", decisions)
+	}
+	v3, err := r.get("v3")
+	if err != nil || v3.Metrics["acc"] != 0.75 {
+		t.Errorf("v3 = %+v, %v; want its evaluated metrics stored", v3, err)
+	}
+
+	// Misuse.
+	if _, err := r.Promote(ctx, "v2", eval, thresholds); err == nil {
+		t.Error("promoting the active version should fail")
+	}
+	if _, err := r.Promote(ctx, "v3", eval, []MetricThreshold{{Metric: "f1"}}); err == nil {
+		t.Error("a threshold on an unreported metric should fail")
+	}
+	if _, err := r.Promote(ctx, "v3", eval, []MetricThreshold{{Metric: "acc", MaxRegression: -1}}); err == nil {
+		t.Error("a negative MaxRegression should fail")
+	}
+	if _, err := r.Promote(ctx, "nope", eval, nil); err == nil {
+		t.Error("promoting an unknown version should fail")
+	}
+	if _, err := r.Promote(ctx, "v3", func(context.Context, ModelVersion) (map[string]float64, error) {
+		return nil, errors.New("boom")
+	}, nil); err == nil {
+		t.Error("an evaluation error should fail the promotion")
+	}
+	if active() != "v2" {
+		t.Errorf("active = %s after failed promotions, want v2", active())
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 var (
 	bucketName      = []byte("models")
+	promotionBucket = []byte("promotions")
 	errNotFound     = errors.New("registry: model version not found")
 	errNilID        = errors.New("registry: ID must not be empty")
 	errNilName      = errors.New("registry: Name must not be empty")
@@ -43,7 +44,10 @@ func NewRegistry(dbPath string) (*Registry, error) {
 		return nil, fmt.Errorf("registry: open db: %w", err)
 	}
 	if err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucketName)
+		if _, err := tx.CreateBucketIfNotExists(bucketName); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(promotionBucket)
 		return err
 	}); err != nil {
 		db.Close()
@@ -81,48 +85,53 @@ func (r *Registry) Activate(id string) error {
 		return errNilID
 	}
 	return r.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucketName)
+		return activate(tx, id)
+	})
+}
 
-		// Load the target version to learn its Name.
-		raw := b.Get([]byte(id))
-		if raw == nil {
-			return errNotFound
-		}
-		var target ModelVersion
-		if err := json.Unmarshal(raw, &target); err != nil {
+// activate does the work of Activate inside tx.
+func activate(tx *bolt.Tx, id string) error {
+	b := tx.Bucket(bucketName)
+
+	// Load the target version to learn its Name.
+	raw := b.Get([]byte(id))
+	if raw == nil {
+		return errNotFound
+	}
+	var target ModelVersion
+	if err := json.Unmarshal(raw, &target); err != nil {
+		return err
+	}
+
+	// Scan all versions: deactivate siblings, activate target.
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var mv ModelVersion
+		if err := json.Unmarshal(v, &mv); err != nil {
 			return err
 		}
-
-		// Scan all versions: deactivate siblings, activate target.
-		c := b.Cursor()
-		for k, v := c.First(); k != nil; k, v = c.Next() {
-			var mv ModelVersion
-			if err := json.Unmarshal(v, &mv); err != nil {
+		if mv.Name != target.Name {
+			continue
+		}
+		changed := false
+		if mv.ID == id && !mv.Active {
+			mv.Active = true
+			changed = true
+		} else if mv.ID != id && mv.Active {
+			mv.Active = false
+			changed = true
+		}
+		if changed {
+			data, err := json.Marshal(mv)
+			if err != nil {
 				return err
 			}
-			if mv.Name != target.Name {
-				continue
-			}
-			changed := false
-			if mv.ID == id && !mv.Active {
-				mv.Active = true
-				changed = true
-			} else if mv.ID != id && mv.Active {
-				mv.Active = false
-				changed = true
-			}
-			if changed {
-				data, err := json.Marshal(mv)
-				if err != nil {
-					return err
-				}
-				if err := b.Put(k, data); err != nil {
-					return err
-				}
+			if err := b.Put(k, data); err != nil {
+				return err
 			}
 		}
-		return nil
-	})
+	}
+	return nil
 }
 
 // GetActive returns the currently active version for the given model name.
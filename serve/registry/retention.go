@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// RetentionPolicy decides which model versions GC removes. Active
+// (promoted) versions are always kept. Any other version is removed if
+// either rule applies to it; a zero field disables its rule.
+type RetentionPolicy struct {
+	// KeepLast keeps the KeepLast most recently created versions of each
+	// model name.
+	KeepLast int
+	// TTL removes versions created more than TTL ago, such as experiments
+	// that were never promoted.
+	TTL time.Duration
+}
+
+// GCResult reports what GC removed, or would remove in a dry run.
+type GCResult struct {
+	// Removed lists the collected versions, oldest first.
+	Removed []ModelVersion
+	// Bytes is the size of the files under the Paths of the removed
+	// versions that no kept version shares.
+	Bytes int64
+}
+
+// GC applies policy to every registered version: it deletes the versions
+// the policy does not keep and the files at their Paths, unless a kept
+// version uses the same Path. With dryRun set it only reports what it would
+// remove. Files that cannot be removed are reported in the returned error
+// after the registry entries are gone.
+func (r *Registry) GC(policy RetentionPolicy, dryRun bool) (*GCResult, error) {
+	if policy.KeepLast < 0 || policy.TTL < 0 {
+		return nil, fmt.Errorf("registry: retention policy must not be negative, got KeepLast %d and TTL %v", policy.KeepLast, policy.TTL)
+	}
+	var all []ModelVersion
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(_, v []byte) error {
+			var mv ModelVersion
+			if err := json.Unmarshal(v, &mv); err != nil {
+				return err
+			}
+			all = append(all, mv)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removed, kept := policy.split(all, time.Now())
+	keptPaths := make(map[string]bool, len(kept))
+	for _, mv := range kept {
+		keptPaths[filepath.Clean(mv.Path)] = true
+	}
+	var paths []string
+	for _, mv := range removed {
+		if mv.Path == "" {
+			continue
+		}
+		p := filepath.Clean(mv.Path)
+		if !keptPaths[p] && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+
+	res := &GCResult{Removed: removed}
+	for _, p := range paths {
+		res.Bytes += diskUsage(p)
+	}
+	if dryRun || len(removed) == 0 {
+		return res, nil
+	}
+
+	if err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		for _, mv := range removed {
+			if err := b.Delete([]byte(mv.ID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+// split partitions versions into those policy removes, oldest first, and
+// those it keeps.
+func (p RetentionPolicy) split(versions []ModelVersion, now time.Time) (removed, kept []ModelVersion) {
+	// Newest first within each name, so a version's rank is its position.
+	slices.SortFunc(versions, func(a, b ModelVersion) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			b.CreatedAt.Compare(a.CreatedAt),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	rank := 0
+	for i, mv := range versions {
+		if i > 0 && versions[i-1].Name == mv.Name {
+			rank++
+		} else {
+			rank = 0
+		}
+		expired := p.TTL > 0 && now.Sub(mv.CreatedAt) > p.TTL
+		surplus := p.KeepLast > 0 && rank >= p.KeepLast
+		if !mv.Active && (expired || surplus) {
+			removed = append(removed, mv)
+		} else {
+			kept = append(kept, mv)
+		}
+	}
+	slices.SortFunc(removed, func(a, b ModelVersion) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return removed, kept
+}
+
+// diskUsage returns the total size of the regular files at path, or 0 if
+// it does not exist.
+func diskUsage(path string) int64 {
+	var n int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				n += info.Size()
+			}
+		}
+		return nil
+	})
+	return n
+}
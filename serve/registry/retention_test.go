@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGC(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name string, size int) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, make([]byte, size), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	shared := write("shared.gguf", 7)
+	versions := []ModelVersion{
+		// gemma: v4 is newest, v1 is promoted, v2 and v3 fall outside
+		// KeepLast, and v3 shares its file with the kept v4.
+		{ID: "gemma-v1", Name: "gemma", Path: write("gemma-v1.gguf", 10), CreatedAt: now.Add(-4 * time.Hour)},
+		{ID: "gemma-v2", Name: "gemma", Path: write("gemma-v2.gguf", 20), CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "gemma-v3", Name: "gemma", Path: shared, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "gemma-v4", Name: "gemma", Path: shared, CreatedAt: now.Add(-1 * time.Hour)},
+		// exp-old is past the TTL; exp-new is not.
+		{ID: "exp-old", Name: "exp", Path: write("exp-old.gguf", 30), CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "exp-new", Name: "exp", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	r := newTestRegistry(t)
+	for _, mv := range versions {
+		if err := r.Register(mv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Activate("gemma-v1"); err != nil {
+		t.Fatal(err)
+	}
+	policy := RetentionPolicy{KeepLast: 1, TTL: 24 * time.Hour}
+
+	ids := func(res *GCResult) []string {
+		var out []string
+		for _, mv := range res.Removed {
+			out = append(out, mv.ID)
+		}
+		return out
+	}
+	wantIDs := []string{"exp-old", "gemma-v2", "gemma-v3"}
+
+	res, err := r.GC(policy, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !slices.Equal(got, wantIDs) || res.Bytes != 50 {
+		t.Fatalf("dry run = %v, %d bytes; want %v, 50 bytes", got, res.Bytes, wantIDs)
+	}
+	if all, _ := r.List("gemma"); len(all) != 4 {
+		t.Fatalf("dry run removed versions: %d gemma versions left", len(all))
+	}
+
+	res, err = r.GC(policy, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !slices.Equal(got, wantIDs) {
+		t.Fatalf("GC removed %v, want %v", got, wantIDs)
+	}
+	for _, name := range []string{"gemma-v2.gguf", "exp-old.gguf"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s not deleted: %v", name, err)
+		}
+	}
+	for _, name := range []string{"gemma-v1.gguf", "shared.gguf"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s deleted: %v", name, err)
+		}
+	}
+	left, _ := r.List("gemma")
+	if len(left) != 2 {
+		t.Errorf("%d gemma versions left, want 2", len(left))
+	}
+
+	// The policy is now satisfied.
+	if res, err := r.GC(policy, false); err != nil || len(res.Removed) != 0 {
+		t.Errorf("second GC = %v, %v; want nothing removed", ids(res), err)
+	}
+	if _, err := r.GC(RetentionPolicy{KeepLast: -1}, true); err == nil {
+		t.Error("negative KeepLast accepted")
+	}
+}
@@ -0,0 +1,301 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestLogEntry is one structured request log record. Every request gets
+// an entry with its latency and outcome; payloads are captured only for
+// sampled requests, after redaction.
+type RequestLogEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Model     string    `json:"model,omitempty"`
+	Status    int       `json:"status"`
+	// Outcome is "ok", "client_error", "server_error", or "canceled" when
+	// the client went away before the response completed.
+	Outcome   string  `json:"outcome"`
+	LatencyMS float64 `json:"latency_ms"`
+	Sampled   bool    `json:"sampled"`
+	// Request and Response hold the redacted JSON payloads of sampled
+	// requests. Non-JSON and oversized payloads are omitted; their size is
+	// still recorded. RequestBytes counts the body bytes the handler read.
+	Request       json.RawMessage `json:"request,omitempty"`
+	Response      json.RawMessage `json:"response,omitempty"`
+	RequestBytes  int64           `json:"request_bytes"`
+	ResponseBytes int64           `json:"response_bytes"`
+}
+
+// RequestLogSink receives request log entries. Write is called from request
+// goroutines and must be safe for concurrent use.
+type RequestLogSink interface {
+	Write(e *RequestLogEntry) error
+}
+
+// JSONRequestLogSink writes entries as JSON lines.
+type JSONRequestLogSink struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewJSONRequestLogSink writes JSON lines to w, for example os.Stdout.
+func NewJSONRequestLogSink(w io.Writer) *JSONRequestLogSink {
+	return &JSONRequestLogSink{enc: json.NewEncoder(w)}
+}
+
+// NewFileRequestLogSink appends JSON lines to the file at path, creating it
+// with mode 0600 if needed. Close the sink to close the file.
+func NewFileRequestLogSink(path string) (*JSONRequestLogSink, error) {
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONRequestLogSink{enc: json.NewEncoder(f), closer: f}, nil
+}
+
+// Write implements RequestLogSink.
+func (s *JSONRequestLogSink) Write(e *RequestLogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(e)
+}
+
+// Close closes the underlying file, if the sink owns one.
+func (s *JSONRequestLogSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// RequestLogConfig configures structured request logging.
+type RequestLogConfig struct {
+	// Sinks receive every entry. At least one is required.
+	Sinks []RequestLogSink
+	// SampleRate is the fraction of requests, 0.0–1.0, whose payloads are
+	// captured. Sampling is keyed on the request ID, so a retried request
+	// with the same X-Request-Id gets the same decision.
+	SampleRate float64
+	// RedactFields lists JSON fields replaced with "[REDACTED]" in captured
+	// payloads. A bare name ("content") matches the field at any depth; a
+	// dotted path ("messages.content") matches that path, with array levels
+	// skipped.
+	RedactFields []string
+	// MaxBodyBytes caps the captured size of each payload. Larger payloads
+	// are not captured. Default 64 KiB.
+	MaxBodyBytes int
+}
+
+// redactedValue replaces redacted fields in captured payloads.
+const redactedValue = `"[REDACTED]"`
+
+// WithRequestLog enables structured request logging to cfg.Sinks.
+func WithRequestLog(cfg RequestLogConfig) ServerOption {
+	return func(s *Server) {
+		if cfg.MaxBodyBytes <= 0 {
+			cfg.MaxBodyBytes = 64 << 10
+		}
+		s.requestLog = &cfg
+	}
+}
+
+// requestLogMiddleware emits a RequestLogEntry for every request. It runs
+// inside requestIDMiddleware so entries carry the request ID.
+func (s *Server) requestLogMiddleware(next http.Handler) http.Handler {
+	cfg := s.requestLog
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		e := &RequestLogEntry{
+			Time:      start.UTC(),
+			RequestID: RequestID(r.Context()),
+			Method:    r.Method,
+			Path:      r.URL.EscapedPath(),
+		}
+		e.Sampled = sampleRequest(e.RequestID, cfg.SampleRate)
+
+		body := &countingReader{r: r.Body}
+		r.Body = body
+		rec := &captureRecorder{statusRecorder: statusRecorder{ResponseWriter: w, status: http.StatusOK}}
+		var reqBuf *cappedBuffer
+		if e.Sampled {
+			reqBuf = &cappedBuffer{max: cfg.MaxBodyBytes}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(body, reqBuf), body}
+			rec.buf = &cappedBuffer{max: cfg.MaxBodyBytes}
+		}
+
+		next.ServeHTTP(rec, r)
+
+		e.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+		e.Status = rec.status
+		e.Outcome = requestOutcome(r, rec.status)
+		e.RequestBytes = body.n
+		e.ResponseBytes = rec.n
+		s.modelMu.RLock()
+		if info := s.model.Info(); info != nil {
+			e.Model = info.ID
+		}
+		s.modelMu.RUnlock()
+		if e.Sampled {
+			e.Request = redactJSON(reqBuf.bytes(), cfg.RedactFields)
+			if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+				e.Response = redactJSON(rec.buf.bytes(), cfg.RedactFields)
+			}
+		}
+		for _, sink := range cfg.Sinks {
+			if err := sink.Write(e); err != nil {
+				s.logger.Warn("request log sink failed", "error", err.Error())
+			}
+		}
+	})
+}
+
+// sampleRequest makes a deterministic sampling decision from the request ID.
+func sampleRequest(id string, rate float64) bool {
+	switch {
+	case rate <= 0:
+		return false
+	case rate >= 1:
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return float64(h.Sum64()%10000) < rate*10000
+}
+
+func requestOutcome(r *http.Request, status int) string {
+	switch {
+	case errors.Is(r.Context().Err(), context.Canceled):
+		return "canceled"
+	case status >= 500:
+		return "server_error"
+	case status >= 400:
+		return "client_error"
+	default:
+		return "ok"
+	}
+}
+
+// redactJSON returns data with the configured fields redacted, or nil if
+// data is empty, truncated, or not JSON. Payloads that cannot be parsed are
+// never logged, since their fields cannot be redacted.
+func redactJSON(data []byte, fields []string) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	v = redactValue(v, "", fields)
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func redactValue(v any, path string, fields []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if redactField(k, p, fields) {
+				t[k] = json.RawMessage(redactedValue)
+				continue
+			}
+			t[k] = redactValue(child, p, fields)
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = redactValue(child, path, fields)
+		}
+	}
+	return v
+}
+
+func redactField(name, path string, fields []string) bool {
+	for _, f := range fields {
+		if strings.EqualFold(f, name) || strings.EqualFold(f, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// cappedBuffer keeps the first max bytes written to it and records whether
+// anything was dropped.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		p = p[:max(room, 0)]
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// bytes returns the captured bytes, or nil if the payload was truncated.
+func (b *cappedBuffer) bytes() []byte {
+	if b == nil || b.truncated {
+		return nil
+	}
+	return b.buf.Bytes()
+}
+
+// countingReader counts bytes read from the request body.
+type countingReader struct {
+	r io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) Close() error { return c.r.Close() }
+
+// captureRecorder extends statusRecorder to count and optionally capture
+// the response body.
+type captureRecorder struct {
+	statusRecorder
+	buf *cappedBuffer
+	n   int64
+}
+
+func (r *captureRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.n += int64(n)
+	if r.buf != nil {
+		_, _ = r.buf.Write(p[:n])
+	}
+	return n, err
+}
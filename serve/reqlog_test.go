@@ -0,0 +1,175 @@
+package serve
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memSink collects request log entries in memory.
+type memSink struct {
+	mu      sync.Mutex
+	entries []RequestLogEntry
+}
+
+func (m *memSink) Write(e *RequestLogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, *e)
+	return nil
+}
+
+func serveRecorded(srv *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequestLog_SampledRedacted(t *testing.T) {
+	sink := &memSink{}
+	srv := NewServer(buildTestModel(t), WithRequestLog(RequestLogConfig{
+		Sinks:        []RequestLogSink{sink},
+		SampleRate:   1,
+		RedactFields: []string{"messages.content"},
+	}))
+	// Serve through a recorder so the entry is written before the response
+	// is inspected.
+	resp := serveRecorded(srv, http.MethodPost, "/v1/chat/completions",
+		`{"model":"test-model","messages":[{"role":"user","content":"my secret"}],"max_tokens":2}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.Code)
+	}
+
+	if len(sink.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(sink.entries))
+	}
+	e := sink.entries[0]
+	if e.RequestID != resp.Header().Get("X-Request-Id") || e.RequestID == "" {
+		t.Errorf("RequestID = %q, header = %q", e.RequestID, resp.Header().Get("X-Request-Id"))
+	}
+	if e.Outcome != "ok" || e.Status != http.StatusOK || e.Model != "test-model" || !e.Sampled {
+		t.Errorf("entry = %+v", e)
+	}
+	if strings.Contains(string(e.Request), "my secret") || !strings.Contains(string(e.Request), "[REDACTED]") {
+		t.Errorf("request not redacted: %s", e.Request)
+	}
+	if !strings.Contains(string(e.Request), `"role":"user"`) {
+		t.Errorf("unredacted fields missing: %s", e.Request)
+	}
+	if len(e.Response) == 0 || e.ResponseBytes == 0 || e.RequestBytes == 0 {
+		t.Errorf("response = %s, request_bytes = %d, response_bytes = %d", e.Response, e.RequestBytes, e.ResponseBytes)
+	}
+}
+
+func TestRequestLog_Unsampled(t *testing.T) {
+	sink := &memSink{}
+	srv := NewServer(buildTestModel(t), WithRequestLog(RequestLogConfig{Sinks: []RequestLogSink{sink}}))
+	serveRecorded(srv, http.MethodPost, "/v1/chat/completions", `{"messages":"bad"}`)
+
+	if len(sink.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(sink.entries))
+	}
+	e := sink.entries[0]
+	if e.Sampled || e.Request != nil || e.Response != nil {
+		t.Errorf("unsampled entry captured payloads: %+v", e)
+	}
+	if e.Outcome != "client_error" || e.Status != http.StatusBadRequest || e.Path != "/v1/chat/completions" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		fields []string
+		want   string
+	}{
+		{"bare name at any depth", `{"a":{"token":"x"},"token":"y","b":1}`, []string{"token"},
+			`{"a":{"token":"[REDACTED]"},"b":1,"token":"[REDACTED]"}`},
+		{"path skips arrays", `{"messages":[{"role":"user","content":"hi"}],"content":"keep"}`, []string{"messages.content"},
+			`{"content":"keep","messages":[{"content":"[REDACTED]","role":"user"}]}`},
+		{"case insensitive", `{"API_Key":"k"}`, []string{"api_key"}, `{"API_Key":"[REDACTED]"}`},
+		{"numbers preserved", `{"n":12345678901234567890}`, nil, `{"n":12345678901234567890}`},
+		{"not json", `hello`, nil, ``},
+		{"empty", ``, nil, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactJSON([]byte(tt.data), tt.fields)); got != tt.want {
+				t.Errorf("redactJSON = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLog_OversizedPayloadOmitted(t *testing.T) {
+	b := &cappedBuffer{max: 8}
+	_, _ = b.Write([]byte(`{"a":"0123456789"}`))
+	if got := redactJSON(b.bytes(), nil); got != nil {
+		t.Errorf("truncated payload captured: %s", got)
+	}
+}
+
+func TestSampleRequest(t *testing.T) {
+	if sampleRequest("id", 0) || !sampleRequest("id", 1) {
+		t.Error("rate 0 and 1 must never and always sample")
+	}
+	n := 0
+	for i := range 10000 {
+		id := fmt.Sprintf("req-%d", i)
+		if sampleRequest(id, 0.1) != sampleRequest(id, 0.1) {
+			t.Fatalf("sampling of %s is not deterministic", id)
+		}
+		if sampleRequest(id, 0.1) {
+			n++
+		}
+	}
+	if n < 800 || n > 1200 {
+		t.Errorf("sampled %d of 10000 at rate 0.1", n)
+	}
+}
+
+func TestFileRequestLogSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	sink, err := NewFileRequestLogSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := sink.Write(&RequestLogEntry{RequestID: id, Outcome: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var ids []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e RequestLogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		ids = append(ids, e.RequestID)
+	}
+	if strings.Join(ids, ",") != "a,b" {
+		t.Errorf("ids = %v, want [a b]", ids)
+	}
+}
@@ -0,0 +1,167 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// Quota limits one client. Zero fields are unlimited.
+type Quota struct {
+	RequestsPerSecond float64 // token-bucket refill rate
+	Burst             int     // bucket capacity; defaults to max(1, ceil(RequestsPerSecond))
+	TokensPerDay      int64   // generated tokens per UTC day
+}
+
+// QuotaReason explains why a request was rejected.
+type QuotaReason string
+
+const (
+	QuotaRate   QuotaReason = "rate"
+	QuotaTokens QuotaReason = "tokens"
+)
+
+// QuotaDecision is the result of a quota check.
+type QuotaDecision struct {
+	Allowed    bool
+	Reason     QuotaReason   // set when Allowed is false
+	RetryAfter time.Duration // set when Allowed is false
+}
+
+// QuotaUsage reports a client's token usage for the current UTC day.
+type QuotaUsage struct {
+	TokensToday int64
+	// TokensRemaining is -1 when the client has no daily token limit.
+	TokensRemaining int64
+}
+
+// QuotaManager enforces per-client request rates and daily generation token
+// budgets. Clients are identified by an opaque ID, normally the API key ID,
+// and fall back to the default quota unless overridden with SetQuota.
+//
+// Unlike RateLimiter, which is keyed by client IP and must bound its map
+// against spoofed addresses, QuotaManager is only consulted after
+// authentication, so the number of tracked clients is bounded by the number
+// of valid keys.
+type QuotaManager struct {
+	mu        sync.Mutex
+	def       Quota
+	overrides map[string]Quota
+	clients   map[string]*clientQuota
+	now       func() time.Time
+}
+
+type clientQuota struct {
+	tokens   float64 // request bucket
+	lastSeen time.Time
+	day      time.Time // UTC midnight of the day used belongs to
+	used     int64
+}
+
+// NewQuotaManager creates a QuotaManager applying def to every client
+// without an override.
+func NewQuotaManager(def Quota) *QuotaManager {
+	return &QuotaManager{
+		def:       def,
+		overrides: make(map[string]Quota),
+		clients:   make(map[string]*clientQuota),
+		now:       time.Now,
+	}
+}
+
+// SetQuota overrides the quota for clientID.
+func (q *QuotaManager) SetQuota(clientID string, quota Quota) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.overrides[clientID] = quota
+}
+
+// QuotaFor returns the quota that applies to clientID.
+func (q *QuotaManager) QuotaFor(clientID string) Quota {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.quotaLocked(clientID)
+}
+
+func (q *QuotaManager) quotaLocked(clientID string) Quota {
+	if o, ok := q.overrides[clientID]; ok {
+		return o
+	}
+	return q.def
+}
+
+// AllowRequest consumes one request from clientID's rate bucket. When
+// generation is true the request also needs daily token budget left.
+func (q *QuotaManager) AllowRequest(clientID string, generation bool) QuotaDecision {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	quota := q.quotaLocked(clientID)
+	c := q.clientLocked(clientID, quota, now)
+
+	if generation && quota.TokensPerDay > 0 && c.used >= quota.TokensPerDay {
+		return QuotaDecision{Reason: QuotaTokens, RetryAfter: c.day.Add(24 * time.Hour).Sub(now)}
+	}
+	if quota.RequestsPerSecond <= 0 {
+		return QuotaDecision{Allowed: true}
+	}
+	burst := float64(quotaBurst(quota))
+	c.tokens = min(burst, c.tokens+now.Sub(c.lastSeen).Seconds()*quota.RequestsPerSecond)
+	c.lastSeen = now
+	if c.tokens < 1 {
+		wait := time.Duration((1 - c.tokens) / quota.RequestsPerSecond * float64(time.Second))
+		return QuotaDecision{Reason: QuotaRate, RetryAfter: wait}
+	}
+	c.tokens--
+	return QuotaDecision{Allowed: true}
+}
+
+// RecordTokens charges n generated tokens to clientID's daily budget. A
+// request admitted with budget left may overshoot it; the next generation
+// request is then rejected until the budget resets at UTC midnight.
+func (q *QuotaManager) RecordTokens(clientID string, n int64) {
+	if n <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := q.clientLocked(clientID, q.quotaLocked(clientID), q.now())
+	c.used += n
+}
+
+// Usage returns clientID's token usage for the current UTC day.
+func (q *QuotaManager) Usage(clientID string) QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	quota := q.quotaLocked(clientID)
+	c := q.clientLocked(clientID, quota, q.now())
+	u := QuotaUsage{TokensToday: c.used, TokensRemaining: -1}
+	if quota.TokensPerDay > 0 {
+		u.TokensRemaining = max(0, quota.TokensPerDay-c.used)
+	}
+	return u
+}
+
+// clientLocked returns clientID's state, creating it with a full bucket and
+// resetting the daily count when the UTC day has changed. Callers must hold
+// q.mu.
+func (q *QuotaManager) clientLocked(clientID string, quota Quota, now time.Time) *clientQuota {
+	day := now.UTC().Truncate(24 * time.Hour)
+	c, ok := q.clients[clientID]
+	if !ok {
+		c = &clientQuota{tokens: float64(quotaBurst(quota)), lastSeen: now, day: day}
+		q.clients[clientID] = c
+	}
+	if !c.day.Equal(day) {
+		c.day = day
+		c.used = 0
+	}
+	return c
+}
+
+func quotaBurst(quota Quota) int {
+	if quota.Burst > 0 {
+		return quota.Burst
+	}
+	return max(1, int(quota.RequestsPerSecond+0.999999))
+}
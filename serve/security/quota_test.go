@@ -0,0 +1,87 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestQuotaManager(def Quota, now *time.Time) *QuotaManager {
+	q := NewQuotaManager(def)
+	q.now = func() time.Time { return *now }
+	return q
+}
+
+func TestQuotaManager_RequestRate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	q := newTestQuotaManager(Quota{RequestsPerSecond: 2, Burst: 2}, &now)
+
+	for i := range 2 {
+		if d := q.AllowRequest("a", false); !d.Allowed {
+			t.Fatalf("request %d rejected: %+v", i, d)
+		}
+	}
+	d := q.AllowRequest("a", false)
+	if d.Allowed || d.Reason != QuotaRate {
+		t.Fatalf("third request = %+v, want rate rejection", d)
+	}
+	if d.RetryAfter != 500*time.Millisecond {
+		t.Errorf("RetryAfter = %v, want 500ms", d.RetryAfter)
+	}
+
+	// Other clients have their own bucket.
+	if d := q.AllowRequest("b", false); !d.Allowed {
+		t.Errorf("client b rejected: %+v", d)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if d := q.AllowRequest("a", false); !d.Allowed {
+		t.Errorf("after refill: %+v", d)
+	}
+}
+
+func TestQuotaManager_TokensPerDay(t *testing.T) {
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	q := newTestQuotaManager(Quota{TokensPerDay: 100}, &now)
+
+	if d := q.AllowRequest("a", true); !d.Allowed {
+		t.Fatalf("first request rejected: %+v", d)
+	}
+	q.RecordTokens("a", 120)
+	if u := q.Usage("a"); u.TokensToday != 120 || u.TokensRemaining != 0 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	d := q.AllowRequest("a", true)
+	if d.Allowed || d.Reason != QuotaTokens || d.RetryAfter != 6*time.Hour {
+		t.Errorf("over budget = %+v, want tokens rejection retrying in 6h", d)
+	}
+	// Non-generation requests are not charged against the token budget.
+	if d := q.AllowRequest("a", false); !d.Allowed {
+		t.Errorf("non-generation request rejected: %+v", d)
+	}
+
+	now = now.Add(6 * time.Hour)
+	if d := q.AllowRequest("a", true); !d.Allowed {
+		t.Errorf("next day rejected: %+v", d)
+	}
+	if u := q.Usage("a"); u.TokensToday != 0 || u.TokensRemaining != 100 {
+		t.Errorf("usage after reset = %+v", u)
+	}
+}
+
+func TestQuotaManager_Overrides(t *testing.T) {
+	now := time.Now()
+	q := newTestQuotaManager(Quota{TokensPerDay: 10}, &now)
+	q.SetQuota("vip", Quota{})
+
+	q.RecordTokens("vip", 1000)
+	if d := q.AllowRequest("vip", true); !d.Allowed {
+		t.Errorf("unlimited override rejected: %+v", d)
+	}
+	if u := q.Usage("vip"); u.TokensRemaining != -1 {
+		t.Errorf("TokensRemaining = %d, want -1", u.TokensRemaining)
+	}
+	if got := q.QuotaFor("other"); got.TokensPerDay != 10 {
+		t.Errorf("default quota = %+v", got)
+	}
+}
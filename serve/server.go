@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	_ "embed"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -31,6 +32,7 @@ type Server struct {
 	mux        *http.ServeMux
 	batch      *BatchScheduler // optional; nil means direct calls
 	unloaded   atomic.Bool     // true after DELETE /v1/models/:id
+	draining   atomic.Bool     // true while the admin API drains traffic
 	// modelMu serializes handler access to model against DELETE /v1/models/:id.
 	// Handlers that touch the model take RLock for the duration of their work;
 	// delete takes Lock before flipping unloaded and closing the model. This
@@ -45,12 +47,16 @@ type Server struct {
 	classifyMetrics *ClassifyMetrics
 	guardMetrics    *GuardMetrics
 	collector       runtime.Collector
-	gpus            []int                 // GPU IDs to distribute model across
-	apiKey          string                // optional; enables Bearer token auth
-	keyStore        *security.KeyStore    // optional; enables scope-based authorization
-	rateLimiter     *security.RateLimiter // optional; enables per-IP rate limiting
-	maxTokens       int                   // server-side upper bound for max_tokens (default 8192)
-	adapterCache    *AdapterCacheHandle   // optional; enables per-request LoRA adapter selection
+	gpus            []int                  // GPU IDs to distribute model across
+	apiKey          string                 // optional; enables Bearer token auth
+	keyStore        *security.KeyStore     // optional; enables scope-based authorization
+	rateLimiter     *security.RateLimiter  // optional; enables per-IP rate limiting
+	maxTokens       int                    // server-side upper bound for max_tokens (default 8192)
+	adapterCache    *AdapterCacheHandle    // optional; enables per-request LoRA adapter selection
+	requestLog      *RequestLogConfig      // optional; enables structured request logging
+	quotas          *security.QuotaManager // optional; enables per-client quotas
+	sessionConfig   *SessionConfig         // optional; enables /v1/sessions
+	sessions        *sessionStore          // non-nil iff sessionConfig is set
 }
 
 // ServerOption configures the server.
@@ -169,26 +175,15 @@ func NewServer(m *inference.Model, opts ...ServerOption) *Server {
 	// Auto-wire batch handler to use GenerateBatch when a BatchScheduler
 	// is attached but no handler has been configured.
 	if s.batch != nil && s.batch.config.Handler == nil {
-		s.batch.config.Handler = func(ctx context.Context, reqs []BatchRequest) []BatchResult {
-			prompts := make([]string, len(reqs))
-			for i, r := range reqs {
-				prompts[i] = r.Prompt
-			}
-			outputs, err := s.model.GenerateBatch(ctx, prompts)
-			results := make([]BatchResult, len(reqs))
-			for i := range results {
-				if err != nil {
-					results[i] = BatchResult{Err: err}
-				} else {
-					results[i] = BatchResult{Value: outputs[i]}
-				}
-			}
-			return results
-		}
+		s.batch.config.Handler = generateBatch
 	}
 	s.metrics = NewServerMetrics(s.collector)
 	s.classifyMetrics = NewClassifyMetrics(s.collector)
 	s.guardMetrics = NewGuardMetrics(s.collector)
+	if s.sessionConfig != nil {
+		s.sessions = newSessionStore(*s.sessionConfig, NewSessionMetrics(s.collector))
+		s.sessions.start()
+	}
 	s.mux.HandleFunc("POST /v1/chat/completions", s.recoveryMiddleware(s.handleChatCompletions))
 	s.mux.HandleFunc("POST /v1/completions", s.recoveryMiddleware(s.handleCompletions))
 	s.mux.HandleFunc("POST /v1/embeddings", s.recoveryMiddleware(s.handleEmbeddings))
@@ -200,6 +195,10 @@ func NewServer(m *inference.Model, opts ...ServerOption) *Server {
 	s.mux.HandleFunc("POST /v1/guard", s.recoveryMiddleware(s.handleGuard))
 	s.mux.HandleFunc("POST /v1/guard/batch", s.recoveryMiddleware(s.handleGuardBatch))
 	s.mux.HandleFunc("POST /v1/guard/scan", s.recoveryMiddleware(s.handleGuardScan))
+	s.mux.HandleFunc("POST /v1/sessions", s.recoveryMiddleware(s.handleSessionCreate))
+	s.mux.HandleFunc("GET /v1/sessions/{id}", s.recoveryMiddleware(s.handleSessionGet))
+	s.mux.HandleFunc("DELETE /v1/sessions/{id}", s.recoveryMiddleware(s.handleSessionDelete))
+	s.mux.HandleFunc("POST /v1/sessions/{id}/chat/completions", s.recoveryMiddleware(s.handleSessionChat))
 	s.mux.HandleFunc("GET /healthz", s.recoveryMiddleware(s.handleHealthz))
 	s.mux.HandleFunc("GET /readyz", s.recoveryMiddleware(s.handleReadyz))
 	s.mux.HandleFunc("GET /openapi.yaml", s.recoveryMiddleware(handleOpenAPISpec))
@@ -209,15 +208,22 @@ func NewServer(m *inference.Model, opts ...ServerOption) *Server {
 
 // Handler returns the HTTP handler for this server.
 func (s *Server) Handler() http.Handler {
-	var h http.Handler = s.mux
+	var h http.Handler = s.drainMiddleware(s.mux)
+	if s.quotas != nil {
+		h = s.quotaMiddleware(h)
+	}
 	if s.apiKey != "" || s.keyStore != nil {
 		h = s.authMiddleware(h)
 	}
 	if s.rateLimiter != nil {
 		h = s.rateLimitMiddleware(h)
 	}
+	if s.requestLog != nil {
+		h = s.requestLogMiddleware(h)
+	}
 	h = s.requestIDMiddleware(h)
 	h = s.logMiddleware(h)
+	h = s.traceMiddleware(h)
 	return s.securityHeadersMiddleware(h)
 }
 
@@ -300,7 +306,7 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 					return
 				}
 			}
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIDKey{}, key.ID)))
 			return
 		}
 
@@ -309,17 +315,21 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			writeError(w, http.StatusUnauthorized, "invalid API key")
 			return
 		}
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIDKey{}, staticKeyClientID)))
 	})
 }
 
 // requiredScope returns the minimum scope required for the given HTTP method and path.
-// DELETE /v1/models requires ScopeAdmin. POST /v1/* requires ScopeInference.
-// All /v1/ routes require at least ScopeReadOnly. Returns empty string for non-/v1/ paths.
+// DELETE /v1/models requires ScopeAdmin. POST /v1/* and DELETE /v1/sessions
+// require ScopeInference. All /v1/ routes require at least ScopeReadOnly.
+// Returns empty string for non-/v1/ paths.
 func requiredScope(method, path string) security.Scope {
 	if method == http.MethodDelete && strings.HasPrefix(path, "/v1/models") {
 		return security.ScopeAdmin
 	}
+	if method == http.MethodDelete && strings.HasPrefix(path, "/v1/sessions") {
+		return security.ScopeInference
+	}
 	if method == http.MethodPost && strings.HasPrefix(path, "/v1/") {
 		return security.ScopeInference
 	}
@@ -332,6 +342,20 @@ func requiredScope(method, path string) security.Scope {
 	return ""
 }
 
+// drainMiddleware rejects new /v1/ requests with 503 while the server is
+// draining, so a load balancer can move traffic away before a model swap or
+// shutdown. Requests already in flight are unaffected.
+func (s *Server) drainMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() && strings.HasPrefix(r.URL.Path, "/v1/") {
+			w.Header().Set("Retry-After", "5")
+			writeError(w, http.StatusServiceUnavailable, "server is draining")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // rateLimitMiddleware rejects requests that exceed the configured rate limit
 // for the client IP. Returns 429 Too Many Requests when the limit is exceeded.
 func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
@@ -382,9 +406,11 @@ func (s *Server) logMiddleware(next http.Handler) http.Handler {
 		}
 
 		modelID := ""
+		s.modelMu.RLock()
 		if info := s.model.Info(); info != nil {
 			modelID = info.ID
 		}
+		s.modelMu.RUnlock()
 
 		fields := []string{
 			"method", r.Method,
@@ -432,5 +458,44 @@ func (s *Server) Close(_ context.Context) error {
 	if s.rateLimiter != nil {
 		s.rateLimiter.Stop()
 	}
+	if s.sessions != nil {
+		s.sessions.Stop()
+	}
 	return nil
 }
+
+// generateBatch is the auto-wired BatchHandler. It runs GenerateBatch on the
+// model each request was submitted against rather than reading s.model, which
+// a concurrent swap may replace; the submitting handler holds modelMu.RLock
+// until its result arrives, so that model stays open for the whole batch.
+func generateBatch(ctx context.Context, reqs []BatchRequest) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	byModel := make(map[*inference.Model][]int)
+	var models []*inference.Model
+	for i, r := range reqs {
+		if r.model == nil {
+			results[i] = BatchResult{Err: errors.New("model not available")}
+			continue
+		}
+		if _, ok := byModel[r.model]; !ok {
+			models = append(models, r.model)
+		}
+		byModel[r.model] = append(byModel[r.model], i)
+	}
+	for _, m := range models {
+		idx := byModel[m]
+		prompts := make([]string, len(idx))
+		for j, i := range idx {
+			prompts[j] = reqs[i].Prompt
+		}
+		outputs, err := m.GenerateBatch(ctx, prompts)
+		for j, i := range idx {
+			if err != nil {
+				results[i] = BatchResult{Err: err}
+			} else {
+				results[i] = BatchResult{Value: outputs[j]}
+			}
+		}
+	}
+	return results
+}
@@ -2371,7 +2371,7 @@ func TestStreamChatCompletion_NoFlusher(t *testing.T) {
 	srv := NewServer(mdl)
 	rec := newNoFlushResponseRecorder()
 
-	srv.streamChatCompletion(rec, context.Background(), nil, nil)
+	srv.streamChatCompletion(rec, context.Background(), nil, nil, nil)
 
 	if rec.code != http.StatusInternalServerError {
 		t.Errorf("status = %d, want %d", rec.code, http.StatusInternalServerError)
@@ -2386,7 +2386,7 @@ func TestStreamCompletion_NoFlusher(t *testing.T) {
 	srv := NewServer(mdl)
 	rec := newNoFlushResponseRecorder()
 
-	srv.streamCompletion(rec, context.Background(), "hello", nil)
+	srv.streamCompletion(rec, context.Background(), "hello", nil, nil)
 
 	if rec.code != http.StatusInternalServerError {
 		t.Errorf("status = %d, want %d", rec.code, http.StatusInternalServerError)
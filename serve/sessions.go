@@ -0,0 +1,560 @@
+package serve
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zerfoo/zerfoo/generate"
+	"github.com/zerfoo/zerfoo/inference"
+	"github.com/zerfoo/ztensor/metrics/runtime"
+)
+
+// Session defaults used when SessionConfig leaves a field zero.
+const (
+	defaultSessionIdleTimeout = 10 * time.Minute
+	defaultMaxSessions        = 64
+)
+
+// SessionConfig configures stateful chat sessions.
+type SessionConfig struct {
+	// IdleTimeout is how long a session may go without a request before it
+	// is evicted. Default 10 minutes.
+	IdleTimeout time.Duration
+	// MaxSessions caps the number of live sessions. Each session holds its
+	// own KV cache, so this bounds the memory sessions can pin. When the
+	// cap is reached, creating a session evicts the least recently used
+	// idle one. Default 64.
+	MaxSessions int
+}
+
+// WithSessions enables the /v1/sessions endpoints. A session keeps its
+// conversation and KV cache on the server between requests, so each turn
+// sends only its new messages and prefills only their tokens instead of
+// the whole history. A session is private to the client that created it
+// (see [ClientID]): requests with any other key get 404 Not Found.
+func WithSessions(cfg SessionConfig) ServerOption {
+	return func(s *Server) {
+		if cfg.IdleTimeout <= 0 {
+			cfg.IdleTimeout = defaultSessionIdleTimeout
+		}
+		if cfg.MaxSessions <= 0 {
+			cfg.MaxSessions = defaultMaxSessions
+		}
+		s.sessionConfig = &cfg
+	}
+}
+
+// SessionCreateRequest is the request body for POST /v1/sessions.
+type SessionCreateRequest struct {
+	System string `json:"system,omitempty"`
+}
+
+// SessionObject describes a chat session.
+type SessionObject struct {
+	ID           string `json:"id"`
+	Object       string `json:"object"`
+	Created      int64  `json:"created"`
+	LastUsed     int64  `json:"last_used"`
+	Model        string `json:"model"`
+	Messages     int    `json:"messages"`
+	CachedTokens int    `json:"cached_tokens"`
+}
+
+// SessionDeleteResponse is the response body for DELETE /v1/sessions/{id}.
+type SessionDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// SessionMetrics records session Prometheus metrics.
+type SessionMetrics struct {
+	active       runtime.GaugeMetric
+	created      runtime.CounterMetric
+	evicted      runtime.CounterMetric
+	reusedTokens runtime.CounterMetric
+}
+
+// NewSessionMetrics creates session metrics backed by the given collector.
+func NewSessionMetrics(c runtime.Collector) *SessionMetrics {
+	return &SessionMetrics{
+		active:       c.Gauge("sessions_active"),
+		created:      c.Counter("sessions_created_total"),
+		evicted:      c.Counter("sessions_evicted_total"),
+		reusedTokens: c.Counter("session_reused_tokens_total"),
+	}
+}
+
+// errSessionsFull is returned when every session slot is taken by a session
+// with a request in flight.
+var errSessionsFull = errors.New("session limit reached")
+
+// errSessionBusy is returned when a session already has a request in flight.
+var errSessionBusy = errors.New("session has a request in flight")
+
+// chatSession is one live session. owner is the [ClientID] of the request
+// that created it; requests from any other client see no such session.
+type chatSession struct {
+	id      string
+	owner   string
+	created time.Time
+	chat    *inference.ChatSession
+
+	// Guarded by sessionStore.mu. messages and cachedTokens are refreshed
+	// when a request releases the session, so describing a session never
+	// waits for a turn in progress.
+	lastUsed     time.Time
+	busy         bool
+	messages     int
+	cachedTokens int
+}
+
+// sessionStore holds the live sessions and evicts idle ones.
+type sessionStore struct {
+	cfg     SessionConfig
+	metrics *SessionMetrics
+
+	mu       sync.Mutex
+	sessions map[string]*chatSession
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newSessionStore(cfg SessionConfig, metrics *SessionMetrics) *sessionStore {
+	return &sessionStore{
+		cfg:      cfg,
+		metrics:  metrics,
+		sessions: make(map[string]*chatSession),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// start runs the idle sweeper until Stop.
+func (st *sessionStore) start() {
+	go func() {
+		defer close(st.done)
+		ticker := time.NewTicker(max(st.cfg.IdleTimeout/4, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				st.sweep(time.Now())
+			case <-st.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the idle sweeper.
+func (st *sessionStore) Stop() {
+	close(st.stop)
+	<-st.done
+}
+
+// add registers a new session owned by owner, evicting the least recently
+// used idle session if the store is full.
+func (st *sessionStore) add(chat *inference.ChatSession, owner string) (*chatSession, error) {
+	now := time.Now()
+	sess := &chatSession{
+		id:       newSessionID(),
+		owner:    owner,
+		created:  now,
+		chat:     chat,
+		lastUsed: now,
+		messages: chat.Conversation().Len(),
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if len(st.sessions) >= st.cfg.MaxSessions {
+		var lru *chatSession
+		for _, c := range st.sessions {
+			if !c.busy && (lru == nil || c.lastUsed.Before(lru.lastUsed)) {
+				lru = c
+			}
+		}
+		if lru == nil {
+			return nil, errSessionsFull
+		}
+		st.evictLocked(lru.id)
+	}
+	st.sessions[sess.id] = sess
+	st.metrics.created.Inc()
+	st.metrics.active.Set(float64(len(st.sessions)))
+	return sess, nil
+}
+
+// lookupLocked returns the session with the given id if owner owns it, or
+// nil. st.mu must be held.
+func (st *sessionStore) lookupLocked(id, owner string) *chatSession {
+	sess, ok := st.sessions[id]
+	if !ok || sess.owner != owner {
+		return nil
+	}
+	return sess
+}
+
+// get returns the session with the given id owned by owner, or nil.
+func (st *sessionStore) get(id, owner string) *chatSession {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.lookupLocked(id, owner)
+}
+
+// acquire marks the session busy for the duration of one request. It
+// returns nil if owner has no session with the given id, and fails with
+// errSessionBusy if another request holds it.
+func (st *sessionStore) acquire(id, owner string) (*chatSession, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess := st.lookupLocked(id, owner)
+	if sess == nil {
+		return nil, nil
+	}
+	if sess.busy {
+		return nil, errSessionBusy
+	}
+	sess.busy = true
+	return sess, nil
+}
+
+// release ends a request started by acquire.
+func (st *sessionStore) release(sess *chatSession) {
+	messages, cached := sess.chat.Conversation().Len(), sess.chat.CachedTokens()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess.busy = false
+	sess.lastUsed = time.Now()
+	sess.messages = messages
+	sess.cachedTokens = cached
+}
+
+// remove deletes a session. It reports false if owner has no session with
+// the given id.
+func (st *sessionStore) remove(id, owner string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.lookupLocked(id, owner) == nil {
+		return false
+	}
+	delete(st.sessions, id)
+	st.metrics.active.Set(float64(len(st.sessions)))
+	return true
+}
+
+// sweep evicts every idle session last used before now - IdleTimeout.
+func (st *sessionStore) sweep(now time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for id, sess := range st.sessions {
+		if !sess.busy && now.Sub(sess.lastUsed) > st.cfg.IdleTimeout {
+			st.evictLocked(id)
+		}
+	}
+}
+
+// clear drops every session. Sessions are bound to the model that created
+// them, so the server clears the store when the model is swapped or
+// unloaded.
+func (st *sessionStore) clear() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for id := range st.sessions {
+		st.evictLocked(id)
+	}
+}
+
+// evictLocked drops a session and counts the eviction. st.mu must be held.
+func (st *sessionStore) evictLocked(id string) {
+	delete(st.sessions, id)
+	st.metrics.evicted.Inc()
+	st.metrics.active.Set(float64(len(st.sessions)))
+}
+
+// newSessionID returns a random session identifier.
+func newSessionID() string {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	return "sess_" + hex.EncodeToString(buf[:])
+}
+
+// sessionObject describes sess. s.modelMu must be held for reading.
+func (s *Server) sessionObject(sess *chatSession) SessionObject {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+
+	return SessionObject{
+		ID:           sess.id,
+		Object:       "chat.session",
+		Created:      sess.created.Unix(),
+		LastUsed:     sess.lastUsed.Unix(),
+		Model:        s.buildModelObject().ID,
+		Messages:     sess.messages,
+		CachedTokens: sess.cachedTokens,
+	}
+}
+
+// sessionsAvailable writes an error and returns false if sessions are not
+// configured or no model is loaded. s.modelMu must be held for reading.
+func (s *Server) sessionsAvailable(w http.ResponseWriter) bool {
+	if s.unloaded.Load() {
+		writeError(w, http.StatusNotFound, "model not available")
+		return false
+	}
+	if s.sessions == nil {
+		writeError(w, http.StatusNotImplemented, "sessions are not configured")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleSessionCreate(w http.ResponseWriter, r *http.Request) {
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
+	if !s.sessionsAvailable(w) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+	var req SessionCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if isMaxBytesError(err) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		s.logger.Debug("invalid request body", "error", err.Error())
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sess, err := s.sessions.add(s.model.NewChatSession(req.System), ClientID(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.sessionObject(sess))
+}
+
+func (s *Server) handleSessionGet(w http.ResponseWriter, r *http.Request) {
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
+	if !s.sessionsAvailable(w) {
+		return
+	}
+
+	id := r.PathValue("id")
+	sess := s.sessions.get(id, ClientID(r.Context()))
+	if sess == nil {
+		writeError(w, http.StatusNotFound, "session '"+id+"' not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.sessionObject(sess))
+}
+
+func (s *Server) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
+	if !s.sessionsAvailable(w) {
+		return
+	}
+
+	id := r.PathValue("id")
+	if !s.sessions.remove(id, ClientID(r.Context())) {
+		writeError(w, http.StatusNotFound, "session '"+id+"' not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, SessionDeleteResponse{
+		ID:      id,
+		Object:  "chat.session",
+		Deleted: true,
+	})
+}
+
+// handleSessionChat runs one chat turn in a session. The request has the
+// shape of a chat completion request, but messages holds only the turn's
+// new messages; the session supplies the history.
+func (s *Server) handleSessionChat(w http.ResponseWriter, r *http.Request) {
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
+	if !s.sessionsAvailable(w) {
+		return
+	}
+
+	id := r.PathValue("id")
+	sess, err := s.sessions.acquire(id, ClientID(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if sess == nil {
+		writeError(w, http.StatusNotFound, "session '"+id+"' not found")
+		return
+	}
+	defer s.sessions.release(sess)
+
+	r.Body = http.MaxBytesReader(w, r.Body, 10<<20) // 10 MB
+	var req ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if isMaxBytesError(err) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		s.logger.Debug("invalid request body", "error", err.Error())
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages array is required")
+		return
+	}
+	if err := validateSamplingParams(req.Temperature, req.TopP, req.TopK); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateMaxTokens(req.MaxTokens); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateStop(req.Stop); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.MaxTokens != nil && *req.MaxTokens > s.maxTokens {
+		clamped := s.maxTokens
+		req.MaxTokens = &clamped
+	}
+
+	msgs := make([]inference.Message, len(req.Messages))
+	for i, m := range req.Messages {
+		if len(m.ImageURLs) > 0 {
+			writeError(w, http.StatusBadRequest, "image inputs are not supported in sessions")
+			return
+		}
+		msgs[i] = inference.Message{Role: m.Role, Content: m.Content}
+	}
+
+	opts := buildGenerationOptions(samplingParams{
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		TopK:        req.TopK,
+		MaxTokens:   req.MaxTokens,
+		Seed:        req.Seed,
+		Stop:        req.Stop,
+	})
+
+	if req.Stream {
+		s.streamSessionChat(w, r, sess, msgs, req.StreamOptions, opts)
+		return
+	}
+
+	start := time.Now()
+	resp, err := sess.chat.Send(r.Context(), msgs, opts...)
+	if err != nil {
+		writeError(w, inferenceErrorStatus(err), s.sanitizeError(err))
+		return
+	}
+	reused := sess.chat.ReusedTokens()
+	s.sessions.metrics.reusedTokens.Add(int64(reused))
+	s.metrics.RecordRequest(resp.CompletionTokens, time.Since(start))
+	s.chargeTokens(r.Context(), resp.CompletionTokens)
+
+	usage := newUsageInfo(resp.PromptTokens, resp.CompletionTokens)
+	usage.PromptTokensDetails = &PromptTokensDetails{CachedTokens: reused}
+	writeJSON(w, http.StatusOK, ChatCompletionResponse{
+		ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   s.buildModelObject().ID,
+		Choices: []ChatCompletionChoice{{
+			Index:        0,
+			Message:      ChatMessage{Role: "assistant", Content: resp.Content},
+			FinishReason: "stop",
+		}},
+		Usage: *usage,
+	})
+}
+
+// streamSessionChat is the SSE counterpart of handleSessionChat.
+func (s *Server) streamSessionChat(w http.ResponseWriter, r *http.Request, sess *chatSession, msgs []inference.Message, streamOpts *StreamOptions, opts []inference.GenerateOption) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	id := fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	created := time.Now().Unix()
+	modelID := s.buildModelObject().ID
+	chunk := func(delta ChatDelta, finish *string) ChatCompletionChunk {
+		return ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   modelID,
+			Choices: []ChatCompletionChunkChoice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	writeSSE(w, flusher, chunk(ChatDelta{Role: "assistant"}, nil))
+
+	// The prompt is rendered before SendStream records the turn, so usage
+	// reports the same prompt the model saw.
+	promptTokens := 0
+	if streamOpts != nil && streamOpts.IncludeUsage {
+		if prompt, err := s.model.RenderChat(append(sess.chat.Conversation().Messages(), msgs...)); err == nil {
+			promptTokens = s.countTokens(prompt)
+		}
+	}
+
+	// The usage chunk and [DONE] are written after SendStream returns: the
+	// session cannot report its reused tokens while the turn is running.
+	completionTokens := 0
+	err := sess.chat.SendStream(r.Context(), msgs, generate.TokenStreamFunc(func(token string, done bool) error {
+		if done {
+			writeSSE(w, flusher, chunk(ChatDelta{}, &finishReasonStop))
+			return nil
+		}
+		completionTokens++
+		writeSSE(w, flusher, chunk(ChatDelta{Content: token}, nil))
+		return nil
+	}), opts...)
+	s.chargeTokens(r.Context(), completionTokens)
+	if err != nil {
+		writeSSEError(w, flusher, s.sanitizeError(err))
+		return
+	}
+
+	reused := sess.chat.ReusedTokens()
+	s.sessions.metrics.reusedTokens.Add(int64(reused))
+	if streamOpts != nil && streamOpts.IncludeUsage {
+		usage := newUsageInfo(promptTokens, completionTokens)
+		usage.PromptTokensDetails = &PromptTokensDetails{CachedTokens: reused}
+		writeSSE(w, flusher, ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   modelID,
+			Choices: []ChatCompletionChunkChoice{},
+			Usage:   usage,
+		})
+	}
+	_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
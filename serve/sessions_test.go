@@ -0,0 +1,227 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/serve/security"
+	"github.com/zerfoo/ztensor/metrics/runtime"
+)
+
+func createSession(t *testing.T, url string) SessionObject {
+	t.Helper()
+	resp := doPost(t, url+"/v1/sessions", "application/json", `{"system":"be brief"}`)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201", resp.StatusCode)
+	}
+	var obj SessionObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestSessions_Lifecycle(t *testing.T) {
+	srv := NewServer(buildTestModel(t), WithSessions(SessionConfig{}))
+	defer func() { _ = srv.Close(context.Background()) }()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	obj := createSession(t, ts.URL)
+	if obj.Object != "chat.session" || obj.Messages != 1 || obj.Model != "test-model" {
+		t.Fatalf("created session = %+v", obj)
+	}
+
+	for _, content := range []string{"hello", "world"} {
+		body := `{"messages":[{"role":"user","content":"` + content + `"}],"max_tokens":5}`
+		resp := doPost(t, ts.URL+"/v1/sessions/"+obj.ID+"/chat/completions", "application/json", body)
+		var result ChatCompletionResponse
+		err := json.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || err != nil {
+			t.Fatalf("turn %q: status = %d, decode error = %v", content, resp.StatusCode, err)
+		}
+		if result.Usage.PromptTokensDetails == nil {
+			t.Errorf("turn %q: usage has no prompt_tokens_details", content)
+		}
+	}
+
+	resp := doGet(t, ts.URL+"/v1/sessions/"+obj.ID)
+	var got SessionObject
+	err := json.NewDecoder(resp.Body).Decode(&got)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || err != nil {
+		t.Fatalf("get: status = %d, decode error = %v", resp.StatusCode, err)
+	}
+	if got.Messages != 5 {
+		t.Errorf("Messages = %d, want 5 (system plus two turns)", got.Messages)
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodDelete, ts.URL+"/v1/sessions/"+obj.ID, http.NoBody)
+	del, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = del.Body.Close()
+	if del.StatusCode != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", del.StatusCode)
+	}
+
+	resp = doPost(t, ts.URL+"/v1/sessions/"+obj.ID+"/chat/completions", "application/json",
+		`{"messages":[{"role":"user","content":"hello"}]}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("turn after delete: status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestSessions_Stream(t *testing.T) {
+	srv := NewServer(buildTestModel(t), WithSessions(SessionConfig{}))
+	defer func() { _ = srv.Close(context.Background()) }()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	obj := createSession(t, ts.URL)
+	body := `{"messages":[{"role":"user","content":"hello"}],"max_tokens":5,"stream":true,"stream_options":{"include_usage":true}}`
+	resp := doPost(t, ts.URL+"/v1/sessions/"+obj.ID+"/chat/completions", "application/json", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, _ := io.ReadAll(resp.Body)
+	if !strings.HasSuffix(string(raw), "data: [DONE]\n\n") {
+		t.Fatalf("stream did not end with [DONE]:\n%s", raw)
+	}
+	var last ChatCompletionChunk
+	for _, line := range strings.Split(string(raw), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: {"); ok {
+			if err := json.Unmarshal([]byte("{"+data), &last); err != nil {
+				t.Fatalf("invalid SSE chunk %q: %v", line, err)
+			}
+		}
+	}
+	if last.Usage == nil || last.Usage.PromptTokensDetails == nil {
+		t.Errorf("final chunk = %+v, want usage with prompt_tokens_details", last)
+	}
+	if got := srv.sessions.get(obj.ID, anonymousClientID).messages; got != 3 {
+		t.Errorf("messages after streamed turn = %d, want 3", got)
+	}
+}
+
+func TestSessions_OwnedByCreator(t *testing.T) {
+	ks := security.NewKeyStore()
+	scopes := []security.Scope{security.ScopeReadOnly, security.ScopeInference}
+	owner, _, err := ks.Create("owner", scopes, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ks.Create("other", scopes, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(buildTestModel(t), WithKeyStore(ks), WithSessions(SessionConfig{}))
+	defer func() { _ = srv.Close(context.Background()) }()
+	h := srv.Handler()
+
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(owner, http.MethodPost, "/v1/sessions", `{}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201", rec.Code)
+	}
+	var obj SessionObject
+	if err := json.NewDecoder(rec.Body).Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/v1/sessions/" + obj.ID
+	turn := `{"messages":[{"role":"user","content":"hello"}],"max_tokens":5}`
+	tests := []struct {
+		name, method, path, body string
+	}{
+		{"get", http.MethodGet, path, ""},
+		{"chat", http.MethodPost, path + "/chat/completions", turn},
+		{"delete", http.MethodDelete, path, ""},
+	}
+	for _, tt := range tests {
+		if rec := do(other, tt.method, tt.path, tt.body); rec.Code != http.StatusNotFound {
+			t.Errorf("%s by another key: status = %d, want 404", tt.name, rec.Code)
+		}
+	}
+	for _, tt := range tests {
+		if rec := do(owner, tt.method, tt.path, tt.body); rec.Code != http.StatusOK {
+			t.Errorf("%s by the owner: status = %d, want 200", tt.name, rec.Code)
+		}
+	}
+}
+
+func TestSessions_NotConfigured(t *testing.T) {
+	ts := httptest.NewServer(NewServer(buildTestModel(t)).Handler())
+	defer ts.Close()
+
+	resp := doPost(t, ts.URL+"/v1/sessions", "application/json", `{}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("status = %d, want 501", resp.StatusCode)
+	}
+}
+
+func TestSessionStore_Eviction(t *testing.T) {
+	m := buildTestModel(t)
+	c := runtime.NewInMemory()
+	st := newSessionStore(SessionConfig{IdleTimeout: time.Minute, MaxSessions: 2}, NewSessionMetrics(c))
+
+	a, _ := st.add(m.NewChatSession(""), "c")
+	b, _ := st.add(m.NewChatSession(""), "c")
+	if _, err := st.acquire(b.id, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.acquire(b.id, "c"); err != errSessionBusy {
+		t.Errorf("second acquire error = %v, want %v", err, errSessionBusy)
+	}
+
+	// a is the only idle session, so it makes room for c.
+	c3, err := st.add(m.NewChatSession(""), "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.get(a.id, "c") != nil {
+		t.Error("least recently used idle session was not evicted")
+	}
+
+	// Both remaining sessions are busy: no room.
+	if _, err := st.acquire(c3.id, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.add(m.NewChatSession(""), "c"); err != errSessionsFull {
+		t.Errorf("add with all sessions busy error = %v, want %v", err, errSessionsFull)
+	}
+
+	// Idle sweep skips busy sessions and evicts stale idle ones.
+	st.release(b)
+	st.sweep(time.Now().Add(2 * time.Minute))
+	if st.get(b.id, "c") != nil || st.get(c3.id, "c") == nil {
+		t.Error("sweep evicted the wrong sessions")
+	}
+
+	snap := c.Snapshot()
+	if snap.Counters["sessions_created_total"] != 3 || snap.Counters["sessions_evicted_total"] != 2 {
+		t.Errorf("counters = %v, want 3 created and 2 evicted", snap.Counters)
+	}
+	if snap.Gauges["sessions_active"] != 1 {
+		t.Errorf("sessions_active = %v, want 1", snap.Gauges["sessions_active"])
+	}
+}
@@ -12,7 +12,11 @@ import (
 	"github.com/zerfoo/zerfoo/inference"
 )
 
-func (s *Server) streamChatCompletion(w http.ResponseWriter, ctx context.Context, messages []inference.Message, opts []inference.GenerateOption) {
+// finishReasonStop is the finish_reason reported on the final chunk of a
+// stream that ended normally.
+var finishReasonStop = "stop"
+
+func (s *Server) streamChatCompletion(w http.ResponseWriter, ctx context.Context, messages []inference.Message, streamOpts *StreamOptions, opts []inference.GenerateOption) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		writeError(w, http.StatusInternalServerError, "streaming not supported")
@@ -30,36 +34,54 @@ func (s *Server) streamChatCompletion(w http.ResponseWriter, ctx context.Context
 	if info := s.model.Info(); info != nil {
 		modelID = info.ID
 	}
+	chunk := func(delta ChatDelta, finish *string) ChatCompletionChunk {
+		return ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   modelID,
+			Choices: []ChatCompletionChunkChoice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	// OpenAI clients expect the role on the first delta, before any content.
+	writeSSE(w, flusher, chunk(ChatDelta{Role: "assistant"}, nil))
 
 	// Use the model's chat template to format messages, matching the
-	// non-streaming Chat() path which calls model.formatMessages().
+	// non-streaming Chat() path which calls model.RenderChat().
+	completionTokens := 0
 	err := s.model.ChatStream(ctx, messages, generate.TokenStreamFunc(func(token string, done bool) error {
 		if done {
+			writeSSE(w, flusher, chunk(ChatDelta{}, &finishReasonStop))
+			if streamOpts != nil && streamOpts.IncludeUsage {
+				promptTokens := 0
+				if prompt, err := s.model.RenderChat(messages); err == nil {
+					promptTokens = s.countTokens(prompt)
+				}
+				writeSSE(w, flusher, ChatCompletionChunk{
+					ID:      id,
+					Object:  "chat.completion.chunk",
+					Created: created,
+					Model:   modelID,
+					Choices: []ChatCompletionChunkChoice{},
+					Usage:   newUsageInfo(promptTokens, completionTokens),
+				})
+			}
 			_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
 			flusher.Flush()
 			return nil
 		}
-		chunk := map[string]interface{}{
-			"id":      id,
-			"object":  "chat.completion.chunk",
-			"created": created,
-			"model":   modelID,
-			"choices": []map[string]interface{}{
-				{"delta": map[string]string{"content": token}},
-			},
-		}
-		data, _ := json.Marshal(chunk)
-		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
-		flusher.Flush()
+		completionTokens++
+		writeSSE(w, flusher, chunk(ChatDelta{Content: token}, nil))
 		return nil
 	}), opts...)
+	s.chargeTokens(ctx, completionTokens)
 	if err != nil {
-		_, _ = fmt.Fprintf(w, "data: {\"error\": %q}\n\n", s.sanitizeError(err))
-		flusher.Flush()
+		writeSSEError(w, flusher, s.sanitizeError(err))
 	}
 }
 
-func (s *Server) streamCompletion(w http.ResponseWriter, ctx context.Context, prompt string, opts []inference.GenerateOption) {
+func (s *Server) streamCompletion(w http.ResponseWriter, ctx context.Context, prompt string, streamOpts *StreamOptions, opts []inference.GenerateOption) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		writeError(w, http.StatusInternalServerError, "streaming not supported")
@@ -77,29 +99,77 @@ func (s *Server) streamCompletion(w http.ResponseWriter, ctx context.Context, pr
 	if info := s.model.Info(); info != nil {
 		modelID = info.ID
 	}
+	chunk := func(text string, finish *string) CompletionChunk {
+		return CompletionChunk{
+			ID:      id,
+			Object:  "text_completion",
+			Created: created,
+			Model:   modelID,
+			Choices: []CompletionChunkChoice{{Text: text, FinishReason: finish}},
+		}
+	}
 
+	completionTokens := 0
 	err := s.model.GenerateStream(ctx, prompt, generate.TokenStreamFunc(func(token string, done bool) error {
 		if done {
+			writeSSE(w, flusher, chunk("", &finishReasonStop))
+			if streamOpts != nil && streamOpts.IncludeUsage {
+				writeSSE(w, flusher, CompletionChunk{
+					ID:      id,
+					Object:  "text_completion",
+					Created: created,
+					Model:   modelID,
+					Choices: []CompletionChunkChoice{},
+					Usage:   newUsageInfo(s.countTokens(prompt), completionTokens),
+				})
+			}
 			_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
 			flusher.Flush()
 			return nil
 		}
-		chunk := map[string]interface{}{
-			"id":      id,
-			"object":  "text_completion",
-			"created": created,
-			"model":   modelID,
-			"choices": []map[string]interface{}{
-				{"text": token},
-			},
-		}
-		data, _ := json.Marshal(chunk)
-		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
-		flusher.Flush()
+		completionTokens++
+		writeSSE(w, flusher, chunk(token, nil))
 		return nil
 	}), opts...)
+	s.chargeTokens(ctx, completionTokens)
+	if err != nil {
+		writeSSEError(w, flusher, s.sanitizeError(err))
+	}
+}
+
+// writeSSE writes v as a single SSE data event and flushes it.
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, v interface{}) {
+	data, _ := json.Marshal(v)
+	_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+	flusher.Flush()
+}
+
+// writeSSEError writes an error event using the same envelope as writeError,
+// so clients parse mid-stream failures the same way as HTTP errors.
+func writeSSEError(w http.ResponseWriter, flusher http.Flusher, message string) {
+	writeSSE(w, flusher, map[string]interface{}{
+		"error": map[string]string{"message": message},
+	})
+}
+
+// countTokens returns the number of tokens text encodes to, or 0 when the
+// model has no tokenizer or encoding fails.
+func (s *Server) countTokens(text string) int {
+	tok := s.model.Tokenizer()
+	if tok == nil {
+		return 0
+	}
+	ids, err := tok.Encode(text)
 	if err != nil {
-		_, _ = fmt.Fprintf(w, "data: {\"error\": %q}\n\n", s.sanitizeError(err))
-		flusher.Flush()
+		return 0
+	}
+	return len(ids)
+}
+
+func newUsageInfo(promptTokens, completionTokens int) *UsageInfo {
+	return &UsageInfo{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
 	}
 }
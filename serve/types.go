@@ -3,9 +3,12 @@
 package serve
 
 import (
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"strings"
@@ -38,6 +41,40 @@ type ChatCompletionRequest struct {
 	Tools          []Tool          `json:"tools,omitempty"`
 	ToolChoice     *ToolChoice     `json:"tool_choice,omitempty"`
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Seed           *int64          `json:"seed,omitempty"`
+	Stop           StopSequences   `json:"stop,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// StopSequences holds the OpenAI "stop" parameter, which clients send either
+// as a single string or as an array of strings.
+type StopSequences []string
+
+// UnmarshalJSON accepts a string, an array of strings, or null.
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*s = nil
+		} else {
+			*s = StopSequences{single}
+		}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("stop must be a string or array of strings")
+	}
+	*s = many
+	return nil
+}
+
+// StreamOptions controls optional streaming behaviour.
+type StreamOptions struct {
+	// IncludeUsage requests a final chunk, sent before [DONE], whose usage
+	// field reports token counts for the whole request and whose choices
+	// array is empty.
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ChatMessage is a single message in the chat.
@@ -52,13 +89,16 @@ type ChatMessage struct {
 
 // CompletionRequest represents the OpenAI completion request.
 type CompletionRequest struct {
-	Model       string   `json:"model"`
-	Prompt      string   `json:"prompt"`
-	Temperature *float64 `json:"temperature,omitempty"`
-	TopP        *float64 `json:"top_p,omitempty"`
-	TopK        *int     `json:"top_k,omitempty"`
-	MaxTokens   *int     `json:"max_tokens,omitempty"`
-	Stream      bool     `json:"stream"`
+	Model         string         `json:"model"`
+	Prompt        string         `json:"prompt"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	TopP          *float64       `json:"top_p,omitempty"`
+	TopK          *int           `json:"top_k,omitempty"`
+	MaxTokens     *int           `json:"max_tokens,omitempty"`
+	Stream        bool           `json:"stream"`
+	Seed          *int64         `json:"seed,omitempty"`
+	Stop          StopSequences  `json:"stop,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
 }
 
 // ChatCompletionResponse is the non-streaming response.
@@ -109,17 +149,68 @@ type CompletionChoice struct {
 	FinishReason string `json:"finish_reason"`
 }
 
+// ChatCompletionChunk is a single SSE event of a streaming chat completion.
+type ChatCompletionChunk struct {
+	ID      string                      `json:"id"`
+	Object  string                      `json:"object"`
+	Created int64                       `json:"created"`
+	Model   string                      `json:"model"`
+	Choices []ChatCompletionChunkChoice `json:"choices"`
+	Usage   *UsageInfo                  `json:"usage,omitempty"`
+}
+
+// ChatCompletionChunkChoice is the per-choice payload of a chat chunk.
+// FinishReason is null on every chunk except the last one for the choice.
+type ChatCompletionChunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        ChatDelta `json:"delta"`
+	FinishReason *string   `json:"finish_reason"`
+}
+
+// ChatDelta is the incremental message content carried by a chat chunk.
+// The first chunk of a stream carries the role; later chunks carry content.
+type ChatDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// CompletionChunk is a single SSE event of a streaming text completion.
+type CompletionChunk struct {
+	ID      string                  `json:"id"`
+	Object  string                  `json:"object"`
+	Created int64                   `json:"created"`
+	Model   string                  `json:"model"`
+	Choices []CompletionChunkChoice `json:"choices"`
+	Usage   *UsageInfo              `json:"usage,omitempty"`
+}
+
+// CompletionChunkChoice is the per-choice payload of a completion chunk.
+type CompletionChunkChoice struct {
+	Index        int     `json:"index"`
+	Text         string  `json:"text"`
+	FinishReason *string `json:"finish_reason"`
+}
+
 // UsageInfo reports token counts.
 type UsageInfo struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens        int                  `json:"prompt_tokens"`
+	CompletionTokens    int                  `json:"completion_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the prompt tokens of a request.
+type PromptTokensDetails struct {
+	// CachedTokens is the number of prompt tokens served from a session's
+	// retained KV cache instead of being prefilled.
+	CachedTokens int `json:"cached_tokens"`
 }
 
 // EmbeddingRequest represents the OpenAI embeddings request.
 type EmbeddingRequest struct {
-	Model string      `json:"model"`
-	Input interface{} `json:"input"` // string or []string
+	Model          string      `json:"model"`
+	Input          interface{} `json:"input"`                     // string or []string
+	EncodingFormat string      `json:"encoding_format,omitempty"` // "float" (default) or "base64"
 }
 
 // EmbeddingObject is a single embedding in the response.
@@ -127,6 +218,29 @@ type EmbeddingObject struct {
 	Object    string    `json:"object"`
 	Embedding []float32 `json:"embedding"`
 	Index     int       `json:"index"`
+
+	// Base64 selects the base64 wire encoding: the embedding is serialized
+	// as little-endian float32 bytes, base64-encoded, as the OpenAI SDKs
+	// request by default.
+	Base64 bool `json:"-"`
+}
+
+// MarshalJSON encodes the embedding as a float array, or as a base64 string
+// when Base64 is set.
+func (e EmbeddingObject) MarshalJSON() ([]byte, error) {
+	if !e.Base64 {
+		type plain EmbeddingObject
+		return json.Marshal(plain(e))
+	}
+	buf := make([]byte, 4*len(e.Embedding))
+	for i, v := range e.Embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return json.Marshal(struct {
+		Object    string `json:"object"`
+		Embedding string `json:"embedding"`
+		Index     int    `json:"index"`
+	}{e.Object, base64.StdEncoding.EncodeToString(buf), e.Index})
 }
 
 // EmbeddingResponse is the /v1/embeddings response.
@@ -201,6 +315,24 @@ func validateMaxTokens(maxTokens *int) error {
 	return nil
 }
 
+// maxStopSequences is the maximum number of stop sequences per request,
+// matching the OpenAI API limit.
+const maxStopSequences = 4
+
+// validateStop rejects requests with more than maxStopSequences stop
+// sequences or with empty entries.
+func validateStop(stop StopSequences) error {
+	if len(stop) > maxStopSequences {
+		return fmt.Errorf("stop accepts at most %d sequences, got %d", maxStopSequences, len(stop))
+	}
+	for _, seq := range stop {
+		if seq == "" {
+			return errors.New("stop sequences must be non-empty")
+		}
+	}
+	return nil
+}
+
 // isOOMError reports whether the error message indicates an out-of-memory condition.
 func isOOMError(err error) bool {
 	msg := strings.ToLower(err.Error())
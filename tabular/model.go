@@ -11,6 +11,7 @@ import (
 	"github.com/zerfoo/ztensor/tensor"
 
 	"github.com/zerfoo/zerfoo/layers/functional"
+	"github.com/zerfoo/zerfoo/model/postprocess"
 )
 
 // Direction represents a trading signal direction.
@@ -70,6 +71,7 @@ type Model struct {
 	ops    numeric.Arithmetic[float32]
 	layers []mlpLayer
 	head   mlpLayer // output head: last hidden -> 3 classes
+	chain  postprocess.Chain
 }
 
 // NewModel creates a new tabular Model with the given configuration.
@@ -157,21 +159,7 @@ func (m *Model) Predict(features []float64) (Direction, float64, error) {
 		return Flat, 0, err
 	}
 
-	// Forward through hidden layers.
-	x := input
-	for _, l := range m.layers {
-		x, err = m.linearForward(ctx, x, l)
-		if err != nil {
-			return Flat, 0, err
-		}
-		x, err = m.applyActivation(ctx, x)
-		if err != nil {
-			return Flat, 0, err
-		}
-	}
-
-	// Output head (no activation — raw logits).
-	logits, err := m.linearForward(ctx, x, m.head)
+	logits, err := m.Forward(ctx, input)
 	if err != nil {
 		return Flat, 0, err
 	}
@@ -189,6 +177,49 @@ func (m *Model) Predict(features []float64) (Direction, float64, error) {
 	return dir, conf, nil
 }
 
+// Forward runs the model on a batch of features of shape [n, InputDim] and
+// returns the raw class logits of shape [n, 3], in Direction order.
+func (m *Model) Forward(ctx context.Context, x *tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	if s := x.Shape(); len(s) != 2 || s[1] != m.config.InputDim {
+		return nil, fmt.Errorf("tabular: expected input shape [n %d], got %v", m.config.InputDim, s)
+	}
+	var err error
+	for _, l := range m.layers {
+		x, err = m.linearForward(ctx, x, l)
+		if err != nil {
+			return nil, err
+		}
+		x, err = m.applyActivation(ctx, x)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Output head (no activation — raw logits).
+	return m.linearForward(ctx, x, m.head)
+}
+
+// InputDim returns the number of input features.
+func (m *Model) InputDim() int {
+	return m.config.InputDim
+}
+
+// Postprocess returns the post-processing chain saved with the model, or
+// nil if it has none.
+func (m *Model) Postprocess() postprocess.Chain {
+	return m.chain
+}
+
+// SetPostprocess sets the post-processing chain that SaveGGUF stores with
+// the model for predict and serve to apply to its outputs.
+func (m *Model) SetPostprocess(c postprocess.Chain) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("tabular: postprocess: %w", err)
+	}
+	m.chain = c
+	return nil
+}
+
 // linearForward computes a linear transformation via functional.Linear.
 // Weights are stored as [in, out] so we transpose to [out, in] for the
 // canonical functional.Linear which computes x @ W^T + b.
@@ -0,0 +1,201 @@
+package tabular
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+	"github.com/zerfoo/zerfoo/model/postprocess"
+)
+
+// GGUFArchitecture is the general.architecture value of tabular GGUF files.
+const GGUFArchitecture = "tabular"
+
+// SaveGGUF writes a Model to the given path as a GGUF file.
+//
+// The file declares general.architecture = "tabular" and stores the config
+// under tabular.input_dim, tabular.hidden_dims, tabular.activation and
+// tabular.dropout_rate, and a post-processing chain, if set, as JSON under
+// tabular.postprocess. Hidden layer i is stored as blk.i.weight [in, out]
+// and blk.i.bias [1, out], the output head as output.weight and
+// output.bias, all as F32.
+func SaveGGUF(model *Model, path string) error {
+	hidden := make([]any, len(model.config.HiddenDims))
+	for i, h := range model.config.HiddenDims {
+		hidden[i] = uint32(h)
+	}
+	metadata := map[string]any{
+		"general.architecture": GGUFArchitecture,
+		"tabular.input_dim":    uint32(model.config.InputDim),
+		"tabular.hidden_dims":  hidden,
+		"tabular.activation":   uint32(model.config.Activation),
+		"tabular.dropout_rate": float32(model.config.DropoutRate),
+	}
+	if len(model.chain) > 0 {
+		chain, err := json.Marshal(model.chain)
+		if err != nil {
+			return fmt.Errorf("tabular: save gguf: %w", err)
+		}
+		metadata["tabular.postprocess"] = string(chain)
+	}
+
+	var tensors []gguf.WriterTensor
+	for i, l := range model.layers {
+		tensors = append(tensors,
+			f32Tensor(fmt.Sprintf("blk.%d.weight", i), l.weights),
+			f32Tensor(fmt.Sprintf("blk.%d.bias", i), l.biases))
+	}
+	tensors = append(tensors,
+		f32Tensor("output.weight", model.head.weights),
+		f32Tensor("output.bias", model.head.biases))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("tabular: save gguf: %w", err)
+	}
+	if err := gguf.Write(f, metadata, tensors); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("tabular: save gguf: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("tabular: save gguf: %w", err)
+	}
+	return nil
+}
+
+// f32Tensor describes t as an F32 GGUF tensor, dimensions innermost first.
+func f32Tensor(name string, t *tensor.TensorNumeric[float32]) gguf.WriterTensor {
+	shape := t.Shape()
+	dims := make([]uint64, len(shape))
+	for i, d := range shape {
+		dims[len(shape)-1-i] = uint64(d)
+	}
+	data := t.Data()
+	return gguf.WriterTensor{
+		Name:       name,
+		Dimensions: dims,
+		Type:       gguf.GGMLTypeF32,
+		Size:       int64(len(data)) * 4,
+		WriteData: func(w io.Writer) error {
+			buf := make([]byte, len(data)*4)
+			for i, v := range data {
+				binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+			}
+			_, err := w.Write(buf)
+			return err
+		},
+	}
+}
+
+// LoadGGUF reads a Model written by SaveGGUF from the given path.
+func LoadGGUF(path string, engine compute.Engine[float32], ops numeric.Arithmetic[float32]) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("tabular: load gguf: %w", err)
+	}
+	defer f.Close()
+	return ReadGGUF(f, engine, ops)
+}
+
+// ReadGGUF reads a Model written by SaveGGUF from r.
+func ReadGGUF(r io.ReadSeeker, engine compute.Engine[float32], ops numeric.Arithmetic[float32]) (*Model, error) {
+	gf, err := gguf.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("tabular: load gguf: %w", err)
+	}
+	if arch, _ := gf.GetString("general.architecture"); arch != GGUFArchitecture {
+		return nil, fmt.Errorf("tabular: load gguf: architecture %q, expected %q", arch, GGUFArchitecture)
+	}
+	config, err := ggufConfig(gf)
+	if err != nil {
+		return nil, fmt.Errorf("tabular: load gguf: %w", err)
+	}
+	var chain postprocess.Chain
+	if raw, ok := gf.GetString("tabular.postprocess"); ok {
+		if err := json.Unmarshal([]byte(raw), &chain); err != nil {
+			return nil, fmt.Errorf("tabular: load gguf: tabular.postprocess: %w", err)
+		}
+		if err := chain.Validate(); err != nil {
+			return nil, fmt.Errorf("tabular: load gguf: tabular.postprocess: %w", err)
+		}
+	}
+	tensors, err := gguf.LoadTensors(gf, r)
+	if err != nil {
+		return nil, fmt.Errorf("tabular: load gguf: %w", err)
+	}
+
+	dims := append([]int{config.InputDim}, config.HiddenDims...)
+	layers := make([]mlpLayer, len(config.HiddenDims))
+	for i := range layers {
+		l, err := ggufLayer(tensors, fmt.Sprintf("blk.%d", i), dims[i], dims[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("tabular: load gguf: layer %d: %w", i, err)
+		}
+		layers[i] = l
+	}
+	head, err := ggufLayer(tensors, "output", dims[len(dims)-1], 3)
+	if err != nil {
+		return nil, fmt.Errorf("tabular: load gguf: output head: %w", err)
+	}
+
+	return &Model{
+		config: config,
+		engine: engine,
+		ops:    ops,
+		layers: layers,
+		head:   head,
+		chain:  chain,
+	}, nil
+}
+
+// ggufConfig reads the ModelConfig from the tabular.* metadata.
+func ggufConfig(f *gguf.File) (ModelConfig, error) {
+	var config ModelConfig
+	inputDim, ok := f.GetUint32("tabular.input_dim")
+	if !ok || inputDim == 0 {
+		return config, fmt.Errorf("missing or zero tabular.input_dim")
+	}
+	config.InputDim = int(inputDim)
+	hidden, _ := f.Metadata["tabular.hidden_dims"].([]any)
+	if len(hidden) == 0 {
+		return config, fmt.Errorf("missing or empty tabular.hidden_dims")
+	}
+	for i, h := range hidden {
+		d, ok := h.(uint32)
+		if !ok || d == 0 {
+			return config, fmt.Errorf("tabular.hidden_dims[%d]: invalid dimension %v", i, h)
+		}
+		config.HiddenDims = append(config.HiddenDims, int(d))
+	}
+	if act, ok := f.GetUint32("tabular.activation"); ok {
+		config.Activation = Activation(act)
+	}
+	if rate, ok := f.GetFloat32("tabular.dropout_rate"); ok {
+		config.DropoutRate = float64(rate)
+	}
+	return config, nil
+}
+
+// ggufLayer returns the prefix.weight and prefix.bias tensors, checking
+// they have the shapes of an in -> out layer.
+func ggufLayer(tensors map[string]*tensor.TensorNumeric[float32], prefix string, in, out int) (mlpLayer, error) {
+	w, b := tensors[prefix+".weight"], tensors[prefix+".bias"]
+	if w == nil || b == nil {
+		return mlpLayer{}, fmt.Errorf("missing %s.weight or %s.bias", prefix, prefix)
+	}
+	if s := w.Shape(); len(s) != 2 || s[0] != in || s[1] != out {
+		return mlpLayer{}, fmt.Errorf("%s.weight shape %v, expected [%d %d]", prefix, s, in, out)
+	}
+	if s := b.Shape(); len(s) != 2 || s[0] != 1 || s[1] != out {
+		return mlpLayer{}, fmt.Errorf("%s.bias shape %v, expected [1 %d]", prefix, s, out)
+	}
+	return mlpLayer{weights: w, biases: b}, nil
+}
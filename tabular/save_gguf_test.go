@@ -0,0 +1,132 @@
+package tabular
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/model/gguf"
+	"github.com/zerfoo/zerfoo/model/postprocess"
+)
+
+func TestGGUFRoundTrip(t *testing.T) {
+	engine, ops := newTestEngine()
+	config := ModelConfig{
+		InputDim:    3,
+		HiddenDims:  []int{8, 4},
+		DropoutRate: 0.25,
+		Activation:  ActivationGELU,
+	}
+	m, err := NewModel(config, engine, ops)
+	if err != nil {
+		t.Fatalf("NewModel: %v", err)
+	}
+	chain := postprocess.Chain{{Op: postprocess.OpRank}, {Op: postprocess.OpClip, Min: 0.1, Max: 0.9}}
+	if err := m.SetPostprocess(chain); err != nil {
+		t.Fatalf("SetPostprocess: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	if err := SaveGGUF(m, path); err != nil {
+		t.Fatalf("SaveGGUF: %v", err)
+	}
+	loaded, err := LoadGGUF(path, engine, ops)
+	if err != nil {
+		t.Fatalf("LoadGGUF: %v", err)
+	}
+	if loaded.config.InputDim != 3 || !slices.Equal(loaded.config.HiddenDims, config.HiddenDims) ||
+		loaded.config.Activation != config.Activation || loaded.config.DropoutRate != config.DropoutRate {
+		t.Errorf("config = %+v, want %+v", loaded.config, config)
+	}
+	if !slices.Equal(loaded.Postprocess(), chain) {
+		t.Errorf("Postprocess() = %v, want %v", loaded.Postprocess(), chain)
+	}
+
+	x, err := tensor.New[float32]([]int{2, 3}, []float32{1, -0.5, 2, 0, 3, -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := m.Forward(context.Background(), x)
+	if err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	got, err := loaded.Forward(context.Background(), x)
+	if err != nil {
+		t.Fatalf("loaded Forward: %v", err)
+	}
+	if !slices.Equal(got.Shape(), []int{2, 3}) {
+		t.Fatalf("logits shape = %v, want [2 3]", got.Shape())
+	}
+	if !slices.Equal(got.Data(), want.Data()) {
+		t.Errorf("logits = %v, want %v", got.Data(), want.Data())
+	}
+}
+
+func TestLoadGGUF_Errors(t *testing.T) {
+	engine, ops := newTestEngine()
+	dir := t.TempDir()
+
+	write := func(name string, metadata map[string]any) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := gguf.Write(f, metadata, nil); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		wantErr  string
+	}{
+		{
+			name:     "wrong architecture",
+			metadata: map[string]any{"general.architecture": "llama"},
+			wantErr:  `architecture "llama"`,
+		},
+		{
+			name:     "missing hidden dims",
+			metadata: map[string]any{"general.architecture": "tabular", "tabular.input_dim": uint32(3)},
+			wantErr:  "tabular.hidden_dims",
+		},
+		{
+			name: "missing tensors",
+			metadata: map[string]any{
+				"general.architecture": "tabular",
+				"tabular.input_dim":    uint32(3),
+				"tabular.hidden_dims":  []any{uint32(4)},
+			},
+			wantErr: "missing blk.0.weight",
+		},
+		{
+			name: "invalid postprocess",
+			metadata: map[string]any{
+				"general.architecture": "tabular",
+				"tabular.input_dim":    uint32(3),
+				"tabular.hidden_dims":  []any{uint32(4)},
+				"tabular.postprocess":  `[{"op":"sort"}]`,
+			},
+			wantErr: `unknown op "sort"`,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := write(string(rune('a'+i))+".gguf", tt.metadata)
+			_, err := LoadGGUF(path, engine, ops)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadGGUF error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package training_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/layers/activations"
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+// The tests in this file train small models on problems with a known
+// solution and require the full stack (graph builder, layers, loss,
+// DefaultTrainer, AdamW) to reach it within a fixed step budget. Unit tests
+// check each piece in isolation; these catch regressions where every piece
+// still "works" but the composition silently stops converging, e.g. a
+// mis-scaled gradient seed or an optimizer that drops bias updates.
+
+// analyticProblem is a full-batch regression task.
+type analyticProblem struct {
+	inDim, outDim int
+	x, y          []float32 // row-major [n, inDim] and [n, outDim]
+}
+
+func (p analyticProblem) rows() int { return len(p.x) / p.inDim }
+
+func (p analyticProblem) tensors(t *testing.T) (x, y *tensor.TensorNumeric[float32]) {
+	t.Helper()
+	x, err := tensor.New[float32]([]int{p.rows(), p.inDim}, p.x)
+	if err != nil {
+		t.Fatalf("input tensor: %v", err)
+	}
+	y, err = tensor.New[float32]([]int{p.rows(), p.outDim}, p.y)
+	if err != nil {
+		t.Fatalf("target tensor: %v", err)
+	}
+	return x, y
+}
+
+// buildMLP builds input -> Dense -> (act -> Dense)* over the given widths
+// and re-initializes every parameter from a seeded source so runs are
+// reproducible: weights uniform in ±sqrt(6/(fanIn+fanOut)), biases zero.
+func buildMLP(t *testing.T, engine compute.Engine[float32], widths []int, act func() graph.Node[float32], seed uint64) (*graph.Graph[float32], graph.Node[float32]) {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, widths[0]})
+	h := input
+	for i := 1; i < len(widths); i++ {
+		if i > 1 {
+			h = b.AddNode(act(), h)
+		}
+		d, err := core.NewDense[float32](fmt.Sprintf("dense%d", i), engine, ops, widths[i-1], widths[i])
+		if err != nil {
+			t.Fatalf("dense %d: %v", i, err)
+		}
+		h = b.AddNode(d, h)
+	}
+	g, err := b.Build(h)
+	if err != nil {
+		t.Fatalf("graph build: %v", err)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	for _, p := range g.Parameters() {
+		shape := p.Value.Shape()
+		data := p.Value.Data()
+		if len(shape) < 2 {
+			clear(data)
+			continue
+		}
+		limit := math.Sqrt(6 / float64(shape[0]+shape[len(shape)-1]))
+		for i := range data {
+			data[i] = float32((rng.Float64()*2 - 1) * limit)
+		}
+	}
+	return g, input
+}
+
+// fit trains g on p with full-batch AdamW steps until the loss drops below
+// target, failing if the budget runs out first or the loss goes non-finite.
+func fit(t *testing.T, engine compute.Engine[float32], g *graph.Graph[float32], input graph.Node[float32], p analyticProblem, lr float32, budget int, target float32) {
+	t.Helper()
+	ctx := context.Background()
+	mse := loss.NewMSE[float32](engine, numeric.Float32Ops{})
+	adamw := optimizer.NewAdamW[float32](engine, lr, 0.9, 0.999, 1e-8, 0)
+	trainer := training.NewDefaultTrainer[float32](g, mse, adamw, nil)
+
+	x, y := p.tensors(t)
+	inputs := map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: x}
+
+	var last float32
+	for step := 1; step <= budget; step++ {
+		l, err := trainer.TrainStep(ctx, g, adamw, inputs, y)
+		if err != nil {
+			t.Fatalf("step %d: %v", step, err)
+		}
+		if math.IsNaN(float64(l)) || math.IsInf(float64(l), 0) {
+			t.Fatalf("step %d: non-finite loss %v", step, l)
+		}
+		last = l
+		if l < target {
+			t.Logf("reached loss %.3g < %.3g after %d steps", l, target, step)
+			return
+		}
+	}
+	t.Fatalf("loss %.4g still above %.3g after %d steps", last, target, budget)
+}
+
+// predict runs a forward pass over p's inputs.
+func predict(t *testing.T, g *graph.Graph[float32], p analyticProblem) []float32 {
+	t.Helper()
+	x, _ := p.tensors(t)
+	out, err := g.Forward(context.Background(), x)
+	if err != nil {
+		t.Fatalf("forward: %v", err)
+	}
+	return out.Data()
+}
+
+// paramByShape returns the single parameter with the given shape.
+func paramByShape(t *testing.T, g *graph.Graph[float32], shape ...int) *graph.Parameter[float32] {
+	t.Helper()
+	var found *graph.Parameter[float32]
+	for _, p := range g.Parameters() {
+		ps := p.Value.Shape()
+		if fmt.Sprint(ps) != fmt.Sprint(shape) {
+			continue
+		}
+		if found != nil {
+			t.Fatalf("more than one parameter with shape %v", shape)
+		}
+		found = p
+	}
+	if found == nil {
+		t.Fatalf("no parameter with shape %v", shape)
+	}
+	return found
+}
+
+// TestAnalytic_LinearRegression fits y = Xw + b on noiseless data; the
+// minimizer is unique, so the learned weights must match the generating ones.
+func TestAnalytic_LinearRegression(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	wantW := []float32{2, -3, 0.5}
+	const wantB = 1.5
+
+	rng := rand.New(rand.NewPCG(1, 0))
+	p := analyticProblem{inDim: 3, outDim: 1}
+	for range 64 {
+		y := float32(wantB)
+		for _, w := range wantW {
+			v := float32(rng.Float64()*2 - 1)
+			p.x = append(p.x, v)
+			y += w * v
+		}
+		p.y = append(p.y, y)
+	}
+
+	g, input := buildMLP(t, engine, []int{3, 1}, nil, 7)
+	fit(t, engine, g, input, p, 0.05, 1500, 1e-6)
+
+	w := paramByShape(t, g, 3, 1).Value.Data()
+	for i := range wantW {
+		if d := math.Abs(float64(w[i] - wantW[i])); d > 1e-2 {
+			t.Errorf("w[%d] = %.4f, want %.4f", i, w[i], wantW[i])
+		}
+	}
+	b := paramByShape(t, g, 1).Value.Data()[0]
+	if d := math.Abs(float64(b - wantB)); d > 1e-2 {
+		t.Errorf("b = %.4f, want %.4f", b, wantB)
+	}
+}
+
+// TestAnalytic_XOR fits XOR, which no linear model can represent, with a
+// 2-8-1 tanh MLP. Every prediction must be within 0.1 of its label.
+func TestAnalytic_XOR(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	p := analyticProblem{
+		inDim:  2,
+		outDim: 1,
+		x:      []float32{0, 0, 0, 1, 1, 0, 1, 1},
+		y:      []float32{0, 1, 1, 0},
+	}
+	tanh := func() graph.Node[float32] {
+		return activations.NewTanh[float32](engine, numeric.Float32Ops{})
+	}
+
+	g, input := buildMLP(t, engine, []int{2, 8, 1}, tanh, 3)
+	fit(t, engine, g, input, p, 0.05, 1000, 1e-3)
+
+	for i, got := range predict(t, g, p) {
+		if want := p.y[i]; math.Abs(float64(got-want)) > 0.1 {
+			t.Errorf("xor(%v, %v) = %.3f, want %v", p.x[2*i], p.x[2*i+1], got, want)
+		}
+	}
+}
+
+// TestAnalytic_Sinusoid fits sin(x) on [-pi, pi] with a 1-16-16-1 tanh MLP
+// and checks the fit on points between the training samples.
+func TestAnalytic_Sinusoid(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	sample := func(n int, offset float64) analyticProblem {
+		p := analyticProblem{inDim: 1, outDim: 1}
+		for i := range n {
+			x := -math.Pi + 2*math.Pi*(float64(i)+offset)/float64(n)
+			p.x = append(p.x, float32(x))
+			p.y = append(p.y, float32(math.Sin(x)))
+		}
+		return p
+	}
+	train := sample(64, 0)
+	tanh := func() graph.Node[float32] {
+		return activations.NewTanh[float32](engine, numeric.Float32Ops{})
+	}
+
+	g, input := buildMLP(t, engine, []int{1, 16, 16, 1}, tanh, 5)
+	fit(t, engine, g, input, train, 0.01, 3000, 2e-4)
+
+	held := sample(63, 0.5)
+	var maxErr float64
+	for i, got := range predict(t, g, held) {
+		maxErr = max(maxErr, math.Abs(float64(got-held.y[i])))
+	}
+	if maxErr > 0.1 {
+		t.Errorf("max error on held-out points = %.4f, want <= 0.1", maxErr)
+	}
+}
+
+// staticDataProvider serves one fixed batch per epoch.
+type staticDataProvider struct {
+	batch *training.Batch[float32]
+}
+
+func (d *staticDataProvider) GetTrainingData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter([]*training.Batch[float32]{d.batch}), nil
+}
+
+func (d *staticDataProvider) GetValidationData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter([]*training.Batch[float32]{d.batch}), nil
+}
+
+func (d *staticDataProvider) GetMetadata() map[string]interface{} { return nil }
+func (d *staticDataProvider) Close() error                        { return nil }
+
+// TestAnalytic_WorkflowLinearRegression drives the linear regression problem
+// through TrainerWorkflowAdapter, so the epoch loop, data iterator, and model
+// provider are exercised alongside the trainer.
+func TestAnalytic_WorkflowLinearRegression(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	ops := numeric.Float32Ops{}
+
+	rng := rand.New(rand.NewPCG(2, 0))
+	p := analyticProblem{inDim: 2, outDim: 1}
+	for range 32 {
+		a, b := float32(rng.Float64()*2-1), float32(rng.Float64()*2-1)
+		p.x = append(p.x, a, b)
+		p.y = append(p.y, 4*a-b+0.25)
+	}
+	g, input := buildMLP(t, engine, []int{2, 1}, nil, 11)
+	x, y := p.tensors(t)
+
+	adamw := optimizer.NewAdamW[float32](engine, 0.05, 0.9, 0.999, 1e-8, 0)
+	trainer := training.NewDefaultTrainer[float32](g, loss.NewMSE[float32](engine, ops), adamw, nil)
+	workflow := training.NewTrainerWorkflowAdapter[float32](trainer, adamw)
+	if err := workflow.Initialize(ctx, training.WorkflowConfig{NumEpochs: 1500}); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	data := &staticDataProvider{batch: &training.Batch[float32]{
+		Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: x},
+		Targets: y,
+	}}
+	models := training.NewSimpleModelProvider[float32](
+		func(context.Context, training.ModelConfig) (*graph.Graph[float32], error) { return g, nil },
+		training.ModelInfo{Name: "linear"},
+	)
+
+	res, err := workflow.Train(ctx, data, models)
+	if err != nil {
+		t.Fatalf("train: %v", err)
+	}
+	if res.TotalEpochs != 1500 {
+		t.Errorf("TotalEpochs = %d, want 1500", res.TotalEpochs)
+	}
+	if res.BestLoss > 1e-6 {
+		t.Fatalf("best loss %.4g after %d epochs, want < 1e-6", res.BestLoss, res.TotalEpochs)
+	}
+	w := paramByShape(t, g, 2, 1).Value.Data()
+	b := paramByShape(t, g, 1).Value.Data()[0]
+	for i, want := range []float32{4, -1} {
+		if math.Abs(float64(w[i]-want)) > 1e-2 {
+			t.Errorf("w[%d] = %.4f, want %.4f", i, w[i], want)
+		}
+	}
+	if math.Abs(float64(b-0.25)) > 1e-2 {
+		t.Errorf("b = %.4f, want 0.25", b)
+	}
+}
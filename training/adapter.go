@@ -3,9 +3,13 @@ package training
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"os"
+	"time"
 
+	"github.com/zerfoo/zerfoo/model/gguf"
 	"github.com/zerfoo/zerfoo/training/optimizer"
 	ztensorgguf "github.com/zerfoo/ztensor/gguf"
 	"github.com/zerfoo/ztensor/graph"
@@ -19,6 +23,7 @@ type TrainerWorkflowAdapter[T tensor.Numeric] struct {
 	optimizer optimizer.Optimizer[T]
 	config    WorkflowConfig
 	metrics   map[string]interface{}
+	now       func() time.Time
 }
 
 // NewTrainerWorkflowAdapter creates a new adapter for legacy trainers.
@@ -27,23 +32,67 @@ func NewTrainerWorkflowAdapter[T tensor.Numeric](trainer Trainer[T], opt optimiz
 		trainer:   trainer,
 		optimizer: opt,
 		metrics:   make(map[string]interface{}),
+		now:       time.Now,
 	}
 }
 
-// Initialize implements TrainingWorkflow.Initialize
+// accumulatingTrainer is a Trainer that accumulates gradients over
+// micro-batches, such as DefaultTrainer with WithGradAccumulation.
+type accumulatingTrainer[T tensor.Numeric] interface {
+	AccumulationSteps() int
+	PendingMicroBatches() int
+	Flush(ctx context.Context, g *graph.Graph[T], optimizer optimizer.Optimizer[T]) error
+}
+
+var _ accumulatingTrainer[float32] = (*DefaultTrainer[float32])(nil)
+
+// Initialize implements TrainingWorkflow.Initialize. The adapter cannot
+// configure the trainer it wraps, so a config with AccumulationSteps above
+// one requires a trainer already built to accumulate that many micro-batches.
 func (a *TrainerWorkflowAdapter[T]) Initialize(ctx context.Context, config WorkflowConfig) error {
+	if config.ClipNorm < 0 || config.ClipValue < 0 {
+		return fmt.Errorf("trainer workflow adapter: ClipNorm and ClipValue must not be negative, got %g and %g", config.ClipNorm, config.ClipValue)
+	}
+	if err := validateEarlyStop(config); err != nil {
+		return fmt.Errorf("trainer workflow adapter: %w", err)
+	}
+	if config.AccumulationSteps > 1 {
+		at, ok := a.trainer.(accumulatingTrainer[T])
+		if !ok || at.AccumulationSteps() != config.AccumulationSteps {
+			return fmt.Errorf("trainer workflow adapter: AccumulationSteps is %d but the trainer does not accumulate that many micro-batches", config.AccumulationSteps)
+		}
+	}
 	a.config = config
 	return nil
 }
 
-// Train implements TrainingWorkflow.Train by adapting to the legacy Trainer interface
+// Train implements TrainingWorkflow.Train by adapting to the legacy Trainer interface.
+// When config.MaxWallClock elapses it stops before the next training step,
+// saves the model to config.CheckpointPath if set, and reports
+// StopMaxWallClock. The interrupted epoch's loss is recorded but not counted
+// in TotalEpochs. Early stopping from config monitors the epoch's mean
+// training loss, the only metric the adapter computes, as "train_loss".
 func (a *TrainerWorkflowAdapter[T]) Train(ctx context.Context, dataset DataProvider[T], modelProvider ModelProvider[T]) (*TrainingResult[T], error) {
+	start := a.now()
+	timeUp := func() bool {
+		return a.config.MaxWallClock > 0 && a.now().Sub(start) >= a.config.MaxWallClock
+	}
+
 	// Create model
-	model, err := modelProvider.CreateModel(ctx, a.config.ModelConfig)
+	model, err := modelProvider.CreateModel(ctx, a.config.modelConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	// The trainer steps the optimizer it is given, so clipping from the
+	// config is applied by wrapping it.
+	var stepOpt optimizer.Optimizer[T] = a.optimizer
+	if clipper := configClipper[T](a.config); clipper != nil {
+		if stepOpt, err = newClippingOptimizer(a.optimizer, model.Engine(), clipper); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get training data
 	dataIter, err := dataset.GetTrainingData(ctx, a.config.BatchConfig)
 	if err != nil {
@@ -55,9 +104,11 @@ func (a *TrainerWorkflowAdapter[T]) Train(ctx context.Context, dataset DataProvi
 	var bestLoss T
 	bestEpoch := 0
 	epoch := 0
+	stopReason := StopCompleted
+	stopper := NewEarlyStopper[T](a.config)
 
 	// Training loop
-	for epoch < a.config.NumEpochs {
+	for epoch < a.config.NumEpochs && stopReason == StopCompleted {
 		epochLoss := T(0)
 		batchCount := 0
 
@@ -67,7 +118,16 @@ func (a *TrainerWorkflowAdapter[T]) Train(ctx context.Context, dataset DataProvi
 		}
 
 		// Process all batches in epoch
-		for dataIter.Next(ctx) {
+		for {
+			// Steps are the safe boundary: the model and optimizer state
+			// are consistent between them.
+			if timeUp() {
+				stopReason = StopMaxWallClock
+				break
+			}
+			if !dataIter.Next(ctx) {
+				break
+			}
 			batch := dataIter.Batch()
 			if batch == nil {
 				break
@@ -77,7 +137,7 @@ func (a *TrainerWorkflowAdapter[T]) Train(ctx context.Context, dataset DataProvi
 			targets := batch.Targets
 
 			// Perform training step using legacy trainer
-			stepLoss, err := a.trainer.TrainStep(ctx, model, a.optimizer, batch.Inputs, targets)
+			stepLoss, err := a.trainer.TrainStep(ctx, model, stepOpt, batch.Inputs, targets)
 			if err != nil {
 				return nil, fmt.Errorf("training step failed at epoch %d: %w", epoch, err)
 			}
@@ -90,6 +150,18 @@ func (a *TrainerWorkflowAdapter[T]) Train(ctx context.Context, dataset DataProvi
 			return nil, fmt.Errorf("data iteration failed at epoch %d: %w", epoch, err)
 		}
 
+		// Apply a partially filled accumulation so no micro-batch carries
+		// over into the next epoch.
+		if at, ok := a.trainer.(accumulatingTrainer[T]); ok && at.PendingMicroBatches() > 0 {
+			if err := at.Flush(ctx, model, stepOpt); err != nil {
+				return nil, fmt.Errorf("training step failed at epoch %d: %w", epoch, err)
+			}
+		}
+
+		if stopReason != StopCompleted && batchCount == 0 {
+			break
+		}
+
 		// Calculate average loss for epoch
 		if batchCount > 0 {
 			epochLoss /= T(batchCount)
@@ -106,17 +178,41 @@ func (a *TrainerWorkflowAdapter[T]) Train(ctx context.Context, dataset DataProvi
 		// Store metrics
 		a.metrics[fmt.Sprintf("epoch_%d_loss", epoch)] = float64(epochLoss)
 
-		epoch++
+		if stopReason == StopCompleted {
+			epoch++
+			if stopper != nil {
+				loss := float64(epochLoss)
+				v, err := stopper.Value(loss, map[string]float64{"train_loss": loss})
+				if err != nil {
+					return nil, fmt.Errorf("epoch %d: %w", epoch-1, err)
+				}
+				if stopper.Step(epoch-1, v, model) {
+					stopReason = StopEarlyStopping
+				}
+			}
+		}
 	}
 
 	// Return training result
 	result := &TrainingResult[T]{
-		FinalLoss:   totalLoss,
-		BestLoss:    bestLoss,
-		BestEpoch:   bestEpoch,
-		TotalEpochs: epoch,
-		Metrics:     make(map[string]float64),
-		Extensions:  make(map[string]interface{}),
+		FinalLoss:    totalLoss,
+		BestLoss:     bestLoss,
+		BestEpoch:    bestEpoch,
+		TotalEpochs:  epoch,
+		TrainingTime: a.now().Sub(start).Seconds(),
+		StopReason:   stopReason,
+		Metrics:      make(map[string]float64),
+		Extensions:   make(map[string]interface{}),
+	}
+	if err := restoreBest(stopper, model, result); err != nil {
+		return nil, err
+	}
+
+	if stopReason == StopMaxWallClock && a.config.CheckpointPath != "" {
+		if err := modelProvider.SaveModel(ctx, model, a.config.CheckpointPath); err != nil {
+			return nil, fmt.Errorf("failed to save checkpoint after wall-clock limit: %w", err)
+		}
+		result.ModelPath = a.config.CheckpointPath
 	}
 
 	// Convert metrics to float64 for result
@@ -287,7 +383,8 @@ func (s *SimpleModelProvider[T]) LoadModel(ctx context.Context, path string) (*g
 // SaveModel implements ModelProvider.SaveModel by writing model parameters to
 // a GGUF file using the shared ztensor/gguf writer. Each graph parameter is
 // stored as a float32 tensor. The model info architecture name is written as
-// the general.architecture metadata key.
+// the general.architecture metadata key. SHA-256 digests of the tensor data
+// are embedded so loaders can detect tampered or truncated files.
 func (s *SimpleModelProvider[T]) SaveModel(ctx context.Context, model *graph.Graph[T], path string) error {
 	if model == nil {
 		return fmt.Errorf("training: SaveModel: model is nil")
@@ -315,13 +412,21 @@ func (s *SimpleModelProvider[T]) SaveModel(ctx context.Context, model *graph.Gra
 		w.AddMetadataString("general.name", s.modelInfo.Name)
 	}
 
-	for _, p := range params {
+	raw := make([][]byte, len(params))
+	for i, p := range params {
 		data := p.Value.Data()
-		f32Data := make([]float32, len(data))
-		for i, v := range data {
-			f32Data[i] = float32(v)
+		b := make([]byte, len(data)*4)
+		for j, v := range data {
+			binary.LittleEndian.PutUint32(b[j*4:], math.Float32bits(float32(v)))
 		}
-		w.AddTensorF32(p.Name, p.Value.Shape(), f32Data)
+		raw[i] = b
+	}
+	tensorSums, dataSum := gguf.IntegrityMetadata(raw)
+	w.AddMetadataStringArray(gguf.MetaTensorSHA256, tensorSums)
+	w.AddMetadataString(gguf.MetaDataSHA256, dataSum)
+
+	for i, p := range params {
+		w.AddTensor(p.Name, ztensorgguf.TypeF32, p.Value.Shape(), raw[i])
 	}
 
 	if err := w.Write(f); err != nil {
@@ -3,10 +3,17 @@ package training
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/model/gguf"
 	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 )
 
@@ -68,6 +75,17 @@ func TestTrainerWorkflowAdapter_Initialize(t *testing.T) {
 	if adapter.config.NumEpochs != 5 {
 		t.Errorf("config.NumEpochs = %d, want 5", adapter.config.NumEpochs)
 	}
+
+	// The adapter cannot make its trainer accumulate, so it rejects a
+	// config asking for accumulation the trainer does not do.
+	config.AccumulationSteps = 4
+	if err := adapter.Initialize(context.Background(), config); err == nil {
+		t.Error("Initialize with AccumulationSteps and a non-accumulating trainer succeeded")
+	}
+	accum := NewDefaultTrainer[float32](nil, nil, nil, &mockGradientStrategy[float32]{}, WithGradAccumulation[float32](4))
+	if err := NewTrainerWorkflowAdapter[float32](accum, &mockOpt[float32]{}).Initialize(context.Background(), config); err != nil {
+		t.Errorf("Initialize with a matching trainer: %v", err)
+	}
 }
 
 func TestTrainerWorkflowAdapter_Train(t *testing.T) {
@@ -206,6 +224,42 @@ func TestSimpleModelProvider_SaveModel(t *testing.T) {
 	}
 }
 
+func TestSimpleModelProvider_SaveModelIntegrity(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	dense, err := core.NewDense[float32]("d", engine, ops, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, b.Input([]int{1, 3})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	sp := NewSimpleModelProvider[float32](nil, ModelInfo{Architecture: "custom"})
+	if err := sp.SaveModel(context.Background(), g, path); err != nil {
+		t.Fatalf("SaveModel: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	gf, err := gguf.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gf.HasIntegrity() {
+		t.Fatal("saved model has no integrity metadata")
+	}
+	if err := gguf.VerifyIntegrity(gf, f); err != nil {
+		t.Errorf("VerifyIntegrity: %v", err)
+	}
+}
+
 func TestSimpleModelProvider_GetModelInfo(t *testing.T) {
 	info := ModelInfo{Name: "test", Version: "2.0", Architecture: "custom"}
 	sp := NewSimpleModelProvider[float32](nil, info)
@@ -311,3 +365,138 @@ func TestTrainerWorkflowAdapter_Train_MultipleBatches(t *testing.T) {
 		t.Errorf("BestEpoch = %d, want 0", result.BestEpoch)
 	}
 }
+
+// clockTrainer advances a fake clock by step on every training step.
+type clockTrainer[T tensor.Numeric] struct {
+	clock *time.Time
+	step  time.Duration
+	steps int
+}
+
+func (c *clockTrainer[T]) TrainStep(ctx context.Context, g *graph.Graph[T], opt optimizer.Optimizer[T], inputs map[graph.Node[T]]*tensor.TensorNumeric[T], targets *tensor.TensorNumeric[T]) (T, error) {
+	*c.clock = c.clock.Add(c.step)
+	c.steps++
+	return T(1), nil
+}
+
+// savingModelProvider records the paths SaveModel is called with.
+type savingModelProvider[T tensor.Numeric] struct {
+	*MockModelProvider[T]
+	saved []string
+	err   error
+}
+
+func (s *savingModelProvider[T]) SaveModel(ctx context.Context, model *graph.Graph[T], path string) error {
+	s.saved = append(s.saved, path)
+	return s.err
+}
+
+func newClockAdapter(step time.Duration, config WorkflowConfig) (*TrainerWorkflowAdapter[float32], *clockTrainer[float32]) {
+	clock := time.Unix(0, 0)
+	trainer := &clockTrainer[float32]{clock: &clock, step: step}
+	adapter := NewTrainerWorkflowAdapter[float32](trainer, &mockOpt[float32]{})
+	adapter.now = func() time.Time { return clock }
+	_ = adapter.Initialize(context.Background(), config)
+	return adapter, trainer
+}
+
+func fourBatches() *MockDataProvider[float32] {
+	batches := make([]*Batch[float32], 4)
+	for i := range batches {
+		batches[i] = &Batch[float32]{Inputs: make(map[graph.Node[float32]]*tensor.TensorNumeric[float32])}
+	}
+	return NewMockDataProvider[float32](batches, nil)
+}
+
+func TestTrainerWorkflowAdapter_Train_MaxWallClock(t *testing.T) {
+	adapter, trainer := newClockAdapter(time.Minute, WorkflowConfig{
+		NumEpochs:      10,
+		MaxWallClock:   10 * time.Minute,
+		CheckpointPath: "/ckpt/model.gguf",
+	})
+	mp := &savingModelProvider[float32]{MockModelProvider: NewMockModelProvider[float32](nil)}
+
+	result, err := adapter.Train(context.Background(), fourBatches(), mp)
+	if err != nil {
+		t.Fatalf("Train failed: %v", err)
+	}
+	if trainer.steps != 10 {
+		t.Errorf("ran %d steps, want 10 (one per minute of budget)", trainer.steps)
+	}
+	if result.StopReason != StopMaxWallClock {
+		t.Errorf("StopReason = %q, want %q", result.StopReason, StopMaxWallClock)
+	}
+	// Steps 9 and 10 belong to the interrupted third epoch.
+	if result.TotalEpochs != 2 {
+		t.Errorf("TotalEpochs = %d, want 2 completed", result.TotalEpochs)
+	}
+	if _, ok := result.Metrics["epoch_2_loss"]; !ok {
+		t.Error("interrupted epoch's loss not recorded")
+	}
+	if result.TrainingTime != 600 {
+		t.Errorf("TrainingTime = %v, want 600", result.TrainingTime)
+	}
+	if len(mp.saved) != 1 || mp.saved[0] != "/ckpt/model.gguf" || result.ModelPath != "/ckpt/model.gguf" {
+		t.Errorf("saved %v, ModelPath %q", mp.saved, result.ModelPath)
+	}
+}
+
+func TestTrainerWorkflowAdapter_Train_WithinWallClock(t *testing.T) {
+	adapter, trainer := newClockAdapter(time.Minute, WorkflowConfig{
+		NumEpochs:      2,
+		MaxWallClock:   time.Hour,
+		CheckpointPath: "/ckpt/model.gguf",
+	})
+	mp := &savingModelProvider[float32]{MockModelProvider: NewMockModelProvider[float32](nil)}
+
+	result, err := adapter.Train(context.Background(), fourBatches(), mp)
+	if err != nil {
+		t.Fatalf("Train failed: %v", err)
+	}
+	if trainer.steps != 8 || result.TotalEpochs != 2 || result.StopReason != StopCompleted {
+		t.Errorf("steps = %d, TotalEpochs = %d, StopReason = %q", trainer.steps, result.TotalEpochs, result.StopReason)
+	}
+	if len(mp.saved) != 0 {
+		t.Errorf("checkpoint written on normal completion: %v", mp.saved)
+	}
+}
+
+func TestTrainerWorkflowAdapter_Train_CheckpointError(t *testing.T) {
+	adapter, _ := newClockAdapter(time.Minute, WorkflowConfig{
+		NumEpochs:      10,
+		MaxWallClock:   time.Minute,
+		CheckpointPath: "/ckpt/model.gguf",
+	})
+	mp := &savingModelProvider[float32]{
+		MockModelProvider: NewMockModelProvider[float32](nil),
+		err:               errors.New("disk full"),
+	}
+
+	if _, err := adapter.Train(context.Background(), fourBatches(), mp); err == nil {
+		t.Error("Train should fail when the checkpoint cannot be written")
+	}
+}
+
+func TestTrainerWorkflowAdapter_Train_EarlyStopping(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewTrainerWorkflowAdapter[float32](&mockTrainer[float32]{}, &mockOpt[float32]{})
+	if err := adapter.Initialize(ctx, WorkflowConfig{NumEpochs: 10, MaxNoImprove: 2, RestoreBest: true}); err != nil {
+		t.Fatal(err)
+	}
+	// The mock trainer's loss is constant, so no epoch after the first
+	// improves.
+	result, err := adapter.Train(ctx, fourBatches(), NewMockModelProvider(newDenseGraph(t, 2, 1)))
+	if err != nil {
+		t.Fatalf("Train failed: %v", err)
+	}
+	if result.StopReason != StopEarlyStopping || result.TotalEpochs != 3 {
+		t.Errorf("StopReason = %q, TotalEpochs = %d, want early stop after 3", result.StopReason, result.TotalEpochs)
+	}
+	if result.Extensions[RestoredEpochKey] != 0 {
+		t.Errorf("restored epoch %v, want 0", result.Extensions[RestoredEpochKey])
+	}
+
+	if err := adapter.Initialize(ctx, WorkflowConfig{NumEpochs: 10, MaxNoImprove: -1}); err == nil {
+		t.Error("Initialize accepted a negative MaxNoImprove")
+	}
+}
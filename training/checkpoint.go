@@ -0,0 +1,443 @@
+package training
+
+import (
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Checkpoint is the state of a training run at a step boundary: enough to
+// resume it and reach bit-for-bit the result of an uninterrupted run.
+type Checkpoint struct {
+	// Epoch is the epoch in progress, counting from 0.
+	Epoch int
+	// EpochStep is the number of training steps taken in Epoch.
+	EpochStep int
+	// Step is the number of training steps taken in total.
+	Step int
+	// EpochLossSum is the sum of the batch losses of Epoch so far.
+	EpochLossSum float64
+	// EpochLosses holds the monitored loss of each completed epoch, to
+	// restore the best loss, early stopping and the learning rate schedule.
+	EpochLosses []float64
+	// Metrics is the metrics snapshot of the latest completed epoch, such
+	// as "val_loss", empty before the first one completes.
+	Metrics map[string]float64
+
+	// Params holds the model parameters by name.
+	Params map[string][]float64
+	// Optimizer is the optimizer's state, nil for an optimizer without
+	// saveable state.
+	Optimizer *optimizer.State
+	// RNG is the state of the manager's random source, if it has one.
+	RNG []byte
+}
+
+// CheckpointRNG is a random source whose state can be checkpointed, such as
+// *rand.PCG or *rand.ChaCha8 from math/rand/v2.
+type CheckpointRNG interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// CheckpointInfo is the metadata of a saved checkpoint, kept next to it in
+// a JSON file so checkpoints can be compared without reading them.
+type CheckpointInfo struct {
+	// Path is the path of the checkpoint file.
+	Path string `json:"-"`
+	// Step, Epoch and EpochStep are the counters of the checkpoint.
+	Step      int `json:"step"`
+	Epoch     int `json:"epoch"`
+	EpochStep int `json:"epoch_step"`
+	// Metrics is the checkpoint's metrics snapshot.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// CheckpointManager writes checkpoints of a training run to a directory
+// every N steps and restores them. Each checkpoint is a gob file named
+// after its step with a JSON CheckpointInfo beside it; parameter and
+// optimizer values are stored as float64, which holds every element type
+// exactly.
+type CheckpointManager[T tensor.Numeric] struct {
+	dir   string
+	every int
+	keep  int
+	rng   CheckpointRNG
+
+	// Best-k retention by a monitored metric.
+	best       int
+	metric     string
+	metricMode string
+}
+
+// CheckpointOption configures a CheckpointManager.
+type CheckpointOption[T tensor.Numeric] func(*CheckpointManager[T])
+
+// WithCheckpointEvery sets the number of training steps between
+// checkpoints. The default is 1000.
+func WithCheckpointEvery[T tensor.Numeric](steps int) CheckpointOption[T] {
+	return func(m *CheckpointManager[T]) {
+		m.every = steps
+	}
+}
+
+// WithCheckpointKeep keeps only the latest n checkpoints, deleting older
+// ones after each save. Zero, the default, keeps all of them.
+func WithCheckpointKeep[T tensor.Numeric](n int) CheckpointOption[T] {
+	return func(m *CheckpointManager[T]) {
+		m.keep = n
+	}
+}
+
+// WithCheckpointBest also keeps the best k checkpoints by metric, a key of
+// Checkpoint.Metrics such as "val_loss", when WithCheckpointKeep prunes the
+// others. mode is "min" if lower values are better, the default, or
+// "max". Checkpoints without the metric never count as best. Train saves
+// a checkpoint at the end of every epoch as well when metric is set, so
+// that each epoch's metrics are captured.
+func WithCheckpointBest[T tensor.Numeric](k int, metric, mode string) CheckpointOption[T] {
+	return func(m *CheckpointManager[T]) {
+		m.best = k
+		m.metric = metric
+		m.metricMode = mode
+	}
+}
+
+// WithCheckpointRNG saves and restores the state of rng with each
+// checkpoint, for runs that draw from it, e.g. to shuffle their data.
+func WithCheckpointRNG[T tensor.Numeric](rng CheckpointRNG) CheckpointOption[T] {
+	return func(m *CheckpointManager[T]) {
+		m.rng = rng
+	}
+}
+
+// NewCheckpointManager creates a CheckpointManager that writes to dir,
+// creating it if needed.
+func NewCheckpointManager[T tensor.Numeric](dir string, opts ...CheckpointOption[T]) (*CheckpointManager[T], error) {
+	m := &CheckpointManager[T]{dir: dir, every: 1000}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.metricMode == "" {
+		m.metricMode = "min"
+	}
+	switch {
+	case dir == "":
+		return nil, errors.New("training: checkpoint directory is empty")
+	case m.every <= 0:
+		return nil, fmt.Errorf("training: checkpoint interval must be positive, got %d", m.every)
+	case m.keep < 0:
+		return nil, fmt.Errorf("training: checkpoint keep count must not be negative, got %d", m.keep)
+	case m.best < 0:
+		return nil, fmt.Errorf("training: best checkpoint count must not be negative, got %d", m.best)
+	case m.best > 0 && m.metric == "":
+		return nil, errors.New("training: best checkpoint retention needs a metric")
+	case m.metricMode != "min" && m.metricMode != "max":
+		return nil, fmt.Errorf(`training: checkpoint metric mode must be "min" or "max", got %q`, m.metricMode)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("training: create checkpoint directory: %w", err)
+	}
+	return m, nil
+}
+
+// Due reports whether a checkpoint is due after step training steps.
+func (m *CheckpointManager[T]) Due(step int) bool {
+	return step > 0 && step%m.every == 0
+}
+
+// Monitors reports whether the manager keeps the best checkpoints by a
+// metric.
+func (m *CheckpointManager[T]) Monitors() bool {
+	return m.metric != ""
+}
+
+// Save fills ckpt with the parameters of model, the state of opt and the
+// RNG state, writes it with its CheckpointInfo, prunes the directory, and
+// returns its path. The counters of ckpt are the
+// caller's. An optimizer that does not implement optimizer.StateSaver is
+// saved without state, which is exact for stateless optimizers such as
+// SGD; one that keeps per-parameter state it cannot save is an error.
+func (m *CheckpointManager[T]) Save(ckpt *Checkpoint, model *graph.Graph[T], opt optimizer.Optimizer[T]) (string, error) {
+	params := model.Parameters()
+	ckpt.Params = make(map[string][]float64, len(params))
+	for _, p := range params {
+		ckpt.Params[p.Name] = dtype.Float64s(nil, p.Value.Data())
+	}
+	ckpt.Optimizer = nil
+	switch o := opt.(type) {
+	case optimizer.StateSaver[T]:
+		state, err := o.SaveState(params)
+		if err != nil {
+			return "", fmt.Errorf("training: save optimizer state: %w", err)
+		}
+		ckpt.Optimizer = state
+	case optimizer.StateMigrator[T]:
+		return "", fmt.Errorf("training: optimizer %T cannot save its state", opt)
+	}
+	ckpt.RNG = nil
+	if m.rng != nil {
+		state, err := m.rng.MarshalBinary()
+		if err != nil {
+			return "", fmt.Errorf("training: save RNG state: %w", err)
+		}
+		ckpt.RNG = state
+	}
+
+	path := filepath.Join(m.dir, checkpointName(ckpt.Step))
+	if err := writeCheckpoint(path, ckpt); err != nil {
+		return "", err
+	}
+	if err := writeInfo(path, ckpt); err != nil {
+		return "", err
+	}
+	if err := m.prune(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeCheckpoint writes ckpt to a temporary file and renames it into
+// place, so a crash mid-write never leaves a truncated checkpoint.
+func writeCheckpoint(path string, ckpt *Checkpoint) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("training: create checkpoint: %w", err)
+	}
+	if err := gob.NewEncoder(f).Encode(ckpt); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("training: encode checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("training: write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("training: write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// writeInfo writes the CheckpointInfo of the checkpoint at path.
+func writeInfo(path string, ckpt *Checkpoint) error {
+	data, err := json.MarshalIndent(CheckpointInfo{
+		Step:      ckpt.Step,
+		Epoch:     ckpt.Epoch,
+		EpochStep: ckpt.EpochStep,
+		Metrics:   ckpt.Metrics,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("training: encode checkpoint info: %w", err)
+	}
+	tmp := infoPath(path) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("training: write checkpoint info: %w", err)
+	}
+	if err := os.Rename(tmp, infoPath(path)); err != nil {
+		return fmt.Errorf("training: write checkpoint info: %w", err)
+	}
+	return nil
+}
+
+// ReadCheckpoint reads the checkpoint at path.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("training: open checkpoint: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	var ckpt Checkpoint
+	if err := gob.NewDecoder(f).Decode(&ckpt); err != nil {
+		return nil, fmt.Errorf("training: decode checkpoint %s: %w", path, err)
+	}
+	return &ckpt, nil
+}
+
+// Restore reads the checkpoint at path and loads it into model, opt and
+// the manager's RNG. Every parameter of model must be in the checkpoint.
+func (m *CheckpointManager[T]) Restore(path string, model *graph.Graph[T], opt optimizer.Optimizer[T]) (*Checkpoint, error) {
+	ckpt, err := ReadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	params := model.Parameters()
+	ops := model.Engine().Ops()
+	values := make(map[string][]T, len(ckpt.Params))
+	for name, v := range ckpt.Params {
+		values[name] = make([]T, len(v))
+		dtype.FromFloat64s(ops, values[name], v)
+	}
+	for _, p := range params {
+		if _, ok := values[p.Name]; !ok {
+			return nil, fmt.Errorf("training: checkpoint %s has no parameter %q", path, p.Name)
+		}
+	}
+	if err := model.LoadParameters(values); err != nil {
+		return nil, fmt.Errorf("training: restore parameters: %w", err)
+	}
+
+	if ckpt.Optimizer != nil {
+		saver, ok := opt.(optimizer.StateSaver[T])
+		if !ok {
+			return nil, fmt.Errorf("training: checkpoint %s has optimizer state, but optimizer %T cannot load it", path, opt)
+		}
+		if err := saver.LoadState(params, ckpt.Optimizer); err != nil {
+			return nil, fmt.Errorf("training: restore optimizer state: %w", err)
+		}
+	}
+	if m.rng != nil && ckpt.RNG != nil {
+		if err := m.rng.UnmarshalBinary(ckpt.RNG); err != nil {
+			return nil, fmt.Errorf("training: restore RNG state: %w", err)
+		}
+	}
+	return ckpt, nil
+}
+
+// Latest returns the path of the latest checkpoint in the directory, or
+// "" if there is none.
+func (m *CheckpointManager[T]) Latest() (string, error) {
+	steps, err := m.steps()
+	if err != nil || len(steps) == 0 {
+		return "", err
+	}
+	return filepath.Join(m.dir, checkpointName(steps[len(steps)-1])), nil
+}
+
+// Checkpoints returns the info of the checkpoints in the directory, in
+// step order. A checkpoint without an info file has only its Path and
+// Step set.
+func (m *CheckpointManager[T]) Checkpoints() ([]CheckpointInfo, error) {
+	steps, err := m.steps()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]CheckpointInfo, len(steps))
+	for i, step := range steps {
+		path := filepath.Join(m.dir, checkpointName(step))
+		infos[i] = CheckpointInfo{Path: path, Step: step}
+		data, err := os.ReadFile(infoPath(path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("training: read checkpoint info: %w", err)
+		}
+		if err := json.Unmarshal(data, &infos[i]); err != nil {
+			return nil, fmt.Errorf("training: decode checkpoint info %s: %w", infoPath(path), err)
+		}
+		infos[i].Path = path
+	}
+	return infos, nil
+}
+
+// Best returns the info of the best checkpoint in the directory by the
+// metric of WithCheckpointBest, and false if no checkpoint has it.
+func (m *CheckpointManager[T]) Best() (CheckpointInfo, bool, error) {
+	infos, err := m.Checkpoints()
+	if err != nil || m.metric == "" {
+		return CheckpointInfo{}, false, err
+	}
+	ranked := m.rank(infos)
+	if len(ranked) == 0 {
+		return CheckpointInfo{}, false, nil
+	}
+	return ranked[0], true, nil
+}
+
+// rank returns the checkpoints of infos that have the monitored metric,
+// best first; ties go to the earlier step.
+func (m *CheckpointManager[T]) rank(infos []CheckpointInfo) []CheckpointInfo {
+	var ranked []CheckpointInfo
+	for _, info := range infos {
+		if _, ok := info.Metrics[m.metric]; ok {
+			ranked = append(ranked, info)
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b CheckpointInfo) int {
+		va, vb := a.Metrics[m.metric], b.Metrics[m.metric]
+		if m.metricMode == "max" {
+			va, vb = vb, va
+		}
+		switch {
+		case va < vb:
+			return -1
+		case va > vb:
+			return 1
+		}
+		return 0
+	})
+	return ranked
+}
+
+// prune deletes all but the latest keep checkpoints and the best ones.
+func (m *CheckpointManager[T]) prune() error {
+	if m.keep == 0 {
+		return nil
+	}
+	infos, err := m.Checkpoints()
+	if err != nil {
+		return err
+	}
+	retain := make(map[int]bool)
+	for _, info := range infos[max(len(infos)-m.keep, 0):] {
+		retain[info.Step] = true
+	}
+	for i, info := range m.rank(infos) {
+		if i == m.best {
+			break
+		}
+		retain[info.Step] = true
+	}
+	for _, info := range infos {
+		if retain[info.Step] {
+			continue
+		}
+		for _, p := range []string{info.Path, infoPath(info.Path)} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("training: prune checkpoint: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// steps returns the steps of the checkpoints in the directory, in order.
+func (m *CheckpointManager[T]) steps() ([]int, error) {
+	matches, err := filepath.Glob(filepath.Join(m.dir, "step-*.ckpt"))
+	if err != nil {
+		return nil, fmt.Errorf("training: list checkpoints: %w", err)
+	}
+	steps := make([]int, 0, len(matches))
+	for _, p := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "step-"), ".ckpt")
+		if step, err := strconv.Atoi(name); err == nil {
+			steps = append(steps, step)
+		}
+	}
+	slices.Sort(steps)
+	return steps, nil
+}
+
+func checkpointName(step int) string {
+	return fmt.Sprintf("step-%09d.ckpt", step)
+}
+
+// infoPath returns the path of the CheckpointInfo of the checkpoint at
+// path.
+func infoPath(path string) string {
+	return strings.TrimSuffix(path, ".ckpt") + ".json"
+}
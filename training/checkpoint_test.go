@@ -0,0 +1,236 @@
+package training_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/zerfoo/training/scheduler"
+)
+
+func newCheckpointWorkflow(t *testing.T, ckpts *training.CheckpointManager[float32]) *training.StandardWorkflow[float32] {
+	t.Helper()
+	w := training.NewStandardWorkflow(
+		func(e compute.Engine[float32]) graph.Node[float32] { return loss.NewMSE(e, e.Ops()) },
+		func(e compute.Engine[float32], lr float64) optimizer.Optimizer[float32] {
+			return optimizer.NewAdamW(e, float32(lr), 0.9, 0.999, 1e-8, 0.01)
+		},
+		training.WithCheckpoints(ckpts),
+		training.WithLRScheduler(func(lr float64, _ int) scheduler.Scheduler[float32] {
+			s, err := scheduler.NewStepDecay(scheduler.StepDecayConfig[float32]{InitialLR: float32(lr), StepSize: 1, Gamma: 0.5})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}),
+	)
+	if err := w.Initialize(context.Background(), training.WorkflowConfig{NumEpochs: 4, LearningRate: 0.05}); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestStandardWorkflow_Resume(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	data := &staticData{train: rig.batches(t, 1, 5), valid: rig.batches(t, 2, 2)}
+	dir := t.TempDir()
+	ckpts, err := training.NewCheckpointManager(dir, training.WithCheckpointEvery[float32](3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := newCheckpointWorkflow(t, ckpts).Train(ctx, data, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantParams := make([][]float32, 0)
+	for _, p := range rig.g.Parameters() {
+		wantParams = append(wantParams, slices.Clone(p.Value.Data()))
+	}
+	latest, err := ckpts.Latest()
+	if err != nil || filepath.Base(latest) != "step-000000018.ckpt" {
+		t.Fatalf("Latest = %q, %v; want step 18 of 20", latest, err)
+	}
+
+	// Step 12 is mid-epoch (epoch 2, step 2 of 5); step 15 ends epoch 2.
+	for _, step := range []string{"step-000000012.ckpt", "step-000000015.ckpt"} {
+		for _, p := range rig.g.Parameters() {
+			clear(p.Value.Data())
+		}
+		got, err := newCheckpointWorkflow(t, ckpts).Resume(ctx, filepath.Join(dir, step), data, &rigModels{g: rig.g})
+		if err != nil {
+			t.Fatalf("%s: %v", step, err)
+		}
+		for i, p := range rig.g.Parameters() {
+			if !slices.Equal(p.Value.Data(), wantParams[i]) {
+				t.Errorf("%s: %s = %v, want %v", step, p.Name, p.Value.Data(), wantParams[i])
+			}
+		}
+		if got.FinalLoss != want.FinalLoss || got.BestLoss != want.BestLoss || got.BestEpoch != want.BestEpoch ||
+			got.TotalEpochs != want.TotalEpochs || got.Metrics["train_loss"] != want.Metrics["train_loss"] ||
+			got.Metrics["learning_rate"] != want.Metrics["learning_rate"] {
+			t.Errorf("%s: result = %+v, want %+v", step, got, want)
+		}
+	}
+
+	if _, err := newCheckpointWorkflow(t, ckpts).Resume(ctx, filepath.Join(dir, "missing.ckpt"), data, &rigModels{g: rig.g}); err == nil {
+		t.Error("expected an error for a missing checkpoint")
+	}
+}
+
+func TestCheckpointManager(t *testing.T) {
+	rig := newRegressionRig(t)
+	engine := rig.g.Engine()
+	opt := optimizer.NewSGD(engine, engine.Ops(), 0.1)
+	src := rand.NewPCG(1, 2)
+	dir := t.TempDir()
+	m, err := training.NewCheckpointManager(dir,
+		training.WithCheckpointKeep[float32](2),
+		training.WithCheckpointRNG[float32](src),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Due(999) || !m.Due(1000) {
+		t.Error("the default interval should be 1000 steps")
+	}
+
+	for step := 1; step <= 3; step++ {
+		rig.g.Parameters()[0].Value.Data()[0] = float32(step)
+		if _, err := m.Save(&training.Checkpoint{Step: step}, rig.g, opt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, _ := filepath.Glob(filepath.Join(dir, "*.ckpt"))
+	if len(entries) != 2 {
+		t.Errorf("%d checkpoints kept, want 2", len(entries))
+	}
+
+	latest, err := m.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDraw := rand.New(rand.NewPCG(1, 2)).Uint64()
+	src.Uint64() // advance the source past the saved state
+	rig.g.Parameters()[0].Value.Data()[0] = 0
+	ckpt, err := m.Restore(latest, rig.g, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ckpt.Step != 3 || rig.g.Parameters()[0].Value.Data()[0] != 3 {
+		t.Errorf("restored step %d, parameter %v; want 3, 3", ckpt.Step, rig.g.Parameters()[0].Value.Data()[0])
+	}
+	if got := src.Uint64(); got != wantDraw {
+		t.Errorf("RNG draws %d after restore, want %d", got, wantDraw)
+	}
+
+	if _, err := training.NewCheckpointManager[float32](dir, training.WithCheckpointEvery[float32](0)); err == nil {
+		t.Error("expected an error for a zero interval")
+	}
+	sophia := optimizer.NewSophia(engine, 0.1)
+	if _, err := m.Save(&training.Checkpoint{Step: 4}, rig.g, sophia); err == nil {
+		t.Error("expected an error for an optimizer with state it cannot save")
+	}
+}
+
+func TestCheckpointManager_BestRetention(t *testing.T) {
+	rig := newRegressionRig(t)
+	opt := optimizer.NewSGD(rig.g.Engine(), rig.g.Engine().Ops(), 0.1)
+	dir := t.TempDir()
+	m, err := training.NewCheckpointManager(dir,
+		training.WithCheckpointKeep[float32](1),
+		training.WithCheckpointBest[float32](2, "val_loss", "min"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step, loss := range []float64{5, 1, 4, 2, 3} {
+		ckpt := &training.Checkpoint{Step: step + 1, Epoch: step, Metrics: map[string]float64{"val_loss": loss}}
+		if _, err := m.Save(ckpt, rig.g, opt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The latest checkpoint, step 5, and the two best, steps 2 and 4.
+	infos, err := m.Checkpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []int
+	for _, info := range infos {
+		steps = append(steps, info.Step)
+	}
+	if !slices.Equal(steps, []int{2, 4, 5}) {
+		t.Errorf("kept steps %v, want [2 4 5]", steps)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 6 {
+		t.Errorf("%d files in the directory, want 3 checkpoints and their info", len(files))
+	}
+	best, ok, err := m.Best()
+	if err != nil || !ok || best.Step != 2 || best.Epoch != 1 || best.Metrics["val_loss"] != 1 {
+		t.Errorf("Best = %+v, %v, %v; want step 2", best, ok, err)
+	}
+	if ckpt, err := training.ReadCheckpoint(best.Path); err != nil || ckpt.Metrics["val_loss"] != 1 {
+		t.Errorf("best checkpoint metrics = %v, %v", ckpt, err)
+	}
+
+	for _, opts := range [][]training.CheckpointOption[float32]{
+		{training.WithCheckpointBest[float32](1, "", "min")},
+		{training.WithCheckpointBest[float32](-1, "val_loss", "min")},
+		{training.WithCheckpointBest[float32](1, "val_loss", "median")},
+	} {
+		if _, err := training.NewCheckpointManager(dir, opts...); err == nil {
+			t.Error("expected an error for invalid best retention")
+		}
+	}
+}
+
+func TestStandardWorkflow_BestCheckpoint(t *testing.T) {
+	rig := newRegressionRig(t)
+	data := &staticData{train: rig.batches(t, 1, 5), valid: rig.batches(t, 2, 2)}
+	ckpts, err := training.NewCheckpointManager(t.TempDir(),
+		training.WithCheckpointEvery[float32](3),
+		training.WithCheckpointBest[float32](1, "val_loss", "min"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := newCheckpointWorkflow(t, ckpts).Train(context.Background(), data, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := ckpts.Checkpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every epoch ends with a checkpoint of its metrics; step checkpoints
+	// carry those of the previous epoch.
+	var epochEnds []int
+	for _, info := range infos {
+		_, hasLoss := info.Metrics["val_loss"]
+		if info.EpochStep == 0 {
+			epochEnds = append(epochEnds, info.Step)
+		}
+		if hasLoss != (info.Epoch > 0) {
+			t.Errorf("checkpoint %+v: val_loss present = %v", info, hasLoss)
+		}
+	}
+	if !slices.Equal(epochEnds, []int{5, 10, 15, 20}) {
+		t.Errorf("epoch-end checkpoints at steps %v, want [5 10 15 20]", epochEnds)
+	}
+	best, ok, err := ckpts.Best()
+	if err != nil || !ok {
+		t.Fatalf("Best = %v, %v", ok, err)
+	}
+	if best.Epoch != res.BestEpoch+1 || best.EpochStep != 0 || best.Metrics["val_loss"] != float64(res.BestLoss) {
+		t.Errorf("best checkpoint %+v, want the end of epoch %d with val_loss %v", best, res.BestEpoch, res.BestLoss)
+	}
+}
@@ -0,0 +1,156 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	opt "github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GradientClipper limits parameter gradients in place after the backward
+// pass and before the optimizer step.
+type GradientClipper[T tensor.Numeric] interface {
+	ClipGradients(ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T]) error
+}
+
+// NormClipper scales all gradients together so that their global L2 norm
+// is at most a threshold, preserving the gradient's direction.
+type NormClipper[T tensor.Numeric] struct {
+	maxNorm float64
+}
+
+// NewNormClipper returns a NormClipper that clips the global gradient norm
+// to maxNorm. A maxNorm <= 0 disables clipping.
+func NewNormClipper[T tensor.Numeric](maxNorm float64) *NormClipper[T] {
+	return &NormClipper[T]{maxNorm: maxNorm}
+}
+
+// ClipGradients implements GradientClipper.
+func (c *NormClipper[T]) ClipGradients(ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T]) error {
+	if c.maxNorm <= 0 {
+		return nil
+	}
+	if _, err := opt.ClipGradNorm(ctx, engine, params, c.maxNorm); err != nil {
+		return fmt.Errorf("training: clip gradient norm: %w", err)
+	}
+	return nil
+}
+
+// ValueClipper clamps every gradient element to [-maxValue, maxValue]
+// independently. Unlike NormClipper it can change the gradient's direction.
+type ValueClipper[T tensor.Numeric] struct {
+	maxValue float64
+}
+
+// NewValueClipper returns a ValueClipper that clamps gradient elements to
+// [-maxValue, maxValue]. A maxValue <= 0 disables clipping.
+func NewValueClipper[T tensor.Numeric](maxValue float64) *ValueClipper[T] {
+	return &ValueClipper[T]{maxValue: maxValue}
+}
+
+// ClipGradients implements GradientClipper.
+func (c *ValueClipper[T]) ClipGradients(ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T]) error {
+	if c.maxValue <= 0 {
+		return nil
+	}
+	ops := engine.Ops()
+	hi := ops.FromFloat64(c.maxValue)
+	lo := ops.FromFloat64(-c.maxValue)
+	clamp := func(v T) T {
+		switch {
+		case ops.GreaterThan(v, hi):
+			return hi
+		case ops.GreaterThan(lo, v):
+			return lo
+		}
+		return v
+	}
+	seen := make(map[*graph.Parameter[T]]bool, len(params))
+	for _, p := range params {
+		if p.Gradient == nil || seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := engine.UnaryOp(ctx, p.Gradient, clamp, p.Gradient); err != nil {
+			return fmt.Errorf("training: clip gradient values of %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// clipperChain applies its clippers in order.
+type clipperChain[T tensor.Numeric] []GradientClipper[T]
+
+// ClipGradients implements GradientClipper.
+func (c clipperChain[T]) ClipGradients(ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T]) error {
+	for _, clipper := range c {
+		if err := clipper.ClipGradients(ctx, engine, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configClipper returns the clipper config asks for, or nil if it asks for
+// none. Values are clamped before the norm is clipped, so the norm bound
+// holds for the gradient the optimizer sees.
+func configClipper[T tensor.Numeric](config WorkflowConfig) GradientClipper[T] {
+	var chain clipperChain[T]
+	if config.ClipValue > 0 {
+		chain = append(chain, NewValueClipper[T](config.ClipValue))
+	}
+	if config.ClipNorm > 0 {
+		chain = append(chain, NewNormClipper[T](config.ClipNorm))
+	}
+	switch len(chain) {
+	case 0:
+		return nil
+	case 1:
+		return chain[0]
+	}
+	return chain
+}
+
+// clippingOptimizer clips gradients before delegating to the wrapped
+// optimizer, for trainers whose step the caller does not control.
+type clippingOptimizer[T tensor.Numeric] struct {
+	opt.Optimizer[T]
+	engine  compute.Engine[T]
+	clipper GradientClipper[T]
+}
+
+// newClippingOptimizer wraps o so that each Step first applies clipper.
+func newClippingOptimizer[T tensor.Numeric](o opt.Optimizer[T], engine compute.Engine[T], clipper GradientClipper[T]) (*clippingOptimizer[T], error) {
+	if engine == nil {
+		return nil, errors.New("training: gradient clipping requires a graph with an engine")
+	}
+	return &clippingOptimizer[T]{Optimizer: o, engine: engine, clipper: clipper}, nil
+}
+
+// Step clips the gradients of params and steps the wrapped optimizer.
+func (c *clippingOptimizer[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
+	if err := c.clipper.ClipGradients(ctx, c.engine, params); err != nil {
+		return err
+	}
+	return c.Optimizer.Step(ctx, params)
+}
+
+// SetZeroGradOnStep forwards to the wrapped optimizer, if it supports it, so
+// trainers applying a GradPolicy still reach it.
+func (c *clippingOptimizer[T]) SetZeroGradOnStep(zero bool) {
+	if z, ok := c.Optimizer.(opt.GradientZeroer); ok {
+		z.SetZeroGradOnStep(zero)
+	}
+}
+
+// Statically assert that the types implement their interfaces.
+var (
+	_ GradientClipper[float32] = (*NormClipper[float32])(nil)
+	_ GradientClipper[float32] = (*ValueClipper[float32])(nil)
+	_ GradientClipper[float32] = clipperChain[float32](nil)
+	_ opt.GradientZeroer       = (*clippingOptimizer[float32])(nil)
+)
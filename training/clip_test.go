@@ -0,0 +1,112 @@
+package training_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+func TestGradientClippers(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	param := func(grad ...float32) *graph.Parameter[float32] {
+		t.Helper()
+		v, err := tensor.New[float32]([]int{len(grad)}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := tensor.New([]int{len(grad)}, grad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &graph.Parameter[float32]{Name: "p", Value: v, Gradient: g}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		clipper training.GradientClipper[float32]
+		grad    []float32
+		want    []float32
+	}{
+		{"value", training.NewValueClipper[float32](1), []float32{3, -5, 0.5}, []float32{1, -1, 0.5}},
+		{"norm", training.NewNormClipper[float32](1), []float32{3, 4}, []float32{0.6, 0.8}},
+		{"norm within bound", training.NewNormClipper[float32](10), []float32{3, 4}, []float32{3, 4}},
+		{"disabled", training.NewValueClipper[float32](0), []float32{3, -5}, []float32{3, -5}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := param(tc.grad...)
+			// A parameter listed twice is clipped once.
+			if err := tc.clipper.ClipGradients(ctx, engine, []*graph.Parameter[float32]{p, p, {Name: "nograd"}}); err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range p.Gradient.Data() {
+				if math.Abs(float64(v-tc.want[i])) > 1e-6 {
+					t.Fatalf("gradient = %v, want %v", p.Gradient.Data(), tc.want)
+				}
+			}
+		})
+	}
+}
+
+// maxParamStep runs train on one batch of rig's data and returns the largest
+// change of any parameter.
+func maxParamStep(t *testing.T, rig *regressionRig, train func(training.DataProvider[float32], training.ModelProvider[float32]) error) float64 {
+	t.Helper()
+	before := make(map[*graph.Parameter[float32]][]float32)
+	for _, p := range rig.g.Parameters() {
+		before[p] = append([]float32(nil), p.Value.Data()...)
+	}
+	if err := train(&staticData{train: rig.batches(t, 1, 1)}, &rigModels{g: rig.g}); err != nil {
+		t.Fatal(err)
+	}
+	var most float64
+	for p, old := range before {
+		for i, v := range p.Value.Data() {
+			most = max(most, math.Abs(float64(v-old[i])))
+		}
+	}
+	return most
+}
+
+func TestWorkflows_ClipValue(t *testing.T) {
+	ctx := context.Background()
+	cfg := training.WorkflowConfig{NumEpochs: 1, LearningRate: 1, ClipValue: 0.01}
+
+	rig := newRegressionRig(t)
+	got := maxParamStep(t, rig, func(d training.DataProvider[float32], m training.ModelProvider[float32]) error {
+		w := newSGDWorkflow()
+		if err := w.Initialize(ctx, cfg); err != nil {
+			return err
+		}
+		_, err := w.Train(ctx, d, m)
+		return err
+	})
+	if got > 0.01+1e-6 || got == 0 {
+		t.Errorf("standard workflow: largest step = %v, want (0, 0.01]", got)
+	}
+
+	rig = newRegressionRig(t)
+	engine := rig.g.Engine()
+	sgd := optimizer.NewSGD(engine, engine.Ops(), float32(cfg.LearningRate))
+	trainer := training.NewDefaultTrainer[float32](rig.g, loss.NewMSE(engine, engine.Ops()), sgd, nil)
+	got = maxParamStep(t, rig, func(d training.DataProvider[float32], m training.ModelProvider[float32]) error {
+		a := training.NewTrainerWorkflowAdapter[float32](trainer, sgd)
+		if err := a.Initialize(ctx, cfg); err != nil {
+			return err
+		}
+		_, err := a.Train(ctx, d, m)
+		return err
+	})
+	if got > 0.01+1e-6 || got == 0 {
+		t.Errorf("adapter: largest step = %v, want (0, 0.01]", got)
+	}
+}
@@ -0,0 +1,223 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// ContinueOptions configures StandardWorkflow.ContinueTraining.
+type ContinueOptions struct {
+	// Epochs is the number of passes over the new data. Zero means one.
+	Epochs int
+	// LearningRate, if positive, becomes the optimizer's learning rate for
+	// this and later updates. Incremental updates usually want a rate well
+	// below the one the model was trained with. Zero keeps the current rate.
+	LearningRate float64
+	// Replay is the number of past batches drawn from the replay buffer and
+	// mixed into each epoch. It requires WithReplayBuffer.
+	Replay int
+}
+
+// ContinueTraining updates the model from the last Train call with the
+// training data of dataset, without starting over: the weights, the
+// optimizer and its state (such as Adam moments), and the trainer carry
+// over. Each epoch trains on the new batches, together with opts.Replay
+// batches replayed from earlier data, in random order, then evaluates the
+// validation data. The new batches are read into memory and added to the
+// replay buffer afterwards. Early stopping and the learning rate scheduler
+// belong to the full run and are not applied; MaxWallClock and
+// CheckpointPath are.
+func (w *StandardWorkflow[T]) ContinueTraining(ctx context.Context, dataset DataProvider[T], opts ContinueOptions) (*TrainingResult[T], error) {
+	epochs := opts.Epochs
+	switch {
+	case w.trainer == nil:
+		return nil, errors.New("standard workflow: ContinueTraining called before Train")
+	case epochs < 0:
+		return nil, fmt.Errorf("standard workflow: Epochs must not be negative, got %d", epochs)
+	case opts.LearningRate < 0:
+		return nil, fmt.Errorf("standard workflow: LearningRate must not be negative, got %g", opts.LearningRate)
+	case opts.Replay < 0:
+		return nil, fmt.Errorf("standard workflow: Replay must not be negative, got %d", opts.Replay)
+	case opts.Replay > 0 && w.replay == nil:
+		return nil, errors.New("standard workflow: Replay needs WithReplayBuffer")
+	}
+	if epochs == 0 {
+		epochs = 1
+	}
+	if opts.LearningRate > 0 {
+		setter, ok := w.opt.(optimizer.LRSetter)
+		if !ok {
+			return nil, fmt.Errorf("standard workflow: optimizer %T does not support setting the learning rate", w.opt)
+		}
+		setter.SetLRFloat64(opts.LearningRate)
+	}
+	start := w.now()
+	model := w.model
+	defer regularization.SetTrainingMode(model, false)
+
+	trainData, validData, err := w.openData(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	if validData != nil {
+		defer func() { _ = validData.Close() }()
+	}
+	var fresh []*Batch[T]
+	for trainData.Next(ctx) {
+		if b := trainData.Batch(); b != nil {
+			fresh = append(fresh, b)
+		}
+	}
+	err = trainData.Error()
+	_ = trainData.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read training data: %w", err)
+	}
+	if len(fresh) == 0 {
+		return nil, errors.New("standard workflow: no new training data")
+	}
+
+	timeUp := func() bool {
+		return w.config.MaxWallClock > 0 && w.now().Sub(start) >= w.config.MaxWallClock
+	}
+	result := &TrainingResult[T]{
+		StopReason: StopCompleted,
+		Metrics:    make(map[string]float64),
+		Extensions: make(map[string]interface{}),
+	}
+	for epoch := range epochs {
+		batches := slices.Clone(fresh)
+		if opts.Replay > 0 {
+			batches = append(batches, w.replay.sample(opts.Replay)...)
+			w.replay.rng.Shuffle(len(batches), func(i, j int) {
+				batches[i], batches[j] = batches[j], batches[i]
+			})
+		}
+		trainLoss, steps, stopped, err := w.trainEpoch(ctx, w.trainer, model, w.opt, NewDataIteratorAdapter(batches), timeUp, nil, epochProgress{}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+		}
+		if stopped {
+			result.StopReason = StopMaxWallClock
+			if steps == 0 {
+				break
+			}
+		}
+
+		monitored := trainLoss
+		epochValues := map[string]interface{}{"epoch": epoch, "train_loss": float64(trainLoss)}
+		if validData != nil {
+			validation, err := w.evaluate(ctx, model, validData)
+			if err != nil {
+				return nil, fmt.Errorf("epoch %d: validation: %w", epoch, err)
+			}
+			monitored = validation.Loss
+			epochValues["val_loss"] = float64(validation.Loss)
+			for name, v := range validation.Metrics {
+				epochValues[name] = v
+			}
+		}
+		w.lastValues = epochValues
+
+		result.FinalLoss = monitored
+		if epoch == 0 || monitored < result.BestLoss {
+			result.BestLoss = monitored
+			result.BestEpoch = epoch
+		}
+		if stopped {
+			break
+		}
+		result.TotalEpochs = epoch + 1
+	}
+	if w.replay != nil {
+		for _, b := range fresh {
+			w.replay.add(b)
+		}
+	}
+
+	for name, v := range w.lastValues {
+		if f, ok := v.(float64); ok {
+			result.Metrics[name] = f
+		}
+	}
+	if w.config.CheckpointPath != "" {
+		if err := w.models.SaveModel(ctx, model, w.config.CheckpointPath); err != nil {
+			return nil, fmt.Errorf("failed to save model: %w", err)
+		}
+		result.ModelPath = w.config.CheckpointPath
+	}
+	result.TrainingTime = w.now().Sub(start).Seconds()
+	return result, nil
+}
+
+// replayBuffer keeps a uniform random sample of the batches offered to it,
+// by reservoir sampling.
+type replayBuffer[T tensor.Numeric] struct {
+	capacity int
+	seen     int
+	batches  []*Batch[T]
+	rng      *rand.Rand
+}
+
+func newReplayBuffer[T tensor.Numeric](capacity int, seed uint64) *replayBuffer[T] {
+	return &replayBuffer[T]{capacity: capacity, rng: rand.New(rand.NewPCG(seed, 0))}
+}
+
+// add offers b to the buffer, which stores a copy if it keeps it.
+func (r *replayBuffer[T]) add(b *Batch[T]) {
+	r.seen++
+	if len(r.batches) < r.capacity {
+		r.batches = append(r.batches, cloneBatch(b))
+		return
+	}
+	if i := r.rng.IntN(r.seen); i < r.capacity {
+		r.batches[i] = cloneBatch(b)
+	}
+}
+
+// fillReplay offers every batch of data to r, as the first epoch of Train
+// does, for a run resumed after it.
+func fillReplay[T tensor.Numeric](ctx context.Context, r *replayBuffer[T], data DataIterator[T]) error {
+	if err := data.Reset(); err != nil {
+		return fmt.Errorf("failed to reset training data: %w", err)
+	}
+	for data.Next(ctx) {
+		if b := data.Batch(); b != nil {
+			r.add(b)
+		}
+	}
+	if err := data.Error(); err != nil {
+		return fmt.Errorf("training data: %w", err)
+	}
+	return nil
+}
+
+// sample returns up to n distinct batches from the buffer.
+func (r *replayBuffer[T]) sample(n int) []*Batch[T] {
+	n = min(n, len(r.batches))
+	out := make([]*Batch[T], n)
+	for i, j := range r.rng.Perm(len(r.batches))[:n] {
+		out[i] = r.batches[j]
+	}
+	return out
+}
+
+// cloneBatch copies b's tensors, since data iterators may reuse them.
+func cloneBatch[T tensor.Numeric](b *Batch[T]) *Batch[T] {
+	c := &Batch[T]{Inputs: make(map[graph.Node[T]]*tensor.TensorNumeric[T], len(b.Inputs))}
+	for node, t := range b.Inputs {
+		c.Inputs[node] = t.Copy()
+	}
+	if b.Targets != nil {
+		c.Targets = b.Targets.Copy()
+	}
+	return c
+}
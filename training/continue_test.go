@@ -0,0 +1,145 @@
+package training_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+// countingSGD is SGD that counts its steps and records its learning rate.
+type countingSGD struct {
+	*optimizer.SGD[float32]
+	steps int
+	lr    float64
+}
+
+func (o *countingSGD) Step(ctx context.Context, params []*graph.Parameter[float32]) error {
+	o.steps++
+	return o.SGD.Step(ctx, params)
+}
+
+func (o *countingSGD) SetLRFloat64(lr float64) {
+	o.lr = lr
+	o.SGD.SetLRFloat64(lr)
+}
+
+// newCountingWorkflow returns an SGD workflow and the optimizers it built.
+func newCountingWorkflow(opts ...training.StandardWorkflowOption[float32]) (*training.StandardWorkflow[float32], *[]*countingSGD) {
+	var built []*countingSGD
+	w := training.NewStandardWorkflow(
+		func(e compute.Engine[float32]) graph.Node[float32] { return loss.NewMSE(e, e.Ops()) },
+		func(e compute.Engine[float32], lr float64) optimizer.Optimizer[float32] {
+			o := &countingSGD{SGD: optimizer.NewSGD(e, e.Ops(), float32(lr)), lr: lr}
+			built = append(built, o)
+			return o
+		},
+		opts...,
+	)
+	return w, &built
+}
+
+// shifted returns batches whose targets are raised by delta.
+func shifted(batches []*training.Batch[float32], delta float32) []*training.Batch[float32] {
+	for _, b := range batches {
+		data := b.Targets.Data()
+		for i := range data {
+			data[i] += delta
+		}
+	}
+	return batches
+}
+
+func TestStandardWorkflow_ContinueTraining(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	models := &rigModels{g: rig.g}
+	w, built := newCountingWorkflow()
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 40, LearningRate: 0.1, CheckpointPath: "model.gguf"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ContinueTraining(ctx, &staticData{train: rig.batches(t, 1, 1)}, training.ContinueOptions{}); err == nil {
+		t.Error("ContinueTraining before Train should fail")
+	}
+	if _, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 8)}, models); err != nil {
+		t.Fatal(err)
+	}
+	trainSteps := (*built)[0].steps
+
+	// The relationship drifts: the intercept moves from 0.5 to 1.
+	update := &staticData{train: shifted(rig.batches(t, 3, 4), 0.5), valid: shifted(rig.batches(t, 4, 2), 0.5)}
+	before, err := w.Validate(ctx, update, models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.ContinueTraining(ctx, update, training.ContinueOptions{Epochs: 10, LearningRate: 0.05})
+	if err != nil {
+		t.Fatalf("ContinueTraining: %v", err)
+	}
+	if len(*built) != 1 {
+		t.Fatalf("%d optimizers built, want the one from Train reused", len(*built))
+	}
+	opt := (*built)[0]
+	if got := opt.steps - trainSteps; got != 40 {
+		t.Errorf("ContinueTraining took %d steps, want 10 epochs x 4 batches", got)
+	}
+	if opt.lr != 0.05 {
+		t.Errorf("learning rate = %v, want 0.05", opt.lr)
+	}
+	if result.TotalEpochs != 10 || result.FinalLoss > before.Loss/10 {
+		t.Errorf("TotalEpochs = %d, validation loss %v -> %v; want 10 epochs and a 10x drop", result.TotalEpochs, before.Loss, result.FinalLoss)
+	}
+	if result.ModelPath != "model.gguf" || len(models.saved) != 2 {
+		t.Errorf("ModelPath = %q, saved %v", result.ModelPath, models.saved)
+	}
+
+	// Without a replay buffer there is nothing to replay.
+	if _, err := w.ContinueTraining(ctx, update, training.ContinueOptions{Replay: 1}); err == nil {
+		t.Error("Replay without WithReplayBuffer should fail")
+	}
+	if _, err := w.ContinueTraining(ctx, &staticData{}, training.ContinueOptions{}); err == nil {
+		t.Error("ContinueTraining without new data should fail")
+	}
+}
+
+func TestStandardWorkflow_ContinueTrainingReplay(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	models := &rigModels{g: rig.g}
+	w, built := newCountingWorkflow(training.WithReplayBuffer[float32](3))
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 5, LearningRate: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 8)}, models); err != nil {
+		t.Fatal(err)
+	}
+	opt := (*built)[0]
+
+	// Each epoch trains on the 2 new batches and 2 replayed ones.
+	steps := opt.steps
+	update := &staticData{train: rig.batches(t, 2, 2)}
+	if _, err := w.ContinueTraining(ctx, update, training.ContinueOptions{Epochs: 3, Replay: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := opt.steps - steps; got != 12 {
+		t.Errorf("took %d steps, want 3 epochs x (2 new + 2 replayed)", got)
+	}
+
+	// Replay is capped by the buffer's capacity.
+	steps = opt.steps
+	if _, err := w.ContinueTraining(ctx, update, training.ContinueOptions{Replay: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if got := opt.steps - steps; got != 5 {
+		t.Errorf("took %d steps, want 2 new + 3 replayed", got)
+	}
+
+	if w, _ := newCountingWorkflow(training.WithReplayBuffer[float32](-1)); w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 1, LearningRate: 0.1}) == nil {
+		t.Error("negative replay capacity accepted")
+	}
+}
@@ -0,0 +1,134 @@
+package training
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// CSVDataProviderName is the name the CSV data provider is registered under
+// in Float32Registry and Float64Registry.
+const CSVDataProviderName = "csv"
+
+// CSVConfig configures a CSVDataProvider. Path is the CSV file; its first
+// row names the columns.
+type CSVConfig[T tensor.Numeric] = TableConfig[T]
+
+// CSVDataProvider is a DataProvider streaming batches from a CSV file. It
+// never holds more of the file in memory than one batch, or the shuffle
+// buffer when shuffling. Feature, target, ID and group columns are selected
+// by name from the header row; feature and target cells must be numbers.
+//
+// Rows are split between training and validation by a hash of the seed and
+// the row's group, its ID when there is no group column, or its line number,
+// so the split is the same every epoch and every run with the same seed.
+// Each Reset of a shuffled training iterator starts a new epoch with a new
+// order, drawn from the seed and the epoch number. Its iterators are
+// CSVIterators.
+type CSVDataProvider[T tensor.Numeric] struct {
+	*tableData[T]
+}
+
+// NewCSVDataProvider returns a provider for config. It reads only the
+// header of the file, to resolve the columns.
+func NewCSVDataProvider[T tensor.Numeric](config CSVConfig[T]) (*CSVDataProvider[T], error) {
+	d, err := newTableData("csv", config, func() ([]string, error) {
+		f, err := os.Open(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		header, err := csv.NewReader(f).Read()
+		if err != nil {
+			return nil, fmt.Errorf("%s: reading header: %w", config.Path, err)
+		}
+		return header, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.source = func() (rowSource, error) {
+		f, err := os.Open(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		src := &csvSource{file: f}
+		if err := src.rewind(); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("%s: %w", config.Path, err)
+		}
+		return src, nil
+	}
+	return &CSVDataProvider[T]{d}, nil
+}
+
+// csvSource is the rowSource of a CSV file.
+type csvSource struct {
+	file   *os.File
+	reader *csv.Reader
+	record []string
+}
+
+func (s *csvSource) next() (bool, error) {
+	rec, err := s.reader.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.record = rec
+	return true, nil
+}
+
+func (s *csvSource) text(c int) string {
+	return s.record[c]
+}
+
+func (s *csvSource) float(c int) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s.record[c]), 64)
+}
+
+// position returns the line number of the current row.
+func (s *csvSource) position() string {
+	line, _ := s.reader.FieldPos(0)
+	return strconv.Itoa(line)
+}
+
+// rewind seeks to the first row after the header.
+func (s *csvSource) rewind() error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.reader = csv.NewReader(s.file)
+	s.reader.ReuseRecord = true
+	if _, err := s.reader.Read(); err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	return nil
+}
+
+func (s *csvSource) close() error {
+	return s.file.Close()
+}
+
+// newCSVDataProviderFactory returns the registry factory for the CSV data
+// provider, configured by the keys of newTableDataProviderFactory.
+func newCSVDataProviderFactory[T tensor.Numeric]() DataProviderFactory[T] {
+	return newTableDataProviderFactory("csv", func(c TableConfig[T]) (DataProvider[T], error) {
+		return NewCSVDataProvider(c)
+	})
+}
+
+func init() {
+	_ = Float32Registry.RegisterDataProvider(CSVDataProviderName, newCSVDataProviderFactory[float32]())
+	_ = Float64Registry.RegisterDataProvider(CSVDataProviderName, newCSVDataProviderFactory[float64]())
+}
+
+// Statically assert that the type implements the DataProvider interface.
+var _ DataProvider[float32] = (*CSVDataProvider[float32])(nil)
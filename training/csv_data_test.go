@@ -0,0 +1,244 @@
+package training_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/training"
+)
+
+// writeRegressionCSV writes n rows of y = 2*x0 - x1 + 0.5 with an id, a
+// group of 5 consecutive rows, and an unused note column, and returns the
+// path.
+func writeRegressionCSV(t *testing.T, n int) string {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(1, 0))
+	var sb strings.Builder
+	sb.WriteString("id,group,x0,note,x1,y\n")
+	for i := range n {
+		x0, x1 := rng.Float64()*2-1, rng.Float64()*2-1
+		fmt.Fprintf(&sb, "r%d,g%d,%g,\"a, b\",%g,%g\n", i, i/5, x0, x1, 2*x0-x1+0.5)
+	}
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readIDs drains it and returns the row IDs in order, checking batch shapes.
+func readIDs(t *testing.T, it training.DataIterator[float32]) []string {
+	t.Helper()
+	var ids []string
+	for it.Next(context.Background()) {
+		b := it.Batch()
+		n := b.Targets.Shape()[0]
+		for _, x := range b.Inputs {
+			if !slices.Equal(x.Shape(), []int{n, 2}) {
+				t.Fatalf("features shape %v, targets shape %v", x.Shape(), b.Targets.Shape())
+			}
+		}
+		if !slices.Equal(b.Targets.Shape(), []int{n, 1}) {
+			t.Fatalf("targets shape %v", b.Targets.Shape())
+		}
+		ids = append(ids, it.(*training.CSVIterator[float32]).IDs()...)
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestCSVDataProvider(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	path := writeRegressionCSV(t, 200)
+	config := map[string]interface{}{
+		"path":             path,
+		"input":            rig.input,
+		"feature_columns":  []interface{}{"x0", "x1"},
+		"target_column":    "y",
+		"id_column":        "id",
+		"group_column":     "group",
+		"validation_ratio": 0.25,
+		"seed":             7,
+		"shuffle_buffer":   50,
+	}
+	data, err := training.Float32Registry.GetDataProvider(ctx, training.CSVDataProviderName, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := training.BatchConfig{BatchSize: 16, Shuffle: true}
+	train, err := data.GetTrainingData(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = train.Close() }()
+	valid, err := data.GetValidationData(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = valid.Close() }()
+
+	// The split holds out whole groups and covers every row once.
+	trainIDs, validIDs := readIDs(t, train), readIDs(t, valid)
+	if len(validIDs) < 20 || len(validIDs) > 80 || len(trainIDs)+len(validIDs) != 200 {
+		t.Fatalf("split %d training and %d validation rows of 200", len(trainIDs), len(validIDs))
+	}
+	group := func(id string) int {
+		var i int
+		_, _ = fmt.Sscanf(id, "r%d", &i)
+		return i / 5
+	}
+	held := make(map[int]bool)
+	for _, id := range validIDs {
+		held[group(id)] = true
+	}
+	all := append(slices.Clone(trainIDs), validIDs...)
+	for _, id := range trainIDs {
+		if held[group(id)] {
+			t.Fatalf("group of %s is on both sides of the split", id)
+		}
+	}
+	slices.Sort(all)
+	if len(slices.Compact(all)) != 200 {
+		t.Fatal("rows repeated across the split")
+	}
+	if slices.IsSorted(trainIDs) {
+		t.Error("shuffled training rows came out in file order")
+	}
+
+	// Each epoch reshuffles the same rows, reproducibly for a seed.
+	if err := train.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	epoch1 := readIDs(t, train)
+	if slices.Equal(epoch1, trainIDs) {
+		t.Error("Reset did not reshuffle")
+	}
+	if !slices.Equal(slices.Sorted(slices.Values(epoch1)), slices.Sorted(slices.Values(trainIDs))) {
+		t.Error("Reset changed the training rows")
+	}
+	again, err := training.Float32Registry.GetDataProvider(ctx, training.CSVDataProviderName, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := again.GetTrainingData(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = it.Close() }()
+	if !slices.Equal(readIDs(t, it), trainIDs) {
+		t.Error("the same seed gave a different order")
+	}
+
+	// The workflow learns y = 2*x0 - x1 + 0.5 from the file.
+	w := newSGDWorkflow()
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 30, LearningRate: 0.1, BatchConfig: batch}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, data, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FinalLoss > 1e-3 {
+		t.Errorf("validation loss %v after 30 epochs, want below 1e-3", result.FinalLoss)
+	}
+}
+
+func TestCSVDataProvider_DropLastAndDefaults(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	path := writeRegressionCSV(t, 50)
+	config := training.CSVConfig[float32]{
+		Path:           path,
+		Input:          rig.input,
+		FeatureColumns: []string{"x0", "x1"},
+		TargetColumns:  []string{"y"},
+	}
+	data, err := training.NewCSVDataProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		batch training.BatchConfig
+		want  []int
+	}{
+		{training.BatchConfig{BatchSize: 16}, []int{16, 16, 16, 2}},
+		{training.BatchConfig{BatchSize: 16, DropLast: true}, []int{16, 16, 16}},
+		{training.BatchConfig{}, []int{32, 18}},
+	} {
+		it, err := data.GetTrainingData(ctx, tc.batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for it.Next(ctx) {
+			got = append(got, it.Batch().Targets.Shape()[0])
+		}
+		_ = it.Close()
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%+v: batch sizes %v, want %v", tc.batch, got, tc.want)
+		}
+	}
+	valid, err := data.GetValidationData(ctx, training.BatchConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid.Next(ctx) {
+		t.Error("validation data without a validation ratio is not empty")
+	}
+
+	// Without FeatureColumns every column but the target, ID and group is a
+	// feature, including the note, which is not a number.
+	config.FeatureColumns, config.IDColumn, config.GroupColumn = nil, "id", "group"
+	data, err = training.NewCSVDataProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := data.GetMetadata()["feature_columns"]; !slices.Equal(got.([]string), []string{"x0", "note", "x1"}) {
+		t.Errorf("feature columns = %v", got)
+	}
+	it, err := data.GetTrainingData(ctx, training.BatchConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = it.Close() }()
+	if it.Next(ctx) || it.Error() == nil || !strings.Contains(it.Error().Error(), `data.csv:2: column "note"`) {
+		t.Errorf("Error() = %v, want a parse error at line 2", it.Error())
+	}
+}
+
+func TestCSVDataProvider_Errors(t *testing.T) {
+	rig := newRegressionRig(t)
+	path := writeRegressionCSV(t, 10)
+	for name, config := range map[string]training.CSVConfig[float32]{
+		"no path":        {Input: rig.input, TargetColumns: []string{"y"}},
+		"no input":       {Path: path, TargetColumns: []string{"y"}},
+		"no targets":     {Path: path, Input: rig.input},
+		"missing column": {Path: path, Input: rig.input, TargetColumns: []string{"z"}},
+		"feature target": {Path: path, Input: rig.input, TargetColumns: []string{"y"}, FeatureColumns: []string{"y"}},
+		"bad ratio":      {Path: path, Input: rig.input, TargetColumns: []string{"y"}, ValidationRatio: 1},
+		"missing file":   {Path: path + ".missing", Input: rig.input, TargetColumns: []string{"y"}},
+	} {
+		if _, err := training.NewCSVDataProvider(config); err == nil {
+			t.Errorf("%s: NewCSVDataProvider succeeded", name)
+		}
+	}
+	for _, config := range []map[string]interface{}{
+		{"path": 3},
+		{"path": path, "input": "x", "target_column": "y"},
+		{"path": path, "input": rig.input, "target_columns": "y"},
+		{"path": path, "input": rig.input, "target_column": "y", "seed": -1},
+	} {
+		if _, err := training.Float32Registry.GetDataProvider(context.Background(), training.CSVDataProviderName, config); err == nil {
+			t.Errorf("config %v should be rejected", config)
+		}
+	}
+}
@@ -3,7 +3,11 @@ package training
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/determinism"
+	"github.com/zerfoo/zerfoo/internal/tracing"
 	opt "github.com/zerfoo/zerfoo/training/optimizer"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -11,11 +15,69 @@ import (
 
 // DefaultTrainer encapsulates stable training components and delegates
 // gradient computation to a strategy.
+//
+// With gradient accumulation enabled (WithGradAccumulation), each TrainStep
+// call processes one micro-batch and the optimizer steps once every N calls
+// on the mean of the N micro-batch gradients. Gradient clipping
+// (WithGradClipNorm) is applied to that accumulated mean immediately before
+// the optimizer step, never to individual micro-batches, so for equal-size
+// micro-batches and a mean-reduced loss, accumulating N micro-batches of size
+// B is equivalent to one step on a batch of size N*B, with or without
+// clipping.
+//
+// Without accumulation, the gradient policy (WithGradPolicy) decides who
+// zeroes parameter gradients between steps; see GradPolicy.
 type DefaultTrainer[T tensor.Numeric] struct {
 	g        *graph.Graph[T]
 	loss     graph.Node[T]
 	opt      opt.Optimizer[T]
 	strategy GradientStrategy[T]
+
+	accumSteps  int
+	maxGradNorm float64
+	clipper     GradientClipper[T]
+	gradPolicy  GradPolicy
+	// zeroerOff records that the trainer disabled the optimizer's own
+	// gradient zeroing, so GradPolicyOptimizer can restore it.
+	zeroerOff bool
+	// saved holds the gradients carried across a GradPolicyAccumulate step.
+	saved map[*graph.Parameter[T]]*tensor.TensorNumeric[T]
+
+	// pending counts micro-batches accumulated since the last optimizer step.
+	pending int
+	// accums holds the running sum of micro-batch gradients per parameter.
+	accums map[*graph.Parameter[T]]*tensor.TensorNumeric[T]
+	// lastGradNorm is the global gradient norm measured before clipping at
+	// the most recent optimizer step, or 0 if clipping is disabled.
+	lastGradNorm float64
+}
+
+// DefaultTrainerOption configures a DefaultTrainer.
+type DefaultTrainerOption[T tensor.Numeric] func(*DefaultTrainer[T])
+
+// WithGradAccumulation sets the number of micro-batches whose gradients are
+// averaged before each optimizer step. Values below 2 disable accumulation.
+func WithGradAccumulation[T tensor.Numeric](steps int) DefaultTrainerOption[T] {
+	return func(t *DefaultTrainer[T]) {
+		t.accumSteps = max(steps, 1)
+	}
+}
+
+// WithGradClipNorm clips the global L2 norm of the (accumulated) gradient to
+// maxNorm before each optimizer step. If maxNorm <= 0, clipping is disabled.
+func WithGradClipNorm[T tensor.Numeric](maxNorm float64) DefaultTrainerOption[T] {
+	return func(t *DefaultTrainer[T]) {
+		t.maxGradNorm = max(maxNorm, 0)
+	}
+}
+
+// WithGradClipper applies clipper to the (accumulated) gradient before each
+// optimizer step, ahead of any WithGradClipNorm clipping. A nil clipper
+// disables it.
+func WithGradClipper[T tensor.Numeric](clipper GradientClipper[T]) DefaultTrainerOption[T] {
+	return func(t *DefaultTrainer[T]) {
+		t.clipper = clipper
+	}
 }
 
 // NewDefaultTrainer constructs a new DefaultTrainer. If strategy is nil,
@@ -25,43 +87,208 @@ func NewDefaultTrainer[T tensor.Numeric](
 	loss graph.Node[T],
 	optimizer opt.Optimizer[T],
 	strategy GradientStrategy[T],
+	opts ...DefaultTrainerOption[T],
 ) *DefaultTrainer[T] {
 	if strategy == nil {
 		strategy = NewDefaultBackpropStrategy[T]()
 	}
 
-	return &DefaultTrainer[T]{
-		g:        g,
-		loss:     loss,
-		opt:      optimizer,
-		strategy: strategy,
+	t := &DefaultTrainer[T]{
+		g:          g,
+		loss:       loss,
+		opt:        optimizer,
+		strategy:   strategy,
+		accumSteps: 1,
 	}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
 }
 
+// AccumulationSteps returns the number of micro-batches per optimizer step.
+func (t *DefaultTrainer[T]) AccumulationSteps() int { return t.accumSteps }
+
+// MaxGradNorm returns the gradient clipping threshold, or 0 if disabled.
+func (t *DefaultTrainer[T]) MaxGradNorm() float64 { return t.maxGradNorm }
+
+// PendingMicroBatches returns the number of micro-batches accumulated since
+// the last optimizer step.
+func (t *DefaultTrainer[T]) PendingMicroBatches() int { return t.pending }
+
+// LastGradNorm returns the global gradient norm, measured before clipping,
+// at the most recent optimizer step. It is 0 until a step has run with
+// clipping enabled.
+func (t *DefaultTrainer[T]) LastGradNorm() float64 { return t.lastGradNorm }
+
 // TrainStep performs a single training step using the configured strategy and optimizer.
+//
+// With gradient accumulation enabled, TrainStep processes one micro-batch and
+// returns its loss; the optimizer only steps on every AccumulationSteps-th
+// call. Use Flush to apply a partially filled accumulation, e.g. at the end
+// of an epoch.
 func (t *DefaultTrainer[T]) TrainStep(
 	ctx context.Context,
 	g *graph.Graph[T],
 	optimizer opt.Optimizer[T],
 	inputs map[graph.Node[T]]*tensor.TensorNumeric[T],
 	targets *tensor.TensorNumeric[T],
+) (T, error) {
+	ctx, span := tracing.Start(ctx, "training", "training.step")
+	lossVal, err := t.trainStep(ctx, g, optimizer, inputs, targets)
+	tracing.End(span, err)
+	return lossVal, err
+}
+
+func (t *DefaultTrainer[T]) trainStep(
+	ctx context.Context,
+	g *graph.Graph[T],
+	optimizer opt.Optimizer[T],
+	inputs map[graph.Node[T]]*tensor.TensorNumeric[T],
+	targets *tensor.TensorNumeric[T],
 ) (T, error) {
 	batch := Batch[T]{
 		Inputs:  inputs,
 		Targets: targets,
 	}
+	if t.accumSteps <= 1 && t.maxGradNorm <= 0 && t.clipper == nil {
+		lossVal, err := t.computeGradients(ctx, g, optimizer, batch)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+
+		if err := t.step(ctx, g, optimizer); err != nil {
+			var zero T
+			return zero, err
+		}
+
+		return lossVal, nil
+	}
+
+	var zero T
+	engine := g.Engine()
+	if engine == nil {
+		return zero, errors.New("training: gradient accumulation and clipping require a graph with an engine")
+	}
+	if t.accumSteps <= 1 {
+		lossVal, err := t.computeGradients(ctx, g, optimizer, batch)
+		if err != nil {
+			return zero, err
+		}
+		return lossVal, t.step(ctx, g, optimizer)
+	}
+
+	// Layers differ in whether Backward adds into or overwrites
+	// Parameter.Gradient, so each micro-batch starts from zeroed gradients
+	// and the trainer keeps the running sum itself.
+	params := uniqueParameters(g)
+	for _, p := range params {
+		if p.Gradient != nil {
+			if err := engine.Fill(ctx, p.Gradient, zero); err != nil {
+				return zero, fmt.Errorf("training: zero gradient of %q: %w", p.Name, err)
+			}
+		}
+	}
 	lossVal, err := t.strategy.ComputeGradients(ctx, g, t.loss, batch)
 	if err != nil {
-		var zero T
 		return zero, err
 	}
+	if t.accums == nil {
+		t.accums = make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T])
+	}
+	for _, p := range params {
+		if p.Gradient == nil {
+			continue
+		}
+		acc, ok := t.accums[p]
+		if !ok {
+			acc, err = tensor.New[T](p.Gradient.Shape(), nil)
+			if err != nil {
+				return zero, err
+			}
+			if err := engine.Zeros(ctx, acc, p.Gradient.Shape()); err != nil {
+				return zero, fmt.Errorf("training: allocate gradient accumulator for %q: %w", p.Name, err)
+			}
+			t.accums[p] = acc
+		}
+		if _, err := engine.Add(ctx, acc, p.Gradient, acc); err != nil {
+			return zero, fmt.Errorf("training: accumulate gradient of %q: %w", p.Name, err)
+		}
+	}
+	t.pending++
+	if t.pending < t.accumSteps {
+		return lossVal, nil
+	}
+	return lossVal, t.Flush(ctx, g, optimizer)
+}
 
-	if err := optimizer.Step(ctx, g.Parameters()); err != nil {
-		var zero T
-		return zero, err
+// Flush applies any accumulated micro-batch gradients: it averages them over
+// the number of micro-batches seen since the last step, clips the result if
+// configured, and steps the optimizer. It is a no-op when nothing is pending.
+func (t *DefaultTrainer[T]) Flush(ctx context.Context, g *graph.Graph[T], optimizer opt.Optimizer[T]) error {
+	if t.pending == 0 {
+		return nil
+	}
+	engine := g.Engine()
+	if engine == nil {
+		return errors.New("training: gradient accumulation and clipping require a graph with an engine")
+	}
+
+	var zero T
+	scale := engine.Ops().FromFloat64(1 / float64(t.pending))
+	for p, acc := range t.accums {
+		determinism.NoteOrder(determinism.MapIteration, p.Name)
+		// Write the mean into the parameter's existing gradient buffer when
+		// it has one, so strategies that track gradient storage identity
+		// (see gradAccumulator) keep seeing the same tensor.
+		dst := p.Gradient
+		mean, err := engine.MulScalar(ctx, acc, scale, dst)
+		if err != nil {
+			return fmt.Errorf("training: average gradient of %q: %w", p.Name, err)
+		}
+		p.Gradient = mean
+		if err := engine.Fill(ctx, acc, zero); err != nil {
+			return fmt.Errorf("training: reset gradient accumulator for %q: %w", p.Name, err)
+		}
+	}
+	t.pending = 0
+	return t.step(ctx, g, optimizer)
+}
+
+// step clips the current gradients if configured and steps the optimizer.
+func (t *DefaultTrainer[T]) step(ctx context.Context, g *graph.Graph[T], optimizer opt.Optimizer[T]) (err error) {
+	ctx, span := tracing.Start(ctx, "training", "training.optimizer_step")
+	defer func() { tracing.End(span, err) }()
+
+	if t.clipper != nil {
+		if err := t.clipper.ClipGradients(ctx, g.Engine(), g.Parameters()); err != nil {
+			return err
+		}
 	}
+	if t.maxGradNorm > 0 {
+		norm, err := opt.ClipGradNorm(ctx, g.Engine(), g.Parameters(), t.maxGradNorm)
+		if err != nil {
+			return fmt.Errorf("training: clip gradients: %w", err)
+		}
+		t.lastGradNorm = norm
+	}
+	return optimizer.Step(ctx, g.Parameters())
+}
 
-	return lossVal, nil
+// uniqueParameters returns g's parameters with duplicates (parameters shared
+// by several nodes) removed.
+func uniqueParameters[T tensor.Numeric](g *graph.Graph[T]) []*graph.Parameter[T] {
+	params := g.Parameters()
+	seen := make(map[*graph.Parameter[T]]bool, len(params))
+	out := params[:0:0]
+	for _, p := range params {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
 }
 
 // Statically assert that the type implements the Trainer interface.
@@ -0,0 +1,204 @@
+package training_test
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/layers/activations"
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+const (
+	accumIn  = 3
+	accumOut = 2
+	accumN   = 8 // rows in the full batch
+)
+
+// accumRig is a small MLP with its own trainer and AdamW state.
+type accumRig struct {
+	g       *graph.Graph[float32]
+	input   graph.Node[float32]
+	opt     *optimizer.AdamW[float32]
+	trainer *training.DefaultTrainer[float32]
+}
+
+// newAccumRig builds a 3-4-2 tanh MLP whose parameters are initialized from
+// seed, so rigs built with the same seed start identical.
+func newAccumRig(t *testing.T, seed uint64, opts ...training.DefaultTrainerOption[float32]) *accumRig {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, accumIn})
+	d1, err := core.NewDense[float32]("d1", engine, ops, accumIn, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := core.NewDense[float32]("d2", engine, ops, 4, accumOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := b.AddNode(d1, input)
+	h = b.AddNode(activations.NewTanh[float32](engine, ops), h)
+	g, err := b.Build(b.AddNode(d2, h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	for _, p := range g.Parameters() {
+		data := p.Value.Data()
+		for i := range data {
+			data[i] = float32(rng.Float64() - 0.5)
+		}
+	}
+
+	opt := optimizer.NewAdamW[float32](engine, 0.01, 0.9, 0.999, 1e-8, 0.01)
+	trainer := training.NewDefaultTrainer[float32](g, loss.NewMSE[float32](engine, ops), opt, nil, opts...)
+	return &accumRig{g: g, input: input, opt: opt, trainer: trainer}
+}
+
+func (r *accumRig) step(t *testing.T, x, y []float32) {
+	t.Helper()
+	rows := len(x) / accumIn
+	xt, err := tensor.New[float32]([]int{rows, accumIn}, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yt, err := tensor.New[float32]([]int{rows, accumOut}, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := map[graph.Node[float32]]*tensor.TensorNumeric[float32]{r.input: xt}
+	if _, err := r.trainer.TrainStep(context.Background(), r.g, r.opt, inputs, yt); err != nil {
+		t.Fatalf("TrainStep: %v", err)
+	}
+}
+
+func (r *accumRig) params() []float32 {
+	var out []float32
+	for _, p := range r.g.Parameters() {
+		out = append(out, p.Value.Data()...)
+	}
+	return out
+}
+
+func syntheticBatch(seed uint64) (x, y []float32) {
+	rng := rand.New(rand.NewPCG(seed, 1))
+	x = make([]float32, accumN*accumIn)
+	y = make([]float32, accumN*accumOut)
+	for i := range x {
+		x[i] = float32(rng.NormFloat64())
+	}
+	for i := range y {
+		y[i] = float32(rng.NormFloat64())
+	}
+	return x, y
+}
+
+func assertClose(t *testing.T, got, want []float32, tol float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > tol {
+			t.Fatalf("param %d = %v, want %v (tol %g)", i, got[i], want[i], tol)
+		}
+	}
+}
+
+// TestDefaultTrainer_AccumulationMatchesLargeBatch checks that accumulating
+// k equal micro-batches produces the same parameters as stepping on their
+// concatenation, with and without clipping. The clip threshold is chosen to
+// bind, so clipping per micro-batch instead of on the accumulated mean would
+// diverge.
+func TestDefaultTrainer_AccumulationMatchesLargeBatch(t *testing.T) {
+	for _, clip := range []float64{0, 0.05} {
+		for _, k := range []int{2, 4} {
+			large := newAccumRig(t, 1, training.WithGradClipNorm[float32](clip))
+			accum := newAccumRig(t, 1,
+				training.WithGradAccumulation[float32](k),
+				training.WithGradClipNorm[float32](clip),
+			)
+			rows := accumN / k
+			for s := range 5 {
+				x, y := syntheticBatch(uint64(s))
+				large.step(t, x, y)
+				for m := range k {
+					accum.step(t,
+						x[m*rows*accumIn:(m+1)*rows*accumIn],
+						y[m*rows*accumOut:(m+1)*rows*accumOut])
+				}
+				if clip > 0 {
+					if d := math.Abs(large.trainer.LastGradNorm() - accum.trainer.LastGradNorm()); d > 1e-5 {
+						t.Fatalf("clip=%v k=%d step %d: grad norm %v, large batch %v", clip, k, s,
+							accum.trainer.LastGradNorm(), large.trainer.LastGradNorm())
+					}
+					if large.trainer.LastGradNorm() <= clip {
+						t.Fatalf("clip threshold %v does not bind (norm %v)", clip, large.trainer.LastGradNorm())
+					}
+				}
+			}
+			assertClose(t, accum.params(), large.params(), 1e-5)
+		}
+	}
+}
+
+func TestDefaultTrainer_AccumulationDefersStepAndFlush(t *testing.T) {
+	accum := newAccumRig(t, 2, training.WithGradAccumulation[float32](4))
+	ref := newAccumRig(t, 2)
+	before := accum.params()
+
+	x, y := syntheticBatch(9)
+	const rows = 2
+	for m := range 3 {
+		accum.step(t, x[m*rows*accumIn:(m+1)*rows*accumIn], y[m*rows*accumOut:(m+1)*rows*accumOut])
+	}
+	if got := accum.trainer.PendingMicroBatches(); got != 3 {
+		t.Fatalf("PendingMicroBatches = %d, want 3", got)
+	}
+	assertClose(t, accum.params(), before, 0)
+
+	// Flushing a partial accumulation averages over the micro-batches seen,
+	// matching one step on those 6 rows.
+	if err := accum.trainer.Flush(context.Background(), accum.g, accum.opt); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := accum.trainer.PendingMicroBatches(); got != 0 {
+		t.Fatalf("PendingMicroBatches after Flush = %d, want 0", got)
+	}
+	ref.step(t, x[:3*rows*accumIn], y[:3*rows*accumOut])
+	assertClose(t, accum.params(), ref.params(), 1e-5)
+
+	// Nothing pending: Flush must not step again.
+	after := accum.params()
+	if err := accum.trainer.Flush(context.Background(), accum.g, accum.opt); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	assertClose(t, accum.params(), after, 0)
+}
+
+func TestDefaultTrainer_AccumulationOptions(t *testing.T) {
+	r := newAccumRig(t, 3)
+	if r.trainer.AccumulationSteps() != 1 || r.trainer.MaxGradNorm() != 0 {
+		t.Errorf("defaults = (%d, %v), want (1, 0)", r.trainer.AccumulationSteps(), r.trainer.MaxGradNorm())
+	}
+	r = newAccumRig(t, 3,
+		training.WithGradAccumulation[float32](0),
+		training.WithGradClipNorm[float32](-1),
+	)
+	if r.trainer.AccumulationSteps() != 1 || r.trainer.MaxGradNorm() != 0 {
+		t.Errorf("invalid values = (%d, %v), want (1, 0)", r.trainer.AccumulationSteps(), r.trainer.MaxGradNorm())
+	}
+}
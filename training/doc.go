@@ -20,6 +20,23 @@
 // [optimizer.Optimizer]. When no strategy is provided, it defaults to
 // [DefaultBackpropStrategy].
 //
+// Gradient accumulation and clipping are configured on the trainer:
+//
+//	trainer := training.NewDefaultTrainer[float32](g, lossNode, opt, nil,
+//		training.WithGradAccumulation[float32](4),
+//		training.WithGradClipNorm[float32](1.0),
+//	)
+//
+// Each TrainStep then processes one micro-batch, and every fourth call
+// averages the accumulated gradients, clips their global norm, and steps the
+// optimizer. Clipping always applies to the accumulated gradient, so
+// accumulation is equivalent to training on the combined batch.
+//
+// Other clipping rules plug in as a [GradientClipper] through
+// WithGradClipper; [ValueClipper] clamps each gradient element. Workflows
+// take the same settings from [WorkflowConfig] (AccumulationSteps, ClipNorm,
+// ClipValue).
+//
 // # Gradient Strategies
 //
 // [GradientStrategy] controls how gradients are computed for each training
@@ -45,6 +62,26 @@
 //   - EMA — Exponential moving average of model parameters.
 //   - SWA — Stochastic weight averaging.
 //
+// [optimizer.GroupedOptimizer] steps parameter groups with per-group
+// learning rates. [optimizer.LayerDecayGroups] builds the groups for
+// layer-wise learning rate decay when fine-tuning, deriving each
+// parameter's depth from its hierarchical name:
+//
+//	groups, err := optimizer.LayerDecayGroups(g.Parameters(), 0.8)
+//	opt, err := optimizer.NewGroupedOptimizer(2e-5, groups, func(lr float64) optimizer.Optimizer[float32] {
+//		return optimizer.NewAdamWFromFloat64[float32](engine, lr, 0.9, 0.999, 1e-8, 0.01)
+//	})
+//
+// For mixed-precision training, build the model in float16 and step it
+// through [MixedPrecision], configured by [MixedPrecisionConfig]. It keeps
+// float32 master weights for a float32 optimizer and applies a dynamic loss
+// scale, skipping steps whose gradients overflow; its Loss wrapper applies
+// the scale to the loss gradient:
+//
+//	mp, err := training.NewMixedPrecision(engine16, optimizer.NewAdamWFromFloat64[float32](engine32, 1e-3, 0.9, 0.999, 1e-8, 0.01),
+//		training.MixedPrecisionConfig{})
+//	trainer := training.NewDefaultTrainer(g, mp.Loss(lossNode), mp, nil)
+//
 // # Batch and Data Iteration
 //
 // [Batch] groups inputs and targets for a single training step. Inputs are
@@ -69,6 +106,64 @@
 // interface. [TrainerWorkflowAdapter] bridges the core [Trainer] interface
 // to [TrainingWorkflow] for use with the plugin system.
 //
+// [StandardWorkflow] is a complete epoch loop built from a loss factory and
+// an optimizer factory: it trains with a [DefaultTrainer], evaluates each
+// epoch on the validation data (or a held-out split of the training data),
+// stops early after MaxNoImprove epochs without improvement of a chosen
+// metric, optionally restoring the parameters of the best epoch
+// ([EarlyStopper]), and honours the wall-clock limit. [WithLRScheduler] adjusts the learning rate between
+// epochs with a training/scheduler Scheduler. It is registered in both global registries as
+// "standard", configured by "loss" and "optimizer" keys:
+//
+//	wf, err := training.Float32Registry.GetWorkflow(ctx, training.StandardWorkflowName,
+//		map[string]interface{}{"loss": "cross_entropy", "optimizer": "adamw"})
+//
+// After Train, [StandardWorkflow.ContinueTraining] updates the model with
+// new data instead of retraining it, keeping the optimizer state warm,
+// optionally at a smaller learning rate and mixed with batches replayed
+// from a [WithReplayBuffer] sample of earlier data:
+//
+//	sw := wf.(*training.StandardWorkflow[float32])
+//	res, err := sw.ContinueTraining(ctx, thisWeek,
+//		training.ContinueOptions{Epochs: 2, LearningRate: 1e-4})
+//
+// [WithHeads] trains a multi-head model, whose output and targets
+// concatenate the heads along their last axis, with a [MultiHeadLoss]: a
+// weighted sum of per-head losses. Validation reports each head's loss as
+// "<head>/loss" and its metrics as "<head>/<metric>":
+//
+//	wf := training.NewStandardWorkflow(nil, newOpt, training.WithHeads(
+//		training.Head[float32]{Name: "price", Width: 1, Loss: mse},
+//		training.Head[float32]{Name: "regime", Width: 3, TargetWidth: 1, Weight: 0.3, Loss: crossEntropy},
+//	))
+//
+// [WithCheckpoints] writes a [Checkpoint] every N steps with a
+// [CheckpointManager]: model parameters, optimizer state (see
+// optimizer.StateSaver), epoch and step counters, and optionally the state
+// of a random source. [StandardWorkflow.Resume] continues the run from one,
+// matching an uninterrupted run bit for bit on deterministic data:
+//
+//	ckpts, err := training.NewCheckpointManager[float32]("ckpt",
+//		training.WithCheckpointEvery[float32](500), training.WithCheckpointKeep[float32](3))
+//	wf := training.NewStandardWorkflow(newLoss, newOpt, training.WithCheckpoints(ckpts))
+//	// ... after a crash:
+//	latest, err := ckpts.Latest()
+//	res, err := wf.Resume(ctx, latest, data, models)
+//
+// Each checkpoint has a [CheckpointInfo] beside it with its counters and
+// the latest epoch's metrics. [WithCheckpointBest] keeps the best k
+// checkpoints by one of those metrics on top of the latest ones, and
+// [CheckpointManager.Best] selects the best for deployment or resumption.
+//
+// [CSVDataProvider] streams batches from a CSV file, selecting feature,
+// target, ID and group columns by name. It splits rows, or whole groups,
+// between training and validation by a seeded hash and shuffles training
+// rows through a bounded buffer, so files larger than memory train with a
+// reproducible split and order. [ParquetDataProvider] does the same for
+// Parquet files, reading one row group at a time and only the columns it
+// uses. Both yield [CSVIterator]s, which report the ID and group of each
+// batch's rows.
+//
 // [PluginRegistry] enables runtime registration and lookup of workflows,
 // data providers, model providers, sequence providers, metric computers,
 // and cross validators. Global registries [Float32Registry] and
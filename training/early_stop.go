@@ -1,5 +1,14 @@
 package training
 
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
 // EarlyStopConfig configures smoothed early stopping behavior.
 type EarlyStopConfig struct {
 	// Patience is the number of epochs without improvement before stopping.
@@ -77,3 +86,149 @@ func (es *EarlyStopping) Reset() {
 func (es *EarlyStopping) BestMetric() float64 {
 	return es.bestSmoothed
 }
+
+// EarlyStopper stops training when a monitored metric stops improving, as
+// configured by WorkflowConfig (MaxNoImprove, EarlyStopTol, EarlyStopMetric,
+// EarlyStopMode), and keeps a snapshot of the model parameters at the best
+// value so they can be restored when training ends (RestoreBest). Unlike
+// EarlyStopping it compares raw, unsmoothed values.
+type EarlyStopper[T tensor.Numeric] struct {
+	metric    string
+	mode      string
+	patience  int
+	minDelta  float64
+	keepBest  bool
+	best      float64
+	bestEpoch int
+	noImprove int
+	seen      bool
+	snapshot  [][]T
+}
+
+// NewEarlyStopper returns the early stopper configured by config, or nil if
+// config.MaxNoImprove is zero and config.RestoreBest is false. With only
+// RestoreBest it tracks the best epoch but never stops training.
+func NewEarlyStopper[T tensor.Numeric](config WorkflowConfig) *EarlyStopper[T] {
+	if config.MaxNoImprove <= 0 && !config.RestoreBest {
+		return nil
+	}
+	mode := config.EarlyStopMode
+	if mode == "" {
+		mode = "min"
+	}
+	return &EarlyStopper[T]{
+		metric:    config.EarlyStopMetric,
+		mode:      mode,
+		patience:  config.MaxNoImprove,
+		minDelta:  config.EarlyStopTol,
+		keepBest:  config.RestoreBest,
+		bestEpoch: -1,
+	}
+}
+
+// validateEarlyStop checks the early stopping settings of config.
+func validateEarlyStop(config WorkflowConfig) error {
+	switch {
+	case config.MaxNoImprove < 0:
+		return fmt.Errorf("MaxNoImprove must not be negative, got %d", config.MaxNoImprove)
+	case config.EarlyStopMode != "" && config.EarlyStopMode != "min" && config.EarlyStopMode != "max":
+		return fmt.Errorf("EarlyStopMode must be \"min\" or \"max\", got %q", config.EarlyStopMode)
+	}
+	return nil
+}
+
+// Metric returns the name of the monitored metric, or "" for the loss the
+// workflow monitors by default.
+func (s *EarlyStopper[T]) Metric() string {
+	return s.metric
+}
+
+// Value picks the monitored value of an epoch: metrics[Metric()], or loss
+// when no metric is set. It fails if the metric is missing.
+func (s *EarlyStopper[T]) Value(loss float64, metrics map[string]float64) (float64, error) {
+	if s.metric == "" {
+		return loss, nil
+	}
+	v, ok := metrics[s.metric]
+	if !ok {
+		return 0, fmt.Errorf("early stopping metric %q not computed (have %v)", s.metric, slices.Sorted(maps.Keys(metrics)))
+	}
+	return v, nil
+}
+
+// Step records the monitored value of epoch and reports whether training
+// should stop. When the value is the best so far and RestoreBest is set,
+// it snapshots the parameters of model; a nil model, as when replaying a
+// resumed run, skips the snapshot.
+func (s *EarlyStopper[T]) Step(epoch int, value float64, model *graph.Graph[T]) bool {
+	improved := !s.seen
+	if s.seen {
+		if s.mode == "max" {
+			improved = value-s.best > s.minDelta
+		} else {
+			improved = s.best-value > s.minDelta
+		}
+	}
+	if improved {
+		s.seen = true
+		s.best, s.bestEpoch, s.noImprove = value, epoch, 0
+		s.snapshot = nil
+		if s.keepBest && model != nil {
+			params := model.Parameters()
+			s.snapshot = make([][]T, len(params))
+			for i, p := range params {
+				s.snapshot[i] = slices.Clone(p.Value.Data())
+			}
+		}
+		return false
+	}
+	s.noImprove++
+	return s.patience > 0 && s.noImprove >= s.patience
+}
+
+// Best returns the best monitored value and its epoch, or -1 before the
+// first Step.
+func (s *EarlyStopper[T]) Best() (float64, int) {
+	return s.best, s.bestEpoch
+}
+
+// Restore copies the best parameter snapshot back into model and reports
+// whether it did. There is no snapshot without RestoreBest, or when the
+// best epoch was replayed from a checkpoint.
+func (s *EarlyStopper[T]) Restore(model *graph.Graph[T]) (bool, error) {
+	if s.snapshot == nil {
+		return false, nil
+	}
+	params := model.Parameters()
+	if len(params) != len(s.snapshot) {
+		return false, fmt.Errorf("early stopping snapshot has %d parameters, model has %d", len(s.snapshot), len(params))
+	}
+	for i, p := range params {
+		if p.Value.Size() != len(s.snapshot[i]) {
+			return false, fmt.Errorf("early stopping snapshot of %s has %d values, want %d", p.Name, len(s.snapshot[i]), p.Value.Size())
+		}
+		p.Value.SetData(slices.Clone(s.snapshot[i]))
+	}
+	return true, nil
+}
+
+// RestoredEpochKey is the TrainingResult.Extensions key holding the epoch
+// whose parameters were restored by WorkflowConfig.RestoreBest.
+const RestoredEpochKey = "restored_epoch"
+
+// restoreBest restores the best parameters kept by stopper, if any, into
+// model and records their epoch in result.
+func restoreBest[T tensor.Numeric](stopper *EarlyStopper[T], model *graph.Graph[T], result *TrainingResult[T]) error {
+	if stopper == nil {
+		return nil
+	}
+	restored, err := stopper.Restore(model)
+	if err != nil {
+		return err
+	}
+	if restored {
+		_, epoch := stopper.Best()
+		result.Extensions[RestoredEpochKey] = epoch
+	}
+	return nil
+}
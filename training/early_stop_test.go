@@ -2,7 +2,13 @@ package training
 
 import (
 	"math"
+	"slices"
 	"testing"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
 )
 
 func TestSmoothedEarlyStopping_Improving(t *testing.T) {
@@ -169,3 +175,81 @@ func TestNewEarlyStopping_Defaults(t *testing.T) {
 		t.Fatalf("expected default mode 'min', got %q", es.config.Mode)
 	}
 }
+
+func TestEarlyStopper(t *testing.T) {
+	if NewEarlyStopper[float32](WorkflowConfig{}) != nil {
+		t.Error("stopper built with early stopping disabled")
+	}
+
+	s := NewEarlyStopper[float32](WorkflowConfig{MaxNoImprove: 2, EarlyStopTol: 0.1, EarlyStopMetric: "acc", EarlyStopMode: "max"})
+	if _, err := s.Value(1, map[string]float64{"val_loss": 1}); err == nil {
+		t.Error("expected an error for a missing metric")
+	}
+	// 0.55 does not beat 0.5 by more than 0.1; 0.7 does.
+	var stops []bool
+	for epoch, acc := range []float64{0.5, 0.55, 0.7, 0.75, 0.6} {
+		v, err := s.Value(9, map[string]float64{"acc": acc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stops = append(stops, s.Step(epoch, v, nil))
+	}
+	if !slices.Equal(stops, []bool{false, false, false, false, true}) {
+		t.Errorf("stops = %v", stops)
+	}
+	if best, epoch := s.Best(); best != 0.7 || epoch != 2 {
+		t.Errorf("Best() = %v, %d, want 0.7, 2", best, epoch)
+	}
+
+	// RestoreBest without patience tracks the best epoch but never stops.
+	g := newDenseGraph(t, 2, 1)
+	want := paramData(g)
+	s = NewEarlyStopper[float32](WorkflowConfig{RestoreBest: true})
+	if v, _ := s.Value(3, nil); s.Step(0, v, g) {
+		t.Error("stopped without MaxNoImprove")
+	}
+	for _, p := range g.Parameters() {
+		p.Value.SetData(make([]float32, p.Value.Size()))
+	}
+	for epoch := 1; epoch < 10; epoch++ {
+		if s.Step(epoch, 4, g) {
+			t.Fatal("stopped without MaxNoImprove")
+		}
+	}
+	restored, err := s.Restore(g)
+	if err != nil || !restored {
+		t.Fatalf("Restore() = %v, %v", restored, err)
+	}
+	if got := paramData(g); !slices.Equal(got, want) {
+		t.Errorf("restored parameters = %v, want %v", got, want)
+	}
+	if restored, err := s.Restore(newDenseGraph(t, 3, 1)); err == nil || restored {
+		t.Errorf("Restore into a different model = %v, %v, want an error", restored, err)
+	}
+}
+
+// newDenseGraph builds a graph of one in x out Dense layer.
+func newDenseGraph(t *testing.T, in, out int) *graph.Graph[float32] {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	dense, err := core.NewDense[float32]("d", engine, ops, in, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, b.Input([]int{1, in})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// paramData returns a copy of the parameter values of g, concatenated.
+func paramData(g *graph.Graph[float32]) []float32 {
+	var data []float32
+	for _, p := range g.Parameters() {
+		data = append(data, p.Value.Data()...)
+	}
+	return data
+}
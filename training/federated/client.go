@@ -0,0 +1,142 @@
+package federated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// WorkflowClient is a federated participant that trains a model locally on
+// its private shard with a training workflow. Each round it loads the
+// global weights into its model, trains for config.NumEpochs epochs, and
+// reports the new weights, not gradients, with the number of samples in
+// its shard, so FedAvg weights its update by shard size. The shard never
+// leaves the client.
+type WorkflowClient[T tensor.Numeric] struct {
+	id       ClientID
+	model    *graph.Graph[T]
+	workflow training.TrainingWorkflow[T]
+	config   training.WorkflowConfig
+	data     training.DataProvider[T]
+	nSamples int
+}
+
+// NewWorkflowClient returns a client training model on data with workflow,
+// initialized with config every round. config.NumEpochs is the number of
+// local epochs per round; config.CheckpointPath should be empty, as the
+// coordinator holds the global model.
+func NewWorkflowClient[T tensor.Numeric](
+	id ClientID,
+	model *graph.Graph[T],
+	workflow training.TrainingWorkflow[T],
+	config training.WorkflowConfig,
+	data training.DataProvider[T],
+) *WorkflowClient[T] {
+	return &WorkflowClient[T]{id: id, model: model, workflow: workflow, config: config, data: data}
+}
+
+// ID implements Client.
+func (c *WorkflowClient[T]) ID() ClientID {
+	return c.id
+}
+
+// Train implements Client. With no global weights, as in the first round
+// of a coordinator without SetGlobalWeights, it starts from the model's
+// own weights.
+func (c *WorkflowClient[T]) Train(globalWeights []float64) (*ModelUpdate, error) {
+	ctx := context.Background()
+	if len(globalWeights) > 0 {
+		if err := SetWeights(c.model, globalWeights); err != nil {
+			return nil, fmt.Errorf("client %s: %w", c.id, err)
+		}
+	}
+	if c.nSamples == 0 {
+		n, err := countSamples(ctx, c.data, c.config.BatchConfig)
+		if err != nil {
+			return nil, fmt.Errorf("client %s: %w", c.id, err)
+		}
+		c.nSamples = n
+	}
+	if err := c.workflow.Initialize(ctx, c.config); err != nil {
+		return nil, fmt.Errorf("client %s: %w", c.id, err)
+	}
+	models := training.NewSimpleModelProvider(
+		func(context.Context, training.ModelConfig) (*graph.Graph[T], error) { return c.model, nil },
+		training.ModelInfo{},
+	)
+	result, err := c.workflow.Train(ctx, c.data, models)
+	if err != nil {
+		return nil, fmt.Errorf("client %s: %w", c.id, err)
+	}
+	return &ModelUpdate{
+		ClientID: c.id,
+		Weights:  Weights(c.model),
+		NSamples: c.nSamples,
+		Metrics:  map[string]float64{"loss": dtype.ToFloat64(result.FinalLoss)},
+	}, nil
+}
+
+// countSamples counts the rows of the training batches of data, read from
+// the leading dimension of each batch's targets.
+func countSamples[T tensor.Numeric](ctx context.Context, data training.DataProvider[T], config training.BatchConfig) (int, error) {
+	it, err := data.GetTrainingData(ctx, config)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = it.Close() }()
+	n := 0
+	for it.Next(ctx) {
+		b := it.Batch()
+		if b == nil || b.Targets == nil || len(b.Targets.Shape()) == 0 {
+			return 0, errors.New("cannot count samples of a batch without targets")
+		}
+		n += b.Targets.Shape()[0]
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, errors.New("empty training shard")
+	}
+	return n, nil
+}
+
+// Weights flattens the parameters of model, in Parameters order, into the
+// weight vector that strategies aggregate.
+func Weights[T tensor.Numeric](model *graph.Graph[T]) []float64 {
+	var weights []float64
+	for _, p := range model.Parameters() {
+		weights = append(weights, dtype.Float64s(nil, p.Value.Data())...)
+	}
+	return weights
+}
+
+// SetWeights loads a weight vector laid out as by Weights into the
+// parameters of model.
+func SetWeights[T tensor.Numeric](model *graph.Graph[T], weights []float64) error {
+	params := model.Parameters()
+	size := 0
+	for _, p := range params {
+		size += p.Value.Size()
+	}
+	if len(weights) != size {
+		return fmt.Errorf("federated: %d weights for a model with %d", len(weights), size)
+	}
+	ops := model.Engine().Ops()
+	for _, p := range params {
+		n := p.Value.Size()
+		data := make([]T, n)
+		dtype.FromFloat64s(ops, data, weights[:n])
+		p.Value.SetData(data)
+		weights = weights[n:]
+	}
+	return nil
+}
+
+// Statically assert that the type implements the Client interface.
+var _ Client = (*WorkflowClient[float32])(nil)
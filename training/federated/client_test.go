@@ -0,0 +1,190 @@
+package federated
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// shard serves fixed training batches.
+type shard struct {
+	batches []*training.Batch[float32]
+}
+
+func (s *shard) GetTrainingData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter(s.batches), nil
+}
+
+func (s *shard) GetValidationData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter[float32](nil), nil
+}
+
+func (s *shard) GetMetadata() map[string]interface{} { return nil }
+func (s *shard) Close() error                        { return nil }
+
+// newRegressionClient returns a client with its own 2-1 linear model and a
+// shard of n batches of 8 rows of y = 2*x0 - x1 + 0.5, drawn from seed.
+func newRegressionClient(t *testing.T, id ClientID, seed uint64, n int) (*WorkflowClient[float32], *graph.Graph[float32]) {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{8, 2})
+	dense, err := core.NewDense[float32]("dense", engine, ops, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	data := &shard{}
+	for range n {
+		x, y := make([]float32, 16), make([]float32, 8)
+		for j := range y {
+			x0, x1 := rng.Float64()*2-1, rng.Float64()*2-1
+			x[2*j], x[2*j+1] = float32(x0), float32(x1)
+			y[j] = float32(2*x0 - x1 + 0.5)
+		}
+		xt, err := tensor.New([]int{8, 2}, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yt, err := tensor.New([]int{8, 1}, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data.batches = append(data.batches, &training.Batch[float32]{
+			Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: xt},
+			Targets: yt,
+		})
+	}
+	workflow := training.NewStandardWorkflow(
+		func(e compute.Engine[float32]) graph.Node[float32] { return loss.NewMSE(e, e.Ops()) },
+		func(e compute.Engine[float32], lr float64) optimizer.Optimizer[float32] {
+			return optimizer.NewSGD(e, e.Ops(), float32(lr))
+		},
+	)
+	config := training.WorkflowConfig{NumEpochs: 2, LearningRate: 0.1}
+	return NewWorkflowClient(id, g, workflow, config, data), g
+}
+
+func TestWorkflowClient_FedAvg(t *testing.T) {
+	var clients []Client
+	var models []*graph.Graph[float32]
+	for i, n := range []int{1, 2, 3, 4} {
+		c, g := newRegressionClient(t, ClientID(rune('a'+i)), uint64(i+1), n)
+		clients = append(clients, c)
+		models = append(models, g)
+	}
+	coord := NewCoordinator(NewFedAvg(WithClientFraction(0.5, 9)), CoordinatorConfig{MinClients: 1, MaxRounds: 30})
+	coord.SetGlobalWeights(Weights(models[0]))
+
+	results, err := coord.Run(clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 30 || coord.Round() != 30 {
+		t.Fatalf("ran %d rounds, coordinator at %d, want 30", len(results), coord.Round())
+	}
+	for _, r := range results {
+		if len(r.Updates) != 2 {
+			t.Fatalf("round %d had %d participants, want 2", r.Model.Round, len(r.Updates))
+		}
+		for _, u := range r.Updates {
+			// Shard sizes in samples are 8 rows per batch.
+			want := 8 * (int(u.ClientID[0]-'a') + 1)
+			if u.NSamples != want {
+				t.Fatalf("client %s reported %d samples, want %d", u.ClientID, u.NSamples, want)
+			}
+		}
+	}
+
+	// The global model learned y = 2*x0 - x1 + 0.5.
+	if err := SetWeights(models[0], coord.GlobalWeights()); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]float32{"dense_linear_weights": {2, -1}, "dense_bias_biases": {0.5}}
+	for _, p := range models[0].Parameters() {
+		for i, v := range p.Value.Data() {
+			if math.Abs(float64(v-want[p.Name][i])) > 0.05 {
+				t.Errorf("%s = %v, want about %v", p.Name, p.Value.Data(), want[p.Name])
+				break
+			}
+		}
+	}
+}
+
+func TestFedAvg_ClientFraction(t *testing.T) {
+	available := []ClientID{"a", "b", "c", "d", "e", "f", "g"}
+	f := NewFedAvg(WithClientFraction(0.3, 1))
+	seen := make(map[ClientID]bool)
+	for round := range 20 {
+		got := f.SelectClients(round, available)
+		if len(got) != 3 {
+			t.Fatalf("round %d selected %v, want 3 clients", round, got)
+		}
+		if !slices.Equal(got, f.SelectClients(round, available)) {
+			t.Fatalf("round %d selection not reproducible", round)
+		}
+		for _, id := range got {
+			seen[id] = true
+		}
+	}
+	if len(seen) != len(available) {
+		t.Errorf("20 rounds selected only %d of %d clients", len(seen), len(available))
+	}
+	if got := NewFedAvg().SelectClients(0, available); !slices.Equal(got, available) {
+		t.Errorf("default selection = %v, want all clients", got)
+	}
+}
+
+func TestCoordinator_RunConvergence(t *testing.T) {
+	target := []float64{1, -1}
+	clients := []Client{
+		newConvergingClient("a", 10, target, []float64{0, 0}, 0.5),
+		newConvergingClient("b", 30, target, []float64{0, 0}, 0.5),
+	}
+	coord := NewCoordinator(NewFedAvg(), CoordinatorConfig{MinClients: 2, MaxRounds: 100, ConvergenceThreshold: 1e-4})
+	results, err := coord.Run(clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) >= 100 || len(results) < 3 {
+		t.Errorf("ran %d rounds, want convergence well before 100", len(results))
+	}
+
+	if _, err := NewCoordinator(NewFedAvg(), CoordinatorConfig{}).Run(clients); err == nil {
+		t.Error("Run without MaxRounds succeeded")
+	}
+}
+
+func TestSetWeights(t *testing.T) {
+	_, g := newRegressionClient(t, "a", 1, 1)
+	w := Weights(g)
+	if len(w) != 3 {
+		t.Fatalf("Weights() has %d values, want 3", len(w))
+	}
+	if err := SetWeights(g, []float64{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got := Weights(g); !slices.Equal(got, []float64{1, 2, 3}) {
+		t.Errorf("Weights() after SetWeights = %v", got)
+	}
+	if err := SetWeights(g, []float64{1}); err == nil {
+		t.Error("SetWeights accepted the wrong number of weights")
+	}
+}
@@ -25,8 +25,22 @@
 //	})
 //	result, err := coord.RunRound(clients)
 //
+// [Coordinator.Run] repeats rounds up to MaxRounds or until the clients'
+// mean loss converges. [WithClientFraction] makes FedAvg sample a seeded
+// fraction of the clients each round.
+//
 // # Client Interface
 //
 // [Client] represents a federated participant that performs local training
-// and reports a [ModelUpdate] back to the coordinator.
+// and reports a [ModelUpdate] back to the coordinator. [WorkflowClient]
+// trains a model graph on a private shard with any training workflow for
+// a number of local epochs per round and reports its weights and shard
+// size:
+//
+//	client := federated.NewWorkflowClient[float32]("site-a", model, workflow,
+//	    training.WorkflowConfig{NumEpochs: 5, LearningRate: 0.01}, shard)
+//	coord := federated.NewCoordinator(federated.NewFedAvg(federated.WithClientFraction(0.1, 1)),
+//	    federated.CoordinatorConfig{MinClients: 1, MaxRounds: 50})
+//	coord.SetGlobalWeights(federated.Weights(model))
+//	results, err := coord.Run(clients)
 package federated
@@ -1,15 +1,42 @@
 package federated
 
-import "errors"
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
 
 // FedAvg implements the Federated Averaging strategy. It computes a weighted
 // average of client model updates, where each client's contribution is
-// proportional to its dataset size (NSamples).
-type FedAvg struct{}
+// proportional to its dataset size (NSamples). By default every available
+// client participates in every round; WithClientFraction samples a subset.
+type FedAvg struct {
+	fraction float64
+	seed     uint64
+}
+
+// FedAvgOption configures a FedAvg strategy.
+type FedAvgOption func(*FedAvg)
+
+// WithClientFraction makes each round select a random fraction of the
+// available clients, rounded up and at least one (the C of McMahan et al.,
+// 2017). A round's sample depends only on seed and the round number, so
+// runs are reproducible. Fractions outside (0, 1) select every client.
+func WithClientFraction(fraction float64, seed uint64) FedAvgOption {
+	return func(f *FedAvg) {
+		f.fraction = fraction
+		f.seed = seed
+	}
+}
 
 // NewFedAvg returns a new FedAvg strategy.
-func NewFedAvg() *FedAvg {
-	return &FedAvg{}
+func NewFedAvg(opts ...FedAvgOption) *FedAvg {
+	f := &FedAvg{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 // Aggregate computes the weighted average of model updates. Each update's
@@ -51,9 +78,20 @@ func (f *FedAvg) Aggregate(updates []ModelUpdate) (*AggregatedModel, error) {
 	}, nil
 }
 
-// SelectClients returns all available clients for every round.
-func (f *FedAvg) SelectClients(_ int, available []ClientID) []ClientID {
-	result := make([]ClientID, len(available))
-	copy(result, available)
+// SelectClients returns the clients participating in round: all available
+// clients, or a sample of them with WithClientFraction, in their original
+// order.
+func (f *FedAvg) SelectClients(round int, available []ClientID) []ClientID {
+	if f.fraction <= 0 || f.fraction >= 1 {
+		return slices.Clone(available)
+	}
+	n := max(1, int(math.Ceil(f.fraction*float64(len(available)))))
+	rng := rand.New(rand.NewPCG(f.seed, uint64(round)))
+	picked := rng.Perm(len(available))[:min(n, len(available))]
+	slices.Sort(picked)
+	result := make([]ClientID, len(picked))
+	for i, j := range picked {
+		result[i] = available[j]
+	}
 	return result
 }
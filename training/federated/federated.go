@@ -1,6 +1,10 @@
 package federated
 
-import "errors"
+import (
+	"errors"
+	"math"
+	"slices"
+)
 
 // ClientID uniquely identifies a federated learning participant.
 type ClientID string
@@ -130,3 +134,61 @@ func (c *Coordinator) RunRound(clients []Client) (*RoundResult, error) {
 func (c *Coordinator) Round() int {
 	return c.round
 }
+
+// SetGlobalWeights sets the global model that the next round distributes,
+// such as the initial weights of a model, so that all clients start from
+// the same point.
+func (c *Coordinator) SetGlobalWeights(weights []float64) {
+	c.globalWeights = slices.Clone(weights)
+}
+
+// GlobalWeights returns the current global model: the last aggregate, or
+// the weights set by SetGlobalWeights.
+func (c *Coordinator) GlobalWeights() []float64 {
+	return c.globalWeights
+}
+
+// Run executes rounds until MaxRounds rounds have run or, with a
+// ConvergenceThreshold, until the sample-weighted mean of the clients'
+// "loss" metrics changes by less than the threshold from one round to the
+// next. It returns the results of the rounds it ran, and those up to a
+// failing round with its error. The final model is GlobalWeights.
+func (c *Coordinator) Run(clients []Client) ([]*RoundResult, error) {
+	if c.config.MaxRounds <= 0 {
+		return nil, errors.New("federated: MaxRounds must be positive")
+	}
+	var results []*RoundResult
+	prev, havePrev := 0.0, false
+	for c.round < c.config.MaxRounds {
+		r, err := c.RunRound(clients)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+		loss, ok := meanLoss(r.Updates)
+		if c.config.ConvergenceThreshold > 0 && ok && havePrev && math.Abs(loss-prev) < c.config.ConvergenceThreshold {
+			break
+		}
+		prev, havePrev = loss, ok
+	}
+	return results, nil
+}
+
+// meanLoss returns the sample-weighted mean of the "loss" metrics of
+// updates, and false if an update has none.
+func meanLoss(updates []ModelUpdate) (float64, bool) {
+	var sum float64
+	n := 0
+	for _, u := range updates {
+		loss, ok := u.Metrics["loss"]
+		if !ok {
+			return 0, false
+		}
+		sum += loss * float64(u.NSamples)
+		n += u.NSamples
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
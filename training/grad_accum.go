@@ -35,6 +35,7 @@ import (
 	"strconv"
 
 	"github.com/zerfoo/float16"
+	"github.com/zerfoo/zerfoo/internal/kernels"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -361,72 +362,15 @@ func (a *gradAccumulator[T]) addInto(ctx context.Context, eng compute.Engine[T],
 	return nil
 }
 
-// addSlice performs dst[i] += src[i] for the built-in numeric types.
-// Minifloat types (float16/bfloat16/float8) are not supported on this host
-// fallback path; configure an engine via SetEngine for those. In practice
-// they cannot reach here: arena-backed gradients only come from the GPU
-// engine, whose kernels are float32-gated.
+// addSlice performs dst[i] += src[i] using the host loop kernels has for T.
+// float8 has none; configure an engine via SetEngine for it. In practice it
+// cannot reach here: arena-backed gradients only come from the GPU engine,
+// whose kernels are float32-gated.
 func addSlice[T tensor.Numeric](dst, src []T) error {
 	if len(dst) != len(src) {
 		return errors.New("length mismatch")
 	}
-	switch d := any(dst).(type) {
-	case []float32:
-		s := any(src).([]float32)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []float64:
-		s := any(src).([]float64)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []int:
-		s := any(src).([]int)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []int8:
-		s := any(src).([]int8)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []int16:
-		s := any(src).([]int16)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []int32:
-		s := any(src).([]int32)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []int64:
-		s := any(src).([]int64)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []uint:
-		s := any(src).([]uint)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []uint8:
-		s := any(src).([]uint8)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []uint32:
-		s := any(src).([]uint32)
-		for i := range d {
-			d[i] += s[i]
-		}
-	case []uint64:
-		s := any(src).([]uint64)
-		for i := range d {
-			d[i] += s[i]
-		}
-	default:
+	if !kernels.Add(dst, dst, src) {
 		return errors.New("unsupported numeric type for host gradient accumulation; set an engine on the strategy")
 	}
 	return nil
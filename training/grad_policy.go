@@ -0,0 +1,185 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	opt "github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GradPolicy selects who resets parameter gradients between training steps.
+type GradPolicy int
+
+const (
+	// GradPolicyOptimizer leaves gradient zeroing to the optimizer: AdamW and
+	// AdamW8bit zero gradients at the end of Step, SGD never does. This is
+	// the default and matches the trainer's behavior before policies existed.
+	GradPolicyOptimizer GradPolicy = iota
+	// GradPolicyZero makes TrainStep zero every gradient before computing
+	// new ones and stops the optimizer from zeroing them after Step, so each
+	// step sees exactly the current batch's gradients and they stay readable
+	// after Step.
+	GradPolicyZero
+	// GradPolicyAccumulate never zeroes gradients automatically: successive
+	// TrainStep calls add into the same gradients until the caller resets
+	// them with DefaultTrainer.ZeroGrad. Use it to sum gradients of several
+	// losses, or to step only part of a model while another part keeps its
+	// gradients, as in alternating GAN updates.
+	GradPolicyAccumulate
+)
+
+// String returns the policy name.
+func (p GradPolicy) String() string {
+	switch p {
+	case GradPolicyOptimizer:
+		return "optimizer"
+	case GradPolicyZero:
+		return "zero"
+	case GradPolicyAccumulate:
+		return "accumulate"
+	default:
+		return fmt.Sprintf("GradPolicy(%d)", int(p))
+	}
+}
+
+// WithGradPolicy sets the trainer's gradient zeroing policy. With gradient
+// accumulation enabled (WithGradAccumulation) the trainer manages
+// micro-batch gradients itself and the policy has no effect.
+func WithGradPolicy[T tensor.Numeric](p GradPolicy) DefaultTrainerOption[T] {
+	return func(t *DefaultTrainer[T]) {
+		t.gradPolicy = p
+	}
+}
+
+// GradPolicy returns the trainer's gradient zeroing policy.
+func (t *DefaultTrainer[T]) GradPolicy() GradPolicy { return t.gradPolicy }
+
+// SetGradPolicy changes the gradient zeroing policy. It takes effect at the
+// next TrainStep, so the policy can differ from one step to the next.
+func (t *DefaultTrainer[T]) SetGradPolicy(p GradPolicy) { t.gradPolicy = p }
+
+// ZeroGrad zeroes the gradients of the trainer's graph parameters in place.
+func (t *DefaultTrainer[T]) ZeroGrad(ctx context.Context) error {
+	return zeroGradients(ctx, t.g)
+}
+
+// computeGradients runs the strategy under the gradient policy. Layers differ
+// in whether Backward adds into or overwrites Parameter.Gradient, so both
+// GradPolicyZero and GradPolicyAccumulate start the strategy from zeroed
+// gradients; GradPolicyAccumulate then adds back the gradients held before
+// the step.
+func (t *DefaultTrainer[T]) computeGradients(ctx context.Context, g *graph.Graph[T], optimizer opt.Optimizer[T], batch Batch[T]) (T, error) {
+	t.applyGradPolicy(optimizer)
+	var zero T
+	switch t.gradPolicy {
+	case GradPolicyZero:
+		if err := zeroGradients(ctx, g); err != nil {
+			return zero, err
+		}
+	case GradPolicyAccumulate:
+		saved, err := t.saveGradients(ctx, g)
+		if err != nil {
+			return zero, err
+		}
+		if err := zeroGradients(ctx, g); err != nil {
+			return zero, err
+		}
+		lossVal, err := t.strategy.ComputeGradients(ctx, g, t.loss, batch)
+		if err != nil {
+			return zero, err
+		}
+		engine := g.Engine()
+		for p, prev := range saved {
+			if p.Gradient == nil {
+				// The step cleared the gradient; keep a copy of the saved
+				// one, since the saved buffer is reused by the next step.
+				if p.Gradient, err = tensor.New[T](prev.Shape(), nil); err != nil {
+					return zero, err
+				}
+				if err := engine.Copy(ctx, p.Gradient, prev); err != nil {
+					return zero, fmt.Errorf("training: restore gradient of %q: %w", p.Name, err)
+				}
+				continue
+			}
+			if _, err := engine.Add(ctx, p.Gradient, prev, p.Gradient); err != nil {
+				return zero, fmt.Errorf("training: accumulate gradient of %q: %w", p.Name, err)
+			}
+		}
+		return lossVal, nil
+	}
+	return t.strategy.ComputeGradients(ctx, g, t.loss, batch)
+}
+
+// saveGradients copies the current gradients of g's parameters into
+// trainer-owned buffers and returns them by parameter.
+func (t *DefaultTrainer[T]) saveGradients(ctx context.Context, g *graph.Graph[T]) (map[*graph.Parameter[T]]*tensor.TensorNumeric[T], error) {
+	engine := g.Engine()
+	if engine == nil {
+		return nil, errors.New("training: GradPolicyAccumulate requires a graph with an engine")
+	}
+	if t.saved == nil {
+		t.saved = make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T])
+	}
+	out := make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T])
+	for _, p := range uniqueParameters(g) {
+		if p.Gradient == nil {
+			continue
+		}
+		buf, ok := t.saved[p]
+		if !ok || !tensor.ShapesEqual(buf.Shape(), p.Gradient.Shape()) {
+			var err error
+			buf, err = tensor.New[T](p.Gradient.Shape(), nil)
+			if err != nil {
+				return nil, err
+			}
+			t.saved[p] = buf
+		}
+		if err := engine.Copy(ctx, buf, p.Gradient); err != nil {
+			return nil, fmt.Errorf("training: save gradient of %q: %w", p.Name, err)
+		}
+		out[p] = buf
+	}
+	return out, nil
+}
+
+// applyGradPolicy configures whether optimizer zeroes gradients in Step.
+// Optimizers that do not implement opt.GradientZeroer are left as they are.
+func (t *DefaultTrainer[T]) applyGradPolicy(optimizer opt.Optimizer[T]) {
+	z, ok := optimizer.(opt.GradientZeroer)
+	if !ok {
+		return
+	}
+	switch {
+	case t.gradPolicy != GradPolicyOptimizer:
+		z.SetZeroGradOnStep(false)
+		t.zeroerOff = true
+	case t.zeroerOff:
+		z.SetZeroGradOnStep(true)
+		t.zeroerOff = false
+	}
+}
+
+// zeroGradients zeroes the gradients of g's parameters in place, keeping
+// their buffers so strategies that track gradient storage identity (see
+// gradAccumulator) keep seeing the same tensors.
+func zeroGradients[T tensor.Numeric](ctx context.Context, g *graph.Graph[T]) error {
+	params := uniqueParameters(g)
+	engine := g.Engine()
+	if engine == nil {
+		opt.ZeroGrad(params)
+		return nil
+	}
+	var zero T
+	for _, p := range params {
+		if p.Gradient == nil {
+			continue
+		}
+		if err := engine.Fill(ctx, p.Gradient, zero); err != nil {
+			return fmt.Errorf("training: zero gradient of %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
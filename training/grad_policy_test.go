@@ -0,0 +1,75 @@
+package training_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/training"
+)
+
+func (r *accumRig) grads() []float32 {
+	var out []float32
+	for _, p := range r.g.Parameters() {
+		if p.Gradient != nil {
+			out = append(out, p.Gradient.Data()...)
+		}
+	}
+	return out
+}
+
+func TestDefaultTrainer_GradPolicy(t *testing.T) {
+	x, y := syntheticBatch(5)
+
+	// The default leaves zeroing to AdamW.
+	r := newAccumRig(t, 4)
+	if got := r.trainer.GradPolicy(); got != training.GradPolicyOptimizer {
+		t.Fatalf("default GradPolicy = %v, want %v", got, training.GradPolicyOptimizer)
+	}
+	r.step(t, x, y)
+	for i, v := range r.grads() {
+		if v != 0 {
+			t.Fatalf("GradPolicyOptimizer: gradient[%d] = %v after step, want 0", i, v)
+		}
+	}
+
+	// GradPolicyZero keeps the gradients of the step just taken, and a
+	// second step overwrites rather than adds to them.
+	r = newAccumRig(t, 4, training.WithGradPolicy[float32](training.GradPolicyZero))
+	r.opt.SetLR(0)
+	r.step(t, x, y)
+	first := slices.Clone(r.grads())
+	if slices.Equal(first, make([]float32, len(first))) {
+		t.Fatal("GradPolicyZero: gradients are zero after step, want them kept")
+	}
+	r.step(t, x, y)
+	assertClose(t, r.grads(), first, 1e-6)
+
+	// GradPolicyAccumulate adds a second step's gradients to the first until
+	// ZeroGrad is called. A zero learning rate keeps the parameters, and so
+	// each step's gradients, fixed.
+	r.trainer.SetGradPolicy(training.GradPolicyAccumulate)
+	r.step(t, x, y)
+	double := make([]float32, len(first))
+	for i, v := range first {
+		double[i] = 2 * v
+	}
+	assertClose(t, r.grads(), double, 1e-5)
+	if err := r.trainer.ZeroGrad(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range r.grads() {
+		if v != 0 {
+			t.Fatalf("gradient[%d] = %v after ZeroGrad, want 0", i, v)
+		}
+	}
+
+	// Switching back hands zeroing to the optimizer again.
+	r.trainer.SetGradPolicy(training.GradPolicyOptimizer)
+	r.step(t, x, y)
+	for i, v := range r.grads() {
+		if v != 0 {
+			t.Fatalf("GradPolicyOptimizer after switch: gradient[%d] = %v, want 0", i, v)
+		}
+	}
+}
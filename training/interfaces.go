@@ -3,6 +3,7 @@ package training
 
 import (
 	"context"
+	"time"
 
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -144,6 +145,39 @@ type WorkflowConfig struct {
 	MaxNoImprove int     `json:"max_no_improve"`
 	RandomSeed   uint64  `json:"random_seed"`
 
+	// Early stopping (see EarlyStopper). Training stops after MaxNoImprove
+	// epochs in which EarlyStopMetric did not improve on its best value by
+	// more than EarlyStopTol; zero disables it. EarlyStopMetric names an
+	// epoch metric such as "val_loss", "train_loss" or a registered metric;
+	// empty monitors the workflow's loss. EarlyStopMode is "min" (default)
+	// or "max". RestoreBest restores the parameters of the best epoch when
+	// training ends, so the returned and saved model is the best one.
+	EarlyStopMetric string `json:"early_stop_metric"`
+	EarlyStopMode   string `json:"early_stop_mode"`
+	RestoreBest     bool   `json:"restore_best"`
+
+	// AccumulationSteps is the number of micro-batches whose gradients are
+	// averaged before each optimizer step, for an effective batch size of
+	// AccumulationSteps * BatchConfig.BatchSize. Zero or one disables
+	// accumulation. Gradient clipping applies to the averaged gradient; see
+	// DefaultTrainer.
+	AccumulationSteps int `json:"accumulation_steps"`
+
+	// Gradient clipping, applied between the backward pass and each
+	// optimizer step. ClipValue clamps every gradient element to
+	// [-ClipValue, ClipValue]; ClipNorm then scales the gradients so their
+	// global L2 norm is at most ClipNorm. Zero disables either.
+	ClipNorm  float64 `json:"clip_norm"`
+	ClipValue float64 `json:"clip_value"`
+
+	// Time limit configuration. MaxWallClock bounds how long Train runs;
+	// zero means no limit. When it elapses, training stops at the next step
+	// boundary and, if CheckpointPath is set, the model is saved there.
+	// Leave headroom below a scheduler's hard limit for the step in flight
+	// and the save.
+	MaxWallClock   time.Duration `json:"max_wall_clock"`
+	CheckpointPath string        `json:"checkpoint_path"`
+
 	// Component configurations
 	BatchConfig   BatchConfig            `json:"batch_config"`
 	ModelConfig   ModelConfig            `json:"model_config"`
@@ -168,6 +202,22 @@ type ModelConfig struct {
 	Architecture map[string]interface{} `json:"architecture"`
 	Hyperparams  map[string]interface{} `json:"hyperparams"`
 	Extensions   map[string]interface{} `json:"extensions"`
+
+	// RandomSeed seeds the model's initial weights. The training
+	// workflows set it from WorkflowConfig.RandomSeed when zero. A provider
+	// makes initialization reproducible by building the model under
+	// components.Seed(engine, RandomSeed).
+	RandomSeed uint64 `json:"random_seed"`
+}
+
+// modelConfig returns the model configuration with the workflow's seed, if
+// the model has none of its own.
+func (c WorkflowConfig) modelConfig() ModelConfig {
+	cfg := c.ModelConfig
+	if cfg.RandomSeed == 0 {
+		cfg.RandomSeed = c.RandomSeed
+	}
+	return cfg
 }
 
 // SequenceConfig configures sequence generation.
@@ -199,6 +249,16 @@ type CVConfig struct {
 
 // Result structures
 
+// StopReason reports why a training run ended.
+type StopReason string
+
+const (
+	// StopCompleted means every configured epoch ran.
+	StopCompleted StopReason = "completed"
+	// StopMaxWallClock means WorkflowConfig.MaxWallClock elapsed first.
+	StopMaxWallClock StopReason = "max_wall_clock"
+)
+
 // TrainingResult contains training outcome information.
 type TrainingResult[T tensor.Numeric] struct {
 	FinalLoss    T                      `json:"final_loss"`
@@ -206,6 +266,7 @@ type TrainingResult[T tensor.Numeric] struct {
 	BestEpoch    int                    `json:"best_epoch"`
 	TotalEpochs  int                    `json:"total_epochs"`
 	TrainingTime float64                `json:"training_time_seconds"`
+	StopReason   StopReason             `json:"stop_reason"`
 	Metrics      map[string]float64     `json:"metrics"`
 	ModelPath    string                 `json:"model_path,omitempty"`
 	Extensions   map[string]interface{} `json:"extensions"`
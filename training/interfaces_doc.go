@@ -124,6 +124,20 @@
 //	    },
 //	}
 //
+// ## Time Limits
+//
+// For batch schedulers with hard time limits, set MaxWallClock below the
+// allocation and a CheckpointPath. Train then stops at the next step
+// boundary once the limit passes, saves the model, and reports
+// StopMaxWallClock in TrainingResult.StopReason so the job can be
+// resubmitted from the checkpoint:
+//
+//	config := WorkflowConfig{
+//	    NumEpochs:      100,
+//	    MaxWallClock:   3*time.Hour + 50*time.Minute,
+//	    CheckpointPath: "/scratch/run-42/model.gguf",
+//	}
+//
 // ## Configuration Validation
 //
 // Implementations should validate their configuration and return descriptive
@@ -137,12 +151,16 @@
 // ## Registration Pattern
 //
 //	// Register a component
-//	err := Float32Registry.RegisterWorkflow("standard", func(ctx context.Context, config map[string]interface{}) (TrainingWorkflow[float32], error) {
-//	    return NewStandardWorkflow(config), nil
+//	err := Float32Registry.RegisterWorkflow("custom", func(ctx context.Context, config map[string]interface{}) (TrainingWorkflow[float32], error) {
+//	    return NewCustomWorkflow(config), nil
 //	})
 //
 //	// Use registered component
-//	workflow, err := Float32Registry.GetWorkflow(ctx, "standard", config)
+//	workflow, err := Float32Registry.GetWorkflow(ctx, "custom", config)
+//
+// The package registers StandardWorkflow as "standard", CSVDataProvider as
+// "csv" and ParquetDataProvider as "parquet" in Float32Registry and
+// Float64Registry.
 //
 // ## Factory Functions
 //
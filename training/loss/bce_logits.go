@@ -0,0 +1,105 @@
+package loss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// BCEWithLogitsLoss is binary cross-entropy on raw scores: the sigmoid is
+// folded into the loss, which is computed as
+// max(x, 0) - x*y + log(1 + exp(-|x|)) so it stays finite for any logit.
+// Prefer it to a Sigmoid layer followed by BCELoss.
+type BCEWithLogitsLoss[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+
+	// Cached inputs for the backward pass.
+	logits  *tensor.TensorNumeric[T]
+	targets *tensor.TensorNumeric[T]
+}
+
+// NewBCEWithLogitsLoss creates a BCEWithLogitsLoss.
+func NewBCEWithLogitsLoss[T tensor.Numeric](engine compute.Engine[T]) *BCEWithLogitsLoss[T] {
+	return &BCEWithLogitsLoss[T]{engine: engine}
+}
+
+// Forward computes the mean loss of inputs[0] (logits) against inputs[1]
+// (targets in [0, 1]).
+func (b *BCEWithLogitsLoss[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("BCEWithLogitsLoss expects 2 inputs, got %d", len(inputs))
+	}
+	logits, targets := inputs[0], inputs[1]
+	b.logits, b.targets = logits, targets
+
+	ops := b.engine.Ops()
+	var zero T
+	one := ops.One()
+	// softplus(x) = max(x, 0) + log(1 + exp(-|x|))
+	softplus, err := b.engine.UnaryOp(ctx, logits, func(x T) T {
+		s := ops.Log(ops.Add(one, ops.Exp(ops.Sub(zero, ops.Abs(x)))))
+		if ops.GreaterThan(x, zero) {
+			return ops.Add(x, s)
+		}
+		return s
+	})
+	if err != nil {
+		return nil, err
+	}
+	xy, err := b.engine.Mul(ctx, logits, targets, nil)
+	if err != nil {
+		return nil, err
+	}
+	perElem, err := b.engine.Sub(ctx, softplus, xy, nil)
+	if err != nil {
+		return nil, err
+	}
+	return meanAll(ctx, b.engine, perElem)
+}
+
+// Backward returns the gradient for the logits, (sigmoid(x) - y) divided
+// by the element count, and nil for the targets.
+func (b *BCEWithLogitsLoss[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if b.logits == nil {
+		return nil, fmt.Errorf("BCEWithLogitsLoss: Backward called before Forward")
+	}
+	probs, err := b.engine.UnaryOp(ctx, b.logits, b.engine.Ops().Sigmoid)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := b.engine.Sub(ctx, probs, b.targets, nil)
+	if err != nil {
+		return nil, err
+	}
+	grad, err := scaleByCount(ctx, b.engine, diff, dOut)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{grad, nil}, nil
+}
+
+// OutputShape returns the output shape of the loss, a single value.
+func (b *BCEWithLogitsLoss[T]) OutputShape() []int {
+	return []int{1}
+}
+
+// OpType returns the operation type of the loss.
+func (b *BCEWithLogitsLoss[T]) OpType() string {
+	return "BCEWithLogitsLoss"
+}
+
+// Attributes returns nil; the loss has no attributes.
+func (b *BCEWithLogitsLoss[T]) Attributes() map[string]interface{} {
+	return nil
+}
+
+// Parameters returns nil; the loss has no trainable parameters.
+func (b *BCEWithLogitsLoss[T]) Parameters() []*graph.Parameter[T] {
+	return nil
+}
+
+var _ graph.Node[float32] = (*BCEWithLogitsLoss[float32])(nil)
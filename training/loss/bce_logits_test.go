@@ -0,0 +1,42 @@
+package loss
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestBCEWithLogitsLoss(t *testing.T) {
+	ctx := context.Background()
+	b := NewBCEWithLogitsLoss(newFloat64Engine())
+	logits, _ := tensor.New([]int{4}, []float64{2, -1, 0.5, -3})
+	targets, _ := tensor.New([]int{4}, []float64{1, 0, 0, 1})
+	out, err := b.Forward(ctx, logits, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want float64
+	for i, x := range logits.Data() {
+		p := 1 / (1 + math.Exp(-x))
+		y := targets.Data()[i]
+		want -= y*math.Log(p) + (1-y)*math.Log(1-p)
+	}
+	want /= 4
+	if got := out.Data()[0]; math.Abs(got-want) > 1e-12 {
+		t.Errorf("loss = %v, want %v", got, want)
+	}
+	checkLossGradient(t, b, logits, targets)
+
+	// Extreme logits stay finite where sigmoid followed by log would not.
+	logits, _ = tensor.New([]int{2}, []float64{800, -800})
+	targets, _ = tensor.New([]int{2}, []float64{0, 1})
+	out, err = b.Forward(ctx, logits, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Data()[0]; math.Abs(got-800) > 1e-9 {
+		t.Errorf("loss on extreme logits = %v, want 800", got)
+	}
+}
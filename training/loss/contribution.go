@@ -0,0 +1,259 @@
+package loss
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/zerfoo/zerfoo/internal/ranking"
+)
+
+// This file holds host-side estimators for Numerai-style scoring: the
+// tournament correlation, meta-model contribution (MMC) and benchmark-model
+// contribution (BMC). All of them rank-gaussianize predictions, so they
+// depend only on the ordering of predictions within an era; score each era
+// separately and aggregate with EraScores and SummarizeEras.
+
+// NumeraiCorr returns Numerai's tournament correlation of preds with
+// targets: predictions are rank-gaussianized and both series are raised to
+// the power 1.5 (keeping their sign) to weight the tails before the Pearson
+// correlation is taken. targets are centered first.
+func NumeraiCorr(preds, targets []float64) (float64, error) {
+	if err := checkLengths(preds, targets); err != nil {
+		return 0, fmt.Errorf("NumeraiCorr: %w", err)
+	}
+	p := gaussianRank(preds)
+	t := centered(targets)
+	for i := range p {
+		p[i] = signedPow(p[i], 1.5)
+		t[i] = signedPow(t[i], 1.5)
+	}
+	return pearsonCorr(p, t), nil
+}
+
+// CorrelationContribution returns the contribution of preds to a reference
+// model: the covariance with centered targets of the rank-gaussianized
+// predictions after the rank-gaussianized reference predictions have been
+// projected out of them. With the meta-model (or a proxy of it) as
+// reference this is MMC; with the benchmark models' ensemble it is BMC.
+// Predictions identical to the reference score zero however well they
+// correlate with the targets.
+func CorrelationContribution(preds, reference, targets []float64) (float64, error) {
+	if err := checkLengths(preds, reference, targets); err != nil {
+		return 0, fmt.Errorf("CorrelationContribution: %w", err)
+	}
+	p := orthogonalize(gaussianRank(preds), gaussianRank(reference))
+	t := centered(targets)
+	return dot(p, t) / float64(len(t)), nil
+}
+
+// NeutralizeTargets returns targets with the rank-gaussianized reference
+// predictions projected out within each era. Since the covariance of
+// neutralized predictions with the targets equals the covariance of the
+// predictions with neutralized targets, training a correlation objective
+// such as CorrLoss on these targets optimizes for contribution to the
+// reference model (MMC or BMC) rather than raw correlation.
+func NeutralizeTargets[E comparable](targets, reference []float64, eras []E) ([]float64, error) {
+	if err := checkLengths(targets, reference); err != nil {
+		return nil, fmt.Errorf("NeutralizeTargets: %w", err)
+	}
+	groups, err := eraGroups(eras, len(targets))
+	if err != nil {
+		return nil, fmt.Errorf("NeutralizeTargets: %w", err)
+	}
+	out := make([]float64, len(targets))
+	for _, g := range groups {
+		t := centered(gather(targets, g.rows))
+		t = orthogonalize(t, gaussianRank(gather(reference, g.rows)))
+		for i, row := range g.rows {
+			out[row] = t[i]
+		}
+	}
+	return out, nil
+}
+
+// EraScore is a metric computed over the rows of one era.
+type EraScore[E comparable] struct {
+	Era   E
+	Score float64
+}
+
+// EraMetric scores one era given its predictions, reference predictions and
+// targets. NumeraiCorr can be adapted by ignoring the reference;
+// CorrelationContribution matches it directly.
+type EraMetric func(preds, reference, targets []float64) (float64, error)
+
+// EraScores splits the rows by era, in order of first appearance, and
+// scores each era with metric. reference may be nil for metrics that do not
+// use it.
+func EraScores[E comparable](metric EraMetric, preds, reference, targets []float64, eras []E) ([]EraScore[E], error) {
+	if reference != nil {
+		if err := checkLengths(preds, reference); err != nil {
+			return nil, fmt.Errorf("EraScores: %w", err)
+		}
+	}
+	if err := checkLengths(preds, targets); err != nil {
+		return nil, fmt.Errorf("EraScores: %w", err)
+	}
+	groups, err := eraGroups(eras, len(preds))
+	if err != nil {
+		return nil, fmt.Errorf("EraScores: %w", err)
+	}
+	scores := make([]EraScore[E], len(groups))
+	for i, g := range groups {
+		var ref []float64
+		if reference != nil {
+			ref = gather(reference, g.rows)
+		}
+		s, err := metric(gather(preds, g.rows), ref, gather(targets, g.rows))
+		if err != nil {
+			return nil, fmt.Errorf("EraScores: era %v: %w", g.era, err)
+		}
+		scores[i] = EraScore[E]{Era: g.era, Score: s}
+	}
+	return scores, nil
+}
+
+// EraSummary aggregates per-era scores.
+type EraSummary struct {
+	Mean   float64 // mean score per era
+	Std    float64 // sample standard deviation across eras
+	Sharpe float64 // Mean / Std, or 0 when Std is 0
+	// MaxDrawdown is the largest drop of the cumulative score from its
+	// running peak, as a non-negative number.
+	MaxDrawdown float64
+}
+
+// SummarizeEras returns the mean, spread, Sharpe ratio and maximum drawdown
+// of per-era scores, in era order.
+func SummarizeEras[E comparable](scores []EraScore[E]) EraSummary {
+	var s EraSummary
+	n := len(scores)
+	if n == 0 {
+		return s
+	}
+	var peak, cum float64
+	for _, e := range scores {
+		s.Mean += e.Score
+		cum += e.Score
+		peak = max(peak, cum)
+		s.MaxDrawdown = max(s.MaxDrawdown, peak-cum)
+	}
+	s.Mean /= float64(n)
+	if n > 1 {
+		var ss float64
+		for _, e := range scores {
+			d := e.Score - s.Mean
+			ss += d * d
+		}
+		s.Std = math.Sqrt(ss / float64(n-1))
+	}
+	if s.Std > 0 {
+		s.Sharpe = s.Mean / s.Std
+	}
+	return s
+}
+
+type eraGroup[E comparable] struct {
+	era  E
+	rows []int
+}
+
+// eraGroups returns the row indices of each era in order of first
+// appearance.
+func eraGroups[E comparable](eras []E, n int) ([]eraGroup[E], error) {
+	if len(eras) != n {
+		return nil, fmt.Errorf("%d era labels for %d rows", len(eras), n)
+	}
+	index := make(map[E]int)
+	var groups []eraGroup[E]
+	for row, e := range eras {
+		i, ok := index[e]
+		if !ok {
+			i = len(groups)
+			index[e] = i
+			groups = append(groups, eraGroup[E]{era: e})
+		}
+		groups[i].rows = append(groups[i].rows, row)
+	}
+	return groups, nil
+}
+
+func checkLengths(first []float64, rest ...[]float64) error {
+	if len(first) == 0 {
+		return errors.New("no rows")
+	}
+	for _, s := range rest {
+		if len(s) != len(first) {
+			return fmt.Errorf("length mismatch: %d vs %d", len(s), len(first))
+		}
+	}
+	return nil
+}
+
+// gaussianRank maps x to normal quantiles of its ranks: ties share their
+// average rank r (1-based), which maps to Φ⁻¹((r - 0.5) / n). The result
+// has mean zero and depends only on the ordering of x. NaN values stay NaN.
+// model/postprocess gaussianizes served predictions with the same helper.
+func gaussianRank(x []float64) []float64 {
+	out := make([]float64, len(x))
+	ranking.Transform(out, x, nil, ranking.Gaussian)
+	return out
+}
+
+// orthogonalize returns v with its projection onto u removed.
+func orthogonalize(v, u []float64) []float64 {
+	uu := dot(u, u)
+	out := slices.Clone(v)
+	if uu == 0 {
+		return out
+	}
+	k := dot(v, u) / uu
+	for i := range out {
+		out[i] -= k * u[i]
+	}
+	return out
+}
+
+func centered(x []float64) []float64 {
+	var mean float64
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	out := make([]float64, len(x))
+	for i, v := range x {
+		out[i] = v - mean
+	}
+	return out
+}
+
+func pearsonCorr(x, y []float64) float64 {
+	x, y = centered(x), centered(y)
+	den := math.Sqrt(dot(x, x) * dot(y, y))
+	if den == 0 {
+		return 0
+	}
+	return dot(x, y) / den
+}
+
+func signedPow(x, p float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(x), p), x)
+}
+
+func dot(x, y []float64) float64 {
+	var s float64
+	for i := range x {
+		s += x[i] * y[i]
+	}
+	return s
+}
+
+func gather(x []float64, rows []int) []float64 {
+	out := make([]float64, len(rows))
+	for i, r := range rows {
+		out[i] = x[r]
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package loss
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestGaussianRank(t *testing.T) {
+	z := gaussianRank([]float64{3, 1, 2, 2})
+	// Ranks 4, 1, 2.5, 2.5 of n = 4.
+	want := []float64{
+		math.Sqrt2 * math.Erfinv(2*3.5/4-1),
+		math.Sqrt2 * math.Erfinv(2*0.5/4-1),
+		0, 0,
+	}
+	for i := range z {
+		if math.Abs(z[i]-want[i]) > 1e-12 {
+			t.Errorf("z[%d] = %v, want %v", i, z[i], want[i])
+		}
+	}
+}
+
+func randomSeries(rng *rand.Rand, n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	return x
+}
+
+func TestCorrelationContribution(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	const n = 500
+	targets := randomSeries(rng, n)
+	meta := randomSeries(rng, n)
+
+	// A copy of the meta-model contributes nothing, even when it is a
+	// monotonic transform of it.
+	copyOfMeta := make([]float64, n)
+	for i, m := range meta {
+		copyOfMeta[i] = math.Exp(m)
+	}
+	mmc, err := CorrelationContribution(copyOfMeta, meta, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(mmc) > 1e-12 {
+		t.Errorf("MMC of the meta-model itself = %v, want 0", mmc)
+	}
+
+	// Predictions that know the target, uncorrelated with the meta-model,
+	// contribute positively.
+	mmc, err = CorrelationContribution(targets, meta, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mmc < 0.5 {
+		t.Errorf("MMC of target-aligned predictions = %v, want > 0.5", mmc)
+	}
+
+	// Scoring against neutralized targets gives the same number.
+	eras := make([]int, n)
+	neutral, err := NeutralizeTargets(targets, meta, eras)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dot(gaussianRank(targets), neutral) / n; math.Abs(got-mmc) > 1e-9 {
+		t.Errorf("covariance with neutralized targets = %v, want MMC %v", got, mmc)
+	}
+
+	if _, err := CorrelationContribution(targets, meta[:10], targets); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+}
+
+func TestNumeraiCorr(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	targets := randomSeries(rng, 200)
+	corr, err := NumeraiCorr(targets, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corr < 0.9 || corr > 1 {
+		t.Errorf("NumeraiCorr of targets with themselves = %v, want in (0.9, 1]", corr)
+	}
+	reversed := make([]float64, len(targets))
+	for i, v := range targets {
+		reversed[i] = -v
+	}
+	anti, err := NumeraiCorr(reversed, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(anti+corr) > 1e-12 {
+		t.Errorf("NumeraiCorr of reversed predictions = %v, want %v", anti, -corr)
+	}
+}
+
+func TestEraScores(t *testing.T) {
+	preds := []float64{1, 2, 3, 3, 2, 1, 1, 2, 3}
+	targets := []float64{1, 2, 3, 1, 2, 3, 1, 2, 3}
+	eras := []string{"0001", "0001", "0001", "0002", "0002", "0002", "0003", "0003", "0003"}
+	corr := func(p, _, t []float64) (float64, error) { return NumeraiCorr(p, t) }
+	scores, err := EraScores(corr, preds, nil, targets, eras)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 3 || scores[0].Era != "0001" || scores[1].Era != "0002" || scores[2].Era != "0003" {
+		t.Fatalf("scores = %+v, want eras 0001, 0002, 0003 in order", scores)
+	}
+	if scores[0].Score <= 0 || scores[1].Score >= 0 || scores[2].Score != scores[0].Score {
+		t.Errorf("scores = %+v, want positive, negative, positive", scores)
+	}
+
+	s := SummarizeEras(scores)
+	c := scores[0].Score
+	if math.Abs(s.Mean-c/3) > 1e-12 {
+		t.Errorf("Mean = %v, want %v", s.Mean, c/3)
+	}
+	if math.Abs(s.MaxDrawdown-c) > 1e-12 {
+		t.Errorf("MaxDrawdown = %v, want %v", s.MaxDrawdown, c)
+	}
+	if want := (c / 3) / (2 * c / math.Sqrt(3)); math.Abs(s.Sharpe-want) > 1e-12 {
+		t.Errorf("Sharpe = %v, want %v", s.Sharpe, want)
+	}
+
+	if _, err := EraScores(corr, preds, nil, targets, eras[:3]); err == nil {
+		t.Error("expected error for missing era labels")
+	}
+}
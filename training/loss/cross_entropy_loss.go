@@ -15,6 +15,8 @@ import (
 // CrossEntropyLoss computes the cross-entropy loss.
 type CrossEntropyLoss[T tensor.Numeric] struct {
 	engine compute.Engine[T]
+	// smoothing is the label smoothing factor.
+	smoothing float64
 
 	// Cached tensors for backward pass
 	predictions   *tensor.TensorNumeric[T]   // Model's output (logits)
@@ -23,11 +25,28 @@ type CrossEntropyLoss[T tensor.Numeric] struct {
 	outputShape   []int
 }
 
+// CrossEntropyOption configures a CrossEntropyLoss.
+type CrossEntropyOption[T tensor.Numeric] func(*CrossEntropyLoss[T])
+
+// WithLabelSmoothing trains against (1-eps) on the target class plus eps
+// spread evenly over all K classes, instead of a one-hot target. eps is in
+// [0, 1); NewCrossEntropyLoss clamps it into that range.
+func WithLabelSmoothing[T tensor.Numeric](eps float64) CrossEntropyOption[T] {
+	return func(cel *CrossEntropyLoss[T]) {
+		cel.smoothing = eps
+	}
+}
+
 // NewCrossEntropyLoss creates a new CrossEntropyLoss layer.
-func NewCrossEntropyLoss[T tensor.Numeric](engine compute.Engine[T]) *CrossEntropyLoss[T] {
-	return &CrossEntropyLoss[T]{
+func NewCrossEntropyLoss[T tensor.Numeric](engine compute.Engine[T], opts ...CrossEntropyOption[T]) *CrossEntropyLoss[T] {
+	cel := &CrossEntropyLoss[T]{
 		engine: engine,
 	}
+	for _, o := range opts {
+		o(cel)
+	}
+	cel.smoothing = min(max(cel.smoothing, 0), 0.999)
+	return cel
 }
 
 // OutputShape returns the output shape of the loss (a scalar).
@@ -127,7 +146,17 @@ func (cel *CrossEntropyLoss[T]) Forward(ctx context.Context, inputs ...*tensor.T
 		}
 		// log_softmax(x)_idx = (x_idx - max) - logSumExp
 		logSoftTarget := numericToFloat64(predData[base+idx]) - maxF64 - logSumExp
-		sumNegLogProb = ops.Sub(sumNegLogProb, ops.FromFloat64(logSoftTarget))
+		negLogProb := -logSoftTarget
+		if cel.smoothing > 0 {
+			// The smoothed target puts eps/K on every class, so add eps
+			// times the mean negative log-probability over all classes.
+			var sumLogSoft float64
+			for k := 0; k < lastDim; k++ {
+				sumLogSoft += numericToFloat64(predData[base+k]) - maxF64 - logSumExp
+			}
+			negLogProb = (1-cel.smoothing)*negLogProb - cel.smoothing*sumLogSoft/float64(lastDim)
+		}
+		sumNegLogProb = ops.Add(sumNegLogProb, ops.FromFloat64(negLogProb))
 	}
 	avgLoss := ops.Div(sumNegLogProb, ops.FromFloat64(float64(n)))
 
@@ -154,6 +183,18 @@ func (cel *CrossEntropyLoss[T]) Backward(ctx context.Context, _ types.BackwardMo
 	if err != nil {
 		return nil, err
 	}
+	if cel.smoothing > 0 {
+		// Smoothed target: (1-eps)*one_hot + eps/K.
+		ops := cel.engine.Ops()
+		oneHotTargets, err = cel.engine.MulScalar(ctx, oneHotTargets, ops.FromFloat64(1-cel.smoothing))
+		if err != nil {
+			return nil, err
+		}
+		oneHotTargets, err = cel.engine.AddScalar(ctx, oneHotTargets, ops.FromFloat64(cel.smoothing/float64(vocabSize)))
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// (softmax(predictions) - one_hot(targets))
 	gradPredictions, err := cel.engine.Sub(ctx, cel.softmaxOutput, oneHotTargets, nil)
@@ -196,6 +237,9 @@ func (cel *CrossEntropyLoss[T]) OpType() string {
 
 // Attributes returns the attributes of the CrossEntropyLoss layer.
 func (cel *CrossEntropyLoss[T]) Attributes() map[string]interface{} {
+	if cel.smoothing > 0 {
+		return map[string]interface{}{"label_smoothing": cel.smoothing}
+	}
 	return nil
 }
 
@@ -2,6 +2,7 @@ package loss
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/zerfoo/ztensor/compute"
@@ -266,3 +267,37 @@ func TestCrossEntropyLoss_Forward_LargeLogitsStability(t *testing.T) {
 		t.Errorf("loss = %v, expected ~150 for this configuration", loss)
 	}
 }
+
+func TestCrossEntropyLoss_LabelSmoothing(t *testing.T) {
+	ctx := context.Background()
+	const eps = 0.2
+	cel := NewCrossEntropyLoss(newFloat64Engine(), WithLabelSmoothing[float64](eps))
+	logits, _ := tensor.New([]int{2, 3}, []float64{2, 0.5, -1, 0, 1, 3})
+	labels, _ := tensor.New([]int{2}, []float64{0, 1})
+	out, err := cel.Forward(ctx, logits, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want float64
+	for i, row := range [][]float64{logits.Data()[:3], logits.Data()[3:]} {
+		var z float64
+		for _, x := range row {
+			z += math.Exp(x)
+		}
+		for k, x := range row {
+			q := eps / 3
+			if k == int(labels.Data()[i]) {
+				q += 1 - eps
+			}
+			want -= q * (x - math.Log(z))
+		}
+	}
+	want /= 2
+	if got := out.Data()[0]; math.Abs(got-want) > 1e-9 {
+		t.Errorf("smoothed loss = %v, want %v", got, want)
+	}
+	checkLossGradient(t, cel, logits, labels)
+	if cel.Attributes()["label_smoothing"] != eps {
+		t.Errorf("Attributes() = %v", cel.Attributes())
+	}
+}
@@ -1,4 +1,17 @@
 // Package loss provides loss function implementations for training.
 //
+// Losses are graph nodes: Forward takes the model output and the batch
+// targets and returns a one-element loss, and Backward returns the
+// gradient for the output. The registry maps names to loss factories, and
+// the standard training workflow selects its loss from it by the "loss"
+// config key. Get knows the built-ins: "mse", "cross_entropy" (softmax
+// cross-entropy over class indices), "bce" (on probabilities),
+// "bce_with_logits", "huber" (delta 1) and "pairwise_ranking" (RankNet).
+// Register adds more, such as cross-entropy with label smoothing:
+//
+//	loss.Register("smoothed_ce", func(e compute.Engine[float32]) graph.Node[float32] {
+//		return loss.NewCrossEntropyLoss(e, loss.WithLabelSmoothing[float32](0.1))
+//	})
+//
 // Stability: beta
 package loss
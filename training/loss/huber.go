@@ -0,0 +1,136 @@
+package loss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// HuberLoss is the mean Huber loss between predictions and targets. For a
+// residual r = prediction - target it is r²/2 where |r| <= delta and
+// delta*(|r| - delta/2) beyond, so it is quadratic near zero and linear
+// for outliers.
+type HuberLoss[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+	delta  float64
+
+	// Cached residual for the backward pass.
+	diff *tensor.TensorNumeric[T]
+}
+
+// NewHuberLoss creates a HuberLoss with the given delta, which must be
+// positive.
+func NewHuberLoss[T tensor.Numeric](engine compute.Engine[T], delta float64) (*HuberLoss[T], error) {
+	if delta <= 0 {
+		return nil, fmt.Errorf("HuberLoss: delta must be positive, got %g", delta)
+	}
+	return &HuberLoss[T]{engine: engine, delta: delta}, nil
+}
+
+// Forward computes the mean Huber loss of inputs[0] (predictions) against
+// inputs[1] (targets).
+func (h *HuberLoss[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("HuberLoss expects 2 inputs, got %d", len(inputs))
+	}
+	diff, err := h.engine.Sub(ctx, inputs[0], inputs[1], nil)
+	if err != nil {
+		return nil, err
+	}
+	h.diff = diff
+
+	ops := h.engine.Ops()
+	delta := ops.FromFloat64(h.delta)
+	half := ops.FromFloat64(0.5)
+	perElem, err := h.engine.UnaryOp(ctx, diff, func(r T) T {
+		a := ops.Abs(r)
+		if ops.GreaterThan(a, delta) {
+			return ops.Mul(delta, ops.Sub(a, ops.Mul(half, delta)))
+		}
+		return ops.Mul(half, ops.Mul(r, r))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meanAll(ctx, h.engine, perElem)
+}
+
+// Backward returns the gradient for the predictions, clip(r, -delta, delta)
+// divided by the element count, and nil for the targets.
+func (h *HuberLoss[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if h.diff == nil {
+		return nil, fmt.Errorf("HuberLoss: Backward called before Forward")
+	}
+	ops := h.engine.Ops()
+	delta := ops.FromFloat64(h.delta)
+	negDelta := ops.FromFloat64(-h.delta)
+	clipped, err := h.engine.UnaryOp(ctx, h.diff, func(r T) T {
+		switch {
+		case ops.GreaterThan(r, delta):
+			return delta
+		case ops.GreaterThan(negDelta, r):
+			return negDelta
+		}
+		return r
+	})
+	if err != nil {
+		return nil, err
+	}
+	grad, err := scaleByCount(ctx, h.engine, clipped, dOut)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{grad, nil}, nil
+}
+
+// OutputShape returns the output shape of the loss, a single value.
+func (h *HuberLoss[T]) OutputShape() []int {
+	return []int{1}
+}
+
+// OpType returns the operation type of the loss.
+func (h *HuberLoss[T]) OpType() string {
+	return "HuberLoss"
+}
+
+// Attributes returns the loss's delta.
+func (h *HuberLoss[T]) Attributes() map[string]interface{} {
+	return map[string]interface{}{"delta": h.delta}
+}
+
+// Parameters returns nil; the loss has no trainable parameters.
+func (h *HuberLoss[T]) Parameters() []*graph.Parameter[T] {
+	return nil
+}
+
+var _ graph.Node[float32] = (*HuberLoss[float32])(nil)
+
+// meanAll returns the mean of every element of t as a one-element tensor.
+func meanAll[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], t *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	sum, err := engine.Sum(ctx, t, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	mean, err := engine.MulScalar(ctx, sum, engine.Ops().FromFloat64(1/float64(t.Size())))
+	if err != nil {
+		return nil, err
+	}
+	return engine.Reshape(ctx, mean, []int{1})
+}
+
+// scaleByCount divides the per-element gradient g by its element count, as
+// for a mean reduction, and chains it with the upstream gradient dOut.
+func scaleByCount[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], g, dOut *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	scaled, err := engine.MulScalar(ctx, g, engine.Ops().FromFloat64(1/float64(g.Size())))
+	if err != nil {
+		return nil, err
+	}
+	if dOut == nil {
+		return scaled, nil
+	}
+	return engine.Mul(ctx, scaled, dOut, nil)
+}
@@ -0,0 +1,87 @@
+package loss
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+func newFloat64Engine() compute.Engine[float64] {
+	return compute.NewCPUEngine[float64](numeric.Float64Ops{})
+}
+
+// checkLossGradient compares node's Backward gradient for preds with
+// central finite differences of its Forward.
+func checkLossGradient(t *testing.T, node graph.Node[float64], preds, targets *tensor.TensorNumeric[float64]) {
+	t.Helper()
+	ctx := context.Background()
+	forward := func() float64 {
+		t.Helper()
+		out, err := node.Forward(ctx, preds, targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out.Data()[0]
+	}
+	forward()
+	dOut, _ := tensor.New([]int{1}, []float64{1})
+	grads, err := node.Backward(ctx, types.FullBackprop, dOut, preds, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grads) != 2 {
+		t.Fatalf("Backward returned %d gradients, want 2", len(grads))
+	}
+	got := grads[0].Data()
+	data := preds.Data()
+	const h = 1e-6
+	for i := range data {
+		orig := data[i]
+		data[i] = orig + h
+		up := forward()
+		data[i] = orig - h
+		down := forward()
+		data[i] = orig
+		want := (up - down) / (2 * h)
+		if math.Abs(got[i]-want) > 1e-5 {
+			t.Errorf("grad[%d] = %v, finite difference %v", i, got[i], want)
+		}
+	}
+}
+
+func TestHuberLoss(t *testing.T) {
+	ctx := context.Background()
+	engine := newFloat64Engine()
+	if _, err := NewHuberLoss(engine, 0); err == nil {
+		t.Error("NewHuberLoss accepted delta 0")
+	}
+	h, err := NewHuberLoss(engine, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Residuals 0.5 (quadratic: 0.125) and -3 (linear: 1*(3-0.5) = 2.5).
+	preds, _ := tensor.New([]int{2, 1}, []float64{1.5, -1})
+	targets, _ := tensor.New([]int{2, 1}, []float64{1, 2})
+	out, err := h.Forward(ctx, preds, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.Data()[0], (0.125+2.5)/2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("loss = %v, want %v", got, want)
+	}
+	if shape := out.Shape(); len(shape) != 1 || shape[0] != 1 {
+		t.Errorf("loss shape = %v, want [1]", shape)
+	}
+	checkLossGradient(t, h, preds, targets)
+
+	fresh, _ := NewHuberLoss(engine, 1)
+	if _, err := fresh.Backward(ctx, types.FullBackprop, nil); err == nil {
+		t.Error("Backward before Forward should fail")
+	}
+}
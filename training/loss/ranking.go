@@ -0,0 +1,183 @@
+package loss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// PairwiseRankingLoss is the RankNet pairwise logistic loss. For every pair
+// of items (i, j) in the batch whose targets satisfy y_i > y_j it adds
+// log(1 + exp(-(s_i - s_j))) for the predicted scores s, and it averages
+// over those pairs. Only the order of the targets matters, so they can be
+// relevance grades, finishing positions negated, or any other score. The
+// batch is one ranking group: all pairs are compared, which costs O(N²).
+type PairwiseRankingLoss[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+
+	// Cached for the backward pass: the score shape, s_i - s_j, the mask of
+	// ordered pairs, and their count.
+	shape []int
+	diff  *tensor.TensorNumeric[T]
+	mask  *tensor.TensorNumeric[T]
+	pairs T
+}
+
+// NewPairwiseRankingLoss creates a PairwiseRankingLoss.
+func NewPairwiseRankingLoss[T tensor.Numeric](engine compute.Engine[T]) *PairwiseRankingLoss[T] {
+	return &PairwiseRankingLoss[T]{engine: engine}
+}
+
+// Forward computes the loss of inputs[0] (scores, one per item) against
+// inputs[1] (targets, one per item). A batch without an ordered pair has
+// zero loss.
+func (p *PairwiseRankingLoss[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("PairwiseRankingLoss expects 2 inputs, got %d", len(inputs))
+	}
+	scores, targets := inputs[0], inputs[1]
+	n := scores.Size()
+	if targets.Size() != n {
+		return nil, fmt.Errorf("PairwiseRankingLoss: %d scores but %d targets", n, targets.Size())
+	}
+	p.shape = scores.Shape()
+
+	diff, err := p.pairwise(ctx, scores, n)
+	if err != nil {
+		return nil, err
+	}
+	targetDiff, err := p.pairwise(ctx, targets, n)
+	if err != nil {
+		return nil, err
+	}
+	ops := p.engine.Ops()
+	var zero T
+	one := ops.One()
+	mask, err := p.engine.UnaryOp(ctx, targetDiff, func(d T) T {
+		if ops.GreaterThan(d, zero) {
+			return one
+		}
+		return zero
+	})
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := p.engine.Sum(ctx, mask, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	p.diff, p.mask, p.pairs = diff, mask, pairs.Data()[0]
+	if ops.IsZero(p.pairs) {
+		return tensor.New[T]([]int{1}, nil)
+	}
+
+	// softplus(-d) = log(1 + exp(-d)), computed stably.
+	perPair, err := p.engine.UnaryOp(ctx, diff, func(d T) T {
+		s := ops.Log(ops.Add(one, ops.Exp(ops.Sub(zero, ops.Abs(d)))))
+		if ops.GreaterThan(zero, d) {
+			return ops.Sub(s, d)
+		}
+		return s
+	})
+	if err != nil {
+		return nil, err
+	}
+	masked, err := p.engine.Mul(ctx, perPair, mask, nil)
+	if err != nil {
+		return nil, err
+	}
+	total, err := p.engine.Sum(ctx, masked, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	mean, err := p.engine.MulScalar(ctx, total, ops.Div(one, p.pairs))
+	if err != nil {
+		return nil, err
+	}
+	return p.engine.Reshape(ctx, mean, []int{1})
+}
+
+// pairwise returns the n×n matrix of x_i - x_j.
+func (p *PairwiseRankingLoss[T]) pairwise(ctx context.Context, x *tensor.TensorNumeric[T], n int) (*tensor.TensorNumeric[T], error) {
+	col, err := p.engine.Reshape(ctx, x, []int{n, 1})
+	if err != nil {
+		return nil, err
+	}
+	row, err := p.engine.Reshape(ctx, x, []int{1, n})
+	if err != nil {
+		return nil, err
+	}
+	return p.engine.Sub(ctx, col, row, nil)
+}
+
+// Backward returns the gradient for the scores and nil for the targets.
+// With G_ij = -mask_ij * sigmoid(-(s_i - s_j)) / pairs, the gradient of
+// s_i is the sum of row i of G minus the sum of column i.
+func (p *PairwiseRankingLoss[T]) Backward(ctx context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if p.diff == nil {
+		return nil, fmt.Errorf("PairwiseRankingLoss: Backward called before Forward")
+	}
+	ops := p.engine.Ops()
+	if ops.IsZero(p.pairs) {
+		grad, err := tensor.New[T](p.shape, nil)
+		return []*tensor.TensorNumeric[T]{grad, nil}, err
+	}
+	var zero T
+	scale := ops.Div(ops.FromFloat64(-1), p.pairs)
+	g, err := p.engine.UnaryOp(ctx, p.diff, func(d T) T {
+		return ops.Mul(scale, ops.Sigmoid(ops.Sub(zero, d)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if g, err = p.engine.Mul(ctx, g, p.mask, nil); err != nil {
+		return nil, err
+	}
+	rows, err := p.engine.Sum(ctx, g, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := p.engine.Sum(ctx, g, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	grad, err := p.engine.Sub(ctx, rows, cols, nil)
+	if err != nil {
+		return nil, err
+	}
+	if grad, err = p.engine.Reshape(ctx, grad, p.shape); err != nil {
+		return nil, err
+	}
+	if dOut != nil {
+		if grad, err = p.engine.Mul(ctx, grad, dOut, nil); err != nil {
+			return nil, err
+		}
+	}
+	return []*tensor.TensorNumeric[T]{grad, nil}, nil
+}
+
+// OutputShape returns the output shape of the loss, a single value.
+func (p *PairwiseRankingLoss[T]) OutputShape() []int {
+	return []int{1}
+}
+
+// OpType returns the operation type of the loss.
+func (p *PairwiseRankingLoss[T]) OpType() string {
+	return "PairwiseRankingLoss"
+}
+
+// Attributes returns nil; the loss has no attributes.
+func (p *PairwiseRankingLoss[T]) Attributes() map[string]interface{} {
+	return nil
+}
+
+// Parameters returns nil; the loss has no trainable parameters.
+func (p *PairwiseRankingLoss[T]) Parameters() []*graph.Parameter[T] {
+	return nil
+}
+
+var _ graph.Node[float32] = (*PairwiseRankingLoss[float32])(nil)
@@ -0,0 +1,55 @@
+package loss
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestPairwiseRankingLoss(t *testing.T) {
+	ctx := context.Background()
+	p := NewPairwiseRankingLoss(newFloat64Engine())
+	scores, _ := tensor.New([]int{4, 1}, []float64{0.3, 1.2, -0.5, 0.9})
+	// Items 1 and 3 tie, so five of the six pairs are ordered.
+	targets, _ := tensor.New([]int{4, 1}, []float64{1, 3, 0, 3})
+	out, err := p.Forward(ctx, scores, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, y := scores.Data(), targets.Data()
+	var want float64
+	var pairs int
+	for i := range s {
+		for j := range s {
+			if y[i] > y[j] {
+				want += math.Log1p(math.Exp(-(s[i] - s[j])))
+				pairs++
+			}
+		}
+	}
+	want /= float64(pairs)
+	if got := out.Data()[0]; math.Abs(got-want) > 1e-12 {
+		t.Errorf("loss = %v, want %v over %d pairs", got, want, pairs)
+	}
+	checkLossGradient(t, p, scores, targets)
+
+	// Ranking the items correctly by a wide margin drives the loss to zero.
+	scores, _ = tensor.New([]int{4, 1}, []float64{10, 30, 0, 30})
+	if out, err = p.Forward(ctx, scores, targets); err != nil || out.Data()[0] > 1e-4 {
+		t.Errorf("loss for a correct ranking = %v, %v", out.Data(), err)
+	}
+
+	// Without ordered pairs there is nothing to learn.
+	ties, _ := tensor.New([]int{4, 1}, []float64{2, 2, 2, 2})
+	if out, err = p.Forward(ctx, scores, ties); err != nil || out.Data()[0] != 0 {
+		t.Errorf("loss with tied targets = %v, %v; want 0", out.Data(), err)
+	}
+	checkLossGradient(t, p, scores, ties)
+
+	short, _ := tensor.New([]int{2, 1}, []float64{1, 2})
+	if _, err := p.Forward(ctx, scores, short); err == nil {
+		t.Error("mismatched scores and targets should fail")
+	}
+}
@@ -0,0 +1,114 @@
+package loss
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Factory builds a loss node for a model running on engine. The node takes
+// the model output and the batch targets as its two inputs and returns a
+// one-element loss; its Backward returns the gradient for the output
+// first.
+type Factory[T tensor.Numeric] func(engine compute.Engine[T]) graph.Node[T]
+
+// builtinNames lists the losses Get knows without registration.
+var builtinNames = []string{
+	"bce",
+	"bce_with_logits",
+	"cross_entropy",
+	"huber",
+	"mse",
+	"pairwise_ranking",
+}
+
+// builtin returns the factory for a built-in loss, or nil.
+func builtin[T tensor.Numeric](name string) Factory[T] {
+	switch name {
+	case "mse":
+		return func(e compute.Engine[T]) graph.Node[T] { return NewMSE(e, e.Ops()) }
+	case "cross_entropy":
+		return func(e compute.Engine[T]) graph.Node[T] { return NewCrossEntropyLoss(e) }
+	case "bce":
+		return func(e compute.Engine[T]) graph.Node[T] { return NewBCELoss(e, e.Ops()) }
+	case "bce_with_logits":
+		return func(e compute.Engine[T]) graph.Node[T] { return NewBCEWithLogitsLoss(e) }
+	case "huber":
+		return func(e compute.Engine[T]) graph.Node[T] {
+			h, _ := NewHuberLoss(e, 1) // delta 1 is valid
+			return h
+		}
+	case "pairwise_ranking":
+		return func(e compute.Engine[T]) graph.Node[T] { return NewPairwiseRankingLoss(e) }
+	}
+	return nil
+}
+
+var (
+	mu sync.RWMutex
+	// registry maps a loss name to its factories by element type name, so
+	// the same name can be registered for float32 and float64.
+	registry = make(map[string]map[string]interface{})
+)
+
+// typeName returns the name of the element type T.
+func typeName[T tensor.Numeric]() string {
+	var zero T
+	return fmt.Sprintf("%T", zero)
+}
+
+// Register adds a loss under name for element type T, typically from an
+// init function. Names of built-in losses and names already registered for
+// T are rejected; parameterized variants of the built-ins go under their
+// own names.
+func Register[T tensor.Numeric](name string, f Factory[T]) error {
+	if name == "" || f == nil {
+		return fmt.Errorf("loss: Register needs a name and a factory")
+	}
+	if slices.Contains(builtinNames, name) {
+		return fmt.Errorf("loss: %q is a built-in loss", name)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	byType := registry[name]
+	if byType == nil {
+		byType = make(map[string]interface{})
+		registry[name] = byType
+	}
+	if _, ok := byType[typeName[T]()]; ok {
+		return fmt.Errorf("loss: %q already registered for %s", name, typeName[T]())
+	}
+	byType[typeName[T]()] = f
+	return nil
+}
+
+// Get returns the factory for the named loss and element type T: a
+// built-in or one added with Register.
+func Get[T tensor.Numeric](name string) (Factory[T], error) {
+	if f := builtin[T](name); f != nil {
+		return f, nil
+	}
+	mu.RLock()
+	f, ok := registry[name][typeName[T]()]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("loss: unknown loss %q for %s (have %v)", name, typeName[T](), Names())
+	}
+	return f.(Factory[T]), nil
+}
+
+// Names returns the names of the built-in and registered losses, sorted.
+func Names() []string {
+	names := slices.Clone(builtinNames)
+	mu.RLock()
+	for name := range registry {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,69 @@
+package loss
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	preds, _ := tensor.New([]int{2, 2}, []float32{0.2, 0.8, 0.6, 0.4})
+	targets, _ := tensor.New([]int{2, 2}, []float32{0, 1, 1, 0})
+	labels, _ := tensor.New([]int{2}, []float32{1, 0})
+	for _, name := range builtinNames {
+		f, err := Get[float32](name)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", name, err)
+		}
+		y := targets
+		if name == "cross_entropy" {
+			y = labels
+		}
+		out, err := f(engine).Forward(ctx, preds, y)
+		if err != nil {
+			t.Errorf("%s: Forward: %v", name, err)
+			continue
+		}
+		if out.Size() != 1 {
+			t.Errorf("%s: loss has %d elements, want 1", name, out.Size())
+		}
+	}
+	if _, err := Get[float64]("huber"); err != nil {
+		t.Errorf("built-ins should exist for float64: %v", err)
+	}
+
+	smoothed := func(e compute.Engine[float32]) graph.Node[float32] {
+		return NewCrossEntropyLoss(e, WithLabelSmoothing[float32](0.1))
+	}
+	if err := Register("test_smoothed_ce", smoothed); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("test_smoothed_ce", smoothed); err == nil {
+		t.Error("duplicate registration accepted")
+	}
+	if err := Register("mse", smoothed); err == nil {
+		t.Error("registration over a built-in accepted")
+	}
+	if err := Register[float32]("", nil); err == nil {
+		t.Error("empty registration accepted")
+	}
+	if _, err := Get[float32]("test_smoothed_ce"); err != nil {
+		t.Error(err)
+	}
+	if _, err := Get[float64]("test_smoothed_ce"); err == nil {
+		t.Error("a float32 registration should not serve float64")
+	}
+	if _, err := Get[float32]("nope"); err == nil {
+		t.Error("unknown loss accepted")
+	}
+	if names := Names(); !slices.Contains(names, "test_smoothed_ce") || !slices.Contains(names, "pairwise_ranking") || !slices.IsSorted(names) {
+		t.Errorf("Names() = %v", names)
+	}
+}
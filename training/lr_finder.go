@@ -0,0 +1,250 @@
+package training
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/float8"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// LRFinderConfig configures an LR range test.
+type LRFinderConfig struct {
+	// MinLR and MaxLR bound the sweep. Defaults: 1e-7 and 10.
+	MinLR, MaxLR float64
+	// Steps is the number of training steps in the sweep. Default: 200.
+	Steps int
+	// Smoothing is the exponential moving average factor applied to the
+	// loss before analysis. Default: 0.98.
+	Smoothing float64
+	// DivergeThreshold stops the sweep once the smoothed loss exceeds this
+	// multiple of the best smoothed loss seen. Default: 4.
+	DivergeThreshold float64
+}
+
+func (c *LRFinderConfig) withDefaults() (LRFinderConfig, error) {
+	out := *c
+	if out.MinLR == 0 {
+		out.MinLR = 1e-7
+	}
+	if out.MaxLR == 0 {
+		out.MaxLR = 10
+	}
+	if out.Steps == 0 {
+		out.Steps = 200
+	}
+	if out.Smoothing == 0 {
+		out.Smoothing = 0.98
+	}
+	if out.DivergeThreshold == 0 {
+		out.DivergeThreshold = 4
+	}
+	switch {
+	case out.MinLR <= 0 || out.MaxLR <= out.MinLR:
+		return out, fmt.Errorf("lr finder: need 0 < MinLR < MaxLR, got %g and %g", out.MinLR, out.MaxLR)
+	case out.Steps < 2:
+		return out, fmt.Errorf("lr finder: need at least 2 steps, got %d", out.Steps)
+	case out.Smoothing < 0 || out.Smoothing >= 1:
+		return out, fmt.Errorf("lr finder: smoothing must be in [0, 1), got %g", out.Smoothing)
+	case out.DivergeThreshold <= 1:
+		return out, fmt.Errorf("lr finder: diverge threshold must be > 1, got %g", out.DivergeThreshold)
+	}
+	return out, nil
+}
+
+// LRFinderPoint is one step of an LR range test.
+type LRFinderPoint struct {
+	Step         int
+	LR           float64
+	Loss         float64
+	SmoothedLoss float64
+}
+
+// LRFinderResult holds the recorded sweep and the suggested learning rates.
+type LRFinderResult struct {
+	Points []LRFinderPoint
+	// SuggestedLR is the learning rate where the smoothed loss falls
+	// fastest with respect to log(LR), a common choice for the maximum LR
+	// of a one-cycle or warmup schedule.
+	SuggestedLR float64
+	// MinLossLR is the learning rate at the lowest smoothed loss. Training
+	// at this rate is usually already unstable; MinLossLR/10 is a
+	// conservative alternative to SuggestedLR.
+	MinLossLR float64
+	// Diverged reports whether the sweep stopped early because the loss
+	// blew up.
+	Diverged bool
+}
+
+// WriteCSV writes the sweep as CSV with the header
+// "step,lr,loss,smoothed_loss", ready for plotting LR on a log axis.
+func (r *LRFinderResult) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"step", "lr", "loss", "smoothed_loss"}); err != nil {
+		return err
+	}
+	for _, p := range r.Points {
+		rec := []string{
+			strconv.Itoa(p.Step),
+			strconv.FormatFloat(p.LR, 'g', 6, 64),
+			strconv.FormatFloat(p.Loss, 'g', 8, 64),
+			strconv.FormatFloat(p.SmoothedLoss, 'g', 8, 64),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RunLRRangeTest sweeps the learning rate exponentially from MinLR to MaxLR
+// over cfg.Steps steps (Smith, 2017). Before step i it calls setLR with the
+// step's rate, then step to train on one batch and report its loss. The
+// sweep stops early if the loss becomes non-finite or diverges.
+//
+// RunLRRangeTest does not restore the model; see LRRangeTest for a version
+// that does.
+func RunLRRangeTest(ctx context.Context, cfg LRFinderConfig, setLR func(lr float64), step func(ctx context.Context, i int) (float64, error)) (*LRFinderResult, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	ratio := math.Pow(cfg.MaxLR/cfg.MinLR, 1/float64(cfg.Steps-1))
+
+	res := &LRFinderResult{}
+	var avg float64
+	best := math.Inf(1)
+	for i := range cfg.Steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lr := cfg.MinLR * math.Pow(ratio, float64(i))
+		setLR(lr)
+		loss, err := step(ctx, i)
+		if err != nil {
+			return nil, fmt.Errorf("lr finder: step %d (lr %g): %w", i, lr, err)
+		}
+		if math.IsNaN(loss) || math.IsInf(loss, 0) {
+			res.Diverged = true
+			break
+		}
+		// Bias-corrected EMA, so early points are not pulled toward 0.
+		avg = cfg.Smoothing*avg + (1-cfg.Smoothing)*loss
+		smoothed := avg / (1 - math.Pow(cfg.Smoothing, float64(i+1)))
+		res.Points = append(res.Points, LRFinderPoint{Step: i, LR: lr, Loss: loss, SmoothedLoss: smoothed})
+		if smoothed < best {
+			best = smoothed
+		}
+		if smoothed > cfg.DivergeThreshold*best {
+			res.Diverged = true
+			break
+		}
+	}
+	if len(res.Points) == 0 {
+		return nil, errors.New("lr finder: loss was non-finite at the first step")
+	}
+	res.suggest()
+	return res, nil
+}
+
+// suggest fills SuggestedLR and MinLossLR from the recorded points.
+func (r *LRFinderResult) suggest() {
+	minIdx := 0
+	for i, p := range r.Points {
+		if p.SmoothedLoss < r.Points[minIdx].SmoothedLoss {
+			minIdx = i
+		}
+	}
+	r.MinLossLR = r.Points[minIdx].LR
+	r.SuggestedLR = r.MinLossLR / 10
+
+	// Steepest descent of the smoothed loss in log(LR), searched only up to
+	// the minimum: past it the curve is dominated by divergence.
+	steepest := 0.0
+	for i := 1; i < minIdx; i++ {
+		prev, next := r.Points[i-1], r.Points[i+1]
+		slope := (next.SmoothedLoss - prev.SmoothedLoss) / (math.Log(next.LR) - math.Log(prev.LR))
+		if slope < steepest {
+			steepest = slope
+			r.SuggestedLR = r.Points[i].LR
+		}
+	}
+}
+
+// lrSetter is implemented by optimizers whose learning rate can be set in
+// float64, such as AdamW and GroupedOptimizer.
+type lrSetter interface {
+	SetLRFloat64(lr float64)
+}
+
+// LRRangeTest runs an LR range test with trainer, calling next for the batch
+// of each step. Parameter values are restored and gradients cleared when it
+// returns, so the model can be trained from the same starting point.
+// The optimizer's internal state (e.g. AdamW moments) is not restored; use a
+// fresh optimizer for the real run.
+func LRRangeTest[T tensor.Numeric](
+	ctx context.Context,
+	cfg LRFinderConfig,
+	trainer Trainer[T],
+	g *graph.Graph[T],
+	opt optimizer.Optimizer[T],
+	next func(i int) (*Batch[T], error),
+) (*LRFinderResult, error) {
+	setter, ok := opt.(lrSetter)
+	if !ok {
+		return nil, fmt.Errorf("lr finder: optimizer %T does not support SetLRFloat64", opt)
+	}
+
+	params := uniqueParameters(g)
+	saved := make([][]T, len(params))
+	for i, p := range params {
+		saved[i] = append([]T(nil), p.Value.Data()...)
+	}
+	defer func() {
+		for i, p := range params {
+			p.Value.SetData(saved[i])
+			if p.Gradient != nil {
+				p.ClearGradient()
+			}
+		}
+	}()
+
+	return RunLRRangeTest(ctx, cfg, setter.SetLRFloat64, func(ctx context.Context, i int) (float64, error) {
+		b, err := next(i)
+		if err != nil {
+			return 0, err
+		}
+		loss, err := trainer.TrainStep(ctx, g, opt, b.Inputs, b.Targets)
+		if err != nil {
+			return 0, err
+		}
+		return numericToFloat64(loss), nil
+	})
+}
+
+// numericToFloat64 converts a tensor.Numeric value to float64.
+func numericToFloat64[T tensor.Numeric](v T) float64 {
+	switch val := any(v).(type) {
+	case float32:
+		return float64(val)
+	case float64:
+		return val
+	case float16.Float16:
+		return float64(val.ToFloat32())
+	case float16.BFloat16:
+		return float64(val.ToFloat32())
+	case float8.Float8:
+		return float64(val.ToFloat32())
+	default:
+		return math.NaN()
+	}
+}
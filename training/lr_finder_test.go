@@ -0,0 +1,141 @@
+package training_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+func TestRunLRRangeTest_SyntheticCurve(t *testing.T) {
+	// Loss as a function of the current LR: flat for tiny rates, falling
+	// fastest around 1e-3, lowest near 1e-2, and exploding beyond.
+	curve := func(lr float64) float64 {
+		x := math.Log10(lr)
+		if x > -2 {
+			return 0.1 * math.Pow(10, 3*(x+2))
+		}
+		return 0.1 + 1/(1+math.Exp(4*(x+3)))
+	}
+	var lr float64
+	res, err := training.RunLRRangeTest(context.Background(),
+		training.LRFinderConfig{MinLR: 1e-6, MaxLR: 1, Steps: 120, Smoothing: 0.5},
+		func(v float64) { lr = v },
+		func(context.Context, int) (float64, error) { return curve(lr), nil },
+	)
+	if err != nil {
+		t.Fatalf("RunLRRangeTest: %v", err)
+	}
+	if !res.Diverged {
+		t.Error("expected the sweep to stop on divergence")
+	}
+	if n := len(res.Points); n == 0 || n >= 120 {
+		t.Errorf("recorded %d points, want an early stop", n)
+	}
+	if res.MinLossLR < 3e-3 || res.MinLossLR > 3e-2 {
+		t.Errorf("MinLossLR = %g, want near 1e-2", res.MinLossLR)
+	}
+	if res.SuggestedLR < 2e-4 || res.SuggestedLR > 5e-3 {
+		t.Errorf("SuggestedLR = %g, want near 1e-3", res.SuggestedLR)
+	}
+	for i := 1; i < len(res.Points); i++ {
+		if res.Points[i].LR <= res.Points[i-1].LR {
+			t.Fatalf("LR not increasing at point %d", i)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := res.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "step,lr,loss,smoothed_loss" || len(lines) != len(res.Points)+1 {
+		t.Errorf("CSV header %q with %d lines, want %d", lines[0], len(lines), len(res.Points)+1)
+	}
+}
+
+func TestRunLRRangeTest_InvalidConfig(t *testing.T) {
+	step := func(context.Context, int) (float64, error) { return 1, nil }
+	for _, cfg := range []training.LRFinderConfig{
+		{MinLR: 1, MaxLR: 0.1},
+		{Steps: 1},
+		{Smoothing: 1},
+		{DivergeThreshold: 0.5},
+	} {
+		if _, err := training.RunLRRangeTest(context.Background(), cfg, func(float64) {}, step); err == nil {
+			t.Errorf("config %+v: expected error", cfg)
+		}
+	}
+}
+
+func TestLRRangeTest_RestoresParameters(t *testing.T) {
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, 2})
+	dense, err := core.NewDense[float32]("d", engine, ops, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before []float32
+	for _, p := range g.Parameters() {
+		before = append(before, p.Value.Data()...)
+	}
+
+	x, _ := tensor.New[float32]([]int{4, 2}, []float32{0, 1, 1, 0, 1, 1, -1, 0.5})
+	y, _ := tensor.New[float32]([]int{4, 1}, []float32{1, -1, 0, -1.5})
+	batch := &training.Batch[float32]{
+		Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: x},
+		Targets: y,
+	}
+	opt := optimizer.NewAdamW[float32](engine, 1e-3, 0.9, 0.999, 1e-8, 0)
+	trainer := training.NewDefaultTrainer[float32](g, loss.NewMSE[float32](engine, ops), opt, nil)
+
+	res, err := training.LRRangeTest(context.Background(),
+		training.LRFinderConfig{MinLR: 1e-5, MaxLR: 1, Steps: 50},
+		trainer, g, opt,
+		func(int) (*training.Batch[float32], error) { return batch, nil },
+	)
+	if err != nil {
+		t.Fatalf("LRRangeTest: %v", err)
+	}
+	if len(res.Points) == 0 || res.SuggestedLR <= 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if first, last := res.Points[0].Loss, res.Points[len(res.Points)-1].Loss; last >= first && !res.Diverged {
+		t.Errorf("loss did not move during the sweep: %v -> %v", first, last)
+	}
+
+	var after []float32
+	for _, p := range g.Parameters() {
+		after = append(after, p.Value.Data()...)
+	}
+	for i := range before {
+		if before[i] != after[i] {
+			t.Fatalf("parameter %d = %v after sweep, want restored %v", i, after[i], before[i])
+		}
+	}
+}
+
+func TestLRRangeTest_RequiresLRSetter(t *testing.T) {
+	_, err := training.LRRangeTest[float32](context.Background(), training.LRFinderConfig{}, nil, nil,
+		&mockOptimizer[float32]{}, nil)
+	if err == nil {
+		t.Error("expected error for optimizer without SetLRFloat64")
+	}
+}
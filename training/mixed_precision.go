@@ -0,0 +1,264 @@
+package training
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	opt "github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// MixedPrecisionConfig configures mixed-precision training: the model runs
+// forward and backward in a reduced-precision T such as float16, while the
+// optimizer updates float32 master copies of the parameters. A dynamic loss
+// scale keeps small gradients from underflowing in T.
+type MixedPrecisionConfig struct {
+	// InitialScale is the starting loss scale. Default 2^15.
+	InitialScale float64
+	// MaxScale caps the loss scale. It must be representable in T; the
+	// default 2^15 is the largest power of two below the float16 maximum.
+	MaxScale float64
+	// GrowthInterval is the number of consecutive steps without overflow
+	// after which the scale grows. Default 2000.
+	GrowthInterval int
+	// GrowthFactor multiplies the scale after GrowthInterval clean steps.
+	// Default 2.
+	GrowthFactor float64
+	// BackoffFactor multiplies the scale after an overflow. Default 0.5.
+	BackoffFactor float64
+	// MasterEngine runs the float32 master-weight arithmetic. Default a CPU
+	// engine.
+	MasterEngine compute.Engine[float32]
+}
+
+func (c MixedPrecisionConfig) withDefaults() (MixedPrecisionConfig, error) {
+	if c.InitialScale == 0 {
+		c.InitialScale = 1 << 15
+	}
+	if c.MaxScale == 0 {
+		c.MaxScale = 1 << 15
+	}
+	if c.GrowthInterval == 0 {
+		c.GrowthInterval = 2000
+	}
+	if c.GrowthFactor == 0 {
+		c.GrowthFactor = 2
+	}
+	if c.BackoffFactor == 0 {
+		c.BackoffFactor = 0.5
+	}
+	if c.MasterEngine == nil {
+		c.MasterEngine = compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	}
+	switch {
+	case c.InitialScale < 1 || c.MaxScale < c.InitialScale:
+		return c, fmt.Errorf("training: mixed precision needs 1 <= InitialScale <= MaxScale, got %g and %g", c.InitialScale, c.MaxScale)
+	case c.GrowthInterval < 1:
+		return c, fmt.Errorf("training: mixed precision GrowthInterval must be positive, got %d", c.GrowthInterval)
+	case c.GrowthFactor <= 1:
+		return c, fmt.Errorf("training: mixed precision GrowthFactor must exceed 1, got %g", c.GrowthFactor)
+	case c.BackoffFactor <= 0 || c.BackoffFactor >= 1:
+		return c, fmt.Errorf("training: mixed precision BackoffFactor must be in (0, 1), got %g", c.BackoffFactor)
+	}
+	return c, nil
+}
+
+// MixedPrecision is an optimizer for a model in reduced precision T. Its
+// Step converts the (loss-scaled) gradients to float32, unscales them, and
+// steps the master optimizer on float32 copies of the parameters, then
+// writes the updated weights back to the model in T. If any gradient
+// overflowed, the step is skipped and the loss scale backs off.
+//
+// The loss must be wrapped with Loss so its gradient carries the scale:
+//
+//	mp, err := training.NewMixedPrecision[float16.Float16](engine, optimizer.NewSGD(engine32, ops32, 0.01), training.MixedPrecisionConfig{})
+//	trainer := training.NewDefaultTrainer(g, mp.Loss(lossNode), mp, nil)
+type MixedPrecision[T tensor.Numeric] struct {
+	cfg    MixedPrecisionConfig
+	engine compute.Engine[T]
+	master opt.Optimizer[float32]
+
+	masters  map[*graph.Parameter[T]]*graph.Parameter[float32]
+	scale    float64
+	clean    int
+	skipped  int
+	zeroGrad bool
+}
+
+// NewMixedPrecision creates a MixedPrecision optimizer for a model on
+// engine, which must run a floating-point T. master updates the float32
+// master weights.
+func NewMixedPrecision[T tensor.Numeric](engine compute.Engine[T], master opt.Optimizer[float32], cfg MixedPrecisionConfig) (*MixedPrecision[T], error) {
+	if engine == nil || master == nil {
+		return nil, fmt.Errorf("training: mixed precision needs an engine and a master optimizer")
+	}
+	if math.IsNaN(numericToFloat64(engine.Ops().One())) {
+		var zero T
+		return nil, fmt.Errorf("training: mixed precision needs a floating-point type, got %T", zero)
+	}
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &MixedPrecision[T]{
+		cfg:      cfg,
+		engine:   engine,
+		master:   master,
+		masters:  make(map[*graph.Parameter[T]]*graph.Parameter[float32]),
+		scale:    cfg.InitialScale,
+		zeroGrad: true,
+	}, nil
+}
+
+// Scale returns the current loss scale.
+func (m *MixedPrecision[T]) Scale() float64 { return m.scale }
+
+// SkippedSteps returns the number of steps skipped because of overflow.
+func (m *MixedPrecision[T]) SkippedSteps() int { return m.skipped }
+
+// Master returns the float32 master copy of p, or nil before p's first
+// step.
+func (m *MixedPrecision[T]) Master(p *graph.Parameter[T]) *graph.Parameter[float32] {
+	return m.masters[p]
+}
+
+// SetLRFloat64 sets the master optimizer's learning rate when it supports
+// that, so learning rate schedulers work through MixedPrecision.
+func (m *MixedPrecision[T]) SetLRFloat64(lr float64) {
+	if s, ok := m.master.(opt.LRSetter); ok {
+		s.SetLRFloat64(lr)
+	}
+}
+
+// SetZeroGradOnStep implements optimizer.GradientZeroer. Gradients that
+// overflowed are always zeroed.
+func (m *MixedPrecision[T]) SetZeroGradOnStep(zero bool) { m.zeroGrad = zero }
+
+// Step implements optimizer.Optimizer.
+func (m *MixedPrecision[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
+	var stepped []*graph.Parameter[T]
+	var masters []*graph.Parameter[float32]
+	seen := make(map[*graph.Parameter[T]]bool, len(params))
+	for _, p := range params {
+		if p.Gradient == nil || seen[p] {
+			continue
+		}
+		seen[p] = true
+		grad, finite, err := toFloat32(p.Gradient)
+		if err != nil {
+			return fmt.Errorf("training: mixed precision gradient of %q: %w", p.Name, err)
+		}
+		if !finite {
+			m.overflow(params)
+			return nil
+		}
+		mp, err := m.masterFor(p)
+		if err != nil {
+			return err
+		}
+		if mp.Gradient, err = m.cfg.MasterEngine.MulScalar(ctx, grad, float32(1/m.scale)); err != nil {
+			return fmt.Errorf("training: unscale gradient of %q: %w", p.Name, err)
+		}
+		stepped = append(stepped, p)
+		masters = append(masters, mp)
+	}
+
+	if err := m.master.Step(ctx, masters); err != nil {
+		return err
+	}
+	ops := m.engine.Ops()
+	for i, p := range stepped {
+		vals := make([]T, p.Value.Size())
+		for j, v := range masters[i].Value.Data() {
+			vals[j] = ops.FromFloat32(v)
+		}
+		p.Value.GetStorage().Set(vals)
+	}
+	if m.zeroGrad {
+		opt.ZeroGrad(stepped)
+	}
+
+	m.clean++
+	if m.clean >= m.cfg.GrowthInterval {
+		m.scale = min(m.scale*m.cfg.GrowthFactor, m.cfg.MaxScale)
+		m.clean = 0
+	}
+	return nil
+}
+
+// overflow skips the step: it discards the gradients and backs off the
+// loss scale.
+func (m *MixedPrecision[T]) overflow(params []*graph.Parameter[T]) {
+	opt.ZeroGrad(params)
+	m.scale = max(m.scale*m.cfg.BackoffFactor, 1)
+	m.clean = 0
+	m.skipped++
+}
+
+// masterFor returns the float32 master copy of p, creating it from p's
+// current value on first use.
+func (m *MixedPrecision[T]) masterFor(p *graph.Parameter[T]) (*graph.Parameter[float32], error) {
+	if mp, ok := m.masters[p]; ok {
+		return mp, nil
+	}
+	value, _, err := toFloat32(p.Value)
+	if err != nil {
+		return nil, fmt.Errorf("training: master copy of %q: %w", p.Name, err)
+	}
+	mp, err := graph.NewParameter[float32](p.Name, value, tensor.New[float32])
+	if err != nil {
+		return nil, fmt.Errorf("training: master copy of %q: %w", p.Name, err)
+	}
+	m.masters[p] = mp
+	return mp, nil
+}
+
+// toFloat32 converts t to a float32 tensor and reports whether every value
+// is finite.
+func toFloat32[T tensor.Numeric](t *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[float32], bool, error) {
+	out := make([]float32, t.Size())
+	finite := true
+	for i, v := range t.Data() {
+		f := numericToFloat64(v)
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			finite = false
+		}
+		out[i] = float32(f)
+	}
+	converted, err := tensor.New(t.Shape(), out)
+	return converted, finite, err
+}
+
+// Loss wraps a loss node so that its gradient is multiplied by the current
+// loss scale. The loss value it returns is unscaled.
+func (m *MixedPrecision[T]) Loss(loss graph.Node[T]) graph.Node[T] {
+	return &scaledLoss[T]{Node: loss, mp: m}
+}
+
+// scaledLoss multiplies the gradient of its loss by the loss scale.
+type scaledLoss[T tensor.Numeric] struct {
+	graph.Node[T]
+	mp *MixedPrecision[T]
+}
+
+// Backward seeds the wrapped loss's backward pass with dOut times the loss
+// scale.
+func (s *scaledLoss[T]) Backward(ctx context.Context, mode types.BackwardMode, dOut *tensor.TensorNumeric[T], inputs ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	dOut, err := s.mp.engine.MulScalar(ctx, dOut, s.mp.engine.Ops().FromFloat64(s.mp.scale))
+	if err != nil {
+		return nil, fmt.Errorf("training: scale loss gradient: %w", err)
+	}
+	return s.Node.Backward(ctx, mode, dOut, inputs...)
+}
+
+var (
+	_ opt.Optimizer[float32] = (*MixedPrecision[float32])(nil)
+	_ opt.GradientZeroer     = (*MixedPrecision[float32])(nil)
+	_ opt.LRSetter           = (*MixedPrecision[float32])(nil)
+	_ graph.Node[float32]    = (*scaledLoss[float32])(nil)
+)
@@ -0,0 +1,176 @@
+package training_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/float16"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+type f16 = float16.Float16
+
+func newF16Param(t *testing.T, name string, vals ...float32) *graph.Parameter[f16] {
+	t.Helper()
+	data := make([]f16, len(vals))
+	for i, v := range vals {
+		data[i] = float16.FromFloat32(v)
+	}
+	value, err := tensor.New([]int{len(vals)}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := graph.NewParameter(name, value, tensor.New[f16])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func setF16Grad(t *testing.T, p *graph.Parameter[f16], vals ...float32) {
+	t.Helper()
+	data := make([]f16, len(vals))
+	for i, v := range vals {
+		data[i] = float16.FromFloat32(v)
+	}
+	g, err := tensor.New(p.Value.Shape(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Gradient = g
+}
+
+func newMixedPrecision(t *testing.T, lr float32, cfg training.MixedPrecisionConfig) *training.MixedPrecision[f16] {
+	t.Helper()
+	engine := compute.NewCPUEngine[f16](numeric.Float16Ops{})
+	engine32 := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	mp, err := training.NewMixedPrecision(engine, optimizer.NewSGD(engine32, numeric.Float32Ops{}, lr), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mp
+}
+
+func TestMixedPrecision_MasterWeightsKeepSmallUpdates(t *testing.T) {
+	ctx := context.Background()
+	mp := newMixedPrecision(t, 1e-4, training.MixedPrecisionConfig{InitialScale: 1024})
+	p := newF16Param(t, "w", 1, -2)
+
+	// Each step moves w by 1e-4, below float16's spacing of ~1e-3 near 1:
+	// stepping in float16 alone would leave w unchanged.
+	for range 20 {
+		setF16Grad(t, p, 1024, -1024) // a unit gradient, scaled by 1024
+		if err := mp.Step(ctx, []*graph.Parameter[f16]{p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	master := mp.Master(p).Value.Data()
+	if math.Abs(float64(master[0])-0.998) > 1e-6 || math.Abs(float64(master[1])+1.998) > 1e-6 {
+		t.Errorf("master = %v, want [0.998 -1.998]", master)
+	}
+	if got := p.Value.Data()[0].ToFloat32(); got >= 1 || math.Abs(float64(got)-0.998) > 1e-3 {
+		t.Errorf("w[0] = %v, want about 0.998", got)
+	}
+	if g := p.Gradient.Data()[0].ToFloat32(); g != 0 {
+		t.Errorf("gradient = %v after Step, want zeroed", g)
+	}
+}
+
+func TestMixedPrecision_DynamicLossScale(t *testing.T) {
+	ctx := context.Background()
+	mp := newMixedPrecision(t, 0.1, training.MixedPrecisionConfig{
+		InitialScale:   256,
+		MaxScale:       1024,
+		GrowthInterval: 2,
+	})
+	p := newF16Param(t, "w", 1)
+	params := []*graph.Parameter[f16]{p}
+
+	setF16Grad(t, p, float32(math.Inf(1)))
+	if err := mp.Step(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if mp.Scale() != 128 || mp.SkippedSteps() != 1 {
+		t.Errorf("after overflow: scale %v, skipped %d; want 128, 1", mp.Scale(), mp.SkippedSteps())
+	}
+	if got := p.Value.Data()[0].ToFloat32(); got != 1 {
+		t.Errorf("w = %v after a skipped step, want 1", got)
+	}
+
+	for range 6 {
+		setF16Grad(t, p, 0)
+		if err := mp.Step(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Three growths from 128, capped at 1024.
+	if mp.Scale() != 1024 {
+		t.Errorf("scale = %v after clean steps, want 1024", mp.Scale())
+	}
+}
+
+func TestMixedPrecision_LossScalesGradient(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[f16](numeric.Float16Ops{})
+	mp := newMixedPrecision(t, 0.1, training.MixedPrecisionConfig{InitialScale: 8})
+	mse := loss.NewMSE(engine, numeric.Float16Ops{})
+	scaled := mp.Loss(mse)
+
+	h := float16.FromFloat32
+	preds, _ := tensor.New([]int{2}, []f16{h(1), h(3)})
+	targets, _ := tensor.New([]int{2}, []f16{h(0), h(2)})
+	seed, _ := tensor.New([]int{1}, []f16{h(1)})
+	want, err := mse.Forward(ctx, preds, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGrads, err := mse.Backward(ctx, types.FullBackprop, seed, preds, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := scaled.Forward(ctx, preds, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Data()[0] != want.Data()[0] {
+		t.Errorf("scaled loss value = %v, want the unscaled %v", got.Data()[0], want.Data()[0])
+	}
+	grads, err := scaled.Backward(ctx, types.FullBackprop, seed, preds, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range grads[0].Data() {
+		if w := 8 * wantGrads[0].Data()[i].ToFloat32(); g.ToFloat32() != w {
+			t.Errorf("grad[%d] = %v, want %v", i, g.ToFloat32(), w)
+		}
+	}
+}
+
+func TestNewMixedPrecision_Validation(t *testing.T) {
+	engine := compute.NewCPUEngine[f16](numeric.Float16Ops{})
+	engine32 := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	sgd := optimizer.NewSGD(engine32, numeric.Float32Ops{}, 0.1)
+	for name, cfg := range map[string]training.MixedPrecisionConfig{
+		"scale above max": {InitialScale: 4096, MaxScale: 1024},
+		"scale below one": {InitialScale: 0.5},
+		"growth factor":   {GrowthFactor: 1},
+		"backoff factor":  {BackoffFactor: 1.5},
+		"growth interval": {GrowthInterval: -1},
+	} {
+		if _, err := training.NewMixedPrecision(engine, sgd, cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	intEngine := compute.NewCPUEngine[int](numeric.IntOps{})
+	if _, err := training.NewMixedPrecision(intEngine, sgd, training.MixedPrecisionConfig{}); err == nil {
+		t.Error("expected an error for an integer type")
+	}
+}
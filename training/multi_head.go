@@ -0,0 +1,244 @@
+package training
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+// Head is one named output of a multi-head model with its own loss and
+// metrics. The model output concatenates the heads along its last axis in
+// order, and so do the batch targets.
+type Head[T tensor.Numeric] struct {
+	Name string
+	// Width is the head's share of the output's last axis.
+	Width int
+	// TargetWidth is the head's share of the targets' last axis. Zero
+	// means Width; a classification head with class-index targets has
+	// TargetWidth 1.
+	TargetWidth int
+	// Weight scales the head's loss in the total. Zero means 1.
+	Weight float64
+	// Loss builds the head's loss node.
+	Loss LossFactory[T]
+	// Metrics, if not nil, computes metrics on the head's output.
+	Metrics MetricComputer[T]
+}
+
+func (h Head[T]) targetWidth() int {
+	if h.TargetWidth == 0 {
+		return h.Width
+	}
+	return h.TargetWidth
+}
+
+func (h Head[T]) weight() float64 {
+	if h.Weight == 0 {
+		return 1
+	}
+	return h.Weight
+}
+
+// validateHeads checks that heads have distinct names, positive widths,
+// non-negative weights and a loss.
+func validateHeads[T tensor.Numeric](heads []Head[T]) error {
+	if len(heads) == 0 {
+		return fmt.Errorf("training: no output heads")
+	}
+	seen := make(map[string]bool, len(heads))
+	for i, h := range heads {
+		switch {
+		case h.Name == "":
+			return fmt.Errorf("training: head %d has no name", i)
+		case seen[h.Name]:
+			return fmt.Errorf("training: duplicate head %q", h.Name)
+		case h.Width <= 0 || h.TargetWidth < 0:
+			return fmt.Errorf("training: head %q needs a positive width, got %d and target width %d", h.Name, h.Width, h.TargetWidth)
+		case h.Weight < 0:
+			return fmt.Errorf("training: head %q has negative weight %g", h.Name, h.Weight)
+		case h.Loss == nil:
+			return fmt.Errorf("training: head %q has no loss", h.Name)
+		}
+		seen[h.Name] = true
+	}
+	return nil
+}
+
+// MultiHeadLoss is the loss of a multi-head model: the weighted sum of
+// each head's loss on its slice of the output and targets.
+type MultiHeadLoss[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+	heads  []Head[T]
+	losses []graph.Node[T]
+
+	// Cached by Forward: the per-head outputs and targets for the backward
+	// pass, and the unweighted per-head losses.
+	outputs []*tensor.TensorNumeric[T]
+	targets []*tensor.TensorNumeric[T]
+	last    map[string]float64
+}
+
+// NewMultiHeadLoss creates a MultiHeadLoss for a model on engine.
+func NewMultiHeadLoss[T tensor.Numeric](engine compute.Engine[T], heads ...Head[T]) (*MultiHeadLoss[T], error) {
+	if err := validateHeads(heads); err != nil {
+		return nil, err
+	}
+	losses := make([]graph.Node[T], len(heads))
+	for i, h := range heads {
+		losses[i] = h.Loss(engine)
+	}
+	return &MultiHeadLoss[T]{
+		engine: engine,
+		heads:  heads,
+		losses: losses,
+		last:   make(map[string]float64, len(heads)),
+	}, nil
+}
+
+// OutputSpecs returns the heads as model output specs, for
+// StandardModelInstance.SetOutputSpec and model.SelectOutput.
+func (m *MultiHeadLoss[T]) OutputSpecs() []model.OutputSpec {
+	specs := make([]model.OutputSpec, len(m.heads))
+	for i, h := range m.heads {
+		specs[i] = model.OutputSpec{Name: h.Name, Width: h.Width}
+	}
+	return specs
+}
+
+// HeadLosses returns each head's unweighted loss from the latest Forward.
+func (m *MultiHeadLoss[T]) HeadLosses() map[string]float64 {
+	out := make(map[string]float64, len(m.last))
+	for k, v := range m.last {
+		out[k] = v
+	}
+	return out
+}
+
+// split splits the output and targets into the heads' slices.
+func (m *MultiHeadLoss[T]) split(output, targets *tensor.TensorNumeric[T]) (outs, tgts []*tensor.TensorNumeric[T], err error) {
+	widths := make([]int, len(m.heads))
+	targetWidths := make([]int, len(m.heads))
+	for i, h := range m.heads {
+		widths[i], targetWidths[i] = h.Width, h.targetWidth()
+	}
+	if outs, err = model.SplitLastAxis(output, widths); err != nil {
+		return nil, nil, fmt.Errorf("MultiHeadLoss: output: %w", err)
+	}
+	if tgts, err = model.SplitLastAxis(targets, targetWidths); err != nil {
+		return nil, nil, fmt.Errorf("MultiHeadLoss: targets: %w", err)
+	}
+	return outs, tgts, nil
+}
+
+// Forward computes the weighted sum of the head losses of inputs[0] (the
+// model output) against inputs[1] (the targets).
+func (m *MultiHeadLoss[T]) Forward(ctx context.Context, inputs ...*tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("MultiHeadLoss expects 2 inputs, got %d", len(inputs))
+	}
+	outs, tgts, err := m.split(inputs[0], inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	m.outputs, m.targets = outs, tgts
+
+	ops := m.engine.Ops()
+	var total *tensor.TensorNumeric[T]
+	for i, h := range m.heads {
+		l, err := m.losses[i].Forward(ctx, outs[i], tgts[i])
+		if err != nil {
+			return nil, fmt.Errorf("MultiHeadLoss: head %q: %w", h.Name, err)
+		}
+		m.last[h.Name] = numericToFloat64(l.Data()[0])
+		weighted, err := m.engine.MulScalar(ctx, l, ops.FromFloat64(h.weight()))
+		if err != nil {
+			return nil, err
+		}
+		if total == nil {
+			total = weighted
+		} else if total, err = m.engine.Add(ctx, total, weighted); err != nil {
+			return nil, err
+		}
+	}
+	return m.engine.Reshape(ctx, total, []int{1})
+}
+
+// Backward returns the gradient for the model output, the heads'
+// gradients concatenated along the last axis, and nil for the targets.
+func (m *MultiHeadLoss[T]) Backward(ctx context.Context, mode types.BackwardMode, dOut *tensor.TensorNumeric[T], _ ...*tensor.TensorNumeric[T]) ([]*tensor.TensorNumeric[T], error) {
+	if m.outputs == nil {
+		return nil, fmt.Errorf("MultiHeadLoss: Backward called before Forward")
+	}
+	ops := m.engine.Ops()
+	grads := make([]*tensor.TensorNumeric[T], len(m.heads))
+	for i, h := range m.heads {
+		d, err := m.engine.MulScalar(ctx, dOut, ops.FromFloat64(h.weight()))
+		if err != nil {
+			return nil, err
+		}
+		g, err := m.losses[i].Backward(ctx, mode, d, m.outputs[i], m.targets[i])
+		if err != nil {
+			return nil, fmt.Errorf("MultiHeadLoss: head %q: %w", h.Name, err)
+		}
+		grads[i] = g[0]
+	}
+	grad, err := m.engine.Concat(ctx, grads, len(grads[0].Shape())-1)
+	if err != nil {
+		return nil, err
+	}
+	return []*tensor.TensorNumeric[T]{grad, nil}, nil
+}
+
+// ComputeHeadMetrics computes each head's metrics on its slice of output
+// and targets. The values are named "<head>/<metric>".
+func (m *MultiHeadLoss[T]) ComputeHeadMetrics(ctx context.Context, output, targets *tensor.TensorNumeric[T]) (map[string]float64, error) {
+	outs, tgts, err := m.split(output, targets)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64)
+	for i, h := range m.heads {
+		if h.Metrics == nil {
+			continue
+		}
+		hv, err := h.Metrics.ComputeMetrics(ctx, outs[i], tgts[i], nil)
+		if err != nil {
+			return nil, fmt.Errorf("head %q: %w", h.Name, err)
+		}
+		for name, v := range hv {
+			values[h.Name+"/"+name] = v
+		}
+	}
+	return values, nil
+}
+
+// OutputShape returns the output shape of the loss, a single value.
+func (m *MultiHeadLoss[T]) OutputShape() []int {
+	return []int{1}
+}
+
+// OpType returns the operation type of the loss.
+func (m *MultiHeadLoss[T]) OpType() string {
+	return "MultiHeadLoss"
+}
+
+// Attributes returns the head names.
+func (m *MultiHeadLoss[T]) Attributes() map[string]interface{} {
+	names := make([]string, len(m.heads))
+	for i, h := range m.heads {
+		names[i] = h.Name
+	}
+	return map[string]interface{}{"heads": names}
+}
+
+// Parameters returns nil; the loss has no trainable parameters.
+func (m *MultiHeadLoss[T]) Parameters() []*graph.Parameter[T] {
+	return nil
+}
+
+var _ graph.Node[float32] = (*MultiHeadLoss[float32])(nil)
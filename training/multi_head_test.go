@@ -0,0 +1,163 @@
+package training_test
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/model"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+)
+
+func mseFactory(e compute.Engine[float32]) graph.Node[float32] { return loss.NewMSE(e, e.Ops()) }
+
+func TestMultiHeadLoss(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	mh, err := training.NewMultiHeadLoss(engine,
+		training.Head[float32]{Name: "reg", Width: 1, Loss: mseFactory},
+		training.Head[float32]{Name: "aux", Width: 2, Weight: 0.5, Loss: mseFactory},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, _ := tensor.New([]int{2, 3}, []float32{1, 2, 3, 4, 5, 6})
+	targets, _ := tensor.New([]int{2, 3}, []float32{0, 2, 2, 2, 5, 4})
+	total, err := mh.Forward(ctx, output, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reg: residuals 1, 2 -> 2.5; aux: residuals 0, 1, 0, 2 -> 1.25.
+	heads := mh.HeadLosses()
+	if heads["reg"] != 2.5 || heads["aux"] != 1.25 {
+		t.Errorf("HeadLosses = %v, want reg 2.5, aux 1.25", heads)
+	}
+	if got := total.Data()[0]; got != 2.5+0.5*1.25 {
+		t.Errorf("loss = %v, want %v", got, 2.5+0.5*1.25)
+	}
+
+	seed, _ := tensor.New([]int{1}, []float32{1})
+	grads, err := mh.Backward(ctx, types.FullBackprop, seed, output, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MSE's gradient is 2*r/N, with N the head's element count; aux's is
+	// then halved by its weight.
+	want := []float32{1, 0, 0.25, 2, 0, 0.5}
+	if got := grads[0]; !slices.Equal(got.Shape(), []int{2, 3}) || !closeAll(got.Data(), want, 1e-6) {
+		t.Errorf("gradient = %v %v, want [2 3] %v", got.Shape(), got.Data(), want)
+	}
+
+	specs := mh.OutputSpecs()
+	aux, err := model.SelectOutput(specs, output, "aux")
+	if err != nil || !closeAll(aux.Data(), []float32{2, 3, 5, 6}, 0) {
+		t.Errorf("SelectOutput(aux) = %v, %v", aux, err)
+	}
+
+	for name, heads := range map[string][]training.Head[float32]{
+		"none":           nil,
+		"duplicate name": {{Name: "a", Width: 1, Loss: mseFactory}, {Name: "a", Width: 1, Loss: mseFactory}},
+		"zero width":     {{Name: "a", Loss: mseFactory}},
+		"no loss":        {{Name: "a", Width: 1}},
+		"negative":       {{Name: "a", Width: 1, Weight: -1, Loss: mseFactory}},
+	} {
+		if _, err := training.NewMultiHeadLoss(engine, heads...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	wide, _ := tensor.New([]int{2, 4}, make([]float32, 8))
+	if _, err := mh.Forward(ctx, wide, targets); err == nil {
+		t.Error("expected an error for an output wider than the heads")
+	}
+}
+
+func TestStandardWorkflow_Heads(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, 2})
+	dense, err := core.NewDense[float32]("dense", engine, ops, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range g.Parameters() {
+		clear(p.Value.Data())
+	}
+
+	// Head "a" is y = 2*x0 - x1 + 0.5, head "b" is y = x0 + x1.
+	batches := func(seed uint64, n int) []*training.Batch[float32] {
+		rng := rand.New(rand.NewPCG(seed, 0))
+		const rows = 8
+		out := make([]*training.Batch[float32], n)
+		for i := range out {
+			x := make([]float32, rows*2)
+			y := make([]float32, rows*2)
+			for j := range rows {
+				x0, x1 := rng.Float64()*2-1, rng.Float64()*2-1
+				x[2*j], x[2*j+1] = float32(x0), float32(x1)
+				y[2*j], y[2*j+1] = float32(2*x0-x1+0.5), float32(x0+x1)
+			}
+			xt, _ := tensor.New([]int{rows, 2}, x)
+			yt, _ := tensor.New([]int{rows, 2}, y)
+			out[i] = &training.Batch[float32]{
+				Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{input: xt},
+				Targets: yt,
+			}
+		}
+		return out
+	}
+	data := &staticData{train: batches(1, 8), valid: batches(2, 2)}
+
+	w := newSGDWorkflow(training.WithHeads(
+		training.Head[float32]{Name: "a", Width: 1, Loss: mseFactory},
+		training.Head[float32]{Name: "b", Width: 1, Loss: mseFactory, Metrics: maeMetric{}},
+	))
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 40, LearningRate: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, data, &rigModels{g: g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a/loss", "b/loss", "b/mae"} {
+		if v, ok := result.Metrics[key]; !ok || v > 1e-2 {
+			t.Errorf("result metrics %v: %q = %v, want below 1e-2", result.Metrics, key, v)
+		}
+	}
+	if _, ok := result.Metrics["a/mae"]; ok {
+		t.Error("head a has no metrics, but a/mae was reported")
+	}
+
+	bad := newSGDWorkflow(training.WithHeads(training.Head[float32]{Name: "a", Width: 1}))
+	if err := bad.Initialize(ctx, training.WorkflowConfig{NumEpochs: 1, LearningRate: 0.1}); err == nil {
+		t.Error("Initialize should reject a head without a loss")
+	}
+}
+
+func closeAll(got, want []float32, tol float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > tol {
+			return false
+		}
+	}
+	return true
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/kernels"
 )
 
 // DARTSLayer implements a DARTS (Differentiable Architecture Search) mixed-operation
@@ -61,27 +63,15 @@ func (d *DARTSLayer[T]) softmax() []T {
 	n := len(alpha)
 	weights := make([]T, n)
 
-	// Find max for numerical stability.
-	maxVal := alpha[0]
-	for i := 1; i < n; i++ {
-		if d.ops.GreaterThan(alpha[i], maxVal) {
-			maxVal = alpha[i]
-		}
-	}
-
-	// Compute exp(alpha_i - max) and sum.
-	var sum T
+	// Subtract the max for numerical stability.
+	maxVal := kernels.MaxOps(d.ops, alpha)
 	for i, a := range alpha {
-		diff := d.ops.Sub(a, maxVal)
-		expVal := d.ops.Exp(diff)
-		weights[i] = expVal
-		sum = d.ops.Add(sum, expVal)
+		weights[i] = d.ops.Exp(d.ops.Sub(a, maxVal))
 	}
 
 	// Normalize.
-	for i := range weights {
-		weights[i] = d.ops.Div(weights[i], sum)
-	}
+	sum := kernels.SumOps(d.ops, weights)
+	kernels.ScaleOps(d.ops, weights, weights, d.ops.Div(d.ops.FromFloat64(1), sum))
 	return weights
 }
 
@@ -9,6 +9,8 @@ import (
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/kernels"
 )
 
 // DARTSOptimizerConfig holds configuration for the DARTS bilevel optimizer.
@@ -144,11 +146,7 @@ func (d *DARTSOptimizer[T]) outerStep(ctx context.Context, input, target *tensor
 
 // sgdUpdate performs w = w - lr * grad on a single parameter.
 func (d *DARTSOptimizer[T]) sgdUpdate(p *graph.Parameter[T], lr T) {
-	wData := p.Value.Data()
-	gData := p.Gradient.Data()
-	for i := range wData {
-		wData[i] = d.ops.Sub(wData[i], d.ops.Mul(lr, gData[i]))
-	}
+	kernels.AxpyOps(d.ops, d.ops.Sub(d.ops.FromFloat64(0), lr), p.Gradient.Data(), p.Value.Data())
 }
 
 // mseLoss computes mean squared error and its gradient.
@@ -161,15 +159,10 @@ func (d *DARTSOptimizer[T]) mseLoss(pred, target *tensor.TensorNumeric[T]) (T, *
 	nT := d.ops.FromFloat64(float64(n))
 	two := d.ops.FromFloat64(2.0)
 
-	var loss T
 	gradData := make([]T, n)
-
-	for i := range pData {
-		diff := d.ops.Sub(pData[i], tData[i])
-		loss = d.ops.Add(loss, d.ops.Mul(diff, diff))
-		gradData[i] = d.ops.Div(d.ops.Mul(two, diff), nT)
-	}
-	loss = d.ops.Div(loss, nT)
+	kernels.SubOps(d.ops, gradData, pData, tData)
+	loss := d.ops.Div(kernels.DotOps(d.ops, gradData, gradData), nT)
+	kernels.ScaleOps(d.ops, gradData, gradData, d.ops.Div(two, nT))
 
 	grad, err := tensor.New[T](pred.Shape(), gradData)
 	if err != nil {
@@ -12,6 +12,8 @@ import (
 	"github.com/zerfoo/ztensor/numeric"
 	"github.com/zerfoo/ztensor/tensor"
 	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/internal/kernels"
 )
 
 // SignalSearchConfig holds configuration for a NAS search over time-series
@@ -289,7 +291,7 @@ func runSignalTrial(
 			if err != nil {
 				return SignalSearchResult{}, err
 			}
-			loss, _, err := signalMSELoss(ops, pred, valTgt)
+			loss, _, err := signalMSELoss(pred, valTgt)
 			if err != nil {
 				return SignalSearchResult{}, err
 			}
@@ -348,21 +350,15 @@ func (c *signalCandidate) Backward(ctx context.Context, _ types.BackwardMode, dO
 }
 
 // signalMSELoss computes MSE loss for signal model evaluation.
-func signalMSELoss(ops numeric.Float32Ops, pred, target *tensor.TensorNumeric[float32]) (float32, *tensor.TensorNumeric[float32], error) {
+func signalMSELoss(pred, target *tensor.TensorNumeric[float32]) (float32, *tensor.TensorNumeric[float32], error) {
 	pData := pred.Data()
 	tData := target.Data()
-	n := len(pData)
-	nT := ops.FromFloat64(float64(n))
-	two := ops.FromFloat64(2.0)
-
-	var loss float32
-	gradData := make([]float32, n)
-	for i := range pData {
-		diff := ops.Sub(pData[i], tData[i])
-		loss = ops.Add(loss, ops.Mul(diff, diff))
-		gradData[i] = ops.Div(ops.Mul(two, diff), nT)
-	}
-	loss = ops.Div(loss, nT)
+	n := float32(len(pData))
+
+	gradData := make([]float32, len(pData))
+	kernels.SubF32(gradData, pData, tData)
+	loss := kernels.DotF32(gradData, gradData) / n
+	kernels.ScaleF32(gradData, gradData, 2/n)
 
 	grad, err := tensor.New[float32](pred.Shape(), gradData)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/zerfoo/float16"
 	"github.com/zerfoo/float8"
+	"github.com/zerfoo/zerfoo/internal/dtype"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -33,6 +34,10 @@ type AdamW[T tensor.Numeric] struct {
 	weightDecay  T
 	maxGradNorm  float64 // If > 0, clip global gradient norm to this value.
 
+	// keepGradients, set by SetZeroGradOnStep(false), makes Step leave
+	// param.Gradient untouched instead of zeroing it after the update.
+	keepGradients bool
+
 	// Full-precision (float64) copies of the hyperparameters.
 	//
 	// AdamW's defaults -- beta2 = 0.999, epsilon = 1e-7/1e-8 -- are NOT
@@ -69,7 +74,20 @@ type AdamW[T tensor.Numeric] struct {
 	mMixed    map[*graph.Parameter[T]][]T
 	useMixedV bool
 
+	// scratch64 is reused across parameters and steps by the host update to
+	// hold the float64 gradient, first moment and weights of one parameter.
+	scratch64 []float64
+
 	t int // Timestep
+
+	// start holds the timestep before each parameter's state was created, so
+	// a parameter added mid-training gets bias correction for its own step
+	// count, a.t - start[p], rather than the optimizer's.
+	start map[*graph.Parameter[T]]int
+	// hostOnly marks parameters whose state was migrated or reset. The fused
+	// GPU kernel keeps its moments on the engine, out of reach of
+	// MigrateState and ResetState, so these parameters take the host path.
+	hostOnly map[*graph.Parameter[T]]bool
 }
 
 // NewAdamW creates a new AdamW optimizer.
@@ -94,6 +112,8 @@ func NewAdamW[T tensor.Numeric](engine compute.Engine[T], learningRate, beta1, b
 		mMixed:       make(map[*graph.Parameter[T]][]T),
 		useMixedV:    shouldUseMixedPrecisionV[T](engine),
 		t:            0,
+		start:        make(map[*graph.Parameter[T]]int),
+		hostOnly:     make(map[*graph.Parameter[T]]bool),
 	}
 	a.learningRateF64 = numericToFloat64(learningRate)
 	a.beta1F64 = numericToFloat64(beta1)
@@ -135,6 +155,8 @@ func NewAdamWFromFloat64[T tensor.Numeric](
 		mMixed:          make(map[*graph.Parameter[T]][]T),
 		useMixedV:       shouldUseMixedPrecisionV[T](engine),
 		t:               0,
+		start:           make(map[*graph.Parameter[T]]int),
+		hostOnly:        make(map[*graph.Parameter[T]]bool),
 	}
 }
 
@@ -173,6 +195,13 @@ func (a *AdamW[T]) SetMaxGradNorm(maxGradNorm float64) {
 	a.maxGradNorm = maxGradNorm
 }
 
+// SetZeroGradOnStep controls whether Step zeroes each parameter's gradient
+// after updating it (the default). Pass false when the caller manages
+// gradients itself, e.g. to accumulate them across steps.
+func (a *AdamW[T]) SetZeroGradOnStep(zero bool) {
+	a.keepGradients = !zero
+}
+
 // Step updates the parameters based on their gradients.
 func (a *AdamW[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
 	// NaN/Inf guard and optional gradient clipping.
@@ -192,14 +221,8 @@ func (a *AdamW[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error
 // through the engine. Preserved for GPU engines and for float64 T where
 // further promotion has no benefit.
 func (a *AdamW[T]) stepEngine(ctx context.Context, params []*graph.Parameter[T]) error {
-	// Bias correction terms.
 	ops := a.engine.Ops()
 	one := ops.FromFloat64(1.0)
-	tAsT := ops.FromFloat64(float64(a.t))
-	numer := ops.Sqrt(ops.Sub(one, ops.Pow(a.beta2, tAsT)))
-	denom := ops.Sub(one, ops.Pow(a.beta1, tAsT))
-	biasCorr := ops.Div(numer, denom)
-	alpha := ops.Mul(a.learningRate, biasCorr)
 
 	for _, param := range params {
 		grad := param.Gradient
@@ -208,7 +231,10 @@ func (a *AdamW[T]) stepEngine(ctx context.Context, params []*graph.Parameter[T])
 			continue
 		}
 
-		if _, ok := a.m[param]; !ok {
+		// State is created on a parameter's first step, and recreated if the
+		// parameter was resized, e.g. widened during progressive growth.
+		if m, ok := a.m[param]; !ok || !tensor.ShapesEqual(m.Shape(), param.Value.Shape()) {
+			a.start[param] = a.t - 1
 			mTensor, err := tensor.New[T](param.Value.Shape(), nil)
 			if err != nil {
 				return err
@@ -232,6 +258,13 @@ func (a *AdamW[T]) stepEngine(ctx context.Context, params []*graph.Parameter[T])
 			a.v[param] = vTensor
 		}
 
+		// Bias correction terms.
+		tAsT := ops.FromFloat64(float64(a.paramStep(param)))
+		numer := ops.Sqrt(ops.Sub(one, ops.Pow(a.beta2, tAsT)))
+		denom := ops.Sub(one, ops.Pow(a.beta1, tAsT))
+		biasCorr := ops.Div(numer, denom)
+		alpha := ops.Mul(a.learningRate, biasCorr)
+
 		m := a.m[param]
 		v := a.v[param]
 		paramValue := param.Value
@@ -307,6 +340,9 @@ func (a *AdamW[T]) stepEngine(ctx context.Context, params []*graph.Parameter[T])
 			return err
 		}
 
+		if a.keepGradients {
+			continue
+		}
 		var zero T
 		if err := a.engine.Fill(ctx, param.Gradient, zero); err != nil {
 			param.ClearGradient()
@@ -348,7 +384,6 @@ func (a *AdamW[T]) stepEngine(ctx context.Context, params []*graph.Parameter[T])
 func (a *AdamW[T]) stepMixedV(ctx context.Context, params []*graph.Parameter[T]) error {
 	ops := a.engine.Ops()
 	one64 := 1.0
-	t64 := float64(a.t)
 	// Read the full-precision hyperparameters, NOT the T-typed fields: for
 	// reduced-precision T the latter may have rounded beta2 -> 1.0 and eps -> 0,
 	// which would zero the update. See the AdamW struct's float64-field comment.
@@ -358,9 +393,6 @@ func (a *AdamW[T]) stepMixedV(ctx context.Context, params []*graph.Parameter[T])
 	lrF := a.learningRateF64
 	wdF := a.weightDecayF64
 
-	numer := math.Sqrt(one64 - math.Pow(beta2F, t64))
-	denom := one64 - math.Pow(beta1F, t64)
-	alpha := lrF * (numer / denom)
 	lrWd := lrF * wdF
 
 	// On-device fast path (ADR 070 end state, ADR 075 lever L1): when the
@@ -381,55 +413,65 @@ func (a *AdamW[T]) stepMixedV(ctx context.Context, params []*graph.Parameter[T])
 			continue
 		}
 
-		if fusedOK && isGPUResident(param.Value) && isGPUResident(grad) {
+		// The fused kernel always zeroes the gradient, so it is skipped when
+		// gradients must be kept.
+		if fusedOK && !a.keepGradients && !a.hostOnly[param] && isGPUResident(param.Value) && isGPUResident(grad) {
+			if _, ok := a.start[param]; !ok {
+				a.start[param] = a.t - 1
+			}
 			if err := fused.GPUFusedAdamW(param.Value, grad,
-				beta1F, beta2F, epsF, lrF, wdF, a.t); err != nil {
+				beta1F, beta2F, epsF, lrF, wdF, a.paramStep(param)); err != nil {
 				return fmt.Errorf("adamw: on-device fused step for parameter %q: %w", param.Name, err)
 			}
 			continue
 		}
 
-		if _, ok := a.mMixed[param]; !ok {
-			total := 1
-			for _, d := range param.Value.Shape() {
-				total *= d
-			}
+		paramData := param.Value.Data() // D2H copy on GPU storage.
+		gradData := grad.Data()         // D2H copy on GPU storage.
+
+		// State is created on a parameter's first step, and recreated if the
+		// parameter was resized, e.g. widened during progressive growth.
+		if m, ok := a.mMixed[param]; !ok || len(m) != len(paramData) {
 			// Host-only first and second moment. These slices are never read
 			// or written by the engine, so on a GPU engine they cost zero
 			// host<->device transfers (cf. the device m tensor in stepEngine).
-			a.mMixed[param] = make([]T, total)
-			a.v64[param] = make([]float64, total)
+			a.mMixed[param] = make([]T, len(paramData))
+			a.v64[param] = make([]float64, len(paramData))
+			a.start[param] = a.t - 1
 		}
 
 		mData := a.mMixed[param]
 		v64 := a.v64[param]
-		paramData := param.Value.Data() // D2H copy on GPU storage.
-		gradData := grad.Data()         // D2H copy on GPU storage.
 
-		if len(v64) != len(paramData) {
-			return fmt.Errorf("adamw: v64 size mismatch for parameter %q: %d vs %d",
-				param.Name, len(v64), len(paramData))
-		}
-		if len(mData) != len(paramData) {
-			return fmt.Errorf("adamw: mMixed size mismatch for parameter %q: %d vs %d",
-				param.Name, len(mData), len(paramData))
-		}
-
-		for i := range paramData {
-			g := numericToFloat64(gradData[i])
-			mOld := numericToFloat64(mData[i])
-			mNew := beta1F*mOld + (one64-beta1F)*g
-			mData[i] = ops.FromFloat64(mNew)
+		t64 := float64(a.paramStep(param))
+		numer := math.Sqrt(one64 - math.Pow(beta2F, t64))
+		denom := one64 - math.Pow(beta1F, t64)
+		alpha := lrF * (numer / denom)
+
+		// Widen gradient, first moment and weights once per parameter into
+		// the reused scratch buffer, update in float64, and narrow back.
+		n := len(paramData)
+		if cap(a.scratch64) < 3*n {
+			a.scratch64 = make([]float64, 3*n)
+		}
+		g64 := dtype.Float64s(a.scratch64[:0:n], gradData)
+		m64 := dtype.Float64s(a.scratch64[n:n:2*n], mData)
+		p64 := dtype.Float64s(a.scratch64[2*n:2*n:3*n], paramData)
+		for i := range p64 {
+			g := g64[i]
+			mNew := beta1F*m64[i] + (one64-beta1F)*g
+			m64[i] = mNew
 
 			v64[i] = beta2F*v64[i] + (one64-beta2F)*g*g
 
 			denomI := math.Sqrt(v64[i]) + epsF
 			update := alpha * mNew / denomI
 
-			pv := numericToFloat64(paramData[i])
-			pv = pv - update - lrWd*pv
-			paramData[i] = ops.FromFloat64(pv)
+			pv := p64[i]
+			p64[i] = pv - update - lrWd*pv
 		}
+		dtype.FromFloat64s(ops, mData, m64)
+		dtype.FromFloat64s(ops, paramData, p64)
 
 		// Persist the updated weights to the parameter's storage. For CPU
 		// storage Data() returned the backing slice and this is a no-op
@@ -450,6 +492,9 @@ func (a *AdamW[T]) stepMixedV(ctx context.Context, params []*graph.Parameter[T])
 		// back through the existing storage keeps the same buffer (a same-size
 		// Set is an in-place memcpy / H2D, not a realloc). gradData is the host
 		// copy already read above, so this reuses it.
+		if a.keepGradients {
+			continue
+		}
 		var zero T
 		for i := range gradData {
 			gradData[i] = zero
@@ -460,6 +505,140 @@ func (a *AdamW[T]) stepMixedV(ctx context.Context, params []*graph.Parameter[T])
 	return nil
 }
 
+// paramStep returns the number of steps param has taken, counting the
+// current one.
+func (a *AdamW[T]) paramStep(param *graph.Parameter[T]) int {
+	return a.t - a.start[param]
+}
+
+// MigrateState moves the optimizer state of from to to, so a parameter that
+// was renamed, or recreated when the model was rebuilt, keeps its moments
+// and step count. from's state is dropped. It is an error for to to have a
+// different shape than the state.
+//
+// Moments kept by the engine's fused GPU kernel cannot be moved; a parameter
+// stepped only on that path restarts from fresh state, like ResetState.
+func (a *AdamW[T]) MigrateState(from, to *graph.Parameter[T]) error {
+	if from == to {
+		return nil
+	}
+	switch {
+	case a.m[from] == nil && a.mMixed[from] == nil:
+		a.ResetState(from, to)
+		return nil
+	case a.m[from] != nil:
+		if !tensor.ShapesEqual(a.m[from].Shape(), to.Value.Shape()) {
+			return fmt.Errorf("adamw: cannot migrate state of %q with shape %v to %q with shape %v",
+				from.Name, a.m[from].Shape(), to.Name, to.Value.Shape())
+		}
+	case a.mMixed[from] != nil:
+		if len(a.mMixed[from]) != to.Value.Size() {
+			return fmt.Errorf("adamw: cannot migrate state of %q with %d elements to %q with shape %v",
+				from.Name, len(a.mMixed[from]), to.Name, to.Value.Shape())
+		}
+	}
+	a.ResetState(to)
+	moveKey(a.m, from, to)
+	moveKey(a.v, from, to)
+	moveKey(a.mMixed, from, to)
+	moveKey(a.v64, from, to)
+	moveKey(a.start, from, to)
+	delete(a.hostOnly, from)
+	a.hostOnly[to] = true
+	return nil
+}
+
+// ResetState drops the optimizer state of params. Each restarts from zero
+// moments and bias correction at its next Step, as if newly added.
+func (a *AdamW[T]) ResetState(params ...*graph.Parameter[T]) {
+	for _, p := range params {
+		delete(a.m, p)
+		delete(a.v, p)
+		delete(a.mMixed, p)
+		delete(a.v64, p)
+		delete(a.start, p)
+		a.hostOnly[p] = true
+	}
+}
+
+// SaveState returns the moments and step counts of params. Moments kept by
+// the engine's fused GPU kernel cannot be read back; saving them is an
+// error.
+func (a *AdamW[T]) SaveState(params []*graph.Parameter[T]) (*State, error) {
+	byName, err := paramsByName(params)
+	if err != nil {
+		return nil, fmt.Errorf("adamw: %w", err)
+	}
+	s := &State{
+		Step:       a.t,
+		Slots:      map[string]map[string][]float64{"m": {}, "v": {}},
+		ParamSteps: make(map[string]int),
+	}
+	for name, p := range byName {
+		if _, ok := a.start[p]; !ok {
+			continue
+		}
+		switch {
+		case a.m[p] != nil:
+			s.Slots["m"][name] = dtype.Float64s(nil, a.m[p].Data())
+			s.Slots["v"][name] = dtype.Float64s(nil, a.v[p].Data())
+		case a.mMixed[p] != nil:
+			s.Slots["m"][name] = dtype.Float64s(nil, a.mMixed[p])
+			s.Slots["v"][name] = append([]float64(nil), a.v64[p]...)
+		default:
+			return nil, fmt.Errorf("adamw: the state of %q is held by the engine's fused GPU kernel and cannot be saved", name)
+		}
+		s.ParamSteps[name] = a.paramStep(p)
+	}
+	return s, nil
+}
+
+// LoadState replaces the moments and step counts of params with s. Loaded
+// parameters take the host path, like migrated ones.
+func (a *AdamW[T]) LoadState(params []*graph.Parameter[T], s *State) error {
+	byName, err := paramsByName(params)
+	if err != nil {
+		return fmt.Errorf("adamw: %w", err)
+	}
+	ms, vs := s.Slots["m"], s.Slots["v"]
+	for name := range ms {
+		p, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("adamw: state for unknown parameter %q", name)
+		}
+		if n := p.Value.Size(); len(ms[name]) != n || len(vs[name]) != n {
+			return fmt.Errorf("adamw: state of %q has %d and %d values, want %d", name, len(ms[name]), len(vs[name]), n)
+		}
+	}
+
+	ops := a.engine.Ops()
+	a.t = s.Step
+	for name, p := range byName {
+		a.ResetState(p)
+		m, ok := ms[name]
+		if !ok {
+			continue
+		}
+		mData := make([]T, len(m))
+		dtype.FromFloat64s(ops, mData, m)
+		if a.useMixedV {
+			a.mMixed[p] = mData
+			a.v64[p] = append([]float64(nil), vs[name]...)
+		} else {
+			vData := make([]T, len(m))
+			dtype.FromFloat64s(ops, vData, vs[name])
+			if a.m[p], err = tensor.New(p.Value.Shape(), mData); err != nil {
+				return err
+			}
+			if a.v[p], err = tensor.New(p.Value.Shape(), vData); err != nil {
+				return err
+			}
+		}
+		a.start[p] = a.t - s.ParamSteps[name]
+	}
+	return nil
+}
+
 // gpuFusedAdamW is implemented by engines that can run the AdamW
 // mixed-precision update entirely on device (e.g. ztensor's GPUEngine). When
 // available and the parameter/gradient are GPU-resident, stepMixedV calls this
@@ -618,3 +797,15 @@ func (a *AdamW[T]) SetLRFloat64(lr float64) {
 
 // Statically assert that the type implements the Optimizer interface.
 var _ Optimizer[float32] = (*AdamW[float32])(nil)
+
+// Statically assert that the type implements the GradientZeroer interface.
+var _ GradientZeroer = (*AdamW[float32])(nil)
+
+// Statically assert that the type implements the StateMigrator interface.
+var _ StateMigrator[float32] = (*AdamW[float32])(nil)
+
+// Statically assert that the type implements the LRSetter interface.
+var _ LRSetter = (*AdamW[float32])(nil)
+
+// Statically assert that the type implements the StateSaver interface.
+var _ StateSaver[float32] = (*AdamW[float32])(nil)
@@ -2,6 +2,7 @@ package optimizer
 
 import (
 	"context"
+	"fmt"
 	"math"
 
 	"github.com/zerfoo/ztensor/compute"
@@ -106,6 +107,11 @@ type AdamW8bit[T tensor.Numeric] struct {
 	lr, beta1, beta2, eps, wd float32
 	step                      int
 	m, v                      map[*graph.Parameter[T]]*Int8State
+	keepGradients             bool // set by SetZeroGradOnStep(false)
+
+	// start holds the step before each parameter's state was created, so a
+	// parameter added mid-training is bias-corrected for its own step count.
+	start map[*graph.Parameter[T]]int
 }
 
 // NewAdamW8bit creates a new 8-bit AdamW optimizer.
@@ -119,20 +125,23 @@ func NewAdamW8bit[T tensor.Numeric](engine compute.Engine[T], lr, beta1, beta2,
 		wd:     wd,
 		m:      make(map[*graph.Parameter[T]]*Int8State),
 		v:      make(map[*graph.Parameter[T]]*Int8State),
+		start:  make(map[*graph.Parameter[T]]int),
 	}
 }
 
+// SetZeroGradOnStep controls whether Step zeroes each parameter's gradient
+// after updating it (the default).
+func (a *AdamW8bit[T]) SetZeroGradOnStep(zero bool) {
+	a.keepGradients = !zero
+}
+
 // Step updates parameters based on their gradients. Moment estimates are stored
 // in INT8 and dequantized for computation, then re-quantized after update.
 func (a *AdamW8bit[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
 	a.step++
 
-	// Bias correction factors.
 	ops := a.engine.Ops()
 	one := ops.FromFloat64(1.0)
-	bc1 := 1.0 - math.Pow(float64(a.beta1), float64(a.step))
-	bc2 := 1.0 - math.Pow(float64(a.beta2), float64(a.step))
-	alphaScalar := ops.FromFloat64(float64(a.lr) * math.Sqrt(bc2) / bc1)
 	b1T := ops.FromFloat64(float64(a.beta1))
 	oneMinusB1 := ops.Sub(one, b1T)
 	b2T := ops.FromFloat64(float64(a.beta2))
@@ -150,16 +159,24 @@ func (a *AdamW8bit[T]) Step(ctx context.Context, params []*graph.Parameter[T]) e
 		n := len(param.Value.Data())
 
 		// Dequantize or initialize moment estimates as float32 slices,
-		// then wrap as tensors for vectorized engine ops.
+		// then wrap as tensors for vectorized engine ops. State is
+		// recreated if the parameter was resized.
 		var mf, vf []float32
-		if ms, ok := a.m[param]; ok {
+		if ms, ok := a.m[param]; ok && len(ms.data) == n {
 			mf = dequantizeFromInt8(*ms)
 			vf = dequantizeFromInt8(*a.v[param])
 		} else {
 			mf = make([]float32, n)
 			vf = make([]float32, n)
+			a.start[param] = a.step - 1
 		}
 
+		// Bias correction factors for the parameter's own step count.
+		t := float64(a.step - a.start[param])
+		bc1 := 1.0 - math.Pow(float64(a.beta1), t)
+		bc2 := 1.0 - math.Pow(float64(a.beta2), t)
+		alphaScalar := ops.FromFloat64(float64(a.lr) * math.Sqrt(bc2) / bc1)
+
 		// Create moment tensors with the same shape as the parameter.
 		mTensor, err := tensor.New[T](shape, float32SliceToT[T](mf, ops))
 		if err != nil {
@@ -243,6 +260,9 @@ func (a *AdamW8bit[T]) Step(ctx context.Context, params []*graph.Parameter[T]) e
 		a.v[param] = &vq
 
 		// Clear gradient.
+		if a.keepGradients {
+			continue
+		}
 		var zero T
 		if err := a.engine.Fill(ctx, param.Gradient, zero); err != nil {
 			param.ClearGradient()
@@ -270,5 +290,46 @@ func tSliceToFloat32[T tensor.Numeric](src []T) []float32 {
 	return dst
 }
 
+// MigrateState moves the optimizer state of from to to and drops it from
+// from. It is an error for to to have a different size than the state.
+func (a *AdamW8bit[T]) MigrateState(from, to *graph.Parameter[T]) error {
+	if from == to {
+		return nil
+	}
+	if ms, ok := a.m[from]; ok && len(ms.data) != to.Value.Size() {
+		return fmt.Errorf("adamw8bit: cannot migrate state of %q with %d elements to %q with shape %v",
+			from.Name, len(ms.data), to.Name, to.Value.Shape())
+	}
+	moveKey(a.m, from, to)
+	moveKey(a.v, from, to)
+	moveKey(a.start, from, to)
+	return nil
+}
+
+// ResetState drops the optimizer state of params. Each restarts from zero
+// moments and bias correction at its next Step.
+func (a *AdamW8bit[T]) ResetState(params ...*graph.Parameter[T]) {
+	for _, p := range params {
+		delete(a.m, p)
+		delete(a.v, p)
+		delete(a.start, p)
+	}
+}
+
+// SetLRFloat64 sets the learning rate. This is typically called by a
+// scheduler.
+func (a *AdamW8bit[T]) SetLRFloat64(lr float64) {
+	a.lr = float32(lr)
+}
+
 // Statically assert that AdamW8bit implements the Optimizer interface.
 var _ Optimizer[float32] = (*AdamW8bit[float32])(nil)
+
+// Statically assert that AdamW8bit implements the GradientZeroer interface.
+var _ GradientZeroer = (*AdamW8bit[float32])(nil)
+
+// Statically assert that AdamW8bit implements the StateMigrator interface.
+var _ StateMigrator[float32] = (*AdamW8bit[float32])(nil)
+
+// Statically assert that AdamW8bit implements the LRSetter interface.
+var _ LRSetter = (*AdamW8bit[float32])(nil)
@@ -0,0 +1,73 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GlobalGradNorm returns the L2 norm of all parameter gradients taken
+// together. Parameters with nil gradients are skipped; a parameter listed
+// more than once is counted once.
+func GlobalGradNorm[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T]) (float64, error) {
+	var normSq float64
+	seen := make(map[*graph.Parameter[T]]bool, len(params))
+	for _, param := range params {
+		if param.Gradient == nil || seen[param] {
+			continue
+		}
+		seen[param] = true
+
+		// Reduce on the engine, as guardAndClipGradients does, so a
+		// device-resident gradient only copies back a scalar.
+		flat, err := param.Gradient.Reshape([]int{-1})
+		if err != nil {
+			return 0, fmt.Errorf("grad norm: reshape gradient of parameter %q: %w", param.Name, err)
+		}
+		sq, err := engine.Mul(ctx, flat, flat, nil)
+		if err != nil {
+			return 0, fmt.Errorf("grad norm: parameter %q: %w", param.Name, err)
+		}
+		sum, err := engine.ReduceSum(ctx, sq, 0, false)
+		if err != nil {
+			return 0, fmt.Errorf("grad norm: parameter %q: %w", param.Name, err)
+		}
+		normSq += numericToFloat64(sum.Data()[0])
+	}
+	return math.Sqrt(normSq), nil
+}
+
+// ClipGradNorm scales all parameter gradients in place so that their global
+// L2 norm is at most maxNorm, and returns the norm measured before clipping.
+// It is a no-op when maxNorm <= 0 or the norm is already within bounds.
+// A non-finite norm is reported as an error rather than propagated into the
+// parameters.
+func ClipGradNorm[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T], maxNorm float64) (float64, error) {
+	norm, err := GlobalGradNorm(ctx, engine, params)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return norm, fmt.Errorf("grad norm: non-finite gradient norm %v", norm)
+	}
+	if maxNorm <= 0 || norm <= maxNorm {
+		return norm, nil
+	}
+
+	scale := engine.Ops().FromFloat64(maxNorm / norm)
+	seen := make(map[*graph.Parameter[T]]bool, len(params))
+	for _, param := range params {
+		if param.Gradient == nil || seen[param] {
+			continue
+		}
+		seen[param] = true
+		if _, err := engine.MulScalar(ctx, param.Gradient, scale, param.Gradient); err != nil {
+			return norm, fmt.Errorf("grad norm: scale gradient of parameter %q: %w", param.Name, err)
+		}
+	}
+	return norm, nil
+}
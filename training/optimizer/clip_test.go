@@ -0,0 +1,88 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func newGradParam(t *testing.T, name string, grad []float32) *graph.Parameter[float32] {
+	t.Helper()
+	value, err := tensor.New[float32]([]int{len(grad)}, make([]float32, len(grad)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := graph.NewParameter(name, value, tensor.New[float32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Gradient, err = tensor.New[float32]([]int{len(grad)}, grad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestClipGradNorm(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+
+	tests := []struct {
+		name    string
+		maxNorm float64
+		want    []float32 // concatenated gradients after clipping
+	}{
+		{"clips to max norm", 2.5, []float32{1.5, 0, 0, 2}},
+		{"within bounds", 10, []float32{3, 0, 0, 4}},
+		{"disabled", 0, []float32{3, 0, 0, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newGradParam(t, "a", []float32{3, 0})
+			b := newGradParam(t, "b", []float32{0, 4})
+			// b is listed twice, as Graph.Parameters does for shared
+			// parameters; it must be counted and scaled once.
+			params := []*graph.Parameter[float32]{a, b, b}
+
+			norm, err := ClipGradNorm(ctx, engine, params, tt.maxNorm)
+			if err != nil {
+				t.Fatalf("ClipGradNorm: %v", err)
+			}
+			if math.Abs(norm-5) > 1e-6 {
+				t.Errorf("norm = %v, want 5", norm)
+			}
+			got := append(append([]float32(nil), a.Gradient.Data()...), b.Gradient.Data()...)
+			for i := range got {
+				if math.Abs(float64(got[i]-tt.want[i])) > 1e-6 {
+					t.Fatalf("gradients = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClipGradNorm_SkipsNilAndRejectsNonFinite(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+
+	a := newGradParam(t, "a", []float32{1, 1})
+	none := newGradParam(t, "none", []float32{0})
+	none.Gradient = nil
+	norm, err := GlobalGradNorm(ctx, engine, []*graph.Parameter[float32]{a, none})
+	if err != nil {
+		t.Fatalf("GlobalGradNorm: %v", err)
+	}
+	if math.Abs(norm-math.Sqrt2) > 1e-6 {
+		t.Errorf("norm = %v, want sqrt(2)", norm)
+	}
+
+	bad := newGradParam(t, "bad", []float32{float32(math.Inf(1))})
+	if _, err := ClipGradNorm(ctx, engine, []*graph.Parameter[float32]{a, bad}, 1); err == nil {
+		t.Error("expected error for non-finite gradient norm")
+	}
+}
@@ -1,4 +1,5 @@
-// Package optimizer provides neural network optimizers including AdamW and SGD.
+// Package optimizer provides neural network optimizers including AdamW, SGD,
+// Sophia and diagonal Shampoo.
 //
 // Stability: beta
 package optimizer
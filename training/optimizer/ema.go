@@ -112,5 +112,21 @@ func (e *EMA[T]) SwapBack(ctx context.Context, params []*graph.Parameter[T]) err
 	return e.SwapShadow(ctx, params)
 }
 
+// ResetToAverage overwrites param.Value with the shadow weights, keeping the
+// shadow as is. Used for Polyak-average restarts, e.g. at an SGDR cycle
+// boundary, where training continues from the averaged point.
+func (e *EMA[T]) ResetToAverage(ctx context.Context, params []*graph.Parameter[T]) error {
+	for _, param := range params {
+		shadow, ok := e.shadow[param]
+		if !ok || param.Value == nil {
+			continue
+		}
+		if err := e.engine.Copy(ctx, param.Value, shadow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Statically assert that the type implements the Optimizer interface.
 var _ Optimizer[float32] = (*EMA[float32])(nil)
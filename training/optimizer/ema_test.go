@@ -124,3 +124,30 @@ func TestEMA_SwapShadow_SwapBack(t *testing.T) {
 		}
 	}
 }
+
+func TestEMA_ResetToAverage(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	ema := NewEMA[float32](&setOptimizer[float32]{value: 10.0}, engine, 0.5)
+
+	value, _ := tensor.New[float32]([]int{1}, []float32{0.0})
+	param, _ := graph.NewParameter("p", value, tensor.New[float32])
+	params := []*graph.Parameter[float32]{param}
+	for range 2 {
+		if err := ema.Step(ctx, params); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+	}
+	// shadow = 10 after the first step's copy, then 0.5*10 + 0.5*10 = 10;
+	// move the live weight away to see the reset.
+	param.Value.Data()[0] = 3
+	if err := ema.ResetToAverage(ctx, params); err != nil {
+		t.Fatalf("ResetToAverage: %v", err)
+	}
+	if got := param.Value.Data()[0]; got != 10 {
+		t.Errorf("param = %v, want shadow value 10", got)
+	}
+	if got := ema.shadow[param].Data()[0]; got != 10 {
+		t.Errorf("shadow = %v, want unchanged 10", got)
+	}
+}
@@ -0,0 +1,175 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GradientFunc recomputes the loss gradient at the current parameter values
+// and stores it in each parameter's Gradient, overwriting what is there. A
+// closure over one forward and backward pass of a fixed batch, preceded by
+// ZeroGrad, is the usual implementation.
+type GradientFunc func(ctx context.Context) error
+
+// GNBHessian returns the Gauss-Newton-Bartlett diagonal Hessian estimate
+// used by Sophia-G: batchSize * g ⊙ g, where g is the current gradient of
+// each parameter. For the estimate to be unbiased, g must be the gradient of
+// the mean loss on labels sampled from the model's own output distribution,
+// not on the true labels. Parameters without a gradient are omitted.
+func GNBHessian[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T], batchSize int) (map[*graph.Parameter[T]]*tensor.TensorNumeric[T], error) {
+	scale := engine.Ops().FromFloat64(float64(batchSize))
+	out := make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T], len(params))
+	for _, p := range params {
+		if p.Gradient == nil {
+			continue
+		}
+		sq, err := engine.Mul(ctx, p.Gradient, p.Gradient)
+		if err != nil {
+			return nil, err
+		}
+		if out[p], err = engine.MulScalar(ctx, sq, scale); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// HutchinsonHessian returns Hutchinson's unbiased estimate of the Hessian
+// diagonal used by Sophia-H: the mean over probes of u ⊙ (H u), for random
+// Rademacher vectors u. The Hessian-vector product is taken as a finite
+// difference of gradients,
+//
+//	H u ≈ (∇L(θ + delta·u) - ∇L(θ)) / delta,
+//
+// so it needs only first-order backward passes: grad is called once at θ and
+// once per probe. Parameter values are restored exactly before returning, and
+// each parameter's Gradient is left holding ∇L(θ), ready for Step.
+func HutchinsonHessian[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], params []*graph.Parameter[T], grad GradientFunc, probes int, delta float64, rng *rand.Rand) (map[*graph.Parameter[T]]*tensor.TensorNumeric[T], error) {
+	if probes < 1 {
+		return nil, fmt.Errorf("hutchinson: probes must be positive, got %d", probes)
+	}
+	if delta <= 0 {
+		return nil, fmt.Errorf("hutchinson: delta must be positive, got %g", delta)
+	}
+	if rng == nil {
+		return nil, errors.New("hutchinson: rng must not be nil")
+	}
+	ops := engine.Ops()
+
+	if err := grad(ctx); err != nil {
+		return nil, err
+	}
+	base := make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T], len(params))
+	values := make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T], len(params))
+	for _, p := range params {
+		if p.Gradient == nil {
+			continue
+		}
+		g, err := clone(ctx, engine, p.Gradient)
+		if err != nil {
+			return nil, err
+		}
+		v, err := clone(ctx, engine, p.Value)
+		if err != nil {
+			return nil, err
+		}
+		base[p], values[p] = g, v
+	}
+
+	// restore puts back θ and ∇L(θ); it runs on every return path so an
+	// error mid-probe never leaves the model perturbed.
+	restore := func() error {
+		for p, v := range values {
+			if err := engine.Copy(ctx, p.Value, v); err != nil {
+				return err
+			}
+			// Copy in place where possible, so strategies that track
+			// gradient storage identity keep seeing the same tensor.
+			if p.Gradient == nil {
+				p.Gradient = base[p]
+			} else if err := engine.Copy(ctx, p.Gradient, base[p]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	plus, minus := ops.FromFloat64(delta), ops.FromFloat64(-delta)
+	invScale := ops.FromFloat64(1 / (delta * delta * float64(probes)))
+	est := make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T], len(base))
+	probe := make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T], len(base))
+	for range probes {
+		// θ + delta·u, with u drawn from {-1, +1}: delta·u is drawn directly.
+		for p, v := range values {
+			du := make([]T, p.Value.Size())
+			for i := range du {
+				if rng.IntN(2) == 0 {
+					du[i] = minus
+				} else {
+					du[i] = plus
+				}
+			}
+			u, err := tensor.New(p.Value.Shape(), du)
+			if err != nil {
+				return nil, errors.Join(err, restore())
+			}
+			probe[p] = u
+			perturbed, err := engine.Add(ctx, v, u)
+			if err != nil {
+				return nil, errors.Join(err, restore())
+			}
+			if err := engine.Copy(ctx, p.Value, perturbed); err != nil {
+				return nil, errors.Join(err, restore())
+			}
+		}
+		if err := grad(ctx); err != nil {
+			return nil, errors.Join(err, restore())
+		}
+		// (delta·u) ⊙ (∇L(θ+delta·u) - ∇L(θ)) / (delta² · probes) is one
+		// probe's share of u ⊙ H u.
+		for p, u := range probe {
+			if p.Gradient == nil {
+				return nil, errors.Join(fmt.Errorf("hutchinson: parameter %q lost its gradient", p.Name), restore())
+			}
+			diff, err := engine.Sub(ctx, p.Gradient, base[p])
+			if err != nil {
+				return nil, errors.Join(err, restore())
+			}
+			hu, err := engine.Mul(ctx, diff, u)
+			if err != nil {
+				return nil, errors.Join(err, restore())
+			}
+			if hu, err = engine.MulScalar(ctx, hu, invScale); err != nil {
+				return nil, errors.Join(err, restore())
+			}
+			if acc, ok := est[p]; ok {
+				if hu, err = engine.Add(ctx, acc, hu); err != nil {
+					return nil, errors.Join(err, restore())
+				}
+			}
+			est[p] = hu
+		}
+	}
+	if err := restore(); err != nil {
+		return nil, err
+	}
+	return est, nil
+}
+
+// clone returns a copy of t with its own storage.
+func clone[T tensor.Numeric](ctx context.Context, engine compute.Engine[T], t *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	out, err := tensor.New[T](t.Shape(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.Copy(ctx, out, t); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
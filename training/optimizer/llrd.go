@@ -0,0 +1,130 @@
+package optimizer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Default patterns for LayerDecayGroups. Block indices are recognized in the
+// naming schemes used across the repo and upstream checkpoints:
+// "model.layers.3.", "blk.3.", "encoder.layer.3.", "h.3.", "blocks.3.".
+var (
+	defaultBlockPattern     = regexp.MustCompile(`(?:^|[._])(?:layers|layer|blk|blocks|block|h)[._](\d+)(?:[._]|$)`)
+	defaultEmbeddingPattern = regexp.MustCompile(`(?i)embed|token_embd|(?:^|[._])(?:wte|wpe)(?:[._]|$)`)
+)
+
+// LayerDecayOption configures LayerDecayGroups.
+type LayerDecayOption func(*layerDecayConfig)
+
+type layerDecayConfig struct {
+	block     *regexp.Regexp
+	embedding *regexp.Regexp
+}
+
+// WithBlockPattern overrides the pattern that extracts a transformer block
+// index from a parameter name. The first capture group must match the
+// decimal block index.
+func WithBlockPattern(re *regexp.Regexp) LayerDecayOption {
+	return func(c *layerDecayConfig) {
+		c.block = re
+	}
+}
+
+// WithEmbeddingPattern overrides the pattern that identifies embedding
+// parameters.
+func WithEmbeddingPattern(re *regexp.Regexp) LayerDecayOption {
+	return func(c *layerDecayConfig) {
+		c.embedding = re
+	}
+}
+
+// LayerDecayGroups builds parameter groups for layer-wise learning rate
+// decay (LLRD), the usual recipe for fine-tuning pretrained transformers:
+// layers nearer the output adapt more than layers nearer the input.
+//
+// Parameters are assigned a depth from their names. With L transformer
+// blocks, embeddings have depth 0, block i has depth i+1, and everything
+// else (final norm, task or LM head) has depth L+1. A group at depth d gets
+//
+//	LRScale = decay^(L+1-d)
+//
+// so the head trains at the base rate, the top block at decay times it, and
+// the embeddings at decay^(L+1). Groups are returned ordered from embeddings
+// to head; empty groups are omitted. Use the result with
+// NewGroupedOptimizer.
+func LayerDecayGroups[T tensor.Numeric](params []*graph.Parameter[T], decay float64, opts ...LayerDecayOption) ([]ParamGroup[T], error) {
+	if decay <= 0 || decay > 1 {
+		return nil, fmt.Errorf("layer decay: decay must be in (0, 1], got %v", decay)
+	}
+	cfg := layerDecayConfig{block: defaultBlockPattern, embedding: defaultEmbeddingPattern}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.block == nil || cfg.embedding == nil {
+		return nil, errors.New("layer decay: nil pattern")
+	}
+
+	const (
+		depthEmbedding = -1
+		depthTop       = math.MaxInt
+	)
+	depths := make(map[*graph.Parameter[T]]int, len(params))
+	var ordered []*graph.Parameter[T]
+	numBlocks := 0
+	for _, p := range params {
+		if _, seen := depths[p]; seen {
+			continue
+		}
+		ordered = append(ordered, p)
+		switch m := cfg.block.FindStringSubmatch(p.Name); {
+		case len(m) > 1:
+			idx, err := strconv.Atoi(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("layer decay: parameter %q: invalid block index %q", p.Name, m[1])
+			}
+			depths[p] = idx
+			numBlocks = max(numBlocks, idx+1)
+		case cfg.embedding.MatchString(p.Name):
+			depths[p] = depthEmbedding
+		default:
+			depths[p] = depthTop
+		}
+	}
+
+	byDepth := make(map[int][]*graph.Parameter[T])
+	for _, p := range ordered {
+		byDepth[depths[p]] = append(byDepth[depths[p]], p)
+	}
+	keys := make([]int, 0, len(byDepth))
+	for d := range byDepth {
+		keys = append(keys, d)
+	}
+	sort.Ints(keys)
+
+	groups := make([]ParamGroup[T], 0, len(keys))
+	for _, d := range keys {
+		var name string
+		var depth int
+		switch d {
+		case depthEmbedding:
+			name, depth = "embeddings", 0
+		case depthTop:
+			name, depth = "head", numBlocks+1
+		default:
+			name, depth = fmt.Sprintf("block.%d", d), d+1
+		}
+		groups = append(groups, ParamGroup[T]{
+			Name:    name,
+			Params:  byDepth[d],
+			LRScale: math.Pow(decay, float64(numBlocks+1-depth)),
+		})
+	}
+	return groups, nil
+}
@@ -0,0 +1,113 @@
+package optimizer
+
+import (
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func namedParams(t *testing.T, names ...string) []*graph.Parameter[float32] {
+	t.Helper()
+	params := make([]*graph.Parameter[float32], len(names))
+	for i, name := range names {
+		v, err := tensor.New[float32]([]int{1}, []float32{0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		params[i], err = graph.NewParameter(name, v, tensor.New[float32])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return params
+}
+
+func TestLayerDecayGroups(t *testing.T) {
+	params := namedParams(t,
+		"model.embed_tokens.weight",
+		"model.layers.0.self_attn.q_proj.weight",
+		"model.layers.0.input_layernorm.weight",
+		"model.layers.1.mlp.up_proj.weight",
+		"model.layers.2.mlp.down_proj.weight",
+		"model.norm.weight",
+		"lm_head.weight",
+	)
+	groups, err := LayerDecayGroups(params, 0.5)
+	if err != nil {
+		t.Fatalf("LayerDecayGroups: %v", err)
+	}
+
+	want := []struct {
+		name   string
+		params int
+		scale  float64
+	}{
+		{"embeddings", 1, 0.0625}, // 0.5^4
+		{"block.0", 2, 0.125},
+		{"block.1", 1, 0.25},
+		{"block.2", 1, 0.5},
+		{"head", 2, 1},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Name != w.name || len(g.Params) != w.params || math.Abs(g.LRScale-w.scale) > 1e-12 {
+			t.Errorf("group %d = {%s, %d params, %v}, want {%s, %d params, %v}",
+				i, g.Name, len(g.Params), g.LRScale, w.name, w.params, w.scale)
+		}
+	}
+}
+
+func TestLayerDecayGroups_NamingSchemes(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"blk.4.attn_q.weight", "block.4"},
+		{"encoder.layer.4.attention.self.query.weight", "block.4"},
+		{"transformer.h.4.attn.c_attn.weight", "block.4"},
+		{"token_embd.weight", "embeddings"},
+		{"transformer.wte.weight", "embeddings"},
+		{"output_norm.weight", "head"},
+	}
+	for _, tt := range tests {
+		groups, err := LayerDecayGroups(namedParams(t, tt.name), 0.9)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(groups) != 1 || groups[0].Name != tt.want {
+			t.Errorf("%s: groups = %+v, want one %q group", tt.name, groups, tt.want)
+		}
+	}
+}
+
+func TestLayerDecayGroups_Options(t *testing.T) {
+	params := namedParams(t, "stage3_proj", "stem_conv", "classifier")
+	groups, err := LayerDecayGroups(params, 0.5,
+		WithBlockPattern(regexp.MustCompile(`^stage(\d+)_`)),
+		WithEmbeddingPattern(regexp.MustCompile(`^stem`)),
+	)
+	if err != nil {
+		t.Fatalf("LayerDecayGroups: %v", err)
+	}
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	if got, want := len(groups), 3; got != want || names[0] != "embeddings" || names[1] != "block.3" || names[2] != "head" {
+		t.Errorf("groups = %v, want [embeddings block.3 head]", names)
+	}
+}
+
+func TestLayerDecayGroups_InvalidDecay(t *testing.T) {
+	for _, d := range []float64{0, -0.5, 1.5} {
+		if _, err := LayerDecayGroups(namedParams(t, "w"), d); err == nil {
+			t.Errorf("decay %v: expected error", d)
+		}
+	}
+}
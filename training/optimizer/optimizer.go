@@ -11,3 +11,10 @@ import (
 type Optimizer[T tensor.Numeric] interface {
 	Step(ctx context.Context, params []*graph.Parameter[T]) error
 }
+
+// LRSetter is implemented by optimizers whose learning rate can be changed
+// between steps, typically by a learning rate scheduler. The rate is a
+// float64 so a small value is not rounded for reduced-precision T.
+type LRSetter interface {
+	SetLRFloat64(lr float64)
+}
@@ -0,0 +1,128 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// ParamGroup is a set of parameters that share optimizer settings. LRScale
+// multiplies the base learning rate for the group's parameters.
+type ParamGroup[T tensor.Numeric] struct {
+	Name    string
+	Params  []*graph.Parameter[T]
+	LRScale float64
+}
+
+// GroupedOptimizer steps each parameter group with its own optimizer
+// instance, built at baseLR * group.LRScale. Parameters passed to Step that
+// belong to no group are stepped by a default optimizer at the base rate.
+//
+// Each group owns its optimizer state (e.g. AdamW moments), so a parameter
+// must belong to at most one group.
+type GroupedOptimizer[T tensor.Numeric] struct {
+	groups   []ParamGroup[T]
+	opts     []Optimizer[T]
+	member   map[*graph.Parameter[T]]int
+	newOpt   func(lr float64) Optimizer[T]
+	baseLR   float64
+	fallback Optimizer[T]
+}
+
+// NewGroupedOptimizer creates a GroupedOptimizer. newOpt constructs the
+// optimizer for one group given its learning rate, e.g.
+//
+//	func(lr float64) optimizer.Optimizer[float32] {
+//	    return optimizer.NewAdamWFromFloat64[float32](engine, lr, 0.9, 0.999, 1e-8, 0.01)
+//	}
+func NewGroupedOptimizer[T tensor.Numeric](baseLR float64, groups []ParamGroup[T], newOpt func(lr float64) Optimizer[T]) (*GroupedOptimizer[T], error) {
+	if newOpt == nil {
+		return nil, errors.New("grouped optimizer: nil optimizer constructor")
+	}
+	g := &GroupedOptimizer[T]{
+		groups: groups,
+		opts:   make([]Optimizer[T], len(groups)),
+		member: make(map[*graph.Parameter[T]]int),
+		newOpt: newOpt,
+		baseLR: baseLR,
+	}
+	for i, grp := range groups {
+		if grp.LRScale < 0 {
+			return nil, fmt.Errorf("grouped optimizer: group %q has negative LR scale %v", grp.Name, grp.LRScale)
+		}
+		for _, p := range grp.Params {
+			if j, dup := g.member[p]; dup && j != i {
+				return nil, fmt.Errorf("grouped optimizer: parameter %q is in groups %q and %q", p.Name, groups[j].Name, grp.Name)
+			}
+			g.member[p] = i
+		}
+		g.opts[i] = newOpt(baseLR * grp.LRScale)
+	}
+	return g, nil
+}
+
+// Groups returns the parameter groups.
+func (g *GroupedOptimizer[T]) Groups() []ParamGroup[T] { return g.groups }
+
+// GroupLR returns the current learning rate of group i.
+func (g *GroupedOptimizer[T]) GroupLR(i int) float64 { return g.baseLR * g.groups[i].LRScale }
+
+// Step partitions params by group and steps each group's optimizer on its
+// share. Parameters listed more than once are passed once.
+func (g *GroupedOptimizer[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
+	parts := make([][]*graph.Parameter[T], len(g.groups))
+	var rest []*graph.Parameter[T]
+	seen := make(map[*graph.Parameter[T]]bool, len(params))
+	for _, p := range params {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if i, ok := g.member[p]; ok {
+			parts[i] = append(parts[i], p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		if err := g.opts[i].Step(ctx, part); err != nil {
+			return fmt.Errorf("group %q: %w", g.groups[i].Name, err)
+		}
+	}
+	if len(rest) > 0 {
+		if g.fallback == nil {
+			g.fallback = g.newOpt(g.baseLR)
+		}
+		if err := g.fallback.Step(ctx, rest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLRFloat64 sets the base learning rate, typically from a scheduler, and
+// rescales every group's optimizer. Group optimizers that do not support
+// SetLRFloat64 keep their original rate.
+func (g *GroupedOptimizer[T]) SetLRFloat64(lr float64) {
+	g.baseLR = lr
+	for i, o := range g.opts {
+		if s, ok := o.(LRSetter); ok {
+			s.SetLRFloat64(lr * g.groups[i].LRScale)
+		}
+	}
+	if s, ok := g.fallback.(LRSetter); ok {
+		s.SetLRFloat64(lr)
+	}
+}
+
+// Statically assert that the type implements the Optimizer interface.
+var _ Optimizer[float32] = (*GroupedOptimizer[float32])(nil)
+
+// Statically assert that the type implements the LRSetter interface.
+var _ LRSetter = (*GroupedOptimizer[float32])(nil)
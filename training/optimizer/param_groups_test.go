@@ -0,0 +1,93 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestGroupedOptimizer_PerGroupLearningRates(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+
+	params := namedParams(t, "model.layers.0.w", "model.layers.1.w", "lm_head.weight", "extra")
+	groups, err := LayerDecayGroups(params[:3], 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const base = 0.4
+	opt, err := NewGroupedOptimizer(base, groups, func(lr float64) Optimizer[float32] {
+		return NewSGD[float32](engine, ops, float32(lr))
+	})
+	if err != nil {
+		t.Fatalf("NewGroupedOptimizer: %v", err)
+	}
+	for _, p := range params {
+		p.Gradient, err = tensor.New[float32]([]int{1}, []float32{1})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Shared parameters may be listed twice; they must step once.
+	if err := opt.Step(ctx, append(params, params[0])); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	// SGD: value = 0 - lr*1. Scales are 0.25, 0.5, 1; "extra" is in no
+	// group and uses the base rate.
+	want := []float64{-0.1, -0.2, -0.4, -0.4}
+	for i, p := range params {
+		if got := float64(p.Value.Data()[0]); math.Abs(got-want[i]) > 1e-6 {
+			t.Errorf("%s = %v, want %v", p.Name, got, want[i])
+		}
+	}
+}
+
+func TestGroupedOptimizer_SetLRFloat64(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	params := namedParams(t, "blk.0.w", "output.weight")
+	groups, err := LayerDecayGroups(params, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var adams []*AdamW[float32]
+	opt, err := NewGroupedOptimizer(1e-3, groups, func(lr float64) Optimizer[float32] {
+		a := NewAdamWFromFloat64[float32](engine, lr, 0.9, 0.999, 1e-8, 0)
+		adams = append(adams, a)
+		return a
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt.SetLRFloat64(2e-3)
+	for i, want := range []float64{1e-3, 2e-3} {
+		if got := adams[i].learningRateF64; math.Abs(got-want) > 1e-12 {
+			t.Errorf("group %d lr = %v, want %v", i, got, want)
+		}
+		if got := opt.GroupLR(i); math.Abs(got-want) > 1e-12 {
+			t.Errorf("GroupLR(%d) = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestNewGroupedOptimizer_Errors(t *testing.T) {
+	params := namedParams(t, "a")
+	newOpt := func(float64) Optimizer[float32] { return &noopOptimizer[float32]{} }
+
+	dup := []ParamGroup[float32]{{Name: "x", Params: params, LRScale: 1}, {Name: "y", Params: params, LRScale: 1}}
+	if _, err := NewGroupedOptimizer(1, dup, newOpt); err == nil {
+		t.Error("expected error for parameter in two groups")
+	}
+	neg := []ParamGroup[float32]{{Name: "x", Params: params, LRScale: -1}}
+	if _, err := NewGroupedOptimizer(1, neg, newOpt); err == nil {
+		t.Error("expected error for negative LR scale")
+	}
+	if _, err := NewGroupedOptimizer[float32](1, nil, nil); err == nil {
+		t.Error("expected error for nil constructor")
+	}
+}
@@ -80,9 +80,13 @@ func (s *SGD[T]) Clip(ctx context.Context, params []*graph.Parameter[T], thresho
 	}
 }
 
-// Step updates the parameters based on their gradients.
+// Step updates the parameters based on their gradients. Parameters without a
+// gradient, such as fitted statistics, are left unchanged.
 func (s *SGD[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
 	for _, p := range params {
+		if p.Gradient == nil {
+			continue
+		}
 		// scaled_grad = learning_rate * gradient
 		scaledGrad, err := s.engine.MulScalar(ctx, p.Gradient, s.learningRate)
 		if err != nil {
@@ -106,5 +110,13 @@ func (s *SGD[T]) SetLR(lr T) {
 	s.learningRate = lr
 }
 
+// SetLRFloat64 sets the learning rate from a float64.
+func (s *SGD[T]) SetLRFloat64(lr float64) {
+	s.learningRate = s.ops.FromFloat64(lr)
+}
+
 // Statically assert that the type implements the Optimizer interface.
 var _ Optimizer[float32] = (*SGD[float32])(nil)
+
+// Statically assert that the type implements the LRSetter interface.
+var _ LRSetter = (*SGD[float32])(nil)
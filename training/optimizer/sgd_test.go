@@ -164,3 +164,20 @@ func TestSGD_Step_Error(t *testing.T) {
 		}
 	})
 }
+
+func TestSGD_SetLRFloat64(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	sgd := NewSGD[float32](engine, numeric.Float32Ops{}, 1.0)
+	sgd.SetLRFloat64(0.5)
+
+	value, _ := tensor.New[float32]([]int{2}, []float32{1, 2})
+	gradient, _ := tensor.New[float32]([]int{2}, []float32{2, 2})
+	param, _ := graph.NewParameter("param1", value, tensor.New[float32])
+	param.Gradient = gradient
+	if err := sgd.Step(context.Background(), []*graph.Parameter[float32]{param}); err != nil {
+		t.Fatal(err)
+	}
+	if got := param.Value.Data(); !reflect.DeepEqual(got, []float32{0, 1}) {
+		t.Errorf("values after step at LR 0.5 = %v, want [0 1]", got)
+	}
+}
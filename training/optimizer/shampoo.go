@@ -0,0 +1,310 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// ShampooOption configures a DiagonalShampoo optimizer.
+type ShampooOption func(*shampooConfig)
+
+type shampooConfig struct {
+	beta1, beta2 float64
+	eps          float64
+	weightDecay  float64
+}
+
+// WithShampooBetas sets the momentum decay applied to the preconditioned
+// gradient (beta1, default 0.9) and the decay of the gradient statistics
+// (beta2, default 0.999). beta2 = 1 sums the statistics over all steps, as
+// the original Shampoo and Adagrad do.
+func WithShampooBetas(beta1, beta2 float64) ShampooOption {
+	return func(c *shampooConfig) {
+		c.beta1, c.beta2 = beta1, beta2
+	}
+}
+
+// WithShampooEpsilon sets the value added to the statistics before they are
+// inverted (default 1e-8).
+func WithShampooEpsilon(eps float64) ShampooOption {
+	return func(c *shampooConfig) {
+		c.eps = eps
+	}
+}
+
+// WithShampooWeightDecay sets decoupled weight decay (default 0).
+func WithShampooWeightDecay(wd float64) ShampooOption {
+	return func(c *shampooConfig) {
+		c.weightDecay = wd
+	}
+}
+
+// DiagonalShampoo implements Shampoo (Gupta et al., 2018) with diagonal
+// Kronecker factors. Full Shampoo preconditions a [rows, cols] gradient G as
+// L^-1/4 G R^-1/4 with L = Σ G Gᵀ and R = Σ Gᵀ G, which costs a matrix
+// root per factor; keeping only the diagonals of L and R reduces the factors
+// to the row and column sums of G ⊙ G:
+//
+//	l = beta2*l + (1-beta2)*rowsum(G²)    [rows, 1]
+//	r = beta2*r + (1-beta2)*colsum(G²)    [1, cols]
+//	P = G ⊙ (l+eps)^-1/4 ⊙ (r+eps)^-1/4
+//	m = beta1*m + (1-beta1)*P
+//	theta = theta - lr*wd*theta - lr*m
+//
+// State is O(rows + cols) per matrix instead of Adam's O(rows * cols), and
+// every step is element-wise, so it runs on the CPU engine without matrix
+// roots. Parameters of rank above 2 are treated as [shape[0], rest];
+// vectors and scalars have a single factor and use (v+eps)^-1/2, the
+// one-factor Shampoo exponent, which makes them Adagrad/RMSProp updates.
+type DiagonalShampoo[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+	lr     float64
+	cfg    shampooConfig
+
+	state         map[*graph.Parameter[T]]*shampooState[T]
+	keepGradients bool // set by SetZeroGradOnStep(false)
+}
+
+// shampooState holds one parameter's statistics. For matrices left and
+// right are the row and column factors; vectors only use left, with the
+// parameter's shape.
+type shampooState[T tensor.Numeric] struct {
+	left, right *tensor.TensorNumeric[T]
+	momentum    *tensor.TensorNumeric[T]
+}
+
+// NewDiagonalShampoo creates a diagonal Shampoo optimizer with learning
+// rate lr.
+func NewDiagonalShampoo[T tensor.Numeric](engine compute.Engine[T], lr float64, opts ...ShampooOption) *DiagonalShampoo[T] {
+	cfg := shampooConfig{
+		beta1: 0.9,
+		beta2: 0.999,
+		eps:   1e-8,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &DiagonalShampoo[T]{
+		engine: engine,
+		lr:     lr,
+		cfg:    cfg,
+		state:  make(map[*graph.Parameter[T]]*shampooState[T]),
+	}
+}
+
+// SetLR sets the learning rate. This is typically called by a scheduler.
+func (s *DiagonalShampoo[T]) SetLR(lr T) {
+	s.lr = numericToFloat64(lr)
+}
+
+// SetLRFloat64 sets the learning rate in full float64 precision.
+func (s *DiagonalShampoo[T]) SetLRFloat64(lr float64) {
+	s.lr = lr
+}
+
+// SetZeroGradOnStep controls whether Step zeroes each parameter's gradient
+// after updating it (the default).
+func (s *DiagonalShampoo[T]) SetZeroGradOnStep(zero bool) {
+	s.keepGradients = !zero
+}
+
+// Step updates the parameters based on their gradients.
+func (s *DiagonalShampoo[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
+	ops := s.engine.Ops()
+	b1 := ops.FromFloat64(s.cfg.beta1)
+	oneMinusB1 := ops.FromFloat64(1 - s.cfg.beta1)
+	lr := ops.FromFloat64(s.lr)
+	decay := ops.FromFloat64(1 - s.lr*s.cfg.weightDecay)
+
+	for _, param := range params {
+		grad := param.Gradient
+		if grad == nil {
+			continue
+		}
+		// A resized parameter, e.g. one widened during progressive growth,
+		// restarts from fresh state.
+		st, ok := s.state[param]
+		if !ok || (st.momentum != nil && !tensor.ShapesEqual(st.momentum.Shape(), param.Value.Shape())) {
+			st = &shampooState[T]{}
+			s.state[param] = st
+		}
+
+		precond, err := s.precondition(ctx, st, grad)
+		if err != nil {
+			return err
+		}
+
+		// m = beta1 * m + (1 - beta1) * P
+		m, err := s.engine.MulScalar(ctx, precond, oneMinusB1)
+		if err != nil {
+			return err
+		}
+		if st.momentum != nil {
+			prev, err := s.engine.MulScalar(ctx, st.momentum, b1)
+			if err != nil {
+				return err
+			}
+			if m, err = s.engine.Add(ctx, prev, m); err != nil {
+				return err
+			}
+		}
+		st.momentum = m
+
+		// param = (1 - lr * wd) * param - lr * m
+		update, err := s.engine.MulScalar(ctx, m, lr)
+		if err != nil {
+			return err
+		}
+		decayed, err := s.engine.MulScalar(ctx, param.Value, decay)
+		if err != nil {
+			return err
+		}
+		if param.Value, err = s.engine.Sub(ctx, decayed, update); err != nil {
+			return err
+		}
+
+		if s.keepGradients {
+			continue
+		}
+		var zero T
+		if err := s.engine.Fill(ctx, param.Gradient, zero); err != nil {
+			param.ClearGradient()
+		}
+	}
+	return nil
+}
+
+// precondition updates st's statistics with grad and returns the
+// preconditioned gradient, with grad's shape.
+func (s *DiagonalShampoo[T]) precondition(ctx context.Context, st *shampooState[T], grad *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	shape := grad.Shape()
+	sq, err := s.engine.Mul(ctx, grad, grad)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shape) < 2 {
+		if st.left, err = s.decayStat(ctx, st.left, sq); err != nil {
+			return nil, err
+		}
+		scale, err := s.inverseRoot(ctx, st.left, false)
+		if err != nil {
+			return nil, err
+		}
+		return s.engine.Mul(ctx, grad, scale)
+	}
+
+	rows := shape[0]
+	g2, err := s.engine.Reshape(ctx, grad, []int{rows, grad.Size() / rows})
+	if err != nil {
+		return nil, err
+	}
+	sq2, err := s.engine.Reshape(ctx, sq, g2.Shape())
+	if err != nil {
+		return nil, err
+	}
+	rowSum, err := s.engine.Sum(ctx, sq2, 1, true)
+	if err != nil {
+		return nil, err
+	}
+	colSum, err := s.engine.Sum(ctx, sq2, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	if st.left, err = s.decayStat(ctx, st.left, rowSum); err != nil {
+		return nil, err
+	}
+	if st.right, err = s.decayStat(ctx, st.right, colSum); err != nil {
+		return nil, err
+	}
+	left, err := s.inverseRoot(ctx, st.left, true)
+	if err != nil {
+		return nil, err
+	}
+	right, err := s.inverseRoot(ctx, st.right, true)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.engine.Mul(ctx, g2, left)
+	if err != nil {
+		return nil, err
+	}
+	if p, err = s.engine.Mul(ctx, p, right); err != nil {
+		return nil, err
+	}
+	return s.engine.Reshape(ctx, p, shape)
+}
+
+// decayStat returns beta2*stat + (1-beta2)*x, or stat + x when beta2 is 1.
+// A nil stat starts from zero.
+func (s *DiagonalShampoo[T]) decayStat(ctx context.Context, stat, x *tensor.TensorNumeric[T]) (*tensor.TensorNumeric[T], error) {
+	ops := s.engine.Ops()
+	if s.cfg.beta2 < 1 {
+		var err error
+		if x, err = s.engine.MulScalar(ctx, x, ops.FromFloat64(1-s.cfg.beta2)); err != nil {
+			return nil, err
+		}
+	}
+	if stat == nil {
+		return x, nil
+	}
+	if s.cfg.beta2 < 1 {
+		var err error
+		if stat, err = s.engine.MulScalar(ctx, stat, ops.FromFloat64(s.cfg.beta2)); err != nil {
+			return nil, err
+		}
+	}
+	return s.engine.Add(ctx, stat, x)
+}
+
+// inverseRoot returns (stat+eps)^-1/4 when quarter is set, else
+// (stat+eps)^-1/2.
+func (s *DiagonalShampoo[T]) inverseRoot(ctx context.Context, stat *tensor.TensorNumeric[T], quarter bool) (*tensor.TensorNumeric[T], error) {
+	x, err := s.engine.AddScalar(ctx, stat, s.engine.Ops().FromFloat64(s.cfg.eps))
+	if err != nil {
+		return nil, err
+	}
+	if quarter {
+		if x, err = s.engine.Sqrt(ctx, x); err != nil {
+			return nil, err
+		}
+	}
+	return s.engine.Rsqrt(ctx, x)
+}
+
+// MigrateState moves the optimizer state of from to to and drops it from
+// from. It is an error for to to have a different shape than the state.
+func (s *DiagonalShampoo[T]) MigrateState(from, to *graph.Parameter[T]) error {
+	if from == to {
+		return nil
+	}
+	if st, ok := s.state[from]; ok && st.momentum != nil && !tensor.ShapesEqual(st.momentum.Shape(), to.Value.Shape()) {
+		return fmt.Errorf("shampoo: cannot migrate state of %q with shape %v to %q with shape %v",
+			from.Name, st.momentum.Shape(), to.Name, to.Value.Shape())
+	}
+	moveKey(s.state, from, to)
+	return nil
+}
+
+// ResetState drops the statistics and momentum of params.
+func (s *DiagonalShampoo[T]) ResetState(params ...*graph.Parameter[T]) {
+	for _, p := range params {
+		delete(s.state, p)
+	}
+}
+
+// Statically assert that DiagonalShampoo implements the Optimizer interface.
+var _ Optimizer[float32] = (*DiagonalShampoo[float32])(nil)
+
+// Statically assert that DiagonalShampoo implements the GradientZeroer interface.
+var _ GradientZeroer = (*DiagonalShampoo[float32])(nil)
+
+// Statically assert that DiagonalShampoo implements the StateMigrator interface.
+var _ StateMigrator[float32] = (*DiagonalShampoo[float32])(nil)
+
+// Statically assert that DiagonalShampoo implements the LRSetter interface.
+var _ LRSetter = (*DiagonalShampoo[float32])(nil)
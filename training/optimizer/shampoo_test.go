@@ -0,0 +1,84 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+func TestDiagonalShampoo_FirstStep(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	s := NewDiagonalShampoo[float32](engine, 1, WithShampooBetas(0, 1), WithShampooEpsilon(0))
+
+	g := []float32{1, 2, 3, 4, 5, 6}
+	p := newGradParam(t, "w", g)
+	var err error
+	p.Value, err = tensor.New[float32]([]int{2, 3}, make([]float32, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Gradient, err = tensor.New[float32]([]int{2, 3}, slices.Clone(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Step(context.Background(), []*graph.Parameter[float32]{p}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without momentum or decay the step is -G ⊙ l^-1/4 ⊙ r^-1/4 with l the
+	// row sums and r the column sums of G².
+	rows := []float64{1 + 4 + 9, 16 + 25 + 36}
+	cols := []float64{1 + 16, 4 + 25, 9 + 36}
+	if got := p.Value.Shape(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("value shape = %v, want [2 3]", got)
+	}
+	for i, v := range p.Value.Data() {
+		want := -float64(g[i]) / math.Pow(rows[i/3]*cols[i%3], 0.25)
+		if math.Abs(float64(v)-want) > 1e-5 {
+			t.Errorf("value[%d] = %v, want %v", i, v, want)
+		}
+	}
+}
+
+func TestDiagonalShampoo_VectorIsAdagrad(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	s := NewDiagonalShampoo[float32](engine, 0.5, WithShampooBetas(0, 1), WithShampooEpsilon(0))
+	p := newGradParam(t, "b", []float32{2, -3})
+	if err := s.Step(context.Background(), []*graph.Parameter[float32]{p}); err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{-0.5, 0.5}
+	for i, v := range p.Value.Data() {
+		if math.Abs(float64(v-want[i])) > 1e-6 {
+			t.Errorf("value[%d] = %v, want %v", i, v, want[i])
+		}
+	}
+}
+
+func TestDiagonalShampoo_ConvergesOnQuadratic(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	q := newQuadratic(t, []float32{100, 1, 0.1}, []float32{1, 1, 1})
+	params := []*graph.Parameter[float32]{q.p}
+	s := NewDiagonalShampoo[float32](engine, 0.05)
+
+	for range 500 {
+		if err := q.grad(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Step(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, v := range q.p.Value.Data() {
+		if math.Abs(float64(v)) > 0.05 {
+			t.Errorf("x[%d] = %v, want near 0", i, v)
+		}
+	}
+}
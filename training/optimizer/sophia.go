@@ -0,0 +1,283 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// SophiaOption configures a Sophia optimizer.
+type SophiaOption func(*sophiaConfig)
+
+type sophiaConfig struct {
+	beta1, beta2 float64
+	rho          float64
+	eps          float64
+	weightDecay  float64
+}
+
+// WithSophiaBetas sets the decay rates of the gradient momentum (beta1,
+// default 0.965) and of the Hessian estimate (beta2, default 0.99).
+func WithSophiaBetas(beta1, beta2 float64) SophiaOption {
+	return func(c *sophiaConfig) {
+		c.beta1, c.beta2 = beta1, beta2
+	}
+}
+
+// WithSophiaRho sets the scale rho applied to the Hessian estimate before
+// clipping (default 0.04). Larger values take smaller, more curvature-bound
+// steps; smaller values clip more coordinates to a sign update.
+func WithSophiaRho(rho float64) SophiaOption {
+	return func(c *sophiaConfig) {
+		c.rho = rho
+	}
+}
+
+// WithSophiaEpsilon sets the floor applied to rho*h (default 1e-12), which
+// bounds the step where the Hessian estimate is zero or negative.
+func WithSophiaEpsilon(eps float64) SophiaOption {
+	return func(c *sophiaConfig) {
+		c.eps = eps
+	}
+}
+
+// WithSophiaWeightDecay sets decoupled weight decay (default 0.1).
+func WithSophiaWeightDecay(wd float64) SophiaOption {
+	return func(c *sophiaConfig) {
+		c.weightDecay = wd
+	}
+}
+
+// Sophia implements the Sophia optimizer (Liu et al., 2023), a light-weight
+// second-order method that preconditions the gradient momentum with an
+// exponential moving average h of a diagonal Hessian estimate and clips the
+// result element-wise:
+//
+//	m     = beta1*m + (1-beta1)*g
+//	theta = theta - lr*wd*theta
+//	theta = theta - lr*clip(m / max(rho*h, eps), 1)
+//
+// The clip bounds every coordinate's step by lr, so a stale or negative
+// curvature estimate degrades to a sign-momentum update instead of
+// diverging. Step never estimates the Hessian itself: refresh h every k
+// steps (k = 10 in the paper) by passing an estimate to UpdateHessian, from
+// GNBHessian for Sophia-G or HutchinsonHessian for Sophia-H. Until the first
+// UpdateHessian, h is zero and Step takes clipped sign-momentum steps.
+type Sophia[T tensor.Numeric] struct {
+	engine compute.Engine[T]
+	lr     float64
+	cfg    sophiaConfig
+
+	m, h          map[*graph.Parameter[T]]*tensor.TensorNumeric[T]
+	keepGradients bool // set by SetZeroGradOnStep(false)
+}
+
+// NewSophia creates a Sophia optimizer with learning rate lr.
+func NewSophia[T tensor.Numeric](engine compute.Engine[T], lr float64, opts ...SophiaOption) *Sophia[T] {
+	cfg := sophiaConfig{
+		beta1:       0.965,
+		beta2:       0.99,
+		rho:         0.04,
+		eps:         1e-12,
+		weightDecay: 0.1,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Sophia[T]{
+		engine: engine,
+		lr:     lr,
+		cfg:    cfg,
+		m:      make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T]),
+		h:      make(map[*graph.Parameter[T]]*tensor.TensorNumeric[T]),
+	}
+}
+
+// SetLR sets the learning rate. This is typically called by a scheduler.
+func (s *Sophia[T]) SetLR(lr T) {
+	s.lr = numericToFloat64(lr)
+}
+
+// SetLRFloat64 sets the learning rate in full float64 precision.
+func (s *Sophia[T]) SetLRFloat64(lr float64) {
+	s.lr = lr
+}
+
+// SetZeroGradOnStep controls whether Step zeroes each parameter's gradient
+// after updating it (the default).
+func (s *Sophia[T]) SetZeroGradOnStep(zero bool) {
+	s.keepGradients = !zero
+}
+
+// UpdateHessian folds a diagonal Hessian estimate into the moving average:
+// h = beta2*h + (1-beta2)*estimate. Parameters missing from estimates keep
+// their current h.
+func (s *Sophia[T]) UpdateHessian(ctx context.Context, estimates map[*graph.Parameter[T]]*tensor.TensorNumeric[T]) error {
+	ops := s.engine.Ops()
+	b2 := ops.FromFloat64(s.cfg.beta2)
+	oneMinusB2 := ops.FromFloat64(1 - s.cfg.beta2)
+	for p, est := range estimates {
+		if !tensor.ShapesEqual(est.Shape(), p.Value.Shape()) {
+			return fmt.Errorf("sophia: Hessian estimate shape %v does not match parameter %q shape %v",
+				est.Shape(), p.Name, p.Value.Shape())
+		}
+		scaled, err := s.engine.MulScalar(ctx, est, oneMinusB2)
+		if err != nil {
+			return err
+		}
+		h, ok := s.h[p]
+		if !ok {
+			s.h[p] = scaled
+			continue
+		}
+		decayed, err := s.engine.MulScalar(ctx, h, b2)
+		if err != nil {
+			return err
+		}
+		if s.h[p], err = s.engine.Add(ctx, decayed, scaled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step updates the parameters based on their gradients and the current
+// Hessian estimate.
+func (s *Sophia[T]) Step(ctx context.Context, params []*graph.Parameter[T]) error {
+	ops := s.engine.Ops()
+	b1 := ops.FromFloat64(s.cfg.beta1)
+	oneMinusB1 := ops.FromFloat64(1 - s.cfg.beta1)
+	rho := ops.FromFloat64(s.cfg.rho)
+	eps := ops.FromFloat64(s.cfg.eps)
+	lr := ops.FromFloat64(s.lr)
+	decay := ops.FromFloat64(1 - s.lr*s.cfg.weightDecay)
+	one := ops.One()
+	negOne := ops.FromFloat64(-1)
+
+	for _, param := range params {
+		grad := param.Gradient
+		if grad == nil {
+			continue
+		}
+		// A resized parameter, e.g. one widened during progressive growth,
+		// restarts from fresh state.
+		if prev, ok := s.m[param]; ok && !tensor.ShapesEqual(prev.Shape(), param.Value.Shape()) {
+			s.ResetState(param)
+		}
+
+		// m = beta1 * m + (1 - beta1) * grad
+		m, err := s.engine.MulScalar(ctx, grad, oneMinusB1)
+		if err != nil {
+			return err
+		}
+		if prev, ok := s.m[param]; ok {
+			prevScaled, err := s.engine.MulScalar(ctx, prev, b1)
+			if err != nil {
+				return err
+			}
+			if m, err = s.engine.Add(ctx, prevScaled, m); err != nil {
+				return err
+			}
+		}
+		s.m[param] = m
+
+		// ratio = clip(m / max(rho * h, eps), 1)
+		var denom *tensor.TensorNumeric[T]
+		if h, ok := s.h[param]; ok {
+			if denom, err = s.engine.MulScalar(ctx, h, rho); err != nil {
+				return err
+			}
+		} else {
+			if denom, err = tensor.New[T](param.Value.Shape(), nil); err != nil {
+				return err
+			}
+		}
+		denom, err = s.engine.UnaryOp(ctx, denom, func(x T) T {
+			if ops.GreaterThan(eps, x) {
+				return eps
+			}
+			return x
+		})
+		if err != nil {
+			return err
+		}
+		ratio, err := s.engine.Div(ctx, m, denom)
+		if err != nil {
+			return err
+		}
+		ratio, err = s.engine.UnaryOp(ctx, ratio, func(x T) T {
+			switch {
+			case ops.GreaterThan(x, one):
+				return one
+			case ops.GreaterThan(negOne, x):
+				return negOne
+			}
+			return x
+		})
+		if err != nil {
+			return err
+		}
+		update, err := s.engine.MulScalar(ctx, ratio, lr)
+		if err != nil {
+			return err
+		}
+
+		// param = (1 - lr * wd) * param - update
+		decayed, err := s.engine.MulScalar(ctx, param.Value, decay)
+		if err != nil {
+			return err
+		}
+		if param.Value, err = s.engine.Sub(ctx, decayed, update); err != nil {
+			return err
+		}
+
+		if s.keepGradients {
+			continue
+		}
+		var zero T
+		if err := s.engine.Fill(ctx, param.Gradient, zero); err != nil {
+			param.ClearGradient()
+		}
+	}
+	return nil
+}
+
+// MigrateState moves the optimizer state of from to to and drops it from
+// from. It is an error for to to have a different shape than the state.
+func (s *Sophia[T]) MigrateState(from, to *graph.Parameter[T]) error {
+	if from == to {
+		return nil
+	}
+	for _, st := range []*tensor.TensorNumeric[T]{s.m[from], s.h[from]} {
+		if st != nil && !tensor.ShapesEqual(st.Shape(), to.Value.Shape()) {
+			return fmt.Errorf("sophia: cannot migrate state of %q with shape %v to %q with shape %v",
+				from.Name, st.Shape(), to.Name, to.Value.Shape())
+		}
+	}
+	moveKey(s.m, from, to)
+	moveKey(s.h, from, to)
+	return nil
+}
+
+// ResetState drops the momentum and Hessian estimate of params.
+func (s *Sophia[T]) ResetState(params ...*graph.Parameter[T]) {
+	for _, p := range params {
+		delete(s.m, p)
+		delete(s.h, p)
+	}
+}
+
+// Statically assert that Sophia implements the Optimizer interface.
+var _ Optimizer[float32] = (*Sophia[float32])(nil)
+
+// Statically assert that Sophia implements the GradientZeroer interface.
+var _ GradientZeroer = (*Sophia[float32])(nil)
+
+// Statically assert that Sophia implements the StateMigrator interface.
+var _ StateMigrator[float32] = (*Sophia[float32])(nil)
+
+// Statically assert that Sophia implements the LRSetter interface.
+var _ LRSetter = (*Sophia[float32])(nil)
@@ -0,0 +1,141 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+// quadratic is f(x) = ½ Σ a_i x_i², whose Hessian is diag(a).
+type quadratic struct {
+	a []float32
+	p *graph.Parameter[float32]
+}
+
+func newQuadratic(t *testing.T, a, x []float32) *quadratic {
+	t.Helper()
+	p := newGradParam(t, "x", make([]float32, len(a)))
+	copy(p.Value.Data(), x)
+	return &quadratic{a: a, p: p}
+}
+
+// grad writes ∇f = a ⊙ x into the parameter's gradient.
+func (q *quadratic) grad(context.Context) error {
+	g := q.p.Gradient.Data()
+	for i, x := range q.p.Value.Data() {
+		g[i] = q.a[i] * x
+	}
+	return nil
+}
+
+func TestSophia_ClipsToSignStepWithoutHessian(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	s := NewSophia[float32](engine, 0.1, WithSophiaWeightDecay(0))
+	p := newGradParam(t, "p", []float32{3, -0.001, 0})
+	if err := s.Step(context.Background(), []*graph.Parameter[float32]{p}); err != nil {
+		t.Fatal(err)
+	}
+	// With h = 0 every non-zero ratio is clipped, so each coordinate moves
+	// by exactly lr against its gradient.
+	want := []float32{-0.1, 0.1, 0}
+	for i, v := range p.Value.Data() {
+		if math.Abs(float64(v-want[i])) > 1e-6 {
+			t.Errorf("value[%d] = %v, want %v", i, v, want[i])
+		}
+	}
+	for i, v := range p.Gradient.Data() {
+		if v != 0 {
+			t.Errorf("gradient[%d] = %v after Step, want 0", i, v)
+		}
+	}
+}
+
+func TestHutchinsonHessian_Quadratic(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	a := []float32{4, 1, 0.25}
+	x := []float32{1, -2, 3}
+	q := newQuadratic(t, a, x)
+	params := []*graph.Parameter[float32]{q.p}
+
+	est, err := HutchinsonHessian(ctx, engine, params, q.grad, 3, 1e-2, rand.New(rand.NewPCG(1, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// For a diagonal Hessian u ⊙ H u = a ⊙ u² = a exactly for every
+	// Rademacher probe.
+	for i, v := range est[q.p].Data() {
+		if math.Abs(float64(v-a[i])) > 1e-3 {
+			t.Errorf("estimate[%d] = %v, want %v", i, v, a[i])
+		}
+	}
+	for i, v := range q.p.Value.Data() {
+		if v != x[i] {
+			t.Errorf("value[%d] = %v after estimate, want %v restored", i, v, x[i])
+		}
+	}
+	for i, g := range q.p.Gradient.Data() {
+		if want := a[i] * x[i]; math.Abs(float64(g-want)) > 1e-6 {
+			t.Errorf("gradient[%d] = %v after estimate, want ∇f = %v", i, g, want)
+		}
+	}
+
+	if _, err := HutchinsonHessian(ctx, engine, params, q.grad, 0, 1e-2, rand.New(rand.NewPCG(1, 2))); err == nil {
+		t.Error("expected error for zero probes")
+	}
+}
+
+func TestGNBHessian(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	p := newGradParam(t, "p", []float32{1, -2, 0.5})
+	est, err := GNBHessian(context.Background(), engine, []*graph.Parameter[float32]{p}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{4, 16, 1}
+	for i, v := range est[p].Data() {
+		if v != want[i] {
+			t.Errorf("estimate[%d] = %v, want %v", i, v, want[i])
+		}
+	}
+}
+
+// TestSophia_ConvergesOnIllConditionedQuadratic minimizes a quadratic whose
+// curvatures span three orders of magnitude, refreshing the Hessian
+// estimate every few steps as Sophia-H does.
+func TestSophia_ConvergesOnIllConditionedQuadratic(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	q := newQuadratic(t, []float32{100, 1, 0.1}, []float32{1, 1, 1})
+	params := []*graph.Parameter[float32]{q.p}
+	s := NewSophia[float32](engine, 0.05, WithSophiaWeightDecay(0), WithSophiaRho(1))
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	for step := range 300 {
+		if step%10 == 0 {
+			est, err := HutchinsonHessian(ctx, engine, params, q.grad, 1, 1e-2, rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.UpdateHessian(ctx, est); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := q.grad(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Step(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, v := range q.p.Value.Data() {
+		if math.Abs(float64(v)) > 0.05 {
+			t.Errorf("x[%d] = %v, want near 0", i, v)
+		}
+	}
+}
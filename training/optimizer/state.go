@@ -0,0 +1,112 @@
+package optimizer
+
+import (
+	"fmt"
+
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// StateMigrator is implemented by optimizers that keep per-parameter state,
+// such as moment estimates. Optimizers key that state by parameter, so a
+// parameter that first appears mid-training (a block added during
+// progressive growth) simply gets fresh state on its first Step; a
+// parameter that is replaced by a new one (renamed, or recreated when the
+// model is rebuilt) needs MigrateState to keep its state.
+type StateMigrator[T tensor.Numeric] interface {
+	// MigrateState moves from's state to to and drops it from from.
+	MigrateState(from, to *graph.Parameter[T]) error
+	// ResetState drops the state of params, which restart as if new.
+	ResetState(params ...*graph.Parameter[T])
+}
+
+// MigrateStateByName carries optimizer state from the parameters of a model
+// over to the parameters of its rebuilt, possibly grown, successor. Each new
+// parameter takes the state of the old parameter with the same name, or,
+// for old names listed in renames, of the old parameter renamed to it.
+// State whose shape no longer fits (a widened layer) is reset, as is the
+// state of old parameters left without a successor; new parameters without
+// a predecessor get fresh state on their first Step.
+func MigrateStateByName[T tensor.Numeric](o StateMigrator[T], oldParams, newParams []*graph.Parameter[T], renames map[string]string) error {
+	byName := make(map[string]*graph.Parameter[T], len(oldParams))
+	for _, p := range oldParams {
+		name := p.Name
+		if r, ok := renames[name]; ok {
+			name = r
+		}
+		byName[name] = p
+	}
+	matched := make(map[*graph.Parameter[T]]bool, len(newParams))
+	for _, p := range newParams {
+		old, ok := byName[p.Name]
+		if !ok {
+			continue
+		}
+		matched[old] = true
+		if old == p {
+			continue
+		}
+		if !tensor.ShapesEqual(old.Value.Shape(), p.Value.Shape()) {
+			o.ResetState(old, p)
+			continue
+		}
+		if err := o.MigrateState(old, p); err != nil {
+			return fmt.Errorf("migrate optimizer state of %q to %q: %w", old.Name, p.Name, err)
+		}
+	}
+	for _, p := range oldParams {
+		if !matched[p] {
+			o.ResetState(p)
+		}
+	}
+	return nil
+}
+
+// moveKey moves m[from] to m[to], deleting to's entry when from has none.
+func moveKey[K comparable, V any](m map[K]V, from, to K) {
+	v, ok := m[from]
+	delete(m, from)
+	if ok {
+		m[to] = v
+	} else {
+		delete(m, to)
+	}
+}
+
+// State is an optimizer's state in a portable form, for checkpoints. It is
+// keyed by parameter name, so it can be loaded into the parameters of a
+// freshly built model. Values are float64, which holds every element type
+// exactly.
+type State struct {
+	// Step is the optimizer's timestep.
+	Step int
+	// Slots holds per-parameter tensors by slot, such as AdamW's "m" and
+	// "v", then by parameter name.
+	Slots map[string]map[string][]float64
+	// ParamSteps holds the number of steps each parameter has taken, which
+	// is less than Step for a parameter added mid-training.
+	ParamSteps map[string]int
+}
+
+// StateSaver is implemented by optimizers whose state can be saved to and
+// restored from a checkpoint.
+type StateSaver[T tensor.Numeric] interface {
+	// SaveState returns the state of params.
+	SaveState(params []*graph.Parameter[T]) (*State, error)
+	// LoadState replaces the state of params with s. Parameters s has no
+	// state for restart as if new.
+	LoadState(params []*graph.Parameter[T], s *State) error
+}
+
+// paramsByName indexes params by name. Shared parameters may repeat; it is
+// an error for distinct parameters to share a name.
+func paramsByName[T tensor.Numeric](params []*graph.Parameter[T]) (map[string]*graph.Parameter[T], error) {
+	byName := make(map[string]*graph.Parameter[T], len(params))
+	for _, p := range params {
+		if q, ok := byName[p.Name]; ok && q != p {
+			return nil, fmt.Errorf("two parameters are named %q", p.Name)
+		}
+		byName[p.Name] = p
+	}
+	return byName, nil
+}
@@ -0,0 +1,196 @@
+package optimizer
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+type statefulOptimizer interface {
+	Optimizer[float32]
+	StateMigrator[float32]
+}
+
+func statefulOptimizers() map[string]func() statefulOptimizer {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	return map[string]func() statefulOptimizer{
+		"AdamW":     func() statefulOptimizer { return NewAdamW[float32](engine, 0.1, 0.9, 0.999, 1e-8, 0.01) },
+		"AdamW8bit": func() statefulOptimizer { return NewAdamW8bit[float32](engine, 0.1, 0.9, 0.999, 1e-8, 0.01) },
+		"Sophia":    func() statefulOptimizer { return NewSophia[float32](engine, 0.1) },
+		"Shampoo":   func() statefulOptimizer { return NewDiagonalShampoo[float32](engine, 0.1) },
+	}
+}
+
+// stepWith sets each parameter's gradient to grad and steps o.
+func stepWith(t *testing.T, o Optimizer[float32], grad []float32, params ...*graph.Parameter[float32]) {
+	t.Helper()
+	for _, p := range params {
+		g, err := tensor.New[float32](p.Value.Shape(), slices.Clone(grad[:p.Value.Size()]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Gradient = g
+	}
+	if err := o.Step(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOptimizer_ParameterAddedMidTraining checks that a parameter first
+// stepped after others have trained is updated exactly as a fresh
+// optimizer would update it.
+func TestOptimizer_ParameterAddedMidTraining(t *testing.T) {
+	grad := []float32{0.5, -1, 2, 0.25}
+	for name, newOpt := range statefulOptimizers() {
+		t.Run(name, func(t *testing.T) {
+			o := newOpt()
+			old := newGradParam(t, "old", make([]float32, 4))
+			for range 5 {
+				stepWith(t, o, grad, old)
+			}
+			added := newGradParam(t, "added", make([]float32, 4))
+			stepWith(t, o, grad, old, added)
+
+			want := newGradParam(t, "added", make([]float32, 4))
+			stepWith(t, newOpt(), grad, want)
+			assertValues(t, added, want.Value.Data())
+		})
+	}
+}
+
+// TestOptimizer_ParameterResized checks that a parameter whose value grows
+// restarts from fresh state instead of failing.
+func TestOptimizer_ParameterResized(t *testing.T) {
+	grad := []float32{0.5, -1, 2, 0.25, 1, -0.5}
+	for name, newOpt := range statefulOptimizers() {
+		t.Run(name, func(t *testing.T) {
+			o := newOpt()
+			p := newGradParam(t, "w", make([]float32, 4))
+			stepWith(t, o, grad, p)
+
+			grown, err := tensor.New[float32]([]int{6}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.Value = grown
+			stepWith(t, o, grad, p)
+
+			want := newGradParam(t, "w", make([]float32, 6))
+			stepWith(t, newOpt(), grad, want)
+			assertValues(t, p, want.Value.Data())
+		})
+	}
+}
+
+// TestMigrateStateByName rebuilds a model mid-training, renaming one
+// parameter, widening another and adding a third, and checks that the
+// carried-over parameter continues exactly where its predecessor was.
+func TestMigrateStateByName(t *testing.T) {
+	grad := []float32{0.5, -1, 2, 0.25, 1, -0.5}
+	for name, newOpt := range statefulOptimizers() {
+		t.Run(name, func(t *testing.T) {
+			o, ref := newOpt(), newOpt()
+			renamed := newGradParam(t, "blocks.0.w", make([]float32, 4))
+			widened := newGradParam(t, "head.w", make([]float32, 4))
+			dropped := newGradParam(t, "aux.w", make([]float32, 4))
+			refParam := newGradParam(t, "blocks.0.w", make([]float32, 4))
+			for range 3 {
+				stepWith(t, o, grad, renamed, widened, dropped)
+				stepWith(t, ref, grad, refParam)
+			}
+
+			moved := newGradParam(t, "blocks.1.w", make([]float32, 4))
+			copy(moved.Value.Data(), renamed.Value.Data())
+			wide := newGradParam(t, "head.w", make([]float32, 6))
+			added := newGradParam(t, "blocks.0.w", make([]float32, 4))
+			err := MigrateStateByName(o,
+				[]*graph.Parameter[float32]{renamed, widened, dropped},
+				[]*graph.Parameter[float32]{moved, wide, added},
+				map[string]string{"blocks.0.w": "blocks.1.w"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stepWith(t, o, grad, moved, wide, added)
+			stepWith(t, ref, grad, refParam)
+			assertValues(t, moved, refParam.Value.Data())
+
+			fresh := newGradParam(t, "fresh", make([]float32, 6))
+			stepWith(t, newOpt(), grad, fresh)
+			assertValues(t, wide, fresh.Value.Data())
+			assertValues(t, added, fresh.Value.Data()[:4])
+		})
+	}
+}
+
+func TestMigrateState_ShapeMismatch(t *testing.T) {
+	for name, newOpt := range statefulOptimizers() {
+		t.Run(name, func(t *testing.T) {
+			o := newOpt()
+			from := newGradParam(t, "a", make([]float32, 4))
+			stepWith(t, o, []float32{1, 1, 1, 1}, from)
+			to := newGradParam(t, "b", make([]float32, 3))
+			if err := o.MigrateState(from, to); err == nil {
+				t.Error("expected error migrating state to a parameter of another shape")
+			}
+		})
+	}
+}
+
+func assertValues(t *testing.T, p *graph.Parameter[float32], want []float32) {
+	t.Helper()
+	got := p.Value.Data()
+	if len(got) != len(want) {
+		t.Fatalf("%s has %d values, want %d", p.Name, len(got), len(want))
+	}
+	for i := range got {
+		if d := got[i] - want[i]; d > 1e-6 || d < -1e-6 {
+			t.Fatalf("%s[%d] = %v, want %v", p.Name, i, got[i], want[i])
+		}
+	}
+}
+
+// TestAdamW_SaveLoadState checks that an optimizer loaded with saved state
+// continues exactly as the one it was saved from.
+func TestAdamW_SaveLoadState(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	newOpt := func() *AdamW[float32] { return NewAdamW[float32](engine, 0.1, 0.9, 0.999, 1e-8, 0.01) }
+	grad := []float32{0.5, -1, 2, 0.25}
+
+	o := newOpt()
+	p := newGradParam(t, "w", make([]float32, 4))
+	for range 3 {
+		stepWith(t, o, grad, p)
+	}
+	s, err := o.SaveState([]*graph.Parameter[float32]{p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Step != 3 || s.ParamSteps["w"] != 3 || len(s.Slots["m"]["w"]) != 4 {
+		t.Fatalf("state = %+v", s)
+	}
+
+	restored := newOpt()
+	q := newGradParam(t, "w", make([]float32, 4))
+	copy(q.Value.Data(), p.Value.Data())
+	if err := restored.LoadState([]*graph.Parameter[float32]{q}, s); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		stepWith(t, o, grad, p)
+		stepWith(t, restored, grad, q)
+	}
+	if !slices.Equal(p.Value.Data(), q.Value.Data()) {
+		t.Errorf("restored = %v, want %v", q.Value.Data(), p.Value.Data())
+	}
+
+	other := newGradParam(t, "b", make([]float32, 4))
+	if err := newOpt().LoadState([]*graph.Parameter[float32]{other}, s); err == nil {
+		t.Error("expected an error for state of an unknown parameter")
+	}
+}
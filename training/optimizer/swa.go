@@ -108,6 +108,22 @@ func (s *SWA[T]) SwapWeights(ctx context.Context, params []*graph.Parameter[T])
 	return nil
 }
 
+// ResetToAverage overwrites param.Value with the averaged weights, keeping
+// the running average as is. Used for Polyak-average restarts, e.g. at an
+// SGDR cycle boundary, where training continues from the averaged point.
+func (s *SWA[T]) ResetToAverage(ctx context.Context, params []*graph.Parameter[T]) error {
+	for _, param := range params {
+		avg, ok := s.avgParams[param]
+		if !ok || param.Value == nil {
+			continue
+		}
+		if err := s.engine.Copy(ctx, param.Value, avg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NAveraged returns the number of checkpoints averaged so far.
 func (s *SWA[T]) NAveraged() int {
 	return s.nAveraged
@@ -161,3 +161,28 @@ func TestSWA_NAveraged_Increments(t *testing.T) {
 		t.Errorf("final NAveraged = %d, want 5", got)
 	}
 }
+
+func TestSWA_ResetToAverage(t *testing.T) {
+	ctx := context.Background()
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	swa := NewSWA[float32](&noopOptimizer[float32]{}, engine, 0)
+
+	value, _ := tensor.New[float32]([]int{1}, []float32{2.0})
+	param, _ := graph.NewParameter("p", value, tensor.New[float32])
+	params := []*graph.Parameter[float32]{param}
+	for epoch, v := range []float32{2, 4, 6} {
+		param.Value.Data()[0] = v
+		if err := swa.UpdateAverage(ctx, params, epoch); err != nil {
+			t.Fatalf("UpdateAverage: %v", err)
+		}
+	}
+	if err := swa.ResetToAverage(ctx, params); err != nil {
+		t.Fatalf("ResetToAverage: %v", err)
+	}
+	if got := param.Value.Data()[0]; math.Abs(float64(got-4)) > 1e-6 {
+		t.Errorf("param = %v, want average 4", got)
+	}
+	if got := swa.avgParams[param].Data()[0]; math.Abs(float64(got-4)) > 1e-6 {
+		t.Errorf("average = %v, want unchanged 4", got)
+	}
+}
@@ -0,0 +1,32 @@
+package optimizer
+
+import (
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// GradientZeroer is implemented by optimizers that zero each parameter's
+// gradient at the end of Step, as AdamW and AdamW8bit do by default.
+// SetZeroGradOnStep(false) makes Step leave gradients untouched, handing
+// their lifecycle to the caller: gradients then accumulate across Backward
+// calls until ZeroGrad clears them.
+type GradientZeroer interface {
+	SetZeroGradOnStep(zero bool)
+}
+
+// ZeroGrad zeroes the gradient of every parameter in place. The gradient
+// keeps its storage buffer, so strategies that track gradient storage
+// identity, and callers holding persistent (non-arena) gradient buffers,
+// keep seeing the same tensor. Parameters without a gradient are skipped.
+func ZeroGrad[T tensor.Numeric](params []*graph.Parameter[T]) {
+	for _, p := range params {
+		if p.Gradient == nil {
+			continue
+		}
+		// Data is the backing slice on CPU storage and a host copy on GPU
+		// storage; Set writes the zeros back without reallocating either.
+		data := p.Gradient.Data()
+		clear(data)
+		p.Gradient.GetStorage().Set(data)
+	}
+}
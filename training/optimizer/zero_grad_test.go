@@ -0,0 +1,65 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+func TestZeroGrad(t *testing.T) {
+	p := newGradParam(t, "p", []float32{1, -2, 3})
+	grad := p.Gradient
+	noGrad := newGradParam(t, "p", []float32{1})
+	noGrad.Gradient = nil
+
+	ZeroGrad([]*graph.Parameter[float32]{p, noGrad})
+
+	if p.Gradient != grad {
+		t.Error("ZeroGrad replaced the gradient tensor, want it zeroed in place")
+	}
+	for i, v := range p.Gradient.Data() {
+		if v != 0 {
+			t.Errorf("gradient[%d] = %v, want 0", i, v)
+		}
+	}
+	if noGrad.Gradient != nil {
+		t.Error("ZeroGrad allocated a gradient for a parameter without one")
+	}
+}
+
+func TestSetZeroGradOnStep(t *testing.T) {
+	engine := compute.NewCPUEngine[float32](numeric.Float32Ops{})
+	optimizers := map[string]interface {
+		Optimizer[float32]
+		GradientZeroer
+	}{
+		"AdamW":     NewAdamW[float32](engine, 0.1, 0.9, 0.999, 1e-8, 0),
+		"AdamW8bit": NewAdamW8bit[float32](engine, 0.1, 0.9, 0.999, 1e-8, 0),
+	}
+	for name, o := range optimizers {
+		t.Run(name, func(t *testing.T) {
+			o.SetZeroGradOnStep(false)
+			p := newGradParam(t, "p", []float32{0.5, -0.5})
+			if err := o.Step(context.Background(), []*graph.Parameter[float32]{p}); err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Gradient.Data(); got[0] != 0.5 || got[1] != -0.5 {
+				t.Errorf("gradient after Step = %v, want it kept as [0.5 -0.5]", got)
+			}
+			if p.Value.Data()[0] == 0 {
+				t.Error("Step did not update the parameter")
+			}
+
+			o.SetZeroGradOnStep(true)
+			if err := o.Step(context.Background(), []*graph.Parameter[float32]{p}); err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Gradient.Data(); got[0] != 0 || got[1] != 0 {
+				t.Errorf("gradient after Step = %v, want zeroed", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package training
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/zerfoo/zerfoo/data/parquet"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// ParquetDataProviderName is the name the Parquet data provider is
+// registered under in Float32Registry and Float64Registry.
+const ParquetDataProviderName = "parquet"
+
+// ParquetConfig configures a ParquetDataProvider. Path is the Parquet file;
+// its schema names the columns.
+type ParquetConfig[T tensor.Numeric] = TableConfig[T]
+
+// ParquetDataProvider is a DataProvider streaming batches from a flat
+// Parquet file. Its iterators read one row group at a time and only the
+// feature, target, ID and group columns, so wide files and files larger
+// than memory stream in bounded memory. Feature and target columns must be
+// numeric; a null in one is an error.
+//
+// Splitting and shuffling follow CSVDataProvider, with rows without an ID
+// or group keyed by their row number. Its iterators are CSVIterators.
+type ParquetDataProvider[T tensor.Numeric] struct {
+	*tableData[T]
+}
+
+// NewParquetDataProvider returns a provider for config. It reads only the
+// footer of the file, to resolve the columns.
+func NewParquetDataProvider[T tensor.Numeric](config ParquetConfig[T]) (*ParquetDataProvider[T], error) {
+	var columns []parquet.Column
+	d, err := newTableData("parquet", config, func() ([]string, error) {
+		f, err := parquet.OpenFile(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		columns = f.Columns()
+		header := make([]string, len(columns))
+		for i, c := range columns {
+			header[i] = c.Name
+		}
+		return header, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range slices.Concat(d.features, d.targets) {
+		if !columns[c].Type.Numeric() {
+			return nil, fmt.Errorf("parquet data provider: %s: column %q of type %s is not numeric", config.Path, columns[c].Name, columns[c].Type)
+		}
+	}
+	cols := d.columns()
+	d.source = func() (rowSource, error) {
+		f, err := parquet.OpenFile(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		s := &parquetSource{file: f, columns: cols, slot: make([]int, len(columns))}
+		for i, c := range cols {
+			s.slot[c] = i
+		}
+		return s, nil
+	}
+	return &ParquetDataProvider[T]{d}, nil
+}
+
+// parquetSource is the rowSource of a Parquet file. It holds the projected
+// columns of one row group at a time.
+type parquetSource struct {
+	file    *parquet.File
+	columns []int
+	// slot maps a file column to its index in values.
+	slot   []int
+	group  int
+	values []*parquet.Values
+	row    int
+	rows   int
+	number int
+}
+
+func (s *parquetSource) next() (bool, error) {
+	s.row++
+	for s.row >= s.rows {
+		if s.group == s.file.NumRowGroups() {
+			return false, nil
+		}
+		values, err := s.file.ReadRowGroup(s.group, s.columns)
+		if err != nil {
+			return false, err
+		}
+		s.values, s.group, s.row, s.rows = values, s.group+1, 0, values[0].Len()
+	}
+	s.number++
+	return true, nil
+}
+
+func (s *parquetSource) text(c int) string {
+	return s.values[s.slot[c]].Text(s.row)
+}
+
+func (s *parquetSource) float(c int) (float64, error) {
+	v := s.values[s.slot[c]]
+	if v.IsNull(s.row) {
+		return 0, errors.New("null value")
+	}
+	return v.Float64(s.row), nil
+}
+
+// position returns the row number of the current row, counting from 1.
+func (s *parquetSource) position() string {
+	return strconv.Itoa(s.number)
+}
+
+func (s *parquetSource) rewind() error {
+	s.group, s.values, s.row, s.rows, s.number = 0, nil, 0, 0, 0
+	return nil
+}
+
+func (s *parquetSource) close() error {
+	return s.file.Close()
+}
+
+// newParquetDataProviderFactory returns the registry factory for the
+// Parquet data provider, configured by the keys of
+// newTableDataProviderFactory.
+func newParquetDataProviderFactory[T tensor.Numeric]() DataProviderFactory[T] {
+	return newTableDataProviderFactory("parquet", func(c TableConfig[T]) (DataProvider[T], error) {
+		return NewParquetDataProvider(c)
+	})
+}
+
+func init() {
+	_ = Float32Registry.RegisterDataProvider(ParquetDataProviderName, newParquetDataProviderFactory[float32]())
+	_ = Float64Registry.RegisterDataProvider(ParquetDataProviderName, newParquetDataProviderFactory[float64]())
+}
+
+// Statically assert that the type implements the DataProvider interface.
+var _ DataProvider[float32] = (*ParquetDataProvider[float32])(nil)
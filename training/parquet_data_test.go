@@ -0,0 +1,171 @@
+package training_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/data/parquet"
+	"github.com/zerfoo/zerfoo/training"
+)
+
+// writeRegressionParquet writes the rows of writeRegressionCSV to a Parquet
+// file in row groups of 16, with x1 a nullable float that is null in row
+// nullRow (none if negative), and returns the path.
+func writeRegressionParquet(t *testing.T, n, nullRow int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := parquet.NewWriter(f, []parquet.Column{
+		{Name: "id", Type: parquet.ByteArray, String: true},
+		{Name: "group", Type: parquet.ByteArray, String: true},
+		{Name: "x0", Type: parquet.Double},
+		{Name: "note", Type: parquet.ByteArray, String: true},
+		{Name: "x1", Type: parquet.Float, Optional: true},
+		{Name: "y", Type: parquet.Double},
+	}, parquet.WithRowGroupSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewPCG(1, 0))
+	for i := range n {
+		x0, x1 := rng.Float64()*2-1, float32(rng.Float64()*2-1)
+		var v any = x1
+		if i == nullRow {
+			v = nil
+		}
+		if err := w.Write(fmt.Sprintf("r%d", i), fmt.Sprintf("g%d", i/5), x0, "a, b", v, 2*x0-float64(x1)+0.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParquetDataProvider(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	path := writeRegressionParquet(t, 200, -1)
+	config := map[string]interface{}{
+		"path":             path,
+		"input":            rig.input,
+		"feature_columns":  []interface{}{"x0", "x1"},
+		"target_column":    "y",
+		"id_column":        "id",
+		"group_column":     "group",
+		"validation_ratio": 0.25,
+		"seed":             7,
+		"shuffle_buffer":   50,
+	}
+	data, err := training.Float32Registry.GetDataProvider(ctx, training.ParquetDataProviderName, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := training.BatchConfig{BatchSize: 16, Shuffle: true}
+	train, err := data.GetTrainingData(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = train.Close() }()
+	valid, err := data.GetValidationData(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = valid.Close() }()
+
+	// Groups split as in the CSV provider, across row group boundaries.
+	trainIDs, validIDs := readIDs(t, train), readIDs(t, valid)
+	if len(validIDs) < 20 || len(validIDs) > 80 || len(trainIDs)+len(validIDs) != 200 {
+		t.Fatalf("split %d training and %d validation rows of 200", len(trainIDs), len(validIDs))
+	}
+	csvData, err := training.Float32Registry.GetDataProvider(ctx, training.CSVDataProviderName,
+		map[string]interface{}{"path": writeRegressionCSV(t, 200), "input": rig.input, "target_column": "y",
+			"feature_columns": []string{"x0", "x1"}, "id_column": "id", "group_column": "group",
+			"validation_ratio": 0.25, "seed": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvValid, err := csvData.GetValidationData(ctx, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = csvValid.Close() }()
+	if got := readIDs(t, csvValid); !slices.Equal(got, validIDs) {
+		t.Errorf("validation rows differ from the same data as CSV")
+	}
+	if err := train.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if epoch1 := readIDs(t, train); slices.Equal(epoch1, trainIDs) || len(epoch1) != len(trainIDs) {
+		t.Error("Reset did not reshuffle the training rows")
+	}
+
+	w := newSGDWorkflow()
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 30, LearningRate: 0.1, BatchConfig: batch}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, data, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FinalLoss > 1e-3 {
+		t.Errorf("validation loss %v after 30 epochs, want below 1e-3", result.FinalLoss)
+	}
+}
+
+func TestParquetDataProvider_Errors(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	path := writeRegressionParquet(t, 40, 21)
+
+	// Without FeatureColumns the note column is a feature, and not numeric.
+	_, err := training.NewParquetDataProvider(training.ParquetConfig[float32]{
+		Path: path, Input: rig.input, TargetColumns: []string{"y"}, IDColumn: "id", GroupColumn: "group",
+	})
+	if err == nil || !strings.Contains(err.Error(), `column "note" of type BYTE_ARRAY is not numeric`) {
+		t.Errorf("err = %v, want the note column rejected", err)
+	}
+
+	// A null feature fails the batch holding it.
+	data, err := training.NewParquetDataProvider(training.ParquetConfig[float32]{
+		Path: path, Input: rig.input, FeatureColumns: []string{"x0", "x1"}, TargetColumns: []string{"y"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := data.GetTrainingData(ctx, training.BatchConfig{BatchSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = it.Close() }()
+	n := 0
+	for it.Next(ctx) {
+		n++
+	}
+	if n != 2 || it.Error() == nil || !strings.Contains(it.Error().Error(), `data.parquet:22: column "x1": null value`) {
+		t.Errorf("%d batches, Error() = %v, want a null error at row 22", n, it.Error())
+	}
+
+	for name, config := range map[string]training.ParquetConfig[float32]{
+		"missing column": {Path: path, Input: rig.input, TargetColumns: []string{"z"}},
+		"missing file":   {Path: path + ".missing", Input: rig.input, TargetColumns: []string{"y"}},
+		"not parquet":    {Path: writeRegressionCSV(t, 5), Input: rig.input, TargetColumns: []string{"y"}},
+	} {
+		if _, err := training.NewParquetDataProvider(config); err == nil {
+			t.Errorf("%s: NewParquetDataProvider succeeded", name)
+		}
+	}
+}
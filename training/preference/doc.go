@@ -0,0 +1,25 @@
+// Package preference fine-tunes models on pairwise human preferences: which
+// of two responses to a prompt is better. (Stability: alpha)
+//
+// A [Pair] holds a prompt and a chosen and a rejected response as token IDs.
+// Two trainers consume them:
+//
+//   - [RewardTrainer] fits a reward model that scores a response, with the
+//     Bradley-Terry loss.
+//   - [DPOTrainer] aligns a causal language model directly with Direct
+//     Preference Optimization, against a frozen reference model, without a
+//     reward model.
+//
+// Both shuffle the pairs with a seed, pad each batch with [TrainConfig]'s
+// PadID, and report the loss, accuracy and margin of every epoch:
+//
+//	dpo, err := preference.NewDPOTrainer(policy, nil,
+//	    optimizer.NewAdamWFromFloat64(engine, 1e-5, 0.9, 0.999, 1e-8, 0),
+//	    preference.DPOConfig{Beta: 0.1, TrainConfig: preference.TrainConfig{Epochs: 3, BatchSize: 16}})
+//	res, err := dpo.Train(ctx, pairs)
+//	fmt.Println(res.Final().Accuracy)
+//
+// With a nil reference the policy's log-probabilities before training serve
+// as the reference. Combined with LoRA adapters from training/lora, only the
+// adapter weights change.
+package preference
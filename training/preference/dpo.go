@@ -0,0 +1,129 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+const defaultBeta = 0.1
+
+// DPOConfig configures a DPOTrainer.
+type DPOConfig struct {
+	TrainConfig
+	// Beta scales the implicit reward beta * log(policy / reference) of a
+	// response. Larger values keep the policy closer to the reference.
+	// 0 means 0.1.
+	Beta float64
+}
+
+// DPOTrainer fine-tunes a causal language model on preference pairs with
+// Direct Preference Optimization (Rafailov et al., 2023). Each step it
+// raises the policy's log-probability of the chosen responses relative to
+// the rejected ones, measured against a frozen reference model, with the
+// loss -log sigmoid(beta * ((log pi(c) - log ref(c)) - (log pi(r) - log ref(r)))).
+// No reward model or sampling is involved.
+//
+// Both models take a [batch, length] matrix of token IDs and return logits
+// of shape [batch, length, vocab], the logits at position t predicting the
+// token at t+1. The reported margin is the implicit reward margin, and the
+// accuracy the fraction of pairs whose chosen response has the larger
+// implicit reward.
+type DPOTrainer[T tensor.Numeric] struct {
+	policy    *graph.Graph[T]
+	reference *graph.Graph[T]
+	opt       optimizer.Optimizer[T]
+	config    DPOConfig
+}
+
+// NewDPOTrainer returns a trainer updating policy with opt. A nil reference
+// uses the policy as it is when Train starts, which saves holding a second
+// copy of the model.
+func NewDPOTrainer[T tensor.Numeric](policy, reference *graph.Graph[T], opt optimizer.Optimizer[T], config DPOConfig) (*DPOTrainer[T], error) {
+	if policy == nil || opt == nil {
+		return nil, errors.New("preference: DPO needs a policy and an optimizer")
+	}
+	if config.Beta < 0 {
+		return nil, fmt.Errorf("preference: negative beta %g", config.Beta)
+	}
+	if config.Beta == 0 {
+		config.Beta = defaultBeta
+	}
+	tc, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	config.TrainConfig = tc
+	return &DPOTrainer[T]{policy: policy, reference: reference, opt: opt, config: config}, nil
+}
+
+// Train fine-tunes the policy on pairs. It first scores every pair with the
+// reference model, then runs the configured epochs.
+func (d *DPOTrainer[T]) Train(ctx context.Context, pairs []Pair) (*Result, error) {
+	if err := validate(pairs); err != nil {
+		return nil, err
+	}
+	refChosen, refRejected, err := d.referenceLogProbs(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+	beta := d.config.Beta
+	step := func(ctx context.Context, chunk []Pair, index []int) ([]float64, error) {
+		b := encode(chunk, d.config.PadID)
+		logits, shape, err := forward(ctx, d.policy, b)
+		if err != nil {
+			return nil, err
+		}
+		lp, err := logProbs(b, logits, shape, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		n := len(chunk)
+		margins := make([]float64, n)
+		scale := make([]float64, 2*n)
+		for i, j := range index {
+			margins[i] = beta * ((lp[i] - refChosen[j]) - (lp[n+i] - refRejected[j]))
+			_, g := pairLoss(margins[i])
+			scale[i] = beta * g / float64(n)
+			scale[n+i] = -scale[i]
+		}
+		grad := make([]float64, len(logits))
+		if _, err := logProbs(b, logits, shape, scale, grad); err != nil {
+			return nil, err
+		}
+		return margins, backward(ctx, d.policy, shape, grad)
+	}
+	return train(ctx, d.policy, d.opt, d.config.TrainConfig, pairs, step)
+}
+
+// referenceLogProbs returns the reference log-probabilities of the chosen
+// and rejected response of every pair.
+func (d *DPOTrainer[T]) referenceLogProbs(ctx context.Context, pairs []Pair) (chosen, rejected []float64, err error) {
+	ref := d.reference
+	if ref == nil {
+		ref = d.policy
+	}
+	regularization.SetTrainingMode(ref, false)
+	chosen, rejected = make([]float64, len(pairs)), make([]float64, len(pairs))
+	for start := 0; start < len(pairs); start += d.config.BatchSize {
+		chunk := pairs[start:min(start+d.config.BatchSize, len(pairs))]
+		b := encode(chunk, d.config.PadID)
+		logits, shape, err := forward(ctx, ref, b)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reference model: %w", err)
+		}
+		lp, err := logProbs(b, logits, shape, nil, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reference model: %w", err)
+		}
+		ref.ClearMemo()
+		copy(chosen[start:], lp[:len(chunk)])
+		copy(rejected[start:], lp[len(chunk):])
+	}
+	return chosen, rejected, nil
+}
@@ -0,0 +1,163 @@
+package preference
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/layers/components"
+	"github.com/zerfoo/zerfoo/layers/embeddings"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+// Tokens of the test vocabulary.
+const (
+	pad = iota
+	bos
+	good
+	bad
+	vocab
+)
+
+// newBigram returns a bigram language model: a [vocab, dim] embedding
+// table whose row for a token is the logits, or with dim 1 the reward, of
+// the next position.
+func newBigram(t *testing.T, seed uint64, dim int) *graph.Graph[float64] {
+	t.Helper()
+	engine := compute.NewCPUEngine[float64](numeric.Float64Ops{})
+	release := components.Seed[float64](engine, seed)
+	defer release()
+	b := graph.NewBuilder[float64](engine)
+	in := b.Input([]int{1, 1})
+	emb, err := embeddings.NewTokenEmbedding[float64](engine, vocab, dim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(emb, in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// goodPairs prefers responses of good tokens to responses of bad ones.
+func goodPairs() []Pair {
+	return []Pair{
+		{Prompt: []int{bos}, Chosen: []int{good}, Rejected: []int{bad}},
+		{Prompt: []int{bos}, Chosen: []int{good, good}, Rejected: []int{bad}},
+		{Prompt: []int{bos, good}, Chosen: []int{good}, Rejected: []int{bad, bad}},
+		{Prompt: []int{bos, bad}, Chosen: []int{good}, Rejected: []int{bad}},
+	}
+}
+
+func TestDPOTrainer(t *testing.T) {
+	ctx := context.Background()
+	policy := newBigram(t, 1, vocab)
+	opt := optimizer.NewSGD(policy.Engine(), policy.Engine().Ops(), 5)
+	dpo, err := NewDPOTrainer(policy, nil, opt, DPOConfig{TrainConfig: TrainConfig{Epochs: 20, BatchSize: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := dpo.Train(ctx, goodPairs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Steps != 20 || len(res.Epochs) != 20 {
+		t.Fatalf("Steps = %d, epochs = %d, want 20", res.Steps, len(res.Epochs))
+	}
+	// The policy starts as its own reference, so every margin is 0.
+	if first := res.Epochs[0]; math.Abs(first.Loss-math.Ln2) > 1e-9 || first.Margin != 0 {
+		t.Errorf("first epoch %+v, want loss ln 2 and margin 0", first)
+	}
+	if final := res.Final(); final.Accuracy != 1 || final.Loss > 0.6 || final.Margin <= 0 {
+		t.Errorf("final epoch %+v, want every pair ranked and the loss falling", final)
+	}
+
+	// After a bos the policy now prefers good to bad.
+	b := encode([]Pair{{Prompt: []int{bos}, Chosen: []int{good}, Rejected: []int{bad}}}, pad)
+	logits, shape, err := forward(ctx, policy, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp, err := logProbs(b, logits, shape, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lp[0] <= lp[1] {
+		t.Errorf("log p(good) = %v, log p(bad) = %v", lp[0], lp[1])
+	}
+}
+
+func TestDPOTrainer_Reference(t *testing.T) {
+	// A separate reference model, frozen while the policy trains.
+	policy, reference := newBigram(t, 1, vocab), newBigram(t, 2, vocab)
+	frozen := slices.Clone(reference.Parameters()[0].Value.Data())
+	opt := optimizer.NewSGD(policy.Engine(), policy.Engine().Ops(), 0.5)
+	dpo, err := NewDPOTrainer(policy, reference, opt, DPOConfig{Beta: 0.5, TrainConfig: TrainConfig{Epochs: 5, BatchSize: 2, Seed: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := dpo.Train(context.Background(), goodPairs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Steps != 10 {
+		t.Errorf("Steps = %d, want 10", res.Steps)
+	}
+	if res.Final().Margin <= res.Epochs[0].Margin {
+		t.Errorf("margin %v -> %v, want it to grow", res.Epochs[0].Margin, res.Final().Margin)
+	}
+	if !slices.Equal(reference.Parameters()[0].Value.Data(), frozen) {
+		t.Error("the reference model changed")
+	}
+
+	if _, err := NewDPOTrainer(policy, nil, opt, DPOConfig{Beta: -1}); err == nil {
+		t.Error("negative beta accepted")
+	}
+	if _, err := dpo.Train(context.Background(), []Pair{{Prompt: []int{bos}, Chosen: []int{good}}}); err == nil {
+		t.Error("pair without a rejected response accepted")
+	}
+}
+
+func TestLogProbs_Gradient(t *testing.T) {
+	b := encode([]Pair{{Prompt: []int{bos}, Chosen: []int{good, bad}, Rejected: []int{bad}}}, pad)
+	shape := []int{2, 3, vocab}
+	logits := make([]float64, 2*3*vocab)
+	for i := range logits {
+		logits[i] = math.Sin(float64(i))
+	}
+	scale := []float64{0.7, -0.3}
+	grad := make([]float64, len(logits))
+	if _, err := logProbs(b, logits, shape, scale, grad); err != nil {
+		t.Fatal(err)
+	}
+	objective := func() float64 {
+		lp, err := logProbs(b, logits, shape, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return scale[0]*lp[0] + scale[1]*lp[1]
+	}
+	const h = 1e-6
+	for i := range logits {
+		x := logits[i]
+		logits[i] = x + h
+		up := objective()
+		logits[i] = x - h
+		down := objective()
+		logits[i] = x
+		if numeric := (up - down) / (2 * h); math.Abs(numeric-grad[i]) > 1e-6 {
+			t.Errorf("grad[%d] = %v, numeric %v", i, grad[i], numeric)
+		}
+	}
+	// The padded last position of the rejected row has no gradient.
+	for v := range vocab {
+		if grad[(3+2)*vocab+v] != 0 {
+			t.Fatal("padding received a gradient")
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	rand "math/rand/v2"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+)
+
+const defaultBatchSize = 8
+
+// Pair is a preference judgement: Chosen and Rejected are two responses to
+// Prompt, and Chosen is the preferred one. All three are token IDs; the
+// prompt must not be empty, so a BOS token alone is a valid prompt.
+type Pair struct {
+	Prompt   []int
+	Chosen   []int
+	Rejected []int
+}
+
+// Metrics summarizes the pairs of a step or epoch.
+type Metrics struct {
+	// Loss is the mean preference loss.
+	Loss float64 `json:"loss"`
+	// Accuracy is the fraction of pairs whose chosen response scores above
+	// the rejected one.
+	Accuracy float64 `json:"accuracy"`
+	// Margin is the mean chosen-minus-rejected score.
+	Margin float64 `json:"margin"`
+}
+
+// Result is the outcome of a training run.
+type Result struct {
+	// Steps is the number of optimizer steps taken.
+	Steps int `json:"steps"`
+	// Epochs holds the metrics of each epoch, measured on each batch before
+	// its update.
+	Epochs []Metrics `json:"epochs"`
+}
+
+// Final returns the metrics of the last epoch.
+func (r *Result) Final() Metrics {
+	if len(r.Epochs) == 0 {
+		return Metrics{}
+	}
+	return r.Epochs[len(r.Epochs)-1]
+}
+
+// TrainConfig configures a preference training run.
+type TrainConfig struct {
+	// Epochs is the number of passes over the pairs; 0 means 1.
+	Epochs int
+	// BatchSize is the number of pairs per optimizer step; 0 means 8.
+	BatchSize int
+	// PadID is the token padding sequences to the length of the longest
+	// in a batch. Padded positions never contribute to the loss.
+	PadID int
+	// Seed seeds the order of the pairs, reshuffled every epoch.
+	Seed uint64
+}
+
+func (c TrainConfig) withDefaults() (TrainConfig, error) {
+	if c.Epochs < 0 || c.BatchSize < 0 {
+		return c, fmt.Errorf("preference: negative epochs %d or batch size %d", c.Epochs, c.BatchSize)
+	}
+	if c.Epochs == 0 {
+		c.Epochs = 1
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	return c, nil
+}
+
+// validate checks that every pair can be scored.
+func validate(pairs []Pair) error {
+	if len(pairs) == 0 {
+		return errors.New("preference: no pairs")
+	}
+	for i, p := range pairs {
+		if len(p.Prompt) == 0 || len(p.Chosen) == 0 || len(p.Rejected) == 0 {
+			return fmt.Errorf("preference: pair %d has an empty prompt or response", i)
+		}
+	}
+	return nil
+}
+
+// batch is a set of token sequences encoded as one [rows, length] token
+// matrix, padded on the right. A batch of n pairs holds the prompt and
+// chosen response of each pair in rows 0..n-1 and the prompt and rejected
+// response in rows n..2n-1.
+type batch struct {
+	rows   int
+	length int
+	ids    []float64
+	// prompt and end are the prompt length and the sequence length of
+	// each row.
+	prompt []int
+	end    []int
+}
+
+// encode encodes pairs as a batch of 2*len(pairs) rows.
+func encode(pairs []Pair, pad int) *batch {
+	n := len(pairs)
+	seqs, prompts := make([][]int, 2*n), make([]int, 2*n)
+	for i, p := range pairs {
+		for j, response := range [][]int{p.Chosen, p.Rejected} {
+			r := i + j*n
+			seqs[r] = append(append(make([]int, 0, len(p.Prompt)+len(response)), p.Prompt...), response...)
+			prompts[r] = len(p.Prompt)
+		}
+	}
+	return newBatch(seqs, prompts, pad)
+}
+
+// newBatch encodes seqs, whose prompts have the given lengths.
+func newBatch(seqs [][]int, prompts []int, pad int) *batch {
+	b := &batch{rows: len(seqs), prompt: prompts, end: make([]int, len(seqs))}
+	for r, seq := range seqs {
+		b.end[r] = len(seq)
+		b.length = max(b.length, len(seq))
+	}
+	b.ids = make([]float64, b.rows*b.length)
+	for r, seq := range seqs {
+		for t := range b.length {
+			tok := pad
+			if t < len(seq) {
+				tok = seq[t]
+			}
+			b.ids[r*b.length+t] = float64(tok)
+		}
+	}
+	return b
+}
+
+// token returns the token at position t of row r.
+func (b *batch) token(r, t int) int {
+	return int(b.ids[r*b.length+t])
+}
+
+// forward runs model over the batch and returns its output as float64s
+// with the output's shape.
+func forward[T tensor.Numeric](ctx context.Context, model *graph.Graph[T], b *batch) ([]float64, []int, error) {
+	ids := make([]T, len(b.ids))
+	dtype.FromFloat64s(model.Engine().Ops(), ids, b.ids)
+	in, err := tensor.New([]int{b.rows, b.length}, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := model.Forward(ctx, in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("preference: forward pass: %w", err)
+	}
+	return dtype.Float64s(nil, out.Data()), out.Shape(), nil
+}
+
+// logProbs returns the log-probability of the response of each row under
+// a causal language model's logits of shape [rows, length, vocab]: the sum
+// over response positions t of log softmax(logits[t-1])[token t]. With a
+// non-nil grad it also returns d logp / d logits in grad, scaled by
+// scale[r] for row r.
+func logProbs(b *batch, logits []float64, shape []int, scale []float64, grad []float64) ([]float64, error) {
+	if len(shape) != 3 || shape[0] != b.rows || shape[1] != b.length {
+		return nil, fmt.Errorf("preference: language model output shape %v, want [%d %d vocab]", shape, b.rows, b.length)
+	}
+	vocab := shape[2]
+	out := make([]float64, b.rows)
+	for r := range out {
+		for t := b.prompt[r] - 1; t < b.end[r]-1; t++ {
+			next := b.token(r, t+1)
+			if next < 0 || next >= vocab {
+				return nil, fmt.Errorf("preference: token %d outside a vocabulary of %d", next, vocab)
+			}
+			row := logits[(r*b.length+t)*vocab:][:vocab]
+			lse := logSumExp(row)
+			out[r] += row[next] - lse
+			if grad == nil {
+				continue
+			}
+			g := grad[(r*b.length+t)*vocab:][:vocab]
+			for v, x := range row {
+				g[v] -= scale[r] * math.Exp(x-lse)
+			}
+			g[next] += scale[r]
+		}
+	}
+	return out, nil
+}
+
+func logSumExp(xs []float64) float64 {
+	m := math.Inf(-1)
+	for _, x := range xs {
+		m = max(m, x)
+	}
+	var s float64
+	for _, x := range xs {
+		s += math.Exp(x - m)
+	}
+	return m + math.Log(s)
+}
+
+// pairLoss is the Bradley-Terry loss -log sigmoid(m) of a pair whose
+// chosen-minus-rejected score is m, and its derivative in m.
+func pairLoss(m float64) (loss, grad float64) {
+	// softplus(-m), computed stably.
+	loss = max(-m, 0) + math.Log1p(math.Exp(-math.Abs(m)))
+	return loss, -1 / (1 + math.Exp(m))
+}
+
+// summarize returns the metrics of the pair margins.
+func summarize(margins []float64) Metrics {
+	var m Metrics
+	for _, x := range margins {
+		l, _ := pairLoss(x)
+		m.Loss += l
+		m.Margin += x
+		if x > 0 {
+			m.Accuracy++
+		}
+	}
+	n := float64(len(margins))
+	m.Loss, m.Accuracy, m.Margin = m.Loss/n, m.Accuracy/n, m.Margin/n
+	return m
+}
+
+// stepFunc computes the margins of a batch of pairs, leaving the gradient of
+// their mean loss on the model's parameters. index holds the position of
+// each pair of the batch in the training set.
+type stepFunc func(ctx context.Context, pairs []Pair, index []int) ([]float64, error)
+
+// train runs config.Epochs shuffled epochs of step over pairs, stepping opt
+// on model's parameters after each batch. The model is in training mode
+// while it trains and in inference mode when train returns.
+func train[T tensor.Numeric](ctx context.Context, model *graph.Graph[T], opt optimizer.Optimizer[T], config TrainConfig, pairs []Pair, step stepFunc) (*Result, error) {
+	regularization.SetTrainingMode(model, true)
+	defer regularization.SetTrainingMode(model, false)
+	res := &Result{}
+	for epoch := range config.Epochs {
+		order := rand.New(rand.NewPCG(config.Seed, uint64(epoch))).Perm(len(pairs))
+		margins := make([]float64, 0, len(pairs))
+		for start := 0; start < len(order); start += config.BatchSize {
+			if err := ctx.Err(); err != nil {
+				return res, err
+			}
+			index := order[start:min(start+config.BatchSize, len(order))]
+			chunk := make([]Pair, len(index))
+			for i, j := range index {
+				chunk[i] = pairs[j]
+			}
+			optimizer.ZeroGrad(model.Parameters())
+			m, err := step(ctx, chunk, index)
+			if err != nil {
+				return res, fmt.Errorf("preference: epoch %d step %d: %w", epoch, res.Steps, err)
+			}
+			if err := opt.Step(ctx, model.Parameters()); err != nil {
+				return res, fmt.Errorf("preference: optimizer step: %w", err)
+			}
+			model.ClearMemo()
+			margins = append(margins, m...)
+			res.Steps++
+		}
+		res.Epochs = append(res.Epochs, summarize(margins))
+	}
+	return res, nil
+}
+
+// backward backpropagates grad, the loss gradient of the model's output,
+// through model.
+func backward[T tensor.Numeric](ctx context.Context, model *graph.Graph[T], shape []int, grad []float64) error {
+	data := make([]T, len(grad))
+	dtype.FromFloat64s(model.Engine().Ops(), data, grad)
+	dOut, err := tensor.New(shape, data)
+	if err != nil {
+		return err
+	}
+	if err := model.Backward(ctx, types.FullBackprop, dOut); err != nil {
+		return fmt.Errorf("preference: backward pass: %w", err)
+	}
+	return nil
+}
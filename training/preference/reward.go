@@ -0,0 +1,110 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// RewardTrainer fine-tunes a reward model on preference pairs with the
+// Bradley-Terry loss -log sigmoid(r(c) - r(r)), so that it scores chosen
+// responses above rejected ones. The trained model can rank samples from a
+// generator or supply rewards to a reinforcement learning loop.
+//
+// The model takes a [batch, length] matrix of token IDs, each row a prompt
+// followed by a response, and returns either one score per row, of shape
+// [batch] or [batch, 1], or one score per position, of shape
+// [batch, length] or [batch, length, 1]. Per-position scores are read at
+// the last token of each row, as for a causal transformer with a scalar
+// head.
+type RewardTrainer[T tensor.Numeric] struct {
+	model  *graph.Graph[T]
+	opt    optimizer.Optimizer[T]
+	config TrainConfig
+}
+
+// NewRewardTrainer returns a trainer updating model with opt.
+func NewRewardTrainer[T tensor.Numeric](model *graph.Graph[T], opt optimizer.Optimizer[T], config TrainConfig) (*RewardTrainer[T], error) {
+	if model == nil || opt == nil {
+		return nil, errors.New("preference: reward training needs a model and an optimizer")
+	}
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &RewardTrainer[T]{model: model, opt: opt, config: config}, nil
+}
+
+// Train fine-tunes the reward model on pairs.
+func (r *RewardTrainer[T]) Train(ctx context.Context, pairs []Pair) (*Result, error) {
+	if err := validate(pairs); err != nil {
+		return nil, err
+	}
+	step := func(ctx context.Context, chunk []Pair, _ []int) ([]float64, error) {
+		b := encode(chunk, r.config.PadID)
+		out, shape, err := forward(ctx, r.model, b)
+		if err != nil {
+			return nil, err
+		}
+		scores, at, err := rowScores(b, out)
+		if err != nil {
+			return nil, err
+		}
+		n := len(chunk)
+		margins := make([]float64, n)
+		grad := make([]float64, len(out))
+		for i := range margins {
+			margins[i] = scores[i] - scores[n+i]
+			_, g := pairLoss(margins[i])
+			grad[at[i]] += g / float64(n)
+			grad[at[n+i]] -= g / float64(n)
+		}
+		return margins, backward(ctx, r.model, shape, grad)
+	}
+	return train(ctx, r.model, r.opt, r.config, pairs, step)
+}
+
+// Score returns the reward model's score of each sequence, a prompt
+// followed by a response, padded with PadID into one batch.
+func (r *RewardTrainer[T]) Score(ctx context.Context, sequences [][]int) ([]float64, error) {
+	if len(sequences) == 0 {
+		return nil, nil
+	}
+	for i, seq := range sequences {
+		if len(seq) == 0 {
+			return nil, fmt.Errorf("preference: sequence %d is empty", i)
+		}
+	}
+	regularization.SetTrainingMode(r.model, false)
+	b := newBatch(sequences, make([]int, len(sequences)), r.config.PadID)
+	out, _, err := forward(ctx, r.model, b)
+	if err != nil {
+		return nil, err
+	}
+	r.model.ClearMemo()
+	scores, _, err := rowScores(b, out)
+	return scores, err
+}
+
+// rowScores returns the score of each row of b from the reward model
+// output out, and the index in out each was read from.
+func rowScores(b *batch, out []float64) (scores []float64, at []int, err error) {
+	scores, at = make([]float64, b.rows), make([]int, b.rows)
+	for r := range scores {
+		switch len(out) {
+		case b.rows:
+			at[r] = r
+		case b.rows * b.length:
+			at[r] = r*b.length + b.end[r] - 1
+		default:
+			return nil, nil, fmt.Errorf("preference: reward model returned %d values for %d rows of %d tokens", len(out), b.rows, b.length)
+		}
+		scores[r] = out[at[r]]
+	}
+	return scores, at, nil
+}
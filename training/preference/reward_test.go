@@ -0,0 +1,46 @@
+package preference
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/training/optimizer"
+)
+
+func TestRewardTrainer(t *testing.T) {
+	ctx := context.Background()
+	// Per-position rewards of shape [batch, length, 1], read at the last
+	// token of each row, past the padding of the shorter responses.
+	model := newBigram(t, 1, 1)
+	opt := optimizer.NewSGD(model.Engine(), model.Engine().Ops(), 1)
+	rm, err := NewRewardTrainer(model, opt, TrainConfig{Epochs: 30, BatchSize: 4, PadID: pad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := rm.Train(ctx, goodPairs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Steps != 30 {
+		t.Errorf("Steps = %d, want 30", res.Steps)
+	}
+	first, final := res.Epochs[0], res.Final()
+	if final.Accuracy != 1 || final.Loss >= first.Loss || final.Margin <= 0 {
+		t.Errorf("epochs %+v -> %+v, want every pair ranked and the loss falling", first, final)
+	}
+
+	scores, err := rm.Score(ctx, [][]int{{bos, good}, {bos, bad, bad}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 2 || scores[0] <= scores[1] {
+		t.Errorf("scores = %v, want the good response first", scores)
+	}
+
+	if _, err := NewRewardTrainer(model, nil, TrainConfig{}); err == nil {
+		t.Error("missing optimizer accepted")
+	}
+	if _, err := rm.Train(ctx, nil); err == nil {
+		t.Error("training without pairs succeeded")
+	}
+}
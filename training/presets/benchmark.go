@@ -0,0 +1,130 @@
+package presets
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/zerfoo/zerfoo/training"
+)
+
+// benchmarkSeed is the seed every benchmark builds its preset with, so runs
+// on different versions train the same job.
+const benchmarkSeed = 1
+
+// Budget bounds a benchmark run. Zero fields keep the preset's settings.
+type Budget struct {
+	// Epochs caps the number of training epochs.
+	Epochs int
+	// WallClock caps the training time; the run stops at the next step
+	// boundary after it elapses.
+	WallClock time.Duration
+}
+
+// BenchmarkResult reports the speed, quality and allocations of a
+// benchmark run.
+type BenchmarkResult struct {
+	Preset     string              `json:"preset"`
+	Steps      int                 `json:"steps"`
+	Epochs     int                 `json:"epochs"`
+	Duration   time.Duration       `json:"duration"`
+	StopReason training.StopReason `json:"stop_reason"`
+	// StepsPerSec is Steps over Duration.
+	StepsPerSec float64 `json:"steps_per_sec"`
+	// FinalLoss and BestLoss are the monitored epoch losses: validation
+	// loss for presets with validation data.
+	FinalLoss float64 `json:"final_loss"`
+	BestLoss  float64 `json:"best_loss"`
+	// Allocs and AllocBytes count the heap allocations made while
+	// training, by every goroutine of the process.
+	Allocs        uint64  `json:"allocs"`
+	AllocBytes    uint64  `json:"alloc_bytes"`
+	AllocsPerStep float64 `json:"allocs_per_step"`
+	BytesPerStep  float64 `json:"bytes_per_step"`
+}
+
+// Benchmark trains the named preset with a fixed seed within budget and
+// measures the run. Building the model and data is not measured. Comparing
+// results across zerfoo versions, or asserting bounds on them in tests,
+// catches speed, quality and allocation regressions:
+//
+//	r, err := presets.Benchmark(ctx, "tiny-mlp", presets.Budget{Epochs: 5})
+//	if r.FinalLoss > 0.05 || r.AllocsPerStep > 20000 { ... }
+//
+// Allocation counts include other goroutines, so run benchmarks alone.
+func Benchmark(ctx context.Context, preset string, budget Budget) (*BenchmarkResult, error) {
+	p, err := Get(preset)
+	if err != nil {
+		return nil, err
+	}
+	run, err := p.Build(ctx, benchmarkSeed)
+	if err != nil {
+		return nil, err
+	}
+	if budget.Epochs > 0 {
+		run.Config.NumEpochs = min(run.Config.NumEpochs, budget.Epochs)
+	}
+	if budget.WallClock > 0 {
+		run.Config.MaxWallClock = budget.WallClock
+	}
+	data := &countingData{DataProvider: run.Data}
+	run.Data = data
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	res, err := run.Train(ctx)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BenchmarkResult{
+		Preset:     preset,
+		Steps:      data.steps,
+		Epochs:     res.TotalEpochs,
+		Duration:   elapsed,
+		StopReason: res.StopReason,
+		FinalLoss:  float64(res.FinalLoss),
+		BestLoss:   float64(res.BestLoss),
+		Allocs:     after.Mallocs - before.Mallocs,
+		AllocBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+	if elapsed > 0 {
+		r.StepsPerSec = float64(r.Steps) / elapsed.Seconds()
+	}
+	if r.Steps > 0 {
+		r.AllocsPerStep = float64(r.Allocs) / float64(r.Steps)
+		r.BytesPerStep = float64(r.AllocBytes) / float64(r.Steps)
+	}
+	return r, nil
+}
+
+// countingData counts the training batches served by its provider.
+type countingData struct {
+	training.DataProvider[float32]
+	steps int
+}
+
+func (d *countingData) GetTrainingData(ctx context.Context, config training.BatchConfig) (training.DataIterator[float32], error) {
+	it, err := d.DataProvider.GetTrainingData(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &countingIterator{DataIterator: it, steps: &d.steps}, nil
+}
+
+type countingIterator struct {
+	training.DataIterator[float32]
+	steps *int
+}
+
+func (it *countingIterator) Next(ctx context.Context) bool {
+	if !it.DataIterator.Next(ctx) {
+		return false
+	}
+	*it.steps++
+	return true
+}
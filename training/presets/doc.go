@@ -0,0 +1,27 @@
+// Package presets provides small end-to-end training examples, each built
+// by name with its own model graph and synthetic data.
+//
+// A preset exercises the whole training stack, from layers and losses to
+// the standard workflow and GGUF checkpointing, in seconds on a CPU. The
+// presets serve as living integration tests and as starting points for new
+// models:
+//
+//	p, err := presets.Get("tiny-lm")
+//	run, err := p.Build(ctx, 1)
+//	result, err := run.Train(ctx)
+//
+// The "zerfoo train --preset <name>" command runs a preset from the command
+// line. Built-in presets are:
+//
+//   - "tiny-mlp": a two-layer MLP regressing a smooth function of its inputs.
+//   - "tiny-lm": a one-block decoder language model learning a token pattern.
+//   - "tiny-patchtst": a PatchTST-style forecaster predicting noisy sine waves.
+//
+// Register adds further presets.
+//
+// Benchmark trains a preset with a fixed seed within a Budget and reports
+// steps per second, the final loss and allocation counts, so downstream
+// projects can assert performance and quality bounds after upgrading:
+//
+//	r, err := presets.Benchmark(ctx, "tiny-mlp", presets.Budget{Epochs: 5})
+package presets
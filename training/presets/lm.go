@@ -0,0 +1,85 @@
+package presets
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/zerfoo/zerfoo/layers/attention"
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/layers/embeddings"
+	"github.com/zerfoo/zerfoo/layers/normalization"
+	"github.com/zerfoo/zerfoo/layers/transformer"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+// buildTinyLM builds a one-block decoder language model: token embedding,
+// a transformer block, RMSNorm, and an LM head. It learns the next token of
+// sequences that count upward modulo the vocabulary from a random start.
+func buildTinyLM(ctx context.Context, seed uint64) (*Run, error) {
+	const (
+		vocab, dim, ffnDim = 16, 32, 64
+		heads              = 2
+		seqLen, batchSize  = 8, 4
+		trainSize          = 8
+		validSize          = 2
+	)
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	ids := b.Input([]int{batchSize, seqLen})
+	embed, err := embeddings.NewTokenEmbedding[float32](engine, vocab, dim)
+	if err != nil {
+		return nil, err
+	}
+	attn, err := attention.NewGlobalAttention[float32](engine, ops, dim, heads, heads)
+	if err != nil {
+		return nil, err
+	}
+	block, err := transformer.NewTransformerBlock[float32](engine, ops, dim, ffnDim, attn)
+	if err != nil {
+		return nil, err
+	}
+	norm, err := normalization.NewRMSNorm[float32]("lm_final_norm", engine, ops, dim)
+	if err != nil {
+		return nil, err
+	}
+	head, err := core.NewDense[float32]("lm_head", engine, ops, dim, vocab, core.WithoutBias[float32]())
+	if err != nil {
+		return nil, err
+	}
+	h := b.AddNode(block, b.AddNode(embed, ids))
+	g, err := b.Build(b.AddNode(head, b.AddNode(norm, h)))
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewPCG(seed, 0))
+	initWeights(g, rng)
+
+	batches := make([]*training.Batch[float32], trainSize+validSize)
+	for i := range batches {
+		xs := make([]float32, batchSize*seqLen)
+		ys := make([]float32, batchSize*seqLen)
+		for j := range batchSize {
+			tok := rng.IntN(vocab)
+			for k := range seqLen {
+				xs[j*seqLen+k] = float32(tok)
+				tok = (tok + 1) % vocab
+				ys[j*seqLen+k] = float32(tok)
+			}
+		}
+		shape := []int{batchSize, seqLen}
+		if batches[i], err = newBatch(ids, shape, xs, shape, ys); err != nil {
+			return nil, err
+		}
+	}
+	return newRun(ctx, g, "cross_entropy", training.WorkflowConfig{
+		NumEpochs:    20,
+		LearningRate: 0.01,
+		RandomSeed:   seed,
+		BatchConfig:  training.BatchConfig{BatchSize: batchSize},
+		ModelConfig:  training.ModelConfig{Type: "tiny-lm"},
+	}, batches[:trainSize], batches[trainSize:])
+}
@@ -0,0 +1,67 @@
+package presets
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+
+	"github.com/zerfoo/zerfoo/layers/activations"
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+// buildTinyMLP builds a 4-16-1 tanh MLP regressing
+// y = sin(x0) + x1*x2 - x3/2 on uniform inputs in [-1, 1].
+func buildTinyMLP(ctx context.Context, seed uint64) (*Run, error) {
+	const (
+		in, hidden = 4, 16
+		batchSize  = 32
+		trainSize  = 8
+		validSize  = 2
+	)
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	x := b.Input([]int{batchSize, in})
+	fc1, err := core.NewDense[float32]("mlp_fc1", engine, ops, in, hidden)
+	if err != nil {
+		return nil, err
+	}
+	fc2, err := core.NewDense[float32]("mlp_fc2", engine, ops, hidden, 1)
+	if err != nil {
+		return nil, err
+	}
+	h := b.AddNode(activations.NewTanh[float32](engine, ops), b.AddNode(fc1, x))
+	g, err := b.Build(b.AddNode(fc2, h))
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewPCG(seed, 0))
+	initWeights(g, rng)
+
+	batches := make([]*training.Batch[float32], trainSize+validSize)
+	for i := range batches {
+		xs := make([]float32, batchSize*in)
+		ys := make([]float32, batchSize)
+		for j := range batchSize {
+			v := xs[j*in : (j+1)*in]
+			for k := range v {
+				v[k] = float32(rng.Float64()*2 - 1)
+			}
+			ys[j] = float32(math.Sin(float64(v[0]))) + v[1]*v[2] - v[3]/2
+		}
+		if batches[i], err = newBatch(x, []int{batchSize, in}, xs, []int{batchSize, 1}, ys); err != nil {
+			return nil, err
+		}
+	}
+	return newRun(ctx, g, "mse", training.WorkflowConfig{
+		NumEpochs:    30,
+		LearningRate: 0.01,
+		RandomSeed:   seed,
+		BatchConfig:  training.BatchConfig{BatchSize: batchSize},
+		ModelConfig:  training.ModelConfig{Type: "tiny-mlp"},
+	}, batches[:trainSize], batches[trainSize:])
+}
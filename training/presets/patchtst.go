@@ -0,0 +1,90 @@
+package presets
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+
+	"github.com/zerfoo/zerfoo/layers/attention"
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/layers/transformer"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+)
+
+// buildTinyPatchTST builds a PatchTST-style forecaster: the lookback window
+// is split into patches, each patch is embedded, a transformer block mixes
+// the patches, and a linear head maps the flattened patch embeddings to the
+// forecast horizon. It forecasts noisy sine waves of random frequency and
+// phase.
+func buildTinyPatchTST(ctx context.Context, seed uint64) (*Run, error) {
+	const (
+		lookback, horizon = 32, 8
+		patchLen          = 8
+		patches           = lookback / patchLen
+		dim, ffnDim       = 16, 32
+		heads             = 2
+		batchSize         = 8
+		trainSize         = 8
+		validSize         = 2
+	)
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	window := b.Input([]int{batchSize, lookback})
+	embed, err := core.NewDense[float32]("patchtst_embed", engine, ops, patchLen, dim)
+	if err != nil {
+		return nil, err
+	}
+	attn, err := attention.NewGlobalAttention[float32](engine, ops, dim, heads, heads)
+	if err != nil {
+		return nil, err
+	}
+	block, err := transformer.NewTransformerBlock[float32](engine, ops, dim, ffnDim, attn)
+	if err != nil {
+		return nil, err
+	}
+	head, err := core.NewDense[float32]("patchtst_head", engine, ops, patches*dim, horizon)
+	if err != nil {
+		return nil, err
+	}
+	h := b.AddNode(core.NewReshape[float32](engine, []int{batchSize, patches, patchLen}), window)
+	h = b.AddNode(block, b.AddNode(embed, h))
+	h = b.AddNode(core.NewReshape[float32](engine, []int{batchSize, patches * dim}), h)
+	g, err := b.Build(b.AddNode(head, h))
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewPCG(seed, 0))
+	initWeights(g, rng)
+
+	batches := make([]*training.Batch[float32], trainSize+validSize)
+	for i := range batches {
+		xs := make([]float32, batchSize*lookback)
+		ys := make([]float32, batchSize*horizon)
+		for j := range batchSize {
+			freq := 0.1 + 0.3*rng.Float64()
+			phase := 2 * math.Pi * rng.Float64()
+			for k := range lookback + horizon {
+				v := float32(math.Sin(freq*float64(k)+phase) + 0.05*rng.NormFloat64())
+				if k < lookback {
+					xs[j*lookback+k] = v
+				} else {
+					ys[j*horizon+k-lookback] = v
+				}
+			}
+		}
+		if batches[i], err = newBatch(window, []int{batchSize, lookback}, xs, []int{batchSize, horizon}, ys); err != nil {
+			return nil, err
+		}
+	}
+	return newRun(ctx, g, "mse", training.WorkflowConfig{
+		NumEpochs:    20,
+		LearningRate: 0.005,
+		RandomSeed:   seed,
+		BatchConfig:  training.BatchConfig{BatchSize: batchSize},
+		ModelConfig:  training.ModelConfig{Type: "tiny-patchtst"},
+	}, batches[:trainSize], batches[trainSize:])
+}
@@ -0,0 +1,177 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Preset is a named, runnable training example.
+type Preset struct {
+	// Name is the name the preset is registered and selected under.
+	Name string
+	// Description says what the preset trains, in one line.
+	Description string
+	// Build creates the preset's model, synthetic data, and workflow. The
+	// same seed always yields the same weights and data.
+	Build func(ctx context.Context, seed uint64) (*Run, error)
+}
+
+// Run is a built preset: a workflow with its configuration, model, and
+// data, ready to train.
+type Run struct {
+	Workflow training.TrainingWorkflow[float32]
+	Config   training.WorkflowConfig
+	Models   training.ModelProvider[float32]
+	Data     training.DataProvider[float32]
+}
+
+// Train initializes the workflow with r.Config and trains the model on the
+// preset's data.
+func (r *Run) Train(ctx context.Context) (*training.TrainingResult[float32], error) {
+	if err := r.Workflow.Initialize(ctx, r.Config); err != nil {
+		return nil, err
+	}
+	return r.Workflow.Train(ctx, r.Data, r.Models)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Preset)
+)
+
+func init() {
+	for _, p := range []Preset{
+		{Name: "tiny-mlp", Description: "Two-layer MLP regressing a smooth function of four inputs", Build: buildTinyMLP},
+		{Name: "tiny-lm", Description: "One-block decoder language model learning a counting pattern", Build: buildTinyLM},
+		{Name: "tiny-patchtst", Description: "PatchTST-style forecaster predicting noisy sine waves", Build: buildTinyPatchTST},
+	} {
+		_ = Register(p)
+	}
+}
+
+// Register adds p to the preset registry. It fails if p has no name or
+// Build function, or if the name is taken.
+func Register(p Preset) error {
+	if p.Name == "" || p.Build == nil {
+		return fmt.Errorf("presets: preset needs a name and a Build function")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[p.Name]; ok {
+		return fmt.Errorf("presets: preset %q already registered", p.Name)
+	}
+	registry[p.Name] = p
+	return nil
+}
+
+// Get returns the preset registered under name.
+func Get(name string) (Preset, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := registry[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("presets: unknown preset %q (have %v)", name, namesLocked())
+	}
+	return p, nil
+}
+
+// Names returns the names of all registered presets, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return namesLocked()
+}
+
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// newRun assembles a Run around a built model graph: the standard workflow
+// with the named loss and AdamW, and fixed training and validation batches.
+func newRun(ctx context.Context, g *graph.Graph[float32], lossName string, config training.WorkflowConfig, train, valid []*training.Batch[float32]) (*Run, error) {
+	w, err := training.Float32Registry.GetWorkflow(ctx, training.StandardWorkflowName, map[string]interface{}{
+		"loss":      lossName,
+		"optimizer": "adamw",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Run{
+		Workflow: w,
+		Config:   config,
+		Models: training.NewSimpleModelProvider(
+			func(context.Context, training.ModelConfig) (*graph.Graph[float32], error) { return g, nil },
+			training.ModelInfo{},
+		),
+		Data: &batchData{train: train, valid: valid},
+	}, nil
+}
+
+// batchData serves fixed, in-memory training and validation batches.
+type batchData struct {
+	train, valid []*training.Batch[float32]
+}
+
+func (d *batchData) GetTrainingData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter(d.train), nil
+}
+
+func (d *batchData) GetValidationData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter(d.valid), nil
+}
+
+func (d *batchData) GetMetadata() map[string]interface{} {
+	return map[string]interface{}{"train_batches": len(d.train), "validation_batches": len(d.valid)}
+}
+
+func (d *batchData) Close() error { return nil }
+
+var _ training.DataProvider[float32] = (*batchData)(nil)
+
+// initWeights draws every weight matrix of g from a scaled uniform
+// distribution seeded by rng, so a preset's starting point depends only on
+// its seed. Vectors such as biases and norm gains keep their defaults.
+func initWeights(g *graph.Graph[float32], rng *rand.Rand) {
+	for _, p := range g.Parameters() {
+		shape := p.Value.Shape()
+		if len(shape) < 2 {
+			continue
+		}
+		scale := math.Sqrt(6 / float64(shape[0]+shape[len(shape)-1]))
+		data := p.Value.Data()
+		for i := range data {
+			data[i] = float32((rng.Float64()*2 - 1) * scale)
+		}
+		p.Value.SetData(data)
+	}
+}
+
+// newBatch wraps inputs and targets, with the given shapes, as a batch
+// feeding the graph input node in.
+func newBatch(in graph.Node[float32], inShape []int, inputs []float32, targetShape []int, targets []float32) (*training.Batch[float32], error) {
+	x, err := tensor.New(inShape, inputs)
+	if err != nil {
+		return nil, err
+	}
+	y, err := tensor.New(targetShape, targets)
+	if err != nil {
+		return nil, err
+	}
+	return &training.Batch[float32]{
+		Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{in: x},
+		Targets: y,
+	}, nil
+}
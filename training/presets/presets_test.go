@@ -0,0 +1,96 @@
+package presets_test
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/presets"
+)
+
+func TestPresetsTrain(t *testing.T) {
+	ctx := context.Background()
+	for _, name := range presets.Names() {
+		t.Run(name, func(t *testing.T) {
+			p, err := presets.Get(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			run, err := p.Build(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := run.Workflow.Initialize(ctx, run.Config); err != nil {
+				t.Fatal(err)
+			}
+			before, err := run.Workflow.Validate(ctx, run.Data, run.Models)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := run.Train(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, err := run.Workflow.Validate(ctx, run.Data, run.Models)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("validation loss %v -> %v, train loss %v", before.Loss, after.Loss, res.FinalLoss)
+			if math.IsNaN(float64(after.Loss)) || after.Loss > before.Loss/2 {
+				t.Errorf("validation loss %v -> %v, want at least halved", before.Loss, after.Loss)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	want := []string{"tiny-lm", "tiny-mlp", "tiny-patchtst"}
+	if got := presets.Names(); !slices.Equal(got, want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+	if _, err := presets.Get("nope"); err == nil {
+		t.Error("Get of an unknown preset succeeded")
+	}
+	if err := presets.Register(presets.Preset{Name: "tiny-mlp", Build: func(context.Context, uint64) (*presets.Run, error) { return nil, nil }}); err == nil {
+		t.Error("duplicate Register succeeded")
+	}
+	if err := presets.Register(presets.Preset{Name: "no-build"}); err == nil {
+		t.Error("Register without Build succeeded")
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	ctx := context.Background()
+	r, err := presets.Benchmark(ctx, "tiny-mlp", presets.Budget{Epochs: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%+v", r)
+	// tiny-mlp has 8 training batches per epoch.
+	if r.Epochs != 3 || r.Steps != 24 {
+		t.Errorf("ran %d epochs, %d steps, want 3 and 24", r.Epochs, r.Steps)
+	}
+	if r.StepsPerSec <= 0 || r.Allocs == 0 || r.AllocsPerStep <= 0 || math.IsNaN(r.FinalLoss) {
+		t.Errorf("result = %+v, want positive rates and a finite loss", r)
+	}
+	again, err := presets.Benchmark(ctx, "tiny-mlp", presets.Budget{Epochs: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.FinalLoss != r.FinalLoss {
+		t.Errorf("final loss %v, then %v: want the same job each run", r.FinalLoss, again.FinalLoss)
+	}
+
+	if r, err = presets.Benchmark(ctx, "tiny-mlp", presets.Budget{WallClock: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	if r.StopReason != training.StopMaxWallClock {
+		t.Errorf("stop reason %q, want %q", r.StopReason, training.StopMaxWallClock)
+	}
+	if _, err := presets.Benchmark(ctx, "nope", presets.Budget{}); err == nil {
+		t.Error("Benchmark of an unknown preset succeeded")
+	}
+}
@@ -1,4 +1,8 @@
-// Package scheduler provides learning rate scheduling strategies for optimizers.
+// Package scheduler provides learning rate scheduling strategies for optimizers:
+// cosine annealing, SGDR, step decay, linear warmup, and ReduceOnPlateau.
+// Apply a scheduler's rate with an optimizer's SetLRFloat64 (see
+// optimizer.LRSetter), or let training.StandardWorkflow do it between
+// epochs via training.WithLRScheduler.
 package scheduler
 
 import "github.com/zerfoo/ztensor/tensor"
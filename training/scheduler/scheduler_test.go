@@ -182,3 +182,54 @@ func TestScheduler_IntegrationWithSetLR(t *testing.T) {
 		t.Errorf("expected LR < initial after half-cycle, got %v", optimizerLR)
 	}
 }
+
+func TestStepDecay(t *testing.T) {
+	s, err := NewStepDecay(StepDecayConfig[float64]{InitialLR: 0.1, StepSize: 2, Gamma: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetLR(); got != 0.1 {
+		t.Errorf("initial LR = %v, want 0.1", got)
+	}
+	for epoch, want := range []float64{0.1, 0.1, 0.05, 0.05, 0.025} {
+		s.Step(epoch, 0)
+		if got := s.GetLR(); math.Abs(got-want) > 1e-12 {
+			t.Errorf("epoch %d: LR = %v, want %v", epoch, got, want)
+		}
+	}
+
+	for _, cfg := range []StepDecayConfig[float64]{
+		{InitialLR: 0.1, StepSize: 0, Gamma: 0.5},
+		{InitialLR: 0.1, StepSize: 1, Gamma: 0},
+		{InitialLR: 0.1, StepSize: 1, Gamma: 1.5},
+	} {
+		if _, err := NewStepDecay(cfg); err == nil {
+			t.Errorf("NewStepDecay(%+v) should fail", cfg)
+		}
+	}
+}
+
+func TestLinearWarmup(t *testing.T) {
+	cosine := NewCosineAnnealing(CosineAnnealingConfig[float64]{EtaMax: 0.1, TMax: 2})
+	s, err := NewLinearWarmup(LinearWarmupConfig[float64]{WarmupEpochs: 4, Next: cosine})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetLR(); math.Abs(got-0.025) > 1e-12 {
+		t.Errorf("initial LR = %v, want 0.025", got)
+	}
+	// Four warmup epochs, then the cosine schedule from its epoch 0.
+	for epoch, want := range []float64{0.025, 0.05, 0.075, 0.1, 0.1, 0.05, 0} {
+		s.Step(epoch, 0)
+		if got := s.GetLR(); math.Abs(got-want) > 1e-12 {
+			t.Errorf("epoch %d: LR = %v, want %v", epoch, got, want)
+		}
+	}
+
+	if _, err := NewLinearWarmup(LinearWarmupConfig[float64]{WarmupEpochs: 0, Next: cosine}); err == nil {
+		t.Error("zero WarmupEpochs should be rejected")
+	}
+	if _, err := NewLinearWarmup(LinearWarmupConfig[float64]{WarmupEpochs: 2}); err == nil {
+		t.Error("missing Next should be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+
+	"github.com/zerfoo/ztensor/metrics/runtime"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// Metric names recorded by SGDR when a Collector is configured.
+const (
+	MetricLearningRate = "learning_rate"
+	MetricLRCycle      = "lr_cycle"
+	MetricLRRestarts   = "lr_restarts_total"
+)
+
+// SGDRConfig holds configuration for the SGDR scheduler.
+type SGDRConfig[T tensor.Numeric] struct {
+	// EtaMax is the learning rate at the start of the first cycle.
+	EtaMax T
+
+	// EtaMin is the learning rate at the end of every cycle.
+	EtaMin float64
+
+	// T0 is the length of the first cycle in epochs (or steps, if Step is
+	// called per step).
+	T0 int
+
+	// TMult multiplies the cycle length after each restart. Zero is
+	// treated as 1 (fixed-length cycles).
+	TMult int
+
+	// CycleDecay multiplies EtaMax after each restart, so later cycles peak
+	// lower. Zero is treated as 1 (no decay).
+	CycleDecay float64
+
+	// OnRestart, if set, is called from Step when a new cycle begins, with
+	// the new cycle index (1 for the first restart) and the epoch passed to
+	// Step. Use it to reset weights to a Polyak average:
+	//
+	//	OnRestart: func(cycle, epoch int) {
+	//	    if err := ema.ResetToAverage(ctx, g.Parameters()); err != nil { ... }
+	//	}
+	OnRestart func(cycle, epoch int)
+
+	// Collector, if set, receives the current learning rate and cycle as
+	// gauges and a counter of restarts, so cycle boundaries show up next to
+	// the loss curves.
+	Collector runtime.Collector
+}
+
+// SGDR implements stochastic gradient descent with warm restarts
+// (Loshchilov & Hutter, 2017): cosine annealing from EtaMax to EtaMin over
+// cycles of length T0, T0*TMult, T0*TMult^2, ..., restarting at the peak
+// after each cycle.
+type SGDR[T tensor.Numeric] struct {
+	etaMax     float64
+	etaMin     float64
+	t0         int
+	tMult      int
+	cycleDecay float64
+	onRestart  func(cycle, epoch int)
+	collector  runtime.Collector
+
+	lr        float64
+	cycle     int
+	stepped   bool
+	restarted bool
+	toT       func(float64) T
+}
+
+// NewSGDR creates a new SGDR scheduler.
+func NewSGDR[T tensor.Numeric](cfg SGDRConfig[T]) (*SGDR[T], error) {
+	if cfg.T0 <= 0 {
+		return nil, errors.New("sgdr: T0 must be positive")
+	}
+	if cfg.TMult < 0 {
+		return nil, errors.New("sgdr: TMult must not be negative")
+	}
+	if cfg.CycleDecay < 0 || cfg.CycleDecay > 1 {
+		return nil, errors.New("sgdr: CycleDecay must be in [0, 1]")
+	}
+	s := &SGDR[T]{
+		etaMax:     float64FromNumeric(cfg.EtaMax),
+		etaMin:     cfg.EtaMin,
+		t0:         cfg.T0,
+		tMult:      max(cfg.TMult, 1),
+		cycleDecay: cfg.CycleDecay,
+		onRestart:  cfg.OnRestart,
+		collector:  cfg.Collector,
+		toT:        converterFor[T](),
+	}
+	if s.cycleDecay == 0 {
+		s.cycleDecay = 1
+	}
+	s.lr = s.etaMax
+	return s, nil
+}
+
+// Position returns the cycle containing epoch, the offset of epoch within
+// that cycle, and the cycle's length.
+func (s *SGDR[T]) Position(epoch int) (cycle, offset, length int) {
+	epoch = max(epoch, 0)
+	length = s.t0
+	for epoch >= length {
+		epoch -= length
+		cycle++
+		length *= s.tMult
+	}
+	return cycle, epoch, length
+}
+
+// Step computes the learning rate for the given epoch. If epoch falls in a
+// later cycle than the previous call, the restart is recorded and
+// OnRestart is invoked once.
+func (s *SGDR[T]) Step(epoch int, _ float64) {
+	cycle, offset, length := s.Position(epoch)
+	peak := s.etaMax * math.Pow(s.cycleDecay, float64(cycle))
+	s.lr = s.etaMin + 0.5*(peak-s.etaMin)*(1+math.Cos(math.Pi*float64(offset)/float64(length)))
+
+	s.restarted = s.stepped && cycle > s.cycle
+	s.cycle = cycle
+	s.stepped = true
+
+	if s.collector != nil {
+		s.collector.Gauge(MetricLearningRate).Set(s.lr)
+		s.collector.Gauge(MetricLRCycle).Set(float64(cycle))
+		if s.restarted {
+			s.collector.Counter(MetricLRRestarts).Inc()
+		}
+	}
+	if s.restarted && s.onRestart != nil {
+		s.onRestart(cycle, epoch)
+	}
+}
+
+// GetLR returns the current learning rate.
+func (s *SGDR[T]) GetLR() T {
+	return s.toT(s.lr)
+}
+
+// LR returns the current learning rate in float64.
+func (s *SGDR[T]) LR() float64 { return s.lr }
+
+// Cycle returns the cycle index of the most recent Step.
+func (s *SGDR[T]) Cycle() int { return s.cycle }
+
+// Restarted reports whether the most recent Step began a new cycle.
+func (s *SGDR[T]) Restarted() bool { return s.restarted }
+
+// Compile-time interface check.
+var _ Scheduler[float32] = (*SGDR[float32])(nil)
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zerfoo/ztensor/metrics/runtime"
+)
+
+func TestSGDR_Position(t *testing.T) {
+	s, err := NewSGDR(SGDRConfig[float64]{EtaMax: 0.1, T0: 2, TMult: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cycles of length 2, 4, 8 start at epochs 0, 2, 6.
+	tests := []struct{ epoch, cycle, offset, length int }{
+		{0, 0, 0, 2}, {1, 0, 1, 2}, {2, 1, 0, 4}, {5, 1, 3, 4}, {6, 2, 0, 8}, {13, 2, 7, 8}, {14, 3, 0, 16},
+	}
+	for _, tt := range tests {
+		c, o, l := s.Position(tt.epoch)
+		if c != tt.cycle || o != tt.offset || l != tt.length {
+			t.Errorf("Position(%d) = (%d, %d, %d), want (%d, %d, %d)", tt.epoch, c, o, l, tt.cycle, tt.offset, tt.length)
+		}
+	}
+}
+
+func TestSGDR_Schedule(t *testing.T) {
+	var restarts [][2]int
+	s, err := NewSGDR(SGDRConfig[float64]{
+		EtaMax:     0.1,
+		EtaMin:     0.001,
+		T0:         4,
+		TMult:      2,
+		CycleDecay: 0.5,
+		OnRestart:  func(cycle, epoch int) { restarts = append(restarts, [2]int{cycle, epoch}) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lrs := make([]float64, 12)
+	for e := range lrs {
+		s.Step(e, 0)
+		lrs[e] = s.LR()
+	}
+	if math.Abs(lrs[0]-0.1) > 1e-9 {
+		t.Errorf("lr[0] = %v, want 0.1", lrs[0])
+	}
+	// Mid-cycle of the 4-epoch first cycle: the cosine midpoint.
+	if want := 0.001 + 0.5*(0.1-0.001); math.Abs(lrs[2]-want) > 1e-9 {
+		t.Errorf("lr[2] = %v, want %v", lrs[2], want)
+	}
+	for e := 1; e < 4; e++ {
+		if lrs[e] >= lrs[e-1] {
+			t.Errorf("lr not decreasing within cycle at epoch %d: %v", e, lrs)
+		}
+	}
+	// Restart at epoch 4 to the decayed peak.
+	if math.Abs(lrs[4]-0.05) > 1e-9 {
+		t.Errorf("lr[4] = %v, want 0.05 after restart", lrs[4])
+	}
+	if len(restarts) != 1 || restarts[0] != [2]int{1, 4} {
+		t.Errorf("restarts = %v, want [[1 4]]", restarts)
+	}
+	// Epoch 11 is the last epoch of cycle 1 (length 8, starting at 4).
+	if s.Cycle() != 1 || s.Restarted() {
+		t.Errorf("after epoch 11: cycle=%d restarted=%v, want 1 false", s.Cycle(), s.Restarted())
+	}
+}
+
+func TestSGDR_Collector(t *testing.T) {
+	c := runtime.NewInMemory()
+	s, err := NewSGDR(SGDRConfig[float64]{EtaMax: 0.1, T0: 3, Collector: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e := range 10 {
+		s.Step(e, 0)
+	}
+	snap := c.Snapshot()
+	if got := snap.Counters[MetricLRRestarts]; got != 3 {
+		t.Errorf("restarts = %d, want 3", got)
+	}
+	if got := snap.Gauges[MetricLRCycle]; got != 3 {
+		t.Errorf("cycle gauge = %v, want 3", got)
+	}
+	if got := snap.Gauges[MetricLearningRate]; math.Abs(got-0.1) > 1e-9 {
+		t.Errorf("lr gauge = %v, want 0.1 at the start of a cycle", got)
+	}
+}
+
+func TestNewSGDR_InvalidConfig(t *testing.T) {
+	for _, cfg := range []SGDRConfig[float64]{
+		{EtaMax: 0.1, T0: 0},
+		{EtaMax: 0.1, T0: 1, TMult: -1},
+		{EtaMax: 0.1, T0: 1, CycleDecay: 1.5},
+	} {
+		if _, err := NewSGDR(cfg); err == nil {
+			t.Errorf("NewSGDR(%+v): expected error", cfg)
+		}
+	}
+}
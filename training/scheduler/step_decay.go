@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// StepDecayConfig holds configuration for the StepDecay scheduler.
+type StepDecayConfig[T tensor.Numeric] struct {
+	// InitialLR is the learning rate for the first StepSize epochs.
+	InitialLR T
+
+	// StepSize is the number of epochs between decays.
+	StepSize int
+
+	// Gamma is the multiplier applied to the LR every StepSize epochs
+	// (e.g. 0.1).
+	Gamma float64
+}
+
+// StepDecay multiplies the learning rate by Gamma every StepSize epochs.
+type StepDecay[T tensor.Numeric] struct {
+	initialLR float64
+	stepSize  int
+	gamma     float64
+	lr        float64
+	toT       func(float64) T
+}
+
+// NewStepDecay creates a new StepDecay scheduler.
+func NewStepDecay[T tensor.Numeric](cfg StepDecayConfig[T]) (*StepDecay[T], error) {
+	if cfg.StepSize <= 0 {
+		return nil, errors.New("step decay: StepSize must be positive")
+	}
+	if cfg.Gamma <= 0 || cfg.Gamma > 1 {
+		return nil, errors.New("step decay: Gamma must be in (0, 1]")
+	}
+	initialLR := float64FromNumeric(cfg.InitialLR)
+	return &StepDecay[T]{
+		initialLR: initialLR,
+		stepSize:  cfg.StepSize,
+		gamma:     cfg.Gamma,
+		lr:        initialLR,
+		toT:       converterFor[T](),
+	}, nil
+}
+
+// Step computes the learning rate for the given epoch.
+func (s *StepDecay[T]) Step(epoch int, _ float64) {
+	s.lr = s.initialLR * math.Pow(s.gamma, float64(max(epoch, 0)/s.stepSize))
+}
+
+// GetLR returns the current learning rate.
+func (s *StepDecay[T]) GetLR() T {
+	return s.toT(s.lr)
+}
+
+// Compile-time interface check.
+var _ Scheduler[float32] = (*StepDecay[float32])(nil)
@@ -1,5 +1,11 @@
 package scheduler
 
+import (
+	"errors"
+
+	"github.com/zerfoo/ztensor/tensor"
+)
+
 // WarmupLR returns the effective learning rate for the given epoch,
 // applying linear warmup over the first warmupEpochs epochs.
 func WarmupLR(baseLR float64, epoch, warmupEpochs int) float64 {
@@ -12,3 +18,63 @@ func WarmupLR(baseLR float64, epoch, warmupEpochs int) float64 {
 	}
 	return baseLR * scale
 }
+
+// LinearWarmupConfig holds configuration for the LinearWarmup scheduler.
+type LinearWarmupConfig[T tensor.Numeric] struct {
+	// WarmupEpochs is the number of epochs over which the LR ramps up
+	// linearly to Next's initial learning rate.
+	WarmupEpochs int
+
+	// Next takes over once warmup ends. It sees epochs counted from the
+	// end of warmup, so a CosineAnnealing with TMax = epochs - WarmupEpochs
+	// decays over exactly the remaining epochs.
+	Next Scheduler[T]
+}
+
+// LinearWarmup ramps the learning rate up linearly before handing over to
+// another scheduler.
+type LinearWarmup[T tensor.Numeric] struct {
+	warmupEpochs int
+	targetLR     float64
+	next         Scheduler[T]
+	lr           float64
+	toT          func(float64) T
+}
+
+// NewLinearWarmup creates a new LinearWarmup scheduler. Its learning rate
+// starts at 1/WarmupEpochs of Next's initial learning rate.
+func NewLinearWarmup[T tensor.Numeric](cfg LinearWarmupConfig[T]) (*LinearWarmup[T], error) {
+	if cfg.WarmupEpochs <= 0 {
+		return nil, errors.New("linear warmup: WarmupEpochs must be positive")
+	}
+	if cfg.Next == nil {
+		return nil, errors.New("linear warmup: Next scheduler is required")
+	}
+	target := float64FromNumeric(cfg.Next.GetLR())
+	return &LinearWarmup[T]{
+		warmupEpochs: cfg.WarmupEpochs,
+		targetLR:     target,
+		next:         cfg.Next,
+		lr:           WarmupLR(target, 0, cfg.WarmupEpochs),
+		toT:          converterFor[T](),
+	}, nil
+}
+
+// Step computes the learning rate for the given epoch. After warmup it
+// steps Next with the epoch offset by WarmupEpochs.
+func (w *LinearWarmup[T]) Step(epoch int, metric float64) {
+	if epoch < w.warmupEpochs {
+		w.lr = WarmupLR(w.targetLR, epoch, w.warmupEpochs)
+		return
+	}
+	w.next.Step(epoch-w.warmupEpochs, metric)
+	w.lr = float64FromNumeric(w.next.GetLR())
+}
+
+// GetLR returns the current learning rate.
+func (w *LinearWarmup[T]) GetLR() T {
+	return w.toT(w.lr)
+}
+
+// Compile-time interface check.
+var _ Scheduler[float32] = (*LinearWarmup[float32])(nil)
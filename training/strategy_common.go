@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zerfoo/zerfoo/internal/tracing"
 	"github.com/zerfoo/ztensor/compute"
 	"github.com/zerfoo/ztensor/graph"
 	"github.com/zerfoo/ztensor/tensor"
@@ -113,7 +114,12 @@ func computeGradientsTensorCommon[T tensor.Numeric](
 	batch Batch[T],
 	mode types.BackwardMode,
 	acc *gradAccumulator[T],
-) (*tensor.TensorNumeric[T], error) {
+) (_ *tensor.TensorNumeric[T], err error) {
+	// The step is traced as a forward span followed by a backward span; the
+	// deferred End closes whichever is open when the function returns.
+	fwdCtx, span := tracing.Start(ctx, "training", "training.forward")
+	defer func() { tracing.End(span, err) }()
+
 	// Materialize inputs in graph input order
 	var inputSlice []*tensor.TensorNumeric[T]
 	for _, inputNode := range g.Inputs() {
@@ -121,16 +127,18 @@ func computeGradientsTensorCommon[T tensor.Numeric](
 	}
 
 	// Forward pass
-	output, err := g.Forward(ctx, inputSlice...)
+	output, err := g.Forward(fwdCtx, inputSlice...)
 	if err != nil {
 		return nil, fmt.Errorf("forward pass failed: %w", err)
 	}
 
 	// Loss forward
-	lossTensor, err := loss.Forward(ctx, output, batch.Targets)
+	lossTensor, err := loss.Forward(fwdCtx, output, batch.Targets)
 	if err != nil {
 		return nil, fmt.Errorf("loss computation failed: %w", err)
 	}
+	span.End()
+	ctx, span = tracing.Start(ctx, "training", "training.backward")
 
 	// Loss backward.
 	//
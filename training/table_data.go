@@ -0,0 +1,587 @@
+package training
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	rand "math/rand/v2"
+	"slices"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+const (
+	defaultTableBatchSize     = 32
+	defaultTableShuffleBuffer = 1024
+)
+
+// TableConfig configures a DataProvider reading a table file, such as a
+// CSVDataProvider or a ParquetDataProvider.
+type TableConfig[T tensor.Numeric] struct {
+	// Path is the table file.
+	Path string
+	// Input is the graph input node the features are fed to.
+	Input graph.Node[T]
+	// FeatureColumns are the input columns, in order. Empty means every
+	// column that is not a target, ID or group column.
+	FeatureColumns []string
+	// TargetColumns are the target columns, in order. At least one is
+	// required.
+	TargetColumns []string
+	// IDColumn optionally names a row identifier, reported by
+	// CSVIterator.IDs.
+	IDColumn string
+	// GroupColumn optionally names a group key, reported by
+	// CSVIterator.Groups. Rows of a group land on the same side of the
+	// train/validation split, so related rows, such as the samples of one
+	// patient or one day, do not leak across it.
+	GroupColumn string
+	// ValidationRatio is the fraction of rows, or of groups, held out for
+	// validation, in [0, 1).
+	ValidationRatio float64
+	// Seed seeds the split and the shuffle.
+	Seed uint64
+	// ShuffleBuffer is the number of rows a shuffled iterator draws from;
+	// 0 means 1024. Larger buffers shuffle more thoroughly and hold more
+	// rows in memory.
+	ShuffleBuffer int
+	// Ops converts values to T. It is required for element types other
+	// than float32 and float64.
+	Ops numeric.Arithmetic[T]
+}
+
+// rowSource streams the rows of a table file for a CSVIterator.
+type rowSource interface {
+	// next advances to the next row, reporting false at the end.
+	next() (bool, error)
+	// text returns column c of the current row as text.
+	text(c int) string
+	// float returns column c of the current row as a number.
+	float(c int) (float64, error)
+	// position identifies the current row within the file, for splitting
+	// rows without an ID or group.
+	position() string
+	// rewind returns to the first row.
+	rewind() error
+	close() error
+}
+
+// tableData is the DataProvider machinery shared by the table providers:
+// the resolved columns, the split, and the factory of row sources.
+type tableData[T tensor.Numeric] struct {
+	kind     string
+	config   TableConfig[T]
+	header   []string
+	features []int
+	targets  []int
+	id       int
+	group    int
+	source   func() (rowSource, error)
+}
+
+// newTableData validates config, then resolves its columns against the
+// column names of the file, returned by readHeader. kind prefixes errors.
+func newTableData[T tensor.Numeric](kind string, config TableConfig[T], readHeader func() ([]string, error)) (*tableData[T], error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("%s data provider: no path", kind)
+	}
+	if config.Input == nil {
+		return nil, fmt.Errorf("%s data provider: no input node", kind)
+	}
+	if len(config.TargetColumns) == 0 {
+		return nil, fmt.Errorf("%s data provider: no target columns", kind)
+	}
+	if config.ValidationRatio < 0 || config.ValidationRatio >= 1 {
+		return nil, fmt.Errorf("%s data provider: validation ratio %g not in [0, 1)", kind, config.ValidationRatio)
+	}
+	if config.ShuffleBuffer < 0 {
+		return nil, fmt.Errorf("%s data provider: negative shuffle buffer %d", kind, config.ShuffleBuffer)
+	}
+	if config.ShuffleBuffer == 0 {
+		config.ShuffleBuffer = defaultTableShuffleBuffer
+	}
+	if config.Ops == nil {
+		var zero T
+		switch any(zero).(type) {
+		case float32, float64:
+		default:
+			return nil, fmt.Errorf("%s data provider: element type %T needs Ops", kind, zero)
+		}
+	}
+
+	header, err := readHeader()
+	if err != nil {
+		return nil, fmt.Errorf("%s data provider: %w", kind, err)
+	}
+	d := &tableData[T]{kind: kind, config: config, header: header, id: -1, group: -1}
+	column := func(name string) (int, error) {
+		i := slices.Index(header, name)
+		if i < 0 {
+			return 0, fmt.Errorf("%s data provider: %s has no column %q", kind, config.Path, name)
+		}
+		return i, nil
+	}
+	used := make(map[int]bool)
+	if config.IDColumn != "" {
+		if d.id, err = column(config.IDColumn); err != nil {
+			return nil, err
+		}
+		used[d.id] = true
+	}
+	if config.GroupColumn != "" {
+		if d.group, err = column(config.GroupColumn); err != nil {
+			return nil, err
+		}
+		used[d.group] = true
+	}
+	for _, name := range config.TargetColumns {
+		i, err := column(name)
+		if err != nil {
+			return nil, err
+		}
+		if used[i] {
+			return nil, fmt.Errorf("%s data provider: column %q used twice", kind, name)
+		}
+		used[i] = true
+		d.targets = append(d.targets, i)
+	}
+	if len(config.FeatureColumns) == 0 {
+		for i := range header {
+			if !used[i] {
+				d.features = append(d.features, i)
+			}
+		}
+	}
+	for _, name := range config.FeatureColumns {
+		i, err := column(name)
+		if err != nil {
+			return nil, err
+		}
+		if used[i] {
+			return nil, fmt.Errorf("%s data provider: column %q used twice", kind, name)
+		}
+		used[i] = true
+		d.features = append(d.features, i)
+	}
+	if len(d.features) == 0 {
+		return nil, fmt.Errorf("%s data provider: %s has no feature columns", kind, config.Path)
+	}
+	return d, nil
+}
+
+// columns returns the columns the provider reads: the features, the
+// targets, and the ID and group columns if any.
+func (d *tableData[T]) columns() []int {
+	cols := slices.Concat(d.features, d.targets)
+	for _, c := range []int{d.id, d.group} {
+		if c >= 0 {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// GetTrainingData implements DataProvider. The iterator yields batches of
+// config.BatchSize rows (32 if unset) with features of shape
+// [rows, features] and targets of shape [rows, targets]. With
+// config.Shuffle it shuffles through the shuffle buffer; with
+// config.DropLast it drops a final short batch.
+func (d *tableData[T]) GetTrainingData(_ context.Context, config BatchConfig) (DataIterator[T], error) {
+	return d.open(config, false)
+}
+
+// GetValidationData implements DataProvider. Validation batches are never
+// shuffled. With a zero ValidationRatio the iterator is empty.
+func (d *tableData[T]) GetValidationData(_ context.Context, config BatchConfig) (DataIterator[T], error) {
+	if d.config.ValidationRatio == 0 {
+		return NewDataIteratorAdapter[T](nil), nil
+	}
+	config.Shuffle = false
+	return d.open(config, true)
+}
+
+// GetMetadata implements DataProvider.
+func (d *tableData[T]) GetMetadata() map[string]interface{} {
+	names := func(cols []int) []string {
+		out := make([]string, len(cols))
+		for i, c := range cols {
+			out[i] = d.header[c]
+		}
+		return out
+	}
+	return map[string]interface{}{
+		"path":             d.config.Path,
+		"feature_columns":  names(d.features),
+		"target_columns":   names(d.targets),
+		"num_features":     len(d.features),
+		"num_targets":      len(d.targets),
+		"validation_ratio": d.config.ValidationRatio,
+	}
+}
+
+// Close implements DataProvider. Each iterator holds its own file handle,
+// released by the iterator's Close.
+func (d *tableData[T]) Close() error {
+	return nil
+}
+
+func (d *tableData[T]) open(config BatchConfig, validation bool) (*CSVIterator[T], error) {
+	if config.BatchSize < 0 {
+		return nil, fmt.Errorf("%s data provider: negative batch size %d", d.kind, config.BatchSize)
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = defaultTableBatchSize
+	}
+	src, err := d.source()
+	if err != nil {
+		return nil, fmt.Errorf("%s data provider: %w", d.kind, err)
+	}
+	it := &CSVIterator[T]{d: d, config: config, validation: validation, src: src}
+	it.restart()
+	return it, nil
+}
+
+// inValidation reports whether the row with split key key is held out.
+func (d *tableData[T]) inValidation(key string) bool {
+	if d.config.ValidationRatio == 0 {
+		return false
+	}
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, d.config.Seed)
+	_, _ = h.Write([]byte(key))
+	// FNV mixes the last bytes into the high bits poorly; finish with the
+	// SplitMix64 finalizer before taking the top 53 bits.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11)/(1<<53) < d.config.ValidationRatio
+}
+
+// tableRow is a read row: its features followed by its targets.
+type tableRow struct {
+	values    []float64
+	id, group string
+}
+
+// CSVIterator is the DataIterator of the table providers,
+// CSVDataProvider and ParquetDataProvider. It keeps the name it had when
+// CSV was the only table format.
+type CSVIterator[T tensor.Numeric] struct {
+	d          *tableData[T]
+	config     BatchConfig
+	validation bool
+	src        rowSource
+	epoch      uint64
+	rng        *rand.Rand
+	buf        []tableRow
+	eof        bool
+	batch      *Batch[T]
+	ids        []string
+	groups     []string
+	err        error
+}
+
+// Next implements DataIterator.
+func (it *CSVIterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil || it.src == nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	rows := make([]tableRow, 0, it.config.BatchSize)
+	for len(rows) < it.config.BatchSize {
+		row, ok := it.nextRow()
+		if !ok {
+			break
+		}
+		rows = append(rows, row)
+	}
+	if it.err != nil || len(rows) == 0 || (it.config.DropLast && len(rows) < it.config.BatchSize) {
+		it.batch, it.ids, it.groups = nil, nil, nil
+		return false
+	}
+
+	nf, nt := len(it.d.features), len(it.d.targets)
+	features := make([]float64, 0, len(rows)*nf)
+	targets := make([]float64, 0, len(rows)*nt)
+	it.ids, it.groups = nil, nil
+	for _, r := range rows {
+		features = append(features, r.values[:nf]...)
+		targets = append(targets, r.values[nf:]...)
+		if it.d.id >= 0 {
+			it.ids = append(it.ids, r.id)
+		}
+		if it.d.group >= 0 {
+			it.groups = append(it.groups, r.group)
+		}
+	}
+	x, err := it.tensor([]int{len(rows), nf}, features)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	y, err := it.tensor([]int{len(rows), nt}, targets)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.batch = &Batch[T]{
+		Inputs:  map[graph.Node[T]]*tensor.TensorNumeric[T]{it.d.config.Input: x},
+		Targets: y,
+	}
+	return true
+}
+
+func (it *CSVIterator[T]) tensor(shape []int, values []float64) (*tensor.TensorNumeric[T], error) {
+	data := make([]T, len(values))
+	dtype.FromFloat64s(it.d.config.Ops, data, values)
+	return tensor.New(shape, data)
+}
+
+// nextRow returns the next row of the iterator's split, drawn at random
+// from the shuffle buffer when shuffling.
+func (it *CSVIterator[T]) nextRow() (tableRow, bool) {
+	if !it.config.Shuffle {
+		return it.readRow()
+	}
+	for !it.eof && len(it.buf) < it.d.config.ShuffleBuffer {
+		row, ok := it.readRow()
+		if !ok {
+			it.eof = true
+			break
+		}
+		it.buf = append(it.buf, row)
+	}
+	if it.err != nil || len(it.buf) == 0 {
+		return tableRow{}, false
+	}
+	i, last := it.rng.IntN(len(it.buf)), len(it.buf)-1
+	row := it.buf[i]
+	it.buf[i] = it.buf[last]
+	it.buf = it.buf[:last]
+	return row, true
+}
+
+// readRow reads the next row of the iterator's split from the source.
+func (it *CSVIterator[T]) readRow() (tableRow, bool) {
+	d := it.d
+	for {
+		ok, err := it.src.next()
+		if err != nil {
+			it.err = fmt.Errorf("%s data provider: %s: %w", d.kind, d.config.Path, err)
+			return tableRow{}, false
+		}
+		if !ok {
+			return tableRow{}, false
+		}
+
+		var row tableRow
+		key := it.src.position()
+		if d.id >= 0 {
+			row.id = it.src.text(d.id)
+			key = row.id
+		}
+		if d.group >= 0 {
+			row.group = it.src.text(d.group)
+			key = row.group
+		}
+		if d.inValidation(key) != it.validation {
+			continue
+		}
+
+		row.values = make([]float64, 0, len(d.features)+len(d.targets))
+		for _, cols := range [][]int{d.features, d.targets} {
+			for _, c := range cols {
+				v, err := it.src.float(c)
+				if err != nil {
+					it.err = fmt.Errorf("%s data provider: %s:%s: column %q: %w", d.kind, d.config.Path, it.src.position(), d.header[c], err)
+					return tableRow{}, false
+				}
+				row.values = append(row.values, v)
+			}
+		}
+		return row, true
+	}
+}
+
+// Batch implements DataIterator.
+func (it *CSVIterator[T]) Batch() *Batch[T] {
+	return it.batch
+}
+
+// IDs returns the ID column values of the current batch's rows, or nil
+// without an ID column.
+func (it *CSVIterator[T]) IDs() []string {
+	return it.ids
+}
+
+// Groups returns the group column values of the current batch's rows, or
+// nil without a group column.
+func (it *CSVIterator[T]) Groups() []string {
+	return it.groups
+}
+
+// Error implements DataIterator.
+func (it *CSVIterator[T]) Error() error {
+	return it.err
+}
+
+// Close implements DataIterator.
+func (it *CSVIterator[T]) Close() error {
+	if it.src == nil {
+		return nil
+	}
+	err := it.src.close()
+	it.src, it.buf = nil, nil
+	return err
+}
+
+// Reset implements DataIterator. It rewinds to the first row and, when
+// shuffling, starts a new epoch with a new order.
+func (it *CSVIterator[T]) Reset() error {
+	if it.src == nil {
+		return fmt.Errorf("%s data provider: iterator closed", it.d.kind)
+	}
+	if err := it.src.rewind(); err != nil {
+		return fmt.Errorf("%s data provider: %s: %w", it.d.kind, it.d.config.Path, err)
+	}
+	it.epoch++
+	it.restart()
+	return nil
+}
+
+// restart reseeds the shuffle for the current epoch and clears the state
+// of the previous one.
+func (it *CSVIterator[T]) restart() {
+	it.rng = rand.New(rand.NewPCG(it.d.config.Seed, it.epoch))
+	it.buf, it.eof = it.buf[:0], false
+	it.batch, it.ids, it.groups, it.err = nil, nil, nil, nil
+}
+
+// newTableDataProviderFactory returns a registry factory for a table data
+// provider. Its config keys mirror the TableConfig fields: "path",
+// "input" (a graph.Node[T]), "feature_columns" and "target_columns" (lists
+// of names; "target_column" names a single target), "id_column",
+// "group_column", "validation_ratio", "seed" and "shuffle_buffer".
+func newTableDataProviderFactory[T tensor.Numeric](kind string, build func(TableConfig[T]) (DataProvider[T], error)) DataProviderFactory[T] {
+	return func(_ context.Context, config map[string]interface{}) (DataProvider[T], error) {
+		var (
+			c   TableConfig[T]
+			err error
+		)
+		str := func(key string) (string, error) {
+			v, ok := config[key]
+			if !ok {
+				return "", nil
+			}
+			s, ok := v.(string)
+			if !ok {
+				return "", fmt.Errorf("%s data provider: %s must be a string, got %T", kind, key, v)
+			}
+			return s, nil
+		}
+		strs := func(key string) ([]string, error) {
+			switch v := config[key].(type) {
+			case nil:
+				return nil, nil
+			case []string:
+				return v, nil
+			case []interface{}:
+				out := make([]string, len(v))
+				for i, e := range v {
+					s, ok := e.(string)
+					if !ok {
+						return nil, fmt.Errorf("%s data provider: %s must be a list of strings, got %T element", kind, key, e)
+					}
+					out[i] = s
+				}
+				return out, nil
+			default:
+				return nil, fmt.Errorf("%s data provider: %s must be a list of strings, got %T", kind, key, v)
+			}
+		}
+		num := func(key string) (float64, error) {
+			switch v := config[key].(type) {
+			case nil:
+				return 0, nil
+			case float64:
+				return v, nil
+			case float32:
+				return float64(v), nil
+			case int:
+				return float64(v), nil
+			case int64:
+				return float64(v), nil
+			case uint64:
+				return float64(v), nil
+			default:
+				return 0, fmt.Errorf("%s data provider: %s must be a number, got %T", kind, key, v)
+			}
+		}
+
+		if c.Path, err = str("path"); err != nil {
+			return nil, err
+		}
+		if v, ok := config["input"]; ok {
+			if c.Input, ok = v.(graph.Node[T]); !ok {
+				return nil, fmt.Errorf("%s data provider: input must be a graph.Node, got %T", kind, v)
+			}
+		}
+		if c.FeatureColumns, err = strs("feature_columns"); err != nil {
+			return nil, err
+		}
+		if c.TargetColumns, err = strs("target_columns"); err != nil {
+			return nil, err
+		}
+		target, err := str("target_column")
+		if err != nil {
+			return nil, err
+		}
+		if target != "" {
+			c.TargetColumns = append(c.TargetColumns, target)
+		}
+		if c.IDColumn, err = str("id_column"); err != nil {
+			return nil, err
+		}
+		if c.GroupColumn, err = str("group_column"); err != nil {
+			return nil, err
+		}
+		if c.ValidationRatio, err = num("validation_ratio"); err != nil {
+			return nil, err
+		}
+		// A uint64 seed keeps its full range.
+		if s, ok := config["seed"].(uint64); ok {
+			c.Seed = s
+		} else {
+			seed, err := num("seed")
+			if err != nil {
+				return nil, err
+			}
+			if seed < 0 || seed != float64(uint64(seed)) {
+				return nil, fmt.Errorf("%s data provider: seed must be a non-negative integer, got %v", kind, seed)
+			}
+			c.Seed = uint64(seed)
+		}
+		buffer, err := num("shuffle_buffer")
+		if err != nil {
+			return nil, err
+		}
+		if buffer != float64(int(buffer)) {
+			return nil, fmt.Errorf("%s data provider: shuffle_buffer must be an integer, got %v", kind, buffer)
+		}
+		c.ShuffleBuffer = int(buffer)
+		return build(c)
+	}
+}
+
+// Statically assert that the type implements the DataIterator interface.
+var _ DataIterator[float32] = (*CSVIterator[float32])(nil)
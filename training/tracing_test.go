@@ -0,0 +1,57 @@
+package training_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDefaultTrainer_TrainStepSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	builder := graph.NewBuilder[float32](compute.NewCPUEngine[float32](numeric.Float32Ops{}))
+	inputNode := builder.Input([]int{1, 1})
+	modelNode := &mockNode[float32]{outputShape: []int{1, 1}}
+	builder.AddNode(modelNode, inputNode)
+	g, err := builder.Build(modelNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimizer := &mockOptimizer[float32]{}
+	trainer := training.NewDefaultTrainer[float32](g, &mockNode[float32]{outputShape: []int{1}}, optimizer, nil)
+
+	input, _ := tensor.New[float32]([]int{1, 1}, []float32{0})
+	targets, _ := tensor.New[float32]([]int{1, 1}, []float32{1})
+	inputs := map[graph.Node[float32]]*tensor.TensorNumeric[float32]{inputNode: input}
+	if _, err := trainer.TrainStep(context.Background(), g, optimizer, inputs, targets); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := rec.Ended()
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name())
+	}
+	want := []string{"training.forward", "training.backward", "training.optimizer_step", "training.step"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+	step := spans[3].SpanContext().SpanID()
+	for _, s := range spans[:3] {
+		if s.Parent().SpanID() != step {
+			t.Errorf("%s is not a child of training.step", s.Name())
+		}
+	}
+}
@@ -0,0 +1,764 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zerfoo/zerfoo/internal/dtype"
+	"github.com/zerfoo/zerfoo/internal/tracing"
+	"github.com/zerfoo/zerfoo/layers/regularization"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/zerfoo/training/scheduler"
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/tensor"
+)
+
+// StandardWorkflowName is the name the standard workflow is registered under
+// in Float32Registry and Float64Registry.
+const StandardWorkflowName = "standard"
+
+// StopEarlyStopping means the early stopping metric stopped improving for
+// WorkflowConfig.MaxNoImprove epochs.
+const StopEarlyStopping StopReason = "early_stopping"
+
+// LossFactory builds the loss node for a model running on engine. The node
+// takes the model output and the batch targets and returns a scalar loss.
+type LossFactory[T tensor.Numeric] func(engine compute.Engine[T]) graph.Node[T]
+
+// OptimizerFactory builds the optimizer for a model running on engine.
+type OptimizerFactory[T tensor.Numeric] func(engine compute.Engine[T], learningRate float64) optimizer.Optimizer[T]
+
+// SchedulerFactory builds the learning rate scheduler for a run from
+// WorkflowConfig.LearningRate and NumEpochs.
+type SchedulerFactory[T tensor.Numeric] func(learningRate float64, numEpochs int) scheduler.Scheduler[T]
+
+// StandardWorkflow is the generic end-to-end TrainingWorkflow. Each epoch it
+// trains on every batch of the training data, evaluates the validation data,
+// computes the registered metrics, and tracks the best validation loss
+// (training loss when there is no validation data). It honours NumEpochs,
+// early stopping and best-model restoring (see EarlyStopper), MaxWallClock
+// and CheckpointPath from WorkflowConfig. With WithLRScheduler it adjusts the
+// optimizer's learning rate between epochs. Mode-dependent layers such as
+// Dropout are in training mode only for the training passes; validation and
+// the returned model run in inference mode.
+type StandardWorkflow[T tensor.Numeric] struct {
+	newLoss      LossFactory[T]
+	newOptimizer OptimizerFactory[T]
+	newSchedule  SchedulerFactory[T]
+	metrics      MetricComputer[T]
+	strategy     GradientStrategy[T]
+	trainerOpts  []DefaultTrainerOption[T]
+	splitRatio   float64
+	replayCap    int
+	heads        []Head[T]
+	ckpts        *CheckpointManager[T]
+	resumeFrom   string
+	now          func() time.Time
+
+	config     WorkflowConfig
+	model      *graph.Graph[T]
+	lossNode   graph.Node[T]
+	models     ModelProvider[T]
+	opt        optimizer.Optimizer[T]
+	trainer    *DefaultTrainer[T]
+	replay     *replayBuffer[T]
+	lastValues map[string]interface{}
+}
+
+// StandardWorkflowOption configures a StandardWorkflow.
+type StandardWorkflowOption[T tensor.Numeric] func(*StandardWorkflow[T])
+
+// WithMetricComputer sets the metrics computed on the validation data each
+// epoch. Their mean over the validation batches is reported under the
+// metric's name in GetMetrics and in the results.
+func WithMetricComputer[T tensor.Numeric](mc MetricComputer[T]) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.metrics = mc
+	}
+}
+
+// WithWorkflowGradientStrategy sets the gradient strategy of the workflow's
+// trainer. The default is DefaultBackpropStrategy.
+func WithWorkflowGradientStrategy[T tensor.Numeric](s GradientStrategy[T]) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.strategy = s
+	}
+}
+
+// WithTrainerOptions passes options, such as gradient accumulation or
+// clipping, to the workflow's DefaultTrainer.
+func WithTrainerOptions[T tensor.Numeric](opts ...DefaultTrainerOption[T]) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.trainerOpts = append(w.trainerOpts, opts...)
+	}
+}
+
+// WithLRScheduler schedules the learning rate. The optimizer starts at the
+// scheduler's initial rate, and after each epoch the workflow calls
+// Step(nextEpoch, loss) with the monitored loss and applies the new rate.
+// The optimizer must implement optimizer.LRSetter. The rate used in the
+// latest epoch is reported as "learning_rate".
+func WithLRScheduler[T tensor.Numeric](f SchedulerFactory[T]) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.newSchedule = f
+	}
+}
+
+// WithValidationSplit holds out the last ratio of the training batches for
+// validation when the data provider has no validation data. The training
+// batches are read into memory once to split them.
+func WithValidationSplit[T tensor.Numeric](ratio float64) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.splitRatio = ratio
+	}
+}
+
+// WithReplayBuffer keeps a uniform sample of up to capacity training
+// batches seen by Train and ContinueTraining, so that ContinueOptions.Replay
+// can mix past data into incremental updates. The batches are copied.
+func WithReplayBuffer[T tensor.Numeric](capacity int) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.replayCap = capacity
+	}
+}
+
+// WithHeads trains a multi-head model: the loss is a MultiHeadLoss over
+// heads, replacing the workflow's loss factory. Validation reports each
+// head's loss as "<head>/loss" and its metrics as "<head>/<metric>".
+func WithHeads[T tensor.Numeric](heads ...Head[T]) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.heads = heads
+		w.newLoss = func(engine compute.Engine[T]) graph.Node[T] {
+			mh, _ := NewMultiHeadLoss(engine, heads...) // heads are checked by Initialize
+			return mh
+		}
+	}
+}
+
+// WithCheckpoints makes Train write a checkpoint with m whenever m is due,
+// at a step boundary with no gradient accumulation pending, and at the end
+// of every epoch if m keeps the best checkpoints by a metric. Resume
+// continues a run from such a checkpoint.
+func WithCheckpoints[T tensor.Numeric](m *CheckpointManager[T]) StandardWorkflowOption[T] {
+	return func(w *StandardWorkflow[T]) {
+		w.ckpts = m
+	}
+}
+
+// NewStandardWorkflow creates a StandardWorkflow that trains with the loss
+// and optimizer the factories build for the model's engine.
+func NewStandardWorkflow[T tensor.Numeric](newLoss LossFactory[T], newOptimizer OptimizerFactory[T], opts ...StandardWorkflowOption[T]) *StandardWorkflow[T] {
+	w := &StandardWorkflow[T]{
+		newLoss:      newLoss,
+		newOptimizer: newOptimizer,
+		now:          time.Now,
+		lastValues:   make(map[string]interface{}),
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// Initialize implements TrainingWorkflow.Initialize.
+func (w *StandardWorkflow[T]) Initialize(ctx context.Context, config WorkflowConfig) error {
+	switch {
+	case w.newLoss == nil:
+		return errors.New("standard workflow: no loss factory")
+	case w.newOptimizer == nil:
+		return errors.New("standard workflow: no optimizer factory")
+	case config.NumEpochs <= 0:
+		return fmt.Errorf("standard workflow: NumEpochs must be positive, got %d", config.NumEpochs)
+	case config.LearningRate <= 0:
+		return fmt.Errorf("standard workflow: LearningRate must be positive, got %g", config.LearningRate)
+	case config.AccumulationSteps < 0:
+		return fmt.Errorf("standard workflow: AccumulationSteps must not be negative, got %d", config.AccumulationSteps)
+	case config.ClipNorm < 0 || config.ClipValue < 0:
+		return fmt.Errorf("standard workflow: ClipNorm and ClipValue must not be negative, got %g and %g", config.ClipNorm, config.ClipValue)
+	case w.splitRatio < 0 || w.splitRatio >= 1:
+		return fmt.Errorf("standard workflow: validation split must be in [0, 1), got %g", w.splitRatio)
+	case w.replayCap < 0:
+		return fmt.Errorf("standard workflow: replay buffer capacity must not be negative, got %d", w.replayCap)
+	}
+	if err := validateEarlyStop(config); err != nil {
+		return fmt.Errorf("standard workflow: %w", err)
+	}
+	if w.heads != nil {
+		if err := validateHeads(w.heads); err != nil {
+			return fmt.Errorf("standard workflow: %w", err)
+		}
+	}
+	w.config = config
+	return nil
+}
+
+// Train implements TrainingWorkflow.Train. It creates the model, runs up to
+// NumEpochs epochs, and saves the final model to CheckpointPath if set.
+// The trained model and its optimizer are kept for Validate and
+// ContinueTraining.
+func (w *StandardWorkflow[T]) Train(ctx context.Context, dataset DataProvider[T], modelProvider ModelProvider[T]) (*TrainingResult[T], error) {
+	if w.config.NumEpochs <= 0 {
+		return nil, errors.New("standard workflow: Train called before Initialize")
+	}
+	start := w.now()
+
+	model, err := modelProvider.CreateModel(ctx, w.config.modelConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model: %w", err)
+	}
+	engine := model.Engine()
+	if engine == nil {
+		return nil, errors.New("standard workflow: model graph has no engine")
+	}
+	w.model = model
+	// Epochs switch the model between training and inference mode; leave
+	// it in inference mode for whoever uses it next.
+	defer regularization.SetTrainingMode(model, false)
+	w.lossNode = w.newLoss(engine)
+	opt := w.newOptimizer(engine, w.config.LearningRate)
+	var sched scheduler.Scheduler[T]
+	var lrSetter optimizer.LRSetter
+	if w.newSchedule != nil {
+		var ok bool
+		if lrSetter, ok = opt.(optimizer.LRSetter); !ok {
+			return nil, fmt.Errorf("standard workflow: optimizer %T does not support learning rate scheduling", opt)
+		}
+		sched = w.newSchedule(w.config.LearningRate, w.config.NumEpochs)
+		lrSetter.SetLRFloat64(dtype.ToFloat64(sched.GetLR()))
+	}
+	strategy := w.strategy
+	if strategy == nil {
+		backprop := NewDefaultBackpropStrategy[T]()
+		backprop.SetEngine(engine)
+		strategy = backprop
+	}
+	// Options passed through WithTrainerOptions come later and override the
+	// config.
+	trainerOpts := []DefaultTrainerOption[T]{
+		WithGradAccumulation[T](w.config.AccumulationSteps),
+		WithGradClipNorm[T](w.config.ClipNorm),
+	}
+	if w.config.ClipValue > 0 {
+		trainerOpts = append(trainerOpts, WithGradClipper[T](NewValueClipper[T](w.config.ClipValue)))
+	}
+	trainerOpts = append(trainerOpts, w.trainerOpts...)
+	trainer := NewDefaultTrainer(model, w.lossNode, opt, strategy, trainerOpts...)
+	w.models, w.opt, w.trainer = modelProvider, opt, trainer
+	w.replay = nil
+	if w.replayCap > 0 {
+		w.replay = newReplayBuffer[T](w.replayCap, w.config.RandomSeed)
+	}
+
+	trainData, validData, err := w.openData(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = trainData.Close() }()
+	if validData != nil {
+		defer func() { _ = validData.Close() }()
+	}
+
+	var resumed *Checkpoint
+	if w.resumeFrom != "" {
+		ckpts := w.ckpts
+		if ckpts == nil {
+			ckpts = &CheckpointManager[T]{}
+		}
+		if resumed, err = ckpts.Restore(w.resumeFrom, model, opt); err != nil {
+			return nil, err
+		}
+	}
+
+	stopper := NewEarlyStopper[T](w.config)
+	timeUp := func() bool {
+		return w.config.MaxWallClock > 0 && w.now().Sub(start) >= w.config.MaxWallClock
+	}
+
+	result := &TrainingResult[T]{
+		StopReason: StopCompleted,
+		Metrics:    make(map[string]float64),
+		Extensions: make(map[string]interface{}),
+	}
+	// record tracks the monitored loss of an epoch.
+	record := func(epoch int, monitored T) {
+		result.FinalLoss = monitored
+		if epoch == 0 || monitored < result.BestLoss {
+			result.BestLoss = monitored
+			result.BestEpoch = epoch
+		}
+	}
+	// endEpoch completes an epoch and reports whether early stopping, as
+	// decided by the stopper's verdict stop, ends the run.
+	var epochLosses []float64
+	endEpoch := func(epoch int, monitored T, stop bool) bool {
+		result.TotalEpochs = epoch + 1
+		epochLosses = append(epochLosses, float64(monitored))
+		if stop {
+			result.StopReason = StopEarlyStopping
+			return true
+		}
+		if sched != nil {
+			sched.Step(epoch+1, float64(monitored))
+			lrSetter.SetLRFloat64(dtype.ToFloat64(sched.GetLR()))
+		}
+		return false
+	}
+
+	// A resumed run replays the bookkeeping of the epochs it completed and
+	// skips the steps it took in the epoch in progress. Only the epoch
+	// losses are saved, so a stopper monitoring another metric starts
+	// afresh, and the best parameters of replayed epochs are not restored.
+	firstEpoch, step := 0, 0
+	var progress epochProgress
+	var metrics map[string]float64 // snapshot of the latest epoch
+	if resumed != nil {
+		metrics = resumed.Metrics
+		for epoch, l := range resumed.EpochLosses {
+			record(epoch, T(l))
+			endEpoch(epoch, T(l), stopper != nil && stopper.Metric() == "" && stopper.Step(epoch, l, nil))
+		}
+		firstEpoch, step = resumed.Epoch, resumed.Step
+		progress = epochProgress{steps: resumed.EpochStep, lossSum: resumed.EpochLossSum}
+		if firstEpoch > 0 && w.replay != nil {
+			if err := fillReplay(ctx, w.replay, trainData); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var validation *ValidationResult[T]
+	for epoch := firstEpoch; epoch < w.config.NumEpochs; epoch++ {
+		// The first epoch sees every batch once; later ones would only
+		// skew the replay sample towards repeats.
+		var keep func(*Batch[T])
+		if epoch == 0 && w.replay != nil {
+			keep = w.replay.add
+		}
+		var checkpoint func(epochProgress) error
+		if w.ckpts != nil {
+			checkpoint = func(p epochProgress) error {
+				step++
+				if !w.ckpts.Due(step) {
+					return nil
+				}
+				_, err := w.ckpts.Save(&Checkpoint{
+					Epoch:        epoch,
+					EpochStep:    p.steps,
+					Step:         step,
+					EpochLossSum: p.lossSum,
+					EpochLosses:  slices.Clone(epochLosses),
+					Metrics:      metrics,
+				}, model, opt)
+				return err
+			}
+		}
+		trainLoss, steps, stopped, err := w.trainEpoch(ctx, trainer, model, opt, trainData, timeUp, keep, progress, checkpoint)
+		progress = epochProgress{}
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+		}
+		if stopped {
+			result.StopReason = StopMaxWallClock
+			if steps == 0 {
+				break
+			}
+		}
+
+		monitored := trainLoss
+		epochValues := map[string]interface{}{"epoch": epoch, "train_loss": float64(trainLoss)}
+		if validData != nil {
+			validation, err = w.evaluate(ctx, model, validData)
+			if err != nil {
+				return nil, fmt.Errorf("epoch %d: validation: %w", epoch, err)
+			}
+			monitored = validation.Loss
+			epochValues["val_loss"] = float64(validation.Loss)
+			for name, v := range validation.Metrics {
+				epochValues[name] = v
+			}
+		}
+		if sched != nil {
+			epochValues["learning_rate"] = dtype.ToFloat64(sched.GetLR())
+		}
+		w.lastValues = epochValues
+		metrics = make(map[string]float64, len(epochValues))
+		for name, v := range epochValues {
+			if f, ok := v.(float64); ok {
+				metrics[name] = f
+			}
+		}
+
+		stop := false
+		if stopper != nil {
+			v, err := stopper.Value(float64(monitored), metrics)
+			if err != nil {
+				return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+			}
+			stop = stopper.Step(epoch, v, model)
+		}
+
+		record(epoch, monitored)
+		if stopped || endEpoch(epoch, monitored, stop) {
+			break
+		}
+		// A manager that keeps the best checkpoints by a metric gets one
+		// with each epoch's fresh metrics.
+		if w.ckpts != nil && w.ckpts.Monitors() {
+			if _, err := w.ckpts.Save(&Checkpoint{
+				Epoch:       epoch + 1,
+				Step:        step,
+				EpochLosses: slices.Clone(epochLosses),
+				Metrics:     metrics,
+			}, model, opt); err != nil {
+				return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+			}
+		}
+	}
+
+	for name, v := range w.lastValues {
+		if f, ok := v.(float64); ok {
+			result.Metrics[name] = f
+		}
+	}
+	if err := restoreBest(stopper, model, result); err != nil {
+		return nil, err
+	}
+	if w.config.CheckpointPath != "" {
+		if err := modelProvider.SaveModel(ctx, model, w.config.CheckpointPath); err != nil {
+			return nil, fmt.Errorf("failed to save model: %w", err)
+		}
+		result.ModelPath = w.config.CheckpointPath
+	}
+	result.TrainingTime = w.now().Sub(start).Seconds()
+	return result, nil
+}
+
+// Resume continues the run saved in the checkpoint at path, such as one
+// written by WithCheckpoints: it creates the model as Train does, restores
+// its parameters, the optimizer state and the RNG of the workflow's
+// checkpoint manager, and trains from the saved step to the end of the
+// run. With deterministic data the result matches an uninterrupted Train
+// bit for bit. The wall-clock limit counts from the resume.
+func (w *StandardWorkflow[T]) Resume(ctx context.Context, path string, dataset DataProvider[T], modelProvider ModelProvider[T]) (*TrainingResult[T], error) {
+	w.resumeFrom = path
+	defer func() { w.resumeFrom = "" }()
+	return w.Train(ctx, dataset, modelProvider)
+}
+
+// openData returns the training and validation iterators. The validation
+// iterator is nil when there is no validation data.
+func (w *StandardWorkflow[T]) openData(ctx context.Context, dataset DataProvider[T]) (train, valid DataIterator[T], err error) {
+	train, err = dataset.GetTrainingData(ctx, w.config.BatchConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get training data: %w", err)
+	}
+	valid, err = dataset.GetValidationData(ctx, w.config.BatchConfig)
+	if err != nil {
+		_ = train.Close()
+		return nil, nil, fmt.Errorf("failed to get validation data: %w", err)
+	}
+	if valid != nil {
+		if ok, err := hasBatches(ctx, valid); err != nil || ok {
+			if err != nil {
+				_ = train.Close()
+				_ = valid.Close()
+				return nil, nil, fmt.Errorf("failed to read validation data: %w", err)
+			}
+			return train, valid, nil
+		}
+		_ = valid.Close()
+	}
+	if w.splitRatio == 0 {
+		return train, nil, nil
+	}
+
+	var batches []*Batch[T]
+	for train.Next(ctx) {
+		if b := train.Batch(); b != nil {
+			batches = append(batches, b)
+		}
+	}
+	err = train.Error()
+	_ = train.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read training data: %w", err)
+	}
+	held := int(float64(len(batches)) * w.splitRatio)
+	if held == 0 || held == len(batches) {
+		return nil, nil, fmt.Errorf("standard workflow: validation split %g of %d batches leaves an empty split", w.splitRatio, len(batches))
+	}
+	cut := len(batches) - held
+	return NewDataIteratorAdapter(batches[:cut]), NewDataIteratorAdapter(batches[cut:]), nil
+}
+
+// hasBatches reports whether it yields at least one batch, and rewinds it.
+func hasBatches[T tensor.Numeric](ctx context.Context, it DataIterator[T]) (bool, error) {
+	ok := it.Next(ctx)
+	if err := it.Error(); err != nil {
+		return false, err
+	}
+	return ok, it.Reset()
+}
+
+// epochProgress is the progress of a training epoch.
+type epochProgress struct {
+	steps   int
+	lossSum float64
+}
+
+// trainEpoch runs one pass over the training data and returns the mean
+// batch loss and the number of steps taken. stopped reports that the
+// wall-clock limit cut the epoch short. keep, if not nil, is called with
+// every batch trained on. The epoch continues from, skipping the batches
+// already trained on. checkpoint, if not nil, is called after every step
+// with no gradient accumulation pending.
+func (w *StandardWorkflow[T]) trainEpoch(ctx context.Context, trainer *DefaultTrainer[T], model *graph.Graph[T], opt optimizer.Optimizer[T], data DataIterator[T], timeUp func() bool, keep func(*Batch[T]), from epochProgress, checkpoint func(epochProgress) error) (mean T, steps int, stopped bool, err error) {
+	if err := data.Reset(); err != nil {
+		return mean, 0, false, fmt.Errorf("failed to reset training data: %w", err)
+	}
+	regularization.SetTrainingMode(model, true)
+	steps, total := from.steps, from.lossSum
+	skip := from.steps
+	for {
+		if err := ctx.Err(); err != nil {
+			return mean, steps, false, err
+		}
+		if timeUp() {
+			stopped = true
+			break
+		}
+		batch := nextBatch(ctx, data)
+		if batch == nil {
+			break
+		}
+		if keep != nil {
+			keep(batch)
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		// Not every optimizer clears gradients in Step, and layers such as
+		// Linear add into them, so each step starts from zero.
+		optimizer.ZeroGrad(model.Parameters())
+		l, err := trainer.TrainStep(ctx, model, opt, batch.Inputs, batch.Targets)
+		if err != nil {
+			return mean, steps, false, fmt.Errorf("training step %d: %w", steps, err)
+		}
+		total += float64(l)
+		steps++
+		if checkpoint != nil && trainer.PendingMicroBatches() == 0 {
+			if err := checkpoint(epochProgress{steps: steps, lossSum: total}); err != nil {
+				return mean, steps, false, err
+			}
+		}
+	}
+	if err := data.Error(); err != nil {
+		return mean, steps, false, fmt.Errorf("training data: %w", err)
+	}
+	if trainer.PendingMicroBatches() > 0 {
+		if err := trainer.Flush(ctx, model, opt); err != nil {
+			return mean, steps, false, err
+		}
+	}
+	if steps > 0 {
+		mean = T(total / float64(steps))
+	}
+	return mean, steps, stopped, nil
+}
+
+// nextBatch loads the next batch of data under a training.data_load span.
+// It returns nil when data is exhausted or fails.
+func nextBatch[T tensor.Numeric](ctx context.Context, data DataIterator[T]) *Batch[T] {
+	ctx, span := tracing.Start(ctx, "training", "training.data_load")
+	defer span.End()
+	if !data.Next(ctx) {
+		return nil
+	}
+	return data.Batch()
+}
+
+// evaluate runs the model forward over data and returns the mean batch loss
+// and metrics.
+func (w *StandardWorkflow[T]) evaluate(ctx context.Context, model *graph.Graph[T], data DataIterator[T]) (*ValidationResult[T], error) {
+	start := w.now()
+	if err := data.Reset(); err != nil {
+		return nil, fmt.Errorf("failed to reset validation data: %w", err)
+	}
+	regularization.SetTrainingMode(model, false)
+	var (
+		totalLoss float64
+		batches   int
+		sums      = make(map[string]float64)
+	)
+	for data.Next(ctx) {
+		batch := data.Batch()
+		if batch == nil {
+			break
+		}
+		inputs := make([]*tensor.TensorNumeric[T], 0, len(batch.Inputs))
+		for _, in := range model.Inputs() {
+			inputs = append(inputs, batch.Inputs[in])
+		}
+		output, err := model.Forward(ctx, inputs...)
+		if err != nil {
+			return nil, fmt.Errorf("forward pass failed: %w", err)
+		}
+		l, err := w.lossNode.Forward(ctx, output, batch.Targets)
+		if err != nil {
+			return nil, fmt.Errorf("loss computation failed: %w", err)
+		}
+		totalLoss += float64(l.Data()[0])
+		if w.metrics != nil {
+			values, err := w.metrics.ComputeMetrics(ctx, output, batch.Targets, nil)
+			if err != nil {
+				return nil, fmt.Errorf("metrics: %w", err)
+			}
+			for name, v := range values {
+				sums[name] += v
+			}
+		}
+		if mh, ok := w.lossNode.(*MultiHeadLoss[T]); ok {
+			for name, v := range mh.HeadLosses() {
+				sums[name+"/loss"] += v
+			}
+			values, err := mh.ComputeHeadMetrics(ctx, output, batch.Targets)
+			if err != nil {
+				return nil, fmt.Errorf("metrics: %w", err)
+			}
+			for name, v := range values {
+				sums[name] += v
+			}
+		}
+		model.ClearMemo()
+		batches++
+	}
+	if err := data.Error(); err != nil {
+		return nil, fmt.Errorf("validation data: %w", err)
+	}
+
+	result := &ValidationResult[T]{
+		Metrics:        make(map[string]float64, len(sums)),
+		SampleCount:    batches,
+		ValidationTime: w.now().Sub(start).Seconds(),
+		Extensions:     make(map[string]interface{}),
+	}
+	if batches > 0 {
+		result.Loss = T(totalLoss / float64(batches))
+		for name, sum := range sums {
+			result.Metrics[name] = sum / float64(batches)
+		}
+	}
+	return result, nil
+}
+
+// Validate implements TrainingWorkflow.Validate. It evaluates the model from
+// the last Train call, or a new model from modelProvider if Train has not
+// run, on the dataset's validation data.
+func (w *StandardWorkflow[T]) Validate(ctx context.Context, dataset DataProvider[T], modelProvider ModelProvider[T]) (*ValidationResult[T], error) {
+	model := w.model
+	if model == nil {
+		if w.newLoss == nil {
+			return nil, errors.New("standard workflow: no loss factory")
+		}
+		var err error
+		model, err = modelProvider.CreateModel(ctx, w.config.modelConfig())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create model: %w", err)
+		}
+		if model.Engine() == nil {
+			return nil, errors.New("standard workflow: model graph has no engine")
+		}
+		w.model = model
+		w.lossNode = w.newLoss(model.Engine())
+	}
+	data, err := dataset.GetValidationData(ctx, w.config.BatchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validation data: %w", err)
+	}
+	defer func() { _ = data.Close() }()
+	return w.evaluate(ctx, model, data)
+}
+
+// GetMetrics implements TrainingWorkflow.GetMetrics. It returns the values of
+// the most recent epoch: "epoch", "train_loss", and, with validation data,
+// "val_loss" and every metric.
+func (w *StandardWorkflow[T]) GetMetrics() map[string]interface{} {
+	out := make(map[string]interface{}, len(w.lastValues))
+	for k, v := range w.lastValues {
+		out[k] = v
+	}
+	return out
+}
+
+// Shutdown implements TrainingWorkflow.Shutdown. It releases the trained
+// model.
+func (w *StandardWorkflow[T]) Shutdown(_ context.Context) error {
+	w.model = nil
+	w.lossNode = nil
+	w.lastValues = make(map[string]interface{})
+	return nil
+}
+
+// newStandardWorkflowFactory returns the registry factory for the standard
+// workflow. The config keys "loss" (a name from the training/loss registry;
+// default "mse") and "optimizer" ("adamw" or "sgd"; default "adamw") select
+// the loss and optimizer.
+func newStandardWorkflowFactory[T tensor.Numeric]() WorkflowFactory[T] {
+	return func(_ context.Context, config map[string]interface{}) (TrainingWorkflow[T], error) {
+		name := func(key, def string) (string, error) {
+			v, ok := config[key]
+			if !ok {
+				return def, nil
+			}
+			s, ok := v.(string)
+			if !ok {
+				return "", fmt.Errorf("standard workflow: %s must be a string, got %T", key, v)
+			}
+			return s, nil
+		}
+
+		lossName, err := name("loss", "mse")
+		if err != nil {
+			return nil, err
+		}
+		lossFactory, err := loss.Get[T](lossName)
+		if err != nil {
+			return nil, fmt.Errorf("standard workflow: %w", err)
+		}
+		newLoss := LossFactory[T](lossFactory)
+
+		optName, err := name("optimizer", "adamw")
+		if err != nil {
+			return nil, err
+		}
+		var newOpt OptimizerFactory[T]
+		switch optName {
+		case "adamw":
+			newOpt = func(e compute.Engine[T], lr float64) optimizer.Optimizer[T] {
+				return optimizer.NewAdamWFromFloat64(e, lr, 0.9, 0.999, 1e-8, 0.01)
+			}
+		case "sgd":
+			newOpt = func(e compute.Engine[T], lr float64) optimizer.Optimizer[T] {
+				return optimizer.NewSGD(e, e.Ops(), float32(lr))
+			}
+		default:
+			return nil, fmt.Errorf("standard workflow: unknown optimizer %q", optName)
+		}
+
+		return NewStandardWorkflow(newLoss, newOpt), nil
+	}
+}
+
+func init() {
+	_ = Float32Registry.RegisterWorkflow(StandardWorkflowName, newStandardWorkflowFactory[float32]())
+	_ = Float64Registry.RegisterWorkflow(StandardWorkflowName, newStandardWorkflowFactory[float64]())
+}
+
+// Statically assert that the type implements the TrainingWorkflow interface.
+var _ TrainingWorkflow[float32] = (*StandardWorkflow[float32])(nil)
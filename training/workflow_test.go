@@ -0,0 +1,510 @@
+package training_test
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zerfoo/ztensor/compute"
+	"github.com/zerfoo/ztensor/graph"
+	"github.com/zerfoo/ztensor/numeric"
+	"github.com/zerfoo/ztensor/tensor"
+	"github.com/zerfoo/ztensor/types"
+
+	"github.com/zerfoo/zerfoo/layers/core"
+	"github.com/zerfoo/zerfoo/training"
+	"github.com/zerfoo/zerfoo/training/loss"
+	"github.com/zerfoo/zerfoo/training/optimizer"
+	"github.com/zerfoo/zerfoo/training/scheduler"
+)
+
+// regressionRig is a 2-1 linear model and batches of y = 2*x0 - x1 + 0.5.
+type regressionRig struct {
+	g     *graph.Graph[float32]
+	input graph.Node[float32]
+}
+
+func newRegressionRig(t *testing.T) *regressionRig {
+	t.Helper()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, 2})
+	dense, err := core.NewDense[float32]("dense", engine, ops, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.Build(b.AddNode(dense, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range g.Parameters() {
+		clear(p.Value.Data())
+	}
+	return &regressionRig{g: g, input: input}
+}
+
+func (r *regressionRig) batches(t *testing.T, seed uint64, n int) []*training.Batch[float32] {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(seed, 0))
+	const rows = 8
+	out := make([]*training.Batch[float32], n)
+	for i := range out {
+		x := make([]float32, rows*2)
+		y := make([]float32, rows)
+		for j := range rows {
+			x0, x1 := rng.Float64()*2-1, rng.Float64()*2-1
+			x[2*j], x[2*j+1] = float32(x0), float32(x1)
+			y[j] = float32(2*x0 - x1 + 0.5)
+		}
+		xt, err := tensor.New([]int{rows, 2}, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yt, err := tensor.New([]int{rows, 1}, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = &training.Batch[float32]{
+			Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{r.input: xt},
+			Targets: yt,
+		}
+	}
+	return out
+}
+
+// staticData serves fixed training and validation batches.
+type staticData struct {
+	train, valid []*training.Batch[float32]
+}
+
+func (d *staticData) GetTrainingData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter(d.train), nil
+}
+
+func (d *staticData) GetValidationData(context.Context, training.BatchConfig) (training.DataIterator[float32], error) {
+	return training.NewDataIteratorAdapter(d.valid), nil
+}
+
+func (d *staticData) GetMetadata() map[string]interface{} { return nil }
+func (d *staticData) Close() error                        { return nil }
+
+// rigModels hands out the rig's graph and records saves.
+type rigModels struct {
+	g     *graph.Graph[float32]
+	saved []string
+}
+
+func (m *rigModels) CreateModel(context.Context, training.ModelConfig) (*graph.Graph[float32], error) {
+	return m.g, nil
+}
+
+func (m *rigModels) LoadModel(context.Context, string) (*graph.Graph[float32], error) {
+	return m.g, nil
+}
+
+func (m *rigModels) SaveModel(_ context.Context, _ *graph.Graph[float32], path string) error {
+	m.saved = append(m.saved, path)
+	return nil
+}
+
+func (m *rigModels) GetModelInfo() training.ModelInfo { return training.ModelInfo{} }
+
+// maeMetric computes the mean absolute error.
+type maeMetric struct{}
+
+func (maeMetric) ComputeMetrics(_ context.Context, predictions, targets *tensor.TensorNumeric[float32], _ map[string]interface{}) (map[string]float64, error) {
+	var sum float64
+	p, y := predictions.Data(), targets.Data()
+	for i := range p {
+		sum += math.Abs(float64(p[i] - y[i]))
+	}
+	return map[string]float64{"mae": sum / float64(len(p))}, nil
+}
+
+func (maeMetric) RegisterMetric(string, training.MetricFunction[float32]) {}
+func (maeMetric) UnregisterMetric(string)                                 {}
+func (maeMetric) AvailableMetrics() []string                              { return []string{"mae"} }
+
+func newSGDWorkflow(opts ...training.StandardWorkflowOption[float32]) *training.StandardWorkflow[float32] {
+	return training.NewStandardWorkflow(
+		func(e compute.Engine[float32]) graph.Node[float32] { return loss.NewMSE(e, e.Ops()) },
+		func(e compute.Engine[float32], lr float64) optimizer.Optimizer[float32] {
+			return optimizer.NewSGD(e, e.Ops(), float32(lr))
+		},
+		opts...,
+	)
+}
+
+func TestStandardWorkflow_TrainsAndValidates(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	data := &staticData{train: rig.batches(t, 1, 8), valid: rig.batches(t, 2, 2)}
+	models := &rigModels{g: rig.g}
+
+	w := newSGDWorkflow(training.WithMetricComputer[float32](maeMetric{}))
+	if err := w.Initialize(ctx, training.WorkflowConfig{
+		NumEpochs:      40,
+		LearningRate:   0.1,
+		CheckpointPath: "final.gguf",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	initial, err := w.Validate(ctx, data, models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, data, models)
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+
+	if result.StopReason != training.StopCompleted || result.TotalEpochs != 40 {
+		t.Errorf("StopReason = %q, TotalEpochs = %d", result.StopReason, result.TotalEpochs)
+	}
+	if result.FinalLoss > initial.Loss/100 {
+		t.Errorf("validation loss %v -> %v, want it to fall 100x", initial.Loss, result.FinalLoss)
+	}
+	if result.BestLoss > result.FinalLoss || result.BestEpoch < 30 {
+		t.Errorf("BestLoss = %v at epoch %d, FinalLoss = %v", result.BestLoss, result.BestEpoch, result.FinalLoss)
+	}
+	for _, key := range []string{"train_loss", "val_loss", "mae"} {
+		if _, ok := result.Metrics[key]; !ok {
+			t.Errorf("result metrics %v missing %q", result.Metrics, key)
+		}
+	}
+	if got := w.GetMetrics()["epoch"]; got != 39 {
+		t.Errorf("GetMetrics()[epoch] = %v, want 39", got)
+	}
+	if result.ModelPath != "final.gguf" || len(models.saved) != 1 {
+		t.Errorf("ModelPath = %q, saved %v", result.ModelPath, models.saved)
+	}
+
+	final, err := w.Validate(ctx, data, models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Loss != result.FinalLoss || final.SampleCount != 2 || final.Metrics["mae"] != result.Metrics["mae"] {
+		t.Errorf("Validate after Train = %+v, want loss %v", final, result.FinalLoss)
+	}
+}
+
+func TestStandardWorkflow_AccumulationSteps(t *testing.T) {
+	ctx := context.Background()
+	train := func(cfg training.WorkflowConfig, batches func(*regressionRig) []*training.Batch[float32], opts ...training.StandardWorkflowOption[float32]) []float32 {
+		t.Helper()
+		rig := newRegressionRig(t)
+		w := newSGDWorkflow(opts...)
+		if err := w.Initialize(ctx, cfg); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Train(ctx, &staticData{train: batches(rig)}, &rigModels{g: rig.g}); err != nil {
+			t.Fatal(err)
+		}
+		var params []float32
+		for _, p := range rig.g.Parameters() {
+			params = append(params, p.Value.Data()...)
+		}
+		return params
+	}
+	cfg := training.WorkflowConfig{NumEpochs: 1, LearningRate: 0.1}
+
+	// Two accumulated micro-batches of 8 rows take the same step as one
+	// batch of their 16 rows under a mean-reduced loss.
+	accumCfg := cfg
+	accumCfg.AccumulationSteps = 2
+	accum := train(accumCfg, func(r *regressionRig) []*training.Batch[float32] { return r.batches(t, 1, 2) })
+	large := train(cfg, func(r *regressionRig) []*training.Batch[float32] {
+		b := r.batches(t, 1, 2)
+		var x, y []float32
+		for _, mb := range b {
+			x = append(x, mb.Inputs[r.input].Data()...)
+			y = append(y, mb.Targets.Data()...)
+		}
+		xt, err := tensor.New([]int{16, 2}, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yt, err := tensor.New([]int{16, 1}, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []*training.Batch[float32]{{
+			Inputs:  map[graph.Node[float32]]*tensor.TensorNumeric[float32]{r.input: xt},
+			Targets: yt,
+		}}
+	})
+	for i := range large {
+		if math.Abs(float64(accum[i]-large[i])) > 1e-6 {
+			t.Fatalf("accumulated params = %v, want %v", accum, large)
+		}
+	}
+
+	// The config is the same as the trainer option, and a leftover
+	// micro-batch at the end of the epoch is still applied.
+	three := func(r *regressionRig) []*training.Batch[float32] { return r.batches(t, 1, 3) }
+	fromConfig := train(accumCfg, three)
+	fromOption := train(cfg, three, training.WithTrainerOptions(training.WithGradAccumulation[float32](2)))
+	unflushed := train(accumCfg, func(r *regressionRig) []*training.Batch[float32] { return r.batches(t, 1, 2) })
+	if !slices.Equal(fromConfig, fromOption) {
+		t.Fatalf("AccumulationSteps params = %v, WithGradAccumulation params = %v", fromConfig, fromOption)
+	}
+	if slices.Equal(fromConfig, unflushed) {
+		t.Error("the third micro-batch was not applied at the end of the epoch")
+	}
+}
+
+func TestStandardWorkflow_EarlyStopping(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	w := newSGDWorkflow()
+	// No epoch can improve by 1e9, so training stops once patience runs out.
+	if err := w.Initialize(ctx, training.WorkflowConfig{
+		NumEpochs:    50,
+		LearningRate: 0.1,
+		MaxNoImprove: 2,
+		EarlyStopTol: 1e9,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 2)}, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StopReason != training.StopEarlyStopping || result.TotalEpochs != 3 {
+		t.Errorf("StopReason = %q, TotalEpochs = %d, want early stop after 3", result.StopReason, result.TotalEpochs)
+	}
+	if _, ok := result.Metrics["val_loss"]; ok {
+		t.Error("val_loss reported without validation data")
+	}
+}
+
+func TestStandardWorkflow_RestoreBest(t *testing.T) {
+	ctx := context.Background()
+	// At learning rate 3 SGD diverges, so the first epoch is the best.
+	train := func(config training.WorkflowConfig) ([]float32, *training.TrainingResult[float32]) {
+		rig := newRegressionRig(t)
+		w := newSGDWorkflow()
+		config.LearningRate = 3
+		if err := w.Initialize(ctx, config); err != nil {
+			t.Fatal(err)
+		}
+		result, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 2)}, &rigModels{g: rig.g})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var params []float32
+		for _, p := range rig.g.Parameters() {
+			params = append(params, p.Value.Data()...)
+		}
+		return params, result
+	}
+	first, _ := train(training.WorkflowConfig{NumEpochs: 1})
+	params, result := train(training.WorkflowConfig{
+		NumEpochs:       20,
+		MaxNoImprove:    3,
+		EarlyStopMetric: "train_loss",
+		RestoreBest:     true,
+	})
+	if result.StopReason != training.StopEarlyStopping || result.TotalEpochs != 4 {
+		t.Errorf("StopReason = %q, TotalEpochs = %d, want early stop after 4", result.StopReason, result.TotalEpochs)
+	}
+	if result.Extensions[training.RestoredEpochKey] != 0 {
+		t.Errorf("restored epoch %v, want 0", result.Extensions[training.RestoredEpochKey])
+	}
+	if !slices.Equal(params, first) {
+		t.Errorf("restored params = %v, want those after epoch 0, %v", params, first)
+	}
+
+	// Without RestoreBest the final parameters are kept.
+	if params, _ = train(training.WorkflowConfig{NumEpochs: 20, MaxNoImprove: 3}); slices.Equal(params, first) {
+		t.Error("parameters restored without RestoreBest")
+	}
+
+	w := newSGDWorkflow()
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 1, LearningRate: 1, MaxNoImprove: 1, EarlyStopMode: "up"}); err == nil {
+		t.Error("Initialize accepted an invalid EarlyStopMode")
+	}
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 1, LearningRate: 1, MaxNoImprove: 1, EarlyStopMetric: "f1"}); err != nil {
+		t.Fatal(err)
+	}
+	rig := newRegressionRig(t)
+	if _, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 2)}, &rigModels{g: rig.g}); err == nil || !strings.Contains(err.Error(), `metric "f1"`) {
+		t.Errorf("unknown metric: err = %v", err)
+	}
+}
+
+func TestStandardWorkflow_ValidationSplit(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	w := newSGDWorkflow(training.WithValidationSplit[float32](0.25))
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 2, LearningRate: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 4)}, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.Metrics["val_loss"]; !ok {
+		t.Errorf("no validation on the held-out split: %v", result.Metrics)
+	}
+
+	// A split that leaves one side empty is rejected.
+	if _, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 2)}, &rigModels{g: rig.g}); err == nil {
+		t.Error("0.25 of 2 batches should be rejected")
+	}
+}
+
+func TestStandardWorkflow_LRScheduler(t *testing.T) {
+	ctx := context.Background()
+	rig := newRegressionRig(t)
+	var gotLR float64
+	var gotEpochs int
+	w := newSGDWorkflow(training.WithLRScheduler(func(lr float64, epochs int) scheduler.Scheduler[float32] {
+		gotLR, gotEpochs = lr, epochs
+		s, err := scheduler.NewStepDecay(scheduler.StepDecayConfig[float32]{InitialLR: float32(lr), StepSize: 1, Gamma: 0.5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}))
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 3, LearningRate: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 2)}, &rigModels{g: rig.g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotLR != 0.1 || gotEpochs != 3 {
+		t.Errorf("scheduler factory got lr %v, epochs %d", gotLR, gotEpochs)
+	}
+	// The third epoch ran at 0.1 * 0.5^2.
+	if got := result.Metrics["learning_rate"]; math.Abs(got-0.025) > 1e-6 {
+		t.Errorf("learning_rate = %v, want 0.025", got)
+	}
+
+	// Scheduling needs an optimizer whose rate can be set.
+	w = training.NewStandardWorkflow(
+		func(e compute.Engine[float32]) graph.Node[float32] { return loss.NewMSE(e, e.Ops()) },
+		func(compute.Engine[float32], float64) optimizer.Optimizer[float32] { return fixedLROptimizer{} },
+		training.WithLRScheduler(func(lr float64, _ int) scheduler.Scheduler[float32] {
+			return scheduler.NewCosineAnnealing(scheduler.CosineAnnealingConfig[float32]{EtaMax: float32(lr), TMax: 3})
+		}),
+	)
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 1, LearningRate: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 1)}, &rigModels{g: rig.g}); err == nil {
+		t.Error("Train with a fixed-rate optimizer and a scheduler should fail")
+	}
+}
+
+// fixedLROptimizer is an optimizer without optimizer.LRSetter.
+type fixedLROptimizer struct{}
+
+func (fixedLROptimizer) Step(context.Context, []*graph.Parameter[float32]) error { return nil }
+
+func TestStandardWorkflow_InitializeValidation(t *testing.T) {
+	ctx := context.Background()
+	for _, cfg := range []training.WorkflowConfig{
+		{NumEpochs: 0, LearningRate: 0.1},
+		{NumEpochs: 1, LearningRate: 0},
+		{NumEpochs: 1, LearningRate: 0.1, MaxNoImprove: -1},
+		{NumEpochs: 1, LearningRate: 0.1, AccumulationSteps: -1},
+	} {
+		if err := newSGDWorkflow().Initialize(ctx, cfg); err == nil {
+			t.Errorf("Initialize(%+v) should fail", cfg)
+		}
+	}
+	if err := newSGDWorkflow(training.WithValidationSplit[float32](1)).Initialize(ctx, training.WorkflowConfig{NumEpochs: 1, LearningRate: 0.1}); err == nil {
+		t.Error("validation split 1 should be rejected")
+	}
+	if _, err := newSGDWorkflow().Train(ctx, &staticData{}, &rigModels{}); err == nil {
+		t.Error("Train before Initialize should fail")
+	}
+}
+
+func TestStandardWorkflow_Registered(t *testing.T) {
+	ctx := context.Background()
+	w, err := training.Float32Registry.GetWorkflow(ctx, training.StandardWorkflowName, map[string]interface{}{"optimizer": "sgd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rig := newRegressionRig(t)
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 3, LearningRate: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Train(ctx, &staticData{train: rig.batches(t, 1, 2)}, &rigModels{g: rig.g}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := training.Float64Registry.GetWorkflow(ctx, training.StandardWorkflowName, nil); err != nil {
+		t.Errorf("float64 registry: %v", err)
+	}
+	for _, cfg := range []map[string]interface{}{{"loss": "hinge"}, {"optimizer": "lbfgs"}, {"loss": 3}} {
+		if _, err := training.Float32Registry.GetWorkflow(ctx, training.StandardWorkflowName, cfg); err == nil {
+			t.Errorf("config %v should be rejected", cfg)
+		}
+	}
+}
+
+// modeSpy is a pass-through node that counts forward passes per mode.
+type modeSpy struct {
+	graph.NoParameters[float32]
+	training            bool
+	trainPass, evalPass int
+}
+
+func (s *modeSpy) SetTraining(training bool)  { s.training = training }
+func (s *modeSpy) OpType() string             { return "ModeSpy" }
+func (s *modeSpy) Attributes() map[string]any { return nil }
+func (s *modeSpy) OutputShape() []int         { return []int{1, 1} }
+func (s *modeSpy) Forward(_ context.Context, in ...*tensor.TensorNumeric[float32]) (*tensor.TensorNumeric[float32], error) {
+	if s.training {
+		s.trainPass++
+	} else {
+		s.evalPass++
+	}
+	return in[0], nil
+}
+
+func (s *modeSpy) Backward(_ context.Context, _ types.BackwardMode, dOut *tensor.TensorNumeric[float32], _ ...*tensor.TensorNumeric[float32]) ([]*tensor.TensorNumeric[float32], error) {
+	return []*tensor.TensorNumeric[float32]{dOut}, nil
+}
+
+func TestStandardWorkflow_TrainingMode(t *testing.T) {
+	ctx := context.Background()
+	ops := numeric.Float32Ops{}
+	engine := compute.NewCPUEngine[float32](ops)
+	b := graph.NewBuilder[float32](engine)
+	input := b.Input([]int{1, 2})
+	dense, err := core.NewDense[float32]("dense", engine, ops, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spy := &modeSpy{}
+	g, err := b.Build(b.AddNode(spy, b.AddNode(dense, input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rig := &regressionRig{g: g, input: input}
+	data := &staticData{train: rig.batches(t, 1, 3), valid: rig.batches(t, 2, 2)}
+
+	w := newSGDWorkflow()
+	if err := w.Initialize(ctx, training.WorkflowConfig{NumEpochs: 2, LearningRate: 0.01}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Train(ctx, data, &rigModels{g: g}); err != nil {
+		t.Fatal(err)
+	}
+	if spy.trainPass != 6 || spy.evalPass != 4 {
+		t.Errorf("forward passes in training, inference mode = %d, %d; want 6, 4", spy.trainPass, spy.evalPass)
+	}
+	if spy.training {
+		t.Error("model left in training mode after Train")
+	}
+}